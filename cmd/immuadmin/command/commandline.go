@@ -91,6 +91,8 @@ func (cl *commandline) Register(rootCmd *cobra.Command) *cobra.Command {
 	cl.stats(rootCmd)
 	cl.serverConfig(rootCmd)
 	cl.database(rootCmd)
+	cl.operation(rootCmd)
+	cl.job(rootCmd)
 	return rootCmd
 }
 
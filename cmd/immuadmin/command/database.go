@@ -18,6 +18,7 @@ package immuadmin
 
 import (
 	"fmt"
+	"strings"
 
 	c "github.com/codenotary/immudb/cmd/helper"
 	"github.com/codenotary/immudb/pkg/api/schema"
@@ -32,7 +33,7 @@ func (cl *commandline) database(cmd *cobra.Command) {
 		Aliases: []string{"d"},
 		//PersistentPreRunE: cl.ConfigChain(cl.connect),
 		PersistentPostRun: cl.disconnect,
-		ValidArgs:         []string{"list", "create", "use", "clean"},
+		ValidArgs:         []string{"list", "create", "use", "clean", "keyrules"},
 	}
 	ccd := &cobra.Command{
 		Use:               "list",
@@ -68,10 +69,25 @@ func (cl *commandline) database(cmd *cobra.Command) {
 		Short:             "Create a new database",
 		PersistentPreRunE: cl.ConfigChain(cl.connect),
 		PersistentPostRun: cl.disconnect,
-		Example:           "create {database_name}",
+		Example:           "create {database_name} [--worm [--worm-max-versions 1]] [--compression flate]",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			worm, err := cmd.Flags().GetBool("worm")
+			if err != nil {
+				return err
+			}
+			wormMaxVersions, err := cmd.Flags().GetUint32("worm-max-versions")
+			if err != nil {
+				return err
+			}
+			compression, err := cmd.Flags().GetString("compression")
+			if err != nil {
+				return err
+			}
 			if err := cl.immuClient.CreateDatabase(cl.context, &schema.Database{
-				DatabaseName: args[0],
+				DatabaseName:    args[0],
+				Worm:            worm,
+				WormMaxVersions: wormMaxVersions,
+				Compression:     compression,
 			}); err != nil {
 				return err
 			}
@@ -80,6 +96,9 @@ func (cl *commandline) database(cmd *cobra.Command) {
 		},
 		Args: cobra.ExactArgs(1),
 	}
+	cc.Flags().Bool("worm", false, "create the database in write-once mode, it can't be disabled afterwards")
+	cc.Flags().Uint32("worm-max-versions", 0, "maximum number of values a key can be assigned in write-once mode (default 0, no limit)")
+	cc.Flags().String("compression", "none", "format values are compressed with before being written: none, flate, gzip, lzw or zlib. It can't be changed afterwards")
 
 	ccu := &cobra.Command{
 		Use:               "use command",
@@ -131,6 +150,72 @@ func (cl *commandline) database(cmd *cobra.Command) {
 		Args: cobra.ExactArgs(0),
 	}
 
+	cck := &cobra.Command{
+		Use:               "keyrules",
+		Short:             "Show or set the naming rules keys written into the selected database must satisfy",
+		Example:           "keyrules --allowed-prefix orders: --allowed-prefix customers: --max-depth 3",
+		PersistentPreRunE: cl.ConfigChain(cl.connect),
+		PersistentPostRun: cl.disconnect,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cmd.Flags().NFlag() > 0 {
+				prefixes, err := cmd.Flags().GetStringArray("allowed-prefix")
+				if err != nil {
+					return err
+				}
+				pattern, err := cmd.Flags().GetString("pattern")
+				if err != nil {
+					return err
+				}
+				maxDepth, err := cmd.Flags().GetUint32("max-depth")
+				if err != nil {
+					return err
+				}
+				separator, err := cmd.Flags().GetString("separator")
+				if err != nil {
+					return err
+				}
+
+				rules := &schema.KeyRules{Pattern: pattern, MaxDepth: maxDepth, Separator: separator}
+				for _, p := range prefixes {
+					rules.AllowedPrefixes = append(rules.AllowedPrefixes, []byte(p))
+				}
+
+				if clear, _ := cmd.Flags().GetBool("clear"); clear {
+					rules = &schema.KeyRules{}
+				}
+
+				if err := cl.immuClient.SetKeyRules(cl.context, rules); err != nil {
+					cl.quit(err)
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "key rules successfully set\n")
+				return nil
+			}
+
+			rules, err := cl.immuClient.GetKeyRules(cl.context)
+			if err != nil {
+				cl.quit(err)
+			}
+
+			prefixes := make([]string, len(rules.AllowedPrefixes))
+			for i, p := range rules.AllowedPrefixes {
+				prefixes[i] = string(p)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Allowed prefixes: %s\n", strings.Join(prefixes, ", "))
+			fmt.Fprintf(cmd.OutOrStdout(), "Pattern:          %s\n", rules.Pattern)
+			fmt.Fprintf(cmd.OutOrStdout(), "Max depth:        %d\n", rules.MaxDepth)
+			fmt.Fprintf(cmd.OutOrStdout(), "Separator:        %s\n", rules.Separator)
+			return nil
+		},
+		Args: cobra.ExactArgs(0),
+	}
+	cck.Flags().StringArray("allowed-prefix", nil, "prefix keys are allowed to start with, it can be repeated")
+	cck.Flags().String("pattern", "", "regular expression keys must fully match")
+	cck.Flags().Uint32("max-depth", 0, "maximum number of separator-delimited key segments")
+	cck.Flags().String("separator", "", "character delimiting key segments (default ':')")
+	cck.Flags().Bool("clear", false, "remove all the rules")
+
+	ccmd.AddCommand(cck)
 	ccmd.AddCommand(ccc)
 	ccmd.AddCommand(ccu)
 	ccmd.AddCommand(ccd)
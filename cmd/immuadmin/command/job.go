@@ -0,0 +1,228 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package immuadmin
+
+import (
+	"fmt"
+	"time"
+
+	c "github.com/codenotary/immudb/cmd/helper"
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/spf13/cobra"
+)
+
+func (cl *commandline) job(cmd *cobra.Command) {
+	ccmd := &cobra.Command{
+		Use:               "job",
+		Short:             "Issue all job commands",
+		Aliases:           []string{"j"},
+		PersistentPostRun: cl.disconnect,
+		ValidArgs:         []string{"schedule", "unschedule", "list", "runs", "status", "cancel", "watch"},
+	}
+	ccs := &cobra.Command{
+		Use:               "schedule",
+		Short:             "Create or replace a job schedule",
+		Example:           "schedule nightly-compaction compaction \"0 3 * * *\" --database mydb",
+		PersistentPreRunE: cl.ConfigChain(cl.connect),
+		PersistentPostRun: cl.disconnect,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			database, err := cmd.Flags().GetString("database")
+			if err != nil {
+				cl.quit(err)
+			}
+			if err := cl.immuClient.ScheduleJob(cl.context, &schema.JobSchedule{
+				Name:     args[0],
+				Kind:     args[1],
+				Cron:     args[2],
+				Database: database,
+			}); err != nil {
+				cl.quit(err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "job %s successfully scheduled\n", args[0])
+			return nil
+		},
+		Args: cobra.ExactArgs(3),
+	}
+	ccs.Flags().String("database", "", "database the job is run on, all databases when not set")
+	ccu := &cobra.Command{
+		Use:               "unschedule",
+		Short:             "Remove a job schedule",
+		Example:           "unschedule {job_name}",
+		PersistentPreRunE: cl.ConfigChain(cl.connect),
+		PersistentPostRun: cl.disconnect,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cl.immuClient.UnscheduleJob(cl.context, args[0]); err != nil {
+				cl.quit(err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "job %s successfully unscheduled\n", args[0])
+			return nil
+		},
+		Args: cobra.ExactArgs(1),
+	}
+	ccl := &cobra.Command{
+		Use:               "list",
+		Short:             "List the jobs scheduled on the server",
+		Aliases:           []string{"l"},
+		PersistentPreRunE: cl.ConfigChain(cl.connect),
+		PersistentPostRun: cl.disconnect,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			resp, err := cl.immuClient.ListJobSchedules(cl.context)
+			if err != nil {
+				cl.quit(err)
+			}
+			c.PrintTable(
+				cmd.OutOrStdout(),
+				[]string{"Name", "Kind", "Cron", "Database"},
+				len(resp.Schedules),
+				func(i int) []string {
+					js := resp.Schedules[i]
+					database := js.Database
+					if database == "" {
+						database = "*"
+					}
+					return []string{js.Name, js.Kind, js.Cron, database}
+				},
+				fmt.Sprintf("%d job(s)", len(resp.Schedules)),
+			)
+			return nil
+		},
+		Args: cobra.ExactArgs(0),
+	}
+	ccr := &cobra.Command{
+		Use:               "runs",
+		Short:             "List the most recent runs of a scheduled job",
+		Example:           "runs {job_name} --limit 10",
+		PersistentPreRunE: cl.ConfigChain(cl.connect),
+		PersistentPostRun: cl.disconnect,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			limit, err := cmd.Flags().GetUint32("limit")
+			if err != nil {
+				cl.quit(err)
+			}
+			resp, err := cl.immuClient.ListJobRuns(cl.context, args[0], limit)
+			if err != nil {
+				cl.quit(err)
+			}
+			c.PrintTable(
+				cmd.OutOrStdout(),
+				[]string{"Database", "Started", "Duration", "Error"},
+				len(resp.Runs),
+				func(i int) []string {
+					run := resp.Runs[i]
+					return []string{
+						run.Database,
+						time.Unix(run.StartedAt, 0).Format(time.RFC3339),
+						(time.Duration(run.FinishedAt-run.StartedAt) * time.Second).String(),
+						run.Error,
+					}
+				},
+				fmt.Sprintf("%d run(s)", len(resp.Runs)),
+			)
+			return nil
+		},
+		Args: cobra.ExactArgs(1),
+	}
+	ccr.Flags().Uint32("limit", 0, "maximum number of runs to be listed")
+	ccst := &cobra.Command{
+		Use:               "status",
+		Short:             "Show the running and recently finished jobs, or a single job given its id",
+		Example:           "status [job_id]",
+		PersistentPreRunE: cl.ConfigChain(cl.connect),
+		PersistentPostRun: cl.disconnect,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var jobs []*schema.Job
+			if len(args) == 1 {
+				jb, err := cl.immuClient.GetJob(cl.context, args[0])
+				if err != nil {
+					cl.quit(err)
+				}
+				jobs = append(jobs, jb)
+			} else {
+				resp, err := cl.immuClient.ListJobs(cl.context)
+				if err != nil {
+					cl.quit(err)
+				}
+				jobs = resp.Jobs
+			}
+			c.PrintTable(
+				cmd.OutOrStdout(),
+				[]string{"ID", "Kind", "Database", "State", "Progress", "Started", "Error"},
+				len(jobs),
+				func(i int) []string {
+					jb := jobs[i]
+					return []string{
+						jb.Id,
+						jb.Kind,
+						jb.Database,
+						jb.State,
+						fmt.Sprintf("%d%%", jb.Progress),
+						time.Unix(jb.StartedAt, 0).Format(time.RFC3339),
+						jb.Error,
+					}
+				},
+				fmt.Sprintf("%d job(s)", len(jobs)),
+			)
+			return nil
+		},
+		Args: cobra.MaximumNArgs(1),
+	}
+	ccc := &cobra.Command{
+		Use:               "cancel",
+		Short:             "Cancel a running job",
+		Example:           "cancel {job_id}",
+		PersistentPreRunE: cl.ConfigChain(cl.connect),
+		PersistentPostRun: cl.disconnect,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cl.immuClient.CancelJob(cl.context, args[0]); err != nil {
+				cl.quit(err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "job %s successfully cancelled\n", args[0])
+			return nil
+		},
+		Args: cobra.ExactArgs(1),
+	}
+	ccw := &cobra.Command{
+		Use:               "watch",
+		Short:             "Follow the progress of a job until it is finished",
+		Example:           "watch {job_id}",
+		PersistentPreRunE: cl.ConfigChain(cl.connect),
+		PersistentPostRun: cl.disconnect,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := cl.immuClient.WatchJob(cl.context, args[0], func(jb *schema.Job) error {
+				if jb.Error != "" {
+					fmt.Fprintf(cmd.OutOrStdout(), "%s %s %d%%: %s\n", jb.Id, jb.State, jb.Progress, jb.Error)
+					return nil
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "%s %s %d%%\n", jb.Id, jb.State, jb.Progress)
+				return nil
+			})
+			if err != nil {
+				cl.quit(err)
+			}
+			return nil
+		},
+		Args: cobra.ExactArgs(1),
+	}
+
+	ccmd.AddCommand(ccs)
+	ccmd.AddCommand(ccu)
+	ccmd.AddCommand(ccl)
+	ccmd.AddCommand(ccr)
+	ccmd.AddCommand(ccst)
+	ccmd.AddCommand(ccc)
+	ccmd.AddCommand(ccw)
+	cmd.AddCommand(ccmd)
+}
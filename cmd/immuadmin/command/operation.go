@@ -0,0 +1,87 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package immuadmin
+
+import (
+	"fmt"
+	"time"
+
+	c "github.com/codenotary/immudb/cmd/helper"
+	"github.com/spf13/cobra"
+)
+
+func (cl *commandline) operation(cmd *cobra.Command) {
+	ccmd := &cobra.Command{
+		Use:               "operation",
+		Short:             "Issue all operation commands",
+		Aliases:           []string{"o"},
+		PersistentPostRun: cl.disconnect,
+		ValidArgs:         []string{"list", "cancel"},
+	}
+	ccl := &cobra.Command{
+		Use:               "list",
+		Short:             "List operations currently running on the server",
+		Aliases:           []string{"l"},
+		PersistentPreRunE: cl.ConfigChain(cl.connect),
+		PersistentPostRun: cl.disconnect,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			resp, err := cl.immuClient.ListOperations(cl.context)
+			if err != nil {
+				cl.quit(err)
+			}
+			c.PrintTable(
+				cmd.OutOrStdout(),
+				[]string{"ID", "Method", "User", "Database", "Client", "Elapsed", "Cancelled"},
+				len(resp.Operations),
+				func(i int) []string {
+					op := resp.Operations[i]
+					return []string{
+						op.Id,
+						op.Method,
+						op.User,
+						op.Database,
+						op.Client,
+						(time.Duration(op.Elapsed) * time.Millisecond).String(),
+						fmt.Sprintf("%t", op.Cancelled),
+					}
+				},
+				fmt.Sprintf("%d operation(s)", len(resp.Operations)),
+			)
+			return nil
+		},
+		Args: cobra.ExactArgs(0),
+	}
+	ccc := &cobra.Command{
+		Use:               "cancel",
+		Short:             "Cancel a running operation",
+		Example:           "cancel {operation_id}",
+		PersistentPreRunE: cl.ConfigChain(cl.connect),
+		PersistentPostRun: cl.disconnect,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cl.immuClient.CancelOperation(cl.context, args[0]); err != nil {
+				cl.quit(err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "operation %s successfully cancelled\n", args[0])
+			return nil
+		},
+		Args: cobra.ExactArgs(1),
+	}
+
+	ccmd.AddCommand(ccl)
+	ccmd.AddCommand(ccc)
+	cmd.AddCommand(ccmd)
+}
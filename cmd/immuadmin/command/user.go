@@ -22,6 +22,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	c "github.com/codenotary/immudb/cmd/helper"
 	"github.com/codenotary/immudb/pkg/api/schema"
@@ -126,12 +127,31 @@ immuadmin user create user1 admin mydb`,
 		},
 		Args: cobra.ExactValidArgs(4),
 	}
+	userEnrollmentToken := &cobra.Command{
+		Use:     "enrollment-token [read|readwrite] {database}",
+		Short:   "Create a one-time token a device can exchange for its own credentials",
+		Example: "immuadmin user enrollment-token readwrite mydb --ttl 1h",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ttl, err := cmd.Flags().GetDuration("ttl")
+			if err != nil {
+				return err
+			}
+			var resp string
+			if resp, err = cl.createEnrollmentToken(args, ttl); err == nil {
+				fmt.Fprint(cmd.OutOrStdout(), resp)
+			}
+			return err
+		},
+		Args: cobra.ExactArgs(2),
+	}
+	userEnrollmentToken.Flags().Duration("ttl", 0, "validity of the token, server default is used if not specified")
 	ccmd.AddCommand(userListCmd)
 	ccmd.AddCommand(userCreate)
 	ccmd.AddCommand(userChangePassword)
 	ccmd.AddCommand(userActivate)
 	ccmd.AddCommand(userDeactivate)
 	ccmd.AddCommand(userPermission)
+	ccmd.AddCommand(userEnrollmentToken)
 	cmd.AddCommand(ccmd)
 }
 
@@ -283,6 +303,20 @@ func (cl *commandline) userCreate(args []string) (string, error) {
 	return fmt.Sprintf("Created user %s", username), nil
 }
 
+func (cl *commandline) createEnrollmentToken(args []string, ttl time.Duration) (string, error) {
+	permission, err := permissionFromString(args[0])
+	if err != nil {
+		return "", err
+	}
+
+	et, err := cl.immuClient.CreateEnrollmentToken(cl.context, args[1], permission, ttl)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Enrollment token (valid until %s):\n%s\n", time.Unix(et.ExpiresAt, 0).Format(time.RFC3339), et.Token), nil
+}
+
 func (cl *commandline) setActiveUser(args []string, active bool) (string, error) {
 	username := args[0]
 	err := cl.immuClient.SetActiveUser(cl.context, &schema.SetActiveUserRequest{
@@ -0,0 +1,201 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package immubench
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/client"
+)
+
+const preloadBatchSize = 100
+
+// benchmark sends operations to the server and records their latency
+type benchmark struct {
+	cli      client.ImmuClient
+	recorder *recorder
+
+	// set when the run is recorded as a trace
+	trace      *traceWriter
+	traceErr   error
+	traceMutex sync.Mutex
+}
+
+// run sends every operation received from ops through the given number of concurrent workers
+func (b *benchmark) run(ctx context.Context, ops <-chan *operation, concurrency int) *report {
+	start := time.Now()
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for op := range ops {
+				sentAt := time.Since(start)
+
+				err := op.execute(ctx, b.cli)
+				b.recorder.record(op.Op, time.Since(start)-sentAt, err)
+
+				if b.trace != nil {
+					op.At = sentAt.Microseconds()
+					b.recordTrace(op)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return b.recorder.report(time.Since(start))
+}
+
+func (b *benchmark) recordTrace(op *operation) {
+	b.traceMutex.Lock()
+	defer b.traceMutex.Unlock()
+
+	if b.traceErr == nil {
+		b.traceErr = b.trace.write(op)
+	}
+}
+
+// generate sends the operations of the workload until its duration or number of operations is reached
+func generate(ctx context.Context, w *workload, ops chan<- *operation) {
+	defer close(ops)
+
+	g := newGenerator(w)
+	start := time.Now()
+
+	for i := 0; w.ops <= 0 || i < w.ops; i++ {
+		if w.duration > 0 && time.Since(start) >= w.duration {
+			return
+		}
+
+		select {
+		case ops <- g.next():
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// replay sends the operations of a trace at their recorded offsets divided by speed,
+// or as fast as possible when speed is zero
+func replay(ctx context.Context, tr *traceReader, speed float64, ops chan<- *operation) error {
+	defer close(ops)
+
+	start := time.Now()
+
+	for {
+		op, err := tr.next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if speed > 0 {
+			wait := time.Duration(float64(op.At)*float64(time.Microsecond)/speed) - time.Since(start)
+			if wait > 0 {
+				select {
+				case <-time.After(wait):
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+		}
+
+		select {
+		case ops <- op:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// prepare creates the table used by the SQL workload
+func prepare(ctx context.Context, cli client.ImmuClient, w *workload) error {
+	if w.kind != workloadSQL {
+		return nil
+	}
+
+	_, err := cli.SQLExec(ctx, fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s(id INTEGER, value VARCHAR, PRIMARY KEY id)", benchTable), nil)
+	return err
+}
+
+// preload writes every key of the workload, so reads and sorted set additions find them
+func preload(ctx context.Context, cli client.ImmuClient, w *workload) error {
+	g := newGenerator(w)
+
+	for from := 0; from < w.keys; from += preloadBatchSize {
+		to := from + preloadBatchSize
+		if to > w.keys {
+			to = w.keys
+		}
+
+		var err error
+
+		switch w.kind {
+		case workloadSQL:
+			rows := make([]string, 0, to-from)
+			params := make(map[string]interface{}, 2*(to-from))
+
+			for i := from; i < to; i++ {
+				rows = append(rows, fmt.Sprintf("(@id%d, @value%d)", i, i))
+				params[fmt.Sprintf("id%d", i)] = int64(i)
+				params[fmt.Sprintf("value%d", i)] = string(g.nextValue())
+			}
+
+			_, err = cli.SQLExec(ctx, fmt.Sprintf("UPSERT INTO %s(id, value) VALUES %s", benchTable, strings.Join(rows, ", ")), params)
+
+		case workloadZ:
+			req := &schema.ExecAllRequest{}
+
+			for i := from; i < to; i++ {
+				req.Operations = append(req.Operations,
+					&schema.Op{Operation: &schema.Op_Kv{Kv: &schema.KeyValue{Key: benchKey(i), Value: g.nextValue()}}},
+					&schema.Op{Operation: &schema.Op_ZAdd{ZAdd: &schema.ZAddRequest{Set: []byte(benchSet), Key: benchKey(i), Score: float64(i)}}},
+				)
+			}
+
+			_, err = cli.ExecAll(ctx, req)
+
+		default:
+			req := &schema.SetRequest{}
+
+			for i := from; i < to; i++ {
+				req.KVs = append(req.KVs, &schema.KeyValue{Key: benchKey(i), Value: g.nextValue()})
+			}
+
+			_, err = cli.SetAll(ctx, req)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
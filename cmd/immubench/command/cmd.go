@@ -0,0 +1,214 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package immubench
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/codenotary/immudb/cmd/version"
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/auth"
+	"github.com/codenotary/immudb/pkg/client"
+	"github.com/codenotary/immudb/pkg/server"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/metadata"
+)
+
+// NewCmd creates a new immubench command
+func NewCmd(newImmuClient func(*client.Options) (client.ImmuClient, error), out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "immubench",
+		Short: "Generate load against immudb and report latency percentiles and throughput",
+		Long: `Generate load against immudb and report latency percentiles and throughput.
+
+Workloads write and read keys (kv), sorted set entries (zset) or table rows (sql), picking keys uniformly,
+following a zipf distribution or sequentially. Keys are written before the run unless --preload=false.
+
+Runs can be recorded with --record and replayed with --replay. Traces hold one JSON operation per line:
+  {"at":1500,"op":"set","key":"a2V5","value":"dmFsdWU="}
+  {"at":2100,"op":"sqlQuery","sql":"SELECT * FROM t WHERE id = @id","params":{"id":1}}
+where "at" is the offset in microseconds from the beginning of the trace, keys and values are base64 encoded
+and op is one of set, verifiedSet, get, verifiedGet, zadd, verifiedZAdd, zscan, sqlExec or sqlQuery.`,
+		Example: `  immubench --workload kv --read-ratio 0.9 --distribution zipf --duration 30s
+  immubench --workload sql --concurrency 16 --ops 100000
+  immubench --workload kv --verified --duration 1m --record trace.jsonl
+  immubench --replay trace.jsonl --replay-speed 2`,
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	flags := cmd.Flags()
+	flags.StringP("immudb-address", "a", client.DefaultOptions().Address, "immudb host address")
+	flags.IntP("immudb-port", "p", client.DefaultOptions().Port, "immudb port number")
+	flags.StringP("user", "u", auth.SysAdminUsername, "user name")
+	flags.String("password", auth.SysAdminPassword, "user password")
+	flags.StringP("database", "d", server.DefaultdbName, "database to run the benchmark against")
+
+	flags.String("workload", workloadKV, "kind of workload: kv, zset or sql")
+	flags.Int("keys", 10000, "number of distinct keys")
+	flags.String("distribution", distributionUniform, "key distribution: uniform, zipf or sequential")
+	flags.Int("value-size", 256, "size in bytes of written values")
+	flags.Float64("read-ratio", 0.5, "fraction of read operations, between 0 and 1")
+	flags.Bool("verified", false, "use verified reads and writes (kv and zset workloads)")
+	flags.Int("concurrency", 8, "number of concurrent workers")
+	flags.Duration("duration", 10*time.Second, "duration of the run, zero to run until --ops operations are sent")
+	flags.Int("ops", 0, "number of operations to send, zero to run for --duration")
+	flags.Int64("seed", 0, "seed of the generated workload, zero for a random one")
+	flags.Bool("preload", true, "write every key before the run")
+	flags.String("replay", "", "replay the operations of a trace instead of generating a workload")
+	flags.Float64("replay-speed", 1, "speed multiplier applied to the offsets of replayed operations, zero to send them as fast as possible")
+	flags.String("record", "", "record the operations sent during the run into a trace")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		w := &workload{}
+
+		w.kind, _ = flags.GetString("workload")
+		w.keys, _ = flags.GetInt("keys")
+		w.distribution, _ = flags.GetString("distribution")
+		w.valueSize, _ = flags.GetInt("value-size")
+		w.readRatio, _ = flags.GetFloat64("read-ratio")
+		w.verified, _ = flags.GetBool("verified")
+		w.concurrency, _ = flags.GetInt("concurrency")
+		w.duration, _ = flags.GetDuration("duration")
+		w.ops, _ = flags.GetInt("ops")
+		w.seed, _ = flags.GetInt64("seed")
+
+		if w.seed == 0 {
+			w.seed = time.Now().UnixNano()
+		}
+
+		if w.ops > 0 && !flags.Changed("duration") {
+			w.duration = 0
+		}
+
+		replayFile, _ := flags.GetString("replay")
+		replaySpeed, _ := flags.GetFloat64("replay-speed")
+		recordFile, _ := flags.GetString("record")
+		doPreload, _ := flags.GetBool("preload")
+
+		if replayFile == "" {
+			if err := w.validate(); err != nil {
+				return err
+			}
+		} else if w.concurrency <= 0 || replaySpeed < 0 {
+			return fmt.Errorf("the concurrency must be greater than 0 and the replay speed can't be negative")
+		}
+
+		address, _ := flags.GetString("immudb-address")
+		port, _ := flags.GetInt("immudb-port")
+		user, _ := flags.GetString("user")
+		password, _ := flags.GetString("password")
+		database, _ := flags.GetString("database")
+
+		cli, err := newImmuClient(client.DefaultOptions().WithAddress(address).WithPort(port))
+		if err != nil {
+			return err
+		}
+		defer cli.Disconnect()
+
+		ctx := context.Background()
+
+		lr, err := cli.Login(ctx, []byte(user), []byte(password))
+		if err != nil {
+			return err
+		}
+		ctx = metadata.NewOutgoingContext(ctx, metadata.Pairs("authorization", lr.Token))
+
+		ur, err := cli.UseDatabase(ctx, &schema.Database{DatabaseName: database})
+		if err != nil {
+			return err
+		}
+		ctx = metadata.NewOutgoingContext(ctx, metadata.Pairs("authorization", ur.Token))
+
+		b := &benchmark{cli: cli, recorder: newRecorder()}
+
+		if recordFile != "" {
+			f, err := os.Create(recordFile)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			b.trace = newTraceWriter(f)
+		}
+
+		ops := make(chan *operation, w.concurrency)
+
+		var rep *report
+
+		if replayFile != "" {
+			f, err := os.Open(replayFile)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			fmt.Fprintf(out, "Replaying %s with %d workers...\n", replayFile, w.concurrency)
+
+			replayErr := make(chan error, 1)
+			go func() {
+				replayErr <- replay(ctx, newTraceReader(f), replaySpeed, ops)
+			}()
+
+			rep = b.run(ctx, ops, w.concurrency)
+
+			if err := <-replayErr; err != nil {
+				return err
+			}
+		} else {
+			if err := prepare(ctx, cli, w); err != nil {
+				return err
+			}
+
+			if doPreload {
+				fmt.Fprintf(out, "Preloading %d keys...\n", w.keys)
+
+				if err := preload(ctx, cli, w); err != nil {
+					return err
+				}
+			}
+
+			fmt.Fprintf(out, "Running %s workload with %d workers (%s distribution, %.0f%% reads, seed %d)...\n",
+				w.kind, w.concurrency, w.distribution, w.readRatio*100, w.seed)
+
+			go generate(ctx, w, ops)
+
+			rep = b.run(ctx, ops, w.concurrency)
+		}
+
+		if b.trace != nil {
+			if b.traceErr != nil {
+				return b.traceErr
+			}
+			if err := b.trace.flush(); err != nil {
+				return err
+			}
+		}
+
+		rep.print(out)
+
+		return nil
+	}
+
+	cmd.AddCommand(version.VersionCmd())
+
+	return cmd
+}
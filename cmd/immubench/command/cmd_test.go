@@ -0,0 +1,184 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package immubench
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codenotary/immudb/pkg/client"
+	"github.com/codenotary/immudb/pkg/client/clienttest"
+	"github.com/stretchr/testify/require"
+)
+
+func newFakeClient(fake *clienttest.ImmuClientFake) func(*client.Options) (client.ImmuClient, error) {
+	return func(*client.Options) (client.ImmuClient, error) {
+		return fake, nil
+	}
+}
+
+func TestImmubenchKV(t *testing.T) {
+	fake := clienttest.NewImmuClientFake()
+
+	var out bytes.Buffer
+
+	cmd := NewCmd(newFakeClient(fake), &out)
+	cmd.SetArgs([]string{"--keys", "50", "--ops", "200", "--read-ratio", "0.5", "--verified", "--seed", "1"})
+
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	require.Contains(t, out.String(), "200 operations (0 errors)")
+	require.Contains(t, out.String(), opVerifiedGet)
+	require.Contains(t, out.String(), opVerifiedSet)
+
+	count, err := fake.CountAll(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, uint64(50), count.Count)
+}
+
+func TestImmubenchZSetRecordAndReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "immubench")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	trace := filepath.Join(dir, "trace.jsonl")
+
+	var out bytes.Buffer
+
+	cmd := NewCmd(newFakeClient(clienttest.NewImmuClientFake()), &out)
+	cmd.SetArgs([]string{"--workload", "zset", "--keys", "20", "--ops", "100", "--distribution", "zipf", "--record", trace})
+
+	err = cmd.Execute()
+	require.NoError(t, err)
+	require.Contains(t, out.String(), "100 operations (0 errors)")
+
+	content, err := ioutil.ReadFile(trace)
+	require.NoError(t, err)
+	require.Len(t, strings.Split(strings.TrimSpace(string(content)), "\n"), 100)
+
+	// the replayed operations find the keys written by the preload of the recorded run
+	fake := clienttest.NewImmuClientFake()
+
+	cmd = NewCmd(newFakeClient(fake), &out)
+	cmd.SetArgs([]string{"--workload", "zset", "--keys", "20", "--ops", "1", "--read-ratio", "1"})
+	require.NoError(t, cmd.Execute())
+
+	out.Reset()
+
+	cmd = NewCmd(newFakeClient(fake), &out)
+	cmd.SetArgs([]string{"--replay", trace, "--replay-speed", "0"})
+
+	err = cmd.Execute()
+	require.NoError(t, err)
+	require.Contains(t, out.String(), "100 operations (0 errors)")
+}
+
+func TestImmubenchInvalidArguments(t *testing.T) {
+	for _, args := range [][]string{
+		{"--workload", "graph"},
+		{"--distribution", "normal"},
+		{"--read-ratio", "2"},
+		{"--keys", "0"},
+		{"--duration", "0"},
+	} {
+		cmd := NewCmd(newFakeClient(clienttest.NewImmuClientFake()), ioutil.Discard)
+		cmd.SetArgs(args)
+		require.Error(t, cmd.Execute(), args)
+	}
+}
+
+func TestGenerator(t *testing.T) {
+	w := &workload{kind: workloadKV, keys: 10, distribution: distributionSequential, valueSize: 8, readRatio: 0}
+
+	g := newGenerator(w)
+
+	for i := 0; i < 25; i++ {
+		op := g.next()
+		require.Equal(t, opSet, op.Op)
+		require.Equal(t, benchKey(i%10), op.Key)
+		require.Len(t, op.Value, 8)
+	}
+
+	w = &workload{kind: workloadSQL, keys: 100, distribution: distributionZipf, readRatio: 1}
+
+	g = newGenerator(w)
+
+	hits := make(map[int64]int)
+	for i := 0; i < 1000; i++ {
+		op := g.next()
+		require.Equal(t, opSQLQuery, op.Op)
+		hits[op.Params["id"].(int64)]++
+	}
+
+	// the first keys are the most popular ones
+	require.Greater(t, hits[0], hits[50])
+}
+
+func TestTraceReader(t *testing.T) {
+	tr := newTraceReader(strings.NewReader(`{"at":10,"op":"set","key":"azE=","value":"djE="}
+
+{"at":20,"op":"sqlQuery","sql":"SELECT * FROM t WHERE id = @id","params":{"id":7,"name":"a"}}
+{"at":30,"op":"sqlQuery","params":{"id":1.5}}
+not json`))
+
+	op, err := tr.next()
+	require.NoError(t, err)
+	require.Equal(t, int64(10), op.At)
+	require.Equal(t, []byte("k1"), op.Key)
+	require.Equal(t, []byte("v1"), op.Value)
+
+	op, err = tr.next()
+	require.NoError(t, err)
+	require.Equal(t, int64(7), op.Params["id"])
+	require.Equal(t, "a", op.Params["name"])
+
+	_, err = tr.next()
+	require.Error(t, err)
+
+	_, err = tr.next()
+	require.Error(t, err)
+}
+
+func TestReport(t *testing.T) {
+	r := newRecorder()
+
+	for i := 1; i <= 100; i++ {
+		r.record(opGet, time.Duration(i)*time.Millisecond, nil)
+	}
+	r.record(opSet, time.Millisecond, context.DeadlineExceeded)
+
+	rep := r.report(time.Second)
+	require.Equal(t, 101, rep.count)
+	require.Equal(t, 1, rep.errors)
+	require.Equal(t, 101.0, rep.throughput())
+
+	require.Len(t, rep.operations, 2)
+	require.Equal(t, opGet, rep.operations[0].op)
+	require.Equal(t, 50*time.Millisecond, rep.operations[0].p50)
+	require.Equal(t, 99*time.Millisecond, rep.operations[0].p99)
+	require.Equal(t, 100*time.Millisecond, rep.operations[0].max)
+
+	var out bytes.Buffer
+	rep.print(&out)
+	require.Contains(t, out.String(), "101 operations (1 errors)")
+}
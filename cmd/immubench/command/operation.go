@@ -0,0 +1,165 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package immubench
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/client"
+)
+
+const (
+	opSet          = "set"
+	opVerifiedSet  = "verifiedSet"
+	opGet          = "get"
+	opVerifiedGet  = "verifiedGet"
+	opZAdd         = "zadd"
+	opVerifiedZAdd = "verifiedZAdd"
+	opZScan        = "zscan"
+	opSQLExec      = "sqlExec"
+	opSQLQuery     = "sqlQuery"
+)
+
+// operation is a single request sent to the server. Operations are also the lines of traces, encoded as JSON,
+// where At is the offset in microseconds from the beginning of the trace at which the operation is sent
+type operation struct {
+	At     int64                  `json:"at"`
+	Op     string                 `json:"op"`
+	Key    []byte                 `json:"key,omitempty"`
+	Value  []byte                 `json:"value,omitempty"`
+	Set    []byte                 `json:"set,omitempty"`
+	Score  float64                `json:"score,omitempty"`
+	SQL    string                 `json:"sql,omitempty"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+func (op *operation) execute(ctx context.Context, cli client.ImmuClient) error {
+	var err error
+
+	switch op.Op {
+	case opSet:
+		_, err = cli.Set(ctx, op.Key, op.Value)
+	case opVerifiedSet:
+		_, err = cli.VerifiedSet(ctx, op.Key, op.Value)
+	case opGet:
+		_, err = cli.Get(ctx, op.Key)
+	case opVerifiedGet:
+		_, err = cli.VerifiedGet(ctx, op.Key)
+	case opZAdd:
+		_, err = cli.ZAdd(ctx, op.Set, op.Score, op.Key)
+	case opVerifiedZAdd:
+		_, err = cli.VerifiedZAdd(ctx, op.Set, op.Score, op.Key)
+	case opZScan:
+		_, err = cli.ZScan(ctx, &schema.ZScanRequest{Set: op.Set, MinScore: &schema.Score{Score: op.Score}, Limit: zScanLimit})
+	case opSQLExec:
+		_, err = cli.SQLExec(ctx, op.SQL, op.Params)
+	case opSQLQuery:
+		_, err = cli.SQLQuery(ctx, op.SQL, op.Params, false)
+	default:
+		err = fmt.Errorf("unknown operation %q", op.Op)
+	}
+
+	return err
+}
+
+// traceReader reads the operations of a trace, one JSON object per line
+type traceReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func newTraceReader(r io.Reader) *traceReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 32*1024*1024)
+
+	return &traceReader{scanner: scanner}
+}
+
+// next returns the next operation of the trace, or io.EOF at its end
+func (tr *traceReader) next() (*operation, error) {
+	for tr.scanner.Scan() {
+		tr.line++
+
+		line := strings.TrimSpace(tr.scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		dec := json.NewDecoder(strings.NewReader(line))
+		dec.UseNumber()
+
+		op := &operation{}
+		if err := dec.Decode(op); err != nil {
+			return nil, fmt.Errorf("invalid trace line %d: %v", tr.line, err)
+		}
+
+		if err := op.normalizeParams(); err != nil {
+			return nil, fmt.Errorf("invalid trace line %d: %v", tr.line, err)
+		}
+
+		return op, nil
+	}
+
+	if err := tr.scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return nil, io.EOF
+}
+
+// normalizeParams converts numeric SQL parameters decoded from JSON into integers
+func (op *operation) normalizeParams() error {
+	for name, v := range op.Params {
+		n, ok := v.(json.Number)
+		if !ok {
+			continue
+		}
+
+		i, err := n.Int64()
+		if err != nil {
+			return fmt.Errorf("parameter %s is not an integer", name)
+		}
+
+		op.Params[name] = i
+	}
+
+	return nil
+}
+
+// traceWriter records operations so a run can be replayed
+type traceWriter struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+func newTraceWriter(w io.Writer) *traceWriter {
+	bw := bufio.NewWriter(w)
+	return &traceWriter{w: bw, enc: json.NewEncoder(bw)}
+}
+
+func (tw *traceWriter) write(op *operation) error {
+	return tw.enc.Encode(op)
+}
+
+func (tw *traceWriter) flush() error {
+	return tw.w.Flush()
+}
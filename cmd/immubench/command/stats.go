@@ -0,0 +1,130 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package immubench
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// recorder collects the latency of every operation sent during a run
+type recorder struct {
+	mutex     sync.Mutex
+	latencies map[string][]time.Duration
+	errors    map[string]int
+}
+
+func newRecorder() *recorder {
+	return &recorder{
+		latencies: make(map[string][]time.Duration),
+		errors:    make(map[string]int),
+	}
+}
+
+func (r *recorder) record(op string, latency time.Duration, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.latencies[op] = append(r.latencies[op], latency)
+	if err != nil {
+		r.errors[op]++
+	}
+}
+
+type operationStats struct {
+	op     string
+	count  int
+	errors int
+	p50    time.Duration
+	p90    time.Duration
+	p99    time.Duration
+	p999   time.Duration
+	max    time.Duration
+}
+
+type report struct {
+	elapsed    time.Duration
+	count      int
+	errors     int
+	operations []*operationStats
+}
+
+// percentile returns the nearest-rank percentile of sorted latencies
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	i := int(p*float64(len(sorted))+0.5) - 1
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(sorted) {
+		i = len(sorted) - 1
+	}
+
+	return sorted[i]
+}
+
+func (r *recorder) report(elapsed time.Duration) *report {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	rep := &report{elapsed: elapsed}
+
+	for op, latencies := range r.latencies {
+		sorted := make([]time.Duration, len(latencies))
+		copy(sorted, latencies)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+		rep.operations = append(rep.operations, &operationStats{
+			op:     op,
+			count:  len(sorted),
+			errors: r.errors[op],
+			p50:    percentile(sorted, 0.5),
+			p90:    percentile(sorted, 0.9),
+			p99:    percentile(sorted, 0.99),
+			p999:   percentile(sorted, 0.999),
+			max:    sorted[len(sorted)-1],
+		})
+
+		rep.count += len(sorted)
+		rep.errors += r.errors[op]
+	}
+
+	sort.Slice(rep.operations, func(i, j int) bool { return rep.operations[i].op < rep.operations[j].op })
+
+	return rep
+}
+
+func (rep *report) throughput() float64 {
+	if rep.elapsed <= 0 {
+		return 0
+	}
+	return float64(rep.count) / rep.elapsed.Seconds()
+}
+
+func (rep *report) print(w io.Writer) {
+	fmt.Fprintf(w, "%d operations (%d errors) in %s, %.1f ops/s\n", rep.count, rep.errors, rep.elapsed.Round(time.Millisecond), rep.throughput())
+	fmt.Fprintf(w, "%-14s %10s %8s %12s %12s %12s %12s %12s\n", "operation", "count", "errors", "p50", "p90", "p99", "p99.9", "max")
+
+	for _, s := range rep.operations {
+		fmt.Fprintf(w, "%-14s %10d %8d %12s %12s %12s %12s %12s\n", s.op, s.count, s.errors, s.p50, s.p90, s.p99, s.p999, s.max)
+	}
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package immubench
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+const (
+	workloadKV  = "kv"
+	workloadSQL = "sql"
+	workloadZ   = "zset"
+
+	distributionUniform    = "uniform"
+	distributionZipf       = "zipf"
+	distributionSequential = "sequential"
+
+	benchTable = "immubench"
+	benchSet   = "immubench"
+
+	zScanLimit = 10
+)
+
+// workload describes the load generated by a benchmark run
+type workload struct {
+	kind         string
+	keys         int
+	distribution string
+	valueSize    int
+	readRatio    float64
+	verified     bool
+	concurrency  int
+	duration     time.Duration
+	ops          int
+	seed         int64
+}
+
+func (w *workload) validate() error {
+	switch w.kind {
+	case workloadKV, workloadSQL, workloadZ:
+	default:
+		return fmt.Errorf("unknown workload %q, it must be one of %s, %s or %s", w.kind, workloadKV, workloadSQL, workloadZ)
+	}
+
+	switch w.distribution {
+	case distributionUniform, distributionZipf, distributionSequential:
+	default:
+		return fmt.Errorf("unknown key distribution %q, it must be one of %s, %s or %s",
+			w.distribution, distributionUniform, distributionZipf, distributionSequential)
+	}
+
+	if w.keys <= 0 || w.valueSize < 0 || w.concurrency <= 0 {
+		return errors.New("the number of keys and the concurrency must be greater than 0")
+	}
+
+	if w.readRatio < 0 || w.readRatio > 1 {
+		return errors.New("the read ratio must be between 0 and 1")
+	}
+
+	if w.duration <= 0 && w.ops <= 0 {
+		return errors.New("either a duration or a number of operations must be provided")
+	}
+
+	return nil
+}
+
+// generator produces the operations of a workload, it's not safe for concurrent use
+type generator struct {
+	w    *workload
+	rnd  *rand.Rand
+	zipf *rand.Zipf
+	seq  int
+}
+
+func newGenerator(w *workload) *generator {
+	g := &generator{
+		w:   w,
+		rnd: rand.New(rand.NewSource(w.seed)),
+	}
+
+	if w.distribution == distributionZipf && w.keys > 1 {
+		g.zipf = rand.NewZipf(g.rnd, 1.1, 1, uint64(w.keys-1))
+	}
+
+	return g
+}
+
+func (g *generator) nextKey() int {
+	switch g.w.distribution {
+	case distributionSequential:
+		k := g.seq % g.w.keys
+		g.seq++
+		return k
+	case distributionZipf:
+		if g.zipf == nil {
+			return 0
+		}
+		return int(g.zipf.Uint64())
+	default:
+		return g.rnd.Intn(g.w.keys)
+	}
+}
+
+const valueChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+func (g *generator) nextValue() []byte {
+	v := make([]byte, g.w.valueSize)
+	for i := range v {
+		v[i] = valueChars[g.rnd.Intn(len(valueChars))]
+	}
+	return v
+}
+
+func benchKey(i int) []byte {
+	return []byte(fmt.Sprintf("immubench:%010d", i))
+}
+
+func (g *generator) next() *operation {
+	read := g.rnd.Float64() < g.w.readRatio
+	k := g.nextKey()
+
+	switch g.w.kind {
+	case workloadSQL:
+		if read {
+			return &operation{
+				Op:     opSQLQuery,
+				SQL:    fmt.Sprintf("SELECT id, value FROM %s WHERE id = @id", benchTable),
+				Params: map[string]interface{}{"id": int64(k)},
+			}
+		}
+		return &operation{
+			Op:     opSQLExec,
+			SQL:    fmt.Sprintf("UPSERT INTO %s(id, value) VALUES (@id, @value)", benchTable),
+			Params: map[string]interface{}{"id": int64(k), "value": string(g.nextValue())},
+		}
+
+	case workloadZ:
+		score := g.rnd.Float64() * float64(g.w.keys)
+		if read {
+			return &operation{Op: opZScan, Set: []byte(benchSet), Score: score}
+		}
+		if g.w.verified {
+			return &operation{Op: opVerifiedZAdd, Set: []byte(benchSet), Key: benchKey(k), Score: score}
+		}
+		return &operation{Op: opZAdd, Set: []byte(benchSet), Key: benchKey(k), Score: score}
+
+	default:
+		if read {
+			if g.w.verified {
+				return &operation{Op: opVerifiedGet, Key: benchKey(k)}
+			}
+			return &operation{Op: opGet, Key: benchKey(k)}
+		}
+		if g.w.verified {
+			return &operation{Op: opVerifiedSet, Key: benchKey(k), Value: g.nextValue()}
+		}
+		return &operation{Op: opSet, Key: benchKey(k), Value: g.nextValue()}
+	}
+}
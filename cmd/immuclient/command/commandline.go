@@ -61,6 +61,7 @@ func (cl *commandline) Register(rootCmd *cobra.Command) *cobra.Command {
 	cl.safereference(rootCmd)
 	// misc
 	cl.consistency(rootCmd)
+	cl.verifyDB(rootCmd)
 	cl.history(rootCmd)
 	cl.status(rootCmd)
 	cl.auditmode(rootCmd)
@@ -19,6 +19,7 @@ package immuclient
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/codenotary/immudb/pkg/client"
 	"github.com/spf13/cobra"
@@ -56,6 +57,7 @@ func (cl *commandline) configureFlags(cmd *cobra.Command) error {
 	cmd.PersistentFlags().String("audit-notification-username", "", "Username used to authenticate when publishing audit result to 'audit-notification-url'.")
 	cmd.PersistentFlags().String("audit-notification-password", "", "Password used to authenticate when publishing audit result to 'audit-notification-url'.")
 	cmd.PersistentFlags().String("server-signing-pub-key", "", "Path to the public key to verify signatures when presents")
+	cmd.PersistentFlags().StringSlice("collection-codec", nil, fmt.Sprintf("codec used to display the values of the keys starting with a prefix, as 'prefix=codec' where codec is one of %s (can be repeated)", strings.Join(client.CodecNames(), ", ")))
 
 	viper.BindPFlag("immudb-port", cmd.PersistentFlags().Lookup("immudb-port"))
 	viper.BindPFlag("immudb-address", cmd.PersistentFlags().Lookup("immudb-address"))
@@ -81,6 +83,7 @@ func (cl *commandline) configureFlags(cmd *cobra.Command) error {
 	viper.BindPFlag("audit-notification-username", cmd.PersistentFlags().Lookup("audit-notification-username"))
 	viper.BindPFlag("audit-notification-password", cmd.PersistentFlags().Lookup("audit-notification-password"))
 	viper.BindPFlag("server-signing-pub-key", cmd.PersistentFlags().Lookup("server-signing-pub-key"))
+	viper.BindPFlag("collection-codec", cmd.PersistentFlags().Lookup("collection-codec"))
 
 	viper.SetDefault("immudb-port", client.DefaultOptions().Port)
 	viper.SetDefault("immudb-address", client.DefaultOptions().Address)
@@ -105,6 +108,7 @@ func (cl *commandline) configureFlags(cmd *cobra.Command) error {
 	viper.SetDefault("audit-notification-username", "")
 	viper.SetDefault("audit-notification-password", "")
 	viper.SetDefault("server-signing-pub-key", "")
+	viper.SetDefault("collection-codec", []string{})
 	viper.SetDefault("dir", os.TempDir())
 	return nil
 }
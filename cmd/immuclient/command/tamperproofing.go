@@ -18,6 +18,7 @@ package immuclient
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/spf13/cobra"
 )
@@ -42,3 +43,25 @@ func (cl *commandline) consistency(cmd *cobra.Command) {
 	}
 	cmd.AddCommand(ccmd)
 }
+
+func (cl *commandline) verifyDB(cmd *cobra.Command) {
+	ccmd := &cobra.Command{
+		Use:   "verify-db [checkpoint-file]",
+		Short: "Verify the whole database locally, recomputing the hash chain of every transaction",
+		Long: `Fetch every transaction of the current database, recompute its entries hash, accumulative linear hash and
+binary linking root locally and compare the resulting chain against the signed server state.
+When a checkpoint file is given, the progress is saved into it so an interrupted verification can be resumed.`,
+		PersistentPreRunE: cl.ConfigChain(cl.connect),
+		PersistentPostRun: cl.disconnect,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			resp, err := cl.immucl.VerifyDB(args, cmd.OutOrStdout())
+			if err != nil {
+				cl.quit(err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), resp+"\n")
+			return nil
+		},
+		Args: cobra.MaximumNArgs(1),
+	}
+	cmd.AddCommand(ccmd)
+}
@@ -88,7 +88,7 @@ func (i *immuc) Get(args []string) (string, error) {
 	}
 
 	entry := response.(*schema.Entry)
-	return PrintKV(entry.Key, entry.Value, entry.Tx, false, i.valueOnly), nil
+	return i.printKV(entry.Key, entry.Value, entry.Tx, false, i.valueOnly), nil
 }
 
 func (i *immuc) VerifiedGet(args []string) (string, error) {
@@ -109,5 +109,5 @@ func (i *immuc) VerifiedGet(args []string) (string, error) {
 	}
 
 	entry := response.(*schema.Entry)
-	return PrintKV(entry.Key, entry.Value, entry.Tx, true, i.valueOnly), nil
+	return i.printKV(entry.Key, entry.Value, entry.Tx, true, i.valueOnly), nil
 }
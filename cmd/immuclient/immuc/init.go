@@ -18,6 +18,7 @@ package immuc
 import (
 	"errors"
 	"fmt"
+	"io"
 	"strings"
 
 	c "github.com/codenotary/immudb/cmd/helper"
@@ -73,6 +74,7 @@ type Client interface {
 	SQLQuery(args []string) (string, error)
 	ListTables() (string, error)
 	DescribeTable(args []string) (string, error)
+	VerifyDB(args []string, out io.Writer) (string, error)
 }
 
 // Init ...
@@ -168,6 +170,12 @@ func Options() *client.Options {
 		WithTokenService(client.NewTokenService().WithTokenFileName(viper.GetString("tokenfile")).WithHds(client.NewHomedirService())).
 		WithServerSigningPubKey(viper.GetString("server-signing-pub-key"))
 
+	for _, cc := range viper.GetStringSlice("collection-codec") {
+		if i := strings.LastIndex(cc, "="); i >= 0 {
+			options.WithCollectionCodec(cc[:i], cc[i+1:])
+		}
+	}
+
 	if viper.GetBool("mtls") {
 		// todo https://golang.org/src/crypto/x509/root_linux.go
 		options.MTLsOptions = client.DefaultMTLsOptions().
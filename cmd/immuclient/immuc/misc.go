@@ -49,7 +49,7 @@ func (i *immuc) History(args []string) (string, error) {
 	}
 
 	for _, entry := range entries.Entries {
-		str.WriteString(PrintKV(entry.Key, entry.Value, entry.Tx, false, false))
+		str.WriteString(i.printKV(entry.Key, entry.Value, entry.Tx, false, false))
 		str.WriteString("\n")
 	}
 
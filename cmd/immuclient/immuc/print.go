@@ -23,21 +23,27 @@ import (
 
 	"github.com/codenotary/immudb/embedded/store"
 	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/client"
 )
 
 // PrintKV ...
 func PrintKV(key []byte, value []byte, tx uint64, verified, valueOnly bool) string {
+	return printKV(key, value, value, tx, verified, valueOnly)
+}
+
+// printKV prints displayed as the value, while the hash is calculated over the stored value
+func printKV(key []byte, value []byte, displayed []byte, tx uint64, verified, valueOnly bool) string {
 	hash := (&store.KV{Key: key, Value: value}).Digest()
 
 	if valueOnly {
-		return fmt.Sprintf("%s\n", value)
+		return fmt.Sprintf("%s\n", displayed)
 	}
 
 	str := strings.Builder{}
 	if !valueOnly {
 		str.WriteString(fmt.Sprintf("tx:		%d \n", tx))
 		str.WriteString(fmt.Sprintf("key:		%s \n", key))
-		str.WriteString(fmt.Sprintf("value:		%s \n", value))
+		str.WriteString(fmt.Sprintf("value:		%s \n", displayed))
 		str.WriteString(fmt.Sprintf("hash:		%x \n", hash))
 		if verified {
 			str.WriteString(fmt.Sprintf("verified:	%t \n", verified))
@@ -47,6 +53,22 @@ func PrintKV(key []byte, value []byte, tx uint64, verified, valueOnly bool) stri
 	return str.String()
 }
 
+// printKV decodes the value with the codec set for the collection of the key, if any
+func (i *immuc) printKV(key []byte, value []byte, tx uint64, verified, valueOnly bool) string {
+	displayed := value
+
+	if i.options != nil {
+		codec, err := i.options.CodecFor(key)
+		if err == nil && codec != nil {
+			if v, err := client.DisplayValue(codec, value); err == nil {
+				displayed = v
+			}
+		}
+	}
+
+	return printKV(key, value, displayed, tx, verified, valueOnly)
+}
+
 // PrintSetItem ...
 func PrintSetItem(set []byte, referencedkey []byte, score float64, txMetadata *schema.TxMetadata, verified bool) string {
 	return fmt.Sprintf("tx:		%d\nset:		%s\nreferenced key:		%s\nscore:		%f\nhash:		%x\nverified:	%t\n",
@@ -0,0 +1,41 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package immuc
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/client"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintKVWithCollectionCodec(t *testing.T) {
+	value, err := client.MsgpackCodec.Marshal(map[string]interface{}{"status": "open"})
+	require.NoError(t, err)
+
+	ic := &immuc{options: client.DefaultOptions().WithCollectionCodec("orders:", "msgpack")}
+
+	str := ic.printKV([]byte("orders:1"), value, 1, false, false)
+	require.Contains(t, str, `{"status":"open"}`)
+	// the hash is calculated over the stored value
+	require.Contains(t, str, fmt.Sprintf("hash:\t\t%x", (&store.KV{Key: []byte("orders:1"), Value: value}).Digest()))
+
+	str = ic.printKV([]byte("users:1"), []byte("raw"), 1, false, true)
+	require.Equal(t, "raw\n", str)
+}
@@ -68,7 +68,7 @@ func (i *immuc) SetReference(args []string) (string, error) {
 	}
 
 	txMeta := response.(*schema.TxMetadata)
-	return PrintKV([]byte(args[0]), value, uint64(txMeta.Id), false, false), nil
+	return i.printKV([]byte(args[0]), value, uint64(txMeta.Id), false, false), nil
 }
 
 func (i *immuc) VerifiedSetReference(args []string) (string, error) {
@@ -110,5 +110,5 @@ func (i *immuc) VerifiedSetReference(args []string) (string, error) {
 	}
 
 	txMeta := response.(*schema.TxMetadata)
-	return PrintKV([]byte(args[0]), value, uint64(txMeta.Id), true, false), nil
+	return i.printKV([]byte(args[0]), value, uint64(txMeta.Id), true, false), nil
 }
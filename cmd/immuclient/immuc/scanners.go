@@ -51,7 +51,7 @@ func (i *immuc) ZScan(args []string) (string, error) {
 	}
 
 	for _, entry := range zEntries.Entries {
-		str.WriteString(PrintKV(entry.Entry.Key, entry.Entry.Value, entry.Entry.Tx, false, i.valueOnly))
+		str.WriteString(i.printKV(entry.Entry.Key, entry.Entry.Value, entry.Entry.Tx, false, i.valueOnly))
 		str.WriteString("\n")
 	}
 
@@ -83,7 +83,7 @@ func (i *immuc) Scan(args []string) (res string, err error) {
 	}
 
 	for _, entry := range entries.Entries {
-		str.WriteString(PrintKV(entry.Key, entry.Value, entry.Tx, false, i.valueOnly))
+		str.WriteString(i.printKV(entry.Key, entry.Value, entry.Tx, false, i.valueOnly))
 		str.WriteString("\n")
 	}
 
@@ -76,7 +76,7 @@ func (i *immuc) Set(args []string) (string, error) {
 		return "", err
 	}
 
-	return PrintKV([]byte(args[0]), value2, scstr.(*schema.Entry).Tx, false, false), nil
+	return i.printKV([]byte(args[0]), value2, scstr.(*schema.Entry).Tx, false, false), nil
 }
 
 func (i *immuc) VerifiedSet(args []string) (string, error) {
@@ -122,7 +122,7 @@ func (i *immuc) VerifiedSet(args []string) (string, error) {
 		return "", err
 	}
 
-	return PrintKV([]byte(args[0]), value2, vi.(*schema.Entry).Tx, true, false), nil
+	return i.printKV([]byte(args[0]), value2, vi.(*schema.Entry).Tx, true, false), nil
 }
 
 func (i *immuc) ZAdd(args []string) (string, error) {
@@ -0,0 +1,105 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package immuc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/codenotary/immudb/pkg/client"
+)
+
+// VerifyDB audits the whole current database, reporting progress to out. When a checkpoint file is given,
+// the verification resumes from it and the file is updated after every batch of verified transactions
+func (i *immuc) VerifyDB(args []string, out io.Writer) (string, error) {
+	var checkpoint string
+	if len(args) > 0 {
+		checkpoint = args[0]
+	}
+
+	from, err := loadDBVerification(checkpoint)
+	if err != nil {
+		return "", err
+	}
+
+	if from != nil {
+		fmt.Fprintf(out, "resuming verification from tx %d\n", from.TxID)
+	}
+
+	ctx := context.Background()
+
+	response, err := i.Execute(func(immuClient client.ImmuClient) (interface{}, error) {
+		return immuClient.VerifyDB(ctx, from, client.DefaultVerifyDBBatchSize, func(v *client.DBVerification, lastTx uint64) error {
+			fmt.Fprintf(out, "verified %d/%d transactions\n", v.TxID, lastTx)
+			return saveDBVerification(checkpoint, v)
+		})
+	})
+	if err != nil {
+		return "", err
+	}
+
+	v := response.(*client.DBVerification)
+
+	return fmt.Sprintf("database:\t%s\ntxID:\t\t%d\nhash:\t\t%x\nverified:\ttrue", v.Db, v.TxID, v.Alh), nil
+}
+
+func loadDBVerification(checkpoint string) (*client.DBVerification, error) {
+	if checkpoint == "" {
+		return nil, nil
+	}
+
+	b, err := ioutil.ReadFile(checkpoint)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var v client.DBVerification
+
+	err = json.Unmarshal(b, &v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid checkpoint file %s: %v", checkpoint, err)
+	}
+
+	return &v, nil
+}
+
+func saveDBVerification(checkpoint string, v *client.DBVerification) error {
+	if checkpoint == "" {
+		return nil
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	// written aside and renamed so an interrupted write never corrupts the checkpoint
+	tmp := checkpoint + ".tmp"
+
+	err = ioutil.WriteFile(tmp, b, 0600)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, checkpoint)
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package immuc_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	test "github.com/codenotary/immudb/cmd/immuclient/immuclienttest"
+	"github.com/codenotary/immudb/pkg/client"
+	"github.com/codenotary/immudb/pkg/server"
+	"github.com/codenotary/immudb/pkg/server/servertest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyDB(t *testing.T) {
+	options := server.DefaultOptions().WithAuth(true)
+	bs := servertest.NewBufconnServer(options)
+
+	bs.Start()
+	defer bs.Stop()
+
+	defer os.RemoveAll(options.Dir)
+	defer os.Remove(".state-")
+
+	ts := client.NewTokenService().WithTokenFileName("testTokenFile").WithHds(&test.HomedirServiceMock{})
+	ic := test.NewClientTest(&test.PasswordReader{
+		Pass: []string{"immudb"},
+	}, ts).WithOptions(client.DefaultOptions())
+	ic.
+		Connect(bs.Dialer)
+	ic.Login("immudb")
+
+	_, err := ic.Imc.Set([]string{"key", "value"})
+	require.NoError(t, err)
+
+	checkpoint, err := ioutil.TempFile("", "verifydb")
+	require.NoError(t, err)
+	checkpoint.Close()
+	os.Remove(checkpoint.Name())
+	defer os.Remove(checkpoint.Name())
+
+	var out bytes.Buffer
+
+	msg, err := ic.Imc.VerifyDB([]string{checkpoint.Name()}, &out)
+	require.NoError(t, err)
+	require.Contains(t, msg, "verified:\ttrue")
+	require.Contains(t, out.String(), "verified 1/1 transactions")
+	require.FileExists(t, checkpoint.Name())
+
+	_, err = ic.Imc.Set([]string{"key", "value2"})
+	require.NoError(t, err)
+
+	out.Reset()
+
+	msg, err = ic.Imc.VerifyDB([]string{checkpoint.Name()}, &out)
+	require.NoError(t, err)
+	require.Contains(t, msg, "txID:\t\t2")
+	require.True(t, strings.HasPrefix(out.String(), "resuming verification from tx 1\n"))
+
+	err = ioutil.WriteFile(checkpoint.Name(), []byte("{"), 0600)
+	require.NoError(t, err)
+
+	_, err = ic.Imc.VerifyDB([]string{checkpoint.Name()}, &out)
+	require.Error(t, err)
+}
@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -66,6 +67,65 @@ func TestImmudbCommandFlagParser(t *testing.T) {
 	assert.Equal(t, o.Logfile, options.Logfile)
 }
 
+func TestImmudbCommandScheduledJobs(t *testing.T) {
+	var options *server.Options
+	cmd := &cobra.Command{
+		Use: "immudb",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			options, err = parseOptions()
+			return err
+		},
+	}
+	cl := Commandline{}
+	cl.setupFlags(cmd, server.DefaultOptions())
+
+	err := viper.BindPFlags(cmd.Flags())
+	require.NoError(t, err)
+
+	setupDefaults(server.DefaultOptions())
+
+	_, err = executeCommand(cmd, "--scheduled-job=nightly;compaction;0,30 3 * * *", "--scheduled-job=check;audit;@daily;defaultdb")
+	require.NoError(t, err)
+	require.Len(t, options.JobSchedules, 2)
+	require.Equal(t, "nightly", options.JobSchedules[0].Name)
+	require.Equal(t, "0,30 3 * * *", options.JobSchedules[0].Cron)
+	require.Empty(t, options.JobSchedules[0].Database)
+	require.Equal(t, "audit", options.JobSchedules[1].Kind)
+	require.Equal(t, "defaultdb", options.JobSchedules[1].Database)
+
+	_, err = parseJobSchedule("nightly;compaction")
+	require.Error(t, err)
+}
+
+func TestImmudbCommandAlerts(t *testing.T) {
+	var options *server.Options
+	cmd := &cobra.Command{
+		Use: "immudb",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			options, err = parseOptions()
+			return err
+		},
+	}
+	cl := Commandline{}
+	cl.setupFlags(cmd, server.DefaultOptions())
+
+	err := viper.BindPFlags(cmd.Flags())
+	require.NoError(t, err)
+
+	setupDefaults(server.DefaultOptions())
+
+	_, err = executeCommand(cmd)
+	require.NoError(t, err)
+	require.Nil(t, options.AlertOptions)
+
+	_, err = executeCommand(cmd, "--alert-smtp-address=localhost:25", "--alert-email-from=immudb@localhost",
+		"--alert-email-to=ops@localhost", "--alert-email-to=dev@localhost", "--alert-max-commit-latency=1s")
+	require.NoError(t, err)
+	require.NotNil(t, options.AlertOptions)
+	require.Equal(t, []string{"ops@localhost", "dev@localhost"}, options.AlertOptions.EmailTo)
+	require.Equal(t, time.Second, options.AlertOptions.MaxCommitLatency)
+}
+
 //Priority:
 // 1. overrides
 // 2. flags
@@ -18,6 +18,8 @@ package immudb
 
 import (
 	c "github.com/codenotary/immudb/cmd/helper"
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/replication"
 	"github.com/codenotary/immudb/pkg/server"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -48,6 +50,61 @@ func (cl *Commandline) setupFlags(cmd *cobra.Command, options *server.Options) {
 	cmd.Flags().Int("web-server-port", options.WebServerPort, "web/console server port")
 	cmd.Flags().Bool("pgsql-server", true, "enable or disable pgsql server")
 	cmd.Flags().Int("pgsql-server-port", 5432, "pgsql server port")
+	cmd.Flags().Bool("grpc-reflection", options.GRPCReflection, "enable or disable the gRPC reflection service exposing the protobuf descriptors of the API")
+	cmd.Flags().String("receipt-verification-url", "", "base of the links notarization receipts are verified at, also encoded as QR codes in PDF receipts (receipts have no link when empty)")
+	cmd.Flags().Bool("public-verification", options.PublicVerification, "enable the unauthenticated web endpoint ("+server.VerificationPath+") proving the inclusion of entries, e.g. of notarization receipts")
+	cmd.Flags().Int("public-verification-rate-limit", options.PublicVerificationRateLimit, "max requests per second each client can send to the public verification endpoint (0 means unlimited)")
+	cmd.Flags().Int("stream-bandwidth", options.StreamBandwidth, "max bytes per second sent to replicas, and on every outgoing stream when stream-bandwidth-scope is all (0 means unlimited)")
+	cmd.Flags().Bool("attribution", options.Attribution, "record the user performing each write as part of the transaction")
+	cmd.Flags().String("stream-throttling", "", "comma separated daily windows in which stream bandwidth is limited. E.g. \"08:00-18:00\" (default is always)")
+	cmd.Flags().String("stream-bandwidth-scope", options.StreamBandwidthScope, "traffic limited by stream-bandwidth: replication (transactions exported to replicas) or all (replication and every outgoing stream)")
+	cmd.Flags().String("archive-dir", "", "location (e.g. a different mount) where value-log segments are archived into")
+	cmd.Flags().Int("archive-after-days", 0, "archive value-log segments not modified during the given number of days (0 means never)")
+	cmd.Flags().Bool("paranoid-reads", false, "verify every value read through the index against the entry stored in its transaction (slower scans)")
+	cmd.Flags().Bool("index-warm-up", false, "pre-load the index nodes accessed before the last shutdown when databases are opened")
+	cmd.Flags().Int("readahead-window", store.DefaultReadaheadWindow, "bytes of the value-log read ahead by sequential scans (0 disables readahead)")
+	cmd.Flags().Int("key-filter-capacity", 0, "number of keys the existence filter of each database is sized for, lookups of missing keys skip the index (0 disables the filter)")
+	cmd.Flags().Int("prefix-stats-depth", 0, "number of key segments used to group per-prefix operation statistics (0 disables them)")
+	cmd.Flags().String("prefix-stats-separator", string(options.PrefixStatsSeparator), "character delimiting key segments for per-prefix operation statistics")
+	cmd.Flags().Duration("sql-tx-timeout", options.SQLTxTimeout, "time an interactive SQL transaction can stay idle before being aborted (0 disables the timeout)")
+	cmd.Flags().Duration("iterator-lease-timeout", options.IteratorLeaseTimeout, "time an iterator lease is kept without receiving any request (0 disables the timeout)")
+	cmd.Flags().Duration("idempotency-window", options.IdempotencyWindow, "time a write is remembered by its idempotency key (0 disables deduplication)")
+	cmd.Flags().Int("idempotency-max-keys", options.IdempotencyMaxKeys, "maximum number of idempotency keys remembered by each database")
+	cmd.Flags().Bool("idempotency-persisted", false, "store idempotency keys along with their transactions, so retries are deduplicated across restarts")
+	cmd.Flags().Int("sql-cache-size", options.SQLCacheSize, "maximum number of SQL query results cached by each database (0 disables caching)")
+	cmd.Flags().Duration("keepalive-time", options.KeepaliveTime, "time without activity after which the server pings a client to check the connection is alive")
+	cmd.Flags().Duration("keepalive-timeout", options.KeepaliveTimeout, "time the server waits for the response to a keepalive ping before closing the connection")
+	cmd.Flags().Duration("keepalive-min-time", options.KeepaliveMinTime, "minimum interval between client keepalive pings, connections of clients pinging more often are closed")
+	cmd.Flags().Bool("keepalive-permit-without-stream", false, "allow clients to send keepalive pings when there are no active streams")
+	cmd.Flags().Duration("max-connection-idle", 0, "time after which idle connections are closed (0 keeps them open)")
+	cmd.Flags().Duration("max-connection-age", 0, "maximum age of a connection before clients are asked to reconnect (0 disables the limit)")
+	cmd.Flags().Duration("max-connection-age-grace", 0, "time pending calls are given to complete once a connection reached its maximum age (0 waits indefinitely)")
+	cmd.Flags().Uint32("max-concurrent-streams", 0, "maximum number of concurrent streams of each connection (0 means unlimited)")
+	cmd.Flags().Int("max-concurrent-ops", 0, "maximum number of calls served concurrently, further calls are queued with interactive ones ahead of batch ones (0 means unlimited)")
+	cmd.Flags().Duration("batch-op-timeout", options.BatchOpTimeout, "timeout of batch calls arriving once the maximum number of concurrent calls is reached (0 disables the timeout)")
+	cmd.Flags().StringArray("scheduled-job", nil, "job run by the server on a schedule, as 'name;kind;cron[;database]' where kind is compaction or audit (can be repeated)")
+	cmd.Flags().Bool("replication-enabled", false, "set the default database as a read-only replica of a database in the master server")
+	cmd.Flags().String("replication-master-address", "", "master server address")
+	cmd.Flags().Int("replication-master-port", replication.DefaultMasterPort, "master server port")
+	cmd.Flags().String("replication-master-database", replication.DefaultOptions().MasterDatabase, "database to be replicated from the master server")
+	cmd.Flags().String("replication-follower-username", "", "username used by the replica to authenticate against the master server")
+	cmd.Flags().String("replication-follower-password", "", "password used by the replica to authenticate against the master server")
+	cmd.Flags().Duration("alert-check-interval", server.DefaultAlertCheckInterval, "time between consecutive alert threshold checks")
+	cmd.Flags().Uint64("alert-max-replication-lag", 0, "number of transactions the replica can fall behind the master before an alert is fired (0 disables the check)")
+	cmd.Flags().Int64("alert-max-disk-usage", 0, "bytes the data directory can take before an alert is fired (0 disables the check)")
+	cmd.Flags().Uint64("alert-max-audit-failures", 0, "number of failed scheduled audits which fires an alert (0 disables the check)")
+	cmd.Flags().Duration("alert-max-commit-latency", 0, "time a database can take to commit a transaction before an alert is fired (0 disables the check)")
+	cmd.Flags().String("alert-webhook-url", "", "URL alerts are posted to as JSON")
+	cmd.Flags().String("alert-smtp-address", "", "address (host:port) of the mail server used to send alerts by email")
+	cmd.Flags().String("alert-smtp-username", "", "username used to authenticate against the mail server")
+	cmd.Flags().String("alert-smtp-password", "", "password used to authenticate against the mail server")
+	cmd.Flags().String("alert-email-from", "", "sender of alert emails")
+	cmd.Flags().StringArray("alert-email-to", nil, "recipient of alert emails (can be repeated)")
+	cmd.Flags().Bool("syslog", false, "enable the listener writing syslog (RFC 5424) and CEF messages to a database")
+	cmd.Flags().String("syslog-network", "udp", "network of the syslog listener, either udp or tcp")
+	cmd.Flags().String("syslog-address", server.DefaultSyslogAddress, "address the syslog listener is bound to")
+	cmd.Flags().String("syslog-database", server.DefaultdbName, "database syslog messages are written to")
+	cmd.Flags().String("syslog-key-prefix", server.DefaultSyslogKeyPrefix, "prefix of the keys and sorted sets syslog messages are written under")
 }
 
 func setupDefaults(options *server.Options) {
@@ -73,4 +130,59 @@ func setupDefaults(options *server.Options) {
 	viper.SetDefault("web-server-port", options.WebServerPort)
 	viper.SetDefault("pgsql-server", true)
 	viper.SetDefault("pgsql-server-port", 5432)
+	viper.SetDefault("grpc-reflection", options.GRPCReflection)
+	viper.SetDefault("receipt-verification-url", "")
+	viper.SetDefault("public-verification", options.PublicVerification)
+	viper.SetDefault("public-verification-rate-limit", options.PublicVerificationRateLimit)
+	viper.SetDefault("stream-bandwidth", options.StreamBandwidth)
+	viper.SetDefault("stream-throttling", "")
+	viper.SetDefault("stream-bandwidth-scope", options.StreamBandwidthScope)
+	viper.SetDefault("attribution", options.Attribution)
+	viper.SetDefault("archive-dir", "")
+	viper.SetDefault("archive-after-days", 0)
+	viper.SetDefault("paranoid-reads", false)
+	viper.SetDefault("index-warm-up", false)
+	viper.SetDefault("readahead-window", store.DefaultReadaheadWindow)
+	viper.SetDefault("key-filter-capacity", 0)
+	viper.SetDefault("prefix-stats-depth", 0)
+	viper.SetDefault("prefix-stats-separator", string(options.PrefixStatsSeparator))
+	viper.SetDefault("sql-tx-timeout", options.SQLTxTimeout)
+	viper.SetDefault("iterator-lease-timeout", options.IteratorLeaseTimeout)
+	viper.SetDefault("idempotency-window", options.IdempotencyWindow)
+	viper.SetDefault("idempotency-max-keys", options.IdempotencyMaxKeys)
+	viper.SetDefault("idempotency-persisted", false)
+	viper.SetDefault("sql-cache-size", options.SQLCacheSize)
+	viper.SetDefault("keepalive-time", options.KeepaliveTime)
+	viper.SetDefault("keepalive-timeout", options.KeepaliveTimeout)
+	viper.SetDefault("keepalive-min-time", options.KeepaliveMinTime)
+	viper.SetDefault("keepalive-permit-without-stream", false)
+	viper.SetDefault("max-connection-idle", 0)
+	viper.SetDefault("max-connection-age", 0)
+	viper.SetDefault("max-connection-age-grace", 0)
+	viper.SetDefault("max-concurrent-streams", 0)
+	viper.SetDefault("max-concurrent-ops", 0)
+	viper.SetDefault("batch-op-timeout", options.BatchOpTimeout)
+	viper.SetDefault("scheduled-job", []string{})
+	viper.SetDefault("replication-enabled", false)
+	viper.SetDefault("replication-master-address", "")
+	viper.SetDefault("replication-master-port", replication.DefaultMasterPort)
+	viper.SetDefault("replication-master-database", replication.DefaultOptions().MasterDatabase)
+	viper.SetDefault("replication-follower-username", "")
+	viper.SetDefault("replication-follower-password", "")
+	viper.SetDefault("alert-check-interval", server.DefaultAlertCheckInterval)
+	viper.SetDefault("alert-max-replication-lag", 0)
+	viper.SetDefault("alert-max-disk-usage", 0)
+	viper.SetDefault("alert-max-audit-failures", 0)
+	viper.SetDefault("alert-max-commit-latency", 0)
+	viper.SetDefault("alert-webhook-url", "")
+	viper.SetDefault("alert-smtp-address", "")
+	viper.SetDefault("alert-smtp-username", "")
+	viper.SetDefault("alert-smtp-password", "")
+	viper.SetDefault("alert-email-from", "")
+	viper.SetDefault("alert-email-to", []string{})
+	viper.SetDefault("syslog", false)
+	viper.SetDefault("syslog-network", "udp")
+	viper.SetDefault("syslog-address", server.DefaultSyslogAddress)
+	viper.SetDefault("syslog-database", server.DefaultdbName)
+	viper.SetDefault("syslog-key-prefix", server.DefaultSyslogKeyPrefix)
 }
@@ -17,7 +17,15 @@ limitations under the License.
 package immudb
 
 import (
+	"encoding/csv"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/replication"
 	"github.com/codenotary/immudb/pkg/server"
+	"github.com/codenotary/immudb/pkg/stream"
 	"github.com/spf13/viper"
 )
 
@@ -43,6 +51,12 @@ func parseOptions() (options *server.Options, err error) {
 	maintenance := viper.GetBool("maintenance")
 	signingKey := viper.GetString("signingKey")
 	synced := viper.GetBool("synced")
+	archiveDir := viper.GetString("archive-dir")
+	archiveAfterDays := viper.GetInt("archive-after-days")
+	paranoidReads := viper.GetBool("paranoid-reads")
+	readaheadWindow := viper.GetInt("readahead-window")
+	keyFilterCapacity := viper.GetInt("key-filter-capacity")
+	indexWarmUp := viper.GetBool("index-warm-up")
 	tokenExpTime := viper.GetInt("token-expiry-time")
 
 	webServer := viper.GetBool("web-server")
@@ -51,7 +65,127 @@ func parseOptions() (options *server.Options, err error) {
 	pgsqlServer := viper.GetBool("pgsql-server")
 	pgsqlServerPort := viper.GetInt("pgsql-server-port")
 
-	storeOpts := server.DefaultStoreOptions().WithSynced(synced)
+	grpcReflection := viper.GetBool("grpc-reflection")
+
+	receiptVerificationURL := viper.GetString("receipt-verification-url")
+
+	publicVerification := viper.GetBool("public-verification")
+	publicVerificationRateLimit := viper.GetInt("public-verification-rate-limit")
+
+	attribution := viper.GetBool("attribution")
+
+	prefixStatsDepth := viper.GetInt("prefix-stats-depth")
+	prefixStatsSeparator := viper.GetString("prefix-stats-separator")
+	if len(prefixStatsSeparator) != 1 {
+		return options, server.ErrIllegalArguments
+	}
+
+	sqlTxTimeout := viper.GetDuration("sql-tx-timeout")
+	iteratorLeaseTimeout := viper.GetDuration("iterator-lease-timeout")
+
+	idempotencyWindow := viper.GetDuration("idempotency-window")
+	idempotencyMaxKeys := viper.GetInt("idempotency-max-keys")
+	idempotencyPersisted := viper.GetBool("idempotency-persisted")
+	sqlCacheSize := viper.GetInt("sql-cache-size")
+
+	keepaliveTime := viper.GetDuration("keepalive-time")
+	keepaliveTimeout := viper.GetDuration("keepalive-timeout")
+	keepaliveMinTime := viper.GetDuration("keepalive-min-time")
+	keepalivePermitWithoutStream := viper.GetBool("keepalive-permit-without-stream")
+	maxConnectionIdle := viper.GetDuration("max-connection-idle")
+	maxConnectionAge := viper.GetDuration("max-connection-age")
+	maxConnectionAgeGrace := viper.GetDuration("max-connection-age-grace")
+	maxConcurrentStreams := viper.GetUint32("max-concurrent-streams")
+	maxConcurrentOps := viper.GetInt("max-concurrent-ops")
+	batchOpTimeout := viper.GetDuration("batch-op-timeout")
+
+	scheduledJobs, err := getStringArray("scheduled-job")
+	if err != nil {
+		return options, err
+	}
+
+	var jobSchedules []*schema.JobSchedule
+
+	for _, job := range scheduledJobs {
+		js, err := parseJobSchedule(job)
+		if err != nil {
+			return options, err
+		}
+		jobSchedules = append(jobSchedules, js)
+	}
+
+	var replicationOpts *replication.Options
+
+	if viper.GetBool("replication-enabled") {
+		replicationOpts = replication.DefaultOptions().
+			WithMasterAddress(viper.GetString("replication-master-address")).
+			WithMasterPort(viper.GetInt("replication-master-port")).
+			WithMasterDatabase(viper.GetString("replication-master-database")).
+			WithFollowerUsername(viper.GetString("replication-follower-username")).
+			WithFollowerPassword(viper.GetString("replication-follower-password"))
+
+		if !replicationOpts.Valid() {
+			return options, replication.ErrIllegalArguments
+		}
+	}
+
+	var alertOpts *server.AlertOptions
+
+	alertWebhookURL := viper.GetString("alert-webhook-url")
+	alertSMTPAddress := viper.GetString("alert-smtp-address")
+
+	if alertWebhookURL != "" || alertSMTPAddress != "" {
+		alertEmailTo, err := getStringArray("alert-email-to")
+		if err != nil {
+			return options, err
+		}
+
+		alertOpts = server.DefaultAlertOptions().
+			WithCheckInterval(viper.GetDuration("alert-check-interval")).
+			WithMaxReplicationLag(viper.GetUint64("alert-max-replication-lag")).
+			WithMaxDiskUsage(viper.GetInt64("alert-max-disk-usage")).
+			WithMaxAuditFailures(viper.GetUint64("alert-max-audit-failures")).
+			WithMaxCommitLatency(viper.GetDuration("alert-max-commit-latency")).
+			WithWebhookURL(alertWebhookURL).
+			WithSMTPAddress(alertSMTPAddress).
+			WithSMTPCredentials(viper.GetString("alert-smtp-username"), viper.GetString("alert-smtp-password")).
+			WithEmail(viper.GetString("alert-email-from"), alertEmailTo)
+
+		if !alertOpts.Valid() {
+			return options, server.ErrIllegalArguments
+		}
+	}
+
+	var syslogOpts *server.SyslogOptions
+
+	if viper.GetBool("syslog") {
+		syslogOpts = server.DefaultSyslogOptions().
+			WithNetwork(viper.GetString("syslog-network")).
+			WithAddress(viper.GetString("syslog-address")).
+			WithDatabase(viper.GetString("syslog-database")).
+			WithKeyPrefix(viper.GetString("syslog-key-prefix"))
+
+		if !syslogOpts.Valid() {
+			return options, server.ErrIllegalArguments
+		}
+	}
+
+	streamBandwidth := viper.GetInt("stream-bandwidth")
+	streamBandwidthScope := viper.GetString("stream-bandwidth-scope")
+	streamThrottling, err := stream.ParseThrottleWindows(viper.GetString("stream-throttling"))
+	if err != nil {
+		return options, err
+	}
+
+	storeOpts := server.DefaultStoreOptions().
+		WithSynced(synced).
+		WithArchivePath(archiveDir).
+		WithArchiveAfter(time.Duration(archiveAfterDays) * 24 * time.Hour).
+		WithParanoidReads(paranoidReads).
+		WithReadaheadWindow(readaheadWindow).
+		WithKeyFilterCapacity(keyFilterCapacity)
+
+	storeOpts.IndexOpts.WithWarmUp(indexWarmUp)
 
 	tlsConfig, err := setUpTLS(pkey, certificate, clientcas, mtls)
 	if err != nil {
@@ -79,7 +213,73 @@ func parseOptions() (options *server.Options, err error) {
 		WithWebServer(webServer).
 		WithWebServerPort(webServerPort).
 		WithPgsqlServer(pgsqlServer).
-		WithPgsqlServerPort(pgsqlServerPort)
+		WithPgsqlServerPort(pgsqlServerPort).
+		WithGRPCReflection(grpcReflection).
+		WithReceiptVerificationURL(receiptVerificationURL).
+		WithPublicVerification(publicVerification).
+		WithPublicVerificationRateLimit(publicVerificationRateLimit).
+		WithStreamBandwidth(streamBandwidth).
+		WithStreamThrottling(streamThrottling).
+		WithStreamBandwidthScope(streamBandwidthScope).
+		WithAttribution(attribution).
+		WithPrefixStatsDepth(prefixStatsDepth).
+		WithPrefixStatsSeparator(prefixStatsSeparator[0]).
+		WithSQLTxTimeout(sqlTxTimeout).
+		WithIteratorLeaseTimeout(iteratorLeaseTimeout).
+		WithIdempotencyWindow(idempotencyWindow).
+		WithIdempotencyMaxKeys(idempotencyMaxKeys).
+		WithIdempotencyPersisted(idempotencyPersisted).
+		WithSQLCacheSize(sqlCacheSize).
+		WithKeepaliveTime(keepaliveTime).
+		WithKeepaliveTimeout(keepaliveTimeout).
+		WithKeepaliveMinTime(keepaliveMinTime).
+		WithKeepalivePermitWithoutStream(keepalivePermitWithoutStream).
+		WithMaxConnectionIdle(maxConnectionIdle).
+		WithMaxConnectionAge(maxConnectionAge).
+		WithMaxConnectionAgeGrace(maxConnectionAgeGrace).
+		WithMaxConcurrentStreams(maxConcurrentStreams).
+		WithMaxConcurrentOps(maxConcurrentOps).
+		WithBatchOpTimeout(batchOpTimeout).
+		WithJobSchedules(jobSchedules).
+		WithReplicationOptions(replicationOpts).
+		WithAlertOptions(alertOpts).
+		WithSyslogOptions(syslogOpts)
 
 	return options, nil
 }
+
+// parseJobSchedule parses a job schedule given as 'name;kind;cron[;database]'
+func parseJobSchedule(s string) (*schema.JobSchedule, error) {
+	fields := strings.Split(s, ";")
+	if len(fields) < 3 || len(fields) > 4 {
+		return nil, fmt.Errorf("invalid scheduled job '%s', expected 'name;kind;cron[;database]'", s)
+	}
+
+	js := &schema.JobSchedule{
+		Name: strings.TrimSpace(fields[0]),
+		Kind: strings.TrimSpace(fields[1]),
+		Cron: strings.TrimSpace(fields[2]),
+	}
+
+	if len(fields) == 4 {
+		js.Database = strings.TrimSpace(fields[3])
+	}
+
+	return js, nil
+}
+
+// getStringArray returns the values of a repeatable flag. Values may contain spaces and commas, so the
+// string form viper returns for array flags is parsed as CSV instead of being split on whitespace
+func getStringArray(key string) ([]string, error) {
+	s, ok := viper.Get(key).(string)
+	if !ok {
+		return viper.GetStringSlice(key), nil
+	}
+
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+	if s == "" {
+		return nil, nil
+	}
+
+	return csv.NewReader(strings.NewReader(s)).Read()
+}
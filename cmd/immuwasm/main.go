@@ -0,0 +1,116 @@
+//go:build js && wasm
+// +build js,wasm
+
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"syscall/js"
+
+	"github.com/codenotary/immudb/pkg/verification/rest"
+)
+
+// main registers immudbClient(url) in the global scope. The returned object exposes login(user, password),
+// useDatabase(db), verifiedGet(key) and state(), all but the last one returning promises
+func main() {
+	js.Global().Set("immudbClient", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		if len(args) != 1 {
+			return js.Global().Get("Error").New("immudbClient(url) expects one argument")
+		}
+		return newClient(rest.NewClient(args[0].String()))
+	}))
+
+	select {}
+}
+
+func newClient(c *rest.Client) js.Value {
+	obj := js.Global().Get("Object").New()
+
+	obj.Set("login", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		user, password := []byte(arg(args, 0)), []byte(arg(args, 1))
+
+		return promise(func() (interface{}, error) {
+			return nil, c.Login(context.Background(), user, password)
+		})
+	}))
+
+	obj.Set("useDatabase", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		db := arg(args, 0)
+
+		return promise(func() (interface{}, error) {
+			return nil, c.UseDatabase(context.Background(), db)
+		})
+	}))
+
+	obj.Set("verifiedGet", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		key := []byte(arg(args, 0))
+
+		return promise(func() (interface{}, error) {
+			e, err := c.VerifiedGet(context.Background(), key)
+			if err != nil {
+				return nil, err
+			}
+
+			return map[string]interface{}{
+				"tx":    float64(e.Tx),
+				"key":   string(e.Key),
+				"value": string(e.Value),
+			}, nil
+		})
+	}))
+
+	obj.Set("state", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		s := c.State()
+
+		return map[string]interface{}{
+			"txId":   float64(s.TxID),
+			"txHash": hex.EncodeToString(s.TxHash[:]),
+		}
+	}))
+
+	return obj
+}
+
+func arg(args []js.Value, i int) string {
+	if i >= len(args) {
+		return ""
+	}
+	return args[i].String()
+}
+
+// promise runs fn in a goroutine, as blocking calls (e.g. http requests) can't be made from the js event loop
+func promise(fn func() (interface{}, error)) js.Value {
+	handler := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		resolve, reject := args[0], args[1]
+
+		go func() {
+			res, err := fn()
+			if err != nil {
+				reject.Invoke(js.Global().Get("Error").New(err.Error()))
+				return
+			}
+			resolve.Invoke(res)
+		}()
+
+		return nil
+	})
+
+	return js.Global().Get("Promise").New(handler)
+}
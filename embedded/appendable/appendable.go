@@ -41,6 +41,7 @@ type Appendable interface {
 	Offset() int64
 	SetOffset(off int64) error
 	Append(bs []byte) (off int64, n int, err error)
+	AppendUncompressed(bs []byte) (off int64, n int, err error)
 	Flush() error
 	Sync() error
 	ReadAt(bs []byte, off int64) (int, error)
@@ -0,0 +1,51 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package appendable
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrUnsupportedCompressionFormat = errors.New("unsupported compression format")
+
+var compressionFormatNames = map[int]string{
+	NoCompression:    "none",
+	FlateCompression: "flate",
+	GZipCompression:  "gzip",
+	LZWCompression:   "lzw",
+	ZLibCompression:  "zlib",
+}
+
+// CompressionFormatName returns the name of the compression format
+func CompressionFormatName(compressionFormat int) string {
+	return compressionFormatNames[compressionFormat]
+}
+
+// CompressionFormatByName returns the format with the given (case-insensitive) name, NoCompression if name is empty
+func CompressionFormatByName(name string) (int, error) {
+	if name == "" {
+		return NoCompression, nil
+	}
+
+	for f, n := range compressionFormatNames {
+		if strings.EqualFold(n, name) {
+			return f, nil
+		}
+	}
+
+	return 0, ErrUnsupportedCompressionFormat
+}
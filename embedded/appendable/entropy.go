@@ -0,0 +1,62 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package appendable
+
+import "math"
+
+// entropySampleSize is the maximum number of bytes inspected by IsIncompressible
+const entropySampleSize = 4096
+
+// entropyMinSize is the minimum size of values inspected by IsIncompressible, smaller values are always compressed
+const entropyMinSize = 256
+
+// IncompressibleEntropyThreshold is the Shannon entropy (in bits per byte) above which data is considered incompressible
+const IncompressibleEntropyThreshold = 7.5
+
+// IsIncompressible estimates the Shannon entropy of a sample of bs to detect
+// already compressed or encrypted data, for which a compression attempt is wasted
+func IsIncompressible(bs []byte) bool {
+	if len(bs) < entropyMinSize {
+		return false
+	}
+
+	var freqs [256]int
+
+	sample := len(bs)
+	step := 1
+
+	if sample > entropySampleSize {
+		step = sample / entropySampleSize
+		sample = entropySampleSize
+	}
+
+	for i := 0; i < sample; i++ {
+		freqs[bs[i*step]]++
+	}
+
+	var entropy float64
+
+	for _, f := range freqs {
+		if f == 0 {
+			continue
+		}
+
+		p := float64(f) / float64(sample)
+		entropy -= p * math.Log2(p)
+	}
+
+	return entropy > IncompressibleEntropyThreshold
+}
@@ -0,0 +1,38 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package appendable
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsIncompressible(t *testing.T) {
+	random := make([]byte, 64*1024)
+	_, err := rand.Read(random)
+	require.NoError(t, err)
+
+	require.True(t, IsIncompressible(random))
+	require.True(t, IsIncompressible(random[:1024]))
+	require.False(t, IsIncompressible(random[:entropyMinSize-1]))
+
+	require.False(t, IsIncompressible(nil))
+	require.False(t, IsIncompressible(bytes.Repeat([]byte("immudb"), 1000)))
+	require.False(t, IsIncompressible(bytes.Repeat([]byte{0}, 64*1024)))
+}
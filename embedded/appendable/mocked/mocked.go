@@ -16,16 +16,17 @@ limitations under the License.
 package mocked
 
 type MockedAppendable struct {
-	MetadataFn  func() []byte
-	SizeFn      func() (int64, error)
-	OffsetFn    func() int64
-	SetOffsetFn func(off int64) error
-	AppendFn    func(bs []byte) (off int64, n int, err error)
-	FlushFn     func() error
-	SyncFn      func() error
-	ReadAtFn    func(bs []byte, off int64) (int, error)
-	CopyFn      func(dstPath string) error
-	CloseFn     func() error
+	MetadataFn           func() []byte
+	SizeFn               func() (int64, error)
+	OffsetFn             func() int64
+	SetOffsetFn          func(off int64) error
+	AppendFn             func(bs []byte) (off int64, n int, err error)
+	AppendUncompressedFn func(bs []byte) (off int64, n int, err error)
+	FlushFn              func() error
+	SyncFn               func() error
+	ReadAtFn             func(bs []byte, off int64) (int, error)
+	CopyFn               func(dstPath string) error
+	CloseFn              func() error
 }
 
 func (a *MockedAppendable) Metadata() []byte {
@@ -52,6 +53,10 @@ func (a *MockedAppendable) Append(bs []byte) (off int64, n int, err error) {
 	return a.AppendFn(bs)
 }
 
+func (a *MockedAppendable) AppendUncompressed(bs []byte) (off int64, n int, err error) {
+	return a.AppendUncompressedFn(bs)
+}
+
 func (a *MockedAppendable) Flush() error {
 	return a.FlushFn()
 }
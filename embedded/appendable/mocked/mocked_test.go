@@ -48,6 +48,10 @@ func TestMocked(t *testing.T) {
 		return 0, 0, nil
 	}
 
+	mocked.AppendUncompressedFn = func(bs []byte) (off int64, n int, err error) {
+		return 0, 0, nil
+	}
+
 	mocked.FlushFn = func() error {
 		return nil
 	}
@@ -85,6 +89,11 @@ func TestMocked(t *testing.T) {
 	require.Equal(t, 0, n)
 	require.NoError(t, err)
 
+	off, n, err = mocked.AppendUncompressed(nil)
+	require.Equal(t, int64(0), off)
+	require.Equal(t, 0, n)
+	require.NoError(t, err)
+
 	err = mocked.Flush()
 	require.NoError(t, err)
 
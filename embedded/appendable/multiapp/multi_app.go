@@ -26,6 +26,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/codenotary/immudb/embedded/appendable"
 	"github.com/codenotary/immudb/embedded/appendable/singleapp"
@@ -36,6 +37,7 @@ var ErrorPathIsNotADirectory = errors.New("path is not a directory")
 var ErrIllegalArguments = errors.New("illegal arguments")
 var ErrAlreadyClosed = errors.New("multi-appendable already closed")
 var ErrReadOnly = errors.New("cannot append when openned in read-only mode")
+var ErrArchivingDisabled = errors.New("archiving is disabled")
 
 const (
 	metaFileSize    = "FILE_SIZE"
@@ -55,6 +57,8 @@ type MultiFileAppendable struct {
 	fileSize int
 	fileExt  string
 
+	archivePath string
+
 	closed bool
 
 	mutex sync.Mutex
@@ -133,6 +137,7 @@ func Open(path string, opts *Options) (*MultiFileAppendable, error) {
 		fileMode:    opts.fileMode,
 		fileSize:    fileSize,
 		fileExt:     opts.fileExt,
+		archivePath: opts.archivePath,
 		closed:      false,
 	}, nil
 }
@@ -180,6 +185,19 @@ func (mf *MultiFileAppendable) Copy(dstPath string) error {
 		}
 	}
 
+	// archived files are copied as well, so the copy is self-contained
+	archived, err := mf.archivedFiles()
+	if err != nil {
+		return err
+	}
+
+	for _, fd := range archived {
+		_, err = copyFile(path.Join(mf.archivePath, fd.Name()), path.Join(dstPath, fd.Name()))
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -199,6 +217,105 @@ func copyFile(srcPath, dstPath string) (int64, error) {
 	return io.Copy(dstFile, srcFile)
 }
 
+func (mf *MultiFileAppendable) archivedFiles() ([]os.FileInfo, error) {
+	if mf.archivePath == "" {
+		return nil, nil
+	}
+
+	fis, err := ioutil.ReadDir(mf.archivePath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	return fis, err
+}
+
+// Archive moves the files last modified before olderThan into the archive location.
+// The file currently being appended is never archived. Archived files remain readable, with a higher latency
+// as they are expected to reside on slower storage
+func (mf *MultiFileAppendable) Archive(olderThan time.Time) (archived int, err error) {
+	mf.mutex.Lock()
+	defer mf.mutex.Unlock()
+
+	if mf.closed {
+		return 0, ErrAlreadyClosed
+	}
+
+	if mf.archivePath == "" {
+		return 0, ErrArchivingDisabled
+	}
+
+	if mf.readOnly {
+		return 0, ErrReadOnly
+	}
+
+	fis, err := ioutil.ReadDir(mf.path)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, fd := range fis {
+		appID, err := strconv.ParseInt(strings.TrimSuffix(fd.Name(), filepath.Ext(fd.Name())), 10, 64)
+		if err != nil {
+			return archived, err
+		}
+
+		if appID >= mf.currAppID || !fd.ModTime().Before(olderThan) {
+			continue
+		}
+
+		app, err := mf.appendables.Pop(appID)
+		if err == nil {
+			err = app.(*singleapp.AppendableFile).Close()
+			if err != nil {
+				return archived, err
+			}
+		}
+
+		err = mf.archiveFile(fd.Name())
+		if err != nil {
+			return archived, err
+		}
+
+		archived++
+	}
+
+	return archived, nil
+}
+
+func (mf *MultiFileAppendable) archiveFile(name string) error {
+	err := os.MkdirAll(mf.archivePath, mf.fileMode)
+	if err != nil {
+		return err
+	}
+
+	srcPath := filepath.Join(mf.path, name)
+	dstPath := filepath.Join(mf.archivePath, name)
+
+	if os.Rename(srcPath, dstPath) == nil {
+		return nil
+	}
+
+	// archive location may be on a different device
+	_, err = copyFile(srcPath, dstPath)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(dstPath)
+	if err != nil {
+		return err
+	}
+
+	err = f.Sync()
+	f.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Remove(srcPath)
+}
+
 func (mf *MultiFileAppendable) CompressionFormat() int {
 	mf.mutex.Lock()
 	defer mf.mutex.Unlock()
@@ -237,6 +354,14 @@ func (mf *MultiFileAppendable) Size() (int64, error) {
 }
 
 func (mf *MultiFileAppendable) Append(bs []byte) (off int64, n int, err error) {
+	return mf.append(bs, false)
+}
+
+func (mf *MultiFileAppendable) AppendUncompressed(bs []byte) (off int64, n int, err error) {
+	return mf.append(bs, true)
+}
+
+func (mf *MultiFileAppendable) append(bs []byte, uncompressed bool) (off int64, n int, err error) {
 	mf.mutex.Lock()
 	defer mf.mutex.Unlock()
 
@@ -288,7 +413,13 @@ func (mf *MultiFileAppendable) Append(bs []byte) (off int64, n int, err error) {
 			d = len(bs) - n
 		}
 
-		offn, _, err := mf.currApp.Append(bs[n : n+d])
+		var offn int64
+
+		if uncompressed {
+			offn, _, err = mf.currApp.AppendUncompressed(bs[n : n+d])
+		} else {
+			offn, _, err = mf.currApp.Append(bs[n : n+d])
+		}
 		if err != nil {
 			return off, n, err
 		}
@@ -304,15 +435,29 @@ func (mf *MultiFileAppendable) Append(bs []byte) (off int64, n int, err error) {
 }
 
 func (mf *MultiFileAppendable) openAppendable(appname string) (*singleapp.AppendableFile, error) {
+	appPath := filepath.Join(mf.path, appname)
+	readOnly := mf.readOnly
+
+	if mf.archivePath != "" {
+		if _, err := os.Stat(appPath); os.IsNotExist(err) {
+			archivedPath := filepath.Join(mf.archivePath, appname)
+
+			if _, err := os.Stat(archivedPath); err == nil {
+				appPath = archivedPath
+				readOnly = true
+			}
+		}
+	}
+
 	appendableOpts := singleapp.DefaultOptions().
-		WithReadOnly(mf.readOnly).
+		WithReadOnly(readOnly).
 		WithSynced(mf.synced).
 		WithFileMode(mf.fileMode).
 		WithCompressionFormat(mf.currApp.CompressionFormat()).
 		WithCompresionLevel(mf.currApp.CompressionLevel()).
 		WithMetadata(mf.currApp.Metadata())
 
-	return singleapp.Open(filepath.Join(mf.path, appname), appendableOpts)
+	return singleapp.Open(appPath, appendableOpts)
 }
 
 func (mf *MultiFileAppendable) Offset() int64 {
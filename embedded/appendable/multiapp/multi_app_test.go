@@ -20,6 +20,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/codenotary/immudb/embedded/appendable"
 	"github.com/codenotary/immudb/embedded/appendable/singleapp"
@@ -283,6 +284,89 @@ func TestMultiAppCompression(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, []byte{1, 2, 3}, bs)
 
+	off, _, err = a.AppendUncompressed([]byte{4, 5, 6})
+	require.NoError(t, err)
+
+	err = a.Flush()
+	require.NoError(t, err)
+
+	_, err = a.ReadAt(bs, off)
+	require.NoError(t, err)
+	require.Equal(t, []byte{4, 5, 6}, bs)
+
+	err = a.Close()
+	require.NoError(t, err)
+}
+
+func TestMultiAppArchive(t *testing.T) {
+	defer os.RemoveAll("testdata_archive")
+
+	a, err := Open("testdata", DefaultOptions().WithFileSize(4))
+	defer os.RemoveAll("testdata")
+	require.NoError(t, err)
+
+	_, err = a.Archive(time.Now())
+	require.Equal(t, ErrArchivingDisabled, err)
+
+	err = a.Close()
+	require.NoError(t, err)
+
+	a, err = Open("testdata", DefaultOptions().WithFileSize(4).WithMaxOpenedFiles(1).WithArchivePath("testdata_archive"))
+	require.NoError(t, err)
+
+	_, _, err = a.Append([]byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9})
+	require.NoError(t, err)
+
+	err = a.Flush()
+	require.NoError(t, err)
+
+	b := make([]byte, 10)
+	_, err = a.ReadAt(b, 0)
+	require.NoError(t, err)
+
+	archived, err := a.Archive(time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.Zero(t, archived)
+
+	archived, err = a.Archive(time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 2, archived)
+
+	_, err = os.Stat(filepath.Join("testdata", "00000000.aof"))
+	require.True(t, os.IsNotExist(err))
+
+	_, err = os.Stat(filepath.Join("testdata_archive", "00000000.aof"))
+	require.NoError(t, err)
+
+	_, err = os.Stat(filepath.Join("testdata", "00000002.aof"))
+	require.NoError(t, err)
+
+	b = make([]byte, 10)
+	_, err = a.ReadAt(b, 0)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, b)
+
+	err = a.Copy("testdata_copy")
+	defer os.RemoveAll("testdata_copy")
+	require.NoError(t, err)
+
+	_, err = os.Stat(filepath.Join("testdata_copy", "00000000.aof"))
+	require.NoError(t, err)
+
+	err = a.Close()
+	require.NoError(t, err)
+
+	_, err = a.Archive(time.Now())
+	require.Equal(t, ErrAlreadyClosed, err)
+
+	a, err = Open("testdata", DefaultOptions().WithFileSize(4).WithArchivePath("testdata_archive"))
+	require.NoError(t, err)
+
+	b = make([]byte, 10)
+	_, err = a.ReadAt(b, 0)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, b)
+
 	err = a.Close()
 	require.NoError(t, err)
 }
@@ -37,6 +37,7 @@ type Options struct {
 	maxOpenedFiles    int
 	compressionFormat int
 	compressionLevel  int
+	archivePath       string
 }
 
 func DefaultOptions() *Options {
@@ -103,3 +104,9 @@ func (opt *Options) WithCompresionLevel(compressionLevel int) *Options {
 	opt.compressionLevel = compressionLevel
 	return opt
 }
+
+// WithArchivePath sets the location where archived files are moved to, archived files are still readable
+func (opt *Options) WithArchivePath(archivePath string) *Options {
+	opt.archivePath = archivePath
+	return opt
+}
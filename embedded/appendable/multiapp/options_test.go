@@ -40,6 +40,7 @@ func TestValidOptions(t *testing.T) {
 	require.Equal(t, []byte{}, opts.WithMetadata([]byte{}).metadata)
 	require.Equal(t, DefaultCompressionFormat, opts.WithCompressionFormat(DefaultCompressionFormat).compressionFormat)
 	require.Equal(t, DefaultCompressionLevel, opts.WithCompresionLevel(DefaultCompressionLevel).compressionLevel)
+	require.Equal(t, "archive", opts.WithArchivePath("archive").archivePath)
 
 	require.True(t, opts.WithSynced(true).synced)
 
@@ -43,6 +43,8 @@ const (
 	metaWrappedMeta       = "WRAPPED_METADATA"
 )
 
+const rawRecordFlag = uint32(1) << 31
+
 type AppendableFile struct {
 	f *os.File
 
@@ -298,7 +300,17 @@ func (aof *AppendableFile) reader(r io.Reader) (reader io.ReadCloser, err error)
 	return
 }
 
+// Append writes bs, compressing it unless it's detected to be incompressible
 func (aof *AppendableFile) Append(bs []byte) (off int64, n int, err error) {
+	return aof.append(bs, false)
+}
+
+// AppendUncompressed writes bs skipping the compression attempt, e.g. when it is known to be already compressed or encrypted
+func (aof *AppendableFile) AppendUncompressed(bs []byte) (off int64, n int, err error) {
+	return aof.append(bs, true)
+}
+
+func (aof *AppendableFile) append(bs []byte, uncompressed bool) (off int64, n int, err error) {
 	aof.mutex.Lock()
 	defer aof.mutex.Unlock()
 
@@ -322,6 +334,10 @@ func (aof *AppendableFile) Append(bs []byte) (off int64, n int, err error) {
 		return
 	}
 
+	if uncompressed || appendable.IsIncompressible(bs) {
+		return aof.appendRecord(bs, true)
+	}
+
 	var b bytes.Buffer
 
 	w, err := aof.writer(&b)
@@ -336,17 +352,27 @@ func (aof *AppendableFile) Append(bs []byte) (off int64, n int, err error) {
 
 	w.(io.Closer).Close()
 
-	bb := b.Bytes()
+	return aof.appendRecord(b.Bytes(), false)
+}
+
+// appendRecord writes a length-prefixed record. The highest bit of the length flags records stored without compression
+func (aof *AppendableFile) appendRecord(bs []byte, raw bool) (off int64, n int, err error) {
+	off = aof.offset
+
+	rLen := uint32(len(bs))
+	if raw {
+		rLen |= rawRecordFlag
+	}
 
-	bbLenBs := make([]byte, 4)
-	binary.BigEndian.PutUint32(bbLenBs, uint32(len(bb)))
+	rLenBs := make([]byte, 4)
+	binary.BigEndian.PutUint32(rLenBs, rLen)
 
-	n, err = aof.w.Write(bbLenBs)
+	n, err = aof.w.Write(rLenBs)
 	if err != nil {
 		return
 	}
 
-	n, err = aof.w.Write(bb)
+	n, err = aof.w.Write(bs)
 	if err != nil {
 		return off, 4 + n, err
 	}
@@ -392,21 +418,27 @@ func (aof *AppendableFile) ReadAt(bs []byte, off int64) (n int, err error) {
 		return 0, err
 	}
 
-	cBs := make([]byte, binary.BigEndian.Uint32(clenBs))
+	clen := binary.BigEndian.Uint32(clenBs)
+
+	cBs := make([]byte, clen&^rawRecordFlag)
 	_, err = io.ReadFull(br, cBs)
 	if err != nil {
 		return 0, err
 	}
 
-	r, err := aof.reader(bytes.NewReader(cBs))
-	if err != nil {
-		return 0, err
-	}
-	defer r.Close()
+	rbs := cBs
 
-	var buf bytes.Buffer
-	buf.ReadFrom(r)
-	rbs := buf.Bytes()
+	if clen&rawRecordFlag == 0 {
+		r, err := aof.reader(bytes.NewReader(cBs))
+		if err != nil {
+			return 0, err
+		}
+		defer r.Close()
+
+		var buf bytes.Buffer
+		buf.ReadFrom(r)
+		rbs = buf.Bytes()
+	}
 
 	n = minInt(len(rbs), len(bs))
 
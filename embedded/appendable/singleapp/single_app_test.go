@@ -17,6 +17,8 @@ package singleapp
 
 import (
 	"bufio"
+	"bytes"
+	"crypto/rand"
 	"encoding/binary"
 	"io"
 	"io/ioutil"
@@ -393,3 +395,49 @@ func TestSingleAppCantCreateFile(t *testing.T) {
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "exists")
 }
+
+func TestSingleAppIncompressibleData(t *testing.T) {
+	opts := DefaultOptions().WithCompressionFormat(appendable.GZipCompression)
+	a, err := Open("testdata_incompressible.aof", opts)
+	defer os.Remove("testdata_incompressible.aof")
+	require.NoError(t, err)
+
+	random := make([]byte, 1024)
+	_, err = rand.Read(random)
+	require.NoError(t, err)
+
+	compressible := bytes.Repeat([]byte("immudb"), 200)
+
+	off1, n1, err := a.Append(random)
+	require.NoError(t, err)
+	require.Equal(t, 4+len(random), n1)
+
+	off2, n2, err := a.Append(compressible)
+	require.NoError(t, err)
+	require.Less(t, n2, len(compressible))
+
+	off3, n3, err := a.AppendUncompressed(compressible)
+	require.NoError(t, err)
+	require.Equal(t, 4+len(compressible), n3)
+
+	err = a.Flush()
+	require.NoError(t, err)
+
+	bs := make([]byte, len(random))
+	_, err = a.ReadAt(bs, off1)
+	require.NoError(t, err)
+	require.Equal(t, random, bs)
+
+	bs = make([]byte, len(compressible))
+	_, err = a.ReadAt(bs, off2)
+	require.NoError(t, err)
+	require.Equal(t, compressible, bs)
+
+	bs = make([]byte, len(compressible))
+	_, err = a.ReadAt(bs, off3)
+	require.NoError(t, err)
+	require.Equal(t, compressible, bs)
+
+	err = a.Close()
+	require.NoError(t, err)
+}
@@ -101,6 +101,25 @@ func (c *LRUCache) Get(key interface{}) (interface{}, error) {
 	return e.value, nil
 }
 
+func (c *LRUCache) Pop(key interface{}) (interface{}, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if key == nil {
+		return nil, ErrIllegalArguments
+	}
+
+	e, ok := c.data[key]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+
+	delete(c.data, key)
+	c.lruList.Remove(e.order)
+
+	return e.value, nil
+}
+
 func (c *LRUCache) Size() int {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
@@ -108,6 +127,14 @@ func (c *LRUCache) Size() int {
 	return c.size
 }
 
+// EntriesCount returns the number of entries currently in the cache
+func (c *LRUCache) EntriesCount() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return len(c.data)
+}
+
 func (c *LRUCache) Apply(fun func(k interface{}, v interface{}) error) error {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
@@ -31,6 +31,7 @@ func TestCacheCreation(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, cache)
 	require.Equal(t, cacheSize, cache.Size())
+	require.Zero(t, cache.EntriesCount())
 
 	_, err = cache.Get(nil)
 	require.Equal(t, ErrIllegalArguments, err)
@@ -43,6 +44,8 @@ func TestCacheCreation(t *testing.T) {
 		require.NoError(t, err)
 	}
 
+	require.Equal(t, cacheSize, cache.EntriesCount())
+
 	for i := cacheSize; i > 0; i-- {
 		v, err := cache.Get(i - 1)
 		require.NoError(t, err)
@@ -100,3 +103,31 @@ func TestApply(t *testing.T) {
 	})
 	require.Error(t, err)
 }
+
+func TestPop(t *testing.T) {
+	cache, err := NewLRUCache(2)
+	require.NoError(t, err)
+
+	_, err = cache.Pop(nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, _, err = cache.Put(1, 10)
+	require.NoError(t, err)
+
+	_, _, err = cache.Put(2, 20)
+	require.NoError(t, err)
+
+	v, err := cache.Pop(1)
+	require.NoError(t, err)
+	require.Equal(t, 10, v)
+
+	_, err = cache.Pop(1)
+	require.Equal(t, ErrKeyNotFound, err)
+
+	_, err = cache.Get(1)
+	require.Equal(t, ErrKeyNotFound, err)
+
+	rkey, _, err := cache.Put(3, 30)
+	require.NoError(t, err)
+	require.Nil(t, rkey)
+}
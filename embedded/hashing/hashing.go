@@ -0,0 +1,81 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package hashing
+
+import (
+	"crypto/sha256"
+	"errors"
+)
+
+var ErrUnsupportedAlgorithm = errors.New("unsupported hash algorithm")
+
+// Size is the digest size of every supported algorithm, thus trees and proofs keep the same layout regardless of the algorithm
+const Size = sha256.Size
+
+// Algorithm identifies the hash function used to calculate digests. It's stored as part of the store metadata
+// and included in states and proofs, zero value is SHA-256. It's not selectable, as trees and Alh are still
+// hashed with SHA-256 directly: they must go through the Hasher before another algorithm is registered
+type Algorithm uint32
+
+const (
+	SHA256 Algorithm = iota
+)
+
+const DefaultAlgorithm = SHA256
+
+type Hasher interface {
+	Algorithm() Algorithm
+	Sum(data []byte) [Size]byte
+}
+
+type sha256Hasher struct{}
+
+func (h sha256Hasher) Algorithm() Algorithm {
+	return SHA256
+}
+
+func (h sha256Hasher) Sum(data []byte) [Size]byte {
+	return sha256.Sum256(data)
+}
+
+var hashers = map[Algorithm]Hasher{
+	SHA256: sha256Hasher{},
+}
+
+var names = map[Algorithm]string{
+	SHA256: "SHA-256",
+}
+
+func (a Algorithm) String() string {
+	name, ok := names[a]
+	if !ok {
+		return "unknown"
+	}
+	return name
+}
+
+func (a Algorithm) Supported() bool {
+	_, ok := hashers[a]
+	return ok
+}
+
+func HasherFor(a Algorithm) (Hasher, error) {
+	h, ok := hashers[a]
+	if !ok {
+		return nil, ErrUnsupportedAlgorithm
+	}
+	return h, nil
+}
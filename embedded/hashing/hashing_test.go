@@ -0,0 +1,41 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package hashing
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashing(t *testing.T) {
+	require.Equal(t, SHA256, DefaultAlgorithm)
+	require.Equal(t, "SHA-256", SHA256.String())
+	require.True(t, SHA256.Supported())
+
+	h, err := HasherFor(SHA256)
+	require.NoError(t, err)
+	require.Equal(t, SHA256, h.Algorithm())
+	require.Equal(t, sha256.Sum256([]byte("data")), h.Sum([]byte("data")))
+
+	unknown := Algorithm(255)
+	require.Equal(t, "unknown", unknown.String())
+	require.False(t, unknown.Supported())
+
+	_, err = HasherFor(unknown)
+	require.Equal(t, ErrUnsupportedAlgorithm, err)
+}
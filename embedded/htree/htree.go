@@ -19,6 +19,8 @@ import (
 	"crypto/sha256"
 	"errors"
 	"math/bits"
+
+	"github.com/codenotary/immudb/embedded/hashing"
 )
 
 var ErrMaxWidthExceeded = errors.New("max width exceeded")
@@ -36,9 +38,10 @@ type HTree struct {
 }
 
 type InclusionProof struct {
-	Leaf  int
-	Width int
-	Terms [][sha256.Size]byte
+	Leaf          int
+	Width         int
+	Terms         [][sha256.Size]byte
+	HashAlgorithm hashing.Algorithm
 }
 
 func New(maxWidth int) (*HTree, error) {
@@ -165,7 +168,7 @@ func (t *HTree) InclusionProof(i int) (proof *InclusionProof, err error) {
 }
 
 func VerifyInclusion(proof *InclusionProof, digest, root [sha256.Size]byte) bool {
-	if proof == nil {
+	if proof == nil || !proof.HashAlgorithm.Supported() {
 		return false
 	}
 
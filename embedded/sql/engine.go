@@ -48,6 +48,7 @@ var ErrIndexAlreadyExists = errors.New("index already exists")
 var ErrInvalidNumberOfValues = errors.New("invalid number of values provided")
 var ErrInvalidValue = errors.New("invalid value provided")
 var ErrExpectingDQLStmt = errors.New("illegal statement. DQL statement expected")
+var ErrExpectingDMLStmt = errors.New("illegal statement. DML statement expected")
 var ErrLimitedOrderBy = errors.New("order is limit to one indexed column")
 var ErrIllegalMappedKey = errors.New("error illegal mapped key")
 var ErrCorruptedData = store.ErrCorruptedData
@@ -69,6 +70,12 @@ var ErrMissingParameter = errors.New("missing paramter")
 var ErrUnsupportedParameter = errors.New("unsupported parameter")
 var ErrLimitedIndex = errors.New("index creation is only supported on empty tables")
 var ErrAlreadyClosed = errors.New("sql engine already closed")
+var ErrTxAlreadyClosed = errors.New("sql tx already closed")
+var ErrDDLNotSupportedInTx = errors.New("DDL statements are not supported within interactive transactions")
+var ErrSavepointOutsideTx = errors.New("savepoints are only supported within interactive transactions")
+var ErrSavepointDoesNotExist = errors.New("savepoint does not exist")
+var ErrSerializationConflict = errors.New("tx conflicts with a concurrently committed transaction and can be retried")
+var ErrInvalidIsolationLevel = errors.New("invalid isolation level")
 
 var mKeyVal = [32]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
 
@@ -92,6 +99,9 @@ type Engine struct {
 	snapshot       *store.Snapshot
 	snapAsBeforeTx uint64
 
+	// serializes data commits so conflicts of interactive transactions are checked against the latest state
+	commitMutex sync.Mutex
+
 	closed bool
 
 	mutex sync.Mutex
@@ -957,7 +967,21 @@ func (e *Engine) QueryPreparedStmt(stmt *SelectStmt, params map[string]interface
 		return nil, err
 	}
 
-	return stmt.Resolve(e, implicitDB, snapshot, params, nil)
+	return stmt.Resolve(e, implicitDB, snapshot, nil, params, nil)
+}
+
+// ReferencedTables returns the tables read by the statement, including those of joins and subqueries
+func (e *Engine) ReferencedTables(stmt *SelectStmt) ([]*Table, error) {
+	if stmt == nil {
+		return nil, ErrIllegalArguments
+	}
+
+	implicitDB, err := e.DatabaseInUse()
+	if err != nil {
+		return nil, err
+	}
+
+	return stmt.referencedTables(e, implicitDB)
 }
 
 func (e *Engine) ExecStmt(sql string, params map[string]interface{}, waitForIndexing bool) (ddTxs, dmTxs []*store.TxMetadata, err error) {
@@ -1016,7 +1040,9 @@ func (e *Engine) ExecPreparedStmts(stmts []SQLStmt, params map[string]interface{
 		}
 
 		if len(dentries) > 0 {
+			e.commitMutex.Lock()
 			txmd, err := e.dataStore.Commit(dentries, waitForIndexing)
+			e.commitMutex.Unlock()
 			if err != nil {
 				return ddTxs, dmTxs, err
 			}
@@ -19,6 +19,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -1520,3 +1521,59 @@ func TestSubQuery(t *testing.T) {
 	err = engine.Close()
 	require.NoError(t, err)
 }
+
+func TestReferencedTables(t *testing.T) {
+	catalogStore, err := store.Open("catalog_reftables", store.DefaultOptions())
+	require.NoError(t, err)
+	defer os.RemoveAll("catalog_reftables")
+
+	dataStore, err := store.Open("sqldata_reftables", store.DefaultOptions())
+	require.NoError(t, err)
+	defer os.RemoveAll("sqldata_reftables")
+
+	engine, err := NewEngine(catalogStore, dataStore, prefix)
+	require.NoError(t, err)
+	defer engine.Close()
+
+	_, err = engine.ReferencedTables(nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, _, err = engine.ExecStmt("CREATE DATABASE db1", nil, true)
+	require.NoError(t, err)
+
+	err = engine.UseDatabase("db1")
+	require.NoError(t, err)
+
+	_, _, err = engine.ExecStmt("CREATE TABLE table1 (id INTEGER, fkid INTEGER, PRIMARY KEY id)", nil, true)
+	require.NoError(t, err)
+
+	_, _, err = engine.ExecStmt("CREATE TABLE table2 (id INTEGER, PRIMARY KEY id)", nil, true)
+	require.NoError(t, err)
+
+	referencedTables := func(query string) ([]string, error) {
+		stmts, err := Parse(strings.NewReader(query))
+		require.NoError(t, err)
+
+		tables, err := engine.ReferencedTables(stmts[0].(*SelectStmt))
+		if err != nil {
+			return nil, err
+		}
+
+		names := make([]string, len(tables))
+		for i, t := range tables {
+			names[i] = t.Name()
+		}
+		return names, nil
+	}
+
+	names, err := referencedTables("SELECT id FROM table1")
+	require.NoError(t, err)
+	require.Equal(t, []string{"table1"}, names)
+
+	names, err = referencedTables("SELECT id FROM (SELECT id, fkid FROM table1) INNER JOIN table2 ON table1.fkid = table2.id")
+	require.NoError(t, err)
+	require.Equal(t, []string{"table1", "table2"}, names)
+
+	_, err = referencedTables("SELECT id FROM table3")
+	require.Equal(t, ErrTableDoesNotExist, err)
+}
@@ -47,7 +47,7 @@ func TestGroupedRowReader(t *testing.T) {
 	snap, err := engine.Snapshot()
 	require.NoError(t, err)
 
-	r, err := engine.newRawRowReader(db, snap, table, 0, "", "id", EqualTo, nil)
+	r, err := engine.newRawRowReader(db, snap, nil, table, 0, "", "id", EqualTo, nil)
 	require.NoError(t, err)
 
 	gr, err := engine.newGroupedRowReader(r, []Selector{&ColSelector{col: "id"}}, []*ColSelector{{col: "id"}})
@@ -24,7 +24,8 @@ type jointRowReader struct {
 	e          *Engine
 	implicitDB *Database
 
-	snap *store.Snapshot
+	snap   *store.Snapshot
+	writes *writeSet
 
 	rowReader RowReader
 
@@ -33,7 +34,7 @@ type jointRowReader struct {
 	params map[string]interface{}
 }
 
-func (e *Engine) newJointRowReader(db *Database, snap *store.Snapshot, params map[string]interface{}, rowReader RowReader, joins []*JoinSpec) (*jointRowReader, error) {
+func (e *Engine) newJointRowReader(db *Database, snap *store.Snapshot, writes *writeSet, params map[string]interface{}, rowReader RowReader, joins []*JoinSpec) (*jointRowReader, error) {
 	if db == nil || snap == nil || rowReader == nil || len(joins) == 0 {
 		return nil, ErrIllegalArguments
 	}
@@ -58,6 +59,7 @@ func (e *Engine) newJointRowReader(db *Database, snap *store.Snapshot, params ma
 		e:          e,
 		implicitDB: db,
 		snap:       snap,
+		writes:     writes,
 		params:     params,
 		rowReader:  rowReader,
 		joins:      joins,
@@ -149,7 +151,7 @@ func (jointr *jointRowReader) Read() (*Row, error) {
 				useInitKeyVal: true,
 			}
 
-			jr, err := jspec.ds.Resolve(jointr.e, jointr.implicitDB, jointr.snap, jointr.params, pkOrd)
+			jr, err := jspec.ds.Resolve(jointr.e, jointr.implicitDB, jointr.snap, jointr.writes, jointr.params, pkOrd)
 			if err != nil {
 				return nil, err
 			}
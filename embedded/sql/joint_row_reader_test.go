@@ -36,7 +36,7 @@ func TestJointRowReader(t *testing.T) {
 	engine, err := NewEngine(catalogStore, dataStore, prefix)
 	require.NoError(t, err)
 
-	_, err = engine.newJointRowReader(nil, nil, nil, nil, nil)
+	_, err = engine.newJointRowReader(nil, nil, nil, nil, nil, nil)
 	require.Equal(t, ErrIllegalArguments, err)
 
 	db, err := engine.catalog.newDatabase("db1")
@@ -48,19 +48,19 @@ func TestJointRowReader(t *testing.T) {
 	snap, err := engine.Snapshot()
 	require.NoError(t, err)
 
-	r, err := engine.newRawRowReader(db, snap, table, 0, "", "id", EqualTo, nil)
+	r, err := engine.newRawRowReader(db, snap, nil, table, 0, "", "id", EqualTo, nil)
 	require.NoError(t, err)
 
-	_, err = engine.newJointRowReader(db, snap, nil, r, []*JoinSpec{{joinType: LeftJoin}})
+	_, err = engine.newJointRowReader(db, snap, nil, nil, r, []*JoinSpec{{joinType: LeftJoin}})
 	require.Equal(t, ErrUnsupportedJoinType, err)
 
-	_, err = engine.newJointRowReader(db, snap, nil, r, []*JoinSpec{{joinType: InnerJoin, ds: &SelectStmt{}}})
+	_, err = engine.newJointRowReader(db, snap, nil, nil, r, []*JoinSpec{{joinType: InnerJoin, ds: &SelectStmt{}}})
 	require.Equal(t, ErrLimitedJoins, err)
 
-	_, err = engine.newJointRowReader(db, snap, nil, r, []*JoinSpec{{joinType: InnerJoin, ds: &TableRef{table: "table2"}}})
+	_, err = engine.newJointRowReader(db, snap, nil, nil, r, []*JoinSpec{{joinType: InnerJoin, ds: &TableRef{table: "table2"}}})
 	require.Equal(t, ErrTableDoesNotExist, err)
 
-	jr, err := engine.newJointRowReader(db, snap, nil, r, []*JoinSpec{{joinType: InnerJoin, ds: &TableRef{table: "table1"}}})
+	jr, err := engine.newJointRowReader(db, snap, nil, nil, r, []*JoinSpec{{joinType: InnerJoin, ds: &TableRef{table: "table1"}}})
 	require.NoError(t, err)
 
 	cols, err := jr.Columns()
@@ -51,6 +51,9 @@ var reservedWords = map[string]int{
 	"BEGIN":       BEGIN,
 	"TRANSACTION": TRANSACTION,
 	"COMMIT":      COMMIT,
+	"SAVEPOINT":   SAVEPOINT,
+	"ROLLBACK":    ROLLBACK,
+	"RELEASE":     RELEASE,
 	"SELECT":      SELECT,
 	"DISTINCT":    DISTINCT,
 	"FROM":        FROM,
@@ -513,6 +513,50 @@ func TestTxStmt(t *testing.T) {
 	}
 }
 
+func TestSavepointStmts(t *testing.T) {
+	testCases := []struct {
+		input          string
+		expectedOutput []SQLStmt
+		expectedError  error
+	}{
+		{
+			input: "SAVEPOINT sp1; UPSERT INTO table1 (id) VALUES (1); ROLLBACK TO SAVEPOINT sp1; RELEASE SAVEPOINT sp1",
+			expectedOutput: []SQLStmt{
+				&SavepointStmt{name: "sp1"},
+				&UpsertIntoStmt{
+					tableRef: &TableRef{table: "table1"},
+					cols:     []string{"id"},
+					rows: []*RowSpec{
+						{Values: []ValueExp{&Number{val: 1}}},
+					},
+				},
+				&RollbackToSavepointStmt{name: "sp1"},
+				&ReleaseSavepointStmt{name: "sp1"},
+			},
+			expectedError: nil,
+		},
+		{
+			input:          "ROLLBACK SAVEPOINT sp1",
+			expectedOutput: nil,
+			expectedError:  errors.New("syntax error: unexpected SAVEPOINT, expecting TO"),
+		},
+		{
+			input:          "SAVEPOINT",
+			expectedOutput: nil,
+			expectedError:  errors.New("syntax error: unexpected $end, expecting IDENTIFIER"),
+		},
+	}
+
+	for i, tc := range testCases {
+		res, err := ParseString(tc.input)
+		require.Equal(t, tc.expectedError, err, fmt.Sprintf("failed on iteration %d", i))
+
+		if tc.expectedError == nil {
+			require.Equal(t, tc.expectedOutput, res, fmt.Sprintf("failed on iteration %d", i))
+		}
+	}
+}
+
 func TestSelectStmt(t *testing.T) {
 	bs, _ := hex.DecodeString("AED0393F")
 
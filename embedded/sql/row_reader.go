@@ -17,6 +17,7 @@ limitations under the License.
 package sql
 
 import (
+	"bytes"
 	"encoding/binary"
 
 	"github.com/codenotary/immudb/embedded/store"
@@ -75,6 +76,15 @@ type rawRowReader struct {
 	col        string
 	desc       bool
 	reader     *store.KeyReader
+
+	// writes buffered by the transaction being read, they take precedence over the entries of the snapshot
+	writes  *writeSet
+	pending []*store.KV
+
+	// next entry read from the snapshot, not yet merged with the pending writes
+	snapKey  []byte
+	snapVal  *store.ValueRef
+	snapDone bool
 }
 
 type ColDescriptor struct {
@@ -82,7 +92,7 @@ type ColDescriptor struct {
 	Type     SQLValueType
 }
 
-func (e *Engine) newRawRowReader(db *Database, snap *store.Snapshot, table *Table, asBefore uint64, tableAlias string, colName string, cmp Comparison, encInitKeyVal []byte) (*rawRowReader, error) {
+func (e *Engine) newRawRowReader(db *Database, snap *store.Snapshot, writes *writeSet, table *Table, asBefore uint64, tableAlias string, colName string, cmp Comparison, encInitKeyVal []byte) (*rawRowReader, error) {
 	if snap == nil || table == nil {
 		return nil, ErrIllegalArguments
 	}
@@ -159,6 +169,8 @@ func (e *Engine) newRawRowReader(db *Database, snap *store.Snapshot, table *Tabl
 		col:        col.colName,
 		desc:       rSpec.DescOrder,
 		reader:     r,
+		writes:     writes,
+		pending:    writes.within(rSpec),
 	}, nil
 }
 
@@ -179,35 +191,31 @@ func (r *rawRowReader) colsBySelector() (map[string]*ColDescriptor, error) {
 }
 
 func (r *rawRowReader) Read() (row *Row, err error) {
-	var mkey []byte
-	var vref *store.ValueRef
-
-	if r.asBefore > 0 {
-		mkey, vref, _, err = r.reader.ReadAsBefore(r.asBefore)
-	} else {
-		mkey, vref, _, _, err = r.reader.Read()
-	}
+	mkey, v, err := r.read()
 	if err != nil {
 		return nil, err
 	}
 
-	var v []byte
-
 	//decompose key, determine if it's pk, when it's pk, the value holds the actual row data
-	if r.table.pk.colName == r.col {
-		v, err = vref.Resolve()
-		if err != nil {
-			return nil, err
-		}
-	} else {
+	if r.table.pk.colName != r.col {
 		_, _, _, _, encPKVal, err := r.e.unmapIndexedRow(mkey)
 		if err != nil {
 			return nil, err
 		}
 
-		v, _, _, err = r.snap.Get(r.e.mapKey(RowPrefix, EncodeID(r.table.db.id), EncodeID(r.table.id), EncodeID(r.table.pk.id), encPKVal))
-		if err != nil {
-			return nil, err
+		pkey := r.e.mapKey(RowPrefix, EncodeID(r.table.db.id), EncodeID(r.table.id), EncodeID(r.table.pk.id), encPKVal)
+
+		var written bool
+
+		if r.asBefore == 0 {
+			v, written = r.writes.get(pkey)
+		}
+
+		if !written {
+			v, _, _, err = r.snap.Get(pkey)
+			if err != nil {
+				return nil, err
+			}
 		}
 	}
 
@@ -247,6 +255,68 @@ func (r *rawRowReader) Read() (row *Row, err error) {
 	return &Row{Values: values}, nil
 }
 
+// read returns the next entry in the reading order, merging the snapshot with the writes buffered by the transaction.
+// Reads as of a previous transaction only observe the snapshot
+func (r *rawRowReader) read() (key []byte, value []byte, err error) {
+	if r.asBefore > 0 {
+		key, vref, _, err := r.reader.ReadAsBefore(r.asBefore)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		value, err = vref.Resolve()
+		return key, value, err
+	}
+
+	if r.snapKey == nil && !r.snapDone {
+		key, vref, _, _, err := r.reader.Read()
+		if err == store.ErrNoMoreEntries {
+			r.snapDone = true
+		} else if err != nil {
+			return nil, nil, err
+		} else {
+			r.snapKey, r.snapVal = key, vref
+		}
+	}
+
+	if len(r.pending) > 0 {
+		w := r.pending[0]
+
+		cmp := 1
+		if r.snapKey != nil {
+			cmp = bytes.Compare(r.snapKey, w.Key)
+			if r.desc {
+				cmp = -cmp
+			}
+		}
+
+		// a write precedes the snapshot entry or replaces it when both have the same key
+		if cmp >= 0 {
+			r.pending = r.pending[1:]
+
+			if cmp == 0 {
+				r.snapKey = nil
+			}
+
+			return w.Key, w.Value, nil
+		}
+	}
+
+	if r.snapKey == nil {
+		return nil, nil, store.ErrNoMoreEntries
+	}
+
+	key = r.snapKey
+	r.snapKey = nil
+
+	value, err = r.snapVal.Resolve()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return key, value, nil
+}
+
 func (r *rawRowReader) Close() error {
 	return r.reader.Close()
 }
@@ -59,7 +59,7 @@ func setResult(l yyLexer, stmts []SQLStmt) {
 }
 
 %token CREATE USE DATABASE SNAPSHOT SINCE UP TO TABLE INDEX ON ALTER ADD COLUMN PRIMARY KEY
-%token BEGIN TRANSACTION COMMIT
+%token BEGIN TRANSACTION COMMIT SAVEPOINT ROLLBACK RELEASE
 %token INSERT UPSERT INTO VALUES
 %token SELECT DISTINCT FROM BEFORE TX JOIN HAVING WHERE GROUP BY LIMIT ORDER ASC DESC AS
 %token NOT LIKE IF EXISTS
@@ -154,6 +154,21 @@ sqlstmt:
     {
         $$ = &TxStmt{stmts: $3}
     }
+|
+    SAVEPOINT IDENTIFIER
+    {
+        $$ = &SavepointStmt{name: $2}
+    }
+|
+    ROLLBACK TO SAVEPOINT IDENTIFIER
+    {
+        $$ = &RollbackToSavepointStmt{name: $4}
+    }
+|
+    RELEASE SAVEPOINT IDENTIFIER
+    {
+        $$ = &ReleaseSavepointStmt{name: $3}
+    }
 
 dstmt: ddlstmt | dmlstmt
 
@@ -62,42 +62,45 @@ const KEY = 57360
 const BEGIN = 57361
 const TRANSACTION = 57362
 const COMMIT = 57363
-const INSERT = 57364
-const UPSERT = 57365
-const INTO = 57366
-const VALUES = 57367
-const SELECT = 57368
-const DISTINCT = 57369
-const FROM = 57370
-const BEFORE = 57371
-const TX = 57372
-const JOIN = 57373
-const HAVING = 57374
-const WHERE = 57375
-const GROUP = 57376
-const BY = 57377
-const LIMIT = 57378
-const ORDER = 57379
-const ASC = 57380
-const DESC = 57381
-const AS = 57382
-const NOT = 57383
-const LIKE = 57384
-const IF = 57385
-const EXISTS = 57386
-const NULL = 57387
-const JOINTYPE = 57388
-const LOP = 57389
-const CMPOP = 57390
-const IDENTIFIER = 57391
-const TYPE = 57392
-const NUMBER = 57393
-const VARCHAR = 57394
-const BOOLEAN = 57395
-const BLOB = 57396
-const AGGREGATE_FUNC = 57397
-const ERROR = 57398
-const STMT_SEPARATOR = 57399
+const SAVEPOINT = 57364
+const ROLLBACK = 57365
+const RELEASE = 57366
+const INSERT = 57367
+const UPSERT = 57368
+const INTO = 57369
+const VALUES = 57370
+const SELECT = 57371
+const DISTINCT = 57372
+const FROM = 57373
+const BEFORE = 57374
+const TX = 57375
+const JOIN = 57376
+const HAVING = 57377
+const WHERE = 57378
+const GROUP = 57379
+const BY = 57380
+const LIMIT = 57381
+const ORDER = 57382
+const ASC = 57383
+const DESC = 57384
+const AS = 57385
+const NOT = 57386
+const LIKE = 57387
+const IF = 57388
+const EXISTS = 57389
+const NULL = 57390
+const JOINTYPE = 57391
+const LOP = 57392
+const CMPOP = 57393
+const IDENTIFIER = 57394
+const TYPE = 57395
+const NUMBER = 57396
+const VARCHAR = 57397
+const BOOLEAN = 57398
+const BLOB = 57399
+const AGGREGATE_FUNC = 57400
+const ERROR = 57401
+const STMT_SEPARATOR = 57402
 
 var yyToknames = [...]string{
 	"$end",
@@ -121,6 +124,9 @@ var yyToknames = [...]string{
 	"BEGIN",
 	"TRANSACTION",
 	"COMMIT",
+	"SAVEPOINT",
+	"ROLLBACK",
+	"RELEASE",
 	"INSERT",
 	"UPSERT",
 	"INTO",
@@ -181,143 +187,147 @@ var yyExca = [...]int{
 
 const yyPrivate = 57344
 
-const yyLast = 250
+const yyLast = 259
 
 var yyAct = [...]int{
 
-	204, 37, 56, 144, 122, 4, 124, 143, 99, 71,
-	63, 90, 126, 72, 85, 129, 136, 196, 195, 190,
-	134, 105, 130, 131, 132, 133, 38, 189, 185, 106,
-	127, 164, 136, 154, 155, 128, 170, 135, 130, 131,
-	132, 133, 48, 50, 150, 151, 153, 152, 105, 49,
-	59, 183, 161, 135, 154, 155, 104, 39, 116, 155,
-	77, 112, 96, 161, 73, 150, 151, 153, 152, 150,
-	151, 153, 152, 76, 145, 97, 160, 95, 81, 94,
-	150, 151, 153, 152, 88, 79, 53, 93, 69, 67,
-	58, 18, 16, 103, 153, 152, 39, 68, 59, 203,
-	194, 167, 38, 109, 111, 114, 55, 34, 123, 39,
-	182, 31, 199, 102, 138, 38, 5, 83, 139, 115,
-	137, 7, 39, 140, 187, 162, 118, 146, 113, 36,
-	100, 157, 158, 159, 101, 86, 32, 87, 78, 75,
-	62, 60, 49, 47, 49, 44, 40, 80, 92, 166,
-	100, 175, 169, 173, 42, 176, 177, 178, 179, 180,
-	181, 156, 142, 61, 74, 70, 184, 57, 186, 172,
-	32, 188, 205, 206, 192, 193, 149, 121, 15, 108,
-	148, 110, 82, 17, 65, 64, 54, 21, 10, 11,
-	7, 119, 117, 29, 198, 201, 202, 197, 12, 10,
-	11, 28, 51, 6, 165, 207, 13, 14, 208, 12,
-	7, 52, 19, 2, 84, 66, 22, 13, 14, 163,
-	43, 23, 24, 27, 46, 25, 26, 141, 41, 171,
-	30, 200, 191, 120, 125, 147, 107, 91, 89, 45,
-	20, 35, 33, 168, 174, 98, 9, 8, 3, 1,
+	213, 45, 65, 153, 131, 4, 133, 152, 108, 80,
+	72, 99, 81, 135, 94, 205, 138, 145, 47, 204,
+	199, 143, 114, 139, 140, 141, 142, 46, 198, 194,
+	115, 136, 173, 145, 85, 114, 137, 179, 144, 139,
+	140, 141, 142, 113, 163, 164, 125, 56, 58, 68,
+	121, 170, 163, 164, 144, 159, 160, 162, 161, 57,
+	164, 105, 192, 159, 160, 162, 161, 170, 154, 86,
+	159, 160, 162, 161, 82, 159, 160, 162, 161, 169,
+	90, 132, 88, 78, 76, 67, 104, 61, 103, 21,
+	19, 162, 161, 97, 106, 102, 77, 68, 47, 212,
+	203, 5, 112, 176, 46, 123, 191, 64, 44, 42,
+	37, 47, 118, 120, 208, 111, 92, 46, 148, 124,
+	10, 47, 196, 147, 38, 171, 127, 122, 109, 146,
+	110, 95, 149, 96, 87, 84, 155, 71, 69, 62,
+	166, 167, 168, 57, 57, 55, 83, 52, 48, 40,
+	23, 101, 175, 109, 89, 50, 165, 151, 70, 66,
+	184, 178, 182, 38, 185, 186, 187, 188, 189, 190,
+	214, 215, 79, 181, 18, 193, 201, 195, 202, 20,
+	197, 158, 130, 117, 157, 119, 91, 74, 73, 63,
+	27, 10, 128, 126, 35, 34, 39, 25, 59, 22,
+	13, 14, 174, 207, 210, 211, 206, 2, 93, 75,
+	15, 172, 51, 60, 216, 6, 33, 217, 7, 8,
+	9, 16, 17, 13, 14, 10, 28, 36, 24, 54,
+	150, 29, 30, 15, 31, 32, 49, 180, 209, 200,
+	129, 134, 156, 116, 16, 17, 100, 98, 53, 26,
+	43, 41, 177, 183, 107, 12, 11, 3, 1,
 }
 var yyPact = [...]int{
 
-	184, -1000, -1000, 29, 28, -1000, 192, 160, -1000, -1000,
-	210, 219, 212, 177, 169, -1000, 184, -1000, -1000, 195,
-	47, -1000, 97, 111, 207, 96, 216, 94, 93, 93,
-	-1000, 181, 23, 158, -1000, 49, 127, -1000, 26, 36,
-	-1000, 92, 122, 91, -1000, 156, 154, 200, 25, 35,
-	24, -1000, -1000, 195, 0, 60, -1000, 90, 8, 89,
-	21, 103, 14, -1000, 152, 66, 198, 86, 88, 86,
-	-1000, 102, -1000, 95, 127, -1000, -1000, -3, 13, 81,
-	-1000, 85, 62, -1000, 81, -9, -1000, -1000, -36, 146,
-	-1000, 102, 150, 156, -4, -1000, -1000, 79, 48, -1000,
-	69, -7, -1000, -1000, 167, 77, 166, 143, -29, -1000,
-	0, 127, -1000, -1000, 101, 121, -1000, 10, -1000, 10,
-	148, 141, 7, 119, -1000, -1000, -29, -29, -29, 12,
-	-1000, -1000, -1000, -1000, -12, 76, -1000, 206, -34, 186,
-	-1000, -1000, 104, 44, -1000, -13, 44, 132, -29, 73,
-	-29, -29, -29, -29, -29, -29, 58, 11, 34, -14,
-	164, -37, -1000, -29, -1000, 75, -1000, 10, -38, -1000,
-	-1, 138, 140, 7, 43, -1000, 34, 34, -1000, -1000,
-	11, 22, -1000, -1000, -47, -1000, 7, -48, -1000, -1000,
-	-13, 127, 61, 73, 73, -1000, -1000, -1000, -1000, -1000,
-	42, 134, -1000, 73, -1000, -1000, -1000, 134, -1000,
+	196, -1000, -1000, 24, 23, -1000, 179, 98, 218, 175,
+	160, -1000, -1000, 220, 228, 205, 168, 167, -1000, 196,
+	-1000, -1000, 219, -1000, 174, 97, 46, -1000, 96, 109,
+	199, 95, 221, 93, 92, 92, -1000, 177, 21, 87,
+	-1000, 158, -1000, 47, 116, -1000, 18, 32, -1000, 86,
+	114, 85, -1000, 156, 154, 194, 17, 31, 16, -1000,
+	-1000, 219, -1000, 7, 59, -1000, 83, -34, 82, 15,
+	107, 13, -1000, 153, 62, 192, 79, 81, 79, -1000,
+	102, -1000, 91, 116, -1000, -1000, -7, 29, 76, -1000,
+	78, 61, -1000, 76, -25, -1000, -1000, -38, 147, -1000,
+	102, 151, 156, -18, -1000, -1000, 75, 45, -1000, 66,
+	-22, -1000, -1000, 165, 74, 164, 145, -31, -1000, 7,
+	116, -1000, -1000, 101, 113, -1000, 1, -1000, 1, 149,
+	143, 2, 111, -1000, -1000, -31, -31, -31, 12, -1000,
+	-1000, -1000, -1000, -16, 73, -1000, 198, -36, 184, -1000,
+	-1000, 104, 43, -1000, -15, 43, 133, -31, 69, -31,
+	-31, -31, -31, -31, -31, 51, 9, 28, -6, 162,
+	-39, -1000, -31, -1000, 70, -1000, 1, -40, -1000, 0,
+	137, 140, 2, 40, -1000, 28, 28, -1000, -1000, 9,
+	14, -1000, -1000, -49, -1000, 2, -53, -1000, -1000, -15,
+	116, 60, 69, 69, -1000, -1000, -1000, -1000, -1000, 39,
+	129, -1000, 69, -1000, -1000, -1000, 129, -1000,
 }
 var yyPgo = [...]int{
 
-	0, 249, 213, 111, 248, 116, 247, 246, 5, 245,
-	8, 14, 244, 7, 3, 243, 6, 108, 242, 241,
-	1, 240, 9, 13, 239, 10, 238, 11, 237, 4,
-	236, 235, 234, 233, 232, 2, 231, 229, 0, 228,
-	227, 178,
+	0, 258, 207, 110, 257, 101, 256, 255, 5, 254,
+	8, 14, 253, 7, 3, 252, 6, 81, 251, 250,
+	1, 249, 9, 12, 248, 10, 247, 11, 246, 4,
+	243, 242, 241, 240, 239, 2, 238, 237, 0, 236,
+	230, 174,
 }
 var yyR1 = [...]int{
 
-	0, 1, 2, 2, 2, 41, 41, 4, 4, 5,
-	5, 3, 3, 6, 6, 6, 6, 6, 6, 24,
-	24, 39, 39, 7, 7, 13, 13, 14, 11, 11,
-	12, 12, 15, 15, 16, 16, 16, 16, 16, 16,
-	16, 9, 9, 10, 40, 40, 8, 21, 21, 18,
-	18, 19, 19, 17, 17, 17, 20, 20, 20, 22,
-	22, 22, 23, 23, 25, 25, 26, 26, 27, 27,
-	28, 30, 30, 33, 33, 31, 31, 34, 34, 37,
-	37, 36, 36, 38, 38, 38, 35, 35, 29, 29,
-	29, 29, 29, 29, 29, 29, 32, 32, 32, 32,
-	32, 32,
+	0, 1, 2, 2, 2, 41, 41, 4, 4, 4,
+	4, 4, 5, 5, 3, 3, 6, 6, 6, 6,
+	6, 6, 24, 24, 39, 39, 7, 7, 13, 13,
+	14, 11, 11, 12, 12, 15, 15, 16, 16, 16,
+	16, 16, 16, 16, 9, 9, 10, 40, 40, 8,
+	21, 21, 18, 18, 19, 19, 17, 17, 17, 20,
+	20, 20, 22, 22, 22, 23, 23, 25, 25, 26,
+	26, 27, 27, 28, 30, 30, 33, 33, 31, 31,
+	34, 34, 37, 37, 36, 36, 38, 38, 38, 35,
+	35, 29, 29, 29, 29, 29, 29, 29, 29, 32,
+	32, 32, 32, 32, 32,
 }
 var yyR2 = [...]int{
 
-	0, 1, 2, 2, 3, 0, 1, 1, 4, 1,
-	1, 2, 3, 3, 3, 4, 11, 7, 6, 0,
-	3, 0, 3, 8, 8, 1, 3, 3, 1, 3,
-	1, 3, 1, 3, 1, 1, 1, 1, 3, 2,
-	1, 1, 3, 3, 0, 2, 12, 0, 1, 1,
-	1, 2, 4, 1, 3, 4, 1, 3, 5, 1,
-	5, 3, 1, 3, 0, 3, 0, 1, 1, 2,
-	5, 0, 2, 0, 3, 0, 2, 0, 2, 0,
-	3, 2, 4, 0, 1, 1, 0, 2, 1, 1,
-	1, 2, 2, 3, 3, 4, 3, 3, 3, 3,
-	3, 3,
+	0, 1, 2, 2, 3, 0, 1, 1, 4, 2,
+	4, 3, 1, 1, 2, 3, 3, 3, 4, 11,
+	7, 6, 0, 3, 0, 3, 8, 8, 1, 3,
+	3, 1, 3, 1, 3, 1, 3, 1, 1, 1,
+	1, 3, 2, 1, 1, 3, 3, 0, 2, 12,
+	0, 1, 1, 1, 2, 4, 1, 3, 4, 1,
+	3, 5, 1, 5, 3, 1, 3, 0, 3, 0,
+	1, 1, 2, 5, 0, 2, 0, 3, 0, 2,
+	0, 2, 0, 3, 2, 4, 0, 1, 1, 0,
+	2, 1, 1, 1, 2, 2, 3, 3, 4, 3,
+	3, 3, 3, 3, 3,
 }
 var yyChk = [...]int{
 
-	-1000, -1, -2, -4, -8, -5, 19, 26, -6, -7,
-	4, 5, 14, 22, 23, -41, 63, -41, 63, 20,
-	-21, 27, 6, 11, 12, 6, 7, 11, 24, 24,
-	-2, -3, -5, -18, 60, -19, -17, -20, 55, 49,
-	49, -39, 43, 13, 49, -24, 8, 49, -23, 49,
-	-23, 21, -41, 63, 28, 57, -35, 40, 64, 62,
-	49, 41, 49, -25, 29, 30, 15, 64, 62, 64,
-	-3, -22, -23, 64, -17, 49, 65, -20, 49, 64,
-	44, 64, 30, 51, 16, -11, 49, 49, -11, -26,
-	-27, -28, 46, -23, -8, -35, 65, 62, -9, -10,
-	49, 49, 51, -10, 65, 57, 65, -30, 33, -27,
-	31, -25, 65, 49, 57, 50, 65, 25, 49, 25,
-	-33, 34, -29, -17, -16, -32, 41, 59, 64, 44,
-	51, 52, 53, 54, 49, 66, 45, -22, -35, 17,
-	-10, -40, 41, -13, -14, 64, -13, -31, 32, 35,
-	58, 59, 61, 60, 47, 48, 42, -29, -29, -29,
-	64, 64, 49, 13, 65, 18, 45, 57, -15, -16,
-	49, -37, 37, -29, -12, -20, -29, -29, -29, -29,
-	-29, -29, 52, 65, -8, 65, -29, 49, -14, 65,
-	57, -34, 36, 35, 57, 65, 65, -16, -35, 51,
-	-36, -20, -20, 57, -38, 38, 39, -20, -38,
+	-1000, -1, -2, -4, -8, -5, 19, 22, 23, 24,
+	29, -6, -7, 4, 5, 14, 25, 26, -41, 66,
+	-41, 66, 20, 52, 10, 22, -21, 30, 6, 11,
+	12, 6, 7, 11, 27, 27, -2, -3, -5, 22,
+	52, -18, 63, -19, -17, -20, 58, 52, 52, -39,
+	46, 13, 52, -24, 8, 52, -23, 52, -23, 21,
+	-41, 66, 52, 31, 60, -35, 43, 67, 65, 52,
+	44, 52, -25, 32, 33, 15, 67, 65, 67, -3,
+	-22, -23, 67, -17, 52, 68, -20, 52, 67, 47,
+	67, 33, 54, 16, -11, 52, 52, -11, -26, -27,
+	-28, 49, -23, -8, -35, 68, 65, -9, -10, 52,
+	52, 54, -10, 68, 60, 68, -30, 36, -27, 34,
+	-25, 68, 52, 60, 53, 68, 28, 52, 28, -33,
+	37, -29, -17, -16, -32, 44, 62, 67, 47, 54,
+	55, 56, 57, 52, 69, 48, -22, -35, 17, -10,
+	-40, 44, -13, -14, 67, -13, -31, 35, 38, 61,
+	62, 64, 63, 50, 51, 45, -29, -29, -29, 67,
+	67, 52, 13, 68, 18, 48, 60, -15, -16, 52,
+	-37, 40, -29, -12, -20, -29, -29, -29, -29, -29,
+	-29, 55, 68, -8, 68, -29, 52, -14, 68, 60,
+	-34, 39, 38, 60, 68, 68, -16, -35, 54, -36,
+	-20, -20, 60, -38, 41, 42, -20, -38,
 }
 var yyDef = [...]int{
 
-	0, -2, 1, 5, 5, 7, 0, 47, 9, 10,
-	0, 0, 0, 0, 0, 2, 6, 3, 6, 0,
-	0, 48, 0, 21, 0, 0, 19, 0, 0, 0,
-	4, 0, 5, 0, 49, 50, 86, 53, 0, 56,
-	13, 0, 0, 0, 14, 64, 0, 0, 0, 62,
-	0, 8, 11, 6, 0, 0, 51, 0, 0, 0,
-	0, 0, 0, 15, 0, 0, 0, 0, 0, 0,
-	12, 66, 59, 0, 86, 87, 54, 0, 57, 0,
-	22, 0, 0, 20, 0, 0, 28, 63, 0, 71,
-	67, 68, 0, 64, 0, 52, 55, 0, 0, 41,
-	0, 0, 65, 18, 0, 0, 0, 73, 0, 69,
-	0, 86, 61, 58, 0, 44, 17, 0, 29, 0,
-	75, 0, 72, 88, 89, 90, 0, 0, 0, 0,
-	34, 35, 36, 37, 56, 0, 40, 0, 0, 0,
-	42, 43, 0, 23, 25, 0, 24, 79, 0, 0,
-	0, 0, 0, 0, 0, 0, 0, 91, 92, 0,
-	0, 0, 39, 0, 60, 0, 45, 0, 0, 32,
-	0, 77, 0, 76, 74, 30, 96, 97, 98, 99,
-	100, 101, 94, 93, 0, 38, 70, 0, 26, 27,
-	0, 86, 0, 0, 0, 95, 16, 33, 46, 78,
-	80, 83, 31, 0, 81, 84, 85, 83, 82,
+	0, -2, 1, 5, 5, 7, 0, 0, 0, 0,
+	50, 12, 13, 0, 0, 0, 0, 0, 2, 6,
+	3, 6, 0, 9, 0, 0, 0, 51, 0, 24,
+	0, 0, 22, 0, 0, 0, 4, 0, 5, 0,
+	11, 0, 52, 53, 89, 56, 0, 59, 16, 0,
+	0, 0, 17, 67, 0, 0, 0, 65, 0, 8,
+	14, 6, 10, 0, 0, 54, 0, 0, 0, 0,
+	0, 0, 18, 0, 0, 0, 0, 0, 0, 15,
+	69, 62, 0, 89, 90, 57, 0, 60, 0, 25,
+	0, 0, 23, 0, 0, 31, 66, 0, 74, 70,
+	71, 0, 67, 0, 55, 58, 0, 0, 44, 0,
+	0, 68, 21, 0, 0, 0, 76, 0, 72, 0,
+	89, 64, 61, 0, 47, 20, 0, 32, 0, 78,
+	0, 75, 91, 92, 93, 0, 0, 0, 0, 37,
+	38, 39, 40, 59, 0, 43, 0, 0, 0, 45,
+	46, 0, 26, 28, 0, 27, 82, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 94, 95, 0, 0,
+	0, 42, 0, 63, 0, 48, 0, 0, 35, 0,
+	80, 0, 79, 77, 33, 99, 100, 101, 102, 103,
+	104, 97, 96, 0, 41, 73, 0, 29, 30, 0,
+	89, 0, 0, 0, 98, 19, 36, 49, 81, 83,
+	86, 34, 0, 84, 87, 88, 86, 85,
 }
 var yyTok1 = [...]int{
 
@@ -325,9 +335,9 @@ var yyTok1 = [...]int{
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
-	64, 65, 60, 58, 57, 59, 62, 61, 3, 3,
+	67, 68, 63, 61, 60, 62, 65, 64, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
-	3, 3, 3, 3, 66,
+	3, 3, 3, 3, 69,
 }
 var yyTok2 = [...]int{
 
@@ -336,7 +346,7 @@ var yyTok2 = [...]int{
 	22, 23, 24, 25, 26, 27, 28, 29, 30, 31,
 	32, 33, 34, 35, 36, 37, 38, 39, 40, 41,
 	42, 43, 44, 45, 46, 47, 48, 49, 50, 51,
-	52, 53, 54, 55, 56, 63,
+	52, 53, 54, 55, 56, 57, 58, 59, 66,
 }
 var yyTok3 = [...]int{
 	0,
@@ -712,182 +722,197 @@ yydefault:
 		{
 			yyVAL.stmt = &TxStmt{stmts: yyDollar[3].stmts}
 		}
+	case 9:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		{
+			yyVAL.stmt = &SavepointStmt{name: yyDollar[2].id}
+		}
+	case 10:
+		yyDollar = yyS[yypt-4 : yypt+1]
+		{
+			yyVAL.stmt = &RollbackToSavepointStmt{name: yyDollar[4].id}
+		}
 	case 11:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		{
+			yyVAL.stmt = &ReleaseSavepointStmt{name: yyDollar[3].id}
+		}
+	case 14:
 		yyDollar = yyS[yypt-2 : yypt+1]
 		{
 			yyVAL.stmts = []SQLStmt{yyDollar[1].stmt}
 		}
-	case 12:
+	case 15:
 		yyDollar = yyS[yypt-3 : yypt+1]
 		{
 			yyVAL.stmts = append([]SQLStmt{yyDollar[1].stmt}, yyDollar[3].stmts...)
 		}
-	case 13:
+	case 16:
 		yyDollar = yyS[yypt-3 : yypt+1]
 		{
 			yyVAL.stmt = &CreateDatabaseStmt{DB: yyDollar[3].id}
 		}
-	case 14:
+	case 17:
 		yyDollar = yyS[yypt-3 : yypt+1]
 		{
 			yyVAL.stmt = &UseDatabaseStmt{DB: yyDollar[3].id}
 		}
-	case 15:
+	case 18:
 		yyDollar = yyS[yypt-4 : yypt+1]
 		{
 			yyVAL.stmt = &UseSnapshotStmt{sinceTx: yyDollar[3].number, asBefore: yyDollar[4].number}
 		}
-	case 16:
+	case 19:
 		yyDollar = yyS[yypt-11 : yypt+1]
 		{
 			yyVAL.stmt = &CreateTableStmt{ifNotExists: yyDollar[3].boolean, table: yyDollar[4].id, colsSpec: yyDollar[6].colsSpec, pk: yyDollar[10].id}
 		}
-	case 17:
+	case 20:
 		yyDollar = yyS[yypt-7 : yypt+1]
 		{
 			yyVAL.stmt = &CreateIndexStmt{table: yyDollar[4].id, col: yyDollar[6].id}
 		}
-	case 18:
+	case 21:
 		yyDollar = yyS[yypt-6 : yypt+1]
 		{
 			yyVAL.stmt = &AddColumnStmt{table: yyDollar[3].id, colSpec: yyDollar[6].colSpec}
 		}
-	case 19:
+	case 22:
 		yyDollar = yyS[yypt-0 : yypt+1]
 		{
 			yyVAL.number = 0
 		}
-	case 20:
+	case 23:
 		yyDollar = yyS[yypt-3 : yypt+1]
 		{
 			yyVAL.number = yyDollar[3].number
 		}
-	case 21:
+	case 24:
 		yyDollar = yyS[yypt-0 : yypt+1]
 		{
 			yyVAL.boolean = false
 		}
-	case 22:
+	case 25:
 		yyDollar = yyS[yypt-3 : yypt+1]
 		{
 			yyVAL.boolean = true
 		}
-	case 23:
+	case 26:
 		yyDollar = yyS[yypt-8 : yypt+1]
 		{
 			yyVAL.stmt = &UpsertIntoStmt{isInsert: true, tableRef: yyDollar[3].tableRef, cols: yyDollar[5].ids, rows: yyDollar[8].rows}
 		}
-	case 24:
+	case 27:
 		yyDollar = yyS[yypt-8 : yypt+1]
 		{
 			yyVAL.stmt = &UpsertIntoStmt{tableRef: yyDollar[3].tableRef, cols: yyDollar[5].ids, rows: yyDollar[8].rows}
 		}
-	case 25:
+	case 28:
 		yyDollar = yyS[yypt-1 : yypt+1]
 		{
 			yyVAL.rows = []*RowSpec{yyDollar[1].row}
 		}
-	case 26:
+	case 29:
 		yyDollar = yyS[yypt-3 : yypt+1]
 		{
 			yyVAL.rows = append(yyDollar[1].rows, yyDollar[3].row)
 		}
-	case 27:
+	case 30:
 		yyDollar = yyS[yypt-3 : yypt+1]
 		{
 			yyVAL.row = &RowSpec{Values: yyDollar[2].values}
 		}
-	case 28:
+	case 31:
 		yyDollar = yyS[yypt-1 : yypt+1]
 		{
 			yyVAL.ids = []string{yyDollar[1].id}
 		}
-	case 29:
+	case 32:
 		yyDollar = yyS[yypt-3 : yypt+1]
 		{
 			yyVAL.ids = append(yyDollar[1].ids, yyDollar[3].id)
 		}
-	case 30:
+	case 33:
 		yyDollar = yyS[yypt-1 : yypt+1]
 		{
 			yyVAL.cols = []*ColSelector{yyDollar[1].col}
 		}
-	case 31:
+	case 34:
 		yyDollar = yyS[yypt-3 : yypt+1]
 		{
 			yyVAL.cols = append(yyDollar[1].cols, yyDollar[3].col)
 		}
-	case 32:
+	case 35:
 		yyDollar = yyS[yypt-1 : yypt+1]
 		{
 			yyVAL.values = []ValueExp{yyDollar[1].value}
 		}
-	case 33:
+	case 36:
 		yyDollar = yyS[yypt-3 : yypt+1]
 		{
 			yyVAL.values = append(yyDollar[1].values, yyDollar[3].value)
 		}
-	case 34:
+	case 37:
 		yyDollar = yyS[yypt-1 : yypt+1]
 		{
 			yyVAL.value = &Number{val: yyDollar[1].number}
 		}
-	case 35:
+	case 38:
 		yyDollar = yyS[yypt-1 : yypt+1]
 		{
 			yyVAL.value = &Varchar{val: yyDollar[1].str}
 		}
-	case 36:
+	case 39:
 		yyDollar = yyS[yypt-1 : yypt+1]
 		{
 			yyVAL.value = &Bool{val: yyDollar[1].boolean}
 		}
-	case 37:
+	case 40:
 		yyDollar = yyS[yypt-1 : yypt+1]
 		{
 			yyVAL.value = &Blob{val: yyDollar[1].blob}
 		}
-	case 38:
+	case 41:
 		yyDollar = yyS[yypt-3 : yypt+1]
 		{
 			yyVAL.value = &SysFn{fn: yyDollar[1].id}
 		}
-	case 39:
+	case 42:
 		yyDollar = yyS[yypt-2 : yypt+1]
 		{
 			yyVAL.value = &Param{id: yyDollar[2].id}
 		}
-	case 40:
+	case 43:
 		yyDollar = yyS[yypt-1 : yypt+1]
 		{
 			yyVAL.value = &NullValue{}
 		}
-	case 41:
+	case 44:
 		yyDollar = yyS[yypt-1 : yypt+1]
 		{
 			yyVAL.colsSpec = []*ColSpec{yyDollar[1].colSpec}
 		}
-	case 42:
+	case 45:
 		yyDollar = yyS[yypt-3 : yypt+1]
 		{
 			yyVAL.colsSpec = append(yyDollar[1].colsSpec, yyDollar[3].colSpec)
 		}
-	case 43:
+	case 46:
 		yyDollar = yyS[yypt-3 : yypt+1]
 		{
 			yyVAL.colSpec = &ColSpec{colName: yyDollar[1].id, colType: yyDollar[2].sqlType, notNull: yyDollar[3].boolean}
 		}
-	case 44:
+	case 47:
 		yyDollar = yyS[yypt-0 : yypt+1]
 		{
 			yyVAL.boolean = false
 		}
-	case 45:
+	case 48:
 		yyDollar = yyS[yypt-2 : yypt+1]
 		{
 			yyVAL.boolean = true
 		}
-	case 46:
+	case 49:
 		yyDollar = yyS[yypt-12 : yypt+1]
 		{
 			yyVAL.stmt = &SelectStmt{
@@ -903,281 +928,281 @@ yydefault:
 				as:        yyDollar[12].id,
 			}
 		}
-	case 47:
+	case 50:
 		yyDollar = yyS[yypt-0 : yypt+1]
 		{
 			yyVAL.distinct = false
 		}
-	case 48:
+	case 51:
 		yyDollar = yyS[yypt-1 : yypt+1]
 		{
 			yyVAL.distinct = true
 		}
-	case 49:
+	case 52:
 		yyDollar = yyS[yypt-1 : yypt+1]
 		{
 			yyVAL.sels = nil
 		}
-	case 50:
+	case 53:
 		yyDollar = yyS[yypt-1 : yypt+1]
 		{
 			yyVAL.sels = yyDollar[1].sels
 		}
-	case 51:
+	case 54:
 		yyDollar = yyS[yypt-2 : yypt+1]
 		{
 			yyDollar[1].sel.setAlias(yyDollar[2].id)
 			yyVAL.sels = []Selector{yyDollar[1].sel}
 		}
-	case 52:
+	case 55:
 		yyDollar = yyS[yypt-4 : yypt+1]
 		{
 			yyDollar[3].sel.setAlias(yyDollar[4].id)
 			yyVAL.sels = append(yyDollar[1].sels, yyDollar[3].sel)
 		}
-	case 53:
+	case 56:
 		yyDollar = yyS[yypt-1 : yypt+1]
 		{
 			yyVAL.sel = yyDollar[1].col
 		}
-	case 54:
+	case 57:
 		yyDollar = yyS[yypt-3 : yypt+1]
 		{
 			yyVAL.sel = &AggColSelector{aggFn: yyDollar[1].aggFn, col: "*"}
 		}
-	case 55:
+	case 58:
 		yyDollar = yyS[yypt-4 : yypt+1]
 		{
 			yyVAL.sel = &AggColSelector{aggFn: yyDollar[1].aggFn, db: yyDollar[3].col.db, table: yyDollar[3].col.table, col: yyDollar[3].col.col}
 		}
-	case 56:
+	case 59:
 		yyDollar = yyS[yypt-1 : yypt+1]
 		{
 			yyVAL.col = &ColSelector{col: yyDollar[1].id}
 		}
-	case 57:
+	case 60:
 		yyDollar = yyS[yypt-3 : yypt+1]
 		{
 			yyVAL.col = &ColSelector{table: yyDollar[1].id, col: yyDollar[3].id}
 		}
-	case 58:
+	case 61:
 		yyDollar = yyS[yypt-5 : yypt+1]
 		{
 			yyVAL.col = &ColSelector{db: yyDollar[1].id, table: yyDollar[3].id, col: yyDollar[5].id}
 		}
-	case 59:
+	case 62:
 		yyDollar = yyS[yypt-1 : yypt+1]
 		{
 			yyVAL.ds = yyDollar[1].tableRef
 		}
-	case 60:
+	case 63:
 		yyDollar = yyS[yypt-5 : yypt+1]
 		{
 			yyDollar[2].tableRef.asBefore = yyDollar[3].number
 			yyDollar[2].tableRef.as = yyDollar[4].id
 			yyVAL.ds = yyDollar[2].tableRef
 		}
-	case 61:
+	case 64:
 		yyDollar = yyS[yypt-3 : yypt+1]
 		{
 			yyVAL.ds = yyDollar[2].stmt.(*SelectStmt)
 		}
-	case 62:
+	case 65:
 		yyDollar = yyS[yypt-1 : yypt+1]
 		{
 			yyVAL.tableRef = &TableRef{table: yyDollar[1].id}
 		}
-	case 63:
+	case 66:
 		yyDollar = yyS[yypt-3 : yypt+1]
 		{
 			yyVAL.tableRef = &TableRef{db: yyDollar[1].id, table: yyDollar[3].id}
 		}
-	case 64:
+	case 67:
 		yyDollar = yyS[yypt-0 : yypt+1]
 		{
 			yyVAL.number = 0
 		}
-	case 65:
+	case 68:
 		yyDollar = yyS[yypt-3 : yypt+1]
 		{
 			yyVAL.number = yyDollar[3].number
 		}
-	case 66:
+	case 69:
 		yyDollar = yyS[yypt-0 : yypt+1]
 		{
 			yyVAL.joins = nil
 		}
-	case 67:
+	case 70:
 		yyDollar = yyS[yypt-1 : yypt+1]
 		{
 			yyVAL.joins = yyDollar[1].joins
 		}
-	case 68:
+	case 71:
 		yyDollar = yyS[yypt-1 : yypt+1]
 		{
 			yyVAL.joins = []*JoinSpec{yyDollar[1].join}
 		}
-	case 69:
+	case 72:
 		yyDollar = yyS[yypt-2 : yypt+1]
 		{
 			yyVAL.joins = append([]*JoinSpec{yyDollar[1].join}, yyDollar[2].joins...)
 		}
-	case 70:
+	case 73:
 		yyDollar = yyS[yypt-5 : yypt+1]
 		{
 			yyVAL.join = &JoinSpec{joinType: yyDollar[1].joinType, ds: yyDollar[3].ds, cond: yyDollar[5].boolExp}
 		}
-	case 71:
+	case 74:
 		yyDollar = yyS[yypt-0 : yypt+1]
 		{
 			yyVAL.boolExp = nil
 		}
-	case 72:
+	case 75:
 		yyDollar = yyS[yypt-2 : yypt+1]
 		{
 			yyVAL.boolExp = yyDollar[2].boolExp
 		}
-	case 73:
+	case 76:
 		yyDollar = yyS[yypt-0 : yypt+1]
 		{
 			yyVAL.cols = nil
 		}
-	case 74:
+	case 77:
 		yyDollar = yyS[yypt-3 : yypt+1]
 		{
 			yyVAL.cols = yyDollar[3].cols
 		}
-	case 75:
+	case 78:
 		yyDollar = yyS[yypt-0 : yypt+1]
 		{
 			yyVAL.boolExp = nil
 		}
-	case 76:
+	case 79:
 		yyDollar = yyS[yypt-2 : yypt+1]
 		{
 			yyVAL.boolExp = yyDollar[2].boolExp
 		}
-	case 77:
+	case 80:
 		yyDollar = yyS[yypt-0 : yypt+1]
 		{
 			yyVAL.number = 0
 		}
-	case 78:
+	case 81:
 		yyDollar = yyS[yypt-2 : yypt+1]
 		{
 			yyVAL.number = yyDollar[2].number
 		}
-	case 79:
+	case 82:
 		yyDollar = yyS[yypt-0 : yypt+1]
 		{
 			yyVAL.ordcols = nil
 		}
-	case 80:
+	case 83:
 		yyDollar = yyS[yypt-3 : yypt+1]
 		{
 			yyVAL.ordcols = yyDollar[3].ordcols
 		}
-	case 81:
+	case 84:
 		yyDollar = yyS[yypt-2 : yypt+1]
 		{
 			yyVAL.ordcols = []*OrdCol{{sel: yyDollar[1].col, cmp: yyDollar[2].opt_ord}}
 		}
-	case 82:
+	case 85:
 		yyDollar = yyS[yypt-4 : yypt+1]
 		{
 			yyVAL.ordcols = append(yyDollar[1].ordcols, &OrdCol{sel: yyDollar[3].col, cmp: yyDollar[4].opt_ord})
 		}
-	case 83:
+	case 86:
 		yyDollar = yyS[yypt-0 : yypt+1]
 		{
 			yyVAL.opt_ord = GreaterOrEqualTo
 		}
-	case 84:
+	case 87:
 		yyDollar = yyS[yypt-1 : yypt+1]
 		{
 			yyVAL.opt_ord = GreaterOrEqualTo
 		}
-	case 85:
+	case 88:
 		yyDollar = yyS[yypt-1 : yypt+1]
 		{
 			yyVAL.opt_ord = LowerOrEqualTo
 		}
-	case 86:
+	case 89:
 		yyDollar = yyS[yypt-0 : yypt+1]
 		{
 			yyVAL.id = ""
 		}
-	case 87:
+	case 90:
 		yyDollar = yyS[yypt-2 : yypt+1]
 		{
 			yyVAL.id = yyDollar[2].id
 		}
-	case 88:
+	case 91:
 		yyDollar = yyS[yypt-1 : yypt+1]
 		{
 			yyVAL.boolExp = yyDollar[1].sel
 		}
-	case 89:
+	case 92:
 		yyDollar = yyS[yypt-1 : yypt+1]
 		{
 			yyVAL.boolExp = yyDollar[1].value
 		}
-	case 90:
+	case 93:
 		yyDollar = yyS[yypt-1 : yypt+1]
 		{
 			yyVAL.boolExp = yyDollar[1].binExp
 		}
-	case 91:
+	case 94:
 		yyDollar = yyS[yypt-2 : yypt+1]
 		{
 			yyVAL.boolExp = &NotBoolExp{exp: yyDollar[2].boolExp}
 		}
-	case 92:
+	case 95:
 		yyDollar = yyS[yypt-2 : yypt+1]
 		{
 			yyVAL.boolExp = &NumExp{left: &Number{val: uint64(0)}, op: SUBSOP, right: yyDollar[2].boolExp}
 		}
-	case 93:
+	case 96:
 		yyDollar = yyS[yypt-3 : yypt+1]
 		{
 			yyVAL.boolExp = yyDollar[2].boolExp
 		}
-	case 94:
+	case 97:
 		yyDollar = yyS[yypt-3 : yypt+1]
 		{
 			yyVAL.boolExp = &LikeBoolExp{sel: yyDollar[1].sel, pattern: yyDollar[3].str}
 		}
-	case 95:
+	case 98:
 		yyDollar = yyS[yypt-4 : yypt+1]
 		{
 			yyVAL.boolExp = &ExistsBoolExp{q: (yyDollar[3].stmt).(*SelectStmt)}
 		}
-	case 96:
+	case 99:
 		yyDollar = yyS[yypt-3 : yypt+1]
 		{
 			yyVAL.binExp = &NumExp{left: yyDollar[1].boolExp, op: ADDOP, right: yyDollar[3].boolExp}
 		}
-	case 97:
+	case 100:
 		yyDollar = yyS[yypt-3 : yypt+1]
 		{
 			yyVAL.binExp = &NumExp{left: yyDollar[1].boolExp, op: SUBSOP, right: yyDollar[3].boolExp}
 		}
-	case 98:
+	case 101:
 		yyDollar = yyS[yypt-3 : yypt+1]
 		{
 			yyVAL.binExp = &NumExp{left: yyDollar[1].boolExp, op: DIVOP, right: yyDollar[3].boolExp}
 		}
-	case 99:
+	case 102:
 		yyDollar = yyS[yypt-3 : yypt+1]
 		{
 			yyVAL.binExp = &NumExp{left: yyDollar[1].boolExp, op: MULTOP, right: yyDollar[3].boolExp}
 		}
-	case 100:
+	case 103:
 		yyDollar = yyS[yypt-3 : yypt+1]
 		{
 			yyVAL.binExp = &BinBoolExp{left: yyDollar[1].boolExp, op: yyDollar[2].logicOp, right: yyDollar[3].boolExp}
 		}
-	case 101:
+	case 104:
 		yyDollar = yyS[yypt-3 : yypt+1]
 		{
 			yyVAL.binExp = &CmpBoolExp{left: yyDollar[1].boolExp, op: yyDollar[2].cmpOp, right: yyDollar[3].boolExp}
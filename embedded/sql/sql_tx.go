@@ -0,0 +1,499 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package sql
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/embedded/tbtree"
+)
+
+// IsolationLevel determines which committed data the reads of an interactive transaction observe
+// and whether conflicts are checked when it's committed
+type IsolationLevel int
+
+const (
+	// SnapshotIsolation resolves every read against the snapshot taken when the transaction was opened,
+	// the commit fails with ErrSerializationConflict if any written key was updated by a concurrent transaction
+	SnapshotIsolation IsolationLevel = iota
+	// ReadCommitted resolves each query against the latest committed data, writes are committed without conflict checks
+	ReadCommitted
+)
+
+// SQLTx is an interactive transaction. Its writes are buffered and atomically committed as a single store transaction,
+// queries observe them over the snapshot of the transaction
+type SQLTx struct {
+	e *Engine
+
+	isolation IsolationLevel
+
+	snapshot   *store.Snapshot
+	implicitDB *Database
+
+	entries    []*store.KV
+	savepoints []*savepoint
+
+	closed bool
+
+	mutex sync.Mutex
+}
+
+type savepoint struct {
+	name       string
+	entries    int
+	implicitDB *Database
+}
+
+// NewTx opens an interactive transaction reading from a snapshot which includes every committed transaction
+func (e *Engine) NewTx(isolation IsolationLevel) (*SQLTx, error) {
+	if isolation != SnapshotIsolation && isolation != ReadCommitted {
+		return nil, ErrInvalidIsolationLevel
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.closed {
+		return nil, ErrAlreadyClosed
+	}
+
+	snap, err := e.latestSnapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLTx{
+		e:          e,
+		isolation:  isolation,
+		snapshot:   snap,
+		implicitDB: e.implicitDB,
+	}, nil
+}
+
+func (e *Engine) latestSnapshot() (*store.Snapshot, error) {
+	lastTxID, _ := e.dataStore.Alh()
+	err := e.dataStore.WaitForIndexingUpto(lastTxID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.dataStore.SnapshotSince(math.MaxUint64)
+}
+
+// Isolation returns the isolation level of the transaction
+func (tx *SQLTx) Isolation() IsolationLevel {
+	return tx.isolation
+}
+
+// SnapshotTx returns the id of the latest transaction visible to the reads of this transaction.
+// Under ReadCommitted it corresponds to the snapshot used by the latest query
+func (tx *SQLTx) SnapshotTx() uint64 {
+	tx.mutex.Lock()
+	defer tx.mutex.Unlock()
+
+	return tx.snapshot.Ts()
+}
+
+func (tx *SQLTx) ExecStmt(sql string, params map[string]interface{}) error {
+	return tx.Exec(strings.NewReader(sql), params)
+}
+
+func (tx *SQLTx) Exec(sql io.ByteReader, params map[string]interface{}) error {
+	stmts, err := Parse(sql)
+	if err != nil {
+		return err
+	}
+
+	return tx.ExecPreparedStmts(stmts, params)
+}
+
+// ExecPreparedStmts compiles the given statements and appends the resulting entries to the write set of the transaction.
+// Only DML and savepoint statements are accepted, entries are visible to the queries of the transaction and to other
+// transactions once it's committed. Statements are applied atomically, the transaction is left unchanged if any of them fails
+func (tx *SQLTx) ExecPreparedStmts(stmts []SQLStmt, params map[string]interface{}) error {
+	if len(stmts) == 0 {
+		return ErrIllegalArguments
+	}
+
+	if includesDDL(stmts) {
+		return ErrDDLNotSupportedInTx
+	}
+
+	tx.mutex.Lock()
+	defer tx.mutex.Unlock()
+
+	if tx.closed {
+		return ErrTxAlreadyClosed
+	}
+
+	tx.e.catalogRWMux.RLock()
+	defer tx.e.catalogRWMux.RUnlock()
+
+	implicitDB := tx.implicitDB
+	entries := tx.entries
+	savepoints := append([]*savepoint(nil), tx.savepoints...)
+
+	for _, stmt := range stmts {
+		switch s := stmt.(type) {
+		case *SelectStmt:
+			{
+				return ErrExpectingDMLStmt
+			}
+		case *SavepointStmt:
+			{
+				savepoints = append(savepoints, &savepoint{name: s.name, entries: len(entries), implicitDB: implicitDB})
+			}
+		case *RollbackToSavepointStmt:
+			{
+				i, err := lookupSavepoint(savepoints, s.name)
+				if err != nil {
+					return err
+				}
+
+				sp := savepoints[i]
+
+				// capacity is limited so further appends don't overwrite the current write set
+				entries = entries[:sp.entries:sp.entries]
+				implicitDB = sp.implicitDB
+				savepoints = savepoints[:i+1]
+			}
+		case *ReleaseSavepointStmt:
+			{
+				i, err := lookupSavepoint(savepoints, s.name)
+				if err != nil {
+					return err
+				}
+
+				savepoints = savepoints[:i]
+			}
+		default:
+			{
+				_, des, db, err := stmt.CompileUsing(tx.e, implicitDB, params)
+				if err != nil {
+					return err
+				}
+
+				implicitDB = db
+				entries = append(entries, des...)
+			}
+		}
+	}
+
+	// rows inserted over rows already written by the transaction are rejected right away
+	_, err := newWriteSet(entries)
+	if err != nil {
+		return err
+	}
+
+	tx.implicitDB = implicitDB
+	tx.entries = entries
+	tx.savepoints = savepoints
+
+	return nil
+}
+
+// lookupSavepoint returns the position of the latest savepoint with the given name
+func lookupSavepoint(savepoints []*savepoint, name string) (int, error) {
+	for i := len(savepoints) - 1; i >= 0; i-- {
+		if savepoints[i].name == name {
+			return i, nil
+		}
+	}
+
+	return -1, ErrSavepointDoesNotExist
+}
+
+func (tx *SQLTx) QueryStmt(sql string, params map[string]interface{}) (RowReader, error) {
+	return tx.Query(strings.NewReader(sql), params)
+}
+
+func (tx *SQLTx) Query(sql io.ByteReader, params map[string]interface{}) (RowReader, error) {
+	stmts, err := Parse(sql)
+	if err != nil {
+		return nil, err
+	}
+	if len(stmts) > 1 {
+		return nil, ErrExpectingDQLStmt
+	}
+
+	stmt, ok := stmts[0].(*SelectStmt)
+	if !ok {
+		return nil, ErrExpectingDQLStmt
+	}
+
+	return tx.QueryPreparedStmt(stmt, params)
+}
+
+// QueryPreparedStmt resolves the query against the snapshot of the transaction, with the writes buffered by the
+// transaction on top of it
+func (tx *SQLTx) QueryPreparedStmt(stmt *SelectStmt, params map[string]interface{}) (RowReader, error) {
+	if stmt == nil {
+		return nil, ErrIllegalArguments
+	}
+
+	tx.mutex.Lock()
+	defer tx.mutex.Unlock()
+
+	if tx.closed {
+		return nil, ErrTxAlreadyClosed
+	}
+
+	_, _, _, err := stmt.CompileUsing(tx.e, tx.implicitDB, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if tx.isolation == ReadCommitted {
+		err = tx.renewSnapshot()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	writes, err := newWriteSet(tx.entries)
+	if err != nil {
+		return nil, err
+	}
+
+	return stmt.Resolve(tx.e, tx.implicitDB, tx.snapshot, writes, params, nil)
+}
+
+// renewSnapshot replaces the snapshot of the transaction with one including every committed transaction.
+// The current snapshot is kept while it has readers in use
+func (tx *SQLTx) renewSnapshot() error {
+	err := tx.snapshot.Close()
+	if err == tbtree.ErrReadersNotClosed {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	tx.snapshot, err = tx.e.latestSnapshot()
+
+	return err
+}
+
+// Commit atomically commits the buffered writes. No store transaction is created when nothing was written
+func (tx *SQLTx) Commit(waitForIndexing bool) (*store.TxMetadata, error) {
+	tx.mutex.Lock()
+	defer tx.mutex.Unlock()
+
+	if tx.closed {
+		return nil, ErrTxAlreadyClosed
+	}
+
+	tx.closed = true
+
+	snapshotTx := tx.snapshot.Ts()
+
+	err := tx.snapshot.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tx.entries) == 0 {
+		return nil, nil
+	}
+
+	writes, err := newWriteSet(tx.entries)
+	if err != nil {
+		return nil, err
+	}
+
+	tx.e.catalogRWMux.RLock()
+	defer tx.e.catalogRWMux.RUnlock()
+
+	tx.e.commitMutex.Lock()
+	defer tx.e.commitMutex.Unlock()
+
+	if tx.isolation == SnapshotIsolation {
+		err = tx.checkConflicts(writes, snapshotTx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return tx.e.dataStore.Commit(writes.entries, waitForIndexing)
+}
+
+// checkConflicts returns ErrSerializationConflict if any of the keys written by the transaction
+// was updated after the given tx. It must be called while holding the commit mutex
+func (tx *SQLTx) checkConflicts(writes *writeSet, snapshotTx uint64) error {
+	lastTxID, _ := tx.e.dataStore.Alh()
+	if lastTxID <= snapshotTx {
+		return nil
+	}
+
+	err := tx.e.dataStore.WaitForIndexingUpto(lastTxID, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, kv := range writes.entries {
+		_, ktx, _, err := tx.e.dataStore.Get(kv.Key)
+		if err == store.ErrKeyNotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if ktx > snapshotTx {
+			return ErrSerializationConflict
+		}
+	}
+
+	return nil
+}
+
+// ValidateWrites checks the buffered writes can be committed and returns them, without committing them
+func (tx *SQLTx) ValidateWrites() ([]*store.KV, error) {
+	tx.mutex.Lock()
+	defer tx.mutex.Unlock()
+
+	if tx.closed {
+		return nil, ErrTxAlreadyClosed
+	}
+
+	if len(tx.entries) == 0 {
+		return nil, nil
+	}
+
+	writes, err := newWriteSet(tx.entries)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.e.dataStore.ValidateEntries(writes.entries)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]*store.KV(nil), writes.entries...), nil
+}
+
+// Cancel discards the buffered writes and releases the snapshot of the transaction
+func (tx *SQLTx) Cancel() error {
+	tx.mutex.Lock()
+	defer tx.mutex.Unlock()
+
+	if tx.closed {
+		return ErrTxAlreadyClosed
+	}
+
+	tx.closed = true
+	tx.entries = nil
+	tx.savepoints = nil
+
+	return tx.snapshot.Close()
+}
+
+// writeSet holds the latest write of each key buffered by a transaction
+type writeSet struct {
+	// entries in the order their keys were first written, as they are committed
+	entries []*store.KV
+	// entries sorted by key, as they are read
+	sorted []*store.KV
+}
+
+// newWriteSet keeps the latest of the entries written to each key, so rows upserted more than once are committed
+// with their last values. Entries written to keys required to be unique, as inserted rows, must be the first ones
+func newWriteSet(entries []*store.KV) (*writeSet, error) {
+	latest := make(map[string]int, len(entries))
+
+	var deduped []*store.KV
+
+	for _, e := range entries {
+		i, ok := latest[string(e.Key)]
+		if !ok {
+			latest[string(e.Key)] = len(deduped)
+			deduped = append(deduped, e)
+			continue
+		}
+
+		if e.Unique {
+			return nil, store.ErrKeyAlreadyExists
+		}
+
+		// the key stays unique if it was first inserted by the transaction
+		deduped[i] = &store.KV{Key: e.Key, Value: e.Value, Unique: deduped[i].Unique}
+	}
+
+	sorted := append([]*store.KV(nil), deduped...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i].Key, sorted[j].Key) < 0
+	})
+
+	return &writeSet{entries: deduped, sorted: sorted}, nil
+}
+
+// get returns the value written to the key, if any
+func (ws *writeSet) get(key []byte) ([]byte, bool) {
+	if ws == nil {
+		return nil, false
+	}
+
+	i := sort.Search(len(ws.sorted), func(i int) bool {
+		return bytes.Compare(ws.sorted[i].Key, key) >= 0
+	})
+
+	if i < len(ws.sorted) && bytes.Equal(ws.sorted[i].Key, key) {
+		return ws.sorted[i].Value, true
+	}
+
+	return nil, false
+}
+
+// within returns the entries a key reader with the given spec would read, in the same order
+func (ws *writeSet) within(spec *store.KeyReaderSpec) []*store.KV {
+	if ws == nil {
+		return nil
+	}
+
+	var entries []*store.KV
+
+	for _, e := range ws.sorted {
+		if !bytes.HasPrefix(e.Key, spec.Prefix) {
+			continue
+		}
+
+		cmp := bytes.Compare(e.Key, spec.SeekKey)
+		if spec.DescOrder {
+			cmp = -cmp
+		}
+
+		if cmp < 0 || (cmp == 0 && !spec.InclusiveSeek) {
+			continue
+		}
+
+		entries = append(entries, e)
+	}
+
+	if spec.DescOrder {
+		for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+			entries[i], entries[j] = entries[j], entries[i]
+		}
+	}
+
+	return entries
+}
@@ -0,0 +1,412 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package sql
+
+import (
+	"os"
+	"testing"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/stretchr/testify/require"
+)
+
+func countRows(t *testing.T, r RowReader) int {
+	defer r.Close()
+
+	n := 0
+
+	for {
+		_, err := r.Read()
+		if err == ErrNoMoreRows {
+			return n
+		}
+		require.NoError(t, err)
+		n++
+	}
+}
+
+func TestSQLTx(t *testing.T) {
+	catalogStore, err := store.Open("catalog_tx", store.DefaultOptions())
+	require.NoError(t, err)
+	defer os.RemoveAll("catalog_tx")
+
+	dataStore, err := store.Open("sqldata_tx", store.DefaultOptions())
+	require.NoError(t, err)
+	defer os.RemoveAll("sqldata_tx")
+
+	engine, err := NewEngine(catalogStore, dataStore, prefix)
+	require.NoError(t, err)
+
+	_, _, err = engine.ExecStmt("CREATE DATABASE db1", nil, true)
+	require.NoError(t, err)
+
+	err = engine.UseDatabase("db1")
+	require.NoError(t, err)
+
+	_, _, err = engine.ExecStmt("CREATE TABLE table1 (id INTEGER, title VARCHAR, PRIMARY KEY id)", nil, true)
+	require.NoError(t, err)
+
+	_, dtxs, err := engine.ExecStmt("INSERT INTO table1 (id, title) VALUES (1, 'title1')", nil, true)
+	require.NoError(t, err)
+
+	tx, err := engine.NewTx(SnapshotIsolation)
+	require.NoError(t, err)
+	require.Equal(t, dtxs[0].ID, tx.SnapshotTx())
+
+	r, err := tx.QueryStmt("SELECT id FROM table1", nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, countRows(t, r))
+
+	_, _, err = engine.ExecStmt("INSERT INTO table1 (id, title) VALUES (2, 'title2')", nil, true)
+	require.NoError(t, err)
+
+	err = tx.ExecStmt("INSERT INTO table1 (id, title) VALUES (3, 'title3')", nil)
+	require.NoError(t, err)
+
+	err = tx.ExecStmt("CREATE TABLE table2 (id INTEGER, PRIMARY KEY id)", nil)
+	require.Equal(t, ErrDDLNotSupportedInTx, err)
+
+	err = tx.ExecStmt("SELECT id FROM table1", nil)
+	require.Equal(t, ErrExpectingDMLStmt, err)
+
+	_, err = tx.QueryStmt("INSERT INTO table1 (id, title) VALUES (4, 'title4')", nil)
+	require.Equal(t, ErrExpectingDQLStmt, err)
+
+	// buffered writes are visible within the transaction, concurrent ones are not
+	r, err = tx.QueryStmt("SELECT id FROM table1", nil)
+	require.NoError(t, err)
+	require.Equal(t, 2, countRows(t, r))
+
+	txmd, err := tx.Commit(true)
+	require.NoError(t, err)
+	require.NotNil(t, txmd)
+	require.Equal(t, 1, txmd.NEntries)
+
+	_, err = tx.Commit(true)
+	require.Equal(t, ErrTxAlreadyClosed, err)
+
+	err = tx.ExecStmt("INSERT INTO table1 (id, title) VALUES (4, 'title4')", nil)
+	require.Equal(t, ErrTxAlreadyClosed, err)
+
+	_, err = tx.QueryStmt("SELECT id FROM table1", nil)
+	require.Equal(t, ErrTxAlreadyClosed, err)
+
+	r, err = engine.QueryStmt("SELECT id FROM table1", nil, true)
+	require.NoError(t, err)
+	require.Equal(t, 3, countRows(t, r))
+
+	tx, err = engine.NewTx(SnapshotIsolation)
+	require.NoError(t, err)
+
+	err = tx.ExecStmt("INSERT INTO table1 (id, title) VALUES (4, 'title4')", nil)
+	require.NoError(t, err)
+
+	err = tx.Cancel()
+	require.NoError(t, err)
+
+	err = tx.Cancel()
+	require.Equal(t, ErrTxAlreadyClosed, err)
+
+	tx, err = engine.NewTx(SnapshotIsolation)
+	require.NoError(t, err)
+
+	txmd, err = tx.Commit(true)
+	require.NoError(t, err)
+	require.Nil(t, txmd)
+
+	r, err = engine.QueryStmt("SELECT id FROM table1", nil, true)
+	require.NoError(t, err)
+	require.Equal(t, 3, countRows(t, r))
+
+	err = engine.Close()
+	require.NoError(t, err)
+
+	_, err = engine.NewTx(SnapshotIsolation)
+	require.Equal(t, ErrAlreadyClosed, err)
+}
+
+func TestSQLTxWriteSet(t *testing.T) {
+	catalogStore, err := store.Open("catalog_txws", store.DefaultOptions())
+	require.NoError(t, err)
+	defer os.RemoveAll("catalog_txws")
+
+	dataStore, err := store.Open("sqldata_txws", store.DefaultOptions())
+	require.NoError(t, err)
+	defer os.RemoveAll("sqldata_txws")
+
+	engine, err := NewEngine(catalogStore, dataStore, prefix)
+	require.NoError(t, err)
+	defer engine.Close()
+
+	_, _, err = engine.ExecStmt("CREATE DATABASE db1", nil, true)
+	require.NoError(t, err)
+
+	err = engine.UseDatabase("db1")
+	require.NoError(t, err)
+
+	_, _, err = engine.ExecStmt("CREATE TABLE table1 (id INTEGER, title VARCHAR, PRIMARY KEY id)", nil, true)
+	require.NoError(t, err)
+
+	_, _, err = engine.ExecStmt("INSERT INTO table1 (id, title) VALUES (2, 'title2')", nil, true)
+	require.NoError(t, err)
+
+	readTitles := func(r RowReader) map[uint64]string {
+		defer r.Close()
+
+		titles := make(map[uint64]string)
+
+		for {
+			row, err := r.Read()
+			if err == ErrNoMoreRows {
+				return titles
+			}
+			require.NoError(t, err)
+
+			id := row.Values[EncodeSelector("", "db1", "table1", "id")].Value().(uint64)
+			titles[id] = row.Values[EncodeSelector("", "db1", "table1", "title")].Value().(string)
+		}
+	}
+
+	t.Run("rows upserted twice should be committed with their last values", func(t *testing.T) {
+		tx, err := engine.NewTx(SnapshotIsolation)
+		require.NoError(t, err)
+
+		err = tx.ExecStmt("UPSERT INTO table1 (id, title) VALUES (1, 'first')", nil)
+		require.NoError(t, err)
+
+		err = tx.ExecStmt("UPSERT INTO table1 (id, title) VALUES (1, 'second')", nil)
+		require.NoError(t, err)
+
+		txmd, err := tx.Commit(true)
+		require.NoError(t, err)
+		require.Equal(t, 1, txmd.NEntries)
+
+		r, err := engine.QueryStmt("SELECT id, title FROM table1 WHERE id = 1", nil, true)
+		require.NoError(t, err)
+		require.Equal(t, map[uint64]string{1: "second"}, readTitles(r))
+	})
+
+	t.Run("rows written by the transaction should be read by its queries", func(t *testing.T) {
+		tx, err := engine.NewTx(SnapshotIsolation)
+		require.NoError(t, err)
+
+		err = tx.ExecStmt("INSERT INTO table1 (id, title) VALUES (3, 'title3'); UPSERT INTO table1 (id, title) VALUES (2, 'updated')", nil)
+		require.NoError(t, err)
+
+		r, err := tx.QueryStmt("SELECT id, title FROM table1", nil)
+		require.NoError(t, err)
+		require.Equal(t, map[uint64]string{1: "second", 2: "updated", 3: "title3"}, readTitles(r))
+
+		r, err = tx.QueryStmt("SELECT id, title FROM table1 WHERE id = 3", nil)
+		require.NoError(t, err)
+		require.Equal(t, map[uint64]string{3: "title3"}, readTitles(r))
+
+		r, err = tx.QueryStmt("SELECT id FROM table1 ORDER BY id DESC", nil)
+		require.NoError(t, err)
+
+		for _, id := range []uint64{3, 2, 1} {
+			row, err := r.Read()
+			require.NoError(t, err)
+			require.Equal(t, id, row.Values[EncodeSelector("", "db1", "table1", "id")].Value())
+		}
+
+		_, err = r.Read()
+		require.Equal(t, ErrNoMoreRows, err)
+
+		err = r.Close()
+		require.NoError(t, err)
+
+		// rows can't be inserted over rows written by the transaction
+		err = tx.ExecStmt("INSERT INTO table1 (id, title) VALUES (3, 'title3')", nil)
+		require.Equal(t, store.ErrKeyAlreadyExists, err)
+
+		// other transactions don't observe the buffered writes
+		r, err = engine.QueryStmt("SELECT id, title FROM table1", nil, true)
+		require.NoError(t, err)
+		require.Equal(t, map[uint64]string{1: "second", 2: "title2"}, readTitles(r))
+
+		_, err = tx.Commit(true)
+		require.NoError(t, err)
+
+		r, err = engine.QueryStmt("SELECT id, title FROM table1", nil, true)
+		require.NoError(t, err)
+		require.Equal(t, map[uint64]string{1: "second", 2: "updated", 3: "title3"}, readTitles(r))
+	})
+}
+
+func TestSQLTxSavepoints(t *testing.T) {
+	catalogStore, err := store.Open("catalog_txsp", store.DefaultOptions())
+	require.NoError(t, err)
+	defer os.RemoveAll("catalog_txsp")
+
+	dataStore, err := store.Open("sqldata_txsp", store.DefaultOptions())
+	require.NoError(t, err)
+	defer os.RemoveAll("sqldata_txsp")
+
+	engine, err := NewEngine(catalogStore, dataStore, prefix)
+	require.NoError(t, err)
+	defer engine.Close()
+
+	_, _, err = engine.ExecStmt("CREATE DATABASE db1", nil, true)
+	require.NoError(t, err)
+
+	err = engine.UseDatabase("db1")
+	require.NoError(t, err)
+
+	_, _, err = engine.ExecStmt("CREATE TABLE table1 (id INTEGER, PRIMARY KEY id)", nil, true)
+	require.NoError(t, err)
+
+	_, _, err = engine.ExecStmt("SAVEPOINT sp1", nil, true)
+	require.Equal(t, ErrSavepointOutsideTx, err)
+
+	tx, err := engine.NewTx(SnapshotIsolation)
+	require.NoError(t, err)
+
+	err = tx.ExecStmt("INSERT INTO table1 (id) VALUES (1); SAVEPOINT sp1; INSERT INTO table1 (id) VALUES (2)", nil)
+	require.NoError(t, err)
+
+	err = tx.ExecStmt("SAVEPOINT sp2; INSERT INTO table1 (id) VALUES (3)", nil)
+	require.NoError(t, err)
+
+	err = tx.ExecStmt("ROLLBACK TO SAVEPOINT sp3", nil)
+	require.Equal(t, ErrSavepointDoesNotExist, err)
+
+	// failing statements leave the transaction unchanged
+	err = tx.ExecStmt("ROLLBACK TO SAVEPOINT sp1; INSERT INTO table1 (id) VALUES (4); INSERT INTO table2 (id) VALUES (5)", nil)
+	require.Equal(t, ErrTableDoesNotExist, err)
+	require.Len(t, tx.entries, 3)
+	require.Len(t, tx.savepoints, 2)
+
+	err = tx.ExecStmt("ROLLBACK TO SAVEPOINT sp2", nil)
+	require.NoError(t, err)
+	require.Len(t, tx.entries, 2)
+
+	err = tx.ExecStmt("ROLLBACK TO SAVEPOINT sp1; INSERT INTO table1 (id) VALUES (4)", nil)
+	require.NoError(t, err)
+	require.Len(t, tx.entries, 2)
+
+	err = tx.ExecStmt("ROLLBACK TO SAVEPOINT sp2", nil)
+	require.Equal(t, ErrSavepointDoesNotExist, err)
+
+	err = tx.ExecStmt("RELEASE SAVEPOINT sp1", nil)
+	require.NoError(t, err)
+
+	err = tx.ExecStmt("ROLLBACK TO SAVEPOINT sp1", nil)
+	require.Equal(t, ErrSavepointDoesNotExist, err)
+
+	_, err = tx.Commit(true)
+	require.NoError(t, err)
+
+	r, err := engine.QueryStmt("SELECT id FROM table1", nil, true)
+	require.NoError(t, err)
+
+	row, err := r.Read()
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), row.Values[EncodeSelector("", "db1", "table1", "id")].Value())
+
+	row, err = r.Read()
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), row.Values[EncodeSelector("", "db1", "table1", "id")].Value())
+
+	_, err = r.Read()
+	require.Equal(t, ErrNoMoreRows, err)
+
+	err = r.Close()
+	require.NoError(t, err)
+}
+
+func TestSQLTxIsolation(t *testing.T) {
+	catalogStore, err := store.Open("catalog_txiso", store.DefaultOptions())
+	require.NoError(t, err)
+	defer os.RemoveAll("catalog_txiso")
+
+	dataStore, err := store.Open("sqldata_txiso", store.DefaultOptions())
+	require.NoError(t, err)
+	defer os.RemoveAll("sqldata_txiso")
+
+	engine, err := NewEngine(catalogStore, dataStore, prefix)
+	require.NoError(t, err)
+	defer engine.Close()
+
+	_, _, err = engine.ExecStmt("CREATE DATABASE db1", nil, true)
+	require.NoError(t, err)
+
+	err = engine.UseDatabase("db1")
+	require.NoError(t, err)
+
+	_, _, err = engine.ExecStmt("CREATE TABLE table1 (id INTEGER, title VARCHAR, PRIMARY KEY id)", nil, true)
+	require.NoError(t, err)
+
+	_, err = engine.NewTx(IsolationLevel(10))
+	require.Equal(t, ErrInvalidIsolationLevel, err)
+
+	t.Run("snapshot isolation should detect write-write conflicts", func(t *testing.T) {
+		tx1, err := engine.NewTx(SnapshotIsolation)
+		require.NoError(t, err)
+		require.Equal(t, SnapshotIsolation, tx1.Isolation())
+
+		tx2, err := engine.NewTx(SnapshotIsolation)
+		require.NoError(t, err)
+
+		err = tx1.ExecStmt("UPSERT INTO table1 (id, title) VALUES (1, 'tx1')", nil)
+		require.NoError(t, err)
+
+		err = tx2.ExecStmt("UPSERT INTO table1 (id, title) VALUES (1, 'tx2')", nil)
+		require.NoError(t, err)
+
+		_, err = tx1.Commit(true)
+		require.NoError(t, err)
+
+		_, err = tx2.Commit(true)
+		require.Equal(t, ErrSerializationConflict, err)
+
+		tx3, err := engine.NewTx(SnapshotIsolation)
+		require.NoError(t, err)
+
+		_, _, err = engine.ExecStmt("UPSERT INTO table1 (id, title) VALUES (2, 'title2')", nil, true)
+		require.NoError(t, err)
+
+		// disjoint writes don't conflict
+		err = tx3.ExecStmt("UPSERT INTO table1 (id, title) VALUES (3, 'tx3')", nil)
+		require.NoError(t, err)
+
+		_, err = tx3.Commit(true)
+		require.NoError(t, err)
+	})
+
+	t.Run("read committed should observe concurrent commits", func(t *testing.T) {
+		tx, err := engine.NewTx(ReadCommitted)
+		require.NoError(t, err)
+
+		r, err := tx.QueryStmt("SELECT id FROM table1", nil)
+		require.NoError(t, err)
+		require.Equal(t, 3, countRows(t, r))
+
+		_, dtxs, err := engine.ExecStmt("UPSERT INTO table1 (id, title) VALUES (4, 'title4')", nil, true)
+		require.NoError(t, err)
+
+		err = tx.ExecStmt("UPSERT INTO table1 (id, title) VALUES (4, 'tx')", nil)
+		require.NoError(t, err)
+
+		r, err = tx.QueryStmt("SELECT id FROM table1", nil)
+		require.NoError(t, err)
+		require.Equal(t, dtxs[0].ID, tx.SnapshotTx())
+		require.Equal(t, 4, countRows(t, r))
+
+		_, err = tx.Commit(true)
+		require.NoError(t, err)
+	})
+}
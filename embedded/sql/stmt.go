@@ -124,6 +124,45 @@ func (stmt *TxStmt) CompileUsing(e *Engine, implicitDB *Database, params map[str
 	return ces, des, implicitDB, nil
 }
 
+// SavepointStmt marks the current state of the write set of an interactive transaction
+type SavepointStmt struct {
+	name string
+}
+
+func (stmt *SavepointStmt) isDDL() bool {
+	return false
+}
+
+func (stmt *SavepointStmt) CompileUsing(e *Engine, implicitDB *Database, params map[string]interface{}) (ces, des []*store.KV, db *Database, err error) {
+	return nil, nil, nil, ErrSavepointOutsideTx
+}
+
+// RollbackToSavepointStmt discards the writes made after the savepoint was established, which is kept
+type RollbackToSavepointStmt struct {
+	name string
+}
+
+func (stmt *RollbackToSavepointStmt) isDDL() bool {
+	return false
+}
+
+func (stmt *RollbackToSavepointStmt) CompileUsing(e *Engine, implicitDB *Database, params map[string]interface{}) (ces, des []*store.KV, db *Database, err error) {
+	return nil, nil, nil, ErrSavepointOutsideTx
+}
+
+// ReleaseSavepointStmt removes the savepoint, and the ones established after it, keeping the writes made since then
+type ReleaseSavepointStmt struct {
+	name string
+}
+
+func (stmt *ReleaseSavepointStmt) isDDL() bool {
+	return false
+}
+
+func (stmt *ReleaseSavepointStmt) CompileUsing(e *Engine, implicitDB *Database, params map[string]interface{}) (ces, des []*store.KV, db *Database, err error) {
+	return nil, nil, nil, ErrSavepointOutsideTx
+}
+
 type CreateDatabaseStmt struct {
 	DB string
 }
@@ -820,7 +859,7 @@ const (
 )
 
 type DataSource interface {
-	Resolve(e *Engine, implicitDB *Database, snap *store.Snapshot, params map[string]interface{}, ordCol *OrdCol) (RowReader, error)
+	Resolve(e *Engine, implicitDB *Database, snap *store.Snapshot, writes *writeSet, params map[string]interface{}, ordCol *OrdCol) (RowReader, error)
 	Alias() string
 }
 
@@ -845,6 +884,60 @@ func (stmt *SelectStmt) Limit() uint64 {
 	return stmt.limit
 }
 
+// AsBefore makes the query read its tables as they were before the given transaction,
+// tables with an explicit BEFORE TX clause are left untouched
+func (stmt *SelectStmt) AsBefore(txID uint64) {
+	asBefore(stmt.ds, txID)
+
+	for _, j := range stmt.joins {
+		asBefore(j.ds, txID)
+	}
+}
+
+func asBefore(ds DataSource, txID uint64) {
+	switch ds := ds.(type) {
+	case *TableRef:
+		if ds.asBefore == 0 {
+			ds.asBefore = txID
+		}
+	case *SelectStmt:
+		ds.AsBefore(txID)
+	}
+}
+
+func (stmt *SelectStmt) referencedTables(e *Engine, implicitDB *Database) ([]*Table, error) {
+	tables, err := referencedTables(stmt.ds, e, implicitDB)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, j := range stmt.joins {
+		jtables, err := referencedTables(j.ds, e, implicitDB)
+		if err != nil {
+			return nil, err
+		}
+
+		tables = append(tables, jtables...)
+	}
+
+	return tables, nil
+}
+
+func referencedTables(ds DataSource, e *Engine, implicitDB *Database) ([]*Table, error) {
+	switch ds := ds.(type) {
+	case *TableRef:
+		table, err := ds.referencedTable(e, implicitDB)
+		if err != nil {
+			return nil, err
+		}
+		return []*Table{table}, nil
+	case *SelectStmt:
+		return ds.referencedTables(e, implicitDB)
+	}
+
+	return nil, nil
+}
+
 func (stmt *SelectStmt) CompileUsing(e *Engine, implicitDB *Database, params map[string]interface{}) (ces, des []*store.KV, db *Database, err error) {
 	if stmt.distinct {
 		return nil, nil, nil, ErrNoSupported
@@ -887,20 +980,20 @@ func (stmt *SelectStmt) CompileUsing(e *Engine, implicitDB *Database, params map
 	return nil, nil, implicitDB, nil
 }
 
-func (stmt *SelectStmt) Resolve(e *Engine, implicitDB *Database, snap *store.Snapshot, params map[string]interface{}, ordCol *OrdCol) (RowReader, error) {
+func (stmt *SelectStmt) Resolve(e *Engine, implicitDB *Database, snap *store.Snapshot, writes *writeSet, params map[string]interface{}, ordCol *OrdCol) (RowReader, error) {
 	var orderByCol *OrdCol
 
 	if len(stmt.orderBy) > 0 {
 		orderByCol = stmt.orderBy[0]
 	}
 
-	rowReader, err := stmt.ds.Resolve(e, implicitDB, snap, params, orderByCol)
+	rowReader, err := stmt.ds.Resolve(e, implicitDB, snap, writes, params, orderByCol)
 	if err != nil {
 		return nil, err
 	}
 
 	if stmt.joins != nil {
-		rowReader, err = e.newJointRowReader(implicitDB, snap, params, rowReader, stmt.joins)
+		rowReader, err = e.newJointRowReader(implicitDB, snap, writes, params, rowReader, stmt.joins)
 		if err != nil {
 			return nil, err
 		}
@@ -986,7 +1079,7 @@ func (stmt *TableRef) referencedTable(e *Engine, implicitDB *Database) (*Table,
 	return table, nil
 }
 
-func (stmt *TableRef) Resolve(e *Engine, implicitDB *Database, snap *store.Snapshot, params map[string]interface{}, ordCol *OrdCol) (RowReader, error) {
+func (stmt *TableRef) Resolve(e *Engine, implicitDB *Database, snap *store.Snapshot, writes *writeSet, params map[string]interface{}, ordCol *OrdCol) (RowReader, error) {
 	if e == nil || snap == nil || (ordCol != nil && ordCol.sel == nil) {
 		return nil, ErrIllegalArguments
 	}
@@ -1042,7 +1135,7 @@ func (stmt *TableRef) Resolve(e *Engine, implicitDB *Database, snap *store.Snaps
 		asBefore = e.snapAsBeforeTx
 	}
 
-	return e.newRawRowReader(implicitDB, snap, table, asBefore, stmt.as, colName, cmp, initKeyVal)
+	return e.newRawRowReader(implicitDB, snap, writes, table, asBefore, stmt.as, colName, cmp, initKeyVal)
 }
 
 func (stmt *TableRef) Alias() string {
@@ -0,0 +1,95 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrArchivingDisabled = errors.New("archiving is disabled")
+
+// MaxArchiveCheckInterval is the maximum interval between checks for value-log segments to be archived
+const MaxArchiveCheckInterval = 1 * time.Hour
+
+type archivableAppendable interface {
+	Archive(olderThan time.Time) (int, error)
+}
+
+// ArchiveValueLogs moves the value-log segments not modified during the ArchiveAfter period into the archive location.
+// Archived segments remain readable. It returns the number of archived segments
+func (s *ImmuStore) ArchiveValueLogs() (int, error) {
+	if s.archivePath == "" {
+		return 0, ErrArchivingDisabled
+	}
+
+	olderThan := time.Now().Add(-s.archiveAfter)
+
+	archived := 0
+
+	for i := range s.vLogs {
+		vLog, err := s.fetchVLog(i+1, true)
+		if err != nil {
+			return archived, err
+		}
+
+		avLog, ok := vLog.(archivableAppendable)
+		if !ok {
+			s.releaseVLog(i + 1)
+			continue
+		}
+
+		n, err := avLog.Archive(olderThan)
+		s.releaseVLog(i + 1)
+
+		archived += n
+
+		if err != nil {
+			return archived, s.wrapAppendableErr(err, "archiving value log")
+		}
+	}
+
+	return archived, nil
+}
+
+func (s *ImmuStore) archiving() {
+	checkInterval := s.archiveAfter
+	if checkInterval > MaxArchiveCheckInterval {
+		checkInterval = MaxArchiveCheckInterval
+	}
+
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.archiveDone:
+			return
+		case <-ticker.C:
+			n, err := s.ArchiveValueLogs()
+			if err == ErrAlreadyClosed {
+				return
+			}
+			if err != nil {
+				s.notify(Error, true, "%s: while archiving value logs at '%s'", err, s.path)
+				continue
+			}
+			if n > 0 {
+				s.notify(Info, true, "%d value-log segments archived at '%s'", n, s.path)
+			}
+		}
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestImmudbStoreArchiveValueLogs(t *testing.T) {
+	defer os.RemoveAll("data_archiving")
+	defer os.RemoveAll("data_archive")
+
+	immuStore, err := Open("data_archiving", DefaultOptions().WithSynced(false).WithFileSize(16))
+	require.NoError(t, err)
+
+	_, err = immuStore.ArchiveValueLogs()
+	require.Equal(t, ErrArchivingDisabled, err)
+
+	err = immuStore.Close()
+	require.NoError(t, err)
+
+	opts := DefaultOptions().
+		WithSynced(false).
+		WithFileSize(16).
+		WithArchivePath("data_archive")
+
+	immuStore, err = Open("data_archiving", opts)
+	require.NoError(t, err)
+
+	txCount := 10
+
+	for i := 0; i < txCount; i++ {
+		_, err = immuStore.Commit([]*KV{{Key: []byte(fmt.Sprintf("key%d", i)), Value: []byte(fmt.Sprintf("value%d", i))}}, false)
+		require.NoError(t, err)
+	}
+
+	archived, err := immuStore.ArchiveValueLogs()
+	require.NoError(t, err)
+	require.Greater(t, archived, 0)
+
+	fis, err := ioutil.ReadDir(filepath.Join("data_archive", "data_archiving", "val_0"))
+	require.NoError(t, err)
+	require.Len(t, fis, archived)
+
+	tx := immuStore.NewTx()
+
+	for i := 0; i < txCount; i++ {
+		err = immuStore.ReadTx(uint64(i+1), tx)
+		require.NoError(t, err)
+
+		val, err := immuStore.ReadValue(tx, []byte(fmt.Sprintf("key%d", i)))
+		require.NoError(t, err)
+		require.Equal(t, []byte(fmt.Sprintf("value%d", i)), val)
+	}
+
+	err = immuStore.Close()
+	require.NoError(t, err)
+
+	_, err = immuStore.ArchiveValueLogs()
+	require.Equal(t, ErrAlreadyClosed, err)
+
+	immuStore, err = Open("data_archiving", opts.WithArchiveAfter(10*time.Millisecond))
+	require.NoError(t, err)
+
+	_, err = immuStore.Commit([]*KV{{Key: []byte("key"), Value: []byte("a value filling a segment")}}, false)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		fis, err := ioutil.ReadDir(filepath.Join("data_archive", "data_archiving", "val_0"))
+		require.NoError(t, err)
+		return len(fis) > archived
+	}, 5*time.Second, 10*time.Millisecond)
+
+	err = immuStore.Close()
+	require.NoError(t, err)
+}
@@ -0,0 +1,108 @@
+// +build faultinject
+
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrInjectedFault is returned by the operations made to fail through fault injection
+var ErrInjectedFault = errors.New("injected fault")
+
+// faults holds the faults to be injected, only available when building with the faultinject tag
+type faults struct {
+	mutex sync.Mutex
+
+	failSync      bool
+	corruptAppend bool
+	commitDelay   time.Duration
+}
+
+// FailNextSync makes the next fsync fail with ErrInjectedFault. Data is fsynced when calling Sync and,
+// if the store is synced, when committing a transaction
+func (s *ImmuStore) FailNextSync() {
+	s.faults.mutex.Lock()
+	defer s.faults.mutex.Unlock()
+
+	s.faults.failSync = true
+}
+
+// CorruptNextAppend flips a bit of the next value written into the value logs, the transaction is committed
+// but reading the value fails with ErrCorruptedData
+func (s *ImmuStore) CorruptNextAppend() {
+	s.faults.mutex.Lock()
+	defer s.faults.mutex.Unlock()
+
+	s.faults.corruptAppend = true
+}
+
+// DelayCommits makes every commit wait for the provided duration before being written, a zero duration disables it
+func (s *ImmuStore) DelayCommits(delay time.Duration) {
+	s.faults.mutex.Lock()
+	defer s.faults.mutex.Unlock()
+
+	s.faults.commitDelay = delay
+}
+
+// ResetFaults discards all the pending faults
+func (s *ImmuStore) ResetFaults() {
+	s.faults.mutex.Lock()
+	defer s.faults.mutex.Unlock()
+
+	s.faults.failSync = false
+	s.faults.corruptAppend = false
+	s.faults.commitDelay = 0
+}
+
+func (f *faults) syncFault() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.failSync {
+		f.failSync = false
+		return ErrInjectedFault
+	}
+	return nil
+}
+
+func (f *faults) appendFault(value []byte) []byte {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if !f.corruptAppend {
+		return value
+	}
+	f.corruptAppend = false
+
+	// the value is shared with the ongoing commit, which already hashed it
+	corrupted := make([]byte, len(value))
+	copy(corrupted, value)
+	corrupted[0] ^= 1
+
+	return corrupted
+}
+
+func (f *faults) delayCommit() {
+	f.mutex.Lock()
+	delay := f.commitDelay
+	f.mutex.Unlock()
+
+	time.Sleep(delay)
+}
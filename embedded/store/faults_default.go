@@ -0,0 +1,31 @@
+// +build !faultinject
+
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+// faults is empty unless building with the faultinject tag, thus no fault is ever injected
+type faults struct{}
+
+func (f *faults) syncFault() error {
+	return nil
+}
+
+func (f *faults) appendFault(value []byte) []byte {
+	return value
+}
+
+func (f *faults) delayCommit() {}
@@ -0,0 +1,90 @@
+// +build faultinject
+
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestImmudbStoreFaultInjection(t *testing.T) {
+	defer os.RemoveAll("data_faults")
+
+	immuStore, err := Open("data_faults", DefaultOptions())
+	require.NoError(t, err)
+	defer immuStore.Close()
+
+	_, err = immuStore.Commit([]*KV{{Key: []byte("key1"), Value: []byte("value1")}}, true)
+	require.NoError(t, err)
+
+	t.Run("fail next sync", func(t *testing.T) {
+		immuStore.FailNextSync()
+
+		err := immuStore.Sync()
+		require.Equal(t, ErrInjectedFault, err)
+
+		err = immuStore.Sync()
+		require.NoError(t, err)
+
+		immuStore.FailNextSync()
+
+		// the failed commit does not advance the store, the next one takes its place
+		_, err = immuStore.Commit([]*KV{{Key: []byte("key2"), Value: []byte("value2")}}, true)
+		require.Equal(t, ErrInjectedFault, err)
+
+		md, err := immuStore.Commit([]*KV{{Key: []byte("key2"), Value: []byte("value2")}}, true)
+		require.NoError(t, err)
+		require.Equal(t, uint64(2), md.ID)
+	})
+
+	t.Run("corrupt next append", func(t *testing.T) {
+		immuStore.CorruptNextAppend()
+
+		_, err := immuStore.Commit([]*KV{{Key: []byte("key3"), Value: []byte("value3")}}, true)
+		require.NoError(t, err)
+
+		_, _, _, err = immuStore.Get([]byte("key3"))
+		require.Equal(t, ErrCorruptedData, err)
+
+		_, err = immuStore.Commit([]*KV{{Key: []byte("key4"), Value: []byte("value4")}}, true)
+		require.NoError(t, err)
+
+		val, _, _, err := immuStore.Get([]byte("key4"))
+		require.NoError(t, err)
+		require.Equal(t, []byte("value4"), val)
+	})
+
+	t.Run("delay commits", func(t *testing.T) {
+		immuStore.DelayCommits(50 * time.Millisecond)
+
+		start := time.Now()
+		_, err := immuStore.Commit([]*KV{{Key: []byte("key5"), Value: []byte("value5")}}, true)
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, int64(time.Since(start)), int64(50*time.Millisecond))
+
+		immuStore.ResetFaults()
+
+		start = time.Now()
+		_, err = immuStore.Commit([]*KV{{Key: []byte("key6"), Value: []byte("value6")}}, true)
+		require.NoError(t, err)
+		require.Less(t, int64(time.Since(start)), int64(50*time.Millisecond))
+	})
+}
@@ -27,6 +27,7 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/codenotary/immudb/embedded/ahtree"
@@ -34,6 +35,7 @@ import (
 	"github.com/codenotary/immudb/embedded/appendable/multiapp"
 	"github.com/codenotary/immudb/embedded/appendable/singleapp"
 	"github.com/codenotary/immudb/embedded/cache"
+	"github.com/codenotary/immudb/embedded/hashing"
 	"github.com/codenotary/immudb/embedded/multierr"
 	"github.com/codenotary/immudb/embedded/tbtree"
 	"github.com/codenotary/immudb/embedded/watchers"
@@ -54,12 +56,15 @@ var ErrMaxConcurrencyLimitExceeded = errors.New("max concurrency limit exceeded"
 var ErrorPathIsNotADirectory = errors.New("path is not a directory")
 var ErrorCorruptedTxData = errors.New("tx data is corrupted")
 var ErrCorruptedData = errors.New("data is corrupted")
+var ErrCorruptedIndex = errors.New("index is corrupted")
 var ErrCorruptedCLog = errors.New("commit log is corrupted")
 var ErrTxSizeGreaterThanMaxTxSize = errors.New("tx size greater than max tx size")
 var ErrCorruptedAHtree = errors.New("appendable hash tree is corrupted")
 var ErrKeyNotFound = tbtree.ErrKeyNotFound
 var ErrKeyAlreadyExists = errors.New("key already exists")
 var ErrTxNotFound = errors.New("tx not found")
+var ErrUnexpectedTxID = errors.New("unexpected tx id")
+var ErrReplicaDivergence = errors.New("replicated tx diverges from the original one")
 var ErrNoMoreEntries = tbtree.ErrNoMoreEntries
 var ErrIllegalState = tbtree.ErrIllegalState
 var ErrOffsetOutOfRange = tbtree.ErrOffsetOutOfRange
@@ -89,6 +94,7 @@ const (
 	metaMaxKeyLen    = "MAX_KEY_LEN"
 	metaMaxValueLen  = "MAX_VALUE_LEN"
 	metaFileSize     = "FILE_SIZE"
+	metaHashAlg      = "HASH_ALGORITHM"
 )
 
 const indexDirname = "index"
@@ -136,6 +142,7 @@ type ImmuStore struct {
 	aht      *ahtree.AHtree
 	blBuffer chan ([sha256.Size]byte)
 	blErr    error
+	blWHub   *watchers.WatchersHub
 
 	wHub *watchers.WatchersHub
 
@@ -144,6 +151,26 @@ type ImmuStore struct {
 	closed bool
 	done   chan (struct{})
 
+	archivePath  string
+	archiveAfter time.Duration
+	archiveDone  chan (struct{})
+
+	paranoidReads  bool
+	paranoidTxPool sync.Pool
+	corruptedReads uint64
+
+	commitLatency int64
+
+	readaheadWindow int
+
+	compressionFormat int
+
+	keyFilter *keyFilter
+
+	hasher hashing.Hasher
+
+	faults faults
+
 	mutex sync.Mutex
 }
 
@@ -156,6 +183,8 @@ type KV struct {
 	Key    []byte
 	Value  []byte
 	Unique bool
+	// Incompressible hints the value should be stored without attempting compression
+	Incompressible bool
 }
 
 func (kv *KV) Digest() [sha256.Size]byte {
@@ -194,6 +223,8 @@ func Open(path string, opts *Options) (*ImmuStore, error) {
 	metadata.PutInt(metaMaxKeyLen, opts.MaxKeyLen)
 	metadata.PutInt(metaMaxValueLen, opts.MaxValueLen)
 	metadata.PutInt(metaFileSize, opts.FileSize)
+	// the algorithm is recorded, so stores are not mistaken as SHA-256 ones once others are supported
+	metadata.PutInt(metaHashAlg, int(hashing.DefaultAlgorithm))
 
 	appendableOpts := multiapp.DefaultOptions().
 		WithReadOnly(opts.ReadOnly).
@@ -208,6 +239,9 @@ func Open(path string, opts *Options) (*ImmuStore, error) {
 		appendableOpts.WithCompressionFormat(opts.CompressionFormat)
 		appendableOpts.WithCompresionLevel(opts.CompressionLevel)
 		appendableOpts.WithMaxOpenedFiles(opts.VLogMaxOpenedFiles)
+		if opts.ArchivePath != "" {
+			appendableOpts.WithArchivePath(filepath.Join(opts.ArchivePath, filepath.Base(path), fmt.Sprintf("val_%d", i)))
+		}
 		vLogPath := filepath.Join(path, fmt.Sprintf("val_%d", i))
 		vLog, err := multiapp.Open(vLogPath, appendableOpts)
 		if err != nil {
@@ -218,6 +252,7 @@ func Open(path string, opts *Options) (*ImmuStore, error) {
 
 	appendableOpts.WithFileExt("tx")
 	appendableOpts.WithCompressionFormat(appendable.NoCompression)
+	appendableOpts.WithArchivePath("")
 	appendableOpts.WithMaxOpenedFiles(opts.TxLogMaxOpenedFiles)
 	txLogPath := filepath.Join(path, "tx")
 	txLog, err := multiapp.Open(txLogPath, appendableOpts)
@@ -264,6 +299,17 @@ func OpenWith(path string, vLogs []appendable.Appendable, txLog, cLog appendable
 		return nil, ErrCorruptedCLog
 	}
 
+	// stores created before the hash algorithm was recorded use SHA-256
+	hashAlg, ok := metadata.GetInt(metaHashAlg)
+	if !ok {
+		hashAlg = int(hashing.SHA256)
+	}
+
+	hasher, err := hashing.HasherFor(hashing.Algorithm(hashAlg))
+	if err != nil {
+		return nil, err
+	}
+
 	cLogSize, err := cLog.Size()
 	if err != nil {
 		return nil, err
@@ -361,6 +407,18 @@ func OpenWith(path string, vLogs []appendable.Appendable, txLog, cLog appendable
 		return nil, err
 	}
 
+	// the format is recorded by the value-logs when created, opts only apply to new ones
+	compressionFormat := opts.CompressionFormat
+	if vLog, ok := vLogs[0].(interface{ CompressionFormat() int }); ok {
+		compressionFormat = vLog.CompressionFormat()
+	}
+
+	// raw value-log regions can not be served when values are compressed
+	readaheadWindow := opts.ReadaheadWindow
+	if compressionFormat != appendable.NoCompression {
+		readaheadWindow = 0
+	}
+
 	store := &ImmuStore{
 		path:               path,
 		log:                opts.log,
@@ -387,6 +445,7 @@ func OpenWith(path string, vLogs []appendable.Appendable, txLog, cLog appendable
 
 		aht:      aht,
 		blBuffer: blBuffer,
+		blWHub:   watchers.New(0, 1+opts.MaxWaitees),
 
 		wHub: watchers.New(0, 1+opts.MaxWaitees),
 
@@ -395,6 +454,23 @@ func OpenWith(path string, vLogs []appendable.Appendable, txLog, cLog appendable
 		_txbs: txbs,
 
 		done: make(chan struct{}),
+
+		archivePath:  opts.ArchivePath,
+		archiveAfter: opts.ArchiveAfter,
+
+		paranoidReads: opts.ParanoidReads,
+
+		readaheadWindow: readaheadWindow,
+
+		compressionFormat: compressionFormat,
+
+		keyFilter: newKeyFilter(opts.KeyFilterCapacity),
+
+		hasher: hasher,
+	}
+
+	store.paranoidTxPool.New = func() interface{} {
+		return store.NewTx()
 	}
 
 	err = store.wHub.DoneUpto(committedTxID)
@@ -414,7 +490,8 @@ func OpenWith(path string, vLogs []appendable.Appendable, txLog, cLog appendable
 		WithMaxNodeSize(opts.IndexOpts.MaxNodeSize).
 		WithRenewSnapRootAfter(opts.IndexOpts.RenewSnapRootAfter).
 		WithCompactionThld(opts.IndexOpts.CompactionThld).
-		WithDelayDuringCompaction(opts.IndexOpts.DelayDuringCompaction)
+		WithDelayDuringCompaction(opts.IndexOpts.DelayDuringCompaction).
+		WithWarmUp(opts.IndexOpts.WarmUp)
 
 	indexPath := filepath.Join(store.path, indexDirname)
 
@@ -434,10 +511,21 @@ func OpenWith(path string, vLogs []appendable.Appendable, txLog, cLog appendable
 		return nil, err
 	}
 
+	err = store.blWHub.DoneUpto(store.aht.Size())
+	if err != nil {
+		store.Close()
+		return nil, err
+	}
+
 	if store.blBuffer != nil {
 		go store.binaryLinking()
 	}
 
+	if store.archivePath != "" && store.archiveAfter > 0 && !store.readOnly {
+		store.archiveDone = make(chan struct{})
+		go store.archiving()
+	}
+
 	return store, nil
 }
 
@@ -482,12 +570,16 @@ func (s *ImmuStore) ExistKeyWith(prefix []byte, neq []byte, smaller bool) (bool,
 }
 
 func (s *ImmuStore) Get(key []byte) (value []byte, tx uint64, hc uint64, err error) {
+	if !s.keyFilter.mayContain(key) {
+		return nil, 0, 0, ErrKeyNotFound
+	}
+
 	indexedVal, tx, hc, err := s.indexer.Get(key)
 	if err != nil {
 		return nil, 0, 0, err
 	}
 
-	valRef, err := s.valueRefFrom(indexedVal)
+	valRef, err := s.valueRefFrom(key, tx, indexedVal)
 	if err != nil {
 		return nil, 0, 0, err
 	}
@@ -541,8 +633,12 @@ func (s *ImmuStore) binaryLinking() {
 				if err != nil {
 					s.SetBlErr(err)
 					s.log.Errorf("Binary linking at '%s' stopped due to error: %v", s.path, err)
+					// wake up waiters, they will get the binary linking error
+					s.blWHub.Close()
 					return
 				}
+
+				s.blWHub.DoneUpto(s.aht.Size())
 			}
 		case <-s.done:
 			{
@@ -559,6 +655,17 @@ func (s *ImmuStore) SetBlErr(err error) {
 	s.blErr = err
 }
 
+// HashAlgorithm returns the algorithm recorded when the store was created
+func (s *ImmuStore) HashAlgorithm() hashing.Algorithm {
+	return s.hasher.Algorithm()
+}
+
+// CompressionFormat returns the format values are compressed with in the value-logs, values are hashed before
+// being compressed
+func (s *ImmuStore) CompressionFormat() int {
+	return s.compressionFormat
+}
+
 func (s *ImmuStore) Alh() (uint64, [sha256.Size]byte) {
 	txID, txAlh, _ := s.commitState()
 	return txID, txAlh
@@ -585,6 +692,8 @@ func (s *ImmuStore) syncBinaryLinking() error {
 	}
 	defer s.releaseAllocTx(tx)
 
+	recovery := newRecoveryTracker(s, RecoveryBinaryLinking, s.aht.Size(), s.committedTxID)
+
 	txReader, err := s.NewTxReader(s.aht.Size()+1, false, tx)
 	if err != nil {
 		return err
@@ -601,6 +710,8 @@ func (s *ImmuStore) syncBinaryLinking() error {
 
 		alh := tx.Alh
 		s.aht.Append(alh[:])
+
+		recovery.progress(tx.ID)
 	}
 
 	s.log.Infof("Binary Linking up to date at '%s'", s.path)
@@ -754,7 +865,16 @@ func (s *ImmuStore) appendData(entries []*KV, donec chan<- appendableResult) {
 			continue
 		}
 
-		voff, _, err := vLog.Append(entries[i].Value)
+		var voff int64
+		var err error
+
+		value := s.faults.appendFault(entries[i].Value)
+
+		if entries[i].Incompressible {
+			voff, _, err = vLog.AppendUncompressed(value)
+		} else {
+			voff, _, err = vLog.Append(value)
+		}
 		if err != nil {
 			donec <- appendableResult{nil, err}
 			return
@@ -772,6 +892,47 @@ func (s *ImmuStore) appendData(entries []*KV, donec chan<- appendableResult) {
 }
 
 func (s *ImmuStore) Commit(entries []*KV, waitForIndexing bool) (*TxMetadata, error) {
+	return s.commitEntries(entries, nil, waitForIndexing)
+}
+
+// ReplicateTx commits entries as the transaction described by txMetadata, as exported from another store.
+// The original timestamp and binary linking are preserved, thus the resulting Alh must match the original one,
+// otherwise ErrReplicaDivergence is returned and the transaction is not committed
+func (s *ImmuStore) ReplicateTx(txMetadata *TxMetadata, entries []*KV, waitForIndexing bool) (*TxMetadata, error) {
+	if txMetadata == nil || txMetadata.BlTxID >= txMetadata.ID {
+		return nil, ErrIllegalArguments
+	}
+
+	committedTxID, _, _ := s.commitState()
+	if committedTxID+1 != txMetadata.ID {
+		return nil, ErrUnexpectedTxID
+	}
+
+	// binary linking is built asynchronously, the original tx may be linked to a tree not yet available in this store
+	err := s.waitForBinaryLinkingUpto(txMetadata.BlTxID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.commitEntries(entries, txMetadata, waitForIndexing)
+}
+
+func (s *ImmuStore) waitForBinaryLinkingUpto(txID uint64) error {
+	err := s.blWHub.WaitFor(txID, nil)
+	if err == watchers.ErrAlreadyClosed {
+		// the hub is closed either when the store is closed or when binary linking stopped due to an error
+		_, blErr := s.BlInfo()
+		if blErr != nil {
+			return blErr
+		}
+
+		return ErrAlreadyClosed
+	}
+
+	return err
+}
+
+func (s *ImmuStore) commitEntries(entries []*KV, replicated *TxMetadata, waitForIndexing bool) (*TxMetadata, error) {
 	s.mutex.Lock()
 	if s.closed {
 		s.mutex.Unlock()
@@ -800,7 +961,7 @@ func (s *ImmuStore) Commit(entries []*KV, waitForIndexing bool) (*TxMetadata, er
 		txe := tx.entries[i]
 		txe.setKey(e.Key)
 		txe.vLen = len(e.Value)
-		txe.hVal = sha256.Sum256(e.Value)
+		txe.hVal = s.hasher.Sum(e.Value)
 		txe.unique = e.Unique
 	}
 
@@ -819,7 +980,7 @@ func (s *ImmuStore) Commit(entries []*KV, waitForIndexing bool) (*TxMetadata, er
 		return nil, ErrAlreadyClosed
 	}
 
-	err = s.commit(tx, r.offsets)
+	err = s.commit(tx, r.offsets, replicated)
 	if err != nil {
 		s.mutex.Unlock()
 		return nil, err
@@ -837,11 +998,15 @@ func (s *ImmuStore) Commit(entries []*KV, waitForIndexing bool) (*TxMetadata, er
 	return tx.Metadata(), nil
 }
 
-func (s *ImmuStore) commit(tx *Tx, offsets []int64) error {
+func (s *ImmuStore) commit(tx *Tx, offsets []int64, replicated *TxMetadata) error {
 	if s.blErr != nil {
 		return s.blErr
 	}
 
+	start := time.Now()
+
+	s.faults.delayCommit()
+
 	// will overwrite partially written and uncommitted data
 	committedTxID, committedAlh, committedTxLogSize := s.commitState()
 
@@ -850,11 +1015,31 @@ func (s *ImmuStore) commit(tx *Tx, offsets []int64) error {
 	tx.ID = committedTxID + 1
 	tx.Ts = time.Now().Unix()
 
+	if replicated != nil {
+		if replicated.ID != tx.ID {
+			return ErrUnexpectedTxID
+		}
+
+		tx.Ts = replicated.Ts
+	}
+
 	blTxID, blRoot, err := s.aht.Root()
 	if err != nil && err != ahtree.ErrEmptyTree {
 		return err
 	}
 
+	if replicated != nil && replicated.BlTxID != blTxID {
+		blTxID = replicated.BlTxID
+		blRoot = [sha256.Size]byte{}
+
+		if blTxID > 0 {
+			blRoot, err = s.aht.RootAt(blTxID)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
 	tx.BlTxID = blTxID
 	tx.BlRoot = blRoot
 
@@ -916,6 +1101,10 @@ func (s *ImmuStore) commit(tx *Tx, offsets []int64) error {
 
 	tx.CalcAlh()
 
+	if replicated != nil && tx.Alh != replicated.Alh() {
+		return ErrReplicaDivergence
+	}
+
 	// tx serialization using pre-allocated buffer
 	copy(s._txbs[txSize:], tx.Alh[:])
 	txSize += sha256.Size
@@ -933,6 +1122,14 @@ func (s *ImmuStore) commit(tx *Tx, offsets []int64) error {
 		return err
 	}
 
+	// flushing a synced log implies an fsync
+	if s.synced {
+		err = s.faults.syncFault()
+		if err != nil {
+			return err
+		}
+	}
+
 	err = s.txLog.Flush()
 	if err != nil {
 		return err
@@ -943,6 +1140,8 @@ func (s *ImmuStore) commit(tx *Tx, offsets []int64) error {
 		if err != nil {
 			return err
 		}
+
+		s.blWHub.DoneUpto(s.aht.Size())
 	} else {
 		s.blBuffer <- tx.Alh
 	}
@@ -963,6 +1162,8 @@ func (s *ImmuStore) commit(tx *Tx, offsets []int64) error {
 	committedTxID = s.advanceCommitState(tx.Alh, int64(txSize))
 	s.wHub.DoneUpto(committedTxID)
 
+	atomic.StoreInt64(&s.commitLatency, int64(time.Since(start)))
+
 	return nil
 }
 
@@ -1000,6 +1201,42 @@ func (s *ImmuStore) CommitWith(callback func(txID uint64, index KeyIndex) ([]*KV
 	return md, err
 }
 
+// DryRunWith builds the entries of a transaction as CommitWith does and validates them, without committing them
+func (s *ImmuStore) DryRunWith(callback func(txID uint64, index KeyIndex) ([]*KV, error)) ([]*KV, error) {
+	if callback == nil {
+		return nil, ErrIllegalArguments
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closed {
+		return nil, ErrAlreadyClosed
+	}
+
+	s.indexer.Pause()
+	defer s.indexer.Resume()
+
+	committedTxID, _, _ := s.commitState()
+
+	entries, err := callback(committedTxID+1, &unsafeIndex{st: s})
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.validateEntries(entries)
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// ValidateEntries checks entries can be committed as a single transaction, without committing them
+func (s *ImmuStore) ValidateEntries(entries []*KV) error {
+	return s.validateEntries(entries)
+}
+
 type KeyIndex interface {
 	Get(key []byte) (value []byte, tx uint64, hc uint64, err error)
 }
@@ -1056,7 +1293,7 @@ func (s *ImmuStore) commitWith(callback func(txID uint64, index KeyIndex) ([]*KV
 		txe := tx.entries[i]
 		txe.setKey(e.Key)
 		txe.vLen = len(e.Value)
-		txe.hVal = sha256.Sum256(e.Value)
+		txe.hVal = s.hasher.Sum(e.Value)
 		txe.unique = e.Unique
 	}
 
@@ -1068,7 +1305,7 @@ func (s *ImmuStore) commitWith(callback func(txID uint64, index KeyIndex) ([]*KV
 		return nil, err
 	}
 
-	err = s.commit(tx, r.offsets)
+	err = s.commit(tx, r.offsets, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -1084,6 +1321,7 @@ type DualProof struct {
 	TargetBlTxAlh      [sha256.Size]byte
 	LastInclusionProof [][sha256.Size]byte
 	LinearProof        *LinearProof
+	HashAlgorithm      hashing.Algorithm
 }
 
 // DualProof combines linear cryptographic linking i.e. transactions include the linear accumulative hash up to the previous one,
@@ -1103,6 +1341,7 @@ func (s *ImmuStore) DualProof(sourceTx, targetTx *Tx) (proof *DualProof, err err
 	proof = &DualProof{
 		SourceTxMetadata: sourceTx.Metadata(),
 		TargetTxMetadata: targetTx.Metadata(),
+		HashAlgorithm:    s.hasher.Algorithm(),
 	}
 
 	if sourceTx.ID < targetTx.BlTxID {
@@ -1289,7 +1528,10 @@ func (s *ImmuStore) ReadTx(txID uint64, tx *Tx) error {
 
 	err = tx.readFrom(r)
 	if err == io.EOF {
-		return ErrorCorruptedTxData
+		err = ErrorCorruptedTxData
+	}
+	if err == ErrorCorruptedTxData {
+		s.notifyCorruption(err)
 	}
 
 	return err
@@ -1328,13 +1570,54 @@ func (s *ImmuStore) ReadValueAt(b []byte, off int64, hvalue [sha256.Size]byte) (
 		}
 	}
 
-	if hvalue != sha256.Sum256(b) {
+	if hvalue != s.hasher.Sum(b) {
+		s.notifyCorruption(ErrCorruptedData)
 		return len(b), ErrCorruptedData
 	}
 
 	return len(b), nil
 }
 
+// CorruptedReads returns the number of reads aborted because corrupted data was detected since the store was opened
+func (s *ImmuStore) CorruptedReads() uint64 {
+	return atomic.LoadUint64(&s.corruptedReads)
+}
+
+// CommitLatency returns the time taken to durably write the latest committed transaction
+func (s *ImmuStore) CommitLatency() time.Duration {
+	return time.Duration(atomic.LoadInt64(&s.commitLatency))
+}
+
+func (s *ImmuStore) notifyCorruption(err error) {
+	atomic.AddUint64(&s.corruptedReads, 1)
+	s.log.Errorf("Read at '%s' aborted due to error: %v", s.path, err)
+}
+
+// verifyIndexedValue checks the value reference resolved through the index matches the entry stored in its tx
+func (s *ImmuStore) verifyIndexedValue(key []byte, txID uint64, v *ValueRef) error {
+	tx := s.paranoidTxPool.Get().(*Tx)
+	defer s.paranoidTxPool.Put(tx)
+
+	err := s.ReadTx(txID, tx)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range tx.Entries() {
+		if bytes.Equal(e.key(), key) {
+			if e.hVal != v.hVal || e.vOff != v.vOff || e.vLen != int(v.valLen) {
+				break
+			}
+
+			return nil
+		}
+	}
+
+	s.notifyCorruption(ErrCorruptedIndex)
+
+	return ErrCorruptedIndex
+}
+
 func (s *ImmuStore) validateEntries(entries []*KV) error {
 	if len(entries) == 0 {
 		return ErrorNoEntriesProvided
@@ -1374,6 +1657,11 @@ func (s *ImmuStore) Sync() error {
 		return ErrAlreadyClosed
 	}
 
+	err := s.faults.syncFault()
+	if err != nil {
+		return err
+	}
+
 	for i := range s.vLogs {
 		vLog, _ := s.fetchVLog(i+1, false)
 		defer s.releaseVLog(i + 1)
@@ -1384,7 +1672,7 @@ func (s *ImmuStore) Sync() error {
 		}
 	}
 
-	err := s.txLog.Sync()
+	err = s.txLog.Sync()
 	if err != nil {
 		return err
 	}
@@ -1407,6 +1695,12 @@ func (s *ImmuStore) Close() error {
 
 	s.closed = true
 
+	s.keyFilter.stopLoading()
+
+	if s.archiveDone != nil {
+		close(s.archiveDone)
+	}
+
 	errors := make([]error, 0)
 
 	for i := range s.vLogs {
@@ -1427,6 +1721,7 @@ func (s *ImmuStore) Close() error {
 	}
 
 	s.wHub.Close()
+	s.blWHub.Close()
 
 	iErr := s.indexer.Close()
 	if iErr != nil {
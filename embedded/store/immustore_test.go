@@ -17,6 +17,7 @@ package store
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"encoding/binary"
 	"errors"
 	"fmt"
@@ -30,6 +31,7 @@ import (
 	"github.com/codenotary/immudb/embedded/appendable"
 	"github.com/codenotary/immudb/embedded/appendable/mocked"
 	"github.com/codenotary/immudb/embedded/appendable/multiapp"
+	"github.com/codenotary/immudb/embedded/hashing"
 	"github.com/codenotary/immudb/embedded/htree"
 	"github.com/codenotary/immudb/embedded/tbtree"
 
@@ -623,6 +625,133 @@ func TestImmudbStoreCommitWith(t *testing.T) {
 	require.Equal(t, []byte("value"), val)
 }
 
+func TestImmudbStoreDryRunWith(t *testing.T) {
+	opts := DefaultOptions().WithSynced(false).WithMaxConcurrency(1)
+	immuStore, err := Open("data_dry_run_with", opts)
+	require.NoError(t, err)
+	defer os.RemoveAll("data_dry_run_with")
+
+	require.NotNil(t, immuStore)
+	defer immuStore.Close()
+
+	_, err = immuStore.DryRunWith(nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	callback := func(txID uint64, index KeyIndex) ([]*KV, error) {
+		return nil, nil
+	}
+	_, err = immuStore.DryRunWith(callback)
+	require.Equal(t, ErrorNoEntriesProvided, err)
+
+	callback = func(txID uint64, index KeyIndex) ([]*KV, error) {
+		return []*KV{
+			{Key: []byte("key"), Value: []byte("value1")},
+			{Key: []byte("key"), Value: []byte("value2")},
+		}, nil
+	}
+	_, err = immuStore.DryRunWith(callback)
+	require.Equal(t, ErrDuplicatedKey, err)
+
+	callback = func(txID uint64, index KeyIndex) ([]*KV, error) {
+		return []*KV{
+			{Key: []byte(fmt.Sprintf("keyInsertedAtTx%d", txID)), Value: []byte("value")},
+		}, nil
+	}
+
+	entries, err := immuStore.DryRunWith(callback)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, []byte("keyInsertedAtTx1"), entries[0].Key)
+	require.Equal(t, uint64(0), immuStore.TxCount())
+
+	_, _, _, err = immuStore.Get([]byte("keyInsertedAtTx1"))
+	require.Equal(t, ErrKeyNotFound, err)
+}
+
+func TestImmudbStoreReplicateTx(t *testing.T) {
+	opts := DefaultOptions().WithSynced(false).WithMaxConcurrency(1)
+
+	masterStore, err := Open("data_master", opts)
+	require.NoError(t, err)
+	defer os.RemoveAll("data_master")
+	defer masterStore.Close()
+
+	replicaStore, err := Open("data_replica", opts)
+	require.NoError(t, err)
+	defer os.RemoveAll("data_replica")
+	defer replicaStore.Close()
+
+	_, err = replicaStore.ReplicateTx(nil, nil, false)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	tx := masterStore.NewTx()
+
+	for i := 0; i < 3; i++ {
+		kvs := []*KV{
+			{Key: []byte(fmt.Sprintf("key%d", i)), Value: []byte(fmt.Sprintf("value%d", i))},
+		}
+
+		md, err := masterStore.Commit(kvs, false)
+		require.NoError(t, err)
+
+		err = masterStore.ReadTx(md.ID, tx)
+		require.NoError(t, err)
+
+		if i == 0 {
+			_, err = replicaStore.ReplicateTx(&TxMetadata{ID: 2}, kvs, false)
+			require.Equal(t, ErrUnexpectedTxID, err)
+		}
+
+		rmd, err := replicaStore.ReplicateTx(tx.Metadata(), kvs, true)
+		require.NoError(t, err)
+		require.Equal(t, tx.Alh, rmd.Alh())
+	}
+
+	md, err := masterStore.Commit([]*KV{{Key: []byte("key"), Value: []byte("value")}}, false)
+	require.NoError(t, err)
+
+	_, err = replicaStore.ReplicateTx(md, []*KV{{Key: []byte("key"), Value: []byte("diverged")}}, false)
+	require.Equal(t, ErrReplicaDivergence, err)
+
+	replicaTxID, _ := replicaStore.Alh()
+	require.Equal(t, uint64(3), replicaTxID)
+
+	_, err = replicaStore.ReplicateTx(md, []*KV{{Key: []byte("key"), Value: []byte("value")}}, false)
+	require.NoError(t, err)
+
+	masterTxID, masterAlh := masterStore.Alh()
+	replicaTxID, replicaAlh := replicaStore.Alh()
+	require.Equal(t, masterTxID, replicaTxID)
+	require.Equal(t, masterAlh, replicaAlh)
+}
+
+func TestImmudbStoreWaitForBinaryLinkingOnClose(t *testing.T) {
+	st, err := Open("data_bl_wait", DefaultOptions().WithSynced(false).WithMaxConcurrency(1))
+	require.NoError(t, err)
+	defer os.RemoveAll("data_bl_wait")
+
+	_, err = st.Commit([]*KV{{Key: []byte("key"), Value: []byte("value")}}, false)
+	require.NoError(t, err)
+
+	require.NoError(t, st.waitForBinaryLinkingUpto(1))
+
+	done := make(chan error)
+
+	go func() {
+		done <- st.waitForBinaryLinkingUpto(2)
+	}()
+
+	select {
+	case <-done:
+		require.Fail(t, "binary linking is not expected to reach an uncommitted tx")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	require.NoError(t, st.Close())
+
+	require.Equal(t, ErrAlreadyClosed, <-done)
+}
+
 func TestImmudbStoreHistoricalValues(t *testing.T) {
 	opts := DefaultOptions().WithSynced(false).WithMaxConcurrency(1)
 	opts.WithIndexOptions(opts.IndexOpts.WithFlushThld(10))
@@ -1251,6 +1380,128 @@ func TestReOpenningWithCompressionEnabledImmudbStore(t *testing.T) {
 	}
 }
 
+func TestIncompressibleValuesWithCompressionEnabled(t *testing.T) {
+	defer os.RemoveAll("data_incompressible")
+
+	opts := DefaultOptions().
+		WithSynced(false).
+		WithCompressionFormat(appendable.GZipCompression).
+		WithMaxConcurrency(1)
+
+	immuStore, err := Open("data_incompressible", opts)
+	require.NoError(t, err)
+
+	random := make([]byte, 1024)
+	_, err = rand.Read(random)
+	require.NoError(t, err)
+
+	kvs := []*KV{
+		{Key: []byte("k1"), Value: random},
+		{Key: []byte("k2"), Value: []byte("value2"), Incompressible: true},
+		{Key: []byte("k3"), Value: []byte("value3")},
+	}
+
+	txMetadata, err := immuStore.Commit(kvs, true)
+	require.NoError(t, err)
+
+	tx := immuStore.NewTx()
+	err = immuStore.ReadTx(txMetadata.ID, tx)
+	require.NoError(t, err)
+
+	for i, e := range tx.Entries() {
+		val, err := immuStore.ReadValue(tx, e.Key())
+		require.NoError(t, err)
+		require.Equal(t, kvs[i].Value, val)
+	}
+
+	err = immuStore.Close()
+	require.NoError(t, err)
+}
+
+func TestImmudbStoreParanoidReads(t *testing.T) {
+	defer os.RemoveAll("data_paranoid")
+
+	immuStore, err := Open("data_paranoid", DefaultOptions().WithSynced(false).WithParanoidReads(true))
+	require.NoError(t, err)
+	defer immuStore.Close()
+
+	for i := 0; i < 2; i++ {
+		_, err = immuStore.Commit([]*KV{{Key: []byte("key"), Value: []byte(fmt.Sprintf("value%d", i))}}, true)
+		require.NoError(t, err)
+	}
+
+	val, tx, _, err := immuStore.Get([]byte("key"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("value1"), val)
+	require.Equal(t, uint64(2), tx)
+
+	snap, err := immuStore.Snapshot()
+	require.NoError(t, err)
+
+	reader, err := snap.NewKeyReader(&KeyReaderSpec{Prefix: []byte("key")})
+	require.NoError(t, err)
+
+	_, valRef, _, _, err := reader.Read()
+	require.NoError(t, err)
+
+	val, err = valRef.Resolve()
+	require.NoError(t, err)
+	require.Equal(t, []byte("value1"), val)
+
+	require.NoError(t, reader.Close())
+	require.NoError(t, snap.Close())
+
+	require.Zero(t, immuStore.CorruptedReads())
+
+	// value reference of the previous version, as if the index were not updated
+	err = immuStore.verifyIndexedValue([]byte("key"), 1, valRef)
+	require.Equal(t, ErrCorruptedIndex, err)
+
+	err = immuStore.verifyIndexedValue([]byte("key"), 2, valRef)
+	require.NoError(t, err)
+
+	_, err = immuStore.ReadValueAt(make([]byte, len(val)), valRef.vOff, sha256.Sum256([]byte("value0")))
+	require.Equal(t, ErrCorruptedData, err)
+
+	require.Equal(t, uint64(2), immuStore.CorruptedReads())
+}
+
+func TestImmudbStoreHashAlgorithm(t *testing.T) {
+	defer os.RemoveAll("data_hash_alg")
+
+	immuStore, err := Open("data_hash_alg", DefaultOptions().WithSynced(false))
+	require.NoError(t, err)
+	defer immuStore.Close()
+
+	require.Equal(t, hashing.SHA256, immuStore.HashAlgorithm())
+
+	for i := 0; i < 2; i++ {
+		_, err = immuStore.Commit([]*KV{{Key: []byte("key"), Value: []byte(fmt.Sprintf("value%d", i))}}, false)
+		require.NoError(t, err)
+	}
+
+	sourceTx := immuStore.NewTx()
+	require.NoError(t, immuStore.ReadTx(1, sourceTx))
+
+	targetTx := immuStore.NewTx()
+	require.NoError(t, immuStore.ReadTx(2, targetTx))
+
+	proof, err := immuStore.DualProof(sourceTx, targetTx)
+	require.NoError(t, err)
+	require.Equal(t, hashing.SHA256, proof.HashAlgorithm)
+	require.True(t, VerifyDualProof(proof, 1, 2, sourceTx.Alh, targetTx.Alh))
+
+	proof.HashAlgorithm = hashing.Algorithm(255)
+	require.False(t, VerifyDualProof(proof, 1, 2, sourceTx.Alh, targetTx.Alh))
+
+	iproof, err := targetTx.Proof([]byte("key"))
+	require.NoError(t, err)
+	require.True(t, VerifyInclusion(iproof, &KV{Key: []byte("key"), Value: []byte("value1")}, targetTx.Eh()))
+
+	iproof.HashAlgorithm = hashing.Algorithm(255)
+	require.False(t, VerifyInclusion(iproof, &KV{Key: []byte("key"), Value: []byte("value1")}, targetTx.Eh()))
+}
+
 func TestUncommittedTxOverwriting(t *testing.T) {
 	path := "data_overwriting"
 	err := os.Mkdir(path, 0700)
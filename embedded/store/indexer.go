@@ -41,6 +41,9 @@ type indexer struct {
 	state     int
 	stateCond *sync.Cond
 
+	// recovery tracks the indexing of the transactions committed before the store was opened
+	recovery *recoveryTracker
+
 	closed bool
 
 	compactionMutex sync.Mutex
@@ -75,6 +78,11 @@ func newIndexer(path string, store *ImmuStore, indexOpts *tbtree.Options, maxWai
 		stateCond: sync.NewCond(&sync.Mutex{}),
 	}
 
+	store.keyFilter.startLoading(store, index.Ts())
+
+	committedTxID, _, _ := store.commitState()
+	indexer.recovery = newRecoveryTracker(store, RecoveryIndexing, index.Ts(), committedTxID)
+
 	indexer.resume()
 
 	return indexer, nil
@@ -363,12 +371,16 @@ func (idx *indexer) indexSince(txID uint64, limit int) error {
 
 			idx.store._kvs[i].K = e.key()
 			idx.store._kvs[i].V = b[:]
+
+			idx.store.keyFilter.add(idx.store._kvs[i].K)
 		}
 
 		err = idx.index.BulkInsert(idx.store._kvs[:len(txEntries)])
 		if err != nil {
 			return err
 		}
+
+		idx.recovery.progress(tx.ID)
 	}
 
 	return nil
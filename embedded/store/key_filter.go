@@ -0,0 +1,194 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"errors"
+	"hash/fnv"
+	"math"
+	"sync"
+)
+
+// keyFilterFalsePositiveRate is the rate of lookups of missing keys not filtered out once the filter holds as many keys
+// as it was sized for. The rate increases as more keys are added but lookups are never filtered out wrongly
+const keyFilterFalsePositiveRate = 0.01
+
+var errKeyFilterLoadingStopped = errors.New("key filter loading stopped")
+
+// keyFilter is a bloom filter holding every indexed key, so lookups of keys which were never written are answered
+// without reading the index. Keys are added before being indexed and never removed.
+// The keys indexed before the store was opened are loaded in background, the filter is bypassed until they are loaded
+type keyFilter struct {
+	bits   []uint64
+	m      uint64
+	hashes int
+
+	loaded bool
+
+	loadingDone    chan struct{}
+	loadingStopped chan struct{}
+
+	mutex sync.RWMutex
+}
+
+func newKeyFilter(capacity int) *keyFilter {
+	if capacity <= 0 {
+		return nil
+	}
+
+	m := uint64(math.Ceil(-float64(capacity) * math.Log(keyFilterFalsePositiveRate) / (math.Ln2 * math.Ln2)))
+	hashes := int(math.Round(float64(m) / float64(capacity) * math.Ln2))
+
+	return &keyFilter{
+		bits:   make([]uint64, (m+63)/64),
+		m:      m,
+		hashes: hashes,
+	}
+}
+
+// positions derives the bits of a key by double hashing the two halves of its 64-bit FNV-1a hash
+func (f *keyFilter) positions(key []byte, fn func(pos uint64)) {
+	h := fnv.New64a()
+	h.Write(key)
+	sum := h.Sum64()
+
+	h1, h2 := sum&math.MaxUint32, sum>>32
+
+	for i := 0; i < f.hashes; i++ {
+		fn((h1 + uint64(i)*h2) % f.m)
+	}
+}
+
+func (f *keyFilter) add(key []byte) {
+	if f == nil {
+		return
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.positions(key, func(pos uint64) {
+		f.bits[pos/64] |= 1 << (pos % 64)
+	})
+}
+
+// mayContain returns false only if the key was never added
+func (f *keyFilter) mayContain(key []byte) bool {
+	if f == nil {
+		return true
+	}
+
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	if !f.loaded {
+		return true
+	}
+
+	found := true
+
+	f.positions(key, func(pos uint64) {
+		if f.bits[pos/64]&(1<<(pos%64)) == 0 {
+			found = false
+		}
+	})
+
+	return found
+}
+
+// startLoading loads in background the keys of the transactions already indexed when the store is opened, the keys
+// of the remaining ones are added as they get indexed. The filter is bypassed until loading completes, or for as long
+// as the store is open if loading fails
+func (f *keyFilter) startLoading(st *ImmuStore, indexedTxID uint64) {
+	if f == nil {
+		return
+	}
+
+	if indexedTxID == 0 {
+		f.setLoaded()
+		return
+	}
+
+	f.loadingDone = make(chan struct{})
+	f.loadingStopped = make(chan struct{})
+
+	go func() {
+		defer close(f.loadingStopped)
+
+		err := f.load(st, indexedTxID)
+		if err == errKeyFilterLoadingStopped {
+			return
+		}
+		if err != nil {
+			st.log.Warningf("Key filter at '%s' bypassed due to error: %v", st.path, err)
+			return
+		}
+
+		f.setLoaded()
+
+		st.log.Infof("Key filter loaded at '%s'", st.path)
+	}()
+}
+
+// stopLoading stops loading keys, if still in progress, and waits for it to end
+func (f *keyFilter) stopLoading() {
+	if f == nil || f.loadingDone == nil {
+		return
+	}
+
+	close(f.loadingDone)
+	<-f.loadingStopped
+}
+
+func (f *keyFilter) setLoaded() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.loaded = true
+}
+
+// load adds the keys of the transactions up to indexedTxID. Transactions are read instead of the index to leave
+// its snapshots untouched
+func (f *keyFilter) load(st *ImmuStore, indexedTxID uint64) error {
+	txReader, err := st.newTxReader(1, false, st.NewTx())
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-f.loadingDone:
+			return errKeyFilterLoadingStopped
+		default:
+		}
+
+		tx, err := txReader.Read()
+		if err == ErrNoMoreEntries {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, e := range tx.Entries() {
+			f.add(e.key())
+		}
+
+		if tx.ID == indexedTxID {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyFilter(t *testing.T) {
+	require.Nil(t, newKeyFilter(0))
+
+	var f *keyFilter
+	require.True(t, f.mayContain([]byte("key")))
+
+	f = newKeyFilter(1000)
+	f.setLoaded()
+
+	for i := 0; i < 1000; i++ {
+		f.add([]byte(fmt.Sprintf("key%d", i)))
+	}
+
+	falsePositives := 0
+
+	for i := 0; i < 1000; i++ {
+		require.True(t, f.mayContain([]byte(fmt.Sprintf("key%d", i))))
+
+		if f.mayContain([]byte(fmt.Sprintf("missing%d", i))) {
+			falsePositives++
+		}
+	}
+
+	require.Less(t, falsePositives, 50)
+}
+
+func TestKeyFilterBypassedUntilLoaded(t *testing.T) {
+	f := newKeyFilter(100)
+
+	// the filter is bypassed while keys indexed before the store was opened are being loaded
+	require.True(t, f.mayContain([]byte("missing")))
+
+	f.setLoaded()
+	require.False(t, f.mayContain([]byte("missing")))
+
+	f.add([]byte("key"))
+	require.True(t, f.mayContain([]byte("key")))
+}
+
+func TestImmuStoreKeyFilterLoadingStopped(t *testing.T) {
+	opts := DefaultOptions().WithSynced(false).WithKeyFilterCapacity(1000)
+
+	immuStore, err := Open("data_key_filter_stopped", opts)
+	require.NoError(t, err)
+	defer os.RemoveAll("data_key_filter_stopped")
+
+	for i := 0; i < 100; i++ {
+		_, err := immuStore.Commit([]*KV{{Key: []byte(fmt.Sprintf("key%d", i)), Value: []byte("value")}}, true)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, immuStore.Close())
+
+	immuStore, err = Open("data_key_filter_stopped", opts)
+	require.NoError(t, err)
+
+	// closing the store stops loading the filter, leaving it bypassed
+	require.NoError(t, immuStore.Close())
+
+	select {
+	case <-immuStore.keyFilter.loadingStopped:
+	default:
+		require.Fail(t, "key filter still loading")
+	}
+
+	if !immuStore.keyFilter.loaded {
+		require.True(t, immuStore.keyFilter.mayContain([]byte("missing")))
+	}
+}
+
+func TestImmuStoreKeyFilter(t *testing.T) {
+	opts := DefaultOptions().WithSynced(false).WithKeyFilterCapacity(100)
+
+	immuStore, err := Open("data_key_filter", opts)
+	require.NoError(t, err)
+	defer os.RemoveAll("data_key_filter")
+
+	for i := 0; i < 10; i++ {
+		var k [8]byte
+		binary.BigEndian.PutUint64(k[:], uint64(i))
+
+		_, err := immuStore.Commit([]*KV{{Key: k[:], Value: k[:]}}, true)
+		require.NoError(t, err)
+	}
+
+	snap, err := immuStore.Snapshot()
+	require.NoError(t, err)
+
+	_, _, _, err = immuStore.Get([]byte("missing"))
+	require.Equal(t, ErrKeyNotFound, err)
+
+	_, _, _, err = snap.Get([]byte("missing"))
+	require.Equal(t, ErrKeyNotFound, err)
+
+	require.NoError(t, snap.Close())
+	require.NoError(t, immuStore.Close())
+
+	// keys indexed before the store is opened are loaded into the filter in background
+	immuStore, err = Open("data_key_filter", opts)
+	require.NoError(t, err)
+	defer immuStore.Close()
+
+	<-immuStore.keyFilter.loadingStopped
+
+	require.False(t, immuStore.keyFilter.mayContain([]byte("missing")))
+
+	for i := 0; i < 10; i++ {
+		var k [8]byte
+		binary.BigEndian.PutUint64(k[:], uint64(i))
+
+		require.True(t, immuStore.keyFilter.mayContain(k[:]))
+
+		v, _, _, err := immuStore.Get(k[:])
+		require.NoError(t, err)
+		require.Equal(t, k[:], v)
+	}
+
+	_, err = Open("data_key_filter_invalid", DefaultOptions().WithKeyFilterCapacity(-1))
+	require.Equal(t, ErrIllegalArguments, err)
+}
@@ -31,6 +31,7 @@ type Snapshot struct {
 type KeyReader struct {
 	store  *ImmuStore
 	reader *tbtree.Reader
+	ra     *readahead
 	_tx    *Tx
 }
 
@@ -38,16 +39,23 @@ type KeyReaderSpec struct {
 	SeekKey       []byte
 	Prefix        []byte
 	InclusiveSeek bool
-	DescOrder     bool
+	// EndKey stops the reader at the given key in the reading order, it's not applied when empty
+	EndKey       []byte
+	InclusiveEnd bool
+	DescOrder    bool
 }
 
 func (s *Snapshot) Get(key []byte) (val []byte, tx uint64, hc uint64, err error) {
+	if !s.st.keyFilter.mayContain(key) {
+		return nil, 0, 0, ErrKeyNotFound
+	}
+
 	indexedVal, tx, hc, err := s.snap.Get(key)
 	if err != nil {
 		return nil, 0, 0, err
 	}
 
-	valRef, err := s.st.valueRefFrom(indexedVal)
+	valRef, err := s.st.valueRefFrom(key, tx, indexedVal)
 	if err != nil {
 		return nil, 0, 0, err
 	}
@@ -81,6 +89,8 @@ func (s *Snapshot) NewKeyReader(spec *KeyReaderSpec) (*KeyReader, error) {
 		SeekKey:       spec.SeekKey,
 		Prefix:        spec.Prefix,
 		InclusiveSeek: spec.InclusiveSeek,
+		EndKey:        spec.EndKey,
+		InclusiveEnd:  spec.InclusiveEnd,
 		DescOrder:     spec.DescOrder,
 	})
 	if err != nil {
@@ -90,6 +100,7 @@ func (s *Snapshot) NewKeyReader(spec *KeyReaderSpec) (*KeyReader, error) {
 	return &KeyReader{
 		store:  s.st,
 		reader: r,
+		ra:     newReadahead(s.st),
 		_tx:    s.st.NewTx(),
 	}, nil
 }
@@ -99,9 +110,10 @@ type ValueRef struct {
 	vOff   int64
 	valLen uint32
 	st     *ImmuStore
+	ra     *readahead
 }
 
-func (st *ImmuStore) valueRefFrom(indexedVal []byte) (*ValueRef, error) {
+func (st *ImmuStore) valueRefFrom(key []byte, tx uint64, indexedVal []byte) (*ValueRef, error) {
 	if len(indexedVal) != 4+8+32 {
 		return nil, ErrCorruptedData
 	}
@@ -112,17 +124,37 @@ func (st *ImmuStore) valueRefFrom(indexedVal []byte) (*ValueRef, error) {
 	var hVal [sha256.Size]byte
 	copy(hVal[:], indexedVal[4+8:])
 
-	return &ValueRef{
+	valRef := &ValueRef{
 		hVal:   hVal,
 		vOff:   int64(vOff),
 		valLen: valLen,
 		st:     st,
-	}, nil
+	}
+
+	if st.paranoidReads {
+		err := st.verifyIndexedValue(key, tx, valRef)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return valRef, nil
+}
+
+// Len returns the length of the referenced value
+func (v *ValueRef) Len() uint32 {
+	return v.valLen
 }
 
 // Resolve ...
 func (v *ValueRef) Resolve() ([]byte, error) {
 	refVal := make([]byte, v.valLen)
+
+	if v.ra != nil {
+		_, err := v.ra.readValueAt(refVal, v.vOff, v.hVal)
+		return refVal, err
+	}
+
 	_, err := v.st.ReadValueAt(refVal, v.vOff, v.hVal)
 	return refVal, err
 }
@@ -145,6 +177,7 @@ func (r *KeyReader) ReadAsBefore(txID uint64) (key []byte, val *ValueRef, tx uin
 				vOff:   int64(e.vOff),
 				valLen: uint32(e.vLen),
 				st:     r.store,
+				ra:     r.ra,
 			}
 
 			return key, val, ktxID, nil
@@ -160,14 +193,30 @@ func (r *KeyReader) Read() (key []byte, val *ValueRef, tx uint64, hc uint64, err
 		return nil, nil, 0, 0, err
 	}
 
-	val, err = r.store.valueRefFrom(indexedVal)
+	val, err = r.store.valueRefFrom(key, tx, indexedVal)
 	if err != nil {
 		return nil, nil, 0, 0, err
 	}
 
+	val.ra = r.ra
+
 	return key, val, tx, hc, nil
 }
 
+// ReadKey returns the next key along with the length of its value, without referencing nor verifying the value
+func (r *KeyReader) ReadKey() (key []byte, valLen uint32, tx uint64, err error) {
+	key, indexedVal, tx, _, err := r.reader.Read()
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	if len(indexedVal) != 4+8+32 {
+		return nil, 0, 0, ErrCorruptedData
+	}
+
+	return key, binary.BigEndian.Uint32(indexedVal), tx, nil
+}
+
 func (r *KeyReader) Reset() error {
 	return r.reader.Reset()
 }
@@ -37,6 +37,7 @@ const DefaultCompressionFormat = appendable.DefaultCompressionFormat
 const DefaultCompressionLevel = appendable.DefaultCompressionLevel
 const DefaultTxLogCacheSize = 1000
 const DefaultMaxWaitees = 1000
+const DefaultReadaheadWindow = 256 << 10 // 256Kb
 
 const MaxFileSize = (1 << 31) - 1 // 2Gb
 
@@ -58,6 +59,21 @@ type Options struct {
 
 	MaxWaitees int
 
+	// value-log segments not modified during ArchiveAfter are moved into ArchivePath, archiving is disabled when empty
+	ArchivePath  string
+	ArchiveAfter time.Duration
+
+	// ParanoidReads verifies every value resolved through the index against the entry stored in its tx
+	ParanoidReads bool
+
+	// ReadaheadWindow is the size in bytes of the value-log region buffered by readers found to be sequential, zero disables readahead
+	ReadaheadWindow int
+
+	// KeyFilterCapacity is the number of keys the existence filter is sized for, zero disables the filter.
+	// Lookups of keys which were never written are answered without reading the index when the filter is enabled.
+	// The filter is rebuilt in background when the store is opened and bypassed until then
+	KeyFilterCapacity int
+
 	// options below are only set during initialization and stored as metadata
 	MaxTxEntries      int
 	MaxKeyLen         int
@@ -78,6 +94,9 @@ type IndexOptions struct {
 	RenewSnapRootAfter    time.Duration
 	CompactionThld        int
 	DelayDuringCompaction time.Duration
+
+	// WarmUp pre-loads the index nodes accessed before the last close when the store is opened
+	WarmUp bool
 }
 
 func DefaultOptions() *Options {
@@ -99,6 +118,8 @@ func DefaultOptions() *Options {
 
 		MaxWaitees: DefaultMaxWaitees,
 
+		ReadaheadWindow: DefaultReadaheadWindow,
+
 		// options below are only set during initialization and stored as metadata
 		MaxTxEntries:      DefaultMaxTxEntries,
 		MaxKeyLen:         DefaultMaxKeyLen,
@@ -138,6 +159,12 @@ func validOptions(opts *Options) bool {
 
 		opts.MaxWaitees >= 0 &&
 
+		opts.ArchiveAfter >= 0 &&
+
+		opts.ReadaheadWindow >= 0 &&
+
+		opts.KeyFilterCapacity >= 0 &&
+
 		// options below are only set during initialization and stored as metadata
 		opts.MaxTxEntries > 0 &&
 		opts.MaxKeyLen > 0 &&
@@ -248,6 +275,31 @@ func (opts *Options) WithCompresionLevel(compressionLevel int) *Options {
 	return opts
 }
 
+func (opts *Options) WithArchivePath(archivePath string) *Options {
+	opts.ArchivePath = archivePath
+	return opts
+}
+
+func (opts *Options) WithArchiveAfter(archiveAfter time.Duration) *Options {
+	opts.ArchiveAfter = archiveAfter
+	return opts
+}
+
+func (opts *Options) WithParanoidReads(paranoidReads bool) *Options {
+	opts.ParanoidReads = paranoidReads
+	return opts
+}
+
+func (opts *Options) WithReadaheadWindow(readaheadWindow int) *Options {
+	opts.ReadaheadWindow = readaheadWindow
+	return opts
+}
+
+func (opts *Options) WithKeyFilterCapacity(keyFilterCapacity int) *Options {
+	opts.KeyFilterCapacity = keyFilterCapacity
+	return opts
+}
+
 func (opts *Options) WithIndexOptions(indexOptions *IndexOptions) *Options {
 	opts.IndexOpts = indexOptions
 	return opts
@@ -289,3 +341,8 @@ func (opts *IndexOptions) WithDelayDuringCompaction(delayDuringCompaction time.D
 	opts.DelayDuringCompaction = delayDuringCompaction
 	return opts
 }
+
+func (opts *IndexOptions) WithWarmUp(warmUp bool) *IndexOptions {
+	opts.WarmUp = warmUp
+	return opts
+}
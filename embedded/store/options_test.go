@@ -49,6 +49,11 @@ func TestValidOptions(t *testing.T) {
 	require.Equal(t, 2, opts.WithTxLogMaxOpenedFiles(2).TxLogMaxOpenedFiles)
 	require.Equal(t, 3, opts.WithVLogMaxOpenedFiles(3).VLogMaxOpenedFiles)
 	require.Equal(t, DefaultMaxWaitees, opts.WithMaxWaitees(DefaultMaxWaitees).MaxWaitees)
+	require.Equal(t, "archive", opts.WithArchivePath("archive").ArchivePath)
+	require.Equal(t, time.Hour, opts.WithArchiveAfter(time.Hour).ArchiveAfter)
+	require.True(t, opts.WithParanoidReads(true).ParanoidReads)
+	require.Equal(t, DefaultReadaheadWindow, opts.WithReadaheadWindow(DefaultReadaheadWindow).ReadaheadWindow)
+	require.Equal(t, 1000, opts.WithKeyFilterCapacity(1000).KeyFilterCapacity)
 
 	require.True(t, opts.WithSynced(true).Synced)
 
@@ -79,4 +84,5 @@ func TestValidOptions(t *testing.T) {
 	require.True(t, validOptions(opts))
 	require.Equal(t, 3, indexOpts.WithCompactionThld(3).CompactionThld)
 	require.Equal(t, 1*time.Millisecond, indexOpts.WithDelayDuringCompaction(1*time.Millisecond).DelayDuringCompaction)
+	require.True(t, indexOpts.WithWarmUp(true).WarmUp)
 }
@@ -0,0 +1,146 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"crypto/sha256"
+	"io"
+
+	"github.com/codenotary/immudb/embedded/appendable/multiapp"
+	"github.com/codenotary/immudb/embedded/appendable/singleapp"
+)
+
+// number of consecutive sequential value reads required before reading ahead
+const readaheadThld = 2
+
+// readahead serves the values resolved by a single reader from a buffered value-log window
+// once its reads are found to be sequential. Random access patterns keep reading values directly
+type readahead struct {
+	st     *ImmuStore
+	window int
+
+	lastVLogID byte
+	lastEnd    int64
+	seqReads   int
+
+	buf       []byte
+	bufVLogID byte
+	bufOff    int64
+
+	// set when buffered values can not be verified e.g. value-logs are compressed
+	disabled bool
+}
+
+func newReadahead(st *ImmuStore) *readahead {
+	if st.readaheadWindow == 0 {
+		return nil
+	}
+
+	return &readahead{st: st, window: st.readaheadWindow}
+}
+
+func (ra *readahead) readValueAt(b []byte, off int64, hvalue [sha256.Size]byte) (int, error) {
+	vLogID, offset := decodeOffset(off)
+
+	if vLogID == 0 || ra.disabled || len(b) > ra.window {
+		return ra.st.ReadValueAt(b, off, hvalue)
+	}
+
+	if vLogID == ra.lastVLogID && offset >= ra.lastEnd && offset-ra.lastEnd <= int64(ra.window) {
+		ra.seqReads++
+	} else {
+		ra.seqReads = 0
+	}
+
+	ra.lastVLogID = vLogID
+	ra.lastEnd = offset + int64(len(b))
+
+	if ra.seqReads < readaheadThld {
+		return ra.st.ReadValueAt(b, off, hvalue)
+	}
+
+	if !ra.buffered(vLogID, offset, len(b)) {
+		err := ra.fill(vLogID, offset)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if ra.buffered(vLogID, offset, len(b)) {
+		copy(b, ra.buf[offset-ra.bufOff:])
+
+		if hvalue == ra.st.hasher.Sum(b) {
+			return len(b), nil
+		}
+
+		ra.disabled = true
+		ra.buf = nil
+	}
+
+	return ra.st.ReadValueAt(b, off, hvalue)
+}
+
+func (ra *readahead) buffered(vLogID byte, offset int64, size int) bool {
+	return vLogID == ra.bufVLogID &&
+		offset >= ra.bufOff &&
+		offset+int64(size) <= ra.bufOff+int64(len(ra.buf))
+}
+
+func (ra *readahead) fill(vLogID byte, offset int64) error {
+	vLog, err := ra.st.fetchVLog(vLogID, true)
+	if err != nil {
+		return err
+	}
+	defer ra.st.releaseVLog(vLogID)
+
+	// only flushed data is read ahead
+	size, err := vLog.Size()
+	if err == multiapp.ErrAlreadyClosed || err == singleapp.ErrAlreadyClosed {
+		return ErrAlreadyClosed
+	}
+	if err != nil {
+		return err
+	}
+
+	n := ra.window
+	if size-offset < int64(n) {
+		n = int(size - offset)
+	}
+
+	ra.bufVLogID = vLogID
+	ra.bufOff = offset
+
+	if n <= 0 {
+		ra.buf = ra.buf[:0]
+		return nil
+	}
+
+	if ra.buf == nil {
+		ra.buf = make([]byte, ra.window)
+	}
+
+	rn, err := vLog.ReadAt(ra.buf[:n], offset)
+	if err == multiapp.ErrAlreadyClosed || err == singleapp.ErrAlreadyClosed {
+		return ErrAlreadyClosed
+	}
+	if err != nil && err != io.EOF {
+		return err
+	}
+
+	ra.buf = ra.buf[:rn]
+
+	return nil
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"encoding/binary"
+	"os"
+	"testing"
+
+	"github.com/codenotary/immudb/embedded/appendable"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadahead(t *testing.T) {
+	opts := DefaultOptions().WithSynced(false).WithReadaheadWindow(1024)
+	immuStore, err := Open("data_readahead", opts)
+	require.NoError(t, err)
+	defer os.RemoveAll("data_readahead")
+	defer immuStore.Close()
+
+	eCount := 1000
+
+	for i := 0; i < eCount; i++ {
+		var k [8]byte
+		binary.BigEndian.PutUint64(k[:], uint64(i))
+
+		_, err := immuStore.Commit([]*KV{{Key: k[:], Value: k[:]}}, true)
+		require.NoError(t, err)
+	}
+
+	snap, err := immuStore.SnapshotSince(uint64(eCount))
+	require.NoError(t, err)
+	defer snap.Close()
+
+	reader, err := snap.NewKeyReader(&KeyReaderSpec{})
+	require.NoError(t, err)
+	defer reader.Close()
+
+	require.NotNil(t, reader.ra)
+
+	for i := 0; i < eCount; i++ {
+		rk, vref, _, _, err := reader.Read()
+		require.NoError(t, err)
+
+		rv, err := vref.Resolve()
+		require.NoError(t, err)
+		require.Equal(t, rk, rv)
+	}
+
+	require.NotEmpty(t, reader.ra.buf)
+	require.False(t, reader.ra.disabled)
+
+	// random access patterns are not buffered
+	descReader, err := snap.NewKeyReader(&KeyReaderSpec{SeekKey: []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}, DescOrder: true})
+	require.NoError(t, err)
+	defer descReader.Close()
+
+	for i := 0; i < eCount; i++ {
+		rk, vref, _, _, err := descReader.Read()
+		require.NoError(t, err)
+
+		rv, err := vref.Resolve()
+		require.NoError(t, err)
+		require.Equal(t, rk, rv)
+	}
+
+	require.Empty(t, descReader.ra.buf)
+}
+
+func TestReadaheadDisabled(t *testing.T) {
+	opts := DefaultOptions().WithReadaheadWindow(0)
+	immuStore, err := Open("data_readahead_disabled", opts)
+	require.NoError(t, err)
+	defer os.RemoveAll("data_readahead_disabled")
+	defer immuStore.Close()
+
+	require.Nil(t, newReadahead(immuStore))
+
+	opts = DefaultOptions().WithCompressionFormat(appendable.FlateCompression)
+	compressedStore, err := Open("data_readahead_compressed", opts)
+	require.NoError(t, err)
+	defer os.RemoveAll("data_readahead_compressed")
+	defer compressedStore.Close()
+
+	require.Nil(t, newReadahead(compressedStore))
+
+	_, err = Open("data_readahead_invalid", DefaultOptions().WithReadaheadWindow(-1))
+	require.Equal(t, ErrIllegalArguments, err)
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"sync"
+	"time"
+)
+
+// Recovery phases, run when opening a store to catch up with the transactions committed before it was closed
+const (
+	RecoveryBinaryLinking = "binary linking"
+	RecoveryIndexing      = "indexing"
+)
+
+// RecoveryLogInterval is how often the progress of a recovery is logged
+var RecoveryLogInterval = 10 * time.Second
+
+// RecoveryStatus is the progress of a recovery phase
+type RecoveryStatus struct {
+	Phase          string
+	TxReplayed     uint64
+	TxTotal        uint64
+	BytesProcessed int64
+	BytesTotal     int64
+	Elapsed        time.Duration
+	ETA            time.Duration
+	Completed      bool
+}
+
+type recoveryTracker struct {
+	store *ImmuStore
+	phase string
+
+	fromTx uint64
+	toTx   uint64
+	doneTx uint64
+
+	fromOffset int64
+	toOffset   int64
+
+	startedAt time.Time
+	loggedAt  time.Time
+	elapsed   time.Duration
+
+	mutex sync.Mutex
+}
+
+// newRecoveryTracker returns nil when there are no transactions after fromTx to be replayed
+func newRecoveryTracker(store *ImmuStore, phase string, fromTx, toTx uint64) *recoveryTracker {
+	if fromTx >= toTx {
+		return nil
+	}
+
+	rt := &recoveryTracker{
+		store:     store,
+		phase:     phase,
+		fromTx:    fromTx,
+		toTx:      toTx,
+		doneTx:    fromTx,
+		startedAt: time.Now(),
+		loggedAt:  time.Now(),
+	}
+
+	rt.fromOffset = rt.txEnd(fromTx)
+	rt.toOffset = rt.txEnd(toTx)
+
+	store.log.Infof("Recovering %s at '%s': %d transaction/s (%d bytes) to be replayed", phase, store.path, toTx-fromTx, rt.toOffset-rt.fromOffset)
+
+	return rt
+}
+
+// txEnd returns the offset in the transaction log right after the given transaction
+func (rt *recoveryTracker) txEnd(txID uint64) int64 {
+	if txID == 0 {
+		return 0
+	}
+
+	off, size, err := rt.store.txOffsetAndSize(txID)
+	if err != nil {
+		return 0
+	}
+
+	return off + int64(size)
+}
+
+func (rt *recoveryTracker) progress(txID uint64) {
+	if rt == nil {
+		return
+	}
+
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+
+	if rt.doneTx >= rt.toTx || txID <= rt.doneTx {
+		return
+	}
+
+	rt.doneTx = txID
+
+	if rt.doneTx >= rt.toTx {
+		rt.elapsed = time.Since(rt.startedAt)
+		rt.store.log.Infof("Recovery of %s at '%s' completed in %s", rt.phase, rt.store.path, rt.elapsed.Round(time.Millisecond))
+		return
+	}
+
+	if time.Since(rt.loggedAt) < RecoveryLogInterval {
+		return
+	}
+
+	rt.loggedAt = time.Now()
+
+	st := rt.statusUnlocked()
+
+	rt.store.log.Infof("Recovering %s at '%s': %d/%d transaction/s (%d/%d bytes) replayed, ETA %s",
+		rt.phase, rt.store.path, st.TxReplayed, st.TxTotal, st.BytesProcessed, st.BytesTotal, st.ETA.Round(time.Second))
+}
+
+func (rt *recoveryTracker) status() *RecoveryStatus {
+	if rt == nil {
+		return nil
+	}
+
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+
+	return rt.statusUnlocked()
+}
+
+func (rt *recoveryTracker) statusUnlocked() *RecoveryStatus {
+	st := &RecoveryStatus{
+		Phase:      rt.phase,
+		TxReplayed: rt.doneTx - rt.fromTx,
+		TxTotal:    rt.toTx - rt.fromTx,
+		BytesTotal: rt.toOffset - rt.fromOffset,
+		Completed:  rt.doneTx >= rt.toTx,
+	}
+
+	if st.Completed {
+		st.BytesProcessed = st.BytesTotal
+		st.Elapsed = rt.elapsed
+		return st
+	}
+
+	st.Elapsed = time.Since(rt.startedAt)
+
+	if st.TxReplayed > 0 {
+		st.BytesProcessed = rt.txEnd(rt.doneTx) - rt.fromOffset
+		st.ETA = time.Duration(float64(st.Elapsed) / float64(st.TxReplayed) * float64(st.TxTotal-st.TxReplayed))
+	}
+
+	return st
+}
+
+// RecoveryStatus returns the progress of the indexing of the transactions committed before the store was opened,
+// nil when the index was up to date. Binary linking is recovered while the store is being opened and it's only
+// reported in the log
+func (s *ImmuStore) RecoveryStatus() *RecoveryStatus {
+	return s.indexer.recovery.status()
+}
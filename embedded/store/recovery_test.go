@@ -0,0 +1,88 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecoveryStatus(t *testing.T) {
+	defer os.RemoveAll("data_recovery")
+
+	opts := DefaultOptions().WithSynced(false)
+
+	immuStore, err := Open("data_recovery", opts)
+	require.NoError(t, err)
+	require.Nil(t, immuStore.RecoveryStatus())
+
+	for i := 0; i < 100; i++ {
+		_, err = immuStore.Commit([]*KV{{Key: []byte("key"), Value: []byte("value")}}, false)
+		require.NoError(t, err)
+	}
+
+	err = immuStore.Close()
+	require.NoError(t, err)
+
+	// both the index and the binary linking are rebuilt when the store is opened again
+	require.NoError(t, os.RemoveAll(filepath.Join("data_recovery", indexDirname)))
+	require.NoError(t, os.RemoveAll(filepath.Join("data_recovery", ahtDirname)))
+
+	defaultLogInterval := RecoveryLogInterval
+	RecoveryLogInterval = 0
+	defer func() { RecoveryLogInterval = defaultLogInterval }()
+
+	immuStore, err = Open("data_recovery", opts)
+	require.NoError(t, err)
+	defer immuStore.Close()
+
+	blSize, err := immuStore.BlInfo()
+	require.NoError(t, err)
+	require.Equal(t, uint64(100), blSize)
+
+	st := immuStore.RecoveryStatus()
+	require.NotNil(t, st)
+	require.Equal(t, RecoveryIndexing, st.Phase)
+	require.Equal(t, uint64(100), st.TxTotal)
+	require.Greater(t, st.BytesTotal, int64(0))
+
+	err = immuStore.WaitForIndexingUpto(100, nil)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return immuStore.RecoveryStatus().Completed
+	}, 5*time.Second, 10*time.Millisecond)
+
+	st = immuStore.RecoveryStatus()
+	require.Equal(t, uint64(100), st.TxReplayed)
+	require.Equal(t, st.BytesTotal, st.BytesProcessed)
+	require.Zero(t, st.ETA)
+
+	rt := newRecoveryTracker(immuStore, RecoveryIndexing, 0, 100)
+	rt.progress(50)
+
+	st = rt.status()
+	require.False(t, st.Completed)
+	require.Equal(t, uint64(50), st.TxReplayed)
+	require.Greater(t, st.BytesProcessed, int64(0))
+	require.Less(t, st.BytesProcessed, st.BytesTotal)
+
+	require.Nil(t, newRecoveryTracker(immuStore, RecoveryIndexing, 100, 100))
+}
@@ -56,6 +56,7 @@ func VerifyLinearProof(proof *LinearProof, sourceTxID, targetTxID uint64, source
 
 func VerifyDualProof(proof *DualProof, sourceTxID, targetTxID uint64, sourceAlh, targetAlh [sha256.Size]byte) bool {
 	if proof == nil ||
+		!proof.HashAlgorithm.Supported() ||
 		proof.SourceTxMetadata == nil ||
 		proof.TargetTxMetadata == nil ||
 		proof.SourceTxMetadata.ID != sourceTxID ||
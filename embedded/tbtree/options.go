@@ -51,6 +51,9 @@ type Options struct {
 	compactionThld        int
 	delayDuringCompaction time.Duration
 
+	// warmUp pre-loads the nodes recorded in the access profile persisted when the index was closed
+	warmUp bool
+
 	// options below are only set during initialization and stored as metadata
 	maxNodeSize int
 	fileSize    int
@@ -152,3 +155,8 @@ func (opts *Options) WithDelayDuringCompaction(delay time.Duration) *Options {
 	opts.delayDuringCompaction = delay
 	return opts
 }
+
+func (opts *Options) WithWarmUp(warmUp bool) *Options {
+	opts.warmUp = warmUp
+	return opts
+}
@@ -25,6 +25,8 @@ type Reader struct {
 	seekKey       []byte
 	prefix        []byte
 	inclusiveSeek bool
+	endKey        []byte
+	inclusiveEnd  bool
 	descOrder     bool
 	asBeforeTs    uint64
 	path          path
@@ -37,7 +39,10 @@ type ReaderSpec struct {
 	SeekKey       []byte
 	Prefix        []byte
 	InclusiveSeek bool
-	DescOrder     bool
+	// EndKey stops the reader at the given key in the reading order, it's not applied when empty
+	EndKey       []byte
+	InclusiveEnd bool
+	DescOrder    bool
 }
 
 func (r *Reader) Reset() error {
@@ -121,6 +126,10 @@ func (r *Reader) ReadAsBefore(beforeTs uint64) (key []byte, ts uint64, err error
 			continue
 		}
 
+		if r.pastEnd(leafValue.key) {
+			return nil, 0, ErrNoMoreEntries
+		}
+
 		if len(r.prefix) == 0 {
 			ts, err := leafValue.asBefore(r.snapshot.t.hLog, beforeTs)
 			if err == nil {
@@ -220,6 +229,10 @@ func (r *Reader) Read() (key []byte, value []byte, ts uint64, hc uint64, err err
 			continue
 		}
 
+		if r.pastEnd(leafValue.key) {
+			return nil, nil, 0, 0, ErrNoMoreEntries
+		}
+
 		if len(r.prefix) == 0 {
 			return leafValue.key, leafValue.value, leafValue.ts, leafValue.hCount, nil
 		}
@@ -245,6 +258,20 @@ func (r *Reader) Read() (key []byte, value []byte, ts uint64, hc uint64, err err
 	}
 }
 
+// pastEnd returns true if the key comes after the end key in the reading order
+func (r *Reader) pastEnd(key []byte) bool {
+	if len(r.endKey) == 0 {
+		return false
+	}
+
+	c := bytes.Compare(key, r.endKey)
+	if r.descOrder {
+		c = -c
+	}
+
+	return c > 0 || (c == 0 && !r.inclusiveEnd)
+}
+
 func (r *Reader) Close() error {
 	if r.closed {
 		return ErrAlreadyClosed
@@ -104,6 +104,70 @@ func TestReaderAscendingScan(t *testing.T) {
 	require.Equal(t, ErrAlreadyClosed, err)
 }
 
+func TestReaderWithEndKey(t *testing.T) {
+	tbtree, err := Open("test_tree_rend", DefaultOptions().WithMaxNodeSize(MinNodeSize))
+	require.NoError(t, err)
+	defer os.RemoveAll("test_tree_rend")
+
+	monotonicInsertions(t, tbtree, 1, 1000, true)
+
+	snapshot, err := tbtree.Snapshot()
+	require.NoError(t, err)
+	defer snapshot.Close()
+
+	readAll := func(spec *ReaderSpec) []uint32 {
+		reader, err := snapshot.NewReader(spec)
+		require.NoError(t, err)
+		defer reader.Close()
+
+		var keys []uint32
+
+		for {
+			k, _, _, _, err := reader.Read()
+			if err == ErrNoMoreEntries {
+				return keys
+			}
+			require.NoError(t, err)
+
+			keys = append(keys, binary.BigEndian.Uint32(k))
+		}
+	}
+
+	require.Equal(t, []uint32{250, 251, 252}, readAll(&ReaderSpec{
+		SeekKey:       []byte{0, 0, 0, 250},
+		InclusiveSeek: true,
+		EndKey:        []byte{0, 0, 0, 253},
+	}))
+
+	require.Equal(t, []uint32{250, 251, 252, 253}, readAll(&ReaderSpec{
+		SeekKey:       []byte{0, 0, 0, 250},
+		InclusiveSeek: true,
+		EndKey:        []byte{0, 0, 0, 253},
+		InclusiveEnd:  true,
+	}))
+
+	require.Equal(t, []uint32{253, 252, 251}, readAll(&ReaderSpec{
+		SeekKey:       []byte{0, 0, 0, 253},
+		InclusiveSeek: true,
+		EndKey:        []byte{0, 0, 0, 250},
+		DescOrder:     true,
+	}))
+
+	require.Equal(t, []uint32{2, 1, 0}, readAll(&ReaderSpec{
+		SeekKey:       []byte{0, 0, 0, 2},
+		InclusiveSeek: true,
+		EndKey:        []byte{0, 0, 0, 0},
+		InclusiveEnd:  true,
+		DescOrder:     true,
+	}))
+
+	require.Empty(t, readAll(&ReaderSpec{
+		SeekKey:       []byte{0, 0, 0, 250},
+		InclusiveSeek: true,
+		EndKey:        []byte{0, 0, 0, 200},
+	}))
+}
+
 func TestReaderAscendingScanAsBefore(t *testing.T) {
 	tbtree, err := Open("test_tree_rasc_as_before", DefaultOptions().WithMaxNodeSize(MinNodeSize))
 	require.NoError(t, err)
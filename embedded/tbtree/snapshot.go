@@ -108,7 +108,7 @@ func (s *Snapshot) NewReader(spec *ReaderSpec) (r *Reader, err error) {
 		return nil, ErrAlreadyClosed
 	}
 
-	if spec == nil || len(spec.SeekKey) > s.t.maxKeyLen || len(spec.Prefix) > s.t.maxKeyLen {
+	if spec == nil || len(spec.SeekKey) > s.t.maxKeyLen || len(spec.Prefix) > s.t.maxKeyLen || len(spec.EndKey) > s.t.maxKeyLen {
 		return nil, ErrIllegalArguments
 	}
 
@@ -138,6 +138,8 @@ func (s *Snapshot) NewReader(spec *ReaderSpec) (r *Reader, err error) {
 		seekKey:       seekKey,
 		prefix:        spec.Prefix,
 		inclusiveSeek: inclusiveSeek,
+		endKey:        spec.EndKey,
+		inclusiveEnd:  spec.InclusiveEnd,
 		descOrder:     spec.DescOrder,
 		closed:        false,
 	}
@@ -86,6 +86,7 @@ type TBtree struct {
 	maxKeyLen             int
 	compactionThld        int
 	delayDuringCompaction time.Duration
+	warmUpEnabled         bool
 
 	greatestKey []byte
 
@@ -270,6 +271,7 @@ func OpenWith(path string, nLog, hLog, cLog appendable.Appendable, opts *Options
 		maxKeyLen:             opts.maxKeyLen,
 		compactionThld:        opts.compactionThld,
 		delayDuringCompaction: opts.delayDuringCompaction,
+		warmUpEnabled:         opts.warmUp,
 		greatestKey:           greatestKeyOfSize(opts.maxKeyLen),
 		readOnly:              opts.readOnly,
 		synced:                opts.synced,
@@ -299,6 +301,10 @@ func OpenWith(path string, nLog, hLog, cLog appendable.Appendable, opts *Options
 
 	t.root = root
 
+	if t.warmUpEnabled && t.path != "" {
+		t.warmUp()
+	}
+
 	return t, nil
 }
 
@@ -324,7 +330,8 @@ func (t *TBtree) GetOptions() *Options {
 		WithMaxNodeSize(t.maxNodeSize).
 		WithRenewSnapRootAfter(t.renewSnapRootAfter).
 		WithCompactionThld(t.compactionThld).
-		WithDelayDuringCompaction(t.delayDuringCompaction)
+		WithDelayDuringCompaction(t.delayDuringCompaction).
+		WithWarmUp(t.warmUpEnabled)
 }
 
 func (t *TBtree) cachePut(n node) {
@@ -875,6 +882,13 @@ func (t *TBtree) Close() error {
 		return err
 	}
 
+	if t.warmUpEnabled && t.path != "" && !t.readOnly {
+		err = t.writeWarmUpProfile()
+		if err != nil {
+			t.log.Warningf("Access profile of index '%s' could not be persisted: %v", t.path, err)
+		}
+	}
+
 	t.closed = true
 
 	errors := make([]error, 0)
@@ -0,0 +1,161 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package tbtree
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// WarmUpProfileFilename holds the min keys of the leaf nodes cached when the index was closed
+const WarmUpProfileFilename = "warmup.prof"
+
+// warmUp pre-loads the children of the root node and the leaf nodes, along with their inner nodes,
+// recorded in the access profile persisted during the last close
+func (t *TBtree) warmUp() {
+	start := time.Now()
+
+	if root, ok := t.root.(*innerNode); ok {
+		for _, n := range root.nodes {
+			ref, ok := n.(*nodeRef)
+			if !ok {
+				continue
+			}
+
+			_, err := t.nodeAt(ref.off)
+			if err != nil {
+				t.log.Warningf("Warming up index '%s' aborted due to error: %v", t.path, err)
+				return
+			}
+		}
+	}
+
+	keys, err := t.readWarmUpProfile()
+	if err != nil && !os.IsNotExist(err) {
+		t.log.Warningf("Discarding warm-up profile of index '%s' due to error: %v", t.path, err)
+	}
+
+	for _, k := range keys {
+		if t.cache.EntriesCount() >= t.cacheSize {
+			break
+		}
+
+		// profiled keys may no longer be indexed after a crash, the path to the leaf is loaded anyway
+		_, _, _, err := t.root.findLeafNode(k, nil, nil, false)
+		if err != nil && err != ErrKeyNotFound {
+			t.log.Warningf("Warming up index '%s' aborted due to error: %v", t.path, err)
+			return
+		}
+	}
+
+	t.log.Infof("Index '%s' warmed up with %d nodes in %s", t.path, t.cache.EntriesCount(), time.Since(start))
+}
+
+func (t *TBtree) warmUpProfilePath() string {
+	return filepath.Join(t.path, WarmUpProfileFilename)
+}
+
+func (t *TBtree) writeWarmUpProfile() error {
+	var keys [][]byte
+
+	t.nmutex.Lock()
+
+	err := t.cache.Apply(func(k, v interface{}) error {
+		if l, ok := v.(*leafNode); ok && len(l.values) > 0 {
+			keys = append(keys, l.values[0].key)
+		}
+		return nil
+	})
+
+	t.nmutex.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(t.warmUpProfilePath(), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, t.fileMode)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+
+	var b [4]byte
+
+	for _, k := range keys {
+		binary.BigEndian.PutUint32(b[:], uint32(len(k)))
+
+		_, err = w.Write(b[:])
+		if err == nil {
+			_, err = w.Write(k)
+		}
+		if err != nil {
+			f.Close()
+			return err
+		}
+	}
+
+	err = w.Flush()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+func (t *TBtree) readWarmUpProfile() ([][]byte, error) {
+	f, err := os.Open(t.warmUpProfilePath())
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+
+	var keys [][]byte
+	var b [4]byte
+
+	for len(keys) < t.cacheSize {
+		_, err = io.ReadFull(r, b[:])
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, ErrCorruptedFile
+		}
+
+		kLen := int(binary.BigEndian.Uint32(b[:]))
+		if kLen == 0 || kLen > t.maxKeyLen {
+			return nil, ErrCorruptedFile
+		}
+
+		k := make([]byte, kLen)
+
+		_, err = io.ReadFull(r, k)
+		if err != nil {
+			return nil, ErrCorruptedFile
+		}
+
+		keys = append(keys, k)
+	}
+
+	return keys, nil
+}
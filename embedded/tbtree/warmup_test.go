@@ -0,0 +1,88 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package tbtree
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWarmUp(t *testing.T) {
+	defer os.RemoveAll("test_tree_warmup")
+
+	opts := DefaultOptions().WithMaxNodeSize(256).WithCacheSize(1000).WithWarmUp(true)
+
+	tree, err := Open("test_tree_warmup", DefaultOptions().WithMaxNodeSize(256).WithCacheSize(1000))
+	require.NoError(t, err)
+
+	for i := 0; i < 10_000; i++ {
+		var k [8]byte
+		binary.BigEndian.PutUint64(k[:], uint64(i))
+
+		err = tree.Insert(k[:], k[:])
+		require.NoError(t, err)
+	}
+
+	err = tree.Close()
+	require.NoError(t, err)
+
+	_, err = os.Stat(filepath.Join("test_tree_warmup", WarmUpProfileFilename))
+	require.True(t, os.IsNotExist(err))
+
+	tree, err = Open("test_tree_warmup", opts)
+	require.NoError(t, err)
+	require.Equal(t, opts, tree.GetOptions())
+
+	// only the children of the root node are loaded when there is no profile
+	rootChildren := tree.cache.EntriesCount()
+	require.Equal(t, len(tree.root.(*innerNode).nodes), rootChildren)
+
+	for i := 0; i < 100; i++ {
+		var k [8]byte
+		binary.BigEndian.PutUint64(k[:], uint64(i*100))
+
+		_, _, _, err = tree.Get(k[:])
+		require.NoError(t, err)
+	}
+
+	cachedNodes := tree.cache.EntriesCount()
+	require.Greater(t, cachedNodes, rootChildren)
+
+	err = tree.Close()
+	require.NoError(t, err)
+
+	tree, err = Open("test_tree_warmup", opts)
+	require.NoError(t, err)
+	require.Equal(t, cachedNodes, tree.cache.EntriesCount())
+
+	err = tree.Close()
+	require.NoError(t, err)
+
+	err = ioutil.WriteFile(filepath.Join("test_tree_warmup", WarmUpProfileFilename), []byte{0, 0, 0, 10, 1}, 0644)
+	require.NoError(t, err)
+
+	tree, err = Open("test_tree_warmup", opts)
+	require.NoError(t, err)
+	require.NotZero(t, tree.cache.EntriesCount())
+
+	err = tree.Close()
+	require.NoError(t, err)
+}
@@ -18,6 +18,7 @@ package schema
 import (
 	"crypto/sha256"
 
+	"github.com/codenotary/immudb/embedded/hashing"
 	"github.com/codenotary/immudb/embedded/htree"
 	"github.com/codenotary/immudb/embedded/store"
 )
@@ -65,17 +66,19 @@ func TxFrom(stx *Tx) *store.Tx {
 
 func InclusionProofTo(iproof *htree.InclusionProof) *InclusionProof {
 	return &InclusionProof{
-		Leaf:  int32(iproof.Leaf),
-		Width: int32(iproof.Width),
-		Terms: DigestsTo(iproof.Terms),
+		Leaf:          int32(iproof.Leaf),
+		Width:         int32(iproof.Width),
+		Terms:         DigestsTo(iproof.Terms),
+		HashAlgorithm: uint32(iproof.HashAlgorithm),
 	}
 }
 
 func InclusionProofFrom(iproof *InclusionProof) *htree.InclusionProof {
 	return &htree.InclusionProof{
-		Leaf:  int(iproof.Leaf),
-		Width: int(iproof.Width),
-		Terms: DigestsFrom(iproof.Terms),
+		Leaf:          int(iproof.Leaf),
+		Width:         int(iproof.Width),
+		Terms:         DigestsFrom(iproof.Terms),
+		HashAlgorithm: hashing.Algorithm(iproof.HashAlgorithm),
 	}
 }
 
@@ -88,6 +91,7 @@ func DualProofTo(dualProof *store.DualProof) *DualProof {
 		TargetBlTxAlh:      dualProof.TargetBlTxAlh[:],
 		LastInclusionProof: DigestsTo(dualProof.LastInclusionProof),
 		LinearProof:        LinearProofTo(dualProof.LinearProof),
+		HashAlgorithm:      uint32(dualProof.HashAlgorithm),
 	}
 }
 
@@ -120,6 +124,7 @@ func DualProofFrom(dproof *DualProof) *store.DualProof {
 		TargetBlTxAlh:      DigestFrom(dproof.TargetBlTxAlh),
 		LastInclusionProof: DigestsFrom(dproof.LastInclusionProof),
 		LinearProof:        LinearProofFrom(dproof.LinearProof),
+		HashAlgorithm:      hashing.Algorithm(dproof.HashAlgorithm),
 	}
 }
 
@@ -26,4 +26,5 @@ var (
 	ErrDuplicatedKeysNotSupported       = status.New(codes.InvalidArgument, "duplicated keys are not supported in single batch transaction").Err()
 	ErrDuplicatedZAddNotSupported       = status.New(codes.InvalidArgument, "duplicated index inside zAdd insertions are not supported in single batch transaction").Err()
 	ErrDuplicatedReferencesNotSupported = status.New(codes.InvalidArgument, "duplicated references insertions are not supported in single batch transaction").Err()
+	ErrInvalidPrecondition              = status.New(codes.InvalidArgument, "invalid precondition").Err()
 )
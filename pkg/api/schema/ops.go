@@ -60,5 +60,5 @@ func (m *ExecAllRequest) Validate() error {
 		}
 	}
 
-	return nil
+	return ValidatePreconditions(m.Preconditions)
 }
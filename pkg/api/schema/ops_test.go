@@ -166,3 +166,27 @@ func TestOps_ValidateOperationNilElementFound(t *testing.T) {
 	err := aOps.Validate()
 	require.Equal(t, status.Error(codes.InvalidArgument, "operation is not set"), err)
 }
+
+func TestOps_ValidateErrInvalidPrecondition(t *testing.T) {
+	aOps := &ExecAllRequest{
+		Operations: []*Op{
+			{
+				Operation: &Op_Kv{
+					Kv: &KeyValue{
+						Key:   []byte(`key`),
+						Value: []byte(`val`),
+					},
+				},
+			},
+		},
+		Preconditions: []*Precondition{
+			PreconditionKeyMustNotExist([]byte(`key`)),
+			PreconditionKeyNotModifiedAfterTX(nil, 1),
+		},
+	}
+	err := aOps.Validate()
+	require.Equal(t, err, ErrInvalidPrecondition)
+
+	aOps.Preconditions = aOps.Preconditions[:1]
+	require.NoError(t, aOps.Validate())
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package schema
+
+// PreconditionKeyMustExist returns a precondition holding only if the key exists
+func PreconditionKeyMustExist(key []byte) *Precondition {
+	return &Precondition{
+		Precondition: &Precondition_KeyMustExist{
+			KeyMustExist: &Precondition_KeyMustExistPrecondition{Key: key},
+		},
+	}
+}
+
+// PreconditionKeyMustNotExist returns a precondition holding only if the key doesn't exist
+func PreconditionKeyMustNotExist(key []byte) *Precondition {
+	return &Precondition{
+		Precondition: &Precondition_KeyMustNotExist{
+			KeyMustNotExist: &Precondition_KeyMustNotExistPrecondition{Key: key},
+		},
+	}
+}
+
+// PreconditionKeyNotModifiedAfterTX returns a precondition holding only if the key wasn't assigned a value after
+// the transaction txID, which includes keys not existing at all
+func PreconditionKeyNotModifiedAfterTX(key []byte, txID uint64) *Precondition {
+	return &Precondition{
+		Precondition: &Precondition_KeyNotModifiedAfterTX{
+			KeyNotModifiedAfterTX: &Precondition_KeyNotModifiedAfterTXPrecondition{Key: key, TxID: txID},
+		},
+	}
+}
+
+// Validate checks the precondition is set and refers to a key
+func (p *Precondition) Validate() error {
+	if p == nil {
+		return ErrInvalidPrecondition
+	}
+
+	var key []byte
+
+	switch x := p.Precondition.(type) {
+	case *Precondition_KeyMustExist:
+		key = x.KeyMustExist.GetKey()
+	case *Precondition_KeyMustNotExist:
+		key = x.KeyMustNotExist.GetKey()
+	case *Precondition_KeyNotModifiedAfterTX:
+		key = x.KeyNotModifiedAfterTX.GetKey()
+	}
+
+	if len(key) == 0 {
+		return ErrInvalidPrecondition
+	}
+
+	return nil
+}
+
+// ValidatePreconditions checks all the preconditions are valid
+func ValidatePreconditions(preconditions []*Precondition) error {
+	for _, p := range preconditions {
+		err := p.Validate()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -25,7 +25,19 @@ import (
 )
 
 func (state *ImmutableState) ToBytes() []byte {
-	b := make([]byte, 4+len(state.Db)+8+sha256.Size)
+	l := 4 + len(state.Db) + 8 + sha256.Size
+
+	// the write-once flag is only appended when set, so states of other databases are signed as before
+	if state.Worm {
+		l++
+	}
+
+	// likewise the hash algorithm is only appended when it's not SHA-256, any other one being covered by the signature
+	if state.HashAlgorithm != 0 {
+		l += 4
+	}
+
+	b := make([]byte, l)
 	i := 0
 
 	binary.BigEndian.PutUint32(b[i:], uint32(len(state.Db)))
@@ -38,6 +50,16 @@ func (state *ImmutableState) ToBytes() []byte {
 	i += 8
 
 	copy(b[i:], state.TxHash[:])
+	i += sha256.Size
+
+	if state.Worm {
+		b[i] = 1
+		i++
+	}
+
+	if state.HashAlgorithm != 0 {
+		binary.BigEndian.PutUint32(b[i:], state.HashAlgorithm)
+	}
 
 	return b
 }
@@ -49,3 +71,84 @@ func (state *ImmutableState) CheckSignature(key *ecdsa.PublicKey) (ok bool, err
 	}
 	return signer.Verify(state.ToBytes(), state.Signature.Signature, key)
 }
+
+func (state *CombinedState) ToBytes() []byte {
+	b := make([]byte, 4+len(state.Db)+2*(8+sha256.Size))
+	i := 0
+
+	binary.BigEndian.PutUint32(b[i:], uint32(len(state.Db)))
+	i += 4
+
+	copy(b[i:], []byte(state.Db))
+	i += len(state.Db)
+
+	binary.BigEndian.PutUint64(b[i:], state.TxId)
+	i += 8
+
+	copy(b[i:], state.TxHash[:])
+	i += sha256.Size
+
+	binary.BigEndian.PutUint64(b[i:], state.CatalogTxId)
+	i += 8
+
+	copy(b[i:], state.CatalogTxHash[:])
+
+	return b
+}
+
+// Root returns the digest anchoring both the database and the SQL catalog states
+func (state *CombinedState) Root() [sha256.Size]byte {
+	return sha256.Sum256(state.ToBytes())
+}
+
+// CheckSignature
+func (state *CombinedState) CheckSignature(key *ecdsa.PublicKey) (ok bool, err error) {
+	if state.Signature == nil {
+		return false, errors.New("no signature found")
+	}
+	return signer.Verify(state.ToBytes(), state.Signature.Signature, key)
+}
+
+// rewindAttestationTag prefixes the signed form of rewind attestations, so they can't be mistaken for signed states
+var rewindAttestationTag = []byte("REWIND")
+
+// ToBytes returns the signed form of the attestation. The transaction recording it is not part of it,
+// since it's only known once the attestation is committed
+func (a *RewindAttestation) ToBytes() []byte {
+	b := make([]byte, len(rewindAttestationTag)+4+len(a.Db)+8+sha256.Size+8+4+len(a.IssuedBy)+4+len(a.Reason))
+	i := copy(b, rewindAttestationTag)
+
+	binary.BigEndian.PutUint32(b[i:], uint32(len(a.Db)))
+	i += 4
+
+	i += copy(b[i:], []byte(a.Db))
+
+	binary.BigEndian.PutUint64(b[i:], a.TxId)
+	i += 8
+
+	copy(b[i:], a.TxHash)
+	i += sha256.Size
+
+	binary.BigEndian.PutUint64(b[i:], uint64(a.IssuedAt))
+	i += 8
+
+	binary.BigEndian.PutUint32(b[i:], uint32(len(a.IssuedBy)))
+	i += 4
+
+	i += copy(b[i:], []byte(a.IssuedBy))
+
+	binary.BigEndian.PutUint32(b[i:], uint32(len(a.Reason)))
+	i += 4
+
+	copy(b[i:], []byte(a.Reason))
+
+	return b
+}
+
+// CheckSignature
+func (a *RewindAttestation) CheckSignature(key *ecdsa.PublicKey) (ok bool, err error) {
+	if a.Signature == nil {
+		return false, errors.New("no signature found")
+	}
+	return signer.Verify(a.ToBytes(), a.Signature.Signature, key)
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/codenotary/immudb/pkg/signer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImmutableStateSignedHashAlgorithm(t *testing.T) {
+	state := &ImmutableState{Db: "db", TxId: 1, TxHash: make([]byte, 32)}
+
+	// states of SHA-256 databases are signed as before the algorithm was part of them
+	require.Len(t, state.ToBytes(), 4+2+8+32)
+
+	state.HashAlgorithm = 1
+	require.Len(t, state.ToBytes(), 4+2+8+32+4)
+
+	state.Worm = true
+	require.Len(t, state.ToBytes(), 4+2+8+32+1+4)
+
+	pk, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	sig, pub, err := signer.NewSignerFromPKey(rand.Reader, pk).Sign(state.ToBytes())
+	require.NoError(t, err)
+
+	state.Signature = &Signature{Signature: sig, PublicKey: pub}
+
+	ok, err := state.CheckSignature(&pk.PublicKey)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// the algorithm can't be replaced without invalidating the signature
+	state.HashAlgorithm = 0
+
+	ok, err = state.CheckSignature(&pk.PublicKey)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
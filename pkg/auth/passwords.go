@@ -17,17 +17,29 @@ limitations under the License.
 package auth
 
 import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"unicode"
 
+	"github.com/codenotary/immudb/pkg/fips"
 	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/pbkdf2"
 )
 
-// HashAndSaltPassword hashes and salts the provided password
+// HashAndSaltPassword hashes and salts the provided password.
+// bcrypt is used unless running in FIPS mode, where PBKDF2 with HMAC-SHA256 is used instead
 func HashAndSaltPassword(plainPassword []byte) ([]byte, error) {
+	if fips.Enabled {
+		return pbkdf2HashPassword(plainPassword)
+	}
+
 	hashedPasswordBytes, err := bcrypt.GenerateFromPassword(plainPassword, bcrypt.DefaultCost)
 	if err != nil {
 		return nil, fmt.Errorf("error hashing password: %v", err)
@@ -35,11 +47,82 @@ func HashAndSaltPassword(plainPassword []byte) ([]byte, error) {
 	return hashedPasswordBytes, nil
 }
 
-// ComparePasswords compares the provided plainPassword against the provided hashed password
+// ComparePasswords compares the provided plainPassword against the provided hashed password.
+// bcrypt hashes are verified in FIPS mode as well so existing users are not locked out,
+// UpgradePasswordHash must then be used to replace them once the password is verified
 func ComparePasswords(hashedPassword []byte, plainPassword []byte) error {
+	if bytes.HasPrefix(hashedPassword, []byte(pbkdf2Prefix)) {
+		return pbkdf2ComparePasswords(hashedPassword, plainPassword)
+	}
+
 	return bcrypt.CompareHashAndPassword(hashedPassword, plainPassword)
 }
 
+// UpgradePasswordHash returns a PBKDF2 hash of the already verified plainPassword when running in FIPS mode
+// and hashedPassword is a bcrypt hash. nil is returned when the hash does not need to be upgraded
+func UpgradePasswordHash(hashedPassword []byte, plainPassword []byte) ([]byte, error) {
+	return upgradePasswordHash(hashedPassword, plainPassword, fips.Enabled)
+}
+
+func upgradePasswordHash(hashedPassword []byte, plainPassword []byte, fipsMode bool) ([]byte, error) {
+	if !fipsMode || bytes.HasPrefix(hashedPassword, []byte(pbkdf2Prefix)) {
+		return nil, nil
+	}
+
+	return pbkdf2HashPassword(plainPassword)
+}
+
+const pbkdf2Prefix = "$pbkdf2-sha256$"
+const pbkdf2Iterations = 100000
+const pbkdf2SaltLen = 16
+const pbkdf2KeyLen = 32
+
+// pbkdf2HashPassword returns the hash encoded as $pbkdf2-sha256$<iterations>$<salt>$<key>
+func pbkdf2HashPassword(plainPassword []byte) ([]byte, error) {
+	salt := make([]byte, pbkdf2SaltLen)
+
+	_, err := rand.Read(salt)
+	if err != nil {
+		return nil, fmt.Errorf("error hashing password: %v", err)
+	}
+
+	return pbkdf2Encode(plainPassword, salt, pbkdf2Iterations), nil
+}
+
+func pbkdf2Encode(plainPassword, salt []byte, iterations int) []byte {
+	key := pbkdf2.Key(plainPassword, salt, iterations, pbkdf2KeyLen, sha256.New)
+
+	return []byte(fmt.Sprintf("%s%d$%s$%s",
+		pbkdf2Prefix,
+		iterations,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	))
+}
+
+func pbkdf2ComparePasswords(hashedPassword []byte, plainPassword []byte) error {
+	parts := strings.Split(strings.TrimPrefix(string(hashedPassword), pbkdf2Prefix), "$")
+	if len(parts) != 3 {
+		return errors.New("malformed password hash")
+	}
+
+	iterations, err := strconv.Atoi(parts[0])
+	if err != nil || iterations <= 0 {
+		return errors.New("malformed password hash")
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return errors.New("malformed password hash")
+	}
+
+	if subtle.ConstantTimeCompare(hashedPassword, pbkdf2Encode(plainPassword, salt, iterations)) != 1 {
+		return bcrypt.ErrMismatchedHashAndPassword
+	}
+
+	return nil
+}
+
 const minPasswordLen = 8
 const maxPasswordLen = 32
 
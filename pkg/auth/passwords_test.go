@@ -20,6 +20,8 @@ import (
 	"encoding/base64"
 	"strings"
 	"testing"
+
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestIsStrongPassword(t *testing.T) {
@@ -61,3 +63,55 @@ func TestDecodeBase64Password(t *testing.T) {
 	}
 
 }
+
+func TestPBKDF2Passwords(t *testing.T) {
+	hashed, err := pbkdf2HashPassword([]byte("1~Password"))
+	if err != nil {
+		t.Fatalf("pbkdf2HashPassword %s", err)
+	}
+	if !strings.HasPrefix(string(hashed), pbkdf2Prefix) {
+		t.Errorf("pbkdf2HashPassword unexpected hash format")
+	}
+	if err := ComparePasswords(hashed, []byte("1~Password")); err != nil {
+		t.Errorf("ComparePasswords failed to match password %s", err)
+	}
+	if err := ComparePasswords(hashed, []byte("2~Password")); err == nil {
+		t.Errorf("ComparePasswords matched wrong password")
+	}
+	if err := ComparePasswords([]byte(pbkdf2Prefix+"x$y"), []byte("1~Password")); err == nil {
+		t.Errorf("ComparePasswords failed to detect malformed hash")
+	}
+}
+
+func TestUpgradePasswordHash(t *testing.T) {
+	legacy, err := bcrypt.GenerateFromPassword([]byte("1~Password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("bcrypt.GenerateFromPassword %s", err)
+	}
+
+	// users created before switching to FIPS mode must still be able to login
+	if err := ComparePasswords(legacy, []byte("1~Password")); err != nil {
+		t.Fatalf("ComparePasswords failed to match bcrypt password %s", err)
+	}
+
+	upgraded, err := upgradePasswordHash(legacy, []byte("1~Password"), false)
+	if err != nil || upgraded != nil {
+		t.Errorf("upgradePasswordHash unexpectedly upgraded hash outside FIPS mode")
+	}
+
+	upgraded, err = upgradePasswordHash(legacy, []byte("1~Password"), true)
+	if err != nil {
+		t.Fatalf("upgradePasswordHash %s", err)
+	}
+	if !strings.HasPrefix(string(upgraded), pbkdf2Prefix) {
+		t.Errorf("upgradePasswordHash unexpected hash format")
+	}
+	if err := ComparePasswords(upgraded, []byte("1~Password")); err != nil {
+		t.Errorf("ComparePasswords failed to match upgraded password %s", err)
+	}
+
+	again, err := upgradePasswordHash(upgraded, []byte("1~Password"), true)
+	if err != nil || again != nil {
+		t.Errorf("upgradePasswordHash unexpectedly upgraded PBKDF2 hash")
+	}
+}
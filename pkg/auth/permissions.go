@@ -32,14 +32,30 @@ const (
 var methodsPermissions = map[string][]uint32{
 	// readwrite methods
 	"Set":                    {PermissionSysAdmin, PermissionAdmin, PermissionRW},
+	"SetIf":                  {PermissionSysAdmin, PermissionAdmin, PermissionRW},
+	"Delete":                 {PermissionSysAdmin, PermissionAdmin, PermissionRW},
+	"DeleteByPrefix":         {PermissionSysAdmin, PermissionAdmin, PermissionRW},
+	"PutObject":              {PermissionSysAdmin, PermissionAdmin, PermissionRW},
+	"Increment":              {PermissionSysAdmin, PermissionAdmin, PermissionRW},
 	"VerifiableSet":          {PermissionSysAdmin, PermissionAdmin, PermissionRW},
 	"StreamSet":              {PermissionSysAdmin, PermissionAdmin, PermissionRW},
+	"SetStream":              {PermissionSysAdmin, PermissionAdmin, PermissionRW},
 	"StreamVerifiableSet":    {PermissionSysAdmin, PermissionAdmin, PermissionRW},
 	"Get":                    {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
 	"VerifiableGet":          {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
 	"StreamGet":              {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
+	"GetStream":              {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
+	"GetObject":              {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
+	"ListObjectVersions":     {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
+	"ListSecondaryIndexes":   {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
+	"IndexScan":              {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
+	"GenerateBindings":       {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
+	"NotarizationReceipt":    {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
+	"ListReferences":         {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
 	"StreamVerifiableGet":    {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
 	"GetAll":                 {PermissionSysAdmin, PermissionAdmin, PermissionRW},
+	"GetMulti":               {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
+	"VerifiableGetMulti":     {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
 	"ExecAll":                {PermissionSysAdmin, PermissionAdmin, PermissionRW},
 	"StreamExecAll":          {PermissionSysAdmin, PermissionAdmin, PermissionRW},
 	"SetReference":           {PermissionSysAdmin, PermissionAdmin, PermissionRW},
@@ -47,38 +63,102 @@ var methodsPermissions = map[string][]uint32{
 	"ZAdd":                   {PermissionSysAdmin, PermissionAdmin, PermissionRW},
 	"VerifiableZAdd":         {PermissionSysAdmin, PermissionAdmin, PermissionRW},
 	"ZScan":                  {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
+	"ZRem":                   {PermissionSysAdmin, PermissionAdmin, PermissionRW},
+	"ZCount":                 {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
+	"ZCard":                  {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
+	"ZAddMulti":              {PermissionSysAdmin, PermissionAdmin, PermissionRW},
+	"ZRank":                  {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
+	"ZIncrBy":                {PermissionSysAdmin, PermissionAdmin, PermissionRW},
+	"ZUnionStore":            {PermissionSysAdmin, PermissionAdmin, PermissionRW},
+	"ZInterStore":            {PermissionSysAdmin, PermissionAdmin, PermissionRW},
+	"ZPopMin":                {PermissionSysAdmin, PermissionAdmin, PermissionRW},
+	"ZPopMax":                {PermissionSysAdmin, PermissionAdmin, PermissionRW},
+	"GetApprovalRules":       {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
+	"ProposeWrite":           {PermissionSysAdmin, PermissionAdmin, PermissionRW},
+	"ListPendingWrites":      {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
+	"ApproveWrite":           {PermissionSysAdmin, PermissionAdmin, PermissionRW},
+	"RejectWrite":            {PermissionSysAdmin, PermissionAdmin, PermissionRW},
+	"ListLegalHolds":         {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
+	"LegalHoldHistory":       {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
+	"ListPinnedSnapshots":    {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
 	"StreamZScan":            {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
 	"VerifiableTxByID":       {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
+	"TxRangeProofs":          {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
+	"ConsistencyProof":       {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
+	"CompareState":           {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
+	"GetRewindAttestation":   {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
 	"IScan":                  {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
 	"Scan":                   {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
 	"StreamScan":             {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
+	"OpenIterator":           {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
+	"IteratorNext":           {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
+	"KeepAliveIterator":      {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
+	"CloseIterator":          {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
 	"History":                {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
 	"StreamHistory":          {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
+	"HistoryStream":          {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
+	"ZScanStream":            {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
 	"TxByID":                 {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
 	"TxScan":                 {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
+	"Diff":                   {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
+	"ExportTx":               {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
 	"Count":                  {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
 	"CountAll":               {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
 	"DatabaseList":           {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
 	"CurrentState":           {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
+	"CurrentCombinedState":   {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
 	"SQLExec":                {PermissionSysAdmin, PermissionAdmin, PermissionRW},
 	"UseSnapshot":            {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
 	"SQLQuery":               {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
+	"NewSQLTx":               {PermissionSysAdmin, PermissionAdmin, PermissionRW},
+	"SQLTxExec":              {PermissionSysAdmin, PermissionAdmin, PermissionRW},
+	"SQLTxQuery":             {PermissionSysAdmin, PermissionAdmin, PermissionRW},
+	"CommitSQLTx":            {PermissionSysAdmin, PermissionAdmin, PermissionRW},
+	"RollbackSQLTx":          {PermissionSysAdmin, PermissionAdmin, PermissionRW},
 	"ListTables":             {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
 	"DescribeTable":          {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
+	"SQLRowProvenance":       {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
 	"VerifiableSQLGet":       {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
+	"GetKeyRules":            {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
+	"Watch":                  {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
+	"WatchAck":               {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
+	"Unwatch":                {PermissionSysAdmin, PermissionAdmin, PermissionRW, PermissionR},
 
 	// admin methods
-	"ListUsers":        {PermissionSysAdmin, PermissionAdmin},
-	"CreateUser":       {PermissionSysAdmin, PermissionAdmin},
-	"ChangePassword":   {PermissionSysAdmin, PermissionAdmin},
-	"SetPermission":    {PermissionSysAdmin, PermissionAdmin},
-	"DeactivateUser":   {PermissionSysAdmin, PermissionAdmin},
-	"SetActiveUser":    {PermissionSysAdmin, PermissionAdmin},
-	"UpdateAuthConfig": {PermissionSysAdmin},
-	"UpdateMTLSConfig": {PermissionSysAdmin},
-	"CreateDatabase":   {PermissionSysAdmin},
-	"Dump":             {PermissionSysAdmin, PermissionAdmin},
-	"CleanIndex":       {PermissionSysAdmin, PermissionAdmin},
+	"ListUsers":             {PermissionSysAdmin, PermissionAdmin},
+	"CreateUser":            {PermissionSysAdmin, PermissionAdmin},
+	"ChangePassword":        {PermissionSysAdmin, PermissionAdmin},
+	"SetPermission":         {PermissionSysAdmin, PermissionAdmin},
+	"DeactivateUser":        {PermissionSysAdmin, PermissionAdmin},
+	"SetActiveUser":         {PermissionSysAdmin, PermissionAdmin},
+	"UpdateAuthConfig":      {PermissionSysAdmin},
+	"UpdateMTLSConfig":      {PermissionSysAdmin},
+	"CreateDatabase":        {PermissionSysAdmin},
+	"Dump":                  {PermissionSysAdmin, PermissionAdmin},
+	"CleanIndex":            {PermissionSysAdmin, PermissionAdmin},
+	"ListOperations":        {PermissionSysAdmin},
+	"CancelOperation":       {PermissionSysAdmin},
+	"CreateEnrollmentToken": {PermissionSysAdmin, PermissionAdmin},
+	"TopPrefixes":           {PermissionSysAdmin, PermissionAdmin},
+	"KeyspaceHistogram":     {PermissionSysAdmin, PermissionAdmin},
+	"SetKeyRules":           {PermissionSysAdmin, PermissionAdmin},
+	"SetApprovalRules":      {PermissionSysAdmin, PermissionAdmin},
+	"PlaceLegalHold":        {PermissionSysAdmin, PermissionAdmin},
+	"LiftLegalHold":         {PermissionSysAdmin, PermissionAdmin},
+	"CreateSecondaryIndex":  {PermissionSysAdmin, PermissionAdmin},
+	"DropSecondaryIndex":    {PermissionSysAdmin, PermissionAdmin},
+	"PinSnapshot":           {PermissionSysAdmin, PermissionAdmin},
+	"UnpinSnapshot":         {PermissionSysAdmin, PermissionAdmin},
+	"AdvanceEpoch":          {PermissionSysAdmin, PermissionAdmin},
+	"AttestRewind":          {PermissionSysAdmin, PermissionAdmin},
+	"ScheduleJob":           {PermissionSysAdmin},
+	"UnscheduleJob":         {PermissionSysAdmin},
+	"ListJobSchedules":      {PermissionSysAdmin},
+	"ListJobRuns":           {PermissionSysAdmin},
+	"ListJobs":              {PermissionSysAdmin},
+	"GetJob":                {PermissionSysAdmin},
+	"CancelJob":             {PermissionSysAdmin},
+	"WatchJob":              {PermissionSysAdmin},
 }
 
 //HasPermissionForMethod checks if userPermission can access method name
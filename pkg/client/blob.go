@@ -0,0 +1,173 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"io"
+	"time"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/database"
+)
+
+// SetStream assigns the key a blob with the content read from r, sent in chunks so it doesn't have to fit in memory.
+// The returned manifest is checked against the size and digest of the content sent
+func (c *immuClient) SetStream(ctx context.Context, key []byte, r io.Reader) (*schema.BlobManifest, error) {
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	start := time.Now()
+	defer func() { c.Logger.Debugf("SetStream finished in %s", time.Since(start)) }()
+
+	str, err := c.ServiceClient.SetStream(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	digest := sha256.New()
+	size := uint64(0)
+
+	buf := make([]byte, c.Options.StreamChunkSize)
+
+	for first := true; ; first = false {
+		n, rerr := io.ReadFull(r, buf)
+		if rerr != nil && rerr != io.EOF && rerr != io.ErrUnexpectedEOF {
+			str.CloseSend()
+			return nil, rerr
+		}
+
+		if n == 0 && !first {
+			break
+		}
+
+		digest.Write(buf[:n])
+		size += uint64(n)
+
+		chunk := &schema.BlobChunk{Content: buf[:n]}
+		if first {
+			chunk.Manifest = &schema.BlobManifest{Key: key}
+		}
+
+		// once the server ended the call, its error is returned by CloseAndRecv
+		err = str.Send(chunk)
+		if err == io.EOF || rerr != nil {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	manifest, err := str.CloseAndRecv()
+	if err != nil {
+		return nil, err
+	}
+
+	if manifest.Size != size || !bytes.Equal(manifest.Digest, digest.Sum(nil)) {
+		return nil, store.ErrCorruptedData
+	}
+
+	return manifest, nil
+}
+
+// GetStream writes the content of the blob assigned to the key to w, as it's received in chunks. The content is
+// then checked against the size and digest of the returned manifest, store.ErrCorruptedData is returned once
+// the whole content was written if they don't match
+func (c *immuClient) GetStream(ctx context.Context, req *schema.KeyRequest, w io.Writer) (*schema.BlobManifest, error) {
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	start := time.Now()
+	defer func() { c.Logger.Debugf("GetStream finished in %s", time.Since(start)) }()
+
+	str, err := c.ServiceClient.GetStream(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest *schema.BlobManifest
+
+	digest := sha256.New()
+	size := uint64(0)
+
+	for {
+		chunk, err := str.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if manifest == nil {
+			if chunk.Manifest == nil {
+				return nil, store.ErrCorruptedData
+			}
+			manifest = chunk.Manifest
+		}
+
+		_, err = w.Write(chunk.Content)
+		if err != nil {
+			return nil, err
+		}
+
+		digest.Write(chunk.Content)
+		size += uint64(len(chunk.Content))
+	}
+
+	if manifest == nil || manifest.Size != size || !bytes.Equal(manifest.Digest, digest.Sum(nil)) {
+		return nil, store.ErrCorruptedData
+	}
+
+	return manifest, nil
+}
+
+// VerifiedGetStream is GetStream of a blob whose manifest is verified first as VerifiedGet does, so the content is
+// checked against a digest covered by the proofs of the key
+func (c *immuClient) VerifiedGetStream(ctx context.Context, key []byte, w io.Writer) (*schema.BlobManifest, error) {
+	e, err := c.VerifiedGet(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if !e.Blob {
+		return nil, database.ErrNotBlob
+	}
+
+	verified, err := database.DecodeBlobManifest(e.Key, e.Value, e.Tx)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := c.GetStream(ctx, &schema.KeyRequest{Key: e.Key, AtTx: e.Tx}, w)
+	if err != nil {
+		return nil, err
+	}
+
+	if manifest.Id != verified.Id || manifest.Chunks != verified.Chunks || manifest.Size != verified.Size ||
+		!bytes.Equal(manifest.Digest, verified.Digest) {
+		return nil, store.ErrCorruptedData
+	}
+
+	return verified, nil
+}
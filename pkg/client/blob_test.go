@@ -0,0 +1,97 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"os"
+	"testing"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/server"
+	"github.com/codenotary/immudb/pkg/server/servertest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestImmuClient_Blob(t *testing.T) {
+	options := server.DefaultOptions().WithAuth(true)
+	bs := servertest.NewBufconnServer(options)
+
+	defer os.RemoveAll(options.Dir)
+	defer os.Remove(".state-")
+
+	bs.Start()
+	defer bs.Stop()
+
+	ts := NewTokenService().WithTokenFileName("testTokenFile").WithHds(DefaultHomedirServiceMock())
+	client, err := NewImmuClient(DefaultOptions().WithDialOptions(&[]grpc.DialOption{grpc.WithContextDialer(bs.Dialer), grpc.WithInsecure()}).WithTokenService(ts))
+	require.NoError(t, err)
+	defer client.Disconnect()
+
+	lr, err := client.Login(context.TODO(), []byte(`immudb`), []byte(`immudb`))
+	require.NoError(t, err)
+
+	ctx := metadata.NewOutgoingContext(context.Background(), metadata.Pairs("authorization", lr.Token))
+
+	content := make([]byte, 5<<20+123)
+	_, err = rand.Read(content)
+	require.NoError(t, err)
+
+	manifest, err := client.SetStream(ctx, []byte(`blob`), bytes.NewReader(content))
+	require.NoError(t, err)
+	require.Equal(t, uint64(len(content)), manifest.Size)
+
+	digest := sha256.Sum256(content)
+	require.Equal(t, digest[:], manifest.Digest)
+
+	var buf bytes.Buffer
+	read, err := client.GetStream(ctx, &schema.KeyRequest{Key: []byte(`blob`)}, &buf)
+	require.NoError(t, err)
+	require.Equal(t, manifest.Tx, read.Tx)
+	require.Equal(t, content, buf.Bytes())
+
+	buf.Reset()
+	verified, err := client.VerifiedGetStream(ctx, []byte(`blob`), &buf)
+	require.NoError(t, err)
+	require.Equal(t, manifest.Digest, verified.Digest)
+	require.Equal(t, content, buf.Bytes())
+
+	_, err = client.SetStream(ctx, []byte(`empty`), bytes.NewReader(nil))
+	require.NoError(t, err)
+
+	buf.Reset()
+	_, err = client.VerifiedGetStream(ctx, []byte(`empty`), &buf)
+	require.NoError(t, err)
+	require.Zero(t, buf.Len())
+
+	_, err = client.Set(ctx, []byte(`plain`), []byte(`value`))
+	require.NoError(t, err)
+
+	_, err = client.GetStream(ctx, &schema.KeyRequest{Key: []byte(`plain`)}, &buf)
+	require.Error(t, err)
+
+	_, err = client.VerifiedGetStream(ctx, []byte(`plain`), &buf)
+	require.Error(t, err)
+
+	_, err = client.SetStream(ctx, nil, bytes.NewReader(content))
+	require.Error(t, err)
+}
@@ -0,0 +1,300 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"container/list"
+	"encoding/binary"
+	"sync"
+	"time"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/golang/protobuf/proto"
+)
+
+// EntryCache keeps entries read from the server keyed by database, key and transaction.
+// Since the value of a key at a given transaction can not change, cached entries only need to be dropped once they expire.
+// Entries may also be recorded as the latest value of their keys, until a newer write of the key is invalidated.
+// Verified flags entries whose inclusion and consistency were checked against a trusted state.
+// Entries are copied when stored and returned, so callers are free to modify them.
+type EntryCache interface {
+	Get(db string, key []byte, tx uint64, verified bool) (*schema.Entry, bool)
+	Set(db string, key []byte, entry *schema.Entry, verified bool)
+	// GetLatest returns the entry recorded as the latest value of key
+	GetLatest(db string, key []byte, verified bool) (*schema.Entry, bool)
+	// SetLatest stores the entry and records it as the latest value of key, unless any key was invalidated since
+	// seq was returned by InvalidationSeq, as the entry may have been read before the invalidated write
+	SetLatest(db string, key []byte, entry *schema.Entry, verified bool, seq uint64)
+	// InvalidationSeq returns the number of invalidations, it must be taken before reading the latest value of a key
+	InvalidationSeq() uint64
+	// Invalidate notifies key was written at tx, so entries read before are no longer its latest value
+	Invalidate(db string, key []byte, tx uint64)
+	// InvalidateAll forgets the latest value of every key of db
+	InvalidateAll(db string)
+	// Verified returns the verified entries of a database, so they can be checked again against newer states
+	Verified(db string) []*schema.Entry
+	Remove(db string, key []byte, tx uint64)
+	Len() int
+}
+
+type cachedEntry struct {
+	id       string
+	db       string
+	entry    *schema.Entry
+	verified bool
+	// id of the key in the latest entries, when the entry is the latest value of its key
+	latest string
+}
+
+// expired returns true once the entry can no longer be read, as it happens on the server
+func (ce *cachedEntry) expired(now time.Time) bool {
+	return ce.entry.ExpiresAt > 0 && now.Unix() >= ce.entry.ExpiresAt
+}
+
+type inMemoryEntryCache struct {
+	maxEntries    int
+	entries       map[string]*list.Element
+	latest        map[string]*list.Element
+	invalidations uint64
+	lru           *list.List
+	mutex         sync.Mutex
+}
+
+// NewInMemoryEntryCache returns a new in-memory entry cache holding up to maxEntries entries.
+// Least recently used entries are evicted first.
+func NewInMemoryEntryCache(maxEntries int) EntryCache {
+	return &inMemoryEntryCache{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		latest:     make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+func entryID(db string, key []byte, tx uint64) string {
+	b := make([]byte, 8+len(db)+len(key)+8)
+	binary.BigEndian.PutUint64(b, uint64(len(db)))
+	copy(b[8:], db)
+	copy(b[8+len(db):], key)
+	binary.BigEndian.PutUint64(b[8+len(db)+len(key):], tx)
+	return string(b)
+}
+
+func latestID(db string, key []byte) string {
+	return entryID(db, key, 0)
+}
+
+// Get returns the entry of key at tx. If verified is true, only entries which were verified are returned.
+// Expired entries are dropped
+func (c *inMemoryEntryCache) Get(db string, key []byte, tx uint64, verified bool) (*schema.Entry, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.get(c.entries[entryID(db, key, tx)], verified)
+}
+
+// get must be called while holding the mutex, el may be nil
+func (c *inMemoryEntryCache) get(el *list.Element, verified bool) (*schema.Entry, bool) {
+	if el == nil {
+		return nil, false
+	}
+
+	ce := el.Value.(*cachedEntry)
+
+	if ce.expired(time.Now()) {
+		c.remove(el)
+		return nil, false
+	}
+
+	if verified && !ce.verified {
+		return nil, false
+	}
+
+	c.lru.MoveToFront(el)
+
+	return proto.Clone(ce.entry).(*schema.Entry), true
+}
+
+// Set stores the entry of key at entry.Tx. Resolved references are not cached as they may point to newer values
+func (c *inMemoryEntryCache) Set(db string, key []byte, entry *schema.Entry, verified bool) {
+	if c.maxEntries <= 0 || entry == nil || entry.ReferencedBy != nil {
+		return
+	}
+
+	entry = proto.Clone(entry).(*schema.Entry)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.set(db, key, entry, verified)
+}
+
+// set must be called while holding the mutex
+func (c *inMemoryEntryCache) set(db string, key []byte, entry *schema.Entry, verified bool) *list.Element {
+	id := entryID(db, key, entry.Tx)
+
+	if el, ok := c.entries[id]; ok {
+		ce := el.Value.(*cachedEntry)
+		ce.entry = entry
+		ce.verified = ce.verified || verified
+		c.lru.MoveToFront(el)
+		return el
+	}
+
+	el := c.lru.PushFront(&cachedEntry{id: id, db: db, entry: entry, verified: verified})
+	c.entries[id] = el
+
+	for c.lru.Len() > c.maxEntries {
+		c.remove(c.lru.Back())
+	}
+
+	return el
+}
+
+// GetLatest returns the entry recorded as the latest value of key. If verified is true, it's only returned if it was verified
+func (c *inMemoryEntryCache) GetLatest(db string, key []byte, verified bool) (*schema.Entry, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.get(c.latest[latestID(db, key)], verified)
+}
+
+// SetLatest stores the entry of key at entry.Tx and records it as the latest value of key, unless any key was
+// invalidated since seq was taken or a newer entry of key is already recorded
+func (c *inMemoryEntryCache) SetLatest(db string, key []byte, entry *schema.Entry, verified bool, seq uint64) {
+	if c.maxEntries <= 0 || entry == nil || entry.ReferencedBy != nil {
+		return
+	}
+
+	entry = proto.Clone(entry).(*schema.Entry)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	el := c.set(db, key, entry, verified)
+
+	if c.invalidations != seq {
+		return
+	}
+
+	lid := latestID(db, key)
+
+	if prev, ok := c.latest[lid]; ok {
+		if prev.Value.(*cachedEntry).entry.Tx > entry.Tx {
+			return
+		}
+		prev.Value.(*cachedEntry).latest = ""
+	}
+
+	el.Value.(*cachedEntry).latest = lid
+	c.latest[lid] = el
+}
+
+// InvalidationSeq returns the number of invalidations
+func (c *inMemoryEntryCache) InvalidationSeq() uint64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.invalidations
+}
+
+// Invalidate notifies key was written at tx, the entry recorded as its latest value is dropped if it's older
+func (c *inMemoryEntryCache) Invalidate(db string, key []byte, tx uint64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.invalidations++
+
+	lid := latestID(db, key)
+
+	if el, ok := c.latest[lid]; ok && el.Value.(*cachedEntry).entry.Tx < tx {
+		el.Value.(*cachedEntry).latest = ""
+		delete(c.latest, lid)
+	}
+}
+
+// InvalidateAll forgets the latest value of every key of db, the entries stay cached by transaction
+func (c *inMemoryEntryCache) InvalidateAll(db string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.invalidations++
+
+	for lid, el := range c.latest {
+		ce := el.Value.(*cachedEntry)
+		if ce.db == db {
+			ce.latest = ""
+			delete(c.latest, lid)
+		}
+	}
+}
+
+// Verified returns the verified entries of db which didn't expire, most recently used first
+func (c *inMemoryEntryCache) Verified(db string) []*schema.Entry {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := time.Now()
+
+	var entries []*schema.Entry
+
+	for el := c.lru.Front(); el != nil; {
+		next := el.Next()
+
+		ce := el.Value.(*cachedEntry)
+
+		if ce.expired(now) {
+			c.remove(el)
+		} else if ce.verified && ce.db == db {
+			entries = append(entries, proto.Clone(ce.entry).(*schema.Entry))
+		}
+
+		el = next
+	}
+
+	return entries
+}
+
+// Remove evicts the entry of key at tx
+func (c *inMemoryEntryCache) Remove(db string, key []byte, tx uint64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if el, ok := c.entries[entryID(db, key, tx)]; ok {
+		c.remove(el)
+	}
+}
+
+// remove must be called while holding the mutex
+func (c *inMemoryEntryCache) remove(el *list.Element) {
+	ce := el.Value.(*cachedEntry)
+
+	c.lru.Remove(el)
+	delete(c.entries, ce.id)
+
+	if ce.latest != "" {
+		delete(c.latest, ce.latest)
+	}
+}
+
+// Len returns the amount of cached entries
+func (c *inMemoryEntryCache) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.lru.Len()
+}
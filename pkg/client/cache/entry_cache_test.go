@@ -0,0 +1,206 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInMemoryEntryCache(t *testing.T) {
+	ec := NewInMemoryEntryCache(2)
+	require.IsType(t, &inMemoryEntryCache{}, ec)
+
+	ec.Set("db1", []byte("k1"), &schema.Entry{Tx: 1, Key: []byte("k1"), Value: []byte("v1")}, false)
+	ec.Set("db1", []byte("k1"), &schema.Entry{Tx: 2, Key: []byte("k1"), Value: []byte("v2")}, true)
+	require.Equal(t, 2, ec.Len())
+
+	e, ok := ec.Get("db1", []byte("k1"), 1, false)
+	require.True(t, ok)
+	require.Equal(t, []byte("v1"), e.Value)
+
+	_, ok = ec.Get("db1", []byte("k1"), 1, true)
+	require.False(t, ok)
+
+	e, ok = ec.Get("db1", []byte("k1"), 2, true)
+	require.True(t, ok)
+	require.Equal(t, []byte("v2"), e.Value)
+
+	_, ok = ec.Get("db2", []byte("k1"), 2, false)
+	require.False(t, ok)
+
+	ec.Set("db1", []byte("k1"), &schema.Entry{Tx: 1, Key: []byte("k1"), Value: []byte("v1")}, true)
+	_, ok = ec.Get("db1", []byte("k1"), 1, true)
+	require.True(t, ok)
+
+	// k1@2 is the least recently used one
+	ec.Set("db1", []byte("k2"), &schema.Entry{Tx: 3, Key: []byte("k2"), Value: []byte("v3")}, false)
+	require.Equal(t, 2, ec.Len())
+
+	_, ok = ec.Get("db1", []byte("k1"), 2, false)
+	require.False(t, ok)
+
+	_, ok = ec.Get("db1", []byte("k2"), 3, false)
+	require.True(t, ok)
+
+	ec.Set("db1", []byte("ref"), &schema.Entry{Tx: 3, Key: []byte("k2"), ReferencedBy: &schema.Reference{Tx: 4}}, true)
+	_, ok = ec.Get("db1", []byte("ref"), 3, false)
+	require.False(t, ok)
+}
+
+func TestInMemoryEntryCacheDisabled(t *testing.T) {
+	ec := NewInMemoryEntryCache(0)
+	ec.Set("db1", []byte("k1"), &schema.Entry{Tx: 1, Key: []byte("k1"), Value: []byte("v1")}, true)
+	require.Equal(t, 0, ec.Len())
+}
+
+func TestInMemoryEntryCacheCopiesEntries(t *testing.T) {
+	ec := NewInMemoryEntryCache(10)
+
+	entry := &schema.Entry{Tx: 1, Key: []byte("k1"), Value: []byte("v1")}
+	ec.Set("db1", []byte("k1"), entry, true)
+
+	// neither the stored entry nor the returned ones are shared with callers
+	entry.Value = []byte("changed")
+
+	e, ok := ec.Get("db1", []byte("k1"), 1, true)
+	require.True(t, ok)
+	require.Equal(t, []byte("v1"), e.Value)
+
+	e.Value[0] = 'x'
+
+	e, ok = ec.Get("db1", []byte("k1"), 1, true)
+	require.True(t, ok)
+	require.Equal(t, []byte("v1"), e.Value)
+}
+
+func TestInMemoryEntryCacheVerified(t *testing.T) {
+	ec := NewInMemoryEntryCache(10)
+
+	ec.Set("db1", []byte("k1"), &schema.Entry{Tx: 1, Key: []byte("k1"), Value: []byte("v1")}, true)
+	ec.Set("db1", []byte("k2"), &schema.Entry{Tx: 2, Key: []byte("k2"), Value: []byte("v2")}, false)
+	ec.Set("db1", []byte("k3"), &schema.Entry{Tx: 3, Key: []byte("k3"), Value: []byte("v3")}, true)
+	ec.Set("db2", []byte("k1"), &schema.Entry{Tx: 1, Key: []byte("k1"), Value: []byte("v1")}, true)
+
+	verified := ec.Verified("db1")
+	require.Len(t, verified, 2)
+	require.Equal(t, []byte("k3"), verified[0].Key)
+	require.Equal(t, []byte("k1"), verified[1].Key)
+
+	ec.Remove("db1", []byte("k3"), 3)
+	ec.Remove("db1", []byte("missing"), 3)
+	require.Equal(t, 3, ec.Len())
+
+	_, ok := ec.Get("db1", []byte("k3"), 3, false)
+	require.False(t, ok)
+
+	require.Len(t, ec.Verified("db1"), 1)
+	require.Len(t, ec.Verified("db2"), 1)
+}
+
+func TestInMemoryEntryCacheExpiredEntries(t *testing.T) {
+	ec := NewInMemoryEntryCache(10)
+
+	expiresAt := time.Now().Add(-time.Second).Unix()
+
+	ec.Set("db1", []byte("k1"), &schema.Entry{Tx: 1, Key: []byte("k1"), Value: []byte("v1"), ExpiresAt: expiresAt}, true)
+	ec.Set("db1", []byte("k2"), &schema.Entry{Tx: 1, Key: []byte("k2"), Value: []byte("v2"), ExpiresAt: expiresAt}, true)
+	ec.Set("db1", []byte("k3"), &schema.Entry{Tx: 1, Key: []byte("k3"), Value: []byte("v3"), ExpiresAt: time.Now().Add(time.Hour).Unix()}, true)
+	require.Equal(t, 3, ec.Len())
+
+	_, ok := ec.Get("db1", []byte("k1"), 1, false)
+	require.False(t, ok)
+	require.Equal(t, 2, ec.Len())
+
+	e, ok := ec.Get("db1", []byte("k3"), 1, true)
+	require.True(t, ok)
+	require.Equal(t, []byte("v3"), e.Value)
+
+	verified := ec.Verified("db1")
+	require.Len(t, verified, 1)
+	require.Equal(t, []byte("k3"), verified[0].Key)
+	require.Equal(t, 1, ec.Len())
+}
+
+func TestInMemoryEntryCacheLatest(t *testing.T) {
+	ec := NewInMemoryEntryCache(2)
+
+	seq := ec.InvalidationSeq()
+
+	ec.SetLatest("db1", []byte("k1"), &schema.Entry{Tx: 2, Key: []byte("k1"), Value: []byte("v2")}, false, seq)
+
+	e, ok := ec.GetLatest("db1", []byte("k1"), false)
+	require.True(t, ok)
+	require.Equal(t, []byte("v2"), e.Value)
+
+	_, ok = ec.GetLatest("db1", []byte("k1"), true)
+	require.False(t, ok)
+
+	_, ok = ec.GetLatest("db2", []byte("k1"), false)
+	require.False(t, ok)
+
+	// older entries don't replace the latest one
+	ec.SetLatest("db1", []byte("k1"), &schema.Entry{Tx: 1, Key: []byte("k1"), Value: []byte("v1")}, false, seq)
+
+	e, ok = ec.GetLatest("db1", []byte("k1"), false)
+	require.True(t, ok)
+	require.Equal(t, []byte("v2"), e.Value)
+
+	// writes already read don't invalidate the latest entry
+	ec.Invalidate("db1", []byte("k1"), 2)
+
+	_, ok = ec.GetLatest("db1", []byte("k1"), false)
+	require.True(t, ok)
+
+	ec.Invalidate("db1", []byte("k1"), 3)
+
+	_, ok = ec.GetLatest("db1", []byte("k1"), false)
+	require.False(t, ok)
+
+	// the entry is still cached by transaction
+	_, ok = ec.Get("db1", []byte("k1"), 2, false)
+	require.True(t, ok)
+
+	// entries read before an invalidation are not recorded as the latest ones
+	ec.SetLatest("db1", []byte("k1"), &schema.Entry{Tx: 3, Key: []byte("k1"), Value: []byte("v3")}, true, seq)
+
+	_, ok = ec.GetLatest("db1", []byte("k1"), false)
+	require.False(t, ok)
+
+	ec.SetLatest("db1", []byte("k1"), &schema.Entry{Tx: 3, Key: []byte("k1"), Value: []byte("v3")}, true, ec.InvalidationSeq())
+
+	e, ok = ec.GetLatest("db1", []byte("k1"), true)
+	require.True(t, ok)
+	require.Equal(t, []byte("v3"), e.Value)
+
+	ec.InvalidateAll("db1")
+
+	_, ok = ec.GetLatest("db1", []byte("k1"), false)
+	require.False(t, ok)
+
+	// evicted entries are no longer the latest ones
+	ec.SetLatest("db1", []byte("k1"), &schema.Entry{Tx: 4, Key: []byte("k1"), Value: []byte("v4")}, false, ec.InvalidationSeq())
+	ec.Set("db1", []byte("k2"), &schema.Entry{Tx: 5, Key: []byte("k2")}, false)
+	ec.Set("db1", []byte("k3"), &schema.Entry{Tx: 6, Key: []byte("k3")}, false)
+
+	_, ok = ec.GetLatest("db1", []byte("k1"), false)
+	require.False(t, ok)
+	require.Equal(t, 2, ec.Len())
+}
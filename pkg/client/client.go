@@ -17,6 +17,7 @@ limitations under the License.
 package client
 
 import (
+	"bytes"
 	"context"
 	"crypto/ecdsa"
 	"crypto/sha256"
@@ -26,6 +27,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math"
 	"os"
 	"sync"
 	"time"
@@ -78,6 +80,9 @@ type ImmuClient interface {
 	WithTokenService(tokenService TokenService) *immuClient
 	WithServerSigningPubKey(serverSigningPubKey *ecdsa.PublicKey) *immuClient
 	WithStreamServiceFactory(ssf stream.ServiceFactory) *immuClient
+	WithEntryCache(entryCache cache.EntryCache) *immuClient
+	ReverifyEntryCache(ctx context.Context) (evicted int, err error)
+	WatchEntryCache(ctx context.Context) error
 
 	GetServiceClient() schema.ImmuServiceClient
 	GetOptions() *Options
@@ -89,15 +94,60 @@ type ImmuClient interface {
 	SetActiveUser(ctx context.Context, u *schema.SetActiveUserRequest) error
 
 	CleanIndex(ctx context.Context, req *emptypb.Empty) error
+	TopPrefixes(ctx context.Context, by string, limit uint32) (*schema.PrefixStatsList, error)
+	KeyspaceHistogram(ctx context.Context, req *schema.KeyspaceHistogramRequest) (*schema.KeyspaceHistogramResult, error)
+	SetKeyRules(ctx context.Context, rules *schema.KeyRules) error
+	GetKeyRules(ctx context.Context) (*schema.KeyRules, error)
+	SetApprovalRules(ctx context.Context, rules *schema.ApprovalRules) error
+	GetApprovalRules(ctx context.Context) (*schema.ApprovalRules, error)
+	ProposeWrite(ctx context.Context, req *schema.ExecAllRequest) (*schema.PendingWrite, error)
+	ListPendingWrites(ctx context.Context, includeDecided bool) (*schema.PendingWrites, error)
+	ApproveWrite(ctx context.Context, id string) (*schema.PendingWrite, error)
+	RejectWrite(ctx context.Context, id string, reason string) (*schema.PendingWrite, error)
+	PlaceLegalHold(ctx context.Context, prefix []byte, reason string) (*schema.LegalHold, error)
+	LiftLegalHold(ctx context.Context, prefix []byte, reason string) (*schema.LegalHold, error)
+	ListLegalHolds(ctx context.Context) (*schema.LegalHolds, error)
+	LegalHoldHistory(ctx context.Context, prefix []byte) (*schema.LegalHolds, error)
+
+	PinSnapshot(ctx context.Context, name string, txID uint64) (*schema.PinnedSnapshot, error)
+	UnpinSnapshot(ctx context.Context, name string) error
+	ListPinnedSnapshots(ctx context.Context) (*schema.PinnedSnapshots, error)
+
+	AdvanceEpoch(ctx context.Context) (*schema.Epoch, error)
+	CompareState(ctx context.Context, checkpoints ...*schema.ImmutableState) (*schema.StateComparison, error)
+	AttestRewind(ctx context.Context, reason string) (*schema.RewindAttestation, error)
+	GetRewindAttestation(ctx context.Context) (*schema.RewindAttestation, error)
+	SetIf(ctx context.Context, key []byte, value []byte, expectedValue []byte) (*schema.TxMetadata, error)
+	SetIfTx(ctx context.Context, key []byte, value []byte, expectedTx uint64) (*schema.TxMetadata, error)
+	Epoch() uint64
+
+	ListOperations(ctx context.Context) (*schema.OperationList, error)
+	CancelOperation(ctx context.Context, id string) error
+
+	ScheduleJob(ctx context.Context, js *schema.JobSchedule) error
+	UnscheduleJob(ctx context.Context, name string) error
+	ListJobSchedules(ctx context.Context) (*schema.JobScheduleList, error)
+	ListJobRuns(ctx context.Context, name string, limit uint32) (*schema.JobRunList, error)
+	ListJobs(ctx context.Context) (*schema.JobList, error)
+	GetJob(ctx context.Context, id string) (*schema.Job, error)
+	CancelJob(ctx context.Context, id string) error
+	WatchJob(ctx context.Context, id string, onUpdate func(*schema.Job) error) error
+
+	CreateEnrollmentToken(ctx context.Context, database string, permission uint32, ttl time.Duration) (*schema.EnrollmentToken, error)
+	Enroll(ctx context.Context, token string, device string) (*schema.EnrollResponse, error)
 
 	CurrentState(ctx context.Context) (*schema.ImmutableState, error)
+	VerifiedCombinedState(ctx context.Context) (*schema.CombinedState, error)
 
 	Set(ctx context.Context, key []byte, value []byte) (*schema.TxMetadata, error)
+	ExpirableSet(ctx context.Context, key []byte, value []byte, expiresAt time.Time) (*schema.TxMetadata, error)
 	VerifiedSet(ctx context.Context, key []byte, value []byte) (*schema.TxMetadata, error)
 
 	Get(ctx context.Context, key []byte) (*schema.Entry, error)
 	GetSince(ctx context.Context, key []byte, tx uint64) (*schema.Entry, error)
 	GetAt(ctx context.Context, key []byte, tx uint64) (*schema.Entry, error)
+	GetAtSnapshot(ctx context.Context, key []byte, snapshot string) (*schema.Entry, error)
+	GetAtTime(ctx context.Context, key []byte, t time.Time) (*schema.Entry, error)
 
 	VerifiedGet(ctx context.Context, key []byte) (*schema.Entry, error)
 	VerifiedGetSince(ctx context.Context, key []byte, tx uint64) (*schema.Entry, error)
@@ -109,20 +159,47 @@ type ImmuClient interface {
 	VerifiedZAdd(ctx context.Context, set []byte, score float64, key []byte) (*schema.TxMetadata, error)
 
 	ZAddAt(ctx context.Context, set []byte, score float64, key []byte, atTx uint64) (*schema.TxMetadata, error)
+	ZAddMulti(ctx context.Context, req *schema.ZAddMultiRequest) (*schema.TxMetadata, error)
 	VerifiedZAddAt(ctx context.Context, set []byte, score float64, key []byte, atTx uint64) (*schema.TxMetadata, error)
 
 	Scan(ctx context.Context, req *schema.ScanRequest) (*schema.Entries, error)
 	ZScan(ctx context.Context, req *schema.ZScanRequest) (*schema.ZEntries, error)
+	ZRem(ctx context.Context, set []byte, key []byte) (*schema.TxMetadata, error)
+	ZCount(ctx context.Context, req *schema.ZCountRequest) (*schema.EntryCount, error)
+	ZCard(ctx context.Context, set []byte) (*schema.EntryCount, error)
+	ZRank(ctx context.Context, set []byte, key []byte) (*schema.ZRankResult, error)
+	ZRevRank(ctx context.Context, set []byte, key []byte) (*schema.ZRankResult, error)
+	ZIncrBy(ctx context.Context, set []byte, increment float64, key []byte) (*schema.ZIncrByResult, error)
+	ZUnionStore(ctx context.Context, req *schema.ZStoreRequest) (*schema.ZStoreResult, error)
+	ZInterStore(ctx context.Context, req *schema.ZStoreRequest) (*schema.ZStoreResult, error)
+	ZPopMin(ctx context.Context, set []byte, count uint64) (*schema.ZPopResult, error)
+	ZPopMax(ctx context.Context, set []byte, count uint64) (*schema.ZPopResult, error)
+
+	OpenIterator(ctx context.Context, req *schema.OpenIteratorRequest) (*schema.IteratorLease, error)
+	IteratorNext(ctx context.Context, leaseID string, limit uint64) (*schema.IteratorPage, error)
+	KeepAliveIterator(ctx context.Context, leaseID string) (*schema.IteratorLease, error)
+	CloseIterator(ctx context.Context, leaseID string) error
+	NewIterator(ctx context.Context, req *schema.OpenIteratorRequest) (*Iterator, error)
 
 	TxByID(ctx context.Context, tx uint64) (*schema.Tx, error)
 	VerifiedTxByID(ctx context.Context, tx uint64) (*schema.Tx, error)
+	VerifiedTxRange(ctx context.Context, initialTx, finalTx uint64, chunkSize uint32) (*schema.VerifiableTxRange, error)
+	VerifyStateConsistency(ctx context.Context, source, target *schema.ImmutableState) (*schema.ImmutableState, error)
+	VerifyDB(ctx context.Context, from *DBVerification, batchSize uint32, progress func(v *DBVerification, lastTx uint64) error) (*DBVerification, error)
 	TxScan(ctx context.Context, req *schema.TxScanRequest) (*schema.TxList, error)
+	Diff(ctx context.Context, req *schema.DiffRequest) (*schema.DiffResponse, error)
+	Delete(ctx context.Context, keys ...[]byte) (*schema.TxMetadata, error)
+	DeleteByPrefix(ctx context.Context, req *schema.DeleteByPrefixRequest) (*schema.DeleteByPrefixResponse, error)
+	Increment(ctx context.Context, key []byte, delta int64) (*schema.IncrementResponse, error)
+	Decrement(ctx context.Context, key []byte, delta int64) (*schema.IncrementResponse, error)
 
 	Count(ctx context.Context, prefix []byte) (*schema.EntryCount, error)
 	CountAll(ctx context.Context) (*schema.EntryCount, error)
 
 	SetAll(ctx context.Context, kvList *schema.SetRequest) (*schema.TxMetadata, error)
 	GetAll(ctx context.Context, keys [][]byte) (*schema.Entries, error)
+	GetMulti(ctx context.Context, req *schema.GetMultiRequest) (*schema.GetMultiResponse, error)
+	VerifiedGetMulti(ctx context.Context, req *schema.GetMultiRequest) (*schema.GetMultiResponse, error)
 
 	ExecAll(ctx context.Context, in *schema.ExecAllRequest) (*schema.TxMetadata, error)
 
@@ -141,13 +218,49 @@ type ImmuClient interface {
 	StreamScan(ctx context.Context, req *schema.ScanRequest) (*schema.Entries, error)
 	StreamZScan(ctx context.Context, req *schema.ZScanRequest) (*schema.ZEntries, error)
 	StreamHistory(ctx context.Context, req *schema.HistoryRequest) (*schema.Entries, error)
+	HistoryStream(ctx context.Context, req *schema.HistoryStreamRequest, onEntries func(*schema.Entries) error) error
+	ZScanStream(ctx context.Context, req *schema.ZScanStreamRequest, onEntries func(*schema.ZEntries) error) error
 	StreamExecAll(ctx context.Context, req *stream.ExecAllRequest) (*schema.TxMetadata, error)
 
+	SetStream(ctx context.Context, key []byte, r io.Reader) (*schema.BlobManifest, error)
+	GetStream(ctx context.Context, req *schema.KeyRequest, w io.Writer) (*schema.BlobManifest, error)
+	VerifiedGetStream(ctx context.Context, key []byte, w io.Writer) (*schema.BlobManifest, error)
+
+	PutObject(ctx context.Context, req *schema.PutObjectRequest) (*schema.ObjectVersion, error)
+	GetObject(ctx context.Context, key []byte, versionID uint64) (*schema.Object, error)
+	ListObjectVersions(ctx context.Context, req *schema.ListObjectVersionsRequest) (*schema.ObjectVersions, error)
+
+	CreateSecondaryIndex(ctx context.Context, def *schema.SecondaryIndex) (*schema.SecondaryIndex, error)
+	DropSecondaryIndex(ctx context.Context, name string) error
+	ListSecondaryIndexes(ctx context.Context) (*schema.SecondaryIndexes, error)
+	IndexScan(ctx context.Context, req *schema.IndexScanRequest) (*schema.IndexEntries, error)
+
+	GenerateBindings(ctx context.Context, req *schema.BindingsRequest) (*schema.Bindings, error)
+
+	NotarizationReceipt(ctx context.Context, req *schema.ReceiptRequest) (*schema.Receipt, error)
+
+	ListReferences(ctx context.Context, req *schema.ListReferencesRequest) (*schema.ReferenceList, error)
+
+	Watch(ctx context.Context, req *schema.WatchRequest, onEvent func(*schema.WatchEvent) error) error
+	WatchAck(ctx context.Context, subscriptionID string, tx uint64) error
+	Unwatch(ctx context.Context, subscriptionID string) error
+
 	SQLExec(ctx context.Context, sql string, params map[string]interface{}) (*schema.SQLExecResult, error)
+	SQLExecDryRun(ctx context.Context, sql string, params map[string]interface{}) (*schema.DryRunResult, error)
 	UseSnapshot(ctx context.Context, sinceTx, asBeforeTx uint64) error
 	SQLQuery(ctx context.Context, sql string, params map[string]interface{}, renewSnapshot bool) (*schema.SQLQueryResult, error)
+	SQLQueryAtSnapshot(ctx context.Context, sql string, params map[string]interface{}, snapshot string) (*schema.SQLQueryResult, error)
 	ListTables(ctx context.Context) (*schema.SQLQueryResult, error)
 	DescribeTable(ctx context.Context, tableName string) (*schema.SQLQueryResult, error)
+	SQLRowProvenance(ctx context.Context, req *schema.SQLRowProvenanceRequest) (*schema.SQLRowVersions, error)
+
+	NewSQLTx(ctx context.Context, isolation schema.SQLTxIsolation) (*schema.SQLTx, error)
+	SQLTxExec(ctx context.Context, txID string, sql string, params map[string]interface{}) error
+	SQLTxQuery(ctx context.Context, txID string, sql string, params map[string]interface{}) (*schema.SQLQueryResult, error)
+	CommitSQLTx(ctx context.Context, txID string) (*schema.SQLExecResult, error)
+	RollbackSQLTx(ctx context.Context, txID string) error
+	RunTx(ctx context.Context, fn func(tx *SQLTx) error) (*schema.SQLExecResult, error)
+	RunTxWith(ctx context.Context, opts *TxOptions, fn func(tx *SQLTx) error) (*schema.SQLExecResult, error)
 
 	VerifyRow(ctx context.Context, row *schema.Row, table string, pkVal *schema.SQLValue) error
 }
@@ -164,6 +277,12 @@ type immuClient struct {
 	Tkns                 TokenService
 	serverSigningPubKey  *ecdsa.PublicKey
 	StreamServiceFactory stream.ServiceFactory
+	EntryCache           cache.EntryCache
+	cacheReverifier      *cacheReverifier
+	cacheWatches         entryCacheWatches
+	catalogStates        map[string]*schema.ImmutableState
+	session              *session
+	epochs               *epochs
 	sync.RWMutex
 }
 
@@ -174,6 +293,8 @@ func DefaultClient() ImmuClient {
 		Options:              DefaultOptions(),
 		Logger:               logger.NewSimpleLogger("immuclient", os.Stderr),
 		StreamServiceFactory: stream.NewStreamServiceFactory(DefaultOptions().StreamChunkSize),
+		session:              &session{},
+		epochs:               &epochs{byDatabase: make(map[string]uint64)},
 	}
 }
 
@@ -234,6 +355,11 @@ func NewImmuClient(options *Options) (c ImmuClient, err error) {
 
 	c.WithStateService(stateService)
 
+	if options.EntryCacheSize > 0 {
+		c.WithEntryCache(cache.NewInMemoryEntryCache(options.EntryCacheSize))
+		c.(*immuClient).startCacheReverifier()
+	}
+
 	return c, nil
 }
 
@@ -302,15 +428,25 @@ func (c *immuClient) SetupDialOptions(options *Options) *[]grpc.DialOption {
 	if c.serverSigningPubKey != nil {
 		uic = append(uic, c.SignatureVerifierInterceptor)
 	}
-	uic = append(uic, c.IllegalStateHandlerInterceptor)
+	uic = append(uic, c.IllegalStateHandlerInterceptor, c.EpochUnaryInterceptor)
+
+	sic := []grpc.StreamClientInterceptor{c.EpochStreamInterceptor}
+
+	if options.HeartbeatInterval > 0 {
+		uic = append(uic, c.SessionUnaryInterceptor)
+		sic = append(sic, c.SessionStreamInterceptor)
+	}
 
 	if options.Auth && c.Tkns != nil {
 		token, err := c.Tkns.GetToken()
 		uic = append(uic, auth.ClientUnaryInterceptor(token))
 		if err == nil {
-			opts = append(opts, grpc.WithStreamInterceptor(auth.ClientStreamInterceptor(token)))
+			sic = append(sic, auth.ClientStreamInterceptor(token))
 		}
 	}
+	if len(sic) > 0 {
+		opts = append(opts, grpc.WithStreamInterceptor(grpc_middleware.ChainStreamClient(sic...)))
+	}
 	opts = append(opts, grpc.WithUnaryInterceptor(grpc_middleware.ChainUnaryClient(uic...)), grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(options.MaxRecvMsgSize)))
 
 	return &opts
@@ -331,6 +467,9 @@ func (c *immuClient) Disconnect() error {
 		return ErrNotConnected
 	}
 
+	c.stopHeartbeat()
+	c.stopCacheReverifier()
+
 	if err := c.clientConn.Close(); err != nil {
 		return err
 	}
@@ -467,6 +606,10 @@ func (c *immuClient) Login(ctx context.Context, user []byte, pass []byte) (*sche
 		User:     user,
 		Password: pass,
 	})
+	if err == nil {
+		c.observeEpoch(c.Options.CurrentDatabase, result.Epoch)
+		c.startHeartbeat(ctx, user, pass, result.Token)
+	}
 
 	c.Logger.Debugf("login finished in %s", time.Since(start))
 
@@ -485,6 +628,8 @@ func (c *immuClient) Logout(ctx context.Context) error {
 		return err
 	}
 
+	c.stopHeartbeat()
+
 	tokenFileExists, err := c.Tkns.IsTokenPresent()
 	if err != nil {
 		return fmt.Errorf("error checking if token file exists: %v", err)
@@ -521,7 +666,20 @@ func (c *immuClient) Get(ctx context.Context, key []byte) (*schema.Entry, error)
 	start := time.Now()
 	defer c.Logger.Debugf("get finished in %s", time.Since(start))
 
-	return c.ServiceClient.Get(ctx, &schema.KeyRequest{Key: key})
+	if e, ok := c.cachedLatestEntry(key, false); ok {
+		return e, nil
+	}
+
+	seq := c.entryCacheSeq()
+
+	e, err := c.ServiceClient.Get(ctx, &schema.KeyRequest{Key: key})
+	if err != nil {
+		return nil, err
+	}
+
+	c.cacheLatestEntry(key, e, false, seq)
+
+	return e, nil
 }
 
 // VerifiedGet ...
@@ -554,6 +712,23 @@ func (c *immuClient) VerifiedGetAt(ctx context.Context, key []byte, tx uint64) (
 }
 
 func (c *immuClient) verifiedGet(ctx context.Context, kReq *schema.KeyRequest) (vi *schema.Entry, err error) {
+	if kReq.AtTx > 0 {
+		if e, ok := c.cachedEntry(kReq.Key, kReq.AtTx, true); ok {
+			return e, nil
+		}
+	}
+
+	if isLatestRead(kReq) {
+		if e, ok := c.cachedLatestEntry(kReq.Key, true); ok {
+			return e, nil
+		}
+	}
+
+	return c.fetchVerifiedEntry(ctx, kReq)
+}
+
+// fetchVerifiedEntry reads the entry from the server, verifying it against the current state which is moved forward
+func (c *immuClient) fetchVerifiedEntry(ctx context.Context, kReq *schema.KeyRequest) (vi *schema.Entry, err error) {
 	err = c.StateService.CacheLock()
 	if err != nil {
 		return nil, err
@@ -564,6 +739,8 @@ func (c *immuClient) verifiedGet(ctx context.Context, kReq *schema.KeyRequest) (
 		return nil, ErrNotConnected
 	}
 
+	seq := c.entryCacheSeq()
+
 	state, err := c.StateService.GetState(ctx, c.Options.CurrentDatabase)
 	if err != nil {
 		return nil, err
@@ -579,20 +756,58 @@ func (c *immuClient) verifiedGet(ctx context.Context, kReq *schema.KeyRequest) (
 		return nil, err
 	}
 
+	targetID, targetAlh, err := verifyEntry(kReq.Key, vEntry, state)
+	if err != nil {
+		return nil, err
+	}
+
+	newState := &schema.ImmutableState{
+		Db:        c.currentDatabase(),
+		TxId:      targetID,
+		TxHash:    targetAlh[:],
+		Signature: vEntry.VerifiableTx.Signature,
+	}
+
+	if c.serverSigningPubKey != nil {
+		ok, err := newState.CheckSignature(c.serverSigningPubKey)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, store.ErrCorruptedData
+		}
+	}
+
+	err = c.StateService.SetState(c.Options.CurrentDatabase, newState)
+	if err != nil {
+		return nil, err
+	}
+
+	if isLatestRead(kReq) {
+		c.cacheLatestEntry(kReq.Key, vEntry.Entry, true, seq)
+	} else {
+		c.cacheEntry(kReq.Key, vEntry.Entry, true)
+	}
+
+	return vEntry.Entry, nil
+}
+
+// verifyEntry checks the proofs of an entry against the given state, returning the state the entry was proven to be consistent with
+func verifyEntry(key []byte, vEntry *schema.VerifiableEntry, state *schema.ImmutableState) (targetID uint64, targetAlh [sha256.Size]byte, err error) {
 	inclusionProof := schema.InclusionProofFrom(vEntry.InclusionProof)
 	dualProof := schema.DualProofFrom(vEntry.VerifiableTx.DualProof)
 
 	var eh [sha256.Size]byte
 
-	var sourceID, targetID uint64
-	var sourceAlh, targetAlh [sha256.Size]byte
+	var sourceID uint64
+	var sourceAlh [sha256.Size]byte
 
 	var vTx uint64
 	var kv *store.KV
 
 	if vEntry.Entry.ReferencedBy == nil {
 		vTx = vEntry.Entry.Tx
-		kv = database.EncodeKV(kReq.Key, vEntry.Entry.Value)
+		kv = entryKV(key, vEntry.Entry)
 	} else {
 		vTx = vEntry.Entry.ReferencedBy.Tx
 		kv = database.EncodeReference(vEntry.Entry.ReferencedBy.Key, vEntry.Entry.Key, vEntry.Entry.ReferencedBy.AtTx)
@@ -619,7 +834,7 @@ func (c *immuClient) verifiedGet(ctx context.Context, kReq *schema.KeyRequest) (
 		kv,
 		eh)
 	if !verifies {
-		return nil, store.ErrCorruptedData
+		return 0, targetAlh, store.ErrCorruptedData
 	}
 
 	if state.TxId > 0 {
@@ -631,33 +846,27 @@ func (c *immuClient) verifiedGet(ctx context.Context, kReq *schema.KeyRequest) (
 			targetAlh,
 		)
 		if !verifies {
-			return nil, store.ErrCorruptedData
+			return 0, targetAlh, store.ErrCorruptedData
 		}
 	}
 
-	newState := &schema.ImmutableState{
-		Db:        c.currentDatabase(),
-		TxId:      targetID,
-		TxHash:    targetAlh[:],
-		Signature: vEntry.VerifiableTx.Signature,
-	}
+	return targetID, targetAlh, nil
+}
 
-	if c.serverSigningPubKey != nil {
-		ok, err := newState.CheckSignature(c.serverSigningPubKey)
-		if err != nil {
-			return nil, err
-		}
-		if !ok {
-			return nil, store.ErrCorruptedData
-		}
+func (c *immuClient) cachedEntry(key []byte, tx uint64, verified bool) (*schema.Entry, bool) {
+	if c.EntryCache == nil {
+		return nil, false
 	}
 
-	err = c.StateService.SetState(c.Options.CurrentDatabase, newState)
-	if err != nil {
-		return nil, err
+	return c.EntryCache.Get(c.Options.CurrentDatabase, key, tx, verified)
+}
+
+func (c *immuClient) cacheEntry(key []byte, e *schema.Entry, verified bool) {
+	if c.EntryCache == nil {
+		return
 	}
 
-	return vEntry.Entry, nil
+	c.EntryCache.Set(c.Options.CurrentDatabase, key, e, verified)
 }
 
 // GetSince ...
@@ -681,7 +890,42 @@ func (c *immuClient) GetAt(ctx context.Context, key []byte, tx uint64) (*schema.
 	start := time.Now()
 	defer c.Logger.Debugf("get finished in %s", time.Since(start))
 
-	return c.ServiceClient.Get(ctx, &schema.KeyRequest{Key: key, AtTx: tx})
+	if e, ok := c.cachedEntry(key, tx, false); ok {
+		return e, nil
+	}
+
+	e, err := c.ServiceClient.Get(ctx, &schema.KeyRequest{Key: key, AtTx: tx})
+	if err != nil {
+		return nil, err
+	}
+
+	c.cacheEntry(key, e, false)
+
+	return e, nil
+}
+
+// GetAtSnapshot returns the value the key had as of the transaction of a pinned snapshot
+func (c *immuClient) GetAtSnapshot(ctx context.Context, key []byte, snapshot string) (*schema.Entry, error) {
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	start := time.Now()
+	defer c.Logger.Debugf("get finished in %s", time.Since(start))
+
+	return c.ServiceClient.Get(ctx, &schema.KeyRequest{Key: key, Snapshot: snapshot})
+}
+
+// GetAtTime returns the value the key had at the given time, as of the last transaction committed by then
+func (c *immuClient) GetAtTime(ctx context.Context, key []byte, t time.Time) (*schema.Entry, error) {
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	start := time.Now()
+	defer c.Logger.Debugf("get finished in %s", time.Since(start))
+
+	return c.ServiceClient.Get(ctx, &schema.KeyRequest{Key: key, AtTime: t.Unix()})
 }
 
 // Scan ...
@@ -727,12 +971,18 @@ func (c *immuClient) Set(ctx context.Context, key []byte, value []byte) (*schema
 	start := time.Now()
 	defer c.Logger.Debugf("set finished in %s", time.Since(start))
 
-	txmd, err := c.ServiceClient.Set(ctx, &schema.SetRequest{KVs: []*schema.KeyValue{{Key: key, Value: value}}})
+	req := &schema.SetRequest{
+		KVs:            []*schema.KeyValue{{Key: key, Value: value}},
+		Attribution:    c.attribution(),
+		IdempotencyKey: idempotencyKey(ctx),
+	}
+
+	txmd, err := c.ServiceClient.Set(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
-	if int(txmd.Nentries) != 1 {
+	if !isExpectedKVTxLen(txmd, 1, req.IdempotencyKey) {
 		return nil, store.ErrCorruptedData
 	}
 
@@ -760,7 +1010,11 @@ func (c *immuClient) VerifiedSet(ctx context.Context, key []byte, value []byte)
 	}
 
 	req := &schema.VerifiableSetRequest{
-		SetRequest:   &schema.SetRequest{KVs: []*schema.KeyValue{{Key: key, Value: value}}},
+		SetRequest: &schema.SetRequest{
+			KVs:            []*schema.KeyValue{{Key: key, Value: value}},
+			Attribution:    c.attribution(),
+			IdempotencyKey: idempotencyKey(ctx),
+		},
 		ProveSinceTx: state.TxId,
 	}
 
@@ -775,7 +1029,12 @@ func (c *immuClient) VerifiedSet(ctx context.Context, key []byte, value []byte)
 		return nil, err
 	}
 
-	if verifiableTx.Tx.Metadata.Nentries != 1 {
+	err = verifyTxAttribution(verifiableTx.Tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isExpectedTxEntries(verifiableTx.Tx, 1, req.SetRequest.IdempotencyKey) {
 		return nil, store.ErrCorruptedData
 	}
 
@@ -842,31 +1101,150 @@ func (c *immuClient) VerifiedSet(ctx context.Context, key []byte, value []byte)
 	return verifiableTx.Tx.Metadata, nil
 }
 
+// SetAll writes the key-values of the request in a single transaction. The idempotency key set with
+// WithIdempotencyKey is assigned to the request unless it already has one
 func (c *immuClient) SetAll(ctx context.Context, req *schema.SetRequest) (*schema.TxMetadata, error) {
 	if !c.IsConnected() {
 		return nil, ErrNotConnected
 	}
 
+	if req != nil && req.IdempotencyKey == "" {
+		req.IdempotencyKey = idempotencyKey(ctx)
+	}
+
 	txmd, err := c.ServiceClient.Set(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
-	if int(txmd.Nentries) != len(req.KVs) {
+	if !isExpectedKVTxLen(txmd, len(req.KVs), req.IdempotencyKey) {
+		return nil, store.ErrCorruptedData
+	}
+
+	return txmd, nil
+}
+
+// Delete marks the keys as deleted, so they are no longer read while their previous values are still listed by History
+func (c *immuClient) Delete(ctx context.Context, keys ...[]byte) (*schema.TxMetadata, error) {
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	start := time.Now()
+	defer c.Logger.Debugf("Delete finished in %s", time.Since(start))
+
+	txmd, err := c.ServiceClient.Delete(ctx, &schema.DeleteKeysRequest{
+		Keys:        keys,
+		Attribution: c.attribution(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !isExpectedTxLen(txmd, len(keys)) {
 		return nil, store.ErrCorruptedData
 	}
 
 	return txmd, nil
 }
 
-// ExecAll ...
+// DeleteByPrefix marks every key under the prefix as deleted, in transactions of at most req.BatchSize keys.
+// With req.DryRun only the number of keys which would be deleted is returned
+func (c *immuClient) DeleteByPrefix(ctx context.Context, req *schema.DeleteByPrefixRequest) (*schema.DeleteByPrefixResponse, error) {
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	start := time.Now()
+	defer c.Logger.Debugf("DeleteByPrefix finished in %s", time.Since(start))
+
+	if req != nil && req.Attribution == nil {
+		req.Attribution = c.attribution()
+	}
+
+	res, err := c.ServiceClient.DeleteByPrefix(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.DryRun {
+		return res, nil
+	}
+
+	// each transaction holds a tombstone per key, plus its attribution when enabled
+	entries := uint64(0)
+
+	for _, txmd := range res.Txs {
+		entries += uint64(txmd.Nentries)
+	}
+
+	if entries < res.Count || entries > res.Count+uint64(len(res.Txs)) {
+		return nil, store.ErrCorruptedData
+	}
+
+	return res, nil
+}
+
+// Increment atomically adds delta to the integer held by the key, which is zero when the key doesn't exist,
+// and returns the resulting value
+func (c *immuClient) Increment(ctx context.Context, key []byte, delta int64) (*schema.IncrementResponse, error) {
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	start := time.Now()
+	defer c.Logger.Debugf("Increment finished in %s", time.Since(start))
+
+	res, err := c.ServiceClient.Increment(ctx, &schema.IncrementRequest{
+		Key:         key,
+		Delta:       delta,
+		Attribution: c.attribution(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !isExpectedKVTxLen(res.TxMetadata, 1, "") {
+		return nil, store.ErrCorruptedData
+	}
+
+	return res, nil
+}
+
+// Decrement atomically subtracts delta from the integer held by the key and returns the resulting value
+func (c *immuClient) Decrement(ctx context.Context, key []byte, delta int64) (*schema.IncrementResponse, error) {
+	if delta == math.MinInt64 {
+		return nil, ErrIllegalArguments
+	}
+
+	return c.Increment(ctx, key, -delta)
+}
+
+// ExecAll executes the operations of the request in a single transaction. The idempotency key set with
+// WithIdempotencyKey is assigned to the request unless it already has one
 func (c *immuClient) ExecAll(ctx context.Context, req *schema.ExecAllRequest) (*schema.TxMetadata, error) {
+	if req != nil && req.IdempotencyKey == "" {
+		req.IdempotencyKey = idempotencyKey(ctx)
+	}
+
 	txmd, err := c.ServiceClient.ExecAll(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
-	if int(txmd.Nentries) != len(req.Operations) {
+	// references and sorted set entries may be written along with their reference index entries, and key-values
+	// along with the entries of the secondary indexes covering them
+	indexed := idempotencyEntries(req.IdempotencyKey)
+	for _, op := range req.Operations {
+		switch op.Operation.(type) {
+		case *schema.Op_Ref, *schema.Op_ZAdd:
+			indexed++
+		case *schema.Op_Kv:
+			indexed += database.MaxSecondaryIndexes
+		}
+	}
+
+	if !isExpectedIndexedTxLen(txmd, len(req.Operations), indexed) {
 		return nil, store.ErrCorruptedData
 	}
 
@@ -889,6 +1267,116 @@ func (c *immuClient) GetAll(ctx context.Context, keys [][]byte) (*schema.Entries
 	return c.ServiceClient.GetAll(ctx, keyList)
 }
 
+// GetMulti resolves a list of keys against a single snapshot in one round trip
+func (c *immuClient) GetMulti(ctx context.Context, req *schema.GetMultiRequest) (*schema.GetMultiResponse, error) {
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	start := time.Now()
+	defer c.Logger.Debugf("GetMulti finished in %s", time.Since(start))
+
+	return c.ServiceClient.GetMulti(ctx, req)
+}
+
+// VerifiedGetMulti resolves a list of keys against a single snapshot in one round trip, verifying every entry
+// against the local state
+func (c *immuClient) VerifiedGetMulti(ctx context.Context, req *schema.GetMultiRequest) (*schema.GetMultiResponse, error) {
+	if req == nil {
+		return nil, ErrIllegalArguments
+	}
+
+	err := c.StateService.CacheLock()
+	if err != nil {
+		return nil, err
+	}
+	defer c.StateService.CacheUnlock()
+
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	start := time.Now()
+	defer c.Logger.Debugf("VerifiedGetMulti finished in %s", time.Since(start))
+
+	state, err := c.StateService.GetState(ctx, c.Options.CurrentDatabase)
+	if err != nil {
+		return nil, err
+	}
+
+	vRes, err := c.ServiceClient.VerifiableGetMulti(ctx, &schema.VerifiableGetMultiRequest{
+		GetMultiRequest: req,
+		ProveSinceTx:    state.TxId,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(vRes.Items) != len(req.Keys) {
+		return nil, store.ErrCorruptedData
+	}
+
+	res := &schema.GetMultiResponse{
+		Items:      make([]*schema.GetMultiItem, len(vRes.Items)),
+		SnapshotTx: vRes.SnapshotTx,
+	}
+
+	newState := state
+
+	for i, item := range vRes.Items {
+		if !bytes.Equal(item.Key, req.Keys[i]) {
+			return nil, store.ErrCorruptedData
+		}
+
+		res.Items[i] = &schema.GetMultiItem{Key: item.Key, Error: item.Error}
+
+		if item.VerifiableEntry == nil {
+			continue
+		}
+
+		targetID, targetAlh, err := verifyEntry(item.Key, item.VerifiableEntry, state)
+		if err != nil {
+			return nil, err
+		}
+
+		if targetID > newState.TxId {
+			newState = &schema.ImmutableState{
+				Db:        c.currentDatabase(),
+				TxId:      targetID,
+				TxHash:    targetAlh[:],
+				Signature: item.VerifiableEntry.VerifiableTx.Signature,
+			}
+		}
+
+		res.Items[i].Entry = item.VerifiableEntry.Entry
+	}
+
+	if newState != state {
+		if c.serverSigningPubKey != nil {
+			ok, err := newState.CheckSignature(c.serverSigningPubKey)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				return nil, store.ErrCorruptedData
+			}
+		}
+
+		err = c.StateService.SetState(c.Options.CurrentDatabase, newState)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, item := range res.Items {
+		if item.Entry != nil {
+			c.cacheEntry(item.Key, item.Entry, true)
+		}
+	}
+
+	return res, nil
+}
+
 // TxByID ...
 func (c *immuClient) TxByID(ctx context.Context, tx uint64) (*schema.Tx, error) {
 	if !c.IsConnected() {
@@ -941,19 +1429,34 @@ func (c *immuClient) VerifiedTxByID(ctx context.Context, tx uint64) (*schema.Tx,
 
 	dualProof := schema.DualProofFrom(vTx.DualProof)
 
-	var sourceID, targetID uint64
-	var sourceAlh, targetAlh [sha256.Size]byte
+	err = verifyTxAttribution(vTx.Tx)
+	if err != nil {
+		return nil, err
+	}
+
+	txAlh := schema.TxFrom(vTx.Tx).Alh
+
+	var sourceID, targetID uint64
+	var sourceAlh, targetAlh [sha256.Size]byte
 
 	if state.TxId <= vTx.Tx.Metadata.Id {
 		sourceID = state.TxId
 		sourceAlh = schema.DigestFrom(state.TxHash)
 		targetID = vTx.Tx.Metadata.Id
 		targetAlh = dualProof.TargetTxMetadata.Alh()
+
+		if txAlh != targetAlh {
+			return nil, store.ErrCorruptedData
+		}
 	} else {
 		sourceID = vTx.Tx.Metadata.Id
 		sourceAlh = dualProof.SourceTxMetadata.Alh()
 		targetID = state.TxId
 		targetAlh = schema.DigestFrom(state.TxHash)
+
+		if txAlh != sourceAlh {
+			return nil, store.ErrCorruptedData
+		}
 	}
 
 	if state.TxId > 0 {
@@ -1005,6 +1508,19 @@ func (c *immuClient) TxScan(ctx context.Context, req *schema.TxScanRequest) (*sc
 	return c.ServiceClient.TxScan(ctx, req)
 }
 
+// Diff returns the keys modified by the transactions committed after req.FromTx up to req.ToTx, along with their
+// latest value. Fewer transactions are considered when the limit of keys is reached, the response tells up to which one
+func (c *immuClient) Diff(ctx context.Context, req *schema.DiffRequest) (*schema.DiffResponse, error) {
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	start := time.Now()
+	defer c.Logger.Debugf("Diff finished in %s", time.Since(start))
+
+	return c.ServiceClient.Diff(ctx, req)
+}
+
 // History ...
 func (c *immuClient) History(ctx context.Context, req *schema.HistoryRequest) (sl *schema.Entries, err error) {
 	if !c.IsConnected() {
@@ -1017,6 +1533,60 @@ func (c *immuClient) History(ctx context.Context, req *schema.HistoryRequest) (s
 	return c.ServiceClient.History(ctx, req)
 }
 
+// verifyTxAttribution checks the attribution of a transaction, if any, matches the one written as part of it
+func verifyTxAttribution(tx *schema.Tx) error {
+	if tx == nil {
+		return ErrIllegalArguments
+	}
+
+	var attributionEntry *schema.TxEntry
+
+	for _, e := range tx.Entries {
+		if bytes.Equal(e.Key, database.AttributionKey) {
+			attributionEntry = e
+			break
+		}
+	}
+
+	if tx.Attribution == nil && attributionEntry == nil {
+		return nil
+	}
+
+	if tx.Attribution == nil || attributionEntry == nil {
+		return store.ErrCorruptedData
+	}
+
+	hValue := sha256.Sum256(database.EncodeAttribution(tx.Attribution).Value)
+	if !bytes.Equal(hValue[:], attributionEntry.HValue) {
+		return store.ErrCorruptedData
+	}
+
+	return nil
+}
+
+// VerifyEntryInclusion checks an entry returned along with its tx metadata and inclusion proof is included in that transaction.
+// The Alh of the transaction can then be computed from its metadata and checked against a verified state.
+func VerifyEntryInclusion(entry *schema.Entry) error {
+	if entry == nil || entry.TxMetadata == nil || entry.InclusionProof == nil {
+		return ErrIllegalArguments
+	}
+
+	if entry.TxMetadata.Id != entry.Tx {
+		return store.ErrCorruptedData
+	}
+
+	verifies := store.VerifyInclusion(
+		schema.InclusionProofFrom(entry.InclusionProof),
+		entryKV(entry.Key, entry),
+		schema.DigestFrom(entry.TxMetadata.EH),
+	)
+	if !verifies {
+		return store.ErrCorruptedData
+	}
+
+	return nil
+}
+
 // SetReference ...
 func (c *immuClient) SetReference(ctx context.Context, key []byte, referencedKey []byte) (*schema.TxMetadata, error) {
 	return c.SetReferenceAt(ctx, key, referencedKey, 0)
@@ -1041,7 +1611,8 @@ func (c *immuClient) SetReferenceAt(ctx context.Context, key []byte, referencedK
 		return nil, err
 	}
 
-	if int(txmd.Nentries) != 1 {
+	// the reference may be written along with its reference index entry, servers preceding the index don't write it
+	if !isExpectedIndexedTxLen(txmd, 1, 1) {
 		return nil, store.ErrCorruptedData
 	}
 
@@ -1093,7 +1664,8 @@ func (c *immuClient) VerifiedSetReferenceAt(ctx context.Context, key []byte, ref
 		return nil, err
 	}
 
-	if verifiableTx.Tx.Metadata.Nentries != 1 {
+	// the reference may be written along with its reference index entry, servers preceding the index don't write it
+	if !isExpectedIndexedTxLen(verifiableTx.Tx.Metadata, 1, 1) {
 		return nil, store.ErrCorruptedData
 	}
 
@@ -1164,6 +1736,18 @@ func (c *immuClient) ZAdd(ctx context.Context, set []byte, score float64, key []
 	return c.ZAddAt(ctx, set, score, key, 0)
 }
 
+// ZAddMulti adds many entries, to one or more sorted sets, within a single transaction
+func (c *immuClient) ZAddMulti(ctx context.Context, req *schema.ZAddMultiRequest) (*schema.TxMetadata, error) {
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	start := time.Now()
+	defer c.Logger.Debugf("zaddmulti finished in %s", time.Since(start))
+
+	return c.ServiceClient.ZAddMulti(ctx, req)
+}
+
 // ZAddAt ...
 func (c *immuClient) ZAddAt(ctx context.Context, set []byte, score float64, key []byte, atTx uint64) (*schema.TxMetadata, error) {
 	if !c.IsConnected() {
@@ -1184,13 +1768,131 @@ func (c *immuClient) ZAddAt(ctx context.Context, set []byte, score float64, key
 		return nil, err
 	}
 
-	if int(txmd.Nentries) != 1 {
+	// the sorted set entry may be written along with its reference index entry, servers preceding the index don't write it
+	if !isExpectedIndexedTxLen(txmd, 1, 1) {
 		return nil, store.ErrCorruptedData
 	}
 
 	return txmd, nil
 }
 
+// ZRem removes a key from a sorted set
+func (c *immuClient) ZRem(ctx context.Context, set []byte, key []byte) (*schema.TxMetadata, error) {
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	start := time.Now()
+	defer c.Logger.Debugf("zrem finished in %s", time.Since(start))
+
+	return c.ServiceClient.ZRem(ctx, &schema.ZRemRequest{
+		Set: set,
+		Key: key,
+	})
+}
+
+// ZCount returns the number of entries of a sorted set whose score is within the requested bounds
+func (c *immuClient) ZCount(ctx context.Context, req *schema.ZCountRequest) (*schema.EntryCount, error) {
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	return c.ServiceClient.ZCount(ctx, req)
+}
+
+// ZCard returns the number of entries of a sorted set. The server reads the whole set to count them, thus the
+// cost of the call is proportional to the size of the set, as for ZCount without score bounds
+func (c *immuClient) ZCard(ctx context.Context, set []byte) (*schema.EntryCount, error) {
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	return c.ServiceClient.ZCard(ctx, &schema.ZCardRequest{Set: set})
+}
+
+// ZRank returns the position of a key in a sorted set ordered by ascending score, starting from zero
+func (c *immuClient) ZRank(ctx context.Context, set []byte, key []byte) (*schema.ZRankResult, error) {
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	return c.ServiceClient.ZRank(ctx, &schema.ZRankRequest{Set: set, Key: key})
+}
+
+// ZRevRank returns the position of a key in a sorted set ordered by descending score, starting from zero
+func (c *immuClient) ZRevRank(ctx context.Context, set []byte, key []byte) (*schema.ZRankResult, error) {
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	return c.ServiceClient.ZRank(ctx, &schema.ZRankRequest{Set: set, Key: key, Desc: true})
+}
+
+// ZIncrBy atomically increments the score of a key in a sorted set, the key is added with the increment as score if
+// it's not yet a member
+func (c *immuClient) ZIncrBy(ctx context.Context, set []byte, increment float64, key []byte) (*schema.ZIncrByResult, error) {
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	start := time.Now()
+	defer c.Logger.Debugf("zincrby finished in %s", time.Since(start))
+
+	return c.ServiceClient.ZIncrBy(ctx, &schema.ZIncrByRequest{
+		Set:       set,
+		Key:       key,
+		Increment: increment,
+	})
+}
+
+// ZUnionStore replaces the content of the destination set with the union of the provided sets
+func (c *immuClient) ZUnionStore(ctx context.Context, req *schema.ZStoreRequest) (*schema.ZStoreResult, error) {
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	start := time.Now()
+	defer c.Logger.Debugf("zunionstore finished in %s", time.Since(start))
+
+	return c.ServiceClient.ZUnionStore(ctx, req)
+}
+
+// ZInterStore replaces the content of the destination set with the intersection of the provided sets
+func (c *immuClient) ZInterStore(ctx context.Context, req *schema.ZStoreRequest) (*schema.ZStoreResult, error) {
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	start := time.Now()
+	defer c.Logger.Debugf("zinterstore finished in %s", time.Since(start))
+
+	return c.ServiceClient.ZInterStore(ctx, req)
+}
+
+// ZPopMin atomically removes and returns up to count entries of a sorted set with the lowest scores
+func (c *immuClient) ZPopMin(ctx context.Context, set []byte, count uint64) (*schema.ZPopResult, error) {
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	start := time.Now()
+	defer c.Logger.Debugf("zpopmin finished in %s", time.Since(start))
+
+	return c.ServiceClient.ZPopMin(ctx, &schema.ZPopRequest{Set: set, Count: count})
+}
+
+// ZPopMax atomically removes and returns up to count entries of a sorted set with the highest scores
+func (c *immuClient) ZPopMax(ctx context.Context, set []byte, count uint64) (*schema.ZPopResult, error) {
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	start := time.Now()
+	defer c.Logger.Debugf("zpopmax finished in %s", time.Since(start))
+
+	return c.ServiceClient.ZPopMax(ctx, &schema.ZPopRequest{Set: set, Count: count})
+}
+
 // ZAdd ...
 func (c *immuClient) VerifiedZAdd(ctx context.Context, set []byte, score float64, key []byte) (*schema.TxMetadata, error) {
 	return c.VerifiedZAddAt(ctx, set, score, key, 0)
@@ -1237,7 +1939,8 @@ func (c *immuClient) VerifiedZAddAt(ctx context.Context, set []byte, score float
 		return nil, err
 	}
 
-	if vtx.Tx.Metadata.Nentries != 1 {
+	// the sorted set entry may be written along with its reference index entry, servers preceding the index don't write it
+	if !isExpectedIndexedTxLen(vtx.Tx.Metadata, 1, 1) {
 		return nil, store.ErrCorruptedData
 	}
 
@@ -1366,6 +2069,10 @@ func (c *immuClient) UseDatabase(ctx context.Context, db *schema.Database) (*sch
 	}
 
 	result, err := c.ServiceClient.UseDatabase(ctx, db)
+	if err == nil {
+		c.observeEpoch(db.DatabaseName, result.Epoch)
+		c.setSessionDatabase(db.DatabaseName, result.Token)
+	}
 
 	c.Options.CurrentDatabase = db.DatabaseName
 
@@ -1388,6 +2095,262 @@ func (c *immuClient) CleanIndex(ctx context.Context, req *empty.Empty) error {
 	return err
 }
 
+// TopPrefixes returns up to limit key prefixes of the selected database, sorted in descending order by the given counter
+func (c *immuClient) TopPrefixes(ctx context.Context, by string, limit uint32) (*schema.PrefixStatsList, error) {
+	start := time.Now()
+
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	result, err := c.ServiceClient.TopPrefixes(ctx, &schema.TopPrefixesRequest{By: by, Limit: limit})
+
+	c.Logger.Debugf("TopPrefixes finished in %s", time.Since(start))
+
+	return result, err
+}
+
+// KeyspaceHistogram returns the number of keys of the selected database and the space taken by them, grouped by prefix
+func (c *immuClient) KeyspaceHistogram(ctx context.Context, req *schema.KeyspaceHistogramRequest) (*schema.KeyspaceHistogramResult, error) {
+	start := time.Now()
+
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	result, err := c.ServiceClient.KeyspaceHistogram(ctx, req)
+
+	c.Logger.Debugf("KeyspaceHistogram finished in %s", time.Since(start))
+
+	return result, err
+}
+
+// SetKeyRules replaces the naming rules keys written into the selected database must satisfy, empty rules accept any key
+func (c *immuClient) SetKeyRules(ctx context.Context, rules *schema.KeyRules) error {
+	start := time.Now()
+
+	if !c.IsConnected() {
+		return ErrNotConnected
+	}
+
+	_, err := c.ServiceClient.SetKeyRules(ctx, rules)
+
+	c.Logger.Debugf("SetKeyRules finished in %s", time.Since(start))
+
+	return err
+}
+
+// GetKeyRules returns the naming rules keys written into the selected database must satisfy
+func (c *immuClient) GetKeyRules(ctx context.Context) (*schema.KeyRules, error) {
+	start := time.Now()
+
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	result, err := c.ServiceClient.GetKeyRules(ctx, &empty.Empty{})
+
+	c.Logger.Debugf("GetKeyRules finished in %s", time.Since(start))
+
+	return result, err
+}
+
+// SetApprovalRules replaces the key prefixes of the selected database whose writes must be approved by a second user
+func (c *immuClient) SetApprovalRules(ctx context.Context, rules *schema.ApprovalRules) error {
+	start := time.Now()
+
+	if !c.IsConnected() {
+		return ErrNotConnected
+	}
+
+	_, err := c.ServiceClient.SetApprovalRules(ctx, rules)
+
+	c.Logger.Debugf("SetApprovalRules finished in %s", time.Since(start))
+
+	return err
+}
+
+// GetApprovalRules returns the key prefixes of the selected database whose writes must be approved
+func (c *immuClient) GetApprovalRules(ctx context.Context) (*schema.ApprovalRules, error) {
+	start := time.Now()
+
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	result, err := c.ServiceClient.GetApprovalRules(ctx, &empty.Empty{})
+
+	c.Logger.Debugf("GetApprovalRules finished in %s", time.Since(start))
+
+	return result, err
+}
+
+// ProposeWrite stages operations writing keys which require approval, they are committed once approved by a second user
+func (c *immuClient) ProposeWrite(ctx context.Context, req *schema.ExecAllRequest) (*schema.PendingWrite, error) {
+	start := time.Now()
+
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	result, err := c.ServiceClient.ProposeWrite(ctx, req)
+
+	c.Logger.Debugf("ProposeWrite finished in %s", time.Since(start))
+
+	return result, err
+}
+
+// ListPendingWrites returns the writes waiting for approval, approved and rejected ones are included if includeDecided is set
+func (c *immuClient) ListPendingWrites(ctx context.Context, includeDecided bool) (*schema.PendingWrites, error) {
+	start := time.Now()
+
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	result, err := c.ServiceClient.ListPendingWrites(ctx, &schema.PendingWritesRequest{IncludeDecided: includeDecided})
+
+	c.Logger.Debugf("ListPendingWrites finished in %s", time.Since(start))
+
+	return result, err
+}
+
+// ApproveWrite commits a write proposed by another user
+func (c *immuClient) ApproveWrite(ctx context.Context, id string) (*schema.PendingWrite, error) {
+	start := time.Now()
+
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	result, err := c.ServiceClient.ApproveWrite(ctx, &schema.PendingWriteDecision{Id: id})
+
+	c.Logger.Debugf("ApproveWrite finished in %s", time.Since(start))
+
+	return result, err
+}
+
+// RejectWrite discards a write proposed by another user, the rejection is recorded along with the reason
+func (c *immuClient) RejectWrite(ctx context.Context, id string, reason string) (*schema.PendingWrite, error) {
+	start := time.Now()
+
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	result, err := c.ServiceClient.RejectWrite(ctx, &schema.PendingWriteDecision{Id: id, Reason: reason})
+
+	c.Logger.Debugf("RejectWrite finished in %s", time.Since(start))
+
+	return result, err
+}
+
+// PlaceLegalHold places a legal hold on a prefix, keys within it can't be deleted or expired until the hold is lifted
+func (c *immuClient) PlaceLegalHold(ctx context.Context, prefix []byte, reason string) (*schema.LegalHold, error) {
+	start := time.Now()
+
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	result, err := c.ServiceClient.PlaceLegalHold(ctx, &schema.LegalHoldRequest{Prefix: prefix, Reason: reason})
+
+	c.Logger.Debugf("PlaceLegalHold finished in %s", time.Since(start))
+
+	return result, err
+}
+
+// LiftLegalHold lifts the legal hold placed on a prefix
+func (c *immuClient) LiftLegalHold(ctx context.Context, prefix []byte, reason string) (*schema.LegalHold, error) {
+	start := time.Now()
+
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	result, err := c.ServiceClient.LiftLegalHold(ctx, &schema.LegalHoldRequest{Prefix: prefix, Reason: reason})
+
+	c.Logger.Debugf("LiftLegalHold finished in %s", time.Since(start))
+
+	return result, err
+}
+
+// ListLegalHolds returns the legal holds currently placed on the selected database
+func (c *immuClient) ListLegalHolds(ctx context.Context) (*schema.LegalHolds, error) {
+	start := time.Now()
+
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	result, err := c.ServiceClient.ListLegalHolds(ctx, &empty.Empty{})
+
+	c.Logger.Debugf("ListLegalHolds finished in %s", time.Since(start))
+
+	return result, err
+}
+
+// LegalHoldHistory returns every placement and lift of the legal hold on a prefix
+func (c *immuClient) LegalHoldHistory(ctx context.Context, prefix []byte) (*schema.LegalHolds, error) {
+	start := time.Now()
+
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	result, err := c.ServiceClient.LegalHoldHistory(ctx, &schema.LegalHoldRequest{Prefix: prefix})
+
+	c.Logger.Debugf("LegalHoldHistory finished in %s", time.Since(start))
+
+	return result, err
+}
+
+// PinSnapshot pins the state of the selected database as of a transaction under a name, the latest committed
+// transaction is pinned when txID is zero
+func (c *immuClient) PinSnapshot(ctx context.Context, name string, txID uint64) (*schema.PinnedSnapshot, error) {
+	start := time.Now()
+
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	result, err := c.ServiceClient.PinSnapshot(ctx, &schema.PinSnapshotRequest{Name: name, TxID: txID})
+
+	c.Logger.Debugf("PinSnapshot finished in %s", time.Since(start))
+
+	return result, err
+}
+
+// UnpinSnapshot removes the snapshot pinned under the name
+func (c *immuClient) UnpinSnapshot(ctx context.Context, name string) error {
+	start := time.Now()
+
+	if !c.IsConnected() {
+		return ErrNotConnected
+	}
+
+	_, err := c.ServiceClient.UnpinSnapshot(ctx, &schema.UnpinSnapshotRequest{Name: name})
+
+	c.Logger.Debugf("UnpinSnapshot finished in %s", time.Since(start))
+
+	return err
+}
+
+// ListPinnedSnapshots returns the snapshots pinned on the selected database
+func (c *immuClient) ListPinnedSnapshots(ctx context.Context) (*schema.PinnedSnapshots, error) {
+	start := time.Now()
+
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	result, err := c.ServiceClient.ListPinnedSnapshots(ctx, &empty.Empty{})
+
+	c.Logger.Debugf("ListPinnedSnapshots finished in %s", time.Since(start))
+
+	return result, err
+}
+
 func (c *immuClient) ChangePermission(ctx context.Context, action schema.PermissionAction, username string, database string, permissions uint32) error {
 	start := time.Now()
 
@@ -1462,6 +2425,65 @@ func (c *immuClient) SafeReference(ctx context.Context, key []byte, referencedKe
 	return c.VerifiedSetReference(ctx, key, referencedKey)
 }
 
+// isExpectedTxLen checks the transaction holds the written entries plus, when the server records attribution, an additional one
+func isExpectedTxLen(txmd *schema.TxMetadata, written int) bool {
+	return int(txmd.Nentries) == written || int(txmd.Nentries) == written+1
+}
+
+// isExpectedKVTxLen checks the transaction holds the written key-values plus the entries of the secondary indexes
+// covering them, up to one per key-value and index, and the entry recording the idempotency key when one was sent
+// and the server persists them, as checked by isExpectedIndexedTxLen
+func isExpectedKVTxLen(txmd *schema.TxMetadata, written int, idempotencyKey string) bool {
+	return isExpectedIndexedTxLen(txmd, written, written*database.MaxSecondaryIndexes+idempotencyEntries(idempotencyKey))
+}
+
+// isExpectedTxEntries checks the entries of a verifiable transaction are the written ones plus the secondary index
+// entries added by the server, the attribution entry when the transaction is attributed and, when an idempotency
+// key was sent, the entry recording it if the server persists them
+func isExpectedTxEntries(tx *schema.Tx, written int, idempotencyKey string) bool {
+	if int(tx.Metadata.Nentries) != len(tx.Entries) {
+		return false
+	}
+
+	if tx.Attribution != nil {
+		written++
+	}
+
+	idempotent := idempotencyEntries(idempotencyKey)
+
+	for _, e := range tx.Entries {
+		if len(e.Key) > 0 && e.Key[0] == database.SecondaryIndexKeyPrefix {
+			continue
+		}
+		if idempotent > 0 && len(e.Key) > 0 && e.Key[0] == database.IdempotencyKeyPrefix {
+			idempotent--
+			continue
+		}
+		written--
+	}
+
+	return written == 0
+}
+
+// isExpectedIndexedTxLen checks the transaction holds the written entries plus up to indexed reference index entries,
+// as checked by isExpectedTxLen. Index entries are optional, written ones are verified by their inclusion proofs
+func isExpectedIndexedTxLen(txmd *schema.TxMetadata, written, indexed int) bool {
+	for i := 0; i <= indexed; i++ {
+		if isExpectedTxLen(txmd, written+i) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *immuClient) attribution() *schema.Attribution {
+	if c.Options.Actor == "" {
+		return nil
+	}
+	return &schema.Attribution{Actor: c.Options.Actor}
+}
+
 func decodeTxEntries(entries []*schema.TxEntry) {
 	for _, it := range entries {
 		it.Key = it.Key[1:]
@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"math"
 	"os"
 	"path"
 	"testing"
@@ -148,6 +149,85 @@ func testZAdd(ctx context.Context, t *testing.T, set []byte, scores []float64, k
 	}
 }
 
+func testZCountAndZRem(ctx context.Context, t *testing.T, set []byte, scores []float64, keys [][]byte, client ImmuClient) {
+	for i := 0; i < len(scores); i++ {
+		_, err := client.ZAdd(ctx, set, scores[i], keys[i])
+		require.NoError(t, err)
+	}
+
+	count, err := client.ZCount(ctx, &schema.ZCountRequest{Set: set, MinScore: &schema.Score{Score: scores[1]}})
+	require.NoError(t, err)
+	require.Equal(t, uint64(len(scores)-1), count.Count)
+
+	rank, err := client.ZRank(ctx, set, keys[0])
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), rank.Rank)
+
+	rank, err = client.ZRevRank(ctx, set, keys[0])
+	require.NoError(t, err)
+	require.Equal(t, uint64(len(scores)-1), rank.Rank)
+
+	_, err = client.ZRem(ctx, set, keys[len(keys)-1])
+	require.NoError(t, err)
+
+	count, err = client.ZCount(ctx, &schema.ZCountRequest{Set: set, MinScore: &schema.Score{Score: scores[1]}})
+	require.NoError(t, err)
+	require.Equal(t, uint64(len(scores)-2), count.Count)
+
+	incr, err := client.ZIncrBy(ctx, set, 10, keys[0])
+	require.NoError(t, err)
+	require.Equal(t, scores[0]+10, incr.Score)
+
+	rank, err = client.ZRank(ctx, set, keys[0])
+	require.NoError(t, err)
+	require.Equal(t, uint64(len(scores)-2), rank.Rank)
+
+	dst := append([]byte("copy_"), set...)
+
+	stored, err := client.ZUnionStore(ctx, &schema.ZStoreRequest{Destination: dst, Sets: [][]byte{set}})
+	require.NoError(t, err)
+	require.Equal(t, uint64(len(scores)-1), stored.Count)
+
+	stored, err = client.ZInterStore(ctx, &schema.ZStoreRequest{
+		Destination: dst,
+		Sets:        [][]byte{set, dst},
+		Aggregation: schema.ZAggregation_ZAGGREGATE_MIN,
+	})
+	require.NoError(t, err)
+	require.Equal(t, uint64(len(scores)-1), stored.Count)
+	require.Nil(t, stored.TxMetadata)
+
+	popped, err := client.ZPopMax(ctx, dst, 1)
+	require.NoError(t, err)
+	require.Len(t, popped.Entries, 1)
+	require.Equal(t, keys[0], popped.Entries[0].Key)
+	require.Equal(t, scores[0]+10, popped.Entries[0].Score)
+
+	popped, err = client.ZPopMin(ctx, dst, 10)
+	require.NoError(t, err)
+	require.Len(t, popped.Entries, len(scores)-2)
+
+	count, err = client.ZCard(ctx, dst)
+	require.NoError(t, err)
+	require.Zero(t, count.Count)
+
+	count, err = client.ZCard(ctx, set)
+	require.NoError(t, err)
+	require.Equal(t, uint64(len(scores)-1), count.Count)
+
+	entries := make([]*schema.ZAddRequest, len(keys))
+	for i := range keys {
+		entries[i] = &schema.ZAddRequest{Set: dst, Key: keys[i], Score: scores[i]}
+	}
+
+	_, err = client.ZAddMulti(ctx, &schema.ZAddMultiRequest{Entries: entries})
+	require.NoError(t, err)
+
+	count, err = client.ZCard(ctx, dst)
+	require.NoError(t, err)
+	require.Equal(t, uint64(len(keys)), count.Count)
+}
+
 func testZAddAt(ctx context.Context, t *testing.T, set []byte, scores []float64, keys [][]byte, values [][]byte, at uint64, client ImmuClient) {
 	var md *schema.TxMetadata
 	var err error
@@ -271,6 +351,8 @@ func TestImmuClient(t *testing.T) {
 	testImmuClient_VerifiedTxByID(ctx, t, testData.set, testData.scores, testData.keys, testData.values, client)
 
 	testGet(ctx, t, client)
+
+	testZCountAndZRem(ctx, t, []byte("zcountset"), testData.scores, testData.keys, client)
 }
 
 func TestImmuClientTampering(t *testing.T) {
@@ -515,6 +597,9 @@ func TestImmuClientDisconnect(t *testing.T) {
 	require.Equal(t, ErrNotConnected, client.UpdateMTLSConfig(ctx, false))
 	require.Equal(t, ErrNotConnected, client.CleanIndex(ctx, &emptypb.Empty{}))
 
+	_, err = client.TopPrefixes(ctx, "reads", 10)
+	require.Equal(t, ErrNotConnected, err)
+
 	_, err = client.Login(context.TODO(), []byte("user"), []byte("passwd"))
 	require.Equal(t, ErrNotConnected, err)
 
@@ -844,6 +929,159 @@ func TestImmuClient_History(t *testing.T) {
 	client.Disconnect()
 }
 
+func TestImmuClient_Delete(t *testing.T) {
+	options := server.DefaultOptions().WithAuth(true)
+	bs := servertest.NewBufconnServer(options)
+
+	defer os.RemoveAll(options.Dir)
+	defer os.Remove(".state-")
+
+	bs.Start()
+	defer bs.Stop()
+
+	ts := NewTokenService().WithTokenFileName("testTokenFile").WithHds(DefaultHomedirServiceMock())
+	client, err := NewImmuClient(DefaultOptions().WithDialOptions(&[]grpc.DialOption{grpc.WithContextDialer(bs.Dialer), grpc.WithInsecure()}).WithTokenService(ts))
+	require.NoError(t, err)
+	defer client.Disconnect()
+
+	lr, err := client.Login(context.TODO(), []byte(`immudb`), []byte(`immudb`))
+	require.NoError(t, err)
+
+	ctx := metadata.NewOutgoingContext(context.Background(), metadata.Pairs("authorization", lr.Token))
+
+	_, err = client.Set(ctx, []byte(`key1`), []byte(`val1`))
+	require.NoError(t, err)
+
+	txmd, err := client.Delete(ctx, []byte(`key1`))
+	require.NoError(t, err)
+
+	_, err = client.Get(ctx, []byte(`key1`))
+	require.Error(t, err)
+
+	_, err = client.Delete(ctx, []byte(`key1`))
+	require.Error(t, err)
+
+	sil, err := client.History(ctx, &schema.HistoryRequest{
+		Key:     []byte(`key1`),
+		SinceTx: txmd.Id,
+	})
+	require.NoError(t, err)
+	require.Len(t, sil.Entries, 2)
+	require.True(t, sil.Entries[1].Deleted)
+
+	_, err = client.SetAll(ctx, &schema.SetRequest{KVs: []*schema.KeyValue{
+		{Key: []byte(`tenant1:key1`), Value: []byte(`val1`)},
+		{Key: []byte(`tenant1:key2`), Value: []byte(`val2`)},
+		{Key: []byte(`tenant1:key3`), Value: []byte(`val3`)},
+	}})
+	require.NoError(t, err)
+
+	res, err := client.DeleteByPrefix(ctx, &schema.DeleteByPrefixRequest{Prefix: []byte(`tenant1:`), DryRun: true})
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), res.Count)
+	require.Empty(t, res.Txs)
+
+	res, err = client.DeleteByPrefix(ctx, &schema.DeleteByPrefixRequest{Prefix: []byte(`tenant1:`), BatchSize: 2})
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), res.Count)
+	require.Len(t, res.Txs, 2)
+
+	_, err = client.Get(ctx, []byte(`tenant1:key3`))
+	require.Error(t, err)
+}
+
+func TestImmuClient_Increment(t *testing.T) {
+	options := server.DefaultOptions().WithAuth(true)
+	bs := servertest.NewBufconnServer(options)
+
+	defer os.RemoveAll(options.Dir)
+	defer os.Remove(".state-")
+
+	bs.Start()
+	defer bs.Stop()
+
+	ts := NewTokenService().WithTokenFileName("testTokenFile").WithHds(DefaultHomedirServiceMock())
+	client, err := NewImmuClient(DefaultOptions().WithDialOptions(&[]grpc.DialOption{grpc.WithContextDialer(bs.Dialer), grpc.WithInsecure()}).WithTokenService(ts))
+	require.NoError(t, err)
+	defer client.Disconnect()
+
+	lr, err := client.Login(context.TODO(), []byte(`immudb`), []byte(`immudb`))
+	require.NoError(t, err)
+
+	ctx := metadata.NewOutgoingContext(context.Background(), metadata.Pairs("authorization", lr.Token))
+
+	res, err := client.Increment(ctx, []byte(`counter`), 10)
+	require.NoError(t, err)
+	require.Equal(t, int64(10), res.Value)
+
+	res, err = client.Decrement(ctx, []byte(`counter`), 3)
+	require.NoError(t, err)
+	require.Equal(t, int64(7), res.Value)
+
+	e, err := client.Get(ctx, []byte(`counter`))
+	require.NoError(t, err)
+	require.Equal(t, []byte(`7`), e.Value)
+	require.Equal(t, res.TxMetadata.Id, e.Tx)
+
+	_, err = client.Decrement(ctx, []byte(`counter`), math.MinInt64)
+	require.Equal(t, ErrIllegalArguments, err)
+}
+
+func TestImmuClient_GetMulti(t *testing.T) {
+	options := server.DefaultOptions().WithAuth(true)
+	bs := servertest.NewBufconnServer(options)
+
+	defer os.RemoveAll(options.Dir)
+	defer os.Remove(".state-")
+
+	bs.Start()
+	defer bs.Stop()
+
+	ts := NewTokenService().WithTokenFileName("testTokenFile").WithHds(DefaultHomedirServiceMock())
+	client, err := NewImmuClient(DefaultOptions().WithDialOptions(&[]grpc.DialOption{grpc.WithContextDialer(bs.Dialer), grpc.WithInsecure()}).WithTokenService(ts))
+	require.NoError(t, err)
+	defer client.Disconnect()
+
+	lr, err := client.Login(context.TODO(), []byte(`immudb`), []byte(`immudb`))
+	require.NoError(t, err)
+
+	ctx := metadata.NewOutgoingContext(context.Background(), metadata.Pairs("authorization", lr.Token))
+
+	_, err = client.VerifiedSet(ctx, []byte(`key1`), []byte(`value1`))
+	require.NoError(t, err)
+
+	_, err = client.Set(ctx, []byte(`key2`), []byte(`value2`))
+	require.NoError(t, err)
+
+	req := &schema.GetMultiRequest{
+		Keys:          [][]byte{[]byte(`key1`), []byte(`key3`), []byte(`key2`)},
+		PerItemErrors: true,
+	}
+
+	res, err := client.GetMulti(ctx, req)
+	require.NoError(t, err)
+	require.Len(t, res.Items, 3)
+	require.Equal(t, []byte(`value1`), res.Items[0].Entry.Value)
+	require.NotEmpty(t, res.Items[1].Error)
+	require.Equal(t, []byte(`value2`), res.Items[2].Entry.Value)
+
+	vres, err := client.VerifiedGetMulti(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, res.SnapshotTx, vres.SnapshotTx)
+	require.Len(t, vres.Items, 3)
+	require.Equal(t, []byte(`value1`), vres.Items[0].Entry.Value)
+	require.Nil(t, vres.Items[1].Entry)
+	require.NotEmpty(t, vres.Items[1].Error)
+	require.Equal(t, []byte(`value2`), vres.Items[2].Entry.Value)
+
+	state, err := client.CurrentState(ctx)
+	require.NoError(t, err)
+	require.Equal(t, vres.Items[2].Entry.Tx, state.TxId)
+
+	_, err = client.VerifiedGetMulti(ctx, nil)
+	require.Equal(t, ErrIllegalArguments, err)
+}
+
 func TestImmuClient_SetAll(t *testing.T) {
 	options := server.DefaultOptions().WithAuth(true)
 	options.StoreOptions.WithIndexOptions(options.StoreOptions.IndexOpts.WithCompactionThld(0))
@@ -887,6 +1125,10 @@ func TestImmuClient_SetAll(t *testing.T) {
 	err = client.CleanIndex(ctx, &emptypb.Empty{})
 	require.NoError(t, err)
 
+	// per-prefix statistics are disabled by default
+	_, err = client.TopPrefixes(ctx, "reads", 10)
+	require.Error(t, err)
+
 	for _, kv := range setRequest.KVs {
 		i, err := client.Get(ctx, kv.Key)
 		require.NoError(t, err)
@@ -1504,6 +1746,240 @@ func TestImmuClient_VerifiedGetAt(t *testing.T) {
 	client.Disconnect()
 }
 
+func TestImmuClient_EntryCache(t *testing.T) {
+	options := server.DefaultOptions().WithAuth(true)
+	bs := servertest.NewBufconnServer(options)
+
+	defer os.RemoveAll(options.Dir)
+	defer os.Remove(".state-")
+
+	bs.Start()
+	defer bs.Stop()
+
+	ts := NewTokenService().WithTokenFileName("testTokenFile").WithHds(DefaultHomedirServiceMock())
+	opts := DefaultOptions().WithDialOptions(&[]grpc.DialOption{grpc.WithContextDialer(bs.Dialer), grpc.WithInsecure()}).WithTokenService(ts)
+	client, err := NewImmuClient(opts.WithEntryCacheSize(10))
+	require.NoError(t, err)
+
+	lr, err := client.Login(context.TODO(), []byte(`immudb`), []byte(`immudb`))
+	require.NoError(t, err)
+
+	md := metadata.Pairs("authorization", lr.Token)
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	txMeta, err := client.Set(ctx, []byte(`key1`), []byte(`val1`))
+	require.NoError(t, err)
+
+	entry, err := client.Get(ctx, []byte(`key1`))
+	require.NoError(t, err)
+	require.Equal(t, []byte(`val1`), entry.Value)
+
+	entry, err = client.VerifiedGetAt(ctx, []byte(`key1`), txMeta.Id)
+	require.NoError(t, err)
+	require.Equal(t, []byte(`val1`), entry.Value)
+
+	// cached entries are served even once disconnected
+	client.Disconnect()
+
+	entry, err = client.VerifiedGetAt(ctx, []byte(`key1`), txMeta.Id)
+	require.NoError(t, err)
+	require.Equal(t, []byte(`val1`), entry.Value)
+
+	_, err = client.GetAt(ctx, []byte(`key1`), txMeta.Id+1)
+	require.Equal(t, ErrNotConnected, err)
+}
+
+func TestImmuClient_ReverifyEntryCache(t *testing.T) {
+	options := server.DefaultOptions().WithAuth(true)
+	bs := servertest.NewBufconnServer(options)
+
+	defer os.RemoveAll(options.Dir)
+	defer os.Remove(".state-")
+
+	bs.Start()
+	defer bs.Stop()
+
+	ts := NewTokenService().WithTokenFileName("testTokenFile").WithHds(DefaultHomedirServiceMock())
+	opts := DefaultOptions().WithDialOptions(&[]grpc.DialOption{grpc.WithContextDialer(bs.Dialer), grpc.WithInsecure()}).WithTokenService(ts)
+	client, err := NewImmuClient(opts.WithEntryCacheSize(10))
+	require.NoError(t, err)
+	defer client.Disconnect()
+
+	lr, err := client.Login(context.TODO(), []byte(`immudb`), []byte(`immudb`))
+	require.NoError(t, err)
+
+	md := metadata.Pairs("authorization", lr.Token)
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	txMeta1, err := client.Set(ctx, []byte(`key1`), []byte(`val1`))
+	require.NoError(t, err)
+
+	txMeta2, err := client.Set(ctx, []byte(`key2`), []byte(`val2`))
+	require.NoError(t, err)
+
+	_, err = client.VerifiedGetAt(ctx, []byte(`key1`), txMeta1.Id)
+	require.NoError(t, err)
+
+	_, err = client.VerifiedGetAt(ctx, []byte(`key2`), txMeta2.Id)
+	require.NoError(t, err)
+
+	evicted, err := client.ReverifyEntryCache(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 0, evicted)
+
+	// a cached entry which no longer matches the server one is evicted
+	cache := client.(*immuClient).EntryCache
+	db := client.GetOptions().CurrentDatabase
+	cache.Set(db, []byte(`key2`), &schema.Entry{Tx: txMeta2.Id, Key: []byte(`key2`), Value: []byte(`tampered`)}, true)
+
+	evicted, err = client.ReverifyEntryCache(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, evicted)
+
+	_, ok := cache.Get(db, []byte(`key2`), txMeta2.Id, true)
+	require.False(t, ok)
+
+	_, ok = cache.Get(db, []byte(`key1`), txMeta1.Id, true)
+	require.True(t, ok)
+}
+
+func TestImmuClient_EntryCacheReverifyInterval(t *testing.T) {
+	options := server.DefaultOptions().WithAuth(false)
+	bs := servertest.NewBufconnServer(options)
+
+	defer os.RemoveAll(options.Dir)
+	defer os.Remove(".state-")
+
+	bs.Start()
+	defer bs.Stop()
+
+	ts := NewTokenService().WithTokenFileName("testTokenFile").WithHds(DefaultHomedirServiceMock())
+	opts := DefaultOptions().
+		WithDialOptions(&[]grpc.DialOption{grpc.WithContextDialer(bs.Dialer), grpc.WithInsecure()}).
+		WithTokenService(ts).
+		WithAuth(false).
+		WithEntryCacheSize(10).
+		WithEntryCacheReverifyInterval(10 * time.Millisecond)
+
+	client, err := NewImmuClient(opts)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	txMeta, err := client.Set(ctx, []byte(`key1`), []byte(`val1`))
+	require.NoError(t, err)
+
+	_, err = client.VerifiedGetAt(ctx, []byte(`key1`), txMeta.Id)
+	require.NoError(t, err)
+
+	cache := client.(*immuClient).EntryCache
+	db := client.GetOptions().CurrentDatabase
+	cache.Set(db, []byte(`key1`), &schema.Entry{Tx: txMeta.Id, Key: []byte(`key1`), Value: []byte(`tampered`)}, true)
+
+	// verified entries are checked again in background
+	require.Eventually(t, func() bool {
+		_, ok := cache.Get(db, []byte(`key1`), txMeta.Id, true)
+		return !ok
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, client.Disconnect())
+	require.Nil(t, client.(*immuClient).cacheReverifier)
+}
+
+func TestImmuClient_WatchEntryCache(t *testing.T) {
+	options := server.DefaultOptions().WithAuth(true)
+	bs := servertest.NewBufconnServer(options)
+
+	defer os.RemoveAll(options.Dir)
+	defer os.Remove(".state-")
+
+	bs.Start()
+	defer bs.Stop()
+
+	ts := NewTokenService().WithTokenFileName("testTokenFile").WithHds(DefaultHomedirServiceMock())
+	opts := DefaultOptions().WithDialOptions(&[]grpc.DialOption{grpc.WithContextDialer(bs.Dialer), grpc.WithInsecure()}).WithTokenService(ts)
+	client, err := NewImmuClient(opts.WithEntryCacheSize(10))
+	require.NoError(t, err)
+	defer client.Disconnect()
+
+	lr, err := client.Login(context.TODO(), []byte(`immudb`), []byte(`immudb`))
+	require.NoError(t, err)
+
+	md := metadata.Pairs("authorization", lr.Token)
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	_, err = client.Set(ctx, []byte(`key1`), []byte(`val1`))
+	require.NoError(t, err)
+
+	cache := client.(*immuClient).EntryCache
+	db := client.GetOptions().CurrentDatabase
+
+	// latest values are not served from the cache unless it's watched
+	_, err = client.Get(ctx, []byte(`key1`))
+	require.NoError(t, err)
+
+	_, ok := cache.GetLatest(db, []byte(`key1`), false)
+	require.False(t, ok)
+
+	watchCtx, cancel := context.WithCancel(ctx)
+
+	watchErr := make(chan error)
+	go func() {
+		watchErr <- client.WatchEntryCache(watchCtx)
+	}()
+
+	require.Eventually(t, client.(*immuClient).isEntryCacheWatched, 5*time.Second, 10*time.Millisecond)
+
+	e, err := client.VerifiedGet(ctx, []byte(`key1`))
+	require.NoError(t, err)
+	require.Equal(t, []byte(`val1`), e.Value)
+
+	_, ok = cache.GetLatest(db, []byte(`key1`), true)
+	require.True(t, ok)
+
+	_, err = client.Set(ctx, []byte(`key1`), []byte(`val2`))
+	require.NoError(t, err)
+
+	// the cached value is invalidated once the write is notified
+	require.Eventually(t, func() bool {
+		_, ok := cache.GetLatest(db, []byte(`key1`), false)
+		return !ok
+	}, 5*time.Second, 10*time.Millisecond)
+
+	e, err = client.Get(ctx, []byte(`key1`))
+	require.NoError(t, err)
+	require.Equal(t, []byte(`val2`), e.Value)
+
+	e, ok = cache.GetLatest(db, []byte(`key1`), false)
+	require.True(t, ok)
+	require.Equal(t, []byte(`val2`), e.Value)
+
+	_, err = client.Delete(ctx, []byte(`key1`))
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		_, ok := cache.GetLatest(db, []byte(`key1`), false)
+		return !ok
+	}, 5*time.Second, 10*time.Millisecond)
+
+	_, err = client.Get(ctx, []byte(`key1`))
+	require.Error(t, err)
+
+	_, err = client.Set(ctx, []byte(`key1`), []byte(`val3`))
+	require.NoError(t, err)
+
+	_, err = client.Get(ctx, []byte(`key1`))
+	require.NoError(t, err)
+
+	cancel()
+	require.Error(t, <-watchErr)
+
+	require.False(t, client.(*immuClient).isEntryCacheWatched())
+
+	_, ok = cache.GetLatest(db, []byte(`key1`), false)
+	require.False(t, ok)
+}
+
 func TestImmuClient_VerifiedGetSince(t *testing.T) {
 	options := server.DefaultOptions().WithAuth(true)
 	bs := servertest.NewBufconnServer(options)
@@ -1710,3 +2186,68 @@ func (ts TokenServiceMock) WithHds(hds HomedirService) TokenService {
 func (ts TokenServiceMock) WithTokenFileName(tfn string) TokenService {
 	return ts
 }
+
+func TestImmuClient_Attribution(t *testing.T) {
+	options := server.DefaultOptions().WithAuth(true).WithAttribution(true)
+	bs := servertest.NewBufconnServer(options)
+
+	defer os.RemoveAll(options.Dir)
+	defer os.Remove(".state-")
+
+	bs.Start()
+	defer bs.Stop()
+
+	ts := NewTokenService().WithTokenFileName("testTokenFile").WithHds(DefaultHomedirServiceMock())
+	opts := DefaultOptions().WithDialOptions(&[]grpc.DialOption{grpc.WithContextDialer(bs.Dialer), grpc.WithInsecure()}).WithTokenService(ts)
+	client, err := NewImmuClient(opts.WithActor("billing-service"))
+	require.NoError(t, err)
+	defer client.Disconnect()
+
+	lr, err := client.Login(context.TODO(), []byte(`immudb`), []byte(`immudb`))
+	require.NoError(t, err)
+
+	md := metadata.Pairs("authorization", lr.Token)
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	txMeta, err := client.Set(ctx, []byte(`key1`), []byte(`val1`))
+	require.NoError(t, err)
+	require.Equal(t, int32(2), txMeta.Nentries)
+
+	txMeta, err = client.VerifiedSet(ctx, []byte(`key1`), []byte(`val2`))
+	require.NoError(t, err)
+
+	tx, err := client.VerifiedTxByID(ctx, txMeta.Id)
+	require.NoError(t, err)
+	require.Equal(t, auth.SysAdminUsername, tx.Attribution.User)
+	require.Equal(t, "billing-service", tx.Attribution.Actor)
+
+	tx, err = client.(*immuClient).ServiceClient.TxById(ctx, &schema.TxRequest{Tx: txMeta.Id})
+	require.NoError(t, err)
+	require.NoError(t, verifyTxAttribution(tx))
+
+	tx.Attribution.User = "someone-else"
+	require.Equal(t, store.ErrCorruptedData, verifyTxAttribution(tx))
+
+	tx.Attribution = nil
+	require.Equal(t, store.ErrCorruptedData, verifyTxAttribution(tx))
+
+	history, err := client.History(ctx, &schema.HistoryRequest{Key: []byte(`key1`), WithTxMetadata: true})
+	require.NoError(t, err)
+	require.Len(t, history.Entries, 2)
+	require.Equal(t, auth.SysAdminUsername, history.Entries[0].Attribution.User)
+
+	_, err = client.ExecAll(ctx, &schema.ExecAllRequest{
+		Operations: []*schema.Op{
+			{Operation: &schema.Op_Kv{Kv: &schema.KeyValue{Key: []byte(`key2`), Value: []byte(`val1`)}}},
+		},
+		Attribution: &schema.Attribution{User: "forged", Actor: "batch"},
+	})
+	require.NoError(t, err)
+
+	txs, err := client.TxScan(ctx, &schema.TxScanRequest{InitialTx: txMeta.Id + 1})
+	require.NoError(t, err)
+	require.Equal(t, auth.SysAdminUsername, txs.Txs[0].Attribution.User)
+	require.Equal(t, "batch", txs.Txs[0].Attribution.Actor)
+
+	require.Equal(t, ErrIllegalArguments, verifyTxAttribution(nil))
+}
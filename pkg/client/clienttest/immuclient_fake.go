@@ -0,0 +1,895 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package clienttest
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/client"
+	"google.golang.org/grpc"
+)
+
+// ImmuClientFake is an in-memory implementation of the key-value and sorted set operations of the client,
+// so applications can be unit tested without running a server.
+//
+// Each write is committed as a new transaction whose hash chains the hash of the previous one. Verified operations
+// recompute the chain and compare it with the last state they verified, returning store.ErrCorruptedData on
+// mismatch, thus Tamper can be used to test how an application reacts to tampered data.
+//
+// Operations which are not faked are delegated to the embedded ImmuClient, a disconnected client by default.
+type ImmuClientFake struct {
+	client.ImmuClient
+
+	// Hook is called with the name of the method before running each faked operation,
+	// the operation fails with the returned error if not nil
+	Hook func(ctx context.Context, method string) error
+
+	// Latency is waited before running each faked operation
+	Latency time.Duration
+
+	mutex sync.Mutex
+
+	txs   []*fakeTx
+	keys  map[string][]*fakeEntry
+	zsets map[string][]*fakeEntry
+
+	// last state checked by a verified operation
+	state *schema.ImmutableState
+}
+
+type fakeTx struct {
+	id      uint64
+	ts      int64
+	entries []*fakeEntry
+	hash    [sha256.Size]byte
+}
+
+type fakeEntry struct {
+	tx    uint64
+	key   []byte
+	value []byte
+
+	// set for references
+	refKey  []byte
+	refAtTx uint64
+
+	// set for sorted set entries, atTx is the version of the key they are bound to
+	set   []byte
+	score float64
+	atTx  uint64
+}
+
+// NewImmuClientFake returns an empty fake
+func NewImmuClientFake() *ImmuClientFake {
+	return &ImmuClientFake{
+		ImmuClient: client.DefaultClient(),
+		keys:       make(map[string][]*fakeEntry),
+		zsets:      make(map[string][]*fakeEntry),
+	}
+}
+
+func (e *fakeEntry) digest() [sha256.Size]byte {
+	var b bytes.Buffer
+
+	for _, f := range [][]byte{e.key, e.value, e.refKey, e.set} {
+		binary.Write(&b, binary.BigEndian, uint32(len(f)))
+		b.Write(f)
+	}
+
+	binary.Write(&b, binary.BigEndian, e.refAtTx)
+	binary.Write(&b, binary.BigEndian, math.Float64bits(e.score))
+	binary.Write(&b, binary.BigEndian, e.atTx)
+
+	return sha256.Sum256(b.Bytes())
+}
+
+func (tx *fakeTx) computeHash(prev [sha256.Size]byte) [sha256.Size]byte {
+	var b bytes.Buffer
+
+	b.Write(prev[:])
+	binary.Write(&b, binary.BigEndian, tx.id)
+	binary.Write(&b, binary.BigEndian, tx.ts)
+
+	for _, e := range tx.entries {
+		d := e.digest()
+		b.Write(d[:])
+	}
+
+	return sha256.Sum256(b.Bytes())
+}
+
+func (f *ImmuClientFake) before(ctx context.Context, method string) error {
+	if f.Latency > 0 {
+		select {
+		case <-time.After(f.Latency):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if f.Hook != nil {
+		return f.Hook(ctx, method)
+	}
+
+	return nil
+}
+
+func (f *ImmuClientFake) prevHash() [sha256.Size]byte {
+	if len(f.txs) == 0 {
+		return [sha256.Size]byte{}
+	}
+	return f.txs[len(f.txs)-1].hash
+}
+
+func (f *ImmuClientFake) txMetadata(tx *fakeTx) *schema.TxMetadata {
+	var prev [sha256.Size]byte
+	if tx.id > 1 {
+		prev = f.txs[tx.id-2].hash
+	}
+
+	return &schema.TxMetadata{
+		Id:       tx.id,
+		PrevAlh:  prev[:],
+		Ts:       tx.ts,
+		Nentries: int32(len(tx.entries)),
+	}
+}
+
+// commit must be called while holding the mutex
+func (f *ImmuClientFake) commit(entries []*fakeEntry) *schema.TxMetadata {
+	tx := &fakeTx{
+		id:      uint64(len(f.txs)) + 1,
+		ts:      time.Now().Unix(),
+		entries: entries,
+	}
+
+	for _, e := range entries {
+		e.tx = tx.id
+
+		if e.set != nil {
+			f.zsets[string(e.set)] = append(f.zsets[string(e.set)], e)
+		} else {
+			f.keys[string(e.key)] = append(f.keys[string(e.key)], e)
+		}
+	}
+
+	tx.hash = tx.computeHash(f.prevHash())
+
+	f.txs = append(f.txs, tx)
+
+	return f.txMetadata(tx)
+}
+
+// verify must be called while holding the mutex, it checks the whole chain of transactions is consistent
+// with the hashes computed when they were committed and with the last verified state
+func (f *ImmuClientFake) verify() error {
+	var prev [sha256.Size]byte
+
+	for _, tx := range f.txs {
+		if tx.computeHash(prev) != tx.hash {
+			return store.ErrCorruptedData
+		}
+		prev = tx.hash
+	}
+
+	if f.state != nil && f.state.TxId > 0 {
+		if f.state.TxId > uint64(len(f.txs)) || !bytes.Equal(f.state.TxHash, f.txs[f.state.TxId-1].hash[:]) {
+			return store.ErrCorruptedData
+		}
+	}
+
+	f.state = f.currentState()
+
+	return nil
+}
+
+func (f *ImmuClientFake) currentState() *schema.ImmutableState {
+	h := f.prevHash()
+
+	return &schema.ImmutableState{
+		Db:     f.GetOptions().CurrentDatabase,
+		TxId:   uint64(len(f.txs)),
+		TxHash: h[:],
+	}
+}
+
+// Tamper overwrites the current value of a key without updating the hash of its transaction,
+// so subsequent verified operations fail
+func (f *ImmuClientFake) Tamper(key []byte, value []byte) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	versions, ok := f.keys[string(key)]
+	if !ok {
+		return store.ErrKeyNotFound
+	}
+
+	versions[len(versions)-1].value = value
+
+	return nil
+}
+
+// IsConnected ...
+func (f *ImmuClientFake) IsConnected() bool {
+	return true
+}
+
+// Connect ...
+func (f *ImmuClientFake) Connect(ctx context.Context) (*grpc.ClientConn, error) {
+	return nil, f.before(ctx, "Connect")
+}
+
+// Disconnect ...
+func (f *ImmuClientFake) Disconnect() error {
+	return nil
+}
+
+// HealthCheck ...
+func (f *ImmuClientFake) HealthCheck(ctx context.Context) error {
+	return f.before(ctx, "HealthCheck")
+}
+
+// WaitForHealthCheck ...
+func (f *ImmuClientFake) WaitForHealthCheck(ctx context.Context) error {
+	return f.before(ctx, "WaitForHealthCheck")
+}
+
+// Login accepts any credentials
+func (f *ImmuClientFake) Login(ctx context.Context, user []byte, pass []byte) (*schema.LoginResponse, error) {
+	if err := f.before(ctx, "Login"); err != nil {
+		return nil, err
+	}
+	return &schema.LoginResponse{}, nil
+}
+
+// Logout ...
+func (f *ImmuClientFake) Logout(ctx context.Context) error {
+	return f.before(ctx, "Logout")
+}
+
+// UseDatabase accepts any database, all of them sharing the same data
+func (f *ImmuClientFake) UseDatabase(ctx context.Context, d *schema.Database) (*schema.UseDatabaseReply, error) {
+	if err := f.before(ctx, "UseDatabase"); err != nil {
+		return nil, err
+	}
+
+	f.GetOptions().CurrentDatabase = d.DatabaseName
+
+	return &schema.UseDatabaseReply{}, nil
+}
+
+// CurrentState ...
+func (f *ImmuClientFake) CurrentState(ctx context.Context) (*schema.ImmutableState, error) {
+	if err := f.before(ctx, "CurrentState"); err != nil {
+		return nil, err
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return f.currentState(), nil
+}
+
+// Set ...
+func (f *ImmuClientFake) Set(ctx context.Context, key []byte, value []byte) (*schema.TxMetadata, error) {
+	if err := f.before(ctx, "Set"); err != nil {
+		return nil, err
+	}
+	return f.setAll([]*schema.KeyValue{{Key: key, Value: value}}, false)
+}
+
+// VerifiedSet ...
+func (f *ImmuClientFake) VerifiedSet(ctx context.Context, key []byte, value []byte) (*schema.TxMetadata, error) {
+	if err := f.before(ctx, "VerifiedSet"); err != nil {
+		return nil, err
+	}
+	return f.setAll([]*schema.KeyValue{{Key: key, Value: value}}, true)
+}
+
+// SetAll ...
+func (f *ImmuClientFake) SetAll(ctx context.Context, req *schema.SetRequest) (*schema.TxMetadata, error) {
+	if err := f.before(ctx, "SetAll"); err != nil {
+		return nil, err
+	}
+	if req == nil {
+		return nil, store.ErrIllegalArguments
+	}
+	return f.setAll(req.KVs, false)
+}
+
+func (f *ImmuClientFake) setAll(kvs []*schema.KeyValue, verified bool) (*schema.TxMetadata, error) {
+	if len(kvs) == 0 {
+		return nil, store.ErrIllegalArguments
+	}
+
+	entries := make([]*fakeEntry, len(kvs))
+
+	for i, kv := range kvs {
+		if len(kv.Key) == 0 {
+			return nil, store.ErrIllegalArguments
+		}
+		entries[i] = &fakeEntry{key: kv.Key, value: kv.Value}
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if verified {
+		if err := f.verify(); err != nil {
+			return nil, err
+		}
+	}
+
+	txmd := f.commit(entries)
+
+	if verified {
+		f.state = f.currentState()
+	}
+
+	return txmd, nil
+}
+
+// Get ...
+func (f *ImmuClientFake) Get(ctx context.Context, key []byte) (*schema.Entry, error) {
+	if err := f.before(ctx, "Get"); err != nil {
+		return nil, err
+	}
+	return f.get(key, 0, false)
+}
+
+// GetSince ...
+func (f *ImmuClientFake) GetSince(ctx context.Context, key []byte, tx uint64) (*schema.Entry, error) {
+	if err := f.before(ctx, "GetSince"); err != nil {
+		return nil, err
+	}
+	return f.get(key, 0, false)
+}
+
+// GetAt ...
+func (f *ImmuClientFake) GetAt(ctx context.Context, key []byte, tx uint64) (*schema.Entry, error) {
+	if err := f.before(ctx, "GetAt"); err != nil {
+		return nil, err
+	}
+	return f.get(key, tx, false)
+}
+
+// VerifiedGet ...
+func (f *ImmuClientFake) VerifiedGet(ctx context.Context, key []byte) (*schema.Entry, error) {
+	if err := f.before(ctx, "VerifiedGet"); err != nil {
+		return nil, err
+	}
+	return f.get(key, 0, true)
+}
+
+// VerifiedGetSince ...
+func (f *ImmuClientFake) VerifiedGetSince(ctx context.Context, key []byte, tx uint64) (*schema.Entry, error) {
+	if err := f.before(ctx, "VerifiedGetSince"); err != nil {
+		return nil, err
+	}
+	return f.get(key, 0, true)
+}
+
+// VerifiedGetAt ...
+func (f *ImmuClientFake) VerifiedGetAt(ctx context.Context, key []byte, tx uint64) (*schema.Entry, error) {
+	if err := f.before(ctx, "VerifiedGetAt"); err != nil {
+		return nil, err
+	}
+	return f.get(key, tx, true)
+}
+
+// GetAll ...
+func (f *ImmuClientFake) GetAll(ctx context.Context, keys [][]byte) (*schema.Entries, error) {
+	if err := f.before(ctx, "GetAll"); err != nil {
+		return nil, err
+	}
+
+	list := &schema.Entries{}
+
+	for _, key := range keys {
+		e, err := f.get(key, 0, false)
+		if err == store.ErrKeyNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		list.Entries = append(list.Entries, e)
+	}
+
+	return list, nil
+}
+
+func (f *ImmuClientFake) get(key []byte, atTx uint64, verified bool) (*schema.Entry, error) {
+	if len(key) == 0 {
+		return nil, store.ErrIllegalArguments
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if verified {
+		if err := f.verify(); err != nil {
+			return nil, err
+		}
+	}
+
+	return f.resolve(key, atTx)
+}
+
+// lookup must be called while holding the mutex, it returns the version of the key at the given transaction,
+// or its current one when atTx is zero
+func (f *ImmuClientFake) lookup(key []byte, atTx uint64) (*fakeEntry, error) {
+	versions := f.keys[string(key)]
+
+	for i := len(versions) - 1; i >= 0; i-- {
+		if atTx == 0 || versions[i].tx == atTx {
+			return versions[i], nil
+		}
+	}
+
+	return nil, store.ErrKeyNotFound
+}
+
+// resolve must be called while holding the mutex
+func (f *ImmuClientFake) resolve(key []byte, atTx uint64) (*schema.Entry, error) {
+	e, err := f.lookup(key, atTx)
+	if err != nil {
+		return nil, err
+	}
+
+	if e.refKey == nil {
+		return &schema.Entry{Tx: e.tx, Key: e.key, Value: e.value}, nil
+	}
+
+	ref, err := f.lookup(e.refKey, e.refAtTx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schema.Entry{
+		Tx:           ref.tx,
+		Key:          ref.key,
+		Value:        ref.value,
+		ReferencedBy: &schema.Reference{Tx: e.tx, Key: e.key, AtTx: e.refAtTx},
+	}, nil
+}
+
+// History ...
+func (f *ImmuClientFake) History(ctx context.Context, req *schema.HistoryRequest) (*schema.Entries, error) {
+	if err := f.before(ctx, "History"); err != nil {
+		return nil, err
+	}
+	if req == nil || len(req.Key) == 0 || req.Limit < 0 {
+		return nil, store.ErrIllegalArguments
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	versions := f.keys[string(req.Key)]
+	if len(versions) == 0 {
+		return nil, store.ErrKeyNotFound
+	}
+	if req.Offset >= uint64(len(versions)) {
+		return nil, store.ErrOffsetOutOfRange
+	}
+
+	list := &schema.Entries{}
+
+	for i := req.Offset; i < uint64(len(versions)); i++ {
+		if req.Limit > 0 && len(list.Entries) == int(req.Limit) {
+			break
+		}
+
+		e := versions[i]
+		if req.Desc {
+			e = versions[uint64(len(versions))-1-i]
+		}
+
+		list.Entries = append(list.Entries, &schema.Entry{Tx: e.tx, Key: e.key, Value: e.value})
+	}
+
+	return list, nil
+}
+
+// sortedKeys must be called while holding the mutex
+func (f *ImmuClientFake) sortedKeys(prefix []byte, desc bool) [][]byte {
+	var keys [][]byte
+
+	for k := range f.keys {
+		if bytes.HasPrefix([]byte(k), prefix) {
+			keys = append(keys, []byte(k))
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if desc {
+			return bytes.Compare(keys[i], keys[j]) > 0
+		}
+		return bytes.Compare(keys[i], keys[j]) < 0
+	})
+
+	return keys
+}
+
+// Scan ...
+func (f *ImmuClientFake) Scan(ctx context.Context, req *schema.ScanRequest) (*schema.Entries, error) {
+	if err := f.before(ctx, "Scan"); err != nil {
+		return nil, err
+	}
+	if req == nil {
+		return nil, store.ErrIllegalArguments
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	list := &schema.Entries{}
+
+	for _, k := range f.sortedKeys(req.Prefix, req.Desc) {
+		if len(req.SeekKey) > 0 {
+			c := bytes.Compare(k, req.SeekKey)
+			if (!req.Desc && c < 0) || (req.Desc && c > 0) {
+				continue
+			}
+		}
+
+		if req.Limit > 0 && uint64(len(list.Entries)) == req.Limit {
+			break
+		}
+
+		e, err := f.resolve(k, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		list.Entries = append(list.Entries, e)
+	}
+
+	return list, nil
+}
+
+// Count ...
+func (f *ImmuClientFake) Count(ctx context.Context, prefix []byte) (*schema.EntryCount, error) {
+	if err := f.before(ctx, "Count"); err != nil {
+		return nil, err
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return &schema.EntryCount{Count: uint64(len(f.sortedKeys(prefix, false)))}, nil
+}
+
+// CountAll ...
+func (f *ImmuClientFake) CountAll(ctx context.Context) (*schema.EntryCount, error) {
+	if err := f.before(ctx, "CountAll"); err != nil {
+		return nil, err
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return &schema.EntryCount{Count: uint64(len(f.keys))}, nil
+}
+
+// SetReference ...
+func (f *ImmuClientFake) SetReference(ctx context.Context, key []byte, referencedKey []byte) (*schema.TxMetadata, error) {
+	if err := f.before(ctx, "SetReference"); err != nil {
+		return nil, err
+	}
+	return f.execAll([]*schema.Op{{Operation: &schema.Op_Ref{Ref: &schema.ReferenceRequest{Key: key, ReferencedKey: referencedKey}}}}, false)
+}
+
+// VerifiedSetReference ...
+func (f *ImmuClientFake) VerifiedSetReference(ctx context.Context, key []byte, referencedKey []byte) (*schema.TxMetadata, error) {
+	if err := f.before(ctx, "VerifiedSetReference"); err != nil {
+		return nil, err
+	}
+	return f.execAll([]*schema.Op{{Operation: &schema.Op_Ref{Ref: &schema.ReferenceRequest{Key: key, ReferencedKey: referencedKey}}}}, true)
+}
+
+// SetReferenceAt ...
+func (f *ImmuClientFake) SetReferenceAt(ctx context.Context, key []byte, referencedKey []byte, atTx uint64) (*schema.TxMetadata, error) {
+	if err := f.before(ctx, "SetReferenceAt"); err != nil {
+		return nil, err
+	}
+	return f.execAll([]*schema.Op{{Operation: &schema.Op_Ref{Ref: &schema.ReferenceRequest{Key: key, ReferencedKey: referencedKey, AtTx: atTx, BoundRef: true}}}}, false)
+}
+
+// VerifiedSetReferenceAt ...
+func (f *ImmuClientFake) VerifiedSetReferenceAt(ctx context.Context, key []byte, referencedKey []byte, atTx uint64) (*schema.TxMetadata, error) {
+	if err := f.before(ctx, "VerifiedSetReferenceAt"); err != nil {
+		return nil, err
+	}
+	return f.execAll([]*schema.Op{{Operation: &schema.Op_Ref{Ref: &schema.ReferenceRequest{Key: key, ReferencedKey: referencedKey, AtTx: atTx, BoundRef: true}}}}, true)
+}
+
+// ZAdd ...
+func (f *ImmuClientFake) ZAdd(ctx context.Context, set []byte, score float64, key []byte) (*schema.TxMetadata, error) {
+	if err := f.before(ctx, "ZAdd"); err != nil {
+		return nil, err
+	}
+	return f.execAll([]*schema.Op{{Operation: &schema.Op_ZAdd{ZAdd: &schema.ZAddRequest{Set: set, Score: score, Key: key}}}}, false)
+}
+
+// VerifiedZAdd ...
+func (f *ImmuClientFake) VerifiedZAdd(ctx context.Context, set []byte, score float64, key []byte) (*schema.TxMetadata, error) {
+	if err := f.before(ctx, "VerifiedZAdd"); err != nil {
+		return nil, err
+	}
+	return f.execAll([]*schema.Op{{Operation: &schema.Op_ZAdd{ZAdd: &schema.ZAddRequest{Set: set, Score: score, Key: key}}}}, true)
+}
+
+// ZAddAt ...
+func (f *ImmuClientFake) ZAddAt(ctx context.Context, set []byte, score float64, key []byte, atTx uint64) (*schema.TxMetadata, error) {
+	if err := f.before(ctx, "ZAddAt"); err != nil {
+		return nil, err
+	}
+	return f.execAll([]*schema.Op{{Operation: &schema.Op_ZAdd{ZAdd: &schema.ZAddRequest{Set: set, Score: score, Key: key, AtTx: atTx, BoundRef: true}}}}, false)
+}
+
+// VerifiedZAddAt ...
+func (f *ImmuClientFake) VerifiedZAddAt(ctx context.Context, set []byte, score float64, key []byte, atTx uint64) (*schema.TxMetadata, error) {
+	if err := f.before(ctx, "VerifiedZAddAt"); err != nil {
+		return nil, err
+	}
+	return f.execAll([]*schema.Op{{Operation: &schema.Op_ZAdd{ZAdd: &schema.ZAddRequest{Set: set, Score: score, Key: key, AtTx: atTx, BoundRef: true}}}}, true)
+}
+
+// ExecAll ...
+func (f *ImmuClientFake) ExecAll(ctx context.Context, req *schema.ExecAllRequest) (*schema.TxMetadata, error) {
+	if err := f.before(ctx, "ExecAll"); err != nil {
+		return nil, err
+	}
+	if req == nil {
+		return nil, store.ErrIllegalArguments
+	}
+	return f.execAll(req.Operations, false)
+}
+
+func (f *ImmuClientFake) execAll(ops []*schema.Op, verified bool) (*schema.TxMetadata, error) {
+	if len(ops) == 0 {
+		return nil, store.ErrIllegalArguments
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if verified {
+		if err := f.verify(); err != nil {
+			return nil, err
+		}
+	}
+
+	txID := uint64(len(f.txs)) + 1
+
+	entries := make([]*fakeEntry, len(ops))
+	written := make(map[string]bool)
+
+	// referenced keys must exist, either before or within the same operations, and must not be references
+	checkReferenced := func(key []byte, atTx uint64) error {
+		if atTx == 0 && written[string(key)] {
+			return nil
+		}
+
+		e, err := f.lookup(key, atTx)
+		if err != nil {
+			return err
+		}
+		if e.refKey != nil {
+			return store.ErrIllegalArguments
+		}
+
+		return nil
+	}
+
+	for i, op := range ops {
+		switch x := op.Operation.(type) {
+		case *schema.Op_Kv:
+			if len(x.Kv.Key) == 0 {
+				return nil, store.ErrIllegalArguments
+			}
+
+			entries[i] = &fakeEntry{key: x.Kv.Key, value: x.Kv.Value}
+			written[string(x.Kv.Key)] = true
+
+		case *schema.Op_Ref:
+			if len(x.Ref.Key) == 0 || len(x.Ref.ReferencedKey) == 0 || (x.Ref.AtTx > 0 && !x.Ref.BoundRef) {
+				return nil, store.ErrIllegalArguments
+			}
+
+			if err := checkReferenced(x.Ref.ReferencedKey, x.Ref.AtTx); err != nil {
+				return nil, err
+			}
+
+			atTx := x.Ref.AtTx
+			if x.Ref.BoundRef && atTx == 0 {
+				atTx = txID
+			}
+
+			entries[i] = &fakeEntry{key: x.Ref.Key, refKey: x.Ref.ReferencedKey, refAtTx: atTx}
+
+		case *schema.Op_ZAdd:
+			if len(x.ZAdd.Set) == 0 || len(x.ZAdd.Key) == 0 || (x.ZAdd.AtTx > 0 && !x.ZAdd.BoundRef) {
+				return nil, store.ErrIllegalArguments
+			}
+
+			if err := checkReferenced(x.ZAdd.Key, x.ZAdd.AtTx); err != nil {
+				return nil, err
+			}
+
+			atTx := x.ZAdd.AtTx
+			if x.ZAdd.BoundRef && atTx == 0 {
+				atTx = txID
+			}
+
+			entries[i] = &fakeEntry{key: x.ZAdd.Key, set: x.ZAdd.Set, score: x.ZAdd.Score, atTx: atTx}
+
+		default:
+			return nil, store.ErrIllegalArguments
+		}
+	}
+
+	txmd := f.commit(entries)
+
+	if verified {
+		f.state = f.currentState()
+	}
+
+	return txmd, nil
+}
+
+// ZScan returns the entries of a sorted set ordered by score, the seek and continuation fields are not supported
+func (f *ImmuClientFake) ZScan(ctx context.Context, req *schema.ZScanRequest) (*schema.ZEntries, error) {
+	if err := f.before(ctx, "ZScan"); err != nil {
+		return nil, err
+	}
+	if req == nil || len(req.Set) == 0 {
+		return nil, store.ErrIllegalArguments
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	members := make([]*fakeEntry, 0, len(f.zsets[string(req.Set)]))
+
+	for _, e := range f.zsets[string(req.Set)] {
+		if req.MinScore != nil && e.score < req.MinScore.Score {
+			continue
+		}
+		if req.MaxScore != nil && e.score > req.MaxScore.Score {
+			continue
+		}
+		if !fakeInLexRange(e.key, req.MinKey, req.MaxKey) {
+			continue
+		}
+		members = append(members, e)
+	}
+
+	sort.SliceStable(members, func(i, j int) bool {
+		a, b := members[i], members[j]
+		if req.Desc {
+			a, b = b, a
+		}
+
+		if a.score != b.score {
+			return a.score < b.score
+		}
+		// as indexed by the server, keys with the same score are ordered by length first
+		if len(a.key) != len(b.key) {
+			return len(a.key) < len(b.key)
+		}
+		if c := bytes.Compare(a.key, b.key); c != 0 {
+			return c < 0
+		}
+		return a.atTx < b.atTx
+	})
+
+	list := &schema.ZEntries{}
+
+	for _, e := range members {
+		if req.Limit > 0 && uint64(len(list.Entries)) == req.Limit {
+			break
+		}
+
+		entry, err := f.resolve(e.key, e.atTx)
+		if err != nil {
+			return nil, err
+		}
+
+		list.Entries = append(list.Entries, &schema.ZEntry{
+			Set:   e.set,
+			Key:   e.key,
+			Entry: entry,
+			Score: e.score,
+			AtTx:  e.atTx,
+		})
+	}
+
+	return list, nil
+}
+
+func fakeInLexRange(key []byte, minKey, maxKey *schema.LexBound) bool {
+	if minKey != nil {
+		c := bytes.Compare(key, minKey.Key)
+		if c < 0 || (c == 0 && minKey.Exclusive) {
+			return false
+		}
+	}
+	if maxKey != nil {
+		c := bytes.Compare(key, maxKey.Key)
+		if c > 0 || (c == 0 && maxKey.Exclusive) {
+			return false
+		}
+	}
+	return true
+}
+
+// TxByID ...
+func (f *ImmuClientFake) TxByID(ctx context.Context, tx uint64) (*schema.Tx, error) {
+	if err := f.before(ctx, "TxByID"); err != nil {
+		return nil, err
+	}
+	return f.txByID(tx, false)
+}
+
+// VerifiedTxByID ...
+func (f *ImmuClientFake) VerifiedTxByID(ctx context.Context, tx uint64) (*schema.Tx, error) {
+	if err := f.before(ctx, "VerifiedTxByID"); err != nil {
+		return nil, err
+	}
+	return f.txByID(tx, true)
+}
+
+func (f *ImmuClientFake) txByID(id uint64, verified bool) (*schema.Tx, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if id == 0 || id > uint64(len(f.txs)) {
+		return nil, store.ErrTxNotFound
+	}
+
+	if verified {
+		if err := f.verify(); err != nil {
+			return nil, err
+		}
+	}
+
+	tx := f.txs[id-1]
+
+	stx := &schema.Tx{Metadata: f.txMetadata(tx)}
+
+	for _, e := range tx.entries {
+		d := e.digest()
+		stx.Entries = append(stx.Entries, &schema.TxEntry{
+			Key:    e.key,
+			HValue: d[:],
+			VLen:   int32(len(e.value)),
+		})
+	}
+
+	return stx, nil
+}
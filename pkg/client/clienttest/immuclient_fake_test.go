@@ -0,0 +1,173 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package clienttest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/client"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImmuClientFake(t *testing.T) {
+	var cli client.ImmuClient = NewImmuClientFake()
+
+	ctx := context.Background()
+
+	_, err := cli.Login(ctx, []byte("immudb"), []byte("immudb"))
+	require.NoError(t, err)
+
+	txmd, err := cli.Set(ctx, []byte("k1"), []byte("v1"))
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), txmd.Id)
+
+	_, err = cli.VerifiedSet(ctx, []byte("k1"), []byte("v2"))
+	require.NoError(t, err)
+
+	_, err = cli.SetAll(ctx, &schema.SetRequest{KVs: []*schema.KeyValue{
+		{Key: []byte("k2"), Value: []byte("v1")},
+		{Key: []byte("x1"), Value: []byte("v1")},
+	}})
+	require.NoError(t, err)
+
+	entry, err := cli.VerifiedGet(ctx, []byte("k1"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("v2"), entry.Value)
+	require.Equal(t, uint64(2), entry.Tx)
+
+	entry, err = cli.GetAt(ctx, []byte("k1"), 1)
+	require.NoError(t, err)
+	require.Equal(t, []byte("v1"), entry.Value)
+
+	_, err = cli.Get(ctx, []byte("missing"))
+	require.Equal(t, store.ErrKeyNotFound, err)
+
+	history, err := cli.History(ctx, &schema.HistoryRequest{Key: []byte("k1"), Desc: true})
+	require.NoError(t, err)
+	require.Len(t, history.Entries, 2)
+	require.Equal(t, []byte("v2"), history.Entries[0].Value)
+
+	list, err := cli.Scan(ctx, &schema.ScanRequest{Prefix: []byte("k")})
+	require.NoError(t, err)
+	require.Len(t, list.Entries, 2)
+	require.Equal(t, []byte("k1"), list.Entries[0].Key)
+
+	count, err := cli.CountAll(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), count.Count)
+
+	_, err = cli.VerifiedSetReference(ctx, []byte("ref"), []byte("k2"))
+	require.NoError(t, err)
+
+	entry, err = cli.Get(ctx, []byte("ref"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("k2"), entry.Key)
+	require.Equal(t, []byte("ref"), entry.ReferencedBy.Key)
+
+	_, err = cli.SetReference(ctx, []byte("ref2"), []byte("missing"))
+	require.Equal(t, store.ErrKeyNotFound, err)
+
+	_, err = cli.ZAdd(ctx, []byte("set"), 2, []byte("k1"))
+	require.NoError(t, err)
+
+	_, err = cli.VerifiedZAddAt(ctx, []byte("set"), 1, []byte("k1"), 1)
+	require.NoError(t, err)
+
+	zlist, err := cli.ZScan(ctx, &schema.ZScanRequest{Set: []byte("set")})
+	require.NoError(t, err)
+	require.Len(t, zlist.Entries, 2)
+	require.Equal(t, []byte("v1"), zlist.Entries[0].Entry.Value)
+	require.Equal(t, []byte("v2"), zlist.Entries[1].Entry.Value)
+
+	zlist, err = cli.ZScan(ctx, &schema.ZScanRequest{Set: []byte("set"), Desc: true, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, zlist.Entries, 1)
+	require.Equal(t, float64(2), zlist.Entries[0].Score)
+
+	zlist, err = cli.ZScan(ctx, &schema.ZScanRequest{Set: []byte("set"), MinKey: &schema.LexBound{Key: []byte("k1"), Exclusive: true}})
+	require.NoError(t, err)
+	require.Empty(t, zlist.Entries)
+
+	tx, err := cli.VerifiedTxByID(ctx, 3)
+	require.NoError(t, err)
+	require.Len(t, tx.Entries, 2)
+
+	state, err := cli.CurrentState(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(6), state.TxId)
+}
+
+func TestImmuClientFakeTampering(t *testing.T) {
+	cli := NewImmuClientFake()
+
+	ctx := context.Background()
+
+	_, err := cli.VerifiedSet(ctx, []byte("k1"), []byte("v1"))
+	require.NoError(t, err)
+
+	err = cli.Tamper([]byte("missing"), []byte("v2"))
+	require.Equal(t, store.ErrKeyNotFound, err)
+
+	err = cli.Tamper([]byte("k1"), []byte("v2"))
+	require.NoError(t, err)
+
+	// unverified reads are not affected
+	entry, err := cli.Get(ctx, []byte("k1"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("v2"), entry.Value)
+
+	_, err = cli.VerifiedGet(ctx, []byte("k1"))
+	require.Equal(t, store.ErrCorruptedData, err)
+
+	_, err = cli.VerifiedSet(ctx, []byte("k2"), []byte("v1"))
+	require.Equal(t, store.ErrCorruptedData, err)
+}
+
+func TestImmuClientFakeHooks(t *testing.T) {
+	cli := NewImmuClientFake()
+
+	errInjected := errors.New("injected")
+
+	cli.Hook = func(ctx context.Context, method string) error {
+		if method == "Get" {
+			return errInjected
+		}
+		return nil
+	}
+
+	_, err := cli.Set(context.Background(), []byte("k1"), []byte("v1"))
+	require.NoError(t, err)
+
+	_, err = cli.Get(context.Background(), []byte("k1"))
+	require.Equal(t, errInjected, err)
+
+	cli.Latency = time.Second
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = cli.Set(ctx, []byte("k1"), []byte("v2"))
+	require.Equal(t, context.DeadlineExceeded, err)
+
+	// operations which are not faked are delegated to a disconnected client
+	_, err = cli.SQLQuery(context.Background(), "SELECT id FROM t", nil, false)
+	require.Equal(t, client.ErrNotConnected, err)
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+)
+
+var ErrNotProtoMessage = errors.New("value is not a proto message")
+var ErrCodecNotFound = errors.New("codec not found")
+var ErrCorruptedEncoding = errors.New("corrupted encoding")
+
+// Codec serializes application values into immudb values and back
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// JSONCodec encodes values as JSON
+var JSONCodec Codec = jsonCodec{}
+
+// ProtoCodec encodes proto messages using the protobuf wire format
+var ProtoCodec Codec = protoCodec{}
+
+// MsgpackCodec encodes values as MessagePack. Values are mapped the same way they are by JSONCodec
+var MsgpackCodec Codec = msgpackCodec{}
+
+// CBORCodec encodes values as deterministic CBOR. Values are mapped the same way they are by JSONCodec
+var CBORCodec Codec = cborCodec{}
+
+var codecsMutex sync.RWMutex
+
+var codecs = map[string]Codec{
+	"json":    JSONCodec,
+	"proto":   ProtoCodec,
+	"msgpack": MsgpackCodec,
+	"cbor":    CBORCodec,
+}
+
+// RegisterCodec makes codec available under name, replacing any codec registered with the same name
+func RegisterCodec(name string, codec Codec) {
+	codecsMutex.Lock()
+	defer codecsMutex.Unlock()
+
+	codecs[name] = codec
+}
+
+// GetCodec returns the codec registered under name
+func GetCodec(name string) (Codec, error) {
+	codecsMutex.RLock()
+	defer codecsMutex.RUnlock()
+
+	codec, ok := codecs[name]
+	if !ok {
+		return nil, ErrCodecNotFound
+	}
+	return codec, nil
+}
+
+// CodecNames returns the names of the registered codecs, sorted
+func CodecNames() []string {
+	codecsMutex.RLock()
+	defer codecsMutex.RUnlock()
+
+	names := make([]string, 0, len(codecs))
+	for name := range codecs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// DisplayValue renders a value encoded with codec as JSON. It fails for codecs that need to know the type
+// of the value to decode it, as ProtoCodec
+func DisplayValue(codec Codec, data []byte) ([]byte, error) {
+	var v interface{}
+
+	err := codec.Unmarshal(data, &v)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(v)
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+type protoCodec struct{}
+
+func (protoCodec) Marshal(v interface{}) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, ErrNotProtoMessage
+	}
+	return proto.Marshal(m)
+}
+
+func (protoCodec) Unmarshal(data []byte, v interface{}) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return ErrNotProtoMessage
+	}
+	return proto.Unmarshal(data, m)
+}
+
+// toGeneric maps v into nil, bool, int64, uint64, float64, string, []interface{} and map[string]interface{} values,
+// the same way it would be encoded as JSON
+func toGeneric(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	var g interface{}
+
+	err = dec.Decode(&g)
+	if err != nil {
+		return nil, err
+	}
+
+	return fromJSONNumbers(g), nil
+}
+
+func fromJSONNumbers(v interface{}) interface{} {
+	switch x := v.(type) {
+	case json.Number:
+		if i, err := x.Int64(); err == nil {
+			return i
+		}
+		if u, err := strconv.ParseUint(string(x), 10, 64); err == nil {
+			return u
+		}
+		f, _ := x.Float64()
+		return f
+	case []interface{}:
+		for i, e := range x {
+			x[i] = fromJSONNumbers(e)
+		}
+	case map[string]interface{}:
+		for k, e := range x {
+			x[k] = fromJSONNumbers(e)
+		}
+	}
+	return v
+}
+
+// fromGeneric stores a decoded generic value into v
+func fromGeneric(g interface{}, v interface{}) error {
+	b, err := json.Marshal(g)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,277 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+)
+
+const (
+	cborUint byte = iota
+	cborNegInt
+	cborBytes
+	cborText
+	cborArray
+	cborMap
+	cborTag
+	cborSimple
+)
+
+type cborCodec struct{}
+
+func (cborCodec) Marshal(v interface{}) ([]byte, error) {
+	g, err := toGeneric(v)
+	if err != nil {
+		return nil, err
+	}
+	return cborEncode(nil, g), nil
+}
+
+func (cborCodec) Unmarshal(data []byte, v interface{}) error {
+	g, n, err := cborDecode(data)
+	if err != nil {
+		return err
+	}
+	if n != len(data) {
+		return ErrCorruptedEncoding
+	}
+	return fromGeneric(g, v)
+}
+
+func cborHead(b []byte, major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return append(b, major<<5|byte(n))
+	case n <= math.MaxUint8:
+		return append(b, major<<5|24, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, major<<5|25), uint16(n))
+	case n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, major<<5|26), uint32(n))
+	}
+	return binary.BigEndian.AppendUint64(append(b, major<<5|27), n)
+}
+
+func cborEncode(b []byte, v interface{}) []byte {
+	switch x := v.(type) {
+	case nil:
+		return append(b, 0xf6)
+	case bool:
+		if x {
+			return append(b, 0xf5)
+		}
+		return append(b, 0xf4)
+	case int64:
+		if x >= 0 {
+			return cborHead(b, cborUint, uint64(x))
+		}
+		return cborHead(b, cborNegInt, uint64(-1-x))
+	case uint64:
+		return cborHead(b, cborUint, x)
+	case float64:
+		return binary.BigEndian.AppendUint64(append(b, 0xfb), math.Float64bits(x))
+	case string:
+		return append(cborHead(b, cborText, uint64(len(x))), x...)
+	case []interface{}:
+		b = cborHead(b, cborArray, uint64(len(x)))
+		for _, e := range x {
+			b = cborEncode(b, e)
+		}
+		return b
+	case map[string]interface{}:
+		// keys are sorted by their encoding, as required by the deterministic encoding of RFC 8949
+		keys := make([][]byte, 0, len(x))
+		for k := range x {
+			keys = append(keys, cborEncode(nil, k))
+		}
+		sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i], keys[j]) < 0 })
+
+		b = cborHead(b, cborMap, uint64(len(x)))
+		for _, k := range keys {
+			b = append(b, k...)
+			_, off, _ := cborDecodeHead(k)
+			b = cborEncode(b, x[string(k[off:])])
+		}
+		return b
+	}
+	panic(fmt.Sprintf("unexpected type %T", v))
+}
+
+// cborDecodeHead returns the argument of the data item starting at data[0] and the size of its head
+func cborDecodeHead(data []byte) (uint64, int, error) {
+	if len(data) == 0 {
+		return 0, 0, ErrCorruptedEncoding
+	}
+
+	info := data[0] & 0x1f
+
+	switch {
+	case info < 24:
+		return uint64(info), 1, nil
+	case info <= 27:
+		size := 1 << (info - 24)
+		if len(data) < 1+size {
+			return 0, 0, ErrCorruptedEncoding
+		}
+		return readUint(data[1:], size), 1 + size, nil
+	}
+
+	// indefinite lengths (31) are not supported, 28 to 30 are reserved
+	return 0, 0, fmt.Errorf("%w: unsupported cbor additional information %d", ErrCorruptedEncoding, info)
+}
+
+// cborDecode decodes the first data item in data and returns it along with the number of bytes it takes
+func cborDecode(data []byte) (interface{}, int, error) {
+	if len(data) == 0 {
+		return nil, 0, ErrCorruptedEncoding
+	}
+
+	major := data[0] >> 5
+
+	if major == cborSimple {
+		return cborDecodeSimple(data)
+	}
+
+	n, off, err := cborDecodeHead(data)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	switch major {
+	case cborUint:
+		if n <= math.MaxInt64 {
+			return int64(n), off, nil
+		}
+		return n, off, nil
+	case cborNegInt:
+		if n > math.MaxInt64 {
+			return nil, 0, fmt.Errorf("%w: cbor negative integer out of range", ErrCorruptedEncoding)
+		}
+		return -1 - int64(n), off, nil
+	case cborBytes, cborText:
+		if uint64(len(data)-off) < n {
+			return nil, 0, ErrCorruptedEncoding
+		}
+		end := off + int(n)
+		if major == cborText {
+			return string(data[off:end]), end, nil
+		}
+		bs := make([]byte, n)
+		copy(bs, data[off:end])
+		return bs, end, nil
+	case cborArray:
+		// every element takes at least one byte
+		if uint64(len(data)-off) < n {
+			return nil, 0, ErrCorruptedEncoding
+		}
+		arr := make([]interface{}, n)
+		for i := range arr {
+			e, l, err := cborDecode(data[off:])
+			if err != nil {
+				return nil, 0, err
+			}
+			arr[i] = e
+			off += l
+		}
+		return arr, off, nil
+	case cborMap:
+		if uint64(len(data)-off) < 2*n {
+			return nil, 0, ErrCorruptedEncoding
+		}
+		m := make(map[string]interface{}, n)
+		for i := uint64(0); i < n; i++ {
+			k, l, err := cborDecode(data[off:])
+			if err != nil {
+				return nil, 0, err
+			}
+			off += l
+
+			e, l, err := cborDecode(data[off:])
+			if err != nil {
+				return nil, 0, err
+			}
+			off += l
+
+			m[mapKey(k)] = e
+		}
+		return m, off, nil
+	}
+
+	// tags are ignored, the tagged data item is returned as is
+	e, l, err := cborDecode(data[off:])
+	if err != nil {
+		return nil, 0, err
+	}
+	return e, off + l, nil
+}
+
+func cborDecodeSimple(data []byte) (interface{}, int, error) {
+	switch data[0] {
+	case 0xf4:
+		return false, 1, nil
+	case 0xf5:
+		return true, 1, nil
+	case 0xf6, 0xf7:
+		return nil, 1, nil
+	case 0xf9:
+		if len(data) < 3 {
+			return nil, 0, ErrCorruptedEncoding
+		}
+		return halfToFloat64(binary.BigEndian.Uint16(data[1:])), 3, nil
+	case 0xfa:
+		if len(data) < 5 {
+			return nil, 0, ErrCorruptedEncoding
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(data[1:]))), 5, nil
+	case 0xfb:
+		if len(data) < 9 {
+			return nil, 0, ErrCorruptedEncoding
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(data[1:])), 9, nil
+	}
+
+	return nil, 0, fmt.Errorf("%w: unsupported cbor simple value 0x%x", ErrCorruptedEncoding, data[0])
+}
+
+func halfToFloat64(h uint16) float64 {
+	exp := int(h>>10) & 0x1f
+	mant := float64(h & 0x3ff)
+
+	var f float64
+
+	switch exp {
+	case 0:
+		f = math.Ldexp(mant, -24)
+	case 0x1f:
+		if mant == 0 {
+			f = math.Inf(1)
+		} else {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(mant+1024, exp-25)
+	}
+
+	if h&0x8000 != 0 {
+		return -f
+	}
+	return f
+}
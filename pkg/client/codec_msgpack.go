@@ -0,0 +1,303 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) Marshal(v interface{}) ([]byte, error) {
+	g, err := toGeneric(v)
+	if err != nil {
+		return nil, err
+	}
+	return msgpackEncode(nil, g), nil
+}
+
+func (msgpackCodec) Unmarshal(data []byte, v interface{}) error {
+	g, n, err := msgpackDecode(data)
+	if err != nil {
+		return err
+	}
+	if n != len(data) {
+		return ErrCorruptedEncoding
+	}
+	return fromGeneric(g, v)
+}
+
+func msgpackEncode(b []byte, v interface{}) []byte {
+	switch x := v.(type) {
+	case nil:
+		return append(b, 0xc0)
+	case bool:
+		if x {
+			return append(b, 0xc3)
+		}
+		return append(b, 0xc2)
+	case int64:
+		if x >= 0 {
+			return msgpackEncodeUint(b, uint64(x))
+		}
+		switch {
+		case x >= -32:
+			return append(b, byte(int8(x)))
+		case x >= math.MinInt8:
+			return append(b, 0xd0, byte(int8(x)))
+		case x >= math.MinInt16:
+			return binary.BigEndian.AppendUint16(append(b, 0xd1), uint16(x))
+		case x >= math.MinInt32:
+			return binary.BigEndian.AppendUint32(append(b, 0xd2), uint32(x))
+		}
+		return binary.BigEndian.AppendUint64(append(b, 0xd3), uint64(x))
+	case uint64:
+		return msgpackEncodeUint(b, x)
+	case float64:
+		return binary.BigEndian.AppendUint64(append(b, 0xcb), math.Float64bits(x))
+	case string:
+		l := len(x)
+		switch {
+		case l < 32:
+			b = append(b, 0xa0|byte(l))
+		case l <= math.MaxUint8:
+			b = append(b, 0xd9, byte(l))
+		case l <= math.MaxUint16:
+			b = binary.BigEndian.AppendUint16(append(b, 0xda), uint16(l))
+		default:
+			b = binary.BigEndian.AppendUint32(append(b, 0xdb), uint32(l))
+		}
+		return append(b, x...)
+	case []interface{}:
+		l := len(x)
+		switch {
+		case l < 16:
+			b = append(b, 0x90|byte(l))
+		case l <= math.MaxUint16:
+			b = binary.BigEndian.AppendUint16(append(b, 0xdc), uint16(l))
+		default:
+			b = binary.BigEndian.AppendUint32(append(b, 0xdd), uint32(l))
+		}
+		for _, e := range x {
+			b = msgpackEncode(b, e)
+		}
+		return b
+	case map[string]interface{}:
+		l := len(x)
+		switch {
+		case l < 16:
+			b = append(b, 0x80|byte(l))
+		case l <= math.MaxUint16:
+			b = binary.BigEndian.AppendUint16(append(b, 0xde), uint16(l))
+		default:
+			b = binary.BigEndian.AppendUint32(append(b, 0xdf), uint32(l))
+		}
+		// keys are sorted so equal values are always encoded the same way
+		for _, k := range sortedKeys(x) {
+			b = msgpackEncode(b, k)
+			b = msgpackEncode(b, x[k])
+		}
+		return b
+	}
+	panic(fmt.Sprintf("unexpected type %T", v))
+}
+
+func msgpackEncodeUint(b []byte, x uint64) []byte {
+	switch {
+	case x < 128:
+		return append(b, byte(x))
+	case x <= math.MaxUint8:
+		return append(b, 0xcc, byte(x))
+	case x <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xcd), uint16(x))
+	case x <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, 0xce), uint32(x))
+	}
+	return binary.BigEndian.AppendUint64(append(b, 0xcf), x)
+}
+
+// msgpackDecode decodes the first value in data and returns it along with the number of bytes it takes
+func msgpackDecode(data []byte) (interface{}, int, error) {
+	if len(data) == 0 {
+		return nil, 0, ErrCorruptedEncoding
+	}
+
+	t := data[0]
+
+	switch {
+	case t <= 0x7f:
+		return int64(t), 1, nil
+	case t >= 0xe0:
+		return int64(int8(t)), 1, nil
+	case t&0xe0 == 0xa0:
+		return msgpackDecodeStr(data, 1, int(t&0x1f))
+	case t&0xf0 == 0x90:
+		return msgpackDecodeArray(data, 1, int(t&0x0f))
+	case t&0xf0 == 0x80:
+		return msgpackDecodeMap(data, 1, int(t&0x0f))
+	}
+
+	switch t {
+	case 0xc0:
+		return nil, 1, nil
+	case 0xc2:
+		return false, 1, nil
+	case 0xc3:
+		return true, 1, nil
+	case 0xc4, 0xc5, 0xc6:
+		l, off, err := msgpackLen(data, t-0xc4)
+		if err != nil {
+			return nil, 0, err
+		}
+		if len(data) < off+l {
+			return nil, 0, ErrCorruptedEncoding
+		}
+		bs := make([]byte, l)
+		copy(bs, data[off:])
+		return bs, off + l, nil
+	case 0xca:
+		if len(data) < 5 {
+			return nil, 0, ErrCorruptedEncoding
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(data[1:]))), 5, nil
+	case 0xcb:
+		if len(data) < 9 {
+			return nil, 0, ErrCorruptedEncoding
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(data[1:])), 9, nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		size := 1 << (t - 0xcc)
+		if len(data) < 1+size {
+			return nil, 0, ErrCorruptedEncoding
+		}
+		return readUint(data[1:], size), 1 + size, nil
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (t - 0xd0)
+		if len(data) < 1+size {
+			return nil, 0, ErrCorruptedEncoding
+		}
+		u := readUint(data[1:], size)
+		shift := 64 - 8*size
+		return int64(u<<shift) >> shift, 1 + size, nil
+	case 0xd9, 0xda, 0xdb:
+		l, off, err := msgpackLen(data, t-0xd9)
+		if err != nil {
+			return nil, 0, err
+		}
+		return msgpackDecodeStr(data, off, l)
+	case 0xdc, 0xdd:
+		l, off, err := msgpackLen(data, t-0xdc+1)
+		if err != nil {
+			return nil, 0, err
+		}
+		return msgpackDecodeArray(data, off, l)
+	case 0xde, 0xdf:
+		l, off, err := msgpackLen(data, t-0xde+1)
+		if err != nil {
+			return nil, 0, err
+		}
+		return msgpackDecodeMap(data, off, l)
+	}
+
+	return nil, 0, fmt.Errorf("%w: unsupported msgpack type 0x%x", ErrCorruptedEncoding, t)
+}
+
+// msgpackLen reads a length of 1, 2 or 4 bytes (sizeExp 0, 1 or 2) following the type byte
+func msgpackLen(data []byte, sizeExp byte) (int, int, error) {
+	size := 1 << sizeExp
+	if len(data) < 1+size {
+		return 0, 0, ErrCorruptedEncoding
+	}
+	return int(readUint(data[1:], size)), 1 + size, nil
+}
+
+func msgpackDecodeStr(data []byte, off int, l int) (interface{}, int, error) {
+	if len(data) < off+l {
+		return nil, 0, ErrCorruptedEncoding
+	}
+	return string(data[off : off+l]), off + l, nil
+}
+
+func msgpackDecodeArray(data []byte, off int, l int) (interface{}, int, error) {
+	// every element takes at least one byte
+	if len(data)-off < l {
+		return nil, 0, ErrCorruptedEncoding
+	}
+
+	arr := make([]interface{}, l)
+
+	for i := range arr {
+		e, n, err := msgpackDecode(data[off:])
+		if err != nil {
+			return nil, 0, err
+		}
+		arr[i] = e
+		off += n
+	}
+
+	return arr, off, nil
+}
+
+func msgpackDecodeMap(data []byte, off int, l int) (interface{}, int, error) {
+	if len(data)-off < 2*l {
+		return nil, 0, ErrCorruptedEncoding
+	}
+
+	m := make(map[string]interface{}, l)
+
+	for i := 0; i < l; i++ {
+		k, n, err := msgpackDecode(data[off:])
+		if err != nil {
+			return nil, 0, err
+		}
+		off += n
+
+		e, n, err := msgpackDecode(data[off:])
+		if err != nil {
+			return nil, 0, err
+		}
+		off += n
+
+		m[mapKey(k)] = e
+	}
+
+	return m, off, nil
+}
+
+func readUint(b []byte, size int) uint64 {
+	switch size {
+	case 1:
+		return uint64(b[0])
+	case 2:
+		return uint64(binary.BigEndian.Uint16(b))
+	case 4:
+		return uint64(binary.BigEndian.Uint32(b))
+	}
+	return binary.BigEndian.Uint64(b)
+}
+
+func mapKey(k interface{}) string {
+	switch x := k.(type) {
+	case string:
+		return x
+	case []byte:
+		return string(x)
+	}
+	return fmt.Sprint(k)
+}
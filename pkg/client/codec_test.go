@@ -0,0 +1,150 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/stretchr/testify/require"
+)
+
+type codecTestValue struct {
+	Name   string            `json:"name"`
+	Count  int64             `json:"count"`
+	Big    uint64            `json:"big"`
+	Ratio  float64           `json:"ratio"`
+	Tags   []string          `json:"tags"`
+	Attrs  map[string]string `json:"attrs"`
+	Active bool              `json:"active"`
+	Raw    []byte            `json:"raw"`
+	Next   *codecTestValue   `json:"next"`
+}
+
+func TestCodecs(t *testing.T) {
+	v := &codecTestValue{
+		Name:   "value",
+		Count:  -70000,
+		Big:    math.MaxUint64,
+		Ratio:  0.5,
+		Tags:   []string{"a", "b"},
+		Attrs:  map[string]string{"z": "1", "a": "2"},
+		Active: true,
+		Raw:    []byte{1, 2, 3},
+		Next:   &codecTestValue{Name: "next", Count: 3},
+	}
+
+	for _, name := range []string{"json", "msgpack", "cbor"} {
+		codec, err := GetCodec(name)
+		require.NoError(t, err)
+
+		b1, err := codec.Marshal(v)
+		require.NoError(t, err)
+
+		b2, err := codec.Marshal(v)
+		require.NoError(t, err)
+		require.Equal(t, b1, b2)
+
+		var decoded codecTestValue
+		err = codec.Unmarshal(b1, &decoded)
+		require.NoError(t, err)
+		require.Equal(t, v, &decoded, name)
+
+		displayed, err := DisplayValue(codec, b1)
+		require.NoError(t, err)
+		require.Contains(t, string(displayed), `"name":"value"`)
+
+		err = codec.Unmarshal(b1[:len(b1)-1], &decoded)
+		require.Error(t, err)
+	}
+
+	codec, err := GetCodec("proto")
+	require.NoError(t, err)
+
+	b, err := codec.Marshal(&schema.KeyValue{Key: []byte("key")})
+	require.NoError(t, err)
+
+	_, err = DisplayValue(codec, b)
+	require.Equal(t, ErrNotProtoMessage, err)
+
+	_, err = GetCodec("unknown")
+	require.Equal(t, ErrCodecNotFound, err)
+
+	RegisterCodec("custom", JSONCodec)
+	require.Equal(t, []string{"cbor", "custom", "json", "msgpack", "proto"}, CodecNames())
+}
+
+func TestCodecsKnownEncodings(t *testing.T) {
+	b, err := MsgpackCodec.Marshal(map[string]interface{}{"compact": true, "schema": 0})
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x82, 0xa7, 'c', 'o', 'm', 'p', 'a', 'c', 't', 0xc3, 0xa6, 's', 'c', 'h', 'e', 'm', 'a', 0x00}, b)
+
+	b, err = CBORCodec.Marshal([]interface{}{1, -500, "a", nil})
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x84, 0x01, 0x39, 0x01, 0xf3, 0x61, 'a', 0xf6}, b)
+
+	// keys sorted by encoding, shorter keys first
+	b, err = CBORCodec.Marshal(map[string]int{"bb": 1, "c": 2})
+	require.NoError(t, err)
+	require.Equal(t, []byte{0xa2, 0x61, 'c', 0x02, 0x62, 'b', 'b', 0x01}, b)
+
+	// half precision float and tagged values are decoded
+	var f float64
+	err = CBORCodec.Unmarshal([]byte{0xf9, 0x3e, 0x00}, &f)
+	require.NoError(t, err)
+	require.Equal(t, 1.5, f)
+
+	var s string
+	err = CBORCodec.Unmarshal([]byte{0xc0, 0x61, 'x'}, &s)
+	require.NoError(t, err)
+	require.Equal(t, "x", s)
+
+	var i int
+	err = MsgpackCodec.Unmarshal([]byte{0xd1, 0xfe, 0x0c}, &i)
+	require.NoError(t, err)
+	require.Equal(t, -500, i)
+
+	err = CBORCodec.Unmarshal([]byte{0x9f, 0xff}, &i)
+	require.True(t, errors.Is(err, ErrCorruptedEncoding))
+
+	err = MsgpackCodec.Unmarshal([]byte{0xdd, 0xff, 0xff, 0xff, 0xff}, &i)
+	require.True(t, errors.Is(err, ErrCorruptedEncoding))
+}
+
+func TestCodecFor(t *testing.T) {
+	opts := DefaultOptions().
+		WithCollectionCodec("orders:", "msgpack").
+		WithCollectionCodec("orders:archived:", "cbor").
+		WithCollectionCodec("users:", "unknown")
+
+	codec, err := opts.CodecFor([]byte("orders:1"))
+	require.NoError(t, err)
+	require.Equal(t, MsgpackCodec, codec)
+
+	codec, err = opts.CodecFor([]byte("orders:archived:1"))
+	require.NoError(t, err)
+	require.Equal(t, CBORCodec, codec)
+
+	codec, err = opts.CodecFor([]byte("items:1"))
+	require.NoError(t, err)
+	require.Nil(t, codec)
+
+	_, err = opts.CodecFor([]byte("users:1"))
+	require.Equal(t, ErrCodecNotFound, err)
+}
@@ -0,0 +1,157 @@
+//go:build go1.18
+// +build go1.18
+
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+)
+
+// Collection is a typed view over the keys sharing a prefix. Values are (de)serialized using the provided Codec
+type Collection[T any] struct {
+	client   ImmuClient
+	prefix   []byte
+	codec    Codec
+	verified bool
+}
+
+// CollectionEntry is a decoded value along with its key (without the collection prefix) and transaction
+type CollectionEntry[T any] struct {
+	Key   []byte
+	Value T
+	Tx    uint64
+}
+
+// NewCollection returns a new Collection storing its values under prefix. When codec is nil, the codec set in the
+// client options for prefix is used, JSONCodec if there is none
+func NewCollection[T any](client ImmuClient, prefix []byte, codec Codec) *Collection[T] {
+	if codec == nil {
+		codec = collectionCodec(client, prefix)
+	}
+
+	return &Collection[T]{
+		client: client,
+		prefix: prefix,
+		codec:  codec,
+	}
+}
+
+// WithVerified makes Put and Get use verified operations
+func (c *Collection[T]) WithVerified(verified bool) *Collection[T] {
+	c.verified = verified
+	return c
+}
+
+// Put stores value under key
+func (c *Collection[T]) Put(ctx context.Context, key []byte, value T) (*schema.TxMetadata, error) {
+	b, err := c.codec.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.verified {
+		return c.client.VerifiedSet(ctx, c.fullKey(key), b)
+	}
+
+	return c.client.Set(ctx, c.fullKey(key), b)
+}
+
+// Get returns the current value of key
+func (c *Collection[T]) Get(ctx context.Context, key []byte) (T, error) {
+	var e *schema.Entry
+	var err error
+
+	if c.verified {
+		e, err = c.client.VerifiedGet(ctx, c.fullKey(key))
+	} else {
+		e, err = c.client.Get(ctx, c.fullKey(key))
+	}
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	return c.decode(e.Value)
+}
+
+// Scan returns up to limit entries of the collection, starting after seekKey if provided
+func (c *Collection[T]) Scan(ctx context.Context, seekKey []byte, limit uint64, desc bool) ([]*CollectionEntry[T], error) {
+	req := &schema.ScanRequest{
+		Prefix: c.prefix,
+		Limit:  limit,
+		Desc:   desc,
+	}
+
+	if len(seekKey) > 0 {
+		req.SeekKey = c.fullKey(seekKey)
+	}
+
+	list, err := c.client.Scan(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*CollectionEntry[T], len(list.Entries))
+
+	for i, e := range list.Entries {
+		v, err := c.decode(e.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		entries[i] = &CollectionEntry[T]{
+			Key:   e.Key[len(c.prefix):],
+			Value: v,
+			Tx:    e.Tx,
+		}
+	}
+
+	return entries, nil
+}
+
+func collectionCodec(client ImmuClient, prefix []byte) Codec {
+	if opts := client.GetOptions(); opts != nil {
+		if codec, err := opts.CodecFor(prefix); err == nil && codec != nil {
+			return codec
+		}
+	}
+	return JSONCodec
+}
+
+func (c *Collection[T]) fullKey(key []byte) []byte {
+	k := make([]byte, len(c.prefix)+len(key))
+	copy(k, c.prefix)
+	copy(k[len(c.prefix):], key)
+	return k
+}
+
+func (c *Collection[T]) decode(b []byte) (T, error) {
+	var v T
+
+	// pointer types (e.g. proto messages) are decoded into a newly allocated value
+	if t := reflect.TypeOf(v); t != nil && t.Kind() == reflect.Ptr {
+		v = reflect.New(t.Elem()).Interface().(T)
+		return v, c.codec.Unmarshal(b, v)
+	}
+
+	return v, c.codec.Unmarshal(b, &v)
+}
@@ -0,0 +1,100 @@
+//go:build go1.18
+// +build go1.18
+
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/server"
+	"github.com/codenotary/immudb/pkg/server/servertest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+type collectionItem struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+func TestCollection(t *testing.T) {
+	options := server.DefaultOptions().WithAuth(true)
+	bs := servertest.NewBufconnServer(options)
+
+	defer os.RemoveAll(options.Dir)
+	defer os.Remove(".state-")
+
+	bs.Start()
+	defer bs.Stop()
+
+	ts := NewTokenService().WithTokenFileName("testTokenFile").WithHds(DefaultHomedirServiceMock())
+	opts := DefaultOptions().WithDialOptions(&[]grpc.DialOption{grpc.WithContextDialer(bs.Dialer), grpc.WithInsecure()}).WithTokenService(ts)
+	client, err := NewImmuClient(opts)
+	require.NoError(t, err)
+	defer client.Disconnect()
+
+	lr, err := client.Login(context.TODO(), []byte(`immudb`), []byte(`immudb`))
+	require.NoError(t, err)
+
+	md := metadata.Pairs("authorization", lr.Token)
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	items := NewCollection[collectionItem](client, []byte("items:"), JSONCodec)
+
+	_, err = items.Put(ctx, []byte("a"), collectionItem{Name: "a", Count: 1})
+	require.NoError(t, err)
+
+	_, err = items.WithVerified(true).Put(ctx, []byte("b"), collectionItem{Name: "b", Count: 2})
+	require.NoError(t, err)
+
+	item, err := items.Get(ctx, []byte("a"))
+	require.NoError(t, err)
+	require.Equal(t, collectionItem{Name: "a", Count: 1}, item)
+
+	entry, err := client.Get(ctx, []byte("items:b"))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"name":"b","count":2}`, string(entry.Value))
+
+	entries, err := items.Scan(ctx, nil, 0, false)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, []byte("a"), entries[0].Key)
+	require.Equal(t, collectionItem{Name: "b", Count: 2}, entries[1].Value)
+
+	_, err = items.Get(ctx, []byte("c"))
+	require.Error(t, err)
+
+	states := NewCollection[*schema.ImmutableState](client, []byte("states:"), ProtoCodec)
+
+	_, err = states.Put(ctx, []byte("s1"), &schema.ImmutableState{Db: "defaultdb", TxId: 1})
+	require.NoError(t, err)
+
+	state, err := states.Get(ctx, []byte("s1"))
+	require.NoError(t, err)
+	require.Equal(t, "defaultdb", state.Db)
+	require.Equal(t, uint64(1), state.TxId)
+
+	invalid := NewCollection[collectionItem](client, []byte("invalid:"), ProtoCodec)
+	_, err = invalid.Put(ctx, []byte("a"), collectionItem{})
+	require.Equal(t, ErrNotProtoMessage, err)
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+)
+
+// VerifiedCombinedState returns the current state of both the database and its SQL catalog.
+// Each of them is verified to be consistent with the last one seen by the client, which is then updated.
+// The database state is shared with the rest of verified operations while the catalog state is kept in memory.
+func (c *immuClient) VerifiedCombinedState(ctx context.Context) (*schema.CombinedState, error) {
+	err := c.StateService.CacheLock()
+	if err != nil {
+		return nil, err
+	}
+	defer c.StateService.CacheUnlock()
+
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	db := c.currentDatabase()
+
+	state, err := c.StateService.GetState(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	catalogState := c.catalogStates[db]
+
+	req := &schema.VerifiableCombinedStateRequest{ProveSinceTxId: state.TxId}
+	if catalogState != nil {
+		req.ProveSinceCatalogTxId = catalogState.TxId
+	}
+
+	vstate, err := c.ServiceClient.CurrentCombinedState(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	cstate := vstate.State
+
+	if cstate == nil || cstate.Db != db {
+		return nil, store.ErrCorruptedData
+	}
+
+	if c.serverSigningPubKey != nil {
+		ok, err := cstate.CheckSignature(c.serverSigningPubKey)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, store.ErrCorruptedData
+		}
+	}
+
+	if state.TxId > 0 {
+		if vstate.DualProof == nil {
+			return nil, store.ErrCorruptedData
+		}
+
+		verifies := store.VerifyDualProof(
+			schema.DualProofFrom(vstate.DualProof),
+			state.TxId,
+			cstate.TxId,
+			schema.DigestFrom(state.TxHash),
+			schema.DigestFrom(cstate.TxHash),
+		)
+		if !verifies {
+			return nil, store.ErrCorruptedData
+		}
+	}
+
+	if catalogState != nil && catalogState.TxId > 0 {
+		if vstate.CatalogDualProof == nil {
+			return nil, store.ErrCorruptedData
+		}
+
+		verifies := store.VerifyDualProof(
+			schema.DualProofFrom(vstate.CatalogDualProof),
+			catalogState.TxId,
+			cstate.CatalogTxId,
+			schema.DigestFrom(catalogState.TxHash),
+			schema.DigestFrom(cstate.CatalogTxHash),
+		)
+		if !verifies {
+			return nil, store.ErrCorruptedData
+		}
+	}
+
+	err = c.StateService.SetState(db, &schema.ImmutableState{
+		Db:     db,
+		TxId:   cstate.TxId,
+		TxHash: cstate.TxHash,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if c.catalogStates == nil {
+		c.catalogStates = make(map[string]*schema.ImmutableState)
+	}
+
+	c.catalogStates[db] = &schema.ImmutableState{
+		Db:     db,
+		TxId:   cstate.CatalogTxId,
+		TxHash: cstate.CatalogTxHash,
+	}
+
+	return cstate, nil
+}
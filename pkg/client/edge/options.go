@@ -0,0 +1,68 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package edge
+
+import "time"
+
+const DefaultRetryInterval = 5 * time.Second
+
+// Options of the edge writer
+type Options struct {
+	// Dir is where the local queue is persisted
+	Dir string
+
+	// RetryInterval is the delay between forwarding attempts while the server is unreachable
+	RetryInterval time.Duration
+
+	// Verified makes entries to be forwarded with VerifiedSet instead of Set
+	Verified bool
+
+	// OnForwarded is invoked, in order, with the receipt of every entry acknowledged by the server
+	OnForwarded func(*Receipt)
+}
+
+// DefaultOptions returns the default edge writer options
+func DefaultOptions() *Options {
+	return &Options{
+		Dir:           "edge",
+		RetryInterval: DefaultRetryInterval,
+	}
+}
+
+// WithDir sets the directory where the local queue is persisted
+func (o *Options) WithDir(dir string) *Options {
+	o.Dir = dir
+	return o
+}
+
+// WithRetryInterval sets the delay between forwarding attempts
+func (o *Options) WithRetryInterval(interval time.Duration) *Options {
+	o.RetryInterval = interval
+	return o
+}
+
+// WithVerified sets whether entries are forwarded using verified writes
+func (o *Options) WithVerified(verified bool) *Options {
+	o.Verified = verified
+	return o
+}
+
+// WithOnForwarded sets the callback invoked when an entry has been acknowledged by the server
+func (o *Options) WithOnForwarded(onForwarded func(*Receipt)) *Options {
+	o.OnForwarded = onForwarded
+	return o
+}
@@ -0,0 +1,354 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package edge
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+const queueFilename = "queue"
+const ackFilename = "ack"
+const receiptsFilename = "receipts"
+
+// entry is a write waiting to be forwarded
+type entry struct {
+	seq   uint64
+	key   []byte
+	value []byte
+	hash  [sha256.Size]byte
+}
+
+// Receipt binds a locally queued entry to the transaction it was committed in
+type Receipt struct {
+	Seq  uint64
+	TxID uint64
+	Key  []byte
+	Hash [sha256.Size]byte
+}
+
+// queue is an append-only log of the entries not yet acknowledged by the server.
+// Pending entries are also kept in memory, the log is truncated once all of them are forwarded
+type queue struct {
+	dir string
+
+	log      *os.File
+	receipts *os.File
+
+	lastSeq uint64
+	ackSeq  uint64
+	pending []*entry
+}
+
+// entryHash is the local hash of an entry, computed when it's queued and checked again before forwarding it
+func entryHash(key, value []byte) [sha256.Size]byte {
+	b := make([]byte, 4+len(key)+len(value))
+	binary.BigEndian.PutUint32(b, uint32(len(key)))
+	copy(b[4:], key)
+	copy(b[4+len(key):], value)
+	return sha256.Sum256(b)
+}
+
+func openQueue(dir string) (*queue, error) {
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &queue{dir: dir}
+
+	ack, err := ioutil.ReadFile(filepath.Join(dir, ackFilename))
+	if err == nil {
+		if len(ack) != 8 {
+			return nil, ErrCorruptedQueue
+		}
+		q.ackSeq = binary.BigEndian.Uint64(ack)
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	q.lastSeq = q.ackSeq
+
+	q.log, err = os.OpenFile(filepath.Join(dir, queueFilename), os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	err = q.load()
+	if err != nil {
+		q.log.Close()
+		return nil, err
+	}
+
+	q.receipts, err = os.OpenFile(filepath.Join(dir, receiptsFilename), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		q.log.Close()
+		return nil, err
+	}
+
+	return q, nil
+}
+
+// load reads the queued entries, a partially written trailing entry is discarded
+func (q *queue) load() error {
+	r := bufio.NewReader(q.log)
+
+	var off int64
+
+	for {
+		e, n, err := readEntry(r)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		off += n
+
+		if e.hash != entryHash(e.key, e.value) {
+			return ErrCorruptedQueue
+		}
+
+		if e.seq <= q.ackSeq {
+			continue
+		}
+
+		if e.seq != q.lastSeq+1 {
+			return ErrCorruptedQueue
+		}
+
+		q.lastSeq = e.seq
+		q.pending = append(q.pending, e)
+	}
+
+	err := q.log.Truncate(off)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.log.Seek(off, io.SeekStart)
+	return err
+}
+
+func readEntry(r io.Reader) (*entry, int64, error) {
+	var hdr [12]byte
+
+	_, err := io.ReadFull(r, hdr[:])
+	if err != nil {
+		return nil, 0, err
+	}
+
+	e := &entry{seq: binary.BigEndian.Uint64(hdr[:])}
+	klen := binary.BigEndian.Uint32(hdr[8:])
+
+	e.key, err = readBytes(r, klen)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var vlenBs [4]byte
+
+	_, err = io.ReadFull(r, vlenBs[:])
+	if err != nil {
+		return nil, 0, unexpectedEOF(err)
+	}
+
+	e.value, err = readBytes(r, binary.BigEndian.Uint32(vlenBs[:]))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	_, err = io.ReadFull(r, e.hash[:])
+	if err != nil {
+		return nil, 0, unexpectedEOF(err)
+	}
+
+	return e, int64(12 + len(e.key) + 4 + len(e.value) + sha256.Size), nil
+}
+
+func readBytes(r io.Reader, n uint32) ([]byte, error) {
+	b := make([]byte, n)
+
+	_, err := io.ReadFull(r, b)
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	return b, nil
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// append persists a new entry and returns it once synced
+func (q *queue) append(key, value []byte) (*entry, error) {
+	e := &entry{
+		seq:   q.lastSeq + 1,
+		key:   key,
+		value: value,
+		hash:  entryHash(key, value),
+	}
+
+	b := make([]byte, 12+len(key)+4+len(value)+sha256.Size)
+	binary.BigEndian.PutUint64(b, e.seq)
+	binary.BigEndian.PutUint32(b[8:], uint32(len(key)))
+	copy(b[12:], key)
+	binary.BigEndian.PutUint32(b[12+len(key):], uint32(len(value)))
+	copy(b[16+len(key):], value)
+	copy(b[16+len(key)+len(value):], e.hash[:])
+
+	_, err := q.log.Write(b)
+	if err != nil {
+		return nil, err
+	}
+
+	err = q.log.Sync()
+	if err != nil {
+		return nil, err
+	}
+
+	q.lastSeq = e.seq
+	q.pending = append(q.pending, e)
+
+	return e, nil
+}
+
+func (q *queue) head() *entry {
+	if len(q.pending) == 0 {
+		return nil
+	}
+	return q.pending[0]
+}
+
+// ack records the receipt of the head entry and removes it from the queue
+func (q *queue) ack(r *Receipt) error {
+	e := q.head()
+	if e == nil || e.seq != r.Seq {
+		return errors.New("unexpected acknowledgement")
+	}
+
+	b := make([]byte, 8+8+sha256.Size+4+len(r.Key))
+	binary.BigEndian.PutUint64(b, r.Seq)
+	binary.BigEndian.PutUint64(b[8:], r.TxID)
+	copy(b[16:], r.Hash[:])
+	binary.BigEndian.PutUint32(b[16+sha256.Size:], uint32(len(r.Key)))
+	copy(b[20+sha256.Size:], r.Key)
+
+	_, err := q.receipts.Write(b)
+	if err != nil {
+		return err
+	}
+
+	err = q.receipts.Sync()
+	if err != nil {
+		return err
+	}
+
+	var ack [8]byte
+	binary.BigEndian.PutUint64(ack[:], r.Seq)
+
+	tmp := filepath.Join(q.dir, ackFilename+".tmp")
+
+	err = ioutil.WriteFile(tmp, ack[:], 0600)
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(tmp, filepath.Join(q.dir, ackFilename))
+	if err != nil {
+		return err
+	}
+
+	q.ackSeq = r.Seq
+	q.pending[0] = nil
+	q.pending = q.pending[1:]
+
+	if len(q.pending) > 0 {
+		return nil
+	}
+
+	err = q.log.Truncate(0)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.log.Seek(0, io.SeekStart)
+	return err
+}
+
+// readReceipts returns the receipts of all the forwarded entries
+func (q *queue) readReceipts() ([]*Receipt, error) {
+	f, err := os.Open(filepath.Join(q.dir, receiptsFilename))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+
+	var receipts []*Receipt
+
+	for {
+		var hdr [20 + sha256.Size]byte
+
+		_, err := io.ReadFull(r, hdr[:])
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return receipts, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		rc := &Receipt{
+			Seq:  binary.BigEndian.Uint64(hdr[:]),
+			TxID: binary.BigEndian.Uint64(hdr[8:]),
+		}
+		copy(rc.Hash[:], hdr[16:])
+
+		rc.Key, err = readBytes(r, binary.BigEndian.Uint32(hdr[16+sha256.Size:]))
+		if err == io.ErrUnexpectedEOF {
+			return receipts, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		receipts = append(receipts, rc)
+	}
+}
+
+func (q *queue) close() error {
+	err := q.log.Close()
+
+	rerr := q.receipts.Close()
+	if err == nil {
+		err = rerr
+	}
+
+	return err
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package edge
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueuePartialEntry(t *testing.T) {
+	dir, err := ioutil.TempDir("", "edge_queue")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	q, err := openQueue(dir)
+	require.NoError(t, err)
+
+	_, err = q.append([]byte("k1"), []byte("v1"))
+	require.NoError(t, err)
+
+	_, err = q.append([]byte("k2"), []byte("v2"))
+	require.NoError(t, err)
+
+	require.NoError(t, q.close())
+
+	fname := filepath.Join(dir, queueFilename)
+
+	fi, err := os.Stat(fname)
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate(fname, fi.Size()-1))
+
+	q, err = openQueue(dir)
+	require.NoError(t, err)
+	require.Len(t, q.pending, 1)
+	require.Equal(t, []byte("k1"), q.pending[0].key)
+
+	e, err := q.append([]byte("k3"), []byte("v3"))
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), e.seq)
+
+	require.NoError(t, q.close())
+
+	q, err = openQueue(dir)
+	require.NoError(t, err)
+	require.Len(t, q.pending, 2)
+	require.Equal(t, []byte("k3"), q.pending[1].key)
+	require.NoError(t, q.close())
+}
+
+func TestQueueCorrupted(t *testing.T) {
+	dir, err := ioutil.TempDir("", "edge_queue")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	q, err := openQueue(dir)
+	require.NoError(t, err)
+
+	_, err = q.append([]byte("k1"), []byte("v1"))
+	require.NoError(t, err)
+	require.NoError(t, q.close())
+
+	fname := filepath.Join(dir, queueFilename)
+
+	b, err := ioutil.ReadFile(fname)
+	require.NoError(t, err)
+
+	b[len(b)-1] ^= 1
+	require.NoError(t, ioutil.WriteFile(fname, b, 0600))
+
+	_, err = openQueue(dir)
+	require.Equal(t, ErrCorruptedQueue, err)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, ackFilename), []byte{1}, 0600))
+
+	_, err = openQueue(dir)
+	require.Equal(t, ErrCorruptedQueue, err)
+}
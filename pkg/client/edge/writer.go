@@ -0,0 +1,253 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package edge provides a store-and-forward writer for intermittently connected devices.
+// Writes are persisted to a local queue and forwarded in order, at least once, whenever the server is reachable.
+package edge
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/client"
+)
+
+var ErrIllegalArguments = errors.New("illegal arguments")
+var ErrAlreadyClosed = errors.New("already closed")
+var ErrAlreadyStarted = errors.New("already started")
+var ErrCorruptedQueue = errors.New("local queue is corrupted")
+
+// Writer queues writes locally and forwards them to immudb
+type Writer struct {
+	client client.ImmuClient
+	opts   *Options
+
+	queue *queue
+	mutex sync.Mutex
+
+	forwardMutex sync.Mutex
+
+	notifyc chan struct{}
+	stopc   chan struct{}
+	donec   chan struct{}
+	started bool
+	closed  bool
+}
+
+// Open returns a writer using the queue persisted in opts.Dir, entries left from a previous run are forwarded first
+func Open(c client.ImmuClient, opts *Options) (*Writer, error) {
+	if c == nil || opts == nil || opts.Dir == "" || opts.RetryInterval <= 0 {
+		return nil, ErrIllegalArguments
+	}
+
+	q, err := openQueue(opts.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Writer{
+		client:  c,
+		opts:    opts,
+		queue:   q,
+		notifyc: make(chan struct{}, 1),
+		stopc:   make(chan struct{}),
+		donec:   make(chan struct{}),
+	}, nil
+}
+
+// Set persists the entry into the local queue and returns its sequence number.
+// The entry is forwarded later on, by Flush or by the background forwarder
+func (w *Writer) Set(key, value []byte) (uint64, error) {
+	if len(key) == 0 {
+		return 0, ErrIllegalArguments
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.closed {
+		return 0, ErrAlreadyClosed
+	}
+
+	e, err := w.queue.append(key, value)
+	if err != nil {
+		return 0, err
+	}
+
+	select {
+	case w.notifyc <- struct{}{}:
+	default:
+	}
+
+	return e.seq, nil
+}
+
+// Pending returns the number of entries not yet acknowledged by the server
+func (w *Writer) Pending() int {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	return len(w.queue.pending)
+}
+
+// Flush forwards the queued entries in order. It stops at the first failure, which is returned,
+// leaving the failed entry and the ones after it in the queue
+func (w *Writer) Flush(ctx context.Context) error {
+	w.forwardMutex.Lock()
+	defer w.forwardMutex.Unlock()
+
+	for {
+		w.mutex.Lock()
+		if w.closed {
+			w.mutex.Unlock()
+			return ErrAlreadyClosed
+		}
+		e := w.queue.head()
+		w.mutex.Unlock()
+
+		if e == nil {
+			return nil
+		}
+
+		if e.hash != entryHash(e.key, e.value) {
+			return ErrCorruptedQueue
+		}
+
+		var md *schema.TxMetadata
+		var err error
+
+		if w.opts.Verified {
+			md, err = w.client.VerifiedSet(ctx, e.key, e.value)
+		} else {
+			md, err = w.client.Set(ctx, e.key, e.value)
+		}
+		if err != nil {
+			return err
+		}
+
+		r := &Receipt{
+			Seq:  e.seq,
+			TxID: md.Id,
+			Key:  e.key,
+			Hash: e.hash,
+		}
+
+		w.mutex.Lock()
+		err = w.queue.ack(r)
+		w.mutex.Unlock()
+		if err != nil {
+			return err
+		}
+
+		if w.opts.OnForwarded != nil {
+			w.opts.OnForwarded(r)
+		}
+	}
+}
+
+// Start forwards entries in background as they are queued, retrying every RetryInterval while the server is unreachable.
+// ctx is used for the calls made to the server
+func (w *Writer) Start(ctx context.Context) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.closed {
+		return ErrAlreadyClosed
+	}
+
+	if w.started {
+		return ErrAlreadyStarted
+	}
+
+	w.started = true
+
+	go w.forward(ctx)
+
+	return nil
+}
+
+func (w *Writer) forward(ctx context.Context) {
+	defer close(w.donec)
+
+	for {
+		var retryc <-chan time.Time
+
+		if err := w.Flush(ctx); err != nil {
+			if err == ErrAlreadyClosed {
+				return
+			}
+
+			retryc = time.After(w.opts.RetryInterval)
+		}
+
+		select {
+		case <-w.stopc:
+			return
+		case <-ctx.Done():
+			return
+		case <-w.notifyc:
+		case <-retryc:
+		}
+	}
+}
+
+// Receipts returns the receipts of the entries forwarded so far, an entry may have more than one receipt
+// if the writer was interrupted before recording the acknowledgement of the server
+func (w *Writer) Receipts() ([]*Receipt, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.closed {
+		return nil, ErrAlreadyClosed
+	}
+
+	return w.queue.readReceipts()
+}
+
+// Close stops the background forwarder and releases the local queue, pending entries are kept for the next run
+func (w *Writer) Close() error {
+	w.mutex.Lock()
+
+	if w.closed {
+		w.mutex.Unlock()
+		return ErrAlreadyClosed
+	}
+
+	started := w.started
+
+	if started {
+		close(w.stopc)
+	}
+
+	w.mutex.Unlock()
+
+	if started {
+		<-w.donec
+	}
+
+	w.forwardMutex.Lock()
+	defer w.forwardMutex.Unlock()
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.closed = true
+
+	return w.queue.close()
+}
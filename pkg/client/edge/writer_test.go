@@ -0,0 +1,212 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package edge
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/client"
+	"github.com/codenotary/immudb/pkg/server"
+	"github.com/codenotary/immudb/pkg/server/servertest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+var errOffline = errors.New("offline")
+
+// intermittentClient fails writes while offline
+type intermittentClient struct {
+	client.ImmuClient
+	offline int32
+}
+
+func (c *intermittentClient) setOffline(offline bool) {
+	var v int32
+	if offline {
+		v = 1
+	}
+	atomic.StoreInt32(&c.offline, v)
+}
+
+func (c *intermittentClient) Set(ctx context.Context, key []byte, value []byte) (*schema.TxMetadata, error) {
+	if atomic.LoadInt32(&c.offline) == 1 {
+		return nil, errOffline
+	}
+	return c.ImmuClient.Set(ctx, key, value)
+}
+
+func (c *intermittentClient) VerifiedSet(ctx context.Context, key []byte, value []byte) (*schema.TxMetadata, error) {
+	if atomic.LoadInt32(&c.offline) == 1 {
+		return nil, errOffline
+	}
+	return c.ImmuClient.VerifiedSet(ctx, key, value)
+}
+
+func newTestClient(t *testing.T) (*intermittentClient, context.Context, func()) {
+	options := server.DefaultOptions().WithAuth(true)
+	bs := servertest.NewBufconnServer(options)
+	bs.Start()
+
+	cli, err := client.NewImmuClient(client.DefaultOptions().WithDialOptions(&[]grpc.DialOption{grpc.WithContextDialer(bs.Dialer), grpc.WithInsecure()}))
+	require.NoError(t, err)
+
+	lr, err := cli.Login(context.TODO(), []byte(`immudb`), []byte(`immudb`))
+	require.NoError(t, err)
+
+	md := metadata.Pairs("authorization", lr.Token)
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	return &intermittentClient{ImmuClient: cli}, ctx, func() {
+		cli.Disconnect()
+		bs.Stop()
+		os.RemoveAll(options.Dir)
+		os.Remove(".state-")
+	}
+}
+
+func TestWriter(t *testing.T) {
+	cli, ctx, cleanup := newTestClient(t)
+	defer cleanup()
+
+	dir, err := ioutil.TempDir("", "edge")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	_, err = Open(nil, DefaultOptions().WithDir(dir))
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = Open(cli, DefaultOptions().WithDir(dir).WithRetryInterval(0))
+	require.Equal(t, ErrIllegalArguments, err)
+
+	cli.setOffline(true)
+
+	w, err := Open(cli, DefaultOptions().WithDir(dir))
+	require.NoError(t, err)
+
+	_, err = w.Set(nil, []byte("value"))
+	require.Equal(t, ErrIllegalArguments, err)
+
+	for i, k := range []string{"k1", "k2", "k3"} {
+		seq, err := w.Set([]byte(k), []byte("v"+k))
+		require.NoError(t, err)
+		require.Equal(t, uint64(i+1), seq)
+	}
+
+	err = w.Flush(ctx)
+	require.Equal(t, errOffline, err)
+	require.Equal(t, 3, w.Pending())
+
+	require.NoError(t, w.Close())
+	require.Equal(t, ErrAlreadyClosed, w.Close())
+
+	_, err = w.Set([]byte("k4"), []byte("vk4"))
+	require.Equal(t, ErrAlreadyClosed, err)
+
+	cli.setOffline(false)
+
+	var forwarded []*Receipt
+
+	w, err = Open(cli, DefaultOptions().WithDir(dir).WithVerified(true).WithOnForwarded(func(r *Receipt) {
+		forwarded = append(forwarded, r)
+	}))
+	require.NoError(t, err)
+	require.Equal(t, 3, w.Pending())
+
+	seq, err := w.Set([]byte("k4"), []byte("vk4"))
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), seq)
+
+	err = w.Flush(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 0, w.Pending())
+	require.Len(t, forwarded, 4)
+
+	for i, r := range forwarded {
+		require.Equal(t, uint64(i+1), r.Seq)
+
+		e, err := cli.GetAt(ctx, r.Key, r.TxID)
+		require.NoError(t, err)
+		require.Equal(t, r.Hash, entryHash(r.Key, e.Value))
+
+		if i > 0 {
+			require.Greater(t, r.TxID, forwarded[i-1].TxID)
+		}
+	}
+
+	receipts, err := w.Receipts()
+	require.NoError(t, err)
+	require.Equal(t, forwarded, receipts)
+
+	require.NoError(t, w.Close())
+
+	w, err = Open(cli, DefaultOptions().WithDir(dir))
+	require.NoError(t, err)
+	defer w.Close()
+
+	require.Equal(t, 0, w.Pending())
+
+	seq, err = w.Set([]byte("k5"), []byte("vk5"))
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), seq)
+}
+
+func TestWriterBackgroundForwarding(t *testing.T) {
+	cli, ctx, cleanup := newTestClient(t)
+	defer cleanup()
+
+	dir, err := ioutil.TempDir("", "edge")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	forwardedc := make(chan *Receipt, 10)
+
+	w, err := Open(cli, DefaultOptions().WithDir(dir).WithRetryInterval(10*time.Millisecond).WithOnForwarded(func(r *Receipt) {
+		forwardedc <- r
+	}))
+	require.NoError(t, err)
+
+	cli.setOffline(true)
+
+	require.NoError(t, w.Start(ctx))
+	require.Equal(t, ErrAlreadyStarted, w.Start(ctx))
+
+	_, err = w.Set([]byte("k1"), []byte("v1"))
+	require.NoError(t, err)
+
+	_, err = w.Set([]byte("k2"), []byte("v2"))
+	require.NoError(t, err)
+
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, 2, w.Pending())
+
+	cli.setOffline(false)
+
+	r1 := <-forwardedc
+	r2 := <-forwardedc
+	require.Equal(t, []byte("k1"), r1.Key)
+	require.Equal(t, []byte("k2"), r2.Key)
+
+	require.NoError(t, w.Close())
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+)
+
+// CreateEnrollmentToken mints a one-time token to enroll a device with the given permission on database.
+// The server default validity is used when ttl is zero
+func (c *immuClient) CreateEnrollmentToken(ctx context.Context, database string, permission uint32, ttl time.Duration) (*schema.EnrollmentToken, error) {
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	return c.ServiceClient.CreateEnrollmentToken(ctx, &schema.EnrollmentTokenRequest{
+		Database:   database,
+		Permission: permission,
+		Ttl:        int64(ttl.Seconds()),
+	})
+}
+
+// Enroll exchanges an enrollment token for the credentials of device, which can then be used to login.
+// A device name is generated by the server when device is empty
+func (c *immuClient) Enroll(ctx context.Context, token string, device string) (*schema.EnrollResponse, error) {
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	return c.ServiceClient.Enroll(ctx, &schema.EnrollRequest{Token: token, Device: device})
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/codenotary/immudb/pkg/auth"
+	"github.com/codenotary/immudb/pkg/server"
+	"github.com/codenotary/immudb/pkg/server/servertest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestImmuClient_Enrollment(t *testing.T) {
+	options := server.DefaultOptions().WithAuth(true)
+	bs := servertest.NewBufconnServer(options)
+
+	defer os.RemoveAll(options.Dir)
+	defer os.Remove(".state-")
+
+	bs.Start()
+	defer bs.Stop()
+
+	client, err := NewImmuClient(DefaultOptions().WithDialOptions(&[]grpc.DialOption{grpc.WithContextDialer(bs.Dialer), grpc.WithInsecure()}))
+	require.NoError(t, err)
+	lr, err := client.Login(context.TODO(), []byte(`immudb`), []byte(`immudb`))
+	require.NoError(t, err)
+
+	md := metadata.Pairs("authorization", lr.Token)
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	et, err := client.CreateEnrollmentToken(ctx, "defaultdb", auth.PermissionRW, time.Hour)
+	require.NoError(t, err)
+
+	er, err := client.Enroll(context.Background(), et.Token, "device_1")
+	require.NoError(t, err)
+	require.Equal(t, []byte("device_1"), er.User)
+
+	_, err = client.Enroll(context.Background(), et.Token, "device_2")
+	require.Error(t, err)
+
+	lr, err = client.Login(context.TODO(), er.User, er.Password)
+	require.NoError(t, err)
+
+	md = metadata.Pairs("authorization", lr.Token)
+	ctx = metadata.NewOutgoingContext(context.Background(), md)
+
+	_, err = client.Set(ctx, []byte("key"), []byte("value"))
+	require.NoError(t, err)
+
+	_, err = client.CreateEnrollmentToken(ctx, "defaultdb", auth.PermissionRW, time.Hour)
+	require.Error(t, err)
+
+	client.Disconnect()
+
+	_, err = client.CreateEnrollmentToken(ctx, "defaultdb", auth.PermissionRW, 0)
+	require.Equal(t, ErrNotConnected, err)
+
+	_, err = client.Enroll(ctx, et.Token, "device_3")
+	require.Equal(t, ErrNotConnected, err)
+}
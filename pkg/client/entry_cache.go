@@ -0,0 +1,210 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/empty"
+)
+
+// cacheReverifier periodically checks the verified entries of the entry cache against the latest server state
+type cacheReverifier struct {
+	stop chan struct{}
+	done chan struct{}
+}
+
+// entryCacheWatches counts the WatchEntryCache calls running for each database
+type entryCacheWatches struct {
+	sync.Mutex
+	dbs map[string]int
+}
+
+// WatchEntryCache subscribes to the writes committed to the current database, invalidating the cached values of the
+// keys they update, until ctx is done or the subscription fails. While it runs, reads of the latest value of keys are
+// served from the entry cache, thus they may lag behind the server by the time taken to deliver the subscription events.
+// Keys assigned a reference are not notified by the subscription, their previous values keep being served until
+// they are written again
+func (c *immuClient) WatchEntryCache(ctx context.Context) error {
+	if c.EntryCache == nil {
+		return ErrIllegalArguments
+	}
+
+	if !c.IsConnected() {
+		return ErrNotConnected
+	}
+
+	db := c.Options.CurrentDatabase
+
+	// writes committed after the state are delivered, so no write is missed by reads started from now on
+	state, err := c.ServiceClient.CurrentState(ctx, &empty.Empty{})
+	if err != nil {
+		return err
+	}
+
+	c.cacheWatches.Lock()
+	if c.cacheWatches.dbs == nil {
+		c.cacheWatches.dbs = make(map[string]int)
+	}
+	c.cacheWatches.dbs[db]++
+	c.cacheWatches.Unlock()
+
+	// reads started before the state may return values overwritten before it
+	c.EntryCache.InvalidateAll(db)
+
+	defer func() {
+		c.cacheWatches.Lock()
+		c.cacheWatches.dbs[db]--
+		if c.cacheWatches.dbs[db] == 0 {
+			delete(c.cacheWatches.dbs, db)
+			c.EntryCache.InvalidateAll(db)
+		}
+		c.cacheWatches.Unlock()
+	}()
+
+	req := &schema.WatchRequest{SinceTx: state.TxId + 1, IncludeDeleted: true}
+
+	return c.Watch(ctx, req, func(event *schema.WatchEvent) error {
+		for _, e := range event.Entries {
+			c.EntryCache.Invalidate(db, e.Key, event.Tx)
+		}
+		return nil
+	})
+}
+
+// isEntryCacheWatched returns true while the cached entries of the current database are invalidated by WatchEntryCache
+func (c *immuClient) isEntryCacheWatched() bool {
+	c.cacheWatches.Lock()
+	defer c.cacheWatches.Unlock()
+
+	return c.cacheWatches.dbs[c.Options.CurrentDatabase] > 0
+}
+
+// isLatestRead returns true if the request reads the current value of the key
+func isLatestRead(kReq *schema.KeyRequest) bool {
+	return kReq.AtTx == 0 && kReq.SinceTx == 0 && kReq.Snapshot == "" && kReq.AtTime == 0
+}
+
+func (c *immuClient) cachedLatestEntry(key []byte, verified bool) (*schema.Entry, bool) {
+	if c.EntryCache == nil || !c.isEntryCacheWatched() {
+		return nil, false
+	}
+
+	return c.EntryCache.GetLatest(c.Options.CurrentDatabase, key, verified)
+}
+
+// entryCacheSeq must be taken before reading the latest value of a key, to be passed to cacheLatestEntry
+func (c *immuClient) entryCacheSeq() uint64 {
+	if c.EntryCache == nil {
+		return 0
+	}
+
+	return c.EntryCache.InvalidationSeq()
+}
+
+func (c *immuClient) cacheLatestEntry(key []byte, e *schema.Entry, verified bool, seq uint64) {
+	if c.EntryCache == nil {
+		return
+	}
+
+	if !c.isEntryCacheWatched() {
+		c.EntryCache.Set(c.Options.CurrentDatabase, key, e, verified)
+		return
+	}
+
+	c.EntryCache.SetLatest(c.Options.CurrentDatabase, key, e, verified, seq)
+}
+
+// ReverifyEntryCache verifies again the verified entries of the current database kept in the entry cache, against
+// the latest state of the server. Entries failing verification, or differing from the ones read, are evicted and
+// counted. Any other error stops the verification, leaving the remaining entries cached
+func (c *immuClient) ReverifyEntryCache(ctx context.Context) (evicted int, err error) {
+	if c.EntryCache == nil {
+		return 0, nil
+	}
+
+	if !c.IsConnected() {
+		return 0, ErrNotConnected
+	}
+
+	db := c.Options.CurrentDatabase
+
+	for _, cached := range c.EntryCache.Verified(db) {
+		e, err := c.fetchVerifiedEntry(ctx, &schema.KeyRequest{Key: cached.Key, AtTx: cached.Tx})
+		if err != nil && err != store.ErrCorruptedData {
+			return evicted, err
+		}
+
+		if err != nil || !proto.Equal(e, cached) {
+			c.Logger.Warningf("entry cache: evicting entry of key %q at tx %d: verification failed", cached.Key, cached.Tx)
+			c.EntryCache.Remove(db, cached.Key, cached.Tx)
+			evicted++
+		}
+	}
+
+	return evicted, nil
+}
+
+func (c *immuClient) startCacheReverifier() {
+	if c.EntryCache == nil || c.Options.EntryCacheReverifyInterval <= 0 {
+		return
+	}
+
+	c.cacheReverifier = &cacheReverifier{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+
+	go c.runCacheReverifier(c.cacheReverifier.stop, c.cacheReverifier.done)
+}
+
+func (c *immuClient) stopCacheReverifier() {
+	if c.cacheReverifier == nil {
+		return
+	}
+
+	close(c.cacheReverifier.stop)
+	<-c.cacheReverifier.done
+
+	c.cacheReverifier = nil
+}
+
+func (c *immuClient) runCacheReverifier(stop chan struct{}, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(c.Options.EntryCacheReverifyInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), c.Options.EntryCacheReverifyInterval)
+			_, err := c.ReverifyEntryCache(ctx)
+			cancel()
+			if err != nil {
+				c.Logger.Warningf("entry cache: %v", err)
+			}
+		}
+	}
+}
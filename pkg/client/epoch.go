@@ -0,0 +1,112 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package client
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// epochMetadataKey is the metadata the fencing epoch is sent along with calls, as expected by the server
+const epochMetadataKey = "epoch"
+
+// epochs holds the highest fencing epoch of each database, learned when logging in or selecting the database.
+// Once known, the epoch is sent along with every call to the database, so writes are rejected by a server
+// which is not the primary of that epoch
+type epochs struct {
+	sync.Mutex
+	byDatabase map[string]uint64
+}
+
+// Epoch returns the highest fencing epoch of the selected database the client learned from the servers
+func (c *immuClient) Epoch() uint64 {
+	epoch, _ := c.currentEpoch()
+	return epoch
+}
+
+func (c *immuClient) currentEpoch() (uint64, bool) {
+	if c.epochs == nil {
+		return 0, false
+	}
+
+	c.epochs.Lock()
+	defer c.epochs.Unlock()
+
+	epoch, known := c.epochs.byDatabase[c.Options.CurrentDatabase]
+	return epoch, known
+}
+
+// observeEpoch keeps the highest epoch of the database, a former primary must not lower it
+func (c *immuClient) observeEpoch(database string, epoch uint64) {
+	if c.epochs == nil {
+		return
+	}
+
+	c.epochs.Lock()
+	defer c.epochs.Unlock()
+
+	if current, known := c.epochs.byDatabase[database]; !known || epoch > current {
+		c.epochs.byDatabase[database] = epoch
+	}
+}
+
+func (c *immuClient) withEpoch(ctx context.Context) context.Context {
+	epoch, known := c.currentEpoch()
+	if !known {
+		return ctx
+	}
+
+	if md, ok := metadata.FromOutgoingContext(ctx); ok && len(md.Get(epochMetadataKey)) > 0 {
+		return ctx
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, epochMetadataKey, strconv.FormatUint(epoch, 10))
+}
+
+// EpochUnaryInterceptor sends the fencing epoch along with calls
+func (c *immuClient) EpochUnaryInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return invoker(c.withEpoch(ctx), method, req, reply, cc, opts...)
+}
+
+// EpochStreamInterceptor sends the fencing epoch along with streams
+func (c *immuClient) EpochStreamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return streamer(c.withEpoch(ctx), desc, cc, method, opts...)
+}
+
+// AdvanceEpoch increments the fencing epoch of the selected database, once a replica was promoted to primary
+func (c *immuClient) AdvanceEpoch(ctx context.Context) (*schema.Epoch, error) {
+	start := time.Now()
+
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	result, err := c.ServiceClient.AdvanceEpoch(ctx, &empty.Empty{})
+	if err == nil {
+		c.observeEpoch(c.Options.CurrentDatabase, result.Epoch)
+	}
+
+	c.Logger.Debugf("AdvanceEpoch finished in %s", time.Since(start))
+
+	return result, err
+}
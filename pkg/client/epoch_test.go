@@ -0,0 +1,93 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package client
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/database"
+	"github.com/codenotary/immudb/pkg/server"
+	"github.com/codenotary/immudb/pkg/server/servertest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestImmuClient_EpochFencing(t *testing.T) {
+	options := server.DefaultOptions().WithAuth(true)
+	bs := servertest.NewBufconnServer(options)
+
+	defer os.RemoveAll(options.Dir)
+	defer os.Remove(".state-")
+
+	bs.Start()
+	defer bs.Stop()
+
+	connect := func() (ImmuClient, context.Context) {
+		ts := NewTokenService().WithTokenFileName("testTokenFile").WithHds(DefaultHomedirServiceMock())
+		opts := DefaultOptions().WithDialOptions(&[]grpc.DialOption{grpc.WithContextDialer(bs.Dialer), grpc.WithInsecure()}).WithTokenService(ts)
+
+		cli, err := NewImmuClient(opts)
+		require.NoError(t, err)
+
+		lr, err := cli.Login(context.TODO(), []byte(`immudb`), []byte(`immudb`))
+		require.NoError(t, err)
+
+		ctx := metadata.NewOutgoingContext(context.Background(), metadata.Pairs("authorization", lr.Token))
+
+		ur, err := cli.UseDatabase(ctx, &schema.Database{DatabaseName: "defaultdb"})
+		require.NoError(t, err)
+
+		return cli, metadata.NewOutgoingContext(context.Background(), metadata.Pairs("authorization", ur.Token))
+	}
+
+	stale, staleCtx := connect()
+	defer stale.Disconnect()
+
+	require.Zero(t, stale.Epoch())
+
+	_, err := stale.Set(staleCtx, []byte("key"), []byte("value"))
+	require.NoError(t, err)
+
+	promoted, promotedCtx := connect()
+	defer promoted.Disconnect()
+
+	epoch, err := promoted.AdvanceEpoch(promotedCtx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), epoch.Epoch)
+	require.Equal(t, uint64(1), promoted.Epoch())
+
+	_, err = promoted.Set(promotedCtx, []byte("key"), []byte("value"))
+	require.NoError(t, err)
+
+	_, err = stale.Set(staleCtx, []byte("key"), []byte("value"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), database.ErrStaleEpoch.Error())
+
+	_, err = stale.Get(staleCtx, []byte("key"))
+	require.NoError(t, err)
+
+	ur, err := stale.UseDatabase(staleCtx, &schema.Database{DatabaseName: "defaultdb"})
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), ur.Epoch)
+	require.Equal(t, uint64(1), stale.Epoch())
+
+	_, err = stale.Set(staleCtx, []byte("key"), []byte("value"))
+	require.NoError(t, err)
+}
@@ -32,6 +32,17 @@ var (
 	ErrServerStateIsOlder = errors.New("server state is older than the client one")
 )
 
+// Errors related to rewind attestations
+var (
+	ErrRewindNotAttested = errors.New("the server history was not rewound by a valid attestation")
+)
+
+// Errors related to mapping SQL rows into structs
+var (
+	ErrInvalidDestination   = errors.New("destination must be a non-nil pointer to a struct or to a slice of structs")
+	ErrIncompatibleSQLValue = errors.New("sql value can not be assigned to field")
+)
+
 // Server errors mapping
 var (
 	ErrSrvIllegalArguments   = status.Error(codes.InvalidArgument, "illegal arguments")
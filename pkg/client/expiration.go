@@ -0,0 +1,60 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/database"
+)
+
+// ExpirableSet sets a key which can no longer be read after expiresAt, while the transaction stays in the history
+func (c *immuClient) ExpirableSet(ctx context.Context, key []byte, value []byte, expiresAt time.Time) (*schema.TxMetadata, error) {
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	start := time.Now()
+	defer func() { c.Logger.Debugf("ExpirableSet finished in %s", time.Since(start)) }()
+
+	txmd, err := c.ServiceClient.Set(ctx, &schema.SetRequest{
+		KVs:         []*schema.KeyValue{{Key: key, Value: value, ExpiresAt: expiresAt.Unix()}},
+		Attribution: c.attribution(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !isExpectedKVTxLen(txmd, 1, "") {
+		return nil, store.ErrCorruptedData
+	}
+
+	return txmd, nil
+}
+
+// entryKV returns the key-value an entry was written as, so its inclusion can be verified
+func entryKV(key []byte, e *schema.Entry) *store.KV {
+	if e.Blob {
+		return database.EncodeBlobKV(key, e.Value)
+	}
+	if e.ExpiresAt != 0 {
+		return database.EncodeExpirableKV(key, e.Value, e.ExpiresAt)
+	}
+	return database.EncodeKV(key, e.Value)
+}
@@ -0,0 +1,357 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/client/state"
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// HeartbeatEventKind identifies what the heartbeat detected
+type HeartbeatEventKind int
+
+const (
+	// HeartbeatSessionRenewed is notified after the client logged in again, Token holds the new session token
+	HeartbeatSessionRenewed HeartbeatEventKind = iota
+	// HeartbeatServerRestarted is notified when the server UUID changed. States are pinned to the new server from now on
+	HeartbeatServerRestarted
+	// HeartbeatStateRegression is notified when the server state is behind the state pinned by the client, or
+	// forked from it when rewind attestations are accepted. The pinned state is kept, since the server may have
+	// been tampered with
+	HeartbeatStateRegression
+	// HeartbeatStateRewound is notified when the pinned state was moved to the server one after a rewind,
+	// Attestation holds the rewind attestation sanctioning it
+	HeartbeatStateRewound
+)
+
+// HeartbeatEvent is notified to the heartbeat handler
+type HeartbeatEvent struct {
+	Kind           HeartbeatEventKind
+	Token          string
+	ServerUUID     string
+	PrevServerUUID string
+	Database       string
+	State          *schema.ImmutableState
+	PinnedState    *schema.ImmutableState
+	Attestation    *schema.RewindAttestation
+}
+
+// session holds the credentials the heartbeat uses to renew the session token.
+// Calls not carrying their own authorization metadata are authenticated with the session token
+type session struct {
+	sync.Mutex
+	username     []byte
+	password     []byte
+	database     string
+	token        string
+	renewedAt    time.Time
+	serverUUID   string
+	uuidProvider state.UUIDProvider
+	stop         chan struct{}
+	done         chan struct{}
+}
+
+func (c *immuClient) sessionToken() string {
+	if c.session == nil {
+		return ""
+	}
+
+	c.session.Lock()
+	defer c.session.Unlock()
+
+	return c.session.token
+}
+
+func (c *immuClient) withSessionToken(ctx context.Context) context.Context {
+	token := c.sessionToken()
+	if token == "" {
+		return ctx
+	}
+
+	if md, ok := metadata.FromOutgoingContext(ctx); ok && len(md.Get("authorization")) > 0 {
+		return ctx
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, "authorization", token)
+}
+
+// SessionUnaryInterceptor authenticates calls with the session token renewed by the heartbeat
+func (c *immuClient) SessionUnaryInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return invoker(c.withSessionToken(ctx), method, req, reply, cc, opts...)
+}
+
+// SessionStreamInterceptor authenticates streams with the session token renewed by the heartbeat
+func (c *immuClient) SessionStreamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return streamer(c.withSessionToken(ctx), desc, cc, method, opts...)
+}
+
+func (c *immuClient) startHeartbeat(ctx context.Context, user []byte, pass []byte, token string) {
+	if c.session == nil || c.Options.HeartbeatInterval <= 0 {
+		return
+	}
+
+	uuidProvider := state.NewUUIDProvider(c.ServiceClient)
+
+	serverUUID, err := uuidProvider.CurrentUUID(ctx)
+	if err != nil && err != state.ErrNoServerUuid {
+		c.Logger.Warningf("heartbeat: unable to get server uuid: %v", err)
+	}
+
+	c.session.Lock()
+	defer c.session.Unlock()
+
+	c.session.username = user
+	c.session.password = pass
+	c.session.database = ""
+	c.session.token = token
+	c.session.renewedAt = time.Now()
+	c.session.serverUUID = serverUUID
+	c.session.uuidProvider = uuidProvider
+
+	if c.session.stop != nil {
+		return
+	}
+
+	c.session.stop = make(chan struct{})
+	c.session.done = make(chan struct{})
+
+	go c.runHeartbeat(c.session.stop, c.session.done)
+}
+
+func (c *immuClient) stopHeartbeat() {
+	if c.session == nil {
+		return
+	}
+
+	c.session.Lock()
+	stop, done := c.session.stop, c.session.done
+	c.session.stop = nil
+	c.session.done = nil
+	c.session.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+
+	c.session.Lock()
+	c.session.token = ""
+	c.session.username = nil
+	c.session.password = nil
+	c.session.database = ""
+	c.session.Unlock()
+}
+
+func (c *immuClient) setSessionDatabase(database string, token string) {
+	if c.session == nil {
+		return
+	}
+
+	c.session.Lock()
+	defer c.session.Unlock()
+
+	if c.session.stop == nil {
+		return
+	}
+
+	c.session.database = database
+	c.session.token = token
+}
+
+func (c *immuClient) runHeartbeat(stop chan struct{}, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(c.Options.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), c.Options.HeartbeatInterval)
+			err := c.heartbeat(ctx)
+			cancel()
+			if err != nil {
+				c.Logger.Warningf("heartbeat: %v", err)
+			}
+		}
+	}
+}
+
+func (c *immuClient) heartbeat(ctx context.Context) error {
+	c.session.Lock()
+	uuidProvider := c.session.uuidProvider
+	c.session.Unlock()
+
+	serverUUID, err := uuidProvider.CurrentUUID(ctx)
+	if err != nil && err != state.ErrNoServerUuid {
+		return err
+	}
+
+	c.session.Lock()
+	prevServerUUID := c.session.serverUUID
+	renewedAt := c.session.renewedAt
+	database := c.session.database
+	c.session.serverUUID = serverUUID
+	c.session.Unlock()
+
+	restarted := prevServerUUID != "" && serverUUID != "" && serverUUID != prevServerUUID
+
+	if restarted {
+		if c.StateService != nil {
+			c.StateService.SetServerUUID(serverUUID)
+		}
+
+		c.notifyHeartbeat(&HeartbeatEvent{
+			Kind:           HeartbeatServerRestarted,
+			ServerUUID:     serverUUID,
+			PrevServerUUID: prevServerUUID,
+		})
+	}
+
+	if restarted || time.Since(renewedAt) >= c.Options.SessionRefreshInterval {
+		if err := c.renewSession(ctx); err != nil {
+			return err
+		}
+	}
+
+	st, err := c.ServiceClient.CurrentState(ctx, &empty.Empty{})
+	if status.Code(err) == codes.Unauthenticated {
+		if err := c.renewSession(ctx); err != nil {
+			return err
+		}
+		st, err = c.ServiceClient.CurrentState(ctx, &empty.Empty{})
+	}
+	if err != nil {
+		return err
+	}
+
+	if restarted || c.StateService == nil {
+		return nil
+	}
+
+	if database == "" {
+		database = DefaultDB
+	}
+
+	if err := c.StateService.CacheLock(); err != nil {
+		return err
+	}
+	defer c.StateService.CacheUnlock()
+
+	pinned, err := c.StateService.GetState(ctx, database)
+	if err != nil {
+		return err
+	}
+
+	regressed := st.TxId < pinned.TxId
+
+	if !regressed && c.Options.AcceptRewindAttestations && pinned.TxId > 0 {
+		cmp, err := c.ServiceClient.CompareState(ctx, &schema.StateComparisonRequest{State: pinned})
+		if err != nil {
+			return err
+		}
+
+		regressed = cmp.Relation == schema.StateRelation_STATE_FORKED
+	}
+
+	if !regressed {
+		return nil
+	}
+
+	if c.Options.AcceptRewindAttestations {
+		attestation, err := c.acceptRewind(ctx, database, pinned)
+		if err == nil {
+			c.notifyHeartbeat(&HeartbeatEvent{
+				Kind:        HeartbeatStateRewound,
+				ServerUUID:  serverUUID,
+				Database:    database,
+				State:       st,
+				PinnedState: pinned,
+				Attestation: attestation,
+			})
+
+			return nil
+		}
+
+		c.Logger.Warningf("heartbeat: rewind of database %s not accepted: %v", database, err)
+	}
+
+	c.notifyHeartbeat(&HeartbeatEvent{
+		Kind:        HeartbeatStateRegression,
+		ServerUUID:  serverUUID,
+		Database:    database,
+		State:       st,
+		PinnedState: pinned,
+	})
+
+	return nil
+}
+
+// renewSession logs in again with the credentials of the session and selects the database in use
+func (c *immuClient) renewSession(ctx context.Context) error {
+	c.session.Lock()
+	user, pass, database := c.session.username, c.session.password, c.session.database
+	c.session.Unlock()
+
+	lr, err := c.ServiceClient.Login(ctx, &schema.LoginRequest{User: user, Password: pass})
+	if err != nil {
+		return err
+	}
+
+	token := lr.Token
+
+	if database != "" {
+		dbCtx := metadata.NewOutgoingContext(ctx, metadata.Pairs("authorization", token))
+
+		ur, err := c.ServiceClient.UseDatabase(dbCtx, &schema.Database{DatabaseName: database})
+		if err != nil {
+			return err
+		}
+
+		token = ur.Token
+	}
+
+	c.session.Lock()
+	c.session.token = token
+	c.session.renewedAt = time.Now()
+	c.session.Unlock()
+
+	c.notifyHeartbeat(&HeartbeatEvent{
+		Kind:     HeartbeatSessionRenewed,
+		Token:    token,
+		Database: database,
+	})
+
+	return nil
+}
+
+func (c *immuClient) notifyHeartbeat(e *HeartbeatEvent) {
+	if c.Options.HeartbeatHandler != nil {
+		c.Options.HeartbeatHandler(e)
+	}
+}
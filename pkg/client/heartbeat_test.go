@@ -0,0 +1,130 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/server"
+	"github.com/codenotary/immudb/pkg/server/servertest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+func TestImmuClient_Heartbeat(t *testing.T) {
+	options := server.DefaultOptions().WithAuth(true)
+	bs := servertest.NewBufconnServer(options)
+
+	defer os.RemoveAll(options.Dir)
+
+	stateFileDir, err := ioutil.TempDir("", "heartbeat_state")
+	require.NoError(t, err)
+	defer os.RemoveAll(stateFileDir)
+
+	bs.Start()
+	defer bs.Stop()
+
+	events := make(chan *HeartbeatEvent, 10)
+
+	ts := NewTokenService().WithTokenFileName("testTokenFile").WithHds(DefaultHomedirServiceMock())
+	opts := DefaultOptions().
+		WithDialOptions(&[]grpc.DialOption{grpc.WithContextDialer(bs.Dialer), grpc.WithInsecure()}).
+		WithTokenService(ts).
+		WithDir(stateFileDir).
+		WithHeartbeatInterval(time.Hour).
+		WithHeartbeatHandler(func(e *HeartbeatEvent) { events <- e })
+
+	cli, err := NewImmuClient(opts)
+	require.NoError(t, err)
+	defer cli.Disconnect()
+
+	c := cli.(*immuClient)
+
+	lr, err := c.Login(context.TODO(), []byte(`immudb`), []byte(`immudb`))
+	require.NoError(t, err)
+	require.Equal(t, lr.Token, c.sessionToken())
+
+	// calls without authorization metadata use the session token
+	_, err = c.Set(context.Background(), []byte("key"), []byte("value"))
+	require.NoError(t, err)
+
+	err = c.heartbeat(context.Background())
+	require.NoError(t, err)
+	require.Len(t, events, 0)
+
+	c.Options.WithSessionRefreshInterval(0)
+
+	err = c.heartbeat(context.Background())
+	require.NoError(t, err)
+
+	e := <-events
+	require.Equal(t, HeartbeatSessionRenewed, e.Kind)
+	require.NotEmpty(t, e.Token)
+	require.Equal(t, e.Token, c.sessionToken())
+
+	c.Options.WithSessionRefreshInterval(time.Hour)
+
+	serverUUID := "current"
+	c.session.serverUUID = "previous"
+	c.session.uuidProvider = &fixedUUIDProvider{uuid: serverUUID}
+
+	err = c.heartbeat(context.Background())
+	require.NoError(t, err)
+
+	e = <-events
+	require.Equal(t, HeartbeatServerRestarted, e.Kind)
+	require.Equal(t, "previous", e.PrevServerUUID)
+	require.Equal(t, serverUUID, e.ServerUUID)
+
+	e = <-events
+	require.Equal(t, HeartbeatSessionRenewed, e.Kind)
+
+	err = c.StateService.CacheLock()
+	require.NoError(t, err)
+
+	err = c.StateService.SetState(DefaultDB, &schema.ImmutableState{Db: DefaultDB, TxId: 1000})
+	require.NoError(t, err)
+
+	err = c.StateService.CacheUnlock()
+	require.NoError(t, err)
+
+	err = c.heartbeat(context.Background())
+	require.NoError(t, err)
+
+	e = <-events
+	require.Equal(t, HeartbeatStateRegression, e.Kind)
+	require.Equal(t, DefaultDB, e.Database)
+	require.Equal(t, uint64(1000), e.PinnedState.TxId)
+	require.Less(t, e.State.TxId, e.PinnedState.TxId)
+
+	err = c.Logout(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, c.sessionToken())
+}
+
+type fixedUUIDProvider struct {
+	uuid string
+}
+
+func (p *fixedUUIDProvider) CurrentUUID(ctx context.Context) (string, error) {
+	return p.uuid, nil
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+)
+
+type idempotencyKeyCtxKey struct{}
+
+// WithIdempotencyKey returns a context whose writes made with Set, VerifiedSet, SetAll and ExecAll are sent with the
+// given idempotency key. Retrying a write with the same key, within the idempotency window of the server, returns the
+// original transaction instead of committing a new one. Keys are scoped by the server to the user and the database
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyCtxKey{}, key)
+}
+
+// idempotencyKey returns the idempotency key set with WithIdempotencyKey, if any
+func idempotencyKey(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKeyCtxKey{}).(string)
+	return key
+}
+
+// idempotencyEntries is the number of entries the server may add to a transaction to record its idempotency key
+func idempotencyEntries(key string) int {
+	if key == "" {
+		return 0
+	}
+	return 1
+}
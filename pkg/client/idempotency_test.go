@@ -0,0 +1,125 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/auth"
+	"github.com/codenotary/immudb/pkg/server"
+	"github.com/codenotary/immudb/pkg/server/servertest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestImmuClient_IdempotencyKey(t *testing.T) {
+	options := server.DefaultOptions().WithAuth(true).WithAttribution(true).WithIdempotencyPersisted(true)
+	bs := servertest.NewBufconnServer(options)
+
+	defer os.RemoveAll(options.Dir)
+	defer os.Remove(".state-")
+
+	bs.Start()
+	defer bs.Stop()
+
+	ts := NewTokenService().WithTokenFileName("testTokenFile").WithHds(DefaultHomedirServiceMock())
+	client, err := NewImmuClient(DefaultOptions().WithDialOptions(&[]grpc.DialOption{grpc.WithContextDialer(bs.Dialer), grpc.WithInsecure()}).WithTokenService(ts))
+	require.NoError(t, err)
+	defer client.Disconnect()
+
+	lr, err := client.Login(context.TODO(), []byte(`immudb`), []byte(`immudb`))
+	require.NoError(t, err)
+
+	ctx := metadata.NewOutgoingContext(context.Background(), metadata.Pairs("authorization", lr.Token))
+
+	_, err = client.CreateSecondaryIndex(ctx, &schema.SecondaryIndex{Name: "email", Prefix: []byte(`user:`), JsonPath: "email"})
+	require.NoError(t, err)
+
+	// the entry recording the idempotency key is accepted along with the attribution and secondary index ones
+	verifiedCtx := WithIdempotencyKey(ctx, "verified")
+
+	txmd, err := client.VerifiedSet(verifiedCtx, []byte(`user:1`), []byte(`{"email":"a@example.com"}`))
+	require.NoError(t, err)
+	require.Equal(t, int32(4), txmd.Nentries)
+
+	retried, err := client.VerifiedSet(verifiedCtx, []byte(`user:1`), []byte(`{"email":"a@example.com"}`))
+	require.NoError(t, err)
+	require.Equal(t, txmd.Id, retried.Id)
+
+	setCtx := WithIdempotencyKey(ctx, "set")
+
+	txmd, err = client.Set(setCtx, []byte(`user:2`), []byte(`{"email":"b@example.com"}`))
+	require.NoError(t, err)
+
+	retried, err = client.Set(setCtx, []byte(`user:2`), []byte(`{"email":"b@example.com"}`))
+	require.NoError(t, err)
+	require.Equal(t, txmd.Id, retried.Id)
+
+	txmd, err = client.SetAll(WithIdempotencyKey(ctx, "setAll"), &schema.SetRequest{KVs: []*schema.KeyValue{
+		{Key: []byte(`user:3`), Value: []byte(`{"email":"c@example.com"}`)},
+		{Key: []byte(`user:4`), Value: []byte(`{"email":"d@example.com"}`)},
+	}})
+	require.NoError(t, err)
+
+	retried, err = client.SetAll(ctx, &schema.SetRequest{
+		KVs: []*schema.KeyValue{
+			{Key: []byte(`user:3`), Value: []byte(`{"email":"c@example.com"}`)},
+			{Key: []byte(`user:4`), Value: []byte(`{"email":"d@example.com"}`)},
+		},
+		IdempotencyKey: "setAll",
+	})
+	require.NoError(t, err)
+	require.Equal(t, txmd.Id, retried.Id)
+
+	execAll := func(ctx context.Context) (*schema.TxMetadata, error) {
+		return client.ExecAll(ctx, &schema.ExecAllRequest{Operations: []*schema.Op{
+			{Operation: &schema.Op_Kv{Kv: &schema.KeyValue{Key: []byte(`user:5`), Value: []byte(`{"email":"e@example.com"}`)}}},
+		}})
+	}
+
+	txmd, err = execAll(WithIdempotencyKey(ctx, "execAll"))
+	require.NoError(t, err)
+
+	retried, err = execAll(WithIdempotencyKey(ctx, "execAll"))
+	require.NoError(t, err)
+	require.Equal(t, txmd.Id, retried.Id)
+
+	// keys are scoped to the user, the same key sent by another user is a different write
+	err = client.CreateUser(ctx, []byte("other"), []byte("1Password!*"), auth.PermissionRW, server.DefaultdbName)
+	require.NoError(t, err)
+
+	lr, err = client.Login(context.TODO(), []byte("other"), []byte("1Password!*"))
+	require.NoError(t, err)
+
+	otherCtx := metadata.NewOutgoingContext(context.Background(), metadata.Pairs("authorization", lr.Token))
+
+	db, err := client.UseDatabase(otherCtx, &schema.Database{DatabaseName: server.DefaultdbName})
+	require.NoError(t, err)
+
+	otherCtx = metadata.NewOutgoingContext(context.Background(), metadata.Pairs("authorization", db.Token))
+
+	txmd, err = client.Set(WithIdempotencyKey(ctx, "shared"), []byte(`user:6`), []byte(`{"email":"f@example.com"}`))
+	require.NoError(t, err)
+
+	other, err := client.Set(WithIdempotencyKey(otherCtx, "shared"), []byte(`user:6`), []byte(`{"email":"f@example.com"}`))
+	require.NoError(t, err)
+	require.NotEqual(t, txmd.Id, other.Id)
+}
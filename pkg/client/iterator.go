@@ -0,0 +1,177 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package client
+
+import (
+	"context"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// OpenIterator opens a long-running iterator, the returned lease must be kept alive by sending requests within its timeout
+func (c *immuClient) OpenIterator(ctx context.Context, req *schema.OpenIteratorRequest) (*schema.IteratorLease, error) {
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+	return c.ServiceClient.OpenIterator(ctx, req)
+}
+
+func (c *immuClient) IteratorNext(ctx context.Context, leaseID string, limit uint64) (*schema.IteratorPage, error) {
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+	return c.ServiceClient.IteratorNext(ctx, &schema.IteratorNextRequest{LeaseId: leaseID, Limit: limit})
+}
+
+func (c *immuClient) KeepAliveIterator(ctx context.Context, leaseID string) (*schema.IteratorLease, error) {
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+	return c.ServiceClient.KeepAliveIterator(ctx, &schema.IteratorLeaseRequest{LeaseId: leaseID})
+}
+
+func (c *immuClient) CloseIterator(ctx context.Context, leaseID string) error {
+	if !c.IsConnected() {
+		return ErrNotConnected
+	}
+	_, err := c.ServiceClient.CloseIterator(ctx, &schema.IteratorLeaseRequest{LeaseId: leaseID})
+	return err
+}
+
+// Iterator reads the entries of a leased iterator in pages.
+// When the lease expires or is invalidated by an index compaction, a new one is transparently opened from the latest
+// resume token, so the iteration continues over a newer snapshot
+type Iterator struct {
+	client ImmuClient
+	req    *schema.OpenIteratorRequest
+	lease  *schema.IteratorLease
+
+	resumeToken []byte
+	done        bool
+}
+
+// NewIterator opens a leased iterator over the entries matching req
+func (c *immuClient) NewIterator(ctx context.Context, req *schema.OpenIteratorRequest) (*Iterator, error) {
+	if req == nil {
+		return nil, ErrIllegalArguments
+	}
+
+	lease, err := c.OpenIterator(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Iterator{
+		client:      c,
+		req:         req,
+		lease:       lease,
+		resumeToken: req.ResumeToken,
+	}, nil
+}
+
+// Lease returns the lease currently used by the iterator
+func (it *Iterator) Lease() *schema.IteratorLease {
+	return it.lease
+}
+
+// ResumeToken returns the token from which the iteration can be resumed
+func (it *Iterator) ResumeToken() []byte {
+	return it.resumeToken
+}
+
+// Done returns true once all the entries were read
+func (it *Iterator) Done() bool {
+	return it.done
+}
+
+// Next returns up to limit entries, an empty page is returned once the iteration is done
+func (it *Iterator) Next(ctx context.Context, limit uint64) ([]*schema.Entry, error) {
+	if it.done {
+		return nil, nil
+	}
+
+	page, err := it.client.IteratorNext(ctx, it.lease.LeaseId, limit)
+	if isReleasedLease(err) {
+		err = it.reopen(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		page, err = it.client.IteratorNext(ctx, it.lease.LeaseId, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(page.ResumeToken) > 0 {
+		it.resumeToken = page.ResumeToken
+	}
+	it.done = page.Done
+
+	return page.Entries, nil
+}
+
+// KeepAlive refreshes the lease, it should be called when no page is read within the lease timeout
+func (it *Iterator) KeepAlive(ctx context.Context) error {
+	lease, err := it.client.KeepAliveIterator(ctx, it.lease.LeaseId)
+	if isReleasedLease(err) {
+		return it.reopen(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	it.lease = lease
+
+	return nil
+}
+
+// Close releases the lease
+func (it *Iterator) Close(ctx context.Context) error {
+	return it.client.CloseIterator(ctx, it.lease.LeaseId)
+}
+
+func (it *Iterator) reopen(ctx context.Context) error {
+	// the resumed iteration reads from the latest state
+	req := &schema.OpenIteratorRequest{
+		Prefix:      it.req.Prefix,
+		SeekKey:     it.req.SeekKey,
+		Desc:        it.req.Desc,
+		NoWait:      it.req.NoWait,
+		ResumeToken: it.req.ResumeToken,
+	}
+
+	if len(it.resumeToken) > 0 {
+		req.SeekKey = nil
+		req.ResumeToken = it.resumeToken
+	}
+
+	lease, err := it.client.OpenIterator(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	it.lease = lease
+
+	return nil
+}
+
+func isReleasedLease(err error) bool {
+	code := status.Code(err)
+	return code == codes.DeadlineExceeded || code == codes.Aborted
+}
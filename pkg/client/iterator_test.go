@@ -0,0 +1,118 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package client
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/server"
+	"github.com/codenotary/immudb/pkg/server/servertest"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestImmuClient_Iterator(t *testing.T) {
+	options := server.DefaultOptions().WithAuth(true)
+	options.StoreOptions.WithIndexOptions(options.StoreOptions.IndexOpts.WithCompactionThld(0))
+	bs := servertest.NewBufconnServer(options)
+
+	defer os.RemoveAll(options.Dir)
+	defer os.Remove(".state-")
+
+	bs.Start()
+	defer bs.Stop()
+
+	ts := NewTokenService().WithTokenFileName("testTokenFile").WithHds(DefaultHomedirServiceMock())
+	client, err := NewImmuClient(DefaultOptions().WithDialOptions(&[]grpc.DialOption{grpc.WithContextDialer(bs.Dialer), grpc.WithInsecure()}).WithTokenService(ts))
+	require.NoError(t, err)
+	defer client.Disconnect()
+
+	lr, err := client.Login(context.TODO(), []byte(`immudb`), []byte(`immudb`))
+	require.NoError(t, err)
+
+	md := metadata.Pairs("authorization", lr.Token)
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	for i := 0; i < 10; i++ {
+		_, err := client.Set(ctx, []byte(fmt.Sprintf("key%d", i)), []byte{byte(i)})
+		require.NoError(t, err)
+	}
+
+	_, err = client.NewIterator(ctx, nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	it, err := client.NewIterator(ctx, &schema.OpenIteratorRequest{Prefix: []byte("key")})
+	require.NoError(t, err)
+	require.NotEmpty(t, it.Lease().LeaseId)
+
+	entries, err := it.Next(ctx, 3)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	require.Equal(t, []byte("key2"), it.ResumeToken())
+
+	err = it.KeepAlive(ctx)
+	require.NoError(t, err)
+
+	leaseID := it.Lease().LeaseId
+
+	// compacting the index invalidates the lease, the iteration is resumed from a new one
+	err = client.CleanIndex(ctx, &empty.Empty{})
+	require.NoError(t, err)
+
+	entries, err = it.Next(ctx, 3)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	require.Equal(t, []byte("key3"), entries[0].Key)
+	require.NotEqual(t, leaseID, it.Lease().LeaseId)
+
+	entries, err = it.Next(ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+	require.True(t, it.Done())
+
+	entries, err = it.Next(ctx, 0)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	err = it.Close(ctx)
+	require.NoError(t, err)
+
+	_, err = client.IteratorNext(ctx, it.Lease().LeaseId, 0)
+	require.Error(t, err)
+
+	_, err = client.KeepAliveIterator(ctx, it.Lease().LeaseId)
+	require.Error(t, err)
+
+	client.Disconnect()
+
+	_, err = client.OpenIterator(ctx, &schema.OpenIteratorRequest{})
+	require.Equal(t, ErrNotConnected, err)
+
+	_, err = client.IteratorNext(ctx, leaseID, 0)
+	require.Equal(t, ErrNotConnected, err)
+
+	_, err = client.KeepAliveIterator(ctx, leaseID)
+	require.Equal(t, ErrNotConnected, err)
+
+	err = client.CloseIterator(ctx, leaseID)
+	require.Equal(t, ErrNotConnected, err)
+}
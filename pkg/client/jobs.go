@@ -0,0 +1,125 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package client
+
+import (
+	"context"
+	"io"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// ScheduleJob creates or replaces a job schedule
+func (c *immuClient) ScheduleJob(ctx context.Context, js *schema.JobSchedule) error {
+	if !c.IsConnected() {
+		return ErrNotConnected
+	}
+
+	_, err := c.ServiceClient.ScheduleJob(ctx, js)
+	return err
+}
+
+// UnscheduleJob removes a job schedule given its name
+func (c *immuClient) UnscheduleJob(ctx context.Context, name string) error {
+	if !c.IsConnected() {
+		return ErrNotConnected
+	}
+
+	_, err := c.ServiceClient.UnscheduleJob(ctx, &schema.JobScheduleRequest{Name: name})
+	return err
+}
+
+// ListJobSchedules returns the jobs scheduled on the server
+func (c *immuClient) ListJobSchedules(ctx context.Context) (*schema.JobScheduleList, error) {
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	return c.ServiceClient.ListJobSchedules(ctx, &emptypb.Empty{})
+}
+
+// ListJobRuns returns up to limit of the most recent runs of a scheduled job, newest first
+func (c *immuClient) ListJobRuns(ctx context.Context, name string, limit uint32) (*schema.JobRunList, error) {
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	return c.ServiceClient.ListJobRuns(ctx, &schema.JobRunsRequest{Name: name, Limit: limit})
+}
+
+// ListJobs returns the jobs running on the server and the most recently finished ones
+func (c *immuClient) ListJobs(ctx context.Context) (*schema.JobList, error) {
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	return c.ServiceClient.ListJobs(ctx, &emptypb.Empty{})
+}
+
+// GetJob returns the state and progress of a job given its id
+func (c *immuClient) GetJob(ctx context.Context, id string) (*schema.Job, error) {
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	return c.ServiceClient.GetJob(ctx, &schema.JobRequest{Id: id})
+}
+
+// CancelJob cancels a running job given its id
+func (c *immuClient) CancelJob(ctx context.Context, id string) error {
+	if !c.IsConnected() {
+		return ErrNotConnected
+	}
+
+	_, err := c.ServiceClient.CancelJob(ctx, &schema.JobRequest{Id: id})
+	return err
+}
+
+// WatchJob calls onUpdate every time the state or progress of a job changes, until the job is finished, ctx is done
+// or onUpdate returns an error
+func (c *immuClient) WatchJob(ctx context.Context, id string, onUpdate func(*schema.Job) error) error {
+	if onUpdate == nil {
+		return ErrIllegalArguments
+	}
+
+	if !c.IsConnected() {
+		return ErrNotConnected
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	ws, err := c.ServiceClient.WatchJob(ctx, &schema.JobRequest{Id: id})
+	if err != nil {
+		return err
+	}
+
+	for {
+		jb, err := ws.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		err = onUpdate(jb)
+		if err != nil {
+			return err
+		}
+	}
+}
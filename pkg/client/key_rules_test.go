@@ -0,0 +1,75 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package client
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/server"
+	"github.com/codenotary/immudb/pkg/server/servertest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestImmuClient_KeyRules(t *testing.T) {
+	options := server.DefaultOptions().WithAuth(true)
+	bs := servertest.NewBufconnServer(options)
+
+	defer os.RemoveAll(options.Dir)
+	defer os.Remove(".state-")
+
+	bs.Start()
+	defer bs.Stop()
+
+	ts := NewTokenService().WithTokenFileName("testTokenFile").WithHds(DefaultHomedirServiceMock())
+	client, err := NewImmuClient(DefaultOptions().WithDialOptions(&[]grpc.DialOption{grpc.WithContextDialer(bs.Dialer), grpc.WithInsecure()}).WithTokenService(ts))
+	require.NoError(t, err)
+	defer client.Disconnect()
+
+	lr, err := client.Login(context.TODO(), []byte(`immudb`), []byte(`immudb`))
+	require.NoError(t, err)
+
+	md := metadata.Pairs("authorization", lr.Token)
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	err = client.SetKeyRules(ctx, &schema.KeyRules{Pattern: "[a-z]+", Separator: "/"})
+	require.NoError(t, err)
+
+	rules, err := client.GetKeyRules(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "[a-z]+", rules.Pattern)
+	require.Equal(t, "/", rules.Separator)
+
+	_, err = client.Set(ctx, []byte("key"), []byte("value"))
+	require.NoError(t, err)
+
+	_, err = client.Set(ctx, []byte("Key1"), []byte("value"))
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	client.Disconnect()
+
+	err = client.SetKeyRules(ctx, &schema.KeyRules{})
+	require.Equal(t, ErrNotConnected, err)
+
+	_, err = client.GetKeyRules(ctx)
+	require.Equal(t, ErrNotConnected, err)
+}
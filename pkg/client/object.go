@@ -0,0 +1,83 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"time"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+)
+
+// PutObject writes a new version of an object, whose version id is the transaction writing it
+func (c *immuClient) PutObject(ctx context.Context, req *schema.PutObjectRequest) (*schema.ObjectVersion, error) {
+	start := time.Now()
+
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	result, err := c.ServiceClient.PutObject(ctx, req)
+
+	c.Logger.Debugf("PutObject finished in %s", time.Since(start))
+
+	return result, err
+}
+
+// GetObject returns a version of an object, the latest one when versionID is zero. The content is checked against
+// the etag of the version, store.ErrCorruptedData is returned if they don't match
+func (c *immuClient) GetObject(ctx context.Context, key []byte, versionID uint64) (*schema.Object, error) {
+	start := time.Now()
+
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	obj, err := c.ServiceClient.GetObject(ctx, &schema.GetObjectRequest{Key: key, VersionId: versionID})
+
+	c.Logger.Debugf("GetObject finished in %s", time.Since(start))
+
+	if err != nil {
+		return nil, err
+	}
+
+	etag := sha256.Sum256(obj.Content)
+
+	if obj.Version == nil || !bytes.Equal(obj.Version.Etag, etag[:]) || obj.Version.Size != uint64(len(obj.Content)) {
+		return nil, store.ErrCorruptedData
+	}
+
+	return obj, nil
+}
+
+// ListObjectVersions lists the versions of the objects under req.Prefix, ordered by key and newest version first
+func (c *immuClient) ListObjectVersions(ctx context.Context, req *schema.ListObjectVersionsRequest) (*schema.ObjectVersions, error) {
+	start := time.Now()
+
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	result, err := c.ServiceClient.ListObjectVersions(ctx, req)
+
+	c.Logger.Debugf("ListObjectVersions finished in %s", time.Since(start))
+
+	return result, err
+}
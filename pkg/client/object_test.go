@@ -0,0 +1,82 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/server"
+	"github.com/codenotary/immudb/pkg/server/servertest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestImmuClient_Objects(t *testing.T) {
+	options := server.DefaultOptions().WithAuth(true)
+	bs := servertest.NewBufconnServer(options)
+
+	defer os.RemoveAll(options.Dir)
+	defer os.Remove(".state-")
+
+	bs.Start()
+	defer bs.Stop()
+
+	ts := NewTokenService().WithTokenFileName("testTokenFile").WithHds(DefaultHomedirServiceMock())
+	client, err := NewImmuClient(DefaultOptions().WithDialOptions(&[]grpc.DialOption{grpc.WithContextDialer(bs.Dialer), grpc.WithInsecure()}).WithTokenService(ts))
+	require.NoError(t, err)
+	defer client.Disconnect()
+
+	lr, err := client.Login(context.TODO(), []byte(`immudb`), []byte(`immudb`))
+	require.NoError(t, err)
+
+	ctx := metadata.NewOutgoingContext(context.Background(), metadata.Pairs("authorization", lr.Token))
+
+	v1, err := client.PutObject(ctx, &schema.PutObjectRequest{
+		Key:         []byte(`reports/2021.csv`),
+		Content:     []byte(`a,b`),
+		ContentType: "text/csv",
+		Metadata:    map[string]string{"department": "sales"},
+	})
+	require.NoError(t, err)
+
+	v2, err := client.PutObject(ctx, &schema.PutObjectRequest{Key: []byte(`reports/2021.csv`), Content: []byte(`a,b,c`)})
+	require.NoError(t, err)
+
+	obj, err := client.GetObject(ctx, []byte(`reports/2021.csv`), 0)
+	require.NoError(t, err)
+	require.Equal(t, []byte(`a,b,c`), obj.Content)
+	require.Equal(t, v2.VersionId, obj.Version.VersionId)
+
+	obj, err = client.GetObject(ctx, []byte(`reports/2021.csv`), v1.VersionId)
+	require.NoError(t, err)
+	require.Equal(t, []byte(`a,b`), obj.Content)
+	require.Equal(t, "text/csv", obj.Version.ContentType)
+	require.Equal(t, "sales", obj.Version.Metadata["department"])
+
+	_, err = client.GetObject(ctx, []byte(`reports/2020.csv`), 0)
+	require.Error(t, err)
+
+	list, err := client.ListObjectVersions(ctx, &schema.ListObjectVersionsRequest{Prefix: []byte(`reports/`)})
+	require.NoError(t, err)
+	require.Len(t, list.Versions, 2)
+	require.Equal(t, v2.VersionId, list.Versions[0].VersionId)
+	require.Equal(t, v1.VersionId, list.Versions[1].VersionId)
+}
@@ -17,9 +17,11 @@ limitations under the License.
 package client
 
 import (
+	"bytes"
 	"encoding/json"
 	"github.com/codenotary/immudb/pkg/stream"
 	"strconv"
+	"time"
 
 	c "github.com/codenotary/immudb/cmd/helper"
 	"google.golang.org/grpc"
@@ -28,6 +30,9 @@ import (
 // AdminTokenFileSuffix is the suffix used for the token file name
 const AdminTokenFileSuffix = "_admin"
 
+// DefaultSessionRefreshInterval is how often the heartbeat logs in again to renew the session token
+const DefaultSessionRefreshInterval = time.Hour
+
 // Options client options
 type Options struct {
 	Dir                string
@@ -56,6 +61,18 @@ type Options struct {
 	LogFileName         string
 	ServerSigningPubKey string
 	StreamChunkSize     int
+	EntryCacheSize      int
+	Actor               string
+
+	EntryCacheReverifyInterval time.Duration
+
+	HeartbeatInterval      time.Duration
+	SessionRefreshInterval time.Duration
+	HeartbeatHandler       func(*HeartbeatEvent) `json:"-"`
+
+	CollectionCodecs map[string]string
+
+	AcceptRewindAttestations bool
 }
 
 // DefaultOptions ...
@@ -80,6 +97,8 @@ func DefaultOptions() *Options {
 		LogFileName:         "",
 		ServerSigningPubKey: "",
 		StreamChunkSize:     stream.DefaultChunkSize,
+
+		SessionRefreshInterval: DefaultSessionRefreshInterval,
 	}
 }
 
@@ -228,6 +247,80 @@ func (o *Options) WithStreamChunkSize(streamChunkSize int) *Options {
 	return o
 }
 
+// WithEntryCacheSize sets the max amount of entries kept in the local entry cache. Zero disables the cache
+func (o *Options) WithEntryCacheSize(entryCacheSize int) *Options {
+	o.EntryCacheSize = entryCacheSize
+	return o
+}
+
+// WithEntryCacheReverifyInterval enables the periodic verification of the verified entries kept in the local entry
+// cache against the latest state of the server, entries no longer verifying are evicted. Zero disables it
+func (o *Options) WithEntryCacheReverifyInterval(interval time.Duration) *Options {
+	o.EntryCacheReverifyInterval = interval
+	return o
+}
+
+// WithActor sets the actor identity recorded along with the writes of this client, when the server has attribution enabled
+func (o *Options) WithActor(actor string) *Options {
+	o.Actor = actor
+	return o
+}
+
+// WithHeartbeatInterval enables the background heartbeat started on login, which renews the session token and
+// detects server restarts and state regressions. Zero disables it
+func (o *Options) WithHeartbeatInterval(interval time.Duration) *Options {
+	o.HeartbeatInterval = interval
+	return o
+}
+
+// WithSessionRefreshInterval sets how often the heartbeat logs in again to renew the session token
+func (o *Options) WithSessionRefreshInterval(interval time.Duration) *Options {
+	o.SessionRefreshInterval = interval
+	return o
+}
+
+// WithHeartbeatHandler sets the function notified of the events detected by the heartbeat
+func (o *Options) WithHeartbeatHandler(handler func(*HeartbeatEvent)) *Options {
+	o.HeartbeatHandler = handler
+	return o
+}
+
+// WithAcceptRewindAttestations sets whether the heartbeat moves the pinned state to the one sanctioned by a signed
+// rewind attestation, when the server history no longer reaches it. The server signing public key must be set
+func (o *Options) WithAcceptRewindAttestations(accept bool) *Options {
+	o.AcceptRewindAttestations = accept
+	return o
+}
+
+// WithCollectionCodec sets the name of the codec used for the values of the keys starting with prefix.
+// When several prefixes match a key, the longest one is used
+func (o *Options) WithCollectionCodec(prefix string, codecName string) *Options {
+	if o.CollectionCodecs == nil {
+		o.CollectionCodecs = make(map[string]string)
+	}
+	o.CollectionCodecs[prefix] = codecName
+	return o
+}
+
+// CodecFor returns the codec set for the collection key belongs to, or nil if there is none
+func (o *Options) CodecFor(key []byte) (Codec, error) {
+	var prefix string
+	var found bool
+
+	for p := range o.CollectionCodecs {
+		if bytes.HasPrefix(key, []byte(p)) && (!found || len(p) > len(prefix)) {
+			prefix = p
+			found = true
+		}
+	}
+
+	if !found {
+		return nil, nil
+	}
+
+	return GetCodec(o.CollectionCodecs[prefix])
+}
+
 func (o *Options) String() string {
 	optionsJSON, err := json.Marshal(o)
 	if err != nil {
@@ -18,6 +18,7 @@ package client
 
 import (
 	"testing"
+	"time"
 )
 
 func TestOptions(t *testing.T) {
@@ -45,7 +46,10 @@ func TestOptions(t *testing.T) {
 		WithUsername("some-username").
 		WithPassword("some-password").
 		WithDatabase("some-db").
-		WithStreamChunkSize(4096)
+		WithStreamChunkSize(4096).
+		WithHeartbeatInterval(time.Minute).
+		WithSessionRefreshInterval(time.Hour).
+		WithHeartbeatHandler(func(*HeartbeatEvent) {})
 
 	if op.LogFileName != "logfilename" ||
 		op.PrometheusHost != "localhost" ||
@@ -69,6 +73,9 @@ func TestOptions(t *testing.T) {
 		op.Password != "some-password" ||
 		op.Database != "some-db" ||
 		op.StreamChunkSize != 4096 ||
+		op.HeartbeatInterval != time.Minute ||
+		op.SessionRefreshInterval != time.Hour ||
+		op.HeartbeatHandler == nil ||
 		op.Bind() != "127.0.0.1:4321" ||
 		len(op.String()) == 0 {
 		t.Fatal("Client options fail")
@@ -0,0 +1,38 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// Priority classes of calls, as honored by the server admission control
+const (
+	PriorityInteractive = "interactive"
+	PriorityBatch       = "batch"
+)
+
+// priorityMetadataKey is the metadata the priority class is sent along with calls, as expected by the server
+const priorityMetadataKey = "priority"
+
+// WithPriority returns a context whose calls are sent with the given priority class. Once the server is under load,
+// interactive calls are served ahead of batch ones, and batch calls are given a stricter timeout
+func WithPriority(ctx context.Context, priority string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, priorityMetadataKey, priority)
+}
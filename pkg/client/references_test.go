@@ -0,0 +1,90 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package client
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/server"
+	"github.com/codenotary/immudb/pkg/server/servertest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestImmuClient_ListReferences(t *testing.T) {
+	options := server.DefaultOptions().WithAuth(true)
+	bs := servertest.NewBufconnServer(options)
+
+	defer os.RemoveAll(options.Dir)
+	defer os.Remove(".state-")
+
+	bs.Start()
+	defer bs.Stop()
+
+	ts := NewTokenService().WithTokenFileName("testTokenFile").WithHds(DefaultHomedirServiceMock())
+	client, err := NewImmuClient(DefaultOptions().WithDialOptions(&[]grpc.DialOption{grpc.WithContextDialer(bs.Dialer), grpc.WithInsecure()}).WithTokenService(ts))
+	require.NoError(t, err)
+	defer client.Disconnect()
+
+	lr, err := client.Login(context.TODO(), []byte(`immudb`), []byte(`immudb`))
+	require.NoError(t, err)
+
+	ctx := metadata.NewOutgoingContext(context.Background(), metadata.Pairs("authorization", lr.Token))
+
+	_, err = client.Set(ctx, []byte(`invoice:1`), []byte(`paid`))
+	require.NoError(t, err)
+
+	// references and sorted set entries are written along with their index entries and can still be verified
+	_, err = client.VerifiedSetReference(ctx, []byte(`latest`), []byte(`invoice:1`))
+	require.NoError(t, err)
+
+	_, err = client.VerifiedZAdd(ctx, []byte(`invoices`), 1, []byte(`invoice:1`))
+	require.NoError(t, err)
+
+	_, err = client.ExecAll(ctx, &schema.ExecAllRequest{Operations: []*schema.Op{
+		{Operation: &schema.Op_Kv{Kv: &schema.KeyValue{Key: []byte(`invoice:2`), Value: []byte(`due`)}}},
+		{Operation: &schema.Op_Ref{Ref: &schema.ReferenceRequest{Key: []byte(`previous`), ReferencedKey: []byte(`invoice:1`)}}},
+	}})
+	require.NoError(t, err)
+
+	list, err := client.ListReferences(ctx, &schema.ListReferencesRequest{Key: []byte(`invoice:1`)})
+	require.NoError(t, err)
+	require.Len(t, list.References, 2)
+	require.Equal(t, []byte(`latest`), list.References[0].Key)
+	require.Equal(t, []byte(`previous`), list.References[1].Key)
+	require.Len(t, list.ZEntries, 1)
+	require.Equal(t, []byte(`invoices`), list.ZEntries[0].Set)
+	require.Equal(t, float64(1), list.ZEntries[0].Score)
+
+	_, err = client.ListReferences(ctx, &schema.ListReferencesRequest{})
+	require.Error(t, err)
+}
+
+func TestIsExpectedIndexedTxLen(t *testing.T) {
+	// servers preceding the reference index don't write index entries
+	require.True(t, isExpectedIndexedTxLen(&schema.TxMetadata{Nentries: 1}, 1, 1))
+	require.True(t, isExpectedIndexedTxLen(&schema.TxMetadata{Nentries: 2}, 1, 1))
+	require.True(t, isExpectedIndexedTxLen(&schema.TxMetadata{Nentries: 3}, 1, 1))
+	require.False(t, isExpectedIndexedTxLen(&schema.TxMetadata{Nentries: 4}, 1, 1))
+	require.False(t, isExpectedIndexedTxLen(&schema.TxMetadata{Nentries: 1}, 2, 1))
+
+	require.True(t, isExpectedIndexedTxLen(&schema.TxMetadata{Nentries: 2}, 2, 0))
+	require.False(t, isExpectedIndexedTxLen(&schema.TxMetadata{Nentries: 4}, 2, 0))
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/golang/protobuf/ptypes/empty"
+)
+
+// AttestRewind sanctions the current state of the selected database, e.g. once it was restored from a backup.
+// Clients accepting rewind attestations move their state to it instead of failing to verify the server
+func (c *immuClient) AttestRewind(ctx context.Context, reason string) (*schema.RewindAttestation, error) {
+	start := time.Now()
+
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	result, err := c.ServiceClient.AttestRewind(ctx, &schema.AttestRewindRequest{Reason: reason})
+
+	c.Logger.Debugf("AttestRewind finished in %s", time.Since(start))
+
+	return result, err
+}
+
+// GetRewindAttestation returns the latest rewind attestation of the selected database
+func (c *immuClient) GetRewindAttestation(ctx context.Context) (*schema.RewindAttestation, error) {
+	start := time.Now()
+
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	result, err := c.ServiceClient.GetRewindAttestation(ctx, &empty.Empty{})
+
+	c.Logger.Debugf("GetRewindAttestation finished in %s", time.Since(start))
+
+	return result, err
+}
+
+// acceptRewind moves the pinned state of the database to the current server state, provided the server history was
+// rewound before the pinned state by a signed attestation, and the server history still reaches the attested state
+func (c *immuClient) acceptRewind(ctx context.Context, database string, pinned *schema.ImmutableState) (*schema.RewindAttestation, error) {
+	if c.serverSigningPubKey == nil {
+		return nil, ErrRewindNotAttested
+	}
+
+	attestation, err := c.ServiceClient.GetRewindAttestation(ctx, &empty.Empty{})
+	if err != nil {
+		return nil, err
+	}
+
+	if attestation.Db != database || attestation.TxId >= pinned.TxId {
+		return nil, ErrRewindNotAttested
+	}
+
+	ok, err := attestation.CheckSignature(c.serverSigningPubKey)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrRewindNotAttested
+	}
+
+	attested := &schema.ImmutableState{
+		Db:     database,
+		TxId:   attestation.TxId,
+		TxHash: attestation.TxHash,
+	}
+
+	cmp, err := c.ServiceClient.CompareState(ctx, &schema.StateComparisonRequest{State: attested})
+	if err != nil {
+		return nil, err
+	}
+
+	if cmp.Relation != schema.StateRelation_STATE_EQUAL && cmp.Relation != schema.StateRelation_STATE_SERVER_AHEAD {
+		return nil, ErrRewindNotAttested
+	}
+
+	err = verifyStateComparison(attested, cmp)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.StateService.SetState(database, &schema.ImmutableState{
+		Db:     database,
+		TxId:   cmp.ServerTxId,
+		TxHash: cmp.ServerTxHash,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c.Logger.Warningf("history of database %s was rewound from tx %d to tx %d by %s: %s. State moved to tx %d",
+		database, pinned.TxId, attestation.TxId, attestation.IssuedBy, attestation.Reason, cmp.ServerTxId)
+
+	return attestation, nil
+}
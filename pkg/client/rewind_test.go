@@ -0,0 +1,128 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/server"
+	"github.com/codenotary/immudb/pkg/server/servertest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+func TestImmuClient_AcceptRewind(t *testing.T) {
+	options := server.DefaultOptions().WithAuth(true).WithSigningKey("./../../test/signer/ec1.key")
+	bs := servertest.NewBufconnServer(options)
+
+	defer os.RemoveAll(options.Dir)
+	defer os.Remove(".state-")
+
+	bs.Start()
+	defer bs.Stop()
+
+	events := make(chan *HeartbeatEvent, 10)
+
+	ts := NewTokenService().WithTokenFileName("testTokenFile").WithHds(DefaultHomedirServiceMock())
+	opts := DefaultOptions().
+		WithDialOptions(&[]grpc.DialOption{grpc.WithContextDialer(bs.Dialer), grpc.WithInsecure()}).
+		WithTokenService(ts).
+		WithHeartbeatInterval(time.Hour).
+		WithHeartbeatHandler(func(e *HeartbeatEvent) { events <- e }).
+		WithServerSigningPubKey("./../../test/signer/ec1.pub").
+		WithAcceptRewindAttestations(true)
+
+	cli, err := NewImmuClient(opts)
+	require.NoError(t, err)
+	defer cli.Disconnect()
+
+	c := cli.(*immuClient)
+
+	ctx := context.Background()
+
+	_, err = c.Login(ctx, []byte(`immudb`), []byte(`immudb`))
+	require.NoError(t, err)
+
+	_, err = c.GetRewindAttestation(ctx)
+	require.Error(t, err)
+
+	_, err = c.VerifiedSet(ctx, []byte("key"), []byte("value"))
+	require.NoError(t, err)
+
+	// the state pinned before the server was restored from a backup
+	pinned := &schema.ImmutableState{Db: DefaultDB, TxId: 1000, TxHash: make([]byte, sha256.Size)}
+
+	setPinnedState := func(state *schema.ImmutableState) {
+		err := c.StateService.CacheLock()
+		require.NoError(t, err)
+		defer c.StateService.CacheUnlock()
+
+		err = c.StateService.SetState(DefaultDB, state)
+		require.NoError(t, err)
+	}
+
+	setPinnedState(pinned)
+
+	err = c.heartbeat(ctx)
+	require.NoError(t, err)
+
+	e := <-events
+	require.Equal(t, HeartbeatStateRegression, e.Kind)
+
+	attestation, err := c.AttestRewind(ctx, "restored from backup")
+	require.NoError(t, err)
+	require.Equal(t, DefaultDB, attestation.Db)
+	require.Equal(t, "immudb", attestation.IssuedBy)
+	require.NotNil(t, attestation.Signature)
+
+	err = c.heartbeat(ctx)
+	require.NoError(t, err)
+
+	e = <-events
+	require.Equal(t, HeartbeatStateRewound, e.Kind)
+	require.Equal(t, attestation.TxId, e.Attestation.TxId)
+	require.Equal(t, "restored from backup", e.Attestation.Reason)
+
+	state, err := c.CurrentState(ctx)
+	require.NoError(t, err)
+
+	err = c.StateService.CacheLock()
+	require.NoError(t, err)
+	newPinned, err := c.StateService.GetState(ctx, DefaultDB)
+	require.NoError(t, err)
+	err = c.StateService.CacheUnlock()
+	require.NoError(t, err)
+
+	require.Equal(t, state.TxId, newPinned.TxId)
+	require.Equal(t, state.TxHash, newPinned.TxHash)
+
+	_, err = c.VerifiedGet(ctx, []byte("key"))
+	require.NoError(t, err)
+
+	// attestations of a rewind after the pinned state are not accepted
+	setPinnedState(&schema.ImmutableState{Db: DefaultDB, TxId: attestation.TxId, TxHash: make([]byte, sha256.Size)})
+
+	err = c.heartbeat(ctx)
+	require.NoError(t, err)
+
+	e = <-events
+	require.Equal(t, HeartbeatStateRegression, e.Kind)
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/golang/protobuf/ptypes/empty"
+)
+
+// CreateSecondaryIndex declares an index over either a JSON field or a fixed range of bytes of the values
+// of the keys under def.Prefix
+func (c *immuClient) CreateSecondaryIndex(ctx context.Context, def *schema.SecondaryIndex) (*schema.SecondaryIndex, error) {
+	start := time.Now()
+
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	result, err := c.ServiceClient.CreateSecondaryIndex(ctx, def)
+
+	c.Logger.Debugf("CreateSecondaryIndex finished in %s", time.Since(start))
+
+	return result, err
+}
+
+// DropSecondaryIndex drops an index
+func (c *immuClient) DropSecondaryIndex(ctx context.Context, name string) error {
+	start := time.Now()
+
+	if !c.IsConnected() {
+		return ErrNotConnected
+	}
+
+	_, err := c.ServiceClient.DropSecondaryIndex(ctx, &schema.SecondaryIndexRequest{Name: name})
+
+	c.Logger.Debugf("DropSecondaryIndex finished in %s", time.Since(start))
+
+	return err
+}
+
+// ListSecondaryIndexes returns the indexes declared in the selected database
+func (c *immuClient) ListSecondaryIndexes(ctx context.Context) (*schema.SecondaryIndexes, error) {
+	start := time.Now()
+
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	result, err := c.ServiceClient.ListSecondaryIndexes(ctx, &empty.Empty{})
+
+	c.Logger.Debugf("ListSecondaryIndexes finished in %s", time.Since(start))
+
+	return result, err
+}
+
+// IndexScan lists the entries whose indexed value matches the request, ordered by indexed value and key
+func (c *immuClient) IndexScan(ctx context.Context, req *schema.IndexScanRequest) (*schema.IndexEntries, error) {
+	start := time.Now()
+
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	result, err := c.ServiceClient.IndexScan(ctx, req)
+
+	c.Logger.Debugf("IndexScan finished in %s", time.Since(start))
+
+	return result, err
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/server"
+	"github.com/codenotary/immudb/pkg/server/servertest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestImmuClient_SecondaryIndexes(t *testing.T) {
+	options := server.DefaultOptions().WithAuth(true)
+	bs := servertest.NewBufconnServer(options)
+
+	defer os.RemoveAll(options.Dir)
+	defer os.Remove(".state-")
+
+	bs.Start()
+	defer bs.Stop()
+
+	ts := NewTokenService().WithTokenFileName("testTokenFile").WithHds(DefaultHomedirServiceMock())
+	client, err := NewImmuClient(DefaultOptions().WithDialOptions(&[]grpc.DialOption{grpc.WithContextDialer(bs.Dialer), grpc.WithInsecure()}).WithTokenService(ts))
+	require.NoError(t, err)
+	defer client.Disconnect()
+
+	lr, err := client.Login(context.TODO(), []byte(`immudb`), []byte(`immudb`))
+	require.NoError(t, err)
+
+	ctx := metadata.NewOutgoingContext(context.Background(), metadata.Pairs("authorization", lr.Token))
+
+	_, err = client.Set(ctx, []byte(`user:1`), []byte(`{"email":"a@example.com"}`))
+	require.NoError(t, err)
+
+	idx, err := client.CreateSecondaryIndex(ctx, &schema.SecondaryIndex{Name: "email", Prefix: []byte(`user:`), JsonPath: "email"})
+	require.NoError(t, err)
+	require.NotZero(t, idx.Tx)
+
+	_, err = client.Set(ctx, []byte(`user:2`), []byte(`{"email":"b@example.com"}`))
+	require.NoError(t, err)
+
+	list, err := client.IndexScan(ctx, &schema.IndexScanRequest{Index: "email", Value: []byte("b@example.com")})
+	require.NoError(t, err)
+	require.Len(t, list.Entries, 1)
+	require.Equal(t, []byte(`user:2`), list.Entries[0].Entry.Key)
+
+	indexes, err := client.ListSecondaryIndexes(ctx)
+	require.NoError(t, err)
+	require.Len(t, indexes.Indexes, 1)
+
+	err = client.DropSecondaryIndex(ctx, "email")
+	require.NoError(t, err)
+
+	_, err = client.IndexScan(ctx, &schema.IndexScanRequest{Index: "email"})
+	require.Error(t, err)
+}
+
+func TestImmuClient_SetWithSecondaryIndexes(t *testing.T) {
+	options := server.DefaultOptions().WithAuth(true)
+	bs := servertest.NewBufconnServer(options)
+
+	defer os.RemoveAll(options.Dir)
+	defer os.Remove(".state-")
+
+	bs.Start()
+	defer bs.Stop()
+
+	ts := NewTokenService().WithTokenFileName("testTokenFile").WithHds(DefaultHomedirServiceMock())
+	client, err := NewImmuClient(DefaultOptions().WithDialOptions(&[]grpc.DialOption{grpc.WithContextDialer(bs.Dialer), grpc.WithInsecure()}).WithTokenService(ts))
+	require.NoError(t, err)
+	defer client.Disconnect()
+
+	lr, err := client.Login(context.TODO(), []byte(`immudb`), []byte(`immudb`))
+	require.NoError(t, err)
+
+	ctx := metadata.NewOutgoingContext(context.Background(), metadata.Pairs("authorization", lr.Token))
+
+	_, err = client.CreateSecondaryIndex(ctx, &schema.SecondaryIndex{Name: "email", Prefix: []byte(`user:`), JsonPath: "email"})
+	require.NoError(t, err)
+
+	_, err = client.CreateSecondaryIndex(ctx, &schema.SecondaryIndex{Name: "country", Prefix: []byte(`user:`), JsonPath: "country"})
+	require.NoError(t, err)
+
+	// each written value adds an entry per index to the transaction
+	txmd, err := client.Set(ctx, []byte(`user:1`), []byte(`{"email":"a@example.com","country":"ar"}`))
+	require.NoError(t, err)
+	require.Equal(t, int32(3), txmd.Nentries)
+
+	txmd, err = client.VerifiedSet(ctx, []byte(`user:2`), []byte(`{"email":"b@example.com","country":"uy"}`))
+	require.NoError(t, err)
+	require.Equal(t, int32(3), txmd.Nentries)
+
+	txmd, err = client.SetAll(ctx, &schema.SetRequest{KVs: []*schema.KeyValue{
+		{Key: []byte(`user:3`), Value: []byte(`{"email":"c@example.com","country":"ar"}`)},
+		{Key: []byte(`user:4`), Value: []byte(`{"email":"d@example.com"}`)},
+	}})
+	require.NoError(t, err)
+	require.Equal(t, int32(5), txmd.Nentries)
+
+	list, err := client.IndexScan(ctx, &schema.IndexScanRequest{Index: "country", Value: []byte("ar")})
+	require.NoError(t, err)
+	require.Len(t, list.Entries, 2)
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+)
+
+// SetIf sets the key only if it currently holds expectedValue, otherwise the write is rejected with a failed precondition
+func (c *immuClient) SetIf(ctx context.Context, key []byte, value []byte, expectedValue []byte) (*schema.TxMetadata, error) {
+	return c.setIf(ctx, &schema.SetIfRequest{
+		KV:            &schema.KeyValue{Key: key, Value: value},
+		ExpectedValue: expectedValue,
+	})
+}
+
+// SetIfTx sets the key only if its current value was written in the transaction expectedTx, otherwise the write
+// is rejected with a failed precondition
+func (c *immuClient) SetIfTx(ctx context.Context, key []byte, value []byte, expectedTx uint64) (*schema.TxMetadata, error) {
+	if expectedTx == 0 {
+		return nil, ErrIllegalArguments
+	}
+
+	return c.setIf(ctx, &schema.SetIfRequest{
+		KV:         &schema.KeyValue{Key: key, Value: value},
+		ExpectedTx: expectedTx,
+	})
+}
+
+func (c *immuClient) setIf(ctx context.Context, req *schema.SetIfRequest) (*schema.TxMetadata, error) {
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	start := time.Now()
+	defer func() { c.Logger.Debugf("SetIf finished in %s", time.Since(start)) }()
+
+	req.Attribution = c.attribution()
+
+	txmd, err := c.ServiceClient.SetIf(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isExpectedKVTxLen(txmd, 1, "") {
+		return nil, store.ErrCorruptedData
+	}
+
+	return txmd, nil
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package client
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/codenotary/immudb/pkg/database"
+	"github.com/codenotary/immudb/pkg/server"
+	"github.com/codenotary/immudb/pkg/server/servertest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestImmuClient_SetIf(t *testing.T) {
+	options := server.DefaultOptions().WithAuth(true)
+	bs := servertest.NewBufconnServer(options)
+
+	defer os.RemoveAll(options.Dir)
+	defer os.Remove(".state-")
+
+	bs.Start()
+	defer bs.Stop()
+
+	ts := NewTokenService().WithTokenFileName("testTokenFile").WithHds(DefaultHomedirServiceMock())
+	opts := DefaultOptions().WithDialOptions(&[]grpc.DialOption{grpc.WithContextDialer(bs.Dialer), grpc.WithInsecure()}).WithTokenService(ts)
+
+	client, err := NewImmuClient(opts)
+	require.NoError(t, err)
+	defer client.Disconnect()
+
+	lr, err := client.Login(context.TODO(), []byte(`immudb`), []byte(`immudb`))
+	require.NoError(t, err)
+
+	ctx := metadata.NewOutgoingContext(context.Background(), metadata.Pairs("authorization", lr.Token))
+
+	txmd, err := client.Set(ctx, []byte("key"), []byte("value1"))
+	require.NoError(t, err)
+
+	_, err = client.SetIf(ctx, []byte("key"), []byte("value2"), []byte("value0"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), database.ErrPreconditionFailed.Error())
+
+	_, err = client.SetIf(ctx, []byte("key"), []byte("value2"), []byte("value1"))
+	require.NoError(t, err)
+
+	_, err = client.SetIfTx(ctx, []byte("key"), []byte("value3"), 0)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = client.SetIfTx(ctx, []byte("key"), []byte("value3"), txmd.Id)
+	require.Error(t, err)
+
+	e, err := client.Get(ctx, []byte("key"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("value2"), e.Value)
+
+	_, err = client.SetIfTx(ctx, []byte("key"), []byte("value3"), e.Tx)
+	require.NoError(t, err)
+}
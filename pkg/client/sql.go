@@ -41,6 +41,25 @@ func (c *immuClient) SQLExec(ctx context.Context, sql string, params map[string]
 	return c.ServiceClient.SQLExec(ctx, &schema.SQLExecRequest{Sql: sql, Params: namedParams})
 }
 
+// SQLExecDryRun validates DML statements and describes the entries they would write, without committing them
+func (c *immuClient) SQLExecDryRun(ctx context.Context, sql string, params map[string]interface{}) (*schema.DryRunResult, error) {
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	namedParams, err := encodeParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.ServiceClient.SQLExec(ctx, &schema.SQLExecRequest{Sql: sql, Params: namedParams, DryRun: true})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.DryRun, nil
+}
+
 func (c *immuClient) UseSnapshot(ctx context.Context, sinceTx, asBeforeTx uint64) error {
 	if !c.IsConnected() {
 		return ErrNotConnected
@@ -63,6 +82,20 @@ func (c *immuClient) SQLQuery(ctx context.Context, sql string, params map[string
 	return c.ServiceClient.SQLQuery(ctx, &schema.SQLQueryRequest{Sql: sql, Params: namedParams, ReuseSnapshot: !renewSnapshot})
 }
 
+// SQLQueryAtSnapshot runs the query reading the tables as of the transaction of a pinned snapshot
+func (c *immuClient) SQLQueryAtSnapshot(ctx context.Context, sql string, params map[string]interface{}, snapshot string) (*schema.SQLQueryResult, error) {
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	namedParams, err := encodeParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.ServiceClient.SQLQuery(ctx, &schema.SQLQueryRequest{Sql: sql, Params: namedParams, Snapshot: snapshot})
+}
+
 func (c *immuClient) ListTables(ctx context.Context) (*schema.SQLQueryResult, error) {
 	if !c.IsConnected() {
 		return nil, ErrNotConnected
@@ -77,6 +110,67 @@ func (c *immuClient) DescribeTable(ctx context.Context, tableName string) (*sche
 	return c.ServiceClient.DescribeTable(ctx, &schema.Table{TableName: tableName})
 }
 
+// SQLRowProvenance returns the versions of a row, oldest first, along with the entries written for each of them
+// and the transaction which assigned every column its value
+func (c *immuClient) SQLRowProvenance(ctx context.Context, req *schema.SQLRowProvenanceRequest) (*schema.SQLRowVersions, error) {
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+	return c.ServiceClient.SQLRowProvenance(ctx, req)
+}
+
+// NewSQLTx opens an interactive SQL transaction. Under SNAPSHOT isolation all of its queries are resolved against the same snapshot
+// and committing it fails with a codes.Aborted status if a concurrent transaction updated any of the written rows
+func (c *immuClient) NewSQLTx(ctx context.Context, isolation schema.SQLTxIsolation) (*schema.SQLTx, error) {
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+	return c.ServiceClient.NewSQLTx(ctx, &schema.NewSQLTxRequest{Isolation: isolation})
+}
+
+func (c *immuClient) SQLTxExec(ctx context.Context, txID string, sql string, params map[string]interface{}) error {
+	if !c.IsConnected() {
+		return ErrNotConnected
+	}
+
+	namedParams, err := encodeParams(params)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.ServiceClient.SQLTxExec(ctx, &schema.SQLTxExecRequest{TransactionId: txID, Sql: sql, Params: namedParams})
+	return err
+}
+
+func (c *immuClient) SQLTxQuery(ctx context.Context, txID string, sql string, params map[string]interface{}) (*schema.SQLQueryResult, error) {
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	namedParams, err := encodeParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.ServiceClient.SQLTxQuery(ctx, &schema.SQLTxQueryRequest{TransactionId: txID, Sql: sql, Params: namedParams})
+}
+
+func (c *immuClient) CommitSQLTx(ctx context.Context, txID string) (*schema.SQLExecResult, error) {
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+	return c.ServiceClient.CommitSQLTx(ctx, &schema.SQLTxRequest{TransactionId: txID})
+}
+
+func (c *immuClient) RollbackSQLTx(ctx context.Context, txID string) error {
+	if !c.IsConnected() {
+		return ErrNotConnected
+	}
+
+	_, err := c.ServiceClient.RollbackSQLTx(ctx, &schema.SQLTxRequest{TransactionId: txID})
+	return err
+}
+
 func encodeParams(params map[string]interface{}) ([]*schema.NamedParam, error) {
 	if params == nil {
 		return nil, nil
@@ -0,0 +1,317 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package client
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+)
+
+// SQLTag is the struct field tag used to map fields to SQL columns.
+// Untagged fields are mapped to the lowercased field name and fields tagged with "-" are ignored
+const SQLTag = "sql"
+
+var bytesType = reflect.TypeOf([]byte(nil))
+
+// ScanRows maps the rows of a query result into dest, which must be a pointer to a slice of structs or of pointers to structs
+func ScanRows(res *schema.SQLQueryResult, dest interface{}) error {
+	if res == nil {
+		return ErrIllegalArguments
+	}
+
+	dv := reflect.ValueOf(dest)
+	if dv.Kind() != reflect.Ptr || dv.IsNil() || dv.Elem().Kind() != reflect.Slice {
+		return ErrInvalidDestination
+	}
+
+	sv := dv.Elem()
+	et := sv.Type().Elem()
+
+	isPtr := et.Kind() == reflect.Ptr
+	if isPtr {
+		et = et.Elem()
+	}
+
+	if et.Kind() != reflect.Struct {
+		return ErrInvalidDestination
+	}
+
+	fields := structFields(et)
+
+	rows := reflect.MakeSlice(sv.Type(), 0, len(res.Rows))
+
+	for _, row := range res.Rows {
+		ev := reflect.New(et)
+
+		err := scanRow(row, ev.Elem(), fields)
+		if err != nil {
+			return err
+		}
+
+		if isPtr {
+			rows = reflect.Append(rows, ev)
+		} else {
+			rows = reflect.Append(rows, ev.Elem())
+		}
+	}
+
+	sv.Set(rows)
+
+	return nil
+}
+
+// ScanRow maps a single row into dest, which must be a pointer to a struct
+func ScanRow(row *schema.Row, dest interface{}) error {
+	if row == nil {
+		return ErrIllegalArguments
+	}
+
+	dv := reflect.ValueOf(dest)
+	if dv.Kind() != reflect.Ptr || dv.IsNil() || dv.Elem().Kind() != reflect.Struct {
+		return ErrInvalidDestination
+	}
+
+	return scanRow(row, dv.Elem(), structFields(dv.Elem().Type()))
+}
+
+// InsertInto builds an INSERT statement and its params from the mapped fields of v, a struct or a pointer to a struct
+func InsertInto(table string, v interface{}) (string, map[string]interface{}, error) {
+	return insertStmt("INSERT", table, v)
+}
+
+// UpsertInto builds an UPSERT statement and its params from the mapped fields of v, a struct or a pointer to a struct
+func UpsertInto(table string, v interface{}) (string, map[string]interface{}, error) {
+	return insertStmt("UPSERT", table, v)
+}
+
+func insertStmt(op, table string, v interface{}) (string, map[string]interface{}, error) {
+	if len(table) == 0 {
+		return "", nil, ErrIllegalArguments
+	}
+
+	sv := reflect.ValueOf(v)
+	if sv.Kind() == reflect.Ptr {
+		if sv.IsNil() {
+			return "", nil, ErrIllegalArguments
+		}
+		sv = sv.Elem()
+	}
+
+	if sv.Kind() != reflect.Struct {
+		return "", nil, ErrIllegalArguments
+	}
+
+	fields := structFields(sv.Type())
+	if len(fields) == 0 {
+		return "", nil, ErrIllegalArguments
+	}
+
+	cols := make([]string, len(fields))
+	vals := make([]string, len(fields))
+	params := make(map[string]interface{}, len(fields))
+
+	for i, f := range fields {
+		p, err := paramValue(sv.FieldByIndex(f.index))
+		if err != nil {
+			return "", nil, err
+		}
+
+		cols[i] = f.col
+		vals[i] = "@" + f.col
+		params[f.col] = p
+	}
+
+	stmt := op + " INTO " + table + "(" + strings.Join(cols, ", ") + ") VALUES (" + strings.Join(vals, ", ") + ")"
+
+	return stmt, params, nil
+}
+
+type mappedField struct {
+	col   string
+	index []int
+}
+
+func structFields(t reflect.Type) []*mappedField {
+	var fields []*mappedField
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		if f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+
+		tag := f.Tag.Get(SQLTag)
+		if tag == "-" {
+			continue
+		}
+
+		if f.Anonymous && tag == "" && f.Type.Kind() == reflect.Struct {
+			for _, ef := range structFields(f.Type) {
+				ef.index = append([]int{i}, ef.index...)
+				fields = append(fields, ef)
+			}
+			continue
+		}
+
+		if f.PkgPath != "" {
+			continue
+		}
+
+		col := strings.ToLower(f.Name)
+		if tag != "" {
+			col = tag
+		}
+
+		fields = append(fields, &mappedField{col: col, index: []int{i}})
+	}
+
+	return fields
+}
+
+func scanRow(row *schema.Row, sv reflect.Value, fields []*mappedField) error {
+	if len(row.Columns) != len(row.Values) {
+		return ErrIllegalArguments
+	}
+
+	for i, c := range row.Columns {
+		col := columnName(c)
+
+		for _, f := range fields {
+			if f.col != col && f.col != c {
+				continue
+			}
+
+			err := assignSQLValue(sv.FieldByIndex(f.index), row.Values[i])
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// columnName returns the column name of a selector such as "(db.table.col)"
+func columnName(selector string) string {
+	c := strings.TrimSuffix(selector, ")")
+
+	if i := strings.LastIndexAny(c, ".("); i >= 0 {
+		c = c[i+1:]
+	}
+
+	return strings.ToLower(c)
+}
+
+func assignSQLValue(f reflect.Value, v *schema.SQLValue) error {
+	if v == nil || v.Value == nil {
+		f.Set(reflect.Zero(f.Type()))
+		return nil
+	}
+
+	if _, isNull := v.Value.(*schema.SQLValue_Null); isNull {
+		f.Set(reflect.Zero(f.Type()))
+		return nil
+	}
+
+	if f.Kind() == reflect.Ptr {
+		p := reflect.New(f.Type().Elem())
+
+		err := assignSQLValue(p.Elem(), v)
+		if err != nil {
+			return err
+		}
+
+		f.Set(p)
+		return nil
+	}
+
+	switch tv := v.Value.(type) {
+	case *schema.SQLValue_N:
+		{
+			switch f.Kind() {
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				if f.OverflowInt(int64(tv.N)) {
+					return ErrIncompatibleSQLValue
+				}
+				f.SetInt(int64(tv.N))
+				return nil
+			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+				if f.OverflowUint(tv.N) {
+					return ErrIncompatibleSQLValue
+				}
+				f.SetUint(tv.N)
+				return nil
+			}
+		}
+	case *schema.SQLValue_S:
+		{
+			if f.Kind() == reflect.String {
+				f.SetString(tv.S)
+				return nil
+			}
+		}
+	case *schema.SQLValue_B:
+		{
+			if f.Kind() == reflect.Bool {
+				f.SetBool(tv.B)
+				return nil
+			}
+		}
+	case *schema.SQLValue_Bs:
+		{
+			if f.Type() == bytesType {
+				b := make([]byte, len(tv.Bs))
+				copy(b, tv.Bs)
+				f.SetBytes(b)
+				return nil
+			}
+		}
+	}
+
+	return ErrIncompatibleSQLValue
+}
+
+func paramValue(f reflect.Value) (interface{}, error) {
+	if f.Kind() == reflect.Ptr {
+		if f.IsNil() {
+			return nil, nil
+		}
+		f = f.Elem()
+	}
+
+	switch f.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return f.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return f.Uint(), nil
+	case reflect.String:
+		return f.String(), nil
+	case reflect.Bool:
+		return f.Bool(), nil
+	}
+
+	if f.Type() == bytesType {
+		if f.IsNil() {
+			return nil, nil
+		}
+		return f.Bytes(), nil
+	}
+
+	return nil, ErrIncompatibleSQLValue
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package client
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/server"
+	"github.com/codenotary/immudb/pkg/server/servertest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+type auditInfo struct {
+	Author string
+}
+
+type journalEntry struct {
+	auditInfo
+	ID       int64   `sql:"id"`
+	Title    *string `sql:"title"`
+	Active   bool
+	Payload  []byte
+	Ignored  string `sql:"-"`
+	internal string
+}
+
+func TestSQLMapper(t *testing.T) {
+	stmt, params, err := InsertInto("journal", &journalEntry{ID: 1, Active: true, auditInfo: auditInfo{Author: "immu"}})
+	require.NoError(t, err)
+	require.Equal(t, "INSERT INTO journal(author, id, title, active, payload) VALUES (@author, @id, @title, @active, @payload)", stmt)
+	require.Equal(t, map[string]interface{}{
+		"author":  "immu",
+		"id":      int64(1),
+		"title":   nil,
+		"active":  true,
+		"payload": nil,
+	}, params)
+
+	stmt, _, err = UpsertInto("journal", journalEntry{})
+	require.NoError(t, err)
+	require.Equal(t, "UPSERT INTO journal(author, id, title, active, payload) VALUES (@author, @id, @title, @active, @payload)", stmt)
+
+	_, _, err = InsertInto("", journalEntry{})
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, _, err = InsertInto("journal", 1)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, _, err = InsertInto("journal", struct{ F float64 }{})
+	require.Equal(t, ErrIncompatibleSQLValue, err)
+
+	row := &schema.Row{
+		Columns: []string{"(defaultdb.journal.id)", "(defaultdb.journal.title)", "(defaultdb.journal.active)", "(defaultdb.journal.payload)", "(defaultdb.journal.author)"},
+		Values: []*schema.SQLValue{
+			{Value: &schema.SQLValue_N{N: 1}},
+			{Value: &schema.SQLValue_S{S: "title1"}},
+			{Value: &schema.SQLValue_B{B: true}},
+			{Value: &schema.SQLValue_Null{}},
+			{Value: &schema.SQLValue_S{S: "immu"}},
+		},
+	}
+
+	var e journalEntry
+	err = ScanRow(row, &e)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), e.ID)
+	require.Equal(t, "title1", *e.Title)
+	require.True(t, e.Active)
+	require.Nil(t, e.Payload)
+	require.Equal(t, "immu", e.Author)
+
+	err = ScanRow(row, e)
+	require.Equal(t, ErrInvalidDestination, err)
+
+	err = ScanRow(nil, &e)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	var small struct {
+		ID int8 `sql:"id"`
+	}
+	err = ScanRow(&schema.Row{Columns: []string{"(db.t.id)"}, Values: []*schema.SQLValue{{Value: &schema.SQLValue_N{N: 1000}}}}, &small)
+	require.Equal(t, ErrIncompatibleSQLValue, err)
+
+	var mismatch struct {
+		ID string `sql:"id"`
+	}
+	err = ScanRow(&schema.Row{Columns: []string{"(db.t.id)"}, Values: []*schema.SQLValue{{Value: &schema.SQLValue_N{N: 1}}}}, &mismatch)
+	require.Equal(t, ErrIncompatibleSQLValue, err)
+
+	var entries []journalEntry
+	err = ScanRows(&schema.SQLQueryResult{}, entries)
+	require.Equal(t, ErrInvalidDestination, err)
+
+	var ints []int
+	err = ScanRows(&schema.SQLQueryResult{}, &ints)
+	require.Equal(t, ErrInvalidDestination, err)
+
+	err = ScanRows(nil, &entries)
+	require.Equal(t, ErrIllegalArguments, err)
+}
+
+func TestImmuClient_SQLMapper(t *testing.T) {
+	options := server.DefaultOptions().WithAuth(true)
+	bs := servertest.NewBufconnServer(options)
+
+	defer os.RemoveAll(options.Dir)
+	defer os.Remove(".state-")
+
+	bs.Start()
+	defer bs.Stop()
+
+	client, err := NewImmuClient(DefaultOptions().WithDialOptions(&[]grpc.DialOption{grpc.WithContextDialer(bs.Dialer), grpc.WithInsecure()}))
+	require.NoError(t, err)
+	lr, err := client.Login(context.TODO(), []byte(`immudb`), []byte(`immudb`))
+	require.NoError(t, err)
+
+	md := metadata.Pairs("authorization", lr.Token)
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	_, err = client.SQLExec(ctx, `CREATE TABLE journal(
+		id INTEGER,
+		author VARCHAR,
+		title VARCHAR,
+		active BOOLEAN,
+		payload BLOB,
+		PRIMARY KEY id
+		);`, nil)
+	require.NoError(t, err)
+
+	title := "title1"
+
+	for _, e := range []*journalEntry{
+		{ID: 1, Title: &title, Active: true, Payload: []byte{1, 2, 3}, auditInfo: auditInfo{Author: "immu"}},
+		{ID: 2},
+	} {
+		stmt, params, err := InsertInto("journal", e)
+		require.NoError(t, err)
+
+		_, err = client.SQLExec(ctx, stmt, params)
+		require.NoError(t, err)
+	}
+
+	res, err := client.SQLQuery(ctx, "SELECT id, author, title, active, payload FROM journal", nil, true)
+	require.NoError(t, err)
+
+	var entries []*journalEntry
+	err = ScanRows(res, &entries)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	require.Equal(t, int64(1), entries[0].ID)
+	require.Equal(t, "immu", entries[0].Author)
+	require.Equal(t, title, *entries[0].Title)
+	require.True(t, entries[0].Active)
+	require.Equal(t, []byte{1, 2, 3}, entries[0].Payload)
+
+	require.Equal(t, int64(2), entries[1].ID)
+	require.Empty(t, entries[1].Author)
+	require.Nil(t, entries[1].Title)
+	require.False(t, entries[1].Active)
+	require.Nil(t, entries[1].Payload)
+}
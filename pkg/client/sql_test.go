@@ -21,6 +21,7 @@ import (
 	"testing"
 
 	"github.com/codenotary/immudb/embedded/sql"
+	"github.com/codenotary/immudb/embedded/store"
 	"github.com/codenotary/immudb/pkg/api/schema"
 	"github.com/codenotary/immudb/pkg/server"
 	"github.com/codenotary/immudb/pkg/server/servertest"
@@ -62,6 +63,10 @@ func TestImmuClient_SQL(t *testing.T) {
 	params["active"] = true
 	params["payload"] = []byte{1, 2, 3}
 
+	dryRun, err := client.SQLExecDryRun(ctx, "INSERT INTO table1(id, title) VALUES (@id, @title)", params)
+	require.NoError(t, err)
+	require.Greater(t, dryRun.Nentries, uint32(0))
+
 	_, err = client.SQLExec(ctx, "INSERT INTO table1(id, title, active, payload) VALUES (@id, @title, @active, @payload), (2, 'title2', false, NULL), (3, NULL, NULL, x'AED0393F')", params)
 	require.NoError(t, err)
 
@@ -109,3 +114,113 @@ func TestImmuClient_SQL(t *testing.T) {
 		require.NoError(t, err)
 	}
 }
+
+func TestImmuClient_SQLTx(t *testing.T) {
+	options := server.DefaultOptions().WithAuth(true)
+	bs := servertest.NewBufconnServer(options)
+
+	defer os.RemoveAll(options.Dir)
+	defer os.Remove(".state-")
+
+	bs.Start()
+	defer bs.Stop()
+
+	client, err := NewImmuClient(DefaultOptions().WithDialOptions(&[]grpc.DialOption{grpc.WithContextDialer(bs.Dialer), grpc.WithInsecure()}))
+	require.NoError(t, err)
+	lr, err := client.Login(context.TODO(), []byte(`immudb`), []byte(`immudb`))
+	require.NoError(t, err)
+
+	md := metadata.Pairs("authorization", lr.Token)
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	_, err = client.SQLExec(ctx, "CREATE TABLE table1(id INTEGER, title VARCHAR, PRIMARY KEY id)", nil)
+	require.NoError(t, err)
+
+	tx, err := client.NewSQLTx(ctx, schema.SQLTxIsolation_SNAPSHOT)
+	require.NoError(t, err)
+
+	err = client.SQLTxExec(ctx, tx.TransactionId, "INSERT INTO table1(id, title) VALUES (@id, @title)", map[string]interface{}{"id": 1, "title": "title1"})
+	require.NoError(t, err)
+
+	res, err := client.SQLTxQuery(ctx, tx.TransactionId, "SELECT id FROM table1", nil)
+	require.NoError(t, err)
+	require.Len(t, res.Rows, 1)
+
+	txRes, err := client.CommitSQLTx(ctx, tx.TransactionId)
+	require.NoError(t, err)
+	require.Equal(t, tx.SnapshotTx, txRes.SnapshotTx)
+	require.Len(t, txRes.Dtxs, 1)
+
+	res, err = client.SQLQuery(ctx, "SELECT id FROM table1", nil, true)
+	require.NoError(t, err)
+	require.Len(t, res.Rows, 1)
+
+	tx, err = client.NewSQLTx(ctx, schema.SQLTxIsolation_SNAPSHOT)
+	require.NoError(t, err)
+
+	err = client.RollbackSQLTx(ctx, tx.TransactionId)
+	require.NoError(t, err)
+
+	err = client.RollbackSQLTx(ctx, tx.TransactionId)
+	require.Error(t, err)
+}
+
+func TestImmuClient_VerifiedCombinedState(t *testing.T) {
+	options := server.DefaultOptions().WithAuth(true).WithSigningKey("./../../test/signer/ec1.key")
+	bs := servertest.NewBufconnServer(options)
+
+	defer os.RemoveAll(options.Dir)
+	defer os.Remove(".state-")
+
+	bs.Start()
+	defer bs.Stop()
+
+	client, err := NewImmuClient(DefaultOptions().
+		WithDialOptions(&[]grpc.DialOption{grpc.WithContextDialer(bs.Dialer), grpc.WithInsecure()}).
+		WithServerSigningPubKey("./../../test/signer/ec1.pub"),
+	)
+	require.NoError(t, err)
+	defer client.Disconnect()
+
+	lr, err := client.Login(context.TODO(), []byte(`immudb`), []byte(`immudb`))
+	require.NoError(t, err)
+
+	md := metadata.Pairs("authorization", lr.Token)
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	_, err = client.Set(ctx, []byte("key1"), []byte("value1"))
+	require.NoError(t, err)
+
+	state1, err := client.VerifiedCombinedState(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "defaultdb", state1.Db)
+
+	_, err = client.SQLExec(ctx, "CREATE TABLE table1(id INTEGER, PRIMARY KEY id)", nil)
+	require.NoError(t, err)
+
+	_, err = client.SQLExec(ctx, "INSERT INTO table1(id) VALUES (1)", nil)
+	require.NoError(t, err)
+
+	state2, err := client.VerifiedCombinedState(ctx)
+	require.NoError(t, err)
+	require.Greater(t, state2.TxId, state1.TxId)
+	require.Greater(t, state2.CatalogTxId, state1.CatalogTxId)
+	require.NotEqual(t, state1.Root(), state2.Root())
+
+	ok, err := state2.CheckSignature(client.(*immuClient).serverSigningPubKey)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// the database state is shared with the rest of verified operations
+	_, err = client.VerifiedSet(ctx, []byte("key2"), []byte("value2"))
+	require.NoError(t, err)
+
+	state3, err := client.VerifiedCombinedState(ctx)
+	require.NoError(t, err)
+	require.Equal(t, state2.CatalogTxId, state3.CatalogTxId)
+
+	client.(*immuClient).catalogStates["defaultdb"].TxHash = make([]byte, 32)
+
+	_, err = client.VerifiedCombinedState(ctx)
+	require.Equal(t, store.ErrCorruptedData, err)
+}
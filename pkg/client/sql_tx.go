@@ -0,0 +1,167 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultTxMaxRetries is the number of times RunTx retries a transaction aborted because of a conflict
+const DefaultTxMaxRetries = 5
+
+// DefaultTxRetryBackoff is the delay before the first retry, it's doubled on each subsequent retry
+const DefaultTxRetryBackoff = 10 * time.Millisecond
+
+// DefaultTxMaxRetryBackoff is the maximum delay between retries
+const DefaultTxMaxRetryBackoff = 1 * time.Second
+
+// TxOptions controls how RunTxWith opens and retries transactions
+type TxOptions struct {
+	Isolation       schema.SQLTxIsolation
+	MaxRetries      int
+	RetryBackoff    time.Duration
+	MaxRetryBackoff time.Duration
+}
+
+// DefaultTxOptions returns the options used by RunTx
+func DefaultTxOptions() *TxOptions {
+	return &TxOptions{
+		Isolation:       schema.SQLTxIsolation_SNAPSHOT,
+		MaxRetries:      DefaultTxMaxRetries,
+		RetryBackoff:    DefaultTxRetryBackoff,
+		MaxRetryBackoff: DefaultTxMaxRetryBackoff,
+	}
+}
+
+// WithIsolation sets the isolation level of the transaction
+func (o *TxOptions) WithIsolation(isolation schema.SQLTxIsolation) *TxOptions {
+	o.Isolation = isolation
+	return o
+}
+
+// WithMaxRetries sets how many times a transaction aborted because of a conflict is retried
+func (o *TxOptions) WithMaxRetries(maxRetries int) *TxOptions {
+	o.MaxRetries = maxRetries
+	return o
+}
+
+// WithRetryBackoff sets the delay before the first retry
+func (o *TxOptions) WithRetryBackoff(backoff time.Duration) *TxOptions {
+	o.RetryBackoff = backoff
+	return o
+}
+
+// WithMaxRetryBackoff sets the maximum delay between retries
+func (o *TxOptions) WithMaxRetryBackoff(backoff time.Duration) *TxOptions {
+	o.MaxRetryBackoff = backoff
+	return o
+}
+
+// SQLTx is an interactive SQL transaction handed to the function run by RunTx
+type SQLTx struct {
+	client     ImmuClient
+	id         string
+	snapshotTx uint64
+}
+
+// ID returns the server-side id of the transaction
+func (tx *SQLTx) ID() string {
+	return tx.id
+}
+
+// SnapshotTx returns the id of the latest transaction visible to the reads of the transaction when it was opened
+func (tx *SQLTx) SnapshotTx() uint64 {
+	return tx.snapshotTx
+}
+
+// Exec buffers the writes of the given statements until the transaction is committed
+func (tx *SQLTx) Exec(ctx context.Context, sql string, params map[string]interface{}) error {
+	return tx.client.SQLTxExec(ctx, tx.id, sql, params)
+}
+
+// Query runs the given query within the transaction, observing the writes it buffered
+func (tx *SQLTx) Query(ctx context.Context, sql string, params map[string]interface{}) (*schema.SQLQueryResult, error) {
+	return tx.client.SQLTxQuery(ctx, tx.id, sql, params)
+}
+
+// RunTx runs fn within a SNAPSHOT transaction using the default TxOptions, see RunTxWith
+func (c *immuClient) RunTx(ctx context.Context, fn func(tx *SQLTx) error) (*schema.SQLExecResult, error) {
+	return c.RunTxWith(ctx, DefaultTxOptions(), fn)
+}
+
+// RunTxWith opens a transaction, runs fn within it and commits it. The transaction is rolled back if fn
+// fails or panics. When the commit is aborted because of a conflict with a concurrent transaction,
+// fn is run again within a new transaction, up to MaxRetries times, waiting an exponential backoff in between
+func (c *immuClient) RunTxWith(ctx context.Context, opts *TxOptions, fn func(tx *SQLTx) error) (*schema.SQLExecResult, error) {
+	if opts == nil || fn == nil {
+		return nil, ErrIllegalArguments
+	}
+
+	backoff := opts.RetryBackoff
+
+	for retries := 0; ; retries++ {
+		res, err := c.runTx(ctx, opts.Isolation, fn)
+		if status.Code(err) != codes.Aborted || retries >= opts.MaxRetries {
+			return res, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > opts.MaxRetryBackoff {
+			backoff = opts.MaxRetryBackoff
+		}
+	}
+}
+
+func (c *immuClient) runTx(ctx context.Context, isolation schema.SQLTxIsolation, fn func(tx *SQLTx) error) (res *schema.SQLExecResult, err error) {
+	stx, err := c.NewSQLTx(ctx, isolation)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := &SQLTx{client: c, id: stx.TransactionId, snapshotTx: stx.SnapshotTx}
+
+	committed := false
+
+	defer func() {
+		if !committed {
+			rerr := c.RollbackSQLTx(ctx, tx.id)
+			if err == nil && rerr != nil {
+				err = rerr
+			}
+		}
+	}()
+
+	err = fn(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	// a failed commit discards the transaction on the server
+	committed = true
+
+	return c.CommitSQLTx(ctx, tx.id)
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package client
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/server"
+	"github.com/codenotary/immudb/pkg/server/servertest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestImmuClient_RunTx(t *testing.T) {
+	options := server.DefaultOptions().WithAuth(true)
+	bs := servertest.NewBufconnServer(options)
+
+	defer os.RemoveAll(options.Dir)
+	defer os.Remove(".state-")
+
+	bs.Start()
+	defer bs.Stop()
+
+	client, err := NewImmuClient(DefaultOptions().WithDialOptions(&[]grpc.DialOption{grpc.WithContextDialer(bs.Dialer), grpc.WithInsecure()}))
+	require.NoError(t, err)
+	lr, err := client.Login(context.TODO(), []byte(`immudb`), []byte(`immudb`))
+	require.NoError(t, err)
+
+	md := metadata.Pairs("authorization", lr.Token)
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	_, err = client.SQLExec(ctx, "CREATE TABLE accounts(id INTEGER, balance INTEGER, PRIMARY KEY id)", nil)
+	require.NoError(t, err)
+
+	_, err = client.SQLExec(ctx, "INSERT INTO accounts(id, balance) VALUES (1, 100)", nil)
+	require.NoError(t, err)
+
+	_, err = client.RunTxWith(ctx, nil, func(tx *SQLTx) error { return nil })
+	require.Equal(t, ErrIllegalArguments, err)
+
+	attempts := 0
+
+	res, err := client.RunTx(ctx, func(tx *SQLTx) error {
+		attempts++
+
+		qres, err := tx.Query(ctx, "SELECT balance FROM accounts WHERE id = 1", nil)
+		if err != nil {
+			return err
+		}
+
+		balance := qres.Rows[0].Values[0].GetN()
+
+		if attempts == 1 {
+			// a concurrent update makes the first attempt conflict
+			_, err = client.SQLExec(ctx, "UPSERT INTO accounts(id, balance) VALUES (1, 200)", nil)
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.Exec(ctx, "UPSERT INTO accounts(id, balance) VALUES (1, @balance)", map[string]interface{}{"balance": balance + 50})
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, attempts)
+	require.Len(t, res.Dtxs, 1)
+
+	qres, err := client.SQLQuery(ctx, "SELECT balance FROM accounts WHERE id = 1", nil, true)
+	require.NoError(t, err)
+	require.Equal(t, uint64(250), qres.Rows[0].Values[0].GetN())
+
+	_, err = client.RunTxWith(ctx, DefaultTxOptions().WithMaxRetries(0), func(tx *SQLTx) error {
+		_, err = client.SQLExec(ctx, "UPSERT INTO accounts(id, balance) VALUES (1, 0)", nil)
+		if err != nil {
+			return err
+		}
+
+		return tx.Exec(ctx, "UPSERT INTO accounts(id, balance) VALUES (1, 1)", nil)
+	})
+	require.Equal(t, codes.Aborted, status.Code(err))
+
+	errFn := errors.New("some error")
+
+	var txID string
+
+	_, err = client.RunTx(ctx, func(tx *SQLTx) error {
+		txID = tx.ID()
+		return errFn
+	})
+	require.Equal(t, errFn, err)
+
+	// the transaction was rolled back
+	err = client.RollbackSQLTx(ctx, txID)
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	require.Panics(t, func() {
+		client.RunTxWith(ctx, DefaultTxOptions().WithIsolation(schema.SQLTxIsolation_READ_COMMITTED), func(tx *SQLTx) error {
+			txID = tx.ID()
+			panic("some panic")
+		})
+	})
+
+	err = client.RollbackSQLTx(ctx, txID)
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
@@ -31,6 +31,7 @@ type StateService interface {
 	SetState(db string, state *schema.ImmutableState) error
 	CacheLock() error
 	CacheUnlock() error
+	SetServerUUID(serverUUID string)
 }
 
 type stateService struct {
@@ -96,6 +97,14 @@ func (r *stateService) SetState(db string, state *schema.ImmutableState) error {
 	return r.cache.Set(r.serverUUID, db, state)
 }
 
+// SetServerUUID pins the states kept from now on to a different server, as when the server was replaced
+func (r *stateService) SetServerUUID(serverUUID string) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.serverUUID = serverUUID
+}
+
 func (r *stateService) CacheLock() error {
 	return r.cache.Lock(r.serverUUID)
 }
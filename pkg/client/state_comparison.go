@@ -0,0 +1,94 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+)
+
+// CompareState compares the state of the current database kept by the client with the server history. Older states
+// known by the client may be provided as checkpoints, so the last common transaction can be located when both
+// histories forked. When the server is ahead, its consistency with the client state is verified
+func (c *immuClient) CompareState(ctx context.Context, checkpoints ...*schema.ImmutableState) (*schema.StateComparison, error) {
+	err := c.StateService.CacheLock()
+	if err != nil {
+		return nil, err
+	}
+	defer c.StateService.CacheUnlock()
+
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	start := time.Now()
+	defer func() { c.Logger.Debugf("CompareState finished in %s", time.Since(start)) }()
+
+	state, err := c.StateService.GetState(ctx, c.Options.CurrentDatabase)
+	if err != nil {
+		return nil, err
+	}
+
+	cmp, err := c.ServiceClient.CompareState(ctx, &schema.StateComparisonRequest{
+		State:       state,
+		Checkpoints: checkpoints,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = verifyStateComparison(state, cmp)
+	if err != nil {
+		return nil, err
+	}
+
+	return cmp, nil
+}
+
+// verifyStateComparison checks the proof of consistency between state and the server one, when the server is ahead
+func verifyStateComparison(state *schema.ImmutableState, cmp *schema.StateComparison) error {
+	if cmp.Relation != schema.StateRelation_STATE_SERVER_AHEAD || state.TxId == 0 {
+		return nil
+	}
+
+	if cmp.DualProof == nil {
+		return store.ErrCorruptedData
+	}
+
+	dualProof := schema.DualProofFrom(cmp.DualProof)
+
+	serverAlh := schema.DigestFrom(cmp.ServerTxHash)
+
+	if dualProof.TargetTxMetadata.Alh() != serverAlh {
+		return store.ErrCorruptedData
+	}
+
+	verifies := store.VerifyDualProof(
+		dualProof,
+		state.TxId,
+		cmp.ServerTxId,
+		schema.DigestFrom(state.TxHash),
+		serverAlh,
+	)
+	if !verifies {
+		return store.ErrCorruptedData
+	}
+
+	return nil
+}
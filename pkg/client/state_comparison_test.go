@@ -0,0 +1,73 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"os"
+	"testing"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/server"
+	"github.com/codenotary/immudb/pkg/server/servertest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestImmuClient_CompareState(t *testing.T) {
+	options := server.DefaultOptions().WithAuth(true)
+	bs := servertest.NewBufconnServer(options)
+
+	defer os.RemoveAll(options.Dir)
+	defer os.Remove(".state-")
+
+	bs.Start()
+	defer bs.Stop()
+
+	ts := NewTokenService().WithTokenFileName("testTokenFile").WithHds(DefaultHomedirServiceMock())
+	opts := DefaultOptions().WithDialOptions(&[]grpc.DialOption{grpc.WithContextDialer(bs.Dialer), grpc.WithInsecure()}).WithTokenService(ts)
+
+	client, err := NewImmuClient(opts)
+	require.NoError(t, err)
+	defer client.Disconnect()
+
+	lr, err := client.Login(context.TODO(), []byte(`immudb`), []byte(`immudb`))
+	require.NoError(t, err)
+
+	ctx := metadata.NewOutgoingContext(context.Background(), metadata.Pairs("authorization", lr.Token))
+
+	_, err = client.VerifiedSet(ctx, []byte("key1"), []byte("value1"))
+	require.NoError(t, err)
+
+	cmp, err := client.CompareState(ctx)
+	require.NoError(t, err)
+	require.Equal(t, schema.StateRelation_STATE_EQUAL, cmp.Relation)
+
+	_, err = client.Set(ctx, []byte("key2"), []byte("value2"))
+	require.NoError(t, err)
+
+	cmp, err = client.CompareState(ctx)
+	require.NoError(t, err)
+	require.Equal(t, schema.StateRelation_STATE_SERVER_AHEAD, cmp.Relation)
+	require.NotNil(t, cmp.DualProof)
+
+	cmp, err = client.CompareState(ctx, &schema.ImmutableState{TxId: 1, TxHash: make([]byte, sha256.Size)})
+	require.NoError(t, err)
+	require.Equal(t, schema.StateRelation_STATE_FORKED, cmp.Relation)
+	require.Equal(t, uint64(1), cmp.FirstDivergentTx)
+}
@@ -211,7 +211,12 @@ func (c *immuClient) StreamVerifiedSet(ctx context.Context, kvs []*stream.KeyVal
 		return nil, err
 	}
 
-	if verifiableTx.Tx.Metadata.Nentries != int32(len(kvs)) {
+	err = verifyTxAttribution(verifiableTx.Tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isExpectedTxEntries(verifiableTx.Tx, len(kvs), "") {
 		return nil, store.ErrCorruptedData
 	}
 
@@ -325,7 +330,7 @@ func (c *immuClient) StreamVerifiedGet(ctx context.Context, req *schema.Verifiab
 
 	if vEntry.Entry.ReferencedBy == nil {
 		vTx = vEntry.Entry.Tx
-		kv = database.EncodeKV(req.KeyRequest.Key, vEntry.Entry.Value)
+		kv = entryKV(req.KeyRequest.Key, vEntry.Entry)
 	} else {
 		vTx = vEntry.Entry.ReferencedBy.Tx
 		kv = database.EncodeReference(vEntry.Entry.ReferencedBy.Key, vEntry.Entry.Key, vEntry.Entry.ReferencedBy.AtTx)
@@ -481,6 +486,76 @@ func (c *immuClient) StreamHistory(ctx context.Context, req *schema.HistoryReque
 	return &schema.Entries{Entries: entries}, nil
 }
 
+// HistoryStream reads the history of a key in batches, calling onEntries for each received batch.
+// Reading stops at the first error returned by onEntries.
+func (c *immuClient) HistoryStream(ctx context.Context, req *schema.HistoryStreamRequest, onEntries func(*schema.Entries) error) error {
+	if onEntries == nil {
+		return ErrIllegalArguments
+	}
+
+	if !c.IsConnected() {
+		return ErrNotConnected
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	hs, err := c.ServiceClient.HistoryStream(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	for {
+		entries, err := hs.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		err = onEntries(entries)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// ZScanStream reads the entries of a sorted set in batches, calling onEntries for each received batch.
+// Reading stops at the first error returned by onEntries.
+func (c *immuClient) ZScanStream(ctx context.Context, req *schema.ZScanStreamRequest, onEntries func(*schema.ZEntries) error) error {
+	if onEntries == nil {
+		return ErrIllegalArguments
+	}
+
+	if !c.IsConnected() {
+		return ErrNotConnected
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	zs, err := c.ServiceClient.ZScanStream(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	for {
+		entries, err := zs.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		err = onEntries(entries)
+		if err != nil {
+			return err
+		}
+	}
+}
+
 func (c *immuClient) StreamExecAll(ctx context.Context, req *stream.ExecAllRequest) (*schema.TxMetadata, error) {
 	s, err := c.streamExecAll(ctx)
 	if err != nil {
@@ -121,3 +121,176 @@ func TestImmuClient_StreamHistory(t *testing.T) {
 
 	require.Len(t, historyResp.Entries, 100)
 }
+
+func TestImmuClient_HistoryStream(t *testing.T) {
+	options := server.DefaultOptions().WithAuth(true)
+	bs := servertest.NewBufconnServer(options)
+
+	defer os.RemoveAll(options.Dir)
+	defer os.Remove(".state-")
+
+	bs.Start()
+	defer bs.Stop()
+
+	client, err := NewImmuClient(DefaultOptions().WithDialOptions(
+		&[]grpc.DialOption{grpc.WithContextDialer(bs.Dialer), grpc.WithInsecure()},
+	))
+	require.NoError(t, err)
+	defer client.Disconnect()
+
+	lr, err := client.Login(context.TODO(), []byte(`immudb`), []byte(`immudb`))
+	require.NoError(t, err)
+
+	md := metadata.Pairs("authorization", lr.Token)
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	k := []byte("HistoryStreamTestKey")
+
+	txs := make([]uint64, 100)
+	for i := range txs {
+		meta, err := client.Set(ctx, k, []byte(fmt.Sprintf("val-%d", i)))
+		require.NoError(t, err)
+		txs[i] = meta.Id
+	}
+
+	readHistory := func(req *schema.HistoryStreamRequest) (batches int, entries []*schema.Entry) {
+		err := client.HistoryStream(ctx, req, func(es *schema.Entries) error {
+			batches++
+			entries = append(entries, es.Entries...)
+			return nil
+		})
+		require.NoError(t, err)
+		return batches, entries
+	}
+
+	batches, entries := readHistory(&schema.HistoryStreamRequest{Key: k, SinceTx: txs[99], BatchSize: 30})
+	require.Equal(t, 4, batches)
+	require.Len(t, entries, 100)
+	for i, e := range entries {
+		require.Equal(t, txs[i], e.Tx)
+		require.Equal(t, []byte(fmt.Sprintf("val-%d", i)), e.Value)
+	}
+
+	_, entries = readHistory(&schema.HistoryStreamRequest{Key: k, FromTx: txs[10], ToTx: txs[19], BatchSize: 7})
+	require.Len(t, entries, 10)
+	require.Equal(t, txs[10], entries[0].Tx)
+	require.Equal(t, txs[19], entries[9].Tx)
+
+	_, entries = readHistory(&schema.HistoryStreamRequest{Key: k, Desc: true, FromTx: txs[10], ToTx: txs[19], BatchSize: 7})
+	require.Len(t, entries, 10)
+	require.Equal(t, txs[19], entries[0].Tx)
+	require.Equal(t, txs[10], entries[9].Tx)
+
+	_, entries = readHistory(&schema.HistoryStreamRequest{Key: k, Desc: true, Limit: 5})
+	require.Len(t, entries, 5)
+	require.Equal(t, txs[99], entries[0].Tx)
+
+	err = client.HistoryStream(ctx, &schema.HistoryStreamRequest{Key: []byte("HistoryStreamNonExistentKey")}, func(*schema.Entries) error { return nil })
+	require.Error(t, err)
+
+	err = client.HistoryStream(ctx, &schema.HistoryStreamRequest{Key: k, FromTx: 2, ToTx: 1}, func(*schema.Entries) error { return nil })
+	require.Error(t, err)
+
+	err = client.HistoryStream(ctx, &schema.HistoryStreamRequest{Key: k}, nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, entries = readHistory(&schema.HistoryStreamRequest{Key: k, Limit: 10, WithInclusionProof: true})
+	require.Len(t, entries, 10)
+	for _, e := range entries {
+		require.NoError(t, VerifyEntryInclusion(e))
+	}
+
+	entries[0].Value = []byte("tampered")
+	require.Error(t, VerifyEntryInclusion(entries[0]))
+
+	_, entries = readHistory(&schema.HistoryStreamRequest{Key: k, Limit: 1, WithTxMetadata: true})
+	require.Equal(t, txs[0], entries[0].TxMetadata.Id)
+	require.Equal(t, ErrIllegalArguments, VerifyEntryInclusion(entries[0]))
+
+	history, err := client.History(ctx, &schema.HistoryRequest{Key: k, Limit: 5, WithInclusionProof: true})
+	require.NoError(t, err)
+	for _, e := range history.Entries {
+		require.NoError(t, VerifyEntryInclusion(e))
+	}
+
+	errStop := fmt.Errorf("stop")
+	err = client.HistoryStream(ctx, &schema.HistoryStreamRequest{Key: k, BatchSize: 10}, func(*schema.Entries) error { return errStop })
+	require.Equal(t, errStop, err)
+}
+
+func TestImmuClient_ZScanStream(t *testing.T) {
+	options := server.DefaultOptions().WithAuth(true)
+	bs := servertest.NewBufconnServer(options)
+
+	defer os.RemoveAll(options.Dir)
+	defer os.Remove(".state-")
+
+	bs.Start()
+	defer bs.Stop()
+
+	client, err := NewImmuClient(DefaultOptions().WithDialOptions(
+		&[]grpc.DialOption{grpc.WithContextDialer(bs.Dialer), grpc.WithInsecure()},
+	))
+	require.NoError(t, err)
+	defer client.Disconnect()
+
+	lr, err := client.Login(context.TODO(), []byte(`immudb`), []byte(`immudb`))
+	require.NoError(t, err)
+
+	md := metadata.Pairs("authorization", lr.Token)
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	set := []byte("ZScanStreamTestSet")
+
+	for i := 0; i < 100; i++ {
+		k := []byte(fmt.Sprintf("key-%03d", i))
+
+		_, err = client.Set(ctx, k, []byte(fmt.Sprintf("val-%d", i)))
+		require.NoError(t, err)
+
+		_, err = client.ZAdd(ctx, set, float64(i), k)
+		require.NoError(t, err)
+	}
+
+	readSet := func(req *schema.ZScanStreamRequest) (batches int, entries []*schema.ZEntry) {
+		err := client.ZScanStream(ctx, req, func(es *schema.ZEntries) error {
+			batches++
+			entries = append(entries, es.Entries...)
+			return nil
+		})
+		require.NoError(t, err)
+		return batches, entries
+	}
+
+	batches, entries := readSet(&schema.ZScanStreamRequest{Request: &schema.ZScanRequest{Set: set}, BatchSize: 30})
+	require.Equal(t, 4, batches)
+	require.Len(t, entries, 100)
+	for i, e := range entries {
+		require.Equal(t, float64(i), e.Score)
+		require.Equal(t, []byte(fmt.Sprintf("val-%d", i)), e.Entry.Value)
+	}
+
+	batches, entries = readSet(&schema.ZScanStreamRequest{Request: &schema.ZScanRequest{Set: set, Limit: 45, Desc: true}, BatchSize: 20})
+	require.Equal(t, 3, batches)
+	require.Len(t, entries, 45)
+	require.Equal(t, float64(99), entries[0].Score)
+	require.Equal(t, float64(55), entries[44].Score)
+
+	_, entries = readSet(&schema.ZScanStreamRequest{Request: &schema.ZScanRequest{
+		Set:      set,
+		MinScore: &schema.Score{Score: 10},
+		MaxScore: &schema.Score{Score: 19},
+	}, BatchSize: 3})
+	require.Len(t, entries, 10)
+	require.Equal(t, float64(10), entries[0].Score)
+
+	err = client.ZScanStream(ctx, &schema.ZScanStreamRequest{}, func(*schema.ZEntries) error { return nil })
+	require.Error(t, err)
+
+	err = client.ZScanStream(ctx, &schema.ZScanStreamRequest{Request: &schema.ZScanRequest{Set: set}}, nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	errStop := fmt.Errorf("stop")
+	err = client.ZScanStream(ctx, &schema.ZScanStreamRequest{Request: &schema.ZScanRequest{Set: set}, BatchSize: 10}, func(*schema.ZEntries) error { return errStop })
+	require.Equal(t, errStop, err)
+}
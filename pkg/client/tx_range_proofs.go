@@ -0,0 +1,214 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"time"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+)
+
+// VerifiedTxRange retrieves and verifies the chunked proofs of the transactions in the range [initialTx, finalTx].
+// When the local state precedes initialTx, the proofs are chained from it and the local state is updated to finalTx.
+// The returned proofs and state can be persisted as verification evidence of the whole range
+func (c *immuClient) VerifiedTxRange(ctx context.Context, initialTx, finalTx uint64, chunkSize uint32) (*schema.VerifiableTxRange, error) {
+	err := c.StateService.CacheLock()
+	if err != nil {
+		return nil, err
+	}
+	defer c.StateService.CacheUnlock()
+
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	start := time.Now()
+	defer func() { c.Logger.Debugf("VerifiedTxRange finished in %s", time.Since(start)) }()
+
+	db := c.currentDatabase()
+
+	state, err := c.StateService.GetState(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &schema.TxRangeProofsRequest{
+		InitialTx: initialTx,
+		FinalTx:   finalTx,
+		ChunkSize: chunkSize,
+	}
+
+	if state.TxId > 0 && state.TxId < initialTx {
+		req.ProveSinceTx = state.TxId
+	}
+
+	res, err := c.ServiceClient.TxRangeProofs(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res.Chunks) == 0 || res.State == nil || res.State.Db != db {
+		return nil, store.ErrCorruptedData
+	}
+
+	var sourceID uint64
+	var sourceAlh [sha256.Size]byte
+
+	if req.ProveSinceTx > 0 {
+		sourceID = state.TxId
+		sourceAlh = schema.DigestFrom(state.TxHash)
+	} else {
+		firstProof := res.Chunks[0].DualProof
+		if firstProof == nil || firstProof.SourceTxMetadata == nil || firstProof.SourceTxMetadata.Id != initialTx {
+			return nil, store.ErrCorruptedData
+		}
+
+		sourceID = initialTx
+		sourceAlh = schema.TxMetadataFrom(firstProof.SourceTxMetadata).Alh()
+	}
+
+	for _, chunk := range res.Chunks {
+		if chunk.DualProof == nil {
+			return nil, store.ErrCorruptedData
+		}
+
+		dualProof := schema.DualProofFrom(chunk.DualProof)
+
+		if dualProof.SourceTxMetadata.ID != sourceID ||
+			dualProof.SourceTxMetadata.Alh() != sourceAlh ||
+			dualProof.TargetTxMetadata.ID != chunk.LastTx {
+			return nil, store.ErrCorruptedData
+		}
+
+		targetAlh := dualProof.TargetTxMetadata.Alh()
+
+		if !store.VerifyDualProof(dualProof, sourceID, chunk.LastTx, sourceAlh, targetAlh) {
+			return nil, store.ErrCorruptedData
+		}
+
+		sourceID = chunk.LastTx
+		sourceAlh = targetAlh
+	}
+
+	if res.State.TxId != sourceID || !bytes.Equal(res.State.TxHash, sourceAlh[:]) {
+		return nil, store.ErrCorruptedData
+	}
+
+	if c.serverSigningPubKey != nil {
+		ok, err := res.State.CheckSignature(c.serverSigningPubKey)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, store.ErrCorruptedData
+		}
+	}
+
+	if req.ProveSinceTx > 0 || state.TxId == 0 {
+		err = c.StateService.SetState(db, &schema.ImmutableState{
+			Db:        db,
+			TxId:      res.State.TxId,
+			TxHash:    res.State.TxHash,
+			Signature: res.State.Signature,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// VerifyStateConsistency validates a previously obtained state, e.g. a signed state archived long ago, against a later
+// one. When target is nil the source state is validated against the current server state, which is returned once verified
+func (c *immuClient) VerifyStateConsistency(ctx context.Context, source, target *schema.ImmutableState) (*schema.ImmutableState, error) {
+	if source == nil || source.TxId == 0 {
+		return nil, ErrIllegalArguments
+	}
+
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	start := time.Now()
+	defer func() { c.Logger.Debugf("VerifyStateConsistency finished in %s", time.Since(start)) }()
+
+	db := c.currentDatabase()
+
+	if source.Db != db || (target != nil && (target.Db != db || target.TxId < source.TxId)) {
+		return nil, ErrIllegalArguments
+	}
+
+	if c.serverSigningPubKey != nil {
+		ok, err := source.CheckSignature(c.serverSigningPubKey)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, store.ErrCorruptedData
+		}
+	}
+
+	req := &schema.ConsistencyProofRequest{SourceTx: source.TxId}
+	if target != nil {
+		req.TargetTx = target.TxId
+	}
+
+	res, err := c.ServiceClient.ConsistencyProof(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.DualProof == nil || res.State == nil || res.State.Db != db {
+		return nil, store.ErrCorruptedData
+	}
+
+	if target != nil && (res.State.TxId != target.TxId || !bytes.Equal(res.State.TxHash, target.TxHash)) {
+		return nil, store.ErrCorruptedData
+	}
+
+	dualProof := schema.DualProofFrom(res.DualProof)
+
+	sourceAlh := schema.DigestFrom(source.TxHash)
+	targetAlh := schema.DigestFrom(res.State.TxHash)
+
+	if dualProof.SourceTxMetadata.ID != source.TxId ||
+		dualProof.SourceTxMetadata.Alh() != sourceAlh ||
+		dualProof.TargetTxMetadata.ID != res.State.TxId ||
+		dualProof.TargetTxMetadata.Alh() != targetAlh {
+		return nil, store.ErrCorruptedData
+	}
+
+	if !store.VerifyDualProof(dualProof, source.TxId, res.State.TxId, sourceAlh, targetAlh) {
+		return nil, store.ErrCorruptedData
+	}
+
+	if c.serverSigningPubKey != nil {
+		ok, err := res.State.CheckSignature(c.serverSigningPubKey)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, store.ErrCorruptedData
+		}
+	}
+
+	return res.State, nil
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package client
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/server"
+	"github.com/codenotary/immudb/pkg/server/servertest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestImmuClient_VerifiedTxRange(t *testing.T) {
+	options := server.DefaultOptions().WithAuth(true)
+	bs := servertest.NewBufconnServer(options)
+
+	defer os.RemoveAll(options.Dir)
+	defer os.Remove(".state-")
+
+	bs.Start()
+	defer bs.Stop()
+
+	ts := NewTokenService().WithTokenFileName("testTokenFile").WithHds(DefaultHomedirServiceMock())
+	client, err := NewImmuClient(DefaultOptions().WithDialOptions(&[]grpc.DialOption{grpc.WithContextDialer(bs.Dialer), grpc.WithInsecure()}).WithTokenService(ts))
+	require.NoError(t, err)
+	defer client.Disconnect()
+
+	lr, err := client.Login(context.TODO(), []byte(`immudb`), []byte(`immudb`))
+	require.NoError(t, err)
+
+	md := metadata.Pairs("authorization", lr.Token)
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	_, err = client.VerifiedSet(ctx, []byte("key0"), []byte("value0"))
+	require.NoError(t, err)
+
+	var initialTx, finalTx uint64
+
+	for i := 1; i <= 10; i++ {
+		txMeta, err := client.Set(ctx, []byte("key"), []byte{byte(i)})
+		require.NoError(t, err)
+
+		if initialTx == 0 {
+			initialTx = txMeta.Id
+		}
+		finalTx = txMeta.Id
+	}
+
+	res, err := client.VerifiedTxRange(ctx, initialTx, 0, 3)
+	require.NoError(t, err)
+	require.Len(t, res.Chunks, 4)
+	require.Equal(t, finalTx, res.State.TxId)
+
+	state, err := client.CurrentState(ctx)
+	require.NoError(t, err)
+	require.Equal(t, finalTx, state.TxId)
+
+	res, err = client.VerifiedTxRange(ctx, initialTx, initialTx+1, 0)
+	require.NoError(t, err)
+	require.Len(t, res.Chunks, 1)
+	require.Equal(t, initialTx+1, res.State.TxId)
+
+	_, err = client.VerifiedTxRange(ctx, 0, 0, 0)
+	require.Error(t, err)
+
+	client.Disconnect()
+
+	_, err = client.VerifiedTxRange(ctx, initialTx, 0, 0)
+	require.Equal(t, ErrNotConnected, err)
+}
+
+func TestImmuClient_VerifyStateConsistency(t *testing.T) {
+	options := server.DefaultOptions().WithAuth(true)
+	bs := servertest.NewBufconnServer(options)
+
+	defer os.RemoveAll(options.Dir)
+	defer os.Remove(".state-")
+
+	bs.Start()
+	defer bs.Stop()
+
+	ts := NewTokenService().WithTokenFileName("testTokenFile").WithHds(DefaultHomedirServiceMock())
+	client, err := NewImmuClient(DefaultOptions().WithDialOptions(&[]grpc.DialOption{grpc.WithContextDialer(bs.Dialer), grpc.WithInsecure()}).WithTokenService(ts))
+	require.NoError(t, err)
+	defer client.Disconnect()
+
+	lr, err := client.Login(context.TODO(), []byte(`immudb`), []byte(`immudb`))
+	require.NoError(t, err)
+
+	md := metadata.Pairs("authorization", lr.Token)
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	_, err = client.Set(ctx, []byte("key"), []byte("value"))
+	require.NoError(t, err)
+
+	archived, err := client.CurrentState(ctx)
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		_, err := client.Set(ctx, []byte("key"), []byte{byte(i)})
+		require.NoError(t, err)
+	}
+
+	middle, err := client.CurrentState(ctx)
+	require.NoError(t, err)
+
+	_, err = client.Set(ctx, []byte("key"), []byte("value"))
+	require.NoError(t, err)
+
+	state, err := client.VerifyStateConsistency(ctx, archived, nil)
+	require.NoError(t, err)
+	require.Equal(t, middle.TxId+1, state.TxId)
+
+	state, err = client.VerifyStateConsistency(ctx, archived, middle)
+	require.NoError(t, err)
+	require.Equal(t, middle.TxHash, state.TxHash)
+
+	tampered := &schema.ImmutableState{Db: archived.Db, TxId: archived.TxId, TxHash: make([]byte, len(archived.TxHash))}
+
+	_, err = client.VerifyStateConsistency(ctx, tampered, middle)
+	require.Equal(t, store.ErrCorruptedData, err)
+
+	_, err = client.VerifyStateConsistency(ctx, middle, archived)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = client.VerifyStateConsistency(ctx, nil, nil)
+	require.Equal(t, ErrIllegalArguments, err)
+}
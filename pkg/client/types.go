@@ -21,6 +21,7 @@ import (
 	"github.com/codenotary/immudb/pkg/stream"
 
 	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/client/cache"
 	"github.com/codenotary/immudb/pkg/client/state"
 	"github.com/codenotary/immudb/pkg/logger"
 	"google.golang.org/grpc"
@@ -62,6 +63,12 @@ func (c *immuClient) WithStreamServiceFactory(ssf stream.ServiceFactory) *immuCl
 	return c
 }
 
+// WithEntryCache set the local entry cache
+func (c *immuClient) WithEntryCache(entryCache cache.EntryCache) *immuClient {
+	c.EntryCache = entryCache
+	return c
+}
+
 func (c *immuClient) WithOptions(options *Options) *immuClient {
 	c.Options = options
 	return c
@@ -0,0 +1,222 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"time"
+
+	"github.com/codenotary/immudb/embedded/ahtree"
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/golang/protobuf/ptypes/empty"
+)
+
+// DefaultVerifyDBBatchSize is the number of transactions fetched at once when verifying a whole database
+const DefaultVerifyDBBatchSize = 100
+
+// DBVerification holds the progress of a full verification of a database.
+// It can be persisted and passed back to VerifyDB to resume the verification from the last verified transaction
+type DBVerification struct {
+	Db   string `json:"db"`
+	TxID uint64 `json:"txId"` // last verified transaction
+	Alh  []byte `json:"alh"`  // accumulative linear hash of the last verified transaction
+
+	BlSize    uint64   `json:"blSize"`    // number of transactions linked into the binary linking tree
+	BlRoot    []byte   `json:"blRoot"`    // root of the binary linking tree
+	BlPeaks   [][]byte `json:"blPeaks"`   // roots of the complete subtrees of the binary linking tree, largest first
+	BlPending [][]byte `json:"blPending"` // alh of the verified transactions not yet linked into the tree
+}
+
+// VerifyDB independently audits the current database: every transaction is fetched, its entries hash, accumulative
+// linear hash and binary linking root are recomputed locally, and the resulting chain is compared against the signed
+// server state and the local state. Verification resumes from the given progress when not nil, progress is reported
+// after every batch of transactions and may be persisted to resume an interrupted verification
+func (c *immuClient) VerifyDB(ctx context.Context, from *DBVerification, batchSize uint32, progress func(v *DBVerification, lastTx uint64) error) (*DBVerification, error) {
+	err := c.StateService.CacheLock()
+	if err != nil {
+		return nil, err
+	}
+	defer c.StateService.CacheUnlock()
+
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	start := time.Now()
+	defer func() { c.Logger.Debugf("VerifyDB finished in %s", time.Since(start)) }()
+
+	if batchSize == 0 {
+		batchSize = DefaultVerifyDBBatchSize
+	}
+
+	db := c.currentDatabase()
+
+	serverState, err := c.ServiceClient.CurrentState(ctx, &empty.Empty{})
+	if err != nil {
+		return nil, err
+	}
+
+	if serverState.Db != db {
+		return nil, store.ErrCorruptedData
+	}
+
+	if c.serverSigningPubKey != nil {
+		ok, err := serverState.CheckSignature(c.serverSigningPubKey)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, store.ErrCorruptedData
+		}
+	}
+
+	localState, err := c.StateService.GetState(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if localState.TxId > serverState.TxId {
+		return nil, ErrServerStateIsOlder
+	}
+
+	v := from
+	if v == nil {
+		// transactions are chained from the hash of the empty log
+		alh := sha256.Sum256(nil)
+		v = &DBVerification{Db: db, Alh: alh[:]}
+	}
+
+	if v.Db != db || v.TxID > serverState.TxId {
+		return nil, ErrIllegalArguments
+	}
+
+	for v.TxID < serverState.TxId {
+		list, err := c.ServiceClient.TxScan(ctx, &schema.TxScanRequest{InitialTx: v.TxID + 1, Limit: batchSize})
+		if err != nil {
+			return nil, err
+		}
+
+		if len(list.Txs) == 0 {
+			return nil, store.ErrCorruptedData
+		}
+
+		for _, stx := range list.Txs {
+			if v.TxID == serverState.TxId {
+				break
+			}
+
+			err = v.verifyTx(stx)
+			if err != nil {
+				return nil, err
+			}
+
+			if localState.TxId == v.TxID && !bytes.Equal(localState.TxHash, v.Alh) {
+				return nil, store.ErrCorruptedData
+			}
+		}
+
+		if progress != nil {
+			err = progress(v, serverState.TxId)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if !bytes.Equal(serverState.TxHash, v.Alh) {
+		return nil, store.ErrCorruptedData
+	}
+
+	return v, nil
+}
+
+// verifyTx recomputes the hashes of the transaction following the last verified one and chains it
+func (v *DBVerification) verifyTx(stx *schema.Tx) error {
+	if stx.Metadata == nil || stx.Metadata.Id != v.TxID+1 {
+		return store.ErrCorruptedData
+	}
+
+	tx := schema.TxFrom(stx)
+
+	eh := tx.Eh()
+	if !bytes.Equal(stx.Metadata.EH, eh[:]) {
+		return store.ErrCorruptedData
+	}
+
+	var prevAlh [sha256.Size]byte
+	copy(prevAlh[:], v.Alh)
+
+	if tx.PrevAlh != prevAlh {
+		return store.ErrCorruptedData
+	}
+
+	if tx.BlTxID >= tx.ID || tx.BlTxID < v.BlSize {
+		return store.ErrCorruptedData
+	}
+
+	for v.BlSize < tx.BlTxID {
+		v.link()
+	}
+
+	var blRoot [sha256.Size]byte
+	copy(blRoot[:], v.BlRoot)
+
+	if tx.BlRoot != blRoot {
+		return store.ErrCorruptedData
+	}
+
+	v.TxID = tx.ID
+	v.Alh = tx.Alh[:]
+	v.BlPending = append(v.BlPending, tx.Alh[:])
+
+	return nil
+}
+
+// link appends the oldest pending alh into the binary linking tree, as done by the store when committing
+func (v *DBVerification) link() {
+	b := make([]byte, 1+sha256.Size)
+	b[0] = ahtree.LeafPrefix
+	copy(b[1:], v.BlPending[0])
+	v.BlPending = v.BlPending[1:]
+
+	leaf := sha256.Sum256(b)
+
+	root := leaf
+	for i := len(v.BlPeaks) - 1; i >= 0; i-- {
+		root = nodeHash(v.BlPeaks[i], root[:])
+	}
+
+	v.BlSize++
+	v.BlRoot = root[:]
+
+	v.BlPeaks = append(v.BlPeaks, leaf[:])
+	for n := v.BlSize; n%2 == 0; n /= 2 {
+		last := len(v.BlPeaks) - 1
+		h := nodeHash(v.BlPeaks[last-1], v.BlPeaks[last])
+		v.BlPeaks = append(v.BlPeaks[:last-1], h[:])
+	}
+}
+
+func nodeHash(left, right []byte) [sha256.Size]byte {
+	b := make([]byte, 1+2*sha256.Size)
+	b[0] = ahtree.NodePrefix
+	copy(b[1:], left)
+	copy(b[1+sha256.Size:], right)
+	return sha256.Sum256(b)
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package client
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/server"
+	"github.com/codenotary/immudb/pkg/server/servertest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestImmuClient_VerifyDB(t *testing.T) {
+	options := server.DefaultOptions().WithAuth(true)
+	bs := servertest.NewBufconnServer(options)
+
+	defer os.RemoveAll(options.Dir)
+	defer os.Remove(".state-")
+
+	bs.Start()
+	defer bs.Stop()
+
+	ts := NewTokenService().WithTokenFileName("testTokenFile").WithHds(DefaultHomedirServiceMock())
+	client, err := NewImmuClient(DefaultOptions().WithDialOptions(&[]grpc.DialOption{grpc.WithContextDialer(bs.Dialer), grpc.WithInsecure()}).WithTokenService(ts))
+	require.NoError(t, err)
+	defer client.Disconnect()
+
+	lr, err := client.Login(context.TODO(), []byte(`immudb`), []byte(`immudb`))
+	require.NoError(t, err)
+
+	md := metadata.Pairs("authorization", lr.Token)
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	for i := 0; i < 10; i++ {
+		_, err := client.Set(ctx, []byte("key"), []byte{byte(i)})
+		require.NoError(t, err)
+	}
+
+	_, err = client.VerifiedGet(ctx, []byte("key"))
+	require.NoError(t, err)
+
+	var reported []uint64
+
+	v, err := client.VerifyDB(ctx, nil, 3, func(v *DBVerification, lastTx uint64) error {
+		reported = append(reported, v.TxID)
+		require.LessOrEqual(t, v.TxID, lastTx)
+		return nil
+	})
+	require.NoError(t, err)
+
+	state, err := client.CurrentState(ctx)
+	require.NoError(t, err)
+	require.Equal(t, state.TxId, v.TxID)
+	require.Equal(t, state.TxHash, v.Alh)
+	require.Len(t, reported, int((state.TxId+2)/3))
+
+	for i := 0; i < 5; i++ {
+		_, err := client.Set(ctx, []byte("key"), []byte{byte(i)})
+		require.NoError(t, err)
+	}
+
+	resumedFrom := v.TxID
+
+	v, err = client.VerifyDB(ctx, v, 0, nil)
+	require.NoError(t, err)
+	require.Equal(t, resumedFrom+5, v.TxID)
+
+	v.Alh = make([]byte, len(v.Alh))
+
+	_, err = client.Set(ctx, []byte("key"), []byte("value"))
+	require.NoError(t, err)
+
+	_, err = client.VerifyDB(ctx, v, 0, nil)
+	require.Equal(t, store.ErrCorruptedData, err)
+
+	_, err = client.VerifyDB(ctx, &DBVerification{Db: "otherdb"}, 0, nil)
+	require.Equal(t, ErrIllegalArguments, err)
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package client
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+)
+
+// Watch calls onEvent for each transaction with entries matching the request, as they are committed, until ctx is
+// done or onEvent returns an error. When a subscription id is provided, each event is acknowledged once onEvent
+// returns successfully, so events not fully processed are delivered again when watching with the same id.
+func (c *immuClient) Watch(ctx context.Context, req *schema.WatchRequest, onEvent func(*schema.WatchEvent) error) error {
+	if req == nil || onEvent == nil {
+		return ErrIllegalArguments
+	}
+
+	if !c.IsConnected() {
+		return ErrNotConnected
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	ws, err := c.ServiceClient.Watch(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	for {
+		event, err := ws.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		err = onEvent(event)
+		if err != nil {
+			return err
+		}
+
+		if req.SubscriptionId != "" {
+			err = c.WatchAck(ctx, req.SubscriptionId, event.Tx)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// WatchAck acknowledges the events of a subscription up to the given transaction
+func (c *immuClient) WatchAck(ctx context.Context, subscriptionID string, tx uint64) error {
+	start := time.Now()
+
+	if !c.IsConnected() {
+		return ErrNotConnected
+	}
+
+	_, err := c.ServiceClient.WatchAck(ctx, &schema.WatchAckRequest{SubscriptionId: subscriptionID, Tx: tx})
+
+	c.Logger.Debugf("WatchAck finished in %s", time.Since(start))
+
+	return err
+}
+
+// Unwatch drops a subscription from the server
+func (c *immuClient) Unwatch(ctx context.Context, subscriptionID string) error {
+	start := time.Now()
+
+	if !c.IsConnected() {
+		return ErrNotConnected
+	}
+
+	_, err := c.ServiceClient.Unwatch(ctx, &schema.UnwatchRequest{SubscriptionId: subscriptionID})
+
+	c.Logger.Debugf("Unwatch finished in %s", time.Since(start))
+
+	return err
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package client
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/server"
+	"github.com/codenotary/immudb/pkg/server/servertest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestImmuClient_Watch(t *testing.T) {
+	options := server.DefaultOptions().WithAuth(true)
+	bs := servertest.NewBufconnServer(options)
+
+	defer os.RemoveAll(options.Dir)
+	defer os.Remove(".state-")
+
+	bs.Start()
+	defer bs.Stop()
+
+	ts := NewTokenService().WithTokenFileName("testTokenFile").WithHds(DefaultHomedirServiceMock())
+	client, err := NewImmuClient(DefaultOptions().WithDialOptions(&[]grpc.DialOption{grpc.WithContextDialer(bs.Dialer), grpc.WithInsecure()}).WithTokenService(ts))
+	require.NoError(t, err)
+	defer client.Disconnect()
+
+	lr, err := client.Login(context.TODO(), []byte(`immudb`), []byte(`immudb`))
+	require.NoError(t, err)
+
+	md := metadata.Pairs("authorization", lr.Token)
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	txmd1, err := client.Set(ctx, []byte("orders:1"), []byte(`{"status":"open"}`))
+	require.NoError(t, err)
+
+	_, err = client.Set(ctx, []byte("orders:2"), []byte(`{"status":"closed"}`))
+	require.NoError(t, err)
+
+	txmd3, err := client.Set(ctx, []byte("orders:3"), []byte(`{"status":"open"}`))
+	require.NoError(t, err)
+
+	req := &schema.WatchRequest{
+		SubscriptionId: "sub1",
+		Prefixes:       [][]byte{[]byte("orders:")},
+		Filter:         &schema.WatchFilter{Path: "status", Equals: "open"},
+		SinceTx:        txmd1.Id,
+	}
+
+	errProcessing := errors.New("processing failed")
+
+	var delivered []uint64
+
+	err = client.Watch(ctx, req, func(e *schema.WatchEvent) error {
+		delivered = append(delivered, e.Tx)
+		if e.Tx == txmd3.Id {
+			return errProcessing
+		}
+		return nil
+	})
+	require.Equal(t, errProcessing, err)
+	require.Equal(t, []uint64{txmd1.Id, txmd3.Id}, delivered)
+
+	// the event which failed to be processed was not acknowledged and it's delivered again
+	err = client.Watch(ctx, req, func(e *schema.WatchEvent) error {
+		require.Equal(t, txmd3.Id, e.Tx)
+		require.Equal(t, []byte("orders:3"), e.Entries[0].Key)
+		return errProcessing
+	})
+	require.Equal(t, errProcessing, err)
+
+	err = client.WatchAck(ctx, "sub1", txmd3.Id)
+	require.NoError(t, err)
+
+	err = client.Unwatch(ctx, "sub1")
+	require.NoError(t, err)
+
+	err = client.Unwatch(ctx, "sub1")
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	err = client.Watch(ctx, nil, nil)
+	require.Equal(t, ErrIllegalArguments, err)
+}
@@ -25,7 +25,12 @@ import (
 // ExecAll like Set it permits many insertions at once.
 // The difference is that is possible to to specify a list of a mix of key value set and zAdd insertions.
 // If zAdd reference is not yet present on disk it's possible to add it as a regular key value and the reference is done onFly
+// When req.DryRun is set, operations are validated and the entries which would be written are described but not committed
 func (d *db) ExecAll(req *schema.ExecAllRequest) (*schema.TxMetadata, error) {
+	return d.execAll(req, false)
+}
+
+func (d *db) execAll(req *schema.ExecAllRequest, approved bool) (txmd *schema.TxMetadata, err error) {
 	if req == nil {
 		return nil, store.ErrIllegalArguments
 	}
@@ -34,16 +39,36 @@ func (d *db) ExecAll(req *schema.ExecAllRequest) (*schema.TxMetadata, error) {
 		return nil, err
 	}
 
+	if d.options.replica {
+		return nil, ErrIsReplica
+	}
+
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
 
+	d.secondaryIndexesMutex.RLock()
+	defer d.secondaryIndexesMutex.RUnlock()
+
+	if !req.DryRun {
+		txmd, err = d.beginIdempotentWrite(req.IdempotencyKey)
+		if txmd != nil || err != nil {
+			return txmd, err
+		}
+		defer d.endIdempotentWrite(req.IdempotencyKey, &txmd)
+	}
+
 	lastTxID, _ := d.st.Alh()
-	err := d.st.WaitForIndexingUpto(lastTxID, nil)
+	err = d.st.WaitForIndexingUpto(lastTxID, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	callback := func(txID uint64, index store.KeyIndex) ([]*store.KV, error) {
+		err := d.checkPreconditions(req.Preconditions, index)
+		if err != nil {
+			return nil, err
+		}
+
 		entries := make([]*store.KV, len(req.Operations))
 
 		// In order to:
@@ -52,6 +77,11 @@ func (d *db) ExecAll(req *schema.ExecAllRequest) (*schema.TxMetadata, error) {
 		// we build a map in which we store sha256 sum as key and the index as value
 		kmap := make(map[[sha256.Size]byte]bool)
 
+		// keys assigned a new value, counted in write-once mode
+		var keys [][]byte
+
+		var indexEntries []*store.KV
+
 		for i, op := range req.Operations {
 
 			kv := &store.KV{}
@@ -65,7 +95,25 @@ func (d *db) ExecAll(req *schema.ExecAllRequest) (*schema.TxMetadata, error) {
 					return nil, store.ErrIllegalArguments
 				}
 
-				kv = EncodeKV(x.Kv.Key, x.Kv.Value)
+				err := d.keyRules.validate(x.Kv.Key)
+				if err != nil {
+					return nil, err
+				}
+
+				if !approved {
+					err = d.approvalRules.validate(x.Kv.Key)
+					if err != nil {
+						return nil, err
+					}
+				}
+
+				kv, err = d.encodeKV(x.Kv)
+				if err != nil {
+					return nil, err
+				}
+
+				keys = append(keys, x.Kv.Key)
+				indexEntries = append(indexEntries, d.secondaryIndexEntries(x.Kv.Key, x.Kv.Value)...)
 
 			case *schema.Op_Ref:
 				if len(x.Ref.Key) == 0 || len(x.Ref.ReferencedKey) == 0 {
@@ -76,6 +124,18 @@ func (d *db) ExecAll(req *schema.ExecAllRequest) (*schema.TxMetadata, error) {
 					return nil, store.ErrIllegalArguments
 				}
 
+				err := d.keyRules.validate(x.Ref.Key)
+				if err != nil {
+					return nil, err
+				}
+
+				if !approved {
+					err = d.approvalRules.validate(x.Ref.Key)
+					if err != nil {
+						return nil, err
+					}
+				}
+
 				// check key does not exists or it's already a reference
 				entry, err := d.getAt(EncodeKey(x.Ref.Key), 0, 0, index, d.tx1)
 				if err != nil && err != store.ErrKeyNotFound {
@@ -105,6 +165,14 @@ func (d *db) ExecAll(req *schema.ExecAllRequest) (*schema.TxMetadata, error) {
 					kv = EncodeReference(x.Ref.Key, x.Ref.ReferencedKey, x.Ref.AtTx)
 				}
 
+				keys = append(keys, x.Ref.Key)
+				refEntries, err := d.referenceIndexEntries(kv)
+				if err != nil {
+					return nil, err
+				}
+
+				indexEntries = append(indexEntries, refEntries...)
+
 			case *schema.Op_ZAdd:
 				if len(x.ZAdd.Set) == 0 || len(x.ZAdd.Key) == 0 {
 					return nil, store.ErrIllegalArguments
@@ -135,18 +203,92 @@ func (d *db) ExecAll(req *schema.ExecAllRequest) (*schema.TxMetadata, error) {
 				} else {
 					kv = EncodeZAdd(x.ZAdd.Set, x.ZAdd.Score, key, x.ZAdd.AtTx)
 				}
+
+				refEntries, err := d.referenceIndexEntries(kv)
+				if err != nil {
+					return nil, err
+				}
+
+				indexEntries = append(indexEntries, refEntries...)
 			}
 
 			entries[i] = kv
 		}
 
+		err = d.checkWORMVersions(keys, index)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, indexEntries...)
+
+		if req.Attribution != nil {
+			entries = append(entries, EncodeAttribution(req.Attribution))
+		}
+
+		if e := d.idempotencyEntry(req.IdempotencyKey); e != nil {
+			entries = append(entries, e)
+		}
+
 		return entries, nil
 	}
 
+	if req.DryRun {
+		entries, err := d.st.DryRunWith(callback)
+		if err != nil {
+			return nil, err
+		}
+
+		return &schema.TxMetadata{
+			Nentries: int32(len(entries)),
+			DryRun:   dryRunResult(entries, execAllKeys(req)),
+		}, nil
+	}
+
 	txMetatadata, err := d.st.CommitWith(callback, !req.NoWait)
 	if err != nil {
 		return nil, err
 	}
 
+	for _, op := range req.Operations {
+		switch x := op.Operation.(type) {
+		case *schema.Op_Kv:
+			d.prefixStats.write(x.Kv.Key, len(x.Kv.Key)+len(x.Kv.Value))
+		case *schema.Op_Ref:
+			d.prefixStats.write(x.Ref.Key, len(x.Ref.Key)+len(x.Ref.ReferencedKey))
+		}
+	}
+
 	return schema.TxMetatadaTo(txMetatadata), nil
 }
+
+// execAllKeys returns the keys affected by the operations, sorted sets are reported by their name
+func execAllKeys(req *schema.ExecAllRequest) [][]byte {
+	keys := make([][]byte, 0, len(req.Operations))
+
+	for _, op := range req.Operations {
+		switch x := op.Operation.(type) {
+		case *schema.Op_Kv:
+			keys = append(keys, x.Kv.Key)
+		case *schema.Op_Ref:
+			keys = append(keys, x.Ref.Key)
+		case *schema.Op_ZAdd:
+			keys = append(keys, x.ZAdd.Set)
+		}
+	}
+
+	return keys
+}
+
+func dryRunResult(entries []*store.KV, keys [][]byte) *schema.DryRunResult {
+	res := &schema.DryRunResult{
+		Nentries: uint32(len(entries)),
+		Keys:     keys,
+	}
+
+	for _, e := range entries {
+		res.Bytes += uint64(len(e.Key) + len(e.Value))
+	}
+
+	return res
+}
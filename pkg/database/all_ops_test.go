@@ -16,6 +16,7 @@ limitations under the License.
 package database
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
@@ -274,7 +275,7 @@ func TestExecAllOps(t *testing.T) {
 	zScanOpt := &schema.ZScanRequest{
 		Set: []byte(`mySet`),
 	}
-	zList, err := db.ZScan(zScanOpt)
+	zList, err := db.ZScan(context.Background(), zScanOpt)
 	require.NoError(t, err)
 	println(len(zList.Entries))
 	require.Len(t, zList.Entries, batchSize)
@@ -331,7 +332,7 @@ func TestExecAllOpsZAddOnMixedAlreadyPersitedNotPersistedItems(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, uint64(2), index.Id)
 
-	list, err := db.ZScan(&schema.ZScanRequest{
+	list, err := db.ZScan(context.Background(), &schema.ZScanRequest{
 		Set:     []byte(`mySet`),
 		SinceTx: index.Id,
 	})
@@ -642,7 +643,7 @@ func TestStore_ExecAllOpsConcurrent(t *testing.T) {
 	for i := 1; i <= 10; i++ {
 		set := strconv.FormatUint(uint64(i), 10)
 
-		zList, err := db.ZScan(&schema.ZScanRequest{
+		zList, err := db.ZScan(context.Background(), &schema.ZScanRequest{
 			Set:     []byte(set),
 			SinceTx: 10,
 		})
@@ -1155,3 +1156,52 @@ func TestOps_ReferenceIndexMissing(t *testing.T) {
 	require.Equal(t, ErrReferenceIndexMissing, err)
 }
 */
+
+func TestExecAllOpsDryRun(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	_, err := db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("key1"), Value: []byte("value1")}}})
+	require.NoError(t, err)
+
+	req := &schema.ExecAllRequest{
+		Operations: []*schema.Op{
+			{Operation: &schema.Op_Kv{Kv: &schema.KeyValue{Key: []byte("key2"), Value: []byte("value2")}}},
+			{Operation: &schema.Op_Ref{Ref: &schema.ReferenceRequest{Key: []byte("ref1"), ReferencedKey: []byte("key1")}}},
+			{Operation: &schema.Op_ZAdd{ZAdd: &schema.ZAddRequest{Set: []byte("set1"), Score: 1, Key: []byte("key2")}}},
+		},
+		DryRun: true,
+	}
+
+	md, err := db.ExecAll(req)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), md.Id)
+	// the reference and the sorted set entry are written along with their reference index entries
+	require.Equal(t, int32(5), md.Nentries)
+	require.NotNil(t, md.DryRun)
+	require.Equal(t, uint32(5), md.DryRun.Nentries)
+	require.Greater(t, md.DryRun.Bytes, uint64(0))
+	require.Equal(t, [][]byte{[]byte("key2"), []byte("ref1"), []byte("set1")}, md.DryRun.Keys)
+
+	state, err := db.CurrentState()
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), state.TxId)
+
+	_, err = db.Get(&schema.KeyRequest{Key: []byte("key2")})
+	require.Error(t, err)
+
+	_, err = db.ExecAll(&schema.ExecAllRequest{
+		Operations: []*schema.Op{
+			{Operation: &schema.Op_ZAdd{ZAdd: &schema.ZAddRequest{Set: []byte("set1"), Score: 1, Key: []byte("missing")}}},
+		},
+		DryRun: true,
+	})
+	require.Equal(t, store.ErrKeyNotFound, err)
+
+	req.DryRun = false
+
+	md, err = db.ExecAll(req)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), md.Id)
+	require.Nil(t, md.DryRun)
+}
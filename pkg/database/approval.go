@@ -0,0 +1,116 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"bytes"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+)
+
+// approvalRules lists the key prefixes whose writes must be approved by a second user before being committed
+type approvalRules struct {
+	rules *schema.ApprovalRules
+}
+
+func newApprovalRules(rules *schema.ApprovalRules) (*approvalRules, error) {
+	if rules == nil || len(rules.Prefixes) == 0 {
+		return nil, nil
+	}
+
+	for _, prefix := range rules.Prefixes {
+		if len(prefix) == 0 {
+			return nil, ErrIllegalArguments
+		}
+	}
+
+	return &approvalRules{rules: rules}, nil
+}
+
+func (ar *approvalRules) requiresApproval(key []byte) bool {
+	if ar == nil {
+		return false
+	}
+
+	for _, prefix := range ar.rules.Prefixes {
+		if bytes.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (ar *approvalRules) validate(key []byte) error {
+	if ar.requiresApproval(key) {
+		return ErrApprovalRequired
+	}
+	return nil
+}
+
+// SetApprovalRules replaces the key prefixes whose writes must be approved, empty rules don't require any approval.
+// Such keys are only written through ExecAllApproved, direct writes are rejected
+func (d *db) SetApprovalRules(rules *schema.ApprovalRules) error {
+	ar, err := newApprovalRules(rules)
+	if err != nil {
+		return err
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.approvalRules = ar
+
+	return nil
+}
+
+// GetApprovalRules returns the key prefixes whose writes must be approved
+func (d *db) GetApprovalRules() *schema.ApprovalRules {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	if d.approvalRules == nil {
+		return &schema.ApprovalRules{}
+	}
+
+	return d.approvalRules.rules
+}
+
+// RequiresApproval returns true if any of the operations writes a key which must be approved
+func (d *db) RequiresApproval(req *schema.ExecAllRequest) bool {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	for _, op := range req.Operations {
+		switch x := op.Operation.(type) {
+		case *schema.Op_Kv:
+			if d.approvalRules.requiresApproval(x.Kv.Key) {
+				return true
+			}
+		case *schema.Op_Ref:
+			if d.approvalRules.requiresApproval(x.Ref.Key) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// ExecAllApproved commits operations which were approved, keys requiring approval are accepted
+func (d *db) ExecAllApproved(req *schema.ExecAllRequest) (*schema.TxMetadata, error) {
+	return d.execAll(req, true)
+}
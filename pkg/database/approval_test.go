@@ -0,0 +1,68 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"testing"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApprovalRules(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	err := db.SetApprovalRules(&schema.ApprovalRules{Prefixes: [][]byte{{}}})
+	require.Equal(t, ErrIllegalArguments, err)
+
+	err = db.SetApprovalRules(&schema.ApprovalRules{Prefixes: [][]byte{[]byte("ledger:")}})
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{[]byte("ledger:")}, db.GetApprovalRules().Prefixes)
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("ledger:1"), Value: []byte("value")}}})
+	require.Equal(t, ErrApprovalRequired, err)
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("other:1"), Value: []byte("value")}}})
+	require.NoError(t, err)
+
+	_, err = db.SetReference(&schema.ReferenceRequest{Key: []byte("ledger:ref"), ReferencedKey: []byte("other:1")})
+	require.Equal(t, ErrApprovalRequired, err)
+
+	req := &schema.ExecAllRequest{Operations: []*schema.Op{
+		{Operation: &schema.Op_Kv{Kv: &schema.KeyValue{Key: []byte("ledger:1"), Value: []byte("value")}}},
+		{Operation: &schema.Op_Ref{Ref: &schema.ReferenceRequest{Key: []byte("ref"), ReferencedKey: []byte("other:1")}}},
+	}}
+	require.True(t, db.RequiresApproval(req))
+	require.False(t, db.RequiresApproval(&schema.ExecAllRequest{Operations: req.Operations[1:]}))
+
+	_, err = db.ExecAll(req)
+	require.Equal(t, ErrApprovalRequired, err)
+
+	_, err = db.ExecAllApproved(req)
+	require.NoError(t, err)
+
+	entry, err := db.Get(&schema.KeyRequest{Key: []byte("ledger:1")})
+	require.NoError(t, err)
+	require.Equal(t, []byte("value"), entry.Value)
+
+	err = db.SetApprovalRules(&schema.ApprovalRules{})
+	require.NoError(t, err)
+	require.Empty(t, db.GetApprovalRules().Prefixes)
+
+	_, err = db.ExecAll(req)
+	require.NoError(t, err)
+}
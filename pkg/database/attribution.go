@@ -0,0 +1,86 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package database
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+)
+
+// AttributionKey is the key under which the authorship of a transaction is written as part of the transaction itself,
+// thus being covered by its hash
+var AttributionKey = []byte{AttributionKeyPrefix}
+
+// EncodeAttribution returns the entry recording the authorship of a transaction
+func EncodeAttribution(attribution *schema.Attribution) *store.KV {
+	v := make([]byte, 4+len(attribution.User)+4+len(attribution.Actor))
+
+	binary.BigEndian.PutUint32(v, uint32(len(attribution.User)))
+	copy(v[4:], attribution.User)
+
+	off := 4 + len(attribution.User)
+
+	binary.BigEndian.PutUint32(v[off:], uint32(len(attribution.Actor)))
+	copy(v[off+4:], attribution.Actor)
+
+	return &store.KV{Key: AttributionKey, Value: v}
+}
+
+// DecodeAttribution ...
+func DecodeAttribution(v []byte) (*schema.Attribution, error) {
+	if len(v) < 4 {
+		return nil, store.ErrCorruptedData
+	}
+
+	userLen := int(binary.BigEndian.Uint32(v))
+	if len(v) < 4+userLen+4 {
+		return nil, store.ErrCorruptedData
+	}
+
+	off := 4 + userLen
+
+	actorLen := int(binary.BigEndian.Uint32(v[off:]))
+	if len(v) != off+4+actorLen {
+		return nil, store.ErrCorruptedData
+	}
+
+	return &schema.Attribution{
+		User:  string(v[4:off]),
+		Actor: string(v[off+4:]),
+	}, nil
+}
+
+// attributionOf returns the authorship recorded in tx, if any
+func (d *db) attributionOf(tx *store.Tx) (*schema.Attribution, error) {
+	for _, e := range tx.Entries() {
+		if !bytes.Equal(e.Key(), AttributionKey) {
+			continue
+		}
+
+		v, err := d.st.ReadValue(tx, AttributionKey)
+		if err != nil {
+			return nil, err
+		}
+
+		return DecodeAttribution(v)
+	}
+
+	return nil, nil
+}
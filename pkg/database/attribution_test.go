@@ -0,0 +1,107 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttributionEncoding(t *testing.T) {
+	a := &schema.Attribution{User: "immudb", Actor: "billing-service"}
+
+	kv := EncodeAttribution(a)
+	require.Equal(t, AttributionKey, kv.Key)
+
+	decoded, err := DecodeAttribution(kv.Value)
+	require.NoError(t, err)
+	require.Equal(t, a.User, decoded.User)
+	require.Equal(t, a.Actor, decoded.Actor)
+
+	decoded, err = DecodeAttribution(EncodeAttribution(&schema.Attribution{}).Value)
+	require.NoError(t, err)
+	require.Empty(t, decoded.User)
+	require.Empty(t, decoded.Actor)
+
+	_, err = DecodeAttribution(nil)
+	require.Equal(t, store.ErrCorruptedData, err)
+
+	_, err = DecodeAttribution(kv.Value[:len(kv.Value)-1])
+	require.Equal(t, store.ErrCorruptedData, err)
+
+	_, err = DecodeAttribution([]byte{0, 0, 0, 10})
+	require.Equal(t, store.ErrCorruptedData, err)
+}
+
+func TestAttribution(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	meta, err := db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("k1"), Value: []byte("v1")}}})
+	require.NoError(t, err)
+	require.Equal(t, int32(1), meta.Nentries)
+
+	tx, err := db.TxByID(&schema.TxRequest{Tx: meta.Id})
+	require.NoError(t, err)
+	require.Nil(t, tx.Attribution)
+
+	attribution := &schema.Attribution{User: "immudb", Actor: "actor1"}
+
+	meta, err = db.Set(&schema.SetRequest{
+		KVs:         []*schema.KeyValue{{Key: []byte("k1"), Value: []byte("v2")}},
+		Attribution: attribution,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(2), meta.Nentries)
+
+	tx, err = db.TxByID(&schema.TxRequest{Tx: meta.Id})
+	require.NoError(t, err)
+	require.Equal(t, attribution.User, tx.Attribution.User)
+	require.Equal(t, attribution.Actor, tx.Attribution.Actor)
+
+	vtx, err := db.VerifiableTxByID(&schema.VerifiableTxRequest{Tx: meta.Id})
+	require.NoError(t, err)
+	require.Equal(t, attribution.Actor, vtx.Tx.Attribution.Actor)
+
+	meta, err = db.ExecAll(&schema.ExecAllRequest{
+		Operations: []*schema.Op{
+			{Operation: &schema.Op_Kv{Kv: &schema.KeyValue{Key: []byte("k2"), Value: []byte("v1")}}},
+		},
+		Attribution: &schema.Attribution{User: "user2"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(2), meta.Nentries)
+
+	txs, err := db.TxScan(&schema.TxScanRequest{InitialTx: 1})
+	require.NoError(t, err)
+	require.Equal(t, "user2", txs.Txs[len(txs.Txs)-1].Attribution.User)
+
+	history, err := db.History(&schema.HistoryRequest{Key: []byte("k1"), SinceTx: meta.Id, WithTxMetadata: true})
+	require.NoError(t, err)
+	require.Len(t, history.Entries, 2)
+	require.Nil(t, history.Entries[0].Attribution)
+	require.Equal(t, attribution.User, history.Entries[1].Attribution.User)
+
+	// the attribution entry is not visible as a regular key
+	list, err := db.Scan(context.Background(), &schema.ScanRequest{SinceTx: meta.Id})
+	require.NoError(t, err)
+	require.Len(t, list.Entries, 2)
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+)
+
+// Audit reads every committed transaction, checking each one is linked to its predecessor through the accumulative
+// linear hash and that the values stored in the value log match the hashes committed within their transactions.
+// The number of audited transactions is returned. When provided, progress is called after each audited transaction
+func (d *db) Audit(ctx context.Context, progress func(audited, total uint64)) (uint64, error) {
+	lastTxID, lastAlh := d.st.Alh()
+
+	tx := d.st.NewTx()
+
+	var prevAlh [sha256.Size]byte
+
+	for txID := uint64(1); txID <= lastTxID; txID++ {
+		if err := ctx.Err(); err != nil {
+			return txID - 1, err
+		}
+
+		err := d.st.ReadTx(txID, tx)
+		if err != nil {
+			return txID - 1, err
+		}
+
+		if txID > 1 && tx.PrevAlh != prevAlh {
+			d.Logger.Errorf("audit of database '%s' failed, transaction %d is not linked to its predecessor", d.name, txID)
+			return txID - 1, ErrTxChainMismatch
+		}
+
+		for _, e := range tx.Entries() {
+			_, err = d.st.ReadValueAt(make([]byte, e.VLen()), e.VOff(), e.HVal())
+			if err != nil {
+				return txID - 1, err
+			}
+		}
+
+		prevAlh = tx.Alh
+
+		if progress != nil {
+			progress(txID, lastTxID)
+		}
+	}
+
+	if lastTxID > 0 && prevAlh != lastAlh {
+		d.Logger.Errorf("audit of database '%s' failed, transaction %d does not match the current state", d.name, lastTxID)
+		return lastTxID, ErrTxChainMismatch
+	}
+
+	return lastTxID, nil
+}
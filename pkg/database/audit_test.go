@@ -0,0 +1,55 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAudit(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	n, err := db.Audit(context.Background(), nil)
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	for i := 0; i < 10; i++ {
+		_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("key"), Value: []byte("value")}}})
+		require.NoError(t, err)
+	}
+
+	var audited, total uint64
+
+	n, err = db.Audit(context.Background(), func(a, t uint64) {
+		audited, total = a, t
+	})
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), n)
+	require.Equal(t, uint64(10), audited)
+	require.Equal(t, uint64(10), total)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	n, err = db.Audit(ctx, nil)
+	require.Equal(t, context.Canceled, err)
+	require.Zero(t, n)
+}
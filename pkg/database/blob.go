@@ -0,0 +1,225 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package database
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+)
+
+// Blobs are values too large to be held by a single entry, or to fit in memory. Their content is split into chunks,
+// each one an entry under the BlobChunkKeyPrefix, committed in as many transactions as needed while it's read.
+// The key is then assigned the manifest of the blob, made of its id, size, number of chunks and the sha256 digest of
+// the whole content, so proofs of the key cover the whole content as well
+
+// DefaultBlobChunkSize is the size of the chunks blobs are split into, unless values are limited to a smaller size
+const DefaultBlobChunkSize = 1 << 20
+
+// blobTxSize bounds the size of the chunks committed by each transaction, and so the memory used to set a blob
+const blobTxSize = 16 << 20
+
+const blobManifestLen = 8 + 8 + 8 + sha256.Size
+
+// SetBlob assigns the key a blob with the content read from r, ErrKeyRejected is returned before reading anything
+// if the key can't be written
+func (d *db) SetBlob(key []byte, r io.Reader, attribution *schema.Attribution) (*schema.BlobManifest, error) {
+	if len(key) == 0 || r == nil {
+		return nil, ErrIllegalArguments
+	}
+
+	if d.options.replica {
+		return nil, ErrIsReplica
+	}
+
+	err := d.keyRules.validate(key)
+	if err != nil {
+		return nil, err
+	}
+
+	err = d.approvalRules.validate(key)
+	if err != nil {
+		return nil, err
+	}
+
+	chunkSize := DefaultBlobChunkSize
+	if d.st.MaxValueLen() < chunkSize {
+		chunkSize = d.st.MaxValueLen()
+	}
+
+	chunksPerTx := blobTxSize / chunkSize
+	if d.st.MaxTxEntries() < chunksPerTx {
+		chunksPerTx = d.st.MaxTxEntries()
+	}
+
+	digest := sha256.New()
+
+	var id, size, chunks uint64
+
+	for eof := false; !eof; {
+		var values [][]byte
+
+		for len(values) < chunksPerTx {
+			chunk := make([]byte, chunkSize)
+
+			n, err := io.ReadFull(r, chunk)
+			if n > 0 {
+				values = append(values, chunk[:n])
+				digest.Write(chunk[:n])
+				size += uint64(n)
+			}
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				eof = true
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if len(values) == 0 {
+			break
+		}
+
+		// the blob is identified by the transaction of its first chunk
+		_, err := d.st.CommitWith(func(txID uint64, _ store.KeyIndex) ([]*store.KV, error) {
+			if chunks == 0 {
+				id = txID
+			}
+
+			entries := make([]*store.KV, len(values))
+			for i, v := range values {
+				entries[i] = &store.KV{Key: EncodeBlobChunkKey(id, chunks+uint64(i)), Value: v}
+			}
+
+			return entries, nil
+		}, false)
+		if err != nil {
+			return nil, err
+		}
+
+		chunks += uint64(len(values))
+	}
+
+	var sum [sha256.Size]byte
+	copy(sum[:], digest.Sum(nil))
+
+	txmd, err := d.setBlobManifest(key, encodeBlobManifest(id, size, chunks, sum), attribution)
+	if err != nil {
+		return nil, err
+	}
+
+	d.prefixStats.write(key, len(key)+int(size))
+
+	return &schema.BlobManifest{
+		Key:    key,
+		Size:   size,
+		Digest: sum[:],
+		Chunks: chunks,
+		Id:     id,
+		Tx:     txmd.ID,
+	}, nil
+}
+
+// setBlobManifest assigns the key the manifest as Set does with any other value
+func (d *db) setBlobManifest(key, manifest []byte, attribution *schema.Attribution) (*store.TxMetadata, error) {
+	if d.worm.limitsVersions() {
+		d.mutex.Lock()
+		defer d.mutex.Unlock()
+
+		lastTxID, _ := d.st.Alh()
+		err := d.st.WaitForIndexingUpto(lastTxID, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		err = d.checkWORMVersions([][]byte{key}, d.st)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		d.mutex.RLock()
+		defer d.mutex.RUnlock()
+	}
+
+	entries := []*store.KV{EncodeBlobKV(key, manifest)}
+
+	if attribution != nil {
+		entries = append(entries, EncodeAttribution(attribution))
+	}
+
+	return d.st.Commit(entries, true)
+}
+
+// GetBlob returns the manifest of the blob assigned to the key, as Get resolves it. ErrNotBlob is returned when the
+// key was assigned any other value
+func (d *db) GetBlob(req *schema.KeyRequest) (*schema.BlobManifest, error) {
+	e, err := d.Get(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if !e.Blob {
+		return nil, ErrNotBlob
+	}
+
+	return DecodeBlobManifest(e.Key, e.Value, e.Tx)
+}
+
+// GetBlobChunk returns the chunk of the blob at the given position
+func (d *db) GetBlobChunk(manifest *schema.BlobManifest, chunk uint64) ([]byte, error) {
+	if manifest == nil || chunk >= manifest.Chunks {
+		return nil, ErrIllegalArguments
+	}
+
+	val, _, _, err := d.st.Get(EncodeBlobChunkKey(manifest.Id, chunk))
+	if err == store.ErrKeyNotFound {
+		return nil, store.ErrCorruptedData
+	}
+
+	return val, err
+}
+
+func encodeBlobManifest(id, size, chunks uint64, digest [sha256.Size]byte) []byte {
+	m := make([]byte, blobManifestLen)
+	binary.BigEndian.PutUint64(m, id)
+	binary.BigEndian.PutUint64(m[8:], size)
+	binary.BigEndian.PutUint64(m[16:], chunks)
+	copy(m[24:], digest[:])
+
+	return m
+}
+
+// DecodeBlobManifest decodes the manifest assigned to the key by the transaction tx, as returned as the value of
+// blob entries
+func DecodeBlobManifest(key []byte, manifest []byte, tx uint64) (*schema.BlobManifest, error) {
+	if len(manifest) != blobManifestLen {
+		return nil, store.ErrCorruptedData
+	}
+
+	return &schema.BlobManifest{
+		Key:    key,
+		Id:     binary.BigEndian.Uint64(manifest),
+		Size:   binary.BigEndian.Uint64(manifest[8:]),
+		Chunks: binary.BigEndian.Uint64(manifest[16:]),
+		Digest: manifest[24:],
+		Tx:     tx,
+	}, nil
+}
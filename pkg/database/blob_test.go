@@ -0,0 +1,106 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package database
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlob(t *testing.T) {
+	rootPath := "data_" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	defer os.RemoveAll(rootPath)
+
+	options := DefaultOption().WithDbRootPath(rootPath).WithDbName("db").WithCorruptionChecker(false)
+	options.storeOpts.WithMaxValueLen(64).WithMaxTxEntries(4)
+
+	db, err := NewDb(options, nil, logger.NewSimpleLogger("immudb ", os.Stderr))
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.SetBlob(nil, bytes.NewReader(nil), nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	content := make([]byte, 1000)
+	_, err = rand.Read(content)
+	require.NoError(t, err)
+
+	manifest, err := db.SetBlob([]byte("blob"), bytes.NewReader(content), nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(len(content)), manifest.Size)
+	require.Equal(t, uint64(16), manifest.Chunks)
+
+	digest := sha256.Sum256(content)
+	require.Equal(t, digest[:], manifest.Digest)
+
+	// 16 chunks are committed by 4 transactions, followed by the manifest
+	require.Equal(t, manifest.Id+4, manifest.Tx)
+
+	read, err := db.GetBlob(&schema.KeyRequest{Key: []byte("blob")})
+	require.NoError(t, err)
+	require.Equal(t, manifest, read)
+
+	var got []byte
+	for i := uint64(0); i < read.Chunks; i++ {
+		chunk, err := db.GetBlobChunk(read, i)
+		require.NoError(t, err)
+		got = append(got, chunk...)
+	}
+	require.Equal(t, content, got)
+
+	_, err = db.GetBlobChunk(read, read.Chunks)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	e, err := db.Get(&schema.KeyRequest{Key: []byte("blob")})
+	require.NoError(t, err)
+	require.True(t, e.Blob)
+
+	ve, err := db.VerifiableGet(&schema.VerifiableGetRequest{KeyRequest: &schema.KeyRequest{Key: []byte("blob")}})
+	require.NoError(t, err)
+	require.True(t, ve.Entry.Blob)
+
+	empty, err := db.SetBlob([]byte("empty"), bytes.NewReader(nil), nil)
+	require.NoError(t, err)
+	require.Zero(t, empty.Size)
+	require.Zero(t, empty.Chunks)
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("blob"), Value: []byte("v")}}})
+	require.NoError(t, err)
+
+	_, err = db.GetBlob(&schema.KeyRequest{Key: []byte("blob")})
+	require.Equal(t, ErrNotBlob, err)
+
+	// earlier versions of the blob are still readable
+	read, err = db.GetBlob(&schema.KeyRequest{Key: []byte("blob"), AtTx: manifest.Tx})
+	require.NoError(t, err)
+	require.Equal(t, manifest, read)
+
+	history, err := db.History(&schema.HistoryRequest{Key: []byte("blob")})
+	require.NoError(t, err)
+	require.Len(t, history.Entries, 2)
+	require.True(t, history.Entries[0].Blob)
+	require.False(t, history.Entries[1].Blob)
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/codenotary/immudb/embedded/appendable"
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressedValues(t *testing.T) {
+	rootPath := "data_" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	defer os.RemoveAll(rootPath)
+
+	options := DefaultOption().WithDbRootPath(rootPath).WithDbName("db").
+		WithCompressionFormat(appendable.FlateCompression)
+
+	db, err := NewDb(options, nil, logger.NewSimpleLogger("immudb ", os.Stderr))
+	require.NoError(t, err)
+	require.Equal(t, appendable.FlateCompression, db.CompressionFormat())
+
+	// store options shared with other databases are not modified
+	require.Equal(t, appendable.NoCompression, options.GetStoreOptions().CompressionFormat)
+
+	value := bytes.Repeat([]byte("compressible value "), 100)
+
+	md, err := db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{
+		{Key: []byte("k1"), Value: value},
+		{Key: []byte("k2"), Value: []byte("v2")},
+	}})
+	require.NoError(t, err)
+
+	ve, err := db.VerifiableGet(&schema.VerifiableGetRequest{KeyRequest: &schema.KeyRequest{Key: []byte("k1")}})
+	require.NoError(t, err)
+	require.Equal(t, value, ve.Entry.Value)
+
+	// values are hashed before being compressed
+	var hValue []byte
+	for _, e := range ve.VerifiableTx.Tx.Entries {
+		if bytes.Equal(e.Key, EncodeKey([]byte("k1"))) {
+			hValue = e.HValue
+		}
+	}
+	h := sha256.Sum256(EncodeKV([]byte("k1"), value).Value)
+	require.Equal(t, h[:], hValue)
+
+	err = db.Close()
+	require.NoError(t, err)
+
+	// the format is kept when opening the database with default options
+	db, err = OpenDb(DefaultOption().WithDbRootPath(rootPath).WithDbName("db"), nil, logger.NewSimpleLogger("immudb ", os.Stderr))
+	require.NoError(t, err)
+	defer db.Close()
+
+	require.Equal(t, appendable.FlateCompression, db.CompressionFormat())
+
+	e, err := db.Get(&schema.KeyRequest{Key: []byte("k1"), AtTx: md.Id})
+	require.NoError(t, err)
+	require.Equal(t, value, e.Value)
+
+	entries, err := db.Scan(context.Background(), &schema.ScanRequest{SeekKey: []byte("k")})
+	require.NoError(t, err)
+	require.Len(t, entries.Entries, 2)
+	require.Equal(t, value, entries.Entries[0].Value)
+	require.Equal(t, []byte("v2"), entries.Entries[1].Value)
+}
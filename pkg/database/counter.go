@@ -0,0 +1,146 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+)
+
+// Counters are plain keys holding an integer in decimal notation, so they can be read and set as any other key
+
+// Increment atomically adds a signed delta to the integer held by the key, a missing, expired or deleted key counts
+// as zero. The value is read and written within the commit, so no other write can get in between
+func (d *db) Increment(req *schema.IncrementRequest) (*schema.IncrementResponse, error) {
+	if req == nil || len(req.Key) == 0 {
+		return nil, ErrIllegalArguments
+	}
+
+	if d.options.replica {
+		return nil, ErrIsReplica
+	}
+
+	err := d.keyRules.validate(req.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	err = d.approvalRules.validate(req.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.secondaryIndexesMutex.RLock()
+	defer d.secondaryIndexesMutex.RUnlock()
+
+	lastTxID, _ := d.st.Alh()
+	err = d.st.WaitForIndexingUpto(lastTxID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var value int64
+
+	callback := func(txID uint64, index store.KeyIndex) ([]*store.KV, error) {
+		current, expiresAt, err := d.counterValue(req.Key, index)
+		if err != nil {
+			return nil, err
+		}
+
+		if (req.Delta > 0 && current > math.MaxInt64-req.Delta) || (req.Delta < 0 && current < math.MinInt64-req.Delta) {
+			return nil, ErrCounterOverflow
+		}
+
+		value = current + req.Delta
+
+		err = d.checkWORMVersions([][]byte{req.Key}, index)
+		if err != nil {
+			return nil, err
+		}
+
+		// counters keep expiring when they were set to
+		kv := EncodeKV(req.Key, encodeCounter(value))
+		if expiresAt > 0 {
+			kv = EncodeExpirableKV(req.Key, encodeCounter(value), expiresAt)
+		}
+
+		entries := append([]*store.KV{kv}, d.secondaryIndexEntries(req.Key, encodeCounter(value))...)
+
+		if req.Attribution != nil {
+			entries = append(entries, EncodeAttribution(req.Attribution))
+		}
+
+		return entries, nil
+	}
+
+	txmd, err := d.st.CommitWith(callback, !req.NoWait)
+	if err != nil {
+		return nil, err
+	}
+
+	d.prefixStats.write(req.Key, len(req.Key)+len(encodeCounter(value)))
+
+	return &schema.IncrementResponse{
+		Value:      value,
+		TxMetadata: schema.TxMetatadaTo(txmd),
+	}, nil
+}
+
+func encodeCounter(n int64) []byte {
+	return []byte(strconv.FormatInt(n, 10))
+}
+
+// counterValue returns the integer currently held by the key along with its expiration time, if any.
+// References and non-integer values are rejected
+func (d *db) counterValue(key []byte, index store.KeyIndex) (n int64, expiresAt int64, err error) {
+	val, _, _, err := index.Get(EncodeKey(key))
+	if err == store.ErrKeyNotFound {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if len(val) > 0 && val[0] == ReferenceValuePrefix {
+		return 0, 0, ErrNotCounter
+	}
+
+	if deleted(val) {
+		return 0, 0, nil
+	}
+
+	value, expiresAt, err := decodeValue(val)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if expired(expiresAt) {
+		return 0, 0, nil
+	}
+
+	n, err = strconv.ParseInt(string(value), 10, 64)
+	if err != nil {
+		return 0, 0, ErrNotCounter
+	}
+
+	return n, expiresAt, nil
+}
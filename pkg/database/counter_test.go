@@ -0,0 +1,133 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIncrement(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	_, err := db.Increment(nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = db.Increment(&schema.IncrementRequest{Delta: 1})
+	require.Equal(t, ErrIllegalArguments, err)
+
+	incr := func(key string, delta int64) (*schema.IncrementResponse, error) {
+		return db.Increment(&schema.IncrementRequest{Key: []byte(key), Delta: delta})
+	}
+
+	res, err := incr("counter", 5)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), res.Value)
+
+	res, err = incr("counter", -7)
+	require.NoError(t, err)
+	require.Equal(t, int64(-2), res.Value)
+
+	item, err := db.Get(&schema.KeyRequest{Key: []byte("counter")})
+	require.NoError(t, err)
+	require.Equal(t, []byte("-2"), item.Value)
+	require.Equal(t, res.TxMetadata.Id, item.Tx)
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{
+		{Key: []byte("text"), Value: []byte("abc")},
+		{Key: []byte("max"), Value: []byte("9223372036854775807")},
+		{Key: []byte("min"), Value: []byte("-9223372036854775808")},
+	}})
+	require.NoError(t, err)
+
+	_, err = incr("text", 1)
+	require.Equal(t, ErrNotCounter, err)
+
+	_, err = incr("max", 1)
+	require.Equal(t, ErrCounterOverflow, err)
+
+	_, err = incr("min", -1)
+	require.Equal(t, ErrCounterOverflow, err)
+
+	res, err = incr("min", math.MaxInt64)
+	require.NoError(t, err)
+	require.Equal(t, int64(-1), res.Value)
+
+	_, err = db.SetReference(&schema.ReferenceRequest{Key: []byte("ref"), ReferencedKey: []byte("counter")})
+	require.NoError(t, err)
+
+	_, err = incr("ref", 1)
+	require.Equal(t, ErrNotCounter, err)
+
+	_, err = db.Delete(&schema.DeleteKeysRequest{Keys: [][]byte{[]byte("counter")}})
+	require.NoError(t, err)
+
+	res, err = incr("counter", 1)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), res.Value)
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{
+		{Key: []byte("expired"), Value: []byte("10"), ExpiresAt: time.Now().Add(-time.Minute).Unix()},
+		{Key: []byte("expiring"), Value: []byte("10"), ExpiresAt: time.Now().Add(time.Hour).Unix()},
+	}})
+	require.NoError(t, err)
+
+	res, err = incr("expired", 1)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), res.Value)
+
+	res, err = incr("expiring", 1)
+	require.NoError(t, err)
+	require.Equal(t, int64(11), res.Value)
+
+	item, err = db.Get(&schema.KeyRequest{Key: []byte("expiring")})
+	require.NoError(t, err)
+	require.NotZero(t, item.ExpiresAt)
+}
+
+func TestIncrementConcurrently(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	workers := 10
+	increments := 20
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < increments; j++ {
+				_, err := db.Increment(&schema.IncrementRequest{Key: []byte("counter"), Delta: 1})
+				require.NoError(t, err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	item, err := db.Get(&schema.KeyRequest{Key: []byte("counter")})
+	require.NoError(t, err)
+	require.Equal(t, []byte("200"), item.Value)
+}
@@ -16,13 +16,17 @@ limitations under the License.
 package database
 
 import (
+	"context"
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 
+	"github.com/codenotary/immudb/embedded/appendable"
 	"github.com/codenotary/immudb/embedded/sql"
 	"github.com/codenotary/immudb/embedded/store"
 
@@ -42,6 +46,7 @@ var ErrIllegalState = store.ErrIllegalState
 type DB interface {
 	Health(e *empty.Empty) (*schema.HealthResponse, error)
 	CurrentState() (*schema.ImmutableState, error)
+	VerifiableCombinedState(req *schema.VerifiableCombinedStateRequest) (*schema.VerifiableCombinedState, error)
 	Set(req *schema.SetRequest) (*schema.TxMetadata, error)
 	Get(req *schema.KeyRequest) (*schema.Entry, error)
 	VerifiableSet(req *schema.VerifiableSetRequest) (*schema.VerifiableTx, error)
@@ -53,25 +58,98 @@ type DB interface {
 	CountAll() (*schema.EntryCount, error)
 	TxByID(req *schema.TxRequest) (*schema.Tx, error)
 	VerifiableTxByID(req *schema.VerifiableTxRequest) (*schema.VerifiableTx, error)
+	TxRangeProofs(req *schema.TxRangeProofsRequest) (*schema.VerifiableTxRange, error)
 	TxScan(req *schema.TxScanRequest) (*schema.TxList, error)
+	ExportTx(req *schema.TxRequest) (*schema.ExportedTx, error)
+	ReplicateTx(exportedTx *schema.ExportedTx) (*schema.TxMetadata, error)
 	History(req *schema.HistoryRequest) (*schema.Entries, error)
 	SetReference(req *schema.ReferenceRequest) (*schema.TxMetadata, error)
 	VerifiableSetReference(req *schema.VerifiableReferenceRequest) (*schema.VerifiableTx, error)
 	ZAdd(req *schema.ZAddRequest) (*schema.TxMetadata, error)
-	ZScan(req *schema.ZScanRequest) (*schema.ZEntries, error)
+	ZScan(ctx context.Context, req *schema.ZScanRequest) (*schema.ZEntries, error)
+	ZRem(req *schema.ZRemRequest) (*schema.TxMetadata, error)
+	ZCount(ctx context.Context, req *schema.ZCountRequest) (*schema.EntryCount, error)
+	ZCard(ctx context.Context, req *schema.ZCardRequest) (*schema.EntryCount, error)
+	ZAddMulti(req *schema.ZAddMultiRequest) (*schema.TxMetadata, error)
+	ZRank(ctx context.Context, req *schema.ZRankRequest) (*schema.ZRankResult, error)
+	ZIncrBy(req *schema.ZIncrByRequest) (*schema.ZIncrByResult, error)
+	ZUnionStore(req *schema.ZStoreRequest) (*schema.ZStoreResult, error)
+	ZInterStore(req *schema.ZStoreRequest) (*schema.ZStoreResult, error)
+	ZPopMin(req *schema.ZPopRequest) (*schema.ZPopResult, error)
+	ZPopMax(req *schema.ZPopRequest) (*schema.ZPopResult, error)
 	VerifiableZAdd(req *schema.VerifiableZAddRequest) (*schema.VerifiableTx, error)
-	Scan(req *schema.ScanRequest) (*schema.Entries, error)
+	Scan(ctx context.Context, req *schema.ScanRequest) (*schema.Entries, error)
 	Close() error
 	GetOptions() *DbOptions
 	CompactIndex() error
+	CorruptedReads() uint64
+	CommitLatency() time.Duration
+	TopPrefixes(req *schema.TopPrefixesRequest) (*schema.PrefixStatsList, error)
+	KeyspaceHistogram(ctx context.Context, req *schema.KeyspaceHistogramRequest) (*schema.KeyspaceHistogramResult, error)
 	VerifiableSQLGet(req *schema.VerifiableSQLGetRequest) (*schema.VerifiableSQLEntry, error)
 	SQLExec(req *schema.SQLExecRequest) (*schema.SQLExecResult, error)
 	SQLExecPrepared(stmts []sql.SQLStmt, namedParams []*schema.NamedParam, waitForIndexing bool) (*schema.SQLExecResult, error)
 	UseSnapshot(req *schema.UseSnapshotRequest) error
-	SQLQuery(req *schema.SQLQueryRequest) (*schema.SQLQueryResult, error)
-	SQLQueryPrepared(stmt *sql.SelectStmt, namedParams []*schema.NamedParam, renewSnapshot bool) (*schema.SQLQueryResult, error)
+	SQLQuery(ctx context.Context, req *schema.SQLQueryRequest) (*schema.SQLQueryResult, error)
+	SQLQueryPrepared(ctx context.Context, stmt *sql.SelectStmt, namedParams []*schema.NamedParam, renewSnapshot bool) (*schema.SQLQueryResult, error)
 	ListTables() (*schema.SQLQueryResult, error)
 	DescribeTable(table string) (*schema.SQLQueryResult, error)
+	SQLRowProvenance(req *schema.SQLRowProvenanceRequest) (*schema.SQLRowVersions, error)
+	GetMulti(req *schema.GetMultiRequest) (*schema.GetMultiResponse, error)
+	VerifiableGetMulti(req *schema.VerifiableGetMultiRequest) (*schema.VerifiableGetMultiResponse, error)
+	ConsistencyProof(req *schema.ConsistencyProofRequest) (*schema.VerifiableConsistency, error)
+	NewSQLTx(req *schema.NewSQLTxRequest) (*schema.SQLTx, error)
+	SQLTxExec(req *schema.SQLTxExecRequest) error
+	SQLTxQuery(ctx context.Context, req *schema.SQLTxQueryRequest) (*schema.SQLQueryResult, error)
+	CommitSQLTx(req *schema.SQLTxRequest) (*schema.SQLExecResult, error)
+	RollbackSQLTx(req *schema.SQLTxRequest) error
+	OpenIterator(ctx context.Context, req *schema.OpenIteratorRequest) (*schema.IteratorLease, error)
+	IteratorNext(ctx context.Context, req *schema.IteratorNextRequest) (*schema.IteratorPage, error)
+	KeepAliveIterator(req *schema.IteratorLeaseRequest) (*schema.IteratorLease, error)
+	CloseIterator(req *schema.IteratorLeaseRequest) error
+	SetKeyRules(rules *schema.KeyRules) error
+	GetKeyRules() *schema.KeyRules
+	SetApprovalRules(rules *schema.ApprovalRules) error
+	GetApprovalRules() *schema.ApprovalRules
+	RequiresApproval(req *schema.ExecAllRequest) bool
+	ExecAllApproved(req *schema.ExecAllRequest) (*schema.TxMetadata, error)
+	SetLegalHold(hold *schema.LegalHold) (*schema.LegalHold, error)
+	ListLegalHolds() *schema.LegalHolds
+	LegalHoldHistory(prefix []byte) (*schema.LegalHolds, error)
+	WORMMode() (bool, uint32)
+	CompressionFormat() int
+	PinSnapshot(req *schema.PinSnapshotRequest) (*schema.PinnedSnapshot, error)
+	UnpinSnapshot(name string) error
+	ListPinnedSnapshots() *schema.PinnedSnapshots
+	Epoch() *schema.Epoch
+	AdvanceEpoch() (*schema.Epoch, error)
+	CheckEpoch(epoch uint64) error
+	CompareState(req *schema.StateComparisonRequest) (*schema.StateComparison, error)
+	AttestRewind(issuedBy string, reason string, sign func(*schema.RewindAttestation) error) (*schema.RewindAttestation, error)
+	RewindAttestation() (*schema.RewindAttestation, error)
+	SetIf(req *schema.SetIfRequest) (*schema.TxMetadata, error)
+	Diff(req *schema.DiffRequest) (*schema.DiffResponse, error)
+	Delete(req *schema.DeleteKeysRequest) (*schema.TxMetadata, error)
+	DeleteByPrefix(req *schema.DeleteByPrefixRequest) (*schema.DeleteByPrefixResponse, error)
+	SetBlob(key []byte, r io.Reader, attribution *schema.Attribution) (*schema.BlobManifest, error)
+	GetBlob(req *schema.KeyRequest) (*schema.BlobManifest, error)
+	GetBlobChunk(manifest *schema.BlobManifest, chunk uint64) ([]byte, error)
+	PutObject(req *schema.PutObjectRequest) (*schema.ObjectVersion, error)
+	GetObject(req *schema.GetObjectRequest) (*schema.Object, error)
+	ListObjectVersions(req *schema.ListObjectVersionsRequest) (*schema.ObjectVersions, error)
+	CreateSecondaryIndex(def *schema.SecondaryIndex) (*schema.SecondaryIndex, error)
+	DropSecondaryIndex(name string) error
+	ListSecondaryIndexes() *schema.SecondaryIndexes
+	IndexScan(ctx context.Context, req *schema.IndexScanRequest) (*schema.IndexEntries, error)
+	ListReferences(ctx context.Context, req *schema.ListReferencesRequest) (*schema.ReferenceList, error)
+	Increment(req *schema.IncrementRequest) (*schema.IncrementResponse, error)
+	Watch(ctx context.Context, req *schema.WatchRequest, send func(*schema.WatchEvent) error) error
+	WatchAck(req *schema.WatchAckRequest) error
+	Unwatch(req *schema.UnwatchRequest) error
+	Audit(ctx context.Context, progress func(audited, total uint64)) (uint64, error)
+	IdempotencyStats() *IdempotencyStats
+	TableStats() ([]*TableStats, error)
+	RecoveryProgress() *schema.RecoveryProgress
 	GetName() string
 }
 
@@ -79,6 +157,9 @@ type DB interface {
 type db struct {
 	st *store.ImmuStore
 
+	// catalogSt holds the SQL catalog, it's the database store itself when no catalog database is provided
+	catalogSt *store.ImmuStore
+
 	sqlEngine *sql.Engine
 
 	tx1, tx2 *store.Tx
@@ -87,6 +168,44 @@ type db struct {
 	Logger  logger.Logger
 	options *DbOptions
 
+	prefixStats *prefixStats
+
+	keyRules *keyRules
+
+	approvalRules *approvalRules
+
+	// active legal holds by prefix
+	legalHolds map[string]*schema.LegalHold
+
+	// declared secondary indexes by name, writes hold a read lock until committed so their values are either indexed
+	// by the writing transaction or as part of the declaration of a new index
+	secondaryIndexes      map[string]*secondaryIndex
+	secondaryIndexesMutex sync.RWMutex
+
+	// nil unless the database was created in write-once mode
+	worm *wormMode
+
+	// pinned snapshots by name
+	pinnedSnapshots map[string]*schema.PinnedSnapshot
+
+	epoch      *schema.Epoch
+	epochMutex sync.RWMutex
+
+	idempotency *idempotencyTable
+
+	sqlCache *sqlCache
+
+	tableStats *tableStats
+
+	sqlTxs      map[string]*sqlTxEntry
+	sqlTxsMutex sync.Mutex
+
+	iteratorLeases      map[string]*iteratorLease
+	iteratorLeasesMutex sync.Mutex
+
+	watchSubscriptions      map[string]*watchSubscription
+	watchSubscriptionsMutex sync.Mutex
+
 	name string
 }
 
@@ -95,9 +214,18 @@ func OpenDb(op *DbOptions, catalogDB DB, log logger.Logger) (DB, error) {
 	var err error
 
 	dbi := &db{
-		Logger:  log,
-		options: op,
-		name:    op.dbName,
+		Logger:             log,
+		options:            op,
+		prefixStats:        newPrefixStats(op.prefixStatsDepth, op.prefixStatsSeparator),
+		sqlTxs:             make(map[string]*sqlTxEntry),
+		iteratorLeases:     make(map[string]*iteratorLease),
+		watchSubscriptions: make(map[string]*watchSubscription),
+		idempotency:        newIdempotencyTable(op.idempotencyWindow, op.idempotencyMaxKeys),
+		legalHolds:         make(map[string]*schema.LegalHold),
+		pinnedSnapshots:    make(map[string]*schema.PinnedSnapshot),
+		secondaryIndexes:   make(map[string]*secondaryIndex),
+		epoch:              &schema.Epoch{},
+		name:               op.dbName,
 	}
 
 	dbDir := filepath.Join(op.GetDbRootPath(), op.GetDbName())
@@ -115,24 +243,55 @@ func OpenDb(op *DbOptions, catalogDB DB, log logger.Logger) (DB, error) {
 	dbi.tx1 = dbi.st.NewTx()
 	dbi.tx2 = dbi.st.NewTx()
 
-	var catalogStore *store.ImmuStore
-
 	if catalogDB == nil {
-		catalogStore = dbi.st
+		dbi.catalogSt = dbi.st
 	} else {
-		catalogStore = catalogDB.(*db).st
+		dbi.catalogSt = catalogDB.(*db).st
 	}
 
-	dbi.sqlEngine, err = sql.NewEngine(catalogStore, dbi.st, []byte{SQLPrefix})
+	dbi.sqlEngine, err = sql.NewEngine(dbi.catalogSt, dbi.st, []byte{SQLPrefix})
 	if err != nil {
 		return nil, logErr(dbi.Logger, "Unable to open store: %s", err)
 	}
 
+	dbi.sqlCache = newSQLCache(op.sqlCacheSize, dbi.st)
+	dbi.tableStats = newTableStats()
+
 	err = dbi.sqlEngine.UseDatabase(dbi.options.dbName)
 	if err != nil {
 		return nil, logErr(dbi.Logger, "Unable to open store: %s", err)
 	}
 
+	err = dbi.loadIdempotencyKeys()
+	if err != nil {
+		return nil, logErr(dbi.Logger, "Unable to load idempotency keys: %s", err)
+	}
+
+	err = dbi.loadLegalHolds()
+	if err != nil {
+		return nil, logErr(dbi.Logger, "Unable to load legal holds: %s", err)
+	}
+
+	err = dbi.loadWORMMode()
+	if err != nil {
+		return nil, logErr(dbi.Logger, "Unable to load write-once mode: %s", err)
+	}
+
+	err = dbi.loadSecondaryIndexes()
+	if err != nil {
+		return nil, logErr(dbi.Logger, "Unable to load secondary indexes: %s", err)
+	}
+
+	err = dbi.loadPinnedSnapshots()
+	if err != nil {
+		return nil, logErr(dbi.Logger, "Unable to load pinned snapshots: %s", err)
+	}
+
+	err = dbi.loadEpoch()
+	if err != nil {
+		return nil, logErr(dbi.Logger, "Unable to load epoch: %s", err)
+	}
+
 	return dbi, nil
 }
 
@@ -141,9 +300,18 @@ func NewDb(op *DbOptions, catalogDB DB, log logger.Logger) (DB, error) {
 	var err error
 
 	dbi := &db{
-		Logger:  log,
-		options: op,
-		name:    op.dbName,
+		Logger:             log,
+		options:            op,
+		prefixStats:        newPrefixStats(op.prefixStatsDepth, op.prefixStatsSeparator),
+		sqlTxs:             make(map[string]*sqlTxEntry),
+		iteratorLeases:     make(map[string]*iteratorLease),
+		watchSubscriptions: make(map[string]*watchSubscription),
+		idempotency:        newIdempotencyTable(op.idempotencyWindow, op.idempotencyMaxKeys),
+		legalHolds:         make(map[string]*schema.LegalHold),
+		pinnedSnapshots:    make(map[string]*schema.PinnedSnapshot),
+		secondaryIndexes:   make(map[string]*secondaryIndex),
+		epoch:              &schema.Epoch{},
+		name:               op.dbName,
 	}
 
 	dbDir := filepath.Join(op.GetDbRootPath(), op.GetDbName())
@@ -156,7 +324,13 @@ func NewDb(op *DbOptions, catalogDB DB, log logger.Logger) (DB, error) {
 		return nil, logErr(dbi.Logger, "Unable to create data folder: %s", err)
 	}
 
-	dbi.st, err = store.Open(dbDir, op.GetStoreOptions().WithLog(log))
+	storeOpts := op.GetStoreOptions()
+	if op.compressionFormat != appendable.NoCompression {
+		opts := *storeOpts
+		storeOpts = opts.WithCompressionFormat(op.compressionFormat)
+	}
+
+	dbi.st, err = store.Open(dbDir, storeOpts.WithLog(log))
 	if err != nil {
 		return nil, logErr(dbi.Logger, "Unable to open store: %s", err)
 	}
@@ -164,19 +338,28 @@ func NewDb(op *DbOptions, catalogDB DB, log logger.Logger) (DB, error) {
 	dbi.tx1 = dbi.st.NewTx()
 	dbi.tx2 = dbi.st.NewTx()
 
-	var catalogStore *store.ImmuStore
+	// replicas get the mode from the transactions of their master
+	if op.worm && !op.replica {
+		err = dbi.initWORMMode()
+		if err != nil {
+			return nil, logErr(dbi.Logger, "Unable to record write-once mode: %s", err)
+		}
+	}
 
 	if catalogDB == nil {
-		catalogStore = dbi.st
+		dbi.catalogSt = dbi.st
 	} else {
-		catalogStore = catalogDB.(*db).st
+		dbi.catalogSt = catalogDB.(*db).st
 	}
 
-	dbi.sqlEngine, err = sql.NewEngine(catalogStore, dbi.st, []byte{SQLPrefix})
+	dbi.sqlEngine, err = sql.NewEngine(dbi.catalogSt, dbi.st, []byte{SQLPrefix})
 	if err != nil {
 		return nil, logErr(dbi.Logger, "Unable to open store: %s", err)
 	}
 
+	dbi.sqlCache = newSQLCache(op.sqlCacheSize, dbi.st)
+	dbi.tableStats = newTableStats()
+
 	_, _, err = dbi.sqlEngine.ExecPreparedStmts([]sql.SQLStmt{&sql.CreateDatabaseStmt{DB: dbi.options.dbName}}, nil, true)
 	if err != nil {
 		return nil, logErr(dbi.Logger, "Unable to open store: %s", err)
@@ -195,6 +378,9 @@ func (d *db) CompactIndex() error {
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
 
+	// leased readers are invalidated as the index can't be compacted while their snapshots are open
+	d.invalidateIteratorLeases()
+
 	err := d.sqlEngine.CloseSnapshot()
 	if err != nil {
 		return err
@@ -210,17 +396,38 @@ func (d *db) CompactIndex() error {
 
 // Set ...
 func (d *db) Set(req *schema.SetRequest) (*schema.TxMetadata, error) {
-	d.mutex.RLock()
-	defer d.mutex.RUnlock()
+	if d.options.replica {
+		return nil, ErrIsReplica
+	}
+
+	if d.worm.limitsVersions() || len(req.GetPreconditions()) > 0 {
+		// versions of the keys are counted, and preconditions evaluated, while no other write is in progress
+		d.mutex.Lock()
+		defer d.mutex.Unlock()
+	} else {
+		d.mutex.RLock()
+		defer d.mutex.RUnlock()
+	}
 
 	return d.set(req)
 }
 
-func (d *db) set(req *schema.SetRequest) (*schema.TxMetadata, error) {
+func (d *db) set(req *schema.SetRequest) (txmd *schema.TxMetadata, err error) {
 	if req == nil {
 		return nil, ErrIllegalArguments
 	}
 
+	err = schema.ValidatePreconditions(req.Preconditions)
+	if err != nil {
+		return nil, err
+	}
+
+	txmd, err = d.beginIdempotentWrite(req.IdempotencyKey)
+	if txmd != nil || err != nil {
+		return txmd, err
+	}
+	defer d.endIdempotentWrite(req.IdempotencyKey, &txmd)
+
 	entries := make([]*store.KV, len(req.KVs))
 
 	for i, kv := range req.KVs {
@@ -228,14 +435,82 @@ func (d *db) set(req *schema.SetRequest) (*schema.TxMetadata, error) {
 			return nil, ErrIllegalArguments
 		}
 
-		entries[i] = EncodeKV(kv.Key, kv.Value)
+		err := d.keyRules.validate(kv.Key)
+		if err != nil {
+			return nil, err
+		}
+
+		err = d.approvalRules.validate(kv.Key)
+		if err != nil {
+			return nil, err
+		}
+
+		entries[i], err = d.encodeKV(kv)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if d.worm.limitsVersions() {
+		lastTxID, _ := d.st.Alh()
+		err = d.st.WaitForIndexingUpto(lastTxID, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		keys := make([][]byte, len(req.KVs))
+		for i, kv := range req.KVs {
+			keys[i] = kv.Key
+		}
+
+		err = d.checkWORMVersions(keys, d.st)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	d.secondaryIndexesMutex.RLock()
+	defer d.secondaryIndexesMutex.RUnlock()
+
+	for _, kv := range req.KVs {
+		entries = append(entries, d.secondaryIndexEntries(kv.Key, kv.Value)...)
+	}
+
+	if req.Attribution != nil {
+		entries = append(entries, EncodeAttribution(req.Attribution))
+	}
+
+	if e := d.idempotencyEntry(req.IdempotencyKey); e != nil {
+		entries = append(entries, e)
 	}
 
-	txMetatadata, err := d.st.Commit(entries, !req.NoWait)
+	var txMetatadata *store.TxMetadata
+
+	if len(req.Preconditions) > 0 {
+		lastTxID, _ := d.st.Alh()
+		err = d.st.WaitForIndexingUpto(lastTxID, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		txMetatadata, err = d.st.CommitWith(func(txID uint64, index store.KeyIndex) ([]*store.KV, error) {
+			err := d.checkPreconditions(req.Preconditions, index)
+			if err != nil {
+				return nil, err
+			}
+			return entries, nil
+		}, !req.NoWait)
+	} else {
+		txMetatadata, err = d.st.Commit(entries, !req.NoWait)
+	}
 	if err != nil {
 		return nil, err
 	}
 
+	for _, kv := range req.KVs {
+		d.prefixStats.write(kv.Key, len(kv.Key)+len(kv.Value))
+	}
+
 	return schema.TxMetatadaTo(txMetatadata), nil
 }
 
@@ -249,6 +524,14 @@ func (d *db) Get(req *schema.KeyRequest) (*schema.Entry, error) {
 		return nil, ErrIllegalArguments
 	}
 
+	if req.Snapshot != "" && (req.AtTx > 0 || req.SinceTx > 0) {
+		return nil, ErrIllegalArguments
+	}
+
+	if req.AtTime < 0 || (req.AtTime > 0 && (req.AtTx > 0 || req.SinceTx > 0 || req.Snapshot != "")) {
+		return nil, ErrIllegalArguments
+	}
+
 	err := d.st.WaitForIndexingUpto(req.SinceTx, nil)
 	if err != nil {
 		return nil, err
@@ -257,7 +540,43 @@ func (d *db) Get(req *schema.KeyRequest) (*schema.Entry, error) {
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
 
-	return d.getAt(EncodeKey(req.Key), req.AtTx, 0, d.st, d.tx1)
+	var e *schema.Entry
+
+	if req.Snapshot != "" {
+		txID, err := d.pinnedSnapshotTx(req.Snapshot)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err = d.getAsOf(EncodeKey(req.Key), txID)
+		if err != nil {
+			return nil, err
+		}
+	} else if req.AtTime > 0 {
+		txID, err := d.txAtTime(req.AtTime)
+		if err != nil {
+			return nil, err
+		}
+
+		err = d.st.WaitForIndexingUpto(txID, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err = d.getAsOf(EncodeKey(req.Key), txID)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		e, err = d.getAt(EncodeKey(req.Key), req.AtTx, 0, d.st, d.tx1)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	d.prefixStats.read(req.Key, len(e.Key)+len(e.Value))
+
+	return e, nil
 }
 
 func (d *db) get(key []byte, index store.KeyIndex, tx *store.Tx) (*schema.Entry, error) {
@@ -305,7 +624,7 @@ func (d *db) getAt(key []byte, atTx uint64, resolved int, index store.KeyIndex,
 		return entry, nil
 	}
 
-	return &schema.Entry{Key: TrimPrefix(key), Value: TrimPrefix(val), Tx: ktx}, err
+	return valueEntry(key, val, ktx)
 }
 
 func (d *db) readValue(key []byte, atTx uint64, tx *store.Tx) ([]byte, error) {
@@ -322,6 +641,25 @@ func (d *db) Health(*empty.Empty) (*schema.HealthResponse, error) {
 	return &schema.HealthResponse{Status: true, Version: fmt.Sprintf("%d", store.Version)}, nil
 }
 
+// RecoveryProgress returns the progress of the indexing of the transactions committed before the database was
+// opened, nil when there is nothing left to be recovered
+func (d *db) RecoveryProgress() *schema.RecoveryProgress {
+	st := d.st.RecoveryStatus()
+	if st == nil || st.Completed {
+		return nil
+	}
+
+	return &schema.RecoveryProgress{
+		Database:       d.name,
+		Phase:          st.Phase,
+		TxReplayed:     st.TxReplayed,
+		TxTotal:        st.TxTotal,
+		BytesProcessed: st.BytesProcessed,
+		BytesTotal:     st.BytesTotal,
+		Eta:            st.ETA.Milliseconds(),
+	}
+}
+
 // CurrentState ...
 func (d *db) CurrentState() (*schema.ImmutableState, error) {
 	d.mutex.Lock()
@@ -330,11 +668,79 @@ func (d *db) CurrentState() (*schema.ImmutableState, error) {
 	lastTxID, lastTxAlh := d.st.Alh()
 
 	return &schema.ImmutableState{
-		TxId:   lastTxID,
-		TxHash: lastTxAlh[:],
+		TxId:          lastTxID,
+		TxHash:        lastTxAlh[:],
+		HashAlgorithm: uint32(d.st.HashAlgorithm()),
+		Worm:          d.worm != nil,
 	}, nil
 }
 
+// VerifiableCombinedState returns the current state of both the database and its SQL catalog,
+// along with the proofs of their consistency with the provided ones
+func (d *db) VerifiableCombinedState(req *schema.VerifiableCombinedStateRequest) (*schema.VerifiableCombinedState, error) {
+	if req == nil {
+		return nil, ErrIllegalArguments
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	txID, txAlh := d.st.Alh()
+	catalogTxID, catalogTxAlh := d.catalogSt.Alh()
+
+	if req.ProveSinceTxId > txID || req.ProveSinceCatalogTxId > catalogTxID {
+		return nil, ErrIllegalState
+	}
+
+	vstate := &schema.VerifiableCombinedState{
+		State: &schema.CombinedState{
+			TxId:          txID,
+			TxHash:        txAlh[:],
+			CatalogTxId:   catalogTxID,
+			CatalogTxHash: catalogTxAlh[:],
+		},
+	}
+
+	if req.ProveSinceTxId > 0 {
+		dualProof, err := dualProofBetween(d.st, d.tx1, d.tx2, req.ProveSinceTxId, txID)
+		if err != nil {
+			return nil, err
+		}
+
+		vstate.DualProof = dualProof
+	}
+
+	if req.ProveSinceCatalogTxId > 0 {
+		dualProof, err := dualProofBetween(d.catalogSt, d.catalogSt.NewTx(), d.catalogSt.NewTx(), req.ProveSinceCatalogTxId, catalogTxID)
+		if err != nil {
+			return nil, err
+		}
+
+		vstate.CatalogDualProof = dualProof
+	}
+
+	return vstate, nil
+}
+
+func dualProofBetween(st *store.ImmuStore, sourceTx, targetTx *store.Tx, sourceTxID, targetTxID uint64) (*schema.DualProof, error) {
+	err := st.ReadTx(sourceTxID, sourceTx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = st.ReadTx(targetTxID, targetTx)
+	if err != nil {
+		return nil, err
+	}
+
+	dualProof, err := st.DualProof(sourceTx, targetTx)
+	if err != nil {
+		return nil, err
+	}
+
+	return schema.DualProofTo(dualProof), nil
+}
+
 //VerifiableSet ...
 func (d *db) VerifiableSet(req *schema.VerifiableSetRequest) (*schema.VerifiableTx, error) {
 	if req == nil {
@@ -379,8 +785,13 @@ func (d *db) VerifiableSet(req *schema.VerifiableSetRequest) (*schema.Verifiable
 		return nil, err
 	}
 
+	stx, err := d.txTo(lastTx)
+	if err != nil {
+		return nil, err
+	}
+
 	return &schema.VerifiableTx{
-		Tx:        schema.TxTo(lastTx),
+		Tx:        stx,
 		DualProof: schema.DualProofTo(dualProof),
 	}, nil
 }
@@ -404,6 +815,11 @@ func (d *db) VerifiableGet(req *schema.VerifiableGetRequest) (*schema.Verifiable
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
 
+	return d.verifiableEntry(e, req.ProveSinceTx)
+}
+
+// verifiableEntry bundles the inclusion and consistency proofs of an entry, the caller must hold the mutex
+func (d *db) verifiableEntry(e *schema.Entry, proveSinceTx uint64) (*schema.VerifiableEntry, error) {
 	txEntry := d.tx1
 
 	var vTxID uint64
@@ -418,7 +834,7 @@ func (d *db) VerifiableGet(req *schema.VerifiableGetRequest) (*schema.Verifiable
 	}
 
 	// key-value inclusion proof
-	err = d.st.ReadTx(vTxID, txEntry)
+	err := d.st.ReadTx(vTxID, txEntry)
 	if err != nil {
 		return nil, err
 	}
@@ -430,12 +846,12 @@ func (d *db) VerifiableGet(req *schema.VerifiableGetRequest) (*schema.Verifiable
 
 	var rootTx *store.Tx
 
-	if req.ProveSinceTx == 0 {
+	if proveSinceTx == 0 {
 		rootTx = txEntry
 	} else {
 		rootTx = d.tx2
 
-		err = d.st.ReadTx(req.ProveSinceTx, rootTx)
+		err = d.st.ReadTx(proveSinceTx, rootTx)
 		if err != nil {
 			return nil, err
 		}
@@ -443,7 +859,7 @@ func (d *db) VerifiableGet(req *schema.VerifiableGetRequest) (*schema.Verifiable
 
 	var sourceTx, targetTx *store.Tx
 
-	if req.ProveSinceTx <= vTxID {
+	if proveSinceTx <= vTxID {
 		sourceTx = rootTx
 		targetTx = txEntry
 	} else {
@@ -488,7 +904,7 @@ func (d *db) GetAll(req *schema.KeyListRequest) (*schema.Entries, error) {
 
 	for _, key := range req.Keys {
 		e, err := d.get(EncodeKey(key), snapshot, d.tx1)
-		if err == nil || err == store.ErrKeyNotFound {
+		if err == nil || unreadable(err) {
 			if e != nil {
 				list.Entries = append(list.Entries, e)
 			}
@@ -497,6 +913,8 @@ func (d *db) GetAll(req *schema.KeyListRequest) (*schema.Entries, error) {
 		}
 	}
 
+	d.prefixStats.readEntries(list.Entries)
+
 	return list, nil
 }
 
@@ -533,7 +951,20 @@ func (d *db) TxByID(req *schema.TxRequest) (*schema.Tx, error) {
 		return nil, err
 	}
 
-	return schema.TxTo(d.tx1), nil
+	return d.txTo(d.tx1)
+}
+
+// txTo converts tx including its attribution, if any
+func (d *db) txTo(tx *store.Tx) (*schema.Tx, error) {
+	attribution, err := d.attributionOf(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	stx := schema.TxTo(tx)
+	stx.Attribution = attribution
+
+	return stx, nil
 }
 
 //VerifiableTxByID ...
@@ -586,8 +1017,13 @@ func (d *db) VerifiableTxByID(req *schema.VerifiableTxRequest) (*schema.Verifiab
 		return nil, err
 	}
 
+	stx, err := d.txTo(reqTx)
+	if err != nil {
+		return nil, err
+	}
+
 	return &schema.VerifiableTx{
-		Tx:        schema.TxTo(reqTx),
+		Tx:        stx,
 		DualProof: schema.DualProofTo(dualProof),
 	}, nil
 }
@@ -627,7 +1063,12 @@ func (d *db) TxScan(req *schema.TxScanRequest) (*schema.TxList, error) {
 			return nil, err
 		}
 
-		txList.Txs = append(txList.Txs, schema.TxTo(tx))
+		stx, err := d.txTo(tx)
+		if err != nil {
+			return nil, err
+		}
+
+		txList.Txs = append(txList.Txs, stx)
 	}
 
 	return txList, nil
@@ -659,7 +1100,7 @@ func (d *db) History(req *schema.HistoryRequest) (*schema.Entries, error) {
 
 	key := EncodeKey(req.Key)
 
-	txs, err := d.st.History(key, req.Offset, req.Desc, limit)
+	txs, err := d.history(key, req.Offset, req.Desc, limit, req.FromTx)
 	if err != nil && err != store.ErrOffsetOutOfRange {
 		return nil, err
 	}
@@ -679,17 +1120,105 @@ func (d *db) History(req *schema.HistoryRequest) (*schema.Entries, error) {
 			return nil, err
 		}
 
-		list.Entries[i] = &schema.Entry{Key: req.Key, Value: TrimPrefix(val), Tx: tx}
+		list.Entries[i] = &schema.Entry{Key: req.Key, Value: TrimPrefix(val), Tx: tx, Blob: len(val) > 0 && val[0] == BlobValuePrefix}
+
+		if deleted(val) {
+			list.Entries[i].Value = nil
+			list.Entries[i].Deleted = true
+		}
+
+		// expired values are not returned, the entry of the transaction is still listed
+		if len(val) > 0 && val[0] == ExpirableValuePrefix {
+			value, expiresAt, err := decodeValue(val)
+			if err != nil {
+				return nil, err
+			}
+
+			list.Entries[i].ExpiresAt = expiresAt
+			list.Entries[i].Value = value
+
+			if expired(expiresAt) {
+				list.Entries[i].Value = nil
+			}
+		}
+
+		if req.WithTxMetadata || req.WithInclusionProof {
+			list.Entries[i].TxMetadata = schema.TxMetatadaTo(d.tx1.Metadata())
+
+			list.Entries[i].Attribution, err = d.attributionOf(d.tx1)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if req.WithInclusionProof {
+			inclusionProof, err := d.tx1.Proof(key)
+			if err != nil {
+				return nil, err
+			}
+
+			list.Entries[i].InclusionProof = schema.InclusionProofTo(inclusionProof)
+		}
 	}
 
+	d.prefixStats.readEntries(list.Entries)
+
 	return list, nil
 }
 
+// history returns the transactions in which the key was written, skipping those before fromTx
+func (d *db) history(key []byte, offset uint64, desc bool, limit int, fromTx uint64) ([]uint64, error) {
+	if fromTx <= 1 {
+		return d.st.History(key, offset, desc, limit)
+	}
+
+	var txs []uint64
+	var skipped, off uint64
+
+	for len(txs) < limit {
+		chunk, err := d.st.History(key, off, desc, MaxKeyScanLimit)
+		if err == store.ErrNoMoreEntries {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		off += uint64(len(chunk))
+
+		for _, tx := range chunk {
+			if tx < fromTx {
+				if desc {
+					// older versions follow
+					return txs, nil
+				}
+				continue
+			}
+
+			if skipped < offset {
+				skipped++
+				continue
+			}
+
+			txs = append(txs, tx)
+
+			if len(txs) == limit {
+				break
+			}
+		}
+	}
+
+	return txs, nil
+}
+
 //Close ...
 func (d *db) Close() error {
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
 
+	d.cancelSQLTxs()
+	d.closeIteratorLeases()
+
 	err := d.sqlEngine.Close()
 	if err != nil {
 		return err
@@ -703,11 +1232,26 @@ func (d *db) GetName() string {
 	return d.name
 }
 
+// CorruptedReads returns the number of reads aborted because corrupted data was detected since the database was opened
+func (d *db) CorruptedReads() uint64 {
+	return d.st.CorruptedReads()
+}
+
+// CommitLatency returns the time taken to durably write the latest transaction committed into the database
+func (d *db) CommitLatency() time.Duration {
+	return d.st.CommitLatency()
+}
+
 //GetOptions ...
 func (d *db) GetOptions() *DbOptions {
 	return d.options
 }
 
+// CompressionFormat returns the format values are compressed with in the value-logs of the database
+func (d *db) CompressionFormat() int {
+	return d.st.CompressionFormat()
+}
+
 func logErr(log logger.Logger, formattedMessage string, err error) error {
 	if err != nil {
 		log.Errorf(formattedMessage, err)
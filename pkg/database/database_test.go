@@ -25,6 +25,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/codenotary/immudb/embedded/hashing"
 	"github.com/codenotary/immudb/embedded/store"
 	"github.com/codenotary/immudb/pkg/api/schema"
 	"github.com/codenotary/immudb/pkg/logger"
@@ -303,6 +304,7 @@ func TestCurrentState(t *testing.T) {
 		state, err := db.CurrentState()
 		require.NoError(t, err)
 		require.Equal(t, uint64(ind+1), state.TxId)
+		require.Equal(t, uint32(hashing.SHA256), state.HashAlgorithm)
 	}
 }
 
@@ -395,8 +397,9 @@ func TestSetGetAll(t *testing.T) {
 			Value: []byte("Killy"),
 		},
 		{
-			Key:   []byte("Franz"),
-			Value: []byte("Clamer"),
+			Key:            []byte("Franz"),
+			Value:          []byte("Clamer"),
+			Incompressible: true,
 		},
 	}
 
@@ -535,6 +538,81 @@ func TestHistory(t *testing.T) {
 	})
 	require.NoError(t, err)
 	require.Empty(t, inc.Entries)
+
+	inc, err = db.History(&schema.HistoryRequest{
+		Key:            kvs[0].Key,
+		SinceTx:        lastTx,
+		WithTxMetadata: true,
+	})
+	require.NoError(t, err)
+
+	for _, val := range inc.Entries {
+		require.Equal(t, val.Tx, val.TxMetadata.Id)
+		require.Nil(t, val.InclusionProof)
+	}
+
+	inc, err = db.History(&schema.HistoryRequest{
+		Key:                kvs[0].Key,
+		SinceTx:            lastTx,
+		WithInclusionProof: true,
+	})
+	require.NoError(t, err)
+
+	for _, val := range inc.Entries {
+		require.Equal(t, val.Tx, val.TxMetadata.Id)
+
+		verifies := store.VerifyInclusion(
+			schema.InclusionProofFrom(val.InclusionProof),
+			EncodeKV(val.Key, val.Value),
+			schema.DigestFrom(val.TxMetadata.EH),
+		)
+		require.True(t, verifies)
+	}
+}
+
+func TestHistoryFromTx(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	var txs []uint64
+
+	for i := 0; i < 10; i++ {
+		meta, err := db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("key"), Value: []byte{byte(i)}}}})
+		require.NoError(t, err)
+
+		_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("other"), Value: []byte{byte(i)}}}})
+		require.NoError(t, err)
+
+		txs = append(txs, meta.Id)
+	}
+
+	historyTxs := func(req *schema.HistoryRequest) []uint64 {
+		req.Key = []byte("key")
+		req.SinceTx = txs[len(txs)-1]
+
+		res, err := db.History(req)
+		require.NoError(t, err)
+
+		var hTxs []uint64
+		for _, e := range res.Entries {
+			hTxs = append(hTxs, e.Tx)
+		}
+		return hTxs
+	}
+
+	require.Equal(t, txs[6:], historyTxs(&schema.HistoryRequest{FromTx: txs[6]}))
+	require.Equal(t, txs[6:], historyTxs(&schema.HistoryRequest{FromTx: txs[5] + 1}))
+	require.Equal(t, txs[7:9], historyTxs(&schema.HistoryRequest{FromTx: txs[6], Offset: 1, Limit: 2}))
+
+	require.Equal(t, []uint64{txs[9], txs[8], txs[7], txs[6]}, historyTxs(&schema.HistoryRequest{FromTx: txs[6], Desc: true}))
+	require.Equal(t, []uint64{txs[8], txs[7]}, historyTxs(&schema.HistoryRequest{FromTx: txs[6], Desc: true, Offset: 1, Limit: 2}))
+	require.Equal(t, []uint64{txs[9], txs[8]}, historyTxs(&schema.HistoryRequest{Desc: true, Limit: 2}))
+
+	require.Empty(t, historyTxs(&schema.HistoryRequest{FromTx: txs[9] + 1}))
+	require.Empty(t, historyTxs(&schema.HistoryRequest{FromTx: txs[6], Offset: 4}))
+
+	_, err := db.History(&schema.HistoryRequest{Key: []byte("missing"), FromTx: txs[6]})
+	require.Equal(t, store.ErrKeyNotFound, err)
 }
 
 func TestHealth(t *testing.T) {
@@ -547,6 +625,9 @@ func TestHealth(t *testing.T) {
 	if !h.GetStatus() {
 		t.Fatalf("Health, expected %v, got %v", true, h.GetStatus())
 	}
+	if rp := db.RecoveryProgress(); rp != nil {
+		t.Fatalf("RecoveryProgress, expected nil, got %v", rp)
+	}
 }
 
 /*
@@ -923,3 +1004,59 @@ func TestDb_SetBatchAtomicOperations(t *testing.T) {
 	require.NoError(t, err)
 }
 */
+
+func TestVerifiableCombinedState(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	_, err := db.VerifiableCombinedState(nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("k0"), Value: []byte("v0")}}})
+	require.NoError(t, err)
+
+	vstate, err := db.VerifiableCombinedState(&schema.VerifiableCombinedStateRequest{})
+	require.NoError(t, err)
+	require.Nil(t, vstate.DualProof)
+	require.Nil(t, vstate.CatalogDualProof)
+
+	initial := vstate.State
+
+	_, err = db.VerifiableCombinedState(&schema.VerifiableCombinedStateRequest{ProveSinceTxId: initial.TxId + 1})
+	require.Equal(t, ErrIllegalState, err)
+
+	_, err = db.VerifiableCombinedState(&schema.VerifiableCombinedStateRequest{ProveSinceCatalogTxId: initial.CatalogTxId + 1})
+	require.Equal(t, ErrIllegalState, err)
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("k1"), Value: []byte("v1")}}})
+	require.NoError(t, err)
+
+	_, err = db.SQLExec(&schema.SQLExecRequest{Sql: "CREATE TABLE table1(id INTEGER, PRIMARY KEY id)"})
+	require.NoError(t, err)
+
+	vstate, err = db.VerifiableCombinedState(&schema.VerifiableCombinedStateRequest{
+		ProveSinceTxId:        initial.TxId,
+		ProveSinceCatalogTxId: initial.CatalogTxId,
+	})
+	require.NoError(t, err)
+	require.Greater(t, vstate.State.TxId, initial.TxId)
+	require.Greater(t, vstate.State.CatalogTxId, initial.CatalogTxId)
+
+	verifies := store.VerifyDualProof(
+		schema.DualProofFrom(vstate.DualProof),
+		initial.TxId,
+		vstate.State.TxId,
+		schema.DigestFrom(initial.TxHash),
+		schema.DigestFrom(vstate.State.TxHash),
+	)
+	require.True(t, verifies)
+
+	verifies = store.VerifyDualProof(
+		schema.DualProofFrom(vstate.CatalogDualProof),
+		initial.CatalogTxId,
+		vstate.State.CatalogTxId,
+		schema.DigestFrom(initial.CatalogTxHash),
+		schema.DigestFrom(vstate.State.CatalogTxHash),
+	)
+	require.True(t, verifies)
+}
@@ -16,7 +16,11 @@ limitations under the License.
 
 package database
 
-import "github.com/codenotary/immudb/embedded/store"
+import (
+	"time"
+
+	"github.com/codenotary/immudb/embedded/store"
+)
 
 //DbOptions database instance options
 type DbOptions struct {
@@ -24,7 +28,26 @@ type DbOptions struct {
 	dbName            string
 	dbRootPath        string
 	corruptionChecker bool
+	replica           bool
 	storeOpts         *store.Options
+
+	prefixStatsDepth     int
+	prefixStatsSeparator byte
+
+	sqlTxTimeout time.Duration
+
+	iteratorLeaseTimeout time.Duration
+
+	idempotencyWindow    time.Duration
+	idempotencyMaxKeys   int
+	idempotencyPersisted bool
+
+	worm            bool
+	wormMaxVersions uint32
+
+	compressionFormat int
+
+	sqlCacheSize int
 }
 
 // DefaultOption Initialise Db Optionts to default values
@@ -35,6 +58,17 @@ func DefaultOption() *DbOptions {
 		dbRootPath:        "./data",
 		corruptionChecker: true,
 		storeOpts:         store.DefaultOptions(),
+
+		prefixStatsSeparator: DefaultPrefixStatsSeparator,
+
+		sqlTxTimeout: DefaultSQLTxTimeout,
+
+		iteratorLeaseTimeout: DefaultIteratorLeaseTimeout,
+
+		idempotencyWindow:  DefaultIdempotencyWindow,
+		idempotencyMaxKeys: DefaultIdempotencyMaxKeys,
+
+		sqlCacheSize: DefaultSQLCacheSize,
 	}
 }
 
@@ -71,6 +105,17 @@ func (o *DbOptions) GetCorruptionChecker() bool {
 	return o.corruptionChecker
 }
 
+// WithReplica sets if this database instance is a read-only replica, only updated through replicated transactions
+func (o *DbOptions) WithReplica(replica bool) *DbOptions {
+	o.replica = replica
+	return o
+}
+
+// IsReplica returns if this database instance is a replica
+func (o *DbOptions) IsReplica() bool {
+	return o.replica
+}
+
 // WithStoreOptions sets backing store options
 func (o *DbOptions) WithStoreOptions(storeOpts *store.Options) *DbOptions {
 	o.storeOpts = storeOpts
@@ -81,3 +126,123 @@ func (o *DbOptions) WithStoreOptions(storeOpts *store.Options) *DbOptions {
 func (o *DbOptions) GetStoreOptions() *store.Options {
 	return o.storeOpts
 }
+
+// WithPrefixStatsDepth sets the number of key segments used to group per-prefix statistics, zero disables them
+func (o *DbOptions) WithPrefixStatsDepth(depth int) *DbOptions {
+	o.prefixStatsDepth = depth
+	return o
+}
+
+// GetPrefixStatsDepth returns the number of key segments used to group per-prefix statistics
+func (o *DbOptions) GetPrefixStatsDepth() int {
+	return o.prefixStatsDepth
+}
+
+// WithPrefixStatsSeparator sets the byte delimiting key segments
+func (o *DbOptions) WithPrefixStatsSeparator(separator byte) *DbOptions {
+	o.prefixStatsSeparator = separator
+	return o
+}
+
+// GetPrefixStatsSeparator returns the byte delimiting key segments
+func (o *DbOptions) GetPrefixStatsSeparator() byte {
+	return o.prefixStatsSeparator
+}
+
+// WithSQLTxTimeout sets how long an interactive SQL transaction can stay idle before being aborted, zero disables the timeout
+func (o *DbOptions) WithSQLTxTimeout(timeout time.Duration) *DbOptions {
+	o.sqlTxTimeout = timeout
+	return o
+}
+
+// GetSQLTxTimeout returns how long an interactive SQL transaction can stay idle before being aborted
+func (o *DbOptions) GetSQLTxTimeout() time.Duration {
+	return o.sqlTxTimeout
+}
+
+// WithIteratorLeaseTimeout sets how long an iterator lease is kept without receiving any request, zero disables the timeout
+func (o *DbOptions) WithIteratorLeaseTimeout(timeout time.Duration) *DbOptions {
+	o.iteratorLeaseTimeout = timeout
+	return o
+}
+
+// GetIteratorLeaseTimeout returns how long an iterator lease is kept without receiving any request
+func (o *DbOptions) GetIteratorLeaseTimeout() time.Duration {
+	return o.iteratorLeaseTimeout
+}
+
+// WithIdempotencyWindow sets how long writes are remembered by their idempotency key, zero disables deduplication
+func (o *DbOptions) WithIdempotencyWindow(window time.Duration) *DbOptions {
+	o.idempotencyWindow = window
+	return o
+}
+
+// GetIdempotencyWindow returns how long writes are remembered by their idempotency key
+func (o *DbOptions) GetIdempotencyWindow() time.Duration {
+	return o.idempotencyWindow
+}
+
+// WithIdempotencyMaxKeys sets the maximum number of idempotency keys remembered, zero disables deduplication
+func (o *DbOptions) WithIdempotencyMaxKeys(maxKeys int) *DbOptions {
+	o.idempotencyMaxKeys = maxKeys
+	return o
+}
+
+// GetIdempotencyMaxKeys returns the maximum number of idempotency keys remembered
+func (o *DbOptions) GetIdempotencyMaxKeys() int {
+	return o.idempotencyMaxKeys
+}
+
+// WithIdempotencyPersisted sets whether idempotency keys are written as part of their transactions,
+// so writes are still deduplicated after the database is reopened
+func (o *DbOptions) WithIdempotencyPersisted(persisted bool) *DbOptions {
+	o.idempotencyPersisted = persisted
+	return o
+}
+
+// GetIdempotencyPersisted returns whether idempotency keys are written as part of their transactions
+func (o *DbOptions) GetIdempotencyPersisted() bool {
+	return o.idempotencyPersisted
+}
+
+// WithSQLCacheSize sets the maximum number of SQL query results cached, zero disables caching
+func (o *DbOptions) WithSQLCacheSize(size int) *DbOptions {
+	o.sqlCacheSize = size
+	return o
+}
+
+// GetSQLCacheSize returns the maximum number of SQL query results cached
+func (o *DbOptions) GetSQLCacheSize() int {
+	return o.sqlCacheSize
+}
+
+// WithWORM sets whether a new database is created in write-once mode, where keys can't be deleted or expired
+// and each key can be assigned up to maxVersions values, zero meaning no limit.
+// The mode is recorded when the database is created, it's ignored when opening an existing one
+func (o *DbOptions) WithWORM(worm bool, maxVersions uint32) *DbOptions {
+	o.worm = worm
+	o.wormMaxVersions = maxVersions
+	return o
+}
+
+// GetWORM returns whether a new database is created in write-once mode
+func (o *DbOptions) GetWORM() bool {
+	return o.worm
+}
+
+// GetWORMMaxVersions returns the maximum number of values a key can be assigned in write-once mode
+func (o *DbOptions) GetWORMMaxVersions() uint32 {
+	return o.wormMaxVersions
+}
+
+// WithCompressionFormat sets the format values of a new database are compressed with in its value-logs,
+// the format is recorded when the database is created, it's ignored when opening an existing one
+func (o *DbOptions) WithCompressionFormat(compressionFormat int) *DbOptions {
+	o.compressionFormat = compressionFormat
+	return o
+}
+
+// GetCompressionFormat returns the format values of a new database are compressed with
+func (o *DbOptions) GetCompressionFormat() int {
+	return o.compressionFormat
+}
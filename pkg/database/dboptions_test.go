@@ -18,6 +18,7 @@ package database
 
 import (
 	"testing"
+	"time"
 
 	"github.com/codenotary/immudb/embedded/store"
 	"github.com/stretchr/testify/require"
@@ -57,4 +58,17 @@ func TestDefaultOptions(t *testing.T) {
 	}
 
 	require.Equal(t, storeOpts, op.storeOpts)
+
+	require.Equal(t, DefaultSQLTxTimeout, DefaultOption().GetSQLTxTimeout())
+	require.Equal(t, time.Second, op.WithSQLTxTimeout(time.Second).GetSQLTxTimeout())
+
+	require.Equal(t, DefaultIteratorLeaseTimeout, DefaultOption().GetIteratorLeaseTimeout())
+	require.Equal(t, time.Second, op.WithIteratorLeaseTimeout(time.Second).GetIteratorLeaseTimeout())
+
+	require.Equal(t, DefaultIdempotencyWindow, DefaultOption().GetIdempotencyWindow())
+	require.Equal(t, time.Hour, op.WithIdempotencyWindow(time.Hour).GetIdempotencyWindow())
+	require.Equal(t, DefaultIdempotencyMaxKeys, DefaultOption().GetIdempotencyMaxKeys())
+	require.Equal(t, 10, op.WithIdempotencyMaxKeys(10).GetIdempotencyMaxKeys())
+	require.False(t, DefaultOption().GetIdempotencyPersisted())
+	require.True(t, op.WithIdempotencyPersisted(true).GetIdempotencyPersisted())
 }
@@ -0,0 +1,254 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+)
+
+// Deleting a key assigns it a tombstone, a value made of TombstoneValuePrefix alone. Reads handle it as if the key
+// didn't exist, while the deletion is still listed by History along with the previous values
+
+// Delete marks the keys as deleted. store.ErrKeyNotFound is returned, and nothing is deleted, if any of them doesn't exist
+func (d *db) Delete(req *schema.DeleteKeysRequest) (*schema.TxMetadata, error) {
+	if req == nil || len(req.Keys) == 0 {
+		return nil, ErrIllegalArguments
+	}
+
+	if d.options.replica {
+		return nil, ErrIsReplica
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	err := d.checkWORMRemoval()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*store.KV, 0, len(req.Keys)+1)
+	kmap := make(map[string]struct{}, len(req.Keys))
+
+	for _, key := range req.Keys {
+		if len(key) == 0 {
+			return nil, ErrIllegalArguments
+		}
+
+		if _, ok := kmap[string(key)]; ok {
+			return nil, schema.ErrDuplicatedKeysNotSupported
+		}
+		kmap[string(key)] = struct{}{}
+
+		err := d.keyRules.validate(key)
+		if err != nil {
+			return nil, err
+		}
+
+		err = d.approvalRules.validate(key)
+		if err != nil {
+			return nil, err
+		}
+
+		err = d.checkLegalHold(key)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, EncodeTombstone(key))
+	}
+
+	if req.Attribution != nil {
+		entries = append(entries, EncodeAttribution(req.Attribution))
+	}
+
+	lastTxID, _ := d.st.Alh()
+	err = d.st.WaitForIndexingUpto(lastTxID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	txmd, err := d.st.CommitWith(func(txID uint64, index store.KeyIndex) ([]*store.KV, error) {
+		for _, key := range req.Keys {
+			exists, err := d.keyExists(key, index)
+			if err != nil {
+				return nil, err
+			}
+			if !exists {
+				return nil, store.ErrKeyNotFound
+			}
+		}
+
+		return entries, nil
+	}, !req.NoWait)
+	if err != nil {
+		return nil, err
+	}
+
+	return schema.TxMetatadaTo(txmd), nil
+}
+
+// DeleteByPrefix marks every key under the prefix as deleted, in transactions of at most req.BatchSize keys. All the
+// keys are checked before deleting any of them, so nothing is deleted if any of them can't be. A dry run only
+// returns the number of keys which would be deleted
+func (d *db) DeleteByPrefix(req *schema.DeleteByPrefixRequest) (*schema.DeleteByPrefixResponse, error) {
+	if req == nil || len(req.Prefix) == 0 {
+		return nil, ErrIllegalArguments
+	}
+
+	if d.options.replica && !req.DryRun {
+		return nil, ErrIsReplica
+	}
+
+	batchSize := d.st.MaxTxEntries()
+
+	if req.Attribution != nil {
+		batchSize--
+	}
+
+	if req.BatchSize > 0 {
+		if int(req.BatchSize) > batchSize {
+			return nil, ErrIllegalArguments
+		}
+
+		batchSize = int(req.BatchSize)
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if !req.DryRun {
+		err := d.checkWORMRemoval()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	keys, err := d.liveKeysByPrefix(req.Prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range keys {
+		err := d.keyRules.validate(key)
+		if err != nil {
+			return nil, err
+		}
+
+		err = d.approvalRules.validate(key)
+		if err != nil {
+			return nil, err
+		}
+
+		err = d.checkLegalHold(key)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	res := &schema.DeleteByPrefixResponse{Count: uint64(len(keys))}
+
+	if req.DryRun {
+		return res, nil
+	}
+
+	for i := 0; i < len(keys); i += batchSize {
+		batch := keys[i:]
+		if len(batch) > batchSize {
+			batch = batch[:batchSize]
+		}
+
+		entries := make([]*store.KV, 0, len(batch)+1)
+
+		for _, key := range batch {
+			entries = append(entries, EncodeTombstone(key))
+		}
+
+		if req.Attribution != nil {
+			entries = append(entries, EncodeAttribution(req.Attribution))
+		}
+
+		txmd, err := d.st.Commit(entries, !req.NoWait)
+		if err != nil {
+			return nil, err
+		}
+
+		res.Txs = append(res.Txs, schema.TxMetatadaTo(txmd))
+	}
+
+	return res, nil
+}
+
+// liveKeysByPrefix returns the readable keys under the prefix, once the index is up to date
+func (d *db) liveKeysByPrefix(prefix []byte) ([][]byte, error) {
+	lastTxID, _ := d.st.Alh()
+
+	err := d.st.WaitForIndexingUpto(lastTxID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	snap, err := d.st.SnapshotSince(lastTxID)
+	if err != nil {
+		return nil, err
+	}
+	defer snap.Close()
+
+	r, err := snap.NewKeyReader(&store.KeyReaderSpec{Prefix: EncodeKey(prefix)})
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	var keys [][]byte
+
+	for {
+		key, valRef, _, _, err := r.Read()
+		if err == store.ErrNoMoreEntries {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		val, err := valRef.Resolve()
+		if err != nil {
+			return nil, err
+		}
+
+		isLive, err := live(val)
+		if err != nil {
+			return nil, err
+		}
+
+		if isLive {
+			keys = append(keys, TrimPrefix(key))
+		}
+	}
+
+	return keys, nil
+}
+
+// deleted tells whether the value is a tombstone
+func deleted(val []byte) bool {
+	return len(val) > 0 && val[0] == TombstoneValuePrefix
+}
+
+// unreadable tells whether err is returned for keys which can no longer be read, being expired or deleted
+func unreadable(err error) bool {
+	return err == ErrKeyExpired || err == store.ErrKeyNotFound
+}
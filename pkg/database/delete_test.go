@@ -0,0 +1,208 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDelete(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	_, err := db.Delete(nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = db.Delete(&schema.DeleteKeysRequest{Keys: [][]byte{{}}})
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = db.Delete(&schema.DeleteKeysRequest{Keys: [][]byte{[]byte("k1"), []byte("k1")}})
+	require.Equal(t, schema.ErrDuplicatedKeysNotSupported, err)
+
+	_, err = db.Delete(&schema.DeleteKeysRequest{Keys: [][]byte{[]byte("k1")}})
+	require.Equal(t, store.ErrKeyNotFound, err)
+
+	txmd, err := db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{
+		{Key: []byte("k1"), Value: []byte("v1")},
+		{Key: []byte("k2"), Value: []byte("v2")},
+	}})
+	require.NoError(t, err)
+
+	_, err = db.Delete(&schema.DeleteKeysRequest{Keys: [][]byte{[]byte("k1"), []byte("k3")}})
+	require.Equal(t, store.ErrKeyNotFound, err)
+
+	_, err = db.Get(&schema.KeyRequest{Key: []byte("k1")})
+	require.NoError(t, err)
+
+	deletion, err := db.Delete(&schema.DeleteKeysRequest{Keys: [][]byte{[]byte("k1")}})
+	require.NoError(t, err)
+
+	_, err = db.Get(&schema.KeyRequest{Key: []byte("k1")})
+	require.Equal(t, store.ErrKeyNotFound, err)
+
+	_, err = db.Delete(&schema.DeleteKeysRequest{Keys: [][]byte{[]byte("k1")}})
+	require.Equal(t, store.ErrKeyNotFound, err)
+
+	item, err := db.Get(&schema.KeyRequest{Key: []byte("k1"), AtTx: txmd.Id})
+	require.NoError(t, err)
+	require.Equal(t, []byte("v1"), item.Value)
+
+	entries, err := db.Scan(context.Background(), &schema.ScanRequest{Prefix: []byte("k"), SinceTx: deletion.Id})
+	require.NoError(t, err)
+	require.Len(t, entries.Entries, 1)
+	require.Equal(t, []byte("k2"), entries.Entries[0].Key)
+
+	history, err := db.History(&schema.HistoryRequest{Key: []byte("k1"), SinceTx: deletion.Id})
+	require.NoError(t, err)
+	require.Len(t, history.Entries, 2)
+	require.Equal(t, []byte("v1"), history.Entries[0].Value)
+	require.False(t, history.Entries[0].Deleted)
+	require.Nil(t, history.Entries[1].Value)
+	require.True(t, history.Entries[1].Deleted)
+	require.Equal(t, deletion.Id, history.Entries[1].Tx)
+
+	diff, err := db.Diff(&schema.DiffRequest{FromTx: txmd.Id})
+	require.NoError(t, err)
+	require.Len(t, diff.Entries, 1)
+	require.Equal(t, schema.DiffKind_DIFF_DELETED, diff.Entries[0].Kind)
+	require.Equal(t, []byte("k1"), diff.Entries[0].Entry.Key)
+	require.True(t, diff.Entries[0].Entry.Deleted)
+
+	_, err = db.Set(&schema.SetRequest{
+		KVs:           []*schema.KeyValue{{Key: []byte("k1"), Value: []byte("v1")}},
+		Preconditions: []*schema.Precondition{schema.PreconditionKeyMustNotExist([]byte("k1"))},
+	})
+	require.NoError(t, err)
+
+	item, err = db.Get(&schema.KeyRequest{Key: []byte("k1")})
+	require.NoError(t, err)
+	require.Equal(t, []byte("v1"), item.Value)
+}
+
+func TestDeleteLegalHold(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	_, err := db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("case1:doc"), Value: []byte("v")}}})
+	require.NoError(t, err)
+
+	_, err = db.SetLegalHold(&schema.LegalHold{Prefix: []byte("case1:"), Active: true})
+	require.NoError(t, err)
+
+	_, err = db.Delete(&schema.DeleteKeysRequest{Keys: [][]byte{[]byte("case1:doc")}})
+	require.Equal(t, ErrLegalHold, err)
+}
+
+func TestDeleteWORM(t *testing.T) {
+	rootPath := "data_" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	defer os.RemoveAll(rootPath)
+
+	db, err := NewDb(DefaultOption().WithDbRootPath(rootPath).WithDbName("db").WithWORM(true, 0), nil, logger.NewSimpleLogger("immudb ", os.Stderr))
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("k"), Value: []byte("v")}}})
+	require.NoError(t, err)
+
+	_, err = db.Delete(&schema.DeleteKeysRequest{Keys: [][]byte{[]byte("k")}})
+	require.Equal(t, ErrWORM, err)
+}
+
+func TestDeleteByPrefix(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	_, err := db.DeleteByPrefix(nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = db.DeleteByPrefix(&schema.DeleteByPrefixRequest{})
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = db.DeleteByPrefix(&schema.DeleteByPrefixRequest{Prefix: []byte("tenant1:"), BatchSize: 1 << 20})
+	require.Equal(t, ErrIllegalArguments, err)
+
+	kvs := []*schema.KeyValue{{Key: []byte("tenant2:k"), Value: []byte("v")}}
+	for i := 0; i < 10; i++ {
+		kvs = append(kvs, &schema.KeyValue{Key: []byte("tenant1:k" + strconv.Itoa(i)), Value: []byte("v")})
+	}
+
+	_, err = db.Set(&schema.SetRequest{KVs: kvs})
+	require.NoError(t, err)
+
+	_, err = db.Delete(&schema.DeleteKeysRequest{Keys: [][]byte{[]byte("tenant1:k3")}})
+	require.NoError(t, err)
+
+	res, err := db.DeleteByPrefix(&schema.DeleteByPrefixRequest{Prefix: []byte("tenant1:"), DryRun: true})
+	require.NoError(t, err)
+	require.Equal(t, uint64(9), res.Count)
+	require.Empty(t, res.Txs)
+
+	_, err = db.Get(&schema.KeyRequest{Key: []byte("tenant1:k0")})
+	require.NoError(t, err)
+
+	res, err = db.DeleteByPrefix(&schema.DeleteByPrefixRequest{Prefix: []byte("tenant1:"), BatchSize: 4})
+	require.NoError(t, err)
+	require.Equal(t, uint64(9), res.Count)
+	require.Len(t, res.Txs, 3)
+	require.Equal(t, int32(4), res.Txs[0].Nentries)
+	require.Equal(t, int32(4), res.Txs[1].Nentries)
+	require.Equal(t, int32(1), res.Txs[2].Nentries)
+
+	for i := 0; i < 10; i++ {
+		_, err = db.Get(&schema.KeyRequest{Key: []byte("tenant1:k" + strconv.Itoa(i))})
+		require.Equal(t, store.ErrKeyNotFound, err)
+	}
+
+	_, err = db.Get(&schema.KeyRequest{Key: []byte("tenant2:k")})
+	require.NoError(t, err)
+
+	res, err = db.DeleteByPrefix(&schema.DeleteByPrefixRequest{Prefix: []byte("tenant1:")})
+	require.NoError(t, err)
+	require.Zero(t, res.Count)
+	require.Empty(t, res.Txs)
+}
+
+func TestDeleteByPrefixLegalHold(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	_, err := db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{
+		{Key: []byte("tenant1:doc"), Value: []byte("v")},
+		{Key: []byte("tenant1:case1:doc"), Value: []byte("v")},
+	}})
+	require.NoError(t, err)
+
+	_, err = db.SetLegalHold(&schema.LegalHold{Prefix: []byte("tenant1:case1:"), Active: true})
+	require.NoError(t, err)
+
+	_, err = db.DeleteByPrefix(&schema.DeleteByPrefixRequest{Prefix: []byte("tenant1:"), DryRun: true})
+	require.Equal(t, ErrLegalHold, err)
+
+	_, err = db.DeleteByPrefix(&schema.DeleteByPrefixRequest{Prefix: []byte("tenant1:"), BatchSize: 1})
+	require.Equal(t, ErrLegalHold, err)
+
+	_, err = db.Get(&schema.KeyRequest{Key: []byte("tenant1:doc")})
+	require.NoError(t, err)
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+)
+
+// Diff returns the keys assigned a value by the transactions committed after FromTx up to ToTx, along with
+// their latest value. Changes are read from the transactions themselves, so keys not modified in between are not
+// visited. When the limit of keys is reached, the response covers fewer transactions and ToTx is set to the last one
+func (d *db) Diff(req *schema.DiffRequest) (*schema.DiffResponse, error) {
+	if req == nil {
+		return nil, ErrIllegalArguments
+	}
+
+	if req.Limit > MaxKeyScanLimit {
+		return nil, ErrMaxKeyScanLimitExceeded
+	}
+
+	limit := int(req.Limit)
+	if limit == 0 {
+		limit = MaxKeyScanLimit
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	lastTxID, _ := d.st.Alh()
+
+	toTx := req.ToTx
+	if toTx == 0 {
+		toTx = lastTxID
+	}
+
+	if req.FromTx > toTx || toTx > lastTxID {
+		return nil, ErrIllegalArguments
+	}
+
+	res := &schema.DiffResponse{ToTx: req.FromTx}
+
+	if req.FromTx == toTx {
+		return res, nil
+	}
+
+	err := d.st.WaitForIndexingUpto(toTx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := WrapWithPrefix(req.Prefix, SetKeyPrefix)
+
+	// latest transaction which assigned each key
+	changes := make(map[string]uint64)
+
+	txReader, err := d.st.NewTxReader(req.FromTx+1, false, d.tx1)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		tx, err := txReader.Read()
+		if err == store.ErrNoMoreEntries {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if tx.ID > toTx {
+			break
+		}
+
+		var keys []string
+
+		for _, e := range tx.Entries() {
+			if bytes.HasPrefix(e.Key(), prefix) {
+				keys = append(keys, string(e.Key()))
+			}
+		}
+
+		added := 0
+		for _, k := range keys {
+			if _, ok := changes[k]; !ok {
+				added++
+			}
+		}
+
+		// transactions are never split, unless the first one exceeds the limit on its own
+		if len(changes)+added > limit && res.ToTx > req.FromTx {
+			break
+		}
+
+		for _, k := range keys {
+			changes[k] = tx.ID
+		}
+
+		res.ToTx = tx.ID
+	}
+
+	for k, tx := range changes {
+		e, err := d.diffEntry([]byte(k), tx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		res.Entries = append(res.Entries, e)
+	}
+
+	sort.Slice(res.Entries, func(i, j int) bool {
+		return bytes.Compare(res.Entries[i].Entry.Key, res.Entries[j].Entry.Key) < 0
+	})
+
+	return res, nil
+}
+
+func (d *db) diffEntry(key []byte, tx uint64, req *schema.DiffRequest) (*schema.DiffEntry, error) {
+	e := &schema.DiffEntry{Kind: schema.DiffKind_DIFF_ADDED}
+
+	if req.FromTx > 0 {
+		txs, err := d.st.History(key, 0, false, 1)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(txs) > 0 && txs[0] <= req.FromTx {
+			e.Kind = schema.DiffKind_DIFF_UPDATED
+		}
+	}
+
+	val, err := d.readValue(key, tx, d.tx1)
+	if err != nil {
+		return nil, err
+	}
+
+	if deleted(val) {
+		e.Kind = schema.DiffKind_DIFF_DELETED
+		e.Entry = &schema.Entry{Key: TrimPrefix(key), Tx: tx, Deleted: true}
+		return e, nil
+	}
+
+	if req.NoValues {
+		e.Entry = &schema.Entry{Key: TrimPrefix(key), Tx: tx}
+		return e, nil
+	}
+
+	if val[0] == ReferenceValuePrefix {
+		e.Entry, err = d.getAt(key, tx, 0, d.st, d.tx1)
+		if unreadable(err) {
+			e.Entry = &schema.Entry{Key: TrimPrefix(key), Tx: tx}
+			return e, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return e, nil
+	}
+
+	value, expiresAt, err := decodeValue(val)
+	if err != nil {
+		return nil, err
+	}
+
+	if expired(expiresAt) {
+		value = nil
+	}
+
+	e.Entry = &schema.Entry{Key: TrimPrefix(key), Value: value, Tx: tx, ExpiresAt: expiresAt, Blob: val[0] == BlobValuePrefix}
+
+	return e, nil
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	set := func(kvs ...*schema.KeyValue) uint64 {
+		txmd, err := db.Set(&schema.SetRequest{KVs: kvs})
+		require.NoError(t, err)
+		return txmd.Id
+	}
+
+	_, err := db.Diff(nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = db.Diff(&schema.DiffRequest{Limit: MaxKeyScanLimit + 1})
+	require.Equal(t, ErrMaxKeyScanLimitExceeded, err)
+
+	tx1 := set(&schema.KeyValue{Key: []byte("user:1"), Value: []byte("alice")}, &schema.KeyValue{Key: []byte("user:2"), Value: []byte("bob")})
+	set(&schema.KeyValue{Key: []byte("group:1"), Value: []byte("admins")})
+	set(&schema.KeyValue{Key: []byte("user:1"), Value: []byte("alice smith")})
+	set(&schema.KeyValue{Key: []byte("user:3"), Value: []byte("carol")})
+	tx5 := set(&schema.KeyValue{Key: []byte("user:3"), Value: []byte("carol"), ExpiresAt: time.Now().Add(-time.Minute).Unix()})
+
+	_, err = db.Diff(&schema.DiffRequest{FromTx: tx5 + 1})
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = db.Diff(&schema.DiffRequest{ToTx: tx5 + 1})
+	require.Equal(t, ErrIllegalArguments, err)
+
+	res, err := db.Diff(&schema.DiffRequest{FromTx: tx1, Prefix: []byte("user:")})
+	require.NoError(t, err)
+	require.Equal(t, tx5, res.ToTx)
+	require.Len(t, res.Entries, 2)
+
+	require.Equal(t, schema.DiffKind_DIFF_UPDATED, res.Entries[0].Kind)
+	require.Equal(t, []byte("user:1"), res.Entries[0].Entry.Key)
+	require.Equal(t, []byte("alice smith"), res.Entries[0].Entry.Value)
+
+	require.Equal(t, schema.DiffKind_DIFF_ADDED, res.Entries[1].Kind)
+	require.Equal(t, []byte("user:3"), res.Entries[1].Entry.Key)
+	require.Equal(t, tx5, res.Entries[1].Entry.Tx)
+	require.Nil(t, res.Entries[1].Entry.Value)
+	require.NotZero(t, res.Entries[1].Entry.ExpiresAt)
+
+	res, err = db.Diff(&schema.DiffRequest{FromTx: tx5})
+	require.NoError(t, err)
+	require.Equal(t, tx5, res.ToTx)
+	require.Empty(t, res.Entries)
+
+	// the limit of keys is exceeded by the fourth transaction, so only the first three are considered
+	res, err = db.Diff(&schema.DiffRequest{Limit: 3, NoValues: true})
+	require.NoError(t, err)
+	require.Equal(t, tx1+2, res.ToTx)
+	require.Len(t, res.Entries, 3)
+	require.Nil(t, res.Entries[0].Entry.Value)
+
+	for _, e := range res.Entries {
+		require.Equal(t, schema.DiffKind_DIFF_ADDED, e.Kind)
+	}
+
+	res, err = db.Diff(&schema.DiffRequest{FromTx: res.ToTx, Limit: 3})
+	require.NoError(t, err)
+	require.Equal(t, tx5, res.ToTx)
+	require.Len(t, res.Entries, 1)
+	require.Equal(t, []byte("user:3"), res.Entries[0].Entry.Key)
+
+	// a single transaction exceeding the limit is considered on its own
+	res, err = db.Diff(&schema.DiffRequest{Limit: 1})
+	require.NoError(t, err)
+	require.Equal(t, tx1, res.ToTx)
+	require.Len(t, res.Entries, 2)
+}
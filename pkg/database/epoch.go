@@ -0,0 +1,132 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+)
+
+// The fencing epoch of a database is written into the database itself, under the EpochKeyPrefix key, and advanced
+// whenever a new primary takes over. Replicas get it from the transactions of their master, so a promoted replica
+// continues from the epoch of the former primary. Clients send the highest epoch they know along with writes, so
+// both clients unaware of the new primary and a former primary still accepting writes are fenced off
+
+// EncodeEpoch returns the entry recording the fencing epoch of a database
+func EncodeEpoch(epoch uint64) *store.KV {
+	v := make([]byte, 8)
+	binary.BigEndian.PutUint64(v, epoch)
+
+	return &store.KV{Key: []byte{EpochKeyPrefix}, Value: v}
+}
+
+// Epoch returns the current fencing epoch, zero until the epoch is advanced for the first time
+func (d *db) Epoch() *schema.Epoch {
+	d.epochMutex.RLock()
+	defer d.epochMutex.RUnlock()
+
+	return &schema.Epoch{Epoch: d.epoch.Epoch, Tx: d.epoch.Tx}
+}
+
+// AdvanceEpoch increments the fencing epoch, it's meant to be called on the new primary after a failover
+func (d *db) AdvanceEpoch() (*schema.Epoch, error) {
+	if d.options.replica {
+		return nil, ErrIsReplica
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	epoch := d.Epoch().Epoch + 1
+
+	txmd, err := d.st.Commit([]*store.KV{EncodeEpoch(epoch)}, true)
+	if err != nil {
+		return nil, err
+	}
+
+	d.setEpoch(&schema.Epoch{Epoch: epoch, Tx: txmd.ID})
+
+	return d.Epoch(), nil
+}
+
+// CheckEpoch returns an error if a write sent along with the given epoch must be rejected.
+// ErrStaleEpoch is returned if the epoch was superseded, ErrStalePrimary if this database didn't reach it yet
+func (d *db) CheckEpoch(epoch uint64) error {
+	current := d.Epoch().Epoch
+
+	if epoch < current {
+		return ErrStaleEpoch
+	}
+
+	if epoch > current {
+		return ErrStalePrimary
+	}
+
+	return nil
+}
+
+func (d *db) setEpoch(epoch *schema.Epoch) {
+	d.epochMutex.Lock()
+	defer d.epochMutex.Unlock()
+
+	d.epoch = epoch
+}
+
+// replicateEpoch updates the epoch if it was advanced by a replicated transaction
+func (d *db) replicateEpoch(entries []*store.KV, txID uint64) error {
+	for _, e := range entries {
+		if !bytes.Equal(e.Key, []byte{EpochKeyPrefix}) {
+			continue
+		}
+
+		if len(e.Value) != 8 {
+			return store.ErrCorruptedData
+		}
+
+		d.setEpoch(&schema.Epoch{Epoch: binary.BigEndian.Uint64(e.Value), Tx: txID})
+	}
+
+	return nil
+}
+
+// loadEpoch reads the latest epoch written into the database
+func (d *db) loadEpoch() error {
+	lastTxID, _ := d.st.Alh()
+
+	err := d.st.WaitForIndexingUpto(lastTxID, nil)
+	if err != nil {
+		return err
+	}
+
+	v, tx, _, err := d.st.Get([]byte{EpochKeyPrefix})
+	if err == store.ErrKeyNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(v) != 8 {
+		return store.ErrCorruptedData
+	}
+
+	d.epoch = &schema.Epoch{Epoch: binary.BigEndian.Uint64(v), Tx: tx}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEpoch(t *testing.T) {
+	rootPath := "data_" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	defer os.RemoveAll(rootPath)
+
+	options := DefaultOption().WithDbRootPath(rootPath).WithDbName("db")
+
+	db, err := NewDb(options, nil, logger.NewSimpleLogger("immudb ", os.Stderr))
+	require.NoError(t, err)
+
+	require.Zero(t, db.Epoch().Epoch)
+	require.NoError(t, db.CheckEpoch(0))
+	require.Equal(t, ErrStalePrimary, db.CheckEpoch(1))
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("key"), Value: []byte("value")}}})
+	require.NoError(t, err)
+
+	epoch, err := db.AdvanceEpoch()
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), epoch.Epoch)
+
+	state, err := db.CurrentState()
+	require.NoError(t, err)
+	require.Equal(t, state.TxId, epoch.Tx)
+	require.Equal(t, epoch, db.Epoch())
+
+	require.Equal(t, ErrStaleEpoch, db.CheckEpoch(0))
+	require.NoError(t, db.CheckEpoch(1))
+	require.Equal(t, ErrStalePrimary, db.CheckEpoch(2))
+
+	catalogDB, err := NewDb(DefaultOption().WithDbRootPath(rootPath).WithDbName("catalog"), nil, logger.NewSimpleLogger("immudb ", os.Stderr))
+	require.NoError(t, err)
+	defer catalogDB.Close()
+
+	replicaOpts := DefaultOption().WithDbRootPath(rootPath).WithDbName("replica").WithReplica(true)
+
+	replica, err := NewDb(replicaOpts, catalogDB, logger.NewSimpleLogger("immudb ", os.Stderr))
+	require.NoError(t, err)
+
+	_, err = replica.AdvanceEpoch()
+	require.Equal(t, ErrIsReplica, err)
+
+	for txID := uint64(1); txID <= epoch.Tx; txID++ {
+		etx, err := db.ExportTx(&schema.TxRequest{Tx: txID})
+		require.NoError(t, err)
+
+		_, err = replica.ReplicateTx(etx)
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, epoch, replica.Epoch())
+
+	err = replica.Close()
+	require.NoError(t, err)
+
+	err = db.Close()
+	require.NoError(t, err)
+
+	db, err = OpenDb(options, nil, logger.NewSimpleLogger("immudb ", os.Stderr))
+	require.NoError(t, err)
+	defer db.Close()
+
+	require.Equal(t, epoch, db.Epoch())
+
+	epoch, err = db.AdvanceEpoch()
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), epoch.Epoch)
+}
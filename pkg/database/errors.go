@@ -22,10 +22,47 @@ import (
 )
 
 var (
-	ErrIndexKeyMismatch      = status.New(codes.InvalidArgument, "mismatch between provided index and key").Err()
-	ErrNoReferenceProvided   = status.New(codes.InvalidArgument, "provided argument is not a reference").Err()
-	ErrReferenceKeyMissing   = status.New(codes.InvalidArgument, "reference key not provided").Err()
-	ErrZAddIndexMissing      = status.New(codes.InvalidArgument, "zAdd index not provided").Err()
-	ErrReferenceIndexMissing = status.New(codes.InvalidArgument, "reference index not provided").Err()
-	ErrDatabaseNotExists     = status.New(codes.NotFound, "database does not exist").Err()
+	ErrIndexKeyMismatch              = status.New(codes.InvalidArgument, "mismatch between provided index and key").Err()
+	ErrNoReferenceProvided           = status.New(codes.InvalidArgument, "provided argument is not a reference").Err()
+	ErrReferenceKeyMissing           = status.New(codes.InvalidArgument, "reference key not provided").Err()
+	ErrZAddIndexMissing              = status.New(codes.InvalidArgument, "zAdd index not provided").Err()
+	ErrReferenceIndexMissing         = status.New(codes.InvalidArgument, "reference index not provided").Err()
+	ErrDatabaseNotExists             = status.New(codes.NotFound, "database does not exist").Err()
+	ErrOperationCancelled            = status.New(codes.Canceled, "operation cancelled").Err()
+	ErrIsReplica                     = status.New(codes.FailedPrecondition, "database is read-only because it's a replica").Err()
+	ErrPrefixStatsDisabled           = status.New(codes.FailedPrecondition, "per-prefix statistics are disabled").Err()
+	ErrTooManyTxRangeChunks          = status.New(codes.InvalidArgument, "too many chunks requested, the chunk size should be increased").Err()
+	ErrSQLTxNotFound                 = status.New(codes.NotFound, "sql transaction not found").Err()
+	ErrSQLTxExpired                  = status.New(codes.DeadlineExceeded, "sql transaction was aborted after staying idle longer than the timeout").Err()
+	ErrSQLTxConflict                 = status.New(codes.Aborted, "sql transaction conflicts with a concurrent transaction, it can be retried").Err()
+	ErrIteratorLeaseNotFound         = status.New(codes.NotFound, "iterator lease not found").Err()
+	ErrIteratorLeaseExpired          = status.New(codes.DeadlineExceeded, "iterator lease expired after staying idle longer than the timeout, the iteration can be resumed from the latest resume token").Err()
+	ErrKeyRejected                   = status.New(codes.InvalidArgument, "key does not satisfy the naming rules of the database").Err()
+	ErrIteratorLeaseInvalidated      = status.New(codes.Aborted, "iterator lease was invalidated by an index compaction, the iteration can be resumed from the latest resume token").Err()
+	ErrWatchSubscriptionNotFound     = status.New(codes.NotFound, "watch subscription not found").Err()
+	ErrTxChainMismatch               = status.New(codes.DataLoss, "transaction is not linked to its predecessor").Err()
+	ErrMaxWatchSubscriptionsExceeded = status.New(codes.ResourceExhausted, "maximum number of watch subscriptions exceeded").Err()
+	ErrSortedSetMemberNotFound       = status.New(codes.NotFound, "key is not a member of the sorted set").Err()
+	ErrDryRunDDL                     = status.New(codes.InvalidArgument, "dry run is only supported for DML statements").Err()
+	ErrApprovalRequired              = status.New(codes.FailedPrecondition, "write requires approval, it must be proposed instead").Err()
+	ErrLegalHold                     = status.New(codes.FailedPrecondition, "key is under legal hold").Err()
+	ErrLegalHoldAlreadyPlaced        = status.New(codes.AlreadyExists, "a legal hold is already placed on the prefix").Err()
+	ErrLegalHoldNotFound             = status.New(codes.NotFound, "no legal hold is placed on the prefix").Err()
+	ErrInvalidContinuation           = status.New(codes.InvalidArgument, "invalid continuation token").Err()
+	ErrWORM                          = status.New(codes.FailedPrecondition, "operation not allowed in write-once mode").Err()
+	ErrWORMMaxVersionsExceeded       = status.New(codes.FailedPrecondition, "key reached the maximum number of versions allowed in write-once mode").Err()
+	ErrSnapshotAlreadyPinned         = status.New(codes.AlreadyExists, "a snapshot is already pinned with the same name").Err()
+	ErrSnapshotNotFound              = status.New(codes.NotFound, "no snapshot is pinned with the given name").Err()
+	ErrStaleEpoch                    = status.New(codes.FailedPrecondition, "write rejected, the epoch was superseded by a newer primary").Err()
+	ErrStalePrimary                  = status.New(codes.FailedPrecondition, "write rejected, the database is behind the epoch and is no longer the primary").Err()
+	ErrKeyExpired                    = status.New(codes.NotFound, "key expired").Err()
+	ErrNoRewindAttestation           = status.New(codes.NotFound, "no rewind was attested").Err()
+	ErrPreconditionFailed            = status.New(codes.FailedPrecondition, "write precondition not satisfied").Err()
+	ErrNotCounter                    = status.New(codes.FailedPrecondition, "key does not hold an integer value").Err()
+	ErrCounterOverflow               = status.New(codes.OutOfRange, "counter would overflow").Err()
+	ErrNotBlob                       = status.New(codes.FailedPrecondition, "key does not hold a blob, it must be read with Get").Err()
+	ErrObjectNotFound                = status.New(codes.NotFound, "object version not found").Err()
+	ErrSecondaryIndexAlreadyExists   = status.New(codes.AlreadyExists, "a secondary index already exists with the same name").Err()
+	ErrSecondaryIndexNotFound        = status.New(codes.NotFound, "secondary index not found").Err()
+	ErrMaxSecondaryIndexesExceeded   = status.New(codes.ResourceExhausted, "max number of secondary indexes exceeded").Err()
 )
@@ -0,0 +1,97 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+)
+
+// Expirable values are prefixed with ExpirableValuePrefix followed by the expiration time. Once expired, the key
+// can no longer be read while the transaction which assigned the value stays in the history, so proofs of the
+// transaction can still be verified
+
+// encodeKV returns the entry of a key-value being set, which expires if the key-value has an expiration time
+func (d *db) encodeKV(kv *schema.KeyValue) (*store.KV, error) {
+	if kv.ExpiresAt == 0 {
+		e := EncodeKV(kv.Key, kv.Value)
+		e.Incompressible = kv.Incompressible
+		return e, nil
+	}
+
+	if kv.ExpiresAt < 0 {
+		return nil, ErrIllegalArguments
+	}
+
+	// expiring a key is a scheduled removal
+	err := d.checkWORMRemoval()
+	if err != nil {
+		return nil, err
+	}
+
+	err = d.checkLegalHold(kv.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	e := EncodeExpirableKV(kv.Key, kv.Value, kv.ExpiresAt)
+	e.Incompressible = kv.Incompressible
+
+	return e, nil
+}
+
+// decodeValue returns the value assigned to a key by a plain or expirable value, along with its expiration time
+func decodeValue(val []byte) (value []byte, expiresAt int64, err error) {
+	if len(val) == 0 {
+		return nil, 0, store.ErrCorruptedData
+	}
+
+	if val[0] != ExpirableValuePrefix {
+		return TrimPrefix(val), 0, nil
+	}
+
+	if len(val) < 1+8 {
+		return nil, 0, store.ErrCorruptedData
+	}
+
+	return val[1+8:], int64(binary.BigEndian.Uint64(val[1:])), nil
+}
+
+func expired(expiresAt int64) bool {
+	return expiresAt > 0 && time.Now().Unix() >= expiresAt
+}
+
+// valueEntry returns the entry of a key assigned a value which is not a reference, ErrKeyExpired if it expired
+// and store.ErrKeyNotFound if it was deleted
+func valueEntry(key, val []byte, tx uint64) (*schema.Entry, error) {
+	if deleted(val) {
+		return nil, store.ErrKeyNotFound
+	}
+
+	value, expiresAt, err := decodeValue(val)
+	if err != nil {
+		return nil, err
+	}
+
+	if expired(expiresAt) {
+		return nil, ErrKeyExpired
+	}
+
+	return &schema.Entry{Key: TrimPrefix(key), Value: value, Tx: tx, ExpiresAt: expiresAt, Blob: val[0] == BlobValuePrefix}, nil
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpirableSet(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	_, err := db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("k"), Value: []byte("v"), ExpiresAt: -1}}})
+	require.Equal(t, ErrIllegalArguments, err)
+
+	expired := time.Now().Add(-time.Minute).Unix()
+	live := time.Now().Add(time.Hour).Unix()
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{
+		{Key: []byte("k1"), Value: []byte("v1"), ExpiresAt: live},
+		{Key: []byte("k2"), Value: []byte("v2")},
+	}})
+	require.NoError(t, err)
+
+	txmd, err := db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("k3"), Value: []byte("v3"), ExpiresAt: expired}}})
+	require.NoError(t, err)
+
+	e, err := db.Get(&schema.KeyRequest{Key: []byte("k1")})
+	require.NoError(t, err)
+	require.Equal(t, []byte("v1"), e.Value)
+	require.Equal(t, live, e.ExpiresAt)
+
+	_, err = db.Get(&schema.KeyRequest{Key: []byte("k3")})
+	require.Equal(t, ErrKeyExpired, err)
+
+	_, err = db.Get(&schema.KeyRequest{Key: []byte("k3"), AtTx: txmd.Id})
+	require.Equal(t, ErrKeyExpired, err)
+
+	list, err := db.Scan(context.Background(), &schema.ScanRequest{Prefix: []byte("k")})
+	require.NoError(t, err)
+	require.Len(t, list.Entries, 2)
+	require.Equal(t, []byte("k1"), list.Entries[0].Key)
+	require.Equal(t, []byte("k2"), list.Entries[1].Key)
+
+	list, err = db.GetAll(&schema.KeyListRequest{Keys: [][]byte{[]byte("k1"), []byte("k3")}})
+	require.NoError(t, err)
+	require.Len(t, list.Entries, 1)
+
+	for _, key := range []string{"k1", "k3"} {
+		_, err = db.ZAdd(&schema.ZAddRequest{Set: []byte("set"), Key: []byte(key), Score: 1})
+		if key == "k3" {
+			require.Equal(t, ErrKeyExpired, err)
+			continue
+		}
+		require.NoError(t, err)
+	}
+
+	history, err := db.History(&schema.HistoryRequest{Key: []byte("k3")})
+	require.NoError(t, err)
+	require.Len(t, history.Entries, 1)
+	require.Nil(t, history.Entries[0].Value)
+	require.Equal(t, expired, history.Entries[0].ExpiresAt)
+
+	// the expired entry is still part of the transaction, whose proofs can be verified
+	tx, err := db.TxByID(&schema.TxRequest{Tx: txmd.Id})
+	require.NoError(t, err)
+
+	stx := schema.TxFrom(tx)
+
+	proof, err := stx.Proof(EncodeKey([]byte("k3")))
+	require.NoError(t, err)
+	require.True(t, store.VerifyInclusion(proof, EncodeExpirableKV([]byte("k3"), []byte("v3"), expired), stx.Eh()))
+
+	vtx, err := db.VerifiableTxByID(&schema.VerifiableTxRequest{Tx: txmd.Id, ProveSinceTx: 1})
+	require.NoError(t, err)
+	require.Equal(t, txmd.Id, vtx.Tx.Metadata.Id)
+}
+
+func TestExpirableSetSortedSet(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	_, err := db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{
+		{Key: []byte("k1"), Value: []byte("v1")},
+		{Key: []byte("k2"), Value: []byte("v2")},
+	}})
+	require.NoError(t, err)
+
+	for i, key := range []string{"k1", "k2"} {
+		_, err = db.ZAdd(&schema.ZAddRequest{Set: []byte("set"), Key: []byte(key), Score: float64(i)})
+		require.NoError(t, err)
+	}
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("k1"), Value: []byte("v1"), ExpiresAt: time.Now().Add(-time.Minute).Unix()}}})
+	require.NoError(t, err)
+
+	list, err := db.ZScan(context.Background(), &schema.ZScanRequest{Set: []byte("set")})
+	require.NoError(t, err)
+	require.Len(t, list.Entries, 1)
+	require.Equal(t, []byte("k2"), list.Entries[0].Key)
+
+	popped, err := db.ZPopMin(&schema.ZPopRequest{Set: []byte("set"), Count: 2})
+	require.NoError(t, err)
+	require.Len(t, popped.Entries, 1)
+	require.Equal(t, []byte("k2"), popped.Entries[0].Key)
+}
+
+func TestExpirableSetWORM(t *testing.T) {
+	rootPath := "data_" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	defer os.RemoveAll(rootPath)
+
+	db, err := NewDb(DefaultOption().WithDbRootPath(rootPath).WithDbName("db").WithWORM(true, 0), nil, logger.NewSimpleLogger("immudb ", os.Stderr))
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("k"), Value: []byte("v"), ExpiresAt: time.Now().Add(time.Hour).Unix()}}})
+	require.Equal(t, ErrWORM, err)
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"github.com/codenotary/immudb/pkg/api/schema"
+)
+
+// GetMulti resolves a list of keys against a single snapshot
+func (d *db) GetMulti(req *schema.GetMultiRequest) (*schema.GetMultiResponse, error) {
+	if req == nil {
+		return nil, ErrIllegalArguments
+	}
+
+	if len(req.Keys) > MaxKeyScanLimit {
+		return nil, ErrMaxKeyScanLimitExceeded
+	}
+
+	err := d.st.WaitForIndexingUpto(req.SinceTx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	entries, errs, snapshotTx, err := d.getMulti(req)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &schema.GetMultiResponse{
+		Items:      make([]*schema.GetMultiItem, len(req.Keys)),
+		SnapshotTx: snapshotTx,
+	}
+
+	for i, key := range req.Keys {
+		res.Items[i] = &schema.GetMultiItem{Key: key, Entry: entries[i]}
+
+		if errs[i] != nil {
+			res.Items[i].Error = errs[i].Error()
+		} else {
+			d.prefixStats.read(key, len(entries[i].Key)+len(entries[i].Value))
+		}
+	}
+
+	return res, nil
+}
+
+// VerifiableGetMulti resolves a list of keys against a single snapshot, bundling the proofs of every entry found
+func (d *db) VerifiableGetMulti(req *schema.VerifiableGetMultiRequest) (*schema.VerifiableGetMultiResponse, error) {
+	if req == nil || req.GetMultiRequest == nil {
+		return nil, ErrIllegalArguments
+	}
+
+	if len(req.GetMultiRequest.Keys) > MaxKeyScanLimit {
+		return nil, ErrMaxKeyScanLimitExceeded
+	}
+
+	lastTxID, _ := d.st.Alh()
+	if lastTxID < req.ProveSinceTx {
+		return nil, ErrIllegalState
+	}
+
+	err := d.st.WaitForIndexingUpto(req.GetMultiRequest.SinceTx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	entries, errs, snapshotTx, err := d.getMulti(req.GetMultiRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &schema.VerifiableGetMultiResponse{
+		Items:      make([]*schema.VerifiableGetMultiItem, len(req.GetMultiRequest.Keys)),
+		SnapshotTx: snapshotTx,
+	}
+
+	for i, key := range req.GetMultiRequest.Keys {
+		res.Items[i] = &schema.VerifiableGetMultiItem{Key: key}
+
+		if errs[i] != nil {
+			res.Items[i].Error = errs[i].Error()
+			continue
+		}
+
+		res.Items[i].VerifiableEntry, err = d.verifiableEntry(entries[i], req.ProveSinceTx)
+		if err != nil {
+			return nil, err
+		}
+
+		d.prefixStats.read(key, len(entries[i].Key)+len(entries[i].Value))
+	}
+
+	return res, nil
+}
+
+// getMulti resolves every key against the same snapshot, when per item errors are requested the error of
+// each key is returned in errs instead of failing the whole lookup. The caller must hold the mutex
+func (d *db) getMulti(req *schema.GetMultiRequest) (entries []*schema.Entry, errs []error, snapshotTx uint64, err error) {
+	snapshot, err := d.st.SnapshotSince(req.SinceTx)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	defer snapshot.Close()
+
+	entries = make([]*schema.Entry, len(req.Keys))
+	errs = make([]error, len(req.Keys))
+
+	for i, key := range req.Keys {
+		if len(key) == 0 {
+			errs[i] = ErrIllegalArguments
+		} else {
+			entries[i], errs[i] = d.get(EncodeKey(key), snapshot, d.tx1)
+		}
+
+		if errs[i] != nil {
+			if !req.PerItemErrors {
+				return nil, nil, 0, errs[i]
+			}
+			entries[i] = nil
+		}
+	}
+
+	return entries, errs, snapshot.Ts(), nil
+}
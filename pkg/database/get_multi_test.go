@@ -0,0 +1,105 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"testing"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetMulti(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	_, err := db.GetMulti(nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = db.GetMulti(&schema.GetMultiRequest{Keys: make([][]byte, MaxKeyScanLimit+1)})
+	require.Equal(t, ErrMaxKeyScanLimitExceeded, err)
+
+	md, err := db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{
+		{Key: []byte("key1"), Value: []byte("value1")},
+		{Key: []byte("key2"), Value: []byte("value2")},
+	}})
+	require.NoError(t, err)
+
+	_, err = db.GetMulti(&schema.GetMultiRequest{Keys: [][]byte{[]byte("key1"), []byte("key3")}})
+	require.Equal(t, store.ErrKeyNotFound, err)
+
+	res, err := db.GetMulti(&schema.GetMultiRequest{
+		Keys:          [][]byte{[]byte("key2"), []byte("key3"), []byte("key1"), nil},
+		PerItemErrors: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, md.Id, res.SnapshotTx)
+	require.Len(t, res.Items, 4)
+
+	require.Equal(t, []byte("key2"), res.Items[0].Key)
+	require.Equal(t, []byte("value2"), res.Items[0].Entry.Value)
+	require.Empty(t, res.Items[0].Error)
+
+	require.Nil(t, res.Items[1].Entry)
+	require.Equal(t, store.ErrKeyNotFound.Error(), res.Items[1].Error)
+
+	require.Equal(t, []byte("value1"), res.Items[2].Entry.Value)
+	require.Equal(t, md.Id, res.Items[2].Entry.Tx)
+
+	require.Nil(t, res.Items[3].Entry)
+	require.Equal(t, ErrIllegalArguments.Error(), res.Items[3].Error)
+}
+
+func TestVerifiableGetMulti(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	_, err := db.VerifiableGetMulti(nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	md1, err := db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("key1"), Value: []byte("value1")}}})
+	require.NoError(t, err)
+
+	md2, err := db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("key2"), Value: []byte("value2")}}})
+	require.NoError(t, err)
+
+	_, err = db.VerifiableGetMulti(&schema.VerifiableGetMultiRequest{
+		GetMultiRequest: &schema.GetMultiRequest{Keys: [][]byte{[]byte("key1")}},
+		ProveSinceTx:    md2.Id + 1,
+	})
+	require.Equal(t, ErrIllegalState, err)
+
+	res, err := db.VerifiableGetMulti(&schema.VerifiableGetMultiRequest{
+		GetMultiRequest: &schema.GetMultiRequest{
+			Keys:          [][]byte{[]byte("key1"), []byte("key3"), []byte("key2")},
+			PerItemErrors: true,
+		},
+		ProveSinceTx: md1.Id,
+	})
+	require.NoError(t, err)
+	require.Len(t, res.Items, 3)
+
+	require.Equal(t, []byte("value1"), res.Items[0].VerifiableEntry.Entry.Value)
+	require.Equal(t, md1.Id, res.Items[0].VerifiableEntry.VerifiableTx.Tx.Metadata.Id)
+
+	require.Nil(t, res.Items[1].VerifiableEntry)
+	require.Equal(t, store.ErrKeyNotFound.Error(), res.Items[1].Error)
+
+	require.Equal(t, []byte("value2"), res.Items[2].VerifiableEntry.Entry.Value)
+	require.Equal(t, md2.Id, res.Items[2].VerifiableEntry.VerifiableTx.DualProof.TargetTxMetadata.Id)
+	require.Equal(t, md1.Id, res.Items[2].VerifiableEntry.VerifiableTx.DualProof.SourceTxMetadata.Id)
+}
@@ -0,0 +1,280 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+)
+
+// DefaultIdempotencyWindow is how long a write is remembered by its idempotency key
+const DefaultIdempotencyWindow = 10 * time.Minute
+
+// DefaultIdempotencyMaxKeys is the maximum number of idempotency keys remembered by each database
+const DefaultIdempotencyMaxKeys = 100_000
+
+// MaxIdempotencyKeyLen is the maximum length of idempotency keys
+const MaxIdempotencyKeyLen = 256
+
+// IdempotencyStats ...
+type IdempotencyStats struct {
+	// Keys is the number of idempotency keys currently remembered
+	Keys int
+	// Suppressed is the number of duplicated writes suppressed since the database was opened
+	Suppressed uint64
+}
+
+type idempotentWrite struct {
+	key  string
+	txmd *schema.TxMetadata
+	at   time.Time
+}
+
+// idempotencyTable remembers the transactions committed with an idempotency key, so retried writes return
+// the original transaction instead of being committed again. Keys are forgotten once they fall outside of
+// the time window or when the maximum number of keys is exceeded, oldest first
+type idempotencyTable struct {
+	window  time.Duration
+	maxKeys int
+
+	writes map[string]*list.Element
+	order  *list.List
+
+	// writes being committed, concurrent retries wait for them to complete
+	pending map[string]chan struct{}
+
+	suppressed uint64
+
+	mutex sync.Mutex
+}
+
+func newIdempotencyTable(window time.Duration, maxKeys int) *idempotencyTable {
+	return &idempotencyTable{
+		window:  window,
+		maxKeys: maxKeys,
+		writes:  make(map[string]*list.Element),
+		order:   list.New(),
+		pending: make(map[string]chan struct{}),
+	}
+}
+
+func (t *idempotencyTable) enabled() bool {
+	return t.window > 0 && t.maxKeys > 0
+}
+
+// begin returns the transaction previously committed with the given key, if any. Otherwise the key is
+// reserved until end is called, so concurrent retries are not committed twice
+func (t *idempotencyTable) begin(key string) (*schema.TxMetadata, error) {
+	if len(key) > MaxIdempotencyKeyLen {
+		return nil, ErrIllegalArguments
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for {
+		t.evict(time.Now())
+
+		if e, ok := t.writes[key]; ok {
+			t.suppressed++
+			return e.Value.(*idempotentWrite).txmd, nil
+		}
+
+		ch, ok := t.pending[key]
+		if !ok {
+			t.pending[key] = make(chan struct{})
+			return nil, nil
+		}
+
+		t.mutex.Unlock()
+		<-ch
+		t.mutex.Lock()
+	}
+}
+
+// end releases the key reserved by begin, remembering the transaction if it was committed
+func (t *idempotencyTable) end(key string, txmd *schema.TxMetadata) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if txmd != nil {
+		t.add(key, txmd, time.Now())
+	}
+
+	ch, ok := t.pending[key]
+	if !ok {
+		return
+	}
+
+	close(ch)
+	delete(t.pending, key)
+}
+
+// add must be called while holding the mutex, writes must be added in chronological order
+func (t *idempotencyTable) add(key string, txmd *schema.TxMetadata, at time.Time) {
+	if e, ok := t.writes[key]; ok {
+		t.order.Remove(e)
+	}
+
+	t.writes[key] = t.order.PushBack(&idempotentWrite{key: key, txmd: txmd, at: at})
+
+	t.evict(time.Now())
+}
+
+// evict must be called while holding the mutex
+func (t *idempotencyTable) evict(now time.Time) {
+	for t.order.Len() > 0 {
+		e := t.order.Front()
+		w := e.Value.(*idempotentWrite)
+
+		if t.order.Len() <= t.maxKeys && now.Sub(w.at) <= t.window {
+			return
+		}
+
+		t.order.Remove(e)
+		delete(t.writes, w.key)
+	}
+}
+
+func (t *idempotencyTable) stats() *IdempotencyStats {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.evict(time.Now())
+
+	return &IdempotencyStats{Keys: t.order.Len(), Suppressed: t.suppressed}
+}
+
+// ScopedIdempotencyKey namespaces an idempotency key by the user writing it and the database it's written to, so keys
+// chosen by different users never collide. The scoped key is a digest, hence of fixed length whatever the user name
+func ScopedIdempotencyKey(user, database, key string) (string, error) {
+	if key == "" {
+		return "", nil
+	}
+
+	if len(key) > MaxIdempotencyKeyLen {
+		return "", ErrIllegalArguments
+	}
+
+	h := sha256.New()
+
+	// each part is length-prefixed, so the parts can not be shifted from one another
+	for _, part := range []string{user, database, key} {
+		var l [4]byte
+		binary.BigEndian.PutUint32(l[:], uint32(len(part)))
+
+		h.Write(l[:])
+		h.Write([]byte(part))
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// IdempotencyStats returns the number of remembered idempotency keys and of suppressed duplicated writes
+func (d *db) IdempotencyStats() *IdempotencyStats {
+	return d.idempotency.stats()
+}
+
+// beginIdempotentWrite returns the transaction previously committed with the given idempotency key, if any.
+// Otherwise, when the returned transaction is nil, endIdempotentWrite must be deferred right away, so the
+// reservation is released even if the write panics and concurrent retries are not blocked forever
+func (d *db) beginIdempotentWrite(key string) (*schema.TxMetadata, error) {
+	if key == "" || !d.idempotency.enabled() {
+		return nil, nil
+	}
+
+	return d.idempotency.begin(key)
+}
+
+// endIdempotentWrite releases the key reserved by beginIdempotentWrite. txmd points to the result of the
+// write, as it is only known once the deferred call is executed
+func (d *db) endIdempotentWrite(key string, txmd **schema.TxMetadata) {
+	if key == "" || !d.idempotency.enabled() {
+		return
+	}
+
+	d.idempotency.end(key, *txmd)
+}
+
+// idempotencyEntry returns the entry recording the idempotency key as part of the transaction, if keys are persisted
+func (d *db) idempotencyEntry(key string) *store.KV {
+	if key == "" || !d.idempotency.enabled() || !d.options.idempotencyPersisted {
+		return nil
+	}
+
+	return &store.KV{Key: WrapWithPrefix([]byte(key), IdempotencyKeyPrefix), Value: []byte{}}
+}
+
+// loadIdempotencyKeys rebuilds the idempotency table from the keys written by the transactions committed within the
+// window, so retries are still deduplicated after a restart
+func (d *db) loadIdempotencyKeys() error {
+	if !d.idempotency.enabled() || !d.options.idempotencyPersisted {
+		return nil
+	}
+
+	lastTxID, _ := d.st.Alh()
+
+	now := time.Now()
+
+	var writes []*idempotentWrite
+
+	seen := make(map[string]bool)
+
+	tx := d.st.NewTx()
+
+	// transactions are read from the newest one until the window or the maximum number of keys is exceeded
+	for txID := lastTxID; txID > 0 && len(writes) < d.idempotency.maxKeys; txID-- {
+		err := d.st.ReadTx(txID, tx)
+		if err != nil {
+			return err
+		}
+
+		at := time.Unix(tx.Ts, 0)
+		if now.Sub(at) > d.idempotency.window {
+			break
+		}
+
+		for _, e := range tx.Entries() {
+			if len(e.Key()) == 0 || e.Key()[0] != IdempotencyKeyPrefix {
+				continue
+			}
+
+			key := string(TrimPrefix(e.Key()))
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			writes = append(writes, &idempotentWrite{key: key, txmd: schema.TxMetatadaTo(tx.Metadata()), at: at})
+		}
+	}
+
+	d.idempotency.mutex.Lock()
+	defer d.idempotency.mutex.Unlock()
+
+	for i := len(writes) - 1; i >= 0; i-- {
+		d.idempotency.add(writes[i].key, writes[i].txmd, writes[i].at)
+	}
+
+	return nil
+}
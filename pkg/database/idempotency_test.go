@@ -0,0 +1,232 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotentSet(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	req := &schema.SetRequest{
+		KVs:            []*schema.KeyValue{{Key: []byte("k1"), Value: []byte("v1")}},
+		IdempotencyKey: "req1",
+	}
+
+	txmd1, err := db.Set(req)
+	require.NoError(t, err)
+
+	txmd2, err := db.Set(req)
+	require.NoError(t, err)
+	require.Equal(t, txmd1.Id, txmd2.Id)
+
+	txmd3, err := db.Set(&schema.SetRequest{KVs: req.KVs, IdempotencyKey: "req2"})
+	require.NoError(t, err)
+	require.Greater(t, txmd3.Id, txmd1.Id)
+
+	txmd4, err := db.Set(&schema.SetRequest{KVs: req.KVs})
+	require.NoError(t, err)
+	require.Greater(t, txmd4.Id, txmd3.Id)
+
+	require.Equal(t, &IdempotencyStats{Keys: 2, Suppressed: 1}, db.IdempotencyStats())
+
+	_, err = db.Set(&schema.SetRequest{KVs: req.KVs, IdempotencyKey: strings.Repeat("k", MaxIdempotencyKeyLen+1)})
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = db.Set(&schema.SetRequest{IdempotencyKey: "req3"})
+	require.Error(t, err)
+
+	// failed writes are not remembered
+	txmd5, err := db.Set(&schema.SetRequest{KVs: req.KVs, IdempotencyKey: "req3"})
+	require.NoError(t, err)
+	require.Greater(t, txmd5.Id, txmd4.Id)
+}
+
+func TestIdempotentExecAll(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	req := &schema.ExecAllRequest{
+		Operations: []*schema.Op{
+			{Operation: &schema.Op_Kv{Kv: &schema.KeyValue{Key: []byte("k1"), Value: []byte("v1")}}},
+		},
+		IdempotencyKey: "req1",
+	}
+
+	txmd1, err := db.ExecAll(req)
+	require.NoError(t, err)
+
+	txmd2, err := db.ExecAll(req)
+	require.NoError(t, err)
+	require.Equal(t, txmd1.Id, txmd2.Id)
+
+	// keys are shared between Set and ExecAll
+	txmd3, err := db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("k2"), Value: []byte("v2")}}, IdempotencyKey: "req1"})
+	require.NoError(t, err)
+	require.Equal(t, txmd1.Id, txmd3.Id)
+
+	require.Equal(t, uint64(2), db.IdempotencyStats().Suppressed)
+}
+
+func TestIdempotencyTable(t *testing.T) {
+	table := newIdempotencyTable(time.Minute, 2)
+	require.True(t, table.enabled())
+
+	now := time.Now()
+
+	table.mutex.Lock()
+	table.add("k1", &schema.TxMetadata{Id: 1}, now.Add(-2*time.Minute))
+	table.add("k2", &schema.TxMetadata{Id: 2}, now)
+	table.add("k3", &schema.TxMetadata{Id: 3}, now)
+	table.add("k4", &schema.TxMetadata{Id: 4}, now)
+	table.mutex.Unlock()
+
+	// k1 falls outside of the window and k2 exceeds the maximum number of keys
+	require.Equal(t, &IdempotencyStats{Keys: 2}, table.stats())
+
+	txmd, err := table.begin("k2")
+	require.NoError(t, err)
+	require.Nil(t, txmd)
+
+	done := make(chan *schema.TxMetadata)
+
+	go func() {
+		txmd, _ := table.begin("k2")
+		done <- txmd
+	}()
+
+	table.end("k2", &schema.TxMetadata{Id: 5})
+
+	require.Equal(t, uint64(5), (<-done).Id)
+	require.Equal(t, &IdempotencyStats{Keys: 2, Suppressed: 1}, table.stats())
+
+	require.False(t, newIdempotencyTable(0, 10).enabled())
+	require.False(t, newIdempotencyTable(time.Minute, 0).enabled())
+}
+
+func TestScopedIdempotencyKey(t *testing.T) {
+	key, err := ScopedIdempotencyKey("user", "db", "")
+	require.NoError(t, err)
+	require.Empty(t, key)
+
+	_, err = ScopedIdempotencyKey("user", "db", strings.Repeat("k", MaxIdempotencyKeyLen+1))
+	require.Equal(t, ErrIllegalArguments, err)
+
+	key, err = ScopedIdempotencyKey("user", "db", "key")
+	require.NoError(t, err)
+	require.Len(t, key, 64)
+
+	for _, scope := range [][3]string{
+		{"other", "db", "key"},
+		{"user", "other", "key"},
+		{"use", "rdb", "key"},
+		{"user", "db", "other"},
+	} {
+		other, err := ScopedIdempotencyKey(scope[0], scope[1], scope[2])
+		require.NoError(t, err)
+		require.NotEqual(t, key, other)
+	}
+
+	same, err := ScopedIdempotencyKey("user", "db", "key")
+	require.NoError(t, err)
+	require.Equal(t, key, same)
+}
+
+func TestIdempotentWritePanicReleasesKey(t *testing.T) {
+	database, closer := makeDb()
+	defer closer()
+
+	d := database.(*db)
+
+	write := func() (txmd *schema.TxMetadata, err error) {
+		txmd, err = d.beginIdempotentWrite("req1")
+		if txmd != nil || err != nil {
+			return txmd, err
+		}
+		defer d.endIdempotentWrite("req1", &txmd)
+
+		panic("commit failed")
+	}
+
+	require.Panics(t, func() { write() })
+
+	done := make(chan error)
+
+	go func() {
+		txmd, err := d.beginIdempotentWrite("req1")
+		if err == nil && txmd != nil {
+			err = ErrIllegalState
+		}
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "idempotency key was not released")
+	}
+}
+
+func TestIdempotencyKeysPersisted(t *testing.T) {
+	rootPath := "data_" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	defer os.RemoveAll(rootPath)
+
+	options := DefaultOption().WithDbRootPath(rootPath).WithDbName("db").WithIdempotencyPersisted(true)
+
+	db, err := NewDb(options, nil, logger.NewSimpleLogger("immudb ", os.Stderr))
+	require.NoError(t, err)
+
+	req := &schema.SetRequest{
+		KVs:            []*schema.KeyValue{{Key: []byte("k1"), Value: []byte("v1")}},
+		IdempotencyKey: "req1",
+	}
+
+	txmd1, err := db.Set(req)
+	require.NoError(t, err)
+
+	_, err = db.Set(&schema.SetRequest{KVs: req.KVs, IdempotencyKey: "req2"})
+	require.NoError(t, err)
+
+	err = db.Close()
+	require.NoError(t, err)
+
+	db, err = OpenDb(options, nil, logger.NewSimpleLogger("immudb ", os.Stderr))
+	require.NoError(t, err)
+	defer db.Close()
+
+	require.Equal(t, &IdempotencyStats{Keys: 2}, db.IdempotencyStats())
+
+	txmd2, err := db.Set(req)
+	require.NoError(t, err)
+	require.Equal(t, txmd1.Id, txmd2.Id)
+
+	// idempotency keys are not visible through the key-value interface
+	list, err := db.Scan(context.Background(), &schema.ScanRequest{})
+	require.NoError(t, err)
+	require.Len(t, list.Entries, 1)
+}
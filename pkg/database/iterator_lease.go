@@ -0,0 +1,378 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/rs/xid"
+)
+
+// DefaultIteratorLeaseTimeout is how long an iterator lease is kept without receiving any request
+const DefaultIteratorLeaseTimeout = 1 * time.Minute
+
+const (
+	leaseActive = iota
+	leaseExpired
+	leaseInvalidated
+)
+
+// iteratorLease keeps the snapshot and the reader of a long-running scan open between requests
+type iteratorLease struct {
+	snap       *store.Snapshot
+	snapshotTx uint64
+	reader     *store.KeyReader
+
+	// tx holder used to resolve references, allocated on first use
+	tx *store.Tx
+
+	lastKey []byte
+	done    bool
+
+	// time of the latest request, or of the expiration or invalidation once the lease is released
+	lastUsed time.Time
+	state    int
+
+	mutex sync.Mutex
+}
+
+// OpenIterator takes a snapshot of the index and opens a reader over it, which is kept until the lease is closed,
+// expires because no request was received within the lease timeout or is invalidated by an index compaction.
+// Entries are then read in pages with IteratorNext and each page includes a token to resume the iteration
+// from a new lease.
+func (d *db) OpenIterator(ctx context.Context, req *schema.OpenIteratorRequest) (*schema.IteratorLease, error) {
+	if req == nil || (len(req.ResumeToken) > 0 && len(req.SeekKey) > 0) {
+		return nil, ErrIllegalArguments
+	}
+
+	if len(req.ResumeToken) > 0 && !bytes.HasPrefix(req.ResumeToken, req.Prefix) {
+		return nil, ErrIllegalArguments
+	}
+
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	waitUntilTx := req.SinceTx
+
+	if waitUntilTx == 0 {
+		waitUntilTx, _ = d.st.Alh()
+	}
+
+	if !req.NoWait {
+		err := d.st.WaitForIndexingUpto(waitUntilTx, ctx.Done())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	seekKey := req.SeekKey
+	if len(req.ResumeToken) > 0 {
+		seekKey = req.ResumeToken
+	}
+
+	if len(seekKey) > 0 {
+		seekKey = EncodeKey(seekKey)
+	}
+
+	snap, err := d.st.SnapshotSince(waitUntilTx)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := snap.NewKeyReader(
+		&store.KeyReaderSpec{
+			SeekKey:   seekKey,
+			Prefix:    EncodeKey(req.Prefix),
+			DescOrder: req.Desc,
+		})
+	if err != nil {
+		snap.Close()
+		return nil, err
+	}
+
+	id := xid.New().String()
+
+	now := time.Now()
+
+	d.iteratorLeasesMutex.Lock()
+	d.expireIteratorLeases(now)
+	d.iteratorLeases[id] = &iteratorLease{
+		snap:       snap,
+		snapshotTx: snap.Ts(),
+		reader:     r,
+		lastKey:    req.ResumeToken,
+		lastUsed:   now,
+	}
+	d.iteratorLeasesMutex.Unlock()
+
+	return d.iteratorLeaseTo(id, snap.Ts()), nil
+}
+
+// IteratorNext reads up to limit entries from the leased reader, refreshing the lease.
+// ErrIteratorLeaseExpired or ErrIteratorLeaseInvalidated are returned once the lease was released by the server,
+// the iteration can then be resumed by opening a new lease with the latest resume token
+func (d *db) IteratorNext(ctx context.Context, req *schema.IteratorNextRequest) (*schema.IteratorPage, error) {
+	if req == nil {
+		return nil, ErrIllegalArguments
+	}
+
+	if req.Limit > MaxKeyScanLimit {
+		return nil, ErrMaxKeyScanLimitExceeded
+	}
+
+	limit := req.Limit
+
+	if limit == 0 {
+		limit = MaxKeyScanLimit
+	}
+
+	// prevents the lease from being invalidated while entries are read
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	l, err := d.iteratorLease(req.LeaseId)
+	if err != nil {
+		return nil, err
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	// the lease may have been expired by a concurrent request after being looked up
+	err = l.err()
+	if err != nil {
+		return nil, err
+	}
+
+	page := &schema.IteratorPage{}
+
+	for !l.done && uint64(len(page.Entries)) < limit {
+		if ctx.Err() != nil {
+			return nil, ErrOperationCancelled
+		}
+
+		key, valRef, tx, _, err := l.reader.Read()
+		if err == store.ErrNoMoreEntries {
+			l.done = true
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		val, err := valRef.Resolve()
+		if err != nil {
+			return nil, err
+		}
+
+		var e *schema.Entry
+
+		if val[0] == ReferenceValuePrefix {
+			if l.tx == nil {
+				l.tx = d.st.NewTx()
+			}
+
+			e, err = d.getAt(key, tx, 0, l.snap, l.tx)
+		} else {
+			e, err = valueEntry(key, val, tx)
+		}
+		if unreadable(err) {
+			l.lastKey = TrimPrefix(key)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		page.Entries = append(page.Entries, e)
+
+		l.lastKey = TrimPrefix(key)
+	}
+
+	d.prefixStats.readEntries(page.Entries)
+
+	page.ResumeToken = l.lastKey
+	page.Done = l.done
+
+	return page, nil
+}
+
+// KeepAliveIterator refreshes the lease without reading any entry
+func (d *db) KeepAliveIterator(req *schema.IteratorLeaseRequest) (*schema.IteratorLease, error) {
+	if req == nil {
+		return nil, ErrIllegalArguments
+	}
+
+	l, err := d.iteratorLease(req.LeaseId)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.iteratorLeaseTo(req.LeaseId, l.snapshotTx), nil
+}
+
+// CloseIterator releases the lease along with its snapshot, closing a lease already released by the server is a no-op
+func (d *db) CloseIterator(req *schema.IteratorLeaseRequest) error {
+	if req == nil {
+		return ErrIllegalArguments
+	}
+
+	d.iteratorLeasesMutex.Lock()
+	defer d.iteratorLeasesMutex.Unlock()
+
+	d.expireIteratorLeases(time.Now())
+
+	l, ok := d.iteratorLeases[req.LeaseId]
+	if !ok {
+		return ErrIteratorLeaseNotFound
+	}
+
+	delete(d.iteratorLeases, req.LeaseId)
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.state != leaseActive {
+		return nil
+	}
+
+	return l.release(leaseExpired)
+}
+
+func (d *db) iteratorLeaseTo(id string, snapshotTx uint64) *schema.IteratorLease {
+	return &schema.IteratorLease{
+		LeaseId:       id,
+		SnapshotTx:    snapshotTx,
+		TimeoutMillis: d.options.iteratorLeaseTimeout.Milliseconds(),
+	}
+}
+
+// iteratorLease returns the lease with the given id, refreshing its idle time
+func (d *db) iteratorLease(id string) (*iteratorLease, error) {
+	d.iteratorLeasesMutex.Lock()
+	defer d.iteratorLeasesMutex.Unlock()
+
+	now := time.Now()
+
+	d.expireIteratorLeases(now)
+
+	l, ok := d.iteratorLeases[id]
+	if !ok {
+		return nil, ErrIteratorLeaseNotFound
+	}
+
+	if l.state != leaseActive {
+		delete(d.iteratorLeases, id)
+		return nil, l.err()
+	}
+
+	l.lastUsed = now
+
+	return l, nil
+}
+
+// expireIteratorLeases releases the leases idle for longer than the timeout, so abandoned iterators can't hold
+// their snapshots forever. Released leases are remembered during another timeout period, so clients get
+// an error telling the iteration can be resumed. It must be called while holding iteratorLeasesMutex
+func (d *db) expireIteratorLeases(now time.Time) {
+	timeout := d.options.iteratorLeaseTimeout
+	if timeout <= 0 {
+		return
+	}
+
+	for id, l := range d.iteratorLeases {
+		if now.Sub(l.lastUsed) <= timeout {
+			continue
+		}
+
+		if l.state != leaseActive {
+			delete(d.iteratorLeases, id)
+			continue
+		}
+
+		l.mutex.Lock()
+
+		err := l.release(leaseExpired)
+		if err != nil {
+			d.Logger.Warningf("unable to release expired iterator lease %s: %v", id, err)
+		}
+
+		l.lastUsed = now
+
+		l.mutex.Unlock()
+	}
+}
+
+// invalidateIteratorLeases releases all the active leases so the index can be compacted.
+// It must be called while holding the exclusive lock of the database
+func (d *db) invalidateIteratorLeases() {
+	d.iteratorLeasesMutex.Lock()
+	defer d.iteratorLeasesMutex.Unlock()
+
+	now := time.Now()
+
+	for id, l := range d.iteratorLeases {
+		if l.state != leaseActive {
+			continue
+		}
+
+		err := l.release(leaseInvalidated)
+		if err != nil {
+			d.Logger.Warningf("unable to release invalidated iterator lease %s: %v", id, err)
+		}
+
+		l.lastUsed = now
+	}
+}
+
+func (d *db) closeIteratorLeases() {
+	d.iteratorLeasesMutex.Lock()
+	defer d.iteratorLeasesMutex.Unlock()
+
+	for id, l := range d.iteratorLeases {
+		if l.state == leaseActive {
+			l.release(leaseExpired)
+		}
+		delete(d.iteratorLeases, id)
+	}
+}
+
+func (l *iteratorLease) release(state int) error {
+	l.state = state
+
+	err := l.reader.Close()
+	if err != nil {
+		l.snap.Close()
+		return err
+	}
+
+	return l.snap.Close()
+}
+
+func (l *iteratorLease) err() error {
+	switch l.state {
+	case leaseExpired:
+		return ErrIteratorLeaseExpired
+	case leaseInvalidated:
+		return ErrIteratorLeaseInvalidated
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+// makeIteratorLeaseDb creates a database in a temporary directory, removed by the returned closer
+// even if the database can not be cleanly closed
+func makeIteratorLeaseDb(t *testing.T) (DB, func()) {
+	dir, err := ioutil.TempDir("", "iterator_lease")
+	require.NoError(t, err)
+
+	options := DefaultOption().WithDbRootPath(dir).WithDbName("db").WithCorruptionChecker(false)
+	options.storeOpts.WithIndexOptions(options.storeOpts.IndexOpts.WithCompactionThld(0))
+
+	db, err := NewDb(options, nil, logger.NewSimpleLogger("immudb ", os.Stderr))
+	if err != nil {
+		os.RemoveAll(dir)
+	}
+	require.NoError(t, err)
+
+	return db, func() {
+		defer os.RemoveAll(dir)
+
+		require.NoError(t, db.Close())
+	}
+}
+
+func TestIteratorLease(t *testing.T) {
+	db, closer := makeIteratorLeaseDb(t)
+	defer closer()
+
+	for i := 0; i < 10; i++ {
+		_, err := db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte(fmt.Sprintf("key%d", i)), Value: []byte{byte(i)}}}})
+		require.NoError(t, err)
+	}
+
+	_, err := db.SetReference(&schema.ReferenceRequest{Key: []byte("key9ref"), ReferencedKey: []byte("key9")})
+	require.NoError(t, err)
+
+	_, err = db.OpenIterator(context.Background(), nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = db.OpenIterator(context.Background(), &schema.OpenIteratorRequest{SeekKey: []byte("key1"), ResumeToken: []byte("key2")})
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = db.OpenIterator(context.Background(), &schema.OpenIteratorRequest{Prefix: []byte("key"), ResumeToken: []byte("other")})
+	require.Equal(t, ErrIllegalArguments, err)
+
+	lease, err := db.OpenIterator(context.Background(), &schema.OpenIteratorRequest{Prefix: []byte("key")})
+	require.NoError(t, err)
+	require.NotEmpty(t, lease.LeaseId)
+	require.Equal(t, DefaultIteratorLeaseTimeout.Milliseconds(), lease.TimeoutMillis)
+
+	// entries added after the lease was opened are not visible
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("key99"), Value: []byte{99}}}})
+	require.NoError(t, err)
+
+	_, err = db.IteratorNext(context.Background(), nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = db.IteratorNext(context.Background(), &schema.IteratorNextRequest{LeaseId: lease.LeaseId, Limit: MaxKeyScanLimit + 1})
+	require.Equal(t, ErrMaxKeyScanLimitExceeded, err)
+
+	_, err = db.IteratorNext(context.Background(), &schema.IteratorNextRequest{LeaseId: "unknown"})
+	require.Equal(t, ErrIteratorLeaseNotFound, err)
+
+	page, err := db.IteratorNext(context.Background(), &schema.IteratorNextRequest{LeaseId: lease.LeaseId, Limit: 4})
+	require.NoError(t, err)
+	require.Len(t, page.Entries, 4)
+	require.Equal(t, []byte("key0"), page.Entries[0].Key)
+	require.Equal(t, []byte("key3"), page.ResumeToken)
+	require.False(t, page.Done)
+
+	_, err = db.KeepAliveIterator(&schema.IteratorLeaseRequest{LeaseId: lease.LeaseId})
+	require.NoError(t, err)
+
+	page, err = db.IteratorNext(context.Background(), &schema.IteratorNextRequest{LeaseId: lease.LeaseId})
+	require.NoError(t, err)
+	require.Len(t, page.Entries, 7)
+	require.Equal(t, []byte("key4"), page.Entries[0].Key)
+	// references are resolved while the resume token keeps the key of the reference
+	require.Equal(t, []byte("key9"), page.Entries[6].Key)
+	require.Equal(t, []byte{9}, page.Entries[6].Value)
+	require.Equal(t, []byte("key9ref"), page.ResumeToken)
+	require.True(t, page.Done)
+
+	page, err = db.IteratorNext(context.Background(), &schema.IteratorNextRequest{LeaseId: lease.LeaseId})
+	require.NoError(t, err)
+	require.Empty(t, page.Entries)
+	require.True(t, page.Done)
+
+	err = db.CloseIterator(&schema.IteratorLeaseRequest{LeaseId: lease.LeaseId})
+	require.NoError(t, err)
+
+	err = db.CloseIterator(&schema.IteratorLeaseRequest{LeaseId: lease.LeaseId})
+	require.Equal(t, ErrIteratorLeaseNotFound, err)
+
+	// the iteration is resumed right after the token
+	lease, err = db.OpenIterator(context.Background(), &schema.OpenIteratorRequest{Prefix: []byte("key"), ResumeToken: []byte("key8"), Desc: true})
+	require.NoError(t, err)
+
+	page, err = db.IteratorNext(context.Background(), &schema.IteratorNextRequest{LeaseId: lease.LeaseId, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, page.Entries, 1)
+	require.Equal(t, []byte("key7"), page.Entries[0].Key)
+
+	err = db.CloseIterator(&schema.IteratorLeaseRequest{LeaseId: lease.LeaseId})
+	require.NoError(t, err)
+}
+
+func TestIteratorLeaseExpiration(t *testing.T) {
+	db, closer := makeIteratorLeaseDb(t)
+	defer closer()
+
+	db.GetOptions().WithIteratorLeaseTimeout(10 * time.Millisecond)
+
+	_, err := db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("key1"), Value: []byte("value1")}}})
+	require.NoError(t, err)
+
+	lease1, err := db.OpenIterator(context.Background(), &schema.OpenIteratorRequest{})
+	require.NoError(t, err)
+
+	lease2, err := db.OpenIterator(context.Background(), &schema.OpenIteratorRequest{})
+	require.NoError(t, err)
+
+	time.Sleep(20 * time.Millisecond)
+
+	_, err = db.IteratorNext(context.Background(), &schema.IteratorNextRequest{LeaseId: lease1.LeaseId})
+	require.Equal(t, ErrIteratorLeaseExpired, err)
+
+	_, err = db.IteratorNext(context.Background(), &schema.IteratorNextRequest{LeaseId: lease1.LeaseId})
+	require.Equal(t, ErrIteratorLeaseNotFound, err)
+
+	_, err = db.KeepAliveIterator(&schema.IteratorLeaseRequest{LeaseId: lease2.LeaseId})
+	require.Equal(t, ErrIteratorLeaseExpired, err)
+
+	db.GetOptions().WithIteratorLeaseTimeout(time.Minute)
+
+	lease3, err := db.OpenIterator(context.Background(), &schema.OpenIteratorRequest{})
+	require.NoError(t, err)
+
+	// leased snapshots are released so the index can be compacted
+	err = db.CompactIndex()
+	require.NoError(t, err)
+
+	_, err = db.IteratorNext(context.Background(), &schema.IteratorNextRequest{LeaseId: lease3.LeaseId})
+	require.Equal(t, ErrIteratorLeaseInvalidated, err)
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"regexp"
+	"strings"
+)
+
+// compileKeyPattern compiles the pattern keys are matched against when scanning, the whole key has to match it.
+// Unless regex is set the pattern is a glob: '*' matches any sequence, '?' any single character, '[...]' and
+// '[!...]' a character class and '\' escapes the following character
+func compileKeyPattern(pattern string, regex bool) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+
+	if !regex {
+		var err error
+
+		pattern, err = globToRegexp(pattern)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return nil, ErrIllegalArguments
+	}
+
+	return re, nil
+}
+
+func globToRegexp(glob string) (string, error) {
+	var sb strings.Builder
+
+	// keys are arbitrary bytes, so '.' must match newlines as well
+	sb.WriteString("(?s)")
+
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		case '\\':
+			if i+1 == len(glob) {
+				return "", ErrIllegalArguments
+			}
+			i++
+			sb.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				return "", ErrIllegalArguments
+			}
+
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+
+			sb.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		default:
+			sb.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+
+	return sb.String(), nil
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompileKeyPattern(t *testing.T) {
+	re, err := compileKeyPattern("", false)
+	require.NoError(t, err)
+	require.Nil(t, re)
+
+	for _, invalid := range []string{`user[`, `user\`} {
+		_, err = compileKeyPattern(invalid, false)
+		require.Equal(t, ErrIllegalArguments, err)
+	}
+
+	_, err = compileKeyPattern(`user(`, true)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	cases := []struct {
+		pattern string
+		regex   bool
+		key     string
+		matches bool
+	}{
+		{"user:*", false, "user:1", true},
+		{"user:*", false, "user:", true},
+		{"user:*", false, "users:1", false},
+		{"user:*:profile", false, "user:1:profile", true},
+		{"user:*:profile", false, "user:1:profile:old", false},
+		{"user:?", false, "user:1", true},
+		{"user:?", false, "user:12", false},
+		{"user:[0-9]", false, "user:7", true},
+		{"user:[!0-9]", false, "user:7", false},
+		{"user:[!0-9]", false, "user:x", true},
+		{"a.b", false, "axb", false},
+		{"a.b", false, "a.b", true},
+		{`a\*`, false, "a*", true},
+		{`a\*`, false, "ab", false},
+		{"*", false, "line\nbreak", true},
+		{`user:\d+`, true, "user:42", true},
+		{`user:\d+`, true, "user:42x", false},
+	}
+
+	for _, c := range cases {
+		re, err := compileKeyPattern(c.pattern, c.regex)
+		require.NoError(t, err)
+		require.Equal(t, c.matches, re.MatchString(c.key), "pattern %s, key %s", c.pattern, c.key)
+	}
+}
+
+func TestStoreScanKeyPattern(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	for _, k := range []string{"user:1:profile", "user:1:settings", "user:2:profile", "order:1"} {
+		_, err := db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte(k), Value: []byte(k)}}})
+		require.NoError(t, err)
+	}
+
+	list, err := db.Scan(context.Background(), &schema.ScanRequest{KeyPattern: "user:*:profile"})
+	require.NoError(t, err)
+	require.Len(t, list.Entries, 2)
+	require.Equal(t, []byte("user:1:profile"), list.Entries[0].Key)
+	require.Equal(t, []byte("user:1:profile"), list.Entries[0].Value)
+	require.Equal(t, []byte("user:2:profile"), list.Entries[1].Key)
+
+	list, err = db.Scan(context.Background(), &schema.ScanRequest{Prefix: []byte("user:"), KeyPattern: `user:\d:s.*`, RegexPattern: true})
+	require.NoError(t, err)
+	require.Len(t, list.Entries, 1)
+	require.Equal(t, []byte("user:1:settings"), list.Entries[0].Key)
+
+	list, err = db.Scan(context.Background(), &schema.ScanRequest{KeyPattern: "*:profile", Limit: 1, Desc: true})
+	require.NoError(t, err)
+	require.Len(t, list.Entries, 1)
+	require.Equal(t, []byte("user:2:profile"), list.Entries[0].Key)
+
+	_, err = db.Scan(context.Background(), &schema.ScanRequest{KeyPattern: "user:[1"})
+	require.Equal(t, ErrIllegalArguments, err)
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"bytes"
+	"regexp"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+)
+
+// keyRules restricts the keys which can be written into the database, so buggy clients can't pollute the keyspace
+type keyRules struct {
+	rules *schema.KeyRules
+
+	pattern   *regexp.Regexp
+	separator []byte
+}
+
+func newKeyRules(rules *schema.KeyRules) (*keyRules, error) {
+	if rules == nil || (len(rules.AllowedPrefixes) == 0 && rules.Pattern == "" && rules.MaxDepth == 0) {
+		return nil, nil
+	}
+
+	kr := &keyRules{
+		rules:     rules,
+		separator: []byte{DefaultPrefixStatsSeparator},
+	}
+
+	if rules.Separator != "" {
+		if len(rules.Separator) != 1 {
+			return nil, ErrIllegalArguments
+		}
+		kr.separator = []byte(rules.Separator)
+	}
+
+	if rules.Pattern != "" {
+		pattern, err := regexp.Compile("^(?:" + rules.Pattern + ")$")
+		if err != nil {
+			return nil, ErrIllegalArguments
+		}
+		kr.pattern = pattern
+	}
+
+	return kr, nil
+}
+
+func (kr *keyRules) validate(key []byte) error {
+	if kr == nil {
+		return nil
+	}
+
+	if len(kr.rules.AllowedPrefixes) > 0 {
+		allowed := false
+
+		for _, prefix := range kr.rules.AllowedPrefixes {
+			if bytes.HasPrefix(key, prefix) {
+				allowed = true
+				break
+			}
+		}
+
+		if !allowed {
+			return ErrKeyRejected
+		}
+	}
+
+	if kr.rules.MaxDepth > 0 && bytes.Count(key, kr.separator) >= int(kr.rules.MaxDepth) {
+		return ErrKeyRejected
+	}
+
+	if kr.pattern != nil && !kr.pattern.Match(key) {
+		return ErrKeyRejected
+	}
+
+	return nil
+}
+
+// SetKeyRules replaces the rules keys must satisfy in order to be written, empty rules accept any key.
+// Rules are enforced on the keys of entries and references, previously written keys are not affected
+func (d *db) SetKeyRules(rules *schema.KeyRules) error {
+	kr, err := newKeyRules(rules)
+	if err != nil {
+		return err
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.keyRules = kr
+
+	return nil
+}
+
+// GetKeyRules returns the rules keys must satisfy in order to be written
+func (d *db) GetKeyRules() *schema.KeyRules {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	if d.keyRules == nil {
+		return &schema.KeyRules{}
+	}
+
+	return d.keyRules.rules
+}
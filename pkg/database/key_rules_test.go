@@ -0,0 +1,94 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"testing"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyRules(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	require.Equal(t, &schema.KeyRules{}, db.GetKeyRules())
+
+	err := db.SetKeyRules(&schema.KeyRules{Pattern: "["})
+	require.Equal(t, ErrIllegalArguments, err)
+
+	err = db.SetKeyRules(&schema.KeyRules{MaxDepth: 2, Separator: "::"})
+	require.Equal(t, ErrIllegalArguments, err)
+
+	rules := &schema.KeyRules{
+		AllowedPrefixes: [][]byte{[]byte("orders:"), []byte("customers:")},
+		Pattern:         "[a-z0-9:]+",
+		MaxDepth:        3,
+	}
+
+	err = db.SetKeyRules(rules)
+	require.NoError(t, err)
+	require.Equal(t, rules, db.GetKeyRules())
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("orders:1"), Value: []byte("value")}}})
+	require.NoError(t, err)
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("customers:1:orders"), Value: []byte("value")}}})
+	require.NoError(t, err)
+
+	for _, key := range []string{"invoices:1", "orders:1:items:2", "orders:ID"} {
+		_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte(key), Value: []byte("value")}}})
+		require.Equal(t, ErrKeyRejected, err, key)
+	}
+
+	_, err = db.SetReference(&schema.ReferenceRequest{Key: []byte("ref"), ReferencedKey: []byte("orders:1")})
+	require.Equal(t, ErrKeyRejected, err)
+
+	_, err = db.SetReference(&schema.ReferenceRequest{Key: []byte("orders:ref"), ReferencedKey: []byte("orders:1")})
+	require.NoError(t, err)
+
+	_, err = db.ExecAll(&schema.ExecAllRequest{Operations: []*schema.Op{
+		{Operation: &schema.Op_Kv{Kv: &schema.KeyValue{Key: []byte("orders:2"), Value: []byte("value")}}},
+		{Operation: &schema.Op_Kv{Kv: &schema.KeyValue{Key: []byte("invoices:2"), Value: []byte("value")}}},
+	}})
+	require.Equal(t, ErrKeyRejected, err)
+
+	_, err = db.ExecAll(&schema.ExecAllRequest{Operations: []*schema.Op{
+		{Operation: &schema.Op_Kv{Kv: &schema.KeyValue{Key: []byte("orders:2"), Value: []byte("value")}}},
+		{Operation: &schema.Op_Ref{Ref: &schema.ReferenceRequest{Key: []byte("ref"), ReferencedKey: []byte("orders:2")}}},
+	}})
+	require.Equal(t, ErrKeyRejected, err)
+
+	_, err = db.Get(&schema.KeyRequest{Key: []byte("orders:2")})
+	require.Error(t, err)
+
+	err = db.SetKeyRules(&schema.KeyRules{Separator: "/", MaxDepth: 1})
+	require.NoError(t, err)
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("invoices:1:2"), Value: []byte("value")}}})
+	require.NoError(t, err)
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("invoices/1"), Value: []byte("value")}}})
+	require.Equal(t, ErrKeyRejected, err)
+
+	err = db.SetKeyRules(&schema.KeyRules{})
+	require.NoError(t, err)
+	require.Equal(t, &schema.KeyRules{}, db.GetKeyRules())
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("invoices/1"), Value: []byte("value")}}})
+	require.NoError(t, err)
+}
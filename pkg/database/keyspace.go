@@ -0,0 +1,144 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"bytes"
+	"context"
+	"sort"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+)
+
+// MaxKeyspaceDepth bounds the number of key segments used to group the keys in a keyspace histogram
+const MaxKeyspaceDepth = 8
+
+// keyspaceValueSizeBounds are the inclusive upper bounds of the value size buckets of a keyspace histogram
+var keyspaceValueSizeBounds = []uint64{64, 256, 1 << 10, 4 << 10, 16 << 10, 64 << 10, 256 << 10, 1 << 20}
+
+// KeyspaceHistogram counts the keys, and the bytes taken by them and their current values, grouped by their first
+// depth segments. Values are not read, sizes are taken from the index. Prefixes beyond MaxTrackedPrefixes are
+// aggregated under an empty prefix
+func (d *db) KeyspaceHistogram(ctx context.Context, req *schema.KeyspaceHistogramRequest) (*schema.KeyspaceHistogramResult, error) {
+	if req == nil || req.Depth > MaxKeyspaceDepth || len(req.Separator) > 1 {
+		return nil, ErrIllegalArguments
+	}
+
+	depth := int(req.Depth)
+	if depth == 0 {
+		depth = 1
+	}
+
+	separator := DefaultPrefixStatsSeparator
+	if len(req.Separator) == 1 {
+		separator = req.Separator[0]
+	}
+
+	sampleRate := uint64(req.SampleRate)
+	if sampleRate == 0 {
+		sampleRate = 1
+	}
+
+	lastTxID, _ := d.st.Alh()
+	err := d.st.WaitForIndexingUpto(lastTxID, ctx.Done())
+	if err != nil {
+		return nil, err
+	}
+
+	snap, err := d.st.SnapshotSince(lastTxID)
+	if err != nil {
+		return nil, err
+	}
+	defer snap.Close()
+
+	r, err := snap.NewKeyReader(&store.KeyReaderSpec{Prefix: []byte{SetKeyPrefix}})
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	prefixes := make(map[string]*schema.KeyspacePrefix)
+
+	for i := uint64(0); ; i++ {
+		if ctx.Err() != nil {
+			return nil, ErrOperationCancelled
+		}
+
+		key, valLen, _, err := r.ReadKey()
+		if err == store.ErrNoMoreEntries {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if i%sampleRate != 0 {
+			continue
+		}
+
+		// the encoded key and value are prefixed with their kind
+		key = key[1:]
+		valSize := uint64(valLen) - 1
+
+		prefix := keyPrefix(key, depth, separator)
+
+		p, ok := prefixes[string(prefix)]
+		if !ok {
+			if len(prefixes) >= MaxTrackedPrefixes {
+				prefix = nil
+				p, ok = prefixes[""]
+			}
+			if !ok {
+				p = &schema.KeyspacePrefix{
+					Prefix:     append([]byte{}, prefix...),
+					ValueSizes: make([]uint64, len(keyspaceValueSizeBounds)+1),
+				}
+				prefixes[string(prefix)] = p
+			}
+		}
+
+		p.Keys += sampleRate
+		p.KeyBytes += uint64(len(key)) * sampleRate
+		p.ValueBytes += valSize * sampleRate
+		p.ValueSizes[sort.Search(len(keyspaceValueSizeBounds), func(b int) bool {
+			return valSize <= keyspaceValueSizeBounds[b]
+		})] += sampleRate
+	}
+
+	list := make([]*schema.KeyspacePrefix, 0, len(prefixes))
+	for _, p := range prefixes {
+		list = append(list, p)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		si, sj := list[i].KeyBytes+list[i].ValueBytes, list[j].KeyBytes+list[j].ValueBytes
+		if si == sj {
+			return bytes.Compare(list[i].Prefix, list[j].Prefix) < 0
+		}
+		return si > sj
+	})
+
+	if req.Limit > 0 && len(list) > int(req.Limit) {
+		list = list[:req.Limit]
+	}
+
+	return &schema.KeyspaceHistogramResult{
+		TxId:            snap.Ts(),
+		ValueSizeBounds: keyspaceValueSizeBounds,
+		Prefixes:        list,
+	}, nil
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyspaceHistogram(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	_, err := db.KeyspaceHistogram(context.Background(), nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = db.KeyspaceHistogram(context.Background(), &schema.KeyspaceHistogramRequest{Depth: MaxKeyspaceDepth + 1})
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = db.KeyspaceHistogram(context.Background(), &schema.KeyspaceHistogramRequest{Separator: "::"})
+	require.Equal(t, ErrIllegalArguments, err)
+
+	for i := 0; i < 10; i++ {
+		_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{
+			{Key: []byte(fmt.Sprintf("tenant1:docs:%d", i)), Value: make([]byte, 1000)},
+			{Key: []byte(fmt.Sprintf("tenant2:logs:%d", i)), Value: make([]byte, 10)},
+		}})
+		require.NoError(t, err)
+	}
+
+	_, err = db.ZAdd(&schema.ZAddRequest{Set: []byte("tenant3"), Key: []byte("tenant1:docs:0"), Score: 1})
+	require.NoError(t, err)
+
+	// values are overwritten, only their current size is accounted
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("tenant2:logs:0"), Value: make([]byte, 100)}}})
+	require.NoError(t, err)
+
+	hist, err := db.KeyspaceHistogram(context.Background(), &schema.KeyspaceHistogramRequest{})
+	require.NoError(t, err)
+	require.Len(t, hist.ValueSizeBounds, len(hist.Prefixes[0].ValueSizes)-1)
+	require.Len(t, hist.Prefixes, 2)
+
+	require.Equal(t, []byte("tenant1:"), hist.Prefixes[0].Prefix)
+	require.Equal(t, uint64(10), hist.Prefixes[0].Keys)
+	require.Equal(t, uint64(10*len("tenant1:docs:0")), hist.Prefixes[0].KeyBytes)
+	require.Equal(t, uint64(10*1000), hist.Prefixes[0].ValueBytes)
+	require.Equal(t, uint64(10), hist.Prefixes[0].ValueSizes[2])
+
+	require.Equal(t, []byte("tenant2:"), hist.Prefixes[1].Prefix)
+	require.Equal(t, uint64(10), hist.Prefixes[1].Keys)
+	require.Equal(t, uint64(9*10+100), hist.Prefixes[1].ValueBytes)
+	require.Equal(t, uint64(9), hist.Prefixes[1].ValueSizes[0])
+	require.Equal(t, uint64(1), hist.Prefixes[1].ValueSizes[1])
+
+	hist, err = db.KeyspaceHistogram(context.Background(), &schema.KeyspaceHistogramRequest{Depth: 2, Separator: ":", Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, hist.Prefixes, 1)
+	require.Equal(t, []byte("tenant1:docs:"), hist.Prefixes[0].Prefix)
+
+	// sampled counters are extrapolated
+	hist, err = db.KeyspaceHistogram(context.Background(), &schema.KeyspaceHistogramRequest{SampleRate: 2})
+	require.NoError(t, err)
+
+	var keys uint64
+	for _, p := range hist.Prefixes {
+		require.Zero(t, p.Keys%2)
+		keys += p.Keys
+	}
+	require.Equal(t, uint64(20), keys)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = db.KeyspaceHistogram(ctx, &schema.KeyspaceHistogramRequest{})
+	require.Equal(t, ErrOperationCancelled, err)
+}
@@ -0,0 +1,247 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+)
+
+// Legal holds are written into the database itself, each placement or lift being a new version of the key made of
+// LegalHoldKeyPrefix followed by the held prefix. Thus the history of holds is part of the verifiable history of the
+// database, while active holds are kept in memory to be checked by operations deleting or expiring entries
+
+// EncodeLegalHold returns the entry recording a legal hold event
+func EncodeLegalHold(hold *schema.LegalHold) *store.KV {
+	v := make([]byte, 1+8+4+len(hold.Reason)+4+len(hold.By))
+
+	if hold.Active {
+		v[0] = 1
+	}
+
+	binary.BigEndian.PutUint64(v[1:], uint64(hold.Time))
+
+	off := 1 + 8
+
+	binary.BigEndian.PutUint32(v[off:], uint32(len(hold.Reason)))
+	copy(v[off+4:], hold.Reason)
+
+	off += 4 + len(hold.Reason)
+
+	binary.BigEndian.PutUint32(v[off:], uint32(len(hold.By)))
+	copy(v[off+4:], hold.By)
+
+	return &store.KV{Key: WrapWithPrefix(hold.Prefix, LegalHoldKeyPrefix), Value: v}
+}
+
+// DecodeLegalHold ...
+func DecodeLegalHold(prefix []byte, v []byte) (*schema.LegalHold, error) {
+	if len(v) < 1+8+4 {
+		return nil, store.ErrCorruptedData
+	}
+
+	off := 1 + 8
+
+	reasonLen := int(binary.BigEndian.Uint32(v[off:]))
+	if len(v) < off+4+reasonLen+4 {
+		return nil, store.ErrCorruptedData
+	}
+
+	reason := string(v[off+4 : off+4+reasonLen])
+
+	off += 4 + reasonLen
+
+	byLen := int(binary.BigEndian.Uint32(v[off:]))
+	if len(v) != off+4+byLen {
+		return nil, store.ErrCorruptedData
+	}
+
+	return &schema.LegalHold{
+		Prefix: prefix,
+		Active: v[0] == 1,
+		Time:   int64(binary.BigEndian.Uint64(v[1:])),
+		Reason: reason,
+		By:     string(v[off+4:]),
+	}, nil
+}
+
+// SetLegalHold records the placement of a hold on a prefix, or its lift when hold.Active is false
+func (d *db) SetLegalHold(hold *schema.LegalHold) (*schema.LegalHold, error) {
+	if hold == nil || len(hold.Prefix) == 0 {
+		return nil, ErrIllegalArguments
+	}
+
+	if d.options.replica {
+		return nil, ErrIsReplica
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	_, held := d.legalHolds[string(hold.Prefix)]
+
+	if hold.Active && held {
+		return nil, ErrLegalHoldAlreadyPlaced
+	}
+	if !hold.Active && !held {
+		return nil, ErrLegalHoldNotFound
+	}
+
+	txmd, err := d.st.Commit([]*store.KV{EncodeLegalHold(hold)}, true)
+	if err != nil {
+		return nil, err
+	}
+
+	hold.Tx = txmd.ID
+
+	if hold.Active {
+		d.legalHolds[string(hold.Prefix)] = hold
+	} else {
+		delete(d.legalHolds, string(hold.Prefix))
+	}
+
+	return hold, nil
+}
+
+// ListLegalHolds returns the active legal holds ordered by prefix
+func (d *db) ListLegalHolds() *schema.LegalHolds {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	list := &schema.LegalHolds{}
+
+	for _, hold := range d.legalHolds {
+		list.Holds = append(list.Holds, hold)
+	}
+
+	sort.Slice(list.Holds, func(i, j int) bool {
+		return bytes.Compare(list.Holds[i].Prefix, list.Holds[j].Prefix) < 0
+	})
+
+	return list
+}
+
+// LegalHoldHistory returns every placement and lift of the hold on prefix, oldest first
+func (d *db) LegalHoldHistory(prefix []byte) (*schema.LegalHolds, error) {
+	if len(prefix) == 0 {
+		return nil, ErrIllegalArguments
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	key := WrapWithPrefix(prefix, LegalHoldKeyPrefix)
+
+	list := &schema.LegalHolds{}
+
+	for offset := uint64(0); ; offset += MaxKeyScanLimit {
+		txs, err := d.st.History(key, offset, false, MaxKeyScanLimit)
+		if err == store.ErrOffsetOutOfRange || err == store.ErrKeyNotFound {
+			return list, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tx := range txs {
+			err = d.st.ReadTx(tx, d.tx1)
+			if err != nil {
+				return nil, err
+			}
+
+			v, err := d.st.ReadValue(d.tx1, key)
+			if err != nil {
+				return nil, err
+			}
+
+			hold, err := DecodeLegalHold(prefix, v)
+			if err != nil {
+				return nil, err
+			}
+			hold.Tx = tx
+
+			list.Holds = append(list.Holds, hold)
+		}
+
+		if len(txs) < MaxKeyScanLimit {
+			return list, nil
+		}
+	}
+}
+
+// checkLegalHold must be called while holding the mutex, it returns ErrLegalHold when the key is under an active hold
+func (d *db) checkLegalHold(key []byte) error {
+	for _, hold := range d.legalHolds {
+		if bytes.HasPrefix(key, hold.Prefix) {
+			return ErrLegalHold
+		}
+	}
+	return nil
+}
+
+// loadLegalHolds rebuilds the active legal holds from the latest event recorded for each prefix
+func (d *db) loadLegalHolds() error {
+	lastTxID, _ := d.st.Alh()
+
+	err := d.st.WaitForIndexingUpto(lastTxID, nil)
+	if err != nil {
+		return err
+	}
+
+	snap, err := d.st.SnapshotSince(lastTxID)
+	if err != nil {
+		return err
+	}
+	defer snap.Close()
+
+	r, err := snap.NewKeyReader(&store.KeyReaderSpec{Prefix: []byte{LegalHoldKeyPrefix}})
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	for {
+		key, val, tx, _, err := r.Read()
+		if err == store.ErrNoMoreEntries {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		v, err := val.Resolve()
+		if err != nil {
+			return err
+		}
+
+		prefix := make([]byte, len(key)-1)
+		copy(prefix, TrimPrefix(key))
+
+		hold, err := DecodeLegalHold(prefix, v)
+		if err != nil {
+			return err
+		}
+
+		if hold.Active {
+			hold.Tx = tx
+			d.legalHolds[string(prefix)] = hold
+		}
+	}
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLegalHolds(t *testing.T) {
+	rootPath := "data_" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	defer os.RemoveAll(rootPath)
+
+	options := DefaultOption().WithDbRootPath(rootPath).WithDbName("db")
+
+	db, err := NewDb(options, nil, logger.NewSimpleLogger("immudb ", os.Stderr))
+	require.NoError(t, err)
+
+	_, err = db.SetLegalHold(nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = db.SetLegalHold(&schema.LegalHold{Prefix: []byte("case1:"), Active: false})
+	require.Equal(t, ErrLegalHoldNotFound, err)
+
+	hold, err := db.SetLegalHold(&schema.LegalHold{Prefix: []byte("case1:"), Active: true, Reason: "litigation", By: "admin", Time: 1})
+	require.NoError(t, err)
+	require.Greater(t, hold.Tx, uint64(0))
+
+	_, err = db.SetLegalHold(&schema.LegalHold{Prefix: []byte("case1:"), Active: true})
+	require.Equal(t, ErrLegalHoldAlreadyPlaced, err)
+
+	_, err = db.SetLegalHold(&schema.LegalHold{Prefix: []byte("case2:"), Active: true})
+	require.NoError(t, err)
+
+	_, err = db.SetLegalHold(&schema.LegalHold{Prefix: []byte("case2:"), Active: false})
+	require.NoError(t, err)
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("case1:doc"), Value: []byte("value")}}})
+	require.NoError(t, err)
+
+	_, err = db.ZAdd(&schema.ZAddRequest{Set: []byte("set"), Key: []byte("case1:doc"), Score: 1})
+	require.NoError(t, err)
+
+	_, err = db.ZRem(&schema.ZRemRequest{Set: []byte("set"), Key: []byte("case1:doc")})
+	require.Equal(t, ErrLegalHold, err)
+
+	// hold events are not visible through the key-value interface
+	list, err := db.Scan(context.Background(), &schema.ScanRequest{})
+	require.NoError(t, err)
+	require.Len(t, list.Entries, 1)
+
+	err = db.Close()
+	require.NoError(t, err)
+
+	// active holds are loaded again along with the database
+	db, err = OpenDb(options, nil, logger.NewSimpleLogger("immudb ", os.Stderr))
+	require.NoError(t, err)
+	defer db.Close()
+
+	holds := db.ListLegalHolds()
+	require.Len(t, holds.Holds, 1)
+	require.Equal(t, []byte("case1:"), holds.Holds[0].Prefix)
+	require.Equal(t, "litigation", holds.Holds[0].Reason)
+	require.Equal(t, "admin", holds.Holds[0].By)
+	require.Equal(t, hold.Tx, holds.Holds[0].Tx)
+
+	_, err = db.ZRem(&schema.ZRemRequest{Set: []byte("set"), Key: []byte("case1:doc")})
+	require.Equal(t, ErrLegalHold, err)
+
+	_, err = db.SetLegalHold(&schema.LegalHold{Prefix: []byte("case1:"), Active: false, Reason: "settled"})
+	require.NoError(t, err)
+
+	require.Empty(t, db.ListLegalHolds().Holds)
+
+	_, err = db.ZRem(&schema.ZRemRequest{Set: []byte("set"), Key: []byte("case1:doc")})
+	require.NoError(t, err)
+
+	history, err := db.LegalHoldHistory([]byte("case1:"))
+	require.NoError(t, err)
+	require.Len(t, history.Holds, 2)
+	require.True(t, history.Holds[0].Active)
+	require.False(t, history.Holds[1].Active)
+	require.Equal(t, "settled", history.Holds[1].Reason)
+
+	history, err = db.LegalHoldHistory([]byte("case3:"))
+	require.NoError(t, err)
+	require.Empty(t, history.Holds)
+
+	_, err = db.LegalHoldHistory(nil)
+	require.Equal(t, ErrIllegalArguments, err)
+}
@@ -27,13 +27,30 @@ const (
 	SetKeyPrefix byte = iota
 	SortedSetKeyPrefix
 	SQLPrefix
+	AttributionKeyPrefix
+	IdempotencyKeyPrefix
+	LegalHoldKeyPrefix
+	WORMKeyPrefix
+	PinnedSnapshotKeyPrefix
+	EpochKeyPrefix
+	RewindKeyPrefix
+	BlobChunkKeyPrefix
+	ObjectKeyPrefix
+	SecondaryIndexKeyPrefix
+	ReferenceIndexKeyPrefix
 )
 
 const (
 	PlainValuePrefix = iota
 	ReferenceValuePrefix
+	ExpirableValuePrefix
+	TombstoneValuePrefix
+	BlobValuePrefix
 )
 
+// ZRemovedValue is the value of sorted set entries removed with ZRem
+const ZRemovedValue byte = 1
+
 //WrapWithPrefix ...
 func WrapWithPrefix(b []byte, prefix byte) []byte {
 	wb := make([]byte, 1+len(b))
@@ -57,6 +74,45 @@ func EncodeKV(key []byte, value []byte) *store.KV {
 	}
 }
 
+// EncodeExpirableKV returns the entry of a key which can no longer be read after expiresAt, in unix time seconds
+func EncodeExpirableKV(key []byte, value []byte, expiresAt int64) *store.KV {
+	v := make([]byte, 1+8+len(value))
+	v[0] = ExpirableValuePrefix
+	binary.BigEndian.PutUint64(v[1:], uint64(expiresAt))
+	copy(v[1+8:], value)
+
+	return &store.KV{
+		Key:   WrapWithPrefix(key, SetKeyPrefix),
+		Value: v,
+	}
+}
+
+// EncodeTombstone returns the entry marking a key as deleted
+func EncodeTombstone(key []byte) *store.KV {
+	return &store.KV{
+		Key:   WrapWithPrefix(key, SetKeyPrefix),
+		Value: []byte{TombstoneValuePrefix},
+	}
+}
+
+// EncodeBlobKV returns the entry assigning the key the blob described by the encoded manifest
+func EncodeBlobKV(key []byte, manifest []byte) *store.KV {
+	return &store.KV{
+		Key:   WrapWithPrefix(key, SetKeyPrefix),
+		Value: WrapWithPrefix(manifest, BlobValuePrefix),
+	}
+}
+
+// EncodeBlobChunkKey returns the key of a chunk of a blob, given the id of the blob and the position of the chunk
+func EncodeBlobChunkKey(id uint64, chunk uint64) []byte {
+	k := make([]byte, 1+8+8)
+	k[0] = BlobChunkKeyPrefix
+	binary.BigEndian.PutUint64(k[1:], id)
+	binary.BigEndian.PutUint64(k[1+8:], chunk)
+
+	return k
+}
+
 func EncodeReference(key, referencedKey []byte, atTx uint64) *store.KV {
 	return &store.KV{
 		Key:   WrapWithPrefix(key, SetKeyPrefix),
@@ -81,6 +137,17 @@ func EncodeZAdd(set []byte, score float64, key []byte, atTx uint64) *store.KV {
 	}
 }
 
+// EncodeZRem marks a sorted set entry as removed. Entries added with ZAdd have an empty value,
+// so the removal can be undone by adding the same entry again
+func EncodeZRem(set []byte, score float64, key []byte, atTx uint64) *store.KV {
+	return &store.KV{
+		Key:   WrapZAddReferenceAt(set, score, key, atTx),
+		Value: []byte{ZRemovedValue},
+	}
+}
+
+// WrapZAddReferenceAt returns the index key of a sorted set entry: the set, the score, the member key encoded
+// with encodeZMember and the tx the entry is bound to
 func WrapZAddReferenceAt(set []byte, score float64, key []byte, atTx uint64) []byte {
 	zKey := make([]byte, 1+setLenLen+len(set)+scoreLen+keyLenLen+len(key)+txIDLen)
 	zi := 0
@@ -93,11 +160,31 @@ func WrapZAddReferenceAt(set []byte, score float64, key []byte, atTx uint64) []b
 	zi += len(set)
 	binary.BigEndian.PutUint64(zKey[zi:], math.Float64bits(score))
 	zi += scoreLen
-	binary.BigEndian.PutUint64(zKey[zi:], uint64(len(key)))
-	zi += keyLenLen
-	copy(zKey[zi:], key)
-	zi += len(key)
+	copy(zKey[zi:], encodeZMember(key))
+	zi += keyLenLen + len(key)
 	binary.BigEndian.PutUint64(zKey[zi:], atTx)
 
 	return zKey
 }
+
+// encodeZMember encodes the key of a sorted set entry prefixed by its length. Entries sharing a score are thus
+// ordered by key length first, then lexicographically
+func encodeZMember(key []byte) []byte {
+	member := make([]byte, keyLenLen+len(key))
+	binary.BigEndian.PutUint64(member, uint64(len(key)))
+	copy(member[keyLenLen:], key)
+
+	return member
+}
+
+// decodeZMember returns the key encoded by encodeZMember
+func decodeZMember(member []byte) ([]byte, error) {
+	if len(member) < keyLenLen || binary.BigEndian.Uint64(member) != uint64(len(member)-keyLenLen) {
+		return nil, store.ErrCorruptedIndex
+	}
+
+	key := make([]byte, len(member)-keyLenLen)
+	copy(key, member[keyLenLen:])
+
+	return key, nil
+}
@@ -0,0 +1,331 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package database
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"sort"
+	"time"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+)
+
+// Objects are written under the ObjectKeyPrefix, apart from the key/value entries, each put being a new version of
+// the object key. The version id of an object is the transaction writing it, so versions are listed from the history
+// of the key. Each version holds its content type and metadata along with the content
+
+// DefaultObjectContentType is the content type of objects put without one
+const DefaultObjectContentType = "application/octet-stream"
+
+// EncodeObject returns the entry recording a version of an object
+func EncodeObject(version *schema.ObjectVersion, content []byte) *store.KV {
+	names := make([]string, 0, len(version.Metadata))
+	size := 8 + 4 + len(version.ContentType) + 4
+
+	for name, value := range version.Metadata {
+		names = append(names, name)
+		size += 4 + len(name) + 4 + len(value)
+	}
+
+	sort.Strings(names)
+
+	v := make([]byte, size+len(content))
+	binary.BigEndian.PutUint64(v, uint64(version.LastModified))
+
+	off := 8
+	off += putObjectField(v[off:], version.ContentType)
+
+	binary.BigEndian.PutUint32(v[off:], uint32(len(names)))
+	off += 4
+
+	for _, name := range names {
+		off += putObjectField(v[off:], name)
+		off += putObjectField(v[off:], version.Metadata[name])
+	}
+
+	copy(v[off:], content)
+
+	return &store.KV{Key: WrapWithPrefix(version.Key, ObjectKeyPrefix), Value: v}
+}
+
+func putObjectField(b []byte, field string) int {
+	binary.BigEndian.PutUint32(b, uint32(len(field)))
+	copy(b[4:], field)
+	return 4 + len(field)
+}
+
+// DecodeObject decodes the version of key written by the given transaction
+func DecodeObject(key []byte, v []byte, tx uint64) (*schema.Object, error) {
+	if len(v) < 8 {
+		return nil, store.ErrCorruptedData
+	}
+
+	version := &schema.ObjectVersion{
+		Key:          key,
+		VersionId:    tx,
+		LastModified: int64(binary.BigEndian.Uint64(v)),
+	}
+
+	off := 8
+
+	field := func() (string, error) {
+		if len(v) < off+4 {
+			return "", store.ErrCorruptedData
+		}
+
+		l := int(binary.BigEndian.Uint32(v[off:]))
+		if len(v) < off+4+l {
+			return "", store.ErrCorruptedData
+		}
+
+		f := string(v[off+4 : off+4+l])
+		off += 4 + l
+
+		return f, nil
+	}
+
+	var err error
+
+	version.ContentType, err = field()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(v) < off+4 {
+		return nil, store.ErrCorruptedData
+	}
+
+	n := int(binary.BigEndian.Uint32(v[off:]))
+	off += 4
+
+	if n > 0 {
+		version.Metadata = make(map[string]string, n)
+	}
+
+	for i := 0; i < n; i++ {
+		name, err := field()
+		if err != nil {
+			return nil, err
+		}
+
+		version.Metadata[name], err = field()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	content := v[off:]
+	etag := sha256.Sum256(content)
+
+	version.Size = uint64(len(content))
+	version.Etag = etag[:]
+
+	return &schema.Object{Version: version, Content: content}, nil
+}
+
+// PutObject writes a new version of an object, returning its description
+func (d *db) PutObject(req *schema.PutObjectRequest) (*schema.ObjectVersion, error) {
+	if req == nil || len(req.Key) == 0 {
+		return nil, ErrIllegalArguments
+	}
+
+	if d.options.replica {
+		return nil, ErrIsReplica
+	}
+
+	version := &schema.ObjectVersion{
+		Key:          req.Key,
+		ContentType:  req.ContentType,
+		Metadata:     req.Metadata,
+		LastModified: time.Now().Unix(),
+		IsLatest:     true,
+	}
+
+	if version.ContentType == "" {
+		version.ContentType = DefaultObjectContentType
+	}
+
+	txmd, err := d.st.Commit([]*store.KV{EncodeObject(version, req.Content)}, true)
+	if err != nil {
+		return nil, err
+	}
+
+	etag := sha256.Sum256(req.Content)
+
+	version.VersionId = txmd.ID
+	version.Size = uint64(len(req.Content))
+	version.Etag = etag[:]
+
+	return version, nil
+}
+
+// GetObject returns the requested version of an object, the latest one unless a version id is provided
+func (d *db) GetObject(req *schema.GetObjectRequest) (*schema.Object, error) {
+	if req == nil || len(req.Key) == 0 {
+		return nil, ErrIllegalArguments
+	}
+
+	key := WrapWithPrefix(req.Key, ObjectKeyPrefix)
+
+	v, latest, _, err := d.st.Get(key)
+	if err == store.ErrKeyNotFound {
+		return nil, ErrObjectNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if req.VersionId == 0 || req.VersionId == latest {
+		obj, err := DecodeObject(req.Key, v, latest)
+		if err != nil {
+			return nil, err
+		}
+		obj.Version.IsLatest = true
+
+		return obj, nil
+	}
+
+	if req.VersionId > latest {
+		return nil, ErrObjectNotFound
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	v, err = d.readObjectVersion(key, req.VersionId)
+	if err != nil {
+		return nil, err
+	}
+
+	return DecodeObject(req.Key, v, req.VersionId)
+}
+
+// readObjectVersion must be called while holding the mutex
+func (d *db) readObjectVersion(key []byte, tx uint64) ([]byte, error) {
+	err := d.st.ReadTx(tx, d.tx1)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := d.st.ReadValue(d.tx1, key)
+	if err == store.ErrKeyNotFound {
+		return nil, ErrObjectNotFound
+	}
+
+	return v, err
+}
+
+// ListObjectVersions lists the versions of the objects whose key starts with req.Prefix, ordered by key and newest
+// version first. When the limit is reached, the listing is continued by setting the markers of the response
+func (d *db) ListObjectVersions(req *schema.ListObjectVersionsRequest) (*schema.ObjectVersions, error) {
+	if req == nil || req.Limit > MaxKeyScanLimit || (req.VersionIdMarker > 0 && len(req.KeyMarker) == 0) {
+		return nil, ErrIllegalArguments
+	}
+
+	limit := int(req.Limit)
+	if limit == 0 {
+		limit = MaxKeyScanLimit
+	}
+
+	lastTxID, _ := d.st.Alh()
+
+	snap, err := d.st.SnapshotSince(lastTxID)
+	if err != nil {
+		return nil, err
+	}
+	defer snap.Close()
+
+	spec := &store.KeyReaderSpec{Prefix: WrapWithPrefix(req.Prefix, ObjectKeyPrefix), InclusiveSeek: true}
+	if len(req.KeyMarker) > 0 {
+		spec.SeekKey = WrapWithPrefix(req.KeyMarker, ObjectKeyPrefix)
+	}
+
+	r, err := snap.NewKeyReader(spec)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	list := &schema.ObjectVersions{}
+
+	for {
+		key, _, latest, _, err := r.Read()
+		if err == store.ErrNoMoreEntries {
+			return list, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		objKey := make([]byte, len(key)-1)
+		copy(objKey, TrimPrefix(key))
+
+		atMarker := bytes.Equal(objKey, req.KeyMarker)
+		if atMarker && req.VersionIdMarker == 0 {
+			continue
+		}
+
+		for offset := uint64(0); ; offset += MaxKeyScanLimit {
+			txs, err := snap.History(key, offset, true, MaxKeyScanLimit)
+			if err == store.ErrOffsetOutOfRange {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			for _, tx := range txs {
+				if atMarker && tx >= req.VersionIdMarker {
+					continue
+				}
+
+				if len(list.Versions) == limit {
+					last := list.Versions[limit-1]
+
+					list.IsTruncated = true
+					list.NextKeyMarker = last.Key
+					list.NextVersionIdMarker = last.VersionId
+
+					return list, nil
+				}
+
+				v, err := d.readObjectVersion(key, tx)
+				if err != nil {
+					return nil, err
+				}
+
+				obj, err := DecodeObject(objKey, v, tx)
+				if err != nil {
+					return nil, err
+				}
+				obj.Version.IsLatest = tx == latest
+
+				list.Versions = append(list.Versions, obj.Version)
+			}
+
+			if len(txs) < MaxKeyScanLimit {
+				break
+			}
+		}
+	}
+}
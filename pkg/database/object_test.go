@@ -0,0 +1,134 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package database
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"testing"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObjects(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	v1, err := db.PutObject(&schema.PutObjectRequest{
+		Key:      []byte("photos/cat.png"),
+		Content:  []byte("first"),
+		Metadata: map[string]string{"owner": "alice", "camera": "x100"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, DefaultObjectContentType, v1.ContentType)
+	require.Equal(t, uint64(5), v1.Size)
+	require.True(t, v1.IsLatest)
+
+	v2, err := db.PutObject(&schema.PutObjectRequest{
+		Key:         []byte("photos/cat.png"),
+		Content:     []byte("second"),
+		ContentType: "image/png",
+	})
+	require.NoError(t, err)
+	require.Greater(t, v2.VersionId, v1.VersionId)
+
+	// objects are not key/value entries
+	_, err = db.Get(&schema.KeyRequest{Key: []byte("photos/cat.png")})
+	require.Error(t, err)
+
+	obj, err := db.GetObject(&schema.GetObjectRequest{Key: []byte("photos/cat.png")})
+	require.NoError(t, err)
+	require.Equal(t, []byte("second"), obj.Content)
+	require.Equal(t, v2.VersionId, obj.Version.VersionId)
+	require.Equal(t, "image/png", obj.Version.ContentType)
+	require.Empty(t, obj.Version.Metadata)
+	require.True(t, obj.Version.IsLatest)
+
+	obj, err = db.GetObject(&schema.GetObjectRequest{Key: []byte("photos/cat.png"), VersionId: v1.VersionId})
+	require.NoError(t, err)
+	require.Equal(t, []byte("first"), obj.Content)
+	require.Equal(t, map[string]string{"owner": "alice", "camera": "x100"}, obj.Version.Metadata)
+	require.Equal(t, v1.LastModified, obj.Version.LastModified)
+	require.False(t, obj.Version.IsLatest)
+
+	etag := sha256.Sum256([]byte("first"))
+	require.Equal(t, etag[:], obj.Version.Etag)
+
+	_, err = db.GetObject(&schema.GetObjectRequest{Key: []byte("photos/cat.png"), VersionId: v1.VersionId + 1000})
+	require.Equal(t, ErrObjectNotFound, err)
+
+	_, err = db.GetObject(&schema.GetObjectRequest{Key: []byte("photos/dog.png")})
+	require.Equal(t, ErrObjectNotFound, err)
+
+	_, err = db.GetObject(nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = db.PutObject(&schema.PutObjectRequest{})
+	require.Equal(t, ErrIllegalArguments, err)
+
+	for i := 0; i < 3; i++ {
+		_, err = db.PutObject(&schema.PutObjectRequest{Key: []byte("photos/dog.png"), Content: []byte(fmt.Sprintf("dog%d", i))})
+		require.NoError(t, err)
+	}
+
+	_, err = db.PutObject(&schema.PutObjectRequest{Key: []byte("docs/a.txt"), Content: []byte("a")})
+	require.NoError(t, err)
+
+	list, err := db.ListObjectVersions(&schema.ListObjectVersionsRequest{Prefix: []byte("photos/")})
+	require.NoError(t, err)
+	require.False(t, list.IsTruncated)
+	require.Len(t, list.Versions, 5)
+	require.Equal(t, []byte("photos/cat.png"), list.Versions[0].Key)
+	require.Equal(t, v2.VersionId, list.Versions[0].VersionId)
+	require.True(t, list.Versions[0].IsLatest)
+	require.Equal(t, v1.VersionId, list.Versions[1].VersionId)
+	require.False(t, list.Versions[1].IsLatest)
+	require.Equal(t, []byte("photos/dog.png"), list.Versions[2].Key)
+	require.True(t, list.Versions[2].IsLatest)
+
+	// the listing is resumed from the markers of a truncated response
+	var listed []*schema.ObjectVersion
+
+	req := &schema.ListObjectVersionsRequest{Prefix: []byte("photos/"), Limit: 2}
+
+	for {
+		page, err := db.ListObjectVersions(req)
+		require.NoError(t, err)
+
+		listed = append(listed, page.Versions...)
+
+		if !page.IsTruncated {
+			break
+		}
+
+		req.KeyMarker = page.NextKeyMarker
+		req.VersionIdMarker = page.NextVersionIdMarker
+	}
+	require.Equal(t, list.Versions, listed)
+
+	list, err = db.ListObjectVersions(&schema.ListObjectVersionsRequest{KeyMarker: []byte("photos/cat.png")})
+	require.NoError(t, err)
+	require.Len(t, list.Versions, 3)
+	require.Equal(t, []byte("photos/dog.png"), list.Versions[0].Key)
+
+	_, err = db.ListObjectVersions(&schema.ListObjectVersionsRequest{VersionIdMarker: 1})
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = db.ListObjectVersions(&schema.ListObjectVersionsRequest{Limit: MaxKeyScanLimit + 1})
+	require.Equal(t, ErrIllegalArguments, err)
+}
@@ -0,0 +1,227 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"encoding/binary"
+	"sort"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+)
+
+// Pinned snapshots are written into the database itself, each pin or unpin being a new version of the key made of
+// PinnedSnapshotKeyPrefix followed by the name of the snapshot. Reads targeting a snapshot by name are resolved
+// as of the pinned transaction, thus they see the same state regardless of the transactions committed afterwards
+
+// EncodePinnedSnapshot returns the entry recording the pin of a snapshot, or its removal when txID is zero
+func EncodePinnedSnapshot(name string, txID uint64) *store.KV {
+	v := make([]byte, 8)
+	binary.BigEndian.PutUint64(v, txID)
+
+	return &store.KV{Key: WrapWithPrefix([]byte(name), PinnedSnapshotKeyPrefix), Value: v}
+}
+
+// PinSnapshot pins the state of the database as of a transaction under a name
+func (d *db) PinSnapshot(req *schema.PinSnapshotRequest) (*schema.PinnedSnapshot, error) {
+	if req == nil || len(req.Name) == 0 {
+		return nil, ErrIllegalArguments
+	}
+
+	if d.options.replica {
+		return nil, ErrIsReplica
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if _, pinned := d.pinnedSnapshots[req.Name]; pinned {
+		return nil, ErrSnapshotAlreadyPinned
+	}
+
+	lastTxID, _ := d.st.Alh()
+
+	txID := req.TxID
+	if txID == 0 {
+		txID = lastTxID
+	}
+
+	if txID == 0 || txID > lastTxID {
+		return nil, ErrIllegalArguments
+	}
+
+	txmd, err := d.st.Commit([]*store.KV{EncodePinnedSnapshot(req.Name, txID)}, true)
+	if err != nil {
+		return nil, err
+	}
+
+	snap := &schema.PinnedSnapshot{
+		Name: req.Name,
+		TxID: txID,
+		Tx:   txmd.ID,
+	}
+
+	d.pinnedSnapshots[req.Name] = snap
+
+	return snap, nil
+}
+
+// UnpinSnapshot removes the snapshot pinned under the name
+func (d *db) UnpinSnapshot(name string) error {
+	if len(name) == 0 {
+		return ErrIllegalArguments
+	}
+
+	if d.options.replica {
+		return ErrIsReplica
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if _, pinned := d.pinnedSnapshots[name]; !pinned {
+		return ErrSnapshotNotFound
+	}
+
+	_, err := d.st.Commit([]*store.KV{EncodePinnedSnapshot(name, 0)}, true)
+	if err != nil {
+		return err
+	}
+
+	delete(d.pinnedSnapshots, name)
+
+	return nil
+}
+
+// ListPinnedSnapshots returns the pinned snapshots ordered by name
+func (d *db) ListPinnedSnapshots() *schema.PinnedSnapshots {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	list := &schema.PinnedSnapshots{}
+
+	for _, snap := range d.pinnedSnapshots {
+		list.Snapshots = append(list.Snapshots, snap)
+	}
+
+	sort.Slice(list.Snapshots, func(i, j int) bool {
+		return list.Snapshots[i].Name < list.Snapshots[j].Name
+	})
+
+	return list
+}
+
+// pinnedSnapshotTx must be called while holding the mutex, it returns the transaction pinned under the name
+func (d *db) pinnedSnapshotTx(name string) (uint64, error) {
+	snap, pinned := d.pinnedSnapshots[name]
+	if !pinned {
+		return 0, ErrSnapshotNotFound
+	}
+	return snap.TxID, nil
+}
+
+// getAsOf returns the value the key had once the transaction txID was committed
+func (d *db) getAsOf(key []byte, txID uint64) (*schema.Entry, error) {
+	snap, err := d.st.SnapshotSince(txID)
+	if err != nil {
+		return nil, err
+	}
+	defer snap.Close()
+
+	return d.getAsOfIn(snap, key, txID)
+}
+
+// getAsOfIn returns the value the key had once the transaction txID was committed, snap must include txID
+func (d *db) getAsOfIn(snap *store.Snapshot, key []byte, txID uint64) (*schema.Entry, error) {
+	ktx, err := keyTxAsOf(snap, key, txID)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.getAt(key, ktx, 0, d.st, d.tx1)
+}
+
+// keyTxAsOf returns the transaction which assigned the value the key had once the transaction txID was committed
+func keyTxAsOf(snap *store.Snapshot, key []byte, txID uint64) (uint64, error) {
+	r, err := snap.NewKeyReader(&store.KeyReaderSpec{
+		SeekKey:       key,
+		Prefix:        key,
+		InclusiveSeek: true,
+		EndKey:        key,
+		InclusiveEnd:  true,
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+
+	_, _, ktx, err := r.ReadAsBefore(txID + 1)
+	if err == store.ErrNoMoreEntries {
+		return 0, store.ErrKeyNotFound
+	}
+
+	return ktx, err
+}
+
+// loadPinnedSnapshots rebuilds the pinned snapshots from the latest event recorded for each name
+func (d *db) loadPinnedSnapshots() error {
+	lastTxID, _ := d.st.Alh()
+
+	err := d.st.WaitForIndexingUpto(lastTxID, nil)
+	if err != nil {
+		return err
+	}
+
+	snap, err := d.st.SnapshotSince(lastTxID)
+	if err != nil {
+		return err
+	}
+	defer snap.Close()
+
+	r, err := snap.NewKeyReader(&store.KeyReaderSpec{Prefix: []byte{PinnedSnapshotKeyPrefix}})
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	for {
+		key, val, tx, _, err := r.Read()
+		if err == store.ErrNoMoreEntries {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		v, err := val.Resolve()
+		if err != nil {
+			return err
+		}
+
+		if len(v) != 8 {
+			return store.ErrCorruptedData
+		}
+
+		txID := binary.BigEndian.Uint64(v)
+		if txID == 0 {
+			continue
+		}
+
+		name := string(TrimPrefix(key))
+
+		d.pinnedSnapshots[name] = &schema.PinnedSnapshot{Name: name, TxID: txID, Tx: tx}
+	}
+}
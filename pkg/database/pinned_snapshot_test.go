@@ -0,0 +1,135 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPinnedSnapshots(t *testing.T) {
+	rootPath := "data_" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	defer os.RemoveAll(rootPath)
+
+	options := DefaultOption().WithDbRootPath(rootPath).WithDbName("db")
+
+	db, err := NewDb(options, nil, logger.NewSimpleLogger("immudb ", os.Stderr))
+	require.NoError(t, err)
+
+	_, err = db.PinSnapshot(nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = db.PinSnapshot(&schema.PinSnapshotRequest{})
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = db.SQLExec(&schema.SQLExecRequest{Sql: "CREATE TABLE t(id INTEGER, v VARCHAR, PRIMARY KEY id)"})
+	require.NoError(t, err)
+
+	_, err = db.SQLExec(&schema.SQLExecRequest{Sql: "UPSERT INTO t(id, v) VALUES (1, 'a')"})
+	require.NoError(t, err)
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{
+		{Key: []byte("k1"), Value: []byte("v1")},
+		{Key: []byte("k2"), Value: []byte("v2")},
+	}})
+	require.NoError(t, err)
+
+	_, err = db.PinSnapshot(&schema.PinSnapshotRequest{Name: "eom", TxID: 100})
+	require.Equal(t, ErrIllegalArguments, err)
+
+	snap, err := db.PinSnapshot(&schema.PinSnapshotRequest{Name: "eom"})
+	require.NoError(t, err)
+	require.Equal(t, "eom", snap.Name)
+	require.Greater(t, snap.Tx, snap.TxID)
+
+	_, err = db.PinSnapshot(&schema.PinSnapshotRequest{Name: "eom"})
+	require.Equal(t, ErrSnapshotAlreadyPinned, err)
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{
+		{Key: []byte("k1"), Value: []byte("v1.1")},
+		{Key: []byte("k3"), Value: []byte("v3")},
+	}})
+	require.NoError(t, err)
+
+	_, err = db.SQLExec(&schema.SQLExecRequest{Sql: "UPSERT INTO t(id, v) VALUES (1, 'b'), (2, 'c')"})
+	require.NoError(t, err)
+
+	_, err = db.Get(&schema.KeyRequest{Key: []byte("k1"), Snapshot: "missing"})
+	require.Equal(t, ErrSnapshotNotFound, err)
+
+	_, err = db.Get(&schema.KeyRequest{Key: []byte("k1"), Snapshot: "eom", AtTx: 1})
+	require.Equal(t, ErrIllegalArguments, err)
+
+	checkSnapshot := func(db DB) {
+		e, err := db.Get(&schema.KeyRequest{Key: []byte("k1"), Snapshot: "eom"})
+		require.NoError(t, err)
+		require.Equal(t, []byte("v1"), e.Value)
+
+		_, err = db.Get(&schema.KeyRequest{Key: []byte("k3"), Snapshot: "eom"})
+		require.Equal(t, store.ErrKeyNotFound, err)
+
+		list, err := db.Scan(context.Background(), &schema.ScanRequest{Prefix: []byte("k"), Snapshot: "eom"})
+		require.NoError(t, err)
+		require.Len(t, list.Entries, 2)
+		require.Equal(t, []byte("v1"), list.Entries[0].Value)
+		require.Equal(t, []byte("v2"), list.Entries[1].Value)
+
+		res, err := db.SQLQuery(context.Background(), &schema.SQLQueryRequest{Sql: "SELECT id, v FROM t", Snapshot: "eom"})
+		require.NoError(t, err)
+		require.Len(t, res.Rows, 1)
+		require.Equal(t, "a", res.Rows[0].Values[1].GetS())
+
+		res, err = db.SQLQuery(context.Background(), &schema.SQLQueryRequest{Sql: "SELECT id, v FROM t"})
+		require.NoError(t, err)
+		require.Len(t, res.Rows, 2)
+	}
+
+	checkSnapshot(db)
+
+	err = db.Close()
+	require.NoError(t, err)
+
+	// pinned snapshots are loaded again along with the database
+	db, err = OpenDb(options, nil, logger.NewSimpleLogger("immudb ", os.Stderr))
+	require.NoError(t, err)
+	defer db.Close()
+
+	snaps := db.ListPinnedSnapshots()
+	require.Len(t, snaps.Snapshots, 1)
+	require.Equal(t, snap.TxID, snaps.Snapshots[0].TxID)
+	require.Equal(t, snap.Tx, snaps.Snapshots[0].Tx)
+
+	checkSnapshot(db)
+
+	err = db.UnpinSnapshot("eom")
+	require.NoError(t, err)
+
+	err = db.UnpinSnapshot("eom")
+	require.Equal(t, ErrSnapshotNotFound, err)
+
+	require.Empty(t, db.ListPinnedSnapshots().Snapshots)
+
+	_, err = db.Get(&schema.KeyRequest{Key: []byte("k1"), Snapshot: "eom"})
+	require.Equal(t, ErrSnapshotNotFound, err)
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+)
+
+// checkPreconditions returns ErrPreconditionFailed if any of the preconditions doesn't hold. It's meant to be called
+// within the commit, so no other write can get in between
+func (d *db) checkPreconditions(preconditions []*schema.Precondition, index store.KeyIndex) error {
+	for _, p := range preconditions {
+		switch x := p.Precondition.(type) {
+		case *schema.Precondition_KeyMustExist:
+			exists, err := d.keyExists(x.KeyMustExist.Key, index)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				return ErrPreconditionFailed
+			}
+
+		case *schema.Precondition_KeyMustNotExist:
+			exists, err := d.keyExists(x.KeyMustNotExist.Key, index)
+			if err != nil {
+				return err
+			}
+			if exists {
+				return ErrPreconditionFailed
+			}
+
+		case *schema.Precondition_KeyNotModifiedAfterTX:
+			_, tx, _, err := index.Get(EncodeKey(x.KeyNotModifiedAfterTX.Key))
+			if err == store.ErrKeyNotFound {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if tx > x.KeyNotModifiedAfterTX.TxID {
+				return ErrPreconditionFailed
+			}
+
+		default:
+			return schema.ErrInvalidPrecondition
+		}
+	}
+
+	return nil
+}
+
+// keyExists tells whether the key is currently assigned a value, expired values and deleted keys don't count
+func (d *db) keyExists(key []byte, index store.KeyIndex) (bool, error) {
+	val, _, _, err := index.Get(EncodeKey(key))
+	if err == store.ErrKeyNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return live(val)
+}
+
+// live tells whether the indexed value is readable, references are not resolved
+func live(val []byte) (bool, error) {
+	if len(val) > 0 && val[0] == ReferenceValuePrefix {
+		return true, nil
+	}
+
+	if deleted(val) {
+		return false, nil
+	}
+
+	_, expiresAt, err := decodeValue(val)
+	if err != nil {
+		return false, err
+	}
+
+	return !expired(expiresAt), nil
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetWithPreconditions(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	set := func(key, value string, preconditions ...*schema.Precondition) (*schema.TxMetadata, error) {
+		return db.Set(&schema.SetRequest{
+			KVs:           []*schema.KeyValue{{Key: []byte(key), Value: []byte(value)}},
+			Preconditions: preconditions,
+		})
+	}
+
+	_, err := set("key", "value", nil)
+	require.Equal(t, schema.ErrInvalidPrecondition, err)
+
+	_, err = set("key", "value", schema.PreconditionKeyMustExist(nil))
+	require.Equal(t, schema.ErrInvalidPrecondition, err)
+
+	_, err = set("key", "value", schema.PreconditionKeyMustExist([]byte("key")))
+	require.Equal(t, ErrPreconditionFailed, err)
+
+	txmd, err := set("key", "value1", schema.PreconditionKeyMustNotExist([]byte("key")))
+	require.NoError(t, err)
+
+	_, err = set("key", "value2", schema.PreconditionKeyMustNotExist([]byte("key")))
+	require.Equal(t, ErrPreconditionFailed, err)
+
+	_, err = set("key", "value2", schema.PreconditionKeyMustExist([]byte("key")), schema.PreconditionKeyNotModifiedAfterTX([]byte("key"), txmd.Id))
+	require.NoError(t, err)
+
+	_, err = set("key", "value3", schema.PreconditionKeyNotModifiedAfterTX([]byte("key"), txmd.Id))
+	require.Equal(t, ErrPreconditionFailed, err)
+
+	// keys not existing were not modified
+	_, err = set("other", "value", schema.PreconditionKeyNotModifiedAfterTX([]byte("missing"), 0))
+	require.NoError(t, err)
+
+	item, err := db.Get(&schema.KeyRequest{Key: []byte("key")})
+	require.NoError(t, err)
+	require.Equal(t, []byte("value2"), item.Value)
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("expired"), Value: []byte("value"), ExpiresAt: time.Now().Add(-time.Minute).Unix()}}})
+	require.NoError(t, err)
+
+	_, err = set("expired", "value", schema.PreconditionKeyMustNotExist([]byte("expired")))
+	require.NoError(t, err)
+}
+
+func TestExecAllWithPreconditions(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	_, err := db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("referenced"), Value: []byte("value")}}})
+	require.NoError(t, err)
+
+	req := &schema.ExecAllRequest{
+		Operations: []*schema.Op{
+			{Operation: &schema.Op_Kv{Kv: &schema.KeyValue{Key: []byte("key"), Value: []byte("value")}}},
+			{Operation: &schema.Op_Ref{Ref: &schema.ReferenceRequest{Key: []byte("ref"), ReferencedKey: []byte("referenced")}}},
+		},
+		Preconditions: []*schema.Precondition{
+			schema.PreconditionKeyMustExist([]byte("referenced")),
+			schema.PreconditionKeyMustNotExist([]byte("ref")),
+		},
+	}
+
+	_, err = db.ExecAll(&schema.ExecAllRequest{Operations: req.Operations, Preconditions: []*schema.Precondition{{}}})
+	require.Equal(t, schema.ErrInvalidPrecondition, err)
+
+	_, err = db.ExecAll(&schema.ExecAllRequest{Operations: req.Operations, DryRun: true, Preconditions: req.Preconditions})
+	require.NoError(t, err)
+
+	_, err = db.ExecAll(req)
+	require.NoError(t, err)
+
+	_, err = db.ExecAll(req)
+	require.Equal(t, ErrPreconditionFailed, err)
+
+	_, err = db.ExecAll(&schema.ExecAllRequest{Operations: req.Operations, DryRun: true, Preconditions: req.Preconditions})
+	require.Equal(t, ErrPreconditionFailed, err)
+}
+
+func TestSetWithPreconditionsConcurrently(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	succeeded := 0
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			_, err := db.Set(&schema.SetRequest{
+				KVs:           []*schema.KeyValue{{Key: []byte("key"), Value: []byte{byte(i)}}},
+				Preconditions: []*schema.Precondition{schema.PreconditionKeyMustNotExist([]byte("key"))},
+			})
+			if err == ErrPreconditionFailed {
+				return
+			}
+			require.NoError(t, err)
+
+			mutex.Lock()
+			succeeded++
+			mutex.Unlock()
+		}(i)
+	}
+
+	wg.Wait()
+
+	require.Equal(t, 1, succeeded)
+}
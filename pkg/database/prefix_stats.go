@@ -0,0 +1,203 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package database
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+)
+
+// MaxTrackedPrefixes bounds the number of prefixes tracked per database,
+// operations on further prefixes are aggregated under an empty prefix
+const MaxTrackedPrefixes = 10000
+
+const DefaultPrefixStatsSeparator = byte(':')
+
+const (
+	PrefixStatsByReads        = "reads"
+	PrefixStatsByWrites       = "writes"
+	PrefixStatsByReadBytes    = "read_bytes"
+	PrefixStatsByWrittenBytes = "written_bytes"
+)
+
+// prefixStats keeps read and write counters of the keys grouped by their first depth segments
+type prefixStats struct {
+	depth     int
+	separator byte
+
+	stats map[string]*schema.PrefixStats
+	mutex sync.Mutex
+}
+
+func newPrefixStats(depth int, separator byte) *prefixStats {
+	if depth <= 0 {
+		return nil
+	}
+
+	return &prefixStats{
+		depth:     depth,
+		separator: separator,
+		stats:     make(map[string]*schema.PrefixStats),
+	}
+}
+
+func (p *prefixStats) prefixOf(key []byte) []byte {
+	return keyPrefix(key, p.depth, p.separator)
+}
+
+// keyPrefix returns the key up to, and including, its depth-th separator or the whole key if it has fewer segments
+func keyPrefix(key []byte, depth int, separator byte) []byte {
+	off := 0
+
+	for i := 0; i < depth; i++ {
+		j := bytes.IndexByte(key[off:], separator)
+		if j < 0 {
+			return key
+		}
+		off += j + 1
+	}
+
+	return key[:off]
+}
+
+func (p *prefixStats) statsFor(key []byte) *schema.PrefixStats {
+	prefix := p.prefixOf(key)
+
+	s, ok := p.stats[string(prefix)]
+	if ok {
+		return s
+	}
+
+	if len(p.stats) >= MaxTrackedPrefixes {
+		prefix = nil
+
+		s, ok = p.stats[""]
+		if ok {
+			return s
+		}
+	}
+
+	s = &schema.PrefixStats{Prefix: append([]byte{}, prefix...)}
+	p.stats[string(prefix)] = s
+
+	return s
+}
+
+func (p *prefixStats) read(key []byte, size int) {
+	if p == nil {
+		return
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	s := p.statsFor(key)
+	s.Reads++
+	s.ReadBytes += uint64(size)
+}
+
+func (p *prefixStats) write(key []byte, size int) {
+	if p == nil {
+		return
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	s := p.statsFor(key)
+	s.Writes++
+	s.WrittenBytes += uint64(size)
+}
+
+func (p *prefixStats) readEntries(entries []*schema.Entry) {
+	for _, e := range entries {
+		p.read(e.Key, len(e.Key)+len(e.Value))
+	}
+}
+
+// top returns up to limit prefixes sorted in descending order by the given counter
+func (p *prefixStats) top(by string, limit int) ([]*schema.PrefixStats, error) {
+	var counter func(s *schema.PrefixStats) uint64
+
+	switch by {
+	case PrefixStatsByReads:
+		counter = func(s *schema.PrefixStats) uint64 { return s.Reads }
+	case PrefixStatsByWrites:
+		counter = func(s *schema.PrefixStats) uint64 { return s.Writes }
+	case PrefixStatsByReadBytes:
+		counter = func(s *schema.PrefixStats) uint64 { return s.ReadBytes }
+	case PrefixStatsByWrittenBytes:
+		counter = func(s *schema.PrefixStats) uint64 { return s.WrittenBytes }
+	default:
+		return nil, ErrIllegalArguments
+	}
+
+	if p == nil {
+		return nil, ErrPrefixStatsDisabled
+	}
+
+	p.mutex.Lock()
+
+	list := make([]*schema.PrefixStats, 0, len(p.stats))
+	for _, s := range p.stats {
+		list = append(list, &schema.PrefixStats{
+			Prefix:       s.Prefix,
+			Reads:        s.Reads,
+			Writes:       s.Writes,
+			ReadBytes:    s.ReadBytes,
+			WrittenBytes: s.WrittenBytes,
+		})
+	}
+
+	p.mutex.Unlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		ci, cj := counter(list[i]), counter(list[j])
+		if ci == cj {
+			return bytes.Compare(list[i].Prefix, list[j].Prefix) < 0
+		}
+		return ci > cj
+	})
+
+	if limit > 0 && len(list) > limit {
+		list = list[:limit]
+	}
+
+	return list, nil
+}
+
+// TopPrefixes returns the most accessed key prefixes, sorted by the requested counter
+func (d *db) TopPrefixes(req *schema.TopPrefixesRequest) (*schema.PrefixStatsList, error) {
+	if req == nil {
+		return nil, ErrIllegalArguments
+	}
+
+	by := req.By
+	if by == "" {
+		by = PrefixStatsByReads
+	}
+
+	list, err := d.prefixStats.top(by, int(req.Limit))
+	if err != nil {
+		return nil, err
+	}
+
+	return &schema.PrefixStatsList{Prefixes: list}, nil
+}
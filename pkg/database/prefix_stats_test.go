@@ -0,0 +1,119 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrefixOf(t *testing.T) {
+	p := newPrefixStats(2, '/')
+
+	require.Equal(t, []byte("a/b/"), p.prefixOf([]byte("a/b/c")))
+	require.Equal(t, []byte("a/b/"), p.prefixOf([]byte("a/b/")))
+	require.Equal(t, []byte("a/b"), p.prefixOf([]byte("a/b")))
+	require.Equal(t, []byte("key"), p.prefixOf([]byte("key")))
+
+	require.Nil(t, newPrefixStats(0, '/'))
+}
+
+func TestPrefixStatsOverflow(t *testing.T) {
+	p := newPrefixStats(1, ':')
+
+	for i := 0; i < MaxTrackedPrefixes; i++ {
+		p.write([]byte{byte(i >> 8), byte(i), ':', 'k'}, 1)
+	}
+
+	p.write([]byte("other:k"), 1)
+	p.write([]byte("another:k"), 1)
+
+	list, err := p.top(PrefixStatsByWrites, 1)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.Empty(t, list[0].Prefix)
+	require.Equal(t, uint64(2), list[0].Writes)
+}
+
+func TestTopPrefixes(t *testing.T) {
+	d, closer := makeDb()
+	defer closer()
+
+	_, err := d.TopPrefixes(&schema.TopPrefixesRequest{})
+	require.Equal(t, ErrPrefixStatsDisabled, err)
+
+	d.(*db).prefixStats = newPrefixStats(1, ':')
+
+	_, err = d.TopPrefixes(nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = d.TopPrefixes(&schema.TopPrefixesRequest{By: "unknown"})
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = d.Set(&schema.SetRequest{KVs: []*schema.KeyValue{
+		{Key: []byte("tenant1:k1"), Value: []byte("v1")},
+		{Key: []byte("tenant1:k2"), Value: []byte("v2")},
+		{Key: []byte("tenant2:k1"), Value: []byte("value1")},
+	}})
+	require.NoError(t, err)
+
+	_, err = d.ExecAll(&schema.ExecAllRequest{Operations: []*schema.Op{
+		{Operation: &schema.Op_Kv{Kv: &schema.KeyValue{Key: []byte("tenant2:k2"), Value: []byte("v2")}}},
+		{Operation: &schema.Op_Ref{Ref: &schema.ReferenceRequest{Key: []byte("tenant2:ref"), ReferencedKey: []byte("tenant2:k1")}}},
+	}})
+	require.NoError(t, err)
+
+	_, err = d.Get(&schema.KeyRequest{Key: []byte("tenant1:k1")})
+	require.NoError(t, err)
+
+	_, err = d.Get(&schema.KeyRequest{Key: []byte("tenant1:missing")})
+	require.Error(t, err)
+
+	_, err = d.Scan(context.Background(), &schema.ScanRequest{Prefix: []byte("tenant1:")})
+	require.NoError(t, err)
+
+	_, err = d.History(&schema.HistoryRequest{Key: []byte("tenant2:k1")})
+	require.NoError(t, err)
+
+	list, err := d.TopPrefixes(&schema.TopPrefixesRequest{By: PrefixStatsByReads})
+	require.NoError(t, err)
+	require.Len(t, list.Prefixes, 2)
+	require.Equal(t, []byte("tenant1:"), list.Prefixes[0].Prefix)
+	require.Equal(t, uint64(3), list.Prefixes[0].Reads)
+	require.Equal(t, uint64(2), list.Prefixes[0].Writes)
+	require.Equal(t, uint64(36), list.Prefixes[0].ReadBytes)
+	require.Equal(t, uint64(24), list.Prefixes[0].WrittenBytes)
+	require.Equal(t, []byte("tenant2:"), list.Prefixes[1].Prefix)
+	require.Equal(t, uint64(1), list.Prefixes[1].Reads)
+
+	list, err = d.TopPrefixes(&schema.TopPrefixesRequest{By: PrefixStatsByWrites, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, list.Prefixes, 1)
+	require.Equal(t, []byte("tenant2:"), list.Prefixes[0].Prefix)
+	require.Equal(t, uint64(3), list.Prefixes[0].Writes)
+
+	list, err = d.TopPrefixes(&schema.TopPrefixesRequest{By: PrefixStatsByWrittenBytes})
+	require.NoError(t, err)
+	require.Equal(t, []byte("tenant2:"), list.Prefixes[0].Prefix)
+
+	list, err = d.TopPrefixes(&schema.TopPrefixesRequest{By: PrefixStatsByReadBytes})
+	require.NoError(t, err)
+	require.Equal(t, []byte("tenant1:"), list.Prefixes[0].Prefix)
+}
@@ -36,11 +36,25 @@ func (d *db) SetReference(req *schema.ReferenceRequest) (*schema.TxMetadata, err
 		return nil, store.ErrIllegalArguments
 	}
 
+	if d.options.replica {
+		return nil, ErrIsReplica
+	}
+
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
 
+	err := d.keyRules.validate(req.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	err = d.approvalRules.validate(req.Key)
+	if err != nil {
+		return nil, err
+	}
+
 	lastTxID, _ := d.st.Alh()
-	err := d.st.WaitForIndexingUpto(lastTxID, nil)
+	err = d.st.WaitForIndexingUpto(lastTxID, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -54,6 +68,11 @@ func (d *db) SetReference(req *schema.ReferenceRequest) (*schema.TxMetadata, err
 		return nil, ErrFinalKeyCannotBeConvertedIntoReference
 	}
 
+	err = d.checkWORMVersions([][]byte{req.Key}, d.st)
+	if err != nil {
+		return nil, err
+	}
+
 	// check referenced key exists and it's not a reference
 	refEntry, err := d.getAt(EncodeKey(req.ReferencedKey), req.AtTx, 0, d.st, d.tx1)
 	if err != nil {
@@ -63,11 +82,20 @@ func (d *db) SetReference(req *schema.ReferenceRequest) (*schema.TxMetadata, err
 		return nil, ErrReferencedKeyCannotBeAReference
 	}
 
-	meta, err := d.st.Commit([]*store.KV{EncodeReference(req.Key, req.ReferencedKey, req.AtTx)}, !req.NoWait)
+	kv := EncodeReference(req.Key, req.ReferencedKey, req.AtTx)
+
+	refEntries, err := d.referenceIndexEntries(kv)
+	if err != nil {
+		return nil, err
+	}
+
+	meta, err := d.st.Commit(append([]*store.KV{kv}, refEntries...), !req.NoWait)
 	if err != nil {
 		return nil, err
 	}
 
+	d.prefixStats.write(req.Key, len(req.Key)+len(req.ReferencedKey))
+
 	return schema.TxMetatadaTo(meta), err
 }
 
@@ -0,0 +1,260 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"math"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+)
+
+// The reference index maps keys to the references and sorted set entries pointing to them. Its entries are written
+// under the ReferenceIndexKeyPrefix by the transactions setting references or adding keys to sorted sets, thus they
+// are replicated along with them. Entries are never removed: listings skip references since assigned to a different
+// key and sorted set entries removed with ZRem by checking each one against the current value of its key.
+// References and sorted set entries committed by releases preceding the index are not part of it, thus not listed
+
+var ErrReferenceIndexKeyTooLong = errors.New("reference index entry exceeds the max key length, key or referenced key is too long")
+
+// EncodeReferenceIndexEntry returns the entry mapping a key to the store key of a reference, or sorted set entry,
+// pointing to it. The key is given encoded, as pointed by the reference or sorted set entry
+func EncodeReferenceIndexEntry(key, source []byte) *store.KV {
+	return &store.KV{Key: append(referenceIndexPrefix(key), source...)}
+}
+
+func referenceIndexPrefix(key []byte) []byte {
+	k := make([]byte, 1+8+len(key))
+	k[0] = ReferenceIndexKeyPrefix
+	binary.BigEndian.PutUint64(k[1:], uint64(len(key)))
+	copy(k[1+8:], key)
+	return k
+}
+
+// referenceIndexEntries returns the reference index entries of the references and sorted set entries added by kvs.
+// Every reference and sorted set entry is indexed, thus writes whose index entry would exceed the maximum key length
+// are rejected
+func (d *db) referenceIndexEntries(kvs ...*store.KV) ([]*store.KV, error) {
+	var entries []*store.KV
+
+	for _, kv := range kvs {
+		var key []byte
+
+		switch kv.Key[0] {
+		case SetKeyPrefix:
+			if len(kv.Value) <= 1+8 || kv.Value[0] != ReferenceValuePrefix {
+				continue
+			}
+			key = kv.Value[1+8:]
+		case SortedSetKeyPrefix:
+			// entries removed with ZRem hold a value, the ones added by ZAdd don't
+			if len(kv.Value) > 0 {
+				continue
+			}
+
+			member, ok := zKeyMember(kv.Key)
+			if !ok {
+				continue
+			}
+			key = member
+		default:
+			continue
+		}
+
+		e := EncodeReferenceIndexEntry(key, kv.Key)
+		if len(e.Key) > d.st.MaxKeyLen() {
+			return nil, ErrReferenceIndexKeyTooLong
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries, nil
+}
+
+// zKeyMember returns the encoded key of a sorted set entry
+func zKeyMember(zKey []byte) ([]byte, bool) {
+	if len(zKey) < 1+setLenLen {
+		return nil, false
+	}
+
+	memberOff := 1 + setLenLen + binary.BigEndian.Uint64(zKey[1:]) + scoreLen
+	if memberOff < 1+setLenLen || uint64(len(zKey)) < memberOff {
+		return nil, false
+	}
+
+	member, _, err := zKeyEntry(zKey, int(memberOff))
+	if err != nil {
+		return nil, false
+	}
+
+	return member, true
+}
+
+// ListReferences lists the references currently pointing to req.Key, ordered by key, followed by the sorted set
+// entries of the key, ordered by set and score. Only references and sorted set entries committed since the reference
+// index was introduced are listed
+func (d *db) ListReferences(ctx context.Context, req *schema.ListReferencesRequest) (*schema.ReferenceList, error) {
+	if req == nil || len(req.Key) == 0 {
+		return nil, ErrIllegalArguments
+	}
+
+	if req.Limit > MaxKeyScanLimit {
+		return nil, ErrMaxKeyScanLimitExceeded
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = MaxKeyScanLimit
+	}
+
+	var after []byte
+
+	if req.Continuation != "" {
+		var err error
+
+		after, err = decodeListReferencesContinuation(req.Continuation, req.Key)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	waitUntilTx := req.SinceTx
+	if waitUntilTx == 0 {
+		waitUntilTx, _ = d.st.Alh()
+	}
+
+	err := d.st.WaitForIndexingUpto(waitUntilTx, ctx.Done())
+	if err != nil {
+		return nil, err
+	}
+
+	snap, err := d.st.SnapshotSince(waitUntilTx)
+	if err != nil {
+		return nil, err
+	}
+	defer snap.Close()
+
+	key := EncodeKey(req.Key)
+	prefix := referenceIndexPrefix(key)
+
+	spec := &store.KeyReaderSpec{Prefix: prefix}
+	if after != nil {
+		spec.SeekKey = append(prefix, after...)
+	}
+
+	r, err := snap.NewKeyReader(spec)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	list := &schema.ReferenceList{}
+	n := uint64(0)
+
+	for {
+		if ctx.Err() != nil {
+			return nil, ErrOperationCancelled
+		}
+
+		e, _, _, _, err := r.Read()
+		if err == store.ErrNoMoreEntries {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		s := e[len(prefix):]
+
+		val, tx, _, err := snap.Get(s)
+		if err == store.ErrKeyNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if s[0] == SetKeyPrefix {
+			// the key may have been assigned a different reference
+			if len(val) <= 1+8 || val[0] != ReferenceValuePrefix || !bytes.Equal(val[1+8:], key) {
+				continue
+			}
+
+			list.References = append(list.References, &schema.Reference{
+				Tx:   tx,
+				Key:  TrimPrefix(s),
+				AtTx: binary.BigEndian.Uint64(val[1:]),
+			})
+		} else {
+			if len(val) > 0 {
+				continue
+			}
+
+			setLen := binary.BigEndian.Uint64(s[1:])
+			scoreOff := 1 + setLenLen + setLen
+
+			list.ZEntries = append(list.ZEntries, &schema.ZEntry{
+				Set:   s[1+setLenLen : scoreOff],
+				Key:   req.Key,
+				Score: math.Float64frombits(binary.BigEndian.Uint64(s[scoreOff:])),
+				AtTx:  binary.BigEndian.Uint64(s[len(s)-txIDLen:]),
+			})
+		}
+
+		if n++; n == limit {
+			list.Continuation = encodeListReferencesContinuation(req.Key, s)
+			return list, nil
+		}
+	}
+
+	return list, nil
+}
+
+const listReferencesContinuationVersion byte = 1
+
+// encodeListReferencesContinuation returns an opaque token locating a reference, or sorted set entry, of a key
+func encodeListReferencesContinuation(key, source []byte) string {
+	keyHash := sha256.Sum256(key)
+
+	b := make([]byte, 1+8+len(source))
+	b[0] = listReferencesContinuationVersion
+	copy(b[1:], keyHash[:8])
+	copy(b[1+8:], source)
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeListReferencesContinuation(token string, key []byte) ([]byte, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(b) < 1+8+1 || b[0] != listReferencesContinuationVersion {
+		return nil, ErrInvalidContinuation
+	}
+
+	keyHash := sha256.Sum256(key)
+
+	if !bytes.Equal(b[1:1+8], keyHash[:8]) {
+		return nil, ErrInvalidContinuation
+	}
+
+	return b[1+8:], nil
+}
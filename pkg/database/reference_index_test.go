@@ -0,0 +1,201 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func listReferences(t *testing.T, db DB, key string) (refs []string, zEntries []string) {
+	list, err := db.ListReferences(context.Background(), &schema.ListReferencesRequest{Key: []byte(key)})
+	require.NoError(t, err)
+	require.Empty(t, list.Continuation)
+
+	for _, r := range list.References {
+		refs = append(refs, string(r.Key))
+	}
+
+	for _, e := range list.ZEntries {
+		require.Equal(t, key, string(e.Key))
+		zEntries = append(zEntries, string(e.Set)+":"+strconv.FormatFloat(e.Score, 'f', -1, 64))
+	}
+
+	return refs, zEntries
+}
+
+func TestListReferences(t *testing.T) {
+	rootPath := "data_" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	defer os.RemoveAll(rootPath)
+
+	options := DefaultOption().WithDbRootPath(rootPath).WithDbName("db")
+
+	db, err := NewDb(options, nil, logger.NewSimpleLogger("immudb ", os.Stderr))
+	require.NoError(t, err)
+
+	_, err = db.ListReferences(context.Background(), nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = db.ListReferences(context.Background(), &schema.ListReferencesRequest{})
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = db.ListReferences(context.Background(), &schema.ListReferencesRequest{Key: []byte("key1"), Limit: MaxKeyScanLimit + 1})
+	require.Equal(t, ErrMaxKeyScanLimitExceeded, err)
+
+	_, err = db.ListReferences(context.Background(), &schema.ListReferencesRequest{Key: []byte("key1"), Continuation: "invalid"})
+	require.Equal(t, ErrInvalidContinuation, err)
+
+	refs, zEntries := listReferences(t, db, "key1")
+	require.Empty(t, refs)
+	require.Empty(t, zEntries)
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{
+		{Key: []byte("key1"), Value: []byte("value1")},
+		{Key: []byte("key2"), Value: []byte("value2")},
+	}})
+	require.NoError(t, err)
+
+	// index entries are written by the transaction setting the reference
+	meta, err := db.SetReference(&schema.ReferenceRequest{Key: []byte("ref1"), ReferencedKey: []byte("key1")})
+	require.NoError(t, err)
+	require.Equal(t, int32(2), meta.Nentries)
+
+	_, err = db.ExecAll(&schema.ExecAllRequest{Operations: []*schema.Op{
+		{Operation: &schema.Op_Ref{Ref: &schema.ReferenceRequest{Key: []byte("ref2"), ReferencedKey: []byte("key1")}}},
+		{Operation: &schema.Op_ZAdd{ZAdd: &schema.ZAddRequest{Set: []byte("setB"), Key: []byte("key1"), Score: 2}}},
+	}})
+	require.NoError(t, err)
+
+	_, err = db.ZAdd(&schema.ZAddRequest{Set: []byte("setA"), Key: []byte("key1"), Score: 1})
+	require.NoError(t, err)
+
+	_, err = db.ZAdd(&schema.ZAddRequest{Set: []byte("setA"), Key: []byte("key2"), Score: 3})
+	require.NoError(t, err)
+
+	meta, err = db.ZAddMulti(&schema.ZAddMultiRequest{Entries: []*schema.ZAddRequest{{Set: []byte("setC"), Key: []byte("key1"), Score: 5}}})
+	require.NoError(t, err)
+	require.Equal(t, int32(2), meta.Nentries)
+
+	refs, zEntries = listReferences(t, db, "key1")
+	require.Equal(t, []string{"ref1", "ref2"}, refs)
+	require.Equal(t, []string{"setA:1", "setB:2", "setC:5"}, zEntries)
+
+	// references assigned to a different key and removed sorted set entries are no longer listed
+	_, err = db.SetReference(&schema.ReferenceRequest{Key: []byte("ref2"), ReferencedKey: []byte("key2")})
+	require.NoError(t, err)
+
+	_, err = db.ZRem(&schema.ZRemRequest{Set: []byte("setB"), Key: []byte("key1")})
+	require.NoError(t, err)
+
+	_, err = db.ZIncrBy(&schema.ZIncrByRequest{Set: []byte("setA"), Key: []byte("key2"), Increment: 1})
+	require.NoError(t, err)
+
+	_, err = db.ZUnionStore(&schema.ZStoreRequest{Destination: []byte("setD"), Sets: [][]byte{[]byte("setA")}})
+	require.NoError(t, err)
+
+	refs, zEntries = listReferences(t, db, "key1")
+	require.Equal(t, []string{"ref1"}, refs)
+	require.Equal(t, []string{"setA:1", "setC:5", "setD:1"}, zEntries)
+
+	refs, zEntries = listReferences(t, db, "key2")
+	require.Equal(t, []string{"ref2"}, refs)
+	require.Equal(t, []string{"setA:4", "setD:4"}, zEntries)
+
+	// the listing is paginated with continuations bound to the key
+	var listed int
+	var continuation string
+
+	for {
+		list, err := db.ListReferences(context.Background(), &schema.ListReferencesRequest{Key: []byte("key2"), Limit: 1, Continuation: continuation})
+		require.NoError(t, err)
+
+		listed += len(list.References) + len(list.ZEntries)
+
+		if list.Continuation == "" {
+			break
+		}
+		continuation = list.Continuation
+
+		_, err = db.ListReferences(context.Background(), &schema.ListReferencesRequest{Key: []byte("key1"), Continuation: continuation})
+		require.Equal(t, ErrInvalidContinuation, err)
+	}
+
+	require.Equal(t, 3, listed)
+
+	require.NoError(t, db.Close())
+
+	// index entries are persisted, thus the index is available as soon as the database is reopened
+	db, err = OpenDb(options, nil, logger.NewSimpleLogger("immudb ", os.Stderr))
+	require.NoError(t, err)
+	defer db.Close()
+
+	refs, zEntries = listReferences(t, db, "key1")
+	require.Equal(t, []string{"ref1"}, refs)
+	require.Equal(t, []string{"setA:1", "setC:5", "setD:1"}, zEntries)
+}
+
+func TestZKeyMember(t *testing.T) {
+	zKey := WrapZAddReferenceAt([]byte("set"), 1, EncodeKey([]byte("key")), 2)
+
+	member, ok := zKeyMember(zKey)
+	require.True(t, ok)
+	require.Equal(t, EncodeKey([]byte("key")), member)
+
+	_, ok = zKeyMember(zKey[:len(zKey)-1])
+	require.False(t, ok)
+
+	_, ok = zKeyMember([]byte{SortedSetKeyPrefix, 0xFF})
+	require.False(t, ok)
+}
+
+func TestReferenceIndexKeyTooLong(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	key := make([]byte, 500)
+	ref := make([]byte, 600)
+	set := make([]byte, 600)
+
+	_, err := db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: key, Value: []byte("value")}}})
+	require.NoError(t, err)
+
+	// writes whose index entry exceeds the max key length are rejected instead of being left out of the index
+	_, err = db.SetReference(&schema.ReferenceRequest{Key: ref, ReferencedKey: key})
+	require.Equal(t, ErrReferenceIndexKeyTooLong, err)
+
+	_, err = db.ZAdd(&schema.ZAddRequest{Set: set, Key: key, Score: 1})
+	require.Equal(t, ErrReferenceIndexKeyTooLong, err)
+
+	_, err = db.ExecAll(&schema.ExecAllRequest{Operations: []*schema.Op{
+		{Operation: &schema.Op_Ref{Ref: &schema.ReferenceRequest{Key: ref, ReferencedKey: key}}},
+	}})
+	require.Equal(t, ErrReferenceIndexKeyTooLong, err)
+
+	_, err = db.Get(&schema.KeyRequest{Key: ref})
+	require.Equal(t, store.ErrKeyNotFound, err)
+
+	refs, zEntries := listReferences(t, db, string(key))
+	require.Empty(t, refs)
+	require.Empty(t, zEntries)
+}
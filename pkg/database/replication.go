@@ -0,0 +1,81 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+)
+
+// ExportTx returns the metadata and raw entries of a transaction, so it can be replicated into another database
+func (d *db) ExportTx(req *schema.TxRequest) (*schema.ExportedTx, error) {
+	if req == nil {
+		return nil, ErrIllegalArguments
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	err := d.st.ReadTx(req.Tx, d.tx1)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*schema.KeyValue, len(d.tx1.Entries()))
+
+	for i, e := range d.tx1.Entries() {
+		v, err := d.st.ReadValue(d.tx1, e.Key())
+		if err != nil {
+			return nil, err
+		}
+
+		entries[i] = &schema.KeyValue{Key: e.Key(), Value: v}
+	}
+
+	return &schema.ExportedTx{
+		Metadata: schema.TxMetatadaTo(d.tx1.Metadata()),
+		Entries:  entries,
+	}, nil
+}
+
+// ReplicateTx commits a transaction exported from the master database.
+// store.ErrReplicaDivergence is returned if it does not extend the local history exactly as it did on the master
+func (d *db) ReplicateTx(exportedTx *schema.ExportedTx) (*schema.TxMetadata, error) {
+	if exportedTx == nil || exportedTx.Metadata == nil {
+		return nil, ErrIllegalArguments
+	}
+
+	entries := make([]*store.KV, len(exportedTx.Entries))
+
+	for i, e := range exportedTx.Entries {
+		entries[i] = &store.KV{Key: e.Key, Value: e.Value}
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	txMetadata, err := d.st.ReplicateTx(schema.TxMetadataFrom(exportedTx.Metadata), entries, false)
+	if err != nil {
+		return nil, err
+	}
+
+	err = d.replicateEpoch(entries, txMetadata.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return schema.TxMetatadaTo(txMetadata), nil
+}
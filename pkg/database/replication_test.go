@@ -0,0 +1,116 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplication(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	rootPath := "data_replica_" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	defer os.RemoveAll(rootPath)
+
+	catalogDB, err := NewDb(DefaultOption().WithDbRootPath(rootPath).WithDbName("catalog"), nil, logger.NewSimpleLogger("immudb ", os.Stderr))
+	require.NoError(t, err)
+	defer catalogDB.Close()
+
+	replicaOpts := DefaultOption().WithDbRootPath(rootPath).WithDbName("replica").WithReplica(true)
+	require.True(t, replicaOpts.IsReplica())
+
+	replica, err := NewDb(replicaOpts, catalogDB, logger.NewSimpleLogger("immudb ", os.Stderr))
+	require.NoError(t, err)
+	defer replica.Close()
+
+	_, err = replica.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("key"), Value: []byte("value")}}})
+	require.Equal(t, ErrIsReplica, err)
+
+	_, err = replica.ExecAll(&schema.ExecAllRequest{Operations: []*schema.Op{{Operation: &schema.Op_Kv{Kv: &schema.KeyValue{Key: []byte("key"), Value: []byte("value")}}}}})
+	require.Equal(t, ErrIsReplica, err)
+
+	_, err = replica.SetReference(&schema.ReferenceRequest{Key: []byte("ref"), ReferencedKey: []byte("key")})
+	require.Equal(t, ErrIsReplica, err)
+
+	_, err = replica.ZAdd(&schema.ZAddRequest{Set: []byte("set"), Key: []byte("key")})
+	require.Equal(t, ErrIsReplica, err)
+
+	_, err = replica.SQLExec(&schema.SQLExecRequest{Sql: "CREATE TABLE t(id INTEGER, PRIMARY KEY id)"})
+	require.Equal(t, ErrIsReplica, err)
+
+	_, err = db.ExportTx(nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = replica.ReplicateTx(nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	for _, kv := range kvs {
+		_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{kv}})
+		require.NoError(t, err)
+	}
+
+	_, err = db.ZAdd(&schema.ZAddRequest{Set: []byte("set"), Key: kvs[0].Key, Score: 1})
+	require.NoError(t, err)
+
+	state, err := db.CurrentState()
+	require.NoError(t, err)
+
+	for i := uint64(1); i <= state.TxId; i++ {
+		etx, err := db.ExportTx(&schema.TxRequest{Tx: i})
+		require.NoError(t, err)
+
+		md, err := replica.ReplicateTx(etx)
+		require.NoError(t, err)
+		require.Equal(t, i, md.Id)
+	}
+
+	replicaState, err := replica.CurrentState()
+	require.NoError(t, err)
+	require.Equal(t, state.TxId, replicaState.TxId)
+	require.Equal(t, state.TxHash, replicaState.TxHash)
+
+	entry, err := replica.Get(&schema.KeyRequest{Key: kvs[1].Key, SinceTx: state.TxId})
+	require.NoError(t, err)
+	require.Equal(t, kvs[1].Value, entry.Value)
+
+	zentries, err := replica.ZScan(context.Background(), &schema.ZScanRequest{Set: []byte("set"), SinceTx: state.TxId})
+	require.NoError(t, err)
+	require.Len(t, zentries.Entries, 1)
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("key"), Value: []byte("value")}}})
+	require.NoError(t, err)
+
+	etx, err := db.ExportTx(&schema.TxRequest{Tx: state.TxId + 1})
+	require.NoError(t, err)
+
+	etx.Entries[0].Value = []byte("tampered")
+
+	_, err = replica.ReplicateTx(etx)
+	require.Equal(t, store.ErrReplicaDivergence, err)
+
+	_, err = replica.ReplicateTx(&schema.ExportedTx{Metadata: etx.Metadata})
+	require.Error(t, err)
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"time"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/golang/protobuf/proto"
+)
+
+// Once a database was restored from a backup, clients holding a state it no longer reaches fail to verify it.
+// A rewind attestation, recorded under the RewindKeyPrefix key, sanctions the restored state so clients
+// configured to trust it can move their state to it instead of requiring a manual reset
+
+// AttestRewind records an attestation that the database was intentionally rewound to its current state.
+// The attestation is signed with sign before being committed
+func (d *db) AttestRewind(issuedBy string, reason string, sign func(*schema.RewindAttestation) error) (*schema.RewindAttestation, error) {
+	if sign == nil {
+		return nil, ErrIllegalArguments
+	}
+
+	if d.options.replica {
+		return nil, ErrIsReplica
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	txID, txAlh := d.st.Alh()
+
+	attestation := &schema.RewindAttestation{
+		Db:       d.options.dbName,
+		TxId:     txID,
+		TxHash:   txAlh[:],
+		IssuedAt: time.Now().Unix(),
+		IssuedBy: issuedBy,
+		Reason:   reason,
+	}
+
+	err := sign(attestation)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := proto.Marshal(attestation)
+	if err != nil {
+		return nil, err
+	}
+
+	txmd, err := d.st.Commit([]*store.KV{{Key: []byte{RewindKeyPrefix}, Value: v}}, true)
+	if err != nil {
+		return nil, err
+	}
+
+	attestation.Tx = txmd.ID
+
+	return attestation, nil
+}
+
+// RewindAttestation returns the latest rewind attestation recorded into the database
+func (d *db) RewindAttestation() (*schema.RewindAttestation, error) {
+	lastTxID, _ := d.st.Alh()
+
+	err := d.st.WaitForIndexingUpto(lastTxID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	v, tx, _, err := d.st.Get([]byte{RewindKeyPrefix})
+	if err == store.ErrKeyNotFound {
+		return nil, ErrNoRewindAttestation
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var attestation schema.RewindAttestation
+
+	err = proto.Unmarshal(v, &attestation)
+	if err != nil {
+		return nil, err
+	}
+
+	attestation.Tx = tx
+
+	return &attestation, nil
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttestRewind(t *testing.T) {
+	rootPath := "data_" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	defer os.RemoveAll(rootPath)
+
+	options := DefaultOption().WithDbRootPath(rootPath).WithDbName("db")
+
+	db, err := NewDb(options, nil, logger.NewSimpleLogger("immudb ", os.Stderr))
+	require.NoError(t, err)
+
+	_, err = db.RewindAttestation()
+	require.Equal(t, ErrNoRewindAttestation, err)
+
+	_, err = db.AttestRewind("admin", "restored from backup", nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("key"), Value: []byte("value")}}})
+	require.NoError(t, err)
+
+	state, err := db.CurrentState()
+	require.NoError(t, err)
+
+	sign := func(a *schema.RewindAttestation) error {
+		a.Signature = &schema.Signature{Signature: []byte("signature")}
+		return nil
+	}
+
+	attestation, err := db.AttestRewind("admin", "restored from backup", sign)
+	require.NoError(t, err)
+	require.Equal(t, "db", attestation.Db)
+	require.Equal(t, state.TxId, attestation.TxId)
+	require.Equal(t, state.TxHash, attestation.TxHash)
+	require.Equal(t, "admin", attestation.IssuedBy)
+	require.Equal(t, "restored from backup", attestation.Reason)
+	require.Equal(t, state.TxId+1, attestation.Tx)
+	require.Equal(t, []byte("signature"), attestation.Signature.Signature)
+
+	// attestations are not visible as regular keys
+	_, err = db.Get(&schema.KeyRequest{Key: []byte{RewindKeyPrefix}})
+	require.Error(t, err)
+
+	err = db.Close()
+	require.NoError(t, err)
+
+	db, err = OpenDb(options, nil, logger.NewSimpleLogger("immudb ", os.Stderr))
+	require.NoError(t, err)
+	defer db.Close()
+
+	stored, err := db.RewindAttestation()
+	require.NoError(t, err)
+	require.Equal(t, attestation.TxId, stored.TxId)
+	require.Equal(t, attestation.Tx, stored.Tx)
+	require.Equal(t, attestation.ToBytes(), stored.ToBytes())
+
+	replica, err := NewDb(DefaultOption().WithDbRootPath(rootPath).WithDbName("replica").WithReplica(true), nil, logger.NewSimpleLogger("immudb ", os.Stderr))
+	require.NoError(t, err)
+	defer replica.Close()
+
+	_, err = replica.AttestRewind("admin", "restored from backup", sign)
+	require.Equal(t, ErrIsReplica, err)
+}
@@ -16,12 +16,14 @@ limitations under the License.
 package database
 
 import (
+	"context"
+
 	"github.com/codenotary/immudb/embedded/store"
 	"github.com/codenotary/immudb/pkg/api/schema"
 )
 
 //Scan ...
-func (d *db) Scan(req *schema.ScanRequest) (*schema.Entries, error) {
+func (d *db) Scan(ctx context.Context, req *schema.ScanRequest) (*schema.Entries, error) {
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
 
@@ -33,6 +35,22 @@ func (d *db) Scan(req *schema.ScanRequest) (*schema.Entries, error) {
 		return nil, ErrMaxKeyScanLimitExceeded
 	}
 
+	keyPattern, err := compileKeyPattern(req.KeyPattern, req.RegexPattern)
+	if err != nil {
+		return nil, err
+	}
+
+	var asBeforeTx uint64
+
+	if req.Snapshot != "" {
+		txID, err := d.pinnedSnapshotTx(req.Snapshot)
+		if err != nil {
+			return nil, err
+		}
+
+		asBeforeTx = txID + 1
+	}
+
 	waitUntilTx := req.SinceTx
 
 	if waitUntilTx == 0 {
@@ -40,7 +58,7 @@ func (d *db) Scan(req *schema.ScanRequest) (*schema.Entries, error) {
 	}
 
 	if !req.NoWait {
-		err := d.st.WaitForIndexingUpto(waitUntilTx, nil)
+		err := d.st.WaitForIndexingUpto(waitUntilTx, ctx.Done())
 		if err != nil {
 			return nil, err
 		}
@@ -67,11 +85,19 @@ func (d *db) Scan(req *schema.ScanRequest) (*schema.Entries, error) {
 		seekKey = EncodeKey(req.SeekKey)
 	}
 
+	endKey := req.EndKey
+
+	if len(endKey) > 0 {
+		endKey = EncodeKey(req.EndKey)
+	}
+
 	r, err := snap.NewKeyReader(
 		&store.KeyReaderSpec{
-			SeekKey:   seekKey,
-			Prefix:    EncodeKey(req.Prefix),
-			DescOrder: req.Desc,
+			SeekKey:      seekKey,
+			Prefix:       EncodeKey(req.Prefix),
+			EndKey:       endKey,
+			InclusiveEnd: req.InclusiveEnd,
+			DescOrder:    req.Desc,
 		})
 	if err != nil {
 		return nil, err
@@ -79,7 +105,19 @@ func (d *db) Scan(req *schema.ScanRequest) (*schema.Entries, error) {
 	defer r.Close()
 
 	for {
-		key, _, tx, _, err := r.Read()
+		if ctx.Err() != nil {
+			return nil, ErrOperationCancelled
+		}
+
+		var key []byte
+		var valRef *store.ValueRef
+		var tx uint64
+
+		if asBeforeTx > 0 {
+			key, valRef, tx, err = r.ReadAsBefore(asBeforeTx)
+		} else {
+			key, valRef, tx, _, err = r.Read()
+		}
 		if err == store.ErrNoMoreEntries {
 			break
 		}
@@ -87,7 +125,27 @@ func (d *db) Scan(req *schema.ScanRequest) (*schema.Entries, error) {
 			return nil, err
 		}
 
-		e, err := d.getAt(key, tx, 0, snap, d.tx1)
+		// values of keys not matching the pattern are never read
+		if keyPattern != nil && !keyPattern.Match(TrimPrefix(key)) {
+			continue
+		}
+
+		// values are resolved through the reader so sequential scans benefit from readahead
+		val, err := valRef.Resolve()
+		if err != nil {
+			return nil, err
+		}
+
+		var e *schema.Entry
+
+		if val[0] == ReferenceValuePrefix {
+			e, err = d.getAt(key, tx, 0, snap, d.tx1)
+		} else {
+			e, err = valueEntry(key, val, tx)
+		}
+		if unreadable(err) {
+			continue
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -98,6 +156,8 @@ func (d *db) Scan(req *schema.ScanRequest) (*schema.Entries, error) {
 		}
 	}
 
+	d.prefixStats.readEntries(entries)
+
 	return &schema.Entries{
 		Entries: entries,
 	}, nil
@@ -1,9 +1,11 @@
 package database
 
 import (
+	"context"
 	"testing"
 
 	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/embedded/watchers"
 	"github.com/codenotary/immudb/pkg/api/schema"
 	"github.com/stretchr/testify/require"
 )
@@ -18,7 +20,7 @@ func TestStoreScan(t *testing.T) {
 	scanOptions := schema.ScanRequest{
 		Prefix: []byte(`z`),
 	}
-	list, err := db.Scan(&scanOptions)
+	list, err := db.Scan(context.Background(), &scanOptions)
 	require.NoError(t, err)
 	require.Empty(t, list.Entries)
 
@@ -29,7 +31,7 @@ func TestStoreScan(t *testing.T) {
 	require.Equal(t, []byte(`abc`), item.Key)
 	require.NoError(t, err)
 
-	_, err = db.Scan(nil)
+	_, err = db.Scan(context.Background(), nil)
 	require.Equal(t, store.ErrIllegalArguments, err)
 
 	scanOptions = schema.ScanRequest{
@@ -39,7 +41,7 @@ func TestStoreScan(t *testing.T) {
 		Desc:    true,
 	}
 
-	_, err = db.Scan(&scanOptions)
+	_, err = db.Scan(context.Background(), &scanOptions)
 	require.Equal(t, ErrMaxKeyScanLimitExceeded, err)
 
 	scanOptions = schema.ScanRequest{
@@ -49,7 +51,7 @@ func TestStoreScan(t *testing.T) {
 		Desc:    true,
 	}
 
-	list, err = db.Scan(&scanOptions)
+	list, err = db.Scan(context.Background(), &scanOptions)
 	require.NoError(t, err)
 	require.Exactly(t, 2, len(list.Entries))
 	require.Equal(t, list.Entries[0].Key, []byte(`abc`))
@@ -64,7 +66,7 @@ func TestStoreScan(t *testing.T) {
 		Desc:    false,
 	}
 
-	list1, err1 := db.Scan(&scanOptions1)
+	list1, err1 := db.Scan(context.Background(), &scanOptions1)
 	require.NoError(t, err1)
 	require.Exactly(t, 3, len(list1.Entries))
 	require.Equal(t, list1.Entries[0].Key, []byte(`aaa`))
@@ -93,7 +95,7 @@ func TestStoreScanPrefix(t *testing.T) {
 		SinceTx: meta.Id,
 	}
 
-	list, err := db.Scan(&scanOptions)
+	list, err := db.Scan(context.Background(), &scanOptions)
 	require.NoError(t, err)
 	require.Exactly(t, 3, len(list.Entries))
 	require.Equal(t, list.Entries[0].Key, []byte(`prefix:suffix1`))
@@ -108,7 +110,7 @@ func TestStoreScanPrefix(t *testing.T) {
 		SinceTx: meta.Id,
 	}
 
-	list, err = db.Scan(&scanOptions)
+	list, err = db.Scan(context.Background(), &scanOptions)
 	require.NoError(t, err)
 	require.Exactly(t, 3, len(list.Entries))
 	require.Equal(t, list.Entries[0].Key, []byte(`prefix:suffix3`))
@@ -134,7 +136,7 @@ func TestStoreScanDesc(t *testing.T) {
 		SinceTx: meta.Id,
 	}
 
-	list, err := db.Scan(&scanOptions)
+	list, err := db.Scan(context.Background(), &scanOptions)
 	require.NoError(t, err)
 	require.Exactly(t, 3, len(list.Entries))
 	require.Equal(t, list.Entries[0].Key, []byte(`key1`))
@@ -149,7 +151,7 @@ func TestStoreScanDesc(t *testing.T) {
 		SinceTx: meta.Id,
 	}
 
-	list, err = db.Scan(&scanOptions)
+	list, err = db.Scan(context.Background(), &scanOptions)
 	require.NoError(t, err)
 	require.Exactly(t, 2, len(list.Entries))
 	require.Equal(t, list.Entries[0].Key, []byte(`key2`))
@@ -163,7 +165,7 @@ func TestStoreScanDesc(t *testing.T) {
 		SinceTx: meta.Id,
 	}
 
-	list, err = db.Scan(&scanOptions)
+	list, err = db.Scan(context.Background(), &scanOptions)
 	require.NoError(t, err)
 	require.Exactly(t, 1, len(list.Entries))
 	require.Equal(t, list.Entries[0].Key, []byte(`key1`))
@@ -176,7 +178,58 @@ func TestStoreScanDesc(t *testing.T) {
 		SinceTx: meta.Id,
 	}
 
-	list, err = db.Scan(&scanOptions)
+	list, err = db.Scan(context.Background(), &scanOptions)
 	require.NoError(t, err)
 	require.Len(t, list.Entries, 3)
 }
+
+func TestStoreScanCancellation(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	meta, err := db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte(`aaa`), Value: []byte(`item1`)}}})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = db.Scan(ctx, &schema.ScanRequest{Prefix: []byte(`a`), SinceTx: meta.Id, NoWait: true})
+	require.Equal(t, ErrOperationCancelled, err)
+
+	_, err = db.ZScan(ctx, &schema.ZScanRequest{Set: []byte(`set`), SinceTx: meta.Id, NoWait: true})
+	require.Equal(t, ErrOperationCancelled, err)
+
+	_, err = db.Scan(ctx, &schema.ScanRequest{Prefix: []byte(`a`), SinceTx: meta.Id + 1})
+	require.Equal(t, watchers.ErrCancellationRequested, err)
+}
+
+func TestStoreScanEndKey(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	for _, k := range []string{"a", "b", "c", "d", "e"} {
+		_, err := db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte(k), Value: []byte(k)}}})
+		require.NoError(t, err)
+	}
+
+	scanKeys := func(req *schema.ScanRequest) []string {
+		list, err := db.Scan(context.Background(), req)
+		require.NoError(t, err)
+
+		var keys []string
+		for _, e := range list.Entries {
+			keys = append(keys, string(e.Key))
+		}
+		return keys
+	}
+
+	require.Equal(t, []string{"a", "b", "c"}, scanKeys(&schema.ScanRequest{EndKey: []byte("d")}))
+	require.Equal(t, []string{"a", "b", "c", "d"}, scanKeys(&schema.ScanRequest{EndKey: []byte("d"), InclusiveEnd: true}))
+	require.Equal(t, []string{"c"}, scanKeys(&schema.ScanRequest{SeekKey: []byte("b"), EndKey: []byte("d")}))
+	require.Equal(t, []string{"b", "c"}, scanKeys(&schema.ScanRequest{EndKey: []byte("cc"), SeekKey: []byte("a"), Limit: 2}))
+
+	require.Equal(t, []string{"e", "d", "c"}, scanKeys(&schema.ScanRequest{EndKey: []byte("b"), Desc: true}))
+	require.Equal(t, []string{"d", "c", "b"}, scanKeys(&schema.ScanRequest{SeekKey: []byte("e"), EndKey: []byte("b"), InclusiveEnd: true, Desc: true}))
+
+	require.Empty(t, scanKeys(&schema.ScanRequest{EndKey: []byte("a")}))
+}
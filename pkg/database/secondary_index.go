@@ -0,0 +1,647 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package database
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"sort"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+)
+
+// Secondary indexes map a part of the values, either a field of JSON values or a fixed range of bytes, to the keys
+// holding them. Each index is declared by an entry under the SecondaryIndexKeyPrefix, and every value written to an
+// indexed key adds an entry made of the index name, the indexed value and the key, committed in the same transaction.
+// Entries are never removed: when a key is updated or deleted, scans skip its previous entries by checking each one
+// against the current value of the key
+
+// MaxSecondaryIndexes is the maximum number of secondary indexes of each database, so every written value adds at
+// most as many entries to its transaction
+const MaxSecondaryIndexes = 32
+
+const (
+	secondaryIndexDefinition byte = iota
+	secondaryIndexEntry
+)
+
+type secondaryIndex struct {
+	def  *schema.SecondaryIndex
+	path []string
+}
+
+func newSecondaryIndex(def *schema.SecondaryIndex) (*secondaryIndex, error) {
+	if def == nil || def.Name == "" || (def.JsonPath == "") == (def.Length == 0) || (def.JsonPath != "" && def.Offset > 0) {
+		return nil, ErrIllegalArguments
+	}
+
+	idx := &secondaryIndex{def: def}
+
+	if def.JsonPath != "" {
+		path, err := parseJSONPath(def.JsonPath)
+		if err != nil {
+			return nil, err
+		}
+		idx.path = path
+	}
+
+	return idx, nil
+}
+
+// value returns the indexed part of a value assigned to key, if the key is covered by the index
+func (idx *secondaryIndex) value(key, value []byte) ([]byte, bool) {
+	if !bytes.HasPrefix(key, idx.def.Prefix) {
+		return nil, false
+	}
+
+	if idx.path != nil {
+		v, ok := jsonField(value, idx.path)
+		return []byte(v), ok
+	}
+
+	end := uint64(idx.def.Offset) + uint64(idx.def.Length)
+	if uint64(len(value)) < end {
+		return nil, false
+	}
+
+	return value[idx.def.Offset:end], true
+}
+
+// EncodeSecondaryIndex returns the entry declaring an index, or dropping it when active is false
+func EncodeSecondaryIndex(def *schema.SecondaryIndex, active bool) *store.KV {
+	v := make([]byte, 1+4+len(def.Prefix)+4+len(def.JsonPath)+4+4)
+
+	if active {
+		v[0] = 1
+	}
+
+	off := 1
+
+	binary.BigEndian.PutUint32(v[off:], uint32(len(def.Prefix)))
+	copy(v[off+4:], def.Prefix)
+	off += 4 + len(def.Prefix)
+
+	binary.BigEndian.PutUint32(v[off:], uint32(len(def.JsonPath)))
+	copy(v[off+4:], def.JsonPath)
+	off += 4 + len(def.JsonPath)
+
+	binary.BigEndian.PutUint32(v[off:], def.Offset)
+	binary.BigEndian.PutUint32(v[off+4:], def.Length)
+
+	return &store.KV{Key: secondaryIndexKey(def.Name), Value: v}
+}
+
+// DecodeSecondaryIndex returns the declaration of the named index, along with whether it's active
+func DecodeSecondaryIndex(name string, v []byte) (*schema.SecondaryIndex, bool, error) {
+	if len(v) < 1+4 {
+		return nil, false, store.ErrCorruptedData
+	}
+
+	off := 1
+
+	prefixLen := int(binary.BigEndian.Uint32(v[off:]))
+	if len(v) < off+4+prefixLen+4 {
+		return nil, false, store.ErrCorruptedData
+	}
+
+	prefix := make([]byte, prefixLen)
+	copy(prefix, v[off+4:])
+	off += 4 + prefixLen
+
+	pathLen := int(binary.BigEndian.Uint32(v[off:]))
+	if len(v) != off+4+pathLen+4+4 {
+		return nil, false, store.ErrCorruptedData
+	}
+
+	path := string(v[off+4 : off+4+pathLen])
+	off += 4 + pathLen
+
+	return &schema.SecondaryIndex{
+		Name:     name,
+		Prefix:   prefix,
+		JsonPath: path,
+		Offset:   binary.BigEndian.Uint32(v[off:]),
+		Length:   binary.BigEndian.Uint32(v[off+4:]),
+	}, v[0] == 1, nil
+}
+
+// EncodeSecondaryIndexEntry returns the entry mapping an indexed value to the key holding it
+func EncodeSecondaryIndexEntry(name string, value, key []byte) *store.KV {
+	return &store.KV{Key: append(appendIndexValue(secondaryIndexEntriesPrefix(name), value), key...)}
+}
+
+func secondaryIndexKey(name string) []byte {
+	k := make([]byte, 2+len(name))
+	k[0] = SecondaryIndexKeyPrefix
+	k[1] = secondaryIndexDefinition
+	copy(k[2:], name)
+	return k
+}
+
+func secondaryIndexEntriesPrefix(name string) []byte {
+	k := make([]byte, 2+8+len(name))
+	k[0] = SecondaryIndexKeyPrefix
+	k[1] = secondaryIndexEntry
+	binary.BigEndian.PutUint64(k[2:], uint64(len(name)))
+	copy(k[2+8:], name)
+	return k
+}
+
+// appendIndexValue appends an indexed value so entries are ordered by value and the value can be told apart
+// from the key following it: zero bytes are escaped by a 0xFF byte and the value is terminated by 0x00 0x01
+func appendIndexValue(b, value []byte) []byte {
+	for _, c := range value {
+		b = append(b, c)
+		if c == 0 {
+			b = append(b, 0xFF)
+		}
+	}
+	return append(b, 0, 1)
+}
+
+// splitIndexEntry returns the indexed value and the key of an entry, given its key without the index prefix
+func splitIndexEntry(k []byte) (value []byte, key []byte, err error) {
+	for i := 0; i < len(k)-1; i++ {
+		if k[i] != 0 {
+			value = append(value, k[i])
+			continue
+		}
+
+		switch k[i+1] {
+		case 0xFF:
+			value = append(value, 0)
+			i++
+		case 1:
+			return value, k[i+2:], nil
+		default:
+			return nil, nil, store.ErrCorruptedData
+		}
+	}
+
+	return nil, nil, store.ErrCorruptedData
+}
+
+// secondaryIndexEntries must be called while holding secondaryIndexesMutex, it returns the entries indexing a value
+// written to key. Values whose entry would exceed the maximum key length are not indexed
+func (d *db) secondaryIndexEntries(key, value []byte) []*store.KV {
+	var entries []*store.KV
+
+	for _, idx := range d.secondaryIndexes {
+		v, ok := idx.value(key, value)
+		if !ok {
+			continue
+		}
+
+		e := EncodeSecondaryIndexEntry(idx.def.Name, v, key)
+		if len(e.Key) > d.st.MaxKeyLen() {
+			continue
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries
+}
+
+// CreateSecondaryIndex declares an index over the values of the keys under def.Prefix. Values written before
+// the declaration are indexed right after it, later values are indexed by the transactions writing them
+func (d *db) CreateSecondaryIndex(def *schema.SecondaryIndex) (*schema.SecondaryIndex, error) {
+	idx, err := newSecondaryIndex(def)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.options.replica {
+		return nil, ErrIsReplica
+	}
+
+	d.secondaryIndexesMutex.Lock()
+
+	if _, ok := d.secondaryIndexes[def.Name]; ok {
+		d.secondaryIndexesMutex.Unlock()
+		return nil, ErrSecondaryIndexAlreadyExists
+	}
+
+	if len(d.secondaryIndexes) >= MaxSecondaryIndexes {
+		d.secondaryIndexesMutex.Unlock()
+		return nil, ErrMaxSecondaryIndexesExceeded
+	}
+
+	txmd, err := d.st.Commit([]*store.KV{EncodeSecondaryIndex(def, true)}, true)
+	if err != nil {
+		d.secondaryIndexesMutex.Unlock()
+		return nil, err
+	}
+
+	def.Tx = txmd.ID
+	d.secondaryIndexes[def.Name] = idx
+
+	d.secondaryIndexesMutex.Unlock()
+
+	err = d.indexValues(idx, txmd.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return def, nil
+}
+
+// indexValues adds the entries of the values assigned as of the given transaction to the keys covered by the index.
+// Transactions committed without waiting for indexing may still be pending, thus indexing is awaited up to txID
+func (d *db) indexValues(idx *secondaryIndex, txID uint64) error {
+	err := d.st.WaitForIndexingUpto(txID, nil)
+	if err != nil {
+		return err
+	}
+
+	snap, err := d.st.SnapshotSince(txID)
+	if err != nil {
+		return err
+	}
+	defer snap.Close()
+
+	r, err := snap.NewKeyReader(&store.KeyReaderSpec{Prefix: EncodeKey(idx.def.Prefix)})
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	var entries []*store.KV
+
+	for {
+		key, valRef, _, _, err := r.Read()
+		if err == store.ErrNoMoreEntries {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		val, err := valRef.Resolve()
+		if err != nil {
+			return err
+		}
+
+		value, ok := indexableValue(val)
+		if !ok {
+			continue
+		}
+
+		v, ok := idx.value(TrimPrefix(key), value)
+		if !ok {
+			continue
+		}
+
+		e := EncodeSecondaryIndexEntry(idx.def.Name, v, TrimPrefix(key))
+		if len(e.Key) > d.st.MaxKeyLen() {
+			continue
+		}
+
+		entries = append(entries, e)
+
+		if len(entries) == d.st.MaxTxEntries() {
+			_, err = d.st.Commit(entries, false)
+			if err != nil {
+				return err
+			}
+			entries = nil
+		}
+	}
+
+	if len(entries) == 0 {
+		return nil
+	}
+
+	_, err = d.st.Commit(entries, true)
+	return err
+}
+
+// indexableValue returns the value held by an entry, unless the key was deleted, expired or holds a reference or a blob
+func indexableValue(val []byte) ([]byte, bool) {
+	if len(val) == 0 || val[0] == ReferenceValuePrefix || val[0] == BlobValuePrefix || deleted(val) {
+		return nil, false
+	}
+
+	value, expiresAt, err := decodeValue(val)
+	if err != nil || expired(expiresAt) {
+		return nil, false
+	}
+
+	return value, true
+}
+
+// DropSecondaryIndex drops an index, whose entries are no longer added nor scanned
+func (d *db) DropSecondaryIndex(name string) error {
+	if name == "" {
+		return ErrIllegalArguments
+	}
+
+	if d.options.replica {
+		return ErrIsReplica
+	}
+
+	d.secondaryIndexesMutex.Lock()
+	defer d.secondaryIndexesMutex.Unlock()
+
+	idx, ok := d.secondaryIndexes[name]
+	if !ok {
+		return ErrSecondaryIndexNotFound
+	}
+
+	_, err := d.st.Commit([]*store.KV{EncodeSecondaryIndex(idx.def, false)}, true)
+	if err != nil {
+		return err
+	}
+
+	delete(d.secondaryIndexes, name)
+
+	return nil
+}
+
+// ListSecondaryIndexes returns the declared indexes ordered by name
+func (d *db) ListSecondaryIndexes() *schema.SecondaryIndexes {
+	d.secondaryIndexesMutex.RLock()
+	defer d.secondaryIndexesMutex.RUnlock()
+
+	list := &schema.SecondaryIndexes{}
+
+	for _, idx := range d.secondaryIndexes {
+		list.Indexes = append(list.Indexes, idx.def)
+	}
+
+	sort.Slice(list.Indexes, func(i, j int) bool {
+		return list.Indexes[i].Name < list.Indexes[j].Name
+	})
+
+	return list
+}
+
+// IndexScan lists the entries whose indexed value is equal to req.Value, or within the requested range, ordered by
+// indexed value and key. Entries are checked against the current value of their key, so only the keys currently
+// holding a matching value are listed
+func (d *db) IndexScan(ctx context.Context, req *schema.IndexScanRequest) (*schema.IndexEntries, error) {
+	if req == nil || req.Index == "" || (len(req.Value) > 0 && (len(req.MinValue) > 0 || len(req.MaxValue) > 0)) {
+		return nil, ErrIllegalArguments
+	}
+
+	if req.Limit > MaxKeyScanLimit {
+		return nil, ErrMaxKeyScanLimitExceeded
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = MaxKeyScanLimit
+	}
+
+	waitUntilTx := req.SinceTx
+	if waitUntilTx == 0 {
+		waitUntilTx, _ = d.st.Alh()
+	}
+
+	err := d.st.WaitForIndexingUpto(waitUntilTx, ctx.Done())
+	if err != nil {
+		return nil, err
+	}
+
+	snap, err := d.st.SnapshotSince(waitUntilTx)
+	if err != nil {
+		return nil, err
+	}
+	defer snap.Close()
+
+	// the index is read from the snapshot, so replicas can scan indexes declared in their primary
+	v, _, _, err := snap.Get(secondaryIndexKey(req.Index))
+	if err == store.ErrKeyNotFound {
+		return nil, ErrSecondaryIndexNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	def, active, err := DecodeSecondaryIndex(req.Index, v)
+	if err != nil {
+		return nil, err
+	}
+	if !active {
+		return nil, ErrSecondaryIndexNotFound
+	}
+
+	idx, err := newSecondaryIndex(def)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := secondaryIndexEntriesPrefix(req.Index)
+	spec := indexScanSpec(prefix, req, d.st.MaxKeyLen())
+
+	if req.Continuation != "" {
+		suffix, err := decodeIndexScanContinuation(req.Continuation, req.Index, req.Desc)
+		if err != nil {
+			return nil, err
+		}
+
+		spec.SeekKey = append(append([]byte{}, prefix...), suffix...)
+		spec.InclusiveSeek = false
+	}
+
+	r, err := snap.NewKeyReader(spec)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	list := &schema.IndexEntries{}
+
+	for {
+		if ctx.Err() != nil {
+			return nil, ErrOperationCancelled
+		}
+
+		k, _, _, _, err := r.Read()
+		if err == store.ErrNoMoreEntries {
+			return list, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		value, key, err := splitIndexEntry(k[len(prefix):])
+		if err != nil {
+			return nil, err
+		}
+
+		val, tx, _, err := snap.Get(EncodeKey(key))
+		if err == store.ErrKeyNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		current, ok := indexableValue(val)
+		if !ok {
+			continue
+		}
+
+		// entries of values the key no longer holds are skipped
+		if v, ok := idx.value(key, current); !ok || !bytes.Equal(v, value) {
+			continue
+		}
+
+		e, err := valueEntry(EncodeKey(key), val, tx)
+		if err != nil {
+			return nil, err
+		}
+
+		list.Entries = append(list.Entries, &schema.IndexEntry{Value: value, Entry: e})
+
+		if uint64(len(list.Entries)) == limit {
+			list.Continuation = encodeIndexScanContinuation(req.Index, req.Desc, k[len(prefix):])
+			return list, nil
+		}
+	}
+}
+
+// indexScanSpec returns the spec reading the entries of the requested values, in the requested order
+func indexScanSpec(prefix []byte, req *schema.IndexScanRequest, maxKeyLen int) *store.KeyReaderSpec {
+	minValue, maxValue := req.MinValue, req.MaxValue
+	if len(req.Value) > 0 {
+		minValue, maxValue = req.Value, req.Value
+	}
+
+	var start, end []byte
+
+	if len(minValue) > 0 {
+		// entries of minValue follow the escaped value, as well as the ones of greater values
+		start = appendIndexValue(append([]byte{}, prefix...), minValue)
+		start = start[:len(start)-2]
+	}
+
+	if len(maxValue) > 0 {
+		// entries of maxValue precede the escaped value followed by 0x00 0x02, unlike the ones of greater values
+		end = appendIndexValue(append([]byte{}, prefix...), maxValue)
+		end[len(end)-1] = 2
+	}
+
+	spec := &store.KeyReaderSpec{Prefix: prefix, DescOrder: req.Desc}
+
+	if req.Desc {
+		spec.SeekKey, spec.EndKey, spec.InclusiveEnd = end, start, true
+
+		if end == nil {
+			spec.SeekKey = bytes.Repeat([]byte{0xFF}, maxKeyLen)
+			copy(spec.SeekKey, prefix)
+			spec.InclusiveSeek = true
+		}
+
+		return spec
+	}
+
+	spec.SeekKey, spec.EndKey, spec.InclusiveSeek = start, end, true
+
+	return spec
+}
+
+const indexScanContinuationVersion byte = 1
+
+// encodeIndexScanContinuation returns an opaque token locating an entry of an index scanned in a given order
+func encodeIndexScanContinuation(index string, desc bool, entrySuffix []byte) string {
+	indexHash := sha256.Sum256([]byte(index))
+
+	b := make([]byte, 1+1+8+len(entrySuffix))
+	b[0] = indexScanContinuationVersion
+	if desc {
+		b[1] = 1
+	}
+	copy(b[2:], indexHash[:8])
+	copy(b[2+8:], entrySuffix)
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeIndexScanContinuation(token string, index string, desc bool) ([]byte, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(b) < 1+1+8+2 || b[0] != indexScanContinuationVersion {
+		return nil, ErrInvalidContinuation
+	}
+
+	indexHash := sha256.Sum256([]byte(index))
+
+	if (b[1] == 1) != desc || !bytes.Equal(b[2:2+8], indexHash[:8]) {
+		return nil, ErrInvalidContinuation
+	}
+
+	return b[2+8:], nil
+}
+
+// loadSecondaryIndexes rebuilds the declared indexes from the latest declaration of each name
+func (d *db) loadSecondaryIndexes() error {
+	lastTxID, _ := d.st.Alh()
+
+	err := d.st.WaitForIndexingUpto(lastTxID, nil)
+	if err != nil {
+		return err
+	}
+
+	snap, err := d.st.SnapshotSince(lastTxID)
+	if err != nil {
+		return err
+	}
+	defer snap.Close()
+
+	r, err := snap.NewKeyReader(&store.KeyReaderSpec{Prefix: []byte{SecondaryIndexKeyPrefix, secondaryIndexDefinition}})
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	for {
+		key, val, tx, _, err := r.Read()
+		if err == store.ErrNoMoreEntries {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		v, err := val.Resolve()
+		if err != nil {
+			return err
+		}
+
+		def, active, err := DecodeSecondaryIndex(string(key[2:]), v)
+		if err != nil {
+			return err
+		}
+
+		if !active {
+			continue
+		}
+
+		idx, err := newSecondaryIndex(def)
+		if err != nil {
+			return err
+		}
+
+		def.Tx = tx
+		d.secondaryIndexes[def.Name] = idx
+	}
+}
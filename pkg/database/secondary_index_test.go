@@ -0,0 +1,259 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package database
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func indexScanKeys(t *testing.T, db DB, req *schema.IndexScanRequest) []string {
+	list, err := db.IndexScan(context.Background(), req)
+	require.NoError(t, err)
+
+	keys := make([]string, len(list.Entries))
+	for i, e := range list.Entries {
+		keys[i] = string(e.Entry.Key)
+	}
+
+	return keys
+}
+
+func TestSecondaryIndexes(t *testing.T) {
+	rootPath := "data_" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	defer os.RemoveAll(rootPath)
+
+	options := DefaultOption().WithDbRootPath(rootPath).WithDbName("db")
+
+	db, err := NewDb(options, nil, logger.NewSimpleLogger("immudb ", os.Stderr))
+	require.NoError(t, err)
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{
+		{Key: []byte("orders:1"), Value: []byte(`{"status":"open","total":10}`)},
+		{Key: []byte("orders:2"), Value: []byte(`{"status":"closed","total":20}`)},
+		{Key: []byte("users:1"), Value: []byte(`{"status":"open"}`)},
+	}})
+	require.NoError(t, err)
+
+	// values written before the index is declared are indexed as well
+	idx, err := db.CreateSecondaryIndex(&schema.SecondaryIndex{Name: "status", Prefix: []byte("orders:"), JsonPath: "$.status"})
+	require.NoError(t, err)
+	require.NotZero(t, idx.Tx)
+
+	_, err = db.CreateSecondaryIndex(&schema.SecondaryIndex{Name: "status", JsonPath: "$.total"})
+	require.Equal(t, ErrSecondaryIndexAlreadyExists, err)
+
+	require.Equal(t, []string{"orders:1"}, indexScanKeys(t, db, &schema.IndexScanRequest{Index: "status", Value: []byte("open")}))
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("orders:3"), Value: []byte(`{"status":"open","total":30}`)}}})
+	require.NoError(t, err)
+
+	_, err = db.ExecAll(&schema.ExecAllRequest{Operations: []*schema.Op{
+		{Operation: &schema.Op_Kv{Kv: &schema.KeyValue{Key: []byte("orders:4"), Value: []byte(`{"status":"open"}`)}}},
+	}})
+	require.NoError(t, err)
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("orders:5"), Value: []byte(`{"status":"new"}`)}}})
+	require.NoError(t, err)
+
+	_, err = db.SetIf(&schema.SetIfRequest{
+		KV:            &schema.KeyValue{Key: []byte("orders:5"), Value: []byte(`{"status":"pending"}`)},
+		ExpectedValue: []byte(`{"status":"new"}`),
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"orders:1", "orders:3", "orders:4"}, indexScanKeys(t, db, &schema.IndexScanRequest{Index: "status", Value: []byte("open")}))
+
+	// entries of values no longer held by their keys are skipped
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("orders:1"), Value: []byte(`{"status":"closed"}`)}}})
+	require.NoError(t, err)
+
+	_, err = db.Delete(&schema.DeleteKeysRequest{Keys: [][]byte{[]byte("orders:4")}})
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"orders:3"}, indexScanKeys(t, db, &schema.IndexScanRequest{Index: "status", Value: []byte("open")}))
+	require.Equal(t, []string{"orders:1", "orders:2"}, indexScanKeys(t, db, &schema.IndexScanRequest{Index: "status", Value: []byte("closed")}))
+
+	list, err := db.IndexScan(context.Background(), &schema.IndexScanRequest{Index: "status", MinValue: []byte("open"), MaxValue: []byte("pending")})
+	require.NoError(t, err)
+	require.Len(t, list.Entries, 2)
+	require.Equal(t, []byte("open"), list.Entries[0].Value)
+	require.Equal(t, []byte("pending"), list.Entries[1].Value)
+	require.Equal(t, []byte(`{"status":"pending"}`), list.Entries[1].Entry.Value)
+
+	require.Equal(t, []string{"orders:5", "orders:3", "orders:2", "orders:1"}, indexScanKeys(t, db, &schema.IndexScanRequest{Index: "status", Desc: true}))
+	require.Equal(t, []string{"orders:3", "orders:2", "orders:1"}, indexScanKeys(t, db, &schema.IndexScanRequest{Index: "status", MaxValue: []byte("open"), Desc: true}))
+
+	// scans are resumed from the continuation of the previous page
+	for _, desc := range []bool{false, true} {
+		var keys []string
+
+		req := &schema.IndexScanRequest{Index: "status", Limit: 1, Desc: desc}
+
+		for {
+			page, err := db.IndexScan(context.Background(), req)
+			require.NoError(t, err)
+
+			for _, e := range page.Entries {
+				keys = append(keys, string(e.Entry.Key))
+			}
+
+			if page.Continuation == "" {
+				break
+			}
+			req.Continuation = page.Continuation
+		}
+
+		require.Equal(t, indexScanKeys(t, db, &schema.IndexScanRequest{Index: "status", Desc: desc}), keys)
+	}
+
+	_, err = db.IndexScan(context.Background(), &schema.IndexScanRequest{Index: "status", Continuation: "invalid"})
+	require.Equal(t, ErrInvalidContinuation, err)
+
+	// fixed ranges of bytes can be indexed as well, including counters
+	_, err = db.CreateSecondaryIndex(&schema.SecondaryIndex{Name: "region", Prefix: []byte("codes:"), Offset: 2, Length: 2})
+	require.NoError(t, err)
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{
+		{Key: []byte("codes:1"), Value: []byte("xxEU01")},
+		{Key: []byte("codes:2"), Value: []byte("xxUS02")},
+		{Key: []byte("codes:3"), Value: []byte("x")},
+	}})
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"codes:1"}, indexScanKeys(t, db, &schema.IndexScanRequest{Index: "region", Value: []byte("EU")}))
+
+	_, err = db.CreateSecondaryIndex(&schema.SecondaryIndex{Name: "counter", Prefix: []byte("hits:"), Length: 1})
+	require.NoError(t, err)
+
+	_, err = db.Increment(&schema.IncrementRequest{Key: []byte("hits:home"), Delta: 1})
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"hits:home"}, indexScanKeys(t, db, &schema.IndexScanRequest{Index: "counter", Value: encodeCounter(1)}))
+
+	_, err = db.CreateSecondaryIndex(&schema.SecondaryIndex{Name: "invalid"})
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = db.CreateSecondaryIndex(&schema.SecondaryIndex{Name: "invalid", JsonPath: "a", Length: 1})
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = db.IndexScan(context.Background(), &schema.IndexScanRequest{Index: "status", Value: []byte("a"), MinValue: []byte("a")})
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = db.IndexScan(context.Background(), &schema.IndexScanRequest{Index: "unknown"})
+	require.Equal(t, ErrSecondaryIndexNotFound, err)
+
+	err = db.DropSecondaryIndex("region")
+	require.NoError(t, err)
+
+	err = db.DropSecondaryIndex("region")
+	require.Equal(t, ErrSecondaryIndexNotFound, err)
+
+	_, err = db.IndexScan(context.Background(), &schema.IndexScanRequest{Index: "region"})
+	require.Equal(t, ErrSecondaryIndexNotFound, err)
+
+	err = db.Close()
+	require.NoError(t, err)
+
+	// declared indexes are loaded again along with the database
+	db, err = OpenDb(options, nil, logger.NewSimpleLogger("immudb ", os.Stderr))
+	require.NoError(t, err)
+	defer db.Close()
+
+	indexes := db.ListSecondaryIndexes().Indexes
+	require.Len(t, indexes, 2)
+	require.Equal(t, "counter", indexes[0].Name)
+	require.Equal(t, "status", indexes[1].Name)
+	require.Equal(t, "$.status", indexes[1].JsonPath)
+	require.Equal(t, []byte("orders:"), indexes[1].Prefix)
+	require.Equal(t, idx.Tx, indexes[1].Tx)
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("orders:6"), Value: []byte(`{"status":"open"}`)}}})
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"orders:3", "orders:6"}, indexScanKeys(t, db, &schema.IndexScanRequest{Index: "status", Value: []byte("open")}))
+}
+
+func TestSecondaryIndexCreationWhileIndexingLags(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	var keys []string
+
+	// values written without waiting for them to be indexed are indexed as well
+	for i := 0; i < 100; i++ {
+		key := "orders:" + strconv.Itoa(1000+i)
+		keys = append(keys, key)
+
+		_, err := db.Set(&schema.SetRequest{
+			KVs:    []*schema.KeyValue{{Key: []byte(key), Value: []byte(`{"status":"open"}`)}},
+			NoWait: true,
+		})
+		require.NoError(t, err)
+	}
+
+	_, err := db.CreateSecondaryIndex(&schema.SecondaryIndex{Name: "status", Prefix: []byte("orders:"), JsonPath: "$.status"})
+	require.NoError(t, err)
+
+	require.Equal(t, keys, indexScanKeys(t, db, &schema.IndexScanRequest{Index: "status", Value: []byte("open")}))
+}
+
+func TestMaxSecondaryIndexes(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	for i := 0; i < MaxSecondaryIndexes; i++ {
+		_, err := db.CreateSecondaryIndex(&schema.SecondaryIndex{Name: "idx" + strconv.Itoa(i), Prefix: []byte("orders:"), Length: 1})
+		require.NoError(t, err)
+	}
+
+	_, err := db.CreateSecondaryIndex(&schema.SecondaryIndex{Name: "exceeding", Prefix: []byte("orders:"), Length: 1})
+	require.Equal(t, ErrMaxSecondaryIndexesExceeded, err)
+
+	err = db.DropSecondaryIndex("idx0")
+	require.NoError(t, err)
+
+	_, err = db.CreateSecondaryIndex(&schema.SecondaryIndex{Name: "exceeding", Prefix: []byte("orders:"), Length: 1})
+	require.NoError(t, err)
+}
+
+func TestIndexValueEncoding(t *testing.T) {
+	values := [][]byte{{}, {0}, {0, 0}, {0, 1}, []byte("a"), []byte("a\x00"), []byte("a\x00b"), []byte("ab"), {0xFF}}
+
+	for i, v := range values {
+		k := append(appendIndexValue(nil, v), []byte("key")...)
+
+		value, key, err := splitIndexEntry(k)
+		require.NoError(t, err)
+		require.Equal(t, []byte("key"), key)
+		require.Equal(t, len(v), len(value))
+
+		if i > 0 {
+			require.Less(t, string(appendIndexValue(nil, values[i-1])), string(appendIndexValue(nil, v)))
+		}
+	}
+
+	_, _, err := splitIndexEntry([]byte("novalue"))
+	require.Error(t, err)
+}
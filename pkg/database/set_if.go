@@ -0,0 +1,121 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"bytes"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+)
+
+// SetIf sets the key only if it currently holds the expected value or, when ExpectedTx is given, if its current
+// value was written in that transaction. The condition is evaluated within the commit, so no other write can get
+// in between. ErrPreconditionFailed is returned when it doesn't hold, including when the key doesn't exist
+func (d *db) SetIf(req *schema.SetIfRequest) (*schema.TxMetadata, error) {
+	if req == nil || req.KV == nil || len(req.KV.Key) == 0 {
+		return nil, ErrIllegalArguments
+	}
+
+	if d.options.replica {
+		return nil, ErrIsReplica
+	}
+
+	err := d.keyRules.validate(req.KV.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	err = d.approvalRules.validate(req.KV.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	kv, err := d.encodeKV(req.KV)
+	if err != nil {
+		return nil, err
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.secondaryIndexesMutex.RLock()
+	defer d.secondaryIndexesMutex.RUnlock()
+
+	lastTxID, _ := d.st.Alh()
+	err = d.st.WaitForIndexingUpto(lastTxID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	callback := func(txID uint64, index store.KeyIndex) ([]*store.KV, error) {
+		err := d.checkSetIf(req, index)
+		if err != nil {
+			return nil, err
+		}
+
+		err = d.checkWORMVersions([][]byte{req.KV.Key}, index)
+		if err != nil {
+			return nil, err
+		}
+
+		entries := append([]*store.KV{kv}, d.secondaryIndexEntries(req.KV.Key, req.KV.Value)...)
+
+		if req.Attribution != nil {
+			entries = append(entries, EncodeAttribution(req.Attribution))
+		}
+
+		return entries, nil
+	}
+
+	txmd, err := d.st.CommitWith(callback, !req.NoWait)
+	if err != nil {
+		return nil, err
+	}
+
+	d.prefixStats.write(req.KV.Key, len(req.KV.Key)+len(req.KV.Value))
+
+	return schema.TxMetatadaTo(txmd), nil
+}
+
+func (d *db) checkSetIf(req *schema.SetIfRequest, index store.KeyIndex) error {
+	e, err := d.getAt(EncodeKey(req.KV.Key), 0, 0, index, d.tx1)
+	if unreadable(err) {
+		return ErrPreconditionFailed
+	}
+	if err != nil {
+		return err
+	}
+
+	if req.ExpectedTx > 0 {
+		tx := e.Tx
+		if e.ReferencedBy != nil {
+			tx = e.ReferencedBy.Tx
+		}
+
+		if tx != req.ExpectedTx {
+			return ErrPreconditionFailed
+		}
+
+		return nil
+	}
+
+	if !bytes.Equal(e.Value, req.ExpectedValue) {
+		return ErrPreconditionFailed
+	}
+
+	return nil
+}
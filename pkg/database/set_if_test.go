@@ -0,0 +1,117 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetIf(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	_, err := db.SetIf(nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = db.SetIf(&schema.SetIfRequest{KV: &schema.KeyValue{}})
+	require.Equal(t, ErrIllegalArguments, err)
+
+	setIf := func(value, expectedValue string, expectedTx uint64) (*schema.TxMetadata, error) {
+		return db.SetIf(&schema.SetIfRequest{
+			KV:            &schema.KeyValue{Key: []byte("key"), Value: []byte(value)},
+			ExpectedValue: []byte(expectedValue),
+			ExpectedTx:    expectedTx,
+		})
+	}
+
+	_, err = setIf("value1", "", 0)
+	require.Equal(t, ErrPreconditionFailed, err)
+
+	txmd, err := db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("key"), Value: []byte("value1")}}})
+	require.NoError(t, err)
+
+	_, err = setIf("value2", "value0", 0)
+	require.Equal(t, ErrPreconditionFailed, err)
+
+	_, err = setIf("value2", "value1", 0)
+	require.NoError(t, err)
+
+	_, err = setIf("value3", "", txmd.Id)
+	require.Equal(t, ErrPreconditionFailed, err)
+
+	item, err := db.Get(&schema.KeyRequest{Key: []byte("key")})
+	require.NoError(t, err)
+	require.Equal(t, []byte("value2"), item.Value)
+
+	txmd, err = setIf("value3", "", item.Tx)
+	require.NoError(t, err)
+
+	item, err = db.Get(&schema.KeyRequest{Key: []byte("key")})
+	require.NoError(t, err)
+	require.Equal(t, []byte("value3"), item.Value)
+	require.Equal(t, txmd.Id, item.Tx)
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("expired"), Value: []byte("value"), ExpiresAt: time.Now().Add(-time.Minute).Unix()}}})
+	require.NoError(t, err)
+
+	_, err = db.SetIf(&schema.SetIfRequest{
+		KV:            &schema.KeyValue{Key: []byte("expired"), Value: []byte("value")},
+		ExpectedValue: []byte("value"),
+	})
+	require.Equal(t, ErrPreconditionFailed, err)
+}
+
+func TestSetIfConcurrently(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	_, err := db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("key"), Value: []byte("initial")}}})
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	succeeded := 0
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			_, err := db.SetIf(&schema.SetIfRequest{
+				KV:            &schema.KeyValue{Key: []byte("key"), Value: []byte{byte(i)}},
+				ExpectedValue: []byte("initial"),
+			})
+			if err == ErrPreconditionFailed {
+				return
+			}
+			require.NoError(t, err)
+
+			mutex.Lock()
+			succeeded++
+			mutex.Unlock()
+		}(i)
+	}
+
+	wg.Wait()
+
+	require.Equal(t, 1, succeeded)
+}
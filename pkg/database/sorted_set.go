@@ -16,6 +16,10 @@ limitations under the License.
 package database
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/binary"
 	"math"
 
@@ -41,6 +45,10 @@ func (d *db) ZAdd(req *schema.ZAddRequest) (*schema.TxMetadata, error) {
 		return nil, store.ErrIllegalArguments
 	}
 
+	if d.options.replica {
+		return nil, ErrIsReplica
+	}
+
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
 
@@ -61,13 +69,40 @@ func (d *db) ZAdd(req *schema.ZAddRequest) (*schema.TxMetadata, error) {
 		return nil, ErrReferencedKeyCannotBeAReference
 	}
 
-	meta, err := d.st.Commit([]*store.KV{EncodeZAdd(req.Set, req.Score, key, req.AtTx)}, !req.NoWait)
+	kv := EncodeZAdd(req.Set, req.Score, key, req.AtTx)
+
+	refEntries, err := d.referenceIndexEntries(kv)
+	if err != nil {
+		return nil, err
+	}
+
+	meta, err := d.st.Commit(append([]*store.KV{kv}, refEntries...), !req.NoWait)
 
 	return schema.TxMetatadaTo(meta), err
 }
 
+// ZAddMulti adds many entries, to one or more sorted sets, within a single transaction. Entries are validated
+// as in ZAdd and written as the ZAdd operations of ExecAll
+func (d *db) ZAddMulti(req *schema.ZAddMultiRequest) (*schema.TxMetadata, error) {
+	if req == nil || len(req.Entries) == 0 {
+		return nil, store.ErrIllegalArguments
+	}
+
+	ops := make([]*schema.Op, len(req.Entries))
+
+	for i, e := range req.Entries {
+		if e == nil || (e.AtTx == 0 && e.BoundRef) || (e.AtTx > 0 && !e.BoundRef) {
+			return nil, store.ErrIllegalArguments
+		}
+
+		ops[i] = &schema.Op{Operation: &schema.Op_ZAdd{ZAdd: e}}
+	}
+
+	return d.ExecAll(&schema.ExecAllRequest{Operations: ops, NoWait: req.NoWait})
+}
+
 // ZScan ...
-func (d *db) ZScan(req *schema.ZScanRequest) (*schema.ZEntries, error) {
+func (d *db) ZScan(ctx context.Context, req *schema.ZScanRequest) (*schema.ZEntries, error) {
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
 
@@ -85,33 +120,572 @@ func (d *db) ZScan(req *schema.ZScanRequest) (*schema.ZEntries, error) {
 		limit = MaxKeyScanLimit
 	}
 
-	prefix := make([]byte, 1+setLenLen+len(req.Set))
-	prefix[0] = SortedSetKeyPrefix
-	binary.BigEndian.PutUint64(prefix[1:], uint64(len(req.Set)))
-	copy(prefix[1+setLenLen:], req.Set)
+	if req.Continuation != "" && len(req.SeekKey) > 0 {
+		return nil, store.ErrIllegalArguments
+	}
+
+	prefix := sortedSetPrefix(req.Set)
 
 	var seekKey []byte
+	inclusiveSeek := req.InclusiveSeek
 
-	if len(req.SeekKey) == 0 {
-		seekKey = make([]byte, len(prefix)+scoreLen)
+	if req.Continuation != "" {
+		zKeySuffix, err := decodeZScanContinuation(req.Continuation, req.Set, req.Desc)
+		if err != nil {
+			return nil, err
+		}
+
+		seekKey = make([]byte, len(prefix)+len(zKeySuffix))
 		copy(seekKey, prefix)
-		// here we compose the offset if Min score filter is provided only if is not reversed order
-		if req.MinScore != nil && !req.Desc {
-			binary.BigEndian.PutUint64(seekKey[len(prefix):], math.Float64bits(req.MinScore.Score))
+		copy(seekKey[len(prefix):], zKeySuffix)
+		inclusiveSeek = false
+	} else if len(req.SeekKey) > 0 {
+		seekKey = WrapZAddReferenceAt(req.Set, req.SeekScore, EncodeKey(req.SeekKey), req.SeekAtTx)
+	}
+
+	currTxID, _ := d.st.Alh()
+
+	if req.AtTx > currTxID {
+		return nil, store.ErrIllegalArguments
+	}
+
+	waitUntilTx := req.SinceTx
+
+	if waitUntilTx == 0 {
+		waitUntilTx = currTxID
+	}
+
+	if waitUntilTx < req.AtTx {
+		waitUntilTx = req.AtTx
+	}
+
+	if !req.NoWait || req.AtTx > 0 {
+		// a pinned scan requires the index to include the requested transaction
+		err := d.st.WaitForIndexingUpto(waitUntilTx, ctx.Done())
+		if err != nil {
+			return nil, err
 		}
-		// here we compose the offset if Max score filter is provided only if is reversed order
-		if req.MaxScore != nil && req.Desc {
-			binary.BigEndian.PutUint64(seekKey[len(prefix):], math.Float64bits(req.MaxScore.Score))
+	}
+
+	snap, err := d.st.SnapshotSince(waitUntilTx)
+	if err != nil {
+		return nil, err
+	}
+	defer snap.Close()
+
+	var entries []*schema.ZEntry
+	i := uint64(0)
+
+	ranges := zScoreRanges(prefix, req.MinScore, req.MaxScore)
+	if req.Desc {
+		for l, r := 0, len(ranges)-1; l < r; l, r = l+1, r-1 {
+			ranges[l], ranges[r] = ranges[r], ranges[l]
 		}
-	} else {
-		seekKey = make([]byte, len(prefix)+scoreLen+keyLenLen+1+len(req.SeekKey)+txIDLen)
-		copy(seekKey, prefix)
-		binary.BigEndian.PutUint64(seekKey[len(prefix):], math.Float64bits(req.SeekScore))
-		binary.BigEndian.PutUint64(seekKey[len(prefix)+scoreLen:], uint64(1+len(req.SeekKey)))
-		copy(seekKey[len(prefix)+scoreLen+keyLenLen:], EncodeKey(req.SeekKey))
-		binary.BigEndian.PutUint64(seekKey[len(prefix)+scoreLen+keyLenLen+1+len(req.SeekKey):], req.SeekAtTx)
 	}
 
+	// readRange appends the entries of a range to the list, returning a continuation when the limit is reached
+	readRange := func(spec *store.KeyReaderSpec) (string, error) {
+		r, err := snap.NewKeyReader(spec)
+		if err != nil {
+			return "", err
+		}
+		defer r.Close()
+
+		for {
+			if ctx.Err() != nil {
+				return "", ErrOperationCancelled
+			}
+
+			var zKey []byte
+			var val *store.ValueRef
+
+			if req.AtTx > 0 {
+				zKey, val, _, err = r.ReadAsBefore(req.AtTx + 1)
+			} else {
+				zKey, val, _, _, err = r.Read()
+			}
+			if err == store.ErrNoMoreEntries {
+				return "", nil
+			}
+			if err != nil {
+				return "", err
+			}
+
+			// entries removed with ZRem are skipped
+			if val.Len() > 0 {
+				continue
+			}
+
+			// zKey = [1+setLenLen+len(req.Set)+scoreLen+keyLenLen+1+len(req.Key)+txIDLen]
+			scoreOff := len(prefix)
+			scoreB := binary.BigEndian.Uint64(zKey[scoreOff:])
+			score := math.Float64frombits(scoreB)
+
+			// Guard to ensure that score match the filter range if filter is provided
+			if req.MinScore != nil && score < req.MinScore.Score {
+				continue
+			}
+			if req.MaxScore != nil && score > req.MaxScore.Score {
+				continue
+			}
+
+			key, atTx, err := zKeyEntry(zKey, scoreOff+scoreLen)
+			if err != nil {
+				return "", err
+			}
+
+			if !inLexRange(key[1:], req.MinKey, req.MaxKey) {
+				continue
+			}
+
+			var e *schema.Entry
+
+			if !req.NoValues {
+				if req.AtTx > 0 && atTx == 0 {
+					// members not bound to a version of the key are resolved as of the scanned tx
+					e, err = d.getAsOfIn(snap, key, req.AtTx)
+				} else {
+					e, err = d.getAt(key, atTx, 0, snap, d.tx1)
+				}
+				if unreadable(err) {
+					continue
+				}
+				if err != nil {
+					return "", err
+				}
+			} else {
+				readable, err := d.zMemberReadable(snap, key, atTx, req.AtTx)
+				if err != nil {
+					return "", err
+				}
+				if !readable {
+					continue
+				}
+			}
+
+			zentry := &schema.ZEntry{
+				Set:   req.Set,
+				Key:   key[1:],
+				Entry: e,
+				Score: score,
+				AtTx:  atTx,
+			}
+
+			entries = append(entries, zentry)
+			if i++; i == limit {
+				return encodeZScanContinuation(req.Set, req.Desc, zKey[len(prefix):]), nil
+			}
+		}
+	}
+
+	for _, rng := range ranges {
+		spec := rng.readerSpec(prefix, seekKey, inclusiveSeek, req.Desc)
+		if spec == nil {
+			continue
+		}
+
+		continuation, err := readRange(spec)
+		if err != nil {
+			return nil, err
+		}
+
+		if continuation != "" {
+			return &schema.ZEntries{Entries: entries, Continuation: continuation}, nil
+		}
+	}
+
+	list := &schema.ZEntries{
+		Entries: entries,
+	}
+
+	return list, nil
+}
+
+// zScoreRange is a range of index keys of a sorted set, from start inclusive to end exclusive,
+// a nil end meaning the end of the set
+type zScoreRange struct {
+	start []byte
+	end   []byte
+}
+
+// zScoreRanges returns the ranges of index keys holding the scores within the bounds, in index order.
+// Entries are indexed by the IEEE 754 representation of their scores, thus negative scores come after
+// non-negative ones, in reverse numeric order, and bounds including both need two ranges
+func zScoreRanges(prefix []byte, minScore, maxScore *schema.Score) []*zScoreRange {
+	if minScore == nil && maxScore == nil {
+		return []*zScoreRange{{start: prefix}}
+	}
+
+	// NaN bounds don't filter any entry
+	min, max := math.Inf(-1), math.Inf(1)
+
+	if minScore != nil && !math.IsNaN(minScore.Score) {
+		min = minScore.Score
+	}
+	if maxScore != nil && !math.IsNaN(maxScore.Score) {
+		max = maxScore.Score
+	}
+
+	var ranges []*zScoreRange
+
+	if max >= 0 && min <= max {
+		from := uint64(0)
+		if min > 0 {
+			from = math.Float64bits(min)
+		}
+		ranges = append(ranges, newZScoreRange(prefix, from, math.Float64bits(math.Abs(max))))
+	}
+
+	if min <= 0 && min <= max {
+		from := math.Float64bits(math.Copysign(0, -1))
+		if max < 0 {
+			from = math.Float64bits(max)
+		}
+		ranges = append(ranges, newZScoreRange(prefix, from, math.Float64bits(-math.Abs(min))))
+	}
+
+	return ranges
+}
+
+// newZScoreRange returns the range of index keys of entries whose scores representation is between from and to, both inclusive
+func newZScoreRange(prefix []byte, from, to uint64) *zScoreRange {
+	rng := &zScoreRange{
+		start: make([]byte, len(prefix)+scoreLen),
+		end:   make([]byte, len(prefix)+scoreLen),
+	}
+
+	copy(rng.start, prefix)
+	binary.BigEndian.PutUint64(rng.start[len(prefix):], from)
+
+	copy(rng.end, prefix)
+	binary.BigEndian.PutUint64(rng.end[len(prefix):], to+1)
+
+	return rng
+}
+
+// readerSpec returns the spec reading the range from the seek key, if provided, or nil if the range precedes it
+func (rng *zScoreRange) readerSpec(prefix, seekKey []byte, inclusiveSeek, desc bool) *store.KeyReaderSpec {
+	spec := &store.KeyReaderSpec{Prefix: prefix, DescOrder: desc}
+
+	if desc {
+		spec.EndKey = rng.start
+		spec.InclusiveEnd = true
+
+		switch {
+		case seekKey != nil && bytes.Compare(seekKey, rng.start) < 0:
+			return nil
+		case seekKey != nil && (rng.end == nil || bytes.Compare(seekKey, rng.end) < 0):
+			spec.SeekKey = seekKey
+			spec.InclusiveSeek = inclusiveSeek
+		case rng.end != nil:
+			spec.SeekKey = rng.end
+		default:
+			spec.SeekKey = make([]byte, len(prefix)+scoreLen+keyLenLen)
+			copy(spec.SeekKey, prefix)
+			for i := len(prefix); i < len(spec.SeekKey); i++ {
+				spec.SeekKey[i] = 0xFF
+			}
+			spec.InclusiveSeek = true
+		}
+
+		return spec
+	}
+
+	spec.EndKey = rng.end
+
+	switch {
+	case seekKey != nil && rng.end != nil && bytes.Compare(seekKey, rng.end) >= 0:
+		return nil
+	case seekKey != nil && bytes.Compare(seekKey, rng.start) >= 0:
+		spec.SeekKey = seekKey
+		spec.InclusiveSeek = inclusiveSeek
+	default:
+		spec.SeekKey = rng.start
+		spec.InclusiveSeek = true
+	}
+
+	return spec
+}
+
+const zScanContinuationVersion byte = 1
+
+// encodeZScanContinuation returns an opaque token locating an entry of a sorted set scanned in a given order.
+// The token holds the entry key within the set, so resumed scans are not affected by entries added meanwhile
+func encodeZScanContinuation(set []byte, desc bool, zKeySuffix []byte) string {
+	setHash := sha256.Sum256(set)
+
+	b := make([]byte, 1+1+8+len(zKeySuffix))
+	b[0] = zScanContinuationVersion
+	if desc {
+		b[1] = 1
+	}
+	copy(b[2:], setHash[:8])
+	copy(b[2+8:], zKeySuffix)
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeZScanContinuation(token string, set []byte, desc bool) ([]byte, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(b) < 1+1+8+scoreLen+keyLenLen+txIDLen || b[0] != zScanContinuationVersion {
+		return nil, ErrInvalidContinuation
+	}
+
+	setHash := sha256.Sum256(set)
+
+	if (b[1] == 1) != desc || !bytes.Equal(b[2:2+8], setHash[:8]) {
+		return nil, ErrInvalidContinuation
+	}
+
+	return b[2+8:], nil
+}
+
+// ZRem removes a key from a sorted set. Every entry of the key in the set, regardless of its score, is marked as removed
+// in a new transaction so it's skipped by subsequent scans while the removal remains part of the history
+func (d *db) ZRem(req *schema.ZRemRequest) (*schema.TxMetadata, error) {
+	if req == nil || len(req.Set) == 0 || len(req.Key) == 0 {
+		return nil, store.ErrIllegalArguments
+	}
+
+	if d.options.replica {
+		return nil, ErrIsReplica
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	lastTxID, _ := d.st.Alh()
+	err := d.st.WaitForIndexingUpto(lastTxID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	snap, err := d.st.SnapshotSince(lastTxID)
+	if err != nil {
+		return nil, err
+	}
+	defer snap.Close()
+
+	prefix := sortedSetPrefix(req.Set)
+
+	r, err := snap.NewKeyReader(&store.KeyReaderSpec{Prefix: prefix})
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	err = d.checkWORMRemoval()
+	if err != nil {
+		return nil, err
+	}
+
+	// removing a held key from a sorted set would hide it from the set, as a logical deletion
+	err = d.checkLegalHold(req.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	key := EncodeKey(req.Key)
+	member := encodeZMember(key)
+	memberOff := len(prefix) + scoreLen
+
+	var kvs []*store.KV
+
+	for {
+		zKey, val, _, _, err := r.Read()
+		if err == store.ErrNoMoreEntries {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if val.Len() > 0 {
+			continue
+		}
+
+		if !zHasMember(zKey, memberOff, member) {
+			continue
+		}
+
+		score := math.Float64frombits(binary.BigEndian.Uint64(zKey[len(prefix):]))
+		atTx := binary.BigEndian.Uint64(zKey[len(zKey)-txIDLen:])
+
+		kvs = append(kvs, EncodeZRem(req.Set, score, key, atTx))
+	}
+
+	if len(kvs) == 0 {
+		return nil, ErrSortedSetMemberNotFound
+	}
+
+	meta, err := d.st.Commit(kvs, !req.NoWait)
+
+	return schema.TxMetatadaTo(meta), err
+}
+
+// ZIncrBy increments the score of a key in a sorted set. The current score is read and the entry with the new score
+// is written within the same transaction, replacing the entries of the key which are not bound to a given version.
+// A key which is not yet a member of the set is added using the increment as score
+func (d *db) ZIncrBy(req *schema.ZIncrByRequest) (*schema.ZIncrByResult, error) {
+	if req == nil || len(req.Set) == 0 || len(req.Key) == 0 || math.IsNaN(req.Increment) {
+		return nil, store.ErrIllegalArguments
+	}
+
+	if d.options.replica {
+		return nil, ErrIsReplica
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	// previous scores are marked as removed
+	err := d.checkWORMRemoval()
+	if err != nil {
+		return nil, err
+	}
+
+	lastTxID, _ := d.st.Alh()
+	err = d.st.WaitForIndexingUpto(lastTxID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	key := EncodeKey(req.Key)
+
+	refEntry, err := d.getAt(key, 0, 0, d.st, d.tx1)
+	if err != nil {
+		return nil, err
+	}
+	if refEntry.ReferencedBy != nil {
+		return nil, ErrReferencedKeyCannotBeAReference
+	}
+
+	snap, err := d.st.SnapshotSince(lastTxID)
+	if err != nil {
+		return nil, err
+	}
+	defer snap.Close()
+
+	prefix := sortedSetPrefix(req.Set)
+
+	r, err := snap.NewKeyReader(&store.KeyReaderSpec{Prefix: prefix})
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	member := encodeZMember(key)
+	memberOff := len(prefix) + scoreLen
+
+	var scores []float64
+	var score float64
+	var scoreTx uint64
+
+	for {
+		zKey, valLen, tx, err := r.ReadKey()
+		if err == store.ErrNoMoreEntries {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if valLen > 0 {
+			continue
+		}
+
+		if !zHasMember(zKey, memberOff, member) {
+			continue
+		}
+
+		// entries bound to a version of the key are left untouched
+		if binary.BigEndian.Uint64(zKey[len(zKey)-txIDLen:]) != 0 {
+			continue
+		}
+
+		s := math.Float64frombits(binary.BigEndian.Uint64(zKey[len(prefix):]))
+		scores = append(scores, s)
+
+		// the most recently added score is the current one
+		if tx >= scoreTx {
+			score = s
+			scoreTx = tx
+		}
+	}
+
+	newScore := score + req.Increment
+	if math.IsNaN(newScore) {
+		return nil, store.ErrIllegalArguments
+	}
+
+	kvs := []*store.KV{EncodeZAdd(req.Set, newScore, key, 0)}
+
+	for _, s := range scores {
+		if math.Float64bits(s) != math.Float64bits(newScore) {
+			kvs = append(kvs, EncodeZRem(req.Set, s, key, 0))
+		}
+	}
+
+	refEntries, err := d.referenceIndexEntries(kvs...)
+	if err != nil {
+		return nil, err
+	}
+
+	kvs = append(kvs, refEntries...)
+
+	meta, err := d.st.Commit(kvs, !req.NoWait)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schema.ZIncrByResult{
+		TxMetadata: schema.TxMetatadaTo(meta),
+		Score:      newScore,
+	}, nil
+}
+
+// ZCard returns the number of entries of a sorted set which were not removed. As for ZScan, a key added with
+// different scores is counted once per score. As for ZCount, members whose key expired or was deleted are not counted.
+// The set is read in full, reading the value of the key of each entry: the cost is proportional to the number of
+// entries ever added to the set and not removed. No counter is kept instead, since entries stop being counted as
+// their keys expire or are deleted, without the set being written
+func (d *db) ZCard(ctx context.Context, req *schema.ZCardRequest) (*schema.EntryCount, error) {
+	if req == nil {
+		return nil, store.ErrIllegalArguments
+	}
+
+	return d.ZCount(ctx, &schema.ZCountRequest{
+		Set:     req.Set,
+		SinceTx: req.SinceTx,
+		NoWait:  req.NoWait,
+	})
+}
+
+// ZCount returns the number of entries of a sorted set whose score is within the provided bounds, both inclusive.
+// Members whose key expired or was deleted are not counted, thus the value of the key of each entry is read without
+// being resolved
+func (d *db) ZCount(ctx context.Context, req *schema.ZCountRequest) (*schema.EntryCount, error) {
+	if req == nil || len(req.Set) == 0 {
+		return nil, store.ErrIllegalArguments
+	}
+
+	minScore := math.Inf(-1)
+	if req.MinScore != nil {
+		minScore = req.MinScore.Score
+	}
+
+	maxScore := math.Inf(1)
+	if req.MaxScore != nil {
+		maxScore = req.MaxScore.Score
+	}
+
+	if math.IsNaN(minScore) || math.IsNaN(maxScore) {
+		return nil, store.ErrIllegalArguments
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
 	waitUntilTx := req.SinceTx
 
 	if waitUntilTx == 0 {
@@ -119,7 +693,7 @@ func (d *db) ZScan(req *schema.ZScanRequest) (*schema.ZEntries, error) {
 	}
 
 	if !req.NoWait {
-		err := d.st.WaitForIndexingUpto(waitUntilTx, nil)
+		err := d.st.WaitForIndexingUpto(waitUntilTx, ctx.Done())
 		if err != nil {
 			return nil, err
 		}
@@ -131,70 +705,341 @@ func (d *db) ZScan(req *schema.ZScanRequest) (*schema.ZEntries, error) {
 	}
 	defer snap.Close()
 
-	r, err := snap.NewKeyReader(
-		&store.KeyReaderSpec{
-			SeekKey:       seekKey,
-			Prefix:        prefix,
-			InclusiveSeek: req.InclusiveSeek,
-			DescOrder:     req.Desc,
+	prefix := sortedSetPrefix(req.Set)
+
+	var count uint64
+
+	// scores are indexed by their IEEE 754 representation: non-negative scores come first in ascending order,
+	// followed by negative scores in descending order. Each part is read from its lower bound until the upper one
+	if maxScore >= 0 {
+		from := math.Max(minScore, 0)
+
+		n, err := d.zCountFrom(ctx, snap, prefix, from, func(score float64) bool {
+			return !math.Signbit(score) && score <= maxScore
+		})
+		if err != nil {
+			return nil, err
+		}
+		count += n
+	}
+
+	if minScore <= 0 {
+		from := math.Copysign(0, -1)
+		if maxScore < 0 {
+			from = maxScore
+		}
+
+		n, err := d.zCountFrom(ctx, snap, prefix, from, func(score float64) bool {
+			return math.Signbit(score) && score >= minScore
 		})
+		if err != nil {
+			return nil, err
+		}
+		count += n
+	}
+
+	return &schema.EntryCount{Count: count}, nil
+}
+
+// zCountFrom counts the entries of a sorted set from score until inRange doesn't hold
+func (d *db) zCountFrom(ctx context.Context, snap *store.Snapshot, prefix []byte, score float64, inRange func(score float64) bool) (uint64, error) {
+	seekKey := make([]byte, len(prefix)+scoreLen)
+	copy(seekKey, prefix)
+	binary.BigEndian.PutUint64(seekKey[len(prefix):], math.Float64bits(score))
+
+	r, err := snap.NewKeyReader(&store.KeyReaderSpec{
+		SeekKey:       seekKey,
+		Prefix:        prefix,
+		InclusiveSeek: true,
+	})
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
 	defer r.Close()
 
-	var entries []*schema.ZEntry
-	i := uint64(0)
+	var count uint64
 
 	for {
-		zKey, _, _, _, err := r.Read()
+		if ctx.Err() != nil {
+			return 0, ErrOperationCancelled
+		}
+
+		zKey, valLen, _, err := r.ReadKey()
 		if err == store.ErrNoMoreEntries {
-			break
+			return count, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		if !inRange(math.Float64frombits(binary.BigEndian.Uint64(zKey[len(prefix):]))) {
+			return count, nil
+		}
+
+		// entries removed with ZRem are not counted
+		if valLen > 0 {
+			continue
+		}
+
+		key, atTx, err := zKeyEntry(zKey, len(prefix)+scoreLen)
+		if err != nil {
+			return 0, err
+		}
+
+		readable, err := d.zMemberReadable(snap, key, atTx, 0)
+		if err != nil {
+			return 0, err
 		}
+
+		if readable {
+			count++
+		}
+	}
+}
+
+// ZRank returns the position, starting from zero, of a key within a sorted set ordered by score. Entries sharing
+// the same score are ordered by key. When the key was added more than once, its first entry in the
+// requested order is ranked. Members whose key expired or was deleted are not ranked. The set is read in a single
+// pass, in the requested order, which stops once the key is found: the cost is proportional to the rank, and to the
+// size of the set for missing keys, as the value of the key of each entry read is checked
+func (d *db) ZRank(ctx context.Context, req *schema.ZRankRequest) (*schema.ZRankResult, error) {
+	if req == nil || len(req.Set) == 0 || len(req.Key) == 0 {
+		return nil, store.ErrIllegalArguments
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	waitUntilTx := req.SinceTx
+
+	if waitUntilTx == 0 {
+		waitUntilTx, _ = d.st.Alh()
+	}
+
+	if !req.NoWait {
+		err := d.st.WaitForIndexingUpto(waitUntilTx, ctx.Done())
 		if err != nil {
 			return nil, err
 		}
+	}
 
-		// zKey = [1+setLenLen+len(req.Set)+scoreLen+keyLenLen+1+len(req.Key)+txIDLen]
-		scoreOff := 1 + setLenLen + len(req.Set)
-		scoreB := binary.BigEndian.Uint64(zKey[scoreOff:])
-		score := math.Float64frombits(scoreB)
+	snap, err := d.st.SnapshotSince(waitUntilTx)
+	if err != nil {
+		return nil, err
+	}
+	defer snap.Close()
+
+	prefix := sortedSetPrefix(req.Set)
+	key := EncodeKey(req.Key)
+
+	// scores are indexed by their IEEE 754 representation: non-negative scores come first in ascending order,
+	// followed by negative scores in descending order. Negative scores are thus read in the opposite index order
+	// than non-negative ones, which also reverses the order of the entries sharing a score
+	nonNegative := newZScoreRange(prefix, 0, math.MaxInt64)
+	negative := &zScoreRange{start: make([]byte, len(prefix)+scoreLen)}
+	copy(negative.start, prefix)
+	binary.BigEndian.PutUint64(negative.start[len(prefix):], math.Float64bits(math.Copysign(0, -1)))
+
+	specs := []*store.KeyReaderSpec{
+		negative.readerSpec(prefix, nil, false, true),
+		nonNegative.readerSpec(prefix, nil, false, false),
+	}
+	reversedTies := []bool{true, false}
+
+	if req.Desc {
+		specs = []*store.KeyReaderSpec{
+			nonNegative.readerSpec(prefix, nil, false, true),
+			negative.readerSpec(prefix, nil, false, false),
+		}
+		reversedTies = []bool{false, true}
+	}
+
+	var rank uint64
+
+	for i, spec := range specs {
+		member, preceding, err := d.zRankIn(ctx, snap, spec, key, reversedTies[i])
+		if err != nil {
+			return nil, err
+		}
 
-		// Guard to ensure that score match the filter range if filter is provided
-		if req.MinScore != nil && score < req.MinScore.Score {
+		rank += preceding
+
+		if member != nil {
+			return &schema.ZRankResult{
+				Rank:  rank,
+				Score: math.Float64frombits(binary.BigEndian.Uint64(member[len(prefix):])),
+				AtTx:  binary.BigEndian.Uint64(member[len(member)-txIDLen:]),
+			}, nil
+		}
+	}
+
+	return nil, ErrSortedSetMemberNotFound
+}
+
+// zRankIn reads the entries of a sorted set selected by spec until the first entry of key in the requested order
+// is found, returning it along with the number of entries preceding it. When the reader returns the entries sharing
+// a score in the opposite order than requested (reversedTies), the whole score of the key is read.
+// If key is not found, the number of entries read is returned
+func (d *db) zRankIn(ctx context.Context, snap *store.Snapshot, spec *store.KeyReaderSpec, key []byte, reversedTies bool) (member []byte, preceding uint64, err error) {
+	r, err := snap.NewKeyReader(spec)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer r.Close()
+
+	encodedMember := encodeZMember(key)
+	memberOff := len(spec.Prefix) + scoreLen
+
+	var score uint64
+	var scoreEntries uint64
+	var memberPos uint64
+
+	for {
+		if ctx.Err() != nil {
+			return nil, 0, ErrOperationCancelled
+		}
+
+		zKey, valLen, _, err := r.ReadKey()
+		if err == store.ErrNoMoreEntries {
+			break
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+
+		// entries removed with ZRem are not ranked
+		if valLen > 0 {
 			continue
 		}
-		if req.MaxScore != nil && score > req.MaxScore.Score {
+
+		k, atTx, err := zKeyEntry(zKey, memberOff)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		readable, err := d.zMemberReadable(snap, k, atTx, 0)
+		if err != nil {
+			return nil, 0, err
+		}
+		if !readable {
 			continue
 		}
 
-		keyOff := scoreOff + scoreLen + keyLenLen
-		key := make([]byte, len(zKey)-keyOff-txIDLen)
-		copy(key, zKey[keyOff:])
+		if s := binary.BigEndian.Uint64(zKey[len(spec.Prefix):]); scoreEntries == 0 || s != score {
+			if member != nil {
+				break
+			}
 
-		atTx := binary.BigEndian.Uint64(zKey[keyOff+len(key):])
+			preceding += scoreEntries
+			score = s
+			scoreEntries = 0
+		}
+
+		if zHasMember(zKey, memberOff, encodedMember) {
+			member = make([]byte, len(zKey))
+			copy(member, zKey)
 
-		e, err := d.getAt(key, atTx, 0, snap, d.tx1)
+			if !reversedTies {
+				return member, preceding + scoreEntries, nil
+			}
 
-		zentry := &schema.ZEntry{
-			Set:   req.Set,
-			Key:   key[1:],
-			Entry: e,
-			Score: score,
-			AtTx:  atTx,
+			// the last entry of the key read within the score is the first one in the requested order
+			memberPos = scoreEntries
 		}
 
-		entries = append(entries, zentry)
-		if i++; i == limit {
-			break
+		scoreEntries++
+	}
+
+	if member != nil {
+		return member, preceding + scoreEntries - memberPos - 1, nil
+	}
+
+	return nil, preceding + scoreEntries, nil
+}
+
+// zKeyEntry returns the encoded key of a sorted set entry and the tx it's bound to, given the offset of the member
+func zKeyEntry(zKey []byte, memberOff int) ([]byte, uint64, error) {
+	if len(zKey) < memberOff+keyLenLen+txIDLen {
+		return nil, 0, store.ErrCorruptedIndex
+	}
+
+	key, err := decodeZMember(zKey[memberOff : len(zKey)-txIDLen])
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return key, binary.BigEndian.Uint64(zKey[len(zKey)-txIDLen:]), nil
+}
+
+// zMemberReadable tells if the key of a sorted set entry can still be read, not being expired nor deleted. Only the
+// value assigned to the key is read, references are not resolved. Entries bound to a version of the key are checked
+// against that version, the others against the value of the key as of asOfTx, or its current value if asOfTx is zero
+func (d *db) zMemberReadable(snap *store.Snapshot, key []byte, atTx, asOfTx uint64) (bool, error) {
+	var val []byte
+	var err error
+
+	switch {
+	case atTx > 0:
+		val, err = d.readValue(key, atTx, d.tx1)
+	case asOfTx > 0:
+		var ktx uint64
+
+		ktx, err = keyTxAsOf(snap, key, asOfTx)
+		if err == nil {
+			val, err = d.readValue(key, ktx, d.tx1)
 		}
+	default:
+		val, _, _, err = snap.Get(key)
+	}
+	if err == store.ErrKeyNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
 	}
 
-	list := &schema.ZEntries{
-		Entries: entries,
+	if deleted(val) {
+		return false, nil
 	}
 
-	return list, nil
+	_, expiresAt, err := decodeValue(val)
+	if err != nil {
+		return false, err
+	}
+
+	return !expired(expiresAt), nil
+}
+
+// zHasMember tells if the sorted set entry belongs to the member encoded with encodeZMember
+func zHasMember(zKey []byte, memberOff int, member []byte) bool {
+	return len(zKey) == memberOff+len(member)+txIDLen && bytes.Equal(zKey[memberOff:memberOff+len(member)], member)
+}
+
+// inLexRange tells if the member key is within the lexicographic bounds, a nil bound meaning no limit
+func inLexRange(key []byte, minKey, maxKey *schema.LexBound) bool {
+	if minKey != nil {
+		c := bytes.Compare(key, minKey.Key)
+		if c < 0 || (c == 0 && minKey.Exclusive) {
+			return false
+		}
+	}
+
+	if maxKey != nil {
+		c := bytes.Compare(key, maxKey.Key)
+		if c > 0 || (c == 0 && maxKey.Exclusive) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func sortedSetPrefix(set []byte) []byte {
+	prefix := make([]byte, 1+setLenLen+len(set))
+	prefix[0] = SortedSetKeyPrefix
+	binary.BigEndian.PutUint64(prefix[1:], uint64(len(set)))
+	copy(prefix[1+setLenLen:], set)
+	return prefix
 }
 
 //VerifiableZAdd ...
@@ -0,0 +1,160 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"sort"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+)
+
+// zPopEntry is an entry of a sorted set, a member as returned by zMembers along with one of its scores
+type zPopEntry struct {
+	member string
+	score  float64
+}
+
+// ZPopMin removes and returns the entries of a sorted set with the lowest scores
+func (d *db) ZPopMin(req *schema.ZPopRequest) (*schema.ZPopResult, error) {
+	return d.zPop(req, false)
+}
+
+// ZPopMax removes and returns the entries of a sorted set with the highest scores
+func (d *db) ZPopMax(req *schema.ZPopRequest) (*schema.ZPopResult, error) {
+	return d.zPop(req, true)
+}
+
+// zPop reads the entries of the set and removes the ones with the lowest or highest scores within the same transaction,
+// so concurrent pops never return the same entry. As in ZScan and ZCard, each score a member was added with is an
+// entry of its own, thus a member added with several scores is popped once per score. Entries with equal scores are
+// popped in index order
+func (d *db) zPop(req *schema.ZPopRequest, max bool) (*schema.ZPopResult, error) {
+	if req == nil || len(req.Set) == 0 {
+		return nil, store.ErrIllegalArguments
+	}
+
+	if req.Count > MaxKeyScanLimit {
+		return nil, ErrMaxKeyScanLimitExceeded
+	}
+
+	count := int(req.Count)
+	if count == 0 {
+		count = 1
+	}
+
+	if d.options.replica {
+		return nil, ErrIsReplica
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	lastTxID, _ := d.st.Alh()
+	err := d.st.WaitForIndexingUpto(lastTxID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	snap, err := d.st.SnapshotSince(lastTxID)
+	if err != nil {
+		return nil, err
+	}
+	defer snap.Close()
+
+	members, err := d.zMembers(snap, req.Set)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []zPopEntry
+	for m, zm := range members {
+		for _, s := range zm.scores {
+			entries = append(entries, zPopEntry{member: m, score: s})
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		ei, ej := entries[i], entries[j]
+		if ei.score != ej.score {
+			return (ei.score < ej.score) != max
+		}
+		// within the same score, keys are indexed by their length first
+		if len(ei.member) != len(ej.member) {
+			return (len(ei.member) < len(ej.member)) != max
+		}
+		return (ei.member < ej.member) != max
+	})
+
+	res := &schema.ZPopResult{}
+
+	if len(entries) == 0 {
+		return res, nil
+	}
+
+	err = d.checkWORMRemoval()
+	if err != nil {
+		return nil, err
+	}
+
+	var kvs []*store.KV
+
+	for _, ze := range entries {
+		if len(res.Entries) == count {
+			break
+		}
+
+		key, atTx := zMemberFrom(ze.member)
+
+		// members whose key expired or was deleted are left as they are, as they are not listed either
+		e, err := d.getAt(key, atTx, 0, snap, d.tx1)
+		if unreadable(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// removing a held key from a sorted set would hide it from the set, as a logical deletion
+		err = d.checkLegalHold(key[1:])
+		if err != nil {
+			return nil, err
+		}
+
+		res.Entries = append(res.Entries, &schema.ZEntry{
+			Set:   req.Set,
+			Key:   key[1:],
+			Entry: e,
+			Score: ze.score,
+			AtTx:  atTx,
+		})
+
+		kvs = append(kvs, EncodeZRem(req.Set, ze.score, key, atTx))
+	}
+
+	if len(kvs) == 0 {
+		return res, nil
+	}
+
+	meta, err := d.st.Commit(kvs, !req.NoWait)
+	if err != nil {
+		return nil, err
+	}
+
+	res.TxMetadata = schema.TxMetatadaTo(meta)
+
+	return res, nil
+}
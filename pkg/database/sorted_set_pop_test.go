@@ -0,0 +1,124 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreZPop(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	_, err := db.ZPopMin(nil)
+	require.Equal(t, store.ErrIllegalArguments, err)
+
+	_, err = db.ZPopMax(&schema.ZPopRequest{Set: []byte("queue"), Count: MaxKeyScanLimit + 1})
+	require.Equal(t, ErrMaxKeyScanLimitExceeded, err)
+
+	res, err := db.ZPopMin(&schema.ZPopRequest{Set: []byte("queue")})
+	require.NoError(t, err)
+	require.Empty(t, res.Entries)
+	require.Nil(t, res.TxMetadata)
+
+	for _, kv := range []struct {
+		key   string
+		score float64
+	}{{"low", 1}, {"mid", 5}, {"high", 10}, {"b", 5}, {"negative", -1}} {
+		_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte(kv.key), Value: []byte("task-" + kv.key)}}})
+		require.NoError(t, err)
+
+		_, err = db.ZAdd(&schema.ZAddRequest{Set: []byte("queue"), Key: []byte(kv.key), Score: kv.score})
+		require.NoError(t, err)
+	}
+
+	res, err = db.ZPopMin(&schema.ZPopRequest{Set: []byte("queue")})
+	require.NoError(t, err)
+	require.NotNil(t, res.TxMetadata)
+	require.Len(t, res.Entries, 1)
+	require.Equal(t, []byte("negative"), res.Entries[0].Key)
+	require.Equal(t, float64(-1), res.Entries[0].Score)
+	require.Equal(t, []byte("task-negative"), res.Entries[0].Entry.Value)
+
+	res, err = db.ZPopMax(&schema.ZPopRequest{Set: []byte("queue")})
+	require.NoError(t, err)
+	require.Len(t, res.Entries, 1)
+	require.Equal(t, []byte("high"), res.Entries[0].Key)
+
+	// members with the same score are popped in index order, shorter keys first
+	res, err = db.ZPopMin(&schema.ZPopRequest{Set: []byte("queue"), Count: 10})
+	require.NoError(t, err)
+	require.Len(t, res.Entries, 3)
+	require.Equal(t, []byte("low"), res.Entries[0].Key)
+	require.Equal(t, []byte("b"), res.Entries[1].Key)
+	require.Equal(t, []byte("mid"), res.Entries[2].Key)
+
+	require.Empty(t, zScores(t, db, "queue"))
+
+	// popped members remain part of the history of the set
+	_, err = db.ZAdd(&schema.ZAddRequest{Set: []byte("queue"), Key: []byte("low"), Score: 2})
+	require.NoError(t, err)
+
+	res, err = db.ZPopMax(&schema.ZPopRequest{Set: []byte("queue"), Count: 2})
+	require.NoError(t, err)
+	require.Len(t, res.Entries, 1)
+	require.Equal(t, float64(2), res.Entries[0].Score)
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("twice"), Value: []byte("task-twice")}}})
+	require.NoError(t, err)
+
+	// each score a member was added with is an entry of the set, as listed by ZScan and counted by ZCard
+	for _, score := range []float64{10, 1} {
+		_, err = db.ZAdd(&schema.ZAddRequest{Set: []byte("multi"), Key: []byte("twice"), Score: score})
+		require.NoError(t, err)
+	}
+
+	_, err = db.ZAdd(&schema.ZAddRequest{Set: []byte("multi"), Key: []byte("low"), Score: 5})
+	require.NoError(t, err)
+
+	res, err = db.ZPopMin(&schema.ZPopRequest{Set: []byte("multi")})
+	require.NoError(t, err)
+	require.Len(t, res.Entries, 1)
+	require.Equal(t, []byte("twice"), res.Entries[0].Key)
+	require.Equal(t, float64(1), res.Entries[0].Score)
+
+	entries, err := db.ZScan(context.Background(), &schema.ZScanRequest{Set: []byte("multi")})
+	require.NoError(t, err)
+	require.Len(t, entries.Entries, 2)
+	require.Equal(t, []byte("low"), entries.Entries[0].Key)
+	require.Equal(t, []byte("twice"), entries.Entries[1].Key)
+	require.Equal(t, float64(10), entries.Entries[1].Score)
+
+	card, err := db.ZCard(context.Background(), &schema.ZCardRequest{Set: []byte("multi")})
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), card.Count)
+
+	res, err = db.ZPopMax(&schema.ZPopRequest{Set: []byte("multi"), Count: 2})
+	require.NoError(t, err)
+	require.Len(t, res.Entries, 2)
+	require.Equal(t, []byte("twice"), res.Entries[0].Key)
+	require.Equal(t, float64(10), res.Entries[0].Score)
+	require.Equal(t, []byte("low"), res.Entries[1].Key)
+
+	card, err = db.ZCard(context.Background(), &schema.ZCardRequest{Set: []byte("multi")})
+	require.NoError(t, err)
+	require.Zero(t, card.Count)
+}
@@ -0,0 +1,307 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"encoding/binary"
+	"math"
+	"sort"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+)
+
+// zMember holds the scores of a member of a sorted set, identified by its encoded key and the version it's bound to
+type zMember struct {
+	score   float64 // most recently added score
+	scoreTx uint64
+	scores  []float64 // all the scores which were not removed
+}
+
+// ZUnionStore stores into the destination set the members of any of the provided sets, aggregating their scores
+func (d *db) ZUnionStore(req *schema.ZStoreRequest) (*schema.ZStoreResult, error) {
+	return d.zStore(req, false)
+}
+
+// ZInterStore stores into the destination set the members present in all the provided sets, aggregating their scores
+func (d *db) ZInterStore(req *schema.ZStoreRequest) (*schema.ZStoreResult, error) {
+	return d.zStore(req, true)
+}
+
+// zStore computes the union or intersection of the sets and replaces the content of the destination set with it.
+// Sources are read and the destination written within the same transaction, so the destination may be one of the sources
+func (d *db) zStore(req *schema.ZStoreRequest, intersect bool) (*schema.ZStoreResult, error) {
+	if req == nil || len(req.Destination) == 0 || len(req.Sets) == 0 {
+		return nil, store.ErrIllegalArguments
+	}
+
+	if len(req.Weights) > 0 && len(req.Weights) != len(req.Sets) {
+		return nil, store.ErrIllegalArguments
+	}
+
+	for _, set := range req.Sets {
+		if len(set) == 0 {
+			return nil, store.ErrIllegalArguments
+		}
+	}
+
+	for _, w := range req.Weights {
+		if math.IsNaN(w) {
+			return nil, store.ErrIllegalArguments
+		}
+	}
+
+	aggregate, err := zAggregationFn(req.Aggregation)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.options.replica {
+		return nil, ErrIsReplica
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	lastTxID, _ := d.st.Alh()
+	err = d.st.WaitForIndexingUpto(lastTxID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	snap, err := d.st.SnapshotSince(lastTxID)
+	if err != nil {
+		return nil, err
+	}
+	defer snap.Close()
+
+	var result map[string]float64
+
+	for i, set := range req.Sets {
+		members, err := d.zMembers(snap, set)
+		if err != nil {
+			return nil, err
+		}
+
+		weight := 1.0
+		if len(req.Weights) > 0 {
+			weight = req.Weights[i]
+		}
+
+		if result == nil {
+			result = make(map[string]float64, len(members))
+			for m, zm := range members {
+				result[m] = zm.score * weight
+			}
+			continue
+		}
+
+		if intersect {
+			for m, score := range result {
+				zm, ok := members[m]
+				if !ok {
+					delete(result, m)
+					continue
+				}
+				result[m] = aggregate(score, zm.score*weight)
+			}
+			continue
+		}
+
+		for m, zm := range members {
+			score, ok := result[m]
+			if !ok {
+				result[m] = zm.score * weight
+				continue
+			}
+			result[m] = aggregate(score, zm.score*weight)
+		}
+	}
+
+	for _, score := range result {
+		// e.g. infinite scores of opposite sign being added up
+		if math.IsNaN(score) {
+			return nil, store.ErrIllegalArguments
+		}
+	}
+
+	current, err := d.zMembers(snap, req.Destination)
+	if err != nil {
+		return nil, err
+	}
+
+	var kvs []*store.KV
+
+	for m, zm := range current {
+		score, keep := result[m]
+
+		for _, s := range zm.scores {
+			if keep && math.Float64bits(s) == math.Float64bits(score) {
+				continue
+			}
+
+			err = d.checkWORMRemoval()
+			if err != nil {
+				return nil, err
+			}
+
+			key, atTx := zMemberFrom(m)
+
+			// removing a held key from a sorted set would hide it from the set, as a logical deletion
+			err = d.checkLegalHold(key[1:])
+			if err != nil {
+				return nil, err
+			}
+
+			kvs = append(kvs, EncodeZRem(req.Destination, s, key, atTx))
+		}
+	}
+
+	members := make([]string, 0, len(result))
+	for m := range result {
+		members = append(members, m)
+	}
+	sort.Strings(members)
+
+	for _, m := range members {
+		score := result[m]
+
+		if zm, ok := current[m]; ok && zm.hasScore(score) {
+			continue
+		}
+
+		key, atTx := zMemberFrom(m)
+		kvs = append(kvs, EncodeZAdd(req.Destination, score, key, atTx))
+	}
+
+	res := &schema.ZStoreResult{Count: uint64(len(result))}
+
+	if len(kvs) == 0 {
+		return res, nil
+	}
+
+	refEntries, err := d.referenceIndexEntries(kvs...)
+	if err != nil {
+		return nil, err
+	}
+
+	kvs = append(kvs, refEntries...)
+
+	meta, err := d.st.Commit(kvs, !req.NoWait)
+	if err != nil {
+		return nil, err
+	}
+
+	res.TxMetadata = schema.TxMetatadaTo(meta)
+
+	return res, nil
+}
+
+// zMembers reads the members of a sorted set which were not removed, indexed by the encoded key followed by the tx it's bound to.
+// As in ZCount and ZRank, members whose key expired or was deleted are skipped
+func (d *db) zMembers(snap *store.Snapshot, set []byte) (map[string]*zMember, error) {
+	prefix := sortedSetPrefix(set)
+
+	r, err := snap.NewKeyReader(&store.KeyReaderSpec{Prefix: prefix})
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	memberOff := len(prefix) + scoreLen
+
+	members := make(map[string]*zMember)
+	unreadable := make(map[string]struct{})
+
+	for {
+		zKey, valLen, tx, err := r.ReadKey()
+		if err == store.ErrNoMoreEntries {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if valLen > 0 {
+			continue
+		}
+
+		key, atTx, err := zKeyEntry(zKey, memberOff)
+		if err != nil {
+			return nil, err
+		}
+
+		m := string(key) + string(zKey[len(zKey)-txIDLen:])
+		s := math.Float64frombits(binary.BigEndian.Uint64(zKey[len(prefix):]))
+
+		if _, ok := unreadable[m]; ok {
+			continue
+		}
+
+		zm, ok := members[m]
+		if !ok {
+			readable, err := d.zMemberReadable(snap, key, atTx, 0)
+			if err != nil {
+				return nil, err
+			}
+
+			if !readable {
+				unreadable[m] = struct{}{}
+				continue
+			}
+
+			zm = &zMember{}
+			members[m] = zm
+		}
+
+		zm.scores = append(zm.scores, s)
+
+		// the most recently added score is the current one
+		if tx >= zm.scoreTx {
+			zm.score = s
+			zm.scoreTx = tx
+		}
+	}
+
+	return members, nil
+}
+
+func (zm *zMember) hasScore(score float64) bool {
+	for _, s := range zm.scores {
+		if math.Float64bits(s) == math.Float64bits(score) {
+			return true
+		}
+	}
+	return false
+}
+
+// zMemberFrom splits a member identifier into the encoded key and the version it's bound to
+func zMemberFrom(m string) ([]byte, uint64) {
+	key := []byte(m[:len(m)-txIDLen])
+	return key, binary.BigEndian.Uint64([]byte(m[len(m)-txIDLen:]))
+}
+
+func zAggregationFn(aggregation schema.ZAggregation) (func(a, b float64) float64, error) {
+	switch aggregation {
+	case schema.ZAggregation_ZAGGREGATE_SUM:
+		return func(a, b float64) float64 { return a + b }, nil
+	case schema.ZAggregation_ZAGGREGATE_MIN:
+		return math.Min, nil
+	case schema.ZAggregation_ZAGGREGATE_MAX:
+		return math.Max, nil
+	}
+	return nil, store.ErrIllegalArguments
+}
@@ -0,0 +1,213 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func zScores(t *testing.T, db DB, set string) map[string]float64 {
+	entries, err := db.ZScan(context.Background(), &schema.ZScanRequest{Set: []byte(set)})
+	require.NoError(t, err)
+
+	scores := make(map[string]float64, len(entries.Entries))
+	for _, e := range entries.Entries {
+		scores[string(e.Key)] = e.Score
+	}
+	return scores
+}
+
+func TestStoreZStoreInvalidArguments(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	_, err := db.ZUnionStore(nil)
+	require.Equal(t, store.ErrIllegalArguments, err)
+
+	_, err = db.ZUnionStore(&schema.ZStoreRequest{Destination: []byte("dst")})
+	require.Equal(t, store.ErrIllegalArguments, err)
+
+	_, err = db.ZUnionStore(&schema.ZStoreRequest{Sets: [][]byte{[]byte("set1")}})
+	require.Equal(t, store.ErrIllegalArguments, err)
+
+	_, err = db.ZInterStore(&schema.ZStoreRequest{Destination: []byte("dst"), Sets: [][]byte{[]byte("set1"), nil}})
+	require.Equal(t, store.ErrIllegalArguments, err)
+
+	_, err = db.ZInterStore(&schema.ZStoreRequest{
+		Destination: []byte("dst"),
+		Sets:        [][]byte{[]byte("set1"), []byte("set2")},
+		Weights:     []float64{1},
+	})
+	require.Equal(t, store.ErrIllegalArguments, err)
+
+	_, err = db.ZUnionStore(&schema.ZStoreRequest{
+		Destination: []byte("dst"),
+		Sets:        [][]byte{[]byte("set1")},
+		Weights:     []float64{math.NaN()},
+	})
+	require.Equal(t, store.ErrIllegalArguments, err)
+
+	_, err = db.ZUnionStore(&schema.ZStoreRequest{
+		Destination: []byte("dst"),
+		Sets:        [][]byte{[]byte("set1")},
+		Aggregation: schema.ZAggregation(10),
+	})
+	require.Equal(t, store.ErrIllegalArguments, err)
+}
+
+func TestStoreZUnionAndInterStore(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	_, err := db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{
+		{Key: []byte("a"), Value: []byte("1")},
+		{Key: []byte("b"), Value: []byte("2")},
+		{Key: []byte("c"), Value: []byte("3")},
+	}})
+	require.NoError(t, err)
+
+	for _, z := range []struct {
+		set   string
+		key   string
+		score float64
+	}{
+		{"set1", "a", 1},
+		{"set1", "b", 2},
+		{"set2", "b", 3},
+		{"set2", "c", 4},
+	} {
+		_, err = db.ZAdd(&schema.ZAddRequest{Set: []byte(z.set), Key: []byte(z.key), Score: z.score})
+		require.NoError(t, err)
+	}
+
+	sets := [][]byte{[]byte("set1"), []byte("set2")}
+
+	res, err := db.ZUnionStore(&schema.ZStoreRequest{Destination: []byte("dst"), Sets: sets})
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), res.Count)
+	require.NotNil(t, res.TxMetadata)
+	require.Equal(t, map[string]float64{"a": 1, "b": 5, "c": 4}, zScores(t, db, "dst"))
+
+	// storing the same result again does not commit a transaction
+	res, err = db.ZUnionStore(&schema.ZStoreRequest{Destination: []byte("dst"), Sets: sets})
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), res.Count)
+	require.Nil(t, res.TxMetadata)
+
+	_, err = db.ZUnionStore(&schema.ZStoreRequest{
+		Destination: []byte("dst"),
+		Sets:        sets,
+		Weights:     []float64{10, 1},
+		Aggregation: schema.ZAggregation_ZAGGREGATE_MAX,
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]float64{"a": 10, "b": 20, "c": 4}, zScores(t, db, "dst"))
+
+	// members not in the result are removed from the destination
+	res, err = db.ZInterStore(&schema.ZStoreRequest{
+		Destination: []byte("dst"),
+		Sets:        sets,
+		Aggregation: schema.ZAggregation_ZAGGREGATE_MIN,
+	})
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), res.Count)
+	require.Equal(t, map[string]float64{"b": 2}, zScores(t, db, "dst"))
+
+	// the destination may be one of the sources
+	res, err = db.ZInterStore(&schema.ZStoreRequest{
+		Destination: []byte("dst"),
+		Sets:        [][]byte{[]byte("dst"), []byte("set2")},
+	})
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), res.Count)
+	require.Equal(t, map[string]float64{"b": 5}, zScores(t, db, "dst"))
+
+	res, err = db.ZInterStore(&schema.ZStoreRequest{
+		Destination: []byte("dst"),
+		Sets:        [][]byte{[]byte("set1"), []byte("missing")},
+	})
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), res.Count)
+	require.Empty(t, zScores(t, db, "dst"))
+}
+
+func TestStoreZUnionStoreBoundMembers(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	_, err := db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("a"), Value: []byte("1")}}})
+	require.NoError(t, err)
+
+	_, err = db.ZAdd(&schema.ZAddRequest{Set: []byte("set1"), Key: []byte("a"), Score: 1, AtTx: 1, BoundRef: true})
+	require.NoError(t, err)
+
+	_, err = db.ZAdd(&schema.ZAddRequest{Set: []byte("set2"), Key: []byte("a"), Score: 2})
+	require.NoError(t, err)
+
+	// a member bound to a version of the key is not the same member as the unbound key
+	res, err := db.ZUnionStore(&schema.ZStoreRequest{
+		Destination: []byte("dst"),
+		Sets:        [][]byte{[]byte("set1"), []byte("set2")},
+	})
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), res.Count)
+
+	entries, err := db.ZScan(context.Background(), &schema.ZScanRequest{Set: []byte("dst")})
+	require.NoError(t, err)
+	require.Len(t, entries.Entries, 2)
+	require.Equal(t, 1.0, entries.Entries[0].Score)
+	require.Equal(t, uint64(1), entries.Entries[0].AtTx)
+	require.Equal(t, 2.0, entries.Entries[1].Score)
+	require.Equal(t, uint64(0), entries.Entries[1].AtTx)
+}
+
+func TestStoreZUnionStoreUnreadableMembers(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	_, err := db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{
+		{Key: []byte("a"), Value: []byte("1")},
+		{Key: []byte("b"), Value: []byte("2")},
+		{Key: []byte("c"), Value: []byte("3")},
+	}})
+	require.NoError(t, err)
+
+	for _, key := range []string{"a", "b", "c"} {
+		_, err = db.ZAdd(&schema.ZAddRequest{Set: []byte("set1"), Key: []byte(key), Score: 1})
+		require.NoError(t, err)
+	}
+
+	_, err = db.Delete(&schema.DeleteKeysRequest{Keys: [][]byte{[]byte("b")}})
+	require.NoError(t, err)
+
+	expireKey(t, db, "c")
+
+	// members whose key was deleted or expired are not part of the result, as they are not scanned
+	res, err := db.ZUnionStore(&schema.ZStoreRequest{Destination: []byte("dst"), Sets: [][]byte{[]byte("set1")}})
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), res.Count)
+	require.Equal(t, map[string]float64{"a": 1}, zScores(t, db, "dst"))
+
+	card, err := db.ZCard(context.Background(), &schema.ZCardRequest{Set: []byte("dst")})
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), card.Count)
+}
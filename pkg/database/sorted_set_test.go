@@ -16,8 +16,13 @@ limitations under the License.
 package database
 
 import (
+	"context"
+	"encoding/binary"
+	"fmt"
 	"math"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/codenotary/immudb/embedded/store"
 	"github.com/codenotary/immudb/pkg/api/schema"
@@ -82,7 +87,7 @@ func TestStoreIndexExists(t *testing.T) {
 		Limit: MaxKeyScanLimit + 1,
 	}
 
-	_, err = db.ZScan(zscanOpts)
+	_, err = db.ZScan(context.Background(), zscanOpts)
 	require.Equal(t, ErrMaxKeyScanLimitExceeded, err)
 
 	//try to retrieve directly the value or full scan to debug
@@ -91,7 +96,7 @@ func TestStoreIndexExists(t *testing.T) {
 		Set: []byte(`firstIndex`),
 	}
 
-	itemList1, err := db.ZScan(zscanOpts1)
+	itemList1, err := db.ZScan(context.Background(), zscanOpts1)
 	require.NoError(t, err)
 	require.Len(t, itemList1.Entries, 3)
 	require.Equal(t, []byte(`mySecondElementKey`), itemList1.Entries[0].Entry.Key)
@@ -104,7 +109,7 @@ func TestStoreIndexExists(t *testing.T) {
 		Desc:     true,
 	}
 
-	itemList2, err := db.ZScan(zscanOpts2)
+	itemList2, err := db.ZScan(context.Background(), zscanOpts2)
 	require.NoError(t, err)
 	require.Len(t, itemList2.Entries, 3)
 	require.Equal(t, []byte(`myFirstElementKey`), itemList2.Entries[0].Entry.Key)
@@ -182,7 +187,7 @@ func TestStoreIndexEqualKeys(t *testing.T) {
 		SinceTx: reference3.Id,
 	}
 
-	itemList1, err := db.ZScan(zscanOpts1)
+	itemList1, err := db.ZScan(context.Background(), zscanOpts1)
 	require.NoError(t, err)
 	require.Len(t, itemList1.Entries, 3)
 	require.Equal(t, []byte(`SignerId1`), itemList1.Entries[0].Entry.Key)
@@ -248,7 +253,7 @@ func TestStoreIndexEqualKeysEqualScores(t *testing.T) {
 		SinceTx: reference3.Id,
 	}
 
-	itemList1, err := db.ZScan(zscanOpts1)
+	itemList1, err := db.ZScan(context.Background(), zscanOpts1)
 
 	require.NoError(t, err)
 	require.Len(t, itemList1.Entries, 3)
@@ -358,7 +363,7 @@ func TestStore_ZScanPagination(t *testing.T) {
 		SinceTx:  meta.Id,
 	}
 
-	list0, err := db.ZScan(zScanOption0)
+	list0, err := db.ZScan(context.Background(), zScanOption0)
 	require.NoError(t, err)
 	require.Empty(t, list0.Entries)
 
@@ -372,7 +377,7 @@ func TestStore_ZScanPagination(t *testing.T) {
 		SinceTx:  meta.Id,
 	}
 
-	list1, err := db.ZScan(zScanOption1)
+	list1, err := db.ZScan(context.Background(), zScanOption1)
 	require.NoError(t, err)
 	require.Len(t, list1.Entries, 2)
 	require.Equal(t, list1.Entries[0].Entry.Key, []byte(`key3`))
@@ -392,7 +397,7 @@ func TestStore_ZScanPagination(t *testing.T) {
 		SinceTx:   meta.Id,
 	}
 
-	list, err := db.ZScan(zScanOption2)
+	list, err := db.ZScan(context.Background(), zScanOption2)
 	require.NoError(t, err)
 	require.Len(t, list.Entries, 2)
 	require.Equal(t, list.Entries[0].Entry.Key, []byte(`key5`))
@@ -482,7 +487,7 @@ func TestStore_ZScanReversePagination(t *testing.T) {
 		SinceTx:       meta.Id,
 	}
 
-	list1, err := db.ZScan(zScanOption1)
+	list1, err := db.ZScan(context.Background(), zScanOption1)
 	require.NoError(t, err)
 	require.Len(t, list1.Entries, 2)
 	require.Equal(t, list1.Entries[0].Entry.Key, []byte(`key6`))
@@ -501,7 +506,7 @@ func TestStore_ZScanReversePagination(t *testing.T) {
 		SinceTx:       meta.Id,
 	}
 
-	list2, err := db.ZScan(zScanOption2)
+	list2, err := db.ZScan(context.Background(), zScanOption2)
 	require.NoError(t, err)
 	require.Len(t, list2.Entries, 2)
 	require.Equal(t, list2.Entries[0].Entry.Key, []byte(`key5`))
@@ -518,7 +523,7 @@ func TestStore_ZScanReversePagination(t *testing.T) {
 		SinceTx:       meta.Id,
 	}
 
-	list3, err := db.ZScan(zScanOption3)
+	list3, err := db.ZScan(context.Background(), zScanOption3)
 	require.NoError(t, err)
 	require.Len(t, list3.Entries, 2)
 	require.Equal(t, list3.Entries[0].Entry.Key, []byte(`key4`))
@@ -532,7 +537,7 @@ func TestStore_ZScanInvalidSet(t *testing.T) {
 	opt := &schema.ZScanRequest{
 		Set: nil,
 	}
-	_, err := db.ZScan(opt)
+	_, err := db.ZScan(context.Background(), opt)
 	require.Equal(t, store.ErrIllegalArguments, err)
 }
 
@@ -583,7 +588,7 @@ func TestStore_ZScanOnEqualKeysWithSameScoreAreReturnedOrderedByTS(t *testing.T)
 		SinceTx: meta.Id,
 	}
 
-	list, err := db.ZScan(ZScanRequest)
+	list, err := db.ZScan(context.Background(), ZScanRequest)
 	require.NoError(t, err)
 	// same key, sorted by internal timestamp
 	require.Exactly(t, []byte(`val1-A`), list.Entries[0].Entry.Value)
@@ -646,7 +651,7 @@ func TestStoreZScanOnZAddIndexReference(t *testing.T) {
 		SinceTx: reference3.Id,
 	}
 
-	itemList1, err := db.ZScan(zscanOpts1)
+	itemList1, err := db.ZScan(context.Background(), zscanOpts1)
 	require.NoError(t, err)
 	require.Len(t, itemList1.Entries, 3)
 	require.Equal(t, []byte(`SignerId1`), itemList1.Entries[0].Entry.Key)
@@ -711,9 +716,903 @@ func TestStoreVerifiableZAdd(t *testing.T) {
 		SinceTx: vtx.Tx.Metadata.Id,
 	}
 
-	itemList1, err := db.ZScan(zscanReq)
+	itemList1, err := db.ZScan(context.Background(), zscanReq)
 	require.NoError(t, err)
 	require.Len(t, itemList1.Entries, 1)
 	require.Equal(t, req.Key, itemList1.Entries[0].Entry.Key)
 	require.Equal(t, req.Score, itemList1.Entries[0].Score)
 }
+
+func TestStoreZRem(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	_, err := db.ZRem(nil)
+	require.Equal(t, store.ErrIllegalArguments, err)
+
+	_, err = db.ZRem(&schema.ZRemRequest{Set: []byte("set")})
+	require.Equal(t, store.ErrIllegalArguments, err)
+
+	_, err = db.ZRem(&schema.ZRemRequest{Set: []byte("set"), Key: []byte("key1")})
+	require.Equal(t, ErrSortedSetMemberNotFound, err)
+
+	for _, k := range []string{"key1", "key2"} {
+		_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte(k), Value: []byte("value")}}})
+		require.NoError(t, err)
+	}
+
+	_, err = db.ZAdd(&schema.ZAddRequest{Set: []byte("set"), Key: []byte("key1"), Score: 1})
+	require.NoError(t, err)
+
+	_, err = db.ZAdd(&schema.ZAddRequest{Set: []byte("set"), Key: []byte("key1"), Score: 3})
+	require.NoError(t, err)
+
+	_, err = db.ZAdd(&schema.ZAddRequest{Set: []byte("set"), Key: []byte("key2"), Score: 2})
+	require.NoError(t, err)
+
+	md, err := db.ZRem(&schema.ZRemRequest{Set: []byte("set"), Key: []byte("key1")})
+	require.NoError(t, err)
+	require.Equal(t, int32(2), md.Nentries)
+
+	entries, err := db.ZScan(context.Background(), &schema.ZScanRequest{Set: []byte("set")})
+	require.NoError(t, err)
+	require.Len(t, entries.Entries, 1)
+	require.Equal(t, []byte("key2"), entries.Entries[0].Key)
+
+	_, err = db.ZRem(&schema.ZRemRequest{Set: []byte("set"), Key: []byte("key1")})
+	require.Equal(t, ErrSortedSetMemberNotFound, err)
+
+	// the removal is kept in the history of the set
+	tx, err := db.TxByID(&schema.TxRequest{Tx: md.Id})
+	require.NoError(t, err)
+	require.Len(t, tx.Entries, 2)
+
+	_, err = db.ZAdd(&schema.ZAddRequest{Set: []byte("set"), Key: []byte("key1"), Score: 1})
+	require.NoError(t, err)
+
+	entries, err = db.ZScan(context.Background(), &schema.ZScanRequest{Set: []byte("set")})
+	require.NoError(t, err)
+	require.Len(t, entries.Entries, 2)
+	require.Equal(t, []byte("key1"), entries.Entries[0].Key)
+}
+
+func TestStoreZCount(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	_, err := db.ZCount(context.Background(), nil)
+	require.Equal(t, store.ErrIllegalArguments, err)
+
+	_, err = db.ZCount(context.Background(), &schema.ZCountRequest{Set: []byte("set"), MinScore: &schema.Score{Score: math.NaN()}})
+	require.Equal(t, store.ErrIllegalArguments, err)
+
+	scores := []float64{-10, -2.5, math.Copysign(0, -1), 0, 1, 2.5, 10, 100}
+
+	for i, score := range scores {
+		key := []byte(fmt.Sprintf("key%d", i))
+
+		_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: key, Value: []byte("value")}}})
+		require.NoError(t, err)
+
+		_, err = db.ZAdd(&schema.ZAddRequest{Set: []byte("set"), Key: key, Score: score})
+		require.NoError(t, err)
+	}
+
+	count := func(min, max *schema.Score) uint64 {
+		c, err := db.ZCount(context.Background(), &schema.ZCountRequest{Set: []byte("set"), MinScore: min, MaxScore: max})
+		require.NoError(t, err)
+		return c.Count
+	}
+
+	require.Equal(t, uint64(8), count(nil, nil))
+	require.Equal(t, uint64(6), count(&schema.Score{Score: 0}, nil))
+	require.Equal(t, uint64(4), count(nil, &schema.Score{Score: 0}))
+	require.Equal(t, uint64(2), count(nil, &schema.Score{Score: -1}))
+	require.Equal(t, uint64(5), count(&schema.Score{Score: -2.5}, &schema.Score{Score: 2.5}))
+	require.Equal(t, uint64(2), count(&schema.Score{Score: 1}, &schema.Score{Score: 9}))
+	require.Equal(t, uint64(0), count(&schema.Score{Score: 3}, &schema.Score{Score: 9}))
+	require.Equal(t, uint64(0), count(&schema.Score{Score: 9}, &schema.Score{Score: 3}))
+
+	c, err := db.ZCount(context.Background(), &schema.ZCountRequest{Set: []byte("other")})
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), c.Count)
+
+	_, err = db.ZRem(&schema.ZRemRequest{Set: []byte("set"), Key: []byte("key5")})
+	require.NoError(t, err)
+
+	require.Equal(t, uint64(1), count(&schema.Score{Score: 1}, &schema.Score{Score: 9}))
+
+	// members whose key expired are not counted
+	expireKey(t, db, "key4")
+
+	require.Equal(t, uint64(0), count(&schema.Score{Score: 1}, &schema.Score{Score: 9}))
+	require.Equal(t, uint64(6), count(nil, nil))
+
+	// nor the ones whose key was deleted
+	_, err = db.Delete(&schema.DeleteKeysRequest{Keys: [][]byte{[]byte("key7")}})
+	require.NoError(t, err)
+
+	require.Equal(t, uint64(5), count(nil, nil))
+	require.Equal(t, uint64(0), count(&schema.Score{Score: 100}, nil))
+}
+
+func TestStoreZAddMulti(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	_, err := db.ZAddMulti(nil)
+	require.Equal(t, store.ErrIllegalArguments, err)
+
+	_, err = db.ZAddMulti(&schema.ZAddMultiRequest{})
+	require.Equal(t, store.ErrIllegalArguments, err)
+
+	_, err = db.ZAddMulti(&schema.ZAddMultiRequest{Entries: []*schema.ZAddRequest{{Set: []byte("set"), Key: []byte("key"), BoundRef: true}}})
+	require.Equal(t, store.ErrIllegalArguments, err)
+
+	md, err := db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{
+		{Key: []byte("key1"), Value: []byte("value1")},
+		{Key: []byte("key2"), Value: []byte("value2")},
+	}})
+	require.NoError(t, err)
+
+	// a missing key makes the whole batch fail
+	_, err = db.ZAddMulti(&schema.ZAddMultiRequest{Entries: []*schema.ZAddRequest{
+		{Set: []byte("set1"), Key: []byte("key1"), Score: 1},
+		{Set: []byte("set1"), Key: []byte("missing"), Score: 2},
+	}})
+	require.Equal(t, store.ErrKeyNotFound, err)
+
+	txmd, err := db.ZAddMulti(&schema.ZAddMultiRequest{Entries: []*schema.ZAddRequest{
+		{Set: []byte("set1"), Key: []byte("key1"), Score: 1},
+		{Set: []byte("set1"), Key: []byte("key2"), Score: 2},
+		{Set: []byte("set2"), Key: []byte("key1"), Score: 3, AtTx: md.Id, BoundRef: true},
+	}})
+	require.NoError(t, err)
+	require.Equal(t, md.Id+1, txmd.Id)
+	// each entry is written along with its reference index entry
+	require.Equal(t, int32(6), txmd.Nentries)
+
+	require.Equal(t, map[string]float64{"key1": 1, "key2": 2}, zScores(t, db, "set1"))
+	require.Equal(t, map[string]float64{"key1": 3}, zScores(t, db, "set2"))
+}
+
+func TestStoreZCard(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	_, err := db.ZCard(context.Background(), nil)
+	require.Equal(t, store.ErrIllegalArguments, err)
+
+	_, err = db.ZCard(context.Background(), &schema.ZCardRequest{})
+	require.Equal(t, store.ErrIllegalArguments, err)
+
+	card := func(set string) uint64 {
+		c, err := db.ZCard(context.Background(), &schema.ZCardRequest{Set: []byte(set)})
+		require.NoError(t, err)
+		return c.Count
+	}
+
+	require.Zero(t, card("set"))
+
+	for i, score := range []float64{-1, 0, 1, math.Inf(1)} {
+		key := []byte(fmt.Sprintf("key%d", i))
+
+		_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: key, Value: []byte("value")}}})
+		require.NoError(t, err)
+
+		_, err = db.ZAdd(&schema.ZAddRequest{Set: []byte("set"), Key: key, Score: score})
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, uint64(4), card("set"))
+
+	_, err = db.ZRem(&schema.ZRemRequest{Set: []byte("set"), Key: []byte("key0")})
+	require.NoError(t, err)
+
+	require.Equal(t, uint64(3), card("set"))
+	require.Zero(t, card("other"))
+
+	expireKey(t, db, "key1")
+
+	require.Equal(t, uint64(2), card("set"))
+
+	_, err = db.Delete(&schema.DeleteKeysRequest{Keys: [][]byte{[]byte("key2")}})
+	require.NoError(t, err)
+
+	require.Equal(t, uint64(1), card("set"))
+}
+
+func TestStoreZRank(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	_, err := db.ZRank(context.Background(), nil)
+	require.Equal(t, store.ErrIllegalArguments, err)
+
+	_, err = db.ZRank(context.Background(), &schema.ZRankRequest{Set: []byte("set")})
+	require.Equal(t, store.ErrIllegalArguments, err)
+
+	scores := []float64{10, -2.5, 0, 100, -10, 2.5, 1}
+
+	for i, score := range scores {
+		key := []byte(fmt.Sprintf("key%d", i))
+
+		_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: key, Value: []byte("value")}}})
+		require.NoError(t, err)
+
+		_, err = db.ZAdd(&schema.ZAddRequest{Set: []byte("set"), Key: key, Score: score})
+		require.NoError(t, err)
+	}
+
+	rank := func(key string, desc bool) *schema.ZRankResult {
+		r, err := db.ZRank(context.Background(), &schema.ZRankRequest{Set: []byte("set"), Key: []byte(key), Desc: desc})
+		require.NoError(t, err)
+		return r
+	}
+
+	require.Equal(t, uint64(0), rank("key4", false).Rank)
+	require.Equal(t, uint64(1), rank("key1", false).Rank)
+	require.Equal(t, uint64(2), rank("key2", false).Rank)
+	require.Equal(t, uint64(6), rank("key3", false).Rank)
+	require.Equal(t, uint64(0), rank("key3", true).Rank)
+	require.Equal(t, uint64(6), rank("key4", true).Rank)
+
+	r := rank("key5", false)
+	require.Equal(t, uint64(4), r.Rank)
+	require.Equal(t, 2.5, r.Score)
+
+	_, err = db.ZRank(context.Background(), &schema.ZRankRequest{Set: []byte("set"), Key: []byte("missing")})
+	require.Equal(t, ErrSortedSetMemberNotFound, err)
+
+	_, err = db.ZRank(context.Background(), &schema.ZRankRequest{Set: []byte("other"), Key: []byte("key0")})
+	require.Equal(t, ErrSortedSetMemberNotFound, err)
+
+	// a key added with several scores is ranked by its first entry in the requested order
+	_, err = db.ZAdd(&schema.ZAddRequest{Set: []byte("set"), Key: []byte("key6"), Score: 50})
+	require.NoError(t, err)
+
+	require.Equal(t, uint64(3), rank("key6", false).Rank)
+	require.Equal(t, 1.0, rank("key6", false).Score)
+	require.Equal(t, uint64(1), rank("key6", true).Rank)
+	require.Equal(t, 50.0, rank("key6", true).Score)
+
+	_, err = db.ZRem(&schema.ZRemRequest{Set: []byte("set"), Key: []byte("key1")})
+	require.NoError(t, err)
+
+	require.Equal(t, uint64(1), rank("key2", false).Rank)
+
+	_, err = db.ZRank(context.Background(), &schema.ZRankRequest{Set: []byte("set"), Key: []byte("key1")})
+	require.Equal(t, ErrSortedSetMemberNotFound, err)
+
+	// entries sharing a score are ranked in index order, negative scores included
+	ties := []struct {
+		key   string
+		score float64
+	}{{"tieB", -5}, {"tieA", -5}, {"tieD", 5}, {"tieC", 5}, {"tieA", -7}}
+
+	for _, e := range ties {
+		_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte(e.key), Value: []byte("value")}}})
+		require.NoError(t, err)
+
+		_, err = db.ZAdd(&schema.ZAddRequest{Set: []byte("ties"), Key: []byte(e.key), Score: e.score})
+		require.NoError(t, err)
+	}
+
+	tieRank := func(key string, desc bool) *schema.ZRankResult {
+		r, err := db.ZRank(context.Background(), &schema.ZRankRequest{Set: []byte("ties"), Key: []byte(key), Desc: desc})
+		require.NoError(t, err)
+		return r
+	}
+
+	require.Equal(t, uint64(0), tieRank("tieA", false).Rank)
+	require.Equal(t, -7.0, tieRank("tieA", false).Score)
+	require.Equal(t, uint64(2), tieRank("tieB", false).Rank)
+	require.Equal(t, uint64(3), tieRank("tieC", false).Rank)
+	require.Equal(t, uint64(4), tieRank("tieD", false).Rank)
+
+	require.Equal(t, uint64(0), tieRank("tieD", true).Rank)
+	require.Equal(t, uint64(1), tieRank("tieC", true).Rank)
+	require.Equal(t, uint64(2), tieRank("tieB", true).Rank)
+	require.Equal(t, uint64(3), tieRank("tieA", true).Rank)
+	require.Equal(t, -5.0, tieRank("tieA", true).Score)
+
+	// members whose key expired are not ranked
+	expireKey(t, db, "tieB")
+
+	require.Equal(t, uint64(2), tieRank("tieC", false).Rank)
+	require.Equal(t, uint64(2), tieRank("tieA", true).Rank)
+
+	_, err = db.ZRank(context.Background(), &schema.ZRankRequest{Set: []byte("ties"), Key: []byte("tieB")})
+	require.Equal(t, ErrSortedSetMemberNotFound, err)
+
+	// members whose key was deleted are not ranked either
+	_, err = db.Delete(&schema.DeleteKeysRequest{Keys: [][]byte{[]byte("tieC")}})
+	require.NoError(t, err)
+
+	require.Equal(t, uint64(2), tieRank("tieD", false).Rank)
+	require.Equal(t, uint64(1), tieRank("tieA", true).Rank)
+
+	_, err = db.ZRank(context.Background(), &schema.ZRankRequest{Set: []byte("ties"), Key: []byte("tieC")})
+	require.Equal(t, ErrSortedSetMemberNotFound, err)
+}
+
+func TestStoreZIncrBy(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	_, err := db.ZIncrBy(nil)
+	require.Equal(t, store.ErrIllegalArguments, err)
+
+	_, err = db.ZIncrBy(&schema.ZIncrByRequest{Set: []byte("set"), Key: []byte("key1"), Increment: math.NaN()})
+	require.Equal(t, store.ErrIllegalArguments, err)
+
+	_, err = db.ZIncrBy(&schema.ZIncrByRequest{Set: []byte("set"), Key: []byte("key1"), Increment: 1})
+	require.Equal(t, store.ErrKeyNotFound, err)
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("key1"), Value: []byte("value1")}}})
+	require.NoError(t, err)
+
+	res, err := db.ZIncrBy(&schema.ZIncrByRequest{Set: []byte("set"), Key: []byte("key1"), Increment: 2.5})
+	require.NoError(t, err)
+	require.Equal(t, 2.5, res.Score)
+	require.Equal(t, uint64(2), res.TxMetadata.Id)
+
+	res, err = db.ZIncrBy(&schema.ZIncrByRequest{Set: []byte("set"), Key: []byte("key1"), Increment: -5})
+	require.NoError(t, err)
+	require.Equal(t, -2.5, res.Score)
+
+	res, err = db.ZIncrBy(&schema.ZIncrByRequest{Set: []byte("set"), Key: []byte("key1"), Increment: 0})
+	require.NoError(t, err)
+	require.Equal(t, -2.5, res.Score)
+
+	entries, err := db.ZScan(context.Background(), &schema.ZScanRequest{Set: []byte("set")})
+	require.NoError(t, err)
+	require.Len(t, entries.Entries, 1)
+	require.Equal(t, -2.5, entries.Entries[0].Score)
+	require.Equal(t, []byte("key1"), entries.Entries[0].Key)
+
+	// entries bound to a version of the key are not incremented
+	_, err = db.ZAdd(&schema.ZAddRequest{Set: []byte("set"), Key: []byte("key1"), Score: 100, AtTx: 1, BoundRef: true})
+	require.NoError(t, err)
+
+	_, err = db.ZAdd(&schema.ZAddRequest{Set: []byte("set"), Key: []byte("key1"), Score: 10})
+	require.NoError(t, err)
+
+	res, err = db.ZIncrBy(&schema.ZIncrByRequest{Set: []byte("set"), Key: []byte("key1"), Increment: 1})
+	require.NoError(t, err)
+	require.Equal(t, 11.0, res.Score)
+
+	entries, err = db.ZScan(context.Background(), &schema.ZScanRequest{Set: []byte("set")})
+	require.NoError(t, err)
+	require.Len(t, entries.Entries, 2)
+	require.Equal(t, 11.0, entries.Entries[0].Score)
+	require.Equal(t, 100.0, entries.Entries[1].Score)
+	require.Equal(t, uint64(1), entries.Entries[1].AtTx)
+}
+
+func TestStoreZIncrByConcurrent(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	_, err := db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("key1"), Value: []byte("value1")}}})
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, err := db.ZIncrBy(&schema.ZIncrByRequest{Set: []byte("set"), Key: []byte("key1"), Increment: 1})
+			require.NoError(t, err)
+		}()
+	}
+
+	wg.Wait()
+
+	entries, err := db.ZScan(context.Background(), &schema.ZScanRequest{Set: []byte("set")})
+	require.NoError(t, err)
+	require.Len(t, entries.Entries, 1)
+	require.Equal(t, 10.0, entries.Entries[0].Score)
+}
+
+func TestZScoreRanges(t *testing.T) {
+	prefix := sortedSetPrefix([]byte("set"))
+
+	bits := func(rng *zScoreRange) (uint64, uint64) {
+		return binary.BigEndian.Uint64(rng.start[len(prefix):]), binary.BigEndian.Uint64(rng.end[len(prefix):])
+	}
+
+	ranges := zScoreRanges(prefix, nil, nil)
+	require.Len(t, ranges, 1)
+	require.Equal(t, prefix, ranges[0].start)
+	require.Nil(t, ranges[0].end)
+
+	ranges = zScoreRanges(prefix, &schema.Score{Score: 1}, &schema.Score{Score: 2})
+	require.Len(t, ranges, 1)
+	from, to := bits(ranges[0])
+	require.Equal(t, math.Float64bits(1), from)
+	require.Equal(t, math.Float64bits(2)+1, to)
+
+	ranges = zScoreRanges(prefix, &schema.Score{Score: -2}, &schema.Score{Score: -1})
+	require.Len(t, ranges, 1)
+	from, to = bits(ranges[0])
+	require.Equal(t, math.Float64bits(-1), from)
+	require.Equal(t, math.Float64bits(-2)+1, to)
+
+	ranges = zScoreRanges(prefix, &schema.Score{Score: -1}, nil)
+	require.Len(t, ranges, 2)
+	from, to = bits(ranges[0])
+	require.Equal(t, uint64(0), from)
+	require.Equal(t, math.Float64bits(math.Inf(1))+1, to)
+	from, to = bits(ranges[1])
+	require.Equal(t, math.Float64bits(math.Copysign(0, -1)), from)
+	require.Equal(t, math.Float64bits(-1)+1, to)
+
+	require.Empty(t, zScoreRanges(prefix, &schema.Score{Score: 2}, &schema.Score{Score: 1}))
+}
+
+func TestStoreZScanScoreRanges(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	scores := []float64{-3, -2, -1, 0, 1, 2, 3}
+
+	for i, score := range scores {
+		key := []byte(fmt.Sprintf("key%d", i))
+
+		_, err := db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: key, Value: []byte("value")}}})
+		require.NoError(t, err)
+
+		_, err = db.ZAdd(&schema.ZAddRequest{Set: []byte("set"), Key: key, Score: score})
+		require.NoError(t, err)
+	}
+
+	scan := func(req *schema.ZScanRequest) []float64 {
+		req.Set = []byte("set")
+
+		list, err := db.ZScan(context.Background(), req)
+		require.NoError(t, err)
+
+		var scores []float64
+		for _, e := range list.Entries {
+			scores = append(scores, e.Score)
+		}
+		return scores
+	}
+
+	require.Equal(t, []float64{-1, -2}, scan(&schema.ZScanRequest{MinScore: &schema.Score{Score: -2}, MaxScore: &schema.Score{Score: -0.5}}))
+	require.Equal(t, []float64{-2, -1}, scan(&schema.ZScanRequest{MinScore: &schema.Score{Score: -2}, MaxScore: &schema.Score{Score: -0.5}, Desc: true}))
+	require.Equal(t, []float64{1, 2}, scan(&schema.ZScanRequest{MinScore: &schema.Score{Score: 0.5}, MaxScore: &schema.Score{Score: 2}}))
+	require.Equal(t, []float64{0, 1, -1}, scan(&schema.ZScanRequest{MinScore: &schema.Score{Score: -1}, MaxScore: &schema.Score{Score: 1}}))
+	require.Equal(t, []float64{-1, 1, 0}, scan(&schema.ZScanRequest{MinScore: &schema.Score{Score: -1}, MaxScore: &schema.Score{Score: 1}, Desc: true}))
+	require.Equal(t, []float64{-3, -2}, scan(&schema.ZScanRequest{MaxScore: &schema.Score{Score: 3}, Desc: true, Limit: 2}))
+	require.Equal(t, []float64{3, -1, -2, -3}, scan(&schema.ZScanRequest{
+		MinScore:      &schema.Score{Score: math.Inf(-1)},
+		MaxScore:      &schema.Score{Score: math.Inf(1)},
+		SeekKey:       []byte("key6"),
+		SeekScore:     3,
+		InclusiveSeek: true,
+	}))
+	require.Empty(t, scan(&schema.ZScanRequest{MinScore: &schema.Score{Score: 4}}))
+}
+
+func TestStoreZScanContinuation(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	scores := []float64{1, 2, 2, 3, 4, -1, 2}
+
+	for i, score := range scores {
+		key := []byte(fmt.Sprintf("key%d", i))
+
+		_, err := db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: key, Value: []byte("value")}}})
+		require.NoError(t, err)
+
+		_, err = db.ZAdd(&schema.ZAddRequest{Set: []byte("set"), Key: key, Score: score})
+		require.NoError(t, err)
+	}
+
+	scanAll := func(req *schema.ZScanRequest) []string {
+		var keys []string
+
+		for {
+			list, err := db.ZScan(context.Background(), req)
+			require.NoError(t, err)
+
+			for _, e := range list.Entries {
+				keys = append(keys, string(e.Key))
+			}
+
+			if list.Continuation == "" {
+				return keys
+			}
+
+			req.Continuation = list.Continuation
+		}
+	}
+
+	// entries are ordered by the indexed representation of their scores, negative scores come last
+	require.Equal(t,
+		[]string{"key0", "key1", "key2", "key6", "key3", "key4", "key5"},
+		scanAll(&schema.ZScanRequest{Set: []byte("set"), Limit: 2}),
+	)
+
+	require.Equal(t,
+		[]string{"key5", "key4", "key3", "key6", "key2", "key1", "key0"},
+		scanAll(&schema.ZScanRequest{Set: []byte("set"), Limit: 2, Desc: true}),
+	)
+
+	require.Equal(t,
+		[]string{"key6", "key2", "key1"},
+		scanAll(&schema.ZScanRequest{
+			Set:      []byte("set"),
+			Limit:    2,
+			Desc:     true,
+			MinScore: &schema.Score{Score: 2},
+			MaxScore: &schema.Score{Score: 2},
+		}),
+	)
+
+	require.Equal(t,
+		[]string{"key5", "key3", "key6", "key2", "key1", "key0"},
+		scanAll(&schema.ZScanRequest{Set: []byte("set"), Limit: 3, Desc: true, MaxScore: &schema.Score{Score: 3}}),
+	)
+
+	require.Equal(t,
+		[]string{"key0", "key1", "key2", "key6", "key5"},
+		scanAll(&schema.ZScanRequest{Set: []byte("set"), Limit: 1, MinScore: &schema.Score{Score: -1}, MaxScore: &schema.Score{Score: 2}}),
+	)
+
+	list, err := db.ZScan(context.Background(), &schema.ZScanRequest{Set: []byte("set"), Limit: 2})
+	require.NoError(t, err)
+	require.NotEmpty(t, list.Continuation)
+
+	_, err = db.ZScan(context.Background(), &schema.ZScanRequest{Set: []byte("set"), Continuation: list.Continuation, Desc: true})
+	require.Equal(t, ErrInvalidContinuation, err)
+
+	_, err = db.ZScan(context.Background(), &schema.ZScanRequest{Set: []byte("other"), Continuation: list.Continuation})
+	require.Equal(t, ErrInvalidContinuation, err)
+
+	_, err = db.ZScan(context.Background(), &schema.ZScanRequest{Set: []byte("set"), Continuation: "invalid"})
+	require.Equal(t, ErrInvalidContinuation, err)
+
+	_, err = db.ZScan(context.Background(), &schema.ZScanRequest{Set: []byte("set"), Continuation: list.Continuation, SeekKey: []byte("key0")})
+	require.Equal(t, store.ErrIllegalArguments, err)
+
+	// entries added meanwhile don't affect the position of resumed scans
+	_, err = db.ZAdd(&schema.ZAddRequest{Set: []byte("set"), Key: []byte("key0"), Score: 0})
+	require.NoError(t, err)
+
+	list, err = db.ZScan(context.Background(), &schema.ZScanRequest{Set: []byte("set"), Continuation: list.Continuation, Limit: 1})
+	require.NoError(t, err)
+	require.Equal(t, []byte("key2"), list.Entries[0].Key)
+}
+
+func TestStoreZScanLexBounds(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	keys := []string{"a", "b", "bb", "c", "d"}
+
+	for _, k := range keys {
+		_, err := db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte(k), Value: []byte(k)}}})
+		require.NoError(t, err)
+
+		_, err = db.ZAdd(&schema.ZAddRequest{Set: []byte("set"), Key: []byte(k), Score: 1})
+		require.NoError(t, err)
+	}
+
+	_, err := db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("other"), Value: []byte("other")}}})
+	require.NoError(t, err)
+
+	_, err = db.ZAdd(&schema.ZAddRequest{Set: []byte("set"), Key: []byte("other"), Score: 2})
+	require.NoError(t, err)
+
+	scan := func(req *schema.ZScanRequest) []string {
+		req.Set = []byte("set")
+		req.MinScore = &schema.Score{Score: 1}
+		req.MaxScore = &schema.Score{Score: 1}
+
+		list, err := db.ZScan(context.Background(), req)
+		require.NoError(t, err)
+
+		var members []string
+		for _, e := range list.Entries {
+			members = append(members, string(e.Key))
+		}
+		return members
+	}
+
+	// members sharing a score are ordered by key length first, then lexicographically
+	require.Equal(t, []string{"a", "b", "c", "d", "bb"}, scan(&schema.ZScanRequest{}))
+	require.Equal(t, []string{"bb", "d", "c", "b", "a"}, scan(&schema.ZScanRequest{Desc: true}))
+
+	require.Equal(t, []string{"b", "c", "bb"}, scan(&schema.ZScanRequest{
+		MinKey: &schema.LexBound{Key: []byte("b")},
+		MaxKey: &schema.LexBound{Key: []byte("c")},
+	}))
+
+	require.Equal(t, []string{"c", "bb"}, scan(&schema.ZScanRequest{
+		MinKey: &schema.LexBound{Key: []byte("b"), Exclusive: true},
+		MaxKey: &schema.LexBound{Key: []byte("d"), Exclusive: true},
+	}))
+
+	require.Equal(t, []string{"bb"}, scan(&schema.ZScanRequest{
+		MinKey: &schema.LexBound{Key: []byte("b"), Exclusive: true},
+		MaxKey: &schema.LexBound{Key: []byte("d"), Exclusive: true},
+		Desc:   true,
+		Limit:  1,
+	}))
+
+	require.Empty(t, scan(&schema.ZScanRequest{
+		MinKey: &schema.LexBound{Key: []byte("c")},
+		MaxKey: &schema.LexBound{Key: []byte("b")},
+	}))
+
+	// lexicographic bounds are applied to members of any score
+	list, err := db.ZScan(context.Background(), &schema.ZScanRequest{
+		Set:    []byte("set"),
+		MinKey: &schema.LexBound{Key: []byte("d")},
+	})
+	require.NoError(t, err)
+	require.Len(t, list.Entries, 2)
+	require.Equal(t, []byte("d"), list.Entries[0].Key)
+	require.Equal(t, []byte("other"), list.Entries[1].Key)
+
+	// scans filtered by lexicographic bounds are resumed across scores
+	var members []string
+	req := &schema.ZScanRequest{
+		Set:    []byte("set"),
+		MinKey: &schema.LexBound{Key: []byte("b")},
+		MaxKey: &schema.LexBound{Key: []byte("p")},
+		Desc:   true,
+		Limit:  2,
+	}
+
+	for {
+		list, err := db.ZScan(context.Background(), req)
+		require.NoError(t, err)
+
+		for _, e := range list.Entries {
+			members = append(members, string(e.Key))
+		}
+
+		if list.Continuation == "" {
+			break
+		}
+		req.Continuation = list.Continuation
+	}
+
+	require.Equal(t, []string{"other", "bb", "d", "c", "b"}, members)
+}
+
+func TestZMemberEncoding(t *testing.T) {
+	for _, k := range [][]byte{{}, {0}, []byte("a"), []byte("ab")} {
+		decoded, err := decodeZMember(encodeZMember(k))
+		require.NoError(t, err)
+		require.Equal(t, k, decoded)
+	}
+
+	_, err := decodeZMember([]byte("a"))
+	require.Equal(t, store.ErrCorruptedIndex, err)
+
+	_, err = decodeZMember([]byte{0, 0, 0, 0, 0, 0, 0, 2, 'a'})
+	require.Equal(t, store.ErrCorruptedIndex, err)
+}
+
+func TestStoreZSetWithBaselineLayout(t *testing.T) {
+	d, closer := makeDb()
+	defer closer()
+
+	// zKey = [SortedSetKeyPrefix, len(set), set, score, len(key), key, atTx] as written by previous releases
+	zKey := func(set string, score float64, key string, atTx uint64) []byte {
+		k := []byte{SortedSetKeyPrefix}
+		k = append(k, make([]byte, setLenLen)...)
+		binary.BigEndian.PutUint64(k[1:], uint64(len(set)))
+		k = append(k, set...)
+
+		b := make([]byte, scoreLen+keyLenLen)
+		binary.BigEndian.PutUint64(b, math.Float64bits(score))
+		binary.BigEndian.PutUint64(b[scoreLen:], uint64(1+len(key)))
+		k = append(k, b...)
+		k = append(k, SetKeyPrefix)
+		k = append(k, key...)
+
+		tx := make([]byte, txIDLen)
+		binary.BigEndian.PutUint64(tx, atTx)
+		return append(k, tx...)
+	}
+
+	for i, k := range []string{"b", "aa", "a"} {
+		_, err := d.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte(k), Value: []byte(k)}}})
+		require.NoError(t, err)
+
+		_, err = d.(*db).st.Commit([]*store.KV{{Key: zKey("set", float64(i%2), k, 0)}}, true)
+		require.NoError(t, err)
+	}
+
+	list, err := d.ZScan(context.Background(), &schema.ZScanRequest{Set: []byte("set")})
+	require.NoError(t, err)
+	require.Len(t, list.Entries, 3)
+	require.Equal(t, []byte("a"), list.Entries[0].Key)
+	require.Equal(t, []byte("a"), list.Entries[0].Entry.Value)
+	require.Equal(t, []byte("b"), list.Entries[1].Key)
+	require.Equal(t, []byte("aa"), list.Entries[2].Key)
+
+	list, err = d.ZScan(context.Background(), &schema.ZScanRequest{Set: []byte("set"), MinKey: &schema.LexBound{Key: []byte("aa")}})
+	require.NoError(t, err)
+	require.Len(t, list.Entries, 2)
+	require.Equal(t, []byte("b"), list.Entries[0].Key)
+	require.Equal(t, []byte("aa"), list.Entries[1].Key)
+
+	count, err := d.ZCount(context.Background(), &schema.ZCountRequest{Set: []byte("set"), MinScore: &schema.Score{Score: 0}, MaxScore: &schema.Score{Score: 0}})
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), count.Count)
+
+	rank, err := d.ZRank(context.Background(), &schema.ZRankRequest{Set: []byte("set"), Key: []byte("aa")})
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), rank.Rank)
+	require.Equal(t, float64(1), rank.Score)
+
+	// entries written by ZAdd share the layout of the existing ones
+	require.Equal(t, zKey("set", 1, "aa", 0), WrapZAddReferenceAt([]byte("set"), 1, EncodeKey([]byte("aa")), 0))
+
+	incr, err := d.ZIncrBy(&schema.ZIncrByRequest{Set: []byte("set"), Key: []byte("aa"), Increment: 2})
+	require.NoError(t, err)
+	require.Equal(t, float64(3), incr.Score)
+
+	_, err = d.ZRem(&schema.ZRemRequest{Set: []byte("set"), Key: []byte("b")})
+	require.NoError(t, err)
+
+	popped, err := d.ZPopMin(&schema.ZPopRequest{Set: []byte("set")})
+	require.NoError(t, err)
+	require.Len(t, popped.Entries, 1)
+	require.Equal(t, []byte("a"), popped.Entries[0].Key)
+
+	popped, err = d.ZPopMax(&schema.ZPopRequest{Set: []byte("set")})
+	require.NoError(t, err)
+	require.Len(t, popped.Entries, 1)
+	require.Equal(t, []byte("aa"), popped.Entries[0].Key)
+	require.Equal(t, float64(3), popped.Entries[0].Score)
+}
+
+func TestStoreZScanAtTx(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	for i, score := range []float64{1, 2, 3} {
+		key := []byte(fmt.Sprintf("key%d", i))
+
+		_, err := db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: key, Value: []byte(fmt.Sprintf("value%d", i))}}})
+		require.NoError(t, err)
+
+		_, err = db.ZAdd(&schema.ZAddRequest{Set: []byte("set"), Key: key, Score: score})
+		require.NoError(t, err)
+	}
+
+	pinned, err := db.CurrentState()
+	require.NoError(t, err)
+
+	_, err = db.ZRem(&schema.ZRemRequest{Set: []byte("set"), Key: []byte("key0")})
+	require.NoError(t, err)
+
+	// values updated or deleted afterwards don't affect the members as of the pinned tx
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("key1"), Value: []byte("updated")}}})
+	require.NoError(t, err)
+
+	_, err = db.Delete(&schema.DeleteKeysRequest{Keys: [][]byte{[]byte("key2")}})
+	require.NoError(t, err)
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("key3"), Value: []byte("value")}}})
+	require.NoError(t, err)
+
+	_, err = db.ZAdd(&schema.ZAddRequest{Set: []byte("set"), Key: []byte("key3"), Score: 4})
+	require.NoError(t, err)
+
+	keys := func(req *schema.ZScanRequest) []string {
+		list, err := db.ZScan(context.Background(), req)
+		require.NoError(t, err)
+
+		var ks []string
+		for _, e := range list.Entries {
+			ks = append(ks, string(e.Key))
+		}
+		return ks
+	}
+
+	require.Equal(t, []string{"key1", "key3"}, keys(&schema.ZScanRequest{Set: []byte("set")}))
+	require.Equal(t, []string{"key0", "key1", "key2"}, keys(&schema.ZScanRequest{Set: []byte("set"), AtTx: pinned.TxId}))
+	require.Equal(t, []string{"key2", "key1", "key0"}, keys(&schema.ZScanRequest{Set: []byte("set"), AtTx: pinned.TxId, Desc: true}))
+
+	current, err := db.ZScan(context.Background(), &schema.ZScanRequest{Set: []byte("set")})
+	require.NoError(t, err)
+	require.Equal(t, []byte("updated"), current.Entries[0].Entry.Value)
+
+	asOf, err := db.ZScan(context.Background(), &schema.ZScanRequest{Set: []byte("set"), AtTx: pinned.TxId})
+	require.NoError(t, err)
+	require.Len(t, asOf.Entries, 3)
+
+	for i, e := range asOf.Entries {
+		require.Equal(t, []byte(fmt.Sprintf("key%d", i)), e.Entry.Key)
+		require.Equal(t, []byte(fmt.Sprintf("value%d", i)), e.Entry.Value)
+		require.LessOrEqual(t, e.Entry.Tx, pinned.TxId)
+	}
+
+	page := &schema.ZScanRequest{Set: []byte("set"), AtTx: pinned.TxId, Limit: 2}
+	list, err := db.ZScan(context.Background(), page)
+	require.NoError(t, err)
+	require.Len(t, list.Entries, 2)
+	require.NotEmpty(t, list.Continuation)
+
+	page.Continuation = list.Continuation
+	require.Equal(t, []string{"key2"}, keys(page))
+
+	_, err = db.ZScan(context.Background(), &schema.ZScanRequest{Set: []byte("set"), AtTx: pinned.TxId + 100})
+	require.Equal(t, store.ErrIllegalArguments, err)
+}
+
+func TestStoreZScanNoValues(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	for i, score := range []float64{1, 2} {
+		key := []byte(fmt.Sprintf("key%d", i))
+
+		_, err := db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: key, Value: []byte("value")}}})
+		require.NoError(t, err)
+
+		_, err = db.ZAdd(&schema.ZAddRequest{Set: []byte("set"), Key: key, Score: score})
+		require.NoError(t, err)
+	}
+
+	withValues, err := db.ZScan(context.Background(), &schema.ZScanRequest{Set: []byte("set")})
+	require.NoError(t, err)
+
+	list, err := db.ZScan(context.Background(), &schema.ZScanRequest{Set: []byte("set"), NoValues: true})
+	require.NoError(t, err)
+	require.Len(t, list.Entries, 2)
+
+	for i, e := range list.Entries {
+		require.Nil(t, e.Entry)
+		require.Equal(t, withValues.Entries[i].Key, e.Key)
+		require.Equal(t, withValues.Entries[i].Score, e.Score)
+		require.Equal(t, withValues.Entries[i].AtTx, e.AtTx)
+	}
+
+	beforeExpiration, err := db.CurrentState()
+	require.NoError(t, err)
+
+	// members whose key expired are skipped as when values are resolved
+	expireKey(t, db, "key0")
+
+	list, err = db.ZScan(context.Background(), &schema.ZScanRequest{Set: []byte("set"), NoValues: true})
+	require.NoError(t, err)
+	require.Len(t, list.Entries, 1)
+	require.Equal(t, []byte("key1"), list.Entries[0].Key)
+
+	list, err = db.ZScan(context.Background(), &schema.ZScanRequest{Set: []byte("set"), NoValues: true, AtTx: beforeExpiration.TxId})
+	require.NoError(t, err)
+	require.Len(t, list.Entries, 2)
+
+	// as well as members whose key was deleted
+	_, err = db.Delete(&schema.DeleteKeysRequest{Keys: [][]byte{[]byte("key1")}})
+	require.NoError(t, err)
+
+	list, err = db.ZScan(context.Background(), &schema.ZScanRequest{Set: []byte("set"), NoValues: true})
+	require.NoError(t, err)
+	require.Empty(t, list.Entries)
+
+	list, err = db.ZScan(context.Background(), &schema.ZScanRequest{Set: []byte("set"), NoValues: true, AtTx: beforeExpiration.TxId})
+	require.NoError(t, err)
+	require.Len(t, list.Entries, 2)
+}
+
+// expireKey assigns the key a value which already expired
+func expireKey(t *testing.T, db DB, key string) {
+	_, err := db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{
+		{Key: []byte(key), Value: []byte("expired"), ExpiresAt: time.Now().Add(-time.Minute).Unix()},
+	}})
+	require.NoError(t, err)
+}
@@ -16,7 +16,9 @@ limitations under the License.
 package database
 
 import (
+	"context"
 	"errors"
+	"sort"
 	"strings"
 
 	"github.com/codenotary/immudb/embedded/sql"
@@ -148,15 +150,25 @@ func (d *db) ListTables() (*schema.SQLQueryResult, error) {
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
 
-	db, err := d.sqlEngine.Catalog().GetDatabaseByName(d.options.dbName)
+	stats, err := d.tableStatsList()
 	if err != nil {
 		return nil, err
 	}
 
-	res := &schema.SQLQueryResult{Columns: []*schema.Column{{Name: "TABLE", Type: sql.VarcharType}}}
+	res := &schema.SQLQueryResult{Columns: []*schema.Column{
+		{Name: "TABLE", Type: sql.VarcharType},
+		{Name: "ROWS", Type: sql.IntegerType},
+		{Name: "BYTES", Type: sql.IntegerType},
+		{Name: "LAST_MODIFIED_TX", Type: sql.IntegerType},
+	}}
 
-	for _, t := range db.GetTables() {
-		res.Rows = append(res.Rows, &schema.Row{Values: []*schema.SQLValue{{Value: &schema.SQLValue_S{S: t.Name()}}}})
+	for _, s := range stats {
+		res.Rows = append(res.Rows, &schema.Row{Values: []*schema.SQLValue{
+			{Value: &schema.SQLValue_S{S: s.Table}},
+			{Value: &schema.SQLValue_N{N: s.Rows}},
+			{Value: &schema.SQLValue_N{N: s.Bytes}},
+			{Value: &schema.SQLValue_N{N: s.LastModifiedTx}},
+		}})
 	}
 
 	return res, nil
@@ -178,7 +190,13 @@ func (d *db) DescribeTable(tableName string) (*schema.SQLQueryResult, error) {
 		{Name: "INDEX", Type: sql.VarcharType},
 	}}
 
+	cols := make([]*sql.Column, 0, len(table.ColsByID()))
 	for _, c := range table.ColsByID() {
+		cols = append(cols, c)
+	}
+	sort.Slice(cols, func(i, j int) bool { return cols[i].ID() < cols[j].ID() })
+
+	for _, c := range cols {
 		index := "NO"
 
 		if table.PrimaryKey().Name() == c.Name() {
@@ -211,7 +229,57 @@ func (d *db) SQLExec(req *schema.SQLExecRequest) (*schema.SQLExecResult, error)
 		return nil, ErrIllegalArguments
 	}
 
-	stmts, err := sql.Parse(strings.NewReader(req.Sql))
+	stmts, err := parseSQLExecStmts(req.Sql)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.DryRun {
+		return d.sqlExecDryRun(stmts, req.Params)
+	}
+
+	return d.SQLExecPrepared(stmts, req.Params, !req.NoWait)
+}
+
+// sqlExecDryRun compiles DML statements within a transaction which is then discarded, the entries which would be
+// written are described instead. Keys are reported as stored, i.e. encoded row and index keys
+func (d *db) sqlExecDryRun(stmts []sql.SQLStmt, namedParams []*schema.NamedParam) (*schema.SQLExecResult, error) {
+	if d.options.replica {
+		return nil, ErrIsReplica
+	}
+
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	tx, err := d.sqlEngine.NewTx(sql.SnapshotIsolation)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Cancel()
+
+	err = tx.ExecPreparedStmts(stmts, rawParams(namedParams))
+	if err == sql.ErrDDLNotSupportedInTx {
+		return nil, ErrDryRunDDL
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := tx.ValidateWrites()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([][]byte, len(entries))
+	for i, e := range entries {
+		keys[i] = e.Key
+	}
+
+	return &schema.SQLExecResult{DryRun: dryRunResult(entries, keys)}, nil
+}
+
+func parseSQLExecStmts(src string) ([]sql.SQLStmt, error) {
+	stmts, err := sql.Parse(strings.NewReader(src))
 	if err != nil {
 		return nil, err
 	}
@@ -229,7 +297,7 @@ func (d *db) SQLExec(req *schema.SQLExecRequest) (*schema.SQLExecResult, error)
 		}
 	}
 
-	return d.SQLExecPrepared(stmts, req.Params, !req.NoWait)
+	return stmts, nil
 }
 
 func (d *db) SQLExecPrepared(stmts []sql.SQLStmt, namedParams []*schema.NamedParam, waitForIndexing bool) (*schema.SQLExecResult, error) {
@@ -237,14 +305,14 @@ func (d *db) SQLExecPrepared(stmts []sql.SQLStmt, namedParams []*schema.NamedPar
 		return nil, ErrIllegalArguments
 	}
 
+	if d.options.replica {
+		return nil, ErrIsReplica
+	}
+
 	d.mutex.RLock()
 	defer d.mutex.RUnlock()
 
-	params := make(map[string]interface{})
-
-	for _, p := range namedParams {
-		params[p.Name] = schema.RawValue(p.Value)
-	}
+	params := rawParams(namedParams)
 
 	ddTxs, dmTxs, err := d.sqlEngine.ExecPreparedStmts(stmts, params, waitForIndexing)
 	if err != nil {
@@ -272,10 +340,18 @@ func (d *db) UseSnapshot(req *schema.UseSnapshotRequest) error {
 		return ErrIllegalArguments
 	}
 
-	return d.sqlEngine.UseSnapshot(req.SinceTx, req.AsBeforeTx)
+	err := d.sqlEngine.UseSnapshot(req.SinceTx, req.AsBeforeTx)
+	if err != nil {
+		return err
+	}
+
+	// cached results may have been read with a different snapshot
+	d.sqlCache.clear()
+
+	return nil
 }
 
-func (d *db) SQLQuery(req *schema.SQLQueryRequest) (*schema.SQLQueryResult, error) {
+func (d *db) SQLQuery(ctx context.Context, req *schema.SQLQueryRequest) (*schema.SQLQueryResult, error) {
 	if req == nil {
 		return nil, ErrIllegalArguments
 	}
@@ -290,10 +366,28 @@ func (d *db) SQLQuery(req *schema.SQLQueryRequest) (*schema.SQLQueryResult, erro
 		return nil, ErrIllegalArguments
 	}
 
-	return d.SQLQueryPrepared(stmt, req.Params, !req.ReuseSnapshot)
+	var snapshotTx uint64
+
+	if req.Snapshot != "" {
+		d.mutex.RLock()
+		txID, err := d.pinnedSnapshotTx(req.Snapshot)
+		d.mutex.RUnlock()
+		if err != nil {
+			return nil, err
+		}
+
+		stmt.AsBefore(txID + 1)
+		snapshotTx = txID
+	}
+
+	if d.sqlCache.enabled() && !req.ReuseSnapshot {
+		return d.sqlCachedQuery(ctx, stmt, req, snapshotTx)
+	}
+
+	return d.SQLQueryPrepared(ctx, stmt, req.Params, !req.ReuseSnapshot)
 }
 
-func (d *db) SQLQueryPrepared(stmt *sql.SelectStmt, namedParams []*schema.NamedParam, renewSnapshot bool) (*schema.SQLQueryResult, error) {
+func (d *db) SQLQueryPrepared(ctx context.Context, stmt *sql.SelectStmt, namedParams []*schema.NamedParam, renewSnapshot bool) (*schema.SQLQueryResult, error) {
 	if stmt == nil {
 		return nil, ErrIllegalArguments
 	}
@@ -305,16 +399,17 @@ func (d *db) SQLQueryPrepared(stmt *sql.SelectStmt, namedParams []*schema.NamedP
 	d.mutex.RLock()
 	defer d.mutex.RUnlock()
 
-	params := make(map[string]interface{})
-
-	for _, p := range namedParams {
-		params[p.Name] = schema.RawValue(p.Value)
-	}
+	params := rawParams(namedParams)
 
 	r, err := d.sqlEngine.QueryPreparedStmt(stmt, params, renewSnapshot)
 	if err != nil {
 		return nil, err
 	}
+
+	return sqlQueryResult(ctx, r)
+}
+
+func sqlQueryResult(ctx context.Context, r sql.RowReader) (*schema.SQLQueryResult, error) {
 	defer r.Close()
 
 	colDescriptors, err := r.Columns()
@@ -331,6 +426,10 @@ func (d *db) SQLQueryPrepared(stmt *sql.SelectStmt, namedParams []*schema.NamedP
 	res := &schema.SQLQueryResult{Columns: cols}
 
 	for l := 0; l < MaxKeyScanLimit; l++ {
+		if ctx.Err() != nil {
+			return nil, ErrOperationCancelled
+		}
+
 		row, err := r.Read()
 		if err == sql.ErrNoMoreRows {
 			break
@@ -0,0 +1,295 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"encoding/binary"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/codenotary/immudb/embedded/sql"
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/embedded/tbtree"
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/golang/protobuf/proto"
+)
+
+// DefaultSQLCacheSize is the maximum number of SQL query results cached by each database
+const DefaultSQLCacheSize = 1_000
+
+// maxSQLCacheValidationTxs is the maximum number of transactions read to validate a cached result,
+// results cached further behind are recomputed instead
+const maxSQLCacheValidationTxs = 1_000
+
+var sqlRowPrefix = append([]byte{SQLPrefix}, sql.RowPrefix...)
+
+type sqlTableID struct {
+	dbID    uint64
+	tableID uint64
+}
+
+type sqlCachedResult struct {
+	key string
+	res *schema.SQLQueryResult
+
+	// tables read by the query
+	tables map[sqlTableID]struct{}
+
+	// the result is known to be unchanged up to these transactions
+	txID        uint64
+	catalogTxID uint64
+}
+
+// sqlCache holds the results of SQL queries keyed by the query, its parameters and the snapshot it was read
+// from. A result is served while no transaction committed after it was computed wrote rows of the tables
+// read by the query nor changed the catalog, least recently used results are dropped first
+type sqlCache struct {
+	size int
+
+	results map[string]*list.Element
+	order   *list.List
+
+	// holder used to read the transactions committed after a result was computed
+	tx *store.Tx
+
+	mutex sync.Mutex
+}
+
+func newSQLCache(size int, st *store.ImmuStore) *sqlCache {
+	c := &sqlCache{
+		size:    size,
+		results: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+
+	if c.enabled() {
+		c.tx = st.NewTx()
+	}
+
+	return c
+}
+
+func (c *sqlCache) enabled() bool {
+	return c.size > 0
+}
+
+// get returns the result cached under key if it's still valid as of the given transactions
+func (c *sqlCache) get(key string, st *store.ImmuStore, txID, catalogTxID uint64, sharedCatalog bool) (*schema.SQLQueryResult, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	el, ok := c.results[key]
+	if !ok {
+		return nil, false
+	}
+
+	r := el.Value.(*sqlCachedResult)
+
+	valid := r.txID <= txID && r.catalogTxID <= catalogTxID && (sharedCatalog || r.catalogTxID == catalogTxID)
+
+	if valid && r.txID < txID {
+		valid = c.unchangedSince(r, st, txID)
+	}
+
+	if !valid {
+		c.order.Remove(el)
+		delete(c.results, key)
+		return nil, false
+	}
+
+	r.txID = txID
+	r.catalogTxID = catalogTxID
+
+	c.order.MoveToFront(el)
+
+	return r.res, true
+}
+
+// unchangedSince tells whether none of the transactions committed after the result was computed, up to txID,
+// wrote rows of the tables read by the query or changed the catalog
+func (c *sqlCache) unchangedSince(r *sqlCachedResult, st *store.ImmuStore, txID uint64) bool {
+	if txID-r.txID > maxSQLCacheValidationTxs {
+		return false
+	}
+
+	for id := r.txID + 1; id <= txID; id++ {
+		err := st.ReadTx(id, c.tx)
+		if err != nil {
+			return false
+		}
+
+		for _, e := range c.tx.Entries() {
+			key := e.Key()
+
+			if len(key) == 0 || key[0] != SQLPrefix {
+				continue
+			}
+
+			if !bytes.HasPrefix(key, sqlRowPrefix) || len(key) < len(sqlRowPrefix)+2*sql.EncIDLen {
+				// catalog changes may alter the tables read by the query
+				return false
+			}
+
+			off := len(sqlRowPrefix)
+
+			tid := sqlTableID{
+				dbID:    binary.BigEndian.Uint64(key[off:]),
+				tableID: binary.BigEndian.Uint64(key[off+sql.EncIDLen:]),
+			}
+
+			if _, ok := r.tables[tid]; ok {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func (c *sqlCache) put(r *sqlCachedResult) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if el, ok := c.results[r.key]; ok {
+		c.order.Remove(el)
+	}
+
+	c.results[r.key] = c.order.PushFront(r)
+
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.results, oldest.Value.(*sqlCachedResult).key)
+	}
+}
+
+func (c *sqlCache) clear() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.results = make(map[string]*list.Element)
+	c.order.Init()
+}
+
+// sqlCacheKey identifies the results of a query, parameters are sorted by name so their order doesn't matter
+func sqlCacheKey(query string, namedParams []*schema.NamedParam, snapshotTx uint64) (string, error) {
+	params := make([]*schema.NamedParam, len(namedParams))
+	copy(params, namedParams)
+
+	sort.Slice(params, func(i, j int) bool { return params[i].Name < params[j].Name })
+
+	var b strings.Builder
+
+	var encTx [8]byte
+	binary.BigEndian.PutUint64(encTx[:], snapshotTx)
+	b.Write(encTx[:])
+
+	b.WriteString(query)
+
+	for _, p := range params {
+		encVal, err := proto.Marshal(p.Value)
+		if err != nil {
+			return "", err
+		}
+
+		b.WriteByte(0)
+		b.WriteString(p.Name)
+		b.WriteByte(0)
+		b.Write(encVal)
+	}
+
+	return b.String(), nil
+}
+
+// sqlCachedQuery serves the query from the cache when possible, otherwise the query is resolved on a snapshot
+// including the latest transaction so the result can be validated against the transactions committed afterwards
+func (d *db) sqlCachedQuery(ctx context.Context, stmt *sql.SelectStmt, req *schema.SQLQueryRequest, snapshotTx uint64) (*schema.SQLQueryResult, error) {
+	if stmt.Limit() > MaxKeyScanLimit {
+		return nil, ErrMaxKeyScanLimitExceeded
+	}
+
+	key, err := sqlCacheKey(req.Sql, req.Params, snapshotTx)
+	if err != nil {
+		return nil, err
+	}
+
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	txID, _ := d.st.Alh()
+	catalogTxID, _ := d.catalogSt.Alh()
+	sharedCatalog := d.catalogSt == d.st
+
+	res, ok := d.sqlCache.get(key, d.st, txID, catalogTxID, sharedCatalog)
+	if ok {
+		return proto.Clone(res).(*schema.SQLQueryResult), nil
+	}
+
+	err = d.st.WaitForIndexingUpto(txID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = d.sqlEngine.RenewSnapshot()
+	if err != nil && err != tbtree.ErrReadersNotClosed {
+		return nil, err
+	}
+
+	snap, err := d.sqlEngine.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	// snapshots are only replaced by newer ones, the query is resolved on a snapshot at least as recent
+	cacheable := snap.Ts() >= txID
+
+	tables, err := d.sqlEngine.ReferencedTables(stmt)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := d.sqlEngine.QueryPreparedStmt(stmt, rawParams(req.Params), false)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err = sqlQueryResult(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	if cacheable {
+		cached := &sqlCachedResult{
+			key:         key,
+			res:         proto.Clone(res).(*schema.SQLQueryResult),
+			tables:      make(map[sqlTableID]struct{}, len(tables)),
+			txID:        txID,
+			catalogTxID: catalogTxID,
+		}
+
+		for _, t := range tables {
+			cached.tables[sqlTableID{dbID: t.Database().ID(), tableID: t.ID()}] = struct{}{}
+		}
+
+		d.sqlCache.put(cached)
+	}
+
+	return res, nil
+}
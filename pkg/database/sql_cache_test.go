@@ -0,0 +1,153 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLCache(t *testing.T) {
+	d, closer := makeDb()
+	defer closer()
+
+	cache := d.(*db).sqlCache
+	require.True(t, cache.enabled())
+
+	_, err := d.SQLExec(&schema.SQLExecRequest{Sql: `
+		CREATE TABLE table1(id INTEGER, title VARCHAR, PRIMARY KEY id);
+		CREATE TABLE table2(id INTEGER, PRIMARY KEY id);
+		INSERT INTO table1(id, title) VALUES (1, 'title1'), (2, 'title2');
+	`})
+	require.NoError(t, err)
+
+	params := []*schema.NamedParam{
+		{Name: "id", Value: &schema.SQLValue{Value: &schema.SQLValue_N{N: 0}}},
+		{Name: "title", Value: &schema.SQLValue{Value: &schema.SQLValue_S{S: "title"}}},
+	}
+
+	query := func(sql string) *schema.SQLQueryResult {
+		res, err := d.SQLQuery(context.Background(), &schema.SQLQueryRequest{Sql: sql, Params: params})
+		require.NoError(t, err)
+		return res
+	}
+
+	query1 := func() *schema.SQLQueryResult {
+		return query("SELECT id FROM table1 WHERE id > @id AND title > @title")
+	}
+
+	// a row appended to the cached results tells whether they are served from the cache
+	mark := func() {
+		cache.mutex.Lock()
+		defer cache.mutex.Unlock()
+
+		for el := cache.order.Front(); el != nil; el = el.Next() {
+			r := el.Value.(*sqlCachedResult)
+			r.res.Rows = append(r.res.Rows, &schema.Row{Columns: []string{"cached"}})
+		}
+	}
+
+	cached := func(res *schema.SQLQueryResult) bool {
+		return len(res.Rows) > 0 && res.Rows[len(res.Rows)-1].Columns[0] == "cached"
+	}
+
+	res := query1()
+	require.Len(t, res.Rows, 2)
+	require.False(t, cached(res))
+	require.Equal(t, 1, cache.order.Len())
+
+	mark()
+
+	res = query1()
+	require.True(t, cached(res))
+
+	// parameters are matched regardless of their order
+	params[0], params[1] = params[1], params[0]
+	require.True(t, cached(query1()))
+
+	// returned results are copies of the cached ones
+	res.Rows = nil
+	require.True(t, cached(query1()))
+
+	_, err = d.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("key"), Value: []byte("value")}}})
+	require.NoError(t, err)
+
+	_, err = d.SQLExec(&schema.SQLExecRequest{Sql: "INSERT INTO table2(id) VALUES (1)"})
+	require.NoError(t, err)
+
+	require.True(t, cached(query1()))
+
+	_, err = d.SQLExec(&schema.SQLExecRequest{Sql: "UPSERT INTO table1(id, title) VALUES (3, 'title3')"})
+	require.NoError(t, err)
+
+	res = query1()
+	require.False(t, cached(res))
+	require.Len(t, res.Rows, 3)
+
+	res = query("SELECT id FROM table2 INNER JOIN table1 ON table2.id = table1.id")
+	require.False(t, cached(res))
+	require.Len(t, res.Rows, 1)
+	require.Equal(t, 2, cache.order.Len())
+
+	mark()
+
+	require.True(t, cached(query1()))
+	require.True(t, cached(query("SELECT id FROM table2 INNER JOIN table1 ON table2.id = table1.id")))
+
+	res, err = d.SQLQuery(context.Background(), &schema.SQLQueryRequest{Sql: "SELECT id FROM table1", ReuseSnapshot: true})
+	require.NoError(t, err)
+	require.Len(t, res.Rows, 3)
+	require.Equal(t, 2, cache.order.Len())
+
+	_, err = d.SQLExec(&schema.SQLExecRequest{Sql: "UPSERT INTO table2(id) VALUES (2)"})
+	require.NoError(t, err)
+
+	require.True(t, cached(query1()))
+	require.False(t, cached(query("SELECT id FROM table2 INNER JOIN table1 ON table2.id = table1.id")))
+
+	mark()
+
+	// catalog changes invalidate all the results
+	_, err = d.SQLExec(&schema.SQLExecRequest{Sql: "CREATE TABLE table3(id INTEGER, PRIMARY KEY id)"})
+	require.NoError(t, err)
+
+	require.False(t, cached(query1()))
+
+	err = d.UseSnapshot(&schema.UseSnapshotRequest{})
+	require.NoError(t, err)
+	require.Equal(t, 0, cache.order.Len())
+}
+
+func TestSQLCacheEviction(t *testing.T) {
+	d, closer := makeDb()
+	defer closer()
+
+	cache := newSQLCache(2, d.(*db).st)
+	require.True(t, cache.enabled())
+
+	for _, key := range []string{"q1", "q2", "q1", "q3"} {
+		cache.put(&sqlCachedResult{key: key, res: &schema.SQLQueryResult{}})
+	}
+
+	require.Equal(t, 2, cache.order.Len())
+	require.Contains(t, cache.results, "q1")
+	require.Contains(t, cache.results, "q3")
+
+	require.False(t, newSQLCache(0, d.(*db).st).enabled())
+}
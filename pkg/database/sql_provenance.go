@@ -0,0 +1,222 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+
+	"github.com/codenotary/immudb/embedded/sql"
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+)
+
+// SQLRowProvenance returns the versions of a row along with the entries written for each of them, and the
+// transaction which assigned every column its value, so each value can be verified against the transaction
+// it was written in. Versions are returned oldest first
+func (d *db) SQLRowProvenance(req *schema.SQLRowProvenanceRequest) (*schema.SQLRowVersions, error) {
+	if req == nil || req.PkValue == nil {
+		return nil, ErrIllegalArguments
+	}
+
+	if req.Limit > MaxKeyScanLimit {
+		return nil, ErrMaxKeyScanLimitExceeded
+	}
+
+	limit := int(req.Limit)
+	if limit == 0 {
+		limit = MaxKeyScanLimit
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	table, err := d.sqlEngine.Catalog().GetTableByName(d.options.dbName, req.Table)
+	if err != nil {
+		return nil, err
+	}
+
+	pkEncVal, err := sql.EncodeRawValue(schema.RawValue(req.PkValue), table.PrimaryKey().Type(), true)
+	if err != nil {
+		return nil, err
+	}
+
+	dbID := sql.EncodeID(table.Database().ID())
+	tableID := sql.EncodeID(table.ID())
+
+	pkKey := sql.MapKey([]byte{SQLPrefix}, sql.RowPrefix, dbID, tableID, sql.EncodeID(table.PrimaryKey().ID()), pkEncVal)
+
+	colTypes := make(map[uint64]sql.SQLValueType, len(table.ColsByID()))
+
+	res := &schema.SQLRowVersions{
+		DatabaseId:   table.Database().ID(),
+		TableId:      table.ID(),
+		PkName:       table.PrimaryKey().Name(),
+		ColNamesById: make(map[uint64]string, len(table.ColsByID())),
+		ColTypesById: make(map[uint64]string, len(table.ColsByID())),
+	}
+
+	var colIDs []uint64
+
+	for id, c := range table.ColsByID() {
+		colTypes[id] = c.Type()
+		colIDs = append(colIDs, id)
+		res.ColNamesById[id] = c.Name()
+		res.ColTypesById[id] = c.Type()
+	}
+
+	sort.Slice(colIDs, func(i, j int) bool { return colIDs[i] < colIDs[j] })
+
+	lastTxID, _ := d.st.Alh()
+	err = d.st.WaitForIndexingUpto(lastTxID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// previous encoded value of each column and the transaction it was assigned in,
+	// every version up to the requested ones has to be read for them to be known
+	prevValues := make(map[uint64][]byte, len(colIDs))
+	prevTxs := make(map[uint64]uint64, len(colIDs))
+
+	var offset uint64
+
+	for len(res.Versions) < limit {
+		txs, err := d.st.History(pkKey, offset, false, MaxKeyScanLimit)
+		if err == store.ErrNoMoreEntries {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, txID := range txs {
+			val, err := d.readValue(pkKey, txID, d.tx1)
+			if err != nil {
+				return nil, err
+			}
+
+			values, err := decodeRowValues(val, colTypes)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, id := range colIDs {
+				encVal := values[id]
+
+				_, seen := prevTxs[id]
+				if !seen || !bytes.Equal(prevValues[id], encVal) {
+					prevValues[id] = encVal
+					prevTxs[id] = txID
+				}
+			}
+
+			if offset < req.Offset {
+				offset++
+				continue
+			}
+			offset++
+
+			version := &schema.SQLRowVersion{
+				Entry: &schema.SQLEntry{Key: pkKey, Value: val, Tx: txID},
+			}
+
+			for _, id := range colIDs {
+				c := table.ColsByID()[id]
+
+				value := &schema.SQLValue{Value: &schema.SQLValue_Null{}}
+
+				encVal, ok := values[id]
+				if ok {
+					tv, _, err := sql.DecodeValue(encVal, c.Type())
+					if err != nil {
+						return nil, err
+					}
+					value = typedValueToRowValue(tv)
+
+					indexed, err := table.IsIndexed(c.Name())
+					if err != nil {
+						return nil, err
+					}
+
+					if indexed && id != table.PrimaryKey().ID() {
+						encKeyVal, err := sql.EncodeValue(tv, c.Type(), true)
+						if err != nil {
+							return nil, err
+						}
+
+						version.IndexEntries = append(version.IndexEntries, &schema.SQLEntry{
+							Key: sql.MapKey([]byte{SQLPrefix}, sql.RowPrefix, dbID, tableID, sql.EncodeID(id), encKeyVal, pkEncVal),
+							Tx:  txID,
+						})
+					}
+				}
+
+				version.Columns = append(version.Columns, &schema.SQLColumnVersion{
+					Name:  c.Name(),
+					Value: value,
+					Tx:    prevTxs[id],
+				})
+			}
+
+			res.Versions = append(res.Versions, version)
+
+			if len(res.Versions) == limit {
+				break
+			}
+		}
+	}
+
+	return res, nil
+}
+
+// decodeRowValues returns the encoded value of each column of a row entry, null columns are not included
+func decodeRowValues(encodedRow []byte, colTypes map[uint64]sql.SQLValueType) (map[uint64][]byte, error) {
+	off := 0
+
+	if len(encodedRow) < off+sql.EncLenLen {
+		return nil, sql.ErrCorruptedData
+	}
+
+	colsCount := binary.BigEndian.Uint32(encodedRow[off:])
+	off += sql.EncLenLen
+
+	values := make(map[uint64][]byte, colsCount)
+
+	for i := 0; i < int(colsCount); i++ {
+		if len(encodedRow) < off+sql.EncIDLen {
+			return nil, sql.ErrCorruptedData
+		}
+
+		colID := binary.BigEndian.Uint64(encodedRow[off:])
+		off += sql.EncIDLen
+
+		colType, ok := colTypes[colID]
+		if !ok {
+			return nil, sql.ErrCorruptedData
+		}
+
+		_, n, err := sql.DecodeValue(encodedRow[off:], colType)
+		if err != nil {
+			return nil, err
+		}
+
+		values[colID] = encodedRow[off : off+n]
+		off += n
+	}
+
+	return values, nil
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"testing"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLRowProvenance(t *testing.T) {
+	d, closer := makeDb()
+	defer closer()
+
+	_, err := d.SQLRowProvenance(nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = d.SQLExec(&schema.SQLExecRequest{Sql: `
+		CREATE TABLE table1(id INTEGER, title VARCHAR, amount INTEGER, PRIMARY KEY id);
+		CREATE INDEX ON table1(title);
+	`})
+	require.NoError(t, err)
+
+	pk := &schema.SQLValue{Value: &schema.SQLValue_N{N: 1}}
+
+	_, err = d.SQLRowProvenance(&schema.SQLRowProvenanceRequest{Table: "table1", PkValue: pk})
+	require.Equal(t, store.ErrKeyNotFound, err)
+
+	_, err = d.SQLRowProvenance(&schema.SQLRowProvenanceRequest{Table: "table2", PkValue: pk})
+	require.Error(t, err)
+
+	_, err = d.SQLRowProvenance(&schema.SQLRowProvenanceRequest{Table: "table1", PkValue: pk, Limit: MaxKeyScanLimit + 1})
+	require.Equal(t, ErrMaxKeyScanLimitExceeded, err)
+
+	md1, err := d.SQLExec(&schema.SQLExecRequest{Sql: "INSERT INTO table1(id, title, amount) VALUES (1, 'title1', 10)"})
+	require.NoError(t, err)
+	tx1 := md1.Dtxs[0].Id
+
+	md2, err := d.SQLExec(&schema.SQLExecRequest{Sql: "UPSERT INTO table1(id, title, amount) VALUES (1, 'title1', 20)"})
+	require.NoError(t, err)
+	tx2 := md2.Dtxs[0].Id
+
+	md3, err := d.SQLExec(&schema.SQLExecRequest{Sql: "UPSERT INTO table1(id, title, amount) VALUES (1, 'title3', 20)"})
+	require.NoError(t, err)
+	tx3 := md3.Dtxs[0].Id
+
+	res, err := d.SQLRowProvenance(&schema.SQLRowProvenanceRequest{Table: "table1", PkValue: pk})
+	require.NoError(t, err)
+	require.Equal(t, "id", res.PkName)
+	require.Len(t, res.ColNamesById, 3)
+	require.Len(t, res.Versions, 3)
+
+	columnTxs := func(v *schema.SQLRowVersion) map[string]uint64 {
+		txs := make(map[string]uint64, len(v.Columns))
+		for _, c := range v.Columns {
+			txs[c.Name] = c.Tx
+		}
+		return txs
+	}
+
+	require.Equal(t, tx1, res.Versions[0].Entry.Tx)
+	require.Equal(t, map[string]uint64{"id": tx1, "title": tx1, "amount": tx1}, columnTxs(res.Versions[0]))
+
+	require.Equal(t, tx2, res.Versions[1].Entry.Tx)
+	require.Equal(t, map[string]uint64{"id": tx1, "title": tx1, "amount": tx2}, columnTxs(res.Versions[1]))
+
+	require.Equal(t, tx3, res.Versions[2].Entry.Tx)
+	require.Equal(t, map[string]uint64{"id": tx1, "title": tx3, "amount": tx2}, columnTxs(res.Versions[2]))
+
+	for _, v := range res.Versions {
+		require.Len(t, v.IndexEntries, 1)
+
+		entry, err := d.VerifiableSQLGet(&schema.VerifiableSQLGetRequest{
+			SqlGetRequest: &schema.SQLGetRequest{Table: "table1", PkValue: pk, AtTx: v.Entry.Tx},
+			ProveSinceTx:  v.Entry.Tx,
+		})
+		require.NoError(t, err)
+		require.Equal(t, v.Entry.Key, entry.SqlEntry.Key)
+		require.Equal(t, v.Entry.Value, entry.SqlEntry.Value)
+
+		txs, err := d.(*db).st.History(v.IndexEntries[0].Key, 0, false, 10)
+		require.NoError(t, err)
+		require.Contains(t, txs, v.IndexEntries[0].Tx)
+	}
+
+	for _, c := range res.Versions[2].Columns {
+		if c.Name == "title" {
+			require.Equal(t, "title3", c.Value.GetS())
+		}
+	}
+
+	res, err = d.SQLRowProvenance(&schema.SQLRowProvenanceRequest{Table: "table1", PkValue: pk, Offset: 1, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, res.Versions, 1)
+	require.Equal(t, tx2, res.Versions[0].Entry.Tx)
+	require.Equal(t, map[string]uint64{"id": tx1, "title": tx1, "amount": tx2}, columnTxs(res.Versions[0]))
+
+	res, err = d.SQLRowProvenance(&schema.SQLRowProvenanceRequest{Table: "table1", PkValue: pk, Offset: 3})
+	require.NoError(t, err)
+	require.Empty(t, res.Versions)
+}
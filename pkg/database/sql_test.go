@@ -16,7 +16,9 @@ limitations under the License.
 package database
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/codenotary/immudb/embedded/sql"
 	"github.com/codenotary/immudb/embedded/store"
@@ -77,19 +79,19 @@ func TestSQLExecAndQuery(t *testing.T) {
 	err = db.UseSnapshot(&schema.UseSnapshotRequest{SinceTx: 0})
 	require.NoError(t, err)
 
-	_, err = db.SQLQueryPrepared(nil, nil, false)
+	_, err = db.SQLQueryPrepared(context.Background(), nil, nil, false)
 	require.Equal(t, ErrIllegalArguments, err)
 
-	_, err = db.SQLQuery(nil)
+	_, err = db.SQLQuery(context.Background(), nil)
 	require.Equal(t, ErrIllegalArguments, err)
 
-	_, err = db.SQLQuery(&schema.SQLQueryRequest{Sql: "invalid sql statement"})
+	_, err = db.SQLQuery(context.Background(), &schema.SQLQueryRequest{Sql: "invalid sql statement"})
 	require.Error(t, err)
 
-	_, err = db.SQLQuery(&schema.SQLQueryRequest{Sql: "CREATE INDEX ON table1(title)"})
+	_, err = db.SQLQuery(context.Background(), &schema.SQLQueryRequest{Sql: "CREATE INDEX ON table1(title)"})
 	require.Equal(t, ErrIllegalArguments, err)
 
-	res, err = db.SQLQuery(&schema.SQLQueryRequest{Sql: "SELECT t.id, t.id as id2, title, active, payload FROM (table1 as t) WHERE id <= 3 AND active != @active", Params: params})
+	res, err = db.SQLQuery(context.Background(), &schema.SQLQueryRequest{Sql: "SELECT t.id, t.id as id2, title, active, payload FROM (table1 as t) WHERE id <= 3 AND active != @active", Params: params})
 	require.NoError(t, err)
 	require.Len(t, res.Rows, 2)
 
@@ -134,3 +136,202 @@ func TestSQLExecAndQuery(t *testing.T) {
 	require.Equal(t, store.ErrKeyNotFound, err)
 
 }
+
+func TestSQLTx(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	_, err := db.SQLExec(&schema.SQLExecRequest{Sql: "CREATE TABLE table1(id INTEGER, title VARCHAR, PRIMARY KEY id)"})
+	require.NoError(t, err)
+
+	_, err = db.SQLExec(&schema.SQLExecRequest{Sql: "INSERT INTO table1(id, title) VALUES (1, 'title1')"})
+	require.NoError(t, err)
+
+	err = db.SQLTxExec(nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = db.SQLTxQuery(context.Background(), nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = db.CommitSQLTx(nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	err = db.RollbackSQLTx(nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	err = db.SQLTxExec(&schema.SQLTxExecRequest{TransactionId: "unknown"})
+	require.Equal(t, ErrSQLTxNotFound, err)
+
+	tx, err := db.NewSQLTx(&schema.NewSQLTxRequest{})
+	require.NoError(t, err)
+	require.NotEmpty(t, tx.TransactionId)
+
+	state, err := db.CurrentState()
+	require.NoError(t, err)
+	require.Equal(t, state.TxId, tx.SnapshotTx)
+
+	_, err = db.SQLExec(&schema.SQLExecRequest{Sql: "INSERT INTO table1(id, title) VALUES (2, 'title2')"})
+	require.NoError(t, err)
+
+	err = db.SQLTxExec(&schema.SQLTxExecRequest{TransactionId: tx.TransactionId, Sql: "USE DATABASE db1"})
+	require.Error(t, err)
+
+	err = db.SQLTxExec(&schema.SQLTxExecRequest{
+		TransactionId: tx.TransactionId,
+		Sql:           "INSERT INTO table1(id, title) VALUES (@id, 'title3')",
+		Params:        []*schema.NamedParam{{Name: "id", Value: &schema.SQLValue{Value: &schema.SQLValue_N{N: 3}}}},
+	})
+	require.NoError(t, err)
+
+	// the row written by the transaction is read, the one committed concurrently is not
+	res, err := db.SQLTxQuery(context.Background(), &schema.SQLTxQueryRequest{TransactionId: tx.TransactionId, Sql: "SELECT id FROM table1"})
+	require.NoError(t, err)
+	require.Len(t, res.Rows, 2)
+
+	_, err = db.SQLTxQuery(context.Background(), &schema.SQLTxQueryRequest{TransactionId: tx.TransactionId, Sql: "INSERT INTO table1(id) VALUES (4)"})
+	require.Equal(t, ErrIllegalArguments, err)
+
+	md, err := db.CommitSQLTx(&schema.SQLTxRequest{TransactionId: tx.TransactionId})
+	require.NoError(t, err)
+	require.Equal(t, tx.SnapshotTx, md.SnapshotTx)
+	require.Len(t, md.Dtxs, 1)
+
+	_, err = db.CommitSQLTx(&schema.SQLTxRequest{TransactionId: tx.TransactionId})
+	require.Equal(t, ErrSQLTxNotFound, err)
+
+	res, err = db.SQLQuery(context.Background(), &schema.SQLQueryRequest{Sql: "SELECT id FROM table1"})
+	require.NoError(t, err)
+	require.Len(t, res.Rows, 3)
+
+	tx, err = db.NewSQLTx(&schema.NewSQLTxRequest{})
+	require.NoError(t, err)
+
+	err = db.RollbackSQLTx(&schema.SQLTxRequest{TransactionId: tx.TransactionId})
+	require.NoError(t, err)
+
+	err = db.RollbackSQLTx(&schema.SQLTxRequest{TransactionId: tx.TransactionId})
+	require.Equal(t, ErrSQLTxNotFound, err)
+
+	// transactions left open are discarded when the database is closed
+	_, err = db.NewSQLTx(&schema.NewSQLTxRequest{})
+	require.NoError(t, err)
+}
+
+func TestSQLTxConflict(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	_, err := db.SQLExec(&schema.SQLExecRequest{Sql: "CREATE TABLE table1(id INTEGER, title VARCHAR, PRIMARY KEY id)"})
+	require.NoError(t, err)
+
+	_, err = db.NewSQLTx(nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = db.NewSQLTx(&schema.NewSQLTxRequest{Isolation: schema.SQLTxIsolation(10)})
+	require.Equal(t, ErrIllegalArguments, err)
+
+	tx1, err := db.NewSQLTx(&schema.NewSQLTxRequest{Isolation: schema.SQLTxIsolation_SNAPSHOT})
+	require.NoError(t, err)
+
+	tx2, err := db.NewSQLTx(&schema.NewSQLTxRequest{Isolation: schema.SQLTxIsolation_READ_COMMITTED})
+	require.NoError(t, err)
+	require.Equal(t, schema.SQLTxIsolation_READ_COMMITTED, tx2.Isolation)
+
+	err = db.SQLTxExec(&schema.SQLTxExecRequest{TransactionId: tx1.TransactionId, Sql: "UPSERT INTO table1(id, title) VALUES (1, 'tx1')"})
+	require.NoError(t, err)
+
+	err = db.SQLTxExec(&schema.SQLTxExecRequest{TransactionId: tx2.TransactionId, Sql: "UPSERT INTO table1(id, title) VALUES (1, 'tx2')"})
+	require.NoError(t, err)
+
+	_, err = db.SQLExec(&schema.SQLExecRequest{Sql: "UPSERT INTO table1(id, title) VALUES (1, 'title1')"})
+	require.NoError(t, err)
+
+	_, err = db.CommitSQLTx(&schema.SQLTxRequest{TransactionId: tx1.TransactionId})
+	require.Equal(t, ErrSQLTxConflict, err)
+
+	_, err = db.CommitSQLTx(&schema.SQLTxRequest{TransactionId: tx2.TransactionId})
+	require.NoError(t, err)
+}
+
+func TestSQLTxExpiration(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	db.GetOptions().WithSQLTxTimeout(10 * time.Millisecond)
+
+	_, err := db.SQLExec(&schema.SQLExecRequest{Sql: "CREATE TABLE table1(id INTEGER, PRIMARY KEY id)"})
+	require.NoError(t, err)
+
+	tx1, err := db.NewSQLTx(&schema.NewSQLTxRequest{})
+	require.NoError(t, err)
+
+	tx2, err := db.NewSQLTx(&schema.NewSQLTxRequest{})
+	require.NoError(t, err)
+
+	time.Sleep(20 * time.Millisecond)
+
+	// any request aborts the transactions which stayed idle for too long
+	tx3, err := db.NewSQLTx(&schema.NewSQLTxRequest{})
+	require.NoError(t, err)
+
+	err = db.SQLTxExec(&schema.SQLTxExecRequest{TransactionId: tx1.TransactionId, Sql: "INSERT INTO table1(id) VALUES (1)"})
+	require.Equal(t, ErrSQLTxExpired, err)
+
+	err = db.SQLTxExec(&schema.SQLTxExecRequest{TransactionId: tx1.TransactionId, Sql: "INSERT INTO table1(id) VALUES (1)"})
+	require.Equal(t, ErrSQLTxNotFound, err)
+
+	err = db.SQLTxExec(&schema.SQLTxExecRequest{TransactionId: tx3.TransactionId, Sql: "INSERT INTO table1(id) VALUES (1)"})
+	require.NoError(t, err)
+
+	_, err = db.CommitSQLTx(&schema.SQLTxRequest{TransactionId: tx3.TransactionId})
+	require.NoError(t, err)
+
+	time.Sleep(40 * time.Millisecond)
+
+	// expired transactions are eventually forgotten
+	err = db.RollbackSQLTx(&schema.SQLTxRequest{TransactionId: tx2.TransactionId})
+	require.Equal(t, ErrSQLTxNotFound, err)
+
+	db.GetOptions().WithSQLTxTimeout(0)
+
+	tx4, err := db.NewSQLTx(&schema.NewSQLTxRequest{})
+	require.NoError(t, err)
+
+	time.Sleep(20 * time.Millisecond)
+
+	err = db.RollbackSQLTx(&schema.SQLTxRequest{TransactionId: tx4.TransactionId})
+	require.NoError(t, err)
+}
+
+func TestSQLExecDryRun(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	_, err := db.SQLExec(&schema.SQLExecRequest{Sql: "CREATE TABLE table1(id INTEGER, title VARCHAR, PRIMARY KEY id)"})
+	require.NoError(t, err)
+
+	_, err = db.SQLExec(&schema.SQLExecRequest{Sql: "CREATE TABLE table2(id INTEGER, PRIMARY KEY id)", DryRun: true})
+	require.Equal(t, ErrDryRunDDL, err)
+
+	_, err = db.SQLExec(&schema.SQLExecRequest{Sql: "INSERT INTO table3(id) VALUES (1)", DryRun: true})
+	require.Error(t, err)
+
+	state, err := db.CurrentState()
+	require.NoError(t, err)
+
+	res, err := db.SQLExec(&schema.SQLExecRequest{Sql: "INSERT INTO table1(id, title) VALUES (1, 'title1'), (2, 'title2')", DryRun: true})
+	require.NoError(t, err)
+	require.Empty(t, res.Dtxs)
+	require.NotNil(t, res.DryRun)
+	require.Greater(t, res.DryRun.Nentries, uint32(0))
+	require.Len(t, res.DryRun.Keys, int(res.DryRun.Nentries))
+	require.Greater(t, res.DryRun.Bytes, uint64(0))
+
+	current, err := db.CurrentState()
+	require.NoError(t, err)
+	require.Equal(t, state.TxId, current.TxId)
+
+	rows, err := db.SQLQuery(context.Background(), &schema.SQLQueryRequest{Sql: "SELECT id FROM table1"})
+	require.NoError(t, err)
+	require.Empty(t, rows.Rows)
+}
@@ -0,0 +1,266 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/codenotary/immudb/embedded/sql"
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/rs/xid"
+)
+
+// DefaultSQLTxTimeout is how long an interactive SQL transaction can stay idle before being aborted
+const DefaultSQLTxTimeout = 1 * time.Minute
+
+type sqlTxEntry struct {
+	tx *sql.SQLTx
+
+	// time of the latest request, or of the expiration once the transaction was aborted
+	lastUsed time.Time
+	expired  bool
+}
+
+// NewSQLTx opens an interactive SQL transaction with the requested isolation level.
+// The id to be used on subsequent requests is returned along with the tx id of the snapshot used for its reads
+func (d *db) NewSQLTx(req *schema.NewSQLTxRequest) (*schema.SQLTx, error) {
+	if req == nil {
+		return nil, ErrIllegalArguments
+	}
+
+	if d.options.replica {
+		return nil, ErrIsReplica
+	}
+
+	var isolation sql.IsolationLevel
+
+	switch req.Isolation {
+	case schema.SQLTxIsolation_SNAPSHOT:
+		isolation = sql.SnapshotIsolation
+	case schema.SQLTxIsolation_READ_COMMITTED:
+		isolation = sql.ReadCommitted
+	default:
+		return nil, ErrIllegalArguments
+	}
+
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	tx, err := d.sqlEngine.NewTx(isolation)
+	if err != nil {
+		return nil, err
+	}
+
+	id := xid.New().String()
+
+	d.sqlTxsMutex.Lock()
+	d.expireSQLTxs(time.Now())
+	d.sqlTxs[id] = &sqlTxEntry{tx: tx, lastUsed: time.Now()}
+	d.sqlTxsMutex.Unlock()
+
+	return &schema.SQLTx{TransactionId: id, SnapshotTx: tx.SnapshotTx(), Isolation: req.Isolation}, nil
+}
+
+func (d *db) SQLTxExec(req *schema.SQLTxExecRequest) error {
+	if req == nil {
+		return ErrIllegalArguments
+	}
+
+	tx, err := d.sqlTx(req.TransactionId)
+	if err != nil {
+		return err
+	}
+
+	stmts, err := parseSQLExecStmts(req.Sql)
+	if err != nil {
+		return err
+	}
+
+	return tx.ExecPreparedStmts(stmts, rawParams(req.Params))
+}
+
+func (d *db) SQLTxQuery(ctx context.Context, req *schema.SQLTxQueryRequest) (*schema.SQLQueryResult, error) {
+	if req == nil {
+		return nil, ErrIllegalArguments
+	}
+
+	tx, err := d.sqlTx(req.TransactionId)
+	if err != nil {
+		return nil, err
+	}
+
+	stmts, err := sql.Parse(strings.NewReader(req.Sql))
+	if err != nil {
+		return nil, err
+	}
+
+	stmt, ok := stmts[0].(*sql.SelectStmt)
+	if !ok {
+		return nil, ErrIllegalArguments
+	}
+
+	if stmt.Limit() > MaxKeyScanLimit {
+		return nil, ErrMaxKeyScanLimitExceeded
+	}
+
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	r, err := tx.QueryPreparedStmt(stmt, rawParams(req.Params))
+	if err != nil {
+		return nil, err
+	}
+
+	return sqlQueryResult(ctx, r)
+}
+
+// CommitSQLTx atomically commits the writes of the transaction, the result includes the tx id of the snapshot used for its reads.
+// ErrSQLTxConflict is returned when a concurrent transaction updated any of the written keys, the transaction can then be retried
+func (d *db) CommitSQLTx(req *schema.SQLTxRequest) (*schema.SQLExecResult, error) {
+	if req == nil {
+		return nil, ErrIllegalArguments
+	}
+
+	tx, err := d.takeSQLTx(req.TransactionId)
+	if err != nil {
+		return nil, err
+	}
+
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	res := &schema.SQLExecResult{SnapshotTx: tx.SnapshotTx()}
+
+	txmd, err := tx.Commit(true)
+	if err == sql.ErrSerializationConflict {
+		return nil, ErrSQLTxConflict
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if txmd != nil {
+		res.Dtxs = []*schema.TxMetadata{schema.TxMetatadaTo(txmd)}
+	}
+
+	return res, nil
+}
+
+func (d *db) RollbackSQLTx(req *schema.SQLTxRequest) error {
+	if req == nil {
+		return ErrIllegalArguments
+	}
+
+	tx, err := d.takeSQLTx(req.TransactionId)
+	if err != nil {
+		return err
+	}
+
+	return tx.Cancel()
+}
+
+func (d *db) sqlTx(id string) (*sql.SQLTx, error) {
+	d.sqlTxsMutex.Lock()
+	defer d.sqlTxsMutex.Unlock()
+
+	return d.lookupSQLTx(id, false)
+}
+
+func (d *db) takeSQLTx(id string) (*sql.SQLTx, error) {
+	d.sqlTxsMutex.Lock()
+	defer d.sqlTxsMutex.Unlock()
+
+	return d.lookupSQLTx(id, true)
+}
+
+// lookupSQLTx returns the transaction with the given id, refreshing its idle time unless it's removed.
+// It must be called while holding sqlTxsMutex
+func (d *db) lookupSQLTx(id string, remove bool) (*sql.SQLTx, error) {
+	now := time.Now()
+
+	d.expireSQLTxs(now)
+
+	e, ok := d.sqlTxs[id]
+	if !ok {
+		return nil, ErrSQLTxNotFound
+	}
+
+	if e.expired {
+		delete(d.sqlTxs, id)
+		return nil, ErrSQLTxExpired
+	}
+
+	if remove {
+		delete(d.sqlTxs, id)
+	} else {
+		e.lastUsed = now
+	}
+
+	return e.tx, nil
+}
+
+// expireSQLTxs aborts the transactions idle for longer than the timeout, so abandoned transactions can't hold
+// their snapshots forever. Aborted transactions are remembered during another timeout period, so clients
+// get ErrSQLTxExpired instead of ErrSQLTxNotFound. It must be called while holding sqlTxsMutex
+func (d *db) expireSQLTxs(now time.Time) {
+	timeout := d.options.sqlTxTimeout
+	if timeout <= 0 {
+		return
+	}
+
+	for id, e := range d.sqlTxs {
+		if now.Sub(e.lastUsed) <= timeout {
+			continue
+		}
+
+		if e.expired {
+			delete(d.sqlTxs, id)
+			continue
+		}
+
+		err := e.tx.Cancel()
+		if err != nil {
+			d.Logger.Warningf("unable to abort expired sql transaction %s: %v", id, err)
+		}
+
+		e.expired = true
+		e.lastUsed = now
+	}
+}
+
+func (d *db) cancelSQLTxs() {
+	d.sqlTxsMutex.Lock()
+	defer d.sqlTxsMutex.Unlock()
+
+	for id, e := range d.sqlTxs {
+		if !e.expired {
+			e.tx.Cancel()
+		}
+		delete(d.sqlTxs, id)
+	}
+}
+
+func rawParams(namedParams []*schema.NamedParam) map[string]interface{} {
+	params := make(map[string]interface{}, len(namedParams))
+
+	for _, p := range namedParams {
+		params[p.Name] = schema.RawValue(p.Value)
+	}
+
+	return params
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"sort"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+)
+
+// CompareState tells how the history of the database relates to the one known by a client, e.g. after the server
+// was restored from an old backup. Client states are matched against the server history in transaction order,
+// the last matching one is reported as the last common transaction and the first differing one as the point where
+// both histories forked. When the server is ahead of the client, a proof of their consistency is included
+func (d *db) CompareState(req *schema.StateComparisonRequest) (*schema.StateComparison, error) {
+	if req == nil || req.State == nil {
+		return nil, ErrIllegalArguments
+	}
+
+	states := make([]*schema.ImmutableState, 0, len(req.Checkpoints)+1)
+	states = append(states, req.State)
+	states = append(states, req.Checkpoints...)
+
+	for _, s := range states {
+		if s == nil || (s.TxId > 0 && len(s.TxHash) != sha256.Size) {
+			return nil, ErrIllegalArguments
+		}
+	}
+
+	sort.Slice(states, func(i, j int) bool { return states[i].TxId < states[j].TxId })
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	lastTxID, lastTxAlh := d.st.Alh()
+
+	cmp := &schema.StateComparison{
+		ServerTxId:   lastTxID,
+		ServerTxHash: lastTxAlh[:],
+	}
+
+	for _, s := range states {
+		if s.TxId == 0 || s.TxId > lastTxID {
+			continue
+		}
+
+		err := d.st.ReadTx(s.TxId, d.tx1)
+		if err != nil {
+			return nil, err
+		}
+
+		alh := d.tx1.Alh
+
+		if !bytes.Equal(alh[:], s.TxHash) {
+			// transaction hashes are chained, later states can not match either
+			cmp.FirstDivergentTx = s.TxId
+			break
+		}
+
+		cmp.LastCommonTx = s.TxId
+	}
+
+	switch {
+	case cmp.FirstDivergentTx > 0:
+		cmp.Relation = schema.StateRelation_STATE_FORKED
+	case req.State.TxId > lastTxID:
+		cmp.Relation = schema.StateRelation_STATE_SERVER_BEHIND
+	case req.State.TxId == lastTxID:
+		cmp.Relation = schema.StateRelation_STATE_EQUAL
+	default:
+		cmp.Relation = schema.StateRelation_STATE_SERVER_AHEAD
+
+		if req.State.TxId > 0 {
+			dualProof, err := dualProofBetween(d.st, d.tx1, d.tx2, req.State.TxId, lastTxID)
+			if err != nil {
+				return nil, err
+			}
+
+			cmp.DualProof = dualProof
+		}
+	}
+
+	return cmp, nil
+}
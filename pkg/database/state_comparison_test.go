@@ -0,0 +1,128 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"crypto/sha256"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareState(t *testing.T) {
+	rootPath := "data_" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	defer os.RemoveAll(rootPath)
+
+	db, err := NewDb(DefaultOption().WithDbRootPath(rootPath).WithDbName("db"), nil, logger.NewSimpleLogger("immudb ", os.Stderr))
+	require.NoError(t, err)
+	defer db.Close()
+
+	set := func(db DB, key, value string) *schema.ImmutableState {
+		_, err := db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte(key), Value: []byte(value)}}})
+		require.NoError(t, err)
+
+		state, err := db.CurrentState()
+		require.NoError(t, err)
+		return state
+	}
+
+	_, err = db.CompareState(nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = db.CompareState(&schema.StateComparisonRequest{State: &schema.ImmutableState{TxId: 1, TxHash: []byte{1}}})
+	require.Equal(t, ErrIllegalArguments, err)
+
+	state1 := set(db, "key1", "value1")
+
+	cmp, err := db.CompareState(&schema.StateComparisonRequest{State: state1})
+	require.NoError(t, err)
+	require.Equal(t, schema.StateRelation_STATE_EQUAL, cmp.Relation)
+	require.Equal(t, state1.TxId, cmp.LastCommonTx)
+	require.Zero(t, cmp.FirstDivergentTx)
+
+	set(db, "key2", "value2")
+	state3 := set(db, "key3", "value3")
+
+	cmp, err = db.CompareState(&schema.StateComparisonRequest{State: state1})
+	require.NoError(t, err)
+	require.Equal(t, schema.StateRelation_STATE_SERVER_AHEAD, cmp.Relation)
+	require.Equal(t, state3.TxId, cmp.ServerTxId)
+	require.Equal(t, state3.TxHash, cmp.ServerTxHash)
+	require.NotNil(t, cmp.DualProof)
+
+	verifies := store.VerifyDualProof(
+		schema.DualProofFrom(cmp.DualProof),
+		state1.TxId,
+		state3.TxId,
+		schema.DigestFrom(state1.TxHash),
+		schema.DigestFrom(state3.TxHash),
+	)
+	require.True(t, verifies)
+
+	// a backup of the database taken after its first transaction
+	catalogDB, err := NewDb(DefaultOption().WithDbRootPath(rootPath).WithDbName("catalog"), nil, logger.NewSimpleLogger("immudb ", os.Stderr))
+	require.NoError(t, err)
+	defer catalogDB.Close()
+
+	restoredOpts := DefaultOption().WithDbRootPath(rootPath).WithDbName("restored")
+
+	restored, err := NewDb(restoredOpts.WithReplica(true), catalogDB, logger.NewSimpleLogger("immudb ", os.Stderr))
+	require.NoError(t, err)
+
+	for txID := uint64(1); txID <= state1.TxId; txID++ {
+		etx, err := db.ExportTx(&schema.TxRequest{Tx: txID})
+		require.NoError(t, err)
+
+		_, err = restored.ReplicateTx(etx)
+		require.NoError(t, err)
+	}
+
+	err = restored.Close()
+	require.NoError(t, err)
+
+	restored, err = OpenDb(restoredOpts.WithReplica(false), catalogDB, logger.NewSimpleLogger("immudb ", os.Stderr))
+	require.NoError(t, err)
+	defer restored.Close()
+
+	cmp, err = restored.CompareState(&schema.StateComparisonRequest{State: state3, Checkpoints: []*schema.ImmutableState{state1}})
+	require.NoError(t, err)
+	require.Equal(t, schema.StateRelation_STATE_SERVER_BEHIND, cmp.Relation)
+	require.Equal(t, state1.TxId, cmp.ServerTxId)
+	require.Equal(t, state1.TxId, cmp.LastCommonTx)
+	require.Zero(t, cmp.FirstDivergentTx)
+
+	// once the restored database accepts new writes, its history forks from the original one
+	set(restored, "key2", "another value")
+	set(restored, "key3", "another value")
+
+	cmp, err = restored.CompareState(&schema.StateComparisonRequest{State: state3, Checkpoints: []*schema.ImmutableState{state1}})
+	require.NoError(t, err)
+	require.Equal(t, schema.StateRelation_STATE_FORKED, cmp.Relation)
+	require.Equal(t, state1.TxId, cmp.LastCommonTx)
+	require.Equal(t, state3.TxId, cmp.FirstDivergentTx)
+
+	cmp, err = restored.CompareState(&schema.StateComparisonRequest{State: &schema.ImmutableState{TxId: state1.TxId, TxHash: make([]byte, sha256.Size)}})
+	require.NoError(t, err)
+	require.Equal(t, schema.StateRelation_STATE_FORKED, cmp.Relation)
+	require.Zero(t, cmp.LastCommonTx)
+	require.Equal(t, state1.TxId, cmp.FirstDivergentTx)
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+	"sync"
+
+	"github.com/codenotary/immudb/embedded/sql"
+	"github.com/codenotary/immudb/embedded/store"
+)
+
+// TableStats ...
+type TableStats struct {
+	Table string
+	// Rows is the number of rows currently stored in the table
+	Rows uint64
+	// Bytes is the size of the entries written for the table, every row version and index entry is included
+	// as data is never overwritten
+	Bytes uint64
+	// LastModifiedTx is the latest transaction which wrote rows of the table, zero if there is none
+	LastModifiedTx uint64
+}
+
+type tableCounters struct {
+	rows           uint64
+	bytes          uint64
+	lastModifiedTx uint64
+}
+
+// tableStats accounts the rows written to each table by reading the committed transactions. Transactions are read
+// when statistics are requested, so the first request reads all the transactions of the database and later ones
+// only the transactions committed in between
+type tableStats struct {
+	// last transaction accounted
+	txID uint64
+
+	counters map[sqlTableID]*tableCounters
+
+	// holder used to read committed transactions, allocated on first use
+	tx *store.Tx
+
+	mutex sync.Mutex
+}
+
+func newTableStats() *tableStats {
+	return &tableStats{
+		counters: make(map[sqlTableID]*tableCounters),
+	}
+}
+
+// catchUp accounts the transactions committed up to txID, pkIDs holds the primary key column of each table.
+// The index must be up to date with txID, as it tells whether a primary key entry adds a row or updates it
+func (s *tableStats) catchUp(st *store.ImmuStore, txID uint64, pkIDs map[sqlTableID]uint64) error {
+	if s.tx == nil && s.txID < txID {
+		s.tx = st.NewTx()
+	}
+
+	for id := s.txID + 1; id <= txID; id++ {
+		err := st.ReadTx(id, s.tx)
+		if err != nil {
+			return err
+		}
+
+		for _, e := range s.tx.Entries() {
+			key := e.Key()
+
+			if !bytes.HasPrefix(key, sqlRowPrefix) || len(key) < len(sqlRowPrefix)+3*sql.EncIDLen {
+				continue
+			}
+
+			off := len(sqlRowPrefix)
+
+			tid := sqlTableID{
+				dbID:    binary.BigEndian.Uint64(key[off:]),
+				tableID: binary.BigEndian.Uint64(key[off+sql.EncIDLen:]),
+			}
+			colID := binary.BigEndian.Uint64(key[off+2*sql.EncIDLen:])
+
+			c, ok := s.counters[tid]
+			if !ok {
+				c = &tableCounters{}
+				s.counters[tid] = c
+			}
+
+			c.bytes += uint64(len(key) + e.VLen())
+			c.lastModifiedTx = id
+
+			pkID, ok := pkIDs[tid]
+			if !ok || colID != pkID {
+				continue
+			}
+
+			// rows are never deleted, a primary key entry adds a row when it's the first value of its key
+			txs, err := st.History(key, 0, false, 1)
+			if err != nil {
+				return err
+			}
+
+			if len(txs) > 0 && txs[0] == id {
+				c.rows++
+			}
+		}
+
+		s.txID = id
+	}
+
+	return nil
+}
+
+// TableStats returns the statistics of the tables of the database, sorted by name
+func (d *db) TableStats() ([]*TableStats, error) {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	return d.tableStatsList()
+}
+
+func (d *db) tableStatsList() ([]*TableStats, error) {
+	sqlDB, err := d.sqlEngine.Catalog().GetDatabaseByName(d.options.dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	tables := sqlDB.GetTables()
+
+	pkIDs := make(map[sqlTableID]uint64, len(tables))
+	for _, t := range tables {
+		pkIDs[sqlTableID{dbID: sqlDB.ID(), tableID: t.ID()}] = t.PrimaryKey().ID()
+	}
+
+	d.tableStats.mutex.Lock()
+	defer d.tableStats.mutex.Unlock()
+
+	txID, _ := d.st.Alh()
+
+	err = d.st.WaitForIndexingUpto(txID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = d.tableStats.catchUp(d.st, txID, pkIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*TableStats, len(tables))
+
+	for i, t := range tables {
+		list[i] = &TableStats{Table: t.Name()}
+
+		c, ok := d.tableStats.counters[sqlTableID{dbID: sqlDB.ID(), tableID: t.ID()}]
+		if ok {
+			list[i].Rows = c.rows
+			list[i].Bytes = c.bytes
+			list[i].LastModifiedTx = c.lastModifiedTx
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Table < list[j].Table })
+
+	return list, nil
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"testing"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTableStats(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	stats, err := db.TableStats()
+	require.NoError(t, err)
+	require.Empty(t, stats)
+
+	_, err = db.SQLExec(&schema.SQLExecRequest{Sql: `
+		CREATE TABLE table2(id INTEGER, PRIMARY KEY id);
+		CREATE TABLE table1(id INTEGER, title VARCHAR, PRIMARY KEY id);
+		CREATE INDEX ON table1(title);
+	`})
+	require.NoError(t, err)
+
+	md, err := db.SQLExec(&schema.SQLExecRequest{Sql: "INSERT INTO table1(id, title) VALUES (1, 'title1'), (2, 'title2')"})
+	require.NoError(t, err)
+
+	stats, err = db.TableStats()
+	require.NoError(t, err)
+	require.Len(t, stats, 2)
+	require.Equal(t, "table1", stats[0].Table)
+	require.Equal(t, uint64(2), stats[0].Rows)
+	require.Equal(t, md.Dtxs[0].Id, stats[0].LastModifiedTx)
+	require.NotZero(t, stats[0].Bytes)
+	require.Equal(t, &TableStats{Table: "table2"}, stats[1])
+
+	bytes := stats[0].Bytes
+
+	md, err = db.SQLExec(&schema.SQLExecRequest{Sql: "UPSERT INTO table1(id, title) VALUES (2, 'title2b'), (3, 'title3')"})
+	require.NoError(t, err)
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("key"), Value: []byte("value")}}})
+	require.NoError(t, err)
+
+	stats, err = db.TableStats()
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), stats[0].Rows)
+	require.Equal(t, md.Dtxs[0].Id, stats[0].LastModifiedTx)
+	require.Greater(t, stats[0].Bytes, bytes)
+	require.Equal(t, &TableStats{Table: "table2"}, stats[1])
+
+	res, err := db.ListTables()
+	require.NoError(t, err)
+	require.Len(t, res.Columns, 4)
+	require.Len(t, res.Rows, 2)
+	require.Equal(t, "table1", res.Rows[0].Values[0].GetS())
+	require.Equal(t, uint64(3), res.Rows[0].Values[1].GetN())
+	require.Equal(t, stats[0].Bytes, res.Rows[0].Values[2].GetN())
+	require.Equal(t, md.Dtxs[0].Id, res.Rows[0].Values[3].GetN())
+}
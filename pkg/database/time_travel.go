@@ -0,0 +1,50 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"github.com/codenotary/immudb/embedded/store"
+)
+
+// txAtTime must be called while holding the mutex, it returns the last transaction committed at or before the unix
+// time ts. As timestamps are assigned on commit they don't decrease along transactions, which are binary searched
+func (d *db) txAtTime(ts int64) (uint64, error) {
+	lastTxID, _ := d.st.Alh()
+
+	// transactions up to lo were committed at or before ts, the ones after hi were committed later
+	lo, hi := uint64(0), lastTxID
+
+	for lo < hi {
+		mid := lo + (hi-lo+1)/2
+
+		err := d.st.ReadTx(mid, d.tx1)
+		if err != nil {
+			return 0, err
+		}
+
+		if d.tx1.Ts <= ts {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+
+	if lo == 0 {
+		return 0, store.ErrKeyNotFound
+	}
+
+	return lo, nil
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAtTime(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	_, err := db.Get(&schema.KeyRequest{Key: []byte("k1"), AtTime: -1})
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = db.Get(&schema.KeyRequest{Key: []byte("k1"), AtTime: time.Now().Unix(), AtTx: 1})
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = db.Get(&schema.KeyRequest{Key: []byte("k1"), AtTime: time.Now().Unix(), Snapshot: "eom"})
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = db.Get(&schema.KeyRequest{Key: []byte("k1"), AtTime: 1})
+	require.Equal(t, store.ErrKeyNotFound, err)
+
+	md1, err := db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("k1"), Value: []byte("v1")}}})
+	require.NoError(t, err)
+
+	// timestamps have a resolution of seconds
+	time.Sleep(time.Until(time.Unix(md1.Ts+1, 0)))
+
+	md2, err := db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("k1"), Value: []byte("v2")}}})
+	require.NoError(t, err)
+	require.Greater(t, md2.Ts, md1.Ts)
+
+	_, err = db.Get(&schema.KeyRequest{Key: []byte("k1"), AtTime: md1.Ts - 1})
+	require.Equal(t, store.ErrKeyNotFound, err)
+
+	e, err := db.Get(&schema.KeyRequest{Key: []byte("k1"), AtTime: md1.Ts})
+	require.NoError(t, err)
+	require.Equal(t, []byte("v1"), e.Value)
+	require.Equal(t, md1.Id, e.Tx)
+
+	e, err = db.Get(&schema.KeyRequest{Key: []byte("k1"), AtTime: md2.Ts})
+	require.NoError(t, err)
+	require.Equal(t, []byte("v2"), e.Value)
+
+	e, err = db.Get(&schema.KeyRequest{Key: []byte("k1"), AtTime: time.Now().Add(time.Hour).Unix()})
+	require.NoError(t, err)
+	require.Equal(t, []byte("v2"), e.Value)
+
+	ve, err := db.VerifiableGet(&schema.VerifiableGetRequest{
+		KeyRequest:   &schema.KeyRequest{Key: []byte("k1"), AtTime: md1.Ts},
+		ProveSinceTx: md1.Id,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []byte("v1"), ve.Entry.Value)
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import "github.com/codenotary/immudb/pkg/api/schema"
+
+// DefaultTxRangeChunkSize is the number of transactions covered by each proof when no chunk size is requested
+const DefaultTxRangeChunkSize = 1000
+
+// MaxTxRangeChunks is the maximum number of proofs returned by a single request
+const MaxTxRangeChunks = 1000
+
+// TxRangeProofs returns one dual proof per chunk of chunkSize transactions in the range [initialTx, finalTx].
+// The proof of each chunk links its last transaction to the last one of the previous chunk, so after a bulk load
+// importers can verify the whole range, chained from a trusted state, and persist the evidence without per-entry
+// verified reads
+func (d *db) TxRangeProofs(req *schema.TxRangeProofsRequest) (*schema.VerifiableTxRange, error) {
+	if req == nil || req.InitialTx == 0 {
+		return nil, ErrIllegalArguments
+	}
+
+	lastTxID, _ := d.st.Alh()
+
+	finalTx := req.FinalTx
+	if finalTx == 0 {
+		finalTx = lastTxID
+	}
+
+	if finalTx < req.InitialTx || finalTx > lastTxID || req.ProveSinceTx >= req.InitialTx {
+		return nil, ErrIllegalArguments
+	}
+
+	chunkSize := uint64(req.ChunkSize)
+	if chunkSize == 0 {
+		chunkSize = DefaultTxRangeChunkSize
+	}
+
+	if (finalTx-req.InitialTx)/chunkSize >= MaxTxRangeChunks {
+		return nil, ErrTooManyTxRangeChunks
+	}
+
+	// dedicated txs are used so the database doesn't need to be locked while proofs are built
+	sourceTx := d.st.NewTx()
+	targetTx := d.st.NewTx()
+
+	sourceID := req.ProveSinceTx
+	if sourceID == 0 {
+		sourceID = req.InitialTx
+	}
+
+	err := d.st.ReadTx(sourceID, sourceTx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &schema.VerifiableTxRange{}
+
+	for firstTx := req.InitialTx; firstTx <= finalTx; firstTx += chunkSize {
+		lastTx := firstTx + chunkSize - 1
+		if lastTx > finalTx {
+			lastTx = finalTx
+		}
+
+		err = d.st.ReadTx(lastTx, targetTx)
+		if err != nil {
+			return nil, err
+		}
+
+		dualProof, err := d.st.DualProof(sourceTx, targetTx)
+		if err != nil {
+			return nil, err
+		}
+
+		res.Chunks = append(res.Chunks, &schema.TxRangeChunkProof{
+			FirstTx:   firstTx,
+			LastTx:    lastTx,
+			DualProof: schema.DualProofTo(dualProof),
+		})
+
+		sourceTx, targetTx = targetTx, sourceTx
+	}
+
+	alh := sourceTx.Alh
+
+	res.State = &schema.ImmutableState{
+		Db:            d.options.dbName,
+		TxId:          finalTx,
+		TxHash:        alh[:],
+		HashAlgorithm: uint32(d.st.HashAlgorithm()),
+	}
+
+	return res, nil
+}
+
+// ConsistencyProof returns the proof that targetTx is consistent with sourceTx, so a state at sourceTx, e.g. a
+// signed state archived long ago, can be validated against the state at targetTx
+func (d *db) ConsistencyProof(req *schema.ConsistencyProofRequest) (*schema.VerifiableConsistency, error) {
+	if req == nil || req.SourceTx == 0 {
+		return nil, ErrIllegalArguments
+	}
+
+	lastTxID, _ := d.st.Alh()
+
+	targetID := req.TargetTx
+	if targetID == 0 {
+		targetID = lastTxID
+	}
+
+	if targetID < req.SourceTx || targetID > lastTxID {
+		return nil, ErrIllegalArguments
+	}
+
+	// dedicated txs are used so the database doesn't need to be locked while the proof is built
+	sourceTx := d.st.NewTx()
+	targetTx := d.st.NewTx()
+
+	err := d.st.ReadTx(req.SourceTx, sourceTx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = d.st.ReadTx(targetID, targetTx)
+	if err != nil {
+		return nil, err
+	}
+
+	dualProof, err := d.st.DualProof(sourceTx, targetTx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schema.VerifiableConsistency{
+		DualProof: schema.DualProofTo(dualProof),
+		State: &schema.ImmutableState{
+			Db:            d.options.dbName,
+			TxId:          targetID,
+			TxHash:        targetTx.Alh[:],
+			HashAlgorithm: uint32(d.st.HashAlgorithm()),
+		},
+	}, nil
+}
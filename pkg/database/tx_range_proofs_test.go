@@ -0,0 +1,147 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"testing"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxRangeProofs(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	for i := 0; i < 10; i++ {
+		_, err := db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte{byte(i)}, Value: []byte{byte(i)}}}})
+		require.NoError(t, err)
+	}
+
+	state, err := db.CurrentState()
+	require.NoError(t, err)
+
+	_, err = db.TxRangeProofs(nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = db.TxRangeProofs(&schema.TxRangeProofsRequest{})
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = db.TxRangeProofs(&schema.TxRangeProofsRequest{InitialTx: 3, FinalTx: 2})
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = db.TxRangeProofs(&schema.TxRangeProofsRequest{InitialTx: 1, FinalTx: state.TxId + 1})
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = db.TxRangeProofs(&schema.TxRangeProofsRequest{InitialTx: 3, ProveSinceTx: 3})
+	require.Equal(t, ErrIllegalArguments, err)
+
+	res, err := db.TxRangeProofs(&schema.TxRangeProofsRequest{InitialTx: 2, ChunkSize: 3, ProveSinceTx: 1})
+	require.NoError(t, err)
+	require.Len(t, res.Chunks, int((state.TxId-2)/3+1))
+	require.Equal(t, state.TxId, res.State.TxId)
+	require.Equal(t, state.TxHash, res.State.TxHash)
+
+	sourceID := uint64(1)
+	sourceAlh := schema.TxMetadataFrom(res.Chunks[0].DualProof.SourceTxMetadata).Alh()
+
+	for i, chunk := range res.Chunks {
+		require.Equal(t, 2+uint64(i)*3, chunk.FirstTx)
+
+		dualProof := schema.DualProofFrom(chunk.DualProof)
+		require.Equal(t, sourceID, dualProof.SourceTxMetadata.ID)
+		require.Equal(t, chunk.LastTx, dualProof.TargetTxMetadata.ID)
+
+		targetAlh := dualProof.TargetTxMetadata.Alh()
+		require.True(t, store.VerifyDualProof(dualProof, sourceID, chunk.LastTx, sourceAlh, targetAlh))
+
+		sourceID = chunk.LastTx
+		sourceAlh = targetAlh
+	}
+
+	require.Equal(t, state.TxId, sourceID)
+
+	res, err = db.TxRangeProofs(&schema.TxRangeProofsRequest{InitialTx: 4, FinalTx: 4})
+	require.NoError(t, err)
+	require.Len(t, res.Chunks, 1)
+	require.Equal(t, uint64(4), res.Chunks[0].LastTx)
+	require.Equal(t, uint64(4), res.State.TxId)
+}
+
+func TestTxRangeProofsTooManyChunks(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	for i := 0; i < MaxTxRangeChunks+1; i++ {
+		_, err := db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("key"), Value: []byte{byte(i)}}}})
+		require.NoError(t, err)
+	}
+
+	_, err := db.TxRangeProofs(&schema.TxRangeProofsRequest{InitialTx: 1, ChunkSize: 1})
+	require.Equal(t, ErrTooManyTxRangeChunks, err)
+
+	res, err := db.TxRangeProofs(&schema.TxRangeProofsRequest{InitialTx: 1, ChunkSize: 2})
+	require.NoError(t, err)
+	require.Len(t, res.Chunks, MaxTxRangeChunks/2+1)
+}
+
+func TestConsistencyProof(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	for i := 0; i < 10; i++ {
+		_, err := db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte{byte(i)}, Value: []byte{byte(i)}}}})
+		require.NoError(t, err)
+	}
+
+	state, err := db.CurrentState()
+	require.NoError(t, err)
+
+	_, err = db.ConsistencyProof(nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = db.ConsistencyProof(&schema.ConsistencyProofRequest{})
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = db.ConsistencyProof(&schema.ConsistencyProofRequest{SourceTx: 3, TargetTx: 2})
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = db.ConsistencyProof(&schema.ConsistencyProofRequest{SourceTx: 1, TargetTx: state.TxId + 1})
+	require.Equal(t, ErrIllegalArguments, err)
+
+	res, err := db.ConsistencyProof(&schema.ConsistencyProofRequest{SourceTx: 2})
+	require.NoError(t, err)
+	require.Equal(t, state.TxId, res.State.TxId)
+	require.Equal(t, state.TxHash, res.State.TxHash)
+
+	dualProof := schema.DualProofFrom(res.DualProof)
+	require.Equal(t, uint64(2), dualProof.SourceTxMetadata.ID)
+
+	verifies := store.VerifyDualProof(
+		dualProof,
+		2,
+		state.TxId,
+		dualProof.SourceTxMetadata.Alh(),
+		schema.DigestFrom(state.TxHash),
+	)
+	require.True(t, verifies)
+
+	res, err = db.ConsistencyProof(&schema.ConsistencyProofRequest{SourceTx: 3, TargetTx: 5})
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), res.State.TxId)
+	require.Equal(t, uint64(5), res.DualProof.TargetTxMetadata.Id)
+}
@@ -0,0 +1,281 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/codenotary/immudb/embedded/watchers"
+	"github.com/codenotary/immudb/pkg/api/schema"
+)
+
+// MaxWatchSubscriptions is the maximum number of watch subscriptions kept by each database
+const MaxWatchSubscriptions = 1024
+
+// watchSubscription keeps the latest transaction acknowledged by the client, delivery is resumed
+// right after it when the subscription is watched again
+type watchSubscription struct {
+	acked uint64
+}
+
+// Watch delivers, in commit order, the key-value entries written since req.SinceTx which match the requested
+// prefixes and value filter, along with the deletions of matching keys when req.IncludeDeleted is set,
+// so caches can be invalidated without polling. Each transaction with matching entries is passed to send as a single event.
+// When a subscription id is provided, delivery is at-least-once: watching again with the same id resumes
+// right after the latest transaction acknowledged with WatchAck, so unacknowledged events are redelivered.
+// Watch returns when the context is done or send fails.
+func (d *db) Watch(ctx context.Context, req *schema.WatchRequest, send func(*schema.WatchEvent) error) error {
+	if req == nil || send == nil {
+		return ErrIllegalArguments
+	}
+
+	filter, err := newWatchFilter(req.Filter)
+	if err != nil {
+		return err
+	}
+
+	txID := req.SinceTx
+	if txID == 0 {
+		lastTxID, _ := d.st.Alh()
+		txID = lastTxID + 1
+	}
+
+	if req.SubscriptionId != "" {
+		d.watchSubscriptionsMutex.Lock()
+
+		sub, ok := d.watchSubscriptions[req.SubscriptionId]
+		if !ok {
+			if len(d.watchSubscriptions) >= MaxWatchSubscriptions {
+				d.watchSubscriptionsMutex.Unlock()
+				return ErrMaxWatchSubscriptionsExceeded
+			}
+
+			sub = &watchSubscription{acked: txID - 1}
+			d.watchSubscriptions[req.SubscriptionId] = sub
+		}
+
+		if sub.acked >= txID {
+			txID = sub.acked + 1
+		}
+
+		d.watchSubscriptionsMutex.Unlock()
+	}
+
+	tx := d.st.NewTx()
+
+	for ; ; txID++ {
+		err := d.st.WaitForIndexingUpto(txID, ctx.Done())
+		if err == watchers.ErrCancellationRequested {
+			return ctx.Err()
+		}
+		if err != nil {
+			return err
+		}
+
+		err = d.st.ReadTx(txID, tx)
+		if err != nil {
+			return err
+		}
+
+		var entries []*schema.Entry
+
+		for _, e := range tx.Entries() {
+			key := e.Key()
+
+			if len(key) == 0 || key[0] != SetKeyPrefix || !hasAnyPrefix(TrimPrefix(key), req.Prefixes) {
+				continue
+			}
+
+			v, err := d.st.ReadValue(tx, key)
+			if err != nil {
+				return err
+			}
+
+			// references are not delivered
+			if len(v) == 0 || v[0] == ReferenceValuePrefix {
+				continue
+			}
+
+			if deleted(v) {
+				if req.IncludeDeleted {
+					entries = append(entries, &schema.Entry{Key: TrimPrefix(key), Tx: txID, Deleted: true})
+				}
+				continue
+			}
+
+			e, err := valueEntry(key, v, txID)
+			if unreadable(err) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			if filter != nil && !filter.match(e.Value) {
+				continue
+			}
+
+			entries = append(entries, e)
+		}
+
+		if len(entries) == 0 {
+			continue
+		}
+
+		err = send(&schema.WatchEvent{Tx: txID, Entries: entries})
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// WatchAck acknowledges the events of a subscription up to the given transaction
+func (d *db) WatchAck(req *schema.WatchAckRequest) error {
+	if req == nil || req.SubscriptionId == "" {
+		return ErrIllegalArguments
+	}
+
+	d.watchSubscriptionsMutex.Lock()
+	defer d.watchSubscriptionsMutex.Unlock()
+
+	sub, ok := d.watchSubscriptions[req.SubscriptionId]
+	if !ok {
+		return ErrWatchSubscriptionNotFound
+	}
+
+	if req.Tx > sub.acked {
+		sub.acked = req.Tx
+	}
+
+	return nil
+}
+
+// Unwatch drops a subscription, so its acknowledgments are no longer kept
+func (d *db) Unwatch(req *schema.UnwatchRequest) error {
+	if req == nil || req.SubscriptionId == "" {
+		return ErrIllegalArguments
+	}
+
+	d.watchSubscriptionsMutex.Lock()
+	defer d.watchSubscriptionsMutex.Unlock()
+
+	if _, ok := d.watchSubscriptions[req.SubscriptionId]; !ok {
+		return ErrWatchSubscriptionNotFound
+	}
+
+	delete(d.watchSubscriptions, req.SubscriptionId)
+
+	return nil
+}
+
+func hasAnyPrefix(key []byte, prefixes [][]byte) bool {
+	if len(prefixes) == 0 {
+		return true
+	}
+
+	for _, p := range prefixes {
+		if bytes.HasPrefix(key, p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// watchFilter matches JSON values having the field at path equal to a given value
+type watchFilter struct {
+	path   []string
+	equals string
+}
+
+func newWatchFilter(f *schema.WatchFilter) (*watchFilter, error) {
+	if f == nil {
+		return nil, nil
+	}
+
+	path, err := parseJSONPath(f.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &watchFilter{path: path, equals: f.Equals}, nil
+}
+
+func (f *watchFilter) match(value []byte) bool {
+	v, ok := jsonField(value, f.path)
+	return ok && v == f.equals
+}
+
+// parseJSONPath splits a dot-separated path of a field within JSON values, the leading "$" is optional
+func parseJSONPath(path string) ([]string, error) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if path == "" {
+		return nil, ErrIllegalArguments
+	}
+
+	return strings.Split(path, "."), nil
+}
+
+// jsonField returns the field at path within a JSON value: strings are unquoted, numbers are returned as written
+// and any other value as its JSON encoding
+func jsonField(value []byte, path []string) (string, bool) {
+	dec := json.NewDecoder(bytes.NewReader(value))
+	dec.UseNumber()
+
+	var v interface{}
+
+	err := dec.Decode(&v)
+	if err != nil {
+		return "", false
+	}
+
+	for _, field := range path {
+		switch tv := v.(type) {
+		case map[string]interface{}:
+			fv, ok := tv[field]
+			if !ok {
+				return "", false
+			}
+			v = fv
+		case []interface{}:
+			i, err := strconv.Atoi(field)
+			if err != nil || i < 0 || i >= len(tv) {
+				return "", false
+			}
+			v = tv[i]
+		default:
+			return "", false
+		}
+	}
+
+	switch tv := v.(type) {
+	case string:
+		return tv, true
+	case json.Number:
+		return tv.String(), true
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", false
+	}
+
+	return string(b), true
+}
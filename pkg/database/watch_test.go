@@ -0,0 +1,192 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/stretchr/testify/require"
+)
+
+var errStopWatching = errors.New("stop watching")
+
+func watchN(t *testing.T, db DB, req *schema.WatchRequest, n int) []*schema.WatchEvent {
+	var events []*schema.WatchEvent
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := db.Watch(ctx, req, func(e *schema.WatchEvent) error {
+		events = append(events, e)
+		if len(events) == n {
+			return errStopWatching
+		}
+		return nil
+	})
+	require.Equal(t, errStopWatching, err)
+
+	return events
+}
+
+func TestWatch(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	_, err := db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{
+		{Key: []byte("orders:1"), Value: []byte(`{"status":"open","total":10}`)},
+		{Key: []byte("users:1"), Value: []byte(`{"status":"open"}`)},
+	}})
+	require.NoError(t, err)
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{
+		{Key: []byte("orders:2"), Value: []byte(`{"status":"closed","total":20}`)},
+	}})
+	require.NoError(t, err)
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{
+		{Key: []byte("payments:1"), Value: []byte(`{"status":"open","items":[{"sku":"a"}]}`)},
+		{Key: []byte("payments:2"), Value: []byte(`not json`)},
+	}})
+	require.NoError(t, err)
+
+	events := watchN(t, db, &schema.WatchRequest{
+		Prefixes: [][]byte{[]byte("orders:"), []byte("payments:")},
+		Filter:   &schema.WatchFilter{Path: "$.status", Equals: "open"},
+		SinceTx:  1,
+	}, 2)
+	require.Equal(t, uint64(1), events[0].Tx)
+	require.Len(t, events[0].Entries, 1)
+	require.Equal(t, []byte("orders:1"), events[0].Entries[0].Key)
+	require.Equal(t, uint64(3), events[1].Tx)
+	require.Len(t, events[1].Entries, 1)
+	require.Equal(t, []byte("payments:1"), events[1].Entries[0].Key)
+
+	events = watchN(t, db, &schema.WatchRequest{
+		Filter:  &schema.WatchFilter{Path: "total", Equals: "20"},
+		SinceTx: 1,
+	}, 1)
+	require.Equal(t, []byte("orders:2"), events[0].Entries[0].Key)
+
+	events = watchN(t, db, &schema.WatchRequest{
+		Filter:  &schema.WatchFilter{Path: "items.0.sku", Equals: "a"},
+		SinceTx: 1,
+	}, 1)
+	require.Equal(t, []byte("payments:1"), events[0].Entries[0].Key)
+
+	// new transactions are delivered as they are committed
+	done := make(chan []*schema.WatchEvent)
+
+	go func() {
+		done <- watchN(t, db, &schema.WatchRequest{Prefixes: [][]byte{[]byte("users:")}}, 1)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+
+	txmd, err := db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("users:2"), Value: []byte("v")}}})
+	require.NoError(t, err)
+
+	events = <-done
+	require.Equal(t, txmd.Id, events[0].Tx)
+	require.Equal(t, []byte("users:2"), events[0].Entries[0].Key)
+
+	// deletions are only delivered when requested
+	delmd, err := db.Delete(&schema.DeleteKeysRequest{Keys: [][]byte{[]byte("users:2")}})
+	require.NoError(t, err)
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("users:3"), Value: []byte("v")}}})
+	require.NoError(t, err)
+
+	events = watchN(t, db, &schema.WatchRequest{Prefixes: [][]byte{[]byte("users:")}, SinceTx: delmd.Id}, 1)
+	require.Equal(t, []byte("users:3"), events[0].Entries[0].Key)
+
+	events = watchN(t, db, &schema.WatchRequest{
+		Prefixes:       [][]byte{[]byte("users:")},
+		Filter:         &schema.WatchFilter{Path: "status", Equals: "open"},
+		SinceTx:        delmd.Id,
+		IncludeDeleted: true,
+	}, 1)
+	require.Equal(t, delmd.Id, events[0].Tx)
+	require.Equal(t, []byte("users:2"), events[0].Entries[0].Key)
+	require.True(t, events[0].Entries[0].Deleted)
+	require.Nil(t, events[0].Entries[0].Value)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = db.Watch(ctx, &schema.WatchRequest{}, func(e *schema.WatchEvent) error { return nil })
+	require.Equal(t, context.Canceled, err)
+
+	err = db.Watch(context.Background(), &schema.WatchRequest{Filter: &schema.WatchFilter{Path: "$"}}, func(e *schema.WatchEvent) error { return nil })
+	require.Equal(t, ErrIllegalArguments, err)
+
+	err = db.Watch(context.Background(), nil, nil)
+	require.Equal(t, ErrIllegalArguments, err)
+}
+
+func TestWatchSubscription(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	for i := 0; i < 3; i++ {
+		_, err := db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("key"), Value: []byte("value")}}})
+		require.NoError(t, err)
+	}
+
+	req := &schema.WatchRequest{SubscriptionId: "sub1", SinceTx: 1}
+
+	events := watchN(t, db, req, 2)
+	require.Equal(t, uint64(1), events[0].Tx)
+
+	err := db.WatchAck(&schema.WatchAckRequest{SubscriptionId: "sub1", Tx: events[0].Tx})
+	require.NoError(t, err)
+
+	// unacknowledged events are delivered again
+	events = watchN(t, db, req, 2)
+	require.Equal(t, uint64(2), events[0].Tx)
+	require.Equal(t, uint64(3), events[1].Tx)
+
+	err = db.WatchAck(&schema.WatchAckRequest{SubscriptionId: "sub1", Tx: 3})
+	require.NoError(t, err)
+
+	// acknowledgments never move backwards
+	err = db.WatchAck(&schema.WatchAckRequest{SubscriptionId: "sub1", Tx: 1})
+	require.NoError(t, err)
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("key"), Value: []byte("value")}}})
+	require.NoError(t, err)
+
+	events = watchN(t, db, req, 1)
+	require.Equal(t, uint64(4), events[0].Tx)
+
+	err = db.Unwatch(&schema.UnwatchRequest{SubscriptionId: "sub1"})
+	require.NoError(t, err)
+
+	err = db.Unwatch(&schema.UnwatchRequest{SubscriptionId: "sub1"})
+	require.Equal(t, ErrWatchSubscriptionNotFound, err)
+
+	err = db.WatchAck(&schema.WatchAckRequest{SubscriptionId: "sub1", Tx: 1})
+	require.Equal(t, ErrWatchSubscriptionNotFound, err)
+
+	err = db.WatchAck(&schema.WatchAckRequest{})
+	require.Equal(t, ErrIllegalArguments, err)
+
+	err = db.Unwatch(nil)
+	require.Equal(t, ErrIllegalArguments, err)
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"encoding/binary"
+
+	"github.com/codenotary/immudb/embedded/store"
+)
+
+// The write-once mode of a database is recorded when it's created, in an entry under the WORMKeyPrefix key.
+// Being part of the verifiable history of the database, the mode can't be changed afterwards without being noticed
+
+const wormModeVersion byte = 1
+
+type wormMode struct {
+	maxVersions uint32
+}
+
+func (w *wormMode) limitsVersions() bool {
+	return w != nil && w.maxVersions > 0
+}
+
+// EncodeWORMMode returns the entry recording the write-once mode of a database
+func EncodeWORMMode(maxVersions uint32) *store.KV {
+	v := make([]byte, 1+4)
+	v[0] = wormModeVersion
+	binary.BigEndian.PutUint32(v[1:], maxVersions)
+
+	return &store.KV{Key: []byte{WORMKeyPrefix}, Value: v}
+}
+
+// WORMMode returns whether the database was created in write-once mode and the maximum number of values
+// a key can be assigned, zero meaning no limit
+func (d *db) WORMMode() (bool, uint32) {
+	if d.worm == nil {
+		return false, 0
+	}
+	return true, d.worm.maxVersions
+}
+
+// initWORMMode records the write-once mode of a new database
+func (d *db) initWORMMode() error {
+	_, err := d.st.Commit([]*store.KV{EncodeWORMMode(d.options.wormMaxVersions)}, true)
+	if err != nil {
+		return err
+	}
+
+	d.worm = &wormMode{maxVersions: d.options.wormMaxVersions}
+
+	return nil
+}
+
+// loadWORMMode reads the write-once mode recorded when the database was created, regardless of the options
+func (d *db) loadWORMMode() error {
+	lastTxID, _ := d.st.Alh()
+
+	err := d.st.WaitForIndexingUpto(lastTxID, nil)
+	if err != nil {
+		return err
+	}
+
+	v, _, _, err := d.st.Get([]byte{WORMKeyPrefix})
+	if err == store.ErrKeyNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(v) != 1+4 || v[0] != wormModeVersion {
+		return store.ErrCorruptedData
+	}
+
+	d.worm = &wormMode{maxVersions: binary.BigEndian.Uint32(v[1:])}
+
+	return nil
+}
+
+// checkWORMRemoval returns ErrWORM in write-once mode, it must be called by operations deleting,
+// expiring or overriding entries
+func (d *db) checkWORMRemoval() error {
+	if d.worm != nil {
+		return ErrWORM
+	}
+	return nil
+}
+
+// checkWORMVersions returns ErrWORMMaxVersionsExceeded if writing the keys, which may be repeated,
+// would assign any of them more values than allowed in write-once mode
+func (d *db) checkWORMVersions(keys [][]byte, index store.KeyIndex) error {
+	if !d.worm.limitsVersions() {
+		return nil
+	}
+
+	versions := make(map[string]uint64, len(keys))
+
+	for _, key := range keys {
+		n, ok := versions[string(key)]
+		if !ok {
+			_, _, hc, err := index.Get(EncodeKey(key))
+			if err != nil && err != store.ErrKeyNotFound {
+				return err
+			}
+			n = hc
+		}
+
+		n++
+
+		if n > uint64(d.worm.maxVersions) {
+			return ErrWORMMaxVersionsExceeded
+		}
+
+		versions[string(key)] = n
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWORMMode(t *testing.T) {
+	rootPath := "data_" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	defer os.RemoveAll(rootPath)
+
+	options := DefaultOption().WithDbRootPath(rootPath).WithDbName("db").WithWORM(true, 2)
+
+	db, err := NewDb(options, nil, logger.NewSimpleLogger("immudb ", os.Stderr))
+	require.NoError(t, err)
+
+	worm, maxVersions := db.WORMMode()
+	require.True(t, worm)
+	require.Equal(t, uint32(2), maxVersions)
+
+	state, err := db.CurrentState()
+	require.NoError(t, err)
+	require.True(t, state.Worm)
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("k1"), Value: []byte("v1")}}})
+	require.NoError(t, err)
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{
+		{Key: []byte("k1"), Value: []byte("v2")},
+		{Key: []byte("k1"), Value: []byte("v3")},
+	}})
+	require.Equal(t, ErrWORMMaxVersionsExceeded, err)
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("k1"), Value: []byte("v2")}}})
+	require.NoError(t, err)
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("k1"), Value: []byte("v3")}}})
+	require.Equal(t, ErrWORMMaxVersionsExceeded, err)
+
+	_, err = db.ExecAll(&schema.ExecAllRequest{Operations: []*schema.Op{
+		{Operation: &schema.Op_Kv{Kv: &schema.KeyValue{Key: []byte("k1"), Value: []byte("v3")}}},
+	}})
+	require.Equal(t, ErrWORMMaxVersionsExceeded, err)
+
+	_, err = db.SetReference(&schema.ReferenceRequest{Key: []byte("ref"), ReferencedKey: []byte("k1")})
+	require.NoError(t, err)
+
+	_, err = db.ZAdd(&schema.ZAddRequest{Set: []byte("set"), Key: []byte("k1"), Score: 1})
+	require.NoError(t, err)
+
+	_, err = db.ZRem(&schema.ZRemRequest{Set: []byte("set"), Key: []byte("k1")})
+	require.Equal(t, ErrWORM, err)
+
+	_, err = db.ZIncrBy(&schema.ZIncrByRequest{Set: []byte("set"), Key: []byte("k1"), Increment: 1})
+	require.Equal(t, ErrWORM, err)
+
+	err = db.Close()
+	require.NoError(t, err)
+
+	// the recorded mode prevails over the options used to open the database
+	db, err = OpenDb(options.WithWORM(false, 0), nil, logger.NewSimpleLogger("immudb ", os.Stderr))
+	require.NoError(t, err)
+	defer db.Close()
+
+	worm, maxVersions = db.WORMMode()
+	require.True(t, worm)
+	require.Equal(t, uint32(2), maxVersions)
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("k1"), Value: []byte("v3")}}})
+	require.Equal(t, ErrWORMMaxVersionsExceeded, err)
+}
+
+func TestWORMModeDisabled(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	worm, _ := db.WORMMode()
+	require.False(t, worm)
+
+	state, err := db.CurrentState()
+	require.NoError(t, err)
+	require.False(t, state.Worm)
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("k1"), Value: []byte("v1")}}})
+	require.NoError(t, err)
+
+	_, err = db.ZAdd(&schema.ZAddRequest{Set: []byte("set"), Key: []byte("k1"), Score: 1})
+	require.NoError(t, err)
+
+	_, err = db.ZRem(&schema.ZRemRequest{Set: []byte("set"), Key: []byte("k1")})
+	require.NoError(t, err)
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fips holds the checks applied when immudb is built with a FIPS-validated crypto provider.
+// Such builds are produced with the boringcrypto toolchain experiment (e.g. make immudb-fips),
+// non-approved algorithms are then rejected at startup
+package fips
+
+import (
+	"crypto/elliptic"
+	"crypto/tls"
+	"errors"
+	"fmt"
+
+	"github.com/codenotary/immudb/embedded/hashing"
+)
+
+var ErrNotApproved = errors.New("not approved in FIPS mode")
+
+var approvedHashAlgorithms = map[hashing.Algorithm]bool{
+	hashing.SHA256: true,
+}
+
+var approvedCurves = map[string]bool{
+	elliptic.P256().Params().Name: true,
+	elliptic.P384().Params().Name: true,
+	elliptic.P521().Params().Name: true,
+}
+
+var approvedCipherSuites = map[uint16]bool{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256: true,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384: true,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256:   true,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384:   true,
+	tls.TLS_RSA_WITH_AES_128_GCM_SHA256:         true,
+	tls.TLS_RSA_WITH_AES_256_GCM_SHA384:         true,
+}
+
+// CheckHashAlgorithm returns ErrNotApproved if a is not a FIPS-approved hash algorithm
+func CheckHashAlgorithm(a hashing.Algorithm) error {
+	if !approvedHashAlgorithms[a] {
+		return fmt.Errorf("hash algorithm %s %w", a, ErrNotApproved)
+	}
+	return nil
+}
+
+// CheckCurve returns ErrNotApproved if c is not a FIPS-approved elliptic curve
+func CheckCurve(c elliptic.Curve) error {
+	if c == nil || !approvedCurves[c.Params().Name] {
+		return fmt.Errorf("elliptic curve %w", ErrNotApproved)
+	}
+	return nil
+}
+
+// CheckTLSConfig returns ErrNotApproved if the provided config allows protocol versions or cipher suites not approved
+func CheckTLSConfig(c *tls.Config) error {
+	if c == nil {
+		return nil
+	}
+
+	if c.MinVersion != 0 && c.MinVersion < tls.VersionTLS12 {
+		return fmt.Errorf("TLS versions lower than 1.2 %w", ErrNotApproved)
+	}
+
+	for _, cs := range c.CipherSuites {
+		if !approvedCipherSuites[cs] {
+			return fmt.Errorf("TLS cipher suite %s %w", tls.CipherSuiteName(cs), ErrNotApproved)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fips
+
+import (
+	"crypto/elliptic"
+	"crypto/tls"
+	"errors"
+	"testing"
+
+	"github.com/codenotary/immudb/embedded/hashing"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckHashAlgorithm(t *testing.T) {
+	require.NoError(t, CheckHashAlgorithm(hashing.SHA256))
+
+	err := CheckHashAlgorithm(hashing.Algorithm(99))
+	require.True(t, errors.Is(err, ErrNotApproved))
+}
+
+func TestCheckCurve(t *testing.T) {
+	require.NoError(t, CheckCurve(elliptic.P256()))
+	require.NoError(t, CheckCurve(elliptic.P384()))
+
+	err := CheckCurve(nil)
+	require.True(t, errors.Is(err, ErrNotApproved))
+}
+
+func TestCheckTLSConfig(t *testing.T) {
+	require.NoError(t, CheckTLSConfig(nil))
+	require.NoError(t, CheckTLSConfig(&tls.Config{}))
+	require.NoError(t, CheckTLSConfig(&tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		CipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256},
+	}))
+
+	err := CheckTLSConfig(&tls.Config{MinVersion: tls.VersionTLS10})
+	require.True(t, errors.Is(err, ErrNotApproved))
+
+	err = CheckTLSConfig(&tls.Config{CipherSuites: []uint16{tls.TLS_RSA_WITH_RC4_128_SHA}})
+	require.True(t, errors.Is(err, ErrNotApproved))
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package server
 
 import (
+	"context"
+
 	"github.com/codenotary/immudb/embedded/sql"
 	"github.com/codenotary/immudb/pkg/api/schema"
 	bm "github.com/codenotary/immudb/pkg/pgsql/server/bmessages"
@@ -110,7 +112,7 @@ func (s *session) queryMsg(v fm.QueryMsg) error {
 }
 
 func (s *session) selectStatement(st *sql.SelectStmt) error {
-	res, err := s.database.SQLQueryPrepared(st, nil, true)
+	res, err := s.database.SQLQueryPrepared(context.Background(), st, nil, true)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,123 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package receipt
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// A4 page size, in points
+const (
+	pageWidth  = 595
+	pageHeight = 842
+)
+
+// The fonts of a page, as named in its content stream
+const (
+	fontBold    = "F1"
+	fontRegular = "F2"
+	fontMono    = "F3"
+)
+
+var pageFonts = []struct{ name, baseFont string }{
+	{fontBold, "Helvetica-Bold"},
+	{fontRegular, "Helvetica"},
+	{fontMono, "Courier"},
+}
+
+// page builds the content stream of a single page PDF document, only using the standard fonts
+type page struct {
+	content bytes.Buffer
+}
+
+func (p *page) text(x, y float64, font string, size float64, s string) {
+	fmt.Fprintf(&p.content, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, escapePDFString(s))
+}
+
+func (p *page) rect(x, y, w, h float64) {
+	fmt.Fprintf(&p.content, "%.2f %.2f %.2f %.2f re f\n", x, y, w, h)
+}
+
+func (p *page) line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(&p.content, "%.2f %.2f m %.2f %.2f l S\n", x1, y1, x2, y2)
+}
+
+// bytes returns the document holding the page, objects are numbered in the order they're written
+func (p *page) bytes(title string) []byte {
+	var fonts strings.Builder
+	for i, f := range pageFonts {
+		fmt.Fprintf(&fonts, "/%s %d 0 R ", f.name, 5+i)
+	}
+
+	contentObj := 5 + len(pageFonts)
+
+	objs := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << %s>> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, fonts.String(), contentObj),
+		fmt.Sprintf("<< /Title (%s) /Producer (immudb) >>", escapePDFString(title)),
+	}
+
+	for _, f := range pageFonts {
+		objs = append(objs, fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", f.baseFont))
+	}
+
+	objs = append(objs, fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", p.content.Len(), p.content.String()))
+
+	var doc bytes.Buffer
+
+	doc.WriteString("%PDF-1.4\n")
+
+	offsets := make([]int, len(objs))
+
+	for i, obj := range objs {
+		offsets[i] = doc.Len()
+		fmt.Fprintf(&doc, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+
+	xref := doc.Len()
+
+	fmt.Fprintf(&doc, "xref\n0 %d\n0000000000 65535 f \n", len(objs)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&doc, "%010d 00000 n \n", off)
+	}
+
+	fmt.Fprintf(&doc, "trailer\n<< /Size %d /Root 1 0 R /Info 4 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objs)+1, xref)
+
+	return doc.Bytes()
+}
+
+// escapePDFString escapes s to be written as a literal string, characters outside printable ASCII are replaced
+func escapePDFString(s string) string {
+	var b strings.Builder
+
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20 || r > 0x7E:
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
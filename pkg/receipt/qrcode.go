@@ -0,0 +1,597 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package receipt
+
+import (
+	"errors"
+)
+
+var ErrDataTooLong = errors.New("data too long to be encoded as a QR code")
+
+// QR codes are encoded in byte mode with the low error correction level, as links are read from printed or
+// rendered documents. Versions up to 20 are supported, i.e. up to 858 bytes of data
+
+const qrMaxVersion = 20
+
+// qrBlocks holds, for each version, the error correction codewords of each block and the number of blocks
+// along with their data codewords for each of the two block groups
+var qrBlocks = [qrMaxVersion + 1]struct {
+	ecLen               int
+	g1Blocks, g1DataLen int
+	g2Blocks, g2DataLen int
+}{
+	{},
+	{7, 1, 19, 0, 0},
+	{10, 1, 34, 0, 0},
+	{15, 1, 55, 0, 0},
+	{20, 1, 80, 0, 0},
+	{26, 1, 108, 0, 0},
+	{18, 2, 68, 0, 0},
+	{20, 2, 78, 0, 0},
+	{24, 2, 97, 0, 0},
+	{30, 2, 116, 0, 0},
+	{18, 2, 68, 2, 69},
+	{20, 4, 81, 0, 0},
+	{24, 2, 92, 2, 93},
+	{26, 4, 107, 0, 0},
+	{30, 3, 115, 1, 116},
+	{22, 5, 87, 1, 88},
+	{24, 5, 98, 1, 99},
+	{28, 1, 107, 5, 108},
+	{30, 5, 120, 1, 121},
+	{28, 3, 113, 4, 114},
+	{28, 3, 107, 5, 108},
+}
+
+// QRCode is a square matrix of dark and light modules
+type QRCode struct {
+	Version int
+	Size    int
+
+	modules    [][]bool
+	isFunction [][]bool
+}
+
+// Dark returns whether the module at column x and row y is dark
+func (q *QRCode) Dark(x, y int) bool {
+	return q.modules[y][x]
+}
+
+// EncodeQR encodes data into the smallest QR code able to hold it
+func EncodeQR(data []byte) (*QRCode, error) {
+	version := 1
+	for ; version <= qrMaxVersion; version++ {
+		if 4+qrCountBits(version)+8*len(data) <= 8*qrDataLen(version) {
+			break
+		}
+	}
+	if version > qrMaxVersion {
+		return nil, ErrDataTooLong
+	}
+
+	q := &QRCode{Version: version, Size: 4*version + 17}
+
+	q.modules = make([][]bool, q.Size)
+	q.isFunction = make([][]bool, q.Size)
+	for i := range q.modules {
+		q.modules[i] = make([]bool, q.Size)
+		q.isFunction[i] = make([]bool, q.Size)
+	}
+
+	q.drawFunctionPatterns()
+	q.drawCodewords(q.codewords(data))
+
+	bestMask := 0
+	minPenalty := -1
+
+	for mask := 0; mask < 8; mask++ {
+		q.applyMask(mask)
+		q.drawFormatBits(mask)
+
+		penalty := q.penalty()
+		if minPenalty < 0 || penalty < minPenalty {
+			bestMask = mask
+			minPenalty = penalty
+		}
+
+		// masks are xor-ed, applying one twice reverts it
+		q.applyMask(mask)
+	}
+
+	q.applyMask(bestMask)
+	q.drawFormatBits(bestMask)
+
+	return q, nil
+}
+
+func qrCountBits(version int) int {
+	if version < 10 {
+		return 8
+	}
+	return 16
+}
+
+func qrDataLen(version int) int {
+	b := qrBlocks[version]
+	return b.g1Blocks*b.g1DataLen + b.g2Blocks*b.g2DataLen
+}
+
+// qrRawModules returns the number of modules available for codewords, once function patterns are drawn
+func qrRawModules(version int) int {
+	n := (16*version+128)*version + 64
+
+	if version >= 2 {
+		numAlign := version/7 + 2
+		n -= (25*numAlign-10)*numAlign - 55
+
+		if version >= 7 {
+			n -= 36
+		}
+	}
+
+	return n
+}
+
+// qrAlignmentPositions returns the coordinates of the centers of the alignment patterns, along both axes
+func qrAlignmentPositions(version int) []int {
+	if version == 1 {
+		return nil
+	}
+
+	numAlign := version/7 + 2
+	step := (version*8 + numAlign*3 + 5) / (numAlign*4 - 4) * 2
+
+	pos := make([]int, numAlign)
+	pos[0] = 6
+
+	for i, p := numAlign-1, 4*version+10; i > 0; i, p = i-1, p-step {
+		pos[i] = p
+	}
+
+	return pos
+}
+
+func (q *QRCode) set(x, y int, dark bool) {
+	q.modules[y][x] = dark
+	q.isFunction[y][x] = true
+}
+
+func (q *QRCode) drawFunctionPatterns() {
+	for i := 0; i < q.Size; i++ {
+		q.set(6, i, i%2 == 0)
+		q.set(i, 6, i%2 == 0)
+	}
+
+	q.drawFinderPattern(3, 3)
+	q.drawFinderPattern(q.Size-4, 3)
+	q.drawFinderPattern(3, q.Size-4)
+
+	pos := qrAlignmentPositions(q.Version)
+	last := len(pos) - 1
+
+	for i := range pos {
+		for j := range pos {
+			// alignment patterns overlapping finder patterns are skipped
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			q.drawAlignmentPattern(pos[i], pos[j])
+		}
+	}
+
+	// format bits are reserved here, they are drawn once the mask is selected
+	q.drawFormatBits(0)
+	q.drawVersionBits()
+}
+
+func (q *QRCode) drawFinderPattern(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || xx >= q.Size || yy < 0 || yy >= q.Size {
+				continue
+			}
+
+			dist := maxInt(absInt(dx), absInt(dy))
+			q.set(xx, yy, dist != 2 && dist != 4)
+		}
+	}
+}
+
+func (q *QRCode) drawAlignmentPattern(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			q.set(x+dx, y+dy, maxInt(absInt(dx), absInt(dy)) != 1)
+		}
+	}
+}
+
+// qrFormatBits returns the BCH encoded error correction level (low) and mask
+func qrFormatBits(mask int) int {
+	data := 1<<3 | mask
+
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+
+	return (data<<10 | rem) ^ 0x5412
+}
+
+// qrVersionBits returns the BCH encoded version, drawn from version 7 onwards
+func qrVersionBits(version int) int {
+	rem := version
+	for i := 0; i < 12; i++ {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+	}
+
+	return version<<12 | rem
+}
+
+func (q *QRCode) drawFormatBits(mask int) {
+	bits := qrFormatBits(mask)
+	bit := func(i int) bool { return (bits>>uint(i))&1 != 0 }
+
+	for i := 0; i <= 5; i++ {
+		q.set(8, i, bit(i))
+	}
+	q.set(8, 7, bit(6))
+	q.set(8, 8, bit(7))
+	q.set(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		q.set(14-i, 8, bit(i))
+	}
+
+	for i := 0; i < 8; i++ {
+		q.set(q.Size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		q.set(8, q.Size-15+i, bit(i))
+	}
+
+	q.set(8, q.Size-8, true)
+}
+
+func (q *QRCode) drawVersionBits() {
+	if q.Version < 7 {
+		return
+	}
+
+	bits := qrVersionBits(q.Version)
+
+	for i := 0; i < 18; i++ {
+		dark := (bits>>uint(i))&1 != 0
+		a, b := q.Size-11+i%3, i/3
+
+		q.set(a, b, dark)
+		q.set(b, a, dark)
+	}
+}
+
+// codewords returns the data codewords followed by the error correction ones, interleaved across blocks
+func (q *QRCode) codewords(data []byte) []byte {
+	dataLen := qrDataLen(q.Version)
+
+	bb := &bitBuffer{}
+	bb.append(0x4, 4)
+	bb.append(len(data), qrCountBits(q.Version))
+	for _, b := range data {
+		bb.append(int(b), 8)
+	}
+
+	bb.append(0, minInt(4, 8*dataLen-bb.len))
+	bb.append(0, (8-bb.len%8)%8)
+
+	for pad := 0xEC; bb.len < 8*dataLen; pad ^= 0xEC ^ 0x11 {
+		bb.append(pad, 8)
+	}
+
+	blocks := qrBlocks[q.Version]
+	divisor := rsDivisor(blocks.ecLen)
+
+	var dataBlocks, ecBlocks [][]byte
+
+	cw := bb.bytes()
+
+	for i := 0; i < blocks.g1Blocks+blocks.g2Blocks; i++ {
+		n := blocks.g1DataLen
+		if i >= blocks.g1Blocks {
+			n = blocks.g2DataLen
+		}
+
+		dataBlocks = append(dataBlocks, cw[:n])
+		ecBlocks = append(ecBlocks, rsRemainder(cw[:n], divisor))
+
+		cw = cw[n:]
+	}
+
+	result := make([]byte, 0, qrRawModules(q.Version)/8)
+
+	for i := 0; i < maxInt(blocks.g1DataLen, blocks.g2DataLen); i++ {
+		for _, b := range dataBlocks {
+			if i < len(b) {
+				result = append(result, b[i])
+			}
+		}
+	}
+
+	for i := 0; i < blocks.ecLen; i++ {
+		for _, b := range ecBlocks {
+			result = append(result, b[i])
+		}
+	}
+
+	return result
+}
+
+// drawCodewords places the codewords in the zigzag order, from the bottom right corner in two-module wide columns
+func (q *QRCode) drawCodewords(cw []byte) {
+	i := 0
+
+	for right := q.Size - 1; right >= 1; right -= 2 {
+		// the vertical timing pattern is skipped
+		if right == 6 {
+			right = 5
+		}
+
+		upward := (right+1)&2 == 0
+
+		for vert := 0; vert < q.Size; vert++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+
+				y := vert
+				if upward {
+					y = q.Size - 1 - vert
+				}
+
+				if q.isFunction[y][x] || i >= 8*len(cw) {
+					continue
+				}
+
+				q.modules[y][x] = (cw[i>>3]>>uint(7-i&7))&1 != 0
+				i++
+			}
+		}
+	}
+}
+
+func (q *QRCode) applyMask(mask int) {
+	for y := 0; y < q.Size; y++ {
+		for x := 0; x < q.Size; x++ {
+			if q.isFunction[y][x] {
+				continue
+			}
+
+			var invert bool
+
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+
+			q.modules[y][x] = q.modules[y][x] != invert
+		}
+	}
+}
+
+// penalty scores how hard the symbol may be to read, the mask with the lowest penalty is selected
+func (q *QRCode) penalty() int {
+	penalty := 0
+
+	// runs of five or more modules of the same color, along rows and columns
+	for _, vertical := range []bool{false, true} {
+		for i := 0; i < q.Size; i++ {
+			run := 0
+			var prev bool
+
+			line := make([]bool, q.Size)
+
+			for j := 0; j < q.Size; j++ {
+				dark := q.modules[i][j]
+				if vertical {
+					dark = q.modules[j][i]
+				}
+				line[j] = dark
+
+				if j > 0 && dark == prev {
+					run++
+				} else {
+					if run >= 5 {
+						penalty += run - 2
+					}
+					run = 1
+				}
+				prev = dark
+			}
+
+			if run >= 5 {
+				penalty += run - 2
+			}
+
+			penalty += 40 * finderLikePatterns(line)
+		}
+	}
+
+	// 2x2 blocks of the same color
+	for y := 0; y < q.Size-1; y++ {
+		for x := 0; x < q.Size-1; x++ {
+			c := q.modules[y][x]
+			if c == q.modules[y][x+1] && c == q.modules[y+1][x] && c == q.modules[y+1][x+1] {
+				penalty += 3
+			}
+		}
+	}
+
+	// deviation of the proportion of dark modules from half
+	dark := 0
+	for y := 0; y < q.Size; y++ {
+		for x := 0; x < q.Size; x++ {
+			if q.modules[y][x] {
+				dark++
+			}
+		}
+	}
+
+	total := q.Size * q.Size
+	penalty += 10 * (absInt(20*dark-10*total) / total)
+
+	return penalty
+}
+
+// finderLikePatterns counts the occurrences of dark-light-dark-dark-dark-light-dark with four light modules
+// (or the border) on either side
+func finderLikePatterns(line []bool) int {
+	pattern := []bool{true, false, true, true, true, false, true}
+
+	light := func(i int) bool {
+		return i < 0 || i >= len(line) || !line[i]
+	}
+
+	n := 0
+
+	for i := 0; i+len(pattern) <= len(line); i++ {
+		match := true
+		for j, p := range pattern {
+			if line[i+j] != p {
+				match = false
+				break
+			}
+		}
+		if !match {
+			continue
+		}
+
+		before, after := true, true
+		for j := 1; j <= 4; j++ {
+			before = before && light(i-j)
+			after = after && light(i+len(pattern)-1+j)
+		}
+
+		if before || after {
+			n++
+		}
+	}
+
+	return n
+}
+
+type bitBuffer struct {
+	buf []byte
+	len int
+}
+
+func (bb *bitBuffer) append(v int, n int) {
+	for i := n - 1; i >= 0; i-- {
+		if bb.len%8 == 0 {
+			bb.buf = append(bb.buf, 0)
+		}
+		if (v>>uint(i))&1 != 0 {
+			bb.buf[bb.len/8] |= 0x80 >> uint(bb.len%8)
+		}
+		bb.len++
+	}
+}
+
+func (bb *bitBuffer) bytes() []byte {
+	return bb.buf
+}
+
+// rsDivisor returns the coefficients of the Reed-Solomon generator polynomial of the given degree,
+// from the highest to the lowest power, the leading term being omitted
+func rsDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+
+	root := byte(1)
+
+	for i := 0; i < degree; i++ {
+		for j := range result {
+			result[j] = gfMultiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = gfMultiply(root, 0x02)
+	}
+
+	return result
+}
+
+// rsRemainder returns the error correction codewords of data
+func rsRemainder(data []byte, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+
+	for _, b := range data {
+		factor := b ^ result[0]
+
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+
+		for i, c := range divisor {
+			result[i] ^= gfMultiply(c, factor)
+		}
+	}
+
+	return result
+}
+
+// gfMultiply multiplies in GF(2^8) modulo x^8 + x^4 + x^3 + x^2 + 1
+func gfMultiply(x, y byte) byte {
+	z := 0
+
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11D)
+		z ^= int((y>>uint(i))&1) * int(x)
+	}
+
+	return byte(z)
+}
+
+func absInt(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
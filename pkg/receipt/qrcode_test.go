@@ -0,0 +1,113 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package receipt
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQRReedSolomon(t *testing.T) {
+	// "HELLO WORLD" encoded with version 1 and the medium error correction level
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	ec := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+
+	require.Equal(t, ec, rsRemainder(data, rsDivisor(len(ec))))
+}
+
+func TestQRTables(t *testing.T) {
+	for v := 1; v <= qrMaxVersion; v++ {
+		b := qrBlocks[v]
+		require.Equal(t, qrRawModules(v)/8, qrDataLen(v)+b.ecLen*(b.g1Blocks+b.g2Blocks), "version %d", v)
+	}
+
+	require.Nil(t, qrAlignmentPositions(1))
+	require.Equal(t, []int{6, 18}, qrAlignmentPositions(2))
+	require.Equal(t, []int{6, 22, 38}, qrAlignmentPositions(7))
+	require.Equal(t, []int{6, 26, 46, 66}, qrAlignmentPositions(14))
+	require.Equal(t, []int{6, 34, 62, 90}, qrAlignmentPositions(20))
+
+	require.Equal(t, 0x77C4, qrFormatBits(0))
+	require.Equal(t, 0x662F, qrFormatBits(4))
+	require.Equal(t, 0x07C94, qrVersionBits(7))
+}
+
+func TestEncodeQR(t *testing.T) {
+	q, err := EncodeQR([]byte("https://immudb.io"))
+	require.NoError(t, err)
+	require.Equal(t, 1, q.Version)
+	require.Equal(t, 21, q.Size)
+
+	q, err = EncodeQR([]byte("https://immudb.io/"))
+	require.NoError(t, err)
+	require.Equal(t, 2, q.Version)
+	require.Equal(t, 25, q.Size)
+
+	// finder patterns at three corners, with their separators
+	for _, c := range [][2]int{{0, 0}, {q.Size - 7, 0}, {0, q.Size - 7}} {
+		for i := 0; i < 7; i++ {
+			require.True(t, q.Dark(c[0]+i, c[1]))
+			require.True(t, q.Dark(c[0], c[1]+i))
+		}
+		require.True(t, q.Dark(c[0]+3, c[1]+3))
+		require.False(t, q.Dark(c[0]+1, c[1]+1))
+	}
+	require.False(t, q.Dark(7, 7))
+	require.True(t, q.Dark(8, q.Size-8))
+
+	// format bits are drawn twice
+	var f1, f2 int
+	for i := 0; i < 15; i++ {
+		var x, y int
+		switch {
+		case i < 6:
+			x, y = 8, i
+		case i < 8:
+			x, y = 8, i+1
+		case i == 8:
+			x, y = 7, 8
+		default:
+			x, y = 14-i, 8
+		}
+		if q.Dark(x, y) {
+			f1 |= 1 << uint(i)
+		}
+
+		if i < 8 {
+			x, y = q.Size-1-i, 8
+		} else {
+			x, y = 8, q.Size-15+i
+		}
+		if q.Dark(x, y) {
+			f2 |= 1 << uint(i)
+		}
+	}
+	require.Equal(t, f1, f2)
+	require.Equal(t, qrFormatBits((f1^0x5412)>>10&7), f1)
+
+	q, err = EncodeQR(bytes.Repeat([]byte("a"), 300))
+	require.NoError(t, err)
+	require.Equal(t, 11, q.Version)
+
+	_, err = EncodeQR(bytes.Repeat([]byte("a"), 859))
+	require.Equal(t, ErrDataTooLong, err)
+
+	q, err = EncodeQR(bytes.Repeat([]byte("a"), 858))
+	require.NoError(t, err)
+	require.Equal(t, qrMaxVersion, q.Version)
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package receipt
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"net/url"
+	"strconv"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/codenotary/immudb/embedded/hashing"
+	"github.com/codenotary/immudb/pkg/api/schema"
+)
+
+// Package receipt renders notarization receipts, human-readable certificates of the inclusion of an entry
+// in a database, to be attached to documents by users not verifying proofs themselves
+
+const (
+	margin     = 56
+	valueX     = 180
+	wrapLen    = 64
+	lineHeight = 13
+	qrWidth    = 150
+)
+
+// VerificationURL returns the link to verify the receipt, made of base and the parameters identifying the entry
+func VerificationURL(base string, r *schema.Receipt) (string, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+
+	q := u.Query()
+	q.Set("db", r.Database)
+	q.Set("key", base64.RawURLEncoding.EncodeToString(r.Key))
+	q.Set("tx", strconv.FormatUint(r.Tx, 10))
+	q.Set("hash", hex.EncodeToString(r.ValueHash))
+
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
+}
+
+// PDF renders the receipt as a single page document, the verification URL is encoded as a QR code
+func PDF(r *schema.Receipt) ([]byte, error) {
+	p := &page{}
+
+	y := float64(pageHeight - margin - 20)
+
+	p.text(margin, y, fontBold, 20, "Notarization receipt")
+	y -= 20
+
+	p.text(margin, y, fontRegular, 10, "The entry below was committed to a tamper-evident immudb database.")
+	y -= 14
+
+	p.line(margin, y, pageWidth-margin, y)
+	y -= 24
+
+	field := func(label string, lines ...string) {
+		p.text(margin, y, fontBold, 10, label)
+		for _, l := range lines {
+			p.text(valueX, y, fontMono, 9, l)
+			y -= lineHeight
+		}
+		y -= 6
+	}
+
+	hashAlgorithm := hashing.Algorithm(r.GetState().GetHashAlgorithm())
+
+	field("Database", r.Database)
+	field("Key", wrap(printable(r.Key))...)
+	field("Value hash ("+hashAlgorithm.String()+")", wrap(hex.EncodeToString(r.ValueHash))...)
+	field("Transaction", strconv.FormatUint(r.Tx, 10))
+	field("Committed at", time.Unix(r.Timestamp, 0).UTC().Format(time.RFC3339))
+	field("Transaction hash", wrap(hex.EncodeToString(r.GetState().GetTxHash()))...)
+
+	if sig := r.GetState().GetSignature(); sig != nil {
+		field("Signature", wrap(hex.EncodeToString(sig.Signature))...)
+		field("Public key", wrap(hex.EncodeToString(sig.PublicKey))...)
+	} else {
+		field("Signature", "not signed, the server has no signing key")
+	}
+
+	y -= 6
+	p.line(margin, y, pageWidth-margin, y)
+	y -= 20
+
+	p.text(margin, y, fontRegular, 9, "The value hash is included in the transaction, whose hash is signed by the server.")
+	y -= lineHeight
+	p.text(margin, y, fontRegular, 9, "The JSON receipt embeds the proofs needed to verify this receipt offline.")
+	y -= 24
+
+	if r.VerificationUrl == "" {
+		return p.bytes("Notarization receipt"), nil
+	}
+
+	field("Verify at", wrap(r.VerificationUrl)...)
+
+	q, err := EncodeQR([]byte(r.VerificationUrl))
+	if err != nil {
+		return nil, err
+	}
+
+	// four modules are left blank around the symbol
+	module := float64(qrWidth) / float64(q.Size+8)
+	top := y
+
+	for row := 0; row < q.Size; row++ {
+		for col := 0; col < q.Size; col++ {
+			if q.Dark(col, row) {
+				p.rect(margin+float64(col+4)*module, top-float64(row+5)*module, module, module)
+			}
+		}
+	}
+
+	return p.bytes("Notarization receipt"), nil
+}
+
+// printable returns the key as text when it's made of printable characters, hex encoded otherwise
+func printable(key []byte) string {
+	if !utf8.Valid(key) {
+		return "0x" + hex.EncodeToString(key)
+	}
+
+	for _, r := range string(key) {
+		if r > unicode.MaxASCII || !unicode.IsPrint(r) {
+			return "0x" + hex.EncodeToString(key)
+		}
+	}
+
+	return string(key)
+}
+
+func wrap(s string) []string {
+	var lines []string
+
+	for len(s) > wrapLen {
+		lines = append(lines, s[:wrapLen])
+		s = s[wrapLen:]
+	}
+
+	return append(lines, s)
+}
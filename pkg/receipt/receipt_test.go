@@ -0,0 +1,119 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package receipt
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerificationURL(t *testing.T) {
+	r := &schema.Receipt{Database: "contracts", Key: []byte("c1"), Tx: 7, ValueHash: []byte{0xca, 0xfe}}
+
+	link, err := VerificationURL("https://verify.example.com/receipts?lang=en", r)
+	require.NoError(t, err)
+
+	u, err := url.Parse(link)
+	require.NoError(t, err)
+	require.Equal(t, "verify.example.com", u.Host)
+	require.Equal(t, "en", u.Query().Get("lang"))
+	require.Equal(t, "contracts", u.Query().Get("db"))
+	require.Equal(t, "YzE", u.Query().Get("key"))
+	require.Equal(t, "7", u.Query().Get("tx"))
+	require.Equal(t, "cafe", u.Query().Get("hash"))
+
+	_, err = VerificationURL(":", r)
+	require.Error(t, err)
+}
+
+func TestPDF(t *testing.T) {
+	r := &schema.Receipt{
+		Database:  "contracts",
+		Key:       []byte("contract(2021)"),
+		ValueHash: bytes.Repeat([]byte{0xab}, 32),
+		Tx:        42,
+		Timestamp: 1609459200,
+		State:     &schema.ImmutableState{TxHash: bytes.Repeat([]byte{0xcd}, 32)},
+	}
+
+	doc, err := PDF(r)
+	require.NoError(t, err)
+	requireValidPDF(t, doc)
+
+	require.Contains(t, string(doc), `(contract\(2021\))`)
+	require.Contains(t, string(doc), "(2021-01-01T00:00:00Z)")
+	require.Contains(t, string(doc), "(Value hash \\(SHA-256\\))")
+	require.Contains(t, string(doc), "(not signed, the server has no signing key)")
+	require.NotContains(t, string(doc), " re f")
+
+	r.State.Signature = &schema.Signature{Signature: []byte{1, 2}, PublicKey: []byte{3, 4}}
+	r.VerificationUrl = "https://verify.example.com/?tx=42"
+
+	doc, err = PDF(r)
+	require.NoError(t, err)
+	requireValidPDF(t, doc)
+
+	require.Contains(t, string(doc), "(0102)")
+	require.Contains(t, string(doc), "(https://verify.example.com/?tx=42)")
+	require.Contains(t, string(doc), " re f")
+}
+
+// requireValidPDF checks the cross-reference table points to every object of the document
+func requireValidPDF(t *testing.T, doc []byte) {
+	require.True(t, bytes.HasPrefix(doc, []byte("%PDF-1.4\n")))
+	require.True(t, bytes.HasSuffix(doc, []byte("%%EOF\n")))
+
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(doc)
+	require.NotNil(t, m)
+
+	xref, err := strconv.Atoi(string(m[1]))
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(doc[xref:], []byte("xref\n")))
+
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(doc[xref:], -1)
+	require.NotEmpty(t, entries)
+
+	for i, e := range entries {
+		off, err := strconv.Atoi(string(e[1]))
+		require.NoError(t, err)
+		require.True(t, bytes.HasPrefix(doc[off:], []byte(fmt.Sprintf("%d 0 obj\n", i+1))))
+	}
+
+	m = regexp.MustCompile(`<< /Length (\d+) >>\nstream\n`).FindSubmatch(doc)
+	require.NotNil(t, m)
+
+	length, err := strconv.Atoi(string(m[1]))
+	require.NoError(t, err)
+
+	start := bytes.Index(doc, []byte("stream\n")) + len("stream\n")
+	require.Equal(t, []byte("endstream"), doc[start+length:start+length+len("endstream")])
+}
+
+func TestPrintable(t *testing.T) {
+	require.Equal(t, "invoice/1", printable([]byte("invoice/1")))
+	require.Equal(t, "0x00ff", printable([]byte{0, 0xff}))
+	require.Equal(t, "0x6ec3a9", printable([]byte("né")))
+
+	require.Equal(t, []string{""}, wrap(""))
+	require.Len(t, wrap(string(bytes.Repeat([]byte("a"), wrapLen+1))), 2)
+}
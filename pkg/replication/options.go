@@ -0,0 +1,113 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package replication
+
+import (
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+const DefaultMasterPort = 3322
+const DefaultPollInterval = 1 * time.Second
+const DefaultRetryInterval = 5 * time.Second
+
+// Options replication options
+type Options struct {
+	MasterAddress    string
+	MasterPort       int
+	MasterDatabase   string
+	FollowerUsername string
+	FollowerPassword string `json:"-"`
+	PollInterval     time.Duration
+	RetryInterval    time.Duration
+	DialOptions      []grpc.DialOption
+}
+
+// DefaultOptions returns default replication options
+func DefaultOptions() *Options {
+	return &Options{
+		MasterPort:     DefaultMasterPort,
+		MasterDatabase: "defaultdb",
+		PollInterval:   DefaultPollInterval,
+		RetryInterval:  DefaultRetryInterval,
+		DialOptions:    []grpc.DialOption{grpc.WithInsecure()},
+	}
+}
+
+// Valid checks the options can be used to start replicating
+func (o *Options) Valid() bool {
+	return o != nil &&
+		o.MasterAddress != "" &&
+		o.MasterPort > 0 &&
+		o.MasterDatabase != "" &&
+		o.PollInterval > 0 &&
+		o.RetryInterval > 0
+}
+
+// MasterBind returns the address of the master server
+func (o *Options) MasterBind() string {
+	return fmt.Sprintf("%s:%d", o.MasterAddress, o.MasterPort)
+}
+
+// WithMasterAddress sets the address of the master server
+func (o *Options) WithMasterAddress(address string) *Options {
+	o.MasterAddress = address
+	return o
+}
+
+// WithMasterPort sets the port of the master server
+func (o *Options) WithMasterPort(port int) *Options {
+	o.MasterPort = port
+	return o
+}
+
+// WithMasterDatabase sets the database to be replicated from the master server
+func (o *Options) WithMasterDatabase(db string) *Options {
+	o.MasterDatabase = db
+	return o
+}
+
+// WithFollowerUsername sets the user used to authenticate against the master server
+func (o *Options) WithFollowerUsername(username string) *Options {
+	o.FollowerUsername = username
+	return o
+}
+
+// WithFollowerPassword sets the password used to authenticate against the master server
+func (o *Options) WithFollowerPassword(password string) *Options {
+	o.FollowerPassword = password
+	return o
+}
+
+// WithPollInterval sets how often the master is checked for new transactions once the replica is up to date
+func (o *Options) WithPollInterval(interval time.Duration) *Options {
+	o.PollInterval = interval
+	return o
+}
+
+// WithRetryInterval sets how long to wait before reconnecting after the master became unreachable
+func (o *Options) WithRetryInterval(interval time.Duration) *Options {
+	o.RetryInterval = interval
+	return o
+}
+
+// WithDialOptions sets the grpc dial options used to connect to the master server
+func (o *Options) WithDialOptions(dialOptions []grpc.DialOption) *Options {
+	o.DialOptions = dialOptions
+	return o
+}
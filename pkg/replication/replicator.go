@@ -0,0 +1,271 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package replication
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/database"
+	"github.com/codenotary/immudb/pkg/logger"
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+var ErrIllegalArguments = errors.New("illegal arguments")
+var ErrAlreadyRunning = errors.New("replicator already running")
+var ErrAlreadyStopped = errors.New("replicator already stopped")
+
+// ErrReplicaDivergence is returned when the replica history is not a prefix of the master one
+var ErrReplicaDivergence = store.ErrReplicaDivergence
+
+// TxReplicator keeps a replica database up to date by pulling the transactions committed into the master database.
+// Replication is resumed from the last replicated transaction after the master becomes unreachable,
+// while it is halted if the replica ever diverges from the master
+type TxReplicator struct {
+	db     database.DB
+	opts   *Options
+	logger logger.Logger
+
+	mutex   sync.Mutex
+	running bool
+	cancel  context.CancelFunc
+	donec   chan struct{}
+	err     error
+
+	masterTxID uint64
+}
+
+// NewTxReplicator creates a replicator for db, which should have been opened as a replica
+func NewTxReplicator(db database.DB, opts *Options, logger logger.Logger) (*TxReplicator, error) {
+	if db == nil || !opts.Valid() || logger == nil {
+		return nil, ErrIllegalArguments
+	}
+
+	return &TxReplicator{
+		db:     db,
+		opts:   opts,
+		logger: logger,
+	}, nil
+}
+
+// Start starts replicating in background
+func (r *TxReplicator) Start() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.running {
+		return ErrAlreadyRunning
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	r.running = true
+	r.cancel = cancel
+	r.donec = make(chan struct{})
+	r.err = nil
+
+	go r.replicate(ctx, r.donec)
+
+	r.logger.Infof("Replication of database '%s' from '%s' started", r.db.GetName(), r.opts.MasterBind())
+
+	return nil
+}
+
+// Stop stops replicating, it may be resumed later on by calling Start
+func (r *TxReplicator) Stop() error {
+	r.mutex.Lock()
+
+	if !r.running {
+		r.mutex.Unlock()
+		return ErrAlreadyStopped
+	}
+
+	r.cancel()
+	donec := r.donec
+
+	r.mutex.Unlock()
+
+	<-donec
+
+	r.logger.Infof("Replication of database '%s' stopped", r.db.GetName())
+
+	return nil
+}
+
+// Err returns the error which halted the replication, if any
+func (r *TxReplicator) Err() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.err
+}
+
+// Lag returns the number of transactions committed into the master database which have not been replicated yet,
+// as of the latest master state known by the replicator
+func (r *TxReplicator) Lag() (uint64, error) {
+	r.mutex.Lock()
+	masterTxID := r.masterTxID
+	r.mutex.Unlock()
+
+	state, err := r.db.CurrentState()
+	if err != nil {
+		return 0, err
+	}
+
+	if state.TxId >= masterTxID {
+		return 0, nil
+	}
+
+	return masterTxID - state.TxId, nil
+}
+
+func (r *TxReplicator) setMasterTxID(txID uint64) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.masterTxID = txID
+}
+
+func (r *TxReplicator) replicate(ctx context.Context, donec chan<- struct{}) {
+	defer close(donec)
+
+	for {
+		err := r.replicateFromMaster(ctx)
+		if ctx.Err() != nil {
+			r.halt(nil)
+			return
+		}
+
+		if errors.Is(err, ErrReplicaDivergence) {
+			r.logger.Errorf("Replication of database '%s' halted, replica diverged from master '%s'", r.db.GetName(), r.opts.MasterBind())
+			r.halt(err)
+			return
+		}
+
+		r.logger.Warningf("Replication of database '%s' interrupted: %v. Retrying in %v", r.db.GetName(), err, r.opts.RetryInterval)
+
+		select {
+		case <-ctx.Done():
+			r.halt(nil)
+			return
+		case <-time.After(r.opts.RetryInterval):
+		}
+	}
+}
+
+func (r *TxReplicator) halt(err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.running = false
+	r.err = err
+}
+
+func (r *TxReplicator) replicateFromMaster(ctx context.Context) error {
+	conn, err := grpc.DialContext(ctx, r.opts.MasterBind(), r.opts.DialOptions...)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	client := schema.NewImmuServiceClient(conn)
+
+	lr, err := client.Login(ctx, &schema.LoginRequest{
+		User:     []byte(r.opts.FollowerUsername),
+		Password: []byte(r.opts.FollowerPassword),
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx = metadata.NewOutgoingContext(ctx, metadata.Pairs("authorization", lr.Token))
+
+	udr, err := client.UseDatabase(ctx, &schema.Database{DatabaseName: r.opts.MasterDatabase})
+	if err != nil {
+		return err
+	}
+
+	ctx = metadata.NewOutgoingContext(ctx, metadata.Pairs("authorization", udr.Token))
+
+	state, err := r.db.CurrentState()
+	if err != nil {
+		return err
+	}
+
+	masterState, err := client.CurrentState(ctx, &empty.Empty{})
+	if err != nil {
+		return err
+	}
+
+	r.setMasterTxID(masterState.TxId)
+
+	// the replica must be a prefix of the master either when starting or resuming after a partition
+	if state.TxId > masterState.TxId {
+		return ErrReplicaDivergence
+	}
+
+	if state.TxId > 0 {
+		etx, err := client.ExportTx(ctx, &schema.TxRequest{Tx: state.TxId})
+		if err != nil {
+			return err
+		}
+
+		alh := schema.TxMetadataFrom(etx.Metadata).Alh()
+		if !bytes.Equal(alh[:], state.TxHash) {
+			return ErrReplicaDivergence
+		}
+	}
+
+	for {
+		for txID := state.TxId + 1; txID <= masterState.TxId; txID++ {
+			etx, err := client.ExportTx(ctx, &schema.TxRequest{Tx: txID})
+			if err != nil {
+				return err
+			}
+
+			_, err = r.db.ReplicateTx(etx)
+			if err != nil {
+				return err
+			}
+		}
+
+		state = masterState
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(r.opts.PollInterval):
+		}
+
+		masterState, err = client.CurrentState(ctx, &empty.Empty{})
+		if err != nil {
+			return err
+		}
+
+		r.setMasterTxID(masterState.TxId)
+
+		if masterState.TxId < state.TxId {
+			return ErrReplicaDivergence
+		}
+	}
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package replication_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/client"
+	"github.com/codenotary/immudb/pkg/database"
+	"github.com/codenotary/immudb/pkg/logger"
+	"github.com/codenotary/immudb/pkg/replication"
+	"github.com/codenotary/immudb/pkg/server"
+	"github.com/codenotary/immudb/pkg/server/servertest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func newReplicaDb(t *testing.T, dir string, replica bool) database.DB {
+	log := logger.NewSimpleLogger("immudb ", os.Stderr)
+
+	catalogDB, err := database.NewDb(database.DefaultOption().WithDbRootPath(dir).WithDbName("catalog"), nil, log)
+	require.NoError(t, err)
+
+	db, err := database.NewDb(database.DefaultOption().WithDbRootPath(dir).WithDbName("replica").WithReplica(replica), catalogDB, log)
+	require.NoError(t, err)
+
+	return db
+}
+
+func TestTxReplicator(t *testing.T) {
+	options := server.DefaultOptions().WithAuth(true)
+	bs := servertest.NewBufconnServer(options)
+
+	defer os.RemoveAll(options.Dir)
+	defer os.Remove(".state-")
+
+	bs.Start()
+	defer bs.Stop()
+
+	dialOptions := []grpc.DialOption{grpc.WithContextDialer(bs.Dialer), grpc.WithInsecure()}
+
+	cli, err := client.NewImmuClient(client.DefaultOptions().WithDialOptions(&dialOptions))
+	require.NoError(t, err)
+
+	lr, err := cli.Login(context.TODO(), []byte(`immudb`), []byte(`immudb`))
+	require.NoError(t, err)
+
+	ctx := metadata.NewOutgoingContext(context.Background(), metadata.Pairs("authorization", lr.Token))
+
+	for i := 0; i < 5; i++ {
+		_, err = cli.Set(ctx, []byte("key"), []byte{byte(i)})
+		require.NoError(t, err)
+	}
+
+	replicaDir := "data_replica"
+	defer os.RemoveAll(replicaDir)
+
+	replicaDb := newReplicaDb(t, replicaDir, true)
+	defer replicaDb.Close()
+
+	opts := replication.DefaultOptions().
+		WithMasterAddress("bufnet").
+		WithFollowerUsername("immudb").
+		WithFollowerPassword("immudb").
+		WithPollInterval(10 * time.Millisecond).
+		WithRetryInterval(10 * time.Millisecond).
+		WithDialOptions(dialOptions)
+
+	_, err = replication.NewTxReplicator(replicaDb, replication.DefaultOptions(), logger.NewSimpleLogger("replicator ", os.Stderr))
+	require.Equal(t, replication.ErrIllegalArguments, err)
+
+	replicator, err := replication.NewTxReplicator(replicaDb, opts, logger.NewSimpleLogger("replicator ", os.Stderr))
+	require.NoError(t, err)
+
+	err = replicator.Stop()
+	require.Equal(t, replication.ErrAlreadyStopped, err)
+
+	err = replicator.Start()
+	require.NoError(t, err)
+
+	err = replicator.Start()
+	require.Equal(t, replication.ErrAlreadyRunning, err)
+
+	waitForReplica := func() {
+		masterState, err := cli.CurrentState(ctx)
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool {
+			state, err := replicaDb.CurrentState()
+			require.NoError(t, err)
+			return state.TxId == masterState.TxId
+		}, 5*time.Second, 10*time.Millisecond)
+
+		state, err := replicaDb.CurrentState()
+		require.NoError(t, err)
+		require.Equal(t, masterState.TxHash, state.TxHash)
+	}
+
+	waitForReplica()
+
+	lag, err := replicator.Lag()
+	require.NoError(t, err)
+	require.Zero(t, lag)
+
+	err = replicator.Stop()
+	require.NoError(t, err)
+
+	// replication is resumed from the last replicated tx
+	_, err = cli.Set(ctx, []byte("key"), []byte("value"))
+	require.NoError(t, err)
+
+	err = replicator.Start()
+	require.NoError(t, err)
+
+	waitForReplica()
+
+	entry, err := replicaDb.Get(&schema.KeyRequest{Key: []byte("key")})
+	require.NoError(t, err)
+	require.Equal(t, []byte("value"), entry.Value)
+
+	err = replicator.Stop()
+	require.NoError(t, err)
+	require.NoError(t, replicator.Err())
+
+	// a database with its own history can not follow the master
+	divergedDir := "data_diverged"
+	defer os.RemoveAll(divergedDir)
+
+	divergedDb := newReplicaDb(t, divergedDir, false)
+	defer divergedDb.Close()
+
+	_, err = divergedDb.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("key"), Value: []byte("diverged")}}})
+	require.NoError(t, err)
+
+	replicator, err = replication.NewTxReplicator(divergedDb, opts, logger.NewSimpleLogger("replicator ", os.Stderr))
+	require.NoError(t, err)
+
+	err = replicator.Start()
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return replicator.Err() != nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.Equal(t, replication.ErrReplicaDivergence, replicator.Err())
+
+	err = replicator.Stop()
+	require.Equal(t, replication.ErrAlreadyStopped, err)
+}
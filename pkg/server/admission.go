@@ -0,0 +1,184 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package server
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// PriorityMetadataKey is the metadata clients use to send the priority class of a call
+const PriorityMetadataKey = "priority"
+
+// Priority classes of calls, calls without a priority are interactive
+const (
+	PriorityInteractive = "interactive"
+	PriorityBatch       = "batch"
+)
+
+// ErrAdmissionTimeout is returned when a call times out while waiting to be served
+var ErrAdmissionTimeout = status.Error(codes.ResourceExhausted, "server is under load, the call timed out waiting to be served")
+
+// admissionExempt are the methods served regardless of the load, as they are either long-lived or probes
+var admissionExempt = map[string]bool{
+	"Health":   true,
+	"Watch":    true,
+	"WatchJob": true,
+}
+
+// admission limits the number of calls served concurrently. Once the limit is reached, calls are queued and
+// interactive ones are admitted ahead of batch ones, batch calls arriving under load are given a stricter timeout
+type admission struct {
+	maxOps       int
+	batchTimeout time.Duration
+
+	mutex       sync.Mutex
+	running     int
+	interactive *list.List
+	batch       *list.List
+}
+
+func newAdmission(maxOps int, batchTimeout time.Duration) *admission {
+	return &admission{
+		maxOps:       maxOps,
+		batchTimeout: batchTimeout,
+		interactive:  list.New(),
+		batch:        list.New(),
+	}
+}
+
+func priorityOf(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return PriorityInteractive
+	}
+
+	if p := md.Get(PriorityMetadataKey); len(p) > 0 && p[0] == PriorityBatch {
+		return PriorityBatch
+	}
+
+	return PriorityInteractive
+}
+
+// acquire waits until the call can be served, the returned cancel function must be called once it's done
+func (a *admission) acquire(ctx context.Context, priority string) (context.Context, context.CancelFunc, error) {
+	a.mutex.Lock()
+
+	if a.running < a.maxOps && a.interactive.Len() == 0 && a.batch.Len() == 0 {
+		a.running++
+		a.mutex.Unlock()
+
+		return ctx, a.release, nil
+	}
+
+	cancel := func() {}
+
+	queue := a.interactive
+
+	if priority == PriorityBatch {
+		queue = a.batch
+
+		if a.batchTimeout > 0 {
+			ctx, cancel = context.WithTimeout(ctx, a.batchTimeout)
+		}
+	}
+
+	admitted := make(chan struct{})
+	e := queue.PushBack(admitted)
+
+	a.mutex.Unlock()
+
+	select {
+	case <-admitted:
+		return ctx, func() {
+			cancel()
+			a.release()
+		}, nil
+	case <-ctx.Done():
+		a.mutex.Lock()
+
+		select {
+		case <-admitted:
+			// the slot was handed over meanwhile, so it's passed on
+			a.mutex.Unlock()
+			a.release()
+		default:
+			queue.Remove(e)
+			a.mutex.Unlock()
+		}
+
+		cancel()
+
+		return nil, nil, ErrAdmissionTimeout
+	}
+}
+
+// release hands the slot over to the first queued call, interactive calls first
+func (a *admission) release() {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	for _, queue := range []*list.List{a.interactive, a.batch} {
+		if queue.Len() > 0 {
+			close(queue.Remove(queue.Front()).(chan struct{}))
+			return
+		}
+	}
+
+	a.running--
+}
+
+// AdmissionInterceptor limits the number of unary calls served concurrently
+func (a *admission) AdmissionInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if admissionExempt[info.FullMethod[strings.LastIndex(info.FullMethod, "/")+1:]] {
+		return handler(ctx, req)
+	}
+
+	ctx, done, err := a.acquire(ctx, priorityOf(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	return handler(ctx, req)
+}
+
+// AdmissionStreamInterceptor limits the number of streaming calls served concurrently
+func (a *admission) AdmissionStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if admissionExempt[info.FullMethod[strings.LastIndex(info.FullMethod, "/")+1:]] {
+		return handler(srv, ss)
+	}
+
+	ctx, done, err := a.acquire(ss.Context(), priorityOf(ss.Context()))
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	wss := grpc_middleware.WrapServerStream(ss)
+	wss.WrappedContext = ctx
+
+	return handler(srv, wss)
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestAdmissionPriorityOf(t *testing.T) {
+	require.Equal(t, PriorityInteractive, priorityOf(context.Background()))
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(PriorityMetadataKey, PriorityBatch))
+	require.Equal(t, PriorityBatch, priorityOf(ctx))
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(PriorityMetadataKey, "unknown"))
+	require.Equal(t, PriorityInteractive, priorityOf(ctx))
+}
+
+func TestAdmissionInteractiveAheadOfBatch(t *testing.T) {
+	a := newAdmission(1, 0)
+
+	_, done, err := a.acquire(context.Background(), PriorityInteractive)
+	require.NoError(t, err)
+
+	admitted := make(chan string, 2)
+	released := make(chan struct{}, 2)
+
+	wait := func(priority string) {
+		_, done, err := a.acquire(context.Background(), priority)
+		require.NoError(t, err)
+
+		admitted <- priority
+		done()
+		released <- struct{}{}
+	}
+
+	go wait(PriorityBatch)
+	require.Eventually(t, func() bool { return queued(a) == 1 }, time.Second, time.Millisecond)
+
+	go wait(PriorityInteractive)
+	require.Eventually(t, func() bool { return queued(a) == 2 }, time.Second, time.Millisecond)
+
+	done()
+
+	require.Equal(t, PriorityInteractive, <-admitted)
+	require.Equal(t, PriorityBatch, <-admitted)
+
+	<-released
+	<-released
+
+	require.Equal(t, 0, running(a))
+}
+
+func TestAdmissionBatchTimeout(t *testing.T) {
+	a := newAdmission(1, 10*time.Millisecond)
+
+	_, done, err := a.acquire(context.Background(), PriorityInteractive)
+	require.NoError(t, err)
+
+	_, _, err = a.acquire(context.Background(), PriorityBatch)
+	require.Equal(t, ErrAdmissionTimeout, err)
+	require.Equal(t, 0, queued(a))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, _, err = a.acquire(ctx, PriorityInteractive)
+	require.Equal(t, ErrAdmissionTimeout, err)
+
+	done()
+
+	// batch calls are not limited while the server is not under load
+	ctx, done, err = a.acquire(context.Background(), PriorityBatch)
+	require.NoError(t, err)
+	require.NoError(t, ctx.Err())
+	done()
+
+	require.Equal(t, 0, running(a))
+}
+
+func TestAdmissionInterceptor(t *testing.T) {
+	a := newAdmission(1, 10*time.Millisecond)
+
+	_, done, err := a.acquire(context.Background(), PriorityInteractive)
+	require.NoError(t, err)
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+
+	batchCtx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(PriorityMetadataKey, PriorityBatch))
+
+	_, err = a.AdmissionInterceptor(batchCtx, nil, &grpc.UnaryServerInfo{FullMethod: "/immudb.schema.ImmuService/Get"}, handler)
+	require.Equal(t, ErrAdmissionTimeout, err)
+
+	res, err := a.AdmissionInterceptor(batchCtx, nil, &grpc.UnaryServerInfo{FullMethod: "/immudb.schema.ImmuService/Health"}, handler)
+	require.NoError(t, err)
+	require.Equal(t, "ok", res)
+
+	done()
+
+	res, err = a.AdmissionInterceptor(batchCtx, nil, &grpc.UnaryServerInfo{FullMethod: "/immudb.schema.ImmuService/Get"}, handler)
+	require.NoError(t, err)
+	require.Equal(t, "ok", res)
+
+	require.Equal(t, 0, running(a))
+}
+
+func queued(a *admission) int {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	return a.interactive.Len() + a.batch.Len()
+}
+
+func running(a *admission) int {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	return a.running
+}
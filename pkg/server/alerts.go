@@ -0,0 +1,369 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Built-in alert checks
+const (
+	AlertReplicationLag = "replication_lag"
+	AlertDiskUsage      = "disk_usage"
+	AlertAuditFailures  = "audit_failures"
+	AlertCommitLatency  = "commit_latency"
+)
+
+// Alert statuses
+const (
+	AlertFiring   = "firing"
+	AlertResolved = "resolved"
+)
+
+const DefaultAlertCheckInterval = time.Minute
+
+const alertNotificationTimeout = 10 * time.Second
+
+// AlertOptions configures the threshold checks run by the server to notify operators without a monitoring stack.
+// A check is disabled when its threshold is zero
+type AlertOptions struct {
+	CheckInterval time.Duration
+
+	MaxReplicationLag uint64
+	MaxDiskUsage      int64
+	MaxAuditFailures  uint64
+	MaxCommitLatency  time.Duration
+
+	WebhookURL string
+
+	SMTPAddress  string
+	SMTPUsername string
+	SMTPPassword string `json:"-"`
+	EmailFrom    string
+	EmailTo      []string
+}
+
+// DefaultAlertOptions returns alert options without any check nor notification channel
+func DefaultAlertOptions() *AlertOptions {
+	return &AlertOptions{
+		CheckInterval: DefaultAlertCheckInterval,
+	}
+}
+
+// Valid returns true if the checks are periodically run and their alerts can be notified
+func (o *AlertOptions) Valid() bool {
+	return o != nil &&
+		o.CheckInterval > 0 &&
+		(o.WebhookURL != "" || (o.SMTPAddress != "" && o.EmailFrom != "" && len(o.EmailTo) > 0))
+}
+
+// WithCheckInterval sets the time between consecutive checks
+func (o *AlertOptions) WithCheckInterval(interval time.Duration) *AlertOptions {
+	o.CheckInterval = interval
+	return o
+}
+
+// WithMaxReplicationLag sets the number of transactions a replica can fall behind its master
+func (o *AlertOptions) WithMaxReplicationLag(lag uint64) *AlertOptions {
+	o.MaxReplicationLag = lag
+	return o
+}
+
+// WithMaxDiskUsage sets the number of bytes the data directory can take
+func (o *AlertOptions) WithMaxDiskUsage(bytes int64) *AlertOptions {
+	o.MaxDiskUsage = bytes
+	return o
+}
+
+// WithMaxAuditFailures sets the number of failed audit runs which fires an alert
+func (o *AlertOptions) WithMaxAuditFailures(failures uint64) *AlertOptions {
+	o.MaxAuditFailures = failures
+	return o
+}
+
+// WithMaxCommitLatency sets the time a database can take to commit a transaction
+func (o *AlertOptions) WithMaxCommitLatency(latency time.Duration) *AlertOptions {
+	o.MaxCommitLatency = latency
+	return o
+}
+
+// WithWebhookURL sets the URL alerts are posted to as JSON
+func (o *AlertOptions) WithWebhookURL(url string) *AlertOptions {
+	o.WebhookURL = url
+	return o
+}
+
+// WithSMTPAddress sets the address of the mail server used to send alerts by email
+func (o *AlertOptions) WithSMTPAddress(address string) *AlertOptions {
+	o.SMTPAddress = address
+	return o
+}
+
+// WithSMTPCredentials sets the credentials used to authenticate against the mail server
+func (o *AlertOptions) WithSMTPCredentials(username, password string) *AlertOptions {
+	o.SMTPUsername = username
+	o.SMTPPassword = password
+	return o
+}
+
+// WithEmail sets the sender and the recipients of alert emails
+func (o *AlertOptions) WithEmail(from string, to []string) *AlertOptions {
+	o.EmailFrom = from
+	o.EmailTo = to
+	return o
+}
+
+// Alert is notified when a check exceeds its threshold and once again when it's back within it
+type Alert struct {
+	Check     string `json:"check"`
+	Database  string `json:"database,omitempty"`
+	Status    string `json:"status"`
+	Value     string `json:"value"`
+	Threshold string `json:"threshold"`
+	Time      int64  `json:"time"`
+}
+
+func (a *Alert) String() string {
+	s := fmt.Sprintf("[%s] %s", strings.ToUpper(a.Status), a.Check)
+	if a.Database != "" {
+		s += fmt.Sprintf(" on database '%s'", a.Database)
+	}
+	return s + fmt.Sprintf(": %s (threshold %s)", a.Value, a.Threshold)
+}
+
+type alertCheck struct {
+	alert    *Alert
+	exceeded bool
+}
+
+// alertMonitor periodically runs the checks and notifies the alerts whose status changed since the previous run
+type alertMonitor struct {
+	opts   *AlertOptions
+	checks func() []*alertCheck
+	notify func(*Alert)
+
+	firing map[string]bool
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func newAlertMonitor(opts *AlertOptions, checks func() []*alertCheck, notify func(*Alert)) *alertMonitor {
+	return &alertMonitor{
+		opts:   opts,
+		checks: checks,
+		notify: notify,
+		firing: make(map[string]bool),
+	}
+}
+
+func (m *alertMonitor) start() {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	m.cancel = cancel
+	m.done = make(chan struct{})
+
+	go func() {
+		defer close(m.done)
+
+		ticker := time.NewTicker(m.opts.CheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.run()
+			}
+		}
+	}()
+}
+
+func (m *alertMonitor) stop() {
+	if m.cancel == nil {
+		return
+	}
+
+	m.cancel()
+	<-m.done
+
+	m.cancel = nil
+}
+
+func (m *alertMonitor) run() {
+	for _, c := range m.checks() {
+		id := c.alert.Check + ":" + c.alert.Database
+
+		if c.exceeded == m.firing[id] {
+			continue
+		}
+
+		if c.exceeded {
+			c.alert.Status = AlertFiring
+			m.firing[id] = true
+		} else {
+			c.alert.Status = AlertResolved
+			delete(m.firing, id)
+		}
+
+		c.alert.Time = time.Now().Unix()
+
+		m.notify(c.alert)
+	}
+}
+
+func (s *ImmuServer) alertChecks() []*alertCheck {
+	opts := s.Options.AlertOptions
+
+	var checks []*alertCheck
+
+	if opts.MaxReplicationLag > 0 && s.replicator != nil {
+		lag, err := s.replicator.Lag()
+		if err != nil {
+			s.Logger.Errorf("error checking replication lag: %v", err)
+		} else {
+			checks = append(checks, &alertCheck{
+				alert: &Alert{
+					Check:     AlertReplicationLag,
+					Database:  s.dbList.GetByIndex(DefaultDbIndex).GetName(),
+					Value:     fmt.Sprintf("%d txs", lag),
+					Threshold: fmt.Sprintf("%d txs", opts.MaxReplicationLag),
+				},
+				exceeded: lag > opts.MaxReplicationLag,
+			})
+		}
+	}
+
+	if opts.MaxDiskUsage > 0 {
+		size, err := dirSize(s.Options.Dir)
+		if err != nil {
+			s.Logger.Errorf("error checking disk usage: %v", err)
+		} else {
+			checks = append(checks, &alertCheck{
+				alert: &Alert{
+					Check:     AlertDiskUsage,
+					Value:     fmt.Sprintf("%d bytes", size),
+					Threshold: fmt.Sprintf("%d bytes", opts.MaxDiskUsage),
+				},
+				exceeded: size > opts.MaxDiskUsage,
+			})
+		}
+	}
+
+	if opts.MaxAuditFailures > 0 {
+		failures := atomic.LoadUint64(&s.auditFailures)
+
+		checks = append(checks, &alertCheck{
+			alert: &Alert{
+				Check:     AlertAuditFailures,
+				Value:     fmt.Sprintf("%d failed runs", failures),
+				Threshold: fmt.Sprintf("%d failed runs", opts.MaxAuditFailures),
+			},
+			exceeded: failures >= opts.MaxAuditFailures,
+		})
+	}
+
+	if opts.MaxCommitLatency > 0 {
+		for i := 0; i < s.dbList.Length(); i++ {
+			db := s.dbList.GetByIndex(int64(i))
+			latency := db.CommitLatency()
+
+			checks = append(checks, &alertCheck{
+				alert: &Alert{
+					Check:     AlertCommitLatency,
+					Database:  db.GetName(),
+					Value:     latency.String(),
+					Threshold: opts.MaxCommitLatency.String(),
+				},
+				exceeded: latency > opts.MaxCommitLatency,
+			})
+		}
+	}
+
+	return checks
+}
+
+func (s *ImmuServer) notifyAlert(alert *Alert) {
+	s.Logger.Warningf("alert %s", alert)
+
+	s.recordEvent(context.Background(), EventCategoryAlert, alert.Check, alert.Status, map[string]string{
+		"database":  alert.Database,
+		"value":     alert.Value,
+		"threshold": alert.Threshold,
+	})
+
+	opts := s.Options.AlertOptions
+
+	if opts.WebhookURL != "" {
+		if err := postAlert(opts.WebhookURL, alert); err != nil {
+			s.Logger.Errorf("error posting alert to webhook: %v", err)
+		}
+	}
+
+	if opts.SMTPAddress != "" {
+		if err := emailAlert(opts, alert); err != nil {
+			s.Logger.Errorf("error sending alert email: %v", err)
+		}
+	}
+}
+
+func postAlert(url string, alert *Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: alertNotificationTimeout}
+
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	return nil
+}
+
+func emailAlert(opts *AlertOptions, alert *Alert) error {
+	var auth smtp.Auth
+
+	if opts.SMTPUsername != "" {
+		host, _, err := net.SplitHostPort(opts.SMTPAddress)
+		if err != nil {
+			return err
+		}
+		auth = smtp.PlainAuth("", opts.SMTPUsername, opts.SMTPPassword, host)
+	}
+
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: immudb alert %s %s\r\n\r\n%s\r\n",
+		opts.EmailFrom, strings.Join(opts.EmailTo, ", "), alert.Check, alert.Status, alert)
+
+	return smtp.SendMail(opts.SMTPAddress, auth, opts.EmailFrom, opts.EmailTo, []byte(msg))
+}
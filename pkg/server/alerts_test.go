@@ -0,0 +1,146 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/auth"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestAlertOptions(t *testing.T) {
+	require.False(t, DefaultAlertOptions().Valid())
+	require.True(t, DefaultAlertOptions().WithWebhookURL("http://localhost").Valid())
+	require.False(t, DefaultAlertOptions().WithSMTPAddress("localhost:25").Valid())
+	require.True(t, DefaultAlertOptions().WithSMTPAddress("localhost:25").WithEmail("immudb@localhost", []string{"ops@localhost"}).Valid())
+	require.False(t, DefaultAlertOptions().WithWebhookURL("http://localhost").WithCheckInterval(0).Valid())
+
+	s := DefaultServer().WithOptions(DefaultOptions().WithMetricsServer(false).WithAlertOptions(DefaultAlertOptions())).(*ImmuServer)
+	defer os.RemoveAll(s.Options.Dir)
+
+	require.Error(t, s.Initialize())
+}
+
+func TestAlertMonitor(t *testing.T) {
+	exceeded := false
+
+	var notified []*Alert
+
+	m := newAlertMonitor(DefaultAlertOptions(), func() []*alertCheck {
+		return []*alertCheck{{alert: &Alert{Check: AlertDiskUsage}, exceeded: exceeded}}
+	}, func(a *Alert) {
+		notified = append(notified, a)
+	})
+
+	m.run()
+	require.Empty(t, notified)
+
+	exceeded = true
+	m.run()
+	m.run()
+	require.Len(t, notified, 1)
+	require.Equal(t, AlertFiring, notified[0].Status)
+
+	exceeded = false
+	m.run()
+	require.Len(t, notified, 2)
+	require.Equal(t, AlertResolved, notified[1].Status)
+}
+
+func TestServerAlerts(t *testing.T) {
+	var mutex sync.Mutex
+	var alerts []*Alert
+
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alert Alert
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&alert))
+
+		mutex.Lock()
+		alerts = append(alerts, &alert)
+		mutex.Unlock()
+	}))
+	defer webhook.Close()
+
+	alertOptions := DefaultAlertOptions().
+		WithCheckInterval(10 * time.Millisecond).
+		WithMaxDiskUsage(1).
+		WithMaxCommitLatency(time.Nanosecond).
+		WithMaxAuditFailures(1).
+		WithWebhookURL(webhook.URL)
+
+	serverOptions := DefaultOptions().
+		WithMetricsServer(false).
+		WithAdminPassword(auth.SysAdminPassword).
+		WithAlertOptions(alertOptions)
+
+	s := DefaultServer().WithOptions(serverOptions).(*ImmuServer)
+	defer os.RemoveAll(s.Options.Dir)
+
+	err := s.Initialize()
+	require.NoError(t, err)
+
+	lr, err := s.Login(context.Background(), &schema.LoginRequest{
+		User:     []byte(auth.SysAdminUsername),
+		Password: []byte(auth.SysAdminPassword),
+	})
+	require.NoError(t, err)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", lr.Token))
+
+	_, err = s.Set(ctx, &schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("key"), Value: []byte("value")}}})
+	require.NoError(t, err)
+
+	s.recordJobRun(&schema.JobRun{Name: "audit", Kind: JobAudit, Database: DefaultdbName, Error: "tampered"})
+
+	s.alerts.start()
+	defer s.alerts.stop()
+
+	firing := func(check, database string) bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		for _, a := range alerts {
+			if a.Check == check && a.Database == database && a.Status == AlertFiring {
+				return true
+			}
+		}
+		return false
+	}
+
+	require.Eventually(t, func() bool {
+		return firing(AlertDiskUsage, "") && firing(AlertAuditFailures, "") && firing(AlertCommitLatency, DefaultdbName)
+	}, 5*time.Second, 10*time.Millisecond)
+
+	s.alerts.stop()
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	for _, a := range alerts {
+		require.NotEqual(t, AlertReplicationLag, a.Check)
+		require.NotZero(t, a.Time)
+	}
+}
@@ -0,0 +1,340 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/database"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/rs/xid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	ErrApprovalNotRequired  = status.Error(codes.FailedPrecondition, "write does not require approval")
+	ErrPendingWriteNotFound = status.Error(codes.NotFound, "pending write not found")
+	ErrPendingWriteDecided  = status.Error(codes.FailedPrecondition, "pending write was already approved or rejected")
+	ErrSelfApproval         = status.Error(codes.PermissionDenied, "writes must be approved or rejected by a user other than the proposer")
+)
+
+// SetApprovalRules replaces the key prefixes of the selected database whose writes must be approved by a second user.
+// Rules are persisted in the system database and applied again when the database is loaded
+func (s *ImmuServer) SetApprovalRules(ctx context.Context, rules *schema.ApprovalRules) (*empty.Empty, error) {
+	if rules == nil {
+		return nil, ErrIllegalArguments
+	}
+
+	if !s.Options.GetAuth() || s.sysDb == nil {
+		return nil, fmt.Errorf("this command is available only with authentication on")
+	}
+
+	ind, err := s.getDbIndexFromCtx(ctx, "SetApprovalRules")
+	if err != nil {
+		return nil, err
+	}
+
+	db := s.dbList.GetByIndex(ind)
+
+	prevRules := db.GetApprovalRules()
+
+	err = db.SetApprovalRules(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.saveApprovalRules(db.GetOptions().GetDbName(), rules)
+	if err != nil {
+		db.SetApprovalRules(prevRules)
+		return nil, err
+	}
+
+	s.recordEvent(ctx, EventCategoryDatabase, db.GetOptions().GetDbName(), "approval rules changed", nil)
+
+	return &empty.Empty{}, nil
+}
+
+// GetApprovalRules returns the key prefixes of the selected database whose writes must be approved
+func (s *ImmuServer) GetApprovalRules(ctx context.Context, _ *empty.Empty) (*schema.ApprovalRules, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "GetApprovalRules")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.dbList.GetByIndex(ind).GetApprovalRules(), nil
+}
+
+// ProposeWrite stages operations writing keys which require approval. Operations are validated but nothing is written
+// into the database until a second user approves them
+func (s *ImmuServer) ProposeWrite(ctx context.Context, req *schema.ExecAllRequest) (*schema.PendingWrite, error) {
+	if req == nil || req.DryRun {
+		return nil, ErrIllegalArguments
+	}
+
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	db, user, err := s.approvalContext(ctx, "ProposeWrite")
+	if err != nil {
+		return nil, err
+	}
+
+	if !db.RequiresApproval(req) {
+		return nil, ErrApprovalNotRequired
+	}
+
+	req.Attribution = s.attribution(ctx, req.Attribution)
+
+	dryRun := proto.Clone(req).(*schema.ExecAllRequest)
+	dryRun.DryRun = true
+
+	_, err = db.ExecAllApproved(dryRun)
+	if err != nil {
+		return nil, err
+	}
+
+	pw := &schema.PendingWrite{
+		Id:         xid.New().String(),
+		Database:   db.GetOptions().GetDbName(),
+		Request:    req,
+		ProposedBy: user,
+		ProposedAt: time.Now().Unix(),
+	}
+
+	err = s.savePendingWrite(pw)
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordEvent(ctx, EventCategoryDatabase, pw.Database, "write proposed", map[string]string{"id": pw.Id})
+
+	return pw, nil
+}
+
+// ListPendingWrites returns the writes proposed on the selected database which are waiting for approval
+func (s *ImmuServer) ListPendingWrites(ctx context.Context, req *schema.PendingWritesRequest) (*schema.PendingWrites, error) {
+	if req == nil {
+		return nil, ErrIllegalArguments
+	}
+
+	db, _, err := s.approvalContext(ctx, "ListPendingWrites")
+	if err != nil {
+		return nil, err
+	}
+
+	scanReq := &schema.ScanRequest{Prefix: pendingWriteKey(db.GetOptions().GetDbName(), "")}
+
+	list := &schema.PendingWrites{}
+
+	for {
+		entries, err := s.sysDb.Scan(ctx, scanReq)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, e := range entries.Entries {
+			var pw schema.PendingWrite
+
+			err = proto.Unmarshal(e.Value, &pw)
+			if err != nil {
+				return nil, err
+			}
+
+			if pw.Status == schema.PendingWriteStatus_WRITE_PENDING || req.IncludeDecided {
+				list.Writes = append(list.Writes, &pw)
+			}
+		}
+
+		if len(entries.Entries) < database.MaxKeyScanLimit {
+			return list, nil
+		}
+
+		scanReq.SeekKey = entries.Entries[len(entries.Entries)-1].Key
+	}
+}
+
+// ApproveWrite commits a pending write. The approver must be a user other than the proposer
+func (s *ImmuServer) ApproveWrite(ctx context.Context, req *schema.PendingWriteDecision) (*schema.PendingWrite, error) {
+	return s.decidePendingWrite(ctx, "ApproveWrite", req, true)
+}
+
+// RejectWrite discards a pending write, the rejection is recorded along with the provided reason
+func (s *ImmuServer) RejectWrite(ctx context.Context, req *schema.PendingWriteDecision) (*schema.PendingWrite, error) {
+	return s.decidePendingWrite(ctx, "RejectWrite", req, false)
+}
+
+func (s *ImmuServer) decidePendingWrite(ctx context.Context, methodName string, req *schema.PendingWriteDecision, approve bool) (*schema.PendingWrite, error) {
+	if req == nil || req.Id == "" {
+		return nil, ErrIllegalArguments
+	}
+
+	db, user, err := s.approvalContext(ctx, methodName)
+	if err != nil {
+		return nil, err
+	}
+
+	// decisions are serialized so a pending write is committed at most once
+	s.approvalMux.Lock()
+	defer s.approvalMux.Unlock()
+
+	pw, err := s.loadPendingWrite(db.GetOptions().GetDbName(), req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if pw.Status != schema.PendingWriteStatus_WRITE_PENDING {
+		return nil, ErrPendingWriteDecided
+	}
+
+	if pw.ProposedBy == user {
+		return nil, ErrSelfApproval
+	}
+
+	pw.DecidedBy = user
+	pw.DecidedAt = time.Now().Unix()
+	pw.Reason = req.Reason
+
+	event := "write rejected"
+	pw.Status = schema.PendingWriteStatus_WRITE_REJECTED
+
+	if approve {
+		txmd, err := db.ExecAllApproved(pw.Request)
+		if err != nil {
+			return nil, err
+		}
+
+		event = "write approved"
+		pw.Status = schema.PendingWriteStatus_WRITE_APPROVED
+		pw.Tx = txmd.Id
+	}
+
+	err = s.savePendingWrite(pw)
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordEvent(ctx, EventCategoryDatabase, pw.Database, event, map[string]string{"id": pw.Id, "reason": pw.Reason})
+
+	return pw, nil
+}
+
+// approvalContext returns the selected database and the name of the logged in user.
+// Approvals rely on user identities, thus they are available only with authentication on
+func (s *ImmuServer) approvalContext(ctx context.Context, methodName string) (database.DB, string, error) {
+	if !s.Options.GetAuth() || s.sysDb == nil {
+		return nil, "", fmt.Errorf("this command is available only with authentication on")
+	}
+
+	ind, err := s.getDbIndexFromCtx(ctx, methodName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	_, user, err := s.getLoggedInUserdataFromCtx(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return s.dbList.GetByIndex(ind), user.Username, nil
+}
+
+func (s *ImmuServer) savePendingWrite(pw *schema.PendingWrite) error {
+	pwData, err := proto.Marshal(pw)
+	if err != nil {
+		return logErr(s.Logger, "error saving pending write: %v", err)
+	}
+
+	_, err = s.sysDb.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: pendingWriteKey(pw.Database, pw.Id), Value: pwData}}})
+
+	return logErr(s.Logger, "error saving pending write: %v", err)
+}
+
+func (s *ImmuServer) loadPendingWrite(dbName, id string) (*schema.PendingWrite, error) {
+	item, err := s.sysDb.Get(&schema.KeyRequest{Key: pendingWriteKey(dbName, id)})
+	if err == store.ErrKeyNotFound {
+		return nil, ErrPendingWriteNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var pw schema.PendingWrite
+
+	err = proto.Unmarshal(item.Value, &pw)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pw, nil
+}
+
+func (s *ImmuServer) saveApprovalRules(dbName string, rules *schema.ApprovalRules) error {
+	rulesData, err := proto.Marshal(rules)
+	if err != nil {
+		return logErr(s.Logger, "error saving approval rules: %v", err)
+	}
+
+	_, err = s.sysDb.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: approvalRulesKey(dbName), Value: rulesData}}})
+
+	return logErr(s.Logger, "error saving approval rules: %v", err)
+}
+
+// loadApprovalRules applies the approval rules persisted for the database, if any
+func (s *ImmuServer) loadApprovalRules(db database.DB) error {
+	if s.sysDb == nil {
+		return nil
+	}
+
+	item, err := s.sysDb.Get(&schema.KeyRequest{Key: approvalRulesKey(db.GetOptions().GetDbName())})
+	if err == store.ErrKeyNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var rules schema.ApprovalRules
+
+	err = proto.Unmarshal(item.Value, &rules)
+	if err != nil {
+		return err
+	}
+
+	return db.SetApprovalRules(&rules)
+}
+
+func approvalRulesKey(dbName string) []byte {
+	key := make([]byte, 1+len(dbName))
+	key[0] = KeyPrefixApprovalRules
+	copy(key[1:], dbName)
+	return key
+}
+
+// pendingWriteKey is made of the database name and the id of the write, an empty id gives the prefix of the database
+func pendingWriteKey(dbName, id string) []byte {
+	key := make([]byte, 1+len(dbName)+1+len(id))
+	key[0] = KeyPrefixPendingWrite
+	copy(key[1:], dbName)
+	copy(key[1+len(dbName)+1:], id)
+	return key
+}
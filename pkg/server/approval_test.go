@@ -0,0 +1,162 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package server
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/auth"
+	"github.com/codenotary/immudb/pkg/database"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestServerApprovals(t *testing.T) {
+	serverOptions := DefaultOptions().
+		WithMetricsServer(false).
+		WithAdminPassword(auth.SysAdminPassword).
+		WithListener(bufconn.Listen(1024 * 1024))
+	s := DefaultServer().WithOptions(serverOptions).(*ImmuServer)
+	defer os.RemoveAll(s.Options.Dir)
+
+	require.NoError(t, s.Initialize())
+
+	lr, err := s.Login(context.Background(), &schema.LoginRequest{
+		User:     []byte(auth.SysAdminUsername),
+		Password: []byte(auth.SysAdminPassword),
+	})
+	require.NoError(t, err)
+
+	md := metadata.Pairs("authorization", lr.Token)
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+
+	_, err = s.CreateUser(ctx, &schema.CreateUserRequest{
+		User:       testUsername,
+		Password:   testPassword,
+		Database:   DefaultdbName,
+		Permission: auth.PermissionRW,
+	})
+	require.NoError(t, err)
+
+	lr, err = s.Login(context.Background(), &schema.LoginRequest{User: testUsername, Password: testPassword})
+	require.NoError(t, err)
+
+	ur, err := s.UseDatabase(metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", lr.Token)), &schema.Database{DatabaseName: DefaultdbName})
+	require.NoError(t, err)
+
+	approverCtx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", ur.Token))
+
+	_, err = s.SetApprovalRules(ctx, nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = s.SetApprovalRules(approverCtx, &schema.ApprovalRules{Prefixes: [][]byte{[]byte("ledger:")}})
+	require.Error(t, err)
+
+	_, err = s.SetApprovalRules(ctx, &schema.ApprovalRules{Prefixes: [][]byte{[]byte("ledger:")}})
+	require.NoError(t, err)
+
+	rules, err := s.GetApprovalRules(approverCtx, &empty.Empty{})
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{[]byte("ledger:")}, rules.Prefixes)
+
+	_, err = s.Set(ctx, &schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("ledger:1"), Value: []byte("100")}}})
+	require.Equal(t, database.ErrApprovalRequired, err)
+
+	_, err = s.ProposeWrite(ctx, &schema.ExecAllRequest{Operations: []*schema.Op{
+		{Operation: &schema.Op_Kv{Kv: &schema.KeyValue{Key: []byte("other:1"), Value: []byte("100")}}},
+	}})
+	require.Equal(t, ErrApprovalNotRequired, err)
+
+	req := &schema.ExecAllRequest{Operations: []*schema.Op{
+		{Operation: &schema.Op_Kv{Kv: &schema.KeyValue{Key: []byte("ledger:1"), Value: []byte("100")}}},
+	}}
+
+	pw1, err := s.ProposeWrite(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, schema.PendingWriteStatus_WRITE_PENDING, pw1.Status)
+	require.Equal(t, auth.SysAdminUsername, pw1.ProposedBy)
+
+	pw2, err := s.ProposeWrite(ctx, req)
+	require.NoError(t, err)
+
+	// pending writes are not part of the database state
+	_, err = s.Get(ctx, &schema.KeyRequest{Key: []byte("ledger:1")})
+	require.Error(t, err)
+
+	pending, err := s.ListPendingWrites(approverCtx, &schema.PendingWritesRequest{})
+	require.NoError(t, err)
+	require.Len(t, pending.Writes, 2)
+
+	_, err = s.ApproveWrite(ctx, &schema.PendingWriteDecision{Id: pw1.Id})
+	require.Equal(t, ErrSelfApproval, err)
+
+	_, err = s.ApproveWrite(approverCtx, &schema.PendingWriteDecision{Id: "unknown"})
+	require.Equal(t, ErrPendingWriteNotFound, err)
+
+	approved, err := s.ApproveWrite(approverCtx, &schema.PendingWriteDecision{Id: pw1.Id})
+	require.NoError(t, err)
+	require.Equal(t, schema.PendingWriteStatus_WRITE_APPROVED, approved.Status)
+	require.Equal(t, string(testUsername), approved.DecidedBy)
+	require.Greater(t, approved.Tx, uint64(0))
+
+	entry, err := s.Get(ctx, &schema.KeyRequest{Key: []byte("ledger:1")})
+	require.NoError(t, err)
+	require.Equal(t, []byte("100"), entry.Value)
+	require.Equal(t, approved.Tx, entry.Tx)
+
+	_, err = s.ApproveWrite(approverCtx, &schema.PendingWriteDecision{Id: pw1.Id})
+	require.Equal(t, ErrPendingWriteDecided, err)
+
+	rejected, err := s.RejectWrite(approverCtx, &schema.PendingWriteDecision{Id: pw2.Id, Reason: "duplicated"})
+	require.NoError(t, err)
+	require.Equal(t, schema.PendingWriteStatus_WRITE_REJECTED, rejected.Status)
+	require.Equal(t, "duplicated", rejected.Reason)
+
+	pending, err = s.ListPendingWrites(approverCtx, &schema.PendingWritesRequest{})
+	require.NoError(t, err)
+	require.Empty(t, pending.Writes)
+
+	decided, err := s.ListPendingWrites(approverCtx, &schema.PendingWritesRequest{IncludeDecided: true})
+	require.NoError(t, err)
+	require.Len(t, decided.Writes, 2)
+
+	err = s.CloseDatabases()
+	require.NoError(t, err)
+
+	// rules are loaded again along with the database
+	s = DefaultServer().WithOptions(serverOptions).(*ImmuServer)
+
+	require.NoError(t, s.Initialize())
+
+	lr, err = s.Login(context.Background(), &schema.LoginRequest{
+		User:     []byte(auth.SysAdminUsername),
+		Password: []byte(auth.SysAdminPassword),
+	})
+	require.NoError(t, err)
+
+	ur, err = s.UseDatabase(metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", lr.Token)), &schema.Database{DatabaseName: DefaultdbName})
+	require.NoError(t, err)
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", ur.Token))
+
+	_, err = s.Set(ctx, &schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("ledger:2"), Value: []byte("100")}}})
+	require.Equal(t, database.ErrApprovalRequired, err)
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+)
+
+// attribution returns the authorship to be recorded along with a write, nil if attribution is disabled.
+// The user is always the authenticated one, while the actor is the one provided by the client, if any.
+func (s *ImmuServer) attribution(ctx context.Context, requested *schema.Attribution) *schema.Attribution {
+	if !s.Options.Attribution {
+		return nil
+	}
+
+	attribution := &schema.Attribution{}
+
+	if requested != nil {
+		attribution.Actor = requested.Actor
+	}
+
+	if s.Options.GetAuth() {
+		_, user, err := s.getLoggedInUserdataFromCtx(ctx)
+		if err == nil {
+			attribution.User = user.Username
+		}
+	}
+
+	return attribution
+}
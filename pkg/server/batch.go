@@ -31,6 +31,57 @@ func (s *ImmuServer) GetAll(ctx context.Context, req *schema.KeyListRequest) (*s
 	return list, nil
 }
 
+// GetMulti resolves a list of keys against a single snapshot
+func (s *ImmuServer) GetMulti(ctx context.Context, req *schema.GetMultiRequest) (*schema.GetMultiResponse, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "GetMulti")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.dbList.GetByIndex(ind).GetMulti(req)
+}
+
+// VerifiableGetMulti resolves a list of keys against a single snapshot along with the proofs of each entry
+func (s *ImmuServer) VerifiableGetMulti(ctx context.Context, req *schema.VerifiableGetMultiRequest) (*schema.VerifiableGetMultiResponse, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "VerifiableGetMulti")
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.dbList.GetByIndex(ind).VerifiableGetMulti(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.Options.SigningKey != "" {
+		for _, item := range res.Items {
+			if item.VerifiableEntry == nil {
+				continue
+			}
+
+			vTx := item.VerifiableEntry.VerifiableTx
+
+			md := schema.TxMetadataFrom(vTx.DualProof.TargetTxMetadata)
+			alh := md.Alh()
+
+			newState := &schema.ImmutableState{
+				Db:     s.dbList.GetByIndex(ind).GetOptions().GetDbName(),
+				TxId:   md.ID,
+				TxHash: alh[:],
+			}
+
+			err = s.StateSigner.Sign(newState)
+			if err != nil {
+				return nil, err
+			}
+
+			vTx.Signature = newState.Signature
+		}
+	}
+
+	return res, nil
+}
+
 func (s *ImmuServer) ExecAll(ctx context.Context, req *schema.ExecAllRequest) (*schema.TxMetadata, error) {
 	s.Logger.Debugf("set atomic operations")
 
@@ -39,5 +90,14 @@ func (s *ImmuServer) ExecAll(ctx context.Context, req *schema.ExecAllRequest) (*
 		return nil, err
 	}
 
+	if req != nil {
+		req.Attribution = s.attribution(ctx, req.Attribution)
+
+		req.IdempotencyKey, err = s.idempotencyKey(ctx, ind, req.IdempotencyKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return s.dbList.GetByIndex(ind).ExecAll(req)
 }
@@ -0,0 +1,322 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/codenotary/immudb/embedded/sql"
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/database"
+)
+
+type bindingsColumn struct {
+	name       string
+	sqlType    string
+	nullable   bool
+	primaryKey bool
+}
+
+type bindingsTable struct {
+	name    string
+	columns []*bindingsColumn
+}
+
+type bindingsGenerator func(db string, pkg string, tables []*bindingsTable) []*schema.BindingsFile
+
+var bindingsGenerators = map[string]bindingsGenerator{
+	"proto":      protoBindings,
+	"typescript": typescriptBindings,
+	"python":     pythonBindings,
+}
+
+// GenerateBindings emits typed client stubs for the tables of the selected database
+func (s *ImmuServer) GenerateBindings(ctx context.Context, req *schema.BindingsRequest) (*schema.Bindings, error) {
+	if req == nil {
+		return nil, ErrIllegalArguments
+	}
+
+	generate, ok := bindingsGenerators[strings.ToLower(req.Language)]
+	if !ok {
+		return nil, ErrUnsupportedBindingsLanguage
+	}
+
+	ind, err := s.getDbIndexFromCtx(ctx, "GenerateBindings")
+	if err != nil {
+		return nil, err
+	}
+
+	db := s.dbList.GetByIndex(ind)
+
+	tables, err := describeBindingsTables(db, req.Tables)
+	if err != nil {
+		return nil, err
+	}
+
+	dbName := db.GetOptions().GetDbName()
+
+	pkg := req.Package
+	if pkg == "" {
+		pkg = dbName
+	}
+
+	return &schema.Bindings{Files: generate(dbName, identifier(pkg), tables)}, nil
+}
+
+func describeBindingsTables(db database.DB, tableNames []string) ([]*bindingsTable, error) {
+	if len(tableNames) == 0 {
+		res, err := db.ListTables()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, row := range res.Rows {
+			tableNames = append(tableNames, row.Values[0].GetS())
+		}
+	}
+
+	tables := make([]*bindingsTable, len(tableNames))
+
+	for i, name := range tableNames {
+		res, err := db.DescribeTable(name)
+		if err != nil {
+			return nil, err
+		}
+
+		table := &bindingsTable{name: name}
+
+		for _, row := range res.Rows {
+			primaryKey := row.Values[3].GetS() == "PRIMARY KEY"
+
+			table.columns = append(table.columns, &bindingsColumn{
+				name:       row.Values[0].GetS(),
+				sqlType:    row.Values[1].GetS(),
+				nullable:   row.Values[2].GetB() && !primaryKey,
+				primaryKey: primaryKey,
+			})
+		}
+
+		tables[i] = table
+	}
+
+	return tables, nil
+}
+
+func (t *bindingsTable) primaryKey() string {
+	for _, c := range t.columns {
+		if c.primaryKey {
+			return c.name
+		}
+	}
+	return ""
+}
+
+func (t *bindingsTable) upsertStmt() string {
+	cols := make([]string, len(t.columns))
+	params := make([]string, len(t.columns))
+
+	for i, c := range t.columns {
+		cols[i] = c.name
+		params[i] = "@" + c.name
+	}
+
+	return fmt.Sprintf("UPSERT INTO %s(%s) VALUES (%s)", t.name, strings.Join(cols, ", "), strings.Join(params, ", "))
+}
+
+func bindingsHeader(comment string, db string) string {
+	return fmt.Sprintf("%s Code generated by immudb from the tables of database %s. DO NOT EDIT.\n", comment, db)
+}
+
+func protoBindings(db string, pkg string, tables []*bindingsTable) []*schema.BindingsFile {
+	types := map[string]string{
+		sql.IntegerType:   "int64",
+		sql.BooleanType:   "bool",
+		sql.VarcharType:   "string",
+		sql.BLOBType:      "bytes",
+		sql.TimestampType: "int64",
+	}
+
+	var b strings.Builder
+
+	b.WriteString(bindingsHeader("//", db))
+	b.WriteString("syntax = \"proto3\";\n\n")
+	fmt.Fprintf(&b, "package %s;\n", pkg)
+
+	for _, t := range tables {
+		fmt.Fprintf(&b, "\n// %s is a row of table %s, primary key %s\n", typeName(t.name), t.name, t.primaryKey())
+		fmt.Fprintf(&b, "message %s {\n", typeName(t.name))
+
+		for i, c := range t.columns {
+			optional := ""
+			if c.nullable {
+				optional = "optional "
+			}
+			fmt.Fprintf(&b, "\t%s%s %s = %d;\n", optional, types[c.sqlType], c.name, i+1)
+		}
+
+		b.WriteString("}\n")
+	}
+
+	return []*schema.BindingsFile{{Name: pkg + ".proto", Content: b.String()}}
+}
+
+func typescriptBindings(db string, pkg string, tables []*bindingsTable) []*schema.BindingsFile {
+	types := map[string]string{
+		sql.IntegerType:   "number",
+		sql.BooleanType:   "boolean",
+		sql.VarcharType:   "string",
+		sql.BLOBType:      "Uint8Array",
+		sql.TimestampType: "number",
+	}
+
+	var b strings.Builder
+
+	b.WriteString(bindingsHeader("//", db))
+
+	for _, t := range tables {
+		name := typeName(t.name)
+
+		fmt.Fprintf(&b, "\nexport interface %s {\n", name)
+		for _, c := range t.columns {
+			nullable := ""
+			if c.nullable {
+				nullable = " | null"
+			}
+			fmt.Fprintf(&b, "  %s: %s%s;\n", c.name, types[c.sqlType], nullable)
+		}
+		b.WriteString("}\n")
+
+		cols := make([]string, len(t.columns))
+		fields := make([]string, len(t.columns))
+		for i, c := range t.columns {
+			cols[i] = fmt.Sprintf("%q", c.name)
+			fields[i] = fmt.Sprintf("%s: values[%d] as %s[%q]", c.name, i, name, c.name)
+		}
+
+		fmt.Fprintf(&b, "\nexport const %sTable = {\n", name)
+		fmt.Fprintf(&b, "  name: %q,\n", t.name)
+		fmt.Fprintf(&b, "  columns: [%s] as const,\n", strings.Join(cols, ", "))
+		fmt.Fprintf(&b, "  primaryKey: %q,\n", t.primaryKey())
+		b.WriteString("};\n")
+
+		fmt.Fprintf(&b, "\nexport function %sFromRow(values: unknown[]): %s {\n", lowerFirst(name), name)
+		fmt.Fprintf(&b, "  return { %s };\n", strings.Join(fields, ", "))
+		b.WriteString("}\n")
+
+		fmt.Fprintf(&b, "\nexport function upsert%s(row: %s): { sql: string; params: %s } {\n", name, name, name)
+		fmt.Fprintf(&b, "  return { sql: %q, params: row };\n", t.upsertStmt())
+		b.WriteString("}\n")
+	}
+
+	return []*schema.BindingsFile{{Name: pkg + ".ts", Content: b.String()}}
+}
+
+func pythonBindings(db string, pkg string, tables []*bindingsTable) []*schema.BindingsFile {
+	types := map[string]string{
+		sql.IntegerType:   "int",
+		sql.BooleanType:   "bool",
+		sql.VarcharType:   "str",
+		sql.BLOBType:      "bytes",
+		sql.TimestampType: "int",
+	}
+
+	var b strings.Builder
+
+	b.WriteString(bindingsHeader("#", db))
+	b.WriteString("from dataclasses import asdict, dataclass\n")
+	b.WriteString("from typing import Any, Dict, Optional, Sequence, Tuple\n")
+
+	for _, t := range tables {
+		name := typeName(t.name)
+
+		cols := make([]string, len(t.columns))
+		for i, c := range t.columns {
+			cols[i] = fmt.Sprintf("%q", c.name)
+		}
+
+		fmt.Fprintf(&b, "\n\n@dataclass\nclass %s:\n", name)
+		for _, c := range t.columns {
+			typ := types[c.sqlType]
+			if c.nullable {
+				typ = "Optional[" + typ + "]"
+			}
+			fmt.Fprintf(&b, "    %s: %s\n", c.name, typ)
+		}
+
+		fmt.Fprintf(&b, "\n    TABLE = %q\n", t.name)
+		fmt.Fprintf(&b, "    COLUMNS = (%s,)\n", strings.Join(cols, ", "))
+		fmt.Fprintf(&b, "    PRIMARY_KEY = %q\n", t.primaryKey())
+
+		b.WriteString("\n    @classmethod\n")
+		fmt.Fprintf(&b, "    def from_row(cls, values: Sequence[Any]) -> \"%s\":\n", name)
+		b.WriteString("        return cls(*values)\n")
+
+		b.WriteString("\n    def upsert(self) -> Tuple[str, Dict[str, Any]]:\n")
+		fmt.Fprintf(&b, "        return %q, asdict(self)\n", t.upsertStmt())
+	}
+
+	return []*schema.BindingsFile{{Name: pkg + ".py", Content: b.String()}}
+}
+
+// identifier maps a name into one valid in the generated languages
+func identifier(name string) string {
+	id := []rune(strings.ToLower(name))
+
+	for i, r := range id {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			id[i] = '_'
+		}
+	}
+
+	if len(id) == 0 || unicode.IsDigit(id[0]) {
+		return "_" + string(id)
+	}
+
+	return string(id)
+}
+
+// typeName maps a table name into a type name, e.g. order_items into OrderItems
+func typeName(name string) string {
+	var b strings.Builder
+
+	upper := true
+
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+func lowerFirst(name string) string {
+	if name == "" {
+		return name
+	}
+	r := []rune(name)
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
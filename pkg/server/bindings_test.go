@@ -0,0 +1,106 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package server
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/auth"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestGenerateBindings(t *testing.T) {
+	serverOptions := DefaultOptions().
+		WithMetricsServer(false).
+		WithAdminPassword(auth.SysAdminPassword).
+		WithListener(bufconn.Listen(1024 * 1024))
+	s := DefaultServer().WithOptions(serverOptions).(*ImmuServer)
+	defer os.RemoveAll(s.Options.Dir)
+
+	require.NoError(t, s.Initialize())
+
+	ctx := context.Background()
+
+	_, err := s.GenerateBindings(ctx, nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = s.GenerateBindings(ctx, &schema.BindingsRequest{Language: "python"})
+	require.Error(t, err)
+
+	lr, err := s.Login(ctx, &schema.LoginRequest{
+		User:     []byte(auth.SysAdminUsername),
+		Password: []byte(auth.SysAdminPassword),
+	})
+	require.NoError(t, err)
+
+	md := metadata.Pairs("authorization", lr.Token)
+	ctx = metadata.NewIncomingContext(context.Background(), md)
+
+	_, err = s.GenerateBindings(ctx, &schema.BindingsRequest{Language: "cobol"})
+	require.Equal(t, ErrUnsupportedBindingsLanguage, err)
+
+	res, err := s.GenerateBindings(ctx, &schema.BindingsRequest{Language: "proto"})
+	require.NoError(t, err)
+	require.Len(t, res.Files, 1)
+	require.NotContains(t, res.Files[0].Content, "message")
+
+	_, err = s.SQLExec(ctx, &schema.SQLExecRequest{Sql: `
+		CREATE TABLE order_items (id INTEGER, title VARCHAR, paid BOOLEAN, receipt BLOB, PRIMARY KEY id);
+		CREATE TABLE customers (id INTEGER, name VARCHAR, PRIMARY KEY id);
+	`})
+	require.NoError(t, err)
+
+	_, err = s.GenerateBindings(ctx, &schema.BindingsRequest{Language: "proto", Tables: []string{"missing"}})
+	require.Error(t, err)
+
+	res, err = s.GenerateBindings(ctx, &schema.BindingsRequest{Language: "proto", Package: "shop-api"})
+	require.NoError(t, err)
+	require.Len(t, res.Files, 1)
+	require.Equal(t, "shop_api.proto", res.Files[0].Name)
+	require.Contains(t, res.Files[0].Content, "package shop_api;")
+	require.Contains(t, res.Files[0].Content, "message OrderItems {")
+	require.Contains(t, res.Files[0].Content, "message Customers {")
+	require.Contains(t, res.Files[0].Content, "\tint64 id = 1;")
+	require.Contains(t, res.Files[0].Content, "optional bytes receipt = 4;")
+
+	res, err = s.GenerateBindings(ctx, &schema.BindingsRequest{Language: "TypeScript", Tables: []string{"order_items"}})
+	require.NoError(t, err)
+	require.Equal(t, "defaultdb.ts", res.Files[0].Name)
+	require.Contains(t, res.Files[0].Content, "export interface OrderItems {")
+	require.Contains(t, res.Files[0].Content, "title: string | null;")
+	require.Contains(t, res.Files[0].Content, `primaryKey: "id",`)
+	require.Contains(t, res.Files[0].Content, "UPSERT INTO order_items(id, title, paid, receipt) VALUES (@id, @title, @paid, @receipt)")
+	require.NotContains(t, res.Files[0].Content, "Customers")
+
+	res, err = s.GenerateBindings(ctx, &schema.BindingsRequest{Language: "python", Tables: []string{"customers"}})
+	require.NoError(t, err)
+	require.Equal(t, "defaultdb.py", res.Files[0].Name)
+	require.Contains(t, res.Files[0].Content, "class Customers:")
+	require.Contains(t, res.Files[0].Content, "    name: Optional[str]\n")
+	require.Contains(t, res.Files[0].Content, `COLUMNS = ("id", "name",)`)
+}
+
+func TestBindingsIdentifiers(t *testing.T) {
+	require.Equal(t, "my_db", identifier("My-DB"))
+	require.Equal(t, "_1db", identifier("1db"))
+	require.Equal(t, "OrderItems", typeName("order_items"))
+	require.Equal(t, "orderItems", lowerFirst("OrderItems"))
+}
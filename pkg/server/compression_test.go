@@ -0,0 +1,81 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package server
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/codenotary/immudb/embedded/appendable"
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/auth"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestServerCompressedDatabase(t *testing.T) {
+	serverOptions := DefaultOptions().
+		WithMetricsServer(false).
+		WithAdminPassword(auth.SysAdminPassword).
+		WithListener(bufconn.Listen(1024 * 1024))
+	s := DefaultServer().WithOptions(serverOptions).(*ImmuServer)
+	defer os.RemoveAll(s.Options.Dir)
+
+	require.NoError(t, s.Initialize())
+
+	lr, err := s.Login(context.Background(), &schema.LoginRequest{
+		User:     []byte(auth.SysAdminUsername),
+		Password: []byte(auth.SysAdminPassword),
+	})
+	require.NoError(t, err)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", lr.Token))
+
+	_, err = s.CreateDatabase(ctx, &schema.Database{DatabaseName: "docs", Compression: "snappy"})
+	require.Equal(t, appendable.ErrUnsupportedCompressionFormat, err)
+
+	_, err = s.CreateDatabase(ctx, &schema.Database{DatabaseName: "docs", Compression: "zlib"})
+	require.NoError(t, err)
+
+	dbs, err := s.DatabaseList(ctx, &empty.Empty{})
+	require.NoError(t, err)
+
+	var found bool
+	for _, db := range dbs.Databases {
+		if db.DatabaseName == "docs" {
+			found = true
+			require.Equal(t, "zlib", db.Compression)
+		} else {
+			require.Equal(t, "none", db.Compression)
+		}
+	}
+	require.True(t, found)
+
+	ur, err := s.UseDatabase(ctx, &schema.Database{DatabaseName: "docs"})
+	require.NoError(t, err)
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", ur.Token))
+
+	_, err = s.Set(ctx, &schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("doc1"), Value: []byte("contents")}}})
+	require.NoError(t, err)
+
+	ve, err := s.VerifiableGet(ctx, &schema.VerifiableGetRequest{KeyRequest: &schema.KeyRequest{Key: []byte("doc1")}})
+	require.NoError(t, err)
+	require.Equal(t, []byte("contents"), ve.Entry.Value)
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package server
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSchedule is a parsed cron expression. Standard five-field expressions (minute, hour, day of month, month
+// and day of week) are supported, with lists, ranges and steps, as well as the @hourly, @daily, @weekly,
+// @monthly and @every <duration> descriptors
+type cronSchedule struct {
+	minutes, hours, doms, months, dows uint64
+
+	// day of month and day of week restrictions are combined with OR when both are set, as in cron
+	domRestricted, dowRestricted bool
+
+	every time.Duration
+}
+
+var cronDescriptors = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+	"@yearly":   "0 0 1 1 *",
+}
+
+func parseCronSchedule(expr string) (*cronSchedule, error) {
+	expr = strings.TrimSpace(expr)
+
+	if strings.HasPrefix(expr, "@every ") {
+		d, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(expr, "@every ")))
+		if err != nil || d < time.Second {
+			return nil, fmt.Errorf("invalid cron expression '%s'", expr)
+		}
+		return &cronSchedule{every: d}, nil
+	}
+
+	if d, ok := cronDescriptors[expr]; ok {
+		expr = d
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron expression '%s', five fields expected", expr)
+	}
+
+	var s cronSchedule
+	var err error
+
+	if s.minutes, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, err
+	}
+	if s.hours, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, err
+	}
+	if s.doms, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, err
+	}
+	if s.months, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, err
+	}
+	if s.dows, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, err
+	}
+
+	// both 0 and 7 stand for sunday
+	if s.dows&(1<<7) != 0 {
+		s.dows |= 1
+	}
+
+	s.domRestricted = fields[2] != "*" && fields[2] != "?"
+	s.dowRestricted = fields[4] != "*" && fields[4] != "?"
+
+	return &s, nil
+}
+
+func parseCronField(field string, min, max int) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid cron field '%s'", field)
+			}
+			step = n
+			part = part[:i]
+		}
+
+		from, to := min, max
+
+		if part != "*" && part != "?" {
+			bounds := strings.SplitN(part, "-", 2)
+
+			n, err := strconv.Atoi(bounds[0])
+			if err != nil {
+				return 0, fmt.Errorf("invalid cron field '%s'", field)
+			}
+			from, to = n, n
+
+			if len(bounds) == 2 {
+				n, err = strconv.Atoi(bounds[1])
+				if err != nil {
+					return 0, fmt.Errorf("invalid cron field '%s'", field)
+				}
+				to = n
+			} else if step > 1 {
+				to = max
+			}
+		}
+
+		if from < min || to > max || from > to {
+			return 0, fmt.Errorf("invalid cron field '%s', values must be within %d-%d", field, min, max)
+		}
+
+		for v := from; v <= to; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+
+	return bits, nil
+}
+
+// next returns the first activation time strictly after t, or the zero time if there is none within five years
+func (s *cronSchedule) next(t time.Time) time.Time {
+	if s.every > 0 {
+		return t.Add(s.every)
+	}
+
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if s.months&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if s.hours&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if s.minutes&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	dom := s.doms&(1<<uint(t.Day())) != 0
+	dow := s.dows&(1<<uint(t.Weekday())) != 0
+
+	if s.domRestricted && s.dowRestricted {
+		return dom || dow
+	}
+
+	return dom && dow
+}
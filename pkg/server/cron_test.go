@@ -0,0 +1,66 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCronSchedule(t *testing.T) {
+	at := func(s string) time.Time {
+		ts, err := time.Parse("2006-01-02 15:04", s)
+		require.NoError(t, err)
+		return ts
+	}
+
+	for _, c := range []struct {
+		expr string
+		from string
+		next string
+	}{
+		{"* * * * *", "2021-06-15 10:20", "2021-06-15 10:21"},
+		{"0 3 * * *", "2021-06-15 10:20", "2021-06-16 03:00"},
+		{"0,30 * * * *", "2021-06-15 10:20", "2021-06-15 10:30"},
+		{"*/15 9-17 * * *", "2021-06-15 17:50", "2021-06-16 09:00"},
+		{"5/20 * * * *", "2021-06-15 10:26", "2021-06-15 10:45"},
+		{"0 0 1 * *", "2021-06-15 10:20", "2021-07-01 00:00"},
+		{"0 0 * * 7", "2021-06-15 10:20", "2021-06-20 00:00"},
+		{"0 0 13 * 5", "2021-06-15 10:20", "2021-06-18 00:00"},
+		{"0 0 29 2 *", "2021-06-15 10:20", "2024-02-29 00:00"},
+		{"@daily", "2021-12-31 23:59", "2022-01-01 00:00"},
+		{"@monthly", "2021-12-15 10:20", "2022-01-01 00:00"},
+	} {
+		s, err := parseCronSchedule(c.expr)
+		require.NoError(t, err, c.expr)
+		require.Equal(t, at(c.next), s.next(at(c.from)), c.expr)
+	}
+
+	s, err := parseCronSchedule("@every 90s")
+	require.NoError(t, err)
+	require.Equal(t, at("2021-06-15 10:21").Add(30*time.Second), s.next(at("2021-06-15 10:20")))
+
+	s, err = parseCronSchedule("0 0 31 2 *")
+	require.NoError(t, err)
+	require.True(t, s.next(at("2021-06-15 10:20")).IsZero())
+
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8", "5-1 * * * *", "*/0 * * * *", "a * * * *", "@every", "@every 1ms"} {
+		_, err := parseCronSchedule(expr)
+		require.Error(t, err, expr)
+	}
+}
@@ -0,0 +1,213 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/auth"
+	"github.com/rs/xid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultEnrollmentTokenTTL is the validity of enrollment tokens when no ttl is requested
+const DefaultEnrollmentTokenTTL = 24 * time.Hour
+
+// ErrInvalidEnrollmentToken is returned when enrolling with an unknown, already used or expired token
+var ErrInvalidEnrollmentToken = status.Error(codes.PermissionDenied, "invalid or expired enrollment token")
+
+// enrollment is a pending device enrollment, it can be redeemed once before it expires
+type enrollment struct {
+	database   string
+	permission uint32
+	createdBy  string
+	expiresAt  time.Time
+}
+
+// enrollments keeps the one-time enrollment tokens minted by admins. Tokens are kept in memory only,
+// pending enrollments are thus lost when the server is restarted
+type enrollments struct {
+	pending map[string]*enrollment
+	sync.Mutex
+}
+
+func newEnrollments() *enrollments {
+	return &enrollments{pending: make(map[string]*enrollment)}
+}
+
+func (e *enrollments) add(en *enrollment) (string, error) {
+	b := make([]byte, 32)
+
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	token := hex.EncodeToString(b)
+
+	e.Lock()
+	defer e.Unlock()
+
+	now := time.Now()
+
+	for t, p := range e.pending {
+		if now.After(p.expiresAt) {
+			delete(e.pending, t)
+		}
+	}
+
+	e.pending[token] = en
+
+	return token, nil
+}
+
+// redeem returns the enrollment associated to token and invalidates it
+func (e *enrollments) redeem(token string) (*enrollment, error) {
+	e.Lock()
+	defer e.Unlock()
+
+	en, ok := e.pending[token]
+	if !ok {
+		return nil, ErrInvalidEnrollmentToken
+	}
+
+	delete(e.pending, token)
+
+	if time.Now().After(en.expiresAt) {
+		return nil, ErrInvalidEnrollmentToken
+	}
+
+	return en, nil
+}
+
+// CreateEnrollmentToken mints a one-time token a device can exchange for credentials scoped to a single database
+func (s *ImmuServer) CreateEnrollmentToken(ctx context.Context, req *schema.EnrollmentTokenRequest) (*schema.EnrollmentToken, error) {
+	if req == nil || req.Ttl < 0 {
+		return nil, ErrIllegalArguments
+	}
+
+	if !s.Options.GetAuth() {
+		return nil, fmt.Errorf("this command is available only with authentication on")
+	}
+
+	_, loggedInuser, err := s.getLoggedInUserdataFromCtx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("please login")
+	}
+
+	if s.dbList.GetId(req.Database) < 0 {
+		return nil, fmt.Errorf("database %s does not exist", req.Database)
+	}
+
+	if req.Permission != auth.PermissionR && req.Permission != auth.PermissionRW {
+		return nil, fmt.Errorf("devices can only be enrolled with read or read-write permission")
+	}
+
+	if !loggedInuser.IsSysAdmin && !loggedInuser.HasPermission(req.Database, auth.PermissionAdmin) {
+		return nil, fmt.Errorf("you do not have permission on this database")
+	}
+
+	ttl := DefaultEnrollmentTokenTTL
+	if req.Ttl > 0 {
+		ttl = time.Duration(req.Ttl) * time.Second
+	}
+
+	en := &enrollment{
+		database:   req.Database,
+		permission: req.Permission,
+		createdBy:  loggedInuser.Username,
+		expiresAt:  time.Now().Add(ttl),
+	}
+
+	token, err := s.enrollments.add(en)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Logger.Infof("enrollment token for database '%s' created by %s", en.database, en.createdBy)
+
+	return &schema.EnrollmentToken{Token: token, ExpiresAt: en.expiresAt.Unix()}, nil
+}
+
+// Enroll exchanges a valid enrollment token for the credentials of a new user scoped to the database the token was minted for.
+// It doesn't require the caller to be logged in
+func (s *ImmuServer) Enroll(ctx context.Context, req *schema.EnrollRequest) (*schema.EnrollResponse, error) {
+	if req == nil || len(req.Token) == 0 {
+		return nil, ErrIllegalArguments
+	}
+
+	if !s.Options.GetAuth() {
+		return nil, fmt.Errorf("this command is available only with authentication on")
+	}
+
+	username := req.Device
+	if len(username) == 0 {
+		username = "device_" + xid.New().String()
+	}
+
+	if !auth.IsValidUsername(username) {
+		return nil, status.Errorf(codes.InvalidArgument, "device name can only contain letters, digits and underscores")
+	}
+
+	if _, err := s.getUser([]byte(username), true); err == nil {
+		return nil, fmt.Errorf("user already exists")
+	}
+
+	en, err := s.enrollments.redeem(req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	password, err := generateDevicePassword()
+	if err != nil {
+		return nil, err
+	}
+
+	_, _, err = s.insertNewUser([]byte(username), password, en.permission, en.database, true, en.createdBy)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Logger.Infof("device %s enrolled on database '%s'", username, en.database)
+
+	return &schema.EnrollResponse{
+		User:       []byte(username),
+		Password:   password,
+		Database:   en.database,
+		Permission: en.permission,
+	}, nil
+}
+
+// generateDevicePassword returns a random password meeting the strength requirements
+func generateDevicePassword() ([]byte, error) {
+	b := make([]byte, 21)
+
+	_, err := rand.Read(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(base64.RawURLEncoding.EncodeToString(b) + "Aa1-"), nil
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/auth"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestServerEnrollment(t *testing.T) {
+	serverOptions := DefaultOptions().
+		WithMetricsServer(false).
+		WithAdminPassword(auth.SysAdminPassword).
+		WithListener(bufconn.Listen(1024 * 1024))
+	s := DefaultServer().WithOptions(serverOptions).(*ImmuServer)
+	defer os.RemoveAll(s.Options.Dir)
+
+	require.NoError(t, s.Initialize())
+
+	lr, err := s.Login(context.Background(), &schema.LoginRequest{
+		User:     []byte(auth.SysAdminUsername),
+		Password: []byte(auth.SysAdminPassword),
+	})
+	require.NoError(t, err)
+
+	md := metadata.Pairs("authorization", lr.Token)
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+
+	_, err = s.CreateEnrollmentToken(ctx, nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = s.CreateEnrollmentToken(ctx, &schema.EnrollmentTokenRequest{Database: "unknown", Permission: auth.PermissionRW})
+	require.Error(t, err)
+
+	_, err = s.CreateEnrollmentToken(ctx, &schema.EnrollmentTokenRequest{Database: DefaultdbName, Permission: auth.PermissionAdmin})
+	require.Error(t, err)
+
+	_, err = s.CreateEnrollmentToken(context.Background(), &schema.EnrollmentTokenRequest{Database: DefaultdbName, Permission: auth.PermissionRW})
+	require.Error(t, err)
+
+	et, err := s.CreateEnrollmentToken(ctx, &schema.EnrollmentTokenRequest{Database: DefaultdbName, Permission: auth.PermissionRW})
+	require.NoError(t, err)
+	require.NotEmpty(t, et.Token)
+	require.Greater(t, et.ExpiresAt, time.Now().Unix())
+
+	_, err = s.Enroll(context.Background(), nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = s.Enroll(context.Background(), &schema.EnrollRequest{Token: "unknown"})
+	require.Equal(t, ErrInvalidEnrollmentToken, err)
+
+	_, err = s.Enroll(context.Background(), &schema.EnrollRequest{Token: et.Token, Device: "sensor-1"})
+	require.Error(t, err)
+
+	_, err = s.Enroll(context.Background(), &schema.EnrollRequest{Token: et.Token, Device: auth.SysAdminUsername})
+	require.Error(t, err)
+
+	er, err := s.Enroll(context.Background(), &schema.EnrollRequest{Token: et.Token, Device: "sensor_1"})
+	require.NoError(t, err)
+	require.Equal(t, []byte("sensor_1"), er.User)
+	require.Equal(t, DefaultdbName, er.Database)
+	require.Equal(t, uint32(auth.PermissionRW), er.Permission)
+	require.NoError(t, auth.IsStrongPassword(string(er.Password)))
+
+	_, err = s.Enroll(context.Background(), &schema.EnrollRequest{Token: et.Token, Device: "sensor_2"})
+	require.Equal(t, ErrInvalidEnrollmentToken, err)
+
+	_, err = s.Login(context.Background(), &schema.LoginRequest{User: er.User, Password: er.Password})
+	require.NoError(t, err)
+
+	et, err = s.CreateEnrollmentToken(ctx, &schema.EnrollmentTokenRequest{Database: DefaultdbName, Permission: auth.PermissionR, Ttl: 1})
+	require.NoError(t, err)
+
+	s.enrollments.pending[et.Token].expiresAt = time.Now().Add(-time.Second)
+
+	_, err = s.Enroll(context.Background(), &schema.EnrollRequest{Token: et.Token})
+	require.Equal(t, ErrInvalidEnrollmentToken, err)
+
+	et, err = s.CreateEnrollmentToken(ctx, &schema.EnrollmentTokenRequest{Database: DefaultdbName, Permission: auth.PermissionR})
+	require.NoError(t, err)
+
+	er, err = s.Enroll(context.Background(), &schema.EnrollRequest{Token: et.Token})
+	require.NoError(t, err)
+	require.NotEmpty(t, er.User)
+	require.Equal(t, uint32(auth.PermissionR), er.Permission)
+}
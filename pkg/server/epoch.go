@@ -0,0 +1,74 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package server
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/auth"
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc/metadata"
+)
+
+// EpochMetadataKey is the metadata clients use to send the fencing epoch of the database along with writes
+const EpochMetadataKey = "epoch"
+
+// AdvanceEpoch increments the fencing epoch of the selected database. It's meant to be called once a replica
+// was promoted to primary, so writes sent along with the former epoch are rejected by both servers
+func (s *ImmuServer) AdvanceEpoch(ctx context.Context, _ *empty.Empty) (*schema.Epoch, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "AdvanceEpoch")
+	if err != nil {
+		return nil, err
+	}
+
+	db := s.dbList.GetByIndex(ind)
+
+	epoch, err := db.AdvanceEpoch()
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordEvent(ctx, EventCategoryDatabase, db.GetOptions().GetDbName(), "epoch advanced", map[string]string{"epoch": strconv.FormatUint(epoch.Epoch, 10)})
+
+	return epoch, nil
+}
+
+// checkEpoch rejects any method which is not a read when sent along with an epoch other than the current one
+// of the database. Calls not carrying any epoch are not fenced
+func (s *ImmuServer) checkEpoch(ctx context.Context, ind int64, methodname string) error {
+	if auth.HasPermissionForMethod(auth.PermissionR, methodname) {
+		return nil
+	}
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	values := md.Get(EpochMetadataKey)
+	if len(values) == 0 {
+		return nil
+	}
+
+	epoch, err := strconv.ParseUint(values[0], 10, 64)
+	if err != nil {
+		return ErrIllegalArguments
+	}
+
+	return s.dbList.GetByIndex(ind).CheckEpoch(epoch)
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package server
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/auth"
+	"github.com/codenotary/immudb/pkg/database"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestServerEpochFencing(t *testing.T) {
+	serverOptions := DefaultOptions().
+		WithMetricsServer(false).
+		WithAdminPassword(auth.SysAdminPassword).
+		WithListener(bufconn.Listen(1024 * 1024))
+	s := DefaultServer().WithOptions(serverOptions).(*ImmuServer)
+	defer os.RemoveAll(s.Options.Dir)
+
+	require.NoError(t, s.Initialize())
+
+	lr, err := s.Login(context.Background(), &schema.LoginRequest{
+		User:     []byte(auth.SysAdminUsername),
+		Password: []byte(auth.SysAdminPassword),
+	})
+	require.NoError(t, err)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", lr.Token))
+
+	ur, err := s.UseDatabase(ctx, &schema.Database{DatabaseName: DefaultdbName})
+	require.NoError(t, err)
+	require.Zero(t, ur.Epoch)
+
+	withEpoch := func(epoch string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", ur.Token, EpochMetadataKey, epoch))
+	}
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", ur.Token))
+
+	epoch, err := s.AdvanceEpoch(ctx, &empty.Empty{})
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), epoch.Epoch)
+
+	ur, err = s.UseDatabase(metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", lr.Token)), &schema.Database{DatabaseName: DefaultdbName})
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), ur.Epoch)
+
+	kvs := &schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("k1"), Value: []byte("v1")}}}
+
+	_, err = s.Set(ctx, kvs)
+	require.NoError(t, err)
+
+	_, err = s.Set(withEpoch("1"), kvs)
+	require.NoError(t, err)
+
+	_, err = s.Set(withEpoch("0"), kvs)
+	require.Equal(t, database.ErrStaleEpoch, err)
+
+	_, err = s.Set(withEpoch("2"), kvs)
+	require.Equal(t, database.ErrStalePrimary, err)
+
+	_, err = s.Set(withEpoch("invalid"), kvs)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	// reads are not fenced
+	_, err = s.Get(withEpoch("2"), &schema.KeyRequest{Key: []byte("k1")})
+	require.NoError(t, err)
+}
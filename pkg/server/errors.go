@@ -11,6 +11,10 @@ var (
 	ErrIllegalArguments   = status.Error(codes.InvalidArgument, database.ErrIllegalArguments.Error())
 	ErrIllegalState       = status.Error(codes.InvalidArgument, database.ErrIllegalState.Error())
 	ErrEmptyAdminPassword = status.Error(codes.InvalidArgument, "Admin password cannot be empty")
+
+	ErrUnsupportedBindingsLanguage = status.Error(codes.InvalidArgument, "unsupported bindings language, use proto, typescript or python")
+
+	ErrInvalidStreamBandwidthScope = status.Error(codes.InvalidArgument, "invalid stream bandwidth scope, use replication or all")
 )
 
 func mapServerError(err error) error {
@@ -0,0 +1,151 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/auth"
+	"github.com/codenotary/immudb/pkg/database"
+)
+
+// EventsdbName is the built-in database recording the server history. It can be selected by sysadmins to be queried
+// like any other database but it can't be written through the API
+const EventsdbName = "eventsdb"
+
+// Each event is written as a new version of the key made of its category followed by its subject, e.g. "user:john".
+// The history of a key is the history of its subject and scanning a category prefix returns the latest event of each
+// subject within it
+const (
+	EventCategoryServer   = "server"
+	EventCategoryConfig   = "config"
+	EventCategoryDatabase = "database"
+	EventCategoryUser     = "user"
+	EventCategoryJob      = "job"
+	EventCategoryAlert    = "alert"
+)
+
+// ServerEvent is the JSON value of the entries of the events database
+type ServerEvent struct {
+	Event   string            `json:"event"`
+	By      string            `json:"by,omitempty"`
+	Time    int64             `json:"time"`
+	Details map[string]string `json:"details,omitempty"`
+}
+
+func eventKey(category, subject string) []byte {
+	return []byte(category + ":" + subject)
+}
+
+func (s *ImmuServer) loadEventsDatabase(dataDir string) error {
+	if !s.Options.GetAuth() {
+		return nil
+	}
+
+	op := database.DefaultOption().
+		WithDbName(EventsdbName).
+		WithDbRootPath(dataDir).
+		WithStoreOptions(DefaultStoreOptions().WithSynced(true))
+
+	var db database.DB
+	var err error
+
+	_, err = s.OS.Stat(s.OS.Join(dataDir, EventsdbName))
+	if s.OS.IsNotExist(err) {
+		db, err = database.NewDb(op, s.sysDb, s.Logger)
+	} else {
+		db, err = database.OpenDb(op, s.sysDb, s.Logger)
+	}
+	if err != nil {
+		return err
+	}
+
+	s.eventsDb = db
+	s.dbList.Append(db)
+
+	return nil
+}
+
+// recordEvent appends an event to the events database. The user performing the change is taken from ctx when available
+func (s *ImmuServer) recordEvent(ctx context.Context, category, subject, event string, details map[string]string) {
+	if s.eventsDb == nil {
+		return
+	}
+
+	e := &ServerEvent{
+		Event:   event,
+		Time:    time.Now().Unix(),
+		Details: details,
+	}
+
+	if user, err := auth.GetLoggedInUser(ctx); err == nil {
+		e.By = user.Username
+	}
+
+	value, err := json.Marshal(e)
+	if err != nil {
+		s.Logger.Errorf("error recording event '%s' of %s:%s: %v", event, category, subject, err)
+		return
+	}
+
+	_, err = s.eventsDb.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: eventKey(category, subject), Value: value}}})
+	if err != nil {
+		s.Logger.Errorf("error recording event '%s' of %s:%s: %v", event, category, subject, err)
+	}
+}
+
+// recordConfig records the server configuration whenever it differs from the last recorded one
+func (s *ImmuServer) recordConfig() {
+	if s.eventsDb == nil {
+		return
+	}
+
+	config := s.Options.String()
+
+	entry, err := s.eventsDb.Get(&schema.KeyRequest{Key: eventKey(EventCategoryConfig, EventCategoryServer)})
+	if err != nil && err != store.ErrKeyNotFound {
+		s.Logger.Errorf("error reading last recorded configuration: %v", err)
+		return
+	}
+
+	if err == nil {
+		var last ServerEvent
+		if json.Unmarshal(entry.Value, &last) == nil && last.Details["options"] == config {
+			return
+		}
+	}
+
+	s.recordEvent(context.Background(), EventCategoryConfig, EventCategoryServer, "changed", map[string]string{"options": config})
+}
+
+// checkEventsDbMethod rejects any method which is not a read on the events database
+func (s *ImmuServer) checkEventsDbMethod(ind int64, methodname string) error {
+	if s.eventsDb == nil || s.dbList.GetByIndex(ind) != s.eventsDb {
+		return nil
+	}
+
+	if !auth.HasPermissionForMethod(auth.PermissionR, methodname) {
+		return fmt.Errorf("database %s is read-only", EventsdbName)
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/auth"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestServerEvents(t *testing.T) {
+	serverOptions := DefaultOptions().
+		WithMetricsServer(false).
+		WithAdminPassword(auth.SysAdminPassword).
+		WithListener(bufconn.Listen(1024 * 1024))
+	s := DefaultServer().WithOptions(serverOptions).(*ImmuServer)
+	defer os.RemoveAll(s.Options.Dir)
+
+	require.NoError(t, s.Initialize())
+
+	lr, err := s.Login(context.Background(), &schema.LoginRequest{
+		User:     []byte(auth.SysAdminUsername),
+		Password: []byte(auth.SysAdminPassword),
+	})
+	require.NoError(t, err)
+
+	md := metadata.Pairs("authorization", lr.Token)
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+
+	_, err = s.CreateDatabase(ctx, &schema.Database{DatabaseName: EventsdbName})
+	require.Error(t, err)
+
+	_, err = s.CreateDatabase(ctx, &schema.Database{DatabaseName: "db1"})
+	require.NoError(t, err)
+
+	_, err = s.CreateUser(ctx, &schema.CreateUserRequest{
+		User:       testUsername,
+		Password:   testPassword,
+		Database:   "db1",
+		Permission: auth.PermissionRW,
+	})
+	require.NoError(t, err)
+
+	_, err = s.CreateUser(ctx, &schema.CreateUserRequest{
+		User:       []byte("events"),
+		Password:   testPassword,
+		Database:   EventsdbName,
+		Permission: auth.PermissionR,
+	})
+	require.Error(t, err)
+
+	_, err = s.ChangePermission(ctx, &schema.ChangePermissionRequest{
+		Action:     schema.PermissionAction_GRANT,
+		Username:   string(testUsername),
+		Database:   EventsdbName,
+		Permission: auth.PermissionR,
+	})
+	require.Error(t, err)
+
+	_, err = s.SetActiveUser(ctx, &schema.SetActiveUserRequest{Username: string(testUsername), Active: false})
+	require.NoError(t, err)
+
+	dbs, err := s.DatabaseList(ctx, &empty.Empty{})
+	require.NoError(t, err)
+	for _, db := range dbs.Databases {
+		require.NotEqual(t, EventsdbName, db.DatabaseName)
+	}
+
+	ur, err := s.UseDatabase(ctx, &schema.Database{DatabaseName: EventsdbName})
+	require.NoError(t, err)
+
+	md = metadata.Pairs("authorization", ur.Token)
+	ctx = metadata.NewIncomingContext(context.Background(), md)
+
+	_, err = s.Set(ctx, &schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("key"), Value: []byte("value")}}})
+	require.Error(t, err)
+
+	_, err = s.CleanIndex(ctx, &empty.Empty{})
+	require.Error(t, err)
+
+	entry, err := s.Get(ctx, &schema.KeyRequest{Key: []byte("database:db1")})
+	require.NoError(t, err)
+
+	var e ServerEvent
+	err = json.Unmarshal(entry.Value, &e)
+	require.NoError(t, err)
+	require.Equal(t, "created", e.Event)
+	require.Equal(t, auth.SysAdminUsername, e.By)
+	require.NotZero(t, e.Time)
+
+	history, err := s.History(ctx, &schema.HistoryRequest{Key: []byte("user:" + string(testUsername))})
+	require.NoError(t, err)
+	require.Len(t, history.Entries, 2)
+
+	err = json.Unmarshal(history.Entries[1].Value, &e)
+	require.NoError(t, err)
+	require.Equal(t, "deactivated", e.Event)
+
+	_, err = s.VerifiableGet(ctx, &schema.VerifiableGetRequest{KeyRequest: &schema.KeyRequest{Key: []byte("config:server")}})
+	require.NoError(t, err)
+
+	err = s.CloseDatabases()
+	require.NoError(t, err)
+
+	// the configuration is only recorded again when it changes
+	s = DefaultServer().WithOptions(serverOptions).(*ImmuServer)
+	require.NoError(t, s.Initialize())
+
+	history, err = s.eventsDb.History(&schema.HistoryRequest{Key: []byte("config:server")})
+	require.NoError(t, err)
+	require.Len(t, history.Entries, 1)
+
+	err = s.CloseDatabases()
+	require.NoError(t, err)
+
+	s = DefaultServer().WithOptions(serverOptions.WithPrefixStatsDepth(2)).(*ImmuServer)
+	require.NoError(t, s.Initialize())
+	defer s.CloseDatabases()
+
+	history, err = s.eventsDb.History(&schema.HistoryRequest{Key: []byte("config:server")})
+	require.NoError(t, err)
+	require.Len(t, history.Entries, 2)
+}
@@ -0,0 +1,42 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+
+	"github.com/codenotary/immudb/pkg/database"
+)
+
+// idempotencyKey scopes the idempotency key provided by the client to the authenticated user and the database
+// being written, so a user can not replay, nor learn about, the writes of another one by guessing their keys
+func (s *ImmuServer) idempotencyKey(ctx context.Context, dbIndex int64, key string) (string, error) {
+	if key == "" {
+		return "", nil
+	}
+
+	var username string
+
+	if s.Options.GetAuth() {
+		_, user, err := s.getLoggedInUserdataFromCtx(ctx)
+		if err == nil {
+			username = user.Username
+		}
+	}
+
+	return database.ScopedIdempotencyKey(username, s.dbList.GetByIndex(dbIndex).GetName(), key)
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// IngestionPathPrefix is the path of the HTTP ingestion endpoint, key/value pairs are posted to <prefix>{db}/kv
+// and Elasticsearch bulk requests to <prefix>{db}/_bulk
+const IngestionPathPrefix = "/api/v1/db/"
+
+// maxIngestionBodySize limits the size of the requests accepted by the ingestion endpoint
+const maxIngestionBodySize = 32 << 20
+
+// ingestionKV is a key/value pair posted to the ingestion endpoint. The value is stored as is when it's a JSON string,
+// any other JSON value (e.g. a webhook payload) is stored as its JSON encoding
+type ingestionKV struct {
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+// ingestionRequest accepts either a single key/value pair or a batch of them under "kvs"
+type ingestionRequest struct {
+	ingestionKV
+	KVs []ingestionKV `json:"kvs"`
+}
+
+type ingestionResponse struct {
+	ID uint64 `json:"id"`
+}
+
+type ingestionError struct {
+	Error string `json:"error"`
+}
+
+// ingestionHandler serves the HTTP ingestion endpoint, letting webhook sources and lightweight producers write
+// key/value pairs without a gRPC client. Requests are authenticated either with the user credentials (basic
+// authentication) or with a token previously obtained by logging in, as checked by ingestionAuth, and are written
+// in a single transaction whose id is returned. An Idempotency-Key header makes retries return the original
+// transaction. Elasticsearch bulk requests are served by bulkHandler under the same prefix
+func ingestionHandler(s schema.ImmuServiceServer) http.Handler {
+	ia := newIngestionAuth(s)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.SplitN(strings.TrimPrefix(r.URL.Path, IngestionPathPrefix), "/", 2)
+
+		db, endpoint := path[0], ""
+		if len(path) > 1 {
+			endpoint = path[1]
+		}
+
+		var handler func(http.ResponseWriter, *http.Request, *ingestionAuth, string)
+		method := http.MethodPost
+
+		switch {
+		case db == "":
+		case endpoint == "kv":
+			handler = ingestKVs
+		case endpoint == "_bulk":
+			handler = bulkHandler("")
+		case strings.HasSuffix(endpoint, "/_bulk") && strings.Count(endpoint, "/") == 1:
+			handler = bulkHandler(strings.TrimSuffix(endpoint, "/_bulk"))
+		case endpoint == "":
+			handler, method = bulkInfo, http.MethodGet
+		}
+
+		if handler == nil {
+			writeIngestionError(w, http.StatusNotFound, "unknown path, expected "+IngestionPathPrefix+"{db}/kv")
+			return
+		}
+
+		if r.Method != method && !(method == http.MethodGet && r.Method == http.MethodHead) {
+			w.Header().Set("Allow", method)
+			writeIngestionError(w, http.StatusMethodNotAllowed, "only "+method+" is supported")
+			return
+		}
+
+		handler(w, r, ia, db)
+	})
+}
+
+func ingestKVs(w http.ResponseWriter, r *http.Request, ia *ingestionAuth, db string) {
+	var req ingestionRequest
+
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxIngestionBodySize))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(&req); err != nil {
+		writeIngestionError(w, http.StatusBadRequest, fmt.Sprintf("invalid request: %v", err))
+		return
+	}
+
+	kvs, err := req.toKVs()
+	if err != nil {
+		writeIngestionError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, err := ia.context(r, db, "Set")
+	if err != nil {
+		writeIngestionStatus(w, err)
+		return
+	}
+
+	md, err := ia.s.Set(ctx, &schema.SetRequest{
+		KVs:            kvs,
+		IdempotencyKey: r.Header.Get("Idempotency-Key"),
+	})
+	if err != nil {
+		writeIngestionStatus(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(&ingestionResponse{ID: md.Id})
+}
+
+func (req *ingestionRequest) toKVs() ([]*schema.KeyValue, error) {
+	pairs := req.KVs
+
+	if req.Key != "" || req.Value != nil {
+		if len(pairs) > 0 {
+			return nil, fmt.Errorf("either a single key/value pair or kvs must be provided")
+		}
+		pairs = []ingestionKV{req.ingestionKV}
+	}
+
+	if len(pairs) == 0 {
+		return nil, fmt.Errorf("no key/value pairs provided")
+	}
+
+	kvs := make([]*schema.KeyValue, len(pairs))
+
+	for i, p := range pairs {
+		if p.Key == "" {
+			return nil, fmt.Errorf("empty key at position %d", i)
+		}
+
+		value := []byte(p.Value)
+
+		if bytes.HasPrefix(value, []byte(`"`)) {
+			var s string
+			if err := json.Unmarshal(value, &s); err != nil {
+				return nil, fmt.Errorf("invalid value of key %s: %v", p.Key, err)
+			}
+			value = []byte(s)
+		}
+
+		kvs[i] = &schema.KeyValue{Key: []byte(p.Key), Value: value}
+	}
+
+	return kvs, nil
+}
+
+func writeIngestionStatus(w http.ResponseWriter, err error) {
+	st, ok := status.FromError(mapServerError(err))
+	if !ok {
+		writeIngestionError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if st.Code() == codes.Unauthenticated {
+		w.Header().Set("WWW-Authenticate", `Basic realm="immudb"`)
+	}
+
+	writeIngestionError(w, runtime.HTTPStatusFromCode(st.Code()), st.Message())
+}
+
+func writeIngestionError(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(&ingestionError{Error: msg})
+}
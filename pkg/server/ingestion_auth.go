@@ -0,0 +1,204 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// maxIngestionLogins bounds the number of basic authentication logins remembered by the ingestion endpoints
+const maxIngestionLogins = 1000
+
+// ingestionLoginMargin is how long before its expiration a remembered login is renewed, so it doesn't expire in-flight
+const ingestionLoginMargin = time.Minute
+
+// ingestionLogin is the token obtained by logging in with basic authentication credentials
+type ingestionLogin struct {
+	token     string
+	expiresAt time.Time
+}
+
+// ingestionAuth authenticates the requests of the ingestion endpoints. Logging in verifies the password hash, which
+// is deliberately slow, thus the token obtained with basic authentication credentials is reused by the following
+// requests sending the same credentials until it expires or is invalidated, e.g. by a password change
+type ingestionAuth struct {
+	s schema.ImmuServiceServer
+
+	// credentials are remembered by their keyed digest, so they are never kept in memory as they are
+	secret []byte
+	logins map[[sha256.Size]byte]*ingestionLogin
+	mutex  sync.Mutex
+}
+
+func newIngestionAuth(s schema.ImmuServiceServer) *ingestionAuth {
+	secret := make([]byte, sha256.Size)
+	rand.Read(secret)
+
+	return &ingestionAuth{
+		s:      s,
+		secret: secret,
+		logins: make(map[[sha256.Size]byte]*ingestionLogin),
+	}
+}
+
+// context returns the context of a call to the given method of the database on behalf of the authenticated user.
+// Authentication errors are returned as Unauthenticated and authorization ones as PermissionDenied, so they are
+// answered with 401 and 403, whatever the error returned by the server
+func (a *ingestionAuth) context(r *http.Request, db, method string) (context.Context, error) {
+	if user, password, ok := r.BasicAuth(); ok {
+		cred := a.credential(user, password)
+
+		if token, ok := a.remembered(cred); ok {
+			ctx, err := a.useDatabase(r.Context(), token, db, method)
+			if status.Code(err) != codes.Unauthenticated {
+				return ctx, err
+			}
+
+			// the token was invalidated, e.g. by a password change, credentials are checked again
+			a.forget(cred)
+		}
+
+		token, err := a.login(r.Context(), cred, user, password)
+		if err != nil {
+			return nil, err
+		}
+
+		return a.useDatabase(r.Context(), token, db, method)
+	}
+
+	if bearer := r.Header.Get("Authorization"); strings.HasPrefix(bearer, "Bearer ") {
+		return a.useDatabase(r.Context(), strings.TrimPrefix(bearer, "Bearer "), db, method)
+	}
+
+	return nil, status.Error(codes.Unauthenticated, "basic or bearer authentication required")
+}
+
+func (a *ingestionAuth) login(ctx context.Context, cred [sha256.Size]byte, user, password string) (string, error) {
+	lr, err := a.s.Login(ctx, &schema.LoginRequest{User: []byte(user), Password: []byte(password)})
+	if err != nil {
+		if st, ok := status.FromError(err); ok && st.Code() == codes.PermissionDenied {
+			// wrong credentials
+			return "", status.Error(codes.Unauthenticated, st.Message())
+		}
+		// e.g. inactive users or authentication being disabled
+		return "", authorizationError(err)
+	}
+
+	jsUser, err := auth.GetLoggedInUser(metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", lr.Token)))
+	if err == nil {
+		a.remember(cred, &ingestionLogin{token: lr.Token, expiresAt: jsUser.Expiration.Add(-ingestionLoginMargin)})
+	}
+
+	return lr.Token, nil
+}
+
+// useDatabase selects the database with the given token and checks the user is allowed to call the method on it
+func (a *ingestionAuth) useDatabase(ctx context.Context, token, db, method string) (context.Context, error) {
+	ur, err := a.s.UseDatabase(metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", token)), &schema.Database{DatabaseName: db})
+	if err != nil {
+		return nil, authorizationError(err)
+	}
+
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", ur.Token))
+
+	if is, ok := a.s.(*ImmuServer); ok {
+		if _, err := is.getDbIndexFromCtx(ctx, method); err != nil {
+			return nil, authorizationError(err)
+		}
+	}
+
+	return ctx, nil
+}
+
+// authorizationError returns the error as PermissionDenied unless it already has a status, as the server returns
+// some authorization errors (e.g. missing permissions) without one
+func authorizationError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return status.Error(codes.PermissionDenied, err.Error())
+}
+
+func (a *ingestionAuth) credential(user, password string) [sha256.Size]byte {
+	mac := hmac.New(sha256.New, a.secret)
+	mac.Write([]byte(user))
+	mac.Write([]byte{0})
+	mac.Write([]byte(password))
+
+	var cred [sha256.Size]byte
+	copy(cred[:], mac.Sum(nil))
+
+	return cred
+}
+
+func (a *ingestionAuth) remembered(cred [sha256.Size]byte) (string, bool) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	l, ok := a.logins[cred]
+	if !ok {
+		return "", false
+	}
+
+	if !time.Now().Before(l.expiresAt) {
+		delete(a.logins, cred)
+		return "", false
+	}
+
+	return l.token, true
+}
+
+func (a *ingestionAuth) remember(cred [sha256.Size]byte, l *ingestionLogin) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if len(a.logins) >= maxIngestionLogins {
+		now := time.Now()
+
+		for c, rl := range a.logins {
+			if !now.Before(rl.expiresAt) {
+				delete(a.logins, c)
+			}
+		}
+	}
+
+	// once full of unexpired logins, new ones are not remembered
+	if len(a.logins) >= maxIngestionLogins {
+		return
+	}
+
+	a.logins[cred] = l
+}
+
+func (a *ingestionAuth) forget(cred [sha256.Size]byte) {
+	a.mutex.Lock()
+	delete(a.logins, cred)
+	a.mutex.Unlock()
+}
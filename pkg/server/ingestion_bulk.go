@@ -0,0 +1,255 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/auth"
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"google.golang.org/grpc/status"
+)
+
+// BulkCompatibleVersion is the Elasticsearch version reported to bulk clients, which use it to choose the request format
+const BulkCompatibleVersion = "7.10.2"
+
+// bulkTxSize is the maximum number of documents written in a single transaction by a bulk request
+const bulkTxSize = 256
+
+// bulkAction is the action line preceding a document in a bulk request
+type bulkAction struct {
+	Index string `json:"_index"`
+	ID    string `json:"_id"`
+}
+
+type bulkItemResult struct {
+	Index  string         `json:"_index"`
+	ID     string         `json:"_id,omitempty"`
+	Status int            `json:"status"`
+	Result string         `json:"result,omitempty"`
+	TxID   uint64         `json:"_seq_no,omitempty"`
+	Error  *bulkItemError `json:"error,omitempty"`
+}
+
+type bulkItemError struct {
+	Type   string `json:"type"`
+	Reason string `json:"reason"`
+}
+
+type bulkResponse struct {
+	Took   int64                        `json:"took"`
+	Errors bool                         `json:"errors"`
+	Items  []map[string]*bulkItemResult `json:"items"`
+}
+
+type bulkDocument struct {
+	kv     *schema.KeyValue
+	result *bulkItemResult
+}
+
+// bulkHandler accepts a subset of the Elasticsearch bulk API so log shippers such as Fluent Bit or Logstash can
+// forward documents without a custom plugin. index and create actions store each document as is under <index>:<_id>,
+// generating the id when missing; any other action is reported as failed. Documents are written in transactions
+// of at most bulkTxSize entries, whose ids are reported as sequence numbers
+func bulkHandler(defaultIndex string) func(http.ResponseWriter, *http.Request, *ingestionAuth, string) {
+	return func(w http.ResponseWriter, r *http.Request, ia *ingestionAuth, db string) {
+		start := time.Now()
+
+		items, docs, err := parseBulkRequest(http.MaxBytesReader(w, r.Body, maxIngestionBodySize), defaultIndex)
+		if err != nil {
+			writeIngestionError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		ctx, err := ia.context(r, db, "Set")
+		if err != nil {
+			writeIngestionStatus(w, err)
+			return
+		}
+
+		for len(docs) > 0 {
+			batch := nextBulkBatch(docs)
+			docs = docs[len(batch):]
+
+			kvs := make([]*schema.KeyValue, len(batch))
+			for i, doc := range batch {
+				kvs[i] = doc.kv
+			}
+
+			md, err := ia.s.Set(ctx, &schema.SetRequest{KVs: kvs})
+
+			for _, doc := range batch {
+				if err != nil {
+					doc.result.failed(err)
+					continue
+				}
+				doc.result.Status = http.StatusCreated
+				doc.result.Result = "created"
+				doc.result.TxID = md.Id
+			}
+		}
+
+		resp := &bulkResponse{Items: items}
+		for _, item := range items {
+			for _, res := range item {
+				resp.Errors = resp.Errors || res.Error != nil
+			}
+		}
+		resp.Took = time.Since(start).Milliseconds()
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+// parseBulkRequest reads the newline delimited action and document lines of a bulk request, returning the
+// response items in request order and the documents to be written
+func parseBulkRequest(r io.Reader, defaultIndex string) ([]map[string]*bulkItemResult, []*bulkDocument, error) {
+	var items []map[string]*bulkItemResult
+	var docs []*bulkDocument
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxIngestionBodySize)
+
+	nextLine := func() ([]byte, bool) {
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) > 0 {
+				return line, true
+			}
+		}
+		return nil, false
+	}
+
+	for {
+		line, ok := nextLine()
+		if !ok {
+			break
+		}
+
+		var action map[string]bulkAction
+		if err := json.Unmarshal(line, &action); err != nil || len(action) != 1 {
+			return nil, nil, fmt.Errorf("invalid action at line %d", len(items)+len(docs)+1)
+		}
+
+		for op, meta := range action {
+			if meta.Index == "" {
+				meta.Index = defaultIndex
+			}
+
+			res := &bulkItemResult{Index: meta.Index, ID: meta.ID}
+			items = append(items, map[string]*bulkItemResult{op: res})
+
+			switch op {
+			case "index", "create":
+			case "update":
+				// update actions are followed by a partial document, which is skipped
+				nextLine()
+				fallthrough
+			default:
+				res.fail(http.StatusBadRequest, "illegal_argument_exception", fmt.Sprintf("unsupported action %s", op))
+				continue
+			}
+
+			doc, ok := nextLine()
+			if !ok {
+				return nil, nil, fmt.Errorf("missing document of %s action", op)
+			}
+			if !json.Valid(doc) {
+				res.fail(http.StatusBadRequest, "mapper_parsing_exception", "invalid document")
+				continue
+			}
+			if meta.Index == "" {
+				res.fail(http.StatusBadRequest, "action_request_validation_exception", "index is missing")
+				continue
+			}
+
+			if res.ID == "" {
+				res.ID = auth.NewStringUUID()
+			}
+
+			docs = append(docs, &bulkDocument{
+				kv:     &schema.KeyValue{Key: []byte(res.Index + ":" + res.ID), Value: append([]byte{}, doc...)},
+				result: res,
+			})
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("invalid request: %v", err)
+	}
+
+	return items, docs, nil
+}
+
+// nextBulkBatch returns the leading documents that can be written in a single transaction, which doesn't
+// accept the same key twice
+func nextBulkBatch(docs []*bulkDocument) []*bulkDocument {
+	keys := make(map[string]struct{})
+
+	for i, doc := range docs {
+		if _, dup := keys[string(doc.kv.Key)]; dup || i == bulkTxSize {
+			return docs[:i]
+		}
+		keys[string(doc.kv.Key)] = struct{}{}
+	}
+
+	return docs
+}
+
+func (res *bulkItemResult) fail(code int, errType, reason string) {
+	res.Status = code
+	res.Error = &bulkItemError{Type: errType, Reason: reason}
+}
+
+func (res *bulkItemResult) failed(err error) {
+	st, ok := status.FromError(mapServerError(err))
+	if !ok {
+		res.fail(http.StatusInternalServerError, "exception", err.Error())
+		return
+	}
+	res.fail(runtime.HTTPStatusFromCode(st.Code()), "exception", st.Message())
+}
+
+type bulkInfoResponse struct {
+	Name    string `json:"name"`
+	Version struct {
+		Number string `json:"number"`
+	} `json:"version"`
+	Tagline string `json:"tagline"`
+}
+
+// bulkInfo answers the version check bulk clients perform before sending documents
+func bulkInfo(w http.ResponseWriter, r *http.Request, ia *ingestionAuth, db string) {
+	if _, err := ia.context(r, db, "CurrentState"); err != nil {
+		writeIngestionStatus(w, err)
+		return
+	}
+
+	resp := &bulkInfoResponse{Name: "immudb", Tagline: "immudb bulk ingestion"}
+	resp.Version.Number = BulkCompatibleVersion
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/auth"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestServerBulkIngestion(t *testing.T) {
+	serverOptions := DefaultOptions().
+		WithMetricsServer(false).
+		WithAdminPassword(auth.SysAdminPassword).
+		WithListener(bufconn.Listen(1024 * 1024))
+	s := DefaultServer().WithOptions(serverOptions).(*ImmuServer)
+	defer os.RemoveAll(s.Options.Dir)
+
+	require.NoError(t, s.Initialize())
+
+	h := ingestionHandler(s)
+
+	bulk := func(method, path, body string) (*httptest.ResponseRecorder, *bulkResponse) {
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		r.SetBasicAuth(auth.SysAdminUsername, auth.SysAdminPassword)
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		if w.Code != http.StatusOK || method != http.MethodPost {
+			return w, nil
+		}
+
+		var res bulkResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+
+		return w, &res
+	}
+
+	w, _ := bulk(http.MethodGet, "/api/v1/db/defaultdb/", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), BulkCompatibleVersion)
+
+	_, res := bulk(http.MethodPost, "/api/v1/db/defaultdb/_bulk", `{"index":{"_index":"logs","_id":"1"}}
+{"log":"first"}
+{"create":{"_index":"logs"}}
+{"log":"second"}
+
+{"index":{"_index":"logs","_id":"1"}}
+{"log":"first, again"}
+{"delete":{"_index":"logs","_id":"1"}}
+{"update":{"_index":"logs","_id":"1"}}
+{"doc":{"log":"updated"}}
+{"index":{}}
+{"log":"no index"}
+`)
+	require.True(t, res.Errors)
+	require.Len(t, res.Items, 6)
+
+	first := res.Items[0]["index"]
+	require.Equal(t, http.StatusCreated, first.Status)
+	require.Equal(t, "1", first.ID)
+
+	second := res.Items[1]["create"]
+	require.Equal(t, http.StatusCreated, second.Status)
+	require.NotEmpty(t, second.ID)
+	require.Equal(t, first.TxID, second.TxID)
+
+	again := res.Items[2]["index"]
+	require.Equal(t, http.StatusCreated, again.Status)
+	require.Greater(t, again.TxID, first.TxID)
+
+	require.Equal(t, http.StatusBadRequest, res.Items[3]["delete"].Status)
+	require.Equal(t, http.StatusBadRequest, res.Items[4]["update"].Status)
+	require.Equal(t, http.StatusBadRequest, res.Items[5]["index"].Status)
+
+	lr, err := s.Login(context.Background(), &schema.LoginRequest{
+		User:     []byte(auth.SysAdminUsername),
+		Password: []byte(auth.SysAdminPassword),
+	})
+	require.NoError(t, err)
+
+	ur, err := s.UseDatabase(metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", lr.Token)), &schema.Database{DatabaseName: DefaultdbName})
+	require.NoError(t, err)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", ur.Token))
+
+	e, err := s.Get(ctx, &schema.KeyRequest{Key: []byte("logs:1")})
+	require.NoError(t, err)
+	require.Equal(t, []byte(`{"log":"first, again"}`), e.Value)
+
+	e, err = s.Get(ctx, &schema.KeyRequest{Key: []byte("logs:" + second.ID)})
+	require.NoError(t, err)
+	require.Equal(t, []byte(`{"log":"second"}`), e.Value)
+
+	var body strings.Builder
+	for i := 0; i < bulkTxSize+1; i++ {
+		fmt.Fprintf(&body, "{\"index\":{\"_id\":\"%d\"}}\n{\"n\":%d}\n", i, i)
+	}
+
+	_, res = bulk(http.MethodPost, "/api/v1/db/defaultdb/events/_bulk", body.String())
+	require.False(t, res.Errors)
+	require.Len(t, res.Items, bulkTxSize+1)
+	require.Equal(t, "events", res.Items[0]["index"].Index)
+	require.Greater(t, res.Items[bulkTxSize]["index"].TxID, res.Items[0]["index"].TxID)
+
+	e, err = s.Get(ctx, &schema.KeyRequest{Key: []byte(fmt.Sprintf("events:%d", bulkTxSize))})
+	require.NoError(t, err)
+	require.Equal(t, []byte(fmt.Sprintf(`{"n":%d}`, bulkTxSize)), e.Value)
+
+	w, _ = bulk(http.MethodPost, "/api/v1/db/defaultdb/_bulk", "not json\n")
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	w, _ = bulk(http.MethodPost, "/api/v1/db/defaultdb/_bulk", `{"index":{"_index":"logs"}}`)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	w, _ = bulk(http.MethodGet, "/api/v1/db/defaultdb/_bulk", "")
+	require.Equal(t, http.StatusMethodNotAllowed, w.Code)
+
+	w, _ = bulk(http.MethodPost, "/api/v1/db/defaultdb/a/b/_bulk", "")
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/db/defaultdb/_bulk", strings.NewReader("{\"index\":{\"_index\":\"logs\"}}\n{}\n"))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+}
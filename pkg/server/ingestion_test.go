@@ -0,0 +1,269 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/auth"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestServerIngestion(t *testing.T) {
+	serverOptions := DefaultOptions().
+		WithMetricsServer(false).
+		WithAdminPassword(auth.SysAdminPassword).
+		WithListener(bufconn.Listen(1024 * 1024))
+	s := DefaultServer().WithOptions(serverOptions).(*ImmuServer)
+	defer os.RemoveAll(s.Options.Dir)
+
+	require.NoError(t, s.Initialize())
+
+	h := ingestionHandler(s)
+
+	post := func(path, body string, setAuth func(r *http.Request)) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		if setAuth != nil {
+			setAuth(r)
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		return w
+	}
+
+	basicAuth := func(r *http.Request) {
+		r.SetBasicAuth(auth.SysAdminUsername, auth.SysAdminPassword)
+	}
+
+	txID := func(w *httptest.ResponseRecorder) uint64 {
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var res ingestionResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+
+		return res.ID
+	}
+
+	id := txID(post("/api/v1/db/defaultdb/kv", `{"key":"hook1","value":"plain"}`, basicAuth))
+	require.NotZero(t, id)
+
+	lr, err := s.Login(context.Background(), &schema.LoginRequest{
+		User:     []byte(auth.SysAdminUsername),
+		Password: []byte(auth.SysAdminPassword),
+	})
+	require.NoError(t, err)
+
+	bearerAuth := func(r *http.Request) {
+		r.Header.Set("Authorization", "Bearer "+lr.Token)
+	}
+
+	batchID := txID(post("/api/v1/db/defaultdb/kv", `{"kvs":[{"key":"hook2","value":{"event":"push"}},{"key":"hook3","value":"x"}]}`, bearerAuth))
+	require.Greater(t, batchID, id)
+
+	ur, err := s.UseDatabase(metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", lr.Token)), &schema.Database{DatabaseName: DefaultdbName})
+	require.NoError(t, err)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", ur.Token))
+
+	e, err := s.Get(ctx, &schema.KeyRequest{Key: []byte("hook1")})
+	require.NoError(t, err)
+	require.Equal(t, []byte("plain"), e.Value)
+	require.Equal(t, id, e.Tx)
+
+	e, err = s.Get(ctx, &schema.KeyRequest{Key: []byte("hook2")})
+	require.NoError(t, err)
+	require.Equal(t, []byte(`{"event":"push"}`), e.Value)
+	require.Equal(t, batchID, e.Tx)
+
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/db/defaultdb/kv", strings.NewReader(`{"key":"hook4","value":"v"}`))
+	r.Header.Set("Idempotency-Key", "delivery-1")
+	basicAuth(r)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	retriedID := txID(w)
+
+	r = httptest.NewRequest(http.MethodPost, "/api/v1/db/defaultdb/kv", strings.NewReader(`{"key":"hook4","value":"v"}`))
+	r.Header.Set("Idempotency-Key", "delivery-1")
+	basicAuth(r)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	require.Equal(t, retriedID, txID(w))
+
+	w = post("/api/v1/db/defaultdb/kv", `{"key":"k","value":"v"}`, nil)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	require.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+
+	w = post("/api/v1/db/defaultdb/kv", `{"key":"k","value":"v"}`, func(r *http.Request) {
+		r.SetBasicAuth(auth.SysAdminUsername, "wrong")
+	})
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = post("/api/v1/db/unknowndb/kv", `{"key":"k","value":"v"}`, basicAuth)
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	w = post("/api/v1/db/defaultdb/other", `{"key":"k","value":"v"}`, basicAuth)
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	w = post("/api/v1/db/defaultdb/kv", `{"key":"k","value":"v","kvs":[{"key":"k2","value":"v"}]}`, basicAuth)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = post("/api/v1/db/defaultdb/kv", `{}`, basicAuth)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = post("/api/v1/db/defaultdb/kv", `{"kvs":[{"value":"v"}]}`, basicAuth)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = post("/api/v1/db/defaultdb/kv", `not json`, basicAuth)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	r = httptest.NewRequest(http.MethodGet, "/api/v1/db/defaultdb/kv", nil)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	require.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
+
+func TestServerIngestionAuth(t *testing.T) {
+	serverOptions := DefaultOptions().
+		WithMetricsServer(false).
+		WithAdminPassword(auth.SysAdminPassword).
+		WithListener(bufconn.Listen(1024 * 1024))
+	s := DefaultServer().WithOptions(serverOptions).(*ImmuServer)
+	defer os.RemoveAll(s.Options.Dir)
+
+	require.NoError(t, s.Initialize())
+
+	lr, err := s.Login(context.Background(), &schema.LoginRequest{
+		User:     []byte(auth.SysAdminUsername),
+		Password: []byte(auth.SysAdminPassword),
+	})
+	require.NoError(t, err)
+
+	adminCtx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", lr.Token))
+
+	for _, u := range []struct {
+		name       string
+		permission uint32
+	}{{"writer", auth.PermissionRW}, {"reader", auth.PermissionR}} {
+		_, err = s.CreateUser(adminCtx, &schema.CreateUserRequest{
+			User:       []byte(u.name),
+			Password:   []byte("1Password!*"),
+			Permission: u.permission,
+			Database:   DefaultdbName,
+		})
+		require.NoError(t, err)
+	}
+
+	ia := newIngestionAuth(s)
+
+	request := func(user, password string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/db/defaultdb/kv", nil)
+		r.SetBasicAuth(user, password)
+		return r
+	}
+
+	_, err = ia.context(request("writer", "1Password!*"), DefaultdbName, "Set")
+	require.NoError(t, err)
+	require.Len(t, ia.logins, 1)
+
+	token, ok := ia.remembered(ia.credential("writer", "1Password!*"))
+	require.True(t, ok)
+
+	// following requests reuse the token instead of logging in again
+	_, err = ia.context(request("writer", "1Password!*"), DefaultdbName, "Set")
+	require.NoError(t, err)
+
+	reused, ok := ia.remembered(ia.credential("writer", "1Password!*"))
+	require.True(t, ok)
+	require.Equal(t, token, reused)
+
+	// a password change invalidates the remembered token
+	_, err = s.ChangePassword(adminCtx, &schema.ChangePasswordRequest{
+		User:        []byte("writer"),
+		OldPassword: []byte("1Password!*"),
+		NewPassword: []byte("2Password!*"),
+	})
+	require.NoError(t, err)
+
+	_, err = ia.context(request("writer", "1Password!*"), DefaultdbName, "Set")
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+	require.Empty(t, ia.logins)
+
+	_, err = ia.context(request("writer", "2Password!*"), DefaultdbName, "Set")
+	require.NoError(t, err)
+
+	h := ingestionHandler(s)
+
+	post := func(user, password string) int {
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/db/defaultdb/kv", strings.NewReader(`{"key":"k","value":"v"}`))
+		r.SetBasicAuth(user, password)
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		return w.Code
+	}
+
+	require.Equal(t, http.StatusOK, post("writer", "2Password!*"))
+	require.Equal(t, http.StatusUnauthorized, post("writer", "1Password!*"))
+
+	// users allowed to read the database are not allowed to write to it
+	require.Equal(t, http.StatusForbidden, post("reader", "1Password!*"))
+
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/db/"+SystemdbName+"/kv", strings.NewReader(`{"key":"k","value":"v"}`))
+	r.SetBasicAuth(auth.SysAdminUsername, auth.SysAdminPassword)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	require.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestServerIngestionAuthDisabled(t *testing.T) {
+	serverOptions := DefaultOptions().
+		WithMetricsServer(false).
+		WithAuth(false).
+		WithListener(bufconn.Listen(1024 * 1024))
+	s := DefaultServer().WithOptions(serverOptions).(*ImmuServer)
+	defer os.RemoveAll(s.Options.Dir)
+
+	require.NoError(t, s.Initialize())
+
+	h := ingestionHandler(s)
+
+	for _, setAuth := range []func(r *http.Request){
+		func(r *http.Request) { r.SetBasicAuth(auth.SysAdminUsername, auth.SysAdminPassword) },
+		func(r *http.Request) { r.Header.Set("Authorization", "Bearer token") },
+	} {
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/db/defaultdb/kv", strings.NewReader(`{"key":"k","value":"v"}`))
+		setAuth(r)
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		require.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+	}
+}
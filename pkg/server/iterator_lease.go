@@ -0,0 +1,59 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package server
+
+import (
+	"context"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/golang/protobuf/ptypes/empty"
+)
+
+func (s *ImmuServer) OpenIterator(ctx context.Context, req *schema.OpenIteratorRequest) (*schema.IteratorLease, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "OpenIterator")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.dbList.GetByIndex(ind).OpenIterator(ctx, req)
+}
+
+func (s *ImmuServer) IteratorNext(ctx context.Context, req *schema.IteratorNextRequest) (*schema.IteratorPage, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "IteratorNext")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.dbList.GetByIndex(ind).IteratorNext(ctx, req)
+}
+
+func (s *ImmuServer) KeepAliveIterator(ctx context.Context, req *schema.IteratorLeaseRequest) (*schema.IteratorLease, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "KeepAliveIterator")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.dbList.GetByIndex(ind).KeepAliveIterator(req)
+}
+
+func (s *ImmuServer) CloseIterator(ctx context.Context, req *schema.IteratorLeaseRequest) (*empty.Empty, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "CloseIterator")
+	if err != nil {
+		return nil, err
+	}
+
+	return new(empty.Empty), s.dbList.GetByIndex(ind).CloseIterator(req)
+}
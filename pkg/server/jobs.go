@@ -0,0 +1,313 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/rs/xid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Job states
+const (
+	JobStateRunning   = "running"
+	JobStateCompleted = "completed"
+	JobStateFailed    = "failed"
+	JobStateCancelled = "cancelled"
+)
+
+// maxFinishedJobs is the number of finished jobs kept in memory so their outcome can still be queried
+const maxFinishedJobs = 100
+
+var (
+	ErrJobNotFound       = status.Error(codes.NotFound, "job not found")
+	ErrJobNotRunning     = status.Error(codes.FailedPrecondition, "job is not running")
+	ErrJobNotCancellable = status.Error(codes.FailedPrecondition, "job can not be cancelled")
+)
+
+// job is a long-running task such as an index compaction or an audit
+type job struct {
+	id         string
+	kind       string
+	database   string
+	startedAt  time.Time
+	finishedAt time.Time
+	progress   uint32
+	state      string
+	err        string
+	cancel     context.CancelFunc
+	cancelled  bool
+
+	// changed is closed and replaced every time the job is updated
+	changed chan struct{}
+
+	// only cancellable jobs can be interrupted through CancelJob
+	cancellable bool
+}
+
+// jobs keeps track of the running jobs and of the most recently finished ones
+type jobs struct {
+	all      map[string]*job
+	finished []string
+	sync.Mutex
+}
+
+func newJobs() *jobs {
+	return &jobs{all: make(map[string]*job)}
+}
+
+// run executes f as a job. f is provided with a context which is cancelled when a cancellable job is cancelled, and
+// with a function to report its progress as a percentage
+func (j *jobs) run(ctx context.Context, kind, database string, cancellable bool, f func(ctx context.Context, progress func(uint32)) error) error {
+	jb, ctx := j.begin(ctx, kind, database, cancellable)
+
+	err := f(ctx, func(progress uint32) {
+		j.setProgress(jb, progress)
+	})
+
+	j.end(jb, err)
+
+	return err
+}
+
+func (j *jobs) begin(ctx context.Context, kind, database string, cancellable bool) (*job, context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	jb := &job{
+		id:          xid.New().String(),
+		kind:        kind,
+		database:    database,
+		startedAt:   time.Now(),
+		state:       JobStateRunning,
+		cancel:      cancel,
+		changed:     make(chan struct{}),
+		cancellable: cancellable,
+	}
+
+	j.Lock()
+	j.all[jb.id] = jb
+	j.Unlock()
+
+	return jb, ctx
+}
+
+// notify must be called while holding the mutex
+func (jb *job) notify() {
+	close(jb.changed)
+	jb.changed = make(chan struct{})
+}
+
+func (j *jobs) setProgress(jb *job, progress uint32) {
+	if progress > 100 {
+		progress = 100
+	}
+
+	j.Lock()
+	defer j.Unlock()
+
+	if jb.progress == progress {
+		return
+	}
+
+	jb.progress = progress
+	jb.notify()
+}
+
+func (j *jobs) end(jb *job, err error) {
+	j.Lock()
+	defer j.Unlock()
+
+	jb.finishedAt = time.Now()
+
+	switch {
+	case jb.cancelled:
+		jb.state = JobStateCancelled
+	case err != nil:
+		jb.state = JobStateFailed
+	default:
+		jb.state = JobStateCompleted
+		jb.progress = 100
+	}
+
+	if err != nil {
+		jb.err = err.Error()
+	}
+
+	jb.cancel()
+	jb.notify()
+
+	j.finished = append(j.finished, jb.id)
+
+	if len(j.finished) > maxFinishedJobs {
+		delete(j.all, j.finished[0])
+		j.finished = j.finished[1:]
+	}
+}
+
+// get returns the current state of a job and a channel closed on its next update
+func (j *jobs) get(id string) (*schema.Job, <-chan struct{}, error) {
+	j.Lock()
+	defer j.Unlock()
+
+	jb, ok := j.all[id]
+	if !ok {
+		return nil, nil, ErrJobNotFound
+	}
+
+	return jb.toProto(), jb.changed, nil
+}
+
+func (j *jobs) list() []*schema.Job {
+	j.Lock()
+	defer j.Unlock()
+
+	list := make([]*schema.Job, 0, len(j.all))
+	for _, jb := range j.all {
+		list = append(list, jb.toProto())
+	}
+
+	// xids are ordered by creation time
+	sort.Slice(list, func(i, k int) bool {
+		return list[i].Id < list[k].Id
+	})
+
+	return list
+}
+
+func (j *jobs) cancelJob(id string) error {
+	j.Lock()
+	defer j.Unlock()
+
+	jb, ok := j.all[id]
+	if !ok {
+		return ErrJobNotFound
+	}
+
+	if jb.state != JobStateRunning {
+		return ErrJobNotRunning
+	}
+
+	if !jb.cancellable {
+		return ErrJobNotCancellable
+	}
+
+	jb.cancelled = true
+	jb.cancel()
+
+	return nil
+}
+
+// toProto must be called while holding the mutex
+func (jb *job) toProto() *schema.Job {
+	js := &schema.Job{
+		Id:          jb.id,
+		Kind:        jb.kind,
+		Database:    jb.database,
+		State:       jb.state,
+		Progress:    jb.progress,
+		StartedAt:   jb.startedAt.Unix(),
+		Error:       jb.err,
+		Cancellable: jb.cancellable,
+	}
+
+	if !jb.finishedAt.IsZero() {
+		js.FinishedAt = jb.finishedAt.Unix()
+	}
+
+	return js
+}
+
+// ListJobs returns the running jobs and the most recently finished ones
+func (s *ImmuServer) ListJobs(ctx context.Context, _ *empty.Empty) (*schema.JobList, error) {
+	if err := s.checkSysAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	return &schema.JobList{Jobs: s.jobs.list()}, nil
+}
+
+// GetJob returns the state and progress of a job
+func (s *ImmuServer) GetJob(ctx context.Context, req *schema.JobRequest) (*schema.Job, error) {
+	if req == nil {
+		return nil, ErrIllegalArguments
+	}
+
+	if err := s.checkSysAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	jb, _, err := s.jobs.get(req.Id)
+
+	return jb, err
+}
+
+// CancelJob cancels a running job
+func (s *ImmuServer) CancelJob(ctx context.Context, req *schema.JobRequest) (*empty.Empty, error) {
+	if req == nil {
+		return nil, ErrIllegalArguments
+	}
+
+	if err := s.checkSysAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	s.Logger.Infof("cancelling job %s", req.Id)
+
+	return new(empty.Empty), s.jobs.cancelJob(req.Id)
+}
+
+// WatchJob sends the state of a job every time it changes, until the job is finished
+func (s *ImmuServer) WatchJob(req *schema.JobRequest, stream schema.ImmuService_WatchJobServer) error {
+	if req == nil {
+		return ErrIllegalArguments
+	}
+
+	ctx := stream.Context()
+
+	if err := s.checkSysAdmin(ctx); err != nil {
+		return err
+	}
+
+	for {
+		jb, changed, err := s.jobs.get(req.Id)
+		if err != nil {
+			return err
+		}
+
+		err = stream.Send(jb)
+		if err != nil {
+			return err
+		}
+
+		if jb.State != JobStateRunning {
+			return nil
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/auth"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type watchJobServerMock struct {
+	grpc.ServerStream
+	ctx  context.Context
+	send func(*schema.Job) error
+}
+
+func (m *watchJobServerMock) Context() context.Context {
+	return m.ctx
+}
+
+func (m *watchJobServerMock) Send(jb *schema.Job) error {
+	return m.send(jb)
+}
+
+func TestJobs(t *testing.T) {
+	jobs := newJobs()
+
+	started := make(chan string)
+	done := make(chan error)
+
+	go func() {
+		done <- jobs.run(context.Background(), JobAudit, "db1", true, func(ctx context.Context, progress func(uint32)) error {
+			progress(50)
+			started <- jobs.list()[0].Id
+			<-ctx.Done()
+			return ctx.Err()
+		})
+	}()
+
+	id := <-started
+
+	list := jobs.list()
+	require.Len(t, list, 1)
+	require.Equal(t, JobAudit, list[0].Kind)
+	require.Equal(t, "db1", list[0].Database)
+	require.Equal(t, JobStateRunning, list[0].State)
+	require.Equal(t, uint32(50), list[0].Progress)
+	require.True(t, list[0].Cancellable)
+
+	_, changed, err := jobs.get(id)
+	require.NoError(t, err)
+
+	_, _, err = jobs.get("unknown")
+	require.Equal(t, ErrJobNotFound, err)
+
+	require.Equal(t, ErrJobNotFound, jobs.cancelJob("unknown"))
+
+	err = jobs.cancelJob(id)
+	require.NoError(t, err)
+	require.Equal(t, context.Canceled, <-done)
+
+	<-changed
+
+	js, _, err := jobs.get(id)
+	require.NoError(t, err)
+	require.Equal(t, JobStateCancelled, js.State)
+	require.Equal(t, context.Canceled.Error(), js.Error)
+	require.NotZero(t, js.FinishedAt)
+
+	require.Equal(t, ErrJobNotRunning, jobs.cancelJob(id))
+
+	err = jobs.run(context.Background(), JobCompaction, "db1", false, func(ctx context.Context, progress func(uint32)) error {
+		require.Equal(t, ErrJobNotCancellable, jobs.cancelJob(jobs.list()[1].Id))
+		return errors.New("compaction failed")
+	})
+	require.Error(t, err)
+
+	list = jobs.list()
+	require.Len(t, list, 2)
+	require.Equal(t, JobStateFailed, list[1].State)
+	require.Equal(t, "compaction failed", list[1].Error)
+
+	for i := 0; i < maxFinishedJobs; i++ {
+		err = jobs.run(context.Background(), JobCompaction, "db1", false, func(ctx context.Context, progress func(uint32)) error {
+			return nil
+		})
+		require.NoError(t, err)
+	}
+
+	list = jobs.list()
+	require.Len(t, list, maxFinishedJobs)
+	require.Equal(t, JobStateCompleted, list[0].State)
+	require.Equal(t, uint32(100), list[0].Progress)
+}
+
+func TestServerJobs(t *testing.T) {
+	serverOptions := DefaultOptions().
+		WithMetricsServer(false).
+		WithAdminPassword(auth.SysAdminPassword).
+		WithListener(bufconn.Listen(1024 * 1024))
+	s := DefaultServer().WithOptions(serverOptions).(*ImmuServer)
+	defer os.RemoveAll(s.Options.Dir)
+
+	require.NoError(t, s.Initialize())
+
+	lr, err := s.Login(context.Background(), &schema.LoginRequest{
+		User:     []byte(auth.SysAdminUsername),
+		Password: []byte(auth.SysAdminPassword),
+	})
+	require.NoError(t, err)
+
+	md := metadata.Pairs("authorization", lr.Token)
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+
+	_, err = s.CleanIndex(ctx, &empty.Empty{})
+	require.Error(t, err)
+
+	list, err := s.ListJobs(ctx, &empty.Empty{})
+	require.NoError(t, err)
+	require.Len(t, list.Jobs, 1)
+	require.Equal(t, JobCompaction, list.Jobs[0].Kind)
+	require.Equal(t, DefaultdbName, list.Jobs[0].Database)
+	require.Equal(t, JobStateFailed, list.Jobs[0].State)
+	require.False(t, list.Jobs[0].Cancellable)
+
+	jb, err := s.GetJob(ctx, &schema.JobRequest{Id: list.Jobs[0].Id})
+	require.NoError(t, err)
+	require.Equal(t, list.Jobs[0], jb)
+
+	_, err = s.GetJob(ctx, nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = s.CancelJob(ctx, nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = s.CancelJob(ctx, &schema.JobRequest{Id: jb.Id})
+	require.Equal(t, ErrJobNotRunning, err)
+
+	var updates []*schema.Job
+
+	err = s.WatchJob(&schema.JobRequest{Id: jb.Id}, &watchJobServerMock{
+		ctx: ctx,
+		send: func(jb *schema.Job) error {
+			updates = append(updates, jb)
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, updates, 1)
+	require.Equal(t, JobStateFailed, updates[0].State)
+
+	err = s.WatchJob(&schema.JobRequest{Id: "unknown"}, &watchJobServerMock{ctx: ctx})
+	require.Equal(t, ErrJobNotFound, err)
+
+	err = s.WatchJob(nil, &watchJobServerMock{ctx: ctx})
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = s.ListJobs(context.Background(), &empty.Empty{})
+	require.Error(t, err)
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/database"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/empty"
+)
+
+// SetKeyRules replaces the naming rules keys written into the selected database must satisfy.
+// Rules are persisted in the system database and applied again when the database is loaded
+func (s *ImmuServer) SetKeyRules(ctx context.Context, rules *schema.KeyRules) (*empty.Empty, error) {
+	if rules == nil {
+		return nil, ErrIllegalArguments
+	}
+
+	if !s.Options.GetAuth() || s.sysDb == nil {
+		return nil, fmt.Errorf("this command is available only with authentication on")
+	}
+
+	ind, err := s.getDbIndexFromCtx(ctx, "SetKeyRules")
+	if err != nil {
+		return nil, err
+	}
+
+	db := s.dbList.GetByIndex(ind)
+
+	prevRules := db.GetKeyRules()
+
+	err = db.SetKeyRules(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.saveKeyRules(db.GetOptions().GetDbName(), rules)
+	if err != nil {
+		db.SetKeyRules(prevRules)
+		return nil, err
+	}
+
+	s.recordEvent(ctx, EventCategoryDatabase, db.GetOptions().GetDbName(), "key rules changed", nil)
+
+	return &empty.Empty{}, nil
+}
+
+// GetKeyRules returns the naming rules keys written into the selected database must satisfy
+func (s *ImmuServer) GetKeyRules(ctx context.Context, _ *empty.Empty) (*schema.KeyRules, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "GetKeyRules")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.dbList.GetByIndex(ind).GetKeyRules(), nil
+}
+
+func (s *ImmuServer) saveKeyRules(dbName string, rules *schema.KeyRules) error {
+	rulesData, err := proto.Marshal(rules)
+	if err != nil {
+		return logErr(s.Logger, "error saving key rules: %v", err)
+	}
+
+	_, err = s.sysDb.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: keyRulesKey(dbName), Value: rulesData}}})
+
+	return logErr(s.Logger, "error saving key rules: %v", err)
+}
+
+// loadKeyRules applies the key rules persisted for the database, if any
+func (s *ImmuServer) loadKeyRules(db database.DB) error {
+	if s.sysDb == nil {
+		return nil
+	}
+
+	dbName := db.GetOptions().GetDbName()
+
+	item, err := s.sysDb.Get(&schema.KeyRequest{Key: keyRulesKey(dbName)})
+	if err == store.ErrKeyNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var rules schema.KeyRules
+
+	err = proto.Unmarshal(item.Value, &rules)
+	if err != nil {
+		return err
+	}
+
+	return db.SetKeyRules(&rules)
+}
+
+func keyRulesKey(dbName string) []byte {
+	key := make([]byte, 1+len(dbName))
+	key[0] = KeyPrefixKeyRules
+	copy(key[1:], dbName)
+	return key
+}
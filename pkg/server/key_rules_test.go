@@ -0,0 +1,101 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package server
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/auth"
+	"github.com/codenotary/immudb/pkg/database"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestServerKeyRules(t *testing.T) {
+	serverOptions := DefaultOptions().
+		WithMetricsServer(false).
+		WithAdminPassword(auth.SysAdminPassword).
+		WithListener(bufconn.Listen(1024 * 1024))
+	s := DefaultServer().WithOptions(serverOptions).(*ImmuServer)
+	defer os.RemoveAll(s.Options.Dir)
+
+	require.NoError(t, s.Initialize())
+
+	lr, err := s.Login(context.Background(), &schema.LoginRequest{
+		User:     []byte(auth.SysAdminUsername),
+		Password: []byte(auth.SysAdminPassword),
+	})
+	require.NoError(t, err)
+
+	md := metadata.Pairs("authorization", lr.Token)
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+
+	_, err = s.SetKeyRules(ctx, nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = s.SetKeyRules(context.Background(), &schema.KeyRules{MaxDepth: 2})
+	require.Error(t, err)
+
+	_, err = s.SetKeyRules(ctx, &schema.KeyRules{Pattern: "["})
+	require.Equal(t, database.ErrIllegalArguments, err)
+
+	rules := &schema.KeyRules{AllowedPrefixes: [][]byte{[]byte("orders:")}, MaxDepth: 2}
+
+	_, err = s.SetKeyRules(ctx, rules)
+	require.NoError(t, err)
+
+	_, err = s.Set(ctx, &schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("invoices:1"), Value: []byte("value")}}})
+	require.Equal(t, database.ErrKeyRejected, err)
+
+	_, err = s.Set(ctx, &schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("orders:1"), Value: []byte("value")}}})
+	require.NoError(t, err)
+
+	err = s.CloseDatabases()
+	require.NoError(t, err)
+
+	// rules are loaded again along with the database
+	s = DefaultServer().WithOptions(serverOptions).(*ImmuServer)
+
+	require.NoError(t, s.Initialize())
+
+	lr, err = s.Login(context.Background(), &schema.LoginRequest{
+		User:     []byte(auth.SysAdminUsername),
+		Password: []byte(auth.SysAdminPassword),
+	})
+	require.NoError(t, err)
+
+	md = metadata.Pairs("authorization", lr.Token)
+	ctx = metadata.NewIncomingContext(context.Background(), md)
+
+	loadedRules, err := s.GetKeyRules(ctx, &empty.Empty{})
+	require.NoError(t, err)
+	require.Equal(t, rules.AllowedPrefixes, loadedRules.AllowedPrefixes)
+	require.Equal(t, rules.MaxDepth, loadedRules.MaxDepth)
+
+	_, err = s.Set(ctx, &schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("invoices:1"), Value: []byte("value")}}})
+	require.Equal(t, database.ErrKeyRejected, err)
+
+	_, err = s.SetKeyRules(ctx, &schema.KeyRules{})
+	require.NoError(t, err)
+
+	_, err = s.Set(ctx, &schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("invoices:1"), Value: []byte("value")}}})
+	require.NoError(t, err)
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/golang/protobuf/ptypes/empty"
+)
+
+// PlaceLegalHold places a hold on a prefix of the selected database, keys within it can't be deleted or expired
+// until the hold is lifted. The event is written into the database itself
+func (s *ImmuServer) PlaceLegalHold(ctx context.Context, req *schema.LegalHoldRequest) (*schema.LegalHold, error) {
+	return s.setLegalHold(ctx, "PlaceLegalHold", req, true)
+}
+
+// LiftLegalHold lifts the hold placed on a prefix of the selected database
+func (s *ImmuServer) LiftLegalHold(ctx context.Context, req *schema.LegalHoldRequest) (*schema.LegalHold, error) {
+	return s.setLegalHold(ctx, "LiftLegalHold", req, false)
+}
+
+func (s *ImmuServer) setLegalHold(ctx context.Context, methodName string, req *schema.LegalHoldRequest, active bool) (*schema.LegalHold, error) {
+	if req == nil {
+		return nil, ErrIllegalArguments
+	}
+
+	ind, err := s.getDbIndexFromCtx(ctx, methodName)
+	if err != nil {
+		return nil, err
+	}
+
+	hold := &schema.LegalHold{
+		Prefix: req.Prefix,
+		Active: active,
+		Reason: req.Reason,
+		Time:   time.Now().Unix(),
+	}
+
+	if s.Options.GetAuth() {
+		_, user, err := s.getLoggedInUserdataFromCtx(ctx)
+		if err != nil {
+			return nil, err
+		}
+		hold.By = user.Username
+	}
+
+	db := s.dbList.GetByIndex(ind)
+
+	hold, err = db.SetLegalHold(hold)
+	if err != nil {
+		return nil, err
+	}
+
+	event := "legal hold placed"
+	if !active {
+		event = "legal hold lifted"
+	}
+
+	s.recordEvent(ctx, EventCategoryDatabase, db.GetOptions().GetDbName(), event, map[string]string{"prefix": string(hold.Prefix), "reason": hold.Reason})
+
+	return hold, nil
+}
+
+// ListLegalHolds returns the legal holds currently placed on the selected database
+func (s *ImmuServer) ListLegalHolds(ctx context.Context, _ *empty.Empty) (*schema.LegalHolds, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "ListLegalHolds")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.dbList.GetByIndex(ind).ListLegalHolds(), nil
+}
+
+// LegalHoldHistory returns every placement and lift of the hold on a prefix of the selected database
+func (s *ImmuServer) LegalHoldHistory(ctx context.Context, req *schema.LegalHoldRequest) (*schema.LegalHolds, error) {
+	if req == nil {
+		return nil, ErrIllegalArguments
+	}
+
+	ind, err := s.getDbIndexFromCtx(ctx, "LegalHoldHistory")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.dbList.GetByIndex(ind).LegalHoldHistory(req.Prefix)
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package server
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/auth"
+	"github.com/codenotary/immudb/pkg/database"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestServerLegalHolds(t *testing.T) {
+	serverOptions := DefaultOptions().
+		WithMetricsServer(false).
+		WithAdminPassword(auth.SysAdminPassword).
+		WithListener(bufconn.Listen(1024 * 1024))
+	s := DefaultServer().WithOptions(serverOptions).(*ImmuServer)
+	defer os.RemoveAll(s.Options.Dir)
+
+	require.NoError(t, s.Initialize())
+
+	lr, err := s.Login(context.Background(), &schema.LoginRequest{
+		User:     []byte(auth.SysAdminUsername),
+		Password: []byte(auth.SysAdminPassword),
+	})
+	require.NoError(t, err)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", lr.Token))
+
+	_, err = s.PlaceLegalHold(ctx, nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = s.PlaceLegalHold(context.Background(), &schema.LegalHoldRequest{Prefix: []byte("case1:")})
+	require.Error(t, err)
+
+	hold, err := s.PlaceLegalHold(ctx, &schema.LegalHoldRequest{Prefix: []byte("case1:"), Reason: "litigation"})
+	require.NoError(t, err)
+	require.True(t, hold.Active)
+	require.Equal(t, auth.SysAdminUsername, hold.By)
+
+	holds, err := s.ListLegalHolds(ctx, &empty.Empty{})
+	require.NoError(t, err)
+	require.Len(t, holds.Holds, 1)
+
+	_, err = s.LiftLegalHold(ctx, &schema.LegalHoldRequest{Prefix: []byte("case2:")})
+	require.Equal(t, database.ErrLegalHoldNotFound, err)
+
+	_, err = s.LiftLegalHold(ctx, &schema.LegalHoldRequest{Prefix: []byte("case1:"), Reason: "settled"})
+	require.NoError(t, err)
+
+	history, err := s.LegalHoldHistory(ctx, &schema.LegalHoldRequest{Prefix: []byte("case1:")})
+	require.NoError(t, err)
+	require.Len(t, history.Holds, 2)
+}
@@ -26,6 +26,8 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"google.golang.org/grpc/peer"
 
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/database"
 	"github.com/codenotary/immudb/pkg/logger"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -38,9 +40,30 @@ type MetricsCollection struct {
 	computeDBSizes func() map[string]float64
 	DBSizeGauges   *prometheus.GaugeVec
 
+	computeDBArchivedSizes func() map[string]float64
+	DBArchivedSizeGauges   *prometheus.GaugeVec
+
 	computeDBEntries func() map[string]float64
 	DBEntriesGauges  *prometheus.GaugeVec
 
+	computeDBCorruptedReads func() map[string]float64
+	DBCorruptedReadsGauges  *prometheus.GaugeVec
+
+	computeDBPrefixStats func() map[string][]*schema.PrefixStats
+	DBPrefixOpsGauges    *prometheus.GaugeVec
+	DBPrefixBytesGauges  *prometheus.GaugeVec
+
+	computeDBIdempotencyStats       func() map[string]*database.IdempotencyStats
+	DBIdempotencyKeysGauges         *prometheus.GaugeVec
+	DBIdempotencySuppressedCounters *prometheus.CounterVec
+	// last suppressed writes count observed per database, used to increment the counters
+	dbIdempotencySuppressed map[string]uint64
+
+	computeDBTableStats         func() map[string][]*database.TableStats
+	DBTableRowsGauges           *prometheus.GaugeVec
+	DBTableBytesGauges          *prometheus.GaugeVec
+	DBTableLastModifiedTxGauges *prometheus.GaugeVec
+
 	RPCsPerClientCounters        *prometheus.CounterVec
 	LastMessageAtPerClientGauges *prometheus.GaugeVec
 }
@@ -76,11 +99,36 @@ func (mc *MetricsCollection) WithComputeDBSizes(f func() map[string]float64) {
 	mc.computeDBSizes = f
 }
 
+// WithComputeDBArchivedSizes ...
+func (mc *MetricsCollection) WithComputeDBArchivedSizes(f func() map[string]float64) {
+	mc.computeDBArchivedSizes = f
+}
+
 // WithComputeDBEntries ...
 func (mc *MetricsCollection) WithComputeDBEntries(f func() map[string]float64) {
 	mc.computeDBEntries = f
 }
 
+// WithComputeDBCorruptedReads ...
+func (mc *MetricsCollection) WithComputeDBCorruptedReads(f func() map[string]float64) {
+	mc.computeDBCorruptedReads = f
+}
+
+// WithComputeDBPrefixStats ...
+func (mc *MetricsCollection) WithComputeDBPrefixStats(f func() map[string][]*schema.PrefixStats) {
+	mc.computeDBPrefixStats = f
+}
+
+// WithComputeDBIdempotencyStats ...
+func (mc *MetricsCollection) WithComputeDBIdempotencyStats(f func() map[string]*database.IdempotencyStats) {
+	mc.computeDBIdempotencyStats = f
+}
+
+// WithComputeDBTableStats ...
+func (mc *MetricsCollection) WithComputeDBTableStats(f func() map[string][]*database.TableStats) {
+	mc.computeDBTableStats = f
+}
+
 // UpdateDBMetrics ...
 func (mc *MetricsCollection) UpdateDBMetrics() {
 	if mc.computeDBSizes != nil {
@@ -88,11 +136,62 @@ func (mc *MetricsCollection) UpdateDBMetrics() {
 			mc.DBSizeGauges.WithLabelValues(db).Set(size)
 		}
 	}
+	if mc.computeDBArchivedSizes != nil {
+		for db, size := range mc.computeDBArchivedSizes() {
+			mc.DBArchivedSizeGauges.WithLabelValues(db).Set(size)
+		}
+	}
 	if mc.computeDBEntries != nil {
 		for db, nbEntries := range mc.computeDBEntries() {
 			mc.DBEntriesGauges.WithLabelValues(db).Set(nbEntries)
 		}
 	}
+	if mc.computeDBCorruptedReads != nil {
+		for db, nbReads := range mc.computeDBCorruptedReads() {
+			mc.DBCorruptedReadsGauges.WithLabelValues(db).Set(nbReads)
+		}
+	}
+	if mc.computeDBPrefixStats != nil {
+		// only the current top prefixes are exposed, previous ones are dropped to bound cardinality
+		mc.DBPrefixOpsGauges.Reset()
+		mc.DBPrefixBytesGauges.Reset()
+
+		for db, prefixes := range mc.computeDBPrefixStats() {
+			for _, p := range prefixes {
+				mc.DBPrefixOpsGauges.WithLabelValues(db, string(p.Prefix), "read").Set(float64(p.Reads))
+				mc.DBPrefixOpsGauges.WithLabelValues(db, string(p.Prefix), "write").Set(float64(p.Writes))
+				mc.DBPrefixBytesGauges.WithLabelValues(db, string(p.Prefix), "read").Set(float64(p.ReadBytes))
+				mc.DBPrefixBytesGauges.WithLabelValues(db, string(p.Prefix), "write").Set(float64(p.WrittenBytes))
+			}
+		}
+	}
+	if mc.computeDBIdempotencyStats != nil {
+		for db, stats := range mc.computeDBIdempotencyStats() {
+			mc.DBIdempotencyKeysGauges.WithLabelValues(db).Set(float64(stats.Keys))
+
+			if mc.dbIdempotencySuppressed == nil {
+				mc.dbIdempotencySuppressed = make(map[string]uint64)
+			}
+
+			// the count restarts from zero when the database is reopened
+			delta := stats.Suppressed
+			if last := mc.dbIdempotencySuppressed[db]; last <= stats.Suppressed {
+				delta -= last
+			}
+			mc.dbIdempotencySuppressed[db] = stats.Suppressed
+
+			mc.DBIdempotencySuppressedCounters.WithLabelValues(db).Add(float64(delta))
+		}
+	}
+	if mc.computeDBTableStats != nil {
+		for db, tables := range mc.computeDBTableStats() {
+			for _, t := range tables {
+				mc.DBTableRowsGauges.WithLabelValues(db, t.Table).Set(float64(t.Rows))
+				mc.DBTableBytesGauges.WithLabelValues(db, t.Table).Set(float64(t.Bytes))
+				mc.DBTableLastModifiedTxGauges.WithLabelValues(db, t.Table).Set(float64(t.LastModifiedTx))
+			}
+		}
+	}
 }
 
 // Metrics immudb Prometheus metrics collection
@@ -113,6 +212,14 @@ var Metrics = MetricsCollection{
 		},
 		[]string{"db"},
 	),
+	DBArchivedSizeGauges: promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "db_archived_size_bytes",
+			Help:      "Size in bytes of the database data moved into the archive location.",
+		},
+		[]string{"db"},
+	),
 	DBEntriesGauges: promauto.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Namespace: metricsNamespace,
@@ -121,6 +228,70 @@ var Metrics = MetricsCollection{
 		},
 		[]string{"db"},
 	),
+	DBCorruptedReadsGauges: promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "number_of_corrupted_reads",
+			Help:      "Number of reads aborted because corrupted data was detected since the database was opened.",
+		},
+		[]string{"db"},
+	),
+	DBPrefixOpsGauges: promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "number_of_prefix_operations",
+			Help:      "Number of read and write operations on the most accessed key prefixes since the database was opened.",
+		},
+		[]string{"db", "prefix", "op"},
+	),
+	DBPrefixBytesGauges: promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "prefix_operations_bytes",
+			Help:      "Bytes read and written on the most accessed key prefixes since the database was opened.",
+		},
+		[]string{"db", "prefix", "op"},
+	),
+	DBIdempotencyKeysGauges: promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "number_of_idempotency_keys",
+			Help:      "Number of idempotency keys currently remembered by the database.",
+		},
+		[]string{"db"},
+	),
+	DBIdempotencySuppressedCounters: promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "suppressed_duplicate_writes_total",
+			Help:      "Number of retried writes answered with the original transaction.",
+		},
+		[]string{"db"},
+	),
+	DBTableRowsGauges: promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "number_of_sql_table_rows",
+			Help:      "Number of rows currently stored in each SQL table.",
+		},
+		[]string{"db", "table"},
+	),
+	DBTableBytesGauges: promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "sql_table_size_bytes",
+			Help:      "Size in bytes of the entries written for each SQL table, including every row version and index entry.",
+		},
+		[]string{"db", "table"},
+	),
+	DBTableLastModifiedTxGauges: promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "sql_table_last_modified_tx",
+			Help:      "Latest transaction which wrote rows of each SQL table.",
+		},
+		[]string{"db", "table"},
+	),
 	LastMessageAtPerClientGauges: promauto.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Namespace: metricsNamespace,
@@ -138,12 +309,22 @@ func StartMetrics(
 	l logger.Logger,
 	uptimeCounter func() float64,
 	computeDBSizes func() map[string]float64,
+	computeDBArchivedSizes func() map[string]float64,
 	computeDBEntries func() map[string]float64,
+	computeDBCorruptedReads func() map[string]float64,
+	computeDBPrefixStats func() map[string][]*schema.PrefixStats,
+	computeDBIdempotencyStats func() map[string]*database.IdempotencyStats,
+	computeDBTableStats func() map[string][]*database.TableStats,
 ) *http.Server {
 
 	Metrics.WithUptimeCounter(uptimeCounter)
 	Metrics.WithComputeDBSizes(computeDBSizes)
+	Metrics.WithComputeDBArchivedSizes(computeDBArchivedSizes)
 	Metrics.WithComputeDBEntries(computeDBEntries)
+	Metrics.WithComputeDBCorruptedReads(computeDBCorruptedReads)
+	Metrics.WithComputeDBPrefixStats(computeDBPrefixStats)
+	Metrics.WithComputeDBIdempotencyStats(computeDBIdempotencyStats)
+	Metrics.WithComputeDBTableStats(computeDBTableStats)
 
 	go func() {
 		Metrics.UpdateDBMetrics()
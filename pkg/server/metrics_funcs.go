@@ -21,6 +21,9 @@ import (
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/database"
 )
 
 func (s *ImmuServer) metricFuncServerUptimeCounter() float64 {
@@ -74,6 +77,38 @@ func (s *ImmuServer) metricFuncComputeDBSizes() (dbSizes map[string]float64) {
 	return
 }
 
+func (s *ImmuServer) metricFuncComputeDBArchivedSizes() (dbSizes map[string]float64) {
+	dbSizes = make(map[string]float64)
+
+	if s.Options.StoreOptions == nil || s.Options.StoreOptions.ArchivePath == "" {
+		return
+	}
+
+	dbNames := []string{s.sysDb.GetOptions().GetDbName()}
+
+	for i := 0; i < s.dbList.Length(); i++ {
+		dbNames = append(dbNames, s.dbList.GetByIndex(int64(i)).GetOptions().GetDbName())
+	}
+
+	for _, dbName := range dbNames {
+		archiveDir := filepath.Join(s.Options.StoreOptions.ArchivePath, dbName)
+
+		if _, err := os.Stat(archiveDir); os.IsNotExist(err) {
+			dbSizes[dbName] = 0
+			continue
+		}
+
+		dbSize, err := dirSize(archiveDir)
+		if err != nil {
+			s.Logger.Errorf("error updating archived size metric for db %s: %v", dbName, err)
+			continue
+		}
+		dbSizes[dbName] = float64(dbSize)
+	}
+
+	return
+}
+
 func (s *ImmuServer) metricFuncComputeDBEntries() (nbEntriesPerDB map[string]float64) {
 	nbEntriesPerDB = make(map[string]float64)
 
@@ -103,3 +138,88 @@ func (s *ImmuServer) metricFuncComputeDBEntries() (nbEntriesPerDB map[string]flo
 
 	return
 }
+
+func (s *ImmuServer) metricFuncComputeDBCorruptedReads() (nbReadsPerDB map[string]float64) {
+	nbReadsPerDB = make(map[string]float64)
+
+	for i := 0; i < s.dbList.Length(); i++ {
+		db := s.dbList.GetByIndex(int64(i))
+		nbReadsPerDB[db.GetOptions().GetDbName()] = float64(db.CorruptedReads())
+	}
+
+	// add systemdb
+	nbReadsPerDB[s.sysDb.GetOptions().GetDbName()] = float64(s.sysDb.CorruptedReads())
+
+	return
+}
+
+// metricsTopPrefixes is the number of most read and most written prefixes exposed per database
+const metricsTopPrefixes = 10
+
+func (s *ImmuServer) metricFuncComputeDBPrefixStats() (prefixesPerDB map[string][]*schema.PrefixStats) {
+	prefixesPerDB = make(map[string][]*schema.PrefixStats)
+
+	for i := 0; i < s.dbList.Length(); i++ {
+		db := s.dbList.GetByIndex(int64(i))
+		dbName := db.GetOptions().GetDbName()
+
+		var prefixes []*schema.PrefixStats
+		seen := make(map[string]bool)
+
+		for _, by := range []string{database.PrefixStatsByReads, database.PrefixStatsByWrites} {
+			list, err := db.TopPrefixes(&schema.TopPrefixesRequest{By: by, Limit: metricsTopPrefixes})
+			if err == database.ErrPrefixStatsDisabled {
+				break
+			}
+			if err != nil {
+				s.Logger.Errorf("error updating prefix stats metrics for db %s: %v", dbName, err)
+				break
+			}
+
+			for _, p := range list.Prefixes {
+				if !seen[string(p.Prefix)] {
+					seen[string(p.Prefix)] = true
+					prefixes = append(prefixes, p)
+				}
+			}
+		}
+
+		if len(prefixes) > 0 {
+			prefixesPerDB[dbName] = prefixes
+		}
+	}
+
+	return
+}
+
+func (s *ImmuServer) metricFuncComputeDBIdempotencyStats() (statsPerDB map[string]*database.IdempotencyStats) {
+	statsPerDB = make(map[string]*database.IdempotencyStats)
+
+	for i := 0; i < s.dbList.Length(); i++ {
+		db := s.dbList.GetByIndex(int64(i))
+		statsPerDB[db.GetOptions().GetDbName()] = db.IdempotencyStats()
+	}
+
+	return
+}
+
+func (s *ImmuServer) metricFuncComputeDBTableStats() (tablesPerDB map[string][]*database.TableStats) {
+	tablesPerDB = make(map[string][]*database.TableStats)
+
+	for i := 0; i < s.dbList.Length(); i++ {
+		db := s.dbList.GetByIndex(int64(i))
+		dbName := db.GetOptions().GetDbName()
+
+		tables, err := db.TableStats()
+		if err != nil {
+			s.Logger.Errorf("error updating table stats metrics for db %s: %v", dbName, err)
+			continue
+		}
+
+		if len(tables) > 0 {
+			tablesPerDB[dbName] = tables
+		}
+	}
+
+	return
+}
@@ -18,6 +18,7 @@ package server
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
@@ -36,6 +37,11 @@ type dbMock struct {
 	currentStateF func() (*schema.ImmutableState, error)
 	getOptionsF   func() *database.DbOptions
 	getNameF      func() string
+
+	corruptedReads   uint64
+	idempotencyStats *database.IdempotencyStats
+	tableStatsF      func() ([]*database.TableStats, error)
+	topPrefixesF     func(req *schema.TopPrefixesRequest) (*schema.PrefixStatsList, error)
 }
 
 func (dbm dbMock) CurrentState() (*schema.ImmutableState, error) {
@@ -51,6 +57,31 @@ func (dbm dbMock) GetOptions() *database.DbOptions {
 	return database.DefaultOption()
 }
 
+func (dbm dbMock) CorruptedReads() uint64 {
+	return dbm.corruptedReads
+}
+
+func (dbm dbMock) TopPrefixes(req *schema.TopPrefixesRequest) (*schema.PrefixStatsList, error) {
+	if dbm.topPrefixesF != nil {
+		return dbm.topPrefixesF(req)
+	}
+	return nil, database.ErrPrefixStatsDisabled
+}
+
+func (dbm dbMock) IdempotencyStats() *database.IdempotencyStats {
+	if dbm.idempotencyStats != nil {
+		return dbm.idempotencyStats
+	}
+	return &database.IdempotencyStats{}
+}
+
+func (dbm dbMock) TableStats() ([]*database.TableStats, error) {
+	if dbm.tableStatsF != nil {
+		return dbm.tableStatsF()
+	}
+	return nil, nil
+}
+
 func (dbm dbMock) GetName() string {
 	if dbm.getNameF != nil {
 		return dbm.getNameF()
@@ -159,3 +190,167 @@ func TestMetricFuncComputeDBSizes(t *testing.T) {
 	s.Options.Dir = fmt.Sprintf("%d", time.Now().UnixNano())
 	s.metricFuncComputeDBSizes()
 }
+
+func TestMetricFuncComputeDBArchivedSizes(t *testing.T) {
+	archiveDir := "TestDBArchivedSizesData"
+	defaultDBName := "TestDBArchivedSizesDefaultDB"
+
+	require.NoError(t, os.MkdirAll(filepath.Join(archiveDir, defaultDBName, "val_0"), 0777))
+	defer os.RemoveAll(archiveDir)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(archiveDir, defaultDBName, "val_0", "00000000.val"), []byte{1, 2, 3}, 0666))
+
+	dbList := database.NewDatabaseList()
+	dbList.Append(dbMock{
+		getOptionsF: func() *database.DbOptions {
+			return database.DefaultOption().WithDbName(defaultDBName)
+		},
+	})
+
+	s := ImmuServer{
+		Options: &Options{
+			StoreOptions: DefaultStoreOptions(),
+		},
+		dbList: dbList,
+		sysDb: dbMock{
+			getOptionsF: func() *database.DbOptions {
+				return database.DefaultOption().WithDbName(SystemdbName)
+			},
+		},
+	}
+
+	require.Empty(t, s.metricFuncComputeDBArchivedSizes())
+
+	s.Options.StoreOptions.WithArchivePath(archiveDir)
+
+	dbSizes := s.metricFuncComputeDBArchivedSizes()
+	require.Equal(t, map[string]float64{defaultDBName: 3, SystemdbName: 0}, dbSizes)
+}
+
+func TestMetricFuncComputeDBCorruptedReads(t *testing.T) {
+	dbList := database.NewDatabaseList()
+	dbList.Append(dbMock{
+		getOptionsF: func() *database.DbOptions {
+			return database.DefaultOption().WithDbName("db1")
+		},
+		corruptedReads: 3,
+	})
+
+	s := ImmuServer{
+		dbList: dbList,
+		sysDb: dbMock{
+			getOptionsF: func() *database.DbOptions {
+				return database.DefaultOption().WithDbName(SystemdbName)
+			},
+		},
+	}
+
+	nbReads := s.metricFuncComputeDBCorruptedReads()
+	require.Equal(t, map[string]float64{"db1": 3, SystemdbName: 0}, nbReads)
+}
+
+func TestMetricFuncComputeDBPrefixStats(t *testing.T) {
+	dbList := database.NewDatabaseList()
+	dbList.Append(dbMock{
+		getOptionsF: func() *database.DbOptions {
+			return database.DefaultOption().WithDbName("db1")
+		},
+		topPrefixesF: func(req *schema.TopPrefixesRequest) (*schema.PrefixStatsList, error) {
+			if req.By == database.PrefixStatsByReads {
+				return &schema.PrefixStatsList{Prefixes: []*schema.PrefixStats{
+					{Prefix: []byte("a:"), Reads: 2},
+					{Prefix: []byte("b:"), Reads: 1, Writes: 3},
+				}}, nil
+			}
+			return &schema.PrefixStatsList{Prefixes: []*schema.PrefixStats{
+				{Prefix: []byte("b:"), Reads: 1, Writes: 3},
+				{Prefix: []byte("c:"), Writes: 1},
+			}}, nil
+		},
+	})
+	dbList.Append(dbMock{
+		getOptionsF: func() *database.DbOptions {
+			return database.DefaultOption().WithDbName("db2")
+		},
+	})
+	dbList.Append(dbMock{
+		getOptionsF: func() *database.DbOptions {
+			return database.DefaultOption().WithDbName("db3")
+		},
+		topPrefixesF: func(req *schema.TopPrefixesRequest) (*schema.PrefixStatsList, error) {
+			return nil, fmt.Errorf("some error")
+		},
+	})
+
+	s := ImmuServer{
+		dbList: dbList,
+		Logger: logger.NewSimpleLogger("immudb ", os.Stderr),
+	}
+
+	prefixes := s.metricFuncComputeDBPrefixStats()
+	require.Len(t, prefixes, 1)
+	require.Len(t, prefixes["db1"], 3)
+	require.Equal(t, []byte("a:"), prefixes["db1"][0].Prefix)
+	require.Equal(t, []byte("b:"), prefixes["db1"][1].Prefix)
+	require.Equal(t, []byte("c:"), prefixes["db1"][2].Prefix)
+}
+
+func TestMetricFuncComputeDBIdempotencyStats(t *testing.T) {
+	dbList := database.NewDatabaseList()
+	dbList.Append(dbMock{
+		getOptionsF: func() *database.DbOptions {
+			return database.DefaultOption().WithDbName("db1")
+		},
+		idempotencyStats: &database.IdempotencyStats{Keys: 2, Suppressed: 5},
+	})
+	dbList.Append(dbMock{
+		getOptionsF: func() *database.DbOptions {
+			return database.DefaultOption().WithDbName("db2")
+		},
+	})
+
+	s := ImmuServer{
+		dbList: dbList,
+	}
+
+	stats := s.metricFuncComputeDBIdempotencyStats()
+	require.Equal(t, map[string]*database.IdempotencyStats{
+		"db1": {Keys: 2, Suppressed: 5},
+		"db2": {},
+	}, stats)
+}
+
+func TestMetricFuncComputeDBTableStats(t *testing.T) {
+	dbList := database.NewDatabaseList()
+	dbList.Append(dbMock{
+		getOptionsF: func() *database.DbOptions {
+			return database.DefaultOption().WithDbName("db1")
+		},
+		tableStatsF: func() ([]*database.TableStats, error) {
+			return []*database.TableStats{{Table: "table1", Rows: 2, Bytes: 100, LastModifiedTx: 5}}, nil
+		},
+	})
+	dbList.Append(dbMock{
+		getOptionsF: func() *database.DbOptions {
+			return database.DefaultOption().WithDbName("db2")
+		},
+	})
+	dbList.Append(dbMock{
+		getOptionsF: func() *database.DbOptions {
+			return database.DefaultOption().WithDbName("db3")
+		},
+		tableStatsF: func() ([]*database.TableStats, error) {
+			return nil, fmt.Errorf("some error")
+		},
+	})
+
+	s := ImmuServer{
+		dbList: dbList,
+		Logger: &mockLogger{},
+	}
+
+	tables := s.metricFuncComputeDBTableStats()
+	require.Equal(t, map[string][]*database.TableStats{
+		"db1": {{Table: "table1", Rows: 2, Bytes: 100, LastModifiedTx: 5}},
+	}, tables)
+}
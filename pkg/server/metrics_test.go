@@ -22,7 +22,10 @@ import (
 	"net/http"
 	"testing"
 
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/database"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc/peer"
 )
@@ -34,6 +37,11 @@ func TestStartMetrics(t *testing.T) {
 		func() float64 { return 0 },
 		func() map[string]float64 { return make(map[string]float64) },
 		func() map[string]float64 { return make(map[string]float64) },
+		func() map[string]float64 { return make(map[string]float64) },
+		func() map[string]float64 { return make(map[string]float64) },
+		func() map[string][]*schema.PrefixStats { return make(map[string][]*schema.PrefixStats) },
+		func() map[string]*database.IdempotencyStats { return make(map[string]*database.IdempotencyStats) },
+		func() map[string][]*database.TableStats { return make(map[string][]*database.TableStats) },
 	)
 	defer server.Close()
 
@@ -90,6 +98,14 @@ func TestMetricsCollection_UpdateDBMetrics(t *testing.T) {
 			},
 			[]string{"db"},
 		),
+		DBArchivedSizeGauges: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: metricsNamespace,
+				Name:      "db_archived_size_bytes",
+				Help:      "Size in bytes of the database data moved into the archive location.",
+			},
+			[]string{"db"},
+		),
 		DBEntriesGauges: prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace: metricsNamespace,
@@ -98,6 +114,42 @@ func TestMetricsCollection_UpdateDBMetrics(t *testing.T) {
 			},
 			[]string{"db"},
 		),
+		DBCorruptedReadsGauges: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: metricsNamespace,
+				Name:      "number_of_corrupted_reads",
+				Help:      "Number of reads aborted because corrupted data was detected since the database was opened.",
+			},
+			[]string{"db"},
+		),
+		DBPrefixOpsGauges: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: metricsNamespace,
+				Name:      "number_of_prefix_operations",
+			},
+			[]string{"db", "prefix", "op"},
+		),
+		DBPrefixBytesGauges: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: metricsNamespace,
+				Name:      "prefix_operations_bytes",
+			},
+			[]string{"db", "prefix", "op"},
+		),
+		DBIdempotencyKeysGauges: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: metricsNamespace,
+				Name:      "number_of_idempotency_keys",
+			},
+			[]string{"db"},
+		),
+		DBIdempotencySuppressedCounters: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: metricsNamespace,
+				Name:      "suppressed_duplicate_writes_total",
+			},
+			[]string{"db"},
+		),
 	}
 
 	// update before injecting the funcs, to catch the fast-exit execution path
@@ -106,12 +158,57 @@ func TestMetricsCollection_UpdateDBMetrics(t *testing.T) {
 	mc.computeDBSizes = func() map[string]float64 {
 		return map[string]float64{"db1": 111, "db2": 222}
 	}
+	mc.computeDBArchivedSizes = func() map[string]float64 {
+		return map[string]float64{"db1": 11, "db2": 0}
+	}
 	mc.computeDBEntries = func() map[string]float64 {
 		return map[string]float64{"db1": 10, "db2": 20}
 	}
+	mc.computeDBCorruptedReads = func() map[string]float64 {
+		return map[string]float64{"db1": 0, "db2": 1}
+	}
+	mc.computeDBPrefixStats = func() map[string][]*schema.PrefixStats {
+		return map[string][]*schema.PrefixStats{"db1": {{Prefix: []byte("tenant1:"), Reads: 2, Writes: 1}}}
+	}
+	mc.computeDBIdempotencyStats = func() map[string]*database.IdempotencyStats {
+		return map[string]*database.IdempotencyStats{"db1": {Keys: 3, Suppressed: 1}}
+	}
 
 	// update after injecting the funcs, to catch the normal execution path
 	mc.UpdateDBMetrics()
 
 	assert.IsType(t, MetricsCollection{}, mc)
 }
+
+func TestMetricsCollection_IdempotencySuppressedCounter(t *testing.T) {
+	var suppressed uint64
+
+	mc := MetricsCollection{
+		computeDBIdempotencyStats: func() map[string]*database.IdempotencyStats {
+			return map[string]*database.IdempotencyStats{"db1": {Suppressed: suppressed}}
+		},
+		DBIdempotencyKeysGauges: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "number_of_idempotency_keys"},
+			[]string{"db"},
+		),
+		DBIdempotencySuppressedCounters: prometheus.NewCounterVec(
+			prometheus.CounterOpts{Name: "suppressed_duplicate_writes_total"},
+			[]string{"db"},
+		),
+	}
+
+	counter := mc.DBIdempotencySuppressedCounters.WithLabelValues("db1")
+
+	suppressed = 2
+	mc.UpdateDBMetrics()
+	assert.Equal(t, float64(2), testutil.ToFloat64(counter))
+
+	suppressed = 5
+	mc.UpdateDBMetrics()
+	assert.Equal(t, float64(5), testutil.ToFloat64(counter))
+
+	// the database was reopened, the counter keeps increasing
+	suppressed = 1
+	mc.UpdateDBMetrics()
+	assert.Equal(t, float64(6), testutil.ToFloat64(counter))
+}
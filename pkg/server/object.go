@@ -0,0 +1,53 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+)
+
+// PutObject writes a new version of an object, along with its content type and metadata
+func (s *ImmuServer) PutObject(ctx context.Context, req *schema.PutObjectRequest) (*schema.ObjectVersion, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "PutObject")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.dbList.GetByIndex(ind).PutObject(req)
+}
+
+// GetObject returns the latest or the requested version of an object
+func (s *ImmuServer) GetObject(ctx context.Context, req *schema.GetObjectRequest) (*schema.Object, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "GetObject")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.dbList.GetByIndex(ind).GetObject(req)
+}
+
+// ListObjectVersions lists the versions of the objects under a prefix
+func (s *ImmuServer) ListObjectVersions(ctx context.Context, req *schema.ListObjectVersionsRequest) (*schema.ObjectVersions, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "ListObjectVersions")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.dbList.GetByIndex(ind).ListObjectVersions(req)
+}
@@ -0,0 +1,396 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/auth"
+	"github.com/golang/protobuf/ptypes/empty"
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"github.com/rs/xid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// ErrOperationNotFound is returned when cancelling an operation which is not running
+var ErrOperationNotFound = status.Error(codes.NotFound, "operation not found")
+
+// OperationKindRPC is the kind of the operations serving rpc calls, scheduled jobs are listed by their kind
+const OperationKindRPC = "rpc"
+
+// OperationKindSession is the kind of the entries listing active sessions, their method is the last
+// rpc called, their start time is the login time and their elapsed time is the time since the last activity
+const OperationKindSession = "session"
+
+// maxSessions bounds the number of tracked sessions, the least recently active ones are dropped first
+const maxSessions = 10_000
+
+// operation is a running rpc call or scheduled job
+type operation struct {
+	id        string
+	kind      string
+	method    string
+	database  string
+	client    string
+	startedAt time.Time
+	ctx       context.Context
+	cancel    context.CancelFunc
+	cancelled bool
+}
+
+// session is a token in use, identified by a stable id so the token itself is never listed
+type session struct {
+	id           string
+	token        string
+	method       string
+	client       string
+	loggedInAt   time.Time
+	lastActivity time.Time
+	user         *auth.JSONToken
+}
+
+// operations keeps track of the rpc calls currently being served, of the scheduled jobs being run
+// and of the sessions which have been active since the server started
+type operations struct {
+	running  map[string]*operation
+	sessions map[string]*session
+	sync.RWMutex
+}
+
+func newOperations() *operations {
+	return &operations{
+		running:  make(map[string]*operation),
+		sessions: make(map[string]*session),
+	}
+}
+
+func (o *operations) begin(ctx context.Context, fullMethod string) (*operation, context.Context) {
+	op, ctx := o.start(ctx, OperationKindRPC, fullMethod[strings.LastIndex(fullMethod, "/")+1:], "")
+
+	if p, ok := peer.FromContext(ctx); ok && p != nil {
+		op.client = p.Addr.String()
+	}
+
+	o.register(op)
+
+	if token := tokenFromCtx(ctx); token != "" {
+		o.touch(token, op.method, op.client)
+	}
+
+	return op, ctx
+}
+
+// tokenFromCtx returns the raw authorization token of the call, it is verified only when sessions are listed
+func tokenFromCtx(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok || len(md["authorization"]) == 0 {
+		return ""
+	}
+
+	return strings.TrimPrefix(md["authorization"][0], "Bearer ")
+}
+
+// touch records activity on the session of the given token, starting it if it was not tracked yet
+func (o *operations) touch(token, method, client string) {
+	now := time.Now()
+
+	o.Lock()
+	defer o.Unlock()
+
+	sess, ok := o.sessions[token]
+	if !ok {
+		if len(o.sessions) >= maxSessions {
+			o.evictIdlestSession()
+		}
+
+		sess = &session{id: xid.New().String(), token: token, loggedInAt: now}
+		o.sessions[token] = sess
+	}
+
+	sess.method = method
+	sess.lastActivity = now
+
+	if client != "" {
+		sess.client = client
+	}
+}
+
+// rotate moves a session to the token issued when its user switched database, keeping its id and login time
+func (o *operations) rotate(oldToken, newToken string) {
+	o.Lock()
+	defer o.Unlock()
+
+	sess, ok := o.sessions[oldToken]
+	if !ok {
+		return
+	}
+
+	delete(o.sessions, oldToken)
+
+	sess.token = newToken
+	o.sessions[newToken] = sess
+}
+
+func (o *operations) evictIdlestSession() {
+	var idlest *session
+
+	for _, sess := range o.sessions {
+		if idlest == nil || sess.lastActivity.Before(idlest.lastActivity) {
+			idlest = sess
+		}
+	}
+
+	if idlest != nil {
+		delete(o.sessions, idlest.token)
+	}
+}
+
+// listSessions returns copies of the tracked sessions, dropping those whose token is no longer valid,
+// either because it expired or because its user logged out
+func (o *operations) listSessions() []session {
+	o.Lock()
+	defer o.Unlock()
+
+	sessions := make([]session, 0, len(o.sessions))
+
+	for token, sess := range o.sessions {
+		md := metadata.Pairs("authorization", token)
+
+		jsUser, err := auth.GetLoggedInUser(metadata.NewIncomingContext(context.Background(), md))
+		if err != nil {
+			delete(o.sessions, token)
+			continue
+		}
+
+		listed := *sess
+		listed.user = jsUser
+
+		sessions = append(sessions, listed)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].loggedInAt.Before(sessions[j].loggedInAt)
+	})
+
+	return sessions
+}
+
+// beginJob registers a scheduled job run on the given database
+func (o *operations) beginJob(ctx context.Context, kind, jobName, database string) (*operation, context.Context) {
+	op, ctx := o.start(ctx, kind, jobName, database)
+
+	o.register(op)
+
+	return op, ctx
+}
+
+func (o *operations) start(ctx context.Context, kind, method, database string) (*operation, context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	return &operation{
+		id:        xid.New().String(),
+		kind:      kind,
+		method:    method,
+		database:  database,
+		startedAt: time.Now(),
+		ctx:       ctx,
+		cancel:    cancel,
+	}, ctx
+}
+
+func (o *operations) register(op *operation) {
+	o.Lock()
+	o.running[op.id] = op
+	o.Unlock()
+}
+
+func (o *operations) end(op *operation) {
+	o.Lock()
+	delete(o.running, op.id)
+	o.Unlock()
+
+	op.cancel()
+}
+
+// list returns copies of the running operations, taken while holding the lock as cancel updates them
+func (o *operations) list() []operation {
+	o.RLock()
+	defer o.RUnlock()
+
+	ops := make([]operation, 0, len(o.running))
+	for _, op := range o.running {
+		ops = append(ops, *op)
+	}
+
+	sort.Slice(ops, func(i, j int) bool {
+		return ops[i].startedAt.Before(ops[j].startedAt)
+	})
+
+	return ops
+}
+
+func (o *operations) cancel(id string) error {
+	o.Lock()
+	defer o.Unlock()
+
+	op, ok := o.running[id]
+	if !ok {
+		return ErrOperationNotFound
+	}
+
+	op.cancelled = true
+	op.cancel()
+
+	return nil
+}
+
+// OperationsInterceptor registers unary calls as running operations
+func (o *operations) OperationsInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	op, ctx := o.begin(ctx, info.FullMethod)
+	defer o.end(op)
+
+	res, err := handler(ctx, req)
+	if err != nil {
+		return res, err
+	}
+
+	switch r := res.(type) {
+	case *schema.LoginResponse:
+		o.touch(r.Token, op.method, op.client)
+	case *schema.UseDatabaseReply:
+		o.rotate(tokenFromCtx(ctx), r.Token)
+	}
+
+	return res, err
+}
+
+// OperationsStreamInterceptor registers streaming calls as running operations
+func (o *operations) OperationsStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	op, ctx := o.begin(ss.Context(), info.FullMethod)
+	defer o.end(op)
+
+	wss := grpc_middleware.WrapServerStream(ss)
+	wss.WrappedContext = ctx
+
+	return handler(srv, wss)
+}
+
+// ListOperations returns the operations currently being served followed by the active sessions
+func (s *ImmuServer) ListOperations(ctx context.Context, _ *empty.Empty) (*schema.OperationList, error) {
+	if err := s.checkSysAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	list := &schema.OperationList{}
+
+	for _, op := range s.operations.list() {
+		o := &schema.Operation{
+			Id:        op.id,
+			Kind:      op.kind,
+			Method:    op.method,
+			Database:  op.database,
+			Client:    op.client,
+			StartedAt: op.startedAt.Unix(),
+			Elapsed:   time.Since(op.startedAt).Milliseconds(),
+			Cancelled: op.cancelled,
+		}
+
+		if op.kind != OperationKindRPC {
+			list.Operations = append(list.Operations, o)
+			continue
+		}
+
+		if jsUser, err := auth.GetLoggedInUser(op.ctx); err == nil {
+			o.User = jsUser.Username
+			o.Database = s.databaseNameOf(jsUser)
+		}
+
+		list.Operations = append(list.Operations, o)
+	}
+
+	for _, sess := range s.operations.listSessions() {
+		o := &schema.Operation{
+			Id:        sess.id,
+			Kind:      OperationKindSession,
+			Method:    sess.method,
+			Database:  s.databaseNameOf(sess.user),
+			Client:    sess.client,
+			StartedAt: sess.loggedInAt.Unix(),
+			Elapsed:   time.Since(sess.lastActivity).Milliseconds(),
+			User:      sess.user.Username,
+		}
+
+		list.Operations = append(list.Operations, o)
+	}
+
+	return list, nil
+}
+
+// databaseNameOf returns the name of the database selected by the token, empty if none was selected yet
+func (s *ImmuServer) databaseNameOf(jsUser *auth.JSONToken) string {
+	if jsUser.DatabaseIndex < 0 || jsUser.DatabaseIndex >= int64(s.dbList.Length()) {
+		return ""
+	}
+
+	return s.dbList.GetByIndex(jsUser.DatabaseIndex).GetName()
+}
+
+// CancelOperation cancels the context of a running operation
+func (s *ImmuServer) CancelOperation(ctx context.Context, req *schema.OperationRequest) (*empty.Empty, error) {
+	if req == nil {
+		return nil, ErrIllegalArguments
+	}
+
+	if err := s.checkSysAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	s.Logger.Infof("cancelling operation %s", req.Id)
+
+	return new(empty.Empty), s.operations.cancel(req.Id)
+}
+
+func (s *ImmuServer) checkSysAdmin(ctx context.Context) error {
+	if s.Options.GetMaintenance() {
+		return nil
+	}
+
+	if !s.Options.GetAuth() {
+		return fmt.Errorf("this command is available only with authentication on")
+	}
+
+	_, user, err := s.getLoggedInUserdataFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("could not get loggedin user data")
+	}
+
+	if !user.IsSysAdmin {
+		return fmt.Errorf("Logged In user does not have permissions for this operation")
+	}
+
+	return nil
+}
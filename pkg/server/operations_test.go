@@ -0,0 +1,228 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/auth"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestServerOperations(t *testing.T) {
+	serverOptions := DefaultOptions().
+		WithMetricsServer(false).
+		WithAdminPassword(auth.SysAdminPassword).
+		WithListener(bufconn.Listen(1024 * 1024))
+	s := DefaultServer().WithOptions(serverOptions).(*ImmuServer)
+	defer os.RemoveAll(s.Options.Dir)
+
+	require.NoError(t, s.Initialize())
+
+	lr, err := s.Login(context.Background(), &schema.LoginRequest{
+		User:     []byte(auth.SysAdminUsername),
+		Password: []byte(auth.SysAdminPassword),
+	})
+	require.NoError(t, err)
+
+	md := metadata.Pairs("authorization", lr.Token)
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+
+	_, err = s.CancelOperation(ctx, nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = s.CancelOperation(ctx, &schema.OperationRequest{Id: "unknown"})
+	require.Equal(t, ErrOperationNotFound, err)
+
+	started := make(chan struct{})
+	done := make(chan error)
+
+	go func() {
+		info := &grpc.UnaryServerInfo{FullMethod: "/immudb.schema.ImmuService/Scan"}
+
+		_, err := s.operations.OperationsInterceptor(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			close(started)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+
+		done <- err
+	}()
+
+	<-started
+
+	ops, err := s.ListOperations(ctx, &empty.Empty{})
+	require.NoError(t, err)
+	ops.Operations = runningOperations(ops.Operations)
+	require.Len(t, ops.Operations, 1)
+	require.Equal(t, OperationKindRPC, ops.Operations[0].Kind)
+	require.Equal(t, "Scan", ops.Operations[0].Method)
+	require.Equal(t, auth.SysAdminUsername, ops.Operations[0].User)
+	require.Equal(t, DefaultdbName, ops.Operations[0].Database)
+	require.False(t, ops.Operations[0].Cancelled)
+
+	_, err = s.CancelOperation(ctx, &schema.OperationRequest{Id: ops.Operations[0].Id})
+	require.NoError(t, err)
+
+	require.Equal(t, context.Canceled, <-done)
+
+	ops, err = s.ListOperations(ctx, &empty.Empty{})
+	require.NoError(t, err)
+	require.Empty(t, runningOperations(ops.Operations))
+
+	op, jobCtx := s.operations.beginJob(context.Background(), JobCompaction, "nightly", DefaultdbName)
+
+	ops, err = s.ListOperations(ctx, &empty.Empty{})
+	require.NoError(t, err)
+	ops.Operations = runningOperations(ops.Operations)
+	require.Len(t, ops.Operations, 1)
+	require.Equal(t, JobCompaction, ops.Operations[0].Kind)
+	require.Equal(t, "nightly", ops.Operations[0].Method)
+	require.Equal(t, DefaultdbName, ops.Operations[0].Database)
+	require.Empty(t, ops.Operations[0].User)
+
+	_, err = s.CancelOperation(ctx, &schema.OperationRequest{Id: ops.Operations[0].Id})
+	require.NoError(t, err)
+	require.Equal(t, context.Canceled, jobCtx.Err())
+
+	s.operations.end(op)
+
+	ops, err = s.ListOperations(ctx, &empty.Empty{})
+	require.NoError(t, err)
+	require.Empty(t, runningOperations(ops.Operations))
+}
+
+func TestServerOperationsPermissions(t *testing.T) {
+	serverOptions := DefaultOptions().
+		WithMetricsServer(false).
+		WithAdminPassword(auth.SysAdminPassword).
+		WithListener(bufconn.Listen(1024 * 1024))
+	s := DefaultServer().WithOptions(serverOptions).(*ImmuServer)
+	defer os.RemoveAll(s.Options.Dir)
+
+	err := s.Initialize()
+	require.NoError(t, err)
+
+	_, err = s.ListOperations(context.Background(), &empty.Empty{})
+	require.Error(t, err)
+
+	_, err = s.CancelOperation(context.Background(), &schema.OperationRequest{Id: "unknown"})
+	require.Error(t, err)
+}
+
+func TestServerSessions(t *testing.T) {
+	serverOptions := DefaultOptions().
+		WithMetricsServer(false).
+		WithAdminPassword(auth.SysAdminPassword).
+		WithListener(bufconn.Listen(1024 * 1024))
+	s := DefaultServer().WithOptions(serverOptions).(*ImmuServer)
+	defer os.RemoveAll(s.Options.Dir)
+
+	require.NoError(t, s.Initialize())
+
+	call := func(ctx context.Context, method string, handler grpc.UnaryHandler) (interface{}, error) {
+		info := &grpc.UnaryServerInfo{FullMethod: "/immudb.schema.ImmuService/" + method}
+		return s.operations.OperationsInterceptor(ctx, nil, info, handler)
+	}
+
+	res, err := call(context.Background(), "Login", func(ctx context.Context, req interface{}) (interface{}, error) {
+		return s.Login(ctx, &schema.LoginRequest{
+			User:     []byte(auth.SysAdminUsername),
+			Password: []byte(auth.SysAdminPassword),
+		})
+	})
+	require.NoError(t, err)
+
+	loginToken := res.(*schema.LoginResponse).Token
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", loginToken))
+
+	ops, err := s.ListOperations(ctx, &empty.Empty{})
+	require.NoError(t, err)
+	require.Len(t, ops.Operations, 1)
+	require.Equal(t, OperationKindSession, ops.Operations[0].Kind)
+	require.Equal(t, "Login", ops.Operations[0].Method)
+	require.Equal(t, auth.SysAdminUsername, ops.Operations[0].User)
+
+	sessionID := ops.Operations[0].Id
+	loggedInAt := ops.Operations[0].StartedAt
+
+	res, err = call(ctx, "UseDatabase", func(ctx context.Context, req interface{}) (interface{}, error) {
+		return s.UseDatabase(ctx, &schema.Database{DatabaseName: DefaultdbName})
+	})
+	require.NoError(t, err)
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", res.(*schema.UseDatabaseReply).Token))
+
+	_, err = call(ctx, "CurrentState", func(ctx context.Context, req interface{}) (interface{}, error) {
+		return s.CurrentState(ctx, &empty.Empty{})
+	})
+	require.NoError(t, err)
+
+	ops, err = s.ListOperations(ctx, &empty.Empty{})
+	require.NoError(t, err)
+	require.Len(t, ops.Operations, 1)
+	require.Equal(t, sessionID, ops.Operations[0].Id)
+	require.Equal(t, OperationKindSession, ops.Operations[0].Kind)
+	require.Equal(t, "CurrentState", ops.Operations[0].Method)
+	require.Equal(t, auth.SysAdminUsername, ops.Operations[0].User)
+	require.Equal(t, DefaultdbName, ops.Operations[0].Database)
+	require.Equal(t, loggedInAt, ops.Operations[0].StartedAt)
+	require.GreaterOrEqual(t, ops.Operations[0].Elapsed, int64(0))
+
+	_, err = call(ctx, "Logout", func(ctx context.Context, req interface{}) (interface{}, error) {
+		return s.Logout(ctx, &empty.Empty{})
+	})
+	require.NoError(t, err)
+
+	require.Empty(t, s.operations.listSessions())
+}
+
+func TestServerSessionsEviction(t *testing.T) {
+	o := newOperations()
+
+	for i := 0; i < maxSessions; i++ {
+		o.touch(fmt.Sprintf("token%d", i), "CurrentState", "")
+	}
+
+	o.touch("token0", "Set", "")
+	o.touch("newToken", "Login", "")
+
+	require.Len(t, o.sessions, maxSessions)
+	require.Contains(t, o.sessions, "token0")
+	require.Contains(t, o.sessions, "newToken")
+	require.NotContains(t, o.sessions, "token1")
+}
+
+func runningOperations(ops []*schema.Operation) []*schema.Operation {
+	var running []*schema.Operation
+
+	for _, op := range ops {
+		if op.Kind != OperationKindSession {
+			running = append(running, op)
+		}
+	}
+
+	return running
+}
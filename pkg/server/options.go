@@ -22,16 +22,36 @@ import (
 	"net"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/codenotary/immudb/pkg/stream"
 
 	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
 	"github.com/codenotary/immudb/pkg/auth"
+	"github.com/codenotary/immudb/pkg/database"
+	"github.com/codenotary/immudb/pkg/replication"
 )
 
 const SystemdbName = "systemdb"
 const DefaultdbName = "defaultdb"
 
+// gRPC keepalive defaults
+const (
+	DefaultKeepaliveTime    = 2 * time.Hour
+	DefaultKeepaliveTimeout = 20 * time.Second
+	DefaultKeepaliveMinTime = 5 * time.Minute
+)
+
+// Traffic the stream bandwidth limit applies to
+const (
+	StreamBandwidthScopeReplication = "replication"
+	StreamBandwidthScopeAll         = "all"
+)
+
+// DefaultBatchOpTimeout is the timeout of batch calls arriving while the server is under load
+const DefaultBatchOpTimeout = 30 * time.Second
+
 // Options server options list
 type Options struct {
 	Dir                 string
@@ -60,9 +80,52 @@ type Options struct {
 	SigningKey          string
 	StoreOptions        *store.Options
 	StreamChunkSize     int
+	StreamBandwidth     int
+	StreamThrottling    []stream.ThrottleWindow
+	Attribution         bool
 	TokenExpiryTimeMin  int
 	PgsqlServer         bool
 	PgsqlServerPort     int
+	GRPCReflection      bool
+	ReplicationOptions  *replication.Options
+
+	StreamBandwidthScope string
+
+	ReceiptVerificationURL string
+
+	PublicVerification          bool
+	PublicVerificationRateLimit int
+
+	PrefixStatsDepth     int
+	PrefixStatsSeparator byte
+
+	SQLTxTimeout time.Duration
+
+	IteratorLeaseTimeout time.Duration
+
+	IdempotencyWindow    time.Duration
+	IdempotencyMaxKeys   int
+	IdempotencyPersisted bool
+
+	SQLCacheSize int
+
+	JobSchedules []*schema.JobSchedule
+
+	KeepaliveTime                time.Duration
+	KeepaliveTimeout             time.Duration
+	KeepaliveMinTime             time.Duration
+	KeepalivePermitWithoutStream bool
+	MaxConnectionIdle            time.Duration
+	MaxConnectionAge             time.Duration
+	MaxConnectionAgeGrace        time.Duration
+	MaxConcurrentStreams         uint32
+
+	MaxConcurrentOps int
+	BatchOpTimeout   time.Duration
+
+	AlertOptions *AlertOptions
+
+	SyslogOptions *SyslogOptions
 }
 
 // DefaultOptions returns default server options
@@ -95,6 +158,28 @@ func DefaultOptions() *Options {
 		TokenExpiryTimeMin:  1440,
 		PgsqlServer:         false,
 		PgsqlServerPort:     5432,
+		GRPCReflection:      true,
+
+		StreamBandwidthScope: StreamBandwidthScopeReplication,
+
+		PublicVerificationRateLimit: 10,
+
+		PrefixStatsSeparator: database.DefaultPrefixStatsSeparator,
+
+		SQLTxTimeout: database.DefaultSQLTxTimeout,
+
+		IteratorLeaseTimeout: database.DefaultIteratorLeaseTimeout,
+
+		IdempotencyWindow:  database.DefaultIdempotencyWindow,
+		IdempotencyMaxKeys: database.DefaultIdempotencyMaxKeys,
+
+		SQLCacheSize: database.DefaultSQLCacheSize,
+
+		KeepaliveTime:    DefaultKeepaliveTime,
+		KeepaliveTimeout: DefaultKeepaliveTimeout,
+		KeepaliveMinTime: DefaultKeepaliveMinTime,
+
+		BatchOpTimeout: DefaultBatchOpTimeout,
 	}
 }
 
@@ -230,11 +315,76 @@ func (o *Options) String() string {
 		opts = append(opts, rightPad("Log file", o.Logfile))
 	}
 	opts = append(opts, rightPad("Max recv msg size", o.MaxRecvMsgSize))
+	if o.StreamBandwidth > 0 {
+		opts = append(opts, rightPad("Stream bandwidth", fmt.Sprintf("%d bytes/sec (%s)", o.StreamBandwidth, o.StreamBandwidthScope)))
+	}
 	opts = append(opts, rightPad("Auth enabled", o.auth))
+	if o.Attribution {
+		opts = append(opts, rightPad("Attribution", o.Attribution))
+	}
+	if o.PrefixStatsDepth > 0 {
+		opts = append(opts, rightPad("Prefix stats", fmt.Sprintf("depth %d, separator '%c'", o.PrefixStatsDepth, o.PrefixStatsSeparator)))
+	}
+	if o.SQLTxTimeout != database.DefaultSQLTxTimeout {
+		opts = append(opts, rightPad("SQL tx timeout", o.SQLTxTimeout))
+	}
+	if o.IteratorLeaseTimeout != database.DefaultIteratorLeaseTimeout {
+		opts = append(opts, rightPad("Iterator lease timeout", o.IteratorLeaseTimeout))
+	}
+	if o.IdempotencyWindow != database.DefaultIdempotencyWindow || o.IdempotencyMaxKeys != database.DefaultIdempotencyMaxKeys || o.IdempotencyPersisted {
+		opts = append(opts, rightPad("Idempotency", fmt.Sprintf("window %s, max keys %d, persisted %v", o.IdempotencyWindow, o.IdempotencyMaxKeys, o.IdempotencyPersisted)))
+	}
+	if o.SQLCacheSize != database.DefaultSQLCacheSize {
+		opts = append(opts, rightPad("SQL cache size", o.SQLCacheSize))
+	}
+	if o.KeepaliveTime != DefaultKeepaliveTime || o.KeepaliveTimeout != DefaultKeepaliveTimeout ||
+		o.KeepaliveMinTime != DefaultKeepaliveMinTime || o.KeepalivePermitWithoutStream {
+		opts = append(opts, rightPad("Keepalive", fmt.Sprintf("time %s, timeout %s, min time %s, permit without stream %v",
+			o.KeepaliveTime, o.KeepaliveTimeout, o.KeepaliveMinTime, o.KeepalivePermitWithoutStream)))
+	}
+	if o.MaxConnectionIdle > 0 {
+		opts = append(opts, rightPad("Max conn idle", o.MaxConnectionIdle))
+	}
+	if o.MaxConnectionAge > 0 {
+		opts = append(opts, rightPad("Max conn age", fmt.Sprintf("%s, grace %s", o.MaxConnectionAge, o.MaxConnectionAgeGrace)))
+	}
+	if o.MaxConcurrentStreams > 0 {
+		opts = append(opts, rightPad("Max streams", o.MaxConcurrentStreams))
+	}
+	if o.MaxConcurrentOps > 0 {
+		opts = append(opts, rightPad("Max concurrent ops", fmt.Sprintf("%d, batch timeout %s", o.MaxConcurrentOps, o.BatchOpTimeout)))
+	}
+	for _, js := range o.JobSchedules {
+		opts = append(opts, rightPad("Scheduled job", fmt.Sprintf("%s (%s) at '%s'", js.Name, js.Kind, js.Cron)))
+	}
+	if o.ReplicationOptions != nil {
+		opts = append(opts, rightPad("Replica of", fmt.Sprintf("%s/%s", o.ReplicationOptions.MasterBind(), o.ReplicationOptions.MasterDatabase)))
+	}
+	if o.AlertOptions != nil {
+		opts = append(opts, rightPad("Alert checks", fmt.Sprintf("every %s", o.AlertOptions.CheckInterval)))
+	}
+	if o.SyslogOptions != nil {
+		opts = append(opts, rightPad("Syslog listener", fmt.Sprintf("%s (%s) to %s", o.SyslogOptions.Address, o.SyslogOptions.Network, o.SyslogOptions.Database)))
+	}
 	opts = append(opts, rightPad("Dev mode", o.DevMode))
 	opts = append(opts, rightPad("Default database", o.defaultDbName))
 	opts = append(opts, rightPad("Maintenance mode", o.maintenance))
 	opts = append(opts, rightPad("Synced mode", o.StoreOptions.Synced))
+	if o.StoreOptions.ParanoidReads {
+		opts = append(opts, rightPad("Paranoid reads", o.StoreOptions.ParanoidReads))
+	}
+	if o.StoreOptions.KeyFilterCapacity > 0 {
+		opts = append(opts, rightPad("Key filter", fmt.Sprintf("%d keys", o.StoreOptions.KeyFilterCapacity)))
+	}
+	if o.StoreOptions.IndexOpts != nil && o.StoreOptions.IndexOpts.WarmUp {
+		opts = append(opts, rightPad("Index warm-up", o.StoreOptions.IndexOpts.WarmUp))
+	}
+	if o.StoreOptions.ArchivePath != "" {
+		opts = append(opts, rightPad("Archive dir", o.StoreOptions.ArchivePath))
+		if o.StoreOptions.ArchiveAfter > 0 {
+			opts = append(opts, rightPad("Archive after", o.StoreOptions.ArchiveAfter))
+		}
+	}
 	opts = append(opts, "----------------------------------------")
 	opts = append(opts, "Superadmin default credentials")
 	opts = append(opts, rightPad("   Username", auth.SysAdminUsername))
@@ -315,6 +465,51 @@ func (o *Options) WithStreamChunkSize(streamChunkSize int) *Options {
 	return o
 }
 
+// WithStreamBandwidth limits the bytes per second sent within the scope set with WithStreamBandwidthScope. Zero means unlimited
+func (o *Options) WithStreamBandwidth(bytesPerSec int) *Options {
+	o.StreamBandwidth = bytesPerSec
+	return o
+}
+
+// WithStreamBandwidthScope sets the traffic the stream bandwidth limit applies to, either StreamBandwidthScopeReplication
+// (transactions exported to replicas) or StreamBandwidthScopeAll (replication and every outgoing stream)
+func (o *Options) WithStreamBandwidthScope(scope string) *Options {
+	o.StreamBandwidthScope = scope
+	return o
+}
+
+// WithStreamThrottling restricts stream bandwidth limitation to the given daily windows
+func (o *Options) WithStreamThrottling(windows []stream.ThrottleWindow) *Options {
+	o.StreamThrottling = windows
+	return o
+}
+
+// WithReplicationOptions makes the default database a read-only replica of a database in a remote master server.
+// Replication is disabled when nil
+func (o *Options) WithReplicationOptions(replicationOptions *replication.Options) *Options {
+	o.ReplicationOptions = replicationOptions
+	return o
+}
+
+// WithAlertOptions enables the built-in threshold checks, notifying their alerts through a webhook or by email.
+// Alerts are disabled when nil
+func (o *Options) WithAlertOptions(alertOptions *AlertOptions) *Options {
+	o.AlertOptions = alertOptions
+	return o
+}
+
+// WithSyslogOptions enables the listener writing syslog and CEF messages to a database, it's disabled when nil
+func (o *Options) WithSyslogOptions(syslogOptions *SyslogOptions) *Options {
+	o.SyslogOptions = syslogOptions
+	return o
+}
+
+// WithAttribution records the user performing each write, and the actor provided by the client, as part of the transaction
+func (o *Options) WithAttribution(attribution bool) *Options {
+	o.Attribution = attribution
+	return o
+}
+
 // WithTokenExpiryTime set authentication token expiration time in minutes
 func (o *Options) WithTokenExpiryTime(tokenExpiryTimeMin int) *Options {
 	o.TokenExpiryTimeMin = tokenExpiryTimeMin
@@ -332,3 +527,145 @@ func (o *Options) WithPgsqlServerPort(port int) *Options {
 	o.PgsqlServerPort = port
 	return o
 }
+
+// WithGRPCReflection enables or disables the gRPC reflection service, exposing the protobuf descriptors of the API
+func (o *Options) WithGRPCReflection(enable bool) *Options {
+	o.GRPCReflection = enable
+	return o
+}
+
+// WithReceiptVerificationURL sets the base of the links notarization receipts are verified at, receipts have no link when empty
+func (o *Options) WithReceiptVerificationURL(url string) *Options {
+	o.ReceiptVerificationURL = url
+	return o
+}
+
+// WithPublicVerification enables the unauthenticated endpoint of the web server proving the inclusion of entries
+func (o *Options) WithPublicVerification(enable bool) *Options {
+	o.PublicVerification = enable
+	return o
+}
+
+// WithPublicVerificationRateLimit sets the requests per second each client can send to the public verification endpoint (0 means unlimited)
+func (o *Options) WithPublicVerificationRateLimit(rate int) *Options {
+	o.PublicVerificationRateLimit = rate
+	return o
+}
+
+// WithPrefixStatsDepth enables per-prefix operation statistics, grouping keys by their first depth segments
+func (o *Options) WithPrefixStatsDepth(depth int) *Options {
+	o.PrefixStatsDepth = depth
+	return o
+}
+
+// WithPrefixStatsSeparator sets the byte delimiting key segments for per-prefix statistics
+func (o *Options) WithPrefixStatsSeparator(separator byte) *Options {
+	o.PrefixStatsSeparator = separator
+	return o
+}
+
+// WithSQLTxTimeout sets how long interactive SQL transactions can stay idle before being aborted, zero disables the timeout
+func (o *Options) WithSQLTxTimeout(timeout time.Duration) *Options {
+	o.SQLTxTimeout = timeout
+	return o
+}
+
+// WithIteratorLeaseTimeout sets how long iterator leases are kept without receiving any request, zero disables the timeout
+func (o *Options) WithIteratorLeaseTimeout(timeout time.Duration) *Options {
+	o.IteratorLeaseTimeout = timeout
+	return o
+}
+
+// WithIdempotencyWindow sets how long writes are remembered by their idempotency key, zero disables deduplication
+func (o *Options) WithIdempotencyWindow(window time.Duration) *Options {
+	o.IdempotencyWindow = window
+	return o
+}
+
+// WithIdempotencyMaxKeys sets the maximum number of idempotency keys remembered by each database
+func (o *Options) WithIdempotencyMaxKeys(maxKeys int) *Options {
+	o.IdempotencyMaxKeys = maxKeys
+	return o
+}
+
+// WithIdempotencyPersisted sets whether idempotency keys are stored along with their transactions, so retries are
+// still deduplicated after a restart
+func (o *Options) WithIdempotencyPersisted(persisted bool) *Options {
+	o.IdempotencyPersisted = persisted
+	return o
+}
+
+// WithSQLCacheSize sets the maximum number of SQL query results cached by each database, zero disables caching
+func (o *Options) WithSQLCacheSize(size int) *Options {
+	o.SQLCacheSize = size
+	return o
+}
+
+// WithJobSchedules sets the jobs scheduled by the server configuration
+func (o *Options) WithJobSchedules(schedules []*schema.JobSchedule) *Options {
+	o.JobSchedules = schedules
+	return o
+}
+
+// WithKeepaliveTime sets after how long without activity the server pings a client to check the connection is alive
+func (o *Options) WithKeepaliveTime(t time.Duration) *Options {
+	o.KeepaliveTime = t
+	return o
+}
+
+// WithKeepaliveTimeout sets how long the server waits for the response to a keepalive ping before closing the connection
+func (o *Options) WithKeepaliveTimeout(timeout time.Duration) *Options {
+	o.KeepaliveTimeout = timeout
+	return o
+}
+
+// WithKeepaliveMinTime sets the minimum interval between client keepalive pings, connections of clients pinging
+// more often are closed
+func (o *Options) WithKeepaliveMinTime(t time.Duration) *Options {
+	o.KeepaliveMinTime = t
+	return o
+}
+
+// WithKeepalivePermitWithoutStream sets whether clients are allowed to send keepalive pings when there are no active streams
+func (o *Options) WithKeepalivePermitWithoutStream(permit bool) *Options {
+	o.KeepalivePermitWithoutStream = permit
+	return o
+}
+
+// WithMaxConnectionIdle sets after how long idle connections are closed, zero means never
+func (o *Options) WithMaxConnectionIdle(idle time.Duration) *Options {
+	o.MaxConnectionIdle = idle
+	return o
+}
+
+// WithMaxConnectionAge sets the maximum age of a connection before clients are asked to reconnect, zero means never
+func (o *Options) WithMaxConnectionAge(age time.Duration) *Options {
+	o.MaxConnectionAge = age
+	return o
+}
+
+// WithMaxConnectionAgeGrace sets how long pending calls are given to complete once a connection reached its maximum age
+func (o *Options) WithMaxConnectionAgeGrace(grace time.Duration) *Options {
+	o.MaxConnectionAgeGrace = grace
+	return o
+}
+
+// WithMaxConcurrentStreams sets the maximum number of concurrent streams of each connection, zero means unlimited
+func (o *Options) WithMaxConcurrentStreams(streams uint32) *Options {
+	o.MaxConcurrentStreams = streams
+	return o
+}
+
+// WithMaxConcurrentOps sets the maximum number of calls served concurrently, further calls are queued with interactive
+// ones ahead of batch ones. Zero means unlimited
+func (o *Options) WithMaxConcurrentOps(ops int) *Options {
+	o.MaxConcurrentOps = ops
+	return o
+}
+
+// WithBatchOpTimeout sets the timeout of batch calls arriving once the maximum number of concurrent calls is reached,
+// zero means batch calls wait as long as their own deadline allows
+func (o *Options) WithBatchOpTimeout(timeout time.Duration) *Options {
+	o.BatchOpTimeout = timeout
+	return o
+}
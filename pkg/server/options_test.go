@@ -19,8 +19,10 @@ package server
 import (
 	"crypto/tls"
 	"testing"
+	"time"
 
 	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
 	"github.com/codenotary/immudb/pkg/stream"
 	"github.com/codenotary/immudb/pkg/auth"
 
@@ -68,6 +70,25 @@ func TestSetOptions(t *testing.T) {
 		WithTokenExpiryTime(52).
 		WithWebServer(false).
 		WithStoreOptions(storeOptions).
+		WithPrefixStatsDepth(2).
+		WithPrefixStatsSeparator('/').
+		WithSQLTxTimeout(time.Second).
+		WithIteratorLeaseTimeout(time.Second).
+		WithIdempotencyWindow(time.Hour).
+		WithIdempotencyMaxKeys(10).
+		WithIdempotencyPersisted(true).
+		WithJobSchedules([]*schema.JobSchedule{{Name: "job"}}).
+		WithKeepaliveTime(time.Minute).
+		WithKeepaliveTimeout(time.Second).
+		WithKeepaliveMinTime(10 * time.Second).
+		WithKeepalivePermitWithoutStream(true).
+		WithMaxConnectionIdle(time.Hour).
+		WithMaxConnectionAge(2 * time.Hour).
+		WithMaxConnectionAgeGrace(time.Minute).
+		WithMaxConcurrentStreams(100).
+		WithGRPCReflection(false).
+		WithPublicVerification(true).
+		WithPublicVerificationRateLimit(5).
 		WithTLS(tlsConfig)
 
 	if op.GetAuth() != false ||
@@ -92,7 +113,27 @@ func TestSetOptions(t *testing.T) {
 		op.WebServer != false ||
 		op.StoreOptions != storeOptions ||
 		op.TLSConfig != tlsConfig ||
-		op.TokenExpiryTimeMin != 52 {
+		op.TokenExpiryTimeMin != 52 ||
+		op.PrefixStatsDepth != 2 ||
+		op.PrefixStatsSeparator != '/' ||
+		op.SQLTxTimeout != time.Second ||
+		op.IteratorLeaseTimeout != time.Second ||
+		op.IdempotencyWindow != time.Hour ||
+		op.IdempotencyMaxKeys != 10 ||
+		op.IdempotencyPersisted != true ||
+		len(op.JobSchedules) != 1 ||
+		op.KeepaliveTime != time.Minute ||
+		op.KeepaliveTimeout != time.Second ||
+		op.KeepaliveMinTime != 10*time.Second ||
+		op.KeepalivePermitWithoutStream != true ||
+		op.MaxConnectionIdle != time.Hour ||
+		op.MaxConnectionAge != 2*time.Hour ||
+		op.MaxConnectionAgeGrace != time.Minute ||
+		op.MaxConcurrentStreams != 100 ||
+		op.GRPCReflection ||
+		!op.PublicVerification ||
+		op.PublicVerificationRateLimit != 5 ||
+		len(connectionOptions(op)) != 3 {
 		t.Errorf("database default options mismatch")
 	}
 }
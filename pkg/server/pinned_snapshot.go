@@ -0,0 +1,81 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package server
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/golang/protobuf/ptypes/empty"
+)
+
+// PinSnapshot pins the state of the selected database as of a transaction under a name, so reads can target it
+// across restarts. The pin is written into the database itself
+func (s *ImmuServer) PinSnapshot(ctx context.Context, req *schema.PinSnapshotRequest) (*schema.PinnedSnapshot, error) {
+	if req == nil {
+		return nil, ErrIllegalArguments
+	}
+
+	ind, err := s.getDbIndexFromCtx(ctx, "PinSnapshot")
+	if err != nil {
+		return nil, err
+	}
+
+	db := s.dbList.GetByIndex(ind)
+
+	snap, err := db.PinSnapshot(req)
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordEvent(ctx, EventCategoryDatabase, db.GetOptions().GetDbName(), "snapshot pinned", map[string]string{"name": snap.Name, "txID": strconv.FormatUint(snap.TxID, 10)})
+
+	return snap, nil
+}
+
+// UnpinSnapshot removes a snapshot pinned on the selected database
+func (s *ImmuServer) UnpinSnapshot(ctx context.Context, req *schema.UnpinSnapshotRequest) (*empty.Empty, error) {
+	if req == nil {
+		return nil, ErrIllegalArguments
+	}
+
+	ind, err := s.getDbIndexFromCtx(ctx, "UnpinSnapshot")
+	if err != nil {
+		return nil, err
+	}
+
+	db := s.dbList.GetByIndex(ind)
+
+	err = db.UnpinSnapshot(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordEvent(ctx, EventCategoryDatabase, db.GetOptions().GetDbName(), "snapshot unpinned", map[string]string{"name": req.Name})
+
+	return &empty.Empty{}, nil
+}
+
+// ListPinnedSnapshots returns the snapshots pinned on the selected database
+func (s *ImmuServer) ListPinnedSnapshots(ctx context.Context, _ *empty.Empty) (*schema.PinnedSnapshots, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "ListPinnedSnapshots")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.dbList.GetByIndex(ind).ListPinnedSnapshots(), nil
+}
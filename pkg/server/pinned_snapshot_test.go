@@ -0,0 +1,81 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package server
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/auth"
+	"github.com/codenotary/immudb/pkg/database"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestServerPinnedSnapshots(t *testing.T) {
+	serverOptions := DefaultOptions().
+		WithMetricsServer(false).
+		WithAdminPassword(auth.SysAdminPassword).
+		WithListener(bufconn.Listen(1024 * 1024))
+	s := DefaultServer().WithOptions(serverOptions).(*ImmuServer)
+	defer os.RemoveAll(s.Options.Dir)
+
+	require.NoError(t, s.Initialize())
+
+	lr, err := s.Login(context.Background(), &schema.LoginRequest{
+		User:     []byte(auth.SysAdminUsername),
+		Password: []byte(auth.SysAdminPassword),
+	})
+	require.NoError(t, err)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", lr.Token))
+
+	ur, err := s.UseDatabase(ctx, &schema.Database{DatabaseName: DefaultdbName})
+	require.NoError(t, err)
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", ur.Token))
+
+	_, err = s.PinSnapshot(ctx, nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = s.Set(ctx, &schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("k1"), Value: []byte("v1")}}})
+	require.NoError(t, err)
+
+	snap, err := s.PinSnapshot(ctx, &schema.PinSnapshotRequest{Name: "eom"})
+	require.NoError(t, err)
+	require.Equal(t, "eom", snap.Name)
+
+	_, err = s.Set(ctx, &schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("k1"), Value: []byte("v2")}}})
+	require.NoError(t, err)
+
+	e, err := s.Get(ctx, &schema.KeyRequest{Key: []byte("k1"), Snapshot: "eom"})
+	require.NoError(t, err)
+	require.Equal(t, []byte("v1"), e.Value)
+
+	snaps, err := s.ListPinnedSnapshots(ctx, &empty.Empty{})
+	require.NoError(t, err)
+	require.Len(t, snaps.Snapshots, 1)
+
+	_, err = s.UnpinSnapshot(ctx, &schema.UnpinSnapshotRequest{Name: "eom"})
+	require.NoError(t, err)
+
+	_, err = s.UnpinSnapshot(ctx, &schema.UnpinSnapshotRequest{Name: "eom"})
+	require.Equal(t, database.ErrSnapshotNotFound, err)
+}
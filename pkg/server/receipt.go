@@ -0,0 +1,104 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package server
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/database"
+	"github.com/codenotary/immudb/pkg/receipt"
+)
+
+// NotarizationReceipt returns a certificate of the inclusion of an entry of the selected database, along with
+// the proofs needed to verify it and optionally rendered as a PDF document
+func (s *ImmuServer) NotarizationReceipt(ctx context.Context, req *schema.ReceiptRequest) (*schema.Receipt, error) {
+	if req == nil {
+		return nil, ErrIllegalArguments
+	}
+
+	ind, err := s.getDbIndexFromCtx(ctx, "NotarizationReceipt")
+	if err != nil {
+		return nil, err
+	}
+
+	db := s.dbList.GetByIndex(ind)
+	dbName := db.GetOptions().GetDbName()
+
+	vEntry, err := db.VerifiableGet(&schema.VerifiableGetRequest{
+		KeyRequest: &schema.KeyRequest{Key: req.Key, AtTx: req.AtTx},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := s.targetTxState(dbName, vEntry.VerifiableTx.DualProof)
+	if err != nil {
+		return nil, err
+	}
+
+	currState, err := db.CurrentState()
+	if err != nil {
+		return nil, err
+	}
+
+	// the algorithm is not covered by the signature, it's set to describe the hashes of the receipt
+	state.HashAlgorithm = currState.HashAlgorithm
+
+	vEntry.VerifiableTx.Signature = state.Signature
+
+	// the included entry is the reference when the key was resolved through one
+	vKey := vEntry.Entry.Key
+	if vEntry.Entry.ReferencedBy != nil {
+		vKey = vEntry.Entry.ReferencedBy.Key
+	}
+
+	var valueHash []byte
+
+	for _, e := range vEntry.VerifiableTx.Tx.Entries {
+		if bytes.Equal(e.Key, database.EncodeKey(vKey)) {
+			valueHash = e.HValue
+			break
+		}
+	}
+
+	r := &schema.Receipt{
+		Database:        dbName,
+		Key:             req.Key,
+		ValueHash:       valueHash,
+		Tx:              vEntry.VerifiableTx.Tx.Metadata.Id,
+		Timestamp:       vEntry.VerifiableTx.Tx.Metadata.Ts,
+		State:           state,
+		VerifiableEntry: vEntry,
+	}
+
+	if s.Options.ReceiptVerificationURL != "" {
+		r.VerificationUrl, err = receipt.VerificationURL(s.Options.ReceiptVerificationURL, r)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if req.Pdf {
+		r.Pdf, err = receipt.PDF(r)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
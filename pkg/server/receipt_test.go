@@ -0,0 +1,97 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/auth"
+	"github.com/codenotary/immudb/pkg/database"
+	"github.com/codenotary/immudb/pkg/signer"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestServerNotarizationReceipt(t *testing.T) {
+	serverOptions := DefaultOptions().
+		WithMetricsServer(false).
+		WithAdminPassword(auth.SysAdminPassword).
+		WithSigningKey("./../../test/signer/ec1.key").
+		WithReceiptVerificationURL("https://verify.example.com/receipts").
+		WithListener(bufconn.Listen(1024 * 1024))
+	s := DefaultServer().WithOptions(serverOptions).(*ImmuServer)
+	defer os.RemoveAll(s.Options.Dir)
+
+	require.NoError(t, s.Initialize())
+
+	_, err := s.NotarizationReceipt(context.Background(), nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	lr, err := s.Login(context.Background(), &schema.LoginRequest{
+		User:     []byte(auth.SysAdminUsername),
+		Password: []byte(auth.SysAdminPassword),
+	})
+	require.NoError(t, err)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", lr.Token))
+
+	_, err = s.NotarizationReceipt(ctx, &schema.ReceiptRequest{Key: []byte("contract1")})
+	require.Equal(t, store.ErrKeyNotFound, err)
+
+	md, err := s.Set(ctx, &schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("contract1"), Value: []byte("v1")}}})
+	require.NoError(t, err)
+
+	_, err = s.Set(ctx, &schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("contract1"), Value: []byte("v2")}}})
+	require.NoError(t, err)
+
+	r, err := s.NotarizationReceipt(ctx, &schema.ReceiptRequest{Key: []byte("contract1"), AtTx: md.Id, Pdf: true})
+	require.NoError(t, err)
+	require.Equal(t, DefaultdbName, r.Database)
+	require.Equal(t, []byte("contract1"), r.Key)
+	require.Equal(t, md.Id, r.Tx)
+	require.Equal(t, md.Ts, r.Timestamp)
+	require.Equal(t, []byte("v1"), r.VerifiableEntry.Entry.Value)
+
+	h := sha256.Sum256(database.EncodeKV([]byte("contract1"), []byte("v1")).Value)
+	require.Equal(t, h[:], r.ValueHash)
+
+	// the state of the transaction is signed by the server
+	require.Equal(t, md.Id, r.State.TxId)
+	require.Equal(t, r.State.Signature, r.VerifiableEntry.VerifiableTx.Signature)
+
+	ok, err := signer.Verify(r.State.ToBytes(), r.State.Signature.Signature, signer.UnmarshalKey(r.State.Signature.PublicKey))
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	require.True(t, strings.HasPrefix(r.VerificationUrl, "https://verify.example.com/receipts?"))
+	require.Contains(t, r.VerificationUrl, fmt.Sprintf("tx=%d", md.Id))
+	require.True(t, bytes.HasPrefix(r.Pdf, []byte("%PDF-")))
+
+	r, err = s.NotarizationReceipt(ctx, &schema.ReceiptRequest{Key: []byte("contract1")})
+	require.NoError(t, err)
+	require.Equal(t, []byte("v2"), r.VerifiableEntry.Entry.Value)
+	require.Greater(t, r.Tx, md.Id)
+	require.Nil(t, r.Pdf)
+}
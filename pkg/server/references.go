@@ -0,0 +1,32 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package server
+
+import (
+	"context"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+)
+
+// ListReferences lists the references and sorted set entries pointing to a key of the selected database
+func (s *ImmuServer) ListReferences(ctx context.Context, req *schema.ListReferencesRequest) (*schema.ReferenceList, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "ListReferences")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.dbList.GetByIndex(ind).ListReferences(ctx, req)
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package server
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrRewindAttestationNotSigned is returned when attesting a rewind on a server without a signing key,
+// since clients only trust signed attestations
+var ErrRewindAttestationNotSigned = status.Error(codes.FailedPrecondition, "rewind attestations require the server to sign its states")
+
+// AttestRewind sanctions the current state of the selected database, e.g. once it was restored from a backup,
+// so clients holding a state it no longer reaches can be configured to move to it
+func (s *ImmuServer) AttestRewind(ctx context.Context, req *schema.AttestRewindRequest) (*schema.RewindAttestation, error) {
+	if req == nil {
+		return nil, ErrIllegalArguments
+	}
+
+	if s.Options.SigningKey == "" {
+		return nil, ErrRewindAttestationNotSigned
+	}
+
+	ind, err := s.getDbIndexFromCtx(ctx, "AttestRewind")
+	if err != nil {
+		return nil, err
+	}
+
+	var issuedBy string
+
+	if s.Options.GetAuth() {
+		_, user, err := s.getLoggedInUserdataFromCtx(ctx)
+		if err != nil {
+			return nil, err
+		}
+		issuedBy = user.Username
+	}
+
+	db := s.dbList.GetByIndex(ind)
+
+	attestation, err := db.AttestRewind(issuedBy, req.Reason, s.StateSigner.SignRewindAttestation)
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordEvent(ctx, EventCategoryDatabase, db.GetOptions().GetDbName(), "rewind attested", map[string]string{
+		"txId":   strconv.FormatUint(attestation.TxId, 10),
+		"reason": req.Reason,
+	})
+
+	return attestation, nil
+}
+
+// GetRewindAttestation returns the latest rewind attestation of the selected database
+func (s *ImmuServer) GetRewindAttestation(ctx context.Context, _ *empty.Empty) (*schema.RewindAttestation, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "GetRewindAttestation")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.dbList.GetByIndex(ind).RewindAttestation()
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package server
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/auth"
+	"github.com/codenotary/immudb/pkg/signer"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestServerAttestRewind(t *testing.T) {
+	serverOptions := DefaultOptions().
+		WithMetricsServer(false).
+		WithAdminPassword(auth.SysAdminPassword).
+		WithListener(bufconn.Listen(1024 * 1024))
+	s := DefaultServer().WithOptions(serverOptions).(*ImmuServer)
+	defer os.RemoveAll(s.Options.Dir)
+
+	require.NoError(t, s.Initialize())
+
+	lr, err := s.Login(context.Background(), &schema.LoginRequest{
+		User:     []byte(auth.SysAdminUsername),
+		Password: []byte(auth.SysAdminPassword),
+	})
+	require.NoError(t, err)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", lr.Token))
+
+	_, err = s.AttestRewind(ctx, &schema.AttestRewindRequest{Reason: "restored from backup"})
+	require.Equal(t, ErrRewindAttestationNotSigned, err)
+
+	s.Options.SigningKey = "./../../test/signer/ec1.key"
+
+	sig, err := signer.NewSigner(s.Options.SigningKey)
+	require.NoError(t, err)
+	s.StateSigner = NewStateSigner(sig)
+
+	_, err = s.AttestRewind(ctx, nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	attestation, err := s.AttestRewind(ctx, &schema.AttestRewindRequest{Reason: "restored from backup"})
+	require.NoError(t, err)
+	require.Equal(t, auth.SysAdminUsername, attestation.IssuedBy)
+
+	pubKey, err := signer.ParsePublicKeyFile("./../../test/signer/ec1.pub")
+	require.NoError(t, err)
+
+	ok, err := attestation.CheckSignature(pubKey)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	stored, err := s.GetRewindAttestation(ctx, &empty.Empty{})
+	require.NoError(t, err)
+	require.Equal(t, attestation.Tx, stored.Tx)
+
+	ok, err = stored.CheckSignature(pubKey)
+	require.NoError(t, err)
+	require.True(t, ok)
+}
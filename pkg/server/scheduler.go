@@ -0,0 +1,552 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package server
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/embedded/tbtree"
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/database"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Built-in jobs which can be scheduled
+const (
+	JobCompaction = "compaction"
+	JobAudit      = "audit"
+)
+
+// defaultJobRunsLimit is the number of runs returned by ListJobRuns when no limit is requested
+const defaultJobRunsLimit = 100
+
+var (
+	ErrJobScheduleNotFound = status.Error(codes.NotFound, "job schedule not found")
+	ErrJobScheduleReadOnly = status.Error(codes.FailedPrecondition, "job schedule is defined in the server configuration")
+)
+
+type scheduledJob struct {
+	schedule *schema.JobSchedule
+	cron     *cronSchedule
+	next     time.Time
+	running  bool
+
+	// jobs defined in the server configuration can't be changed through the API
+	configured bool
+}
+
+// jobScheduler triggers the scheduled jobs when their cron expressions are due. A job is skipped if its
+// previous run has not completed yet
+type jobScheduler struct {
+	jobs map[string]*scheduledJob
+	run  func(ctx context.Context, js *schema.JobSchedule)
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	changed chan struct{}
+	started bool
+	done    chan struct{}
+	running sync.WaitGroup
+
+	mutex sync.Mutex
+}
+
+func newJobScheduler(run func(ctx context.Context, js *schema.JobSchedule)) *jobScheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &jobScheduler{
+		jobs:    make(map[string]*scheduledJob),
+		run:     run,
+		ctx:     ctx,
+		cancel:  cancel,
+		changed: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+}
+
+func validateJobSchedule(js *schema.JobSchedule) (*cronSchedule, error) {
+	if js == nil || js.Name == "" {
+		return nil, ErrIllegalArguments
+	}
+
+	if js.Kind != JobCompaction && js.Kind != JobAudit {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown job kind '%s'", js.Kind)
+	}
+
+	cron, err := parseCronSchedule(js.Cron)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return cron, nil
+}
+
+func (sc *jobScheduler) add(js *schema.JobSchedule, configured bool) error {
+	cron, err := validateJobSchedule(js)
+	if err != nil {
+		return err
+	}
+
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+
+	running := false
+
+	if j, ok := sc.jobs[js.Name]; ok {
+		if j.configured {
+			return ErrJobScheduleReadOnly
+		}
+		running = j.running
+	}
+
+	sc.jobs[js.Name] = &scheduledJob{
+		schedule:   js,
+		cron:       cron,
+		next:       cron.next(time.Now()),
+		running:    running,
+		configured: configured,
+	}
+
+	sc.notify()
+
+	return nil
+}
+
+func (sc *jobScheduler) remove(name string) error {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+
+	j, ok := sc.jobs[name]
+	if !ok {
+		return ErrJobScheduleNotFound
+	}
+
+	if j.configured {
+		return ErrJobScheduleReadOnly
+	}
+
+	delete(sc.jobs, name)
+
+	sc.notify()
+
+	return nil
+}
+
+func (sc *jobScheduler) isConfigured(name string) bool {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+
+	j, ok := sc.jobs[name]
+	return ok && j.configured
+}
+
+func (sc *jobScheduler) list() []*schema.JobSchedule {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+
+	schedules := make([]*schema.JobSchedule, 0, len(sc.jobs))
+	for _, j := range sc.jobs {
+		schedules = append(schedules, j.schedule)
+	}
+
+	sort.Slice(schedules, func(i, j int) bool {
+		return schedules[i].Name < schedules[j].Name
+	})
+
+	return schedules
+}
+
+// notify must be called while holding the mutex
+func (sc *jobScheduler) notify() {
+	select {
+	case sc.changed <- struct{}{}:
+	default:
+	}
+}
+
+func (sc *jobScheduler) start() {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+
+	if sc.started {
+		return
+	}
+	sc.started = true
+
+	go sc.loop()
+}
+
+// stop cancels the running jobs and waits for them to complete
+func (sc *jobScheduler) stop() {
+	sc.cancel()
+
+	sc.mutex.Lock()
+	started := sc.started
+	sc.mutex.Unlock()
+
+	if started {
+		<-sc.done
+	}
+
+	sc.running.Wait()
+}
+
+func (sc *jobScheduler) loop() {
+	defer close(sc.done)
+
+	for {
+		wakeUpIn := sc.trigger(time.Now())
+
+		timer := time.NewTimer(wakeUpIn)
+
+		select {
+		case <-sc.ctx.Done():
+			timer.Stop()
+			return
+		case <-sc.changed:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// trigger runs the jobs due at the given time and returns how long to wait until the next one is due
+func (sc *jobScheduler) trigger(now time.Time) time.Duration {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+
+	wakeUpIn := time.Hour
+
+	for _, j := range sc.jobs {
+		if j.next.IsZero() {
+			continue
+		}
+
+		if !j.next.After(now) {
+			if !j.running {
+				j.running = true
+				sc.running.Add(1)
+				go sc.runJob(j)
+			}
+
+			j.next = j.cron.next(now)
+		}
+
+		if d := j.next.Sub(now); d < wakeUpIn {
+			wakeUpIn = d
+		}
+	}
+
+	return wakeUpIn
+}
+
+func (sc *jobScheduler) runJob(j *scheduledJob) {
+	defer sc.running.Done()
+
+	sc.run(sc.ctx, j.schedule)
+
+	sc.mutex.Lock()
+	j.running = false
+	sc.mutex.Unlock()
+}
+
+// runScheduledJob runs a job on each of the targeted databases, recording every run in the system database
+func (s *ImmuServer) runScheduledJob(ctx context.Context, js *schema.JobSchedule) {
+	var dbs []database.DB
+
+	if js.Database == "" {
+		for i := 0; i < s.dbList.Length(); i++ {
+			dbs = append(dbs, s.dbList.GetByIndex(int64(i)))
+		}
+	} else {
+		db, err := s.dbList.GetByName(js.Database)
+		if err != nil {
+			s.recordJobRun(&schema.JobRun{
+				Name:       js.Name,
+				Kind:       js.Kind,
+				Database:   js.Database,
+				StartedAt:  time.Now().Unix(),
+				FinishedAt: time.Now().Unix(),
+				Error:      err.Error(),
+			})
+			return
+		}
+		dbs = append(dbs, db)
+	}
+
+	for _, db := range dbs {
+		run := &schema.JobRun{
+			Name:      js.Name,
+			Kind:      js.Kind,
+			Database:  db.GetName(),
+			StartedAt: time.Now().Unix(),
+		}
+
+		s.Logger.Infof("running scheduled job '%s' (%s) on database '%s'", js.Name, js.Kind, run.Database)
+
+		op, opCtx := s.operations.beginJob(ctx, js.Kind, js.Name, run.Database)
+
+		err := s.jobs.run(opCtx, js.Kind, run.Database, js.Kind == JobAudit, func(ctx context.Context, progress func(uint32)) error {
+			switch js.Kind {
+			case JobCompaction:
+				err := db.CompactIndex()
+				if err == tbtree.ErrCompactionThresholdNotReached {
+					return nil
+				}
+				return err
+			case JobAudit:
+				_, err := db.Audit(ctx, func(audited, total uint64) {
+					progress(uint32(audited * 100 / total))
+				})
+				return err
+			}
+			return nil
+		})
+
+		s.operations.end(op)
+
+		run.FinishedAt = time.Now().Unix()
+
+		if err != nil {
+			s.Logger.Errorf("scheduled job '%s' (%s) failed on database '%s': %v", js.Name, js.Kind, run.Database, err)
+			run.Error = err.Error()
+		}
+
+		s.recordJobRun(run)
+
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+func (s *ImmuServer) recordJobRun(run *schema.JobRun) {
+	details := map[string]string{"kind": run.Kind, "database": run.Database}
+
+	if run.Error == "" {
+		s.recordEvent(context.Background(), EventCategoryJob, run.Name, "run completed", details)
+	} else {
+		details["error"] = run.Error
+		s.recordEvent(context.Background(), EventCategoryJob, run.Name, "run failed", details)
+
+		if run.Kind == JobAudit {
+			atomic.AddUint64(&s.auditFailures, 1)
+		}
+	}
+
+	if s.sysDb == nil {
+		return
+	}
+
+	runData, err := proto.Marshal(run)
+	if err != nil {
+		s.Logger.Errorf("error recording run of job '%s': %v", run.Name, err)
+		return
+	}
+
+	_, err = s.sysDb.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: jobKey(KeyPrefixJobRun, run.Name), Value: runData}}})
+	if err != nil {
+		s.Logger.Errorf("error recording run of job '%s': %v", run.Name, err)
+	}
+}
+
+// loadJobSchedules registers the jobs defined in the server configuration and the ones created through the API
+func (s *ImmuServer) loadJobSchedules() error {
+	for _, js := range s.Options.JobSchedules {
+		err := s.scheduler.add(js, true)
+		if err != nil {
+			return err
+		}
+	}
+
+	if s.sysDb == nil {
+		return nil
+	}
+
+	req := &schema.ScanRequest{Prefix: []byte{KeyPrefixJobSchedule}}
+
+	for {
+		entries, err := s.sysDb.Scan(context.Background(), req)
+		if err != nil {
+			return err
+		}
+
+		for _, e := range entries.Entries {
+			// removed schedules are overwritten with an empty value
+			if len(e.Value) == 0 {
+				continue
+			}
+
+			var js schema.JobSchedule
+
+			err = proto.Unmarshal(e.Value, &js)
+			if err != nil {
+				return err
+			}
+
+			err = s.scheduler.add(&js, false)
+			if err == ErrJobScheduleReadOnly {
+				s.Logger.Warningf("job schedule '%s' is overridden by the server configuration", js.Name)
+				continue
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(entries.Entries) < database.MaxKeyScanLimit {
+			return nil
+		}
+
+		req.SeekKey = entries.Entries[len(entries.Entries)-1].Key
+	}
+}
+
+// ScheduleJob creates or replaces a job schedule. Schedules are persisted in the system database
+func (s *ImmuServer) ScheduleJob(ctx context.Context, js *schema.JobSchedule) (*empty.Empty, error) {
+	if err := s.checkSysAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	if s.sysDb == nil {
+		return nil, fmt.Errorf("this command is available only with authentication on")
+	}
+
+	if _, err := validateJobSchedule(js); err != nil {
+		return nil, err
+	}
+
+	jsData, err := proto.Marshal(js)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.scheduler.isConfigured(js.Name) {
+		return nil, ErrJobScheduleReadOnly
+	}
+
+	_, err = s.sysDb.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: jobKey(KeyPrefixJobSchedule, js.Name), Value: jsData}}})
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.scheduler.add(js, false)
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordEvent(ctx, EventCategoryJob, js.Name, "scheduled", map[string]string{"kind": js.Kind, "cron": js.Cron, "database": js.Database})
+
+	return new(empty.Empty), nil
+}
+
+// UnscheduleJob removes a job schedule created through the API
+func (s *ImmuServer) UnscheduleJob(ctx context.Context, req *schema.JobScheduleRequest) (*empty.Empty, error) {
+	if req == nil {
+		return nil, ErrIllegalArguments
+	}
+
+	if err := s.checkSysAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	if s.sysDb == nil {
+		return nil, fmt.Errorf("this command is available only with authentication on")
+	}
+
+	err := s.scheduler.remove(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.sysDb.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: jobKey(KeyPrefixJobSchedule, req.Name), Value: []byte{}}}})
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordEvent(ctx, EventCategoryJob, req.Name, "unscheduled", nil)
+
+	return new(empty.Empty), nil
+}
+
+// ListJobSchedules returns the job schedules, including the ones defined in the server configuration
+func (s *ImmuServer) ListJobSchedules(ctx context.Context, _ *empty.Empty) (*schema.JobScheduleList, error) {
+	if err := s.checkSysAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	return &schema.JobScheduleList{Schedules: s.scheduler.list()}, nil
+}
+
+// ListJobRuns returns the most recent runs of a scheduled job, newest first
+func (s *ImmuServer) ListJobRuns(ctx context.Context, req *schema.JobRunsRequest) (*schema.JobRunList, error) {
+	if req == nil || req.Name == "" {
+		return nil, ErrIllegalArguments
+	}
+
+	if err := s.checkSysAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	if s.sysDb == nil {
+		return nil, fmt.Errorf("this command is available only with authentication on")
+	}
+
+	limit := int32(defaultJobRunsLimit)
+	if req.Limit > 0 && req.Limit < database.MaxKeyScanLimit {
+		limit = int32(req.Limit)
+	}
+
+	history, err := s.sysDb.History(&schema.HistoryRequest{Key: jobKey(KeyPrefixJobRun, req.Name), Limit: limit, Desc: true})
+	if err == store.ErrKeyNotFound {
+		return &schema.JobRunList{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	runs := &schema.JobRunList{}
+
+	for _, e := range history.Entries {
+		run := &schema.JobRun{}
+
+		err = proto.Unmarshal(e.Value, run)
+		if err != nil {
+			return nil, err
+		}
+
+		run.Tx = e.Tx
+		runs.Runs = append(runs.Runs, run)
+	}
+
+	return runs, nil
+}
+
+func jobKey(prefix byte, name string) []byte {
+	key := make([]byte, 1+len(name))
+	key[0] = prefix
+	copy(key[1:], name)
+	return key
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package server
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/auth"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestServerScheduledJobs(t *testing.T) {
+	serverOptions := DefaultOptions().
+		WithMetricsServer(false).
+		WithAdminPassword(auth.SysAdminPassword).
+		WithJobSchedules([]*schema.JobSchedule{{Name: "configured", Kind: JobCompaction, Cron: "@daily"}}).
+		WithListener(bufconn.Listen(1024 * 1024))
+
+	s := DefaultServer().WithOptions(serverOptions).(*ImmuServer)
+	defer os.RemoveAll(s.Options.Dir)
+
+	require.NoError(t, s.Initialize())
+
+	lr, err := s.Login(context.Background(), &schema.LoginRequest{
+		User:     []byte(auth.SysAdminUsername),
+		Password: []byte(auth.SysAdminPassword),
+	})
+	require.NoError(t, err)
+
+	md := metadata.Pairs("authorization", lr.Token)
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+
+	_, err = s.Set(ctx, &schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("key"), Value: []byte("value")}}})
+	require.NoError(t, err)
+
+	_, err = s.ScheduleJob(ctx, &schema.JobSchedule{Name: "job", Kind: "backup", Cron: "@daily"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = s.ScheduleJob(ctx, &schema.JobSchedule{Name: "job", Kind: JobAudit, Cron: "* * *"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = s.ScheduleJob(ctx, &schema.JobSchedule{Name: "configured", Kind: JobAudit, Cron: "@daily"})
+	require.Equal(t, ErrJobScheduleReadOnly, err)
+
+	_, err = s.ScheduleJob(ctx, &schema.JobSchedule{Name: "audit", Kind: JobAudit, Cron: "@every 1s", Database: DefaultdbName})
+	require.NoError(t, err)
+
+	_, err = s.ScheduleJob(ctx, &schema.JobSchedule{Name: "missing", Kind: JobCompaction, Cron: "@every 1s", Database: "missingdb"})
+	require.NoError(t, err)
+
+	schedules, err := s.ListJobSchedules(ctx, &empty.Empty{})
+	require.NoError(t, err)
+	require.Len(t, schedules.Schedules, 3)
+	require.Equal(t, "audit", schedules.Schedules[0].Name)
+
+	s.scheduler.start()
+	defer s.scheduler.stop()
+
+	var runs *schema.JobRunList
+
+	require.Eventually(t, func() bool {
+		runs, err = s.ListJobRuns(ctx, &schema.JobRunsRequest{Name: "audit"})
+		require.NoError(t, err)
+		return len(runs.Runs) > 0
+	}, 10*time.Second, 100*time.Millisecond)
+
+	require.Equal(t, JobAudit, runs.Runs[0].Kind)
+	require.Equal(t, DefaultdbName, runs.Runs[0].Database)
+	require.Empty(t, runs.Runs[0].Error)
+	require.NotZero(t, runs.Runs[0].Tx)
+
+	require.Eventually(t, func() bool {
+		runs, err = s.ListJobRuns(ctx, &schema.JobRunsRequest{Name: "missing", Limit: 1})
+		require.NoError(t, err)
+		return len(runs.Runs) > 0
+	}, 10*time.Second, 100*time.Millisecond)
+
+	require.Len(t, runs.Runs, 1)
+	require.NotEmpty(t, runs.Runs[0].Error)
+
+	runs, err = s.ListJobRuns(ctx, &schema.JobRunsRequest{Name: "configured"})
+	require.NoError(t, err)
+	require.Empty(t, runs.Runs)
+
+	_, err = s.UnscheduleJob(ctx, &schema.JobScheduleRequest{Name: "missing"})
+	require.NoError(t, err)
+
+	_, err = s.UnscheduleJob(ctx, &schema.JobScheduleRequest{Name: "missing"})
+	require.Equal(t, ErrJobScheduleNotFound, err)
+
+	_, err = s.UnscheduleJob(ctx, &schema.JobScheduleRequest{Name: "configured"})
+	require.Equal(t, ErrJobScheduleReadOnly, err)
+
+	_, err = s.ListJobRuns(ctx, &schema.JobRunsRequest{})
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = s.ListJobSchedules(context.Background(), &empty.Empty{})
+	require.Error(t, err)
+
+	s.scheduler.stop()
+
+	err = s.CloseDatabases()
+	require.NoError(t, err)
+
+	// schedules created through the API are loaded again
+	s = DefaultServer().WithOptions(serverOptions).(*ImmuServer)
+
+	require.NoError(t, s.Initialize())
+
+	lr, err = s.Login(context.Background(), &schema.LoginRequest{
+		User:     []byte(auth.SysAdminUsername),
+		Password: []byte(auth.SysAdminPassword),
+	})
+	require.NoError(t, err)
+
+	md = metadata.Pairs("authorization", lr.Token)
+	ctx = metadata.NewIncomingContext(context.Background(), md)
+
+	schedules, err = s.ListJobSchedules(ctx, &empty.Empty{})
+	require.NoError(t, err)
+	require.Len(t, schedules.Schedules, 2)
+	require.Equal(t, "audit", schedules.Schedules[0].Name)
+	require.Equal(t, "configured", schedules.Schedules[1].Name)
+}
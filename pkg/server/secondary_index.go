@@ -0,0 +1,87 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/golang/protobuf/ptypes/empty"
+)
+
+// CreateSecondaryIndex declares an index over the values of the selected database, existing values are indexed
+// before returning
+func (s *ImmuServer) CreateSecondaryIndex(ctx context.Context, req *schema.SecondaryIndex) (*schema.SecondaryIndex, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "CreateSecondaryIndex")
+	if err != nil {
+		return nil, err
+	}
+
+	db := s.dbList.GetByIndex(ind)
+
+	idx, err := db.CreateSecondaryIndex(req)
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordEvent(ctx, EventCategoryDatabase, db.GetOptions().GetDbName(), "secondary index created", map[string]string{"index": idx.Name})
+
+	return idx, nil
+}
+
+// DropSecondaryIndex drops an index of the selected database
+func (s *ImmuServer) DropSecondaryIndex(ctx context.Context, req *schema.SecondaryIndexRequest) (*empty.Empty, error) {
+	if req == nil {
+		return nil, ErrIllegalArguments
+	}
+
+	ind, err := s.getDbIndexFromCtx(ctx, "DropSecondaryIndex")
+	if err != nil {
+		return nil, err
+	}
+
+	db := s.dbList.GetByIndex(ind)
+
+	err = db.DropSecondaryIndex(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordEvent(ctx, EventCategoryDatabase, db.GetOptions().GetDbName(), "secondary index dropped", map[string]string{"index": req.Name})
+
+	return &empty.Empty{}, nil
+}
+
+// ListSecondaryIndexes returns the indexes declared in the selected database
+func (s *ImmuServer) ListSecondaryIndexes(ctx context.Context, _ *empty.Empty) (*schema.SecondaryIndexes, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "ListSecondaryIndexes")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.dbList.GetByIndex(ind).ListSecondaryIndexes(), nil
+}
+
+// IndexScan lists the entries of the selected database by the value of one of its secondary indexes
+func (s *ImmuServer) IndexScan(ctx context.Context, req *schema.IndexScanRequest) (*schema.IndexEntries, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "IndexScan")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.dbList.GetByIndex(ind).IndexScan(ctx, req)
+}
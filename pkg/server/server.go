@@ -34,29 +34,45 @@ import (
 	"time"
 	"unicode"
 
+	"github.com/codenotary/immudb/embedded/appendable"
 	"github.com/codenotary/immudb/pkg/stream"
 
 	"github.com/codenotary/immudb/pkg/database"
+	"github.com/codenotary/immudb/pkg/fips"
 
 	"github.com/codenotary/immudb/pkg/logger"
+	"github.com/codenotary/immudb/pkg/replication"
 	"github.com/codenotary/immudb/pkg/signer"
 
 	"github.com/codenotary/immudb/cmd/helper"
 	"github.com/codenotary/immudb/cmd/version"
 	"github.com/codenotary/immudb/pkg/api/schema"
 	"github.com/codenotary/immudb/pkg/auth"
+	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes/empty"
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/reflection"
 	"google.golang.org/grpc/status"
 )
 
 const (
 	//KeyPrefixUser All user keys in the key/value store are prefixed by this keys to distinguish them from keys that have other purposes
 	KeyPrefixUser = iota + 1
+	//KeyPrefixKeyRules Key naming rules of each database are stored under this prefix followed by the database name
+	KeyPrefixKeyRules
+	//KeyPrefixJobSchedule Job schedules created through the API are stored under this prefix followed by the job name
+	KeyPrefixJobSchedule
+	//KeyPrefixJobRun Each run of a scheduled job is recorded as a new version of the key made of this prefix followed by the job name
+	KeyPrefixJobRun
+	//KeyPrefixApprovalRules Approval rules of each database are stored under this prefix followed by the database name
+	KeyPrefixApprovalRules
+	//KeyPrefixPendingWrite Writes waiting for approval are stored under this prefix followed by the database name and the write id
+	KeyPrefixPendingWrite
 )
 
 var startedAt time.Time
@@ -82,11 +98,27 @@ func (s *ImmuServer) Initialize() error {
 		return logErr(s.Logger, "%v", err)
 	}
 
+	// options are validated before binding the listener or loading any database
+	if s.Options.StreamChunkSize < stream.MinChunkSize {
+		return stream.ErrChunkTooSmall
+	}
+
+	if s.Options.StreamBandwidthScope != StreamBandwidthScopeReplication && s.Options.StreamBandwidthScope != StreamBandwidthScopeAll {
+		return ErrInvalidStreamBandwidthScope
+	}
+
 	if len(adminPassword) == 0 {
 		s.Logger.Errorf(ErrEmptyAdminPassword.Error())
 		return ErrEmptyAdminPassword
 	}
 
+	if fips.Enabled {
+		if err = checkFIPSCompliance(s.Options); err != nil {
+			return logErr(s.Logger, "FIPS mode: %v", err)
+		}
+		s.Logger.Infof("FIPS mode enabled")
+	}
+
 	dataDir := s.Options.Dir
 
 	if err = s.loadSystemDatabase(dataDir, adminPassword); err != nil {
@@ -101,7 +133,37 @@ func (s *ImmuServer) Initialize() error {
 		return logErr(s.Logger, "Unable load databases: %v", err)
 	}
 
+	if err = s.loadEventsDatabase(dataDir); err != nil {
+		return logErr(s.Logger, "Unable load events database: %v", err)
+	}
+
+	s.recordConfig()
+
+	if s.Options.ReplicationOptions != nil {
+		s.replicator, err = replication.NewTxReplicator(s.dbList.GetByIndex(DefaultDbIndex), s.Options.ReplicationOptions, s.Logger)
+		if err != nil {
+			return logErr(s.Logger, "Unable to configure replication: %v", err)
+		}
+	}
+
+	s.scheduler = newJobScheduler(s.runScheduledJob)
+
+	if s.Options.AlertOptions != nil {
+		if !s.Options.AlertOptions.Valid() {
+			return logErr(s.Logger, "Unable to configure alerts: %v", ErrIllegalArguments)
+		}
+		s.alerts = newAlertMonitor(s.Options.AlertOptions, s.alertChecks, s.notifyAlert)
+	}
+
+	if err = s.loadJobSchedules(); err != nil {
+		return logErr(s.Logger, "Unable to load job schedules: %v", err)
+	}
+
 	s.multidbmode = s.mandatoryAuth()
+	if s.Options.SyslogOptions != nil && !s.Options.SyslogOptions.Valid() {
+		return logErr(s.Logger, "Unable to configure the syslog listener: %v", ErrIllegalArguments)
+	}
+
 	if !s.Options.GetAuth() && s.multidbmode {
 		s.Logger.Infof("Authentication must be on.")
 		return fmt.Errorf("auth should be on")
@@ -149,8 +211,14 @@ func (s *ImmuServer) Initialize() error {
 		return err
 	}
 
-	if s.Options.StreamChunkSize < stream.MinChunkSize {
-		return stream.ErrChunkTooSmall
+	if s.Options.StreamBandwidth > 0 {
+		throttler := stream.NewThrottler(s.Options.StreamBandwidth, s.Options.StreamThrottling...)
+		s.replicationThrottler = throttler
+
+		// streams served to ordinary clients are only limited when requested
+		if s.Options.StreamBandwidthScope == StreamBandwidthScopeAll {
+			s.StreamServiceFactory = stream.NewThrottledStreamServiceFactory(s.Options.StreamChunkSize, throttler)
+		}
 	}
 
 	//===> !NOTE: See Histograms section here:
@@ -179,15 +247,26 @@ func (s *ImmuServer) Initialize() error {
 		grpc_prometheus.StreamServerInterceptor,
 		auth.ServerStreamInterceptor,
 	}
+	if s.Options.MaxConcurrentOps > 0 {
+		admission := newAdmission(s.Options.MaxConcurrentOps, s.Options.BatchOpTimeout)
+		uis = append(uis, admission.AdmissionInterceptor)
+		sss = append(sss, admission.AdmissionStreamInterceptor)
+	}
+	uis = append(uis, s.operations.OperationsInterceptor)
+	sss = append(sss, s.operations.OperationsStreamInterceptor)
 	grpcSrvOpts = append(
 		grpcSrvOpts,
 		grpc.UnaryInterceptor(grpc_middleware.ChainUnaryServer(uis...)),
 		grpc.StreamInterceptor(grpc_middleware.ChainStreamServer(sss...)),
 		grpc.MaxRecvMsgSize(s.Options.MaxRecvMsgSize),
 	)
+	grpcSrvOpts = append(grpcSrvOpts, connectionOptions(s.Options)...)
 
 	s.GrpcServer = grpc.NewServer(grpcSrvOpts...)
 	schema.RegisterImmuServiceServer(s.GrpcServer, s)
+	if s.Options.GRPCReflection {
+		reflection.Register(s.GrpcServer)
+	}
 	grpc_prometheus.Register(s.GrpcServer)
 
 	s.PgsqlSrv = pgsqlsrv.New(pgsqlsrv.Port(s.Options.PgsqlServerPort), pgsqlsrv.DatabaseList(s.dbList), pgsqlsrv.SysDb(s.sysDb), pgsqlsrv.TlsConfig(s.Options.TLSConfig))
@@ -240,6 +319,20 @@ func (s *ImmuServer) Start() (err error) {
 		}()
 	}
 
+	if s.replicator != nil {
+		if err := s.replicator.Start(); err != nil {
+			return err
+		}
+	}
+
+	s.scheduler.start()
+
+	if s.alerts != nil {
+		s.alerts.start()
+	}
+
+	s.recordEvent(context.Background(), EventCategoryServer, s.UUID.String(), "started", nil)
+
 	if s.Options.WebServer {
 		if err := s.setUpWebServer(); err != nil {
 			return err
@@ -251,6 +344,13 @@ func (s *ImmuServer) Start() (err error) {
 		}()
 	}
 
+	if s.Options.SyslogOptions != nil {
+		if err := s.setUpSyslog(); err != nil {
+			return err
+		}
+		defer s.syslog.stop()
+	}
+
 	s.mux.Unlock()
 	s.pgsqlMux.Unlock()
 	<-s.quit
@@ -258,6 +358,29 @@ func (s *ImmuServer) Start() (err error) {
 	return err
 }
 
+// connectionOptions returns the keepalive and connection limits of the gRPC server
+func connectionOptions(opts *Options) []grpc.ServerOption {
+	srvOpts := []grpc.ServerOption{
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			Time:                  opts.KeepaliveTime,
+			Timeout:               opts.KeepaliveTimeout,
+			MaxConnectionIdle:     opts.MaxConnectionIdle,
+			MaxConnectionAge:      opts.MaxConnectionAge,
+			MaxConnectionAgeGrace: opts.MaxConnectionAgeGrace,
+		}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             opts.KeepaliveMinTime,
+			PermitWithoutStream: opts.KeepalivePermitWithoutStream,
+		}),
+	}
+
+	if opts.MaxConcurrentStreams > 0 {
+		srvOpts = append(srvOpts, grpc.MaxConcurrentStreams(opts.MaxConcurrentStreams))
+	}
+
+	return srvOpts
+}
+
 func logErr(log logger.Logger, formattedMessage string, err error) error {
 	if err != nil {
 		log.Errorf(formattedMessage, err)
@@ -265,6 +388,11 @@ func logErr(log logger.Logger, formattedMessage string, err error) error {
 	return err
 }
 
+// checkFIPSCompliance rejects configurations using algorithms not approved in FIPS mode
+func checkFIPSCompliance(opts *Options) error {
+	return fips.CheckTLSConfig(opts.TLSConfig)
+}
+
 func (s *ImmuServer) setupPidFile() error {
 	var err error
 	if s.Options.Pidfile != "" {
@@ -281,7 +409,12 @@ func (s *ImmuServer) setUpMetricsServer() error {
 		s.Logger,
 		s.metricFuncServerUptimeCounter,
 		s.metricFuncComputeDBSizes,
+		s.metricFuncComputeDBArchivedSizes,
 		s.metricFuncComputeDBEntries,
+		s.metricFuncComputeDBCorruptedReads,
+		s.metricFuncComputeDBPrefixStats,
+		s.metricFuncComputeDBIdempotencyStats,
+		s.metricFuncComputeDBTableStats,
 	)
 	return nil
 }
@@ -375,7 +508,16 @@ func (s *ImmuServer) loadDefaultDatabase(dataDir string) error {
 		WithDbName(s.Options.GetDefaultDbName()).
 		WithDbRootPath(dataDir).
 		WithDbRootPath(s.Options.Dir).
-		WithStoreOptions(s.Options.StoreOptions)
+		WithStoreOptions(s.Options.StoreOptions).
+		WithReplica(s.Options.ReplicationOptions != nil).
+		WithPrefixStatsDepth(s.Options.PrefixStatsDepth).
+		WithPrefixStatsSeparator(s.Options.PrefixStatsSeparator).
+		WithSQLTxTimeout(s.Options.SQLTxTimeout).
+		WithIteratorLeaseTimeout(s.Options.IteratorLeaseTimeout).
+		WithIdempotencyWindow(s.Options.IdempotencyWindow).
+		WithIdempotencyMaxKeys(s.Options.IdempotencyMaxKeys).
+		WithIdempotencyPersisted(s.Options.IdempotencyPersisted).
+		WithSQLCacheSize(s.Options.SQLCacheSize)
 
 	_, defaultDbErr := s.OS.Stat(defaultDbRootDir)
 	if s.OS.IsNotExist(defaultDbErr) {
@@ -391,6 +533,16 @@ func (s *ImmuServer) loadDefaultDatabase(dataDir string) error {
 			return err
 		}
 
+		err = s.loadKeyRules(db)
+		if err != nil {
+			return err
+		}
+
+		err = s.loadApprovalRules(db)
+		if err != nil {
+			return err
+		}
+
 		s.dbList.Append(db)
 	}
 
@@ -409,7 +561,8 @@ func (s *ImmuServer) loadUserDatabases(dataDir string) error {
 	for _, f := range files {
 		if !f.IsDir() ||
 			f.Name() == s.Options.GetSystemAdminDbName() ||
-			f.Name() == s.Options.GetDefaultDbName() {
+			f.Name() == s.Options.GetDefaultDbName() ||
+			f.Name() == EventsdbName {
 			continue
 		}
 
@@ -427,13 +580,31 @@ func (s *ImmuServer) loadUserDatabases(dataDir string) error {
 			WithDbName(dbname).
 			WithDbRootPath(dataDir).
 			WithDbRootPath(s.Options.Dir).
-			WithStoreOptions(s.Options.StoreOptions)
+			WithStoreOptions(s.Options.StoreOptions).
+			WithPrefixStatsDepth(s.Options.PrefixStatsDepth).
+			WithPrefixStatsSeparator(s.Options.PrefixStatsSeparator).
+			WithSQLTxTimeout(s.Options.SQLTxTimeout).
+			WithIteratorLeaseTimeout(s.Options.IteratorLeaseTimeout).
+			WithIdempotencyWindow(s.Options.IdempotencyWindow).
+			WithIdempotencyMaxKeys(s.Options.IdempotencyMaxKeys).
+			WithIdempotencyPersisted(s.Options.IdempotencyPersisted).
+			WithSQLCacheSize(s.Options.SQLCacheSize)
 
 		db, err := database.OpenDb(op, s.sysDb, s.Logger)
 		if err != nil {
 			return err
 		}
 
+		err = s.loadKeyRules(db)
+		if err != nil {
+			return err
+		}
+
+		err = s.loadApprovalRules(db)
+		if err != nil {
+			return err
+		}
+
 		s.dbList.Append(db)
 	}
 
@@ -449,6 +620,22 @@ func (s *ImmuServer) Stop() error {
 
 	defer func() { s.quit <- struct{}{} }()
 
+	if s.replicator != nil {
+		if err := s.replicator.Stop(); err != nil && err != replication.ErrAlreadyStopped {
+			s.Logger.Errorf("Failed to stop replication: %v", err)
+		}
+	}
+
+	if s.scheduler != nil {
+		s.scheduler.stop()
+	}
+
+	if s.alerts != nil {
+		s.alerts.stop()
+	}
+
+	s.recordEvent(context.Background(), EventCategoryServer, s.UUID.String(), "stopped", nil)
+
 	if !s.Options.usingCustomListener {
 		s.GrpcServer.Stop()
 		defer func() { s.GrpcServer = nil }()
@@ -498,6 +685,9 @@ func (s *ImmuServer) Login(ctx context.Context, r *schema.LoginRequest) (*schema
 	}
 
 	loginResponse := &schema.LoginResponse{Token: token}
+	if !s.multidbmode {
+		loginResponse.Epoch = s.dbList.GetByIndex(DefaultDbIndex).Epoch().Epoch
+	}
 	if u.Username == auth.SysAdminUsername && string(r.GetPassword()) == auth.SysAdminPassword {
 		loginResponse.Warning = []byte(auth.WarnDefaultAdminPassword)
 	}
@@ -590,6 +780,8 @@ func (s *ImmuServer) UpdateAuthConfig(ctx context.Context, req *schema.AuthConfi
 			auth.AuthEnabled, err)
 	}
 
+	s.recordEvent(ctx, EventCategoryConfig, "auth", "changed", map[string]string{"auth": strconv.FormatBool(auth.AuthEnabled)})
+
 	return e, nil
 }
 
@@ -613,6 +805,8 @@ func (s *ImmuServer) UpdateMTLSConfig(ctx context.Context, req *schema.MTLSConfi
 		return e, fmt.Errorf("MTLS could not be set to %t: %v", req.GetEnabled(), err)
 	}
 
+	s.recordEvent(ctx, EventCategoryConfig, "mtls", "changed", map[string]string{"mtls": strconv.FormatBool(req.GetEnabled())})
+
 	return e, status.Errorf(
 		codes.OK,
 		"MTLS set to %t in server config, but server restart is required for it to take effect.",
@@ -624,10 +818,21 @@ func (s *ImmuServer) Health(ctx context.Context, e *empty.Empty) (*schema.Health
 	ind, _ := s.getDbIndexFromCtx(ctx, "Health")
 
 	if ind < 0 { //probably immuclient hasn't logged in yet
-		return s.dbList.GetByIndex(DefaultDbIndex).Health(e)
+		ind = DefaultDbIndex
+	}
+
+	health, err := s.dbList.GetByIndex(ind).Health(e)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < s.dbList.Length(); i++ {
+		if rp := s.dbList.GetByIndex(int64(i)).RecoveryProgress(); rp != nil {
+			health.Recovery = append(health.Recovery, rp)
+		}
 	}
 
-	return s.dbList.GetByIndex(ind).Health(e)
+	return health, nil
 }
 
 // CurrentState ...
@@ -654,6 +859,54 @@ func (s *ImmuServer) CurrentState(ctx context.Context, e *empty.Empty) (*schema.
 	return state, nil
 }
 
+// CompareState tells whether the selected database is ahead of, behind of or forked from the history known by the client
+func (s *ImmuServer) CompareState(ctx context.Context, req *schema.StateComparisonRequest) (*schema.StateComparison, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "CompareState")
+	if err != nil {
+		return nil, err
+	}
+
+	db := s.dbList.GetByIndex(ind)
+
+	cmp, err := db.CompareState(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if cmp.Relation == schema.StateRelation_STATE_FORKED {
+		s.recordEvent(ctx, EventCategoryDatabase, db.GetOptions().GetDbName(), "history fork detected", map[string]string{
+			"lastCommonTx":     strconv.FormatUint(cmp.LastCommonTx, 10),
+			"firstDivergentTx": strconv.FormatUint(cmp.FirstDivergentTx, 10),
+		})
+	}
+
+	return cmp, nil
+}
+
+// CurrentCombinedState returns the current state of both the database and its SQL catalog, signed as a whole
+func (s *ImmuServer) CurrentCombinedState(ctx context.Context, req *schema.VerifiableCombinedStateRequest) (*schema.VerifiableCombinedState, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "CurrentCombinedState")
+	if err != nil {
+		return nil, err
+	}
+
+	vstate, err := s.dbList.GetByIndex(ind).VerifiableCombinedState(req)
+	if err != nil {
+		return nil, err
+	}
+
+	vstate.State.Db = s.dbList.GetByIndex(ind).GetOptions().GetDbName()
+
+	if s.Options.SigningKey != "" {
+		err = s.StateSigner.SignCombinedState(vstate.State)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return vstate, nil
+}
+
 // Set ...
 func (s *ImmuServer) Set(ctx context.Context, kv *schema.SetRequest) (*schema.TxMetadata, error) {
 	ind, err := s.getDbIndexFromCtx(ctx, "Set")
@@ -662,9 +915,74 @@ func (s *ImmuServer) Set(ctx context.Context, kv *schema.SetRequest) (*schema.Tx
 		return nil, err
 	}
 
+	if kv != nil {
+		kv.Attribution = s.attribution(ctx, kv.Attribution)
+
+		kv.IdempotencyKey, err = s.idempotencyKey(ctx, ind, kv.IdempotencyKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return s.dbList.GetByIndex(ind).Set(kv)
 }
 
+// SetIf sets a key only if its current value is the expected one
+func (s *ImmuServer) SetIf(ctx context.Context, req *schema.SetIfRequest) (*schema.TxMetadata, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "SetIf")
+	if err != nil {
+		return nil, err
+	}
+
+	if req != nil {
+		req.Attribution = s.attribution(ctx, req.Attribution)
+	}
+
+	return s.dbList.GetByIndex(ind).SetIf(req)
+}
+
+// Delete marks keys as deleted, their previous values are still listed by History
+func (s *ImmuServer) Delete(ctx context.Context, req *schema.DeleteKeysRequest) (*schema.TxMetadata, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "Delete")
+	if err != nil {
+		return nil, err
+	}
+
+	if req != nil {
+		req.Attribution = s.attribution(ctx, req.Attribution)
+	}
+
+	return s.dbList.GetByIndex(ind).Delete(req)
+}
+
+// DeleteByPrefix marks every key under a prefix as deleted, in a bounded number of transactions
+func (s *ImmuServer) DeleteByPrefix(ctx context.Context, req *schema.DeleteByPrefixRequest) (*schema.DeleteByPrefixResponse, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "DeleteByPrefix")
+	if err != nil {
+		return nil, err
+	}
+
+	if req != nil {
+		req.Attribution = s.attribution(ctx, req.Attribution)
+	}
+
+	return s.dbList.GetByIndex(ind).DeleteByPrefix(req)
+}
+
+// Increment atomically adds a signed delta to the integer held by a key
+func (s *ImmuServer) Increment(ctx context.Context, req *schema.IncrementRequest) (*schema.IncrementResponse, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "Increment")
+	if err != nil {
+		return nil, err
+	}
+
+	if req != nil {
+		req.Attribution = s.attribution(ctx, req.Attribution)
+	}
+
+	return s.dbList.GetByIndex(ind).Increment(req)
+}
+
 // VerifiableSet ...
 func (s *ImmuServer) VerifiableSet(ctx context.Context, req *schema.VerifiableSetRequest) (*schema.VerifiableTx, error) {
 	ind, err := s.getDbIndexFromCtx(ctx, "VerifiableSet")
@@ -672,6 +990,15 @@ func (s *ImmuServer) VerifiableSet(ctx context.Context, req *schema.VerifiableSe
 		return nil, err
 	}
 
+	if req != nil && req.SetRequest != nil {
+		req.SetRequest.Attribution = s.attribution(ctx, req.SetRequest.Attribution)
+
+		req.SetRequest.IdempotencyKey, err = s.idempotencyKey(ctx, ind, req.SetRequest.IdempotencyKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	vtx, err := s.dbList.GetByIndex(ind).VerifiableSet(req)
 	if err != nil {
 		return nil, err
@@ -721,16 +1048,7 @@ func (s *ImmuServer) VerifiableGet(ctx context.Context, req *schema.VerifiableGe
 	}
 
 	if s.Options.SigningKey != "" {
-		md := schema.TxMetadataFrom(vEntry.VerifiableTx.DualProof.TargetTxMetadata)
-		alh := md.Alh()
-
-		newState := &schema.ImmutableState{
-			Db:     s.dbList.GetByIndex(ind).GetOptions().GetDbName(),
-			TxId:   md.ID,
-			TxHash: alh[:],
-		}
-
-		err = s.StateSigner.Sign(newState)
+		newState, err := s.targetTxState(s.dbList.GetByIndex(ind).GetOptions().GetDbName(), vEntry.VerifiableTx.DualProof)
 		if err != nil {
 			return nil, err
 		}
@@ -741,6 +1059,27 @@ func (s *ImmuServer) VerifiableGet(ctx context.Context, req *schema.VerifiableGe
 	return vEntry, nil
 }
 
+// targetTxState returns the state as of the target transaction of the proof, signed when the server has a signing key
+func (s *ImmuServer) targetTxState(dbName string, dualProof *schema.DualProof) (*schema.ImmutableState, error) {
+	md := schema.TxMetadataFrom(dualProof.TargetTxMetadata)
+	alh := md.Alh()
+
+	state := &schema.ImmutableState{
+		Db:     dbName,
+		TxId:   md.ID,
+		TxHash: alh[:],
+	}
+
+	if s.Options.SigningKey != "" {
+		err := s.StateSigner.Sign(state)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return state, nil
+}
+
 // Scan ...
 func (s *ImmuServer) Scan(ctx context.Context, req *schema.ScanRequest) (*schema.Entries, error) {
 	ind, err := s.getDbIndexFromCtx(ctx, "Scan")
@@ -748,7 +1087,7 @@ func (s *ImmuServer) Scan(ctx context.Context, req *schema.ScanRequest) (*schema
 		return nil, err
 	}
 
-	return s.dbList.GetByIndex(ind).Scan(req)
+	return s.dbList.GetByIndex(ind).Scan(ctx, req)
 }
 
 // Count ...
@@ -787,6 +1126,25 @@ func (s *ImmuServer) TxById(ctx context.Context, req *schema.TxRequest) (*schema
 	return s.dbList.GetByIndex(ind).TxByID(req)
 }
 
+// ExportTx returns the metadata and raw entries of a transaction, used to replicate it
+func (s *ImmuServer) ExportTx(ctx context.Context, req *schema.TxRequest) (*schema.ExportedTx, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "ExportTx")
+	if err != nil {
+		return nil, err
+	}
+
+	etx, err := s.dbList.GetByIndex(ind).ExportTx(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.replicationThrottler != nil {
+		s.replicationThrottler.Wait(proto.Size(etx))
+	}
+
+	return etx, nil
+}
+
 // VerifiableTxByID ...
 func (s *ImmuServer) VerifiableTxById(ctx context.Context, req *schema.VerifiableTxRequest) (*schema.VerifiableTx, error) {
 	ind, err := s.getDbIndexFromCtx(ctx, "VerifiableTxByID")
@@ -820,6 +1178,50 @@ func (s *ImmuServer) VerifiableTxById(ctx context.Context, req *schema.Verifiabl
 	return vtx, nil
 }
 
+// TxRangeProofs returns the proofs of a range of transactions, split in chunks, along with the signed state at its final transaction
+func (s *ImmuServer) TxRangeProofs(ctx context.Context, req *schema.TxRangeProofsRequest) (*schema.VerifiableTxRange, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "TxRangeProofs")
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.dbList.GetByIndex(ind).TxRangeProofs(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.Options.SigningKey != "" {
+		err = s.StateSigner.Sign(res.State)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// ConsistencyProof returns the proof that a transaction is consistent with an earlier one, along with the signed state at the later one
+func (s *ImmuServer) ConsistencyProof(ctx context.Context, req *schema.ConsistencyProofRequest) (*schema.VerifiableConsistency, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "ConsistencyProof")
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.dbList.GetByIndex(ind).ConsistencyProof(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.Options.SigningKey != "" {
+		err = s.StateSigner.Sign(res.State)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
 // TxScan ...
 func (s *ImmuServer) TxScan(ctx context.Context, req *schema.TxScanRequest) (*schema.TxList, error) {
 	ind, err := s.getDbIndexFromCtx(ctx, "TxScan")
@@ -830,6 +1232,16 @@ func (s *ImmuServer) TxScan(ctx context.Context, req *schema.TxScanRequest) (*sc
 	return s.dbList.GetByIndex(ind).TxScan(req)
 }
 
+// Diff returns the keys modified between two transactions
+func (s *ImmuServer) Diff(ctx context.Context, req *schema.DiffRequest) (*schema.DiffResponse, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "Diff")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.dbList.GetByIndex(ind).Diff(req)
+}
+
 // History ...
 func (s *ImmuServer) History(ctx context.Context, req *schema.HistoryRequest) (*schema.Entries, error) {
 	ind, err := s.getDbIndexFromCtx(ctx, "History")
@@ -893,6 +1305,16 @@ func (s *ImmuServer) ZAdd(ctx context.Context, req *schema.ZAddRequest) (*schema
 	return s.dbList.GetByIndex(ind).ZAdd(req)
 }
 
+// ZAddMulti ...
+func (s *ImmuServer) ZAddMulti(ctx context.Context, req *schema.ZAddMultiRequest) (*schema.TxMetadata, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "ZAddMulti")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.dbList.GetByIndex(ind).ZAddMulti(req)
+}
+
 // ZScan ...
 func (s *ImmuServer) ZScan(ctx context.Context, req *schema.ZScanRequest) (*schema.ZEntries, error) {
 	ind, err := s.getDbIndexFromCtx(ctx, "ZScan")
@@ -900,7 +1322,97 @@ func (s *ImmuServer) ZScan(ctx context.Context, req *schema.ZScanRequest) (*sche
 		return nil, err
 	}
 
-	return s.dbList.GetByIndex(ind).ZScan(req)
+	return s.dbList.GetByIndex(ind).ZScan(ctx, req)
+}
+
+// ZRem ...
+func (s *ImmuServer) ZRem(ctx context.Context, req *schema.ZRemRequest) (*schema.TxMetadata, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "ZRem")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.dbList.GetByIndex(ind).ZRem(req)
+}
+
+// ZCount ...
+func (s *ImmuServer) ZCount(ctx context.Context, req *schema.ZCountRequest) (*schema.EntryCount, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "ZCount")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.dbList.GetByIndex(ind).ZCount(ctx, req)
+}
+
+// ZCard ...
+func (s *ImmuServer) ZCard(ctx context.Context, req *schema.ZCardRequest) (*schema.EntryCount, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "ZCard")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.dbList.GetByIndex(ind).ZCard(ctx, req)
+}
+
+// ZRank ...
+func (s *ImmuServer) ZRank(ctx context.Context, req *schema.ZRankRequest) (*schema.ZRankResult, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "ZRank")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.dbList.GetByIndex(ind).ZRank(ctx, req)
+}
+
+// ZIncrBy ...
+func (s *ImmuServer) ZIncrBy(ctx context.Context, req *schema.ZIncrByRequest) (*schema.ZIncrByResult, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "ZIncrBy")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.dbList.GetByIndex(ind).ZIncrBy(req)
+}
+
+// ZUnionStore ...
+func (s *ImmuServer) ZUnionStore(ctx context.Context, req *schema.ZStoreRequest) (*schema.ZStoreResult, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "ZUnionStore")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.dbList.GetByIndex(ind).ZUnionStore(req)
+}
+
+// ZInterStore ...
+func (s *ImmuServer) ZInterStore(ctx context.Context, req *schema.ZStoreRequest) (*schema.ZStoreResult, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "ZInterStore")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.dbList.GetByIndex(ind).ZInterStore(req)
+}
+
+// ZPopMin ...
+func (s *ImmuServer) ZPopMin(ctx context.Context, req *schema.ZPopRequest) (*schema.ZPopResult, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "ZPopMin")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.dbList.GetByIndex(ind).ZPopMin(req)
+}
+
+// ZPopMax ...
+func (s *ImmuServer) ZPopMax(ctx context.Context, req *schema.ZPopRequest) (*schema.ZPopResult, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "ZPopMax")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.dbList.GetByIndex(ind).ZPopMax(req)
 }
 
 // VerifiableZAdd ...
@@ -1007,6 +1519,8 @@ func (s *ImmuServer) ChangePassword(ctx context.Context, r *schema.ChangePasswor
 	// invalidate the token for this user
 	auth.DropTokenKeys(targetUser.Username)
 
+	s.recordEvent(ctx, EventCategoryUser, targetUser.Username, "password changed", nil)
+
 	return new(empty.Empty), nil
 }
 
@@ -1027,7 +1541,7 @@ func (s *ImmuServer) CreateDatabase(ctx context.Context, newdb *schema.Database)
 		return nil, fmt.Errorf("Logged In user does not have permissions for this operation")
 	}
 
-	if newdb.DatabaseName == SystemdbName {
+	if newdb.DatabaseName == SystemdbName || newdb.DatabaseName == EventsdbName {
 		return nil, fmt.Errorf("this database name is reserved")
 	}
 
@@ -1045,13 +1559,28 @@ func (s *ImmuServer) CreateDatabase(ctx context.Context, newdb *schema.Database)
 		return nil, fmt.Errorf("database %s already exists", newdb.GetDatabaseName())
 	}
 
+	compressionFormat, err := appendable.CompressionFormatByName(newdb.Compression)
+	if err != nil {
+		return nil, err
+	}
+
 	dataDir := s.Options.Dir
 
 	op := database.DefaultOption().
 		WithDbName(newdb.DatabaseName).
 		WithDbRootPath(dataDir).
 		WithDbRootPath(s.Options.Dir).
-		WithStoreOptions(s.Options.StoreOptions)
+		WithStoreOptions(s.Options.StoreOptions).
+		WithPrefixStatsDepth(s.Options.PrefixStatsDepth).
+		WithPrefixStatsSeparator(s.Options.PrefixStatsSeparator).
+		WithSQLTxTimeout(s.Options.SQLTxTimeout).
+		WithIteratorLeaseTimeout(s.Options.IteratorLeaseTimeout).
+		WithIdempotencyWindow(s.Options.IdempotencyWindow).
+		WithIdempotencyMaxKeys(s.Options.IdempotencyMaxKeys).
+		WithIdempotencyPersisted(s.Options.IdempotencyPersisted).
+		WithSQLCacheSize(s.Options.SQLCacheSize).
+		WithWORM(newdb.Worm, newdb.WormMaxVersions).
+		WithCompressionFormat(compressionFormat)
 
 	db, err := database.NewDb(op, s.sysDb, s.Logger)
 	if err != nil {
@@ -1062,6 +1591,8 @@ func (s *ImmuServer) CreateDatabase(ctx context.Context, newdb *schema.Database)
 	s.dbList.Append(db)
 	s.multidbmode = true
 
+	s.recordEvent(ctx, EventCategoryDatabase, newdb.DatabaseName, "created", nil)
+
 	return &empty.Empty{}, nil
 }
 
@@ -1099,6 +1630,10 @@ func (s *ImmuServer) CreateUser(ctx context.Context, r *schema.CreateUserRequest
 			return nil, fmt.Errorf("database %s does not exist", r.Database)
 		}
 
+		if r.Database == EventsdbName {
+			return nil, fmt.Errorf("this database can not be assigned")
+		}
+
 		//check permission is a known value
 		if (r.Permission == auth.PermissionNone) ||
 			((r.Permission > auth.PermissionRW) &&
@@ -1128,6 +1663,11 @@ func (s *ImmuServer) CreateUser(ctx context.Context, r *schema.CreateUserRequest
 		return nil, err
 	}
 
+	s.recordEvent(ctx, EventCategoryUser, string(r.User), "created", map[string]string{
+		"database":   r.Database,
+		"permission": strconv.FormatUint(uint64(r.Permission), 10),
+	})
+
 	return &empty.Empty{}, nil
 }
 
@@ -1150,7 +1690,7 @@ func (s *ImmuServer) ListUsers(ctx context.Context, req *empty.Empty) (*schema.U
 		}
 	}
 
-	itemList, err := s.sysDb.Scan(&schema.ScanRequest{
+	itemList, err := s.sysDb.Scan(ctx, &schema.ScanRequest{
 		Prefix:  []byte{KeyPrefixUser},
 		SinceTx: math.MaxUint64,
 		NoWait:  true,
@@ -1264,13 +1804,15 @@ func (s *ImmuServer) DatabaseList(ctx context.Context, req *empty.Empty) (*schem
 	if loggedInuser.IsSysAdmin || s.Options.GetMaintenance() {
 		for i := 0; i < s.dbList.Length(); i++ {
 			val := s.dbList.GetByIndex(int64(i))
-			if val.GetOptions().GetDbName() == SystemdbName {
-				//do not put sysemdb in the list
+			if val.GetOptions().GetDbName() == SystemdbName || val.GetOptions().GetDbName() == EventsdbName {
+				//do not put sysemdb nor the built-in events database in the list
 				continue
 			}
 			db := &schema.Database{
 				DatabaseName: val.GetOptions().GetDbName(),
 			}
+			db.Worm, db.WormMaxVersions = val.WORMMode()
+			db.Compression = appendable.CompressionFormatName(val.CompressionFormat())
 			dbList.Databases = append(dbList.Databases, db)
 		}
 	} else {
@@ -1278,6 +1820,10 @@ func (s *ImmuServer) DatabaseList(ctx context.Context, req *empty.Empty) (*schem
 			db := &schema.Database{
 				DatabaseName: val.Database,
 			}
+			if id := s.dbList.GetId(val.Database); id >= 0 {
+				db.Worm, db.WormMaxVersions = s.dbList.GetByIndex(id).WORMMode()
+				db.Compression = appendable.CompressionFormatName(s.dbList.GetByIndex(id).CompressionFormat())
+			}
 			dbList.Databases = append(dbList.Databases, db)
 		}
 	}
@@ -1339,6 +1885,7 @@ func (s *ImmuServer) UseDatabase(ctx context.Context, db *schema.Database) (*sch
 
 	return &schema.UseDatabaseReply{
 		Token: token,
+		Epoch: s.dbList.GetByIndex(dbid).Epoch().Epoch,
 	}, nil
 }
 
@@ -1352,16 +1899,44 @@ func (s *ImmuServer) CleanIndex(ctx context.Context, req *empty.Empty) (*empty.E
 		return nil, err
 	}
 
-	err = s.dbList.GetByIndex(ind).CompactIndex()
+	db := s.dbList.GetByIndex(ind)
+
+	err = s.jobs.run(ctx, JobCompaction, db.GetName(), false, func(context.Context, func(uint32)) error {
+		return db.CompactIndex()
+	})
 
 	return &empty.Empty{}, err
 }
 
+// TopPrefixes returns the most accessed key prefixes of the selected database
+func (s *ImmuServer) TopPrefixes(ctx context.Context, req *schema.TopPrefixesRequest) (*schema.PrefixStatsList, error) {
+	if req == nil {
+		return nil, ErrIllegalArguments
+	}
+
+	ind, err := s.getDbIndexFromCtx(ctx, "TopPrefixes")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.dbList.GetByIndex(ind).TopPrefixes(req)
+}
+
+// KeyspaceHistogram returns the number of keys and the space taken by them grouped by prefix
+func (s *ImmuServer) KeyspaceHistogram(ctx context.Context, req *schema.KeyspaceHistogramRequest) (*schema.KeyspaceHistogramResult, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "KeyspaceHistogram")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.dbList.GetByIndex(ind).KeyspaceHistogram(ctx, req)
+}
+
 //ChangePermission grant or revoke user permissions on databases
 func (s *ImmuServer) ChangePermission(ctx context.Context, r *schema.ChangePermissionRequest) (*empty.Empty, error) {
 	s.Logger.Debugf("ChangePermission %+v", r)
 
-	if r.Database == SystemdbName {
+	if r.Database == SystemdbName || r.Database == EventsdbName {
 		return nil, fmt.Errorf("this database can not be assigned")
 	}
 
@@ -1434,6 +2009,11 @@ func (s *ImmuServer) ChangePermission(ctx context.Context, r *schema.ChangePermi
 	//remove user from loggedin users
 	s.removeUserFromLoginList(targetUser.Username)
 
+	s.recordEvent(ctx, EventCategoryUser, targetUser.Username, "permission "+strings.ToLower(r.Action.String()), map[string]string{
+		"database":   r.Database,
+		"permission": strconv.FormatUint(uint64(r.Permission), 10),
+	})
+
 	return new(empty.Empty), nil
 }
 
@@ -1488,6 +2068,13 @@ func (s *ImmuServer) SetActiveUser(ctx context.Context, r *schema.SetActiveUserR
 
 	//remove user from loggedin users
 	s.removeUserFromLoginList(targetUser.Username)
+
+	if r.Active {
+		s.recordEvent(ctx, EventCategoryUser, targetUser.Username, "activated", nil)
+	} else {
+		s.recordEvent(ctx, EventCategoryUser, targetUser.Username, "deactivated", nil)
+	}
+
 	return new(empty.Empty), nil
 }
 
@@ -1497,6 +2084,9 @@ func (s *ImmuServer) getDbIndexFromCtx(ctx context.Context, methodname string) (
 	//if auth is disabled return index zero (defaultdb) as it is the first database created/loaded
 	if !s.Options.auth {
 		if !s.multidbmode {
+			if err := s.checkEpoch(ctx, DefaultDbIndex, methodname); err != nil {
+				return 0, err
+			}
 			return DefaultDbIndex, nil
 		}
 	}
@@ -1516,6 +2106,14 @@ func (s *ImmuServer) getDbIndexFromCtx(ctx context.Context, methodname string) (
 		return 0, fmt.Errorf("please select a database first")
 	}
 
+	if err := s.checkEventsDbMethod(ind, methodname); err != nil {
+		return 0, err
+	}
+
+	if err := s.checkEpoch(ctx, ind, methodname); err != nil {
+		return 0, err
+	}
+
 	if usr.IsSysAdmin {
 		return ind, nil
 	}
@@ -1603,6 +2201,18 @@ func (s *ImmuServer) getValidatedUser(username []byte, password []byte) (*auth.U
 		return nil, status.Errorf(codes.PermissionDenied, "invalid user or password")
 	}
 
+	// bcrypt hashes are not approved in FIPS mode, they are replaced once the password is verified
+	upgradedHash, err := auth.UpgradePasswordHash(userdata.HashedPassword, password)
+	if err != nil {
+		s.Logger.Warningf("unable to upgrade password hash of user %s: %v", userdata.Username, err)
+	} else if upgradedHash != nil {
+		userdata.HashedPassword = upgradedHash
+
+		if err := s.saveUser(userdata); err != nil {
+			s.Logger.Warningf("unable to upgrade password hash of user %s: %v", userdata.Username, err)
+		}
+	}
+
 	return userdata, nil
 }
 
@@ -1701,7 +2311,8 @@ func (s *ImmuServer) mandatoryAuth() bool {
 	for i := 0; i < s.dbList.Length(); i++ {
 		val := s.dbList.GetByIndex(int64(i))
 		if (val.GetOptions().GetDbName() != s.Options.defaultDbName) &&
-			(val.GetOptions().GetDbName() != s.Options.systemAdminDbName) {
+			(val.GetOptions().GetDbName() != s.Options.systemAdminDbName) &&
+			(val.GetOptions().GetDbName() != EventsdbName) {
 			return true
 		}
 	}
@@ -1713,7 +2324,7 @@ func (s *ImmuServer) mandatoryAuth() bool {
 
 	if s.sysDb != nil {
 		//check if there is only sysadmin on systemdb and no other user
-		itemList, err := s.sysDb.Scan(&schema.ScanRequest{
+		itemList, err := s.sysDb.Scan(context.Background(), &schema.ScanRequest{
 			Prefix: []byte{KeyPrefixUser},
 		})
 
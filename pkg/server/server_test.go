@@ -18,6 +18,7 @@ package server
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -34,6 +35,7 @@ import (
 	"github.com/codenotary/immudb/pkg/api/schema"
 	"github.com/codenotary/immudb/pkg/auth"
 	"github.com/codenotary/immudb/pkg/database"
+	"github.com/codenotary/immudb/pkg/fips"
 	"github.com/codenotary/immudb/pkg/immuos"
 	"github.com/codenotary/immudb/pkg/logger"
 	"github.com/golang/protobuf/ptypes/empty"
@@ -42,6 +44,7 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
@@ -75,6 +78,13 @@ func TestLogErr(t *testing.T) {
 	require.Error(t, logErr(logger, "error: %v", err))
 }
 
+func TestCheckFIPSCompliance(t *testing.T) {
+	require.NoError(t, checkFIPSCompliance(DefaultOptions()))
+
+	opts := DefaultOptions().WithTLS(&tls.Config{MinVersion: tls.VersionTLS10})
+	require.True(t, errors.Is(checkFIPSCompliance(opts), fips.ErrNotApproved))
+}
+
 func TestServerDefaultDatabaseLoad(t *testing.T) {
 	options := database.DefaultOption()
 	dbRootpath := options.GetDbRootPath()
@@ -188,6 +198,42 @@ func TestServerErrChunkSizeTooSmall(t *testing.T) {
 	assert.Equal(t, stream.ErrChunkTooSmall, err)
 }
 
+func TestServerStreamBandwidthScope(t *testing.T) {
+	serverOptions := DefaultOptions().WithMetricsServer(false).WithStreamBandwidthScope("backups")
+	s := DefaultServer().WithOptions(serverOptions).(*ImmuServer)
+	defer os.RemoveAll(s.Options.Dir)
+
+	err := s.Initialize()
+	assert.Equal(t, ErrInvalidStreamBandwidthScope, err)
+
+	// by default only the transactions exported to replicas are throttled
+	serverOptions = DefaultOptions().
+		WithMetricsServer(false).
+		WithListener(bufconn.Listen(1024 * 1024)).
+		WithStreamBandwidth(1 << 20)
+	s = DefaultServer().WithOptions(serverOptions).(*ImmuServer)
+	defer os.RemoveAll(s.Options.Dir)
+
+	factory := s.StreamServiceFactory
+
+	require.NoError(t, s.Initialize())
+	assert.NotNil(t, s.replicationThrottler)
+	assert.True(t, s.StreamServiceFactory == factory)
+
+	serverOptions = DefaultOptions().
+		WithMetricsServer(false).
+		WithListener(bufconn.Listen(1024 * 1024)).
+		WithStreamBandwidth(1 << 20).
+		WithStreamBandwidthScope(StreamBandwidthScopeAll).
+		WithDir("data_stream_bandwidth_all")
+	s = DefaultServer().WithOptions(serverOptions).(*ImmuServer)
+	defer os.RemoveAll(s.Options.Dir)
+
+	require.NoError(t, s.Initialize())
+	assert.NotNil(t, s.replicationThrottler)
+	assert.False(t, s.StreamServiceFactory == factory)
+}
+
 func TestServerLogin(t *testing.T) {
 	serverOptions := DefaultOptions().WithMetricsServer(false).WithAdminPassword(auth.SysAdminPassword)
 	s := DefaultServer().WithOptions(serverOptions).(*ImmuServer)
@@ -388,7 +434,8 @@ func TestServerLoaduserDatabase(t *testing.T) {
 
 	time.Sleep(1 * time.Second)
 
-	if s.dbList.Length() != 2 {
+	// defaultdb, the events database and the user database
+	if s.dbList.Length() != 3 {
 		t.Fatalf("LoadUserDatabase error %d", s.dbList.Length())
 	}
 }
@@ -937,6 +984,9 @@ func testServerHealth(ctx context.Context, s *ImmuServer, t *testing.T) {
 	if !h.GetStatus() {
 		t.Fatalf("Health, expected %v, got %v", true, h.GetStatus())
 	}
+	if len(h.GetRecovery()) > 0 {
+		t.Fatalf("Health, expected no recovery in progress, got %v", h.GetRecovery())
+	}
 }
 
 func testServerHealthError(ctx context.Context, s *ImmuServer, t *testing.T) {
@@ -1950,3 +2000,39 @@ func TestServerLoginAttempWithEmptyPassword(t *testing.T) {
 
 	require.Contains(t, err.Error(), "invalid user name or password")
 }
+
+func TestServerTopPrefixes(t *testing.T) {
+	serverOptions := DefaultOptions().
+		WithMetricsServer(false).
+		WithAdminPassword(auth.SysAdminPassword).
+		WithPrefixStatsDepth(1).
+		WithListener(bufconn.Listen(1024 * 1024))
+	s := DefaultServer().WithOptions(serverOptions).(*ImmuServer)
+	defer os.RemoveAll(s.Options.Dir)
+
+	require.NoError(t, s.Initialize())
+
+	lr, err := s.Login(context.Background(), &schema.LoginRequest{
+		User:     []byte(auth.SysAdminUsername),
+		Password: []byte(auth.SysAdminPassword),
+	})
+	require.NoError(t, err)
+
+	md := metadata.Pairs("authorization", lr.Token)
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+
+	_, err = s.TopPrefixes(ctx, nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	_, err = s.Set(ctx, &schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("tenant1:k1"), Value: []byte("v1")}}})
+	require.NoError(t, err)
+
+	list, err := s.TopPrefixes(ctx, &schema.TopPrefixesRequest{By: "writes"})
+	require.NoError(t, err)
+	require.Len(t, list.Prefixes, 1)
+	require.Equal(t, []byte("tenant1:"), list.Prefixes[0].Prefix)
+	require.Equal(t, uint64(1), list.Prefixes[0].Writes)
+
+	prefixes := s.metricFuncComputeDBPrefixStats()
+	require.Len(t, prefixes[DefaultdbName], 1)
+}
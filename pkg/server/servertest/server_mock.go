@@ -73,6 +73,22 @@ func (s *ServerMock) StreamHistory(request *schema.HistoryRequest, historyServer
 	return s.Srv.StreamHistory(request, historyServer)
 }
 
+func (s *ServerMock) HistoryStream(request *schema.HistoryStreamRequest, historyServer schema.ImmuService_HistoryStreamServer) error {
+	return s.Srv.HistoryStream(request, historyServer)
+}
+
+func (s *ServerMock) ZScanStream(request *schema.ZScanStreamRequest, zscanServer schema.ImmuService_ZScanStreamServer) error {
+	return s.Srv.ZScanStream(request, zscanServer)
+}
+
+func (s *ServerMock) SetStream(str schema.ImmuService_SetStreamServer) error {
+	return s.Srv.SetStream(str)
+}
+
+func (s *ServerMock) GetStream(req *schema.KeyRequest, str schema.ImmuService_GetStreamServer) error {
+	return s.Srv.GetStream(req, str)
+}
+
 func (s *ServerMock) ListUsers(ctx context.Context, req *empty.Empty) (*schema.UserList, error) {
 	return s.Srv.ListUsers(ctx, req)
 }
@@ -156,10 +172,18 @@ func (s *ServerMock) TxById(ctx context.Context, req *schema.TxRequest) (*schema
 	return s.Srv.TxById(ctx, req)
 }
 
+func (s *ServerMock) ExportTx(ctx context.Context, req *schema.TxRequest) (*schema.ExportedTx, error) {
+	return s.Srv.ExportTx(ctx, req)
+}
+
 func (s *ServerMock) VerifiableTxById(ctx context.Context, req *schema.VerifiableTxRequest) (*schema.VerifiableTx, error) {
 	return s.Srv.VerifiableTxById(ctx, req)
 }
 
+func (s *ServerMock) TxRangeProofs(ctx context.Context, req *schema.TxRangeProofsRequest) (*schema.VerifiableTxRange, error) {
+	return s.Srv.TxRangeProofs(ctx, req)
+}
+
 func (s *ServerMock) TxScan(ctx context.Context, req *schema.TxScanRequest) (*schema.TxList, error) {
 	return s.Srv.TxScan(ctx, req)
 }
@@ -172,6 +196,10 @@ func (s *ServerMock) Health(ctx context.Context, req *empty.Empty) (*schema.Heal
 	return s.Srv.Health(ctx, req)
 }
 
+func (s *ServerMock) CurrentCombinedState(ctx context.Context, req *schema.VerifiableCombinedStateRequest) (*schema.VerifiableCombinedState, error) {
+	return s.Srv.CurrentCombinedState(ctx, req)
+}
+
 func (s *ServerMock) CurrentState(ctx context.Context, req *empty.Empty) (*schema.ImmutableState, error) {
 	return s.Srv.CurrentState(ctx, req)
 }
@@ -264,6 +292,242 @@ func (s *ServerMock) SQLQuery(ctx context.Context, req *schema.SQLQueryRequest)
 	return s.Srv.SQLQuery(ctx, req)
 }
 
+func (s *ServerMock) NewSQLTx(ctx context.Context, req *schema.NewSQLTxRequest) (*schema.SQLTx, error) {
+	return s.Srv.NewSQLTx(ctx, req)
+}
+
+func (s *ServerMock) SQLTxExec(ctx context.Context, req *schema.SQLTxExecRequest) (*empty.Empty, error) {
+	return s.Srv.SQLTxExec(ctx, req)
+}
+
+func (s *ServerMock) SQLTxQuery(ctx context.Context, req *schema.SQLTxQueryRequest) (*schema.SQLQueryResult, error) {
+	return s.Srv.SQLTxQuery(ctx, req)
+}
+
+func (s *ServerMock) CommitSQLTx(ctx context.Context, req *schema.SQLTxRequest) (*schema.SQLExecResult, error) {
+	return s.Srv.CommitSQLTx(ctx, req)
+}
+
+func (s *ServerMock) RollbackSQLTx(ctx context.Context, req *schema.SQLTxRequest) (*empty.Empty, error) {
+	return s.Srv.RollbackSQLTx(ctx, req)
+}
+
+func (s *ServerMock) OpenIterator(ctx context.Context, req *schema.OpenIteratorRequest) (*schema.IteratorLease, error) {
+	return s.Srv.OpenIterator(ctx, req)
+}
+
+func (s *ServerMock) IteratorNext(ctx context.Context, req *schema.IteratorNextRequest) (*schema.IteratorPage, error) {
+	return s.Srv.IteratorNext(ctx, req)
+}
+
+func (s *ServerMock) KeepAliveIterator(ctx context.Context, req *schema.IteratorLeaseRequest) (*schema.IteratorLease, error) {
+	return s.Srv.KeepAliveIterator(ctx, req)
+}
+
+func (s *ServerMock) CloseIterator(ctx context.Context, req *schema.IteratorLeaseRequest) (*empty.Empty, error) {
+	return s.Srv.CloseIterator(ctx, req)
+}
+
+func (s *ServerMock) SetKeyRules(ctx context.Context, req *schema.KeyRules) (*empty.Empty, error) {
+	return s.Srv.SetKeyRules(ctx, req)
+}
+
+func (s *ServerMock) GetKeyRules(ctx context.Context, req *empty.Empty) (*schema.KeyRules, error) {
+	return s.Srv.GetKeyRules(ctx, req)
+}
+
+func (s *ServerMock) Watch(req *schema.WatchRequest, server schema.ImmuService_WatchServer) error {
+	return s.Srv.Watch(req, server)
+}
+
+func (s *ServerMock) WatchAck(ctx context.Context, req *schema.WatchAckRequest) (*empty.Empty, error) {
+	return s.Srv.WatchAck(ctx, req)
+}
+
+func (s *ServerMock) Unwatch(ctx context.Context, req *schema.UnwatchRequest) (*empty.Empty, error) {
+	return s.Srv.Unwatch(ctx, req)
+}
+
+func (s *ServerMock) ScheduleJob(ctx context.Context, req *schema.JobSchedule) (*empty.Empty, error) {
+	return s.Srv.ScheduleJob(ctx, req)
+}
+
+func (s *ServerMock) UnscheduleJob(ctx context.Context, req *schema.JobScheduleRequest) (*empty.Empty, error) {
+	return s.Srv.UnscheduleJob(ctx, req)
+}
+
+func (s *ServerMock) ListJobSchedules(ctx context.Context, req *empty.Empty) (*schema.JobScheduleList, error) {
+	return s.Srv.ListJobSchedules(ctx, req)
+}
+
+func (s *ServerMock) ListJobRuns(ctx context.Context, req *schema.JobRunsRequest) (*schema.JobRunList, error) {
+	return s.Srv.ListJobRuns(ctx, req)
+}
+
+func (s *ServerMock) ListJobs(ctx context.Context, req *empty.Empty) (*schema.JobList, error) {
+	return s.Srv.ListJobs(ctx, req)
+}
+
+func (s *ServerMock) GetJob(ctx context.Context, req *schema.JobRequest) (*schema.Job, error) {
+	return s.Srv.GetJob(ctx, req)
+}
+
+func (s *ServerMock) CancelJob(ctx context.Context, req *schema.JobRequest) (*empty.Empty, error) {
+	return s.Srv.CancelJob(ctx, req)
+}
+
+func (s *ServerMock) WatchJob(req *schema.JobRequest, server schema.ImmuService_WatchJobServer) error {
+	return s.Srv.WatchJob(req, server)
+}
+
+func (s *ServerMock) ZRem(ctx context.Context, req *schema.ZRemRequest) (*schema.TxMetadata, error) {
+	return s.Srv.ZRem(ctx, req)
+}
+
+func (s *ServerMock) ZCount(ctx context.Context, req *schema.ZCountRequest) (*schema.EntryCount, error) {
+	return s.Srv.ZCount(ctx, req)
+}
+
+func (s *ServerMock) ZAddMulti(ctx context.Context, req *schema.ZAddMultiRequest) (*schema.TxMetadata, error) {
+	return s.Srv.ZAddMulti(ctx, req)
+}
+
+func (s *ServerMock) ZCard(ctx context.Context, req *schema.ZCardRequest) (*schema.EntryCount, error) {
+	return s.Srv.ZCard(ctx, req)
+}
+
+func (s *ServerMock) ZRank(ctx context.Context, req *schema.ZRankRequest) (*schema.ZRankResult, error) {
+	return s.Srv.ZRank(ctx, req)
+}
+
+func (s *ServerMock) ZIncrBy(ctx context.Context, req *schema.ZIncrByRequest) (*schema.ZIncrByResult, error) {
+	return s.Srv.ZIncrBy(ctx, req)
+}
+
+func (s *ServerMock) ZUnionStore(ctx context.Context, req *schema.ZStoreRequest) (*schema.ZStoreResult, error) {
+	return s.Srv.ZUnionStore(ctx, req)
+}
+
+func (s *ServerMock) ZInterStore(ctx context.Context, req *schema.ZStoreRequest) (*schema.ZStoreResult, error) {
+	return s.Srv.ZInterStore(ctx, req)
+}
+
+func (s *ServerMock) KeyspaceHistogram(ctx context.Context, req *schema.KeyspaceHistogramRequest) (*schema.KeyspaceHistogramResult, error) {
+	return s.Srv.KeyspaceHistogram(ctx, req)
+}
+
+func (s *ServerMock) ZPopMin(ctx context.Context, req *schema.ZPopRequest) (*schema.ZPopResult, error) {
+	return s.Srv.ZPopMin(ctx, req)
+}
+
+func (s *ServerMock) ZPopMax(ctx context.Context, req *schema.ZPopRequest) (*schema.ZPopResult, error) {
+	return s.Srv.ZPopMax(ctx, req)
+}
+
+func (s *ServerMock) SetApprovalRules(ctx context.Context, req *schema.ApprovalRules) (*empty.Empty, error) {
+	return s.Srv.SetApprovalRules(ctx, req)
+}
+
+func (s *ServerMock) GetApprovalRules(ctx context.Context, req *empty.Empty) (*schema.ApprovalRules, error) {
+	return s.Srv.GetApprovalRules(ctx, req)
+}
+
+func (s *ServerMock) ProposeWrite(ctx context.Context, req *schema.ExecAllRequest) (*schema.PendingWrite, error) {
+	return s.Srv.ProposeWrite(ctx, req)
+}
+
+func (s *ServerMock) ListPendingWrites(ctx context.Context, req *schema.PendingWritesRequest) (*schema.PendingWrites, error) {
+	return s.Srv.ListPendingWrites(ctx, req)
+}
+
+func (s *ServerMock) ApproveWrite(ctx context.Context, req *schema.PendingWriteDecision) (*schema.PendingWrite, error) {
+	return s.Srv.ApproveWrite(ctx, req)
+}
+
+func (s *ServerMock) RejectWrite(ctx context.Context, req *schema.PendingWriteDecision) (*schema.PendingWrite, error) {
+	return s.Srv.RejectWrite(ctx, req)
+}
+
+func (s *ServerMock) PlaceLegalHold(ctx context.Context, req *schema.LegalHoldRequest) (*schema.LegalHold, error) {
+	return s.Srv.PlaceLegalHold(ctx, req)
+}
+
+func (s *ServerMock) LiftLegalHold(ctx context.Context, req *schema.LegalHoldRequest) (*schema.LegalHold, error) {
+	return s.Srv.LiftLegalHold(ctx, req)
+}
+
+func (s *ServerMock) ListLegalHolds(ctx context.Context, req *empty.Empty) (*schema.LegalHolds, error) {
+	return s.Srv.ListLegalHolds(ctx, req)
+}
+
+func (s *ServerMock) LegalHoldHistory(ctx context.Context, req *schema.LegalHoldRequest) (*schema.LegalHolds, error) {
+	return s.Srv.LegalHoldHistory(ctx, req)
+}
+
+func (s *ServerMock) PinSnapshot(ctx context.Context, req *schema.PinSnapshotRequest) (*schema.PinnedSnapshot, error) {
+	return s.Srv.PinSnapshot(ctx, req)
+}
+
+func (s *ServerMock) UnpinSnapshot(ctx context.Context, req *schema.UnpinSnapshotRequest) (*empty.Empty, error) {
+	return s.Srv.UnpinSnapshot(ctx, req)
+}
+
+func (s *ServerMock) ListPinnedSnapshots(ctx context.Context, req *empty.Empty) (*schema.PinnedSnapshots, error) {
+	return s.Srv.ListPinnedSnapshots(ctx, req)
+}
+
+func (s *ServerMock) AdvanceEpoch(ctx context.Context, req *empty.Empty) (*schema.Epoch, error) {
+	return s.Srv.AdvanceEpoch(ctx, req)
+}
+
+func (s *ServerMock) CompareState(ctx context.Context, req *schema.StateComparisonRequest) (*schema.StateComparison, error) {
+	return s.Srv.CompareState(ctx, req)
+}
+
+func (s *ServerMock) AttestRewind(ctx context.Context, req *schema.AttestRewindRequest) (*schema.RewindAttestation, error) {
+	return s.Srv.AttestRewind(ctx, req)
+}
+
+func (s *ServerMock) GetRewindAttestation(ctx context.Context, req *empty.Empty) (*schema.RewindAttestation, error) {
+	return s.Srv.GetRewindAttestation(ctx, req)
+}
+
+func (s *ServerMock) SetIf(ctx context.Context, req *schema.SetIfRequest) (*schema.TxMetadata, error) {
+	return s.Srv.SetIf(ctx, req)
+}
+
+func (s *ServerMock) Diff(ctx context.Context, req *schema.DiffRequest) (*schema.DiffResponse, error) {
+	return s.Srv.Diff(ctx, req)
+}
+
+func (s *ServerMock) Delete(ctx context.Context, req *schema.DeleteKeysRequest) (*schema.TxMetadata, error) {
+	return s.Srv.Delete(ctx, req)
+}
+
+func (s *ServerMock) DeleteByPrefix(ctx context.Context, req *schema.DeleteByPrefixRequest) (*schema.DeleteByPrefixResponse, error) {
+	return s.Srv.DeleteByPrefix(ctx, req)
+}
+
+func (s *ServerMock) Increment(ctx context.Context, req *schema.IncrementRequest) (*schema.IncrementResponse, error) {
+	return s.Srv.Increment(ctx, req)
+}
+
+func (s *ServerMock) SQLRowProvenance(ctx context.Context, req *schema.SQLRowProvenanceRequest) (*schema.SQLRowVersions, error) {
+	return s.Srv.SQLRowProvenance(ctx, req)
+}
+
+func (s *ServerMock) GetMulti(ctx context.Context, req *schema.GetMultiRequest) (*schema.GetMultiResponse, error) {
+	return s.Srv.GetMulti(ctx, req)
+}
+
+func (s *ServerMock) VerifiableGetMulti(ctx context.Context, req *schema.VerifiableGetMultiRequest) (*schema.VerifiableGetMultiResponse, error) {
+	return s.Srv.VerifiableGetMulti(ctx, req)
+}
+
+func (s *ServerMock) ConsistencyProof(ctx context.Context, req *schema.ConsistencyProofRequest) (*schema.VerifiableConsistency, error) {
+	return s.Srv.ConsistencyProof(ctx, req)
+}
+
 func (s *ServerMock) ListTables(ctx context.Context, req *empty.Empty) (*schema.SQLQueryResult, error) {
 	return s.Srv.ListTables(ctx, req)
 }
@@ -275,3 +539,63 @@ func (s *ServerMock) DescribeTable(ctx context.Context, req *schema.Table) (*sch
 func (s *ServerMock) VerifiableSQLGet(ctx context.Context, req *schema.VerifiableSQLGetRequest) (*schema.VerifiableSQLEntry, error) {
 	return s.Srv.VerifiableSQLGet(ctx, req)
 }
+
+func (s *ServerMock) ListOperations(ctx context.Context, req *empty.Empty) (*schema.OperationList, error) {
+	return s.Srv.ListOperations(ctx, req)
+}
+
+func (s *ServerMock) CreateEnrollmentToken(ctx context.Context, req *schema.EnrollmentTokenRequest) (*schema.EnrollmentToken, error) {
+	return s.Srv.CreateEnrollmentToken(ctx, req)
+}
+
+func (s *ServerMock) Enroll(ctx context.Context, req *schema.EnrollRequest) (*schema.EnrollResponse, error) {
+	return s.Srv.Enroll(ctx, req)
+}
+
+func (s *ServerMock) TopPrefixes(ctx context.Context, req *schema.TopPrefixesRequest) (*schema.PrefixStatsList, error) {
+	return s.Srv.TopPrefixes(ctx, req)
+}
+
+func (s *ServerMock) CancelOperation(ctx context.Context, req *schema.OperationRequest) (*empty.Empty, error) {
+	return s.Srv.CancelOperation(ctx, req)
+}
+
+func (s *ServerMock) PutObject(ctx context.Context, req *schema.PutObjectRequest) (*schema.ObjectVersion, error) {
+	return s.Srv.PutObject(ctx, req)
+}
+
+func (s *ServerMock) GetObject(ctx context.Context, req *schema.GetObjectRequest) (*schema.Object, error) {
+	return s.Srv.GetObject(ctx, req)
+}
+
+func (s *ServerMock) ListObjectVersions(ctx context.Context, req *schema.ListObjectVersionsRequest) (*schema.ObjectVersions, error) {
+	return s.Srv.ListObjectVersions(ctx, req)
+}
+
+func (s *ServerMock) CreateSecondaryIndex(ctx context.Context, req *schema.SecondaryIndex) (*schema.SecondaryIndex, error) {
+	return s.Srv.CreateSecondaryIndex(ctx, req)
+}
+
+func (s *ServerMock) DropSecondaryIndex(ctx context.Context, req *schema.SecondaryIndexRequest) (*empty.Empty, error) {
+	return s.Srv.DropSecondaryIndex(ctx, req)
+}
+
+func (s *ServerMock) ListSecondaryIndexes(ctx context.Context, req *empty.Empty) (*schema.SecondaryIndexes, error) {
+	return s.Srv.ListSecondaryIndexes(ctx, req)
+}
+
+func (s *ServerMock) IndexScan(ctx context.Context, req *schema.IndexScanRequest) (*schema.IndexEntries, error) {
+	return s.Srv.IndexScan(ctx, req)
+}
+
+func (s *ServerMock) GenerateBindings(ctx context.Context, req *schema.BindingsRequest) (*schema.Bindings, error) {
+	return s.Srv.GenerateBindings(ctx, req)
+}
+
+func (s *ServerMock) NotarizationReceipt(ctx context.Context, req *schema.ReceiptRequest) (*schema.Receipt, error) {
+	return s.Srv.NotarizationReceipt(ctx, req)
+}
+
+func (s *ServerMock) ListReferences(ctx context.Context, req *schema.ListReferencesRequest) (*schema.ReferenceList, error) {
+	return s.Srv.ListReferences(ctx, req)
+}
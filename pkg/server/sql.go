@@ -55,7 +55,7 @@ func (s *ImmuServer) SQLQuery(ctx context.Context, req *schema.SQLQueryRequest)
 		return nil, err
 	}
 
-	return s.dbList.GetByIndex(ind).SQLQuery(req)
+	return s.dbList.GetByIndex(ind).SQLQuery(ctx, req)
 }
 
 func (s *ImmuServer) ListTables(ctx context.Context, _ *empty.Empty) (*schema.SQLQueryResult, error) {
@@ -79,3 +79,58 @@ func (s *ImmuServer) DescribeTable(ctx context.Context, req *schema.Table) (*sch
 
 	return s.dbList.GetByIndex(ind).DescribeTable(req.TableName)
 }
+
+// SQLRowProvenance returns the versions of a row along with the entries and transactions which produced them
+func (s *ImmuServer) SQLRowProvenance(ctx context.Context, req *schema.SQLRowProvenanceRequest) (*schema.SQLRowVersions, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "SQLRowProvenance")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.dbList.GetByIndex(ind).SQLRowProvenance(req)
+}
+
+func (s *ImmuServer) NewSQLTx(ctx context.Context, req *schema.NewSQLTxRequest) (*schema.SQLTx, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "NewSQLTx")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.dbList.GetByIndex(ind).NewSQLTx(req)
+}
+
+func (s *ImmuServer) SQLTxExec(ctx context.Context, req *schema.SQLTxExecRequest) (*empty.Empty, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "SQLTxExec")
+	if err != nil {
+		return nil, err
+	}
+
+	return new(empty.Empty), s.dbList.GetByIndex(ind).SQLTxExec(req)
+}
+
+func (s *ImmuServer) SQLTxQuery(ctx context.Context, req *schema.SQLTxQueryRequest) (*schema.SQLQueryResult, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "SQLTxQuery")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.dbList.GetByIndex(ind).SQLTxQuery(ctx, req)
+}
+
+func (s *ImmuServer) CommitSQLTx(ctx context.Context, req *schema.SQLTxRequest) (*schema.SQLExecResult, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "CommitSQLTx")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.dbList.GetByIndex(ind).CommitSQLTx(req)
+}
+
+func (s *ImmuServer) RollbackSQLTx(ctx context.Context, req *schema.SQLTxRequest) (*empty.Empty, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "RollbackSQLTx")
+	if err != nil {
+		return nil, err
+	}
+
+	return new(empty.Empty), s.dbList.GetByIndex(ind).RollbackSQLTx(req)
+}
@@ -24,6 +24,8 @@ import (
 
 type StateSigner interface {
 	Sign(state *schema.ImmutableState) error
+	SignCombinedState(state *schema.CombinedState) error
+	SignRewindAttestation(attestation *schema.RewindAttestation) error
 }
 
 type stateSigner struct {
@@ -50,3 +52,33 @@ func (sts *stateSigner) Sign(state *schema.ImmutableState) error {
 
 	return err
 }
+
+func (sts *stateSigner) SignRewindAttestation(attestation *schema.RewindAttestation) error {
+	if attestation == nil {
+		return store.ErrIllegalArguments
+	}
+
+	signature, publicKey, err := sts.Signer.Sign(attestation.ToBytes())
+
+	attestation.Signature = &schema.Signature{
+		Signature: signature,
+		PublicKey: publicKey,
+	}
+
+	return err
+}
+
+func (sts *stateSigner) SignCombinedState(state *schema.CombinedState) error {
+	if state == nil {
+		return store.ErrIllegalArguments
+	}
+
+	signature, publicKey, err := sts.Signer.Sign(state.ToBytes())
+
+	state.Signature = &schema.Signature{
+		Signature: signature,
+		PublicKey: publicKey,
+	}
+
+	return err
+}
@@ -23,6 +23,7 @@ import (
 
 	"github.com/codenotary/immudb/embedded/store"
 	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/database"
 	"github.com/codenotary/immudb/pkg/stream"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -94,7 +95,7 @@ func (s *ImmuServer) StreamSet(str schema.ImmuService_StreamSetServer) error {
 		kvs = append(kvs, &schema.KeyValue{Key: key, Value: value})
 	}
 
-	txMeta, err := s.dbList.GetByIndex(ind).Set(&schema.SetRequest{KVs: kvs})
+	txMeta, err := s.dbList.GetByIndex(ind).Set(&schema.SetRequest{KVs: kvs, Attribution: s.attribution(str.Context(), nil)})
 	if err == store.ErrorMaxValueLenExceeded {
 		return stream.ErrMaxValueLenExceeded
 	}
@@ -151,6 +152,7 @@ func (s *ImmuServer) StreamVerifiableGet(req *schema.VerifiableGetRequest, str s
 		Tx:           vEntry.GetEntry().GetTx(),
 		Key:          vEntry.GetEntry().GetKey(),
 		ReferencedBy: vEntry.GetEntry().GetReferencedBy(),
+		ExpiresAt:    vEntry.GetEntry().GetExpiresAt(),
 	}
 
 	entryWithoutValueProto, err := proto.Marshal(&entryWithoutValue)
@@ -242,7 +244,7 @@ func (s *ImmuServer) StreamVerifiableSet(str schema.ImmuService_StreamVerifiable
 	}
 
 	vSetReq := schema.VerifiableSetRequest{
-		SetRequest:   &schema.SetRequest{KVs: kvs},
+		SetRequest:   &schema.SetRequest{KVs: kvs, Attribution: s.attribution(str.Context(), nil)},
 		ProveSinceTx: proveSinceTx,
 	}
 	verifiableTx, err := s.dbList.GetByIndex(ind).VerifiableSet(&vSetReq)
@@ -287,7 +289,7 @@ func (s *ImmuServer) StreamScan(req *schema.ScanRequest, str schema.ImmuService_
 		return err
 	}
 
-	r, err := s.dbList.GetByIndex(ind).Scan(req)
+	r, err := s.dbList.GetByIndex(ind).Scan(str.Context(), req)
 	if err != nil {
 		return err
 	}
@@ -322,7 +324,7 @@ func (s *ImmuServer) StreamZScan(request *schema.ZScanRequest, server schema.Imm
 		return err
 	}
 
-	r, err := s.dbList.GetByIndex(ind).ZScan(request)
+	r, err := s.dbList.GetByIndex(ind).ZScan(server.Context(), request)
 	if err != nil {
 		return err
 	}
@@ -360,8 +362,8 @@ func (s *ImmuServer) StreamZScan(request *schema.ZScanRequest, server schema.Imm
 				Size:    len(atTxBs),
 			},
 			Value: &stream.ValueSize{
-				Content: bufio.NewReader(bytes.NewBuffer(e.Entry.Value)),
-				Size:    len(e.Entry.Value),
+				Content: bufio.NewReader(bytes.NewBuffer(e.Entry.GetValue())),
+				Size:    len(e.Entry.GetValue()),
 			},
 		}
 
@@ -407,6 +409,146 @@ func (s *ImmuServer) StreamHistory(request *schema.HistoryRequest, server schema
 	return nil
 }
 
+// HistoryStream sends the history of a key in batches of up to BatchSize entries, restricted to versions within [FromTx, ToTx]
+func (s *ImmuServer) HistoryStream(req *schema.HistoryStreamRequest, server schema.ImmuService_HistoryStreamServer) error {
+	if req == nil || len(req.Key) == 0 || (req.ToTx > 0 && req.FromTx > req.ToTx) {
+		return ErrIllegalArguments
+	}
+
+	ind, err := s.getDbIndexFromCtx(server.Context(), "History")
+	if err != nil {
+		return err
+	}
+
+	db := s.dbList.GetByIndex(ind)
+
+	batchSize := int32(database.MaxKeyScanLimit)
+	if req.BatchSize > 0 && req.BatchSize < database.MaxKeyScanLimit {
+		batchSize = int32(req.BatchSize)
+	}
+
+	var offset, sent, lastTx uint64
+
+	for {
+		if err := server.Context().Err(); err != nil {
+			return err
+		}
+
+		r, err := db.History(&schema.HistoryRequest{
+			Key:     req.Key,
+			Offset:  offset,
+			Limit:   batchSize,
+			Desc:    req.Desc,
+			SinceTx: req.SinceTx,
+
+			WithTxMetadata:     req.WithTxMetadata,
+			WithInclusionProof: req.WithInclusionProof,
+		})
+		if err != nil {
+			return err
+		}
+
+		offset += uint64(len(r.Entries))
+
+		done := len(r.Entries) < int(batchSize)
+		batch := make([]*schema.Entry, 0, len(r.Entries))
+
+		for _, e := range r.Entries {
+			// newer versions may shift offsets when reading in descending order
+			if req.Desc && lastTx > 0 && e.Tx >= lastTx {
+				continue
+			}
+
+			if (!req.Desc && e.Tx < req.FromTx) || (req.Desc && req.ToTx > 0 && e.Tx > req.ToTx) {
+				continue
+			}
+
+			if (!req.Desc && req.ToTx > 0 && e.Tx > req.ToTx) || (req.Desc && e.Tx < req.FromTx) {
+				done = true
+				break
+			}
+
+			batch = append(batch, e)
+			lastTx = e.Tx
+			sent++
+
+			if req.Limit > 0 && sent == req.Limit {
+				done = true
+				break
+			}
+		}
+
+		if len(batch) > 0 {
+			err = server.Send(&schema.Entries{Entries: batch})
+			if err != nil {
+				return err
+			}
+		}
+
+		if done {
+			return nil
+		}
+	}
+}
+
+// ZScanStream sends the entries of a sorted set in batches of up to BatchSize entries. Each batch is read once the
+// previous one was sent, thus reading is paused while the client is not consuming them
+func (s *ImmuServer) ZScanStream(req *schema.ZScanStreamRequest, server schema.ImmuService_ZScanStreamServer) error {
+	if req == nil || req.Request == nil {
+		return ErrIllegalArguments
+	}
+
+	ind, err := s.getDbIndexFromCtx(server.Context(), "ZScan")
+	if err != nil {
+		return err
+	}
+
+	db := s.dbList.GetByIndex(ind)
+
+	batchSize := uint64(database.MaxKeyScanLimit)
+	if req.BatchSize > 0 && req.BatchSize < database.MaxKeyScanLimit {
+		batchSize = uint64(req.BatchSize)
+	}
+
+	scanReq := proto.Clone(req.Request).(*schema.ZScanRequest)
+	limit := req.Request.Limit
+
+	var sent uint64
+
+	for {
+		if err := server.Context().Err(); err != nil {
+			return err
+		}
+
+		scanReq.Limit = batchSize
+		if limit > 0 && limit-sent < batchSize {
+			scanReq.Limit = limit - sent
+		}
+
+		r, err := db.ZScan(server.Context(), scanReq)
+		if err != nil {
+			return err
+		}
+
+		if len(r.Entries) > 0 {
+			err = server.Send(&schema.ZEntries{Entries: r.Entries})
+			if err != nil {
+				return err
+			}
+		}
+
+		sent += uint64(len(r.Entries))
+
+		if r.Continuation == "" || (limit > 0 && sent == limit) {
+			return nil
+		}
+
+		// following batches continue after the last entry sent
+		scanReq.SeekKey = nil
+		scanReq.Continuation = r.Continuation
+	}
+}
+
 func (s *ImmuServer) StreamExecAll(str schema.ImmuService_StreamExecAllServer) error {
 	ind, err := s.getDbIndexFromCtx(str.Context(), "StreamSet")
 	if err != nil {
@@ -453,7 +595,7 @@ func (s *ImmuServer) StreamExecAll(str schema.ImmuService_StreamExecAllServer) e
 		}
 	}
 
-	txMeta, err := s.dbList.GetByIndex(ind).ExecAll(&schema.ExecAllRequest{Operations: sops})
+	txMeta, err := s.dbList.GetByIndex(ind).ExecAll(&schema.ExecAllRequest{Operations: sops, Attribution: s.attribution(str.Context(), nil)})
 	if err != nil {
 		return err
 	}
@@ -465,3 +607,94 @@ func (s *ImmuServer) StreamExecAll(str schema.ImmuService_StreamExecAllServer) e
 
 	return nil
 }
+
+// SetStream assigns a key a blob received in chunks, which doesn't have to fit in a single message or in memory
+func (s *ImmuServer) SetStream(str schema.ImmuService_SetStreamServer) error {
+	ind, err := s.getDbIndexFromCtx(str.Context(), "SetStream")
+	if err != nil {
+		return err
+	}
+
+	first, err := str.Recv()
+	if err == io.EOF {
+		return ErrIllegalArguments
+	}
+	if err != nil {
+		return err
+	}
+
+	if first.Manifest == nil {
+		return ErrIllegalArguments
+	}
+
+	manifest, err := s.dbList.GetByIndex(ind).SetBlob(
+		first.Manifest.Key,
+		&blobChunkReader{str: str, buf: first.Content},
+		s.attribution(str.Context(), nil),
+	)
+	if err != nil {
+		return err
+	}
+
+	return str.SendAndClose(manifest)
+}
+
+// blobChunkReader reads the content of the chunks received by SetStream
+type blobChunkReader struct {
+	str schema.ImmuService_SetStreamServer
+	buf []byte
+}
+
+func (r *blobChunkReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		chunk, err := r.str.Recv()
+		if err != nil {
+			return 0, err
+		}
+
+		r.buf = chunk.Content
+	}
+
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+
+	return n, nil
+}
+
+// GetStream sends the blob assigned to a key in chunks, its manifest is sent along with the first one
+func (s *ImmuServer) GetStream(req *schema.KeyRequest, str schema.ImmuService_GetStreamServer) error {
+	ind, err := s.getDbIndexFromCtx(str.Context(), "GetStream")
+	if err != nil {
+		return err
+	}
+
+	db := s.dbList.GetByIndex(ind)
+
+	manifest, err := db.GetBlob(req)
+	if err != nil {
+		return err
+	}
+
+	if manifest.Chunks == 0 {
+		return str.Send(&schema.BlobChunk{Manifest: manifest})
+	}
+
+	for i := uint64(0); i < manifest.Chunks; i++ {
+		content, err := db.GetBlobChunk(manifest, i)
+		if err != nil {
+			return err
+		}
+
+		chunk := &schema.BlobChunk{Content: content}
+		if i == 0 {
+			chunk.Manifest = manifest
+		}
+
+		err = str.Send(chunk)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
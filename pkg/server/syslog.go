@@ -0,0 +1,345 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/database"
+	"github.com/codenotary/immudb/pkg/logger"
+)
+
+// DefaultSyslogAddress is the address the syslog listener is bound to, unless configured otherwise
+const DefaultSyslogAddress = "0.0.0.0:5514"
+
+// DefaultSyslogKeyPrefix is the prefix of the keys syslog messages are written under, unless configured otherwise
+const DefaultSyslogKeyPrefix = "syslog"
+
+// maxSyslogMessageLen bounds the length of received messages, longer ones are truncated
+const maxSyslogMessageLen = 64 * 1024
+
+// SyslogOptions configures the listener accepting syslog (RFC 5424) and CEF messages, turning the server into a
+// tamper-evident log sink. Each message is written as it was received under <prefix>:<unix nanos>:<host>, and
+// indexed by the sorted sets <prefix>:host:<host> and <prefix>:severity:<severity>, scored by its unix time
+type SyslogOptions struct {
+	Network   string
+	Address   string
+	Database  string
+	KeyPrefix string
+}
+
+// DefaultSyslogOptions returns options of a UDP listener writing to the default database
+func DefaultSyslogOptions() *SyslogOptions {
+	return &SyslogOptions{
+		Network:   "udp",
+		Address:   DefaultSyslogAddress,
+		Database:  DefaultdbName,
+		KeyPrefix: DefaultSyslogKeyPrefix,
+	}
+}
+
+// Valid returns true if the listener can be bound and its messages written
+func (o *SyslogOptions) Valid() bool {
+	return o != nil &&
+		(o.Network == "udp" || o.Network == "tcp") &&
+		o.Address != "" &&
+		o.Database != "" &&
+		o.KeyPrefix != ""
+}
+
+// WithNetwork sets the network of the listener, either udp or tcp
+func (o *SyslogOptions) WithNetwork(network string) *SyslogOptions {
+	o.Network = network
+	return o
+}
+
+// WithAddress sets the address the listener is bound to
+func (o *SyslogOptions) WithAddress(address string) *SyslogOptions {
+	o.Address = address
+	return o
+}
+
+// WithDatabase sets the database messages are written to
+func (o *SyslogOptions) WithDatabase(database string) *SyslogOptions {
+	o.Database = database
+	return o
+}
+
+// WithKeyPrefix sets the prefix of the keys and sorted sets messages are written under
+func (o *SyslogOptions) WithKeyPrefix(prefix string) *SyslogOptions {
+	o.KeyPrefix = prefix
+	return o
+}
+
+// syslogListener receives messages over UDP, one per datagram, or over TCP, framed either by octet counting or by
+// new lines (RFC 6587)
+type syslogListener struct {
+	opts   *SyslogOptions
+	write  func(m *syslogMessage) error
+	logger logger.Logger
+
+	mutex  sync.Mutex
+	closer io.Closer
+	conns  map[net.Conn]struct{}
+	wg     sync.WaitGroup
+}
+
+func newSyslogListener(opts *SyslogOptions, write func(m *syslogMessage) error, logger logger.Logger) *syslogListener {
+	return &syslogListener{
+		opts:   opts,
+		write:  write,
+		logger: logger,
+		conns:  make(map[net.Conn]struct{}),
+	}
+}
+
+// start binds the listener, the returned address is the one actually bound
+func (l *syslogListener) start() (net.Addr, error) {
+	if l.opts.Network == "udp" {
+		conn, err := net.ListenPacket("udp", l.opts.Address)
+		if err != nil {
+			return nil, err
+		}
+
+		l.closer = conn
+
+		l.wg.Add(1)
+		go l.servePackets(conn)
+
+		return conn.LocalAddr(), nil
+	}
+
+	ln, err := net.Listen("tcp", l.opts.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	l.closer = ln
+
+	l.wg.Add(1)
+	go l.serveConns(ln)
+
+	return ln.Addr(), nil
+}
+
+func (l *syslogListener) stop() {
+	if l.closer == nil {
+		return
+	}
+
+	l.closer.Close()
+
+	l.mutex.Lock()
+	for conn := range l.conns {
+		conn.Close()
+	}
+	l.mutex.Unlock()
+
+	l.wg.Wait()
+
+	l.closer = nil
+}
+
+func (l *syslogListener) servePackets(conn net.PacketConn) {
+	defer l.wg.Done()
+
+	buf := make([]byte, maxSyslogMessageLen)
+
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+
+		raw := make([]byte, n)
+		copy(raw, buf[:n])
+
+		l.handle(raw, addr)
+	}
+}
+
+func (l *syslogListener) serveConns(ln net.Listener) {
+	defer l.wg.Done()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+
+		l.mutex.Lock()
+		l.conns[conn] = struct{}{}
+		l.mutex.Unlock()
+
+		l.wg.Add(1)
+		go l.serveConn(conn)
+	}
+}
+
+func (l *syslogListener) serveConn(conn net.Conn) {
+	defer l.wg.Done()
+
+	defer func() {
+		l.mutex.Lock()
+		delete(l.conns, conn)
+		l.mutex.Unlock()
+
+		conn.Close()
+	}()
+
+	r := bufio.NewReaderSize(conn, maxSyslogMessageLen)
+
+	for {
+		raw, err := readSyslogFrame(r)
+		if err != nil {
+			if err != io.EOF {
+				l.logger.Warningf("syslog: closing connection from %s: %v", conn.RemoteAddr(), err)
+			}
+			return
+		}
+
+		if len(raw) > 0 {
+			l.handle(raw, conn.RemoteAddr())
+		}
+	}
+}
+
+// readSyslogFrame reads a message framed by octet counting, when it starts with its length, or by a new line
+func readSyslogFrame(r *bufio.Reader) ([]byte, error) {
+	b, err := r.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+
+	if b[0] < '1' || b[0] > '9' {
+		line, err := r.ReadSlice('\n')
+		if err == bufio.ErrBufferFull {
+			// the message is truncated, the rest of the line is discarded
+			raw := append([]byte(nil), line...)
+			for err == bufio.ErrBufferFull {
+				_, err = r.ReadSlice('\n')
+			}
+			return raw, err
+		}
+		if err != nil && (err != io.EOF || len(line) == 0) {
+			return nil, err
+		}
+
+		return append([]byte(nil), line...), nil
+	}
+
+	lenStr, err := r.ReadString(' ')
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := strconv.Atoi(strings.TrimSuffix(lenStr, " "))
+	if err != nil || n > maxSyslogMessageLen {
+		return nil, fmt.Errorf("invalid message length %q", lenStr)
+	}
+
+	raw := make([]byte, n)
+
+	_, err = io.ReadFull(r, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return raw, nil
+}
+
+func (l *syslogListener) handle(raw []byte, addr net.Addr) {
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return
+	}
+
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		host = addr.String()
+	}
+
+	m, err := parseSyslogMessage(raw, time.Now(), host)
+	if err != nil {
+		l.logger.Warningf("syslog: discarding message from %s: %v", host, err)
+		return
+	}
+
+	err = l.write(m)
+	if err != nil {
+		l.logger.Errorf("syslog: unable to write message from %s: %v", host, err)
+	}
+}
+
+// syslogWriter returns the function writing messages to the database, along with their sorted set entries, in a
+// single transaction
+func syslogWriter(db database.DB, prefix string) func(m *syslogMessage) error {
+	return func(m *syslogMessage) error {
+		key := []byte(fmt.Sprintf("%s:%019d:%s", prefix, m.Timestamp.UnixNano(), m.Host))
+		score := float64(m.Timestamp.UnixNano()) / float64(time.Second)
+
+		_, err := db.ExecAll(&schema.ExecAllRequest{
+			Operations: []*schema.Op{
+				{Operation: &schema.Op_Kv{Kv: &schema.KeyValue{Key: key, Value: m.Raw}}},
+				{Operation: &schema.Op_ZAdd{ZAdd: &schema.ZAddRequest{
+					Set:      []byte(prefix + ":host:" + m.Host),
+					Score:    score,
+					Key:      key,
+					BoundRef: true,
+				}}},
+				{Operation: &schema.Op_ZAdd{ZAdd: &schema.ZAddRequest{
+					Set:      []byte(prefix + ":severity:" + m.Severity),
+					Score:    score,
+					Key:      key,
+					BoundRef: true,
+				}}},
+			},
+		})
+
+		return err
+	}
+}
+
+func (s *ImmuServer) setUpSyslog() error {
+	ind := s.dbList.GetId(s.Options.SyslogOptions.Database)
+	if ind < 0 {
+		return fmt.Errorf("syslog: database %s does not exist", s.Options.SyslogOptions.Database)
+	}
+
+	s.syslog = newSyslogListener(
+		s.Options.SyslogOptions,
+		syslogWriter(s.dbList.GetByIndex(int64(ind)), s.Options.SyslogOptions.KeyPrefix),
+		s.Logger,
+	)
+
+	addr, err := s.syslog.start()
+	if err != nil {
+		return err
+	}
+
+	s.Logger.Infof("Syslog listener enabled on %s (%s)", addr, s.Options.SyslogOptions.Network)
+
+	return nil
+}
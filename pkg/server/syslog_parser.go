@@ -0,0 +1,190 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidSyslogMessage is returned for messages which are neither syslog nor CEF ones
+var ErrInvalidSyslogMessage = errors.New("invalid syslog message")
+
+// syslogSeverities are the names of syslog severities, indexed by their code
+var syslogSeverities = []string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+
+// cefExtensionKey matches the keys of the extension of CEF messages, values run up to the next key
+var cefExtensionKey = regexp.MustCompile(`(?:^|\s)([A-Za-z0-9_.]+)=`)
+
+type syslogMessage struct {
+	Timestamp time.Time
+	Host      string
+	Severity  string
+	Raw       []byte
+}
+
+// parseSyslogMessage parses a syslog message, either RFC 5424 or with an unparsed (e.g. RFC 3164) header, carrying
+// a CEF message or not. The time and host of the header are used when present, the ones the message was received
+// at and from otherwise. CEF severities replace the syslog ones, as low, medium, high or very-high
+func parseSyslogMessage(raw []byte, received time.Time, remoteHost string) (*syslogMessage, error) {
+	raw = []byte(strings.TrimRight(string(raw), "\r\n\x00"))
+
+	m := &syslogMessage{
+		Timestamp: received,
+		Host:      remoteHost,
+		Raw:       raw,
+	}
+
+	rest := string(raw)
+	hostFromHeader := false
+
+	if strings.HasPrefix(rest, "<") {
+		end := strings.IndexByte(rest, '>')
+		if end < 2 || end > 4 {
+			return nil, ErrInvalidSyslogMessage
+		}
+
+		pri, err := strconv.Atoi(rest[1:end])
+		if err != nil || pri < 0 || pri > 191 {
+			return nil, ErrInvalidSyslogMessage
+		}
+
+		m.Severity = syslogSeverities[pri%8]
+		rest = rest[end+1:]
+
+		if strings.HasPrefix(rest, "1 ") {
+			// TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA [MSG]
+			fields := strings.SplitN(rest[2:], " ", 3)
+			if len(fields) < 3 {
+				return nil, ErrInvalidSyslogMessage
+			}
+
+			if fields[0] != "-" {
+				ts, err := time.Parse(time.RFC3339Nano, fields[0])
+				if err != nil {
+					return nil, ErrInvalidSyslogMessage
+				}
+				m.Timestamp = ts
+			}
+
+			if fields[1] != "-" {
+				m.Host = fields[1]
+				hostFromHeader = true
+			}
+
+			rest = fields[2]
+		}
+	}
+
+	if i := strings.Index(rest, "CEF:"); i >= 0 {
+		severity, host, err := parseCEF(rest[i:])
+		if err != nil {
+			return nil, err
+		}
+
+		m.Severity = severity
+
+		if !hostFromHeader && host != "" {
+			m.Host = host
+		}
+	}
+
+	if m.Severity == "" {
+		return nil, ErrInvalidSyslogMessage
+	}
+
+	return m, nil
+}
+
+// parseCEF returns the severity of a CEF message and the device host from its extension, if any
+// CEF:Version|Device Vendor|Device Product|Device Version|Signature ID|Name|Severity|Extension
+func parseCEF(msg string) (severity string, host string, err error) {
+	var fields []string
+
+	field := strings.Builder{}
+	escaped := false
+
+	for _, c := range msg {
+		switch {
+		case escaped:
+			field.WriteRune(c)
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == '|' && len(fields) < 7:
+			fields = append(fields, field.String())
+			field.Reset()
+		default:
+			field.WriteRune(c)
+		}
+	}
+
+	if len(fields) < 7 {
+		return "", "", ErrInvalidSyslogMessage
+	}
+
+	extension := field.String()
+
+	severity, err = cefSeverity(strings.TrimSpace(fields[6]))
+	if err != nil {
+		return "", "", err
+	}
+
+	matches := cefExtensionKey.FindAllStringSubmatchIndex(extension, -1)
+
+	for i, match := range matches {
+		if extension[match[2]:match[3]] != "dvchost" {
+			continue
+		}
+
+		end := len(extension)
+		if i+1 < len(matches) {
+			end = matches[i+1][0]
+		}
+
+		host = strings.TrimSpace(extension[match[1]:end])
+	}
+
+	return severity, host, nil
+}
+
+func cefSeverity(s string) (string, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		switch strings.ToLower(s) {
+		case "low", "medium", "high", "very-high":
+			return strings.ToLower(s), nil
+		}
+		return "", ErrInvalidSyslogMessage
+	}
+
+	switch {
+	case n < 0 || n > 10:
+		return "", ErrInvalidSyslogMessage
+	case n <= 3:
+		return "low", nil
+	case n <= 6:
+		return "medium", nil
+	case n <= 8:
+		return "high", nil
+	default:
+		return "very-high", nil
+	}
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/auth"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestSyslogOptions(t *testing.T) {
+	require.True(t, DefaultSyslogOptions().Valid())
+	require.False(t, DefaultSyslogOptions().WithNetwork("unix").Valid())
+	require.False(t, DefaultSyslogOptions().WithKeyPrefix("").Valid())
+
+	var opts *SyslogOptions
+	require.False(t, opts.Valid())
+}
+
+func TestParseSyslogMessage(t *testing.T) {
+	received := time.Unix(1600000000, 0)
+
+	m, err := parseSyslogMessage([]byte("<34>1 2003-10-11T22:14:15.003Z mymachine.example.com su - ID47 - 'su root' failed\n"), received, "10.0.0.1")
+	require.NoError(t, err)
+	require.Equal(t, "mymachine.example.com", m.Host)
+	require.Equal(t, "crit", m.Severity)
+	require.Equal(t, time.Date(2003, 10, 11, 22, 14, 15, 3000000, time.UTC), m.Timestamp.UTC())
+	require.Equal(t, []byte("<34>1 2003-10-11T22:14:15.003Z mymachine.example.com su - ID47 - 'su root' failed"), m.Raw)
+
+	m, err = parseSyslogMessage([]byte("<165>1 - - app - - -"), received, "10.0.0.1")
+	require.NoError(t, err)
+	require.Equal(t, "10.0.0.1", m.Host)
+	require.Equal(t, "notice", m.Severity)
+	require.Equal(t, received, m.Timestamp)
+
+	m, err = parseSyslogMessage([]byte("<13>Oct 11 22:14:15 mymachine su: 'su root' failed"), received, "10.0.0.1")
+	require.NoError(t, err)
+	require.Equal(t, "10.0.0.1", m.Host)
+	require.Equal(t, "notice", m.Severity)
+
+	m, err = parseSyslogMessage([]byte(`<134>1 2003-10-11T22:14:15Z fw1 - - - - CEF:0|Security|threatmanager|1.0|100|worm \| stopped|8|src=10.0.0.1 dvchost=other host msg=a b`), received, "10.0.0.1")
+	require.NoError(t, err)
+	require.Equal(t, "fw1", m.Host)
+	require.Equal(t, "high", m.Severity)
+
+	m, err = parseSyslogMessage([]byte(`CEF:0|Security|threatmanager|1.0|100|worm stopped|Very-High|src=10.0.0.1 dvchost=fw2.example.com msg=a b`), received, "10.0.0.1")
+	require.NoError(t, err)
+	require.Equal(t, "fw2.example.com", m.Host)
+	require.Equal(t, "very-high", m.Severity)
+
+	m, err = parseSyslogMessage([]byte(`CEF:0|Security|threatmanager|1.0|100|worm stopped|2|`), received, "10.0.0.1")
+	require.NoError(t, err)
+	require.Equal(t, "10.0.0.1", m.Host)
+	require.Equal(t, "low", m.Severity)
+
+	for _, invalid := range []string{
+		"plain text",
+		"<>1 - - - - - -",
+		"<192>1 - - - - - -",
+		"<34>1 yesterday host app - - -",
+		"<34>1 -",
+		"CEF:0|Security|threatmanager|1.0|100|worm stopped",
+		"CEF:0|Security|threatmanager|1.0|100|worm stopped|11|",
+		"CEF:0|Security|threatmanager|1.0|100|worm stopped|urgent|",
+	} {
+		_, err = parseSyslogMessage([]byte(invalid), received, "10.0.0.1")
+		require.Equal(t, ErrInvalidSyslogMessage, err, invalid)
+	}
+}
+
+func TestReadSyslogFrame(t *testing.T) {
+	r := bufio.NewReader(strings.NewReader("11 <34>1 - - -<34>1 line\n\n5 <1>1"))
+
+	frame, err := readSyslogFrame(r)
+	require.NoError(t, err)
+	require.Equal(t, "<34>1 - - -", string(frame))
+
+	frame, err = readSyslogFrame(r)
+	require.NoError(t, err)
+	require.Equal(t, "<34>1 line\n", string(frame))
+
+	frame, err = readSyslogFrame(r)
+	require.NoError(t, err)
+	require.Equal(t, "\n", string(frame))
+
+	_, err = readSyslogFrame(r)
+	require.Error(t, err)
+
+	_, err = readSyslogFrame(bufio.NewReader(strings.NewReader("99999999 <1>1")))
+	require.Error(t, err)
+}
+
+func TestSyslogListener(t *testing.T) {
+	serverOptions := DefaultOptions().
+		WithMetricsServer(false).
+		WithAdminPassword(auth.SysAdminPassword).
+		WithListener(bufconn.Listen(1024 * 1024))
+
+	s := DefaultServer().WithOptions(serverOptions).(*ImmuServer)
+	defer os.RemoveAll(s.Options.Dir)
+
+	err := s.Initialize()
+	require.NoError(t, err)
+
+	db := s.dbList.GetByIndex(int64(s.dbList.GetId(DefaultdbName)))
+
+	hostEntries := func(host string) []*schema.ZEntry {
+		zs, err := db.ZScan(context.Background(), &schema.ZScanRequest{Set: []byte("syslog:host:" + host)})
+		require.NoError(t, err)
+		return zs.Entries
+	}
+
+	for _, network := range []string{"udp", "tcp"} {
+		s.Options.WithSyslogOptions(DefaultSyslogOptions().WithNetwork(network).WithAddress("127.0.0.1:0"))
+
+		s.Options.SyslogOptions.Database = "unknown"
+		require.Error(t, s.setUpSyslog())
+		s.Options.SyslogOptions.Database = DefaultdbName
+
+		l := newSyslogListener(s.Options.SyslogOptions, syslogWriter(db, DefaultSyslogKeyPrefix), s.Logger)
+
+		addr, err := l.start()
+		require.NoError(t, err)
+
+		conn, err := net.Dial(network, addr.String())
+		require.NoError(t, err)
+
+		host := network + "-host"
+		msg := fmt.Sprintf("<11>1 2021-06-01T10:00:00Z %s app - - - disk failure", host)
+
+		if network == "tcp" {
+			_, err = fmt.Fprintf(conn, "not a syslog message\n%d %s", len(msg), msg)
+		} else {
+			_, err = conn.Write([]byte(msg))
+		}
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool { return len(hostEntries(host)) == 1 }, 5*time.Second, 10*time.Millisecond)
+
+		conn.Close()
+		l.stop()
+
+		e := hostEntries(host)[0]
+		require.Equal(t, []byte(msg), e.Entry.Value)
+		require.Equal(t, fmt.Sprintf("syslog:%019d:%s", time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC).UnixNano(), host), string(e.Key))
+		require.Equal(t, float64(time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC).Unix()), e.Score)
+
+		zs, err := db.ZScan(context.Background(), &schema.ZScanRequest{Set: []byte("syslog:severity:err")})
+		require.NoError(t, err)
+		require.NotEmpty(t, zs.Entries)
+	}
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/codenotary/immudb/pkg/auth"
 	"github.com/codenotary/immudb/pkg/immuos"
 	"github.com/codenotary/immudb/pkg/logger"
+	"github.com/codenotary/immudb/pkg/replication"
 )
 
 // userDatabasePairs keeps an associacion of username to userdata
@@ -58,6 +59,7 @@ type ImmuServer struct {
 	multidbmode bool
 	//Cc                  CorruptionChecker
 	sysDb                database.DB
+	eventsDb             database.DB
 	metricsServer        *http.Server
 	webServer            *http.Server
 	mux                  sync.Mutex
@@ -65,6 +67,16 @@ type ImmuServer struct {
 	StateSigner          StateSigner
 	StreamServiceFactory stream.ServiceFactory
 	PgsqlSrv             pgsqlsrv.Server
+	operations           *operations
+	enrollments          *enrollments
+	replicator           *replication.TxReplicator
+	scheduler            *jobScheduler
+	jobs                 *jobs
+	approvalMux          sync.Mutex
+	alerts               *alertMonitor
+	syslog               *syslogListener
+	auditFailures        uint64
+	replicationThrottler stream.Throttler
 }
 
 // DefaultServer ...
@@ -78,6 +90,9 @@ func DefaultServer() *ImmuServer {
 		userdata:             &usernameToUserdataMap{Userdata: make(map[string]*auth.User)},
 		GrpcServer:           grpc.NewServer(),
 		StreamServiceFactory: stream.NewStreamServiceFactory(DefaultOptions().StreamChunkSize),
+		operations:           newOperations(),
+		enrollments:          newEnrollments(),
+		jobs:                 newJobs(),
 	}
 }
 
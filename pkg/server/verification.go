@@ -0,0 +1,254 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package server
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/codenotary/immudb/embedded/htree"
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/database"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// VerificationPath is the path of the public verification endpoint, it accepts the query parameters of the links
+// included in notarization receipts: db, tx, hash (hex encoded value hash) and optionally key (base64 url encoded)
+const VerificationPath = "/api/v1/verify"
+
+// maxRateLimitedClients bounds the number of clients whose request rate is tracked at once
+const maxRateLimitedClients = 10000
+
+type verificationResponse struct {
+	Verified       bool                   `json:"verified"`
+	Database       string                 `json:"db"`
+	Tx             uint64                 `json:"tx"`
+	Timestamp      int64                  `json:"timestamp,omitempty"`
+	Key            []byte                 `json:"key,omitempty"`
+	Hash           string                 `json:"hash"`
+	State          *schema.ImmutableState `json:"state"`
+	InclusionProof *schema.InclusionProof `json:"inclusionProof,omitempty"`
+	DualProof      *schema.DualProof      `json:"dualProof,omitempty"`
+}
+
+// verificationHandler serves the public verification endpoint, letting anyone holding an entry hash and the id of
+// its transaction check its inclusion against the current (signed) state of the database without credentials.
+// The endpoint is read-only, it doesn't disclose other entries of the transaction and requests are rate-limited per client
+func verificationHandler(s *ImmuServer) http.Handler {
+	limiter := newRateLimiter(s.Options.PublicVerificationRateLimit)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", http.MethodGet)
+			writeIngestionError(w, http.StatusMethodNotAllowed, "only "+http.MethodGet+" is supported")
+			return
+		}
+
+		if !limiter.allow(clientAddress(r)) {
+			w.Header().Set("Retry-After", "1")
+			writeIngestionError(w, http.StatusTooManyRequests, "rate limit exceeded")
+			return
+		}
+
+		q := r.URL.Query()
+
+		txID, err := strconv.ParseUint(q.Get("tx"), 10, 64)
+		if err != nil || txID == 0 {
+			writeIngestionError(w, http.StatusBadRequest, "invalid tx")
+			return
+		}
+
+		hash, err := hex.DecodeString(q.Get("hash"))
+		if err != nil || len(hash) == 0 {
+			writeIngestionError(w, http.StatusBadRequest, "invalid hash")
+			return
+		}
+
+		var key []byte
+
+		if q.Get("key") != "" {
+			key, err = base64.RawURLEncoding.DecodeString(q.Get("key"))
+			if err != nil {
+				writeIngestionError(w, http.StatusBadRequest, "invalid key")
+				return
+			}
+		}
+
+		res, err := s.verifyInclusion(q.Get("db"), txID, hash, key)
+		if err != nil {
+			writeIngestionStatus(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(res)
+	})
+}
+
+// verifyInclusion looks for an entry with the given value hash, and key when provided, in the transaction and proves
+// its inclusion in the current state of the database
+func (s *ImmuServer) verifyInclusion(dbName string, txID uint64, hash, key []byte) (*verificationResponse, error) {
+	if dbName == SystemdbName {
+		return nil, status.Error(codes.PermissionDenied, "the system database can not be publicly verified")
+	}
+
+	db, err := s.dbList.GetByName(dbName)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+
+	state, err := db.CurrentState()
+	if err != nil {
+		return nil, err
+	}
+
+	state.Db = dbName
+
+	if txID > state.TxId {
+		return nil, status.Errorf(codes.NotFound, "tx %d not found", txID)
+	}
+
+	vTx, err := db.VerifiableTxByID(&schema.VerifiableTxRequest{Tx: txID, ProveSinceTx: state.TxId})
+	if err != nil {
+		return nil, err
+	}
+
+	if s.Options.SigningKey != "" {
+		err = s.StateSigner.Sign(state)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	res := &verificationResponse{
+		Database:  dbName,
+		Tx:        txID,
+		Timestamp: vTx.Tx.Metadata.Ts,
+		Key:       key,
+		Hash:      hex.EncodeToString(hash),
+		State:     state,
+	}
+
+	tx := schema.TxFrom(vTx.Tx)
+
+	for _, e := range tx.Entries() {
+		hVal := e.HVal()
+
+		if !bytes.Equal(hVal[:], hash) || (key != nil && !bytes.Equal(e.Key(), database.EncodeKey(key))) {
+			continue
+		}
+
+		inclusionProof, err := tx.Proof(e.Key())
+		if err != nil {
+			return nil, err
+		}
+
+		// the proofs are checked before being returned, so that they can be trusted as much as the signed state
+		res.Verified = htree.VerifyInclusion(inclusionProof, e.Digest(), tx.Eh()) &&
+			store.VerifyDualProof(schema.DualProofFrom(vTx.DualProof), txID, state.TxId, tx.Alh, schema.DigestFrom(state.TxHash))
+
+		res.InclusionProof = schema.InclusionProofTo(inclusionProof)
+		res.DualProof = vTx.DualProof
+
+		break
+	}
+
+	return res, nil
+}
+
+// clientAddress returns the address requests are rate-limited by
+func clientAddress(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// rateLimiter is a token bucket per client, each one refilled at rate tokens per second up to rate tokens.
+// A non-positive rate disables the limit
+type rateLimiter struct {
+	mutex   sync.Mutex
+	rate    float64
+	buckets map[string]*tokenBucket
+	now     func() time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rate int) *rateLimiter {
+	return &rateLimiter{
+		rate:    float64(rate),
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+func (l *rateLimiter) allow(client string) bool {
+	if l.rate <= 0 {
+		return true
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+
+	b, ok := l.buckets[client]
+	if !ok {
+		if len(l.buckets) >= maxRateLimitedClients {
+			l.evictIdle(now)
+		}
+
+		b = &tokenBucket{tokens: l.rate, last: now}
+		l.buckets[client] = b
+	}
+
+	b.tokens += now.Sub(b.last).Seconds() * l.rate
+	if b.tokens > l.rate {
+		b.tokens = l.rate
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+
+	b.tokens--
+
+	return true
+}
+
+// evictIdle forgets the clients whose bucket is already full, they'd be granted the same tokens as new ones
+func (l *rateLimiter) evictIdle(now time.Time) {
+	for client, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.rate {
+			delete(l.buckets, client)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package server
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/auth"
+	"github.com/codenotary/immudb/pkg/signer"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestServerPublicVerification(t *testing.T) {
+	serverOptions := DefaultOptions().
+		WithMetricsServer(false).
+		WithAdminPassword(auth.SysAdminPassword).
+		WithSigningKey("./../../test/signer/ec1.key").
+		WithReceiptVerificationURL("https://verify.example.com" + VerificationPath).
+		WithPublicVerification(true).
+		WithPublicVerificationRateLimit(0).
+		WithListener(bufconn.Listen(1024 * 1024))
+	s := DefaultServer().WithOptions(serverOptions).(*ImmuServer)
+	defer os.RemoveAll(s.Options.Dir)
+
+	require.NoError(t, s.Initialize())
+
+	lr, err := s.Login(context.Background(), &schema.LoginRequest{
+		User:     []byte(auth.SysAdminUsername),
+		Password: []byte(auth.SysAdminPassword),
+	})
+	require.NoError(t, err)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", lr.Token))
+
+	_, err = s.Set(ctx, &schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("contract1"), Value: []byte("signed")}}})
+	require.NoError(t, err)
+
+	r, err := s.NotarizationReceipt(ctx, &schema.ReceiptRequest{Key: []byte("contract1")})
+	require.NoError(t, err)
+
+	// later transactions are covered by the dual proof
+	_, err = s.Set(ctx, &schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("contract2"), Value: []byte("draft")}}})
+	require.NoError(t, err)
+
+	h := verificationHandler(s)
+
+	get := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, VerificationPath+"?"+query, nil))
+		return w
+	}
+
+	u, err := url.Parse(r.VerificationUrl)
+	require.NoError(t, err)
+
+	w := get(u.RawQuery)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var res verificationResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	require.True(t, res.Verified)
+	require.Equal(t, DefaultdbName, res.Database)
+	require.Equal(t, r.Tx, res.Tx)
+	require.Equal(t, []byte("contract1"), res.Key)
+	require.Greater(t, res.State.TxId, r.Tx)
+	require.NotNil(t, res.InclusionProof)
+	require.NotNil(t, res.DualProof)
+
+	pubKey, err := signer.ParsePublicKeyFile("./../../test/signer/ec1.pub")
+	require.NoError(t, err)
+
+	ok, err := res.State.CheckSignature(pubKey)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	q := u.Query()
+	q.Del("key")
+
+	w = get(q.Encode())
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	require.True(t, res.Verified)
+
+	q.Set("hash", hex.EncodeToString(make([]byte, 32)))
+
+	res = verificationResponse{}
+	w = get(q.Encode())
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	require.False(t, res.Verified)
+	require.Nil(t, res.InclusionProof)
+
+	require.Equal(t, http.StatusBadRequest, get("db=defaultdb&tx=a&hash=00").Code)
+	require.Equal(t, http.StatusBadRequest, get("db=defaultdb&tx=1&hash=zz").Code)
+	require.Equal(t, http.StatusBadRequest, get("db=defaultdb&tx=1&hash=00&key=!!").Code)
+	require.Equal(t, http.StatusNotFound, get("db=defaultdb&tx=100&hash=00").Code)
+	require.Equal(t, http.StatusNotFound, get("db=unknown&tx=1&hash=00").Code)
+	require.Equal(t, http.StatusForbidden, get("db="+SystemdbName+"&tx=1&hash=00").Code)
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, VerificationPath, nil))
+	require.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Now()
+
+	l := newRateLimiter(2)
+	l.now = func() time.Time { return now }
+
+	require.True(t, l.allow("a"))
+	require.True(t, l.allow("a"))
+	require.False(t, l.allow("a"))
+	require.True(t, l.allow("b"))
+
+	now = now.Add(500 * time.Millisecond)
+	require.True(t, l.allow("a"))
+	require.False(t, l.allow("a"))
+
+	now = now.Add(time.Hour)
+	l.evictIdle(now)
+	require.Empty(t, l.buckets)
+
+	unlimited := newRateLimiter(0)
+	for i := 0; i < 100; i++ {
+		require.True(t, unlimited.allow("a"))
+	}
+}
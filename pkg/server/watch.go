@@ -0,0 +1,51 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package server
+
+import (
+	"context"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/golang/protobuf/ptypes/empty"
+)
+
+// Watch streams the entries matching the request as they are committed, until the client cancels the call
+func (s *ImmuServer) Watch(req *schema.WatchRequest, server schema.ImmuService_WatchServer) error {
+	ind, err := s.getDbIndexFromCtx(server.Context(), "Watch")
+	if err != nil {
+		return err
+	}
+
+	return s.dbList.GetByIndex(ind).Watch(server.Context(), req, server.Send)
+}
+
+func (s *ImmuServer) WatchAck(ctx context.Context, req *schema.WatchAckRequest) (*empty.Empty, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "WatchAck")
+	if err != nil {
+		return nil, err
+	}
+
+	return new(empty.Empty), s.dbList.GetByIndex(ind).WatchAck(req)
+}
+
+func (s *ImmuServer) Unwatch(ctx context.Context, req *schema.UnwatchRequest) (*empty.Empty, error) {
+	ind, err := s.getDbIndexFromCtx(ctx, "Unwatch")
+	if err != nil {
+		return nil, err
+	}
+
+	return new(empty.Empty), s.dbList.GetByIndex(ind).Unwatch(req)
+}
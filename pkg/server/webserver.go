@@ -19,6 +19,11 @@ func StartWebServer(addr string, tlsConfig *tls.Config, s schema.ImmuServiceServ
 
 	webMux := http.NewServeMux()
 	webMux.Handle("/api/", http.StripPrefix("/api", proxyMux))
+	webMux.Handle(IngestionPathPrefix, ingestionHandler(s))
+
+	if is, ok := s.(*ImmuServer); ok && is.Options.PublicVerification {
+		webMux.Handle(VerificationPath, verificationHandler(is))
+	}
 
 	err = webconsole.SetupWebconsole(webMux, l, addr)
 	if err != nil {
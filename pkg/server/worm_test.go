@@ -0,0 +1,94 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package server
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/auth"
+	"github.com/codenotary/immudb/pkg/database"
+	"github.com/codenotary/immudb/pkg/signer"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestServerWORMDatabase(t *testing.T) {
+	serverOptions := DefaultOptions().
+		WithMetricsServer(false).
+		WithAdminPassword(auth.SysAdminPassword).
+		WithSigningKey("./../../test/signer/ec1.key").
+		WithListener(bufconn.Listen(1024 * 1024))
+	s := DefaultServer().WithOptions(serverOptions).(*ImmuServer)
+	defer os.RemoveAll(s.Options.Dir)
+
+	require.NoError(t, s.Initialize())
+
+	lr, err := s.Login(context.Background(), &schema.LoginRequest{
+		User:     []byte(auth.SysAdminUsername),
+		Password: []byte(auth.SysAdminPassword),
+	})
+	require.NoError(t, err)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", lr.Token))
+
+	_, err = s.CreateDatabase(ctx, &schema.Database{DatabaseName: "records", Worm: true, WormMaxVersions: 1})
+	require.NoError(t, err)
+
+	dbs, err := s.DatabaseList(ctx, &empty.Empty{})
+	require.NoError(t, err)
+
+	var found bool
+	for _, db := range dbs.Databases {
+		if db.DatabaseName == "records" {
+			found = true
+			require.True(t, db.Worm)
+			require.Equal(t, uint32(1), db.WormMaxVersions)
+		} else {
+			require.False(t, db.Worm)
+		}
+	}
+	require.True(t, found)
+
+	ur, err := s.UseDatabase(ctx, &schema.Database{DatabaseName: "records"})
+	require.NoError(t, err)
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", ur.Token))
+
+	_, err = s.Set(ctx, &schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("trade1"), Value: []byte("v1")}}})
+	require.NoError(t, err)
+
+	_, err = s.Set(ctx, &schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("trade1"), Value: []byte("v2")}}})
+	require.Equal(t, database.ErrWORMMaxVersionsExceeded, err)
+
+	// the mode is covered by the signature of the state
+	state, err := s.CurrentState(ctx, &empty.Empty{})
+	require.NoError(t, err)
+	require.True(t, state.Worm)
+
+	ok, err := signer.Verify(state.ToBytes(), state.Signature.Signature, signer.UnmarshalKey(state.Signature.PublicKey))
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	state.Worm = false
+
+	ok, _ = signer.Verify(state.ToBytes(), state.Signature.Signature, signer.UnmarshalKey(state.Signature.PublicKey))
+	require.False(t, ok)
+}
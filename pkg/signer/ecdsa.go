@@ -28,6 +28,8 @@ import (
 	"io"
 	"io/ioutil"
 	"math/big"
+
+	"github.com/codenotary/immudb/pkg/fips"
 )
 
 type signer struct {
@@ -54,6 +56,12 @@ func NewSigner(privateKeyPath string) (Signer, error) {
 	if err != nil {
 		return nil, err
 	}
+	if fips.Enabled {
+		err = fips.CheckCurve(privateKey.Curve)
+		if err != nil {
+			return nil, err
+		}
+	}
 	return signer{rand: rand.Reader, privateKey: privateKey}, nil
 }
 
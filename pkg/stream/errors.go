@@ -31,3 +31,4 @@ var ErrChunkTooSmall = status.Error(codes.InvalidArgument, fmt.Sprintf("minimum
 var ErrMissingExpectedData = status.Error(codes.Internal, fmt.Sprintf("expected data on stream is missing"))
 var ErrRefOptNotImplemented = status.Error(codes.Unimplemented, fmt.Sprintf("reference operation is not implemented"))
 var ErrUnableToReassembleExecAllMessage = status.Error(codes.Internal, fmt.Sprintf("unable to reassemble ZAdd message on a streamExecAll"))
+var ErrInvalidThrottleWindow = status.Error(codes.InvalidArgument, "invalid throttle window, expected format is hh:mm-hh:mm")
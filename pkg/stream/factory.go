@@ -18,6 +18,7 @@ package stream
 
 type serviceFactory struct {
 	ChunkSize int
+	Throttler Throttler
 }
 
 // ServiceFactory returns high level immudb streaming services
@@ -44,9 +45,16 @@ func NewStreamServiceFactory(chunkSize int) ServiceFactory {
 	return &serviceFactory{ChunkSize: chunkSize}
 }
 
+// NewThrottledStreamServiceFactory returns a new ServiceFactory whose senders share the provided Throttler
+func NewThrottledStreamServiceFactory(chunkSize int, throttler Throttler) ServiceFactory {
+	return &serviceFactory{ChunkSize: chunkSize, Throttler: throttler}
+}
+
 // NewMsgSender returns a MsgSender
 func (s *serviceFactory) NewMsgSender(str ImmuServiceSender_Stream) MsgSender {
-	return NewMsgSender(str, s.ChunkSize)
+	ms := NewMsgSender(str, s.ChunkSize)
+	ms.throttler = s.Throttler
+	return ms
 }
 
 // NewMsgReceiver returns a MsgReceiver
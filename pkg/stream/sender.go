@@ -32,6 +32,7 @@ type msgSender struct {
 	stream          ImmuServiceSender_Stream
 	b               *bytes.Buffer
 	StreamChunkSize int
+	throttler       Throttler
 }
 
 // NewMsgSender returns a NewMsgSender. It can be used on server side or client side to send a message on a stream.
@@ -99,6 +100,9 @@ func (st *msgSender) Send(reader io.Reader, payloadSize int) (err error) {
 		}
 		// sending ...
 		if len(chunk) > 0 {
+			if st.throttler != nil {
+				st.throttler.Wait(len(chunk))
+			}
 			err = st.stream.Send(&schema.Chunk{
 				Content: chunk,
 			})
@@ -0,0 +1,167 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stream
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// Throttler limits the amount of bytes per second sent on streams
+type Throttler interface {
+	Wait(n int)
+}
+
+// ThrottleWindow is a daily time window, expressed as offsets since midnight (local time), in which throttling is applied.
+// When To is before From the window wraps around midnight.
+type ThrottleWindow struct {
+	From time.Duration
+	To   time.Duration
+}
+
+// Contains returns true if the time of the day of t falls inside the window
+func (w ThrottleWindow) Contains(t time.Time) bool {
+	h, m, s := t.Clock()
+	d := time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(s)*time.Second
+
+	if w.From <= w.To {
+		return d >= w.From && d < w.To
+	}
+
+	return d >= w.From || d < w.To
+}
+
+// ParseThrottleWindows parses a comma separated list of windows in the form "hh:mm-hh:mm"
+func ParseThrottleWindows(s string) ([]ThrottleWindow, error) {
+	var windows []ThrottleWindow
+
+	for _, w := range strings.Split(s, ",") {
+		w = strings.TrimSpace(w)
+		if w == "" {
+			continue
+		}
+
+		bounds := strings.Split(w, "-")
+		if len(bounds) != 2 {
+			return nil, ErrInvalidThrottleWindow
+		}
+
+		from, err := parseClock(bounds[0])
+		if err != nil {
+			return nil, err
+		}
+
+		to, err := parseClock(bounds[1])
+		if err != nil {
+			return nil, err
+		}
+
+		windows = append(windows, ThrottleWindow{From: from, To: to})
+	}
+
+	return windows, nil
+}
+
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, ErrInvalidThrottleWindow
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+type throttler struct {
+	bytesPerSec float64
+	windows     []ThrottleWindow
+
+	allowance float64
+	last      time.Time
+
+	now   func() time.Time
+	sleep func(time.Duration)
+
+	mutex sync.Mutex
+}
+
+// NewThrottler returns a Throttler allowing up to bytesPerSec bytes per second.
+// If windows are provided, the limit is only applied while the current time falls inside one of them.
+// A single Throttler can be shared among many streams so the limit applies to all of them together.
+func NewThrottler(bytesPerSec int, windows ...ThrottleWindow) Throttler {
+	return newThrottler(bytesPerSec, windows...)
+}
+
+func newThrottler(bytesPerSec int, windows ...ThrottleWindow) *throttler {
+	return &throttler{
+		bytesPerSec: float64(bytesPerSec),
+		windows:     windows,
+		allowance:   float64(bytesPerSec),
+		now:         time.Now,
+		sleep:       time.Sleep,
+	}
+}
+
+// Wait blocks until n bytes can be sent without exceeding the configured rate
+func (t *throttler) Wait(n int) {
+	if t.bytesPerSec <= 0 {
+		return
+	}
+
+	t.mutex.Lock()
+
+	now := t.now()
+
+	if !t.active(now) {
+		t.mutex.Unlock()
+		return
+	}
+
+	if !t.last.IsZero() {
+		t.allowance += now.Sub(t.last).Seconds() * t.bytesPerSec
+		if t.allowance > t.bytesPerSec {
+			t.allowance = t.bytesPerSec
+		}
+	}
+	t.last = now
+
+	t.allowance -= float64(n)
+
+	var wait time.Duration
+	if t.allowance < 0 {
+		wait = time.Duration(-t.allowance / t.bytesPerSec * float64(time.Second))
+	}
+
+	t.mutex.Unlock()
+
+	if wait > 0 {
+		t.sleep(wait)
+	}
+}
+
+func (t *throttler) active(now time.Time) bool {
+	if len(t.windows) == 0 {
+		return true
+	}
+
+	for _, w := range t.windows {
+		if w.Contains(now) {
+			return true
+		}
+	}
+
+	return false
+}
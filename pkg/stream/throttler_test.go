@@ -0,0 +1,117 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stream
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/codenotary/immudb/pkg/stream/streamtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThrottler(t *testing.T) {
+	now := time.Date(2021, 1, 1, 12, 0, 0, 0, time.Local)
+	var slept time.Duration
+
+	th := newThrottler(1000)
+	th.now = func() time.Time { return now }
+	th.sleep = func(d time.Duration) { slept += d }
+
+	th.Wait(1000)
+	require.Equal(t, time.Duration(0), slept)
+
+	th.Wait(500)
+	require.Equal(t, 500*time.Millisecond, slept)
+
+	now = now.Add(2 * time.Second)
+	slept = 0
+
+	th.Wait(1000)
+	require.Equal(t, time.Duration(0), slept)
+}
+
+func TestThrottlerWindows(t *testing.T) {
+	now := time.Date(2021, 1, 1, 12, 0, 0, 0, time.Local)
+	var slept time.Duration
+
+	windows, err := ParseThrottleWindows("22:00-06:00")
+	require.NoError(t, err)
+	require.Len(t, windows, 1)
+
+	th := newThrottler(1000, windows...)
+	th.now = func() time.Time { return now }
+	th.sleep = func(d time.Duration) { slept += d }
+
+	th.Wait(5000)
+	require.Equal(t, time.Duration(0), slept)
+
+	now = time.Date(2021, 1, 1, 23, 0, 0, 0, time.Local)
+	th.Wait(2000)
+	require.Equal(t, time.Second, slept)
+}
+
+func TestThrottlerDisabled(t *testing.T) {
+	th := newThrottler(0)
+	th.sleep = func(d time.Duration) { t.Fail() }
+	th.Wait(1 << 20)
+}
+
+func TestParseThrottleWindows(t *testing.T) {
+	windows, err := ParseThrottleWindows("")
+	require.NoError(t, err)
+	require.Empty(t, windows)
+
+	windows, err = ParseThrottleWindows("08:00-12:30, 14:00-18:00")
+	require.NoError(t, err)
+	require.Equal(t, []ThrottleWindow{
+		{From: 8 * time.Hour, To: 12*time.Hour + 30*time.Minute},
+		{From: 14 * time.Hour, To: 18 * time.Hour},
+	}, windows)
+
+	_, err = ParseThrottleWindows("08:00")
+	require.Equal(t, ErrInvalidThrottleWindow, err)
+
+	_, err = ParseThrottleWindows("08:00-25:00")
+	require.Equal(t, ErrInvalidThrottleWindow, err)
+
+	_, err = ParseThrottleWindows("aa:00-12:00")
+	require.Equal(t, ErrInvalidThrottleWindow, err)
+}
+
+func TestThrottledMsgSender(t *testing.T) {
+	var waited int
+	th := &throttlerMock{waitF: func(n int) { waited += n }}
+
+	sf := NewThrottledStreamServiceFactory(4096, th)
+	s := sf.NewMsgSender(streamtest.DefaultImmuServiceSenderStreamMock())
+
+	content := []byte(`mycontent`)
+	b := bytes.NewBuffer(content)
+	err := s.Send(b, b.Len())
+	require.NoError(t, err)
+	require.Equal(t, len(content)+8, waited)
+}
+
+type throttlerMock struct {
+	waitF func(n int)
+}
+
+func (t *throttlerMock) Wait(n int) {
+	t.waitF(n)
+}
@@ -0,0 +1,253 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package rest is a minimal client of the immudb REST API verifying every read locally.
+// Like the verification package, it only depends on the standard library so it can be compiled to WebAssembly
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/codenotary/immudb/pkg/verification"
+)
+
+var ErrIllegalArguments = errors.New("illegal arguments")
+var ErrCorruptedData = errors.New("data is corrupted")
+
+// State is the latest verified state of the database
+type State struct {
+	TxID   uint64
+	TxHash [verification.HashSize]byte
+}
+
+// Entry is a verified key-value entry
+type Entry struct {
+	Tx    uint64
+	Key   []byte
+	Value []byte
+}
+
+// Client issues requests to the REST API served under URL (e.g. http://localhost:8080/api)
+type Client struct {
+	URL        string
+	HTTPClient *http.Client
+
+	token string
+	state *State
+}
+
+// NewClient returns a client for the REST API served under url
+func NewClient(url string) *Client {
+	return &Client{
+		URL:        strings.TrimSuffix(url, "/"),
+		HTTPClient: http.DefaultClient,
+		state:      &State{},
+	}
+}
+
+// WithState sets a previously verified state, subsequent reads are then proven consistent with it
+func (c *Client) WithState(state *State) *Client {
+	c.state = state
+	return c
+}
+
+// WithToken sets the token used to authenticate requests
+func (c *Client) WithToken(token string) *Client {
+	c.token = token
+	return c
+}
+
+// State returns the latest verified state
+func (c *Client) State() *State {
+	return c.state
+}
+
+// Login authenticates the client
+func (c *Client) Login(ctx context.Context, user, password []byte) error {
+	var res struct {
+		Token string `json:"token"`
+	}
+
+	err := c.call(ctx, http.MethodPost, "/login", map[string][]byte{"user": user, "password": password}, &res)
+	if err != nil {
+		return err
+	}
+
+	c.token = res.Token
+
+	return nil
+}
+
+// UseDatabase selects the database subsequent requests are made against, the verified state is reset
+func (c *Client) UseDatabase(ctx context.Context, db string) error {
+	var res struct {
+		Token string `json:"token"`
+	}
+
+	err := c.call(ctx, http.MethodGet, "/db/use/"+url.PathEscape(db), nil, &res)
+	if err != nil {
+		return err
+	}
+
+	c.token = res.Token
+	c.state = &State{}
+
+	return nil
+}
+
+// VerifiedGet returns the current value of key once its inclusion and the consistency with the latest verified state are proven
+func (c *Client) VerifiedGet(ctx context.Context, key []byte) (*Entry, error) {
+	if len(key) == 0 {
+		return nil, ErrIllegalArguments
+	}
+
+	req := map[string]interface{}{
+		"keyRequest":   map[string][]byte{"key": key},
+		"proveSinceTx": strconv.FormatUint(c.state.TxID, 10),
+	}
+
+	var res verifiableEntry
+
+	err := c.call(ctx, http.MethodPost, "/db/verifiable/get", req, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	newState, err := verifyEntry(c.state, key, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	c.state = newState
+
+	return &Entry{Tx: uint64(res.Entry.Tx), Key: res.Entry.Key, Value: res.Entry.Value}, nil
+}
+
+func verifyEntry(state *State, key []byte, res *verifiableEntry) (*State, error) {
+	if res.Entry == nil || res.InclusionProof == nil || res.VerifiableTx == nil || res.VerifiableTx.DualProof == nil {
+		return nil, ErrCorruptedData
+	}
+
+	if !bytes.Equal(key, res.Entry.Key) && res.Entry.ReferencedBy == nil {
+		return nil, ErrCorruptedData
+	}
+
+	dualProof, err := res.VerifiableTx.DualProof.toDualProof()
+	if err != nil {
+		return nil, err
+	}
+
+	inclusionProof, err := res.InclusionProof.toInclusionProof()
+	if err != nil {
+		return nil, err
+	}
+
+	var vTx uint64
+	var digest [verification.HashSize]byte
+
+	if res.Entry.ReferencedBy == nil {
+		vTx = uint64(res.Entry.Tx)
+		digest = verification.EntryDigest(key, res.Entry.Value)
+	} else {
+		vTx = uint64(res.Entry.ReferencedBy.Tx)
+		digest = verification.ReferenceDigest(res.Entry.ReferencedBy.Key, res.Entry.Key, uint64(res.Entry.ReferencedBy.AtTx))
+	}
+
+	var eh [verification.HashSize]byte
+	var sourceID, targetID uint64
+	var sourceAlh, targetAlh [verification.HashSize]byte
+
+	if state.TxID <= vTx {
+		eh = dualProof.TargetTxHeader.Eh
+		sourceID = state.TxID
+		sourceAlh = state.TxHash
+		targetID = vTx
+		targetAlh = dualProof.TargetTxHeader.Alh()
+	} else {
+		eh = dualProof.SourceTxHeader.Eh
+		sourceID = vTx
+		sourceAlh = dualProof.SourceTxHeader.Alh()
+		targetID = state.TxID
+		targetAlh = state.TxHash
+	}
+
+	if !verification.VerifyInclusion(inclusionProof, digest, eh) {
+		return nil, ErrCorruptedData
+	}
+
+	if state.TxID > 0 && !verification.VerifyDualProof(dualProof, sourceID, targetID, sourceAlh, targetAlh) {
+		return nil, ErrCorruptedData
+	}
+
+	return &State{TxID: targetID, TxHash: targetAlh}, nil
+}
+
+func (c *Client) call(ctx context.Context, method, path string, body interface{}, res interface{}) error {
+	var r *bytes.Reader
+
+	if body == nil {
+		r = bytes.NewReader(nil)
+	} else {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.URL+path, r)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(b, &e) == nil && e.Message != "" {
+			return fmt.Errorf("%s (http status %d)", e.Message, resp.StatusCode)
+		}
+		return fmt.Errorf("unexpected http status %d", resp.StatusCode)
+	}
+
+	return json.Unmarshal(b, res)
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"go/build"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/auth"
+	"github.com/codenotary/immudb/pkg/server"
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestStdlibOnlyDependencies(t *testing.T) {
+	pkg, err := build.ImportDir(".", 0)
+	require.NoError(t, err)
+
+	for _, imp := range pkg.Imports {
+		if imp == "github.com/codenotary/immudb/pkg/verification" {
+			continue
+		}
+		require.False(t, strings.Contains(strings.Split(imp, "/")[0], "."), "unexpected dependency %s", imp)
+	}
+}
+
+func TestClient(t *testing.T) {
+	options := server.DefaultOptions().WithMetricsServer(false).WithAdminPassword(auth.SysAdminPassword)
+	s := server.DefaultServer().WithOptions(options).(*server.ImmuServer)
+	defer os.RemoveAll(options.Dir)
+
+	require.NoError(t, s.Initialize())
+	defer s.CloseDatabases()
+
+	mux := runtime.NewServeMux()
+	require.NoError(t, schema.RegisterImmuServiceHandlerServer(context.Background(), mux, s))
+
+	hs := httptest.NewServer(mux)
+	defer hs.Close()
+
+	lr, err := s.Login(context.Background(), &schema.LoginRequest{
+		User:     []byte(auth.SysAdminUsername),
+		Password: []byte(auth.SysAdminPassword),
+	})
+	require.NoError(t, err)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", lr.Token))
+
+	for i := 0; i < 10; i++ {
+		_, err = s.Set(ctx, &schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("key"), Value: []byte{byte(i)}}}})
+		require.NoError(t, err)
+	}
+
+	_, err = s.SetReference(ctx, &schema.ReferenceRequest{Key: []byte("ref"), ReferencedKey: []byte("key")})
+	require.NoError(t, err)
+
+	c := NewClient(hs.URL + "/")
+
+	_, err = c.VerifiedGet(context.Background(), []byte("key"))
+	require.Error(t, err)
+
+	err = c.Login(context.Background(), []byte(auth.SysAdminUsername), []byte("wrong"))
+	require.Error(t, err)
+
+	err = c.Login(context.Background(), []byte(auth.SysAdminUsername), []byte(auth.SysAdminPassword))
+	require.NoError(t, err)
+
+	err = c.UseDatabase(context.Background(), server.DefaultdbName)
+	require.NoError(t, err)
+
+	_, err = c.VerifiedGet(context.Background(), nil)
+	require.Equal(t, ErrIllegalArguments, err)
+
+	e, err := c.VerifiedGet(context.Background(), []byte("key"))
+	require.NoError(t, err)
+	require.Equal(t, []byte{9}, e.Value)
+
+	state := c.State()
+	require.Equal(t, e.Tx, state.TxID)
+
+	_, err = s.Set(ctx, &schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("key2"), Value: []byte("value2")}}})
+	require.NoError(t, err)
+
+	e, err = c.VerifiedGet(context.Background(), []byte("key2"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("value2"), e.Value)
+	require.Greater(t, c.State().TxID, state.TxID)
+
+	e, err = c.VerifiedGet(context.Background(), []byte("ref"))
+	require.NoError(t, err)
+	require.Equal(t, []byte{9}, e.Value)
+
+	// a state not matching the server history can't be proven consistent
+	c.WithState(&State{TxID: 2, TxHash: [32]byte{1}})
+
+	_, err = c.VerifiedGet(context.Background(), []byte("key2"))
+	require.Equal(t, ErrCorruptedData, err)
+}
@@ -0,0 +1,255 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/codenotary/immudb/pkg/verification"
+)
+
+// jsonInt64 decodes 64-bit integers, which the REST gateway encodes as strings
+type jsonInt64 int64
+
+func (i *jsonInt64) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		s = string(b)
+	}
+
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	*i = jsonInt64(v)
+
+	return nil
+}
+
+// jsonUint64 decodes unsigned 64-bit integers, which the REST gateway encodes as strings
+type jsonUint64 uint64
+
+func (i *jsonUint64) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		s = string(b)
+	}
+
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	*i = jsonUint64(v)
+
+	return nil
+}
+
+type verifiableEntry struct {
+	Entry          *entry          `json:"entry"`
+	VerifiableTx   *verifiableTx   `json:"verifiableTx"`
+	InclusionProof *inclusionProof `json:"inclusionProof"`
+}
+
+type entry struct {
+	Tx           jsonUint64 `json:"tx"`
+	Key          []byte     `json:"key"`
+	Value        []byte     `json:"value"`
+	ReferencedBy *reference `json:"referencedBy"`
+}
+
+type reference struct {
+	Tx   jsonUint64 `json:"tx"`
+	Key  []byte     `json:"key"`
+	AtTx jsonUint64 `json:"atTx"`
+}
+
+type verifiableTx struct {
+	DualProof *dualProof `json:"dualProof"`
+}
+
+type txMetadata struct {
+	ID       jsonUint64 `json:"id"`
+	PrevAlh  []byte     `json:"prevAlh"`
+	Ts       jsonInt64  `json:"ts"`
+	NEntries int32      `json:"nentries"`
+	EH       []byte     `json:"eH"`
+	BlTxID   jsonUint64 `json:"blTxId"`
+	BlRoot   []byte     `json:"blRoot"`
+}
+
+type inclusionProof struct {
+	Leaf          int32    `json:"leaf"`
+	Width         int32    `json:"width"`
+	Terms         [][]byte `json:"terms"`
+	HashAlgorithm uint32   `json:"hashAlgorithm"`
+}
+
+type linearProof struct {
+	SourceTxID jsonUint64 `json:"sourceTxId"`
+	TargetTxID jsonUint64 `json:"TargetTxId"`
+	Terms      [][]byte   `json:"terms"`
+}
+
+type dualProof struct {
+	SourceTxMetadata   *txMetadata  `json:"sourceTxMetadata"`
+	TargetTxMetadata   *txMetadata  `json:"targetTxMetadata"`
+	InclusionProof     [][]byte     `json:"inclusionProof"`
+	ConsistencyProof   [][]byte     `json:"consistencyProof"`
+	TargetBlTxAlh      []byte       `json:"targetBlTxAlh"`
+	LastInclusionProof [][]byte     `json:"lastInclusionProof"`
+	LinearProof        *linearProof `json:"linearProof"`
+	HashAlgorithm      uint32       `json:"hashAlgorithm"`
+}
+
+func digestFrom(b []byte) ([verification.HashSize]byte, error) {
+	var d [verification.HashSize]byte
+
+	if len(b) != verification.HashSize {
+		return d, ErrCorruptedData
+	}
+
+	copy(d[:], b)
+
+	return d, nil
+}
+
+func digestsFrom(bs [][]byte) ([][verification.HashSize]byte, error) {
+	ds := make([][verification.HashSize]byte, len(bs))
+
+	for i, b := range bs {
+		d, err := digestFrom(b)
+		if err != nil {
+			return nil, err
+		}
+		ds[i] = d
+	}
+
+	return ds, nil
+}
+
+func (md *txMetadata) toTxHeader() (*verification.TxHeader, error) {
+	if md == nil {
+		return nil, ErrCorruptedData
+	}
+
+	prevAlh, err := digestFrom(md.PrevAlh)
+	if err != nil {
+		return nil, err
+	}
+
+	eh, err := digestFrom(md.EH)
+	if err != nil {
+		return nil, err
+	}
+
+	// the root is omitted while there are no binary linked transactions
+	var blRoot [verification.HashSize]byte
+	if len(md.BlRoot) > 0 {
+		blRoot, err = digestFrom(md.BlRoot)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &verification.TxHeader{
+		ID:       uint64(md.ID),
+		PrevAlh:  prevAlh,
+		Ts:       int64(md.Ts),
+		NEntries: int(md.NEntries),
+		Eh:       eh,
+		BlTxID:   uint64(md.BlTxID),
+		BlRoot:   blRoot,
+	}, nil
+}
+
+func (p *inclusionProof) toInclusionProof() (*verification.InclusionProof, error) {
+	if p.HashAlgorithm != 0 {
+		return nil, ErrCorruptedData
+	}
+
+	terms, err := digestsFrom(p.Terms)
+	if err != nil {
+		return nil, err
+	}
+
+	return &verification.InclusionProof{
+		Leaf:  int(p.Leaf),
+		Width: int(p.Width),
+		Terms: terms,
+	}, nil
+}
+
+func (p *dualProof) toDualProof() (*verification.DualProof, error) {
+	if p.HashAlgorithm != 0 || p.LinearProof == nil {
+		return nil, ErrCorruptedData
+	}
+
+	sourceTxHeader, err := p.SourceTxMetadata.toTxHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	targetTxHeader, err := p.TargetTxMetadata.toTxHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	iproof, err := digestsFrom(p.InclusionProof)
+	if err != nil {
+		return nil, err
+	}
+
+	cproof, err := digestsFrom(p.ConsistencyProof)
+	if err != nil {
+		return nil, err
+	}
+
+	lproof, err := digestsFrom(p.LastInclusionProof)
+	if err != nil {
+		return nil, err
+	}
+
+	var targetBlTxAlh [verification.HashSize]byte
+	if len(p.TargetBlTxAlh) > 0 {
+		targetBlTxAlh, err = digestFrom(p.TargetBlTxAlh)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	linearTerms, err := digestsFrom(p.LinearProof.Terms)
+	if err != nil {
+		return nil, err
+	}
+
+	return &verification.DualProof{
+		SourceTxHeader:     sourceTxHeader,
+		TargetTxHeader:     targetTxHeader,
+		InclusionProof:     iproof,
+		ConsistencyProof:   cproof,
+		TargetBlTxAlh:      targetBlTxAlh,
+		LastInclusionProof: lproof,
+		LinearProof: &verification.LinearProof{
+			SourceTxID: uint64(p.LinearProof.SourceTxID),
+			TargetTxID: uint64(p.LinearProof.TargetTxID),
+			Terms:      linearTerms,
+		},
+	}, nil
+}
@@ -0,0 +1,327 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package verification implements the verification of immudb proofs over plain byte structures.
+// It only depends on the standard library so it can be used where the full client can't,
+// such as constrained gateways or WebAssembly environments. Only SHA-256 is supported
+package verification
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// HashSize is the size of the digests used in proofs
+const HashSize = sha256.Size
+
+const leafPrefix = byte(0)
+const nodePrefix = byte(1)
+
+// key and value prefixes used by immudb databases for plain key-value entries
+const setKeyPrefix = byte(0)
+const plainValuePrefix = byte(0)
+const referenceValuePrefix = byte(1)
+
+// TxHeader holds the transaction fields needed to compute its accumulative linear hash (Alh)
+type TxHeader struct {
+	ID       uint64
+	PrevAlh  [HashSize]byte
+	Ts       int64
+	NEntries int
+	Eh       [HashSize]byte
+	BlTxID   uint64
+	BlRoot   [HashSize]byte
+}
+
+// InclusionProof proves an entry is part of the hash tree of a transaction
+type InclusionProof struct {
+	Leaf  int
+	Width int
+	Terms [][HashSize]byte
+}
+
+// LinearProof is the list of inner hashes linking Alh@SourceTxID to Alh@TargetTxID
+type LinearProof struct {
+	SourceTxID uint64
+	TargetTxID uint64
+	Terms      [][HashSize]byte
+}
+
+// DualProof links two transactions combining linear and binary linking
+type DualProof struct {
+	SourceTxHeader     *TxHeader
+	TargetTxHeader     *TxHeader
+	InclusionProof     [][HashSize]byte
+	ConsistencyProof   [][HashSize]byte
+	TargetBlTxAlh      [HashSize]byte
+	LastInclusionProof [][HashSize]byte
+	LinearProof        *LinearProof
+}
+
+// Alh returns hash(txID + prevAlh + hash(ts + nentries + eH + blTxID + blRoot))
+func (h *TxHeader) Alh() [HashSize]byte {
+	var bj [8 + 4 + HashSize + 8 + HashSize]byte
+	binary.BigEndian.PutUint64(bj[:], uint64(h.Ts))
+	binary.BigEndian.PutUint32(bj[8:], uint32(h.NEntries))
+	copy(bj[12:], h.Eh[:])
+	binary.BigEndian.PutUint64(bj[12+HashSize:], h.BlTxID)
+	copy(bj[20+HashSize:], h.BlRoot[:])
+	innerHash := sha256.Sum256(bj[:])
+
+	var bi [8 + 2*HashSize]byte
+	binary.BigEndian.PutUint64(bi[:], h.ID)
+	copy(bi[8:], h.PrevAlh[:])
+	copy(bi[8+HashSize:], innerHash[:])
+
+	return sha256.Sum256(bi[:])
+}
+
+// KVDigest returns the digest of a key-value pair as stored in a transaction
+func KVDigest(key, value []byte) [HashSize]byte {
+	hVal := sha256.Sum256(value)
+
+	b := make([]byte, len(key)+HashSize)
+	copy(b, key)
+	copy(b[len(key):], hVal[:])
+
+	return sha256.Sum256(b)
+}
+
+// EntryDigest returns the digest of a plain entry set through the immudb API, i.e. Set or VerifiableSet
+func EntryDigest(key, value []byte) [HashSize]byte {
+	k := make([]byte, 1+len(key))
+	k[0] = setKeyPrefix
+	copy(k[1:], key)
+
+	v := make([]byte, 1+len(value))
+	v[0] = plainValuePrefix
+	copy(v[1:], value)
+
+	return KVDigest(k, v)
+}
+
+// ReferenceDigest returns the digest of a reference to referencedKey, resolved at atTx or to its latest value if atTx is zero
+func ReferenceDigest(key, referencedKey []byte, atTx uint64) [HashSize]byte {
+	k := make([]byte, 1+len(key))
+	k[0] = setKeyPrefix
+	copy(k[1:], key)
+
+	v := make([]byte, 1+8+1+len(referencedKey))
+	v[0] = referenceValuePrefix
+	binary.BigEndian.PutUint64(v[1:], atTx)
+	v[9] = setKeyPrefix
+	copy(v[10:], referencedKey)
+
+	return KVDigest(k, v)
+}
+
+// VerifyInclusion checks the entry with the provided digest is included in the transaction whose entries hash is root
+func VerifyInclusion(proof *InclusionProof, digest, root [HashSize]byte) bool {
+	if proof == nil || proof.Leaf < 0 || proof.Leaf >= proof.Width {
+		return false
+	}
+
+	leaf := [1 + HashSize]byte{leafPrefix}
+	copy(leaf[1:], digest[:])
+
+	calcRoot := sha256.Sum256(leaf[:])
+	i := proof.Leaf
+	r := proof.Width - 1
+
+	for _, t := range proof.Terms {
+		b := [1 + 2*HashSize]byte{nodePrefix}
+
+		if i%2 == 0 && i != r {
+			copy(b[1:], calcRoot[:])
+			copy(b[1+HashSize:], t[:])
+		} else {
+			copy(b[1:], t[:])
+			copy(b[1+HashSize:], calcRoot[:])
+		}
+
+		calcRoot = sha256.Sum256(b[:])
+		i /= 2
+		r /= 2
+	}
+
+	return i == r && root == calcRoot
+}
+
+// VerifyLinearProof checks Alh@targetTxID is derived from Alh@sourceTxID
+func VerifyLinearProof(proof *LinearProof, sourceTxID, targetTxID uint64, sourceAlh, targetAlh [HashSize]byte) bool {
+	if proof == nil || proof.SourceTxID != sourceTxID || proof.TargetTxID != targetTxID {
+		return false
+	}
+
+	if proof.SourceTxID == 0 || proof.SourceTxID > proof.TargetTxID ||
+		len(proof.Terms) == 0 || sourceAlh != proof.Terms[0] {
+		return false
+	}
+
+	if uint64(len(proof.Terms)) != targetTxID-sourceTxID+1 {
+		return false
+	}
+
+	calculatedAlh := proof.Terms[0]
+
+	for i := 1; i < len(proof.Terms); i++ {
+		var bs [8 + 2*HashSize]byte
+		binary.BigEndian.PutUint64(bs[:], proof.SourceTxID+uint64(i))
+		copy(bs[8:], calculatedAlh[:])
+		copy(bs[8+HashSize:], proof.Terms[i][:])
+		calculatedAlh = sha256.Sum256(bs[:])
+	}
+
+	return targetAlh == calculatedAlh
+}
+
+// VerifyDualProof checks the transaction sourceTxID with sourceAlh precedes the transaction targetTxID with targetAlh
+func VerifyDualProof(proof *DualProof, sourceTxID, targetTxID uint64, sourceAlh, targetAlh [HashSize]byte) bool {
+	if proof == nil ||
+		proof.SourceTxHeader == nil ||
+		proof.TargetTxHeader == nil ||
+		proof.SourceTxHeader.ID != sourceTxID ||
+		proof.TargetTxHeader.ID != targetTxID {
+		return false
+	}
+
+	if sourceTxID == 0 || sourceTxID > targetTxID {
+		return false
+	}
+
+	if sourceAlh != proof.SourceTxHeader.Alh() || targetAlh != proof.TargetTxHeader.Alh() {
+		return false
+	}
+
+	targetBlTxID := proof.TargetTxHeader.BlTxID
+	targetBlRoot := proof.TargetTxHeader.BlRoot
+
+	if sourceTxID < targetBlTxID &&
+		!verifyTreeInclusion(proof.InclusionProof, sourceTxID, targetBlTxID, leafFor(sourceAlh), targetBlRoot) {
+		return false
+	}
+
+	if proof.SourceTxHeader.BlTxID > 0 &&
+		!verifyTreeConsistency(proof.ConsistencyProof, proof.SourceTxHeader.BlTxID, targetBlTxID, proof.SourceTxHeader.BlRoot, targetBlRoot) {
+		return false
+	}
+
+	if targetBlTxID > 0 &&
+		!verifyTreeLastInclusion(proof.LastInclusionProof, targetBlTxID, leafFor(proof.TargetBlTxAlh), targetBlRoot) {
+		return false
+	}
+
+	if sourceTxID < targetBlTxID {
+		return VerifyLinearProof(proof.LinearProof, targetBlTxID, targetTxID, proof.TargetBlTxAlh, targetAlh)
+	}
+
+	return VerifyLinearProof(proof.LinearProof, sourceTxID, targetTxID, sourceAlh, targetAlh)
+}
+
+func leafFor(d [HashSize]byte) [HashSize]byte {
+	var b [1 + HashSize]byte
+	b[0] = leafPrefix
+	copy(b[1:], d[:])
+	return sha256.Sum256(b[:])
+}
+
+func nodeFor(l, r [HashSize]byte) [HashSize]byte {
+	var b [1 + 2*HashSize]byte
+	b[0] = nodePrefix
+	copy(b[1:], l[:])
+	copy(b[1+HashSize:], r[:])
+	return sha256.Sum256(b[:])
+}
+
+// verifyTreeInclusion checks the inclusion of the i-th leaf in the binary linking tree of width j
+func verifyTreeInclusion(iproof [][HashSize]byte, i, j uint64, iLeaf, jRoot [HashSize]byte) bool {
+	if i > j || i == 0 || (i < j && len(iproof) == 0) {
+		return false
+	}
+
+	i1 := i - 1
+	j1 := j - 1
+
+	ciRoot := iLeaf
+
+	for _, h := range iproof {
+		if i1%2 == 0 && i1 != j1 {
+			ciRoot = nodeFor(ciRoot, h)
+		} else {
+			ciRoot = nodeFor(h, ciRoot)
+		}
+
+		i1 >>= 1
+		j1 >>= 1
+	}
+
+	return jRoot == ciRoot
+}
+
+// verifyTreeConsistency checks the binary linking tree of width j is an extension of the one of width i
+func verifyTreeConsistency(cproof [][HashSize]byte, i, j uint64, iRoot, jRoot [HashSize]byte) bool {
+	if i > j || i == 0 || (i < j && len(cproof) == 0) {
+		return false
+	}
+
+	if i == j && len(cproof) == 0 {
+		return iRoot == jRoot
+	}
+
+	fn := i - 1
+	sn := j - 1
+
+	for fn%2 == 1 {
+		fn >>= 1
+		sn >>= 1
+	}
+
+	ciRoot, cjRoot := cproof[0], cproof[0]
+
+	for _, h := range cproof[1:] {
+		if fn%2 == 1 || fn == sn {
+			ciRoot = nodeFor(h, ciRoot)
+			cjRoot = nodeFor(h, cjRoot)
+
+			for fn%2 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			cjRoot = nodeFor(cjRoot, h)
+		}
+
+		fn >>= 1
+		sn >>= 1
+	}
+
+	return iRoot == ciRoot && jRoot == cjRoot
+}
+
+// verifyTreeLastInclusion checks the i-th leaf is the last one of the binary linking tree
+func verifyTreeLastInclusion(iproof [][HashSize]byte, i uint64, leaf, root [HashSize]byte) bool {
+	if i == 0 {
+		return false
+	}
+
+	calcRoot := leaf
+
+	for _, h := range iproof {
+		calcRoot = nodeFor(h, calcRoot)
+	}
+
+	return root == calcRoot
+}
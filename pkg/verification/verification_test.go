@@ -0,0 +1,122 @@
+/*
+Copyright 2021 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verification
+
+import (
+	"go/build"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStdlibOnlyDependencies(t *testing.T) {
+	pkg, err := build.ImportDir(".", 0)
+	require.NoError(t, err)
+
+	for _, imp := range pkg.Imports {
+		require.False(t, strings.Contains(strings.Split(imp, "/")[0], "."), "unexpected dependency %s", imp)
+	}
+}
+
+func txHeaderFrom(md *store.TxMetadata) *TxHeader {
+	return &TxHeader{
+		ID:       md.ID,
+		PrevAlh:  md.PrevAlh,
+		Ts:       md.Ts,
+		NEntries: md.NEntries,
+		Eh:       md.Eh,
+		BlTxID:   md.BlTxID,
+		BlRoot:   md.BlRoot,
+	}
+}
+
+func TestVerification(t *testing.T) {
+	dir, err := ioutil.TempDir("", "verification")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	st, err := store.Open(dir, store.DefaultOptions().WithMaxConcurrency(1))
+	require.NoError(t, err)
+	defer st.Close()
+
+	for i := 0; i < 32; i++ {
+		_, err := st.Commit([]*store.KV{
+			{Key: []byte{0, byte(i)}, Value: []byte{0, byte(i)}},
+			{Key: []byte{1, byte(i)}, Value: []byte{1, byte(i)}},
+		}, false)
+		require.NoError(t, err)
+	}
+
+	for sourceTxID := uint64(1); sourceTxID <= 32; sourceTxID += 5 {
+		sourceTx := st.NewTx()
+		require.NoError(t, st.ReadTx(sourceTxID, sourceTx))
+
+		proof, err := sourceTx.Proof([]byte{1, byte(sourceTxID - 1)})
+		require.NoError(t, err)
+
+		iproof := &InclusionProof{Leaf: proof.Leaf, Width: proof.Width, Terms: proof.Terms}
+		digest := KVDigest([]byte{1, byte(sourceTxID - 1)}, []byte{1, byte(sourceTxID - 1)})
+
+		require.True(t, VerifyInclusion(iproof, digest, sourceTx.Eh()))
+		require.False(t, VerifyInclusion(iproof, KVDigest([]byte{1}, nil), sourceTx.Eh()))
+		require.False(t, VerifyInclusion(nil, digest, sourceTx.Eh()))
+
+		require.Equal(t, sourceTx.Alh, txHeaderFrom(sourceTx.Metadata()).Alh())
+
+		for targetTxID := sourceTxID; targetTxID <= 32; targetTxID += 3 {
+			targetTx := st.NewTx()
+			require.NoError(t, st.ReadTx(targetTxID, targetTx))
+
+			sp, err := st.DualProof(sourceTx, targetTx)
+			require.NoError(t, err)
+
+			dproof := &DualProof{
+				SourceTxHeader:     txHeaderFrom(sp.SourceTxMetadata),
+				TargetTxHeader:     txHeaderFrom(sp.TargetTxMetadata),
+				InclusionProof:     sp.InclusionProof,
+				ConsistencyProof:   sp.ConsistencyProof,
+				TargetBlTxAlh:      sp.TargetBlTxAlh,
+				LastInclusionProof: sp.LastInclusionProof,
+				LinearProof: &LinearProof{
+					SourceTxID: sp.LinearProof.SourceTxID,
+					TargetTxID: sp.LinearProof.TargetTxID,
+					Terms:      sp.LinearProof.Terms,
+				},
+			}
+
+			require.True(t, VerifyDualProof(dproof, sourceTxID, targetTxID, sourceTx.Alh, targetTx.Alh))
+			if sourceTxID != targetTxID {
+				require.False(t, VerifyDualProof(dproof, sourceTxID, targetTxID, targetTx.Alh, sourceTx.Alh))
+			}
+
+			dproof.TargetTxHeader.Ts++
+			require.False(t, VerifyDualProof(dproof, sourceTxID, targetTxID, sourceTx.Alh, targetTx.Alh))
+		}
+	}
+
+	require.False(t, VerifyDualProof(nil, 1, 2, [HashSize]byte{}, [HashSize]byte{}))
+	require.False(t, VerifyLinearProof(nil, 1, 2, [HashSize]byte{}, [HashSize]byte{}))
+}
+
+func TestEntryDigest(t *testing.T) {
+	require.Equal(t, KVDigest([]byte{0, 'k'}, []byte{0, 'v'}), EntryDigest([]byte("k"), []byte("v")))
+	require.Equal(t, KVDigest([]byte{0, 'r'}, []byte{1, 0, 0, 0, 0, 0, 0, 0, 2, 0, 'k'}), ReferenceDigest([]byte("r"), []byte("k"), 2))
+}